@@ -0,0 +1,9 @@
+package main
+
+type contextKey string
+
+const (
+	authenticatedUserContextKey = contextKey("authenticatedUser")
+	userPreferencesContextKey   = contextKey("userPreferences")
+	userSessionContextKey       = contextKey("userSession")
+)
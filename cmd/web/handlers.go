@@ -1,19 +1,47 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
+	"io"
+	"mime"
 	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/paulboeck/FreelanceTrackerGo/internal/bankstatement"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/contacts"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/database"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/importer"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/inbound"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/jobs"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/mailer"
 	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/notify"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/signing"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/storage"
 	"github.com/paulboeck/FreelanceTrackerGo/internal/validator"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/webhook"
 )
 
 const NAME_LENGTH = 255
 
+// MAX_REPEAT_DAYS limits how far a repeated timesheet entry can run, so a mistyped date
+// doesn't log months of time
+const MAX_REPEAT_DAYS = 92
+
 // use `form:"-"` so the go-playground form library will ignore that attribute
 // when parsing a request and populating a form struct
 type clientForm struct {
@@ -26,8 +54,8 @@ type clientForm struct {
 	City                    string `form:"city"`
 	State                   string `form:"state"`
 	ZipCode                 string `form:"zip_code"`
+	Country                 string `form:"country"`
 	HourlyRate              string `form:"hourly_rate"`
-	Notes                   string `form:"notes"`
 	AdditionalInfo          string `form:"additional_info"`
 	AdditionalInfo2         string `form:"additional_info2"`
 	BillTo                  string `form:"bill_to"`
@@ -35,6 +63,21 @@ type clientForm struct {
 	InvoiceCCEmail          string `form:"invoice_cc_email"`
 	InvoiceCCDescription    string `form:"invoice_cc_description"`
 	UniversityAffiliation   string `form:"university_affiliation"`
+	BusinessProfileID       string `form:"business_profile_id"`
+	MonthlyHourAllowance    string `form:"monthly_hour_allowance"`
+	PrepayOnly              bool   `form:"prepay_only"`
+	ConsolidatedInvoicing   bool   `form:"consolidated_invoicing"`
+	FiscalYearEnd           string `form:"fiscal_year_end"`
+	PONumber                string `form:"po_number"`
+	BudgetCode              string `form:"budget_code"`
+	POValidFrom             string `form:"po_valid_from"`
+	POValidTo               string `form:"po_valid_to"`
+	InvoiceLanguage         string `form:"invoice_language"`
+	InvoicePrefix           string `form:"invoice_prefix"`
+	NextInvoiceNumber       string `form:"next_invoice_number"`
+	PipelineStage           string `form:"pipeline_stage"`
+	TagIDs                  tagIDs `form:"tag_ids"`
+	AcknowledgeWarnings     bool   `form:"acknowledge_warnings"`
 	validator.Validator     `form:"-"`
 }
 
@@ -57,6 +100,16 @@ type projectForm struct {
 	CurrencyConversionRate string `form:"currency_conversion_rate"`
 	FlatFeeInvoice         bool   `form:"flat_fee_invoice"`
 	Notes                  string `form:"notes"`
+	BusinessProfileID      string `form:"business_profile_id"`
+	EstimatedHours         string `form:"estimated_hours"`
+	EstimatedAmount        string `form:"estimated_amount"`
+	BudgetCode             string `form:"budget_code"`
+	TagIDs                 tagIDs `form:"tag_ids"`
+	AcknowledgeWarnings    bool   `form:"acknowledge_warnings"`
+	CopyTemplateTimesheets bool   `form:"copy_template_timesheets"`
+	IsUpdate               bool   `form:"-"`
+	CloneOf                string `form:"-"`
+	TemplateTimesheets     int    `form:"-"`
 	validator.Validator    `form:"-"`
 }
 
@@ -64,8 +117,16 @@ type timesheetForm struct {
 	WorkDate            string `form:"work_date"`
 	HoursWorked         string `form:"hours_worked"`
 	HourlyRate          string `form:"hourly_rate"`
+	CostRate            string `form:"cost_rate"`
+	ServiceID           string `form:"service_id"`
+	WorkType            string `form:"work_type"`
 	Description         string `form:"description"`
+	RepeatUntil         string `form:"repeat_until"`
+	SkipWeekends        bool   `form:"skip_weekends"`
+	AcknowledgeWarnings bool   `form:"acknowledge_warnings"`
+	OverrideLock        bool   `form:"override_lock"`
 	IsUpdate            bool   `form:"-"`
+	MaxDescription      int    `form:"-"`
 	validator.Validator `form:"-"`
 }
 
@@ -73,66 +134,306 @@ type invoiceForm struct {
 	InvoiceDate         string `form:"invoice_date"`
 	DatePaid            string `form:"date_paid"`
 	PaymentTerms        string `form:"payment_terms"`
+	PaymentTermsPreset  string `form:"payment_terms_preset"`
 	AmountDue           string `form:"amount_due"`
 	DisplayDetails      bool   `form:"display_details"`
+	AmountPaid          string `form:"amount_paid"`
+	ApplyCredit         bool   `form:"apply_credit"`
+	InvoiceNumber       string `form:"invoice_number"`
+	DueDate             string `form:"due_date"`
+	CCEmail             string `form:"cc_email"`
+	BudgetCode          string `form:"budget_code"`
+	TimesheetsFrom      string `form:"timesheets_from"`
+	TimesheetsTo        string `form:"timesheets_to"`
+	DiscountPercent     string `form:"discount_percent"`
+	DiscountAmount      string `form:"discount_amount"`
+	DiscountReason      string `form:"discount_reason"`
+	AcknowledgeWarnings bool   `form:"acknowledge_warnings"`
+	IsUpdate            bool   `form:"-"`
+	validator.Validator `form:"-"`
+}
+
+type businessProfileForm struct {
+	Name                   string `form:"name"`
+	FreelancerName         string `form:"freelancer_name"`
+	FreelancerAddress      string `form:"freelancer_address"`
+	FreelancerCityStateZip string `form:"freelancer_city_state_zip"`
+	FreelancerPhone        string `form:"freelancer_phone"`
+	FreelancerEmail        string `form:"freelancer_email"`
+	LogoPath               string `form:"logo_path"`
+	BankDetails            string `form:"bank_details"`
+	InvoicePrefix          string `form:"invoice_prefix"`
+	NextInvoiceNumber      string `form:"next_invoice_number"`
+	validator.Validator    `form:"-"`
+}
+
+type serviceForm struct {
+	Name                string `form:"name"`
+	DefaultRate         string `form:"default_rate"`
+	Unit                string `form:"unit"`
+	validator.Validator `form:"-"`
+}
+
+type paymentTermsForm struct {
+	Terms               string `form:"terms"`
+	validator.Validator `form:"-"`
+}
+
+type checklistItemForm struct {
+	Label               string `form:"label"`
+	TagID               int    `form:"tag_id"`
+	validator.Validator `form:"-"`
+}
+
+type externalIDForm struct {
+	Source              string `form:"source"`
+	ExternalID          string `form:"external_id"`
+	validator.Validator `form:"-"`
+}
+
+type tagForm struct {
+	Name                string `form:"name"`
+	Color               string `form:"color"`
+	validator.Validator `form:"-"`
+}
+
+// savedViewForm saves the filter of a list page, given as its URL query, under a name
+type savedViewForm struct {
+	List                string `form:"list"`
+	Name                string `form:"name"`
+	Query               string `form:"query"`
+	validator.Validator `form:"-"`
+}
+
+// tagIDs holds the tags ticked on a client or project form
+type tagIDs []int
+
+// Has reports whether the tag with the given ID is ticked
+func (ids tagIDs) Has(id int) bool {
+	for _, tagID := range ids {
+		if tagID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// projectDefaultsForm holds the terms a client's new projects start from
+type projectDefaultsForm struct {
+	Status                 string `form:"status"`
+	CurrencyDisplay        string `form:"currency_display"`
+	CurrencyConversionRate string `form:"currency_conversion_rate"`
+	DiscountPercent        string `form:"discount_percent"`
+	DiscountReason         string `form:"discount_reason"`
+	FlatFeeInvoice         bool   `form:"flat_fee_invoice"`
+	validator.Validator    `form:"-"`
+}
+
+type clientNoteForm struct {
+	Body                string `form:"body"`
+	Pinned              bool   `form:"pinned"`
+	validator.Validator `form:"-"`
+}
+
+type milestoneForm struct {
+	Name                string `form:"name"`
+	DueDate             string `form:"due_date"`
+	Fee                 string `form:"fee"`
+	Status              string `form:"status"`
+	validator.Validator `form:"-"`
+}
+
+type invoiceVoidForm struct {
+	VoidReason          string `form:"void_reason"`
+	validator.Validator `form:"-"`
+}
+
+type clientApprovalForm struct {
+	Name                string `form:"name"`
+	validator.Validator `form:"-"`
+}
+
+type invoiceEmailForm struct {
+	To                  string `form:"to"`
+	CC                  string `form:"cc"`
+	Subject             string `form:"subject"`
+	Message             string `form:"message"`
+	Attachments         []int  `form:"attachment"`
+	TimesheetReport     bool   `form:"timesheet_report"`
+	validator.Validator `form:"-"`
+}
+
+// invoiceRemindersForm holds the overdue invoices picked to be emailed reminders of
+type invoiceRemindersForm struct {
+	Invoices            []int `form:"invoice"`
+	validator.Validator `form:"-"`
+}
+
+// Picked reports whether the invoice with the given ID is chosen to be reminded of
+func (f invoiceRemindersForm) Picked(id int) bool {
+	return slices.Contains(f.Invoices, id)
+}
+
+// Attached reports whether the project attachment with the given ID is chosen to be sent
+func (f invoiceEmailForm) Attached(id int) bool {
+	return slices.Contains(f.Attachments, id)
+}
+
+type contactSyncForm struct {
+	Source              string `form:"source"`
+	validator.Validator `form:"-"`
+}
+
+type contactSyncRow struct {
+	Apply    bool   `form:"apply"`
+	Name     string `form:"name"`
+	Email    string `form:"email"`
+	Phone    string `form:"phone"`
+	Address1 string `form:"address1"`
+	City     string `form:"city"`
+	State    string `form:"state"`
+	ZipCode  string `form:"zip_code"`
+}
+
+type contactSyncApplyForm struct {
+	Changes     []contactSyncRow `form:"changes"`
+	NewContacts []contactSyncRow `form:"new"`
+}
+
+type csvImportForm struct {
+	Tool                string `form:"tool"`
+	Kind                string `form:"kind"`
+	Preview             bool   `form:"preview"`
+	validator.Validator `form:"-"`
+}
+
+// bankStatementForm holds the payments ticked for recording among those proposed from a bank
+// statement, each written as invoice ID|date paid|amount
+type bankStatementForm struct {
+	Payments            []string `form:"payment"`
+	validator.Validator `form:"-"`
+}
+
+type userLoginForm struct {
+	Email               string `form:"email"`
+	Password            string `form:"password"`
+	RememberMe          bool   `form:"remember_me"`
+	validator.Validator `form:"-"`
+}
+
+type userPreferencesForm struct {
+	PageSize            string `form:"page_size"`
+	LandingPage         string `form:"landing_page"`
+	DateFormat          string `form:"date_format"`
+	Theme               string `form:"theme"`
+	validator.Validator `form:"-"`
+}
+
+type userForm struct {
+	Name                string `form:"name"`
+	Email               string `form:"email"`
+	Password            string `form:"password"`
+	Role                string `form:"role"`
 	validator.Validator `form:"-"`
 }
 
+const MIN_PASSWORD_LENGTH = 8
+
 type settingsForm struct {
 	Settings            map[string]string `form:"-"`
 	validator.Validator `form:"-"`
 }
 
+// projectPickForm holds the search typed into the project picker
+type projectPickForm struct {
+	Query string `form:"q"`
+}
+
+// migrateForm holds the options for applying or rolling back migrations from the admin page
+type migrateForm struct {
+	Steps               int  `form:"steps"`
+	DryRun              bool `form:"dry_run"`
+	validator.Validator `form:"-"`
+}
+
+// databaseMaintenanceForm holds the maintenance tasks picked on the admin page
+type databaseMaintenanceForm struct {
+	Tasks []string `form:"tasks"`
+}
+
+type integrityRepairForm struct {
+	Check               string `form:"check"`
+	Action              string `form:"action"`
+	ParentID            int    `form:"parent_id"`
+	validator.Validator `form:"-"`
+}
+
 // home handles http requests to the root URl of the project
 func (app *application) home(res http.ResponseWriter, req *http.Request) {
-	// Get page size setting with fallback
-	pageSize := 10 // Default fallback
-	if pageSizeSetting, err := app.settings.GetString("list_page_size"); err == nil {
-		if ps, err := strconv.Atoi(pageSizeSetting); err == nil && ps > 0 {
-			pageSize = ps
-		}
+	pageSize := app.pageSize(req)
+	page := currentPage(req, "page")
+	offset := int64((page - 1) * pageSize)
+
+	tagFilter, err := app.tagFilter(req)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	filter, ok := app.listFilter(res, req, models.ClientFilters)
+	if !ok {
+		return
 	}
 
-	// Get current page from query parameter
-	currentPage := 1
-	if pageParam := req.URL.Query().Get("page"); pageParam != "" {
-		if p, err := strconv.Atoi(pageParam); err == nil && p > 0 {
-			currentPage = p
+	// Get paginated clients and total count, narrowed to the filter or tag being filtered on
+	var clients []models.Client
+	var totalCount int64
+	if len(filter) > 0 {
+		var ids []int
+		ids, totalCount, err = app.listFilters.Find(models.ClientFilters, filter, time.Now(), int64(pageSize), offset)
+		if err != nil {
+			app.modelError(res, req, err)
+			return
+		}
+		clients, err = app.clients.GetByIDs(ids)
+	} else if tagFilter != nil {
+		clients, err = app.clients.GetWithPaginationByTag(tagFilter.ID, int64(pageSize), offset)
+		if err == nil {
+			totalCount, err = app.clients.GetCountByTag(tagFilter.ID)
+		}
+	} else {
+		clients, err = app.clients.GetWithPagination(int64(pageSize), offset)
+		if err == nil {
+			totalCount, err = app.clients.GetCount()
 		}
 	}
-
-	// Calculate offset
-	offset := int64((currentPage - 1) * pageSize)
-
-	// Get paginated clients and total count
-	clients, err := app.clients.GetWithPagination(int64(pageSize), offset)
 	if err != nil {
 		app.serverError(res, req, err)
 		return
 	}
 
-	totalCount, err := app.clients.GetCount()
+	tags, err := app.tags.GetAll()
 	if err != nil {
 		app.serverError(res, req, err)
 		return
 	}
-
-	// Calculate pagination info
-	totalPages := int((totalCount + int64(pageSize) - 1) / int64(pageSize))
-
-	pagination := &paginationData{
-		CurrentPage: currentPage,
-		TotalPages:  totalPages,
-		HasPrev:     currentPage > 1,
-		HasNext:     currentPage < totalPages,
-		PrevPage:    currentPage - 1,
-		NextPage:    currentPage + 1,
-		PageSize:    pageSize,
+	clientTags, err := app.tags.ClientTagMap()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	listFilter, err := app.listFilterData(req, models.ClientFilters, filter)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
 	}
 
 	data := app.newTemplateData(req)
 	data.Clients = clients
-	data.Pagination = pagination
+	data.Pagination = newPagination(page, pageSize, totalCount)
+	data.Tags = tags
+	data.TagFilter = tagFilter
+	data.ListFilter = listFilter
+	data.ClientTags = clientTags
 
 	app.render(res, req, http.StatusOK, "home.html", data)
 }
@@ -155,6 +456,7 @@ func (app *application) clientView(res http.ResponseWriter, req *http.Request) {
 		}
 		return
 	}
+	app.recordVisit(req, models.VisitClient, client.ID)
 
 	// Get projects for this client
 	projects, err := app.projects.GetByClient(id)
@@ -163,9 +465,70 @@ func (app *application) clientView(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	credits, err := app.credits.GetByClient(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	balance, err := app.credits.Balance(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	now := time.Now()
+	hoursUsed, err := app.timesheets.GetClientHoursForMonth(id, now)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	paymentBehavior, err := app.clients.PaymentBehavior(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	value, err := app.reports.ClientValue(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	notes, err := app.clientNotes.GetByClient(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	pinned, err := app.clientNotes.GetPinned(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	defaults, err := app.clientProjectDefaults(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
 	data := app.newTemplateData(req)
 	data.Client = &client
+	data.PipelineStages = models.PipelineStages
+	data.ProjectDefaults = defaults
+	data.ClientNotes = notes
+	data.PinnedNotes = pinned
 	data.Projects = projects
+	data.ClientCredits = credits
+	data.ClientCredit = balance
+	data.HourUsage = models.NewHourUsage(client, now, hoursUsed)
+	data.PaymentBehavior = &paymentBehavior
+	data.ClientValue = &value
+	lastMonth, _ := models.MonthRange(now.AddDate(0, 0, -now.Day()))
+	data.ConsolidatedMonth = lastMonth.Format("2006-01")
+	setReportPeriod(&data, now)
 
 	app.render(res, req, http.StatusOK, "client.html", data)
 }
@@ -200,35 +563,162 @@ func (app *application) projectView(res http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	// Get timesheets for this project
-	timesheets, err := app.timesheets.GetByProject(id)
+	// Subcontractors get a restricted view of the project showing only their own time
+	if user := app.currentUser(req); user != nil && !user.IsOwner() {
+		shared, err := app.users.HasProjectAccess(id, user.ID)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		if !shared {
+			http.NotFound(res, req)
+			return
+		}
+
+		timesheets, err := app.timesheets.GetByProject(id)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+
+		var own []models.Timesheet
+		for _, timesheet := range timesheets {
+			if timesheet.UserID != nil && *timesheet.UserID == user.ID {
+				own = append(own, timesheet)
+			}
+		}
+
+		data := app.newTemplateData(req)
+		data.Project = &project
+		data.Client = &client
+		data.Timesheets = own
+		app.render(res, req, http.StatusOK, "project_shared.html", data)
+		return
+	}
+	app.recordVisit(req, models.VisitProject, project.ID)
+
+	// Long-running projects can have thousands of timesheets, so they and the invoices are
+	// shown a page at a time, with the totals worked out over all of them
+	pageSize := app.pageSize(req)
+	timesheetPage := currentPage(req, "timesheets_page")
+	timesheets, err := app.timesheets.GetByProjectWithPagination(id, int64(pageSize), int64((timesheetPage-1)*pageSize))
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	totals, err := app.timesheets.GetTotalsByProject(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	invoicePage := currentPage(req, "invoices_page")
+	invoices, err := app.invoices.GetByProjectWithPagination(id, int64(pageSize), int64((invoicePage-1)*pageSize))
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	invoiceCount, err := app.invoices.GetCountByProject(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	milestones, err := app.milestones.GetByProject(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	checklist, err := app.checklists.GetForProject(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	attachments, err := app.projectAttachments.GetByProject(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	approvals, err := app.approvals.GetByProject(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	locked, err := app.timesheets.GetLockedIDs(id)
 	if err != nil {
 		app.serverError(res, req, err)
 		return
 	}
 
-	// Get invoices for this project
-	invoices, err := app.invoices.GetByProject(id)
+	// Get the subcontractors this project is shared with, and everyone it could be shared with
+	sharedWith, err := app.users.GetProjectUsers(id)
 	if err != nil {
 		app.serverError(res, req, err)
 		return
 	}
 
+	allUsers, err := app.users.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	var subcontractors []models.User
+	for _, user := range allUsers {
+		if !user.IsOwner() {
+			subcontractors = append(subcontractors, user)
+		}
+	}
+
 	data := app.newTemplateData(req)
 	data.Project = &project
 	data.Client = &client
 	data.Timesheets = timesheets
+	data.TimesheetPages = newListPagination(req, "timesheets_page", timesheetPage, pageSize, totals.Entries)
+	data.LockedTimesheets = locked
 	data.Invoices = invoices
+	data.InvoicePages = newListPagination(req, "invoices_page", invoicePage, pageSize, invoiceCount)
+	data.Milestones = milestones
+	milestoneFees := models.TotalMilestoneFees(milestones)
+	data.MilestoneFees = &milestoneFees
+	data.ProjectChecklist = checklist
+	data.ProjectAttachments = attachments
+	data.ClientApprovals = approvals
+	data.SharedWith = sharedWith
+	data.Users = subcontractors
+	data.Margin = &totals.Margin
+	data.Estimate = models.NewProjectEstimate(project.EstimatedHours, project.EstimatedAmount, totals.Hours, totals.Billed)
+	setReportPeriod(&data, time.Now())
 
 	app.render(res, req, http.StatusOK, "project.html", data)
 }
 
 // clientCreate handles a GET request which returns an empty client detail form
 func (app *application) clientCreate(res http.ResponseWriter, req *http.Request) {
+	profiles, err := app.businessProfiles.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	tags, err := app.tags.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
 	data := app.newTemplateData(req)
 	data.Form = clientForm{
 		IncludeAddressOnInvoice: true, // Default to checked
 	}
+	data.BusinessProfiles = profiles
+	data.Tags = tags
+	data.Countries = models.Countries
+	data.InvoiceLanguages = models.InvoiceLanguages
+	data.PipelineStages = models.PipelineStages
+	data.Draft = draftPath(models.DraftClient, 0)
 	app.render(res, req, http.StatusOK, "client_create.html", data)
 }
 
@@ -272,91 +762,71 @@ func (app *application) clientCreatePost(res http.ResponseWriter, req *http.Requ
 	form.CheckField(validator.MaxChars(form.City, NAME_LENGTH), "city", fmt.Sprintf("City must be shorter than %d characters", NAME_LENGTH))
 	form.CheckField(validator.MaxChars(form.State, 50), "state", "State must be shorter than 50 characters")
 	form.CheckField(validator.MaxChars(form.ZipCode, 20), "zip_code", "Zip code must be shorter than 20 characters")
-	form.CheckField(validator.MaxChars(form.Notes, 2000), "notes", "Notes must be shorter than 2000 characters")
+	checkCountry(&form.Validator, form.Country)
+	checkInvoiceLanguage(&form.Validator, form.InvoiceLanguage)
+	checkBudgetCode(&form.Validator, form.BudgetCode)
 	form.CheckField(validator.MaxChars(form.AdditionalInfo, NAME_LENGTH), "additional_info", fmt.Sprintf("Additional info must be shorter than %d characters", NAME_LENGTH))
 	form.CheckField(validator.MaxChars(form.AdditionalInfo2, NAME_LENGTH), "additional_info2", fmt.Sprintf("Additional info 2 must be shorter than %d characters", NAME_LENGTH))
 	form.CheckField(validator.MaxChars(form.BillTo, NAME_LENGTH), "bill_to", fmt.Sprintf("Bill to must be shorter than %d characters", NAME_LENGTH))
 	form.CheckField(validator.MaxChars(form.InvoiceCCDescription, 500), "invoice_cc_description", "Invoice CC description must be shorter than 500 characters")
 	form.CheckField(validator.MaxChars(form.UniversityAffiliation, NAME_LENGTH), "university_affiliation", fmt.Sprintf("University affiliation must be shorter than %d characters", NAME_LENGTH))
-
-	if !form.Valid() {
+	app.checkBusinessProfileField(&form.Validator, form.BusinessProfileID)
+	checkMonthlyHourAllowance(&form.Validator, form.MonthlyHourAllowance)
+	checkClientInvoiceSequence(&form.Validator, form.InvoicePrefix, form.NextInvoiceNumber)
+	dateFormat := app.dateFormat(req)
+	checkPOFields(&form.Validator, dateFormat, form)
+	form.CheckField(form.PipelineStage == "" || models.IsPipelineStage(form.PipelineStage), "pipeline_stage", "Pipeline stage must be one of the listed stages")
+	app.checkClientEmails(req.Context(), &form)
+
+	// An address that looks undeliverable can still be saved once the user has acknowledged it
+	if !form.Valid() || (form.HasWarnings() && !form.AcknowledgeWarnings) {
+		profiles, err := app.businessProfiles.GetAll()
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		tags, err := app.tags.GetAll()
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
 		data := app.newTemplateData(req)
 		data.Form = form
+		data.BusinessProfiles = profiles
+		data.Tags = tags
+		data.Countries = models.Countries
+		data.InvoiceLanguages = models.InvoiceLanguages
+		data.PipelineStages = models.PipelineStages
+		data.Draft = draftPath(models.DraftClient, 0)
 		app.render(res, req, http.StatusUnprocessableEntity, "client_create.html", data)
 		return
 	}
 
-	// Convert string fields to pointers for optional fields
-	var phone, address1, address2, address3, city, state, zipCode, notes, additionalInfo, additionalInfo2, billTo, invoiceCCEmail, invoiceCCDescription, universityAffiliation *string
-
-	if form.Phone != "" {
-		phone = &form.Phone
-	}
-	if form.Address1 != "" {
-		address1 = &form.Address1
-	}
-	if form.Address2 != "" {
-		address2 = &form.Address2
-	}
-	if form.Address3 != "" {
-		address3 = &form.Address3
-	}
-	if form.City != "" {
-		city = &form.City
-	}
-	if form.State != "" {
-		state = &form.State
+	var id int
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		id, err = tx.Clients.Insert(formToClient(form, dateFormat, 0, hourlyRate))
+		if err != nil {
+			return err
+		}
+		if form.PipelineStage != "" {
+			err = tx.Clients.SetPipelineStage(id, form.PipelineStage)
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Tags.SetClientTags(id, form.TagIDs)
+	})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
 	}
-	if form.ZipCode != "" {
-		zipCode = &form.ZipCode
+	app.discardDraft(req, models.DraftClient, 0)
+	if form.PipelineStage != "" {
+		app.flash(req, fmt.Sprintf("Prospect %s added to the pipeline", form.Name))
+	} else {
+		app.flash(req, fmt.Sprintf("Client %s created", form.Name))
 	}
-	if form.Notes != "" {
-		notes = &form.Notes
-	}
-	if form.AdditionalInfo != "" {
-		additionalInfo = &form.AdditionalInfo
-	}
-	if form.AdditionalInfo2 != "" {
-		additionalInfo2 = &form.AdditionalInfo2
-	}
-	if form.BillTo != "" {
-		billTo = &form.BillTo
-	}
-	if form.InvoiceCCEmail != "" {
-		invoiceCCEmail = &form.InvoiceCCEmail
-	}
-	if form.InvoiceCCDescription != "" {
-		invoiceCCDescription = &form.InvoiceCCDescription
-	}
-	if form.UniversityAffiliation != "" {
-		universityAffiliation = &form.UniversityAffiliation
-	}
-
-	id, err := app.clients.Insert(
-		form.Name,
-		form.Email,
-		phone,
-		address1,
-		address2,
-		address3,
-		city,
-		state,
-		zipCode,
-		hourlyRate,
-		notes,
-		additionalInfo,
-		additionalInfo2,
-		billTo,
-		form.IncludeAddressOnInvoice,
-		invoiceCCEmail,
-		invoiceCCDescription,
-		universityAffiliation,
-	)
-	if err != nil {
-		app.serverError(res, req, err)
-		return
-	}
-	http.Redirect(res, req, fmt.Sprintf("/client/view/%d", id), http.StatusSeeOther)
+	app.redirect(res, req, fmt.Sprintf("/client/view/%d", id), http.StatusSeeOther)
 }
 
 // clientUpdate handles a GET request which returns a client update form pre-populated with client data
@@ -377,6 +847,23 @@ func (app *application) clientUpdate(res http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	profiles, err := app.businessProfiles.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	tags, err := app.tags.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	clientTags, err := app.tags.ForClient(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	dateFormat := app.dateFormat(req)
 	data := app.newTemplateData(req)
 	data.Form = clientForm{
 		Name:                    client.Name,
@@ -388,8 +875,8 @@ func (app *application) clientUpdate(res http.ResponseWriter, req *http.Request)
 		City:                    ptrToString(client.City),
 		State:                   ptrToString(client.State),
 		ZipCode:                 ptrToString(client.ZipCode),
+		Country:                 ptrToString(client.Country),
 		HourlyRate:              fmt.Sprintf("%.2f", client.HourlyRate),
-		Notes:                   ptrToString(client.Notes),
 		AdditionalInfo:          ptrToString(client.AdditionalInfo),
 		AdditionalInfo2:         ptrToString(client.AdditionalInfo2),
 		BillTo:                  ptrToString(client.BillTo),
@@ -397,8 +884,26 @@ func (app *application) clientUpdate(res http.ResponseWriter, req *http.Request)
 		InvoiceCCEmail:          ptrToString(client.InvoiceCCEmail),
 		InvoiceCCDescription:    ptrToString(client.InvoiceCCDescription),
 		UniversityAffiliation:   ptrToString(client.UniversityAffiliation),
+		BusinessProfileID:       idToString(client.BusinessProfileID),
+		MonthlyHourAllowance:    floatToString(client.MonthlyHourAllowance),
+		PrepayOnly:              client.PrepayOnly,
+		ConsolidatedInvoicing:   client.ConsolidatedInvoicing,
+		FiscalYearEnd:           ptrToString(client.FiscalYearEnd),
+		PONumber:                ptrToString(client.PONumber),
+		BudgetCode:              ptrToString(client.BudgetCode),
+		POValidFrom:             formatOptionalDate(dateFormat, client.POValidFrom),
+		POValidTo:               formatOptionalDate(dateFormat, client.POValidTo),
+		InvoiceLanguage:         ptrToString(client.InvoiceLanguage),
+		InvoicePrefix:           ptrToString(client.InvoicePrefix),
+		NextInvoiceNumber:       idToString(client.NextInvoiceNumber),
+		TagIDs:                  models.TagIDs(clientTags),
 	}
 	data.Client = &client
+	data.BusinessProfiles = profiles
+	data.Tags = tags
+	data.Countries = models.Countries
+	data.InvoiceLanguages = models.InvoiceLanguages
+	data.Draft = draftPath(models.DraftClient, id)
 	app.render(res, req, http.StatusOK, "client_create.html", data)
 }
 
@@ -410,19 +915,619 @@ func ptrToString(s *string) string {
 	return *s
 }
 
+// stringToPtr converts an optional form value to a *string, treating blank as nil
+func stringToPtr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// idToString formats an optional record ID for a form select
+func idToString(id *int) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.Itoa(*id)
+}
+
+// stringToID parses an optional record ID from a form select, treating blank as nil
+func stringToID(s string) *int {
+	if s == "" {
+		return nil
+	}
+	id, err := strconv.Atoi(s)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+// joinDateFormats lists date formats for an error message
+func joinDateFormats(formats []models.DateFormat) string {
+	names := make([]string, len(formats))
+	for i, format := range formats {
+		names[i] = string(format)
+	}
+	return strings.Join(names, ", ")
+}
+
+// checkDateField validates an optional date entered in the configured date format
+func checkDateField(v *validator.Validator, dateFormat models.DateFormat, value, field, label string) {
+	v.OptionalDate(value, dateFormat, field, label)
+}
+
+// floatToString formats an optional number for a form input
+func floatToString(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+// stringToFloat parses an optional number from a form input, treating blank as nil
+func stringToFloat(s string) *float64 {
+	if s == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// checkMonthlyHourAllowance validates an optional cap on a client's hours each month
+func checkMonthlyHourAllowance(v *validator.Validator, value string) {
+	if value == "" {
+		return
+	}
+	allowance, err := strconv.ParseFloat(value, 64)
+	v.CheckField(err == nil && allowance > 0, "monthly_hour_allowance", "Monthly hour allowance must be a number greater than 0")
+}
+
+// checkClientInvoiceSequence validates a client's optional invoice numbering sequence. A
+// prefix on its own has no sequence to number, so it needs a next invoice number too.
+func checkClientInvoiceSequence(v *validator.Validator, prefix, next string) {
+	v.CheckField(validator.MaxChars(prefix, 20), "invoice_prefix", "Invoice prefix must be shorter than 20 characters")
+	if next == "" {
+		v.CheckField(prefix == "", "next_invoice_number", "Next invoice number is required to give the client its own sequence")
+		return
+	}
+	number, err := strconv.Atoi(next)
+	v.CheckField(err == nil && number >= 1, "next_invoice_number", "Next invoice number must be a whole number of 1 or greater")
+}
+
+// checkPOFields validates a client's optional fiscal year end and purchase order details
+func checkPOFields(v *validator.Validator, dateFormat models.DateFormat, form clientForm) {
+	if form.FiscalYearEnd != "" {
+		_, _, err := models.ParseFiscalYearEnd(form.FiscalYearEnd)
+		v.CheckField(err == nil, "fiscal_year_end", "Fiscal year end must be a month and day written as MM-DD, e.g. 06-30")
+	}
+	v.CheckField(validator.MaxChars(form.PONumber, NAME_LENGTH), "po_number", fmt.Sprintf("PO number must be shorter than %d characters", NAME_LENGTH))
+
+	from := v.OptionalDate(form.POValidFrom, dateFormat, "po_valid_from", "PO valid from")
+	to := v.OptionalDate(form.POValidTo, dateFormat, "po_valid_to", "PO valid to")
+	if from != nil && to != nil {
+		v.CheckField(!to.Before(*from), "po_valid_to", "PO valid to must not be before PO valid from")
+	}
+}
+
+// formatOptionalDate formats an optional date for a form input
+func formatOptionalDate(dateFormat models.DateFormat, t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return dateFormat.Format(*t)
+}
+
+// parseOptionalDate parses an optional date from a form input that has already been
+// validated, treating blank as nil
+func parseOptionalDate(dateFormat models.DateFormat, value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	t, err := dateFormat.Parse(value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// checkCountry validates an optional country, which must be one whose address layout we know
+func checkCountry(v *validator.Validator, value string) {
+	if value == "" {
+		return
+	}
+	_, ok := models.LookupCountry(value)
+	v.CheckField(ok, "country", "Choose a country from the list")
+}
+
+// checkInvoiceLanguage validates an optional invoice language, which must be one we have
+// labels for
+func checkInvoiceLanguage(v *validator.Validator, value string) {
+	if value == "" {
+		return
+	}
+	_, ok := models.LookupInvoiceLanguage(value)
+	v.CheckField(ok, "invoice_language", "Choose an invoice language from the list")
+}
+
+// checkBudgetCode validates an optional budget code, the cost center or account code printed
+// on invoices
+func checkBudgetCode(v *validator.Validator, value string) {
+	v.CheckField(validator.MaxChars(value, NAME_LENGTH), "budget_code", fmt.Sprintf("Budget code must be shorter than %d characters", NAME_LENGTH))
+}
+
+// checkBusinessProfileField validates an optional business profile selection
+func (app *application) checkBusinessProfileField(v *validator.Validator, value string) {
+	if value == "" {
+		return
+	}
+	id, err := strconv.Atoi(value)
+	if err == nil {
+		_, err = app.businessProfiles.Get(id)
+	}
+	v.CheckField(err == nil, "business_profile_id", "Business profile must be one of the available profiles")
+}
+
+// checkPricingWarnings flags a discount or adjustment which has no reason to explain it on the invoice
+func checkPricingWarnings(v *validator.Validator, discountPercent float64, discountReason string, adjustmentAmount float64, adjustmentReason string) {
+	v.CheckWarning(discountPercent == 0 || validator.NotBlank(discountReason), "discount_reason", "A discount is set without a reason to show on the invoice")
+	v.CheckWarning(adjustmentAmount == 0 || validator.NotBlank(adjustmentReason), "adjustment_reason", "An adjustment is set without a reason to show on the invoice")
+}
+
+// checkProjectFormWarnings adds the pricing warnings for the discount and adjustment entered on a project form
+func checkProjectFormWarnings(form *projectForm) {
+	discountPercent, _ := strconv.ParseFloat(form.DiscountPercent, 64)
+	adjustmentAmount, _ := strconv.ParseFloat(form.AdjustmentAmount, 64)
+	checkPricingWarnings(&form.Validator, discountPercent, form.DiscountReason, adjustmentAmount, form.AdjustmentReason)
+}
+
+// checkHourAllowance warns when logging hours would take a client past its monthly allowance.
+// previousHours are the hours already counted for this entry in the same month, if any.
+func (app *application) checkHourAllowance(v *validator.Validator, client models.Client, workDate time.Time, hours, previousHours float64) error {
+	if client.MonthlyHourAllowance == nil {
+		return nil
+	}
+	used, err := app.timesheets.GetClientHoursForMonth(client.ID, workDate)
+	if err != nil {
+		return err
+	}
+	total := used - previousHours + hours
+	v.CheckWarning(total <= *client.MonthlyHourAllowance, "hours_worked",
+		fmt.Sprintf("This brings %s to %.2f hours in %s, over the monthly allowance of %.2f hours",
+			client.Name, total, workDate.Format("January 2006"), *client.MonthlyHourAllowance))
+	return nil
+}
+
+// timesheetService looks up the service chosen on a timesheet form, or returns nil for an
+// ordinary hourly entry. An entry being updated keeps its own service, and the unit it was
+// logged in, even if the service has since been changed or removed from the catalog.
+func (app *application) timesheetService(form *timesheetForm, current *models.Timesheet) (*models.Service, error) {
+	if form.ServiceID == "" {
+		return nil, nil
+	}
+	id, err := strconv.Atoi(form.ServiceID)
+	if err != nil {
+		form.AddFieldError("service_id", "Service must be one of the available services")
+		return nil, nil
+	}
+
+	if current != nil && current.ServiceID != nil && *current.ServiceID == id {
+		return &models.Service{ID: id, Name: current.ServiceName, DefaultRate: current.HourlyRate, Unit: current.Unit}, nil
+	}
+
+	service, err := app.services.Get(id)
+	if errors.Is(err, models.ErrNoRecord) {
+		form.AddFieldError("service_id", "Service must be one of the available services")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
+
+// serviceUnit returns the ID of a timesheet's service and the unit it is billed in, which is
+// hours when there is no service
+func serviceUnit(service *models.Service) (*int, string) {
+	if service == nil {
+		return nil, models.UnitHour
+	}
+	return &service.ID, service.Unit
+}
+
+// sameService reports whether two optional service IDs refer to the same service, or both to none
+func sameService(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// timesheetServices returns the services offered on a timesheet form, including the service
+// of the entry being updated when it is no longer in the catalog
+func (app *application) timesheetServices(current *models.Timesheet) ([]models.Service, error) {
+	services, err := app.services.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	if current == nil || current.ServiceID == nil {
+		return services, nil
+	}
+	for _, service := range services {
+		if service.ID == *current.ServiceID {
+			return services, nil
+		}
+	}
+	return append(services, models.Service{ID: *current.ServiceID, Name: current.ServiceName, DefaultRate: current.HourlyRate, Unit: current.Unit}), nil
+}
+
+// checkInvoiceCCEmail validates the optional address copied on an invoice in place of the
+// project's or client's invoice CC email
+func checkInvoiceCCEmail(form *invoiceForm) {
+	form.CCEmail = strings.TrimSpace(form.CCEmail)
+	if form.CCEmail != "" {
+		form.CheckField(validator.Matches(strings.ToLower(form.CCEmail), validator.EmailRegex), "cc_email", "CC email must be a valid email address")
+	}
+}
+
+// parseInvoiceAmounts reads the invoice date, amount due and the optional payment on an
+// invoice form. Amounts are limited to cents and a payment can't be dated in the future.
+func parseInvoiceAmounts(form *invoiceForm, dateFormat models.DateFormat) (invoiceDate time.Time, amountDue float64, datePaid *time.Time, amountPaid *float64) {
+	invoiceDate = form.Date(form.InvoiceDate, dateFormat, "invoice_date", "Invoice date")
+	amountDue = form.Money(form.AmountDue, "amount_due", "Amount due")
+	form.CheckField(validator.DecimalPlaces(form.AmountDue, 2), "amount_due", "Amount due can't have more than 2 decimal places")
+	datePaid = form.OptionalDate(form.DatePaid, dateFormat, "date_paid", "Date paid")
+	if datePaid != nil {
+		form.CheckField(validator.DateNotInFuture(*datePaid, time.Now()), "date_paid", "Date paid can't be in the future")
+	}
+	amountPaid = form.OptionalMoney(form.AmountPaid, "amount_paid", "Amount paid")
+	form.CheckField(validator.DecimalPlaces(form.AmountPaid, 2), "amount_paid", "Amount paid can't have more than 2 decimal places")
+	return invoiceDate, amountDue, datePaid, amountPaid
+}
+
+// choosePaymentTerms takes an invoice's payment terms from the preset chosen on the form,
+// unless terms have been typed in to override it
+func choosePaymentTerms(form *invoiceForm) {
+	form.PaymentTerms = strings.TrimSpace(form.PaymentTerms)
+	if form.PaymentTerms == "" {
+		form.PaymentTerms = form.PaymentTermsPreset
+	}
+}
+
+// setPaymentTermsPresets offers the payment terms presets on the invoice form. Terms matching
+// a preset are shown as that preset being chosen; any others are shown as typed in.
+func (app *application) setPaymentTermsPresets(data *templateData, form *invoiceForm) error {
+	presets, err := app.paymentTerms.GetAll()
+	if err != nil {
+		return err
+	}
+	data.TermsPresets = presets
+	if preset, ok := models.MatchPaymentTermsPreset(presets, form.PaymentTerms); ok {
+		form.PaymentTermsPreset = preset.Terms
+		form.PaymentTerms = ""
+	}
+	return nil
+}
+
+// parseDueDate reads the optional due date on an invoice form. Left blank it is worked out
+// from the payment terms; otherwise it can't fall before the invoice date.
+func parseDueDate(form *invoiceForm, dateFormat models.DateFormat, invoiceDate time.Time) time.Time {
+	if form.DueDate == "" {
+		return models.DueDateFor(invoiceDate, form.PaymentTerms)
+	}
+	dueDate := form.Date(form.DueDate, dateFormat, "due_date", "Due date")
+	form.CheckDateOrder(&invoiceDate, &dueDate, "due_date", "Due date can't be before the invoice date")
+	return dueDate
+}
+
+// parseTimesheetRange reads the optional work date range limiting the timesheets listed on an
+// invoice. Either end may be left blank to leave that end of the range open.
+func parseTimesheetRange(form *invoiceForm, dateFormat models.DateFormat) (from, to *time.Time) {
+	from = form.OptionalDate(form.TimesheetsFrom, dateFormat, "timesheets_from", "Timesheets from")
+	to = form.OptionalDate(form.TimesheetsTo, dateFormat, "timesheets_to", "Timesheets to")
+	form.CheckDateOrder(from, to, "timesheets_to", "Timesheets to can't be before timesheets from")
+	return from, to
+}
+
+// parseInvoiceDiscount reads the discount given on the invoice form, either a percentage of the
+// amount due or a fixed amount no larger than it but not both, into the terms the invoice is billed on. Leaving
+// both blank gives no discount. A form without the discount fields keeps the terms' discount,
+// reporting that none was given.
+func parseInvoiceDiscount(req *http.Request, form *invoiceForm, terms *models.InvoiceFinancials, amountDue float64) bool {
+	if !req.PostForm.Has("discount_percent") && !req.PostForm.Has("discount_amount") {
+		return false
+	}
+
+	percent := form.OptionalMoney(form.DiscountPercent, "discount_percent", "Discount percent")
+	if percent != nil {
+		form.CheckField(*percent <= 100, "discount_percent", "Discount percent can't be more than 100")
+	}
+	amount := form.OptionalMoney(form.DiscountAmount, "discount_amount", "Discount amount")
+	if amount != nil {
+		form.CheckField(*amount <= amountDue, "discount_amount", "Discount amount can't be more than the amount due")
+	}
+	form.CheckField(validator.DecimalPlaces(form.DiscountAmount, 2), "discount_amount", "Discount amount can't have more than 2 decimal places")
+	form.CheckField(percent == nil || amount == nil, "discount_amount", "Give either a discount percent or a discount amount, not both")
+	form.DiscountReason = strings.TrimSpace(form.DiscountReason)
+	form.CheckField(validator.MaxChars(form.DiscountReason, NAME_LENGTH), "discount_reason", fmt.Sprintf("Discount reason must be shorter than %d characters", NAME_LENGTH))
+	terms.SetDiscount(percent, amount, form.DiscountReason)
+	return true
+}
+
+// setDiscountFields fills in the invoice form's discount from the terms it is billed on
+func setDiscountFields(form *invoiceForm, terms models.InvoiceFinancials) {
+	form.DiscountPercent, form.DiscountAmount = "", ""
+	if terms.DiscountPercent != nil {
+		form.DiscountPercent = strconv.FormatFloat(*terms.DiscountPercent, 'f', -1, 64)
+	}
+	if terms.DiscountAmount != nil {
+		form.DiscountAmount = fmt.Sprintf("%.2f", *terms.DiscountAmount)
+	}
+	form.DiscountReason = terms.DiscountReason
+}
+
+// invoiceTerms returns the financial terms an invoice is billed on: those captured when it
+// was created, or the project's for invoices from before they were captured
+func invoiceTerms(invoice models.Invoice, project models.Project) models.InvoiceFinancials {
+	if invoice.Financials != nil {
+		return *invoice.Financials
+	}
+	return models.FinancialsFromProject(project)
+}
+
+// applyExchangeRate converts a new invoice at the rate of its date in the exchange rate
+// history in place of the project's rate. When the history has no rate for the day itself and
+// an exchange rate service is set up, the day's rate is fetched and stored first; if that
+// fails, the latest rate stored before the day is used, and failing that the project's.
+func (app *application) applyExchangeRate(ctx context.Context, financials *models.InvoiceFinancials, date time.Time) error {
+	currency := strings.ToUpper(strings.TrimSpace(financials.CurrencyDisplay))
+	if !financials.IsConverted() || currency == "" {
+		return nil
+	}
+
+	rate, err := app.exchangeRates.GetRate(currency, date)
+	found := err == nil
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		return err
+	}
+
+	if !found || !rate.Date.Equal(date) {
+		fetched, err := app.fetchExchangeRate(ctx, currency, date)
+		if err != nil {
+			app.logger.Warn("fetching exchange rate", "currency", currency, "date", date.Format("2006-01-02"), "error", err.Error())
+		} else if fetched != nil {
+			rate, found = *fetched, true
+		}
+	}
+
+	if found {
+		financials.UseExchangeRate(rate)
+	}
+	return nil
+}
+
+// fetchExchangeRate fetches a currency's rate on a date from the exchange rate service and
+// stores it in the history under that date, returning nil when no service is set up
+func (app *application) fetchExchangeRate(ctx context.Context, currency string, date time.Time) (*models.ExchangeRate, error) {
+	serviceURL, err := app.settings.GetString("exchange_rate_url")
+	if err != nil || serviceURL == "" {
+		return nil, nil
+	}
+	domestic, err := app.settings.GetString("payment_domestic_currency")
+	if err != nil || domestic == "" {
+		domestic = "USD"
+	}
+
+	rates, err := app.rateFetcher.Fetch(ctx, serviceURL, domestic, date, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rates published for an earlier day, as for weekends, are stored under the day asked for
+	// so the service isn't asked again
+	rate := models.ExchangeRate{Currency: currency, Date: date, Rate: rates.Rates[currency], Source: rates.Source}
+	err = app.exchangeRates.Save(rate)
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// invoiceBusinessProfile returns the business profile whose sequence numbers a project's
+// invoices, or nil when neither the project nor its client has one
+func (app *application) invoiceBusinessProfile(project models.Project, client models.Client) (*models.BusinessProfile, error) {
+	profileID := models.ResolveBusinessProfileID(project, client)
+	if profileID == nil {
+		return nil, nil
+	}
+	profile, err := app.businessProfiles.Get(*profileID)
+	if errors.Is(err, models.ErrNoRecord) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// invoiceSequence returns the numbering sequence for a project's invoices: the client's own
+// when it has one, otherwise that of the invoices' business profile, or nil when neither has one
+func (app *application) invoiceSequence(project models.Project, client models.Client) (*models.InvoiceSequence, error) {
+	profile, err := app.invoiceBusinessProfile(project, client)
+	if err != nil {
+		return nil, err
+	}
+	return models.ResolveInvoiceSequence(client, profile), nil
+}
+
+// checkManualInvoiceNumber validates an invoice number entered on the create form and
+// returns it if it should be used as is. A blank number, or the one the sequence would
+// issue next, returns "" so that the sequence is used and advanced as usual.
+func (app *application) checkManualInvoiceNumber(v *validator.Validator, sequence *models.InvoiceSequence, invoiceNumber string) (string, error) {
+	if invoiceNumber == "" || (sequence != nil && invoiceNumber == sequence.Upcoming()) {
+		return "", nil
+	}
+
+	exists, err := app.invoices.NumberExists(invoiceNumber)
+	if err != nil {
+		return "", err
+	}
+	v.CheckField(!exists, "invoice_number", fmt.Sprintf("Invoice number %s is already in use", invoiceNumber))
+	if sequence != nil {
+		v.CheckField(!sequence.Clashes(invoiceNumber), "invoice_number",
+			fmt.Sprintf("Invoice number %s would be issued again by the %s numbering sequence, which is at %s",
+				invoiceNumber, sequence.Name, sequence.Upcoming()))
+	}
+	return invoiceNumber, nil
+}
+
+// checkInvoiceWarnings adds warnings for an invoice paid before it was issued, for one the
+// client's purchase order or fiscal year won't accept, and for a discount or adjustment that
+// will appear on the invoice without an explanation
+func checkInvoiceWarnings(form *invoiceForm, dateFormat models.DateFormat, client models.Client, terms models.InvoiceFinancials, invoiceDate time.Time, datePaid, timesheetsFrom *time.Time) {
+	form.CheckWarning(datePaid == nil || !datePaid.Before(invoiceDate), "date_paid", "Date paid is before the invoice date")
+	form.CheckWarning(client.InPOPeriod(invoiceDate), "invoice_date",
+		fmt.Sprintf("The invoice date is outside %s's purchase order period of %s", client.Name, poPeriod(dateFormat, client)))
+	if timesheetsFrom != nil {
+		if yearEnd := client.FiscalYearEndFor(*timesheetsFrom); yearEnd != nil {
+			form.CheckWarning(!invoiceDate.After(*yearEnd), "timesheets_from",
+				fmt.Sprintf("The work invoiced starts in %s's fiscal year ending %s, before the invoice date", client.Name, dateFormat.Format(*yearEnd)))
+		}
+	}
+
+	var discount, adjustmentAmount float64
+	if terms.DiscountPercent != nil {
+		discount = *terms.DiscountPercent
+	}
+	if terms.DiscountAmount != nil {
+		discount = *terms.DiscountAmount
+	}
+	if terms.AdjustmentAmount != nil {
+		adjustmentAmount = *terms.AdjustmentAmount
+	}
+	checkPricingWarnings(&form.Validator, discount, terms.DiscountReason, adjustmentAmount, terms.AdjustmentReason)
+}
+
+// poPeriod describes the dates a client's purchase order is valid for
+func poPeriod(dateFormat models.DateFormat, client models.Client) string {
+	switch {
+	case client.POValidFrom != nil && client.POValidTo != nil:
+		return fmt.Sprintf("%s to %s", dateFormat.Format(*client.POValidFrom), dateFormat.Format(*client.POValidTo))
+	case client.POValidFrom != nil:
+		return "from " + dateFormat.Format(*client.POValidFrom)
+	case client.POValidTo != nil:
+		return "until " + dateFormat.Format(*client.POValidTo)
+	}
+	return ""
+}
+
+// settleInvoice records the payment received for an invoice, optionally paying part of it
+// from the client's credit first, and keeps any overpayment as credit for later invoices.
+// The invoice is read back within tx so that both are set against the total it bills.
+func settleInvoice(tx models.TxModels, clientID, invoiceID int, amountPaid *float64, applyCredit bool) error {
+	invoice, err := tx.Invoices.Get(invoiceID)
+	if err != nil {
+		return err
+	}
+	invoice.AmountPaid = amountPaid
+
+	if applyCredit {
+		balance, err := tx.Credits.Balance(clientID)
+		if err != nil {
+			return err
+		}
+
+		invoice.CreditApplied = min(max(balance, 0), invoice.BilledTotal())
+		if invoice.CreditApplied > 0 {
+			err = tx.Credits.Apply(clientID, invoice.ID, invoice.CreditApplied)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Credit applied beyond a total that has since been lowered goes back to the client
+	if billed := invoice.BilledTotal(); invoice.CreditApplied > billed {
+		invoice.CreditApplied = max(billed, 0)
+		err = tx.Credits.SetApplied(clientID, invoice.ID, invoice.CreditApplied)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Invoices.SetPayment(invoice.ID, invoice.AmountPaid, invoice.CreditApplied)
+	if err != nil {
+		return err
+	}
+	return tx.Credits.RecordOverpayment(clientID, invoice.ID, invoice.Overpayment())
+}
+
+// recordInvoiceEvents logs a change to an invoice, along with a paid event when the change
+// is the one that marked it paid
+func recordInvoiceEvents(tx models.TxModels, invoiceID int, wasPaid, datePaid *time.Time, eventType string) error {
+	err := tx.InvoiceEvents.Record(invoiceID, eventType)
+	if err != nil {
+		return err
+	}
+	if wasPaid == nil && datePaid != nil {
+		return tx.InvoiceEvents.Record(invoiceID, models.InvoiceEventPaid)
+	}
+	return nil
+}
+
+// formToClient converts a clientForm to a models.Client struct
+func formToClient(form clientForm, dateFormat models.DateFormat, clientID int, hourlyRate float64) models.Client {
+	return models.Client{
+		ID:                      clientID,
+		Name:                    form.Name,
+		Email:                   form.Email,
+		Phone:                   stringToPtr(form.Phone),
+		Address1:                stringToPtr(form.Address1),
+		Address2:                stringToPtr(form.Address2),
+		Address3:                stringToPtr(form.Address3),
+		City:                    stringToPtr(form.City),
+		State:                   stringToPtr(form.State),
+		ZipCode:                 stringToPtr(form.ZipCode),
+		Country:                 stringToPtr(form.Country),
+		HourlyRate:              hourlyRate,
+		AdditionalInfo:          stringToPtr(form.AdditionalInfo),
+		AdditionalInfo2:         stringToPtr(form.AdditionalInfo2),
+		BillTo:                  stringToPtr(form.BillTo),
+		IncludeAddressOnInvoice: form.IncludeAddressOnInvoice,
+		InvoiceCCEmail:          stringToPtr(form.InvoiceCCEmail),
+		InvoiceCCDescription:    stringToPtr(form.InvoiceCCDescription),
+		UniversityAffiliation:   stringToPtr(form.UniversityAffiliation),
+		BusinessProfileID:       stringToID(form.BusinessProfileID),
+		MonthlyHourAllowance:    stringToFloat(form.MonthlyHourAllowance),
+		PrepayOnly:              form.PrepayOnly,
+		ConsolidatedInvoicing:   form.ConsolidatedInvoicing,
+		FiscalYearEnd:           stringToPtr(form.FiscalYearEnd),
+		PONumber:                stringToPtr(form.PONumber),
+		BudgetCode:              stringToPtr(form.BudgetCode),
+		POValidFrom:             parseOptionalDate(dateFormat, form.POValidFrom),
+		POValidTo:               parseOptionalDate(dateFormat, form.POValidTo),
+		InvoiceLanguage:         stringToPtr(form.InvoiceLanguage),
+		InvoicePrefix:           stringToPtr(form.InvoicePrefix),
+		NextInvoiceNumber:       stringToID(form.NextInvoiceNumber),
+	}
+}
+
 // formToProject converts a projectForm to a models.Project struct
-func formToProject(form projectForm, clientID, projectID int) (models.Project, error) {
+func formToProject(form projectForm, dateFormat models.DateFormat, clientID, projectID int) (models.Project, error) {
 	// Parse dates
 	var deadline *time.Time
 	if form.Deadline != "" {
-		if d, err := time.Parse("2006-01-02", form.Deadline); err == nil {
+		if d, err := dateFormat.Parse(form.Deadline); err == nil {
 			deadline = &d
 		}
 	}
 
 	var scheduledStart *time.Time
 	if form.ScheduledStart != "" {
-		if d, err := time.Parse("2006-01-02", form.ScheduledStart); err == nil {
+		if d, err := dateFormat.Parse(form.ScheduledStart); err == nil {
 			scheduledStart = &d
 		}
 	}
@@ -484,17 +1589,21 @@ func formToProject(form projectForm, clientID, projectID int) (models.Project, e
 		CurrencyConversionRate: currencyConversionRate,
 		FlatFeeInvoice:         form.FlatFeeInvoice,
 		Notes:                  form.Notes,
+		BusinessProfileID:      stringToID(form.BusinessProfileID),
+		EstimatedHours:         stringToFloat(form.EstimatedHours),
+		EstimatedAmount:        stringToFloat(form.EstimatedAmount),
+		BudgetCode:             form.BudgetCode,
 	}, nil
 }
 
 // projectToForm converts a models.Project to a projectForm struct
-func projectToForm(project models.Project) projectForm {
+func projectToForm(project models.Project, dateFormat models.DateFormat) projectForm {
 	// Helper to format dates
 	formatDate := func(t *time.Time) string {
 		if t == nil {
 			return ""
 		}
-		return t.Format("2006-01-02")
+		return dateFormat.Format(*t)
 	}
 
 	// Helper to format float pointers
@@ -524,6 +1633,10 @@ func projectToForm(project models.Project) projectForm {
 		CurrencyConversionRate: fmt.Sprintf("%.5f", project.CurrencyConversionRate),
 		FlatFeeInvoice:         project.FlatFeeInvoice,
 		Notes:                  project.Notes,
+		BusinessProfileID:      idToString(project.BusinessProfileID),
+		EstimatedHours:         floatToString(project.EstimatedHours),
+		EstimatedAmount:        floatToString(project.EstimatedAmount),
+		BudgetCode:             project.BudgetCode,
 	}
 }
 
@@ -573,14 +1686,23 @@ func (app *application) clientUpdatePost(res http.ResponseWriter, req *http.Requ
 	form.CheckField(validator.MaxChars(form.City, NAME_LENGTH), "city", fmt.Sprintf("City must be shorter than %d characters", NAME_LENGTH))
 	form.CheckField(validator.MaxChars(form.State, 50), "state", "State must be shorter than 50 characters")
 	form.CheckField(validator.MaxChars(form.ZipCode, 20), "zip_code", "Zip code must be shorter than 20 characters")
-	form.CheckField(validator.MaxChars(form.Notes, 2000), "notes", "Notes must be shorter than 2000 characters")
+	checkCountry(&form.Validator, form.Country)
+	checkInvoiceLanguage(&form.Validator, form.InvoiceLanguage)
+	checkBudgetCode(&form.Validator, form.BudgetCode)
 	form.CheckField(validator.MaxChars(form.AdditionalInfo, NAME_LENGTH), "additional_info", fmt.Sprintf("Additional info must be shorter than %d characters", NAME_LENGTH))
 	form.CheckField(validator.MaxChars(form.AdditionalInfo2, NAME_LENGTH), "additional_info2", fmt.Sprintf("Additional info 2 must be shorter than %d characters", NAME_LENGTH))
 	form.CheckField(validator.MaxChars(form.BillTo, NAME_LENGTH), "bill_to", fmt.Sprintf("Bill to must be shorter than %d characters", NAME_LENGTH))
 	form.CheckField(validator.MaxChars(form.InvoiceCCDescription, 500), "invoice_cc_description", "Invoice CC description must be shorter than 500 characters")
 	form.CheckField(validator.MaxChars(form.UniversityAffiliation, NAME_LENGTH), "university_affiliation", fmt.Sprintf("University affiliation must be shorter than %d characters", NAME_LENGTH))
-
-	if !form.Valid() {
+	app.checkBusinessProfileField(&form.Validator, form.BusinessProfileID)
+	checkMonthlyHourAllowance(&form.Validator, form.MonthlyHourAllowance)
+	checkClientInvoiceSequence(&form.Validator, form.InvoicePrefix, form.NextInvoiceNumber)
+	dateFormat := app.dateFormat(req)
+	checkPOFields(&form.Validator, dateFormat, form)
+	app.checkClientEmails(req.Context(), &form)
+
+	// An address that looks undeliverable can still be saved once the user has acknowledged it
+	if !form.Valid() || (form.HasWarnings() && !form.AcknowledgeWarnings) {
 		client, err := app.clients.Get(id)
 		if err != nil {
 			if errors.Is(err, models.ErrNoRecord) {
@@ -590,9 +1712,24 @@ func (app *application) clientUpdatePost(res http.ResponseWriter, req *http.Requ
 			}
 			return
 		}
+		profiles, err := app.businessProfiles.GetAll()
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		tags, err := app.tags.GetAll()
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
 		data := app.newTemplateData(req)
 		data.Form = form
 		data.Client = &client
+		data.BusinessProfiles = profiles
+		data.Tags = tags
+		data.Countries = models.Countries
+		data.InvoiceLanguages = models.InvoiceLanguages
+		data.Draft = draftPath(models.DraftClient, id)
 		app.render(res, req, http.StatusUnprocessableEntity, "client_create.html", data)
 		return
 	}
@@ -608,78 +1745,20 @@ func (app *application) clientUpdatePost(res http.ResponseWriter, req *http.Requ
 		return
 	}
 
-	// Convert string fields to pointers for optional fields
-	var phone, address1, address2, address3, city, state, zipCode, notes, additionalInfo, additionalInfo2, billTo, invoiceCCEmail, invoiceCCDescription, universityAffiliation *string
-
-	if form.Phone != "" {
-		phone = &form.Phone
-	}
-	if form.Address1 != "" {
-		address1 = &form.Address1
-	}
-	if form.Address2 != "" {
-		address2 = &form.Address2
-	}
-	if form.Address3 != "" {
-		address3 = &form.Address3
-	}
-	if form.City != "" {
-		city = &form.City
-	}
-	if form.State != "" {
-		state = &form.State
-	}
-	if form.ZipCode != "" {
-		zipCode = &form.ZipCode
-	}
-	if form.Notes != "" {
-		notes = &form.Notes
-	}
-	if form.AdditionalInfo != "" {
-		additionalInfo = &form.AdditionalInfo
-	}
-	if form.AdditionalInfo2 != "" {
-		additionalInfo2 = &form.AdditionalInfo2
-	}
-	if form.BillTo != "" {
-		billTo = &form.BillTo
-	}
-	if form.InvoiceCCEmail != "" {
-		invoiceCCEmail = &form.InvoiceCCEmail
-	}
-	if form.InvoiceCCDescription != "" {
-		invoiceCCDescription = &form.InvoiceCCDescription
-	}
-	if form.UniversityAffiliation != "" {
-		universityAffiliation = &form.UniversityAffiliation
-	}
-
-	err = app.clients.Update(
-		id,
-		form.Name,
-		form.Email,
-		phone,
-		address1,
-		address2,
-		address3,
-		city,
-		state,
-		zipCode,
-		hourlyRate,
-		notes,
-		additionalInfo,
-		additionalInfo2,
-		billTo,
-		form.IncludeAddressOnInvoice,
-		invoiceCCEmail,
-		invoiceCCDescription,
-		universityAffiliation,
-	)
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		err := tx.Clients.Update(formToClient(form, dateFormat, id, hourlyRate))
+		if err != nil {
+			return err
+		}
+		return tx.Tags.SetClientTags(id, form.TagIDs)
+	})
 	if err != nil {
-		app.serverError(res, req, err)
+		app.modelError(res, req, err)
 		return
 	}
-	http.Redirect(res, req, fmt.Sprintf("/client/view/%d", id), http.StatusSeeOther)
+	app.discardDraft(req, models.DraftClient, id)
+	app.flash(req, fmt.Sprintf("Client %s updated", form.Name))
+	app.redirect(res, req, fmt.Sprintf("/client/view/%d", id), http.StatusSeeOther)
 }
 
 // clientDelete handles a POST request to soft delete a client
@@ -691,7 +1770,7 @@ func (app *application) clientDelete(res http.ResponseWriter, req *http.Request)
 	}
 
 	// Check if client exists before deleting
-	_, err = app.clients.Get(id)
+	client, err := app.clients.Get(id)
 	if err != nil {
 		if errors.Is(err, models.ErrNoRecord) {
 			http.NotFound(res, req)
@@ -703,12 +1782,120 @@ func (app *application) clientDelete(res http.ResponseWriter, req *http.Request)
 
 	err = app.clients.Delete(id)
 	if err != nil {
-		app.serverError(res, req, err)
+		app.modelError(res, req, err)
 		return
 	}
 
 	// Redirect to home page after successful deletion
-	http.Redirect(res, req, "/", http.StatusSeeOther)
+	app.flash(req, fmt.Sprintf("Client %s deleted", client.Name))
+	app.redirect(res, req, "/", http.StatusSeeOther)
+}
+
+// clientsPipeline handles a GET request for the board of prospects in each stage of the
+// sales pipeline
+func (app *application) clientsPipeline(res http.ResponseWriter, req *http.Request) {
+	clients, err := app.clients.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Pipeline = models.NewPipeline(clients)
+	data.PipelineStages = models.PipelineStages
+	app.render(res, req, http.StatusOK, "pipeline.html", data)
+}
+
+// clientStagePost handles a POST request moving a prospect to another stage of the pipeline
+func (app *application) clientStagePost(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	client, err := app.clients.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	// Active clients have left the pipeline for good
+	if !client.IsProspect() {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
+	err = req.ParseForm()
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+	stage := req.PostForm.Get("stage")
+	if !models.IsPipelineStage(stage) {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	err = app.clients.SetPipelineStage(id, stage)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("%s moved to %s", client.Name, stage))
+	app.redirect(res, req, "/clients/pipeline", http.StatusSeeOther)
+}
+
+// clientConvertPost handles a POST request converting a prospect to an active client, so
+// that projects can be created for it
+func (app *application) clientConvertPost(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	client, err := app.clients.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	if client.IsProspect() {
+		err = app.clients.SetPipelineStage(id, "")
+		if err != nil {
+			app.modelError(res, req, err)
+			return
+		}
+		app.flash(req, fmt.Sprintf("%s converted to a client", client.Name))
+	}
+
+	app.redirect(res, req, fmt.Sprintf("/client/view/%d", id), http.StatusSeeOther)
+}
+
+// checkProjectForm validates the fields shared by the project create, update and clone forms
+func (app *application) checkProjectForm(form *projectForm, dateFormat models.DateFormat) {
+	form.CheckField(validator.NotBlank(form.Name), "name", "Name is required")
+	form.CheckField(validator.MaxChars(form.Name, NAME_LENGTH), "name", fmt.Sprintf("Name must be shorter than %d characters", NAME_LENGTH))
+
+	form.CheckField(validator.NotBlank(form.Status), "status", "Status is required")
+	form.CheckField(validator.NotBlank(form.HourlyRate), "hourly_rate", "Hourly rate is required")
+	app.checkBusinessProfileField(&form.Validator, form.BusinessProfileID)
+	checkDateField(&form.Validator, dateFormat, form.Deadline, "deadline", "Deadline")
+	checkDateField(&form.Validator, dateFormat, form.ScheduledStart, "scheduled_start", "Scheduled start")
+	form.OptionalMoney(form.EstimatedHours, "estimated_hours", "Estimated hours")
+	form.OptionalMoney(form.EstimatedAmount, "estimated_amount", "Estimated amount")
+	checkBudgetCode(&form.Validator, form.BudgetCode)
+	checkProjectFormWarnings(form)
 }
 
 // projectCreate handles a GET request which returns an empty project creation form
@@ -730,8 +1917,24 @@ func (app *application) projectCreate(res http.ResponseWriter, req *http.Request
 		return
 	}
 
-	data := app.newTemplateData(req)
-	data.Form = projectForm{
+	// Prospects have no projects until they are converted to clients
+	if client.IsProspect() {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
+	profiles, err := app.businessProfiles.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	tags, err := app.tags.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	form := projectForm{
 		Status:                 "Estimating",                             // Default status
 		HourlyRate:             fmt.Sprintf("%.2f", client.HourlyRate),   // Default from client
 		InvoiceCCEmail:         ptrToString(client.InvoiceCCEmail),       // Default from client
@@ -741,7 +1944,23 @@ func (app *application) projectCreate(res http.ResponseWriter, req *http.Request
 		CurrencyDisplay:        "USD",                                    // Default currency
 		CurrencyConversionRate: "1.00000",                                // Default conversion rate
 	}
+
+	// The client's own project defaults take the place of the usual ones
+	defaults, err := app.clientProjectDefaults(clientID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	if defaults != nil {
+		setProjectDefaultFields(&form, *defaults)
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
 	data.Client = &client
+	data.BusinessProfiles = profiles
+	data.Tags = tags
+	data.Draft = draftPath(models.DraftProject, 0)
 	app.render(res, req, http.StatusOK, "project_create.html", data)
 }
 
@@ -765,6 +1984,12 @@ func (app *application) projectCreatePost(res http.ResponseWriter, req *http.Req
 		return
 	}
 
+	// Prospects have no projects until they are converted to clients
+	if client.IsProspect() {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
 	var form projectForm
 	err = app.decodePostForm(req, &form)
 	if err != nil {
@@ -772,33 +1997,53 @@ func (app *application) projectCreatePost(res http.ResponseWriter, req *http.Req
 		return
 	}
 
-	form.CheckField(validator.NotBlank(form.Name), "name", "Name is required")
-	form.CheckField(validator.MaxChars(form.Name, NAME_LENGTH), "name", fmt.Sprintf("Name must be shorter than %d characters", NAME_LENGTH))
-
-	form.CheckField(validator.NotBlank(form.Status), "status", "Status is required")
-	form.CheckField(validator.NotBlank(form.HourlyRate), "hourly_rate", "Hourly rate is required")
+	dateFormat := app.dateFormat(req)
+	app.checkProjectForm(&form, dateFormat)
+	form.CheckWarning(!client.PrepayOnly, "client", fmt.Sprintf("%s is flagged as prepay only, so take payment before starting work", client.Name))
 
-	if !form.Valid() {
+	// Warnings don't block saving once the user has acknowledged them
+	if !form.Valid() || (form.HasWarnings() && !form.AcknowledgeWarnings) {
+		profiles, err := app.businessProfiles.GetAll()
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		tags, err := app.tags.GetAll()
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
 		data := app.newTemplateData(req)
 		data.Form = form
 		data.Client = &client
+		data.BusinessProfiles = profiles
+		data.Tags = tags
+		data.Draft = draftPath(models.DraftProject, 0)
 		app.render(res, req, http.StatusUnprocessableEntity, "project_create.html", data)
 		return
 	}
 
 	// Convert form data to Project struct
-	project, err := formToProject(form, clientID, 0)
+	project, err := formToProject(form, dateFormat, clientID, 0)
 	if err != nil {
 		app.serverError(res, req, err)
 		return
 	}
 
-	_, err = app.projects.Insert(project)
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		id, err := tx.Projects.Insert(project)
+		if err != nil {
+			return err
+		}
+		return tx.Tags.SetProjectTags(id, form.TagIDs)
+	})
 	if err != nil {
-		app.serverError(res, req, err)
+		app.modelError(res, req, err)
 		return
 	}
-	http.Redirect(res, req, fmt.Sprintf("/client/view/%d", clientID), http.StatusSeeOther)
+	app.discardDraft(req, models.DraftProject, 0)
+	app.flash(req, fmt.Sprintf("Project %s created", project.Name))
+	app.redirect(res, req, fmt.Sprintf("/client/view/%d", clientID), http.StatusSeeOther)
 }
 
 // projectUpdate handles a GET request which returns a project update form pre-populated with project data
@@ -830,9 +2075,32 @@ func (app *application) projectUpdate(res http.ResponseWriter, req *http.Request
 		return
 	}
 
+	profiles, err := app.businessProfiles.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	tags, err := app.tags.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	projectTags, err := app.tags.ForProject(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
 	data := app.newTemplateData(req)
-	data.Form = projectToForm(project)
+	form := projectToForm(project, data.DateFormat)
+	form.TagIDs = models.TagIDs(projectTags)
+	form.IsUpdate = true
+	data.Form = form
 	data.Client = &client
+	data.BusinessProfiles = profiles
+	data.Tags = tags
+	data.Draft = draftPath(models.DraftProject, id)
 	app.render(res, req, http.StatusOK, "project_create.html", data)
 }
 
@@ -863,13 +2131,12 @@ func (app *application) projectUpdatePost(res http.ResponseWriter, req *http.Req
 		return
 	}
 
-	form.CheckField(validator.NotBlank(form.Name), "name", "Name is required")
-	form.CheckField(validator.MaxChars(form.Name, NAME_LENGTH), "name", fmt.Sprintf("Name must be shorter than %d characters", NAME_LENGTH))
-
-	form.CheckField(validator.NotBlank(form.Status), "status", "Status is required")
-	form.CheckField(validator.NotBlank(form.HourlyRate), "hourly_rate", "Hourly rate is required")
+	form.IsUpdate = true
+	dateFormat := app.dateFormat(req)
+	app.checkProjectForm(&form, dateFormat)
 
-	if !form.Valid() {
+	// Warnings don't block saving once the user has acknowledged them
+	if !form.Valid() || (form.HasWarnings() && !form.AcknowledgeWarnings) {
 		client, err := app.clients.Get(project.ClientID)
 		if err != nil {
 			if errors.Is(err, models.ErrNoRecord) {
@@ -879,26 +2146,47 @@ func (app *application) projectUpdatePost(res http.ResponseWriter, req *http.Req
 			}
 			return
 		}
+		profiles, err := app.businessProfiles.GetAll()
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		tags, err := app.tags.GetAll()
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
 		data := app.newTemplateData(req)
 		data.Form = form
 		data.Client = &client
+		data.BusinessProfiles = profiles
+		data.Tags = tags
+		data.Draft = draftPath(models.DraftProject, id)
 		app.render(res, req, http.StatusUnprocessableEntity, "project_create.html", data)
 		return
 	}
 
 	// Convert form data to Project struct
-	updatedProject, err := formToProject(form, project.ClientID, id)
+	updatedProject, err := formToProject(form, dateFormat, project.ClientID, id)
 	if err != nil {
 		app.serverError(res, req, err)
 		return
 	}
 
-	err = app.projects.Update(updatedProject)
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		err := tx.Projects.Update(updatedProject)
+		if err != nil {
+			return err
+		}
+		return tx.Tags.SetProjectTags(id, form.TagIDs)
+	})
 	if err != nil {
-		app.serverError(res, req, err)
+		app.modelError(res, req, err)
 		return
 	}
-	http.Redirect(res, req, fmt.Sprintf("/client/view/%d", project.ClientID), http.StatusSeeOther)
+	app.discardDraft(req, models.DraftProject, id)
+	app.flash(req, fmt.Sprintf("Project %s updated", updatedProject.Name))
+	app.redirect(res, req, fmt.Sprintf("/client/view/%d", project.ClientID), http.StatusSeeOther)
 }
 
 // projectDelete handles a POST request to soft delete a project
@@ -922,24 +2210,25 @@ func (app *application) projectDelete(res http.ResponseWriter, req *http.Request
 
 	err = app.projects.Delete(id)
 	if err != nil {
-		app.serverError(res, req, err)
+		app.modelError(res, req, err)
 		return
 	}
 
 	// Redirect to client view page after successful deletion
-	http.Redirect(res, req, fmt.Sprintf("/client/view/%d", project.ClientID), http.StatusSeeOther)
+	app.flash(req, fmt.Sprintf("Project %s deleted", project.Name))
+	app.redirect(res, req, fmt.Sprintf("/client/view/%d", project.ClientID), http.StatusSeeOther)
 }
 
-// timesheetCreate handles a GET request which returns an empty timesheet creation form
-func (app *application) timesheetCreate(res http.ResponseWriter, req *http.Request) {
-	projectID, err := strconv.Atoi(req.PathValue("id"))
-	if err != nil || projectID < 0 {
+// projectReopenPost handles a POST request to reopen an archived project so that its
+// timesheets and invoices can be changed again
+func (app *application) projectReopenPost(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
 		http.NotFound(res, req)
 		return
 	}
 
-	// Check if project exists
-	project, err := app.projects.Get(projectID)
+	project, err := app.projects.Get(id)
 	if err != nil {
 		if errors.Is(err, models.ErrNoRecord) {
 			http.NotFound(res, req)
@@ -949,48 +2238,37 @@ func (app *application) timesheetCreate(res http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	// Get the client for context
-	client, err := app.clients.Get(project.ClientID)
-	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(res, req)
-		} else {
-			app.serverError(res, req, err)
+	if project.IsArchived() {
+		err = app.projects.SetStatus(id, models.ProjectStatusReopened)
+		if err != nil {
+			app.modelError(res, req, err)
+			return
 		}
-		return
+		app.flash(req, fmt.Sprintf("Project %s reopened", project.Name))
 	}
 
-	data := app.newTemplateData(req)
-	data.Form = timesheetForm{
-		WorkDate:   time.Now().Format("2006-01-02"),
-		HourlyRate: fmt.Sprintf("%.2f", project.HourlyRate), // Default from project
-	}
-	data.Project = &project
-	data.Client = &client
-	app.render(res, req, http.StatusOK, "timesheet_create.html", data)
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", id), http.StatusSeeOther)
 }
 
-// timesheetCreatePost handles a POST request with timesheet form data which is then
-// validated and used to insert a new timesheet into the database
-func (app *application) timesheetCreatePost(res http.ResponseWriter, req *http.Request) {
-	projectID, err := strconv.Atoi(req.PathValue("id"))
-	if err != nil || projectID < 0 {
+// cloneSource loads the project being cloned along with its client and template timesheet
+// rows, answering 404 when the project doesn't exist
+func (app *application) cloneSource(res http.ResponseWriter, req *http.Request) (models.Project, models.Client, []models.Timesheet, bool) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
 		http.NotFound(res, req)
-		return
+		return models.Project{}, models.Client{}, nil, false
 	}
 
-	// Check if project exists
-	project, err := app.projects.Get(projectID)
+	project, err := app.projects.Get(id)
 	if err != nil {
 		if errors.Is(err, models.ErrNoRecord) {
 			http.NotFound(res, req)
 		} else {
 			app.serverError(res, req, err)
 		}
-		return
+		return models.Project{}, models.Client{}, nil, false
 	}
 
-	// Get the client for context
 	client, err := app.clients.Get(project.ClientID)
 	if err != nil {
 		if errors.Is(err, models.ErrNoRecord) {
@@ -998,296 +2276,504 @@ func (app *application) timesheetCreatePost(res http.ResponseWriter, req *http.R
 		} else {
 			app.serverError(res, req, err)
 		}
-		return
+		return models.Project{}, models.Client{}, nil, false
 	}
 
-	var form timesheetForm
-	err = app.decodePostForm(req, &form)
+	timesheets, err := app.timesheets.GetByProject(id)
 	if err != nil {
-		app.clientError(res, http.StatusBadRequest)
-		return
-	}
-
-	form.CheckField(validator.NotBlank(form.WorkDate), "work_date", "Work date is required")
-	form.CheckField(validator.NotBlank(form.HoursWorked), "hours_worked", "Hours worked is required")
-	form.CheckField(validator.NotBlank(form.HourlyRate), "hourly_rate", "Hourly rate is required")
-	form.CheckField(validator.NotBlank(form.Description), "description", "Description is required")
-	form.CheckField(validator.MaxChars(form.Description, NAME_LENGTH), "description", fmt.Sprintf("Description must be shorter than %d characters", NAME_LENGTH))
-
-	// Parse and validate work date
-	var workDate time.Time
-	if form.Valid() {
-		workDate, err = time.Parse("2006-01-02", form.WorkDate)
-		if err != nil {
-			form.AddFieldError("work_date", "Work date must be in YYYY-MM-DD format")
-		}
+		app.serverError(res, req, err)
+		return models.Project{}, models.Client{}, nil, false
 	}
+	return project, client, models.TemplateTimesheets(timesheets), true
+}
 
-	// Parse and validate hours worked
-	var hoursWorked float64
-	if form.Valid() {
-		hoursWorked, err = strconv.ParseFloat(form.HoursWorked, 64)
-		if err != nil || hoursWorked < 0 {
-			form.AddFieldError("hours_worked", "Hours worked must be a positive number")
-		}
+// projectClone handles a GET request which returns a project creation form filled in from an
+// existing project, for starting a recurring engagement with the same settings
+func (app *application) projectClone(res http.ResponseWriter, req *http.Request) {
+	source, client, templates, ok := app.cloneSource(res, req)
+	if !ok {
+		return
 	}
 
-	// Parse and validate hourly rate
-	var hourlyRate float64
-	if form.Valid() {
-		hourlyRate, err = strconv.ParseFloat(form.HourlyRate, 64)
-		if err != nil || hourlyRate < 0 {
-			form.AddFieldError("hourly_rate", "Hourly rate must be a positive number")
-		}
+	profiles, err := app.businessProfiles.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
 	}
-
-	if !form.Valid() {
-		data := app.newTemplateData(req)
-		data.Form = form
-		data.Project = &project
-		data.Client = &client
-		app.render(res, req, http.StatusUnprocessableEntity, "timesheet_create.html", data)
+	tags, err := app.tags.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
 		return
 	}
-
-	_, err = app.timesheets.Insert(projectID, workDate, hoursWorked, hourlyRate, form.Description)
+	projectTags, err := app.tags.ForProject(source.ID)
 	if err != nil {
 		app.serverError(res, req, err)
 		return
 	}
-	http.Redirect(res, req, fmt.Sprintf("/project/view/%d", projectID), http.StatusSeeOther)
+
+	data := app.newTemplateData(req)
+	// The new engagement starts afresh, so its status and dates aren't copied
+	form := projectToForm(source, data.DateFormat)
+	form.Name = "Copy of " + source.Name
+	form.Status = "Estimating"
+	form.Deadline = ""
+	form.ScheduledStart = ""
+	form.TagIDs = models.TagIDs(projectTags)
+	form.CloneOf = source.Name
+	form.TemplateTimesheets = len(templates)
+	form.CopyTemplateTimesheets = len(templates) > 0
+	data.Form = form
+	data.Client = &client
+	data.BusinessProfiles = profiles
+	data.Tags = tags
+	app.render(res, req, http.StatusOK, "project_create.html", data)
 }
 
-// timesheetUpdate handles a GET request which returns a timesheet update form pre-populated with timesheet data
-func (app *application) timesheetUpdate(res http.ResponseWriter, req *http.Request) {
-	id, err := strconv.Atoi(req.PathValue("id"))
-	if err != nil || id < 0 {
-		http.NotFound(res, req)
+// projectClonePost handles a POST request with project form data which is validated and used
+// to insert a new project, optionally copying the template timesheet rows of the project it
+// was cloned from
+func (app *application) projectClonePost(res http.ResponseWriter, req *http.Request) {
+	source, client, templates, ok := app.cloneSource(res, req)
+	if !ok {
 		return
 	}
 
-	timesheet, err := app.timesheets.Get(id)
+	var form projectForm
+	err := app.decodePostForm(req, &form)
 	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(res, req)
-		} else {
-			app.serverError(res, req, err)
-		}
+		app.clientError(res, http.StatusBadRequest)
 		return
 	}
+	form.CloneOf = source.Name
+	form.TemplateTimesheets = len(templates)
 
-	// Get the project for context
-	project, err := app.projects.Get(timesheet.ProjectID)
-	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(res, req)
-		} else {
+	dateFormat := app.dateFormat(req)
+	app.checkProjectForm(&form, dateFormat)
+	form.CheckWarning(!client.PrepayOnly, "client", fmt.Sprintf("%s is flagged as prepay only, so take payment before starting work", client.Name))
+
+	// Warnings don't block saving once the user has acknowledged them
+	if !form.Valid() || (form.HasWarnings() && !form.AcknowledgeWarnings) {
+		profiles, err := app.businessProfiles.GetAll()
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		tags, err := app.tags.GetAll()
+		if err != nil {
 			app.serverError(res, req, err)
+			return
 		}
+		data := app.newTemplateData(req)
+		data.Form = form
+		data.Client = &client
+		data.BusinessProfiles = profiles
+		data.Tags = tags
+		app.render(res, req, http.StatusUnprocessableEntity, "project_create.html", data)
 		return
 	}
 
-	// Get the client for context
-	client, err := app.clients.Get(project.ClientID)
+	project, err := formToProject(form, dateFormat, source.ClientID, 0)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	// Template rows are dated today and charged at the new project's rate, except rows for a
+	// service, which keep the service's rate
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	var id int
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		id, err = tx.Projects.Insert(project)
+		if err != nil {
+			return err
+		}
+		err = tx.Tags.SetProjectTags(id, form.TagIDs)
+		if err != nil {
+			return err
+		}
+		if !form.CopyTemplateTimesheets {
+			return nil
+		}
+		for _, row := range templates {
+			rate := project.HourlyRate
+			if row.ServiceID != nil {
+				rate = row.HourlyRate
+			}
+			timesheetID, err := tx.Timesheets.Insert(id, today, 0, rate, row.Description)
+			if err != nil {
+				return err
+			}
+			if row.ServiceID != nil {
+				err = tx.Timesheets.SetService(timesheetID, row.ServiceID, row.Unit)
+				if err != nil {
+					return err
+				}
+			}
+			if row.WorkType != "" {
+				err = tx.Timesheets.SetWorkType(timesheetID, row.WorkType)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	app.flash(req, fmt.Sprintf("Project %s created from %s", project.Name, source.Name))
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", id), http.StatusSeeOther)
+}
+
+// loadClient loads a client for one of its notes or settings, answering 404 when it doesn't exist
+func (app *application) loadClient(res http.ResponseWriter, req *http.Request, clientID int) (models.Client, bool) {
+	client, err := app.clients.Get(clientID)
 	if err != nil {
 		if errors.Is(err, models.ErrNoRecord) {
 			http.NotFound(res, req)
 		} else {
 			app.serverError(res, req, err)
 		}
-		return
-	}
-
-	data := app.newTemplateData(req)
-	data.Form = timesheetForm{
-		WorkDate:    timesheet.WorkDate.Format("2006-01-02"),
-		HoursWorked: fmt.Sprintf("%.2f", timesheet.HoursWorked),
-		HourlyRate:  fmt.Sprintf("%.2f", timesheet.HourlyRate),
-		Description: timesheet.Description,
-		IsUpdate:    true,
+		return models.Client{}, false
 	}
-	data.Project = &project
-	data.Client = &client
-	app.render(res, req, http.StatusOK, "timesheet_create.html", data)
+	return client, true
 }
 
-// timesheetUpdatePost handles a POST request with timesheet form data which is then
-// validated and used to update an existing timesheet in the database
-func (app *application) timesheetUpdatePost(res http.ResponseWriter, req *http.Request) {
+// getClientNote loads the client note named in the request path along with its client,
+// answering 404 when either doesn't exist
+func (app *application) getClientNote(res http.ResponseWriter, req *http.Request) (models.ClientNote, models.Client, bool) {
 	id, err := strconv.Atoi(req.PathValue("id"))
 	if err != nil || id < 0 {
 		http.NotFound(res, req)
-		return
+		return models.ClientNote{}, models.Client{}, false
 	}
 
-	// Get the timesheet to ensure it exists and get the project ID
-	timesheet, err := app.timesheets.Get(id)
+	note, err := app.clientNotes.Get(id)
 	if err != nil {
 		if errors.Is(err, models.ErrNoRecord) {
 			http.NotFound(res, req)
 		} else {
 			app.serverError(res, req, err)
 		}
+		return models.ClientNote{}, models.Client{}, false
+	}
+
+	client, ok := app.loadClient(res, req, note.ClientID)
+	return note, client, ok
+}
+
+// validateClientNoteForm checks the client note form fields
+func validateClientNoteForm(form *clientNoteForm) {
+	form.CheckField(validator.NotBlank(form.Body), "body", "Note is required")
+	form.CheckField(validator.MaxChars(form.Body, 2000), "body", "Note must be shorter than 2000 characters")
+}
+
+// clientNoteCreate handles a GET request which returns an empty note form for a client
+func (app *application) clientNoteCreate(res http.ResponseWriter, req *http.Request) {
+	clientID, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || clientID < 0 {
+		http.NotFound(res, req)
 		return
 	}
 
-	// Get project and client for context
-	project, err := app.projects.Get(timesheet.ProjectID)
-	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(res, req)
-		} else {
-			app.serverError(res, req, err)
-		}
+	client, ok := app.loadClient(res, req, clientID)
+	if !ok {
 		return
 	}
 
-	client, err := app.clients.Get(project.ClientID)
-	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(res, req)
-		} else {
-			app.serverError(res, req, err)
-		}
+	data := app.newTemplateData(req)
+	data.Form = clientNoteForm{}
+	data.Client = &client
+	app.render(res, req, http.StatusOK, "client_note_create.html", data)
+}
+
+// clientNoteCreatePost handles a POST request with note form data which is then validated
+// and used to add a note to a client
+func (app *application) clientNoteCreatePost(res http.ResponseWriter, req *http.Request) {
+	clientID, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || clientID < 0 {
+		http.NotFound(res, req)
 		return
 	}
 
-	var form timesheetForm
+	client, ok := app.loadClient(res, req, clientID)
+	if !ok {
+		return
+	}
+
+	var form clientNoteForm
 	err = app.decodePostForm(req, &form)
 	if err != nil {
 		app.clientError(res, http.StatusBadRequest)
 		return
 	}
 
-	form.CheckField(validator.NotBlank(form.WorkDate), "work_date", "Work date is required")
-	form.CheckField(validator.NotBlank(form.HoursWorked), "hours_worked", "Hours worked is required")
-	form.CheckField(validator.NotBlank(form.HourlyRate), "hourly_rate", "Hourly rate is required")
-	form.CheckField(validator.NotBlank(form.Description), "description", "Description is required")
-	form.CheckField(validator.MaxChars(form.Description, NAME_LENGTH), "description", fmt.Sprintf("Description must be shorter than %d characters", NAME_LENGTH))
+	validateClientNoteForm(&form)
+	if !form.Valid() {
+		data := app.newTemplateData(req)
+		data.Form = form
+		data.Client = &client
+		app.render(res, req, http.StatusUnprocessableEntity, "client_note_create.html", data)
+		return
+	}
 
-	// Parse and validate work date
-	var workDate time.Time
-	if form.Valid() {
-		workDate, err = time.Parse("2006-01-02", form.WorkDate)
-		if err != nil {
-			form.AddFieldError("work_date", "Work date must be in YYYY-MM-DD format")
-		}
+	_, err = app.clientNotes.Insert(client.ID, form.Body, form.Pinned)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
 	}
+	app.flash(req, "Note added")
+	app.redirect(res, req, fmt.Sprintf("/client/view/%d", client.ID), http.StatusSeeOther)
+}
 
-	// Parse and validate hours worked
-	var hoursWorked float64
-	if form.Valid() {
-		hoursWorked, err = strconv.ParseFloat(form.HoursWorked, 64)
-		if err != nil || hoursWorked < 0 {
-			form.AddFieldError("hours_worked", "Hours worked must be a positive number")
-		}
+// clientNoteUpdate handles a GET request which returns a note form pre-populated with the note
+func (app *application) clientNoteUpdate(res http.ResponseWriter, req *http.Request) {
+	note, client, ok := app.getClientNote(res, req)
+	if !ok {
+		return
 	}
 
-	// Parse and validate hourly rate
-	var hourlyRate float64
-	if form.Valid() {
-		hourlyRate, err = strconv.ParseFloat(form.HourlyRate, 64)
-		if err != nil || hourlyRate < 0 {
-			form.AddFieldError("hourly_rate", "Hourly rate must be a positive number")
-		}
+	data := app.newTemplateData(req)
+	data.Form = clientNoteForm{
+		Body:   note.Body,
+		Pinned: note.Pinned,
+	}
+	data.Client = &client
+	data.ClientNote = &note
+	app.render(res, req, http.StatusOK, "client_note_create.html", data)
+}
+
+// clientNoteUpdatePost handles a POST request with note form data which is then validated
+// and used to update an existing note
+func (app *application) clientNoteUpdatePost(res http.ResponseWriter, req *http.Request) {
+	note, client, ok := app.getClientNote(res, req)
+	if !ok {
+		return
+	}
+
+	var form clientNoteForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
 	}
 
+	validateClientNoteForm(&form)
 	if !form.Valid() {
-		form.IsUpdate = true
 		data := app.newTemplateData(req)
 		data.Form = form
-		data.Project = &project
 		data.Client = &client
-		app.render(res, req, http.StatusUnprocessableEntity, "timesheet_create.html", data)
+		data.ClientNote = &note
+		app.render(res, req, http.StatusUnprocessableEntity, "client_note_create.html", data)
 		return
 	}
 
-	err = app.timesheets.Update(id, workDate, hoursWorked, hourlyRate, form.Description)
+	err = app.clientNotes.Update(note.ID, form.Body, form.Pinned)
 	if err != nil {
-		app.serverError(res, req, err)
+		app.modelError(res, req, err)
 		return
 	}
-	http.Redirect(res, req, fmt.Sprintf("/project/view/%d", timesheet.ProjectID), http.StatusSeeOther)
+	app.flash(req, "Note updated")
+	app.redirect(res, req, fmt.Sprintf("/client/view/%d", client.ID), http.StatusSeeOther)
 }
 
-// timesheetDelete handles a POST request to soft delete a timesheet
-func (app *application) timesheetDelete(res http.ResponseWriter, req *http.Request) {
-	id, err := strconv.Atoi(req.PathValue("id"))
-	if err != nil || id < 0 {
-		http.NotFound(res, req)
+// clientNotePinPost handles a POST request which pins a note, or unpins it if it is pinned
+func (app *application) clientNotePinPost(res http.ResponseWriter, req *http.Request) {
+	note, client, ok := app.getClientNote(res, req)
+	if !ok {
 		return
 	}
 
-	// Check if timesheet exists before deleting and get project ID for redirect
-	timesheet, err := app.timesheets.Get(id)
+	err := app.clientNotes.SetPinned(note.ID, !note.Pinned)
 	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(res, req)
-		} else {
-			app.serverError(res, req, err)
-		}
+		app.modelError(res, req, err)
+		return
+	}
+	if note.Pinned {
+		app.flash(req, "Note unpinned")
+	} else {
+		app.flash(req, "Note pinned")
+	}
+	app.redirect(res, req, fmt.Sprintf("/client/view/%d", client.ID), http.StatusSeeOther)
+}
+
+// clientNoteDelete handles a POST request to delete a note
+func (app *application) clientNoteDelete(res http.ResponseWriter, req *http.Request) {
+	note, client, ok := app.getClientNote(res, req)
+	if !ok {
 		return
 	}
 
-	err = app.timesheets.Delete(id)
+	err := app.clientNotes.Delete(note.ID)
 	if err != nil {
-		app.serverError(res, req, err)
+		app.modelError(res, req, err)
 		return
 	}
+	app.flash(req, "Note deleted")
+	app.redirect(res, req, fmt.Sprintf("/client/view/%d", client.ID), http.StatusSeeOther)
+}
 
-	// Redirect to project view page after successful deletion
-	http.Redirect(res, req, fmt.Sprintf("/project/view/%d", timesheet.ProjectID), http.StatusSeeOther)
+// clientProjectDefaults returns the terms a client's new projects start from, or nil when the
+// client has none of its own
+func (app *application) clientProjectDefaults(clientID int) (*models.ProjectDefaults, error) {
+	defaults, err := app.projectDefaults.Get(clientID)
+	if errors.Is(err, models.ErrNoRecord) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &defaults, nil
 }
 
-// invoiceCreate handles a GET request which returns an empty invoice creation form
-func (app *application) invoiceCreate(res http.ResponseWriter, req *http.Request) {
-	projectID, err := strconv.Atoi(req.PathValue("id"))
-	if err != nil || projectID < 0 {
+// setProjectDefaultFields fills in a new project form from a client's project defaults
+func setProjectDefaultFields(form *projectForm, defaults models.ProjectDefaults) {
+	form.Status = defaults.Status
+	form.CurrencyDisplay = defaults.CurrencyDisplay
+	form.CurrencyConversionRate = fmt.Sprintf("%.5f", defaults.CurrencyConversionRate)
+	form.DiscountPercent = ""
+	if defaults.DiscountPercent != nil {
+		form.DiscountPercent = fmt.Sprintf("%.4f", *defaults.DiscountPercent)
+	}
+	form.DiscountReason = defaults.DiscountReason
+	form.FlatFeeInvoice = defaults.FlatFeeInvoice
+}
+
+// clientProjectDefaultsEdit handles a GET request which returns the form for a client's
+// project defaults, filled in with the usual defaults when the client has none yet
+func (app *application) clientProjectDefaultsEdit(res http.ResponseWriter, req *http.Request) {
+	clientID, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || clientID < 0 {
 		http.NotFound(res, req)
 		return
 	}
 
-	// Check if project exists
-	project, err := app.projects.Get(projectID)
+	client, ok := app.loadClient(res, req, clientID)
+	if !ok {
+		return
+	}
+
+	defaults, err := app.clientProjectDefaults(client.ID)
 	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(res, req)
-		} else {
-			app.serverError(res, req, err)
+		app.serverError(res, req, err)
+		return
+	}
+
+	form := projectDefaultsForm{Status: "Estimating", CurrencyDisplay: "USD", CurrencyConversionRate: "1.00000"}
+	if defaults != nil {
+		form = projectDefaultsForm{
+			Status:                 defaults.Status,
+			CurrencyDisplay:        defaults.CurrencyDisplay,
+			CurrencyConversionRate: fmt.Sprintf("%.5f", defaults.CurrencyConversionRate),
+			DiscountReason:         defaults.DiscountReason,
+			FlatFeeInvoice:         defaults.FlatFeeInvoice,
 		}
+		if defaults.DiscountPercent != nil {
+			form.DiscountPercent = fmt.Sprintf("%.4f", *defaults.DiscountPercent)
+		}
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.Client = &client
+	data.ProjectDefaults = defaults
+	data.ProjectStatuses = models.NewProjectStatuses
+	app.render(res, req, http.StatusOK, "project_defaults.html", data)
+}
+
+// clientProjectDefaultsEditPost handles a POST request with a client's project defaults, which
+// are validated and saved
+func (app *application) clientProjectDefaultsEditPost(res http.ResponseWriter, req *http.Request) {
+	clientID, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || clientID < 0 {
+		http.NotFound(res, req)
 		return
 	}
 
-	// Get the client for context
-	client, err := app.clients.Get(project.ClientID)
+	client, ok := app.loadClient(res, req, clientID)
+	if !ok {
+		return
+	}
+
+	var form projectDefaultsForm
+	err = app.decodePostForm(req, &form)
 	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(res, req)
-		} else {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	form.CurrencyDisplay = strings.ToUpper(strings.TrimSpace(form.CurrencyDisplay))
+	form.CheckField(slices.Contains(models.NewProjectStatuses, form.Status), "status", "Status must be one a new project can start in")
+	form.CheckField(validator.NotBlank(form.CurrencyDisplay), "currency_display", "Currency is required")
+	form.CheckField(validator.MaxChars(form.CurrencyDisplay, 3), "currency_display", "Currency must be a three letter code, e.g. EUR")
+	form.CheckField(validator.NotBlank(form.CurrencyConversionRate), "currency_conversion_rate", "Conversion rate is required")
+	rate := form.Money(form.CurrencyConversionRate, "currency_conversion_rate", "Conversion rate")
+	form.CheckField(rate > 0, "currency_conversion_rate", "Conversion rate must be more than zero")
+	discount := form.OptionalMoney(form.DiscountPercent, "discount_percent", "Discount percent")
+	form.CheckField(discount == nil || *discount <= 1, "discount_percent", "Discount percent is a fraction, e.g. 0.1 for 10%")
+	form.CheckField(validator.MaxChars(form.DiscountReason, NAME_LENGTH), "discount_reason", fmt.Sprintf("Discount reason must be shorter than %d characters", NAME_LENGTH))
+
+	if !form.Valid() {
+		defaults, err := app.clientProjectDefaults(client.ID)
+		if err != nil {
 			app.serverError(res, req, err)
+			return
 		}
+
+		data := app.newTemplateData(req)
+		data.Form = form
+		data.Client = &client
+		data.ProjectDefaults = defaults
+		data.ProjectStatuses = models.NewProjectStatuses
+		app.render(res, req, http.StatusUnprocessableEntity, "project_defaults.html", data)
 		return
 	}
 
-	data := app.newTemplateData(req)
-	data.Form = invoiceForm{
-		InvoiceDate: time.Now().Format("2006-01-02"),
+	err = app.projectDefaults.Save(models.ProjectDefaults{
+		ClientID:               client.ID,
+		Status:                 form.Status,
+		CurrencyDisplay:        form.CurrencyDisplay,
+		CurrencyConversionRate: rate,
+		DiscountPercent:        discount,
+		DiscountReason:         strings.TrimSpace(form.DiscountReason),
+		FlatFeeInvoice:         form.FlatFeeInvoice,
+	})
+	if err != nil {
+		app.serverError(res, req, err)
+		return
 	}
-	data.Project = &project
-	data.Client = &client
-	app.render(res, req, http.StatusOK, "invoice_create.html", data)
+	app.flash(req, fmt.Sprintf("Project defaults saved for %s", client.Name))
+	app.redirect(res, req, fmt.Sprintf("/client/view/%d", client.ID), http.StatusSeeOther)
 }
 
-// invoiceCreatePost handles a POST request with invoice form data which is then
-// validated and used to insert a new invoice into the database
-func (app *application) invoiceCreatePost(res http.ResponseWriter, req *http.Request) {
-	projectID, err := strconv.Atoi(req.PathValue("id"))
-	if err != nil || projectID < 0 {
+// clientProjectDefaultsDelete clears a client's project defaults, so its new projects start
+// from the usual ones again
+func (app *application) clientProjectDefaultsDelete(res http.ResponseWriter, req *http.Request) {
+	clientID, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || clientID < 0 {
 		http.NotFound(res, req)
 		return
 	}
 
-	// Check if project exists
+	client, ok := app.loadClient(res, req, clientID)
+	if !ok {
+		return
+	}
+
+	err = app.projectDefaults.Delete(client.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	app.flash(req, fmt.Sprintf("Project defaults cleared for %s", client.Name))
+	app.redirect(res, req, fmt.Sprintf("/client/view/%d", client.ID), http.StatusSeeOther)
+}
+
+// openProject loads a project whose timesheets, invoices or other records are being changed,
+// answering 404 when it doesn't exist and 409 when it is archived and so can't be changed
+func (app *application) openProject(res http.ResponseWriter, req *http.Request, projectID int) (models.Project, bool) {
 	project, err := app.projects.Get(projectID)
 	if err != nil {
 		if errors.Is(err, models.ErrNoRecord) {
@@ -1295,86 +2781,6690 @@ func (app *application) invoiceCreatePost(res http.ResponseWriter, req *http.Req
 		} else {
 			app.serverError(res, req, err)
 		}
-		return
+		return models.Project{}, false
 	}
 
-	// Get the client for context
-	client, err := app.clients.Get(project.ClientID)
+	// Archived projects are kept unchanged until they are reopened
+	if project.IsArchived() {
+		app.clientError(res, http.StatusConflict)
+		return models.Project{}, false
+	}
+	return project, true
+}
+
+// getMilestone loads the milestone named in the request path, answering 404 when it doesn't exist
+func (app *application) getMilestone(res http.ResponseWriter, req *http.Request) (models.Milestone, bool) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return models.Milestone{}, false
+	}
+
+	milestone, err := app.milestones.Get(id)
 	if err != nil {
 		if errors.Is(err, models.ErrNoRecord) {
 			http.NotFound(res, req)
 		} else {
 			app.serverError(res, req, err)
 		}
+		return models.Milestone{}, false
+	}
+	return milestone, true
+}
+
+// validateMilestoneForm checks the milestone form fields and returns the parsed due date and fee
+func validateMilestoneForm(form *milestoneForm, dateFormat models.DateFormat) (*time.Time, float64) {
+	form.CheckField(validator.NotBlank(form.Name), "name", "Name is required")
+	form.CheckField(validator.MaxChars(form.Name, NAME_LENGTH), "name", fmt.Sprintf("Name must be shorter than %d characters", NAME_LENGTH))
+	form.CheckField(models.ValidMilestoneStatus(form.Status), "status", "Status must be Planned, In Progress or Complete")
+
+	dueDate := form.OptionalDate(form.DueDate, dateFormat, "due_date", "Due date")
+	fee := form.Money(form.Fee, "fee", "Fee")
+	return dueDate, fee
+}
+
+// milestoneCreate handles a GET request which returns an empty milestone form for a project
+func (app *application) milestoneCreate(res http.ResponseWriter, req *http.Request) {
+	projectID, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || projectID < 0 {
+		http.NotFound(res, req)
 		return
 	}
 
-	var form invoiceForm
+	project, ok := app.openProject(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = milestoneForm{
+		Status: models.MilestoneStatusPlanned,
+	}
+	data.Project = &project
+	app.render(res, req, http.StatusOK, "milestone_create.html", data)
+}
+
+// milestoneCreatePost handles a POST request with milestone form data which is then
+// validated and used to add a milestone to a project
+func (app *application) milestoneCreatePost(res http.ResponseWriter, req *http.Request) {
+	projectID, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || projectID < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	project, ok := app.openProject(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	var form milestoneForm
 	err = app.decodePostForm(req, &form)
 	if err != nil {
 		app.clientError(res, http.StatusBadRequest)
 		return
 	}
 
-	form.CheckField(validator.NotBlank(form.InvoiceDate), "invoice_date", "Invoice date is required")
-	form.CheckField(validator.NotBlank(form.AmountDue), "amount_due", "Amount due is required")
-	form.CheckField(validator.MaxChars(form.PaymentTerms, NAME_LENGTH), "payment_terms", fmt.Sprintf("Payment terms must be shorter than %d characters", NAME_LENGTH))
+	dueDate, fee := validateMilestoneForm(&form, app.dateFormat(req))
 
-	// Parse and validate invoice date
-	var invoiceDate time.Time
-	if form.Valid() {
-		invoiceDate, err = time.Parse("2006-01-02", form.InvoiceDate)
-		if err != nil {
-			form.AddFieldError("invoice_date", "Invoice date must be in YYYY-MM-DD format")
-		}
+	if !form.Valid() {
+		data := app.newTemplateData(req)
+		data.Form = form
+		data.Project = &project
+		app.render(res, req, http.StatusUnprocessableEntity, "milestone_create.html", data)
+		return
+	}
+
+	_, err = app.milestones.Insert(models.Milestone{
+		ProjectID: projectID,
+		Name:      form.Name,
+		DueDate:   dueDate,
+		Fee:       fee,
+		Status:    form.Status,
+	})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	app.flash(req, fmt.Sprintf("Milestone %s created", form.Name))
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", projectID), http.StatusSeeOther)
+}
+
+// milestoneUpdate handles a GET request which returns a milestone form pre-populated with milestone data
+func (app *application) milestoneUpdate(res http.ResponseWriter, req *http.Request) {
+	milestone, ok := app.getMilestone(res, req)
+	if !ok {
+		return
+	}
+
+	project, ok := app.openProject(res, req, milestone.ProjectID)
+	if !ok {
+		return
+	}
+
+	// Invoiced milestones are kept as they were billed
+	if milestone.IsInvoiced() {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	form := milestoneForm{
+		Name:   milestone.Name,
+		Fee:    strconv.FormatFloat(milestone.Fee, 'f', 2, 64),
+		Status: milestone.Status,
+	}
+	if milestone.DueDate != nil {
+		form.DueDate = data.DateFormat.Format(*milestone.DueDate)
+	}
+	data.Form = form
+	data.Project = &project
+	data.Milestone = &milestone
+	app.render(res, req, http.StatusOK, "milestone_create.html", data)
+}
+
+// milestoneUpdatePost handles a POST request with milestone form data which is then
+// validated and used to update an existing milestone
+func (app *application) milestoneUpdatePost(res http.ResponseWriter, req *http.Request) {
+	milestone, ok := app.getMilestone(res, req)
+	if !ok {
+		return
+	}
+
+	project, ok := app.openProject(res, req, milestone.ProjectID)
+	if !ok {
+		return
+	}
+
+	// Invoiced milestones are kept as they were billed
+	if milestone.IsInvoiced() {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
+	var form milestoneForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	dueDate, fee := validateMilestoneForm(&form, app.dateFormat(req))
+
+	if !form.Valid() {
+		data := app.newTemplateData(req)
+		data.Form = form
+		data.Project = &project
+		data.Milestone = &milestone
+		app.render(res, req, http.StatusUnprocessableEntity, "milestone_create.html", data)
+		return
+	}
+
+	err = app.milestones.Update(models.Milestone{
+		ID:      milestone.ID,
+		Name:    form.Name,
+		DueDate: dueDate,
+		Fee:     fee,
+		Status:  form.Status,
+	})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	app.flash(req, fmt.Sprintf("Milestone %s updated", form.Name))
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", milestone.ProjectID), http.StatusSeeOther)
+}
+
+// milestoneDelete handles a POST request to soft delete a milestone which hasn't been invoiced
+func (app *application) milestoneDelete(res http.ResponseWriter, req *http.Request) {
+	milestone, ok := app.getMilestone(res, req)
+	if !ok {
+		return
+	}
+
+	if _, ok := app.openProject(res, req, milestone.ProjectID); !ok {
+		return
+	}
+
+	// Invoiced milestones are kept as they were billed
+	if milestone.IsInvoiced() {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
+	err := app.milestones.Delete(milestone.ID)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("Milestone %s deleted", milestone.Name))
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", milestone.ProjectID), http.StatusSeeOther)
+}
+
+// milestoneInvoicePost handles a POST request to bill a milestone's fee on a new invoice dated
+// today. Only flat-fee projects are invoiced by milestone; hourly work is invoiced as usual.
+func (app *application) milestoneInvoicePost(res http.ResponseWriter, req *http.Request) {
+	milestone, ok := app.getMilestone(res, req)
+	if !ok {
+		return
+	}
+
+	project, ok := app.openProject(res, req, milestone.ProjectID)
+	if !ok {
+		return
+	}
+
+	if !project.FlatFeeInvoice || !milestone.CanInvoice() {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
+	client, err := app.clients.Get(project.ClientID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	sequence, err := app.invoiceSequence(project, client)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	// Bill on the same terms as the project's latest invoice
+	paymentTerms := fmt.Sprintf("Net %d", models.DefaultPaymentDays)
+	invoices, err := app.invoices.GetByProject(project.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	for _, invoice := range invoices {
+		if !invoice.IsVoided() && invoice.PaymentTerms != "" {
+			paymentTerms = invoice.PaymentTerms
+			break
+		}
+	}
+
+	invoiceDate := time.Now()
+	year, month, day := invoiceDate.Date()
+	invoiceDate = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	dueDate := models.DueDateFor(invoiceDate, paymentTerms)
+
+	financials := models.FinancialsFromProject(project)
+	err = app.applyExchangeRate(req.Context(), &financials, invoiceDate)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	var invoiceID int
+	var invoiceNumber string
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		id, err := tx.Invoices.Insert(project.ID, invoiceDate, nil, paymentTerms, milestone.Fee, false)
+		if err != nil {
+			return err
+		}
+		invoiceID = id
+
+		err = recordInvoiceEvents(tx, id, nil, nil, models.InvoiceEventCreated)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Invoices.SetDueDate(id, &dueDate)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Invoices.SetFinancials(id, financials)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Milestones.SetInvoice(milestone.ID, id)
+		if err != nil {
+			return err
+		}
+
+		if sequence == nil {
+			return nil
+		}
+
+		invoiceNumber, err = sequence.Claim(tx)
+		if err != nil || invoiceNumber == "" {
+			return err
+		}
+		return tx.Invoices.SetInvoiceNumber(id, invoiceNumber)
+	})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("Invoice #%s created for milestone %s", models.Invoice{ID: invoiceID, InvoiceNumber: invoiceNumber}.DisplayNumber(), milestone.Name))
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", project.ID), http.StatusSeeOther)
+}
+
+// maxProjectAttachmentBytes bounds the size of a file uploaded to a project, and
+// maxEmailAttachmentBytes the size of everything attached to an invoice email, the invoice PDF
+// included. Most mail servers refuse messages over 25 MB, which base64 encoding brings 18 MB
+// of attachments close to.
+const (
+	maxProjectAttachmentBytes = 10 << 20
+	maxEmailAttachmentBytes   = 18 << 20
+)
+
+// projectAttachmentTypes are the kinds of file that can be attached to a project, by content
+// type, with the extension they are stored under
+var projectAttachmentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+}
+
+// projectAttachmentCreatePost handles an upload of a supporting document, such as a receipt,
+// to a project so that it can be sent along with the project's invoices
+func (app *application) projectAttachmentCreatePost(res http.ResponseWriter, req *http.Request) {
+	projectID, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || projectID < 0 {
+		http.NotFound(res, req)
+		return
+	}
+	project, ok := app.openProject(res, req, projectID)
+	if !ok {
+		return
+	}
+	back := fmt.Sprintf("/project/view/%d", project.ID)
+
+	req.Body = http.MaxBytesReader(res, req.Body, maxProjectAttachmentBytes+64*1024)
+	err = req.ParseMultipartForm(maxProjectAttachmentBytes)
+	if err != nil {
+		app.flash(req, fmt.Sprintf("Attachments must be smaller than %s", models.FormatFileSize(maxProjectAttachmentBytes)))
+		app.redirect(res, req, back, http.StatusSeeOther)
+		return
+	}
+	file, header, err := req.FormFile("attachment")
+	if err != nil {
+		app.flash(req, "Choose a file to attach")
+		app.redirect(res, req, back, http.StatusSeeOther)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxProjectAttachmentBytes+1))
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	if len(data) > maxProjectAttachmentBytes {
+		app.flash(req, fmt.Sprintf("Attachments must be smaller than %s", models.FormatFileSize(maxProjectAttachmentBytes)))
+		app.redirect(res, req, back, http.StatusSeeOther)
+		return
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := projectAttachmentTypes[contentType]
+	if !ok {
+		app.flash(req, "Attachments must be PDF, PNG or JPEG files")
+		app.redirect(res, req, back, http.StatusSeeOther)
+		return
+	}
+
+	filename := strings.TrimSpace(filepath.Base(header.Filename))
+	if filename == "" || filename == "." || len(filename) > NAME_LENGTH {
+		filename = "attachment" + ext
+	}
+
+	low, err := app.checkDiskSpace(int64(len(data)))
+	if err != nil {
+		app.flash(req, fmt.Sprintf("%s wasn't attached: %s", filename, diskSpaceMessage(low)))
+		app.redirect(res, req, back, http.StatusSeeOther)
+		return
+	}
+
+	path, err := app.uploads.Save(fmt.Sprintf("attachment-%d-%d%s", project.ID, time.Now().UnixNano(), ext), data)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	_, err = app.projectAttachments.Insert(models.ProjectAttachment{
+		ProjectID:   project.ID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Path:        path,
+	})
+	if err != nil {
+		app.uploads.Remove(path)
+		app.modelError(res, req, err)
+		return
+	}
+
+	message := fmt.Sprintf("%s attached", filename)
+	if low != nil {
+		message += ", but " + low.String()
+	}
+	app.flash(req, message)
+	app.redirect(res, req, back, http.StatusSeeOther)
+}
+
+// getProjectAttachment loads the project attachment named in the request path, answering 404
+// when it doesn't exist
+func (app *application) getProjectAttachment(res http.ResponseWriter, req *http.Request) (models.ProjectAttachment, bool) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return models.ProjectAttachment{}, false
+	}
+
+	attachment, err := app.projectAttachments.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return models.ProjectAttachment{}, false
+	}
+	return attachment, true
+}
+
+// projectAttachmentDownload serves a project attachment as a download
+func (app *application) projectAttachmentDownload(res http.ResponseWriter, req *http.Request) {
+	attachment, ok := app.getProjectAttachment(res, req)
+	if !ok {
+		return
+	}
+
+	data, err := os.ReadFile(attachment.Path)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	res.Header().Set("Content-Type", attachment.ContentType)
+	res.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	res.Header().Set("X-Content-Type-Options", "nosniff")
+	res.Write(data)
+}
+
+// projectAttachmentDelete handles a POST request to remove an attachment from a project,
+// deleting its file
+func (app *application) projectAttachmentDelete(res http.ResponseWriter, req *http.Request) {
+	attachment, ok := app.getProjectAttachment(res, req)
+	if !ok {
+		return
+	}
+	if _, ok := app.openProject(res, req, attachment.ProjectID); !ok {
+		return
+	}
+
+	err := app.projectAttachments.Delete(attachment.ID)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	// The record is gone, so a file left behind is only wasted space
+	if err := app.uploads.Remove(attachment.Path); err != nil {
+		app.logger.Error("removing attachment file", "path", attachment.Path, "error", err.Error())
+	}
+
+	app.flash(req, fmt.Sprintf("%s deleted", attachment.Filename))
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", attachment.ProjectID), http.StatusSeeOther)
+}
+
+// clientApprovalCreatePost handles a POST request which creates a link the client can approve
+// the project's timesheets between the start and end dates with
+func (app *application) clientApprovalCreatePost(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+	project, ok := app.openProject(res, req, id)
+	if !ok {
+		return
+	}
+
+	err = req.ParseForm()
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+	start, startErr := time.Parse("2006-01-02", req.PostForm.Get("start"))
+	end, endErr := time.Parse("2006-01-02", req.PostForm.Get("end"))
+	if startErr != nil || endErr != nil || end.Before(start) {
+		app.flash(req, "Choose the first and last days of the time to approve")
+		app.redirect(res, req, fmt.Sprintf("/project/view/%d", project.ID), http.StatusSeeOther)
+		return
+	}
+
+	_, err = app.approvals.Insert(project.ID, start, end)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	app.flash(req, "Approval link created, send it to the client")
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", project.ID), http.StatusSeeOther)
+}
+
+// clientApprovalDelete handles a POST request which withdraws an approval link the client
+// hasn't used yet. Approved links are kept as the record of the approval.
+func (app *application) clientApprovalDelete(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	approval, err := app.approvals.Get(id)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	if _, ok := app.openProject(res, req, approval.ProjectID); !ok {
+		return
+	}
+	if approval.Approved() {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
+	err = app.approvals.Delete(approval.ID)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	app.flash(req, "Approval link withdrawn")
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", approval.ProjectID), http.StatusSeeOther)
+}
+
+// clientApprovalView handles a GET request from a client, who needn't log in, for the page
+// listing the timesheets an approval link covers
+func (app *application) clientApprovalView(res http.ResponseWriter, req *http.Request) {
+	approval, err := app.approvals.GetByToken(req.PathValue("token"))
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	app.renderClientApproval(res, req, http.StatusOK, approval, clientApprovalForm{})
+}
+
+// clientApprovalPost handles a POST request from a client approving the timesheets an
+// approval link covers, recording their name, the time and their IP address
+func (app *application) clientApprovalPost(res http.ResponseWriter, req *http.Request) {
+	token := req.PathValue("token")
+	approval, err := app.approvals.GetByToken(token)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	var form clientApprovalForm
+	err = app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+	form.CheckField(validator.NotBlank(form.Name), "name", "Enter your name to approve the time")
+	form.CheckField(validator.MaxChars(form.Name, 100), "name", "Name must be shorter than 100 characters")
+	if !form.Valid() {
+		app.renderClientApproval(res, req, http.StatusUnprocessableEntity, approval, form)
+		return
+	}
+
+	err = app.approvals.Approve(approval.ID, strings.TrimSpace(form.Name), app.clientIP(req), time.Now())
+	switch {
+	case errors.Is(err, models.ErrAlreadyApproved):
+		app.flash(req, "This time has already been approved")
+	case err != nil:
+		app.modelError(res, req, err)
+		return
+	default:
+		app.flash(req, "Thank you, the time is approved")
+	}
+	app.redirect(res, req, "/approve/"+token, http.StatusSeeOther)
+}
+
+// renderClientApproval renders the page a client approves timesheets on, listing the
+// project's timesheets for the dates the link covers. Entries a subcontractor logged that
+// are still waiting for the owner's approval are left out.
+func (app *application) renderClientApproval(res http.ResponseWriter, req *http.Request, status int, approval models.ClientApproval, form clientApprovalForm) {
+	project, err := app.projects.Get(approval.ProjectID)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	client, err := app.clients.Get(project.ClientID)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	timesheets, err := app.timesheets.GetByProject(project.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	for _, timesheet := range timesheets {
+		if timesheet.PendingApproval || timesheet.IsTemplate() || !approval.Includes(timesheet.WorkDate) {
+			continue
+		}
+		data.Timesheets = append(data.Timesheets, timesheet)
+		if timesheet.IsHourly() {
+			data.ApprovalHours += timesheet.HoursWorked
+		}
+	}
+	data.Public = true
+	data.Project = &project
+	data.Client = &client
+	data.ClientApproval = &approval
+	data.Form = form
+	app.render(res, req, status, "client_approval.html", data)
+}
+
+// consolidatedInvoicePost handles a POST request which invoices all of a client's unbilled
+// time in a month on one invoice
+func (app *application) consolidatedInvoicePost(res http.ResponseWriter, req *http.Request) {
+	clientID, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || clientID < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	client, err := app.clients.Get(clientID)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	err = req.ParseForm()
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+	month, err := time.Parse("2006-01", req.PostForm.Get("month"))
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	invoiceID, invoiceNumber, err := app.createConsolidatedInvoice(req.Context(), client, month)
+	if errors.Is(err, models.ErrNothingToInvoice) {
+		app.flash(req, fmt.Sprintf("%s has no unbilled time in %s", client.Name, month.Format("January 2006")))
+		app.redirect(res, req, fmt.Sprintf("/client/view/%d", client.ID), http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("Invoice #%s created for %s's time in %s", models.Invoice{ID: invoiceID, InvoiceNumber: invoiceNumber}.DisplayNumber(),
+		client.Name, month.Format("January 2006")))
+	app.redirect(res, req, fmt.Sprintf("/invoice/update/%d", invoiceID), http.StatusSeeOther)
+}
+
+// createConsolidatedInvoice invoices the time logged on all of a client's projects in a month
+// that no invoice covers yet, returning ErrNothingToInvoice if there isn't any. The invoice
+// belongs to the first of the projects and lists each project's subtotal.
+func (app *application) createConsolidatedInvoice(ctx context.Context, client models.Client, month time.Time) (int, string, error) {
+	start, end := models.MonthRange(month)
+	lastDay := end.AddDate(0, 0, -1)
+
+	invoiceDate := time.Now()
+	year, m, day := invoiceDate.Date()
+	invoiceDate = time.Date(year, m, day, 0, 0, 0, 0, time.UTC)
+	paymentTerms := fmt.Sprintf("Net %d", models.DefaultPaymentDays)
+	dueDate := models.DueDateFor(invoiceDate, paymentTerms)
+
+	var invoiceID int
+	var invoiceNumber string
+	err := app.transactions.WithinTx(ctx, func(tx models.TxModels) error {
+		subtotals, err := tx.ConsolidatedInvoices.Unbilled(client.ID, month)
+		if err != nil {
+			return err
+		}
+		if len(subtotals) == 0 {
+			return models.ErrNothingToInvoice
+		}
+		_, amount := models.TotalSubtotals(subtotals)
+
+		project, err := tx.Projects.Get(subtotals[0].ProjectID)
+		if err != nil {
+			return err
+		}
+
+		id, err := tx.Invoices.Insert(project.ID, invoiceDate, nil, paymentTerms, amount, false)
+		if err != nil {
+			return err
+		}
+		invoiceID = id
+
+		err = recordInvoiceEvents(tx, id, nil, nil, models.InvoiceEventCreated)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Invoices.SetDueDate(id, &dueDate)
+		if err != nil {
+			return err
+		}
+
+		// A discount or adjustment agreed for one project doesn't apply to the others
+		financials := models.FinancialsFromProject(project)
+		financials.DiscountPercent, financials.DiscountReason = nil, ""
+		financials.AdjustmentAmount, financials.AdjustmentReason = nil, ""
+		err = tx.Invoices.SetFinancials(id, financials)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Invoices.SetTimesheetRange(id, &start, &lastDay)
+		if err != nil {
+			return err
+		}
+
+		err = tx.ConsolidatedInvoices.SetProjects(id, subtotals)
+		if err != nil {
+			return err
+		}
+
+		var profile *models.BusinessProfile
+		if profileID := models.ResolveBusinessProfileID(project, client); profileID != nil {
+			found, err := tx.BusinessProfiles.Get(*profileID)
+			if err != nil && !errors.Is(err, models.ErrNoRecord) {
+				return err
+			}
+			if err == nil {
+				profile = &found
+			}
+		}
+		sequence := models.ResolveInvoiceSequence(client, profile)
+		if sequence == nil {
+			return nil
+		}
+
+		invoiceNumber, err = sequence.Claim(tx)
+		if err != nil || invoiceNumber == "" {
+			return err
+		}
+		return tx.Invoices.SetInvoiceNumber(id, invoiceNumber)
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	return invoiceID, invoiceNumber, nil
+}
+
+// timesheetCreate handles a GET request which returns an empty timesheet creation form
+func (app *application) timesheetCreate(res http.ResponseWriter, req *http.Request) {
+	projectID, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || projectID < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	shared, err := app.canAccessProject(req, projectID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	if !shared {
+		http.NotFound(res, req)
+		return
+	}
+
+	project, ok := app.openProject(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	// Get the client for context
+	client, err := app.clients.Get(project.ClientID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	services, err := app.timesheetServices(nil)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = timesheetForm{
+		WorkDate:       data.DateFormat.Format(time.Now()),
+		HourlyRate:     fmt.Sprintf("%.2f", project.HourlyRate), // Default from project
+		MaxDescription: app.descriptionLength(),
+	}
+	data.Project = &project
+	data.Client = &client
+	data.Services = services
+	data.WorkTypes = app.timesheetWorkTypes("")
+	app.render(res, req, http.StatusOK, "timesheet_create.html", data)
+}
+
+// timesheetCreatePost handles a POST request with timesheet form data which is then
+// validated and used to insert a new timesheet into the database
+func (app *application) timesheetCreatePost(res http.ResponseWriter, req *http.Request) {
+	projectID, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || projectID < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	shared, err := app.canAccessProject(req, projectID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	if !shared {
+		http.NotFound(res, req)
+		return
+	}
+
+	project, ok := app.openProject(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	// Get the client for context
+	client, err := app.clients.Get(project.ClientID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	var form timesheetForm
+	err = app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	service, err := app.timesheetService(&form, nil)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	// Subcontractors don't see billing or cost rates, so their time is billed at the service's
+	// default rate, or the project rate for ordinary hourly entries
+	if app.isSubcontractor(req) {
+		form.HourlyRate = fmt.Sprintf("%.2f", project.HourlyRate)
+		if service != nil {
+			form.HourlyRate = models.FormatRate(service.DefaultRate)
+		}
+		form.CostRate = ""
+	}
+
+	form.CheckField(validator.NotBlank(form.WorkDate), "work_date", "Work date is required")
+	form.CheckField(validator.NotBlank(form.HoursWorked), "hours_worked", "Hours worked is required")
+	form.CheckField(validator.NotBlank(form.HourlyRate), "hourly_rate", "Hourly rate is required")
+	form.CheckField(validator.NotBlank(form.Description), "description", "Description is required")
+	form.MaxDescription = app.descriptionLength()
+	form.CheckField(validator.MaxChars(form.Description, form.MaxDescription), "description", fmt.Sprintf("Description must be shorter than %d characters", form.MaxDescription))
+	workTypes := app.timesheetWorkTypes("")
+	form.CheckField(form.WorkType == "" || slices.Contains(workTypes, form.WorkType), "work_type", "Work type must be one of the work types in settings")
+
+	dateFormat := app.dateFormat(req)
+	workDate := form.Date(form.WorkDate, dateFormat, "work_date", "Work date")
+	hoursWorked := form.Money(form.HoursWorked, "hours_worked", "Hours worked")
+	hourlyRate := form.Money(form.HourlyRate, "hourly_rate", "Hourly rate")
+	costRate := form.OptionalMoney(form.CostRate, "cost_rate", "Cost rate")
+
+	// A repeated entry is logged once for every day from the work date to the repeat until date
+	workDates := []time.Time{workDate}
+	if form.Valid() && form.RepeatUntil != "" {
+		repeatUntil := form.OptionalDate(form.RepeatUntil, dateFormat, "repeat_until", "Repeat until")
+		switch {
+		case repeatUntil == nil:
+		case !validator.NotBefore(*repeatUntil, workDate):
+			form.AddFieldError("repeat_until", "Repeat until can't be before the work date")
+		case !validator.DateBetween(*repeatUntil, workDate, workDate.AddDate(0, 0, MAX_REPEAT_DAYS)):
+			form.AddFieldError("repeat_until", fmt.Sprintf("An entry can be repeated for at most %d days", MAX_REPEAT_DAYS))
+		default:
+			workDates = models.DatesBetween(workDate, *repeatUntil, form.SkipWeekends)
+			form.CheckField(len(workDates) > 0, "repeat_until", "There are no weekdays between the work date and repeat until date")
+		}
+	}
+
+	// Words and pages don't count towards the monthly hour allowance. Repeated entries are
+	// checked against the allowance of each month they fall in.
+	serviceID, unit := serviceUnit(service)
+	if form.Valid() && unit == models.UnitHour {
+		var months []time.Time
+		monthHours := map[string]float64{}
+		for _, date := range workDates {
+			month := date.Format("2006-01")
+			if _, ok := monthHours[month]; !ok {
+				months = append(months, date)
+			}
+			monthHours[month] += hoursWorked
+		}
+		for _, month := range months {
+			err = app.checkHourAllowance(&form.Validator, client, month, monthHours[month.Format("2006-01")], 0)
+			if err != nil {
+				app.serverError(res, req, err)
+				return
+			}
+		}
+	}
+
+	// Going over the allowance doesn't block logging time once the user has acknowledged it
+	if !form.Valid() || (form.HasWarnings() && !form.AcknowledgeWarnings) {
+		services, err := app.timesheetServices(nil)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+
+		data := app.newTemplateData(req)
+		data.Form = form
+		data.Project = &project
+		data.Client = &client
+		data.Services = services
+		data.WorkTypes = workTypes
+		app.render(res, req, http.StatusUnprocessableEntity, "timesheet_create.html", data)
+		return
+	}
+
+	// Record who logged the time along with the entry itself. Repeated entries are all added
+	// or, if any of them fails, none are.
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		for _, date := range workDates {
+			id, err := tx.Timesheets.Insert(projectID, date, hoursWorked, hourlyRate, form.Description)
+			if err != nil {
+				return err
+			}
+
+			if costRate != nil {
+				err = tx.Timesheets.SetCostRate(id, costRate)
+				if err != nil {
+					return err
+				}
+			}
+
+			if serviceID != nil {
+				err = tx.Timesheets.SetService(id, serviceID, unit)
+				if err != nil {
+					return err
+				}
+			}
+
+			if form.WorkType != "" {
+				err = tx.Timesheets.SetWorkType(id, form.WorkType)
+				if err != nil {
+					return err
+				}
+			}
+
+			if user := app.currentUser(req); user != nil {
+				err = tx.Timesheets.SetUser(id, user.ID)
+				if err != nil {
+					return err
+				}
+			}
+
+			// Subcontractors' time is only invoiced once an owner has approved it
+			if app.isSubcontractor(req) {
+				err = tx.Timesheets.SetPendingApproval(id, true)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	var message string
+	if len(workDates) > 1 {
+		message = fmt.Sprintf("%d timesheet entries from %s to %s added", len(workDates),
+			dateFormat.Format(workDates[0]), dateFormat.Format(workDates[len(workDates)-1]))
+	} else {
+		message = fmt.Sprintf("Timesheet entry for %s added", dateFormat.Format(workDate))
+	}
+	if app.isSubcontractor(req) {
+		message += ", waiting for approval"
+	}
+	app.flash(req, message)
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", projectID), http.StatusSeeOther)
+}
+
+// timesheetUpdate handles a GET request which returns a timesheet update form pre-populated with timesheet data
+func (app *application) timesheetUpdate(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	timesheet, err := app.timesheets.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	editable, err := app.canEditTimesheet(req, timesheet)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	if !editable {
+		http.NotFound(res, req)
+		return
+	}
+
+	// Get the project for context
+	project, ok := app.openProject(res, req, timesheet.ProjectID)
+	if !ok {
+		return
+	}
+
+	// Get the client for context
+	client, err := app.clients.Get(project.ClientID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	var costRateStr string
+	if timesheet.CostRate != nil {
+		costRateStr = fmt.Sprintf("%.2f", *timesheet.CostRate)
+	}
+
+	var serviceIDStr string
+	if timesheet.ServiceID != nil {
+		serviceIDStr = strconv.Itoa(*timesheet.ServiceID)
+	}
+
+	// Entries billed on an invoice are locked; only an owner can override the lock
+	locking, err := app.timesheetLockingInvoices(timesheet.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	if len(locking) > 0 && app.isSubcontractor(req) {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
+	services, err := app.timesheetServices(&timesheet)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = timesheetForm{
+		WorkDate:       data.DateFormat.Format(timesheet.WorkDate),
+		HoursWorked:    fmt.Sprintf("%.2f", timesheet.HoursWorked),
+		HourlyRate:     timesheet.Rate(),
+		CostRate:       costRateStr,
+		ServiceID:      serviceIDStr,
+		WorkType:       timesheet.WorkType,
+		Description:    timesheet.Description,
+		IsUpdate:       true,
+		MaxDescription: app.descriptionLength(),
+	}
+	data.Project = &project
+	data.Client = &client
+	data.Services = services
+	data.WorkTypes = app.timesheetWorkTypes(timesheet.WorkType)
+	data.Timesheet = &timesheet
+	data.LockingInvoices = locking
+	app.render(res, req, http.StatusOK, "timesheet_create.html", data)
+}
+
+// timesheetUpdatePost handles a POST request with timesheet form data which is then
+// validated and used to update an existing timesheet in the database
+func (app *application) timesheetUpdatePost(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	// Get the timesheet to ensure it exists and get the project ID
+	timesheet, err := app.timesheets.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	editable, err := app.canEditTimesheet(req, timesheet)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	if !editable {
+		http.NotFound(res, req)
+		return
+	}
+
+	// Get project and client for context
+	project, ok := app.openProject(res, req, timesheet.ProjectID)
+	if !ok {
+		return
+	}
+
+	client, err := app.clients.Get(project.ClientID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	// Entries billed on an invoice are locked; only an owner can override the lock
+	locking, err := app.timesheetLockingInvoices(timesheet.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	if len(locking) > 0 && app.isSubcontractor(req) {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
+	var form timesheetForm
+	err = app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	service, err := app.timesheetService(&form, &timesheet)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	// Subcontractors don't see billing or cost rates, so the entry keeps the rates it was logged
+	// at unless it is moved to a different service, which is billed at that service's rate
+	if app.isSubcontractor(req) {
+		form.HourlyRate = timesheet.Rate()
+		if serviceID, _ := serviceUnit(service); !sameService(serviceID, timesheet.ServiceID) {
+			form.HourlyRate = fmt.Sprintf("%.2f", project.HourlyRate)
+			if service != nil {
+				form.HourlyRate = models.FormatRate(service.DefaultRate)
+			}
+		}
+		form.CostRate = ""
+		if timesheet.CostRate != nil {
+			form.CostRate = fmt.Sprintf("%.2f", *timesheet.CostRate)
+		}
+	}
+
+	form.CheckField(validator.NotBlank(form.WorkDate), "work_date", "Work date is required")
+	form.CheckField(validator.NotBlank(form.HoursWorked), "hours_worked", "Hours worked is required")
+	form.CheckField(validator.NotBlank(form.HourlyRate), "hourly_rate", "Hourly rate is required")
+	form.CheckField(validator.NotBlank(form.Description), "description", "Description is required")
+	form.MaxDescription = app.descriptionLength()
+	form.CheckField(validator.MaxChars(form.Description, form.MaxDescription), "description", fmt.Sprintf("Description must be shorter than %d characters", form.MaxDescription))
+	workTypes := app.timesheetWorkTypes(timesheet.WorkType)
+	form.CheckField(form.WorkType == "" || slices.Contains(workTypes, form.WorkType), "work_type", "Work type must be one of the work types in settings")
+	form.CheckField(len(locking) == 0 || form.OverrideLock, "override_lock", "This entry has been invoiced. Tick the box to change it anyway.")
+
+	dateFormat := app.dateFormat(req)
+	workDate := form.Date(form.WorkDate, dateFormat, "work_date", "Work date")
+	hoursWorked := form.Money(form.HoursWorked, "hours_worked", "Hours worked")
+	hourlyRate := form.Money(form.HourlyRate, "hourly_rate", "Hourly rate")
+	costRate := form.OptionalMoney(form.CostRate, "cost_rate", "Cost rate")
+
+	// Words and pages don't count towards the monthly hour allowance
+	serviceID, unit := serviceUnit(service)
+	if form.Valid() && unit == models.UnitHour {
+		// The entry's current hours are already counted if it stays in the same month
+		var previousHours float64
+		if timesheet.IsHourly() && timesheet.WorkDate.Year() == workDate.Year() && timesheet.WorkDate.Month() == workDate.Month() {
+			previousHours = timesheet.HoursWorked
+		}
+		err = app.checkHourAllowance(&form.Validator, client, workDate, hoursWorked, previousHours)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+	}
+
+	// Going over the allowance doesn't block logging time once the user has acknowledged it
+	if !form.Valid() || (form.HasWarnings() && !form.AcknowledgeWarnings) {
+		services, err := app.timesheetServices(&timesheet)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+
+		form.IsUpdate = true
+		data := app.newTemplateData(req)
+		data.Form = form
+		data.Project = &project
+		data.Client = &client
+		data.Services = services
+		data.WorkTypes = workTypes
+		data.Timesheet = &timesheet
+		data.LockingInvoices = locking
+		app.render(res, req, http.StatusUnprocessableEntity, "timesheet_create.html", data)
+		return
+	}
+
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		err := tx.Timesheets.Update(id, workDate, hoursWorked, hourlyRate, form.Description)
+		if err != nil {
+			return err
+		}
+		err = flagLockingInvoices(tx, locking)
+		if err != nil {
+			return err
+		}
+		err = tx.Timesheets.SetCostRate(id, costRate)
+		if err != nil {
+			return err
+		}
+		err = tx.Timesheets.SetService(id, serviceID, unit)
+		if err != nil {
+			return err
+		}
+		err = tx.Timesheets.SetWorkType(id, form.WorkType)
+		if err != nil {
+			return err
+		}
+
+		// A subcontractor's change needs approving again, even to an entry already approved
+		if app.isSubcontractor(req) {
+			return tx.Timesheets.SetPendingApproval(id, true)
+		}
+		return nil
+	})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	app.flash(req, fmt.Sprintf("Timesheet entry for %s updated%s", dateFormat.Format(workDate), staleInvoicesNote(locking)))
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", timesheet.ProjectID), http.StatusSeeOther)
+}
+
+// timesheetDelete handles a POST request to soft delete a timesheet
+func (app *application) timesheetDelete(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	// Check if timesheet exists before deleting and get project ID for redirect
+	timesheet, err := app.timesheets.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	editable, err := app.canEditTimesheet(req, timesheet)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	if !editable {
+		http.NotFound(res, req)
+		return
+	}
+
+	if _, ok := app.openProject(res, req, timesheet.ProjectID); !ok {
+		return
+	}
+
+	// Entries billed on an invoice are only deleted when an owner overrides the lock
+	locking, err := app.timesheetLockingInvoices(timesheet.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	if len(locking) > 0 && (app.isSubcontractor(req) || req.PostFormValue("override_lock") != "true") {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		err := tx.Timesheets.Delete(id)
+		if err != nil {
+			return err
+		}
+		return flagLockingInvoices(tx, locking)
+	})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	// Redirect to project view page after successful deletion
+	app.flash(req, fmt.Sprintf("Timesheet entry for %s deleted%s", app.dateFormat(req).Format(timesheet.WorkDate), staleInvoicesNote(locking)))
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", timesheet.ProjectID), http.StatusSeeOther)
+}
+
+// timesheetApprovalsList handles a GET request which lists the timesheet entries logged by
+// subcontractors that are waiting for approval
+func (app *application) timesheetApprovalsList(res http.ResponseWriter, req *http.Request) {
+	approvals, err := app.timesheets.GetPendingApproval()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.TimesheetApprovals = approvals
+	app.render(res, req, http.StatusOK, "timesheet_approvals.html", data)
+}
+
+// pendingApprovalTimesheet returns the timesheet entry named in the request path if it is
+// waiting for approval, writing a not found response otherwise
+func (app *application) pendingApprovalTimesheet(res http.ResponseWriter, req *http.Request) (models.Timesheet, bool) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return models.Timesheet{}, false
+	}
+
+	timesheet, err := app.timesheets.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return models.Timesheet{}, false
+	}
+	if !timesheet.PendingApproval {
+		http.NotFound(res, req)
+		return models.Timesheet{}, false
+	}
+	return timesheet, true
+}
+
+// timesheetApprovePost handles a POST request which approves an entry, making it invoiceable
+func (app *application) timesheetApprovePost(res http.ResponseWriter, req *http.Request) {
+	timesheet, ok := app.pendingApprovalTimesheet(res, req)
+	if !ok {
+		return
+	}
+
+	err := app.timesheets.SetPendingApproval(timesheet.ID, false)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("Timesheet entry for %s approved", app.dateFormat(req).Format(timesheet.WorkDate)))
+	app.redirect(res, req, "/timesheets/approvals", http.StatusSeeOther)
+}
+
+// timesheetRejectPost handles a POST request which rejects an entry by soft deleting it
+func (app *application) timesheetRejectPost(res http.ResponseWriter, req *http.Request) {
+	timesheet, ok := app.pendingApprovalTimesheet(res, req)
+	if !ok {
+		return
+	}
+
+	err := app.timesheets.Delete(timesheet.ID)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("Timesheet entry for %s rejected", app.dateFormat(req).Format(timesheet.WorkDate)))
+	app.redirect(res, req, "/timesheets/approvals", http.StatusSeeOther)
+}
+
+// pendingTimesheetsList handles a GET request which lists the timesheet entries captured
+// from inbound email that are waiting to be confirmed
+func (app *application) pendingTimesheetsList(res http.ResponseWriter, req *http.Request) {
+	pending, err := app.pendingTimesheets.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.PendingTimesheets = pending
+	app.render(res, req, http.StatusOK, "pending_timesheets.html", data)
+}
+
+// pendingTimesheetConfirmPost handles a POST request which turns a pending entry into a
+// timesheet at the project's hourly rate
+func (app *application) pendingTimesheetConfirmPost(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	pending, err := app.pendingTimesheets.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	project, ok := app.openProject(res, req, pending.ProjectID)
+	if !ok {
+		return
+	}
+
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		_, err := tx.Timesheets.Insert(project.ID, pending.WorkDate, pending.HoursWorked, project.HourlyRate, pending.Description)
+		if err != nil {
+			return err
+		}
+		return tx.PendingTimesheets.Delete(id)
+	})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("Timesheet entry for %s added to %s", app.dateFormat(req).Format(pending.WorkDate), project.Name))
+	app.redirect(res, req, "/timesheets/pending", http.StatusSeeOther)
+}
+
+// pendingTimesheetDiscardPost handles a POST request to throw away a pending entry
+func (app *application) pendingTimesheetDiscardPost(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	pending, err := app.pendingTimesheets.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	err = app.pendingTimesheets.Delete(id)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("Pending entry for %s discarded", pending.ProjectName))
+	app.redirect(res, req, "/timesheets/pending", http.StatusSeeOther)
+}
+
+// maxInboundEmailBytes bounds the size of a forwarded email, attachments included
+const maxInboundEmailBytes = 10 << 20
+
+// inboundEmailPost handles the webhook Mailgun calls for each email routed to the
+// application. Lines like "log 2h ProjectX: reviewed manuscript" in the subject or body of
+// email from the inbound_email_sender address become pending timesheet entries for today.
+// Rejections use 406, which tells Mailgun not to retry.
+func (app *application) inboundEmailPost(res http.ResponseWriter, req *http.Request) {
+	signingKey, err := app.settings.GetString("inbound_email_signing_key")
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(res, req, err)
+		return
+	}
+	if signingKey == "" {
+		http.NotFound(res, req)
+		return
+	}
+
+	req.Body = http.MaxBytesReader(res, req.Body, maxInboundEmailBytes)
+	err = req.ParseMultipartForm(maxInboundEmailBytes)
+	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	err = inbound.VerifyMailgun(signingKey, req.PostFormValue("timestamp"), req.PostFormValue("token"), req.PostFormValue("signature"), time.Now())
+	if err != nil {
+		app.logger.Warn("rejected inbound email", "reason", err.Error())
+		app.clientError(res, http.StatusNotAcceptable)
+		return
+	}
+
+	allowedSender, err := app.settings.GetString("inbound_email_sender")
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(res, req, err)
+		return
+	}
+	sender := req.PostFormValue("sender")
+	if !inbound.SameAddress(sender, allowedSender) {
+		app.logger.Warn("rejected inbound email", "reason", "unexpected sender", "sender", sender)
+		app.clientError(res, http.StatusNotAcceptable)
+		return
+	}
+
+	// stripped-text leaves out quoted replies and signatures
+	subject := req.PostFormValue("subject")
+	body := req.PostFormValue("stripped-text")
+	if body == "" {
+		body = req.PostFormValue("body-plain")
+	}
+	entries := inbound.Parse(subject + "\n" + body)
+
+	projects, err := app.projects.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	captured := 0
+	for _, entry := range entries {
+		project, ok := findProjectByName(projects, entry.Project)
+		if !ok || project.Status == models.ProjectStatusArchived {
+			app.logger.Warn("skipped inbound timesheet entry", "reason", "no open project with that name", "project", entry.Project)
+			continue
+		}
+
+		_, err = app.pendingTimesheets.Insert(project.ID, today, entry.Hours, entry.Description, subject)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		captured++
+	}
+
+	app.writeJSON(res, req, http.StatusOK, map[string]int{"captured": captured, "skipped": len(entries) - captured})
+}
+
+// findProjectByName returns the project whose name matches name, ignoring case and surrounding spaces
+func findProjectByName(projects []models.ProjectWithClient, name string) (models.ProjectWithClient, bool) {
+	name = strings.TrimSpace(name)
+	for _, project := range projects {
+		if strings.EqualFold(strings.TrimSpace(project.Name), name) {
+			return project, true
+		}
+	}
+	return models.ProjectWithClient{}, false
+}
+
+// invoiceCreate handles a GET request which returns an empty invoice creation form
+func (app *application) invoiceCreate(res http.ResponseWriter, req *http.Request) {
+	projectID, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || projectID < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	// Check if project exists
+	project, ok := app.openProject(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	// Get the client for context
+	client, err := app.clients.Get(project.ClientID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	credit, err := app.credits.Balance(client.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	sequence, err := app.invoiceSequence(project, client)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	form, err := app.newInvoiceForm(req, project)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	if sequence != nil {
+		form.InvoiceNumber = sequence.Upcoming()
+	}
+
+	data := app.newTemplateData(req)
+	err = app.setPaymentTermsPresets(&data, &form)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	data.Form = form
+	data.Project = &project
+	data.Client = &client
+	data.ClientCredit = credit
+	data.InvoiceCC = models.ResolveInvoiceCC(models.Invoice{}, project, client)
+	data.BudgetCode = models.ResolveBudgetCode(models.Invoice{}, project, client)
+	data.PinnedNotes, err = app.clientNotes.GetPinned(client.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	app.render(res, req, http.StatusOK, "invoice_create.html", data)
+}
+
+// newInvoiceForm fills in the form for a new invoice of a project as the invoice default
+// settings ask: the invoice date, payment terms, details and amount due
+func (app *application) newInvoiceForm(req *http.Request, project models.Project) (invoiceForm, error) {
+	settings, err := app.settings.GetAll()
+	if err != nil {
+		return invoiceForm{}, err
+	}
+	defaults := models.NewInvoiceDefaults(settings)
+
+	dateFormat := app.dateFormat(req)
+	form := invoiceForm{
+		PaymentTerms:   defaults.PaymentTerms,
+		DisplayDetails: defaults.DisplayDetails,
+	}
+	// The due date follows the invoice date, so it is only filled in along with it
+	if defaults.DateToday {
+		now := time.Now()
+		form.InvoiceDate = dateFormat.Format(now)
+		form.DueDate = dateFormat.Format(models.DueDateFor(now, defaults.PaymentTerms))
+	}
+	if defaults.AutoAmount {
+		timesheets, err := app.timesheets.GetByProject(project.ID)
+		if err != nil {
+			return invoiceForm{}, err
+		}
+		billed, err := app.timesheets.GetLockedIDs(project.ID)
+		if err != nil {
+			return invoiceForm{}, err
+		}
+		if amount := models.UnbilledAmount(timesheets, billed); amount > 0 {
+			form.AmountDue = fmt.Sprintf("%.2f", amount)
+		}
+	}
+	setDiscountFields(&form, models.FinancialsFromProject(project))
+	return form, nil
+}
+
+// invoiceCreatePost handles a POST request with invoice form data which is then
+// validated and used to insert a new invoice into the database
+func (app *application) invoiceCreatePost(res http.ResponseWriter, req *http.Request) {
+	projectID, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || projectID < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	// Check if project exists
+	project, ok := app.openProject(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	// Get the client for context
+	client, err := app.clients.Get(project.ClientID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	var form invoiceForm
+	err = app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	choosePaymentTerms(&form)
+	form.CheckField(validator.NotBlank(form.InvoiceDate), "invoice_date", "Invoice date is required")
+	form.CheckField(validator.NotBlank(form.AmountDue), "amount_due", "Amount due is required")
+	form.CheckField(validator.MaxChars(form.PaymentTerms, NAME_LENGTH), "payment_terms", fmt.Sprintf("Payment terms must be shorter than %d characters", NAME_LENGTH))
+	form.InvoiceNumber = strings.TrimSpace(form.InvoiceNumber)
+	form.CheckField(validator.MaxChars(form.InvoiceNumber, NAME_LENGTH), "invoice_number", fmt.Sprintf("Invoice number must be shorter than %d characters", NAME_LENGTH))
+	checkInvoiceCCEmail(&form)
+	checkBudgetCode(&form.Validator, form.BudgetCode)
+
+	dateFormat := app.dateFormat(req)
+	invoiceDate, amountDue, datePaid, amountPaid := parseInvoiceAmounts(&form, dateFormat)
+
+	var dueDate time.Time
+	if form.Valid() {
+		dueDate = parseDueDate(&form, dateFormat, invoiceDate)
+	}
+
+	var timesheetsFrom, timesheetsTo *time.Time
+	if form.Valid() {
+		timesheetsFrom, timesheetsTo = parseTimesheetRange(&form, dateFormat)
+	}
+
+	// The invoice is billed on the project's terms, with the discount given on the form
+	terms := models.FinancialsFromProject(project)
+	parseInvoiceDiscount(req, &form, &terms, amountDue)
+
+	// An invoice number other than the one the sequence would issue next is kept as entered
+	sequence, err := app.invoiceSequence(project, client)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	var manualNumber string
+	if form.Valid() {
+		manualNumber, err = app.checkManualInvoiceNumber(&form.Validator, sequence, form.InvoiceNumber)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+	}
+
+	if form.Valid() {
+		checkInvoiceWarnings(&form, dateFormat, client, terms, invoiceDate, datePaid, timesheetsFrom)
+
+		// Guard against billing the same work twice
+		similar, err := app.invoices.ExistsSimilar(projectID, amountDue, invoiceDate)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		form.CheckWarning(!similar, "amount_due", fmt.Sprintf("An invoice for %.2f on this project is already dated within %d days of %s",
+			amountDue, models.SimilarInvoiceWindowDays, dateFormat.Format(invoiceDate)))
+	}
+
+	// Warnings don't block saving once the user has acknowledged them
+	if !form.Valid() || (form.HasWarnings() && !form.AcknowledgeWarnings) {
+		credit, err := app.credits.Balance(client.ID)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+
+		data := app.newTemplateData(req)
+		err = app.setPaymentTermsPresets(&data, &form)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		data.Form = form
+		data.Project = &project
+		data.Client = &client
+		data.ClientCredit = credit
+		data.InvoiceCC = models.ResolveInvoiceCC(models.Invoice{}, project, client)
+		data.BudgetCode = models.ResolveBudgetCode(models.Invoice{}, project, client)
+		data.PinnedNotes, err = app.clientNotes.GetPinned(client.ID)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		app.render(res, req, http.StatusUnprocessableEntity, "invoice_create.html", data)
+		return
+	}
+
+	// A backdated invoice is converted at the rate of its date
+	err = app.applyExchangeRate(req.Context(), &terms, invoiceDate)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	// Insert the invoice and take its number from the business profile's sequence atomically,
+	// so a failure can't leave a gap in the numbering or an unnumbered invoice
+	var invoiceID int
+	var invoiceNumber string
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		id, err := tx.Invoices.Insert(projectID, invoiceDate, datePaid, form.PaymentTerms, amountDue, form.DisplayDetails)
+		if err != nil {
+			return err
+		}
+		invoiceID = id
+
+		err = recordInvoiceEvents(tx, id, nil, datePaid, models.InvoiceEventCreated)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Invoices.SetDueDate(id, &dueDate)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Invoices.SetCCEmail(id, form.CCEmail)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Invoices.SetBudgetCode(id, form.BudgetCode)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Invoices.SetTimesheetRange(id, timesheetsFrom, timesheetsTo)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Invoices.SetFinancials(id, terms)
+		if err != nil {
+			return err
+		}
+
+		// Credit and payment are set against the total, so are settled once the terms are saved
+		err = settleInvoice(tx, client.ID, id, amountPaid, form.ApplyCredit)
+		if err != nil {
+			return err
+		}
+
+		if manualNumber != "" {
+			invoiceNumber = manualNumber
+			return tx.Invoices.SetInvoiceNumber(id, invoiceNumber)
+		}
+
+		if sequence == nil {
+			return nil
+		}
+
+		invoiceNumber, err = sequence.Claim(tx)
+		if err != nil || invoiceNumber == "" {
+			return err
+		}
+		return tx.Invoices.SetInvoiceNumber(id, invoiceNumber)
+	})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	if datePaid != nil {
+		app.notifyInvoicePaid(invoiceID)
+	}
+	app.flash(req, fmt.Sprintf("Invoice #%s created", models.Invoice{ID: invoiceID, InvoiceNumber: invoiceNumber}.DisplayNumber()))
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", projectID), http.StatusSeeOther)
+}
+
+// invoiceUpdate handles a GET request which returns an invoice update form pre-populated with invoice data
+func (app *application) invoiceUpdate(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	invoice, err := app.invoices.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	// Voided invoices are kept unchanged for the record
+	if invoice.IsVoided() {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
+	// Get the project for context
+	project, ok := app.openProject(res, req, invoice.ProjectID)
+	if !ok {
+		return
+	}
+
+	// Get the client for context
+	client, err := app.clients.Get(project.ClientID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	data := app.newTemplateData(req)
+
+	var datePaidStr string
+	if invoice.DatePaid != nil {
+		datePaidStr = data.DateFormat.Format(*invoice.DatePaid)
+	}
+
+	var amountPaidStr string
+	if invoice.AmountPaid != nil {
+		amountPaidStr = fmt.Sprintf("%.2f", *invoice.AmountPaid)
+	}
+
+	var dueDateStr string
+	if invoice.DueDate != nil {
+		dueDateStr = data.DateFormat.Format(*invoice.DueDate)
+	}
+
+	var timesheetsFromStr, timesheetsToStr string
+	if invoice.TimesheetsFrom != nil {
+		timesheetsFromStr = data.DateFormat.Format(*invoice.TimesheetsFrom)
+	}
+	if invoice.TimesheetsTo != nil {
+		timesheetsToStr = data.DateFormat.Format(*invoice.TimesheetsTo)
+	}
+
+	form := invoiceForm{
+		InvoiceDate:    data.DateFormat.Format(invoice.InvoiceDate),
+		DatePaid:       datePaidStr,
+		PaymentTerms:   invoice.PaymentTerms,
+		AmountDue:      fmt.Sprintf("%.2f", invoice.AmountDue),
+		DisplayDetails: invoice.DisplayDetails,
+		AmountPaid:     amountPaidStr,
+		DueDate:        dueDateStr,
+		CCEmail:        invoice.CCEmail,
+		BudgetCode:     invoice.BudgetCode,
+		TimesheetsFrom: timesheetsFromStr,
+		TimesheetsTo:   timesheetsToStr,
+		IsUpdate:       true,
+	}
+	setDiscountFields(&form, invoiceTerms(invoice, project))
+	err = app.setPaymentTermsPresets(&data, &form)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	data.Form = form
+	data.Project = &project
+	data.Client = &client
+	data.Invoice = &invoice
+	data.InvoiceCC = models.ResolveInvoiceCC(models.Invoice{}, project, client)
+	data.BudgetCode = models.ResolveBudgetCode(models.Invoice{}, project, client)
+	data.PinnedNotes, err = app.clientNotes.GetPinned(client.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	data.DeliverySummary, err = app.deliverySummary(invoice.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	app.render(res, req, http.StatusOK, "invoice_create.html", data)
+}
+
+// invoiceUpdatePost handles a POST request with invoice form data which is then
+// validated and used to update an existing invoice in the database
+func (app *application) invoiceUpdatePost(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	// Get the invoice to ensure it exists and get the project ID
+	invoice, err := app.invoices.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	// Voided invoices are kept unchanged for the record
+	if invoice.IsVoided() {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
+	// Get project and client for context
+	project, ok := app.openProject(res, req, invoice.ProjectID)
+	if !ok {
+		return
+	}
+
+	client, err := app.clients.Get(project.ClientID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	var form invoiceForm
+	err = app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	choosePaymentTerms(&form)
+	form.CheckField(validator.NotBlank(form.InvoiceDate), "invoice_date", "Invoice date is required")
+	form.CheckField(validator.NotBlank(form.AmountDue), "amount_due", "Amount due is required")
+	form.CheckField(validator.MaxChars(form.PaymentTerms, NAME_LENGTH), "payment_terms", fmt.Sprintf("Payment terms must be shorter than %d characters", NAME_LENGTH))
+	checkInvoiceCCEmail(&form)
+	checkBudgetCode(&form.Validator, form.BudgetCode)
+
+	dateFormat := app.dateFormat(req)
+	invoiceDate, amountDue, datePaid, amountPaid := parseInvoiceAmounts(&form, dateFormat)
+
+	var dueDate time.Time
+	if form.Valid() {
+		dueDate = parseDueDate(&form, dateFormat, invoiceDate)
+	}
+
+	var timesheetsFrom, timesheetsTo *time.Time
+	if form.Valid() {
+		timesheetsFrom, timesheetsTo = parseTimesheetRange(&form, dateFormat)
+	}
+
+	terms := invoiceTerms(invoice, project)
+	discountGiven := parseInvoiceDiscount(req, &form, &terms, amountDue)
+
+	// A paid invoice's date and amount stay as they were paid unless the payment is removed
+	if form.Valid() && datePaid != nil {
+		form.CheckField(!invoice.ChangesPaidFigures(invoiceDate, invoice.AmountDue), "invoice_date",
+			"This invoice has been paid, so its date can't be changed. Clear the date paid to change it.")
+		form.CheckField(!invoice.ChangesPaidFigures(invoice.InvoiceDate, amountDue), "amount_due",
+			"This invoice has been paid, so its amount can't be changed. Clear the date paid to change it.")
+	}
+
+	if form.Valid() {
+		checkInvoiceWarnings(&form, dateFormat, client, terms, invoiceDate, datePaid, timesheetsFrom)
+	}
+
+	// Warnings don't block saving once the user has acknowledged them
+	if !form.Valid() || (form.HasWarnings() && !form.AcknowledgeWarnings) {
+		form.IsUpdate = true
+		data := app.newTemplateData(req)
+		err = app.setPaymentTermsPresets(&data, &form)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		data.Form = form
+		data.Project = &project
+		data.Client = &client
+		data.Invoice = &invoice
+		data.InvoiceCC = models.ResolveInvoiceCC(models.Invoice{}, project, client)
+		data.BudgetCode = models.ResolveBudgetCode(models.Invoice{}, project, client)
+		data.PinnedNotes, err = app.clientNotes.GetPinned(client.ID)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		data.DeliverySummary, err = app.deliverySummary(invoice.ID)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		app.render(res, req, http.StatusUnprocessableEntity, "invoice_create.html", data)
+		return
+	}
+
+	// Keep the client's credit in step with any change to the payment received
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		err := tx.Invoices.Update(id, invoiceDate, datePaid, form.PaymentTerms, amountDue, form.DisplayDetails)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Invoices.SetDueDate(id, &dueDate)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Invoices.SetCCEmail(id, form.CCEmail)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Invoices.SetBudgetCode(id, form.BudgetCode)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Invoices.SetTimesheetRange(id, timesheetsFrom, timesheetsTo)
+		if err != nil {
+			return err
+		}
+
+		// Terms are only saved when the form gives a discount, so invoices from before
+		// financials were captured otherwise keep following the project
+		if discountGiven {
+			err = tx.Invoices.SetFinancials(id, terms)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = recordInvoiceEvents(tx, id, invoice.DatePaid, datePaid, models.InvoiceEventUpdated)
+		if err != nil {
+			return err
+		}
+
+		return settleInvoice(tx, client.ID, id, amountPaid, false)
+	})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	if invoice.DatePaid == nil && datePaid != nil {
+		app.notifyInvoicePaid(id)
+	}
+	app.flash(req, fmt.Sprintf("Invoice #%s updated", invoice.DisplayNumber()))
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", invoice.ProjectID), http.StatusSeeOther)
+}
+
+// invoiceDelete handles a POST request to soft delete an invoice
+func (app *application) invoiceDelete(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	// Check if invoice exists before deleting and get project ID for redirect
+	invoice, err := app.invoices.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	// Voided invoices are kept unchanged for the record
+	if invoice.IsVoided() {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
+	if _, ok := app.openProject(res, req, invoice.ProjectID); !ok {
+		return
+	}
+
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		err := tx.Invoices.Delete(id)
+		if err != nil {
+			return err
+		}
+		err = tx.Milestones.ReleaseInvoice(id)
+		if err != nil {
+			return err
+		}
+		err = tx.Credits.ReleaseInvoice(id)
+		if err != nil {
+			return err
+		}
+		return tx.InvoiceEvents.Record(id, models.InvoiceEventDeleted)
+	})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	// Redirect to project view page after successful deletion
+	app.flash(req, fmt.Sprintf("Invoice #%s deleted", invoice.DisplayNumber()))
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", invoice.ProjectID), http.StatusSeeOther)
+}
+
+// invoiceVoid handles a GET request which returns a form asking for the reason an invoice is being voided
+func (app *application) invoiceVoid(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	invoice, err := app.invoices.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	if invoice.IsVoided() {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
+	project, ok := app.openProject(res, req, invoice.ProjectID)
+	if !ok {
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = invoiceVoidForm{}
+	data.Invoice = &invoice
+	data.Project = &project
+	app.render(res, req, http.StatusOK, "invoice_void.html", data)
+}
+
+// invoiceVoidPost handles a POST request to void an invoice. Unlike delete, the invoice stays
+// visible with its number but is excluded from revenue.
+func (app *application) invoiceVoidPost(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	invoice, err := app.invoices.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	if _, ok := app.openProject(res, req, invoice.ProjectID); !ok {
+		return
+	}
+
+	var form invoiceVoidForm
+	err = app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.VoidReason), "void_reason", "A reason is required to void an invoice")
+	form.CheckField(validator.MaxChars(form.VoidReason, NAME_LENGTH), "void_reason", fmt.Sprintf("Reason must be shorter than %d characters", NAME_LENGTH))
+
+	if !form.Valid() {
+		project, err := app.projects.Get(invoice.ProjectID)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+
+		data := app.newTemplateData(req)
+		data.Form = form
+		data.Invoice = &invoice
+		data.Project = &project
+		app.render(res, req, http.StatusUnprocessableEntity, "invoice_void.html", data)
+		return
+	}
+
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		err := tx.Invoices.Void(id, strings.TrimSpace(form.VoidReason))
+		if err != nil {
+			return err
+		}
+		err = tx.Milestones.ReleaseInvoice(id)
+		if err != nil {
+			return err
+		}
+		err = tx.Credits.ReleaseInvoice(id)
+		if err != nil {
+			return err
+		}
+		return tx.InvoiceEvents.Record(id, models.InvoiceEventVoided)
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrInvoiceVoided) {
+			app.clientError(res, http.StatusConflict)
+		} else {
+			app.modelError(res, req, err)
+		}
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("Invoice #%s voided", invoice.DisplayNumber()))
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", invoice.ProjectID), http.StatusSeeOther)
+}
+
+// invoicePrint handles a GET request to print an invoice PDF. Rendering the PDF takes a
+// while, so it is queued as a job and the user is sent to a page that waits for it.
+func (app *application) invoicePrint(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	invoice, err := app.invoices.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	// Long PDFs are slow to render and unwieldy to send, so ask before generating one
+	if req.URL.Query().Get("confirm") != "1" {
+		allSettings, err := app.settings.GetAll()
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+
+		pages, err := app.invoices.EstimatePDFPages(id, allSettings)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+
+		if maxPages := models.MaxPDFPages(allSettings); pages > maxPages {
+			project, err := app.projects.Get(invoice.ProjectID)
+			if err != nil {
+				app.serverError(res, req, err)
+				return
+			}
+
+			data := app.newTemplateData(req)
+			data.Invoice = &invoice
+			data.Project = &project
+			data.PDFPages = pages
+			data.MaxPDFPages = maxPages
+			app.render(res, req, http.StatusOK, "invoice_print_warning.html", data)
+			return
+		}
+	}
+
+	jobID, err := app.queue.Enqueue(models.JobInvoicePDF, invoicePDFJobPayload{InvoiceID: id})
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	app.redirect(res, req, fmt.Sprintf("/job/view/%d", jobID), http.StatusSeeOther)
+}
+
+// invoiceHTML handles a GET request which shows an invoice as the page its PDF is printed
+// from, styled for the browser's own printing. It needs no headless browser, so reprints and
+// copying amounts are quick.
+func (app *application) invoiceHTML(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	allSettings, err := app.settings.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	html, err := app.invoices.GenerateHTML(id, allSettings)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	// The invoice carries its own styles and embeds the logo as a data URL
+	res.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; img-src data:; frame-ancestors 'none'")
+	res.Header().Set("Content-Type", "text/html; charset=utf-8")
+	res.Write(html)
+}
+
+// pdfSpaceNeeded is the disk space set aside for generating a PDF: the browser's scratch
+// files and the finished PDF stored with its job
+const pdfSpaceNeeded = 20 << 20
+
+// invoicePDFJobPayload identifies the invoice an invoice PDF job generates
+type invoicePDFJobPayload struct {
+	InvoiceID int `json:"invoice_id"`
+}
+
+// invoicePDFJob generates an invoice PDF for the job queue, using the settings in effect
+// when the job runs
+func (app *application) invoicePDFJob(ctx context.Context, payload []byte) (jobs.Result, error) {
+	var params invoicePDFJobPayload
+	err := json.Unmarshal(payload, &params)
+	if err != nil {
+		return jobs.Result{}, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	pdfBytes, err := app.invoicePDF(params.InvoiceID)
+	if err != nil {
+		return jobs.Result{}, err
+	}
+
+	return jobs.Result{
+		Data:        pdfBytes,
+		ContentType: "application/pdf",
+		Filename:    fmt.Sprintf("invoice_%d.pdf", params.InvoiceID),
+	}, nil
+}
+
+// timesheetReport handles a GET request to print a project's timesheet report PDF for the
+// dates in the start and end query parameters. Like invoice PDFs it is generated by a job.
+func (app *application) timesheetReport(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	_, err = app.projects.Get(id)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.enqueueTimesheetReport(res, req, timesheetReportJobPayload{ProjectID: id})
+}
+
+// clientTimesheetReport handles a GET request to print a timesheet report PDF covering all
+// of a client's projects for the dates in the start and end query parameters
+func (app *application) clientTimesheetReport(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	_, err = app.clients.Get(id)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.enqueueTimesheetReport(res, req, timesheetReportJobPayload{ClientID: id})
+}
+
+// enqueueTimesheetReport queues a timesheet report job for the dates in the request and sends
+// the user to the page that waits for it
+func (app *application) enqueueTimesheetReport(res http.ResponseWriter, req *http.Request, payload timesheetReportJobPayload) {
+	start, err := time.Parse("2006-01-02", req.URL.Query().Get("start"))
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+	end, err := time.Parse("2006-01-02", req.URL.Query().Get("end"))
+	if err != nil || end.Before(start) {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+	payload.Start = start.Format("2006-01-02")
+	payload.End = end.Format("2006-01-02")
+
+	jobID, err := app.queue.Enqueue(models.JobTimesheetReport, payload)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	app.redirect(res, req, fmt.Sprintf("/job/view/%d", jobID), http.StatusSeeOther)
+}
+
+// timesheetReportJobPayload identifies the project, or the client, a timesheet report job
+// covers and its first and last days as "2006-01-02"
+type timesheetReportJobPayload struct {
+	ProjectID int    `json:"project_id,omitempty"`
+	ClientID  int    `json:"client_id,omitempty"`
+	Start     string `json:"start"`
+	End       string `json:"end"`
+}
+
+// timesheetReportJob generates a timesheet report PDF for the job queue
+func (app *application) timesheetReportJob(ctx context.Context, payload []byte) (jobs.Result, error) {
+	var params timesheetReportJobPayload
+	err := json.Unmarshal(payload, &params)
+	if err != nil {
+		return jobs.Result{}, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	pdfBytes, filename, err := app.timesheetReportPDF(params)
+	if err != nil {
+		return jobs.Result{}, err
+	}
+
+	return jobs.Result{
+		Data:        pdfBytes,
+		ContentType: "application/pdf",
+		Filename:    filename,
+	}, nil
+}
+
+// timesheetReportPDF generates the timesheet report PDF described by params, along with the
+// name of the file to save it as
+func (app *application) timesheetReportPDF(params timesheetReportJobPayload) ([]byte, string, error) {
+	start, err := time.Parse("2006-01-02", params.Start)
+	if err != nil {
+		return nil, "", err
+	}
+	end, err := time.Parse("2006-01-02", params.End)
+	if err != nil {
+		return nil, "", err
+	}
+
+	report, filename, err := app.timesheetReportFor(params, start, end)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := app.checkPDFDiskSpace(); err != nil {
+		return nil, "", err
+	}
+
+	allSettings, err := app.settings.GetAll()
+	if err != nil {
+		return nil, "", err
+	}
+	html, err := models.RenderTimesheetReportHTML(report, allSettings)
+	if err != nil {
+		return nil, "", err
+	}
+	pdfBytes, err := models.RenderPDF(html)
+	if err != nil {
+		return nil, "", err
+	}
+	return pdfBytes, filename, nil
+}
+
+// timesheetReportFor builds the timesheet report a job asks for, along with the name of the
+// file to save it as
+func (app *application) timesheetReportFor(params timesheetReportJobPayload, start, end time.Time) (models.TimesheetReport, string, error) {
+	dates := fmt.Sprintf("%s_%s", params.Start, params.End)
+
+	if params.ProjectID != 0 {
+		project, err := app.projects.Get(params.ProjectID)
+		if err != nil {
+			return models.TimesheetReport{}, "", err
+		}
+		client, err := app.clients.Get(project.ClientID)
+		if err != nil {
+			return models.TimesheetReport{}, "", err
+		}
+		entries, err := app.timesheets.GetProjectReport(project.ID, start, end)
+		if err != nil {
+			return models.TimesheetReport{}, "", err
+		}
+		report := models.NewTimesheetReport(project.Name, client.Name, start, end, entries)
+		return report, fmt.Sprintf("timesheets_project_%d_%s.pdf", project.ID, dates), nil
+	}
+
+	client, err := app.clients.Get(params.ClientID)
+	if err != nil {
+		return models.TimesheetReport{}, "", err
+	}
+	entries, err := app.timesheets.GetClientReport(client.ID, start, end)
+	if err != nil {
+		return models.TimesheetReport{}, "", err
+	}
+	report := models.NewTimesheetReport(client.Name, client.Name, start, end, entries)
+	report.ShowProjects = true
+	return report, fmt.Sprintf("timesheets_client_%d_%s.pdf", client.ID, dates), nil
+}
+
+// consolidatedInvoicesJobPayload names the month a consolidated invoices job bills, as "2006-01"
+type consolidatedInvoicesJobPayload struct {
+	Month string `json:"month"`
+}
+
+// consolidatedInvoicesJob invoices a month's unbilled time for every client billed with a
+// consolidated invoice, carrying on past a client that fails. The result lists the invoices created.
+func (app *application) consolidatedInvoicesJob(ctx context.Context, payload []byte) (jobs.Result, error) {
+	var params consolidatedInvoicesJobPayload
+	err := json.Unmarshal(payload, &params)
+	if err != nil {
+		return jobs.Result{}, fmt.Errorf("decoding payload: %w", err)
+	}
+	month, err := time.Parse("2006-01", params.Month)
+	if err != nil {
+		return jobs.Result{}, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	clientIDs, err := app.consolidated.ClientIDs()
+	if err != nil {
+		return jobs.Result{}, err
+	}
+
+	var summary strings.Builder
+	var errs []error
+	for _, clientID := range clientIDs {
+		client, err := app.clients.Get(clientID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("client %d: %w", clientID, err))
+			continue
+		}
+		invoiceID, invoiceNumber, err := app.createConsolidatedInvoice(ctx, client, month)
+		if errors.Is(err, models.ErrNothingToInvoice) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", client.Name, err))
+			continue
+		}
+		fmt.Fprintf(&summary, "Invoice #%s: %s\n", models.Invoice{ID: invoiceID, InvoiceNumber: invoiceNumber}.DisplayNumber(), client.Name)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return jobs.Result{}, err
+	}
+	if summary.Len() == 0 {
+		summary.WriteString("No unbilled time\n")
+	}
+
+	return jobs.Result{
+		Data:        []byte(summary.String()),
+		ContentType: "text/plain; charset=utf-8",
+		Filename:    fmt.Sprintf("consolidated_invoices_%s.txt", month.Format("2006-01")),
+	}, nil
+}
+
+// consolidatedInvoicesPayload bills the calendar month before the one the schedule falls due
+// in, so that the usual schedule at the start of each month bills the month just ended
+func consolidatedInvoicesPayload(due time.Time) any {
+	month := time.Date(due.Year(), due.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+	return consolidatedInvoicesJobPayload{Month: month.Format("2006-01")}
+}
+
+// chatNotifier returns where reminders are sent: the Slack webhook and Telegram bot set in
+// settings, unless the application was given a notifier, as tests are. It returns nil when
+// neither is set up.
+func (app *application) chatNotifier() (notify.Notifier, error) {
+	if app.notifier != nil {
+		return app.notifier, nil
+	}
+
+	var notifiers notify.Multi
+	slackURL, err := app.settings.GetString("slack_webhook_url")
+	if err != nil {
+		return nil, err
+	}
+	if slackURL != "" {
+		notifiers = append(notifiers, notify.Slack{WebhookURL: slackURL})
+	}
+
+	botToken, err := app.settings.GetString("telegram_bot_token")
+	if err != nil {
+		return nil, err
+	}
+	chatID, err := app.settings.GetString("telegram_chat_id")
+	if err != nil {
+		return nil, err
+	}
+	if botToken != "" && chatID != "" {
+		notifiers = append(notifiers, notify.Telegram{BotToken: botToken, ChatID: chatID})
+	}
+
+	if len(notifiers) == 0 {
+		return nil, nil
+	}
+	return notifiers, nil
+}
+
+// remindersJob sends a reminder to the chat apps in settings for each invoice fallen overdue
+// and each project deadline coming up, once each. A reminder that can't be sent is tried again
+// by the next job. The result lists the reminders sent.
+func (app *application) remindersJob(ctx context.Context, payload []byte) (jobs.Result, error) {
+	notifier, err := app.chatNotifier()
+	if err != nil {
+		return jobs.Result{}, err
+	}
+	if notifier == nil {
+		return jobs.Result{Data: []byte("No Slack webhook or Telegram bot is set in settings\n"), ContentType: "text/plain; charset=utf-8", Filename: "reminders.txt"}, nil
+	}
+
+	dateFormatSetting, err := app.settings.GetString("date_format")
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		return jobs.Result{}, err
+	}
+	dateFormat := models.ParseDateFormat(dateFormatSetting)
+	days, err := app.settings.GetInt("deadline_reminder_days")
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		return jobs.Result{}, err
+	}
+
+	invoices, err := app.invoices.GetOpen()
+	if err != nil {
+		return jobs.Result{}, err
+	}
+	projects, err := app.projects.GetAll()
+	if err != nil {
+		return jobs.Result{}, err
+	}
+	now := time.Now()
+	reminders := append(models.OverdueInvoiceReminders(invoices, now, dateFormat), models.DeadlineReminders(projects, now, days, dateFormat)...)
+
+	var summary strings.Builder
+	var errs []error
+	for _, reminder := range reminders {
+		claimed, err := app.notifications.Claim(reminder)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		err = notifier.Notify(ctx, reminder.Text)
+		if err != nil {
+			errs = append(errs, err)
+			if err := app.notifications.Release(reminder); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		fmt.Fprintln(&summary, reminder.Text)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return jobs.Result{}, err
+	}
+	if summary.Len() == 0 {
+		summary.WriteString("Nothing to remind of\n")
+	}
+
+	return jobs.Result{
+		Data:        []byte(summary.String()),
+		ContentType: "text/plain; charset=utf-8",
+		Filename:    "reminders.txt",
+	}, nil
+}
+
+// scheduleTasks adds the recurring jobs to the scheduler, each run on the cron schedule kept
+// in its setting
+func (app *application) scheduleTasks() {
+	app.scheduler.Add(jobs.Task{Kind: models.JobConsolidatedInvoices, Setting: "schedule_consolidated_invoices", Payload: consolidatedInvoicesPayload})
+	app.scheduler.Add(jobs.Task{Kind: models.JobReminders, Setting: "schedule_reminders", Payload: remindersPayload})
+	app.scheduler.Add(jobs.Task{Kind: models.JobAgingReport, Setting: "schedule_aging_report", Payload: agingReportPayload})
+}
+
+// remindersPayload is the payload of a scheduled reminders job. Reminders already sent
+// aren't sent again, so a run made up after a restart doesn't repeat them.
+func remindersPayload(time.Time) any {
+	return struct{}{}
+}
+
+// agingReportJobPayload names the day an aging report job ages unpaid invoices as of, as
+// "2006-01-02"
+type agingReportJobPayload struct {
+	AsOf string `json:"as_of"`
+}
+
+// agingReportPayload ages invoices as of the day the schedule falls due, so that a run made
+// up after a restart counts days overdue to the day it was meant for
+func agingReportPayload(due time.Time) any {
+	return agingReportJobPayload{AsOf: due.Format(time.DateOnly)}
+}
+
+// agingReportJob emails the freelancer the invoice aging report: what is owed in each range of
+// days overdue in the message, and every unpaid invoice in an attached CSV. Nothing is sent
+// until an SMTP server is set in settings. The result is the CSV.
+func (app *application) agingReportJob(ctx context.Context, payload []byte) (jobs.Result, error) {
+	var params agingReportJobPayload
+	err := json.Unmarshal(payload, &params)
+	if err != nil {
+		return jobs.Result{}, fmt.Errorf("decoding payload: %w", err)
+	}
+	asOf, err := time.Parse(time.DateOnly, params.AsOf)
+	if err != nil {
+		return jobs.Result{}, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	sender, err := app.emailSender()
+	if errors.Is(err, errNoSMTPServer) {
+		return jobs.Result{Data: []byte("No SMTP server is set in settings to send the aging report through\n"), ContentType: "text/plain; charset=utf-8", Filename: "aging_report.txt"}, nil
+	}
+	if err != nil {
+		return jobs.Result{}, err
+	}
+	name, err := app.settings.GetString("freelancer_name")
+	if err != nil {
+		return jobs.Result{}, err
+	}
+	email, err := app.settings.GetString("freelancer_email")
+	if err != nil {
+		return jobs.Result{}, err
+	}
+	dateFormatSetting, err := app.settings.GetString("date_format")
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		return jobs.Result{}, err
+	}
+	dateFormat := models.ParseDateFormat(dateFormatSetting)
+
+	report, err := app.reports.Aging(asOf)
+	if err != nil {
+		return jobs.Result{}, err
+	}
+	var detail bytes.Buffer
+	if err := report.WriteCSV(&detail); err != nil {
+		return jobs.Result{}, err
+	}
+	filename := fmt.Sprintf("aging_%s.csv", params.AsOf)
+
+	from := (&mail.Address{Name: name, Address: email}).String()
+	_, err = sender.Send(ctx, mailer.Message{
+		From:    from,
+		To:      []string{from},
+		Subject: "Invoice aging as of " + dateFormat.Format(asOf),
+		Text:    agingReportText(report, dateFormat, filename),
+		Attachments: []mailer.Attachment{
+			{Filename: filename, ContentType: "text/csv", Data: detail.Bytes()},
+		},
+	})
+	if err != nil {
+		return jobs.Result{}, err
+	}
+
+	return jobs.Result{
+		Data:        detail.Bytes(),
+		ContentType: "text/csv; charset=utf-8",
+		Filename:    filename,
+	}, nil
+}
+
+// agingReportText summarizes an aging report for the body of its email, one line per range of
+// days overdue
+func agingReportText(report models.AgingReport, dateFormat models.DateFormat, filename string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Invoice aging as of %s\n\n", dateFormat.Format(report.AsOf))
+	count := 0
+	for _, bucket := range report.Buckets {
+		fmt.Fprintf(&b, "%-14s %12s  %s\n", bucket.Label, fmt.Sprintf("$%.2f", bucket.Total), invoiceCount(len(bucket.Invoices)))
+		count += len(bucket.Invoices)
+	}
+	fmt.Fprintf(&b, "%-14s %12s  %s\n", "Total", fmt.Sprintf("$%.2f", report.Total), invoiceCount(count))
+	if count > 0 {
+		fmt.Fprintf(&b, "\nEvery unpaid invoice is listed in the attached %s.\n", filename)
+	}
+	return b.String()
+}
+
+// invoiceCount describes a number of invoices, such as "1 invoice" or "3 invoices"
+func invoiceCount(n int) string {
+	if n == 1 {
+		return "1 invoice"
+	}
+	return fmt.Sprintf("%d invoices", n)
+}
+
+// invoicePDF generates an invoice's PDF with the settings currently in effect, signed when
+// a signing certificate is configured
+func (app *application) invoicePDF(invoiceID int) ([]byte, error) {
+	if err := app.checkPDFDiskSpace(); err != nil {
+		return nil, err
+	}
+	allSettings, err := app.settings.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	pdfBytes, err := app.invoices.GenerateComprehensivePDF(invoiceID, allSettings)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			return nil, fmt.Errorf("invoice %d no longer exists", invoiceID)
+		}
+		return nil, err
+	}
+
+	return app.signInvoicePDF(invoiceID, pdfBytes)
+}
+
+// signInvoicePDF digitally signs an invoice's PDF with the certificate in settings and
+// records the signature on the invoice. Without a certificate configured the PDF is returned
+// unsigned; a certificate that can't be used fails the PDF rather than quietly skipping the
+// signature clients may require.
+func (app *application) signInvoicePDF(invoiceID int, pdfBytes []byte) ([]byte, error) {
+	certificatePath, err := app.settings.GetString("pdf_signing_certificate")
+	if err != nil || certificatePath == "" {
+		return pdfBytes, err
+	}
+	password, err := app.settings.GetString("pdf_signing_password")
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := signing.LoadPKCS12(certificatePath, password)
+	if err != nil {
+		return nil, fmt.Errorf("loading PDF signing certificate: %w", err)
+	}
+	invoice, err := app.invoices.Get(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := fmt.Sprintf("Invoice %d", invoice.ID)
+	if invoice.InvoiceNumber != "" {
+		reason = "Invoice " + invoice.InvoiceNumber
+	}
+	signedAt := time.Now()
+	signed, err := signer.SignPDF(pdfBytes, reason, signedAt)
+	if err != nil {
+		return nil, fmt.Errorf("signing invoice PDF: %w", err)
+	}
+	if err := app.invoices.SetPDFSignature(invoiceID, signer.Name(), signedAt); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// invoiceEmail displays the form for emailing an invoice, along with every attempt to email it so far
+func (app *application) invoiceEmail(res http.ResponseWriter, req *http.Request) {
+	invoice, project, client, ok := app.invoiceForEmail(res, req)
+	if !ok {
+		return
+	}
+
+	from, err := app.invoiceEmailFrom(project, client)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	sender := senderName(from)
+
+	message := fmt.Sprintf("Please find attached invoice #%s for $%.2f", invoice.DisplayNumber(), invoice.AmountDue)
+	if invoice.DueDate != nil {
+		message += ", due " + app.dateFormat(req).Format(*invoice.DueDate)
+	}
+	form := invoiceEmailForm{
+		To:      client.Email,
+		CC:      models.ResolveInvoiceCC(invoice, project, client).Email,
+		Subject: fmt.Sprintf("Invoice #%s from %s", invoice.DisplayNumber(), sender),
+		Message: message + ".\n\nThank you,\n" + sender,
+	}
+	app.renderInvoiceEmail(res, req, http.StatusOK, invoice, project, form)
+}
+
+// invoiceEmailPost handles a POST request to email an invoice. The PDF is generated and sent
+// in the background, recording the attempt in the invoice's delivery log.
+func (app *application) invoiceEmailPost(res http.ResponseWriter, req *http.Request) {
+	invoice, project, client, ok := app.invoiceForEmail(res, req)
+	if !ok {
+		return
+	}
+
+	var form invoiceEmailForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	host, err := app.settings.GetString("smtp_host")
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	form.CheckField(host != "", "smtp_host", "Set an SMTP server in settings before emailing invoices")
+
+	to := splitAddresses(form.To)
+	cc := splitAddresses(form.CC)
+	form.CheckField(len(to) > 0, "to", "At least one recipient is required")
+	for _, address := range to {
+		form.CheckField(validator.Matches(strings.ToLower(address), validator.EmailRegex), "to", fmt.Sprintf("%s is not a valid email address", address))
+	}
+	for _, address := range cc {
+		form.CheckField(validator.Matches(strings.ToLower(address), validator.EmailRegex), "cc", fmt.Sprintf("%s is not a valid email address", address))
+	}
+	form.CheckField(validator.NotBlank(form.Subject), "subject", "Subject is required")
+	form.CheckField(validator.MaxChars(form.Subject, NAME_LENGTH), "subject", fmt.Sprintf("Subject must be shorter than %d characters", NAME_LENGTH))
+
+	attachments, err := app.projectAttachments.GetByProject(project.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	// The invoice PDF and any timesheet report are only sized when they are generated, so
+	// the files chosen are checked here and everything again before it is sent
+	var size int64
+	for _, id := range form.Attachments {
+		i := slices.IndexFunc(attachments, func(a models.ProjectAttachment) bool { return a.ID == id })
+		if i < 0 {
+			form.AddFieldError("attachment", "Attachments must belong to the invoice's project")
+			break
+		}
+		size += attachments[i].Size
+	}
+	form.CheckField(size <= maxEmailAttachmentBytes, "attachment", fmt.Sprintf("Attachments total %s, more than the %s an email can carry", models.FormatFileSize(size), models.FormatFileSize(maxEmailAttachmentBytes)))
+	if form.TimesheetReport {
+		_, _, ok, err := app.invoiceTimesheetPeriod(invoice)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		form.CheckField(ok, "timesheet_report", "There are no timesheets on this invoice to report")
+	}
+
+	if !form.Valid() {
+		app.renderInvoiceEmail(res, req, http.StatusUnprocessableEntity, invoice, project, form)
+		return
+	}
+
+	from, err := app.invoiceEmailFrom(project, client)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	_, err = app.queue.Enqueue(models.JobInvoiceEmail, invoiceEmailJobPayload{
+		InvoiceID:       invoice.ID,
+		From:            from,
+		To:              to,
+		Cc:              cc,
+		Subject:         strings.TrimSpace(form.Subject),
+		Message:         form.Message,
+		TrackingURL:     app.absoluteURL(req, "/email/open/"),
+		AttachmentIDs:   form.Attachments,
+		TimesheetReport: form.TimesheetReport,
+	})
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("Invoice #%s is being sent to %s", invoice.DisplayNumber(), strings.Join(append(to, cc...), ", ")))
+	app.redirect(res, req, fmt.Sprintf("/invoice/email/%d", invoice.ID), http.StatusSeeOther)
+}
+
+// invoiceForEmail loads the invoice named in the request path with its project and client,
+// responding with an error when it doesn't exist or can no longer be sent
+func (app *application) invoiceForEmail(res http.ResponseWriter, req *http.Request) (models.Invoice, models.Project, models.Client, bool) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return models.Invoice{}, models.Project{}, models.Client{}, false
+	}
+
+	invoice, err := app.invoices.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return models.Invoice{}, models.Project{}, models.Client{}, false
+	}
+
+	// Voided invoices are no longer owed, so there is nothing to send
+	if invoice.IsVoided() {
+		app.clientError(res, http.StatusConflict)
+		return models.Invoice{}, models.Project{}, models.Client{}, false
+	}
+
+	project, err := app.projects.Get(invoice.ProjectID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return models.Invoice{}, models.Project{}, models.Client{}, false
+	}
+	client, err := app.clients.Get(project.ClientID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return models.Invoice{}, models.Project{}, models.Client{}, false
+	}
+	return invoice, project, client, true
+}
+
+// renderInvoiceEmail renders the invoice email page with the invoice's delivery log
+func (app *application) renderInvoiceEmail(res http.ResponseWriter, req *http.Request, status int, invoice models.Invoice, project models.Project, form invoiceEmailForm) {
+	deliveries, err := app.deliveries.GetByInvoice(invoice.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	host, err := app.settings.GetString("smtp_host")
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	attachments, err := app.projectAttachments.GetByProject(project.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	summary := models.SummarizeDeliveries(deliveries)
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.Invoice = &invoice
+	data.Project = &project
+	data.ProjectAttachments = attachments
+	data.InvoiceDeliveries = deliveries
+	data.DeliverySummary = &summary
+	data.SMTPConfigured = host != ""
+	app.render(res, req, status, "invoice_email.html", data)
+}
+
+// deliverySummary totals the attempts to email an invoice
+func (app *application) deliverySummary(invoiceID int) (*models.DeliverySummary, error) {
+	deliveries, err := app.deliveries.GetByInvoice(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	summary := models.SummarizeDeliveries(deliveries)
+	return &summary, nil
+}
+
+// invoiceEmailFrom returns the address invoices of a project are emailed from: that of the
+// project's business profile when it has one, otherwise the freelancer in settings
+func (app *application) invoiceEmailFrom(project models.Project, client models.Client) (string, error) {
+	profile, err := app.invoiceBusinessProfile(project, client)
+	if err != nil {
+		return "", err
+	}
+	if profile != nil && profile.FreelancerEmail != "" {
+		return (&mail.Address{Name: profile.FreelancerName, Address: profile.FreelancerEmail}).String(), nil
+	}
+
+	name, err := app.settings.GetString("freelancer_name")
+	if err != nil {
+		return "", err
+	}
+	email, err := app.settings.GetString("freelancer_email")
+	if err != nil {
+		return "", err
+	}
+	return (&mail.Address{Name: name, Address: email}).String(), nil
+}
+
+// senderName returns the name in an email address to sign emails with, or the address itself
+// when it has no name
+func senderName(from string) string {
+	if address, err := mail.ParseAddress(from); err == nil && address.Name != "" {
+		return address.Name
+	}
+	return from
+}
+
+// splitAddresses splits a list of email addresses separated by commas or semicolons
+func splitAddresses(list string) []string {
+	var addresses []string
+	for _, address := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ';' }) {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// invoiceEmailJobPayload is an invoice email to send. TrackingURL is where the image recording
+// opens is served, to which the delivery's tracking token is appended. AttachmentIDs are the
+// project attachments sent along with the invoice PDF, and TimesheetReport adds a report of
+// the timesheets on the invoice.
+type invoiceEmailJobPayload struct {
+	InvoiceID       int      `json:"invoice_id"`
+	From            string   `json:"from"`
+	To              []string `json:"to"`
+	Cc              []string `json:"cc"`
+	Subject         string   `json:"subject"`
+	Message         string   `json:"message"`
+	TrackingURL     string   `json:"tracking_url"`
+	AttachmentIDs   []int    `json:"attachment_ids,omitempty"`
+	TimesheetReport bool     `json:"timesheet_report,omitempty"`
+}
+
+// delivery returns the log entry for an attempt to send the email, as yet unsent
+func (p invoiceEmailJobPayload) delivery() models.InvoiceDelivery {
+	return models.InvoiceDelivery{
+		InvoiceID:  p.InvoiceID,
+		Recipients: strings.Join(append(append([]string{}, p.To...), p.Cc...), ", "),
+		Subject:    p.Subject,
+		Status:     models.DeliveryStatusFailed,
+	}
+}
+
+// invoiceEmailJob emails an invoice with its PDF attached for the job queue, logging the
+// attempt with the mail server's reply whether or not it succeeds
+func (app *application) invoiceEmailJob(ctx context.Context, payload []byte) (jobs.Result, error) {
+	var params invoiceEmailJobPayload
+	err := json.Unmarshal(payload, &params)
+	if err != nil {
+		return jobs.Result{}, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	pdfBytes, err := app.invoicePDF(params.InvoiceID)
+	if err != nil {
+		return jobs.Result{}, app.recordFailedDelivery(params.delivery(), "", err)
+	}
+	attachments, err := app.invoiceEmailAttachments(params, pdfBytes)
+	if err != nil {
+		return jobs.Result{}, app.recordFailedDelivery(params.delivery(), "", err)
+	}
+	return jobs.Result{}, app.sendInvoiceEmail(ctx, params, attachments)
+}
+
+// invoiceEmailAttachments gathers the files an invoice email carries: the invoice's PDF, then
+// the timesheet report and the project attachments when they were asked for
+func (app *application) invoiceEmailAttachments(params invoiceEmailJobPayload, pdfBytes []byte) ([]mailer.Attachment, error) {
+	attachments := []mailer.Attachment{{
+		Filename:    fmt.Sprintf("invoice_%d.pdf", params.InvoiceID),
+		ContentType: "application/pdf",
+		Data:        pdfBytes,
+	}}
+
+	if params.TimesheetReport {
+		invoice, err := app.invoices.Get(params.InvoiceID)
+		if err != nil {
+			return nil, err
+		}
+		start, end, ok, err := app.invoiceTimesheetPeriod(invoice)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			report, filename, err := app.timesheetReportPDF(timesheetReportJobPayload{
+				ProjectID: invoice.ProjectID,
+				Start:     start.Format("2006-01-02"),
+				End:       end.Format("2006-01-02"),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("generating timesheet report: %w", err)
+			}
+			attachments = append(attachments, mailer.Attachment{Filename: filename, ContentType: "application/pdf", Data: report})
+		}
+	}
+
+	for _, id := range params.AttachmentIDs {
+		attachment, err := app.projectAttachments.Get(id)
+		if err != nil {
+			return nil, fmt.Errorf("attachment %d: %w", id, err)
+		}
+		data, err := os.ReadFile(attachment.Path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", attachment.Filename, err)
+		}
+		attachments = append(attachments, mailer.Attachment{Filename: attachment.Filename, ContentType: attachment.ContentType, Data: data})
+	}
+	return attachments, nil
+}
+
+// invoiceTimesheetPeriod returns the first and last days worked among the timesheets listed on
+// an invoice, which a timesheet report sent with it covers. ok is false when it lists none.
+func (app *application) invoiceTimesheetPeriod(invoice models.Invoice) (start, end time.Time, ok bool, err error) {
+	timesheets, err := app.timesheets.GetByProject(invoice.ProjectID)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+	for _, timesheet := range timesheets {
+		if !invoice.IncludesWorkDate(timesheet.WorkDate) {
+			continue
+		}
+		if !ok || timesheet.WorkDate.Before(start) {
+			start = timesheet.WorkDate
+		}
+		if !ok || timesheet.WorkDate.After(end) {
+			end = timesheet.WorkDate
+		}
+		ok = true
+	}
+	return start, end, ok, nil
+}
+
+// sendInvoiceEmail sends an invoice email with the given files attached, the invoice's PDF
+// first, and logs the attempt. An email whose attachments are too large for mail servers to
+// accept is logged as failed without being sent. When open tracking is on, the message is also
+// sent as HTML with an image recording opens.
+func (app *application) sendInvoiceEmail(ctx context.Context, params invoiceEmailJobPayload, attachments []mailer.Attachment) error {
+	delivery := params.delivery()
+	var size int64
+	for _, attachment := range attachments {
+		size += int64(len(attachment.Data))
+	}
+	if size > maxEmailAttachmentBytes {
+		return app.recordFailedDelivery(delivery, "", fmt.Errorf("attachments total %s, more than the %s an email can carry",
+			models.FormatFileSize(size), models.FormatFileSize(maxEmailAttachmentBytes)))
+	}
+
+	msg := mailer.Message{
+		From:        params.From,
+		To:          params.To,
+		Cc:          params.Cc,
+		Subject:     params.Subject,
+		Text:        params.Message,
+		Attachments: attachments,
+	}
+
+	tracking, err := app.settings.GetBool("email_open_tracking")
+	if err != nil {
+		return err
+	}
+	if tracking && params.TrackingURL != "" {
+		delivery.TrackingToken, err = models.NewTrackingToken()
+		if err != nil {
+			return err
+		}
+		msg.HTML = invoiceEmailHTML(params.Message, params.TrackingURL+delivery.TrackingToken)
+	}
+
+	sender, err := app.emailSender()
+	if err != nil {
+		return app.recordFailedDelivery(delivery, "", err)
+	}
+	response, err := sender.Send(ctx, msg)
+	if err != nil {
+		return app.recordFailedDelivery(delivery, response, err)
+	}
+
+	delivery.Status = models.DeliveryStatusSent
+	delivery.SMTPResponse = response
+	_, err = app.deliveries.Record(delivery)
+	return err
+}
+
+// overdueInvoices handles a GET request which lists the invoices past their due date, for
+// picking those to email reminders of
+func (app *application) overdueInvoices(res http.ResponseWriter, req *http.Request) {
+	app.renderOverdueInvoices(res, req, http.StatusOK, invoiceRemindersForm{})
+}
+
+// invoiceRemindersPost handles a POST request to email reminders of the overdue invoices
+// picked. They are sent by a job whose result lists what became of each.
+func (app *application) invoiceRemindersPost(res http.ResponseWriter, req *http.Request) {
+	var form invoiceRemindersForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	host, err := app.settings.GetString("smtp_host")
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	form.CheckField(host != "", "smtp_host", "Set an SMTP server in settings before emailing reminders")
+	form.CheckField(len(form.Invoices) > 0, "invoice", "Choose at least one invoice to send a reminder of")
+
+	if !form.Valid() {
+		app.renderOverdueInvoices(res, req, http.StatusUnprocessableEntity, form)
+		return
+	}
+
+	jobID, err := app.queue.Enqueue(models.JobInvoiceReminders, invoiceRemindersJobPayload{
+		InvoiceIDs:  form.Invoices,
+		TrackingURL: app.absoluteURL(req, "/email/open/"),
+	})
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	app.redirect(res, req, fmt.Sprintf("/job/view/%d", jobID), http.StatusSeeOther)
+}
+
+// renderOverdueInvoices renders the overdue invoices page, marking those emailed too recently
+// to be reminded of again
+func (app *application) renderOverdueInvoices(res http.ResponseWriter, req *http.Request, status int, form invoiceRemindersForm) {
+	invoices, err := app.invoices.GetOpen()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	host, err := app.settings.GetString("smtp_host")
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	now := time.Now()
+	interval := app.reminderInterval()
+	overdue := models.OverdueInvoices(invoices, now)
+	recent := make(map[int]bool)
+	for i := range overdue {
+		summary, err := app.deliverySummary(overdue[i].ID)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		overdue[i].LastEmailed = summary.LastSent
+		recent[overdue[i].ID] = overdue[i].RemindedRecently(now, interval)
+	}
+
+	data := app.newTemplateData(req)
+	data.OverdueInvoices = overdue
+	data.RecentlyReminded = recent
+	data.ReminderInterval = interval
+	data.SMTPConfigured = host != ""
+	data.Form = form
+	app.render(res, req, status, "invoices_overdue.html", data)
+}
+
+// invoiceRemindersJobPayload lists the invoices to email reminders of. TrackingURL is as for
+// invoiceEmailJobPayload.
+type invoiceRemindersJobPayload struct {
+	InvoiceIDs  []int  `json:"invoice_ids"`
+	TrackingURL string `json:"tracking_url"`
+}
+
+// What became of the reminder of one invoice
+const (
+	reminderSent    = "sent"
+	reminderFailed  = "failed"
+	reminderSkipped = "skipped"
+)
+
+// invoiceRemindersJob emails each invoice in the payload that is still overdue to its client
+// again, as a reminder with the invoice PDF attached. Invoices emailed within the days of the
+// invoice_reminder_interval_days setting are skipped. Every attempt is logged with the
+// invoice's deliveries, and the result lists what became of each invoice.
+func (app *application) invoiceRemindersJob(ctx context.Context, payload []byte) (jobs.Result, error) {
+	var params invoiceRemindersJobPayload
+	err := json.Unmarshal(payload, &params)
+	if err != nil {
+		return jobs.Result{}, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	dateFormatSetting, err := app.settings.GetString("date_format")
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		return jobs.Result{}, err
+	}
+	dateFormat := models.ParseDateFormat(dateFormatSetting)
+	interval := app.reminderInterval()
+
+	counts := make(map[string]int)
+	var details strings.Builder
+	for _, id := range params.InvoiceIDs {
+		outcome, detail := app.sendInvoiceReminder(ctx, id, params.TrackingURL, interval, dateFormat)
+		counts[outcome]++
+		fmt.Fprintln(&details, detail)
+	}
+
+	summary := fmt.Sprintf("Sent %d, failed %d, skipped %d\n\n%s",
+		counts[reminderSent], counts[reminderFailed], counts[reminderSkipped], details.String())
+	return jobs.Result{
+		Data:        []byte(summary),
+		ContentType: "text/plain; charset=utf-8",
+		Filename:    "invoice_reminders.txt",
+	}, nil
+}
+
+// sendInvoiceReminder emails a reminder of one overdue invoice to its client, returning
+// whether it was sent, failed or skipped along with a line saying what happened
+func (app *application) sendInvoiceReminder(ctx context.Context, id int, trackingURL string, interval int, dateFormat models.DateFormat) (string, string) {
+	invoice, err := app.invoices.GetWithClient(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			return reminderSkipped, fmt.Sprintf("Invoice %d: skipped, it no longer exists", id)
+		}
+		return reminderFailed, fmt.Sprintf("Invoice %d: failed, %s", id, err)
+	}
+	label := fmt.Sprintf("Invoice #%s to %s", invoice.DisplayNumber(), invoice.ClientName)
+	failed := func(err error) (string, string) {
+		return reminderFailed, fmt.Sprintf("%s: failed, %s", label, err)
+	}
+
+	now := time.Now()
+	overdue := models.OverdueInvoices([]models.InvoiceWithClient{invoice}, now)
+	if len(overdue) == 0 {
+		return reminderSkipped, label + ": skipped, it isn't overdue"
+	}
+	summary, err := app.deliverySummary(id)
+	if err != nil {
+		return failed(err)
+	}
+	overdue[0].LastEmailed = summary.LastSent
+	if overdue[0].RemindedRecently(now, interval) {
+		return reminderSkipped, fmt.Sprintf("%s: skipped, reminded recently on %s", label, dateFormat.Format(*summary.LastSent))
+	}
+
+	project, err := app.projects.Get(invoice.ProjectID)
+	if err != nil {
+		return failed(err)
+	}
+	client, err := app.clients.Get(invoice.ClientID)
+	if err != nil {
+		return failed(err)
+	}
+	to := splitAddresses(client.Email)
+	if len(to) == 0 {
+		return failed(errors.New("the client has no email address"))
+	}
+	from, err := app.invoiceEmailFrom(project, client)
+	if err != nil {
+		return failed(err)
+	}
+
+	email := invoiceEmailJobPayload{
+		InvoiceID: id,
+		From:      from,
+		To:        to,
+		Cc:        splitAddresses(models.ResolveInvoiceCC(invoice.Invoice, project, client).Email),
+		Subject:   fmt.Sprintf("Reminder: invoice #%s from %s is overdue", invoice.DisplayNumber(), senderName(from)),
+		Message: fmt.Sprintf("This is a reminder that invoice #%s was due on %s and $%.2f of it is still outstanding. Please find the invoice attached.\n\nThank you,\n%s",
+			invoice.DisplayNumber(), dateFormat.Format(overdue[0].DueOn), overdue[0].Outstanding, senderName(from)),
+		TrackingURL: trackingURL,
+	}
+	pdfBytes, err := app.invoicePDF(id)
+	if err != nil {
+		return failed(app.recordFailedDelivery(email.delivery(), "", err))
+	}
+	attachments, err := app.invoiceEmailAttachments(email, pdfBytes)
+	if err != nil {
+		return failed(app.recordFailedDelivery(email.delivery(), "", err))
+	}
+	if err := app.sendInvoiceEmail(ctx, email, attachments); err != nil {
+		return failed(err)
+	}
+	return reminderSent, fmt.Sprintf("%s: sent to %s", label, strings.Join(append(email.To, email.Cc...), ", "))
+}
+
+// recordFailedDelivery logs an attempt to email an invoice that failed, with the mail server's
+// reply or, when it never got that far, the error. It returns the error the attempt failed with.
+func (app *application) recordFailedDelivery(delivery models.InvoiceDelivery, response string, cause error) error {
+	delivery.Status = models.DeliveryStatusFailed
+	delivery.SMTPResponse = response
+	if response == "" {
+		delivery.SMTPResponse = cause.Error()
+	}
+	if _, err := app.deliveries.Record(delivery); err != nil {
+		return errors.Join(cause, err)
+	}
+	return cause
+}
+
+// errNoSMTPServer is returned by emailSender when no SMTP server is set in settings
+var errNoSMTPServer = errors.New("no SMTP server is set in settings")
+
+// emailSender returns the sender invoices are emailed through: the SMTP server in settings,
+// unless the application was given one, as tests are
+func (app *application) emailSender() (mailer.Sender, error) {
+	if app.mailer != nil {
+		return app.mailer, nil
+	}
+
+	host, err := app.settings.GetString("smtp_host")
+	if err != nil {
+		return nil, err
+	}
+	if host == "" {
+		return nil, errNoSMTPServer
+	}
+	port, err := app.settings.GetInt("smtp_port")
+	if err != nil {
+		return nil, err
+	}
+	username, err := app.settings.GetString("smtp_username")
+	if err != nil {
+		return nil, err
+	}
+	password, err := app.settings.GetString("smtp_password")
+	if err != nil {
+		return nil, err
+	}
+	return mailer.SMTP{Host: host, Port: port, Username: username, Password: password}, nil
+}
+
+// invoiceEmailHTML formats an invoice email's message as HTML, ending with the invisible image
+// that records when it is opened
+func invoiceEmailHTML(message, trackingURL string) string {
+	body := strings.ReplaceAll(html.EscapeString(message), "\n", "<br>\n")
+	return fmt.Sprintf("<!DOCTYPE html>\n<html><body>\n<p>%s</p>\n<img src=\"%s\" width=\"1\" height=\"1\" alt=\"\">\n</body></html>\n",
+		body, html.EscapeString(trackingURL))
+}
+
+// transparentGIF is a 1x1 transparent image
+var transparentGIF = []byte("GIF89a\x01\x00\x01\x00\x80\x00\x00\x00\x00\x00\xff\xff\xff!\xf9\x04\x01\x00\x00\x00\x00,\x00\x00\x00\x00\x01\x00\x01\x00\x00\x02\x02D\x01\x00;")
+
+// emailOpen records that an invoice email was opened, when its tracking image is loaded. The
+// image is returned whatever the token, so a mail program never shows it as broken.
+func (app *application) emailOpen(res http.ResponseWriter, req *http.Request) {
+	err := app.deliveries.RecordOpen(req.PathValue("token"), time.Now())
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.logger.Error("recording invoice email open", "error", err.Error())
+	}
+
+	res.Header().Set("Content-Type", "image/gif")
+	res.Header().Set("Cache-Control", "no-store, max-age=0")
+	res.Write(transparentGIF)
+}
+
+// jobStatusResponse is the JSON returned while polling a job
+type jobStatusResponse struct {
+	ID          int    `json:"id"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// getJob loads the job named in the request path, responding with a 404 when there isn't one
+func (app *application) getJob(res http.ResponseWriter, req *http.Request) (models.Job, bool) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return models.Job{}, false
+	}
+
+	job, err := app.jobs.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return models.Job{}, false
+	}
+	return job, true
+}
+
+// recentJobsShown is how many of the latest jobs the jobs page lists
+const recentJobsShown = 50
+
+// jobList handles a GET request for the jobs page, which shows when each scheduled task last
+// ran and runs next, and the latest jobs with any failures
+func (app *application) jobList(res http.ResponseWriter, req *http.Request) {
+	tasks, err := app.scheduler.Status(time.Now())
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	recent, err := app.jobs.ListRecent(recentJobsShown)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.ScheduledTasks = tasks
+	data.RecentJobs = recent
+	app.render(res, req, http.StatusOK, "jobs.html", data)
+}
+
+// jobView handles a GET request to display a job, which waits for it to finish
+func (app *application) jobView(res http.ResponseWriter, req *http.Request) {
+	job, ok := app.getJob(res, req)
+	if !ok {
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Job = &job
+	app.render(res, req, http.StatusOK, "job.html", data)
+}
+
+// jobStatus handles a GET request polling the progress of a job
+func (app *application) jobStatus(res http.ResponseWriter, req *http.Request) {
+	job, ok := app.getJob(res, req)
+	if !ok {
+		return
+	}
+
+	status := jobStatusResponse{ID: job.ID, Status: job.Status, Error: job.Error}
+	if job.Status == models.JobStatusDone {
+		status.DownloadURL = fmt.Sprintf("%s/job/download/%d", app.basePath, job.ID)
+	}
+	app.writeJSON(res, req, http.StatusOK, status)
+}
+
+// jobDownload handles a GET request to download the result of a finished job
+func (app *application) jobDownload(res http.ResponseWriter, req *http.Request) {
+	job, ok := app.getJob(res, req)
+	if !ok {
+		return
+	}
+
+	if job.Status != models.JobStatusDone {
+		app.clientError(res, http.StatusConflict)
+		return
+	}
+
+	res.Header().Set("Content-Type", job.ResultType)
+	res.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", job.ResultName))
+	res.Header().Set("Content-Length", fmt.Sprintf("%d", len(job.Result)))
+
+	_, err := res.Write(job.Result)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+}
+
+// settingsView handles a GET request to view all application settings
+func (app *application) settingsView(res http.ResponseWriter, req *http.Request) {
+	settings, err := app.settings.GetAllDetailed()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	logoPath, err := app.companyLogoPath()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Settings = settings
+	data.LogoPath = logoPath
+	data.Form = settingsForm{}
+
+	app.render(res, req, http.StatusOK, "settings.html", data)
+}
+
+// invoiceTemplateHelp handles a GET request which documents the fields and functions the
+// invoice template can use, with their values for a sample invoice
+func (app *application) invoiceTemplateHelp(res http.ResponseWriter, req *http.Request) {
+	allSettings, err := app.settings.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.TemplateFields = models.DescribeTemplateData(models.NewInvoiceTemplateData(models.SampleInvoiceData(), allSettings))
+	data.TemplateFuncs = models.InvoiceTemplateFuncs()
+	app.render(res, req, http.StatusOK, "invoice_template_help.html", data)
+}
+
+// invoiceTemplateSample handles a GET request which renders the invoice template with the
+// sample invoice, for previewing on the help page
+func (app *application) invoiceTemplateSample(res http.ResponseWriter, req *http.Request) {
+	allSettings, err := app.settings.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	html, err := models.RenderInvoiceHTML(models.NewInvoiceTemplateData(models.SampleInvoiceData(), allSettings))
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	// The invoice carries its own styles and embeds the logo as a data URL, and is shown in a
+	// frame on the help page
+	res.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; img-src data:; frame-ancestors 'self'")
+	res.Header().Set("X-Frame-Options", "sameorigin")
+	res.Header().Set("Content-Type", "text/html; charset=utf-8")
+	res.Write(html)
+}
+
+// companyLogoPath returns the company_logo_path setting, which is blank when no logo is configured
+func (app *application) companyLogoPath() (string, error) {
+	logoPath, err := app.settings.GetString("company_logo_path")
+	if errors.Is(err, models.ErrNoRecord) {
+		return "", nil
+	}
+	return logoPath, err
+}
+
+// settingsNotifyTestPost handles a POST request which sends a test message to the Slack
+// webhook and Telegram bot in settings, so they can be checked before a reminder is due
+func (app *application) settingsNotifyTestPost(res http.ResponseWriter, req *http.Request) {
+	notifier, err := app.chatNotifier()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	if notifier == nil {
+		app.flash(req, "Set a Slack webhook URL or a Telegram bot token and chat ID in settings first")
+		app.redirect(res, req, "/settings", http.StatusSeeOther)
+		return
+	}
+
+	err = notifier.Notify(req.Context(), "FreelanceTracker test alert: overdue invoice and deadline reminders will be sent here")
+	if err != nil {
+		app.flash(req, "Sending the test alert failed: "+err.Error())
+	} else {
+		app.flash(req, "Test alert sent")
+	}
+	app.redirect(res, req, "/settings", http.StatusSeeOther)
+}
+
+// settingsLogoPost handles an upload of a new company logo. The image is checked, stored
+// and the company_logo_path setting pointed at it so that it appears on invoices.
+func (app *application) settingsLogoPost(res http.ResponseWriter, req *http.Request) {
+	var form settingsForm
+
+	req.Body = http.MaxBytesReader(res, req.Body, maxLogoBytes+64*1024)
+	err := req.ParseMultipartForm(maxLogoBytes)
+	if err != nil {
+		form.AddFieldError("logo", fmt.Sprintf("Logo must be smaller than %d KB", maxLogoBytes/1024))
+	}
+
+	var data []byte
+	var ext string
+	if form.Valid() {
+		file, header, err := req.FormFile("logo")
+		if err != nil {
+			form.AddFieldError("logo", "Choose a logo to upload")
+		} else {
+			defer file.Close()
+			data, err = io.ReadAll(io.LimitReader(file, maxLogoBytes+1))
+			if err != nil {
+				app.serverError(res, req, err)
+				return
+			}
+			ext, err = checkLogoImage(header.Filename, data)
+			if err != nil {
+				form.AddFieldError("logo", err.Error())
+			}
+		}
+	}
+
+	var low *storage.LowSpace
+	if form.Valid() {
+		low, err = app.checkDiskSpace(int64(len(data)))
+		if err != nil {
+			form.AddFieldError("logo", "The logo wasn't uploaded: "+diskSpaceMessage(low))
+		}
+	}
+
+	if !form.Valid() {
+		settings, err := app.settings.GetAllDetailed()
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		logoPath, err := app.companyLogoPath()
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		data := app.newTemplateData(req)
+		data.Settings = settings
+		data.LogoPath = logoPath
+		data.Form = form
+		app.render(res, req, http.StatusUnprocessableEntity, "settings.html", data)
+		return
+	}
+
+	// Name the file after its contents so a new logo never reuses a cached URL
+	sum := sha256.Sum256(data)
+	path, err := app.uploads.Save("logo-"+hex.EncodeToString(sum[:8])+ext, data)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	err = app.settings.UpdateValue("company_logo_path", path)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	message := "Company logo uploaded"
+	if low != nil {
+		message += ", but " + low.String()
+	}
+	app.flash(req, message)
+	app.redirect(res, req, "/settings", http.StatusSeeOther)
+}
+
+// settingsLogo serves the configured company logo so that it can be previewed
+func (app *application) settingsLogo(res http.ResponseWriter, req *http.Request) {
+	logoPath, err := app.companyLogoPath()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	if logoPath == "" {
+		http.NotFound(res, req)
+		return
+	}
+
+	// SVG logos may contain script, so never let the browser run anything from them
+	res.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	res.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeFile(res, req, logoPath)
+}
+
+// migrationsData loads the migration status shown on the admin migrations page
+func (app *application) migrationsData(req *http.Request) (templateData, error) {
+	migrations, err := database.MigrationStatus(app.db, app.migrationsDir)
+	if err != nil {
+		return templateData{}, err
+	}
+	size, err := database.DatabaseSize(req.Context(), app.db)
+	if err != nil {
+		return templateData{}, err
+	}
+	data := app.newTemplateData(req)
+	data.Migrations = migrations
+	data.Maintenance = app.maintenance.Load()
+	data.DatabaseSize = &size
+	data.MaintenanceTasks = database.MaintenanceTasks
+	return data, nil
+}
+
+// adminMigrations handles a GET request which lists every migration and whether it has
+// been applied
+func (app *application) adminMigrations(res http.ResponseWriter, req *http.Request) {
+	data, err := app.migrationsData(req)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	data.Form = migrateForm{Steps: 1}
+	app.render(res, req, http.StatusOK, "admin_migrations.html", data)
+}
+
+// adminMigrationsUpPost applies the pending migrations, or lists them when a dry run is asked for
+func (app *application) adminMigrationsUpPost(res http.ResponseWriter, req *http.Request) {
+	var form migrateForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	app.runMigrationsForm(res, req, form, database.MigrateOptions{DryRun: form.DryRun})
+}
+
+// adminMigrationsDownPost rolls back the given number of the most recently applied
+// migrations, or lists them when a dry run is asked for
+func (app *application) adminMigrationsDownPost(res http.ResponseWriter, req *http.Request) {
+	var form migrateForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(form.Steps > 0, "steps", "Roll back at least one migration")
+	if !form.Valid() {
+		data, err := app.migrationsData(req)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		data.Form = form
+		app.render(res, req, http.StatusUnprocessableEntity, "admin_migrations.html", data)
+		return
+	}
+
+	app.runMigrationsForm(res, req, form, database.MigrateOptions{DryRun: form.DryRun, Down: form.Steps})
+}
+
+// runMigrationsForm runs the migrations asked for on the admin migrations page. A dry run
+// shows the migrations that would run on the page, while a real run reports what it did
+// and returns to the page.
+func (app *application) runMigrationsForm(res http.ResponseWriter, req *http.Request, form migrateForm, opts database.MigrateOptions) {
+	migrations, err := app.migrate(opts)
+	if err != nil {
+		if errors.Is(err, errMigrationsRunning) {
+			app.clientError(res, http.StatusConflict)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	if opts.DryRun {
+		data, err := app.migrationsData(req)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		data.Form = form
+		data.PlannedMigrations = migrations
+		data.RollingBack = opts.Down > 0
+		app.render(res, req, http.StatusOK, "admin_migrations.html", data)
+		return
+	}
+
+	if opts.Down > 0 {
+		app.flash(req, fmt.Sprintf("Rolled back %d migrations, maintenance mode stays on until they are applied again", len(migrations)))
+	} else {
+		app.flash(req, fmt.Sprintf("Applied %d migrations", len(migrations)))
+	}
+	app.redirect(res, req, "/admin/migrations", http.StatusSeeOther)
+}
+
+// adminMaintenancePost switches maintenance mode on or off
+func (app *application) adminMaintenancePost(res http.ResponseWriter, req *http.Request) {
+	if app.maintenance.Load() {
+		app.maintenance.Store(false)
+		app.logger.Info("maintenance mode off")
+		app.flash(req, "Maintenance mode off, the application is open again")
+	} else {
+		app.maintenance.Store(true)
+		app.logger.Info("maintenance mode on")
+		app.flash(req, "Maintenance mode on, other pages show the maintenance notice")
+	}
+	app.redirect(res, req, "/admin/migrations", http.StatusSeeOther)
+}
+
+// adminDatabasePost queues the maintenance tasks picked on the admin page and shows the job
+// running them
+func (app *application) adminDatabasePost(res http.ResponseWriter, req *http.Request) {
+	var form databaseMaintenanceForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := database.ParseMaintenanceTasks(strings.Join(form.Tasks, ","))
+	if err != nil {
+		app.flash(req, "Choose at least one maintenance task to run")
+		app.redirect(res, req, "/admin/migrations", http.StatusSeeOther)
+		return
+	}
+
+	jobID, err := app.queue.Enqueue(models.JobDatabaseMaintenance, databaseMaintenanceJobPayload{Tasks: tasks})
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	app.redirect(res, req, fmt.Sprintf("/job/view/%d", jobID), http.StatusSeeOther)
+}
+
+// databaseMaintenanceJobPayload lists the tasks a database maintenance job runs
+type databaseMaintenanceJobPayload struct {
+	Tasks []database.MaintenanceTask `json:"tasks"`
+}
+
+// databaseMaintenanceJob runs maintenance tasks on the database for the job queue, logging
+// each as it finishes. The result lists what each task did.
+func (app *application) databaseMaintenanceJob(ctx context.Context, payload []byte) (jobs.Result, error) {
+	var params databaseMaintenanceJobPayload
+	err := json.Unmarshal(payload, &params)
+	if err != nil {
+		return jobs.Result{}, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	var summary strings.Builder
+	_, err = database.Maintain(ctx, app.db, params.Tasks, func(step database.MaintenanceStep) {
+		app.logger.Info("database maintenance", "task", step.Task, "detail", step.Detail, "before", step.Before.Bytes, "after", step.After.Bytes, "duration", step.Duration)
+		fmt.Fprintln(&summary, step)
+	})
+	if err != nil {
+		return jobs.Result{}, err
+	}
+
+	return jobs.Result{
+		Data:        []byte(summary.String()),
+		ContentType: "text/plain; charset=utf-8",
+		Filename:    "database_maintenance.txt",
+	}, nil
+}
+
+// renderIntegrity runs the integrity check and renders its report with the given repair form
+func (app *application) renderIntegrity(res http.ResponseWriter, req *http.Request, status int, form integrityRepairForm) {
+	report, err := app.integrity.Check()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	data := app.newTemplateData(req)
+	data.Integrity = &report
+	data.Form = form
+	app.render(res, req, status, "admin_integrity.html", data)
+}
+
+// adminIntegrity handles a GET request which checks the database file for corruption and
+// lists rows left pointing at missing or deleted parents
+func (app *application) adminIntegrity(res http.ResponseWriter, req *http.Request) {
+	app.renderIntegrity(res, req, http.StatusOK, integrityRepairForm{})
+}
+
+// adminIntegrityRepairPost fixes the rows one orphan check found, by reattaching them to
+// another parent or soft deleting them
+func (app *application) adminIntegrityRepairPost(res http.ResponseWriter, req *http.Request) {
+	var form integrityRepairForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	check, ok := models.LookupOrphanCheck(form.Check)
+	if !ok {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+	if form.Action == models.RepairReattach {
+		form.CheckField(form.ParentID > 0, "parent_id", fmt.Sprintf("Enter the %s ID to reattach the rows to", check.Parent))
+	}
+	if !form.Valid() {
+		app.renderIntegrity(res, req, http.StatusUnprocessableEntity, form)
+		return
+	}
+
+	changed, err := app.integrity.Repair(form.Check, form.Action, form.ParentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNoRecord):
+			form.AddFieldError("parent_id", fmt.Sprintf("There is no %s with ID %d", check.Parent, form.ParentID))
+			app.renderIntegrity(res, req, http.StatusUnprocessableEntity, form)
+		case errors.Is(err, models.ErrValidation):
+			app.clientError(res, http.StatusBadRequest)
+		default:
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	app.logger.Info("repaired orphaned rows", "check", form.Check, "action", form.Action, "rows", changed)
+	if form.Action == models.RepairReattach {
+		app.flash(req, fmt.Sprintf("Reattached %d rows to %s %d", changed, check.Parent, form.ParentID))
+	} else {
+		app.flash(req, fmt.Sprintf("Soft deleted %d rows", changed))
+	}
+	app.redirect(res, req, "/admin/integrity", http.StatusSeeOther)
+}
+
+// exportFull handles a GET request which downloads every client, project, timesheet, invoice,
+// payment and setting as JSON that can be imported again, or as a ZIP of one JSON file per
+// table when format=zip is given
+func (app *application) exportFull(res http.ResponseWriter, req *http.Request) {
+	zipped := req.URL.Query().Get("format") == "zip"
+
+	schemaVersion, err := database.SchemaVersion(app.db, app.migrationsDir)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	export, err := app.exports.Export(schemaVersion)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	// Build the whole file first so a failure part way through is still reported as an error
+	var buf bytes.Buffer
+	name := fmt.Sprintf("freelance-tracker-export-%s", export.ExportedAt.Format("2006-01-02"))
+	contentType := "application/json"
+	if zipped {
+		err = export.WriteZip(&buf)
+		name += ".zip"
+		contentType = "application/zip"
+	} else {
+		err = export.WriteJSON(&buf)
+		name += ".json"
+	}
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	res.Header().Set("Content-Type", contentType)
+	res.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", name))
+	res.Header().Set("Content-Length", fmt.Sprintf("%d", buf.Len()))
+
+	_, err = buf.WriteTo(res)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+}
+
+// ENTITY_EXPORT_VERSION is bumped whenever the shape of a client, project or invoice export
+// changes in a way scripts reading it would notice
+const ENTITY_EXPORT_VERSION = 1
+
+// entityExport is a single client, project or invoice exported along with its children.
+// Exactly one of Client, Project and Invoice is set, as named by Type. Dates are written as
+// YYYY-MM-DD and timestamps in RFC 3339, in UTC.
+type entityExport struct {
+	Type       string         `json:"type"`
+	Version    int            `json:"version"`
+	ExportedAt string         `json:"exported_at"`
+	Client     *exportClient  `json:"client,omitempty"`
+	Project    *exportProject `json:"project,omitempty"`
+	Invoice    *exportInvoice `json:"invoice,omitempty"`
+}
+
+type exportClient struct {
+	ID                   int             `json:"id"`
+	Name                 string          `json:"name"`
+	Email                string          `json:"email"`
+	Phone                *string         `json:"phone"`
+	Address1             *string         `json:"address1"`
+	Address2             *string         `json:"address2"`
+	Address3             *string         `json:"address3"`
+	City                 *string         `json:"city"`
+	State                *string         `json:"state"`
+	ZipCode              *string         `json:"zip_code"`
+	Country              *string         `json:"country"`
+	HourlyRate           float64         `json:"hourly_rate"`
+	BillTo               *string         `json:"bill_to"`
+	PONumber             *string         `json:"po_number"`
+	BudgetCode           *string         `json:"budget_code"`
+	InvoiceLanguage      *string         `json:"invoice_language"`
+	InvoicePrefix        *string         `json:"invoice_prefix"`
+	NextInvoiceNumber    *int            `json:"next_invoice_number"`
+	MonthlyHourAllowance *float64        `json:"monthly_hour_allowance"`
+	PrepayOnly           bool            `json:"prepay_only"`
+	PipelineStage        string          `json:"pipeline_stage"`
+	CreatedAt            string          `json:"created_at"`
+	UpdatedAt            string          `json:"updated_at"`
+	Notes                []exportNote    `json:"notes"`
+	Projects             []exportProject `json:"projects"`
+}
+
+type exportNote struct {
+	ID        int    `json:"id"`
+	Body      string `json:"body"`
+	Pinned    bool   `json:"pinned"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type exportProject struct {
+	ID               int               `json:"id"`
+	ClientID         int               `json:"client_id"`
+	Name             string            `json:"name"`
+	Status           string            `json:"status"`
+	HourlyRate       float64           `json:"hourly_rate"`
+	Deadline         *string           `json:"deadline"`
+	Currency         string            `json:"currency"`
+	DiscountPercent  *float64          `json:"discount_percent"`
+	DiscountReason   string            `json:"discount_reason"`
+	AdjustmentAmount *float64          `json:"adjustment_amount"`
+	AdjustmentReason string            `json:"adjustment_reason"`
+	EstimatedHours   *float64          `json:"estimated_hours"`
+	EstimatedAmount  *float64          `json:"estimated_amount"`
+	BudgetCode       string            `json:"budget_code"`
+	Notes            string            `json:"notes"`
+	CreatedAt        string            `json:"created_at"`
+	UpdatedAt        string            `json:"updated_at"`
+	Timesheets       []exportTimesheet `json:"timesheets"`
+	Milestones       []exportMilestone `json:"milestones"`
+	Invoices         []exportInvoice   `json:"invoices"`
+}
+
+type exportTimesheet struct {
+	ID              int      `json:"id"`
+	WorkDate        string   `json:"work_date"`
+	Quantity        float64  `json:"quantity"`
+	Unit            string   `json:"unit"`
+	Rate            float64  `json:"rate"`
+	CostRate        *float64 `json:"cost_rate"`
+	Amount          float64  `json:"amount"`
+	Description     string   `json:"description"`
+	Service         string   `json:"service"`
+	WorkType        string   `json:"work_type"`
+	PendingApproval bool     `json:"pending_approval"`
+	CreatedAt       string   `json:"created_at"`
+	UpdatedAt       string   `json:"updated_at"`
+}
+
+type exportMilestone struct {
+	ID        int     `json:"id"`
+	Name      string  `json:"name"`
+	DueDate   *string `json:"due_date"`
+	Fee       float64 `json:"fee"`
+	Status    string  `json:"status"`
+	InvoiceID *int    `json:"invoice_id"`
+}
+
+// exportInvoice is an invoice as exported. Totals and the timesheets billed are only given
+// when the invoice itself is exported; within a project they would repeat its timesheets.
+type exportInvoice struct {
+	ID             int               `json:"id"`
+	ProjectID      int               `json:"project_id"`
+	InvoiceNumber  string            `json:"invoice_number"`
+	Status         string            `json:"status"`
+	InvoiceDate    string            `json:"invoice_date"`
+	DueDate        *string           `json:"due_date"`
+	DatePaid       *string           `json:"date_paid"`
+	PaymentTerms   string            `json:"payment_terms"`
+	BudgetCode     string            `json:"budget_code"`
+	AmountDue      float64           `json:"amount_due"`
+	CreditApplied  float64           `json:"credit_applied"`
+	BalanceDue     float64           `json:"balance_due"`
+	AmountPaid     *float64          `json:"amount_paid"`
+	VoidReason     string            `json:"void_reason"`
+	TimesheetsFrom *string           `json:"timesheets_from"`
+	TimesheetsTo   *string           `json:"timesheets_to"`
+	CreatedAt      string            `json:"created_at"`
+	UpdatedAt      string            `json:"updated_at"`
+	Totals         *exportTotals     `json:"totals,omitempty"`
+	Timesheets     []exportTimesheet `json:"timesheets,omitempty"`
+}
+
+type exportTotals struct {
+	Currency           string  `json:"currency"`
+	Hours              float64 `json:"hours"`
+	Discount           float64 `json:"discount"`
+	Adjustment         float64 `json:"adjustment"`
+	RoundingAdjustment float64 `json:"rounding_adjustment"`
+	Total              float64 `json:"total"`
+}
+
+// exportDate writes an optional date as YYYY-MM-DD
+func exportDate(date *time.Time) *string {
+	if date == nil {
+		return nil
+	}
+	formatted := date.Format("2006-01-02")
+	return &formatted
+}
+
+// exportTimestamp writes a timestamp in RFC 3339, in UTC
+func exportTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func newExportTimesheets(timesheets []models.Timesheet) []exportTimesheet {
+	items := make([]exportTimesheet, len(timesheets))
+	for i, timesheet := range timesheets {
+		items[i] = exportTimesheet{
+			ID:              timesheet.ID,
+			WorkDate:        timesheet.WorkDate.Format("2006-01-02"),
+			Quantity:        timesheet.HoursWorked,
+			Unit:            timesheet.Unit,
+			Rate:            timesheet.HourlyRate,
+			CostRate:        timesheet.CostRate,
+			Amount:          timesheet.HoursWorked * timesheet.HourlyRate,
+			Description:     timesheet.Description,
+			Service:         timesheet.ServiceName,
+			WorkType:        timesheet.WorkType,
+			PendingApproval: timesheet.PendingApproval,
+			CreatedAt:       exportTimestamp(timesheet.Created),
+			UpdatedAt:       exportTimestamp(timesheet.Updated),
+		}
+	}
+	return items
+}
+
+func newExportInvoice(invoice models.Invoice) exportInvoice {
+	return exportInvoice{
+		ID:             invoice.ID,
+		ProjectID:      invoice.ProjectID,
+		InvoiceNumber:  invoice.DisplayNumber(),
+		Status:         invoiceStatus(invoice),
+		InvoiceDate:    invoice.InvoiceDate.Format("2006-01-02"),
+		DueDate:        exportDate(invoice.DueDate),
+		DatePaid:       exportDate(invoice.DatePaid),
+		PaymentTerms:   invoice.PaymentTerms,
+		BudgetCode:     invoice.BudgetCode,
+		AmountDue:      invoice.AmountDue,
+		CreditApplied:  invoice.CreditApplied,
+		BalanceDue:     invoice.BalanceDue(),
+		AmountPaid:     invoice.AmountPaid,
+		VoidReason:     invoice.VoidReason,
+		TimesheetsFrom: exportDate(invoice.TimesheetsFrom),
+		TimesheetsTo:   exportDate(invoice.TimesheetsTo),
+		CreatedAt:      exportTimestamp(invoice.Created),
+		UpdatedAt:      exportTimestamp(invoice.Updated),
+	}
+}
+
+// newExportProject reads a project along with its timesheets, milestones and invoices
+func (app *application) newExportProject(project models.Project) (exportProject, error) {
+	timesheets, err := app.timesheets.GetByProject(project.ID)
+	if err != nil {
+		return exportProject{}, err
+	}
+	milestones, err := app.milestones.GetByProject(project.ID)
+	if err != nil {
+		return exportProject{}, err
+	}
+	invoices, err := app.invoices.GetByProject(project.ID)
+	if err != nil {
+		return exportProject{}, err
+	}
+
+	export := exportProject{
+		ID:               project.ID,
+		ClientID:         project.ClientID,
+		Name:             project.Name,
+		Status:           project.Status,
+		HourlyRate:       project.HourlyRate,
+		Deadline:         exportDate(project.Deadline),
+		Currency:         project.CurrencyDisplay,
+		DiscountPercent:  project.DiscountPercent,
+		DiscountReason:   project.DiscountReason,
+		AdjustmentAmount: project.AdjustmentAmount,
+		AdjustmentReason: project.AdjustmentReason,
+		EstimatedHours:   project.EstimatedHours,
+		EstimatedAmount:  project.EstimatedAmount,
+		BudgetCode:       project.BudgetCode,
+		Notes:            project.Notes,
+		CreatedAt:        exportTimestamp(project.Created),
+		UpdatedAt:        exportTimestamp(project.Updated),
+		Timesheets:       newExportTimesheets(timesheets),
+		Milestones:       make([]exportMilestone, len(milestones)),
+		Invoices:         make([]exportInvoice, len(invoices)),
+	}
+	for i, milestone := range milestones {
+		export.Milestones[i] = exportMilestone{
+			ID:        milestone.ID,
+			Name:      milestone.Name,
+			DueDate:   exportDate(milestone.DueDate),
+			Fee:       milestone.Fee,
+			Status:    milestone.Status,
+			InvoiceID: milestone.InvoiceID,
+		}
+	}
+	for i, invoice := range invoices {
+		export.Invoices[i] = newExportInvoice(invoice)
+	}
+	return export, nil
+}
+
+// writeEntityExport sends an entity export as a JSON file download named after it
+func (app *application) writeEntityExport(res http.ResponseWriter, req *http.Request, id int, export entityExport) {
+	export.Version = ENTITY_EXPORT_VERSION
+	export.ExportedAt = exportTimestamp(time.Now())
+
+	body, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	res.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s-%d.json\"", export.Type, id))
+	res.Write(body)
+}
+
+// clientExport handles a GET request to download a client, with its notes and its projects'
+// timesheets, milestones and invoices, as JSON
+func (app *application) clientExport(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	client, err := app.clients.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	notes, err := app.clientNotes.GetByClient(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	projects, err := app.projects.GetByClient(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	export := exportClient{
+		ID:                   client.ID,
+		Name:                 client.Name,
+		Email:                client.Email,
+		Phone:                client.Phone,
+		Address1:             client.Address1,
+		Address2:             client.Address2,
+		Address3:             client.Address3,
+		City:                 client.City,
+		State:                client.State,
+		ZipCode:              client.ZipCode,
+		Country:              client.Country,
+		HourlyRate:           client.HourlyRate,
+		BillTo:               client.BillTo,
+		PONumber:             client.PONumber,
+		BudgetCode:           client.BudgetCode,
+		MonthlyHourAllowance: client.MonthlyHourAllowance,
+		InvoiceLanguage:      client.InvoiceLanguage,
+		InvoicePrefix:        client.InvoicePrefix,
+		NextInvoiceNumber:    client.NextInvoiceNumber,
+		PrepayOnly:           client.PrepayOnly,
+		PipelineStage:        client.PipelineStage,
+		CreatedAt:            exportTimestamp(client.Created),
+		UpdatedAt:            exportTimestamp(client.Updated),
+		Notes:                make([]exportNote, len(notes)),
+		Projects:             make([]exportProject, len(projects)),
+	}
+	for i, note := range notes {
+		export.Notes[i] = exportNote{
+			ID:        note.ID,
+			Body:      note.Body,
+			Pinned:    note.Pinned,
+			CreatedAt: exportTimestamp(note.Created),
+			UpdatedAt: exportTimestamp(note.Updated),
+		}
+	}
+	for i, project := range projects {
+		export.Projects[i], err = app.newExportProject(project)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+	}
+
+	app.writeEntityExport(res, req, id, entityExport{Type: "client", Client: &export})
+}
+
+// projectExport handles a GET request to download a project, with its timesheets,
+// milestones and invoices, as JSON
+func (app *application) projectExport(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	project, err := app.projects.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	export, err := app.newExportProject(project)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	app.writeEntityExport(res, req, id, entityExport{Type: "project", Project: &export})
+}
+
+// invoiceExport handles a GET request to download an invoice, with its totals and the
+// timesheets it bills, as JSON
+func (app *application) invoiceExport(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	data, err := app.invoices.GetComprehensiveForPDF(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	export := newExportInvoice(data.Invoice)
+	export.Totals = &exportTotals{
+		Currency:           data.Project.CurrencyDisplay,
+		Hours:              data.TotalHours,
+		Discount:           data.DiscountAmount,
+		Adjustment:         data.AdjustmentAmount,
+		RoundingAdjustment: data.RoundingAdjustment,
+		Total:              data.FinalTotal,
+	}
+	export.Timesheets = newExportTimesheets(data.Timesheets)
+
+	app.writeEntityExport(res, req, id, entityExport{Type: "invoice", Invoice: &export})
+}
+
+// settingsEdit handles a GET request to display the settings edit form
+func (app *application) settingsEdit(res http.ResponseWriter, req *http.Request) {
+	settings, err := app.settings.GetAllDetailed()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Settings = settings
+	data.Form = settingsForm{}
+
+	app.render(res, req, http.StatusOK, "settings_edit.html", data)
+}
+
+// settingsEditPost handles a POST request to update application settings
+func (app *application) settingsEditPost(res http.ResponseWriter, req *http.Request) {
+	// Get current settings to know what to expect
+	settings, err := app.settings.GetAllDetailed()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	// Parse form
+	err = req.ParseForm()
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	var form settingsForm
+	form.Settings = make(map[string]string)
+
+	// Extract values from form for each setting. Unticked checkboxes aren't submitted at all,
+	// and secrets are only submitted when replaced or cleared.
+	for _, setting := range settings {
+		value := strings.TrimSpace(req.PostForm.Get(setting.Key))
+		rule := setting.Rule()
+		if rule.Type == "bool" {
+			value = strconv.FormatBool(value == "true")
+		}
+		if rule.Secret && value == "" && req.PostForm.Get(setting.Key+"_clear") != "true" {
+			value = setting.Value
+		}
+		form.Settings[setting.Key] = value
+	}
+
+	// Validate each setting against its rule
+	for _, setting := range settings {
+		err := models.ValidateSetting(setting.Key, setting.DataType, form.Settings[setting.Key])
+		var settingErr *models.SettingError
+		if errors.As(err, &settingErr) {
+			form.AddFieldError(setting.Key, settingErr.Message)
+		}
+	}
+
+	// If there are validation errors, redisplay the form with the values as entered
+	if !form.Valid() {
+		for i := range settings {
+			settings[i].Value = form.Settings[settings[i].Key]
+		}
+
+		data := app.newTemplateData(req)
+		data.Settings = settings
+		data.Form = form
+		app.render(res, req, http.StatusUnprocessableEntity, "settings_edit.html", data)
+		return
+	}
+
+	// Update each setting value
+	for _, setting := range settings {
+		err = app.settings.UpdateValue(setting.Key, form.Settings[setting.Key])
+		if err != nil {
+			app.modelError(res, req, err)
+			return
+		}
+	}
+
+	// Redirect to settings view
+	app.flash(req, "Settings saved")
+	app.redirect(res, req, "/settings", http.StatusSeeOther)
+}
+
+// projectsList handles a GET request which displays all projects
+func (app *application) projectsList(res http.ResponseWriter, req *http.Request) {
+	if user := app.currentUser(req); user != nil && !user.IsOwner() {
+		app.sharedProjectsList(res, req, user)
+		return
+	}
+
+	pageSize := app.pageSize(req)
+	page := currentPage(req, "page")
+	offset := int64((page - 1) * pageSize)
+
+	tagFilter, err := app.tagFilter(req)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	filter, ok := app.listFilter(res, req, models.ProjectFilters)
+	if !ok {
+		return
+	}
+
+	// Get paginated projects and total count, narrowed to the filter or tag being filtered on
+	var projects []models.ProjectWithClient
+	var totalCount int64
+	if len(filter) > 0 {
+		var ids []int
+		ids, totalCount, err = app.listFilters.Find(models.ProjectFilters, filter, time.Now(), int64(pageSize), offset)
+		if err != nil {
+			app.modelError(res, req, err)
+			return
+		}
+		projects, err = app.projects.GetByIDs(ids)
+	} else if tagFilter != nil {
+		projects, err = app.projects.GetWithPaginationByTag(tagFilter.ID, int64(pageSize), offset)
+		if err == nil {
+			totalCount, err = app.projects.GetCountByTag(tagFilter.ID)
+		}
+	} else {
+		projects, err = app.projects.GetWithPagination(int64(pageSize), offset)
+		if err == nil {
+			totalCount, err = app.projects.GetCount()
+		}
+	}
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	tags, err := app.tags.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	projectTags, err := app.tags.ProjectTagMap()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	listFilter, err := app.listFilterData(req, models.ProjectFilters, filter)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.ProjectsWithClient = projects
+	data.Pagination = newPagination(page, pageSize, totalCount)
+	data.Tags = tags
+	data.TagFilter = tagFilter
+	data.ListFilter = listFilter
+	data.ProjectTags = projectTags
+	app.render(res, req, http.StatusOK, "projects.html", data)
+}
+
+// projectSearchLimit is the most projects the project picker offers at once
+const projectSearchLimit = 10
+
+// projectSearch handles a GET request from the project picker, returning the projects whose
+// name or client name contains the q parameter as JSON, most recently active first
+func (app *application) projectSearch(res http.ResponseWriter, req *http.Request) {
+	matches, err := app.projects.Search(req.URL.Query().Get("q"), projectSearchLimit)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	app.writeJSON(res, req, http.StatusOK, matches)
+}
+
+// paletteLimit is the most clients and projects the command palette offers at once
+const paletteLimit = 8
+
+// paletteItem is an entry in the command palette: a client, a project or an action, and the
+// page it opens
+type paletteItem struct {
+	Kind   string `json:"kind"`
+	Label  string `json:"label"`
+	Detail string `json:"detail,omitempty"`
+	URL    string `json:"url"`
+}
+
+// paletteActions are the pages the command palette offers besides clients and projects
+var paletteActions = []paletteItem{
+	{Label: "Log time", URL: "/timesheet/create"},
+	{Label: "New invoice", URL: "/invoice/create"},
+	{Label: "New client", URL: "/client/create"},
+	{Label: "Clients", URL: "/"},
+	{Label: "Projects", URL: "/projects"},
+	{Label: "Pipeline", URL: "/clients/pipeline"},
+	{Label: "Pending timesheets", URL: "/timesheets/pending"},
+	{Label: "Timesheet approvals", URL: "/timesheets/approvals"},
+	{Label: "Reconcile payments", URL: "/invoices/reconcile"},
+	{Label: "Margins report", URL: "/reports/margins"},
+	{Label: "Receivables forecast", URL: "/reports/forecast"},
+	{Label: "Work in progress report", URL: "/reports/wip"},
+	{Label: "Top clients report", URL: "/reports/clients"},
+	{Label: "Trends report", URL: "/reports/trends"},
+	{Label: "Scheduled jobs", URL: "/jobs"},
+	{Label: "Settings", URL: "/settings"},
+	{Label: "Preferences", URL: "/user/preferences"},
+}
+
+// palette handles a GET request from the command palette, returning what it offers as JSON.
+// Without a q parameter these are the clients and projects the user visits most followed by
+// every action. With one they are the actions, then the clients and projects, whose names
+// contain it, those the user visits most first.
+func (app *application) palette(res http.ResponseWriter, req *http.Request) {
+	query := strings.ToLower(strings.TrimSpace(req.URL.Query().Get("q")))
+	matches := func(text string) bool {
+		return strings.Contains(strings.ToLower(text), query)
+	}
+
+	items := []paletteItem{}
+	seen := map[string]bool{}
+	add := func(item paletteItem) {
+		if !seen[item.Kind+item.URL] {
+			seen[item.Kind+item.URL] = true
+			item.URL = app.basePath + item.URL
+			items = append(items, item)
+		}
+	}
+
+	if query != "" {
+		for _, action := range paletteActions {
+			if matches(action.Label) {
+				add(paletteItem{Kind: "action", Label: action.Label, URL: action.URL})
+			}
+		}
+	}
+
+	if user := app.currentUser(req); user != nil {
+		visits, err := app.visits.GetTop(user.ID, paletteLimit, time.Now())
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		for _, visit := range visits {
+			if query != "" && !matches(visit.Name+" "+visit.ClientName) {
+				continue
+			}
+			if visit.Kind == models.VisitClient {
+				add(paletteItem{Kind: "client", Label: visit.Name, URL: fmt.Sprintf("/client/view/%d", visit.ItemID)})
+			} else {
+				add(paletteItem{Kind: "project", Label: visit.Name, Detail: visit.ClientName, URL: fmt.Sprintf("/project/view/%d", visit.ItemID)})
+			}
+		}
+	}
+
+	if query == "" {
+		for _, action := range paletteActions {
+			add(paletteItem{Kind: "action", Label: action.Label, URL: action.URL})
+		}
+		app.writeJSON(res, req, http.StatusOK, map[string][]paletteItem{"items": items})
+		return
+	}
+
+	clients, err := app.clients.Search(query, paletteLimit)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	for _, client := range clients {
+		add(paletteItem{Kind: "client", Label: client.Name, URL: fmt.Sprintf("/client/view/%d", client.ID)})
+	}
+	projects, err := app.projects.Search(query, paletteLimit)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	for _, project := range projects {
+		add(paletteItem{Kind: "project", Label: project.Name, Detail: project.ClientName, URL: fmt.Sprintf("/project/view/%d", project.ID)})
+	}
+	app.writeJSON(res, req, http.StatusOK, map[string][]paletteItem{"items": items})
+}
+
+// timesheetQuickCreate handles a GET request to log time without first finding the project
+func (app *application) timesheetQuickCreate(res http.ResponseWriter, req *http.Request) {
+	app.quickCreate(res, req, "timesheet")
+}
+
+// invoiceQuickCreate handles a GET request to create an invoice without first finding the project
+func (app *application) invoiceQuickCreate(res http.ResponseWriter, req *http.Request) {
+	app.quickCreate(res, req, "invoice")
+}
+
+// quickCreate shows the project picker for creating a timesheet entry or invoice. Once a
+// project is picked it redirects to that project's create form. The picker searches as the
+// user types, and without JavaScript submitting the search lists the matches as links.
+func (app *application) quickCreate(res http.ResponseWriter, req *http.Request, action string) {
+	query := req.URL.Query()
+	if id, err := strconv.Atoi(query.Get("project_id")); err == nil && id > 0 {
+		app.redirect(res, req, fmt.Sprintf("/project/%d/%s/create", id, action), http.StatusSeeOther)
+		return
+	}
+
+	form := projectPickForm{Query: query.Get("q")}
+	data := app.newTemplateData(req)
+	if query.Has("q") {
+		matches, err := app.projects.Search(form.Query, projectSearchLimit)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		data.ProjectMatches = matches
+	}
+	data.Form = form
+	data.PickAction = action
+	app.render(res, req, http.StatusOK, "project_pick.html", data)
+}
+
+// sharedProjectsList displays the projects which have been shared with a subcontractor
+func (app *application) sharedProjectsList(res http.ResponseWriter, req *http.Request, user *models.User) {
+	ids, err := app.users.GetSharedProjectIDs(user.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	projects := make([]models.Project, 0, len(ids))
+	for _, id := range ids {
+		project, err := app.projects.Get(id)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		projects = append(projects, project)
+	}
+
+	data := app.newTemplateData(req)
+	data.Projects = projects
+	app.render(res, req, http.StatusOK, "projects_shared.html", data)
+}
+
+// businessProfilesList handles a GET request which displays all business profiles
+func (app *application) businessProfilesList(res http.ResponseWriter, req *http.Request) {
+	profiles, err := app.businessProfiles.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.BusinessProfiles = profiles
+	app.render(res, req, http.StatusOK, "profiles.html", data)
+}
+
+// businessProfileCreate handles a GET request which returns an empty business profile form
+func (app *application) businessProfileCreate(res http.ResponseWriter, req *http.Request) {
+	data := app.newTemplateData(req)
+	data.Form = businessProfileForm{
+		NextInvoiceNumber: "1",
+	}
+	app.render(res, req, http.StatusOK, "profile_create.html", data)
+}
+
+// validateBusinessProfileForm checks the business profile form fields and returns the parsed next invoice number
+func validateBusinessProfileForm(form *businessProfileForm) int {
+	form.CheckField(validator.NotBlank(form.Name), "name", "Name is required")
+	form.CheckField(validator.MaxChars(form.Name, NAME_LENGTH), "name", fmt.Sprintf("Name must be shorter than %d characters", NAME_LENGTH))
+	form.CheckField(validator.MaxChars(form.FreelancerName, NAME_LENGTH), "freelancer_name", fmt.Sprintf("Freelancer name must be shorter than %d characters", NAME_LENGTH))
+	form.CheckField(validator.MaxChars(form.FreelancerAddress, NAME_LENGTH), "freelancer_address", fmt.Sprintf("Address must be shorter than %d characters", NAME_LENGTH))
+	form.CheckField(validator.MaxChars(form.FreelancerCityStateZip, NAME_LENGTH), "freelancer_city_state_zip", fmt.Sprintf("City, state and ZIP must be shorter than %d characters", NAME_LENGTH))
+	form.CheckField(validator.MaxChars(form.FreelancerPhone, NAME_LENGTH), "freelancer_phone", fmt.Sprintf("Phone must be shorter than %d characters", NAME_LENGTH))
+	form.CheckField(validator.MaxChars(form.LogoPath, NAME_LENGTH), "logo_path", fmt.Sprintf("Logo path must be shorter than %d characters", NAME_LENGTH))
+	form.CheckField(validator.MaxChars(form.BankDetails, 2000), "bank_details", "Bank details must be shorter than 2000 characters")
+	form.CheckField(validator.MaxChars(form.InvoicePrefix, 20), "invoice_prefix", "Invoice prefix must be shorter than 20 characters")
+
+	if form.FreelancerEmail != "" {
+		form.CheckField(validator.Matches(strings.ToLower(form.FreelancerEmail), validator.EmailRegex), "freelancer_email", "Email must be a valid email address")
+	}
+
+	nextInvoiceNumber, err := strconv.Atoi(form.NextInvoiceNumber)
+	if form.NextInvoiceNumber == "" {
+		form.CheckField(false, "next_invoice_number", "Next invoice number is required")
+	} else if err != nil || nextInvoiceNumber < 1 {
+		form.CheckField(false, "next_invoice_number", "Next invoice number must be a whole number of 1 or greater")
+	}
+
+	return nextInvoiceNumber
+}
+
+// formToBusinessProfile converts a businessProfileForm to a models.BusinessProfile struct
+func formToBusinessProfile(form businessProfileForm, profileID, nextInvoiceNumber int) models.BusinessProfile {
+	return models.BusinessProfile{
+		ID:                     profileID,
+		Name:                   form.Name,
+		FreelancerName:         form.FreelancerName,
+		FreelancerAddress:      form.FreelancerAddress,
+		FreelancerCityStateZip: form.FreelancerCityStateZip,
+		FreelancerPhone:        form.FreelancerPhone,
+		FreelancerEmail:        form.FreelancerEmail,
+		LogoPath:               form.LogoPath,
+		BankDetails:            form.BankDetails,
+		InvoicePrefix:          form.InvoicePrefix,
+		NextInvoiceNumber:      nextInvoiceNumber,
+	}
+}
+
+// businessProfileCreatePost handles a POST request with business profile form data which is then
+// validated and used to insert a new business profile into the database
+func (app *application) businessProfileCreatePost(res http.ResponseWriter, req *http.Request) {
+	var form businessProfileForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	nextInvoiceNumber := validateBusinessProfileForm(&form)
+
+	if !form.Valid() {
+		data := app.newTemplateData(req)
+		data.Form = form
+		app.render(res, req, http.StatusUnprocessableEntity, "profile_create.html", data)
+		return
+	}
+
+	_, err = app.businessProfiles.Insert(formToBusinessProfile(form, 0, nextInvoiceNumber))
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	app.flash(req, fmt.Sprintf("Business profile %s created", form.Name))
+	app.redirect(res, req, "/profiles", http.StatusSeeOther)
+}
+
+// businessProfileUpdate handles a GET request which returns a business profile form pre-populated with profile data
+func (app *application) businessProfileUpdate(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	profile, err := app.businessProfiles.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = businessProfileForm{
+		Name:                   profile.Name,
+		FreelancerName:         profile.FreelancerName,
+		FreelancerAddress:      profile.FreelancerAddress,
+		FreelancerCityStateZip: profile.FreelancerCityStateZip,
+		FreelancerPhone:        profile.FreelancerPhone,
+		FreelancerEmail:        profile.FreelancerEmail,
+		LogoPath:               profile.LogoPath,
+		BankDetails:            profile.BankDetails,
+		InvoicePrefix:          profile.InvoicePrefix,
+		NextInvoiceNumber:      strconv.Itoa(profile.NextInvoiceNumber),
+	}
+	data.BusinessProfile = &profile
+	app.render(res, req, http.StatusOK, "profile_create.html", data)
+}
+
+// businessProfileUpdatePost handles a POST request with business profile form data which is then
+// validated and used to update an existing business profile in the database
+func (app *application) businessProfileUpdatePost(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	profile, err := app.businessProfiles.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	var form businessProfileForm
+	err = app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	nextInvoiceNumber := validateBusinessProfileForm(&form)
+
+	if !form.Valid() {
+		data := app.newTemplateData(req)
+		data.Form = form
+		data.BusinessProfile = &profile
+		app.render(res, req, http.StatusUnprocessableEntity, "profile_create.html", data)
+		return
+	}
+
+	err = app.businessProfiles.Update(formToBusinessProfile(form, id, nextInvoiceNumber))
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	app.flash(req, fmt.Sprintf("Business profile %s updated", form.Name))
+	app.redirect(res, req, "/profiles", http.StatusSeeOther)
+}
+
+// businessProfileDelete handles a POST request to soft delete a business profile
+func (app *application) businessProfileDelete(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	// Check if business profile exists before deleting
+	profile, err := app.businessProfiles.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	err = app.businessProfiles.Delete(id)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("Business profile %s deleted", profile.Name))
+	app.redirect(res, req, "/profiles", http.StatusSeeOther)
+}
+
+// servicesList handles a GET request which displays the service catalog
+func (app *application) servicesList(res http.ResponseWriter, req *http.Request) {
+	services, err := app.services.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Services = services
+	app.render(res, req, http.StatusOK, "services.html", data)
+}
+
+// serviceCreate handles a GET request which returns an empty service form
+func (app *application) serviceCreate(res http.ResponseWriter, req *http.Request) {
+	data := app.newTemplateData(req)
+	data.Form = serviceForm{
+		Unit: models.UnitHour,
+	}
+	app.render(res, req, http.StatusOK, "service_create.html", data)
+}
+
+// validateServiceForm checks the service form fields and returns the parsed default rate
+func validateServiceForm(form *serviceForm) float64 {
+	form.CheckField(validator.NotBlank(form.Name), "name", "Name is required")
+	form.CheckField(validator.MaxChars(form.Name, NAME_LENGTH), "name", fmt.Sprintf("Name must be shorter than %d characters", NAME_LENGTH))
+	form.CheckField(models.ValidUnit(form.Unit), "unit", "Unit must be hour, word or page")
+
+	defaultRate, err := strconv.ParseFloat(form.DefaultRate, 64)
+	if form.DefaultRate == "" {
+		form.CheckField(false, "default_rate", "Default rate is required")
+	} else if err != nil || defaultRate < 0 {
+		form.CheckField(false, "default_rate", "Default rate must be a positive number")
+	}
+
+	return defaultRate
+}
+
+// serviceCreatePost handles a POST request with service form data which is then
+// validated and used to add a new service to the catalog
+func (app *application) serviceCreatePost(res http.ResponseWriter, req *http.Request) {
+	var form serviceForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	defaultRate := validateServiceForm(&form)
+
+	if !form.Valid() {
+		data := app.newTemplateData(req)
+		data.Form = form
+		app.render(res, req, http.StatusUnprocessableEntity, "service_create.html", data)
+		return
+	}
+
+	_, err = app.services.Insert(models.Service{Name: form.Name, DefaultRate: defaultRate, Unit: form.Unit})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	app.flash(req, fmt.Sprintf("Service %s created", form.Name))
+	app.redirect(res, req, "/services", http.StatusSeeOther)
+}
+
+// serviceUpdate handles a GET request which returns a service form pre-populated with service data
+func (app *application) serviceUpdate(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	service, err := app.services.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = serviceForm{
+		Name:        service.Name,
+		DefaultRate: service.Rate(),
+		Unit:        service.Unit,
+	}
+	data.Service = &service
+	app.render(res, req, http.StatusOK, "service_create.html", data)
+}
+
+// serviceUpdatePost handles a POST request with service form data which is then
+// validated and used to update an existing service
+func (app *application) serviceUpdatePost(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	service, err := app.services.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	var form serviceForm
+	err = app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	defaultRate := validateServiceForm(&form)
+
+	if !form.Valid() {
+		data := app.newTemplateData(req)
+		data.Form = form
+		data.Service = &service
+		app.render(res, req, http.StatusUnprocessableEntity, "service_create.html", data)
+		return
+	}
+
+	err = app.services.Update(models.Service{ID: id, Name: form.Name, DefaultRate: defaultRate, Unit: form.Unit})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	app.flash(req, fmt.Sprintf("Service %s updated", form.Name))
+	app.redirect(res, req, "/services", http.StatusSeeOther)
+}
+
+// serviceDelete handles a POST request to soft delete a service. Entries already logged for
+// it keep their service and unit.
+func (app *application) serviceDelete(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	service, err := app.services.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	err = app.services.Delete(id)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("Service %s deleted", service.Name))
+	app.redirect(res, req, "/services", http.StatusSeeOther)
+}
+
+// paymentTermsList handles a GET request which displays the payment terms presets offered on
+// the invoice form, along with a form for adding another
+func (app *application) paymentTermsList(res http.ResponseWriter, req *http.Request) {
+	app.renderPaymentTerms(res, req, http.StatusOK, paymentTermsForm{})
+}
+
+// renderPaymentTerms renders the payment terms presets page with the given add form
+func (app *application) renderPaymentTerms(res http.ResponseWriter, req *http.Request, status int, form paymentTermsForm) {
+	presets, err := app.paymentTerms.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.TermsPresets = presets
+	app.render(res, req, status, "payment_terms.html", data)
+}
+
+// paymentTermsCreatePost handles a POST request which adds payment terms to the presets
+func (app *application) paymentTermsCreatePost(res http.ResponseWriter, req *http.Request) {
+	var form paymentTermsForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	form.Terms = strings.TrimSpace(form.Terms)
+	form.CheckField(validator.NotBlank(form.Terms), "terms", "Payment terms are required")
+	form.CheckField(validator.MaxChars(form.Terms, NAME_LENGTH), "terms", fmt.Sprintf("Payment terms must be shorter than %d characters", NAME_LENGTH))
+	if !form.Valid() {
+		app.renderPaymentTerms(res, req, http.StatusUnprocessableEntity, form)
+		return
+	}
+
+	_, err = app.paymentTerms.Insert(form.Terms)
+	if errors.Is(err, models.ErrDuplicate) {
+		form.AddFieldError("terms", "These payment terms are already a preset")
+		app.renderPaymentTerms(res, req, http.StatusUnprocessableEntity, form)
+		return
+	}
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("Payment terms %s added", form.Terms))
+	app.redirect(res, req, "/payment-terms", http.StatusSeeOther)
+}
+
+// paymentTermsDelete handles a POST request which removes a payment terms preset. Invoices
+// already given its terms keep them.
+func (app *application) paymentTermsDelete(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	err = app.paymentTerms.Delete(id)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, "Payment terms preset removed")
+	app.redirect(res, req, "/payment-terms", http.StatusSeeOther)
+}
+
+// checklistList handles a GET request which displays the checklist items kept on projects
+func (app *application) checklistList(res http.ResponseWriter, req *http.Request) {
+	app.renderChecklist(res, req, http.StatusOK, checklistItemForm{})
+}
+
+// renderChecklist renders the checklist items page with the given add form
+func (app *application) renderChecklist(res http.ResponseWriter, req *http.Request, status int, form checklistItemForm) {
+	items, err := app.checklists.GetItems()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	tags, err := app.tags.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.ChecklistItems = items
+	data.Tags = tags
+	app.render(res, req, status, "checklist.html", data)
+}
+
+// checklistCreatePost handles a POST request which adds an item to the checklist of every
+// project, or of the projects carrying the chosen tag
+func (app *application) checklistCreatePost(res http.ResponseWriter, req *http.Request) {
+	var form checklistItemForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	form.Label = strings.TrimSpace(form.Label)
+	form.CheckField(validator.NotBlank(form.Label), "label", "Item is required")
+	form.CheckField(validator.MaxChars(form.Label, NAME_LENGTH), "label", fmt.Sprintf("Item must be shorter than %d characters", NAME_LENGTH))
+
+	var tagID *int
+	if form.TagID != 0 {
+		_, err := app.tags.Get(form.TagID)
+		if errors.Is(err, models.ErrNoRecord) {
+			form.AddFieldError("tag_id", "Choose a tag from the list")
+		} else if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+		tagID = &form.TagID
+	}
+	if !form.Valid() {
+		app.renderChecklist(res, req, http.StatusUnprocessableEntity, form)
+		return
+	}
+
+	_, err = app.checklists.InsertItem(tagID, form.Label)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("Checklist item %s added", form.Label))
+	app.redirect(res, req, "/checklist", http.StatusSeeOther)
+}
+
+// checklistDelete handles a POST request which removes an item from the checklist, along with
+// the record of it being ticked off on projects
+func (app *application) checklistDelete(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	err = app.checklists.DeleteItem(id)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, "Checklist item removed")
+	app.redirect(res, req, "/checklist", http.StatusSeeOther)
+}
+
+// projectChecklistPost handles a POST request which ticks a checklist item off on a project,
+// or clears it when checked is false
+func (app *application) projectChecklistPost(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+	itemID, err := strconv.Atoi(req.PathValue("item"))
+	if err != nil || itemID < 0 {
+		http.NotFound(res, req)
+		return
+	}
+	err = req.ParseForm()
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+	checked, err := strconv.ParseBool(req.PostForm.Get("checked"))
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := app.openProject(res, req, id); !ok {
+		return
+	}
+
+	// Only the items on the project's checklist can be ticked off on it
+	checklist, err := app.checklists.GetForProject(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	found := false
+	for _, item := range checklist {
+		if item.ID == itemID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		http.NotFound(res, req)
+		return
+	}
+
+	err = app.checklists.SetChecked(id, itemID, checked)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", id), http.StatusSeeOther)
+}
+
+// externalIDTarget is the client, project or invoice whose external IDs are being managed,
+// with where to go back to when done
+type externalIDTarget struct {
+	Kind    string
+	ID      int
+	Name    string
+	BackURL string
+}
+
+// externalIDTargetFor loads a client, project or invoice that can have external IDs,
+// returning ErrNoRecord when it doesn't exist or has been deleted
+func (app *application) externalIDTargetFor(kind string, id int) (externalIDTarget, error) {
+	var err error
+	target := externalIDTarget{Kind: kind, ID: id}
+	switch kind {
+	case models.ExternalClient:
+		var client models.Client
+		client, err = app.clients.Get(id)
+		target.Name = client.Name
+		target.BackURL = fmt.Sprintf("/client/view/%d", id)
+	case models.ExternalProject:
+		var project models.Project
+		project, err = app.projects.Get(id)
+		target.Name = project.Name
+		target.BackURL = fmt.Sprintf("/project/view/%d", id)
+	case models.ExternalInvoice:
+		var invoice models.Invoice
+		invoice, err = app.invoices.Get(id)
+		target.Name = "Invoice #" + invoice.DisplayNumber()
+		target.BackURL = fmt.Sprintf("/project/view/%d", invoice.ProjectID)
+	default:
+		err = models.ErrNoRecord
+	}
+	return target, err
+}
+
+// loadExternalIDTarget loads the record named by the kind and id in the path, answering 404
+// when the kind isn't one that has external IDs or there is no such record
+func (app *application) loadExternalIDTarget(res http.ResponseWriter, req *http.Request) (externalIDTarget, bool) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return externalIDTarget{}, false
+	}
+
+	target, err := app.externalIDTargetFor(req.PathValue("kind"), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return externalIDTarget{}, false
+	}
+	return target, true
+}
+
+// externalIDsView handles a GET request which lists the IDs a client, project or invoice has in
+// other systems, with a form to add one
+func (app *application) externalIDsView(res http.ResponseWriter, req *http.Request) {
+	target, ok := app.loadExternalIDTarget(res, req)
+	if !ok {
+		return
+	}
+	app.renderExternalIDs(res, req, http.StatusOK, target, externalIDForm{})
+}
+
+// renderExternalIDs renders a record's external IDs page with the given add form
+func (app *application) renderExternalIDs(res http.ResponseWriter, req *http.Request, status int, target externalIDTarget, form externalIDForm) {
+	ids, err := app.externalIDs.GetAll(target.Kind, target.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.ExternalIDTarget = &target
+	data.ExternalIDs = ids
+	app.render(res, req, status, "external_ids.html", data)
+}
+
+// externalIDsPost handles a POST request which records a record's ID in another system,
+// replacing any ID it already had there
+func (app *application) externalIDsPost(res http.ResponseWriter, req *http.Request) {
+	target, ok := app.loadExternalIDTarget(res, req)
+	if !ok {
+		return
+	}
+
+	var form externalIDForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	form.Source = models.NormalizeExternalSource(form.Source)
+	form.ExternalID = strings.TrimSpace(form.ExternalID)
+	form.CheckField(validator.NotBlank(form.Source), "source", "Source is required")
+	form.CheckField(validator.Matches(form.Source, models.ExternalSourcePattern), "source", "Source must be up to 50 letters, digits, dots, dashes or underscores, such as quickbooks")
+	form.CheckField(validator.NotBlank(form.ExternalID), "external_id", "External ID is required")
+	form.CheckField(validator.MaxChars(form.ExternalID, NAME_LENGTH), "external_id", fmt.Sprintf("External ID must be shorter than %d characters", NAME_LENGTH))
+	if !form.Valid() {
+		app.renderExternalIDs(res, req, http.StatusUnprocessableEntity, target, form)
+		return
+	}
+
+	err = app.externalIDs.Set(target.Kind, target.ID, form.Source, form.ExternalID)
+	if errors.Is(err, models.ErrDuplicate) {
+		form.AddFieldError("external_id", fmt.Sprintf("Another %s already has this ID in %s", target.Kind, form.Source))
+		app.renderExternalIDs(res, req, http.StatusUnprocessableEntity, target, form)
+		return
+	}
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("%s ID %s recorded for %s", form.Source, form.ExternalID, target.Name))
+	app.redirect(res, req, fmt.Sprintf("/external-ids/%s/%d", target.Kind, target.ID), http.StatusSeeOther)
+}
+
+// externalIDDelete handles a POST request which removes a record's ID in another system
+func (app *application) externalIDDelete(res http.ResponseWriter, req *http.Request) {
+	target, ok := app.loadExternalIDTarget(res, req)
+	if !ok {
+		return
+	}
+
+	source := req.PathValue("source")
+	err := app.externalIDs.Delete(target.Kind, target.ID, source)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("%s ID removed from %s", source, target.Name))
+	app.redirect(res, req, fmt.Sprintf("/external-ids/%s/%d", target.Kind, target.ID), http.StatusSeeOther)
+}
+
+// maxFormDraftBytes limits the size of a posted form draft
+const maxFormDraftBytes = 64 << 10
+
+// formDraftKey identifies the logged in user's draft of a client or project form
+type formDraftKey struct {
+	UserID int
+	Form   string
+	ItemID int
+}
+
+// formDraftData is a form draft as the page script reads it
+type formDraftData struct {
+	Fields  url.Values `json:"fields"`
+	Updated time.Time  `json:"updated"`
+}
+
+// loadFormDraftKey reads the form and item a draft is for from the path, answering 404 when
+// the form isn't one drafts are kept for or nobody is logged in to keep it for
+func (app *application) loadFormDraftKey(res http.ResponseWriter, req *http.Request) (formDraftKey, bool) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	form := req.PathValue("form")
+	user := app.currentUser(req)
+	if err != nil || id < 0 || !models.IsDraftForm(form) || user == nil {
+		app.apiError(res, http.StatusNotFound, "no such form")
+		return formDraftKey{}, false
+	}
+	return formDraftKey{UserID: user.ID, Form: form, ItemID: id}, true
+}
+
+// formDraftView handles a GET request which returns the user's saved draft of a form, so that
+// changes typed on another device, or lost with the browser, can be restored
+func (app *application) formDraftView(res http.ResponseWriter, req *http.Request) {
+	key, ok := app.loadFormDraftKey(res, req)
+	if !ok {
+		return
+	}
+
+	draft, err := app.drafts.Get(key.UserID, key.Form, key.ItemID)
+	if errors.Is(err, models.ErrNoRecord) {
+		app.apiError(res, http.StatusNotFound, "no draft")
+		return
+	}
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	app.writeJSON(res, req, http.StatusOK, formDraftData{Fields: draft.Fields, Updated: draft.Updated})
+}
+
+// formDraftPost handles a POST request which saves the form's values as they stand as the
+// user's draft of it, replacing the draft saved before
+func (app *application) formDraftPost(res http.ResponseWriter, req *http.Request) {
+	key, ok := app.loadFormDraftKey(res, req)
+	if !ok {
+		return
+	}
+
+	req.Body = http.MaxBytesReader(res, req.Body, maxFormDraftBytes)
+	if err := req.ParseForm(); err != nil {
+		app.apiError(res, http.StatusBadRequest, "the draft couldn't be read")
+		return
+	}
+	fields := req.PostForm
+	fields.Del("csrf_token")
+
+	err := app.drafts.Save(key.UserID, key.Form, key.ItemID, fields, time.Now())
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// formDraftDiscard handles a POST request which throws away the user's draft of a form
+func (app *application) formDraftDiscard(res http.ResponseWriter, req *http.Request) {
+	key, ok := app.loadFormDraftKey(res, req)
+	if !ok {
+		return
+	}
+
+	err := app.drafts.Delete(key.UserID, key.Form, key.ItemID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// tagsList handles a GET request which displays the tags clients and projects can be grouped by
+func (app *application) tagsList(res http.ResponseWriter, req *http.Request) {
+	tags, err := app.tags.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Tags = tags
+	app.render(res, req, http.StatusOK, "tags.html", data)
+}
+
+// tagCreate handles a GET request which returns an empty tag form
+func (app *application) tagCreate(res http.ResponseWriter, req *http.Request) {
+	data := app.newTemplateData(req)
+	data.Form = tagForm{
+		Color: models.DefaultTagColor,
+	}
+	app.render(res, req, http.StatusOK, "tag_create.html", data)
+}
+
+// validateTagForm checks the tag form fields
+func validateTagForm(form *tagForm) {
+	form.Name = strings.TrimSpace(form.Name)
+	form.CheckField(validator.NotBlank(form.Name), "name", "Name is required")
+	form.CheckField(validator.MaxChars(form.Name, 50), "name", "Name must be shorter than 50 characters")
+	form.CheckField(models.TagColorPattern.MatchString(form.Color), "color", "Colour must be a hex colour such as #6c757d")
+}
+
+// tagCreatePost handles a POST request with tag form data which is then validated and used
+// to add a new tag
+func (app *application) tagCreatePost(res http.ResponseWriter, req *http.Request) {
+	var form tagForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	validateTagForm(&form)
+
+	if !form.Valid() {
+		data := app.newTemplateData(req)
+		data.Form = form
+		app.render(res, req, http.StatusUnprocessableEntity, "tag_create.html", data)
+		return
+	}
+
+	_, err = app.tags.Insert(models.Tag{Name: form.Name, Color: form.Color})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	app.flash(req, fmt.Sprintf("Tag %s created", form.Name))
+	app.redirect(res, req, "/tags", http.StatusSeeOther)
+}
+
+// tagUpdate handles a GET request which returns a tag form pre-populated with tag data
+func (app *application) tagUpdate(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	tag, err := app.tags.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = tagForm{
+		Name:  tag.Name,
+		Color: tag.Color,
+	}
+	data.Tag = &tag
+	app.render(res, req, http.StatusOK, "tag_create.html", data)
+}
+
+// tagUpdatePost handles a POST request with tag form data which is then validated and used
+// to update an existing tag
+func (app *application) tagUpdatePost(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	tag, err := app.tags.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	var form tagForm
+	err = app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	validateTagForm(&form)
+
+	if !form.Valid() {
+		data := app.newTemplateData(req)
+		data.Form = form
+		data.Tag = &tag
+		app.render(res, req, http.StatusUnprocessableEntity, "tag_create.html", data)
+		return
+	}
+
+	err = app.tags.Update(models.Tag{ID: id, Name: form.Name, Color: form.Color})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	app.flash(req, fmt.Sprintf("Tag %s updated", form.Name))
+	app.redirect(res, req, "/tags", http.StatusSeeOther)
+}
+
+// tagDelete handles a POST request to delete a tag, taking it off every client and project
+func (app *application) tagDelete(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	tag, err := app.tags.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(res, req)
+		} else {
+			app.serverError(res, req, err)
+		}
+		return
+	}
+
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		return tx.Tags.Delete(id)
+	})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("Tag %s deleted", tag.Name))
+	app.redirect(res, req, "/tags", http.StatusSeeOther)
+}
+
+// savedViewCreatePost handles a POST request to save the filter of a list page as a view the
+// current user can choose again from the list's dropdown
+func (app *application) savedViewCreatePost(res http.ResponseWriter, req *http.Request) {
+	var form savedViewForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	list, ok := models.LookupFilterList(form.List)
+	if !ok {
+		http.NotFound(res, req)
+		return
+	}
+	filter, err := models.ParseFilterQuery(form.Query)
+	if err == nil {
+		err = list.Validate(filter)
+	}
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	form.Name = strings.TrimSpace(form.Name)
+	form.CheckField(validator.NotBlank(form.Name), "name", "Give the view a name to save it")
+	form.CheckField(validator.MaxChars(form.Name, 50), "name", "View names must be shorter than 50 characters")
+	form.CheckField(len(filter) > 0, "query", "Add a condition to the filter before saving it")
+	if !form.Valid() {
+		message, ok := form.FieldErrors["query"]
+		if !ok {
+			message = form.FieldErrors["name"]
+		}
+		app.flash(req, message)
+		app.redirect(res, req, filterPath(list, filter), http.StatusSeeOther)
+		return
+	}
+
+	_, err = app.savedViews.Insert(app.currentUserID(req), list.Name, form.Name, filter.Query())
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	app.flash(req, fmt.Sprintf("View %s saved", form.Name))
+	app.redirect(res, req, filterPath(list, filter), http.StatusSeeOther)
+}
+
+// savedViewDelete handles a POST request to delete one of the current user's saved views
+func (app *application) savedViewDelete(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		http.NotFound(res, req)
+		return
+	}
+
+	view, err := app.savedViews.Get(id)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+	if !view.BelongsTo(app.currentUserID(req)) {
+		http.NotFound(res, req)
+		return
+	}
+
+	err = app.savedViews.Delete(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	path := "/"
+	if list, ok := models.LookupFilterList(view.List); ok {
+		path = list.Path
+	}
+	app.flash(req, fmt.Sprintf("View %s deleted", view.Name))
+	app.redirect(res, req, path, http.StatusSeeOther)
+}
+
+// clientsSync handles a GET request which shows the address book sources client details can be synced from
+func (app *application) clientsSync(res http.ResponseWriter, req *http.Request) {
+	data := app.newTemplateData(req)
+	data.Form = contactSyncForm{}
+	app.render(res, req, http.StatusOK, "clients_sync.html", data)
+}
+
+// contactSource builds the address book source selected in the sync form from the application settings
+func (app *application) contactSource(req *http.Request, source string) (contacts.Source, error) {
+	switch source {
+	case "carddav":
+		url, _ := app.settings.GetString("contacts_carddav_url")
+		username, _ := app.settings.GetString("contacts_carddav_username")
+		password, _ := app.settings.GetString("contacts_carddav_password")
+		if url == "" {
+			return nil, errors.New("set the CardDAV address book URL in Settings first")
+		}
+		return contacts.CardDAVSource{URL: url, Username: username, Password: password, Client: &http.Client{Timeout: 30 * time.Second}}, nil
+	case "google":
+		token, _ := app.settings.GetString("contacts_google_access_token")
+		if token == "" {
+			return nil, errors.New("set the Google Contacts access token in Settings first")
+		}
+		return contacts.GoogleSource{AccessToken: token, Client: &http.Client{Timeout: 30 * time.Second}}, nil
+	case "csv":
+		file, _, err := req.FormFile("contacts_file")
+		if err != nil {
+			return nil, errors.New("choose a CSV file to upload")
+		}
+		return contacts.CSVSource{Reader: file}, nil
+	}
+	return nil, errors.New("choose an address book to sync from")
+}
+
+// clientsSyncPreview handles a POST request which fetches contacts from the selected address book
+// and shows the changes they would make to existing clients before anything is saved
+func (app *application) clientsSyncPreview(res http.ResponseWriter, req *http.Request) {
+	err := req.ParseMultipartForm(10 << 20)
+	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	form := contactSyncForm{Source: req.FormValue("source")}
+
+	var fetched []contacts.Contact
+	source, err := app.contactSource(req, form.Source)
+	if err != nil {
+		form.AddFieldError("source", err.Error())
+	} else {
+		fetched, err = source.FetchContacts(req.Context())
+		if err != nil {
+			app.logger.Warn("contact sync failed", "source", form.Source, "error", err.Error())
+			form.AddFieldError("source", fmt.Sprintf("Could not fetch contacts: %v", err))
+		}
+	}
+
+	if !form.Valid() {
+		data := app.newTemplateData(req)
+		data.Form = form
+		app.render(res, req, http.StatusUnprocessableEntity, "clients_sync.html", data)
+		return
+	}
+
+	clients, err := app.clients.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	preview := contacts.Preview(clients, fetched)
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.ContactSync = &preview
+	app.render(res, req, http.StatusOK, "clients_sync.html", data)
+}
+
+// clientsSyncApply handles a POST request which applies the contact changes selected on the preview page
+func (app *application) clientsSyncApply(res http.ResponseWriter, req *http.Request) {
+	var form contactSyncApplyForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	var selected []contacts.Contact
+	for _, row := range append(form.Changes, form.NewContacts...) {
+		if row.Apply {
+			selected = append(selected, contacts.Contact{
+				Name:     row.Name,
+				Email:    row.Email,
+				Phone:    row.Phone,
+				Address1: row.Address1,
+				City:     row.City,
+				State:    row.State,
+				ZipCode:  row.ZipCode,
+			})
+		}
+	}
+
+	hourlyRate, err := app.settings.GetDecimal("default_hourly_rate")
+	if err != nil {
+		hourlyRate = 0
+	}
+
+	// Apply all of the selected changes or none of them
+	var updated, created int
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		clients, err := tx.Clients.GetAll()
+		if err != nil {
+			return err
+		}
+
+		// Compare against the current clients again so that nothing changed since the preview is overwritten blindly
+		preview := contacts.Preview(clients, selected)
+		updated, created = len(preview.Changes), len(preview.NewContacts)
+
+		for _, change := range preview.Changes {
+			err = tx.Clients.Update(contacts.ApplyToClient(change.Client, change.Contact))
+			if err != nil {
+				return err
+			}
+		}
+		for _, contact := range preview.NewContacts {
+			_, err = tx.Clients.Insert(contacts.NewClient(contact, hourlyRate))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, fmt.Sprintf("Contacts synced: %d clients updated, %d created", updated, created))
+	app.redirect(res, req, "/", http.StatusSeeOther)
+}
+
+// errImportPreview rolls back an import that was only run to preview what it would create
+var errImportPreview = errors.New("import preview")
+
+// renderCSVImport shows the import form along with the preview of an upload, if any
+func (app *application) renderCSVImport(res http.ResponseWriter, req *http.Request, status int, form csvImportForm, summary *importer.Summary) {
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.ImportPresets = importer.Presets
+	data.ImportKinds = importer.Kinds
+	data.ImportSummary = summary
+	app.render(res, req, status, "import.html", data)
+}
+
+// csvImport handles a GET request which returns the form for importing another tool's CSV export
+func (app *application) csvImport(res http.ResponseWriter, req *http.Request) {
+	form := csvImportForm{Tool: importer.Presets[0].Name, Kind: string(importer.KindClients), Preview: true}
+	app.renderCSVImport(res, req, http.StatusOK, form, nil)
+}
+
+// csvImportPost handles a POST request which imports the clients, time entries or invoices in
+// an uploaded CSV export. A preview runs the whole import and then rolls it back, so it shows
+// exactly what importing would create.
+func (app *application) csvImportPost(res http.ResponseWriter, req *http.Request) {
+	err := req.ParseMultipartForm(10 << 20)
+	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	form := csvImportForm{
+		Tool:    req.FormValue("tool"),
+		Kind:    req.FormValue("kind"),
+		Preview: req.FormValue("preview") == "true",
+	}
+	preset, ok := importer.PresetByName(form.Tool)
+	form.CheckField(ok, "tool", "Choose the tool the export came from")
+	kind := importer.Kind(form.Kind)
+	form.CheckField(slices.Contains(importer.Kinds, kind), "kind", "Choose what the export holds")
+
+	var batch importer.Batch
+	if form.Valid() {
+		file, _, err := req.FormFile("import_file")
+		if err != nil {
+			form.AddFieldError("import_file", "Choose a CSV file to upload")
+		} else {
+			defer file.Close()
+			batch, err = importer.Read(preset, kind, file)
+			if err != nil {
+				form.AddFieldError("import_file", fmt.Sprintf("Could not read the export: %v", err))
+			}
+		}
+	}
+	if !form.Valid() {
+		app.renderCSVImport(res, req, http.StatusUnprocessableEntity, form, nil)
+		return
+	}
+
+	hourlyRate, err := app.settings.GetDecimal("default_hourly_rate")
+	if err != nil {
+		hourlyRate = 0
+	}
+	currency, err := app.settings.GetString("payment_domestic_currency")
+	if err != nil || currency == "" {
+		currency = "USD"
+	}
+	options := importer.Options{HourlyRate: hourlyRate, Currency: currency, MaxDescription: app.descriptionLength()}
+
+	var summary importer.Summary
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		summary, err = importer.Apply(tx, preset, batch, options)
+		if err == nil && form.Preview {
+			return errImportPreview
+		}
+		return err
+	})
+	if err != nil && !errors.Is(err, errImportPreview) {
+		app.modelError(res, req, err)
+		return
+	}
+
+	if form.Preview {
+		app.renderCSVImport(res, req, http.StatusOK, form, &summary)
+		return
+	}
+
+	message := fmt.Sprintf("Imported from %s: %d clients, %d projects, %d timesheet entries and %d invoices created, %d rows already here skipped",
+		preset.Label, summary.ClientsCreated, summary.ProjectsCreated, summary.TimesheetsCreated, summary.InvoicesCreated, summary.Skipped)
+	if len(summary.Problems) > 0 {
+		message += fmt.Sprintf(", %d rows couldn't be read", len(summary.Problems))
+	}
+	app.flash(req, message)
+	app.redirect(res, req, "/", http.StatusSeeOther)
+}
+
+// renderBankStatement shows the bank statement upload form along with the payments proposed
+// from an upload, if any
+func (app *application) renderBankStatement(res http.ResponseWriter, req *http.Request, status int, form bankStatementForm, statement *bankstatement.Statement, matches []bankstatement.Match) {
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.BankStatement = statement
+	data.DepositMatches = matches
+	app.render(res, req, status, "reconcile.html", data)
+}
+
+// reconcile handles a GET request which returns the form for uploading a bank statement
+func (app *application) reconcile(res http.ResponseWriter, req *http.Request) {
+	app.renderBankStatement(res, req, http.StatusOK, bankStatementForm{}, nil, nil)
+}
+
+// reconcilePost handles a POST request with a bank statement CSV. Nothing is saved yet: the
+// deposits on the statement are matched to open invoices and the proposed payments shown
+// for confirming.
+func (app *application) reconcilePost(res http.ResponseWriter, req *http.Request) {
+	err := req.ParseMultipartForm(10 << 20)
+	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	var form bankStatementForm
+	var statement bankstatement.Statement
+	file, _, err := req.FormFile("statement_file")
+	if err != nil {
+		form.AddFieldError("statement_file", "Choose a CSV file to upload")
+	} else {
+		defer file.Close()
+		statement, err = bankstatement.Read(file, app.dateFormat(req))
+		if err != nil {
+			form.AddFieldError("statement_file", fmt.Sprintf("Could not read the statement: %v", err))
+		}
+	}
+	if !form.Valid() {
+		app.renderBankStatement(res, req, http.StatusUnprocessableEntity, form, nil, nil)
+		return
+	}
+
+	invoices, err := app.invoices.GetOpen()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	matches := bankstatement.MatchDeposits(statement.Deposits, invoices)
+	app.renderBankStatement(res, req, http.StatusOK, form, &statement, matches)
+}
+
+// statementPayment is a payment confirmed from a bank statement
+type statementPayment struct {
+	invoice  models.InvoiceWithClient
+	datePaid time.Time
+	amount   float64
+}
+
+// reconcileConfirmPost handles a POST request which records the payments ticked among those
+// proposed from a bank statement. They are recorded in one transaction, so if any of the
+// invoices has been paid or voided since the statement was uploaded none of them are.
+func (app *application) reconcileConfirmPost(res http.ResponseWriter, req *http.Request) {
+	var form bankStatementForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	payments := make([]statementPayment, 0, len(form.Payments))
+	seen := make(map[int]bool)
+	for _, value := range form.Payments {
+		parts := strings.Split(value, "|")
+		if len(parts) != 3 {
+			app.clientError(res, http.StatusBadRequest)
+			return
+		}
+		id, errID := strconv.Atoi(parts[0])
+		datePaid, errDate := time.Parse("2006-01-02", parts[1])
+		amount, errAmount := strconv.ParseFloat(parts[2], 64)
+		if errID != nil || errDate != nil || errAmount != nil || amount <= 0 || seen[id] {
+			app.clientError(res, http.StatusBadRequest)
+			return
+		}
+		seen[id] = true
+
+		invoice, err := app.invoices.GetWithClient(id)
+		if err != nil {
+			app.modelError(res, req, err)
+			return
+		}
+		if invoice.DatePaid != nil || invoice.IsVoided() {
+			app.flash(req, fmt.Sprintf("Invoice #%s has been paid or voided since the statement was uploaded, so no payments were recorded. Upload the statement again to see what is still open.", invoice.DisplayNumber()))
+			app.redirect(res, req, "/invoices/reconcile", http.StatusSeeOther)
+			return
+		}
+		payments = append(payments, statementPayment{invoice: invoice, datePaid: datePaid, amount: amount})
+	}
+	if len(payments) == 0 {
+		app.flash(req, "No payments were ticked, so none were recorded")
+		app.redirect(res, req, "/invoices/reconcile", http.StatusSeeOther)
+		return
+	}
+
+	err = app.transactions.WithinTx(req.Context(), func(tx models.TxModels) error {
+		for _, payment := range payments {
+			invoice := payment.invoice.Invoice
+			err := tx.Invoices.MarkPaid(invoice.ID, payment.datePaid)
+			if err != nil {
+				return err
+			}
+
+			err = recordInvoiceEvents(tx, invoice.ID, nil, &payment.datePaid, models.InvoiceEventUpdated)
+			if err != nil {
+				return err
+			}
+
+			amountPaid := invoice.TotalPaid() + payment.amount
+			err = settleInvoice(tx, payment.invoice.ClientID, invoice.ID, &amountPaid, false)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, models.ErrInvoicePaid) || errors.Is(err, models.ErrInvoiceVoided) {
+		app.flash(req, "An invoice was paid or voided while the payments were being recorded, so none were. Upload the statement again to see what is still open.")
+		app.redirect(res, req, "/invoices/reconcile", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	for _, payment := range payments {
+		app.notifyInvoicePaid(payment.invoice.ID)
+	}
+	app.flash(req, fmt.Sprintf("Payments recorded for %d invoices", len(payments)))
+	app.redirect(res, req, "/invoices/reconcile", http.StatusSeeOther)
+}
+
+// renderLogin renders the login form, offering to remember the login when that is allowed
+func (app *application) renderLogin(res http.ResponseWriter, req *http.Request, status int, form userLoginForm) {
+	data := app.newTemplateData(req)
+	data.Form = form
+	if app.rememberMeFor > 0 {
+		data.RememberMeFor = describeLifetime(app.rememberMeFor)
+	}
+	app.render(res, req, status, "login.html", data)
+}
+
+// userLogin handles a GET request which returns the login form
+func (app *application) userLogin(res http.ResponseWriter, req *http.Request) {
+	app.renderLogin(res, req, http.StatusOK, userLoginForm{})
+}
+
+// userLoginPost handles a POST request with login credentials and starts an authenticated session
+func (app *application) userLoginPost(res http.ResponseWriter, req *http.Request) {
+	var form userLoginForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Email), "email", "Email is required")
+	form.CheckField(validator.NotBlank(form.Password), "password", "Password is required")
+
+	var id int
+	if form.Valid() {
+		id, err = app.users.Authenticate(form.Email, form.Password)
+		if err != nil {
+			if !errors.Is(err, models.ErrInvalidCredentials) {
+				app.serverError(res, req, err)
+				return
+			}
+			form.AddFieldError("credentials", "Email or password is incorrect")
+		}
+	}
+
+	if !form.Valid() {
+		form.Password = ""
+		app.renderLogin(res, req, http.StatusUnprocessableEntity, form)
+		return
+	}
+
+	user, err := app.users.Get(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	// Use a new session token whenever the privilege level changes
+	err = app.sessionManager.RenewToken(req.Context())
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	app.sessionManager.Put(req.Context(), "authenticatedUserID", id)
+	err = app.startUserSession(req, id, form.RememberMe && app.rememberMeFor > 0)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	landingPage := "/projects"
+	if user.IsOwner() {
+		landingPage = "/"
+	}
+	preferences, err := app.preferences.Get(id)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	if page, ok := models.LookupLandingPage(preferences.LandingPage); ok && (user.IsOwner() || !page.OwnerOnly) {
+		landingPage = page.Path
+	}
+	app.redirect(res, req, landingPage, http.StatusSeeOther)
+}
+
+// landingPagesFor lists the landing pages the user is allowed to choose
+func landingPagesFor(user *models.User) []models.LandingPage {
+	var pages []models.LandingPage
+	for _, page := range models.LandingPages {
+		if user.IsOwner() || !page.OwnerOnly {
+			pages = append(pages, page)
+		}
+	}
+	return pages
+}
+
+// renderUserPreferences renders the preferences form with the choices it offers
+func (app *application) renderUserPreferences(res http.ResponseWriter, req *http.Request, status int, user *models.User, form userPreferencesForm) {
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.LandingPages = landingPagesFor(user)
+	data.Themes = models.Themes
+	data.DateFormats = models.DateFormats
+	app.render(res, req, status, "user_preferences.html", data)
+}
+
+// userPreferencesEdit handles a GET request which displays the logged in user's preferences
+func (app *application) userPreferencesEdit(res http.ResponseWriter, req *http.Request) {
+	user := app.currentUser(req)
+	if user == nil {
+		http.NotFound(res, req)
+		return
+	}
+
+	preferences, err := app.preferences.Get(user.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	form := userPreferencesForm{
+		LandingPage: preferences.LandingPage,
+		DateFormat:  preferences.DateFormat,
+		Theme:       preferences.Theme,
+	}
+	if preferences.PageSize != nil {
+		form.PageSize = strconv.Itoa(*preferences.PageSize)
+	}
+	app.renderUserPreferences(res, req, http.StatusOK, user, form)
+}
+
+// userPreferencesEditPost handles a POST request which saves the logged in user's preferences.
+// Fields left blank fall back to the global settings.
+func (app *application) userPreferencesEditPost(res http.ResponseWriter, req *http.Request) {
+	user := app.currentUser(req)
+	if user == nil {
+		http.NotFound(res, req)
+		return
+	}
+
+	var form userPreferencesForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	preferences := models.UserPreferences{
+		UserID: user.ID,
+		Theme:  form.Theme,
+	}
+
+	if form.PageSize = strings.TrimSpace(form.PageSize); form.PageSize != "" {
+		pageSize, err := strconv.Atoi(form.PageSize)
+		form.CheckField(err == nil && pageSize >= 1 && pageSize <= 100, "page_size", "Items per page must be a whole number from 1 to 100")
+		preferences.PageSize = &pageSize
+	}
+
+	if form.LandingPage != "" {
+		page, ok := models.LookupLandingPage(form.LandingPage)
+		form.CheckField(ok && (user.IsOwner() || !page.OwnerOnly), "landing_page", "Choose one of the listed pages")
+		preferences.LandingPage = form.LandingPage
+	}
+
+	if form.DateFormat != "" {
+		format := models.ParseDateFormat(form.DateFormat)
+		form.CheckField(string(format) == form.DateFormat, "date_format", "Date format must be one of "+joinDateFormats(models.DateFormats))
+		preferences.DateFormat = string(format)
+	}
+
+	form.CheckField(models.IsTheme(form.Theme), "theme", "Choose one of the listed themes")
+
+	if !form.Valid() {
+		app.renderUserPreferences(res, req, http.StatusUnprocessableEntity, user, form)
+		return
+	}
+
+	err = app.preferences.Save(preferences)
+	if err != nil {
+		app.modelError(res, req, err)
+		return
+	}
+
+	app.flash(req, "Preferences saved")
+	app.redirect(res, req, "/user/preferences", http.StatusSeeOther)
+}
+
+// userLogoutPost handles a POST request which ends the authenticated session
+func (app *application) userLogoutPost(res http.ResponseWriter, req *http.Request) {
+	if session, ok := app.currentUserSession(req); ok {
+		err := app.userSessions.Delete(session.ID)
+		if err != nil {
+			app.serverError(res, req, err)
+			return
+		}
+	}
+
+	err := app.sessionManager.RenewToken(req.Context())
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+	app.sessionManager.Remove(req.Context(), "authenticatedUserID")
+	app.sessionManager.Remove(req.Context(), "userSessionToken")
+	app.sessionManager.RememberMe(req.Context(), false)
+
+	app.redirect(res, req, "/user/login", http.StatusSeeOther)
+}
+
+// userSessionsList handles a GET request which lists where the logged in user is logged in
+func (app *application) userSessionsList(res http.ResponseWriter, req *http.Request) {
+	user := app.currentUser(req)
+	current, ok := app.currentUserSession(req)
+	if user == nil || !ok {
+		http.NotFound(res, req)
+		return
+	}
+
+	sessions, err := app.userSessions.GetByUser(user.ID, time.Now())
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.UserSessions = sessions
+	data.CurrentSessionID = current.ID
+	app.render(res, req, http.StatusOK, "user_sessions.html", data)
+}
+
+// userSessionsLogoutOthersPost handles a POST request which logs the user out everywhere
+// but the session making the request
+func (app *application) userSessionsLogoutOthersPost(res http.ResponseWriter, req *http.Request) {
+	user := app.currentUser(req)
+	current, ok := app.currentUserSession(req)
+	if user == nil || !ok {
+		http.NotFound(res, req)
+		return
+	}
+
+	count, err := app.userSessions.DeleteOthers(user.ID, current.ID)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	switch count {
+	case 0:
+		app.flash(req, "You weren't logged in anywhere else")
+	case 1:
+		app.flash(req, "Logged out of 1 other session")
+	default:
+		app.flash(req, fmt.Sprintf("Logged out of %d other sessions", count))
+	}
+	app.redirect(res, req, "/user/sessions", http.StatusSeeOther)
+}
+
+// usersList handles a GET request which lists the user accounts
+func (app *application) usersList(res http.ResponseWriter, req *http.Request) {
+	users, err := app.users.GetAll()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Users = users
+	app.render(res, req, http.StatusOK, "users.html", data)
+}
+
+// userCreate handles a GET request which returns an empty user creation form
+func (app *application) userCreate(res http.ResponseWriter, req *http.Request) {
+	data := app.newTemplateData(req)
+	data.Form = userForm{Role: models.RoleSubcontractor}
+	app.render(res, req, http.StatusOK, "user_create.html", data)
+}
+
+// userCreatePost handles a POST request with user form data which is then
+// validated and used to create a new user account
+func (app *application) userCreatePost(res http.ResponseWriter, req *http.Request) {
+	var form userForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(res, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Name), "name", "Name is required")
+	form.CheckField(validator.MaxChars(form.Name, NAME_LENGTH), "name", fmt.Sprintf("Name must be shorter than %d characters", NAME_LENGTH))
+	form.CheckField(validator.NotBlank(form.Email), "email", "Email is required")
+	form.CheckField(validator.Matches(strings.ToLower(form.Email), validator.EmailRegex), "email", "Email must be a valid email address")
+	form.CheckField(validator.MinChars(form.Password, MIN_PASSWORD_LENGTH), "password", fmt.Sprintf("Password must be at least %d characters long", MIN_PASSWORD_LENGTH))
+	form.CheckField(form.Role == models.RoleOwner || form.Role == models.RoleSubcontractor, "role", "Role must be owner or subcontractor")
+
+	// The first account has to be an owner, otherwise nobody could manage the application once logins are required
+	count, err := app.users.Count()
+	if err != nil {
+		app.serverError(res, req, err)
+		return
 	}
-
-	// Parse and validate amount due
-	var amountDue float64
-	if form.Valid() {
-		amountDue, err = strconv.ParseFloat(form.AmountDue, 64)
-		if err != nil || amountDue < 0 {
-			form.AddFieldError("amount_due", "Amount due must be a positive number")
-		}
+	if count == 0 {
+		form.CheckField(form.Role == models.RoleOwner, "role", "The first user must be an owner")
 	}
 
-	// Parse date paid if provided
-	var datePaid *time.Time
-	if form.Valid() && form.DatePaid != "" {
-		parsedDatePaid, err := time.Parse("2006-01-02", form.DatePaid)
+	if form.Valid() {
+		_, err = app.users.Insert(models.User{Name: form.Name, Email: form.Email, Role: form.Role}, form.Password)
 		if err != nil {
-			form.AddFieldError("date_paid", "Date paid must be in YYYY-MM-DD format")
-		} else {
-			datePaid = &parsedDatePaid
+			if !errors.Is(err, models.ErrDuplicateEmail) {
+				app.modelError(res, req, err)
+				return
+			}
+			form.AddFieldError("email", "Email address is already in use")
 		}
 	}
 
 	if !form.Valid() {
+		form.Password = ""
 		data := app.newTemplateData(req)
 		data.Form = form
-		data.Project = &project
-		data.Client = &client
-		app.render(res, req, http.StatusUnprocessableEntity, "invoice_create.html", data)
-		return
-	}
-
-	_, err = app.invoices.Insert(projectID, invoiceDate, datePaid, form.PaymentTerms, amountDue, form.DisplayDetails)
-	if err != nil {
-		app.serverError(res, req, err)
+		app.render(res, req, http.StatusUnprocessableEntity, "user_create.html", data)
 		return
 	}
-	http.Redirect(res, req, fmt.Sprintf("/project/view/%d", projectID), http.StatusSeeOther)
+	app.flash(req, fmt.Sprintf("User %s created", form.Name))
+	app.redirect(res, req, "/users", http.StatusSeeOther)
 }
 
-// invoiceUpdate handles a GET request which returns an invoice update form pre-populated with invoice data
-func (app *application) invoiceUpdate(res http.ResponseWriter, req *http.Request) {
+// userDelete handles a POST request to soft delete a user account
+func (app *application) userDelete(res http.ResponseWriter, req *http.Request) {
 	id, err := strconv.Atoi(req.PathValue("id"))
 	if err != nil || id < 0 {
 		http.NotFound(res, req)
 		return
 	}
 
-	invoice, err := app.invoices.Get(id)
+	user, err := app.users.Get(id)
 	if err != nil {
 		if errors.Is(err, models.ErrNoRecord) {
 			http.NotFound(res, req)
@@ -1384,68 +9474,30 @@ func (app *application) invoiceUpdate(res http.ResponseWriter, req *http.Request
 		return
 	}
 
-	// Get the project for context
-	project, err := app.projects.Get(invoice.ProjectID)
-	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(res, req)
-		} else {
-			app.serverError(res, req, err)
-		}
+	// Don't let the logged in owner lock themselves out
+	if current := app.currentUser(req); current != nil && current.ID == id {
+		app.clientError(res, http.StatusConflict)
 		return
 	}
 
-	// Get the client for context
-	client, err := app.clients.Get(project.ClientID)
+	err = app.users.Delete(id)
 	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(res, req)
-		} else {
-			app.serverError(res, req, err)
-		}
+		app.modelError(res, req, err)
 		return
 	}
-
-	var datePaidStr string
-	if invoice.DatePaid != nil {
-		datePaidStr = invoice.DatePaid.Format("2006-01-02")
-	}
-
-	data := app.newTemplateData(req)
-	data.Form = invoiceForm{
-		InvoiceDate:    invoice.InvoiceDate.Format("2006-01-02"),
-		DatePaid:       datePaidStr,
-		PaymentTerms:   invoice.PaymentTerms,
-		AmountDue:      fmt.Sprintf("%.2f", invoice.AmountDue),
-		DisplayDetails: invoice.DisplayDetails,
-	}
-	data.Project = &project
-	data.Client = &client
-	app.render(res, req, http.StatusOK, "invoice_create.html", data)
+	app.flash(req, fmt.Sprintf("User %s deleted", user.Name))
+	app.redirect(res, req, "/users", http.StatusSeeOther)
 }
 
-// invoiceUpdatePost handles a POST request with invoice form data which is then
-// validated and used to update an existing invoice in the database
-func (app *application) invoiceUpdatePost(res http.ResponseWriter, req *http.Request) {
-	id, err := strconv.Atoi(req.PathValue("id"))
-	if err != nil || id < 0 {
+// projectSharePost handles a POST request which shares a project with a subcontractor
+func (app *application) projectSharePost(res http.ResponseWriter, req *http.Request) {
+	projectID, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || projectID < 0 {
 		http.NotFound(res, req)
 		return
 	}
 
-	// Get the invoice to ensure it exists and get the project ID
-	invoice, err := app.invoices.Get(id)
-	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(res, req)
-		} else {
-			app.serverError(res, req, err)
-		}
-		return
-	}
-
-	// Get project and client for context
-	project, err := app.projects.Get(invoice.ProjectID)
+	_, err = app.projects.Get(projectID)
 	if err != nil {
 		if errors.Is(err, models.ErrNoRecord) {
 			http.NotFound(res, req)
@@ -1455,293 +9507,432 @@ func (app *application) invoiceUpdatePost(res http.ResponseWriter, req *http.Req
 		return
 	}
 
-	client, err := app.clients.Get(project.ClientID)
+	err = req.ParseForm()
 	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(res, req)
-		} else {
-			app.serverError(res, req, err)
-		}
+		app.clientError(res, http.StatusBadRequest)
 		return
 	}
 
-	var form invoiceForm
-	err = app.decodePostForm(req, &form)
+	userID, err := strconv.Atoi(req.PostForm.Get("user_id"))
 	if err != nil {
 		app.clientError(res, http.StatusBadRequest)
 		return
 	}
 
-	form.CheckField(validator.NotBlank(form.InvoiceDate), "invoice_date", "Invoice date is required")
-	form.CheckField(validator.NotBlank(form.AmountDue), "amount_due", "Amount due is required")
-	form.CheckField(validator.MaxChars(form.PaymentTerms, NAME_LENGTH), "payment_terms", fmt.Sprintf("Payment terms must be shorter than %d characters", NAME_LENGTH))
-
-	// Parse and validate invoice date
-	var invoiceDate time.Time
-	if form.Valid() {
-		invoiceDate, err = time.Parse("2006-01-02", form.InvoiceDate)
-		if err != nil {
-			form.AddFieldError("invoice_date", "Invoice date must be in YYYY-MM-DD format")
-		}
-	}
-
-	// Parse and validate amount due
-	var amountDue float64
-	if form.Valid() {
-		amountDue, err = strconv.ParseFloat(form.AmountDue, 64)
-		if err != nil || amountDue < 0 {
-			form.AddFieldError("amount_due", "Amount due must be a positive number")
-		}
-	}
-
-	// Parse date paid if provided
-	var datePaid *time.Time
-	if form.Valid() && form.DatePaid != "" {
-		parsedDatePaid, err := time.Parse("2006-01-02", form.DatePaid)
-		if err != nil {
-			form.AddFieldError("date_paid", "Date paid must be in YYYY-MM-DD format")
+	user, err := app.users.Get(userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.clientError(res, http.StatusBadRequest)
 		} else {
-			datePaid = &parsedDatePaid
+			app.serverError(res, req, err)
 		}
+		return
 	}
 
-	if !form.Valid() {
-		data := app.newTemplateData(req)
-		data.Form = form
-		data.Project = &project
-		data.Client = &client
-		app.render(res, req, http.StatusUnprocessableEntity, "invoice_create.html", data)
+	// Owners can already see every project
+	if user.IsOwner() {
+		app.clientError(res, http.StatusBadRequest)
 		return
 	}
 
-	err = app.invoices.Update(id, invoiceDate, datePaid, form.PaymentTerms, amountDue, form.DisplayDetails)
+	err = app.users.ShareProject(projectID, userID)
 	if err != nil {
 		app.serverError(res, req, err)
 		return
 	}
-	http.Redirect(res, req, fmt.Sprintf("/project/view/%d", invoice.ProjectID), http.StatusSeeOther)
+	app.flash(req, fmt.Sprintf("Project shared with %s", user.Name))
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", projectID), http.StatusSeeOther)
 }
 
-// invoiceDelete handles a POST request to soft delete an invoice
-func (app *application) invoiceDelete(res http.ResponseWriter, req *http.Request) {
-	id, err := strconv.Atoi(req.PathValue("id"))
-	if err != nil || id < 0 {
+// projectUnsharePost handles a POST request which removes a subcontractor's access to a project
+func (app *application) projectUnsharePost(res http.ResponseWriter, req *http.Request) {
+	projectID, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || projectID < 0 {
 		http.NotFound(res, req)
 		return
 	}
 
-	// Check if invoice exists before deleting and get project ID for redirect
-	invoice, err := app.invoices.Get(id)
-	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(res, req)
-		} else {
-			app.serverError(res, req, err)
-		}
+	userID, err := strconv.Atoi(req.PathValue("userID"))
+	if err != nil || userID < 0 {
+		http.NotFound(res, req)
 		return
 	}
 
-	err = app.invoices.Delete(id)
+	err = app.users.UnshareProject(projectID, userID)
 	if err != nil {
 		app.serverError(res, req, err)
 		return
 	}
-
-	// Redirect to project view page after successful deletion
-	http.Redirect(res, req, fmt.Sprintf("/project/view/%d", invoice.ProjectID), http.StatusSeeOther)
+	app.flash(req, "Project access removed")
+	app.redirect(res, req, fmt.Sprintf("/project/view/%d", projectID), http.StatusSeeOther)
 }
 
-// invoicePrint handles a GET request to generate and download an invoice PDF
-func (app *application) invoicePrint(res http.ResponseWriter, req *http.Request) {
-	id, err := strconv.Atoi(req.PathValue("id"))
-	if err != nil || id < 0 {
-		http.NotFound(res, req)
+// marginsReport handles a GET request which compares the billed amount of logged time
+// with its internal cost, per client and per project
+func (app *application) marginsReport(res http.ResponseWriter, req *http.Request) {
+	projectMargins, err := app.reports.ProjectMargins()
+	if err != nil {
+		app.serverError(res, req, err)
 		return
 	}
 
-	// Get settings for PDF generation
-	allSettings, err := app.settings.GetAll()
+	var total models.Margin
+	for _, project := range projectMargins {
+		total.Hours += project.Hours
+		total.Billed += project.Billed
+		total.Cost += project.Cost
+	}
+
+	data := app.newTemplateData(req)
+	data.ProjectMargins = projectMargins
+	data.ClientMargins = models.ClientMargins(projectMargins)
+	data.Margin = &total
+	app.render(res, req, http.StatusOK, "margins.html", data)
+}
+
+// clientValuesReport handles a GET request which lists every client's lifetime value, ordered
+// by the sort query parameter and largest amount billed first by default
+func (app *application) clientValuesReport(res http.ResponseWriter, req *http.Request) {
+	values, err := app.reports.ClientValues()
 	if err != nil {
 		app.serverError(res, req, err)
 		return
 	}
 
-	// Generate professional PDF with comprehensive data and settings
-	pdfBytes, err := app.invoices.GenerateComprehensivePDF(id, allSettings)
-	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(res, req)
-		} else {
-			app.serverError(res, req, err)
-		}
-		return
+	sort := models.ParseClientValueSort(req.URL.Query().Get("sort"))
+	models.SortClientValues(values, sort)
+
+	var total models.ClientValue
+	for _, value := range values {
+		total.Invoices += value.Invoices
+		total.Billed += value.Billed
+		total.Paid += value.Paid
 	}
 
-	// Set headers for PDF download
-	res.Header().Set("Content-Type", "application/pdf")
-	res.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"invoice_%d.pdf\"", id))
-	res.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+	data := app.newTemplateData(req)
+	data.ClientValues = values
+	data.ClientValueSort = sort
+	data.ClientValue = &total
+	app.render(res, req, http.StatusOK, "client_values.html", data)
+}
 
-	// Write PDF to response
-	_, err = res.Write(pdfBytes)
+// receivablesForecast handles a GET request which projects the income expected each week from
+// unpaid invoices, based on their due dates and how late each client usually pays
+func (app *application) receivablesForecast(res http.ResponseWriter, req *http.Request) {
+	forecast, err := app.reports.ReceivablesForecast(time.Now())
 	if err != nil {
 		app.serverError(res, req, err)
 		return
 	}
+
+	data := app.newTemplateData(req)
+	data.Forecast = &forecast
+	app.render(res, req, http.StatusOK, "forecast.html", data)
 }
 
-// settingsView handles a GET request to view all application settings
-func (app *application) settingsView(res http.ResponseWriter, req *http.Request) {
-	settings, err := app.settings.GetAllDetailed()
+// workInProgressReport handles a GET request which lists the work logged on each project that
+// hasn't been invoiced yet, aged by its oldest unbilled entry
+func (app *application) workInProgressReport(res http.ResponseWriter, req *http.Request) {
+	wip, err := app.reports.WorkInProgress(time.Now())
 	if err != nil {
 		app.serverError(res, req, err)
 		return
 	}
 
 	data := app.newTemplateData(req)
-	data.Settings = settings
-
-	app.render(res, req, http.StatusOK, "settings.html", data)
+	data.WorkInProgress = &wip
+	app.render(res, req, http.StatusOK, "wip.html", data)
 }
 
-// settingsEdit handles a GET request to display the settings edit form
-func (app *application) settingsEdit(res http.ResponseWriter, req *http.Request) {
-	settings, err := app.settings.GetAllDetailed()
+// trendsReport handles a GET request which compares this month and this year so far with the
+// same stretches of last month and last year, with the totals of the past twelve months
+func (app *application) trendsReport(res http.ResponseWriter, req *http.Request) {
+	trends, err := app.reports.Trends(time.Now())
 	if err != nil {
 		app.serverError(res, req, err)
 		return
 	}
 
 	data := app.newTemplateData(req)
-	data.Settings = settings
-	data.Form = settingsForm{}
+	data.Trends = &trends
+	app.render(res, req, http.StatusOK, "trends.html", data)
+}
 
-	app.render(res, req, http.StatusOK, "settings_edit.html", data)
+const (
+	API_DEFAULT_LIMIT = 50
+	API_MAX_LIMIT     = 100
+)
+
+// apiInvoice is the flat JSON shape of an invoice in the API and webhooks. Field names are
+// part of the contract with no-code tools, so they shouldn't be renamed.
+type apiInvoice struct {
+	ID            int      `json:"id"`
+	InvoiceNumber string   `json:"invoice_number"`
+	Status        string   `json:"status"`
+	InvoiceDate   string   `json:"invoice_date"`
+	DatePaid      *string  `json:"date_paid"`
+	PaymentTerms  string   `json:"payment_terms"`
+	Currency      string   `json:"currency"`
+	AmountDue     float64  `json:"amount_due"`
+	CreditApplied float64  `json:"credit_applied"`
+	BalanceDue    float64  `json:"balance_due"`
+	AmountPaid    *float64 `json:"amount_paid"`
+	VoidReason    string   `json:"void_reason"`
+	ProjectID     int      `json:"project_id"`
+	ProjectName   string   `json:"project_name"`
+	ClientID      int      `json:"client_id"`
+	ClientName    string   `json:"client_name"`
+	CreatedAt     string   `json:"created_at"`
+	UpdatedAt     string   `json:"updated_at"`
 }
 
-// settingsEditPost handles a POST request to update application settings
-func (app *application) settingsEditPost(res http.ResponseWriter, req *http.Request) {
-	// Get current settings to know what to expect
-	settings, err := app.settings.GetAllDetailed()
+// apiInvoiceEvent is an invoice lifecycle event, flattened together with the invoice
+type apiInvoiceEvent struct {
+	EventID    int    `json:"event_id"`
+	EventType  string `json:"event_type"`
+	OccurredAt string `json:"occurred_at"`
+	apiInvoice
+}
+
+// apiPage is a page of API results. Passing next_cursor back as the cursor parameter
+// fetches the following page.
+type apiPage struct {
+	Data       any     `json:"data"`
+	NextCursor *string `json:"next_cursor"`
+	HasMore    bool    `json:"has_more"`
+}
+
+// invoiceStatus describes where an invoice stands as unpaid, paid, void or deleted
+func invoiceStatus(invoice models.Invoice) string {
+	switch {
+	case invoice.DeletedAt != nil:
+		return "deleted"
+	case invoice.IsVoided():
+		return "void"
+	case invoice.DatePaid != nil:
+		return "paid"
+	}
+	return "unpaid"
+}
+
+func newAPIInvoice(invoice models.InvoiceWithClient) apiInvoice {
+	var datePaid *string
+	if invoice.DatePaid != nil {
+		formatted := invoice.DatePaid.Format("2006-01-02")
+		datePaid = &formatted
+	}
+
+	return apiInvoice{
+		ID:            invoice.ID,
+		InvoiceNumber: invoice.InvoiceNumber,
+		Status:        invoiceStatus(invoice.Invoice),
+		InvoiceDate:   invoice.InvoiceDate.Format("2006-01-02"),
+		DatePaid:      datePaid,
+		PaymentTerms:  invoice.PaymentTerms,
+		Currency:      invoice.Currency,
+		AmountDue:     invoice.AmountDue,
+		CreditApplied: invoice.CreditApplied,
+		BalanceDue:    invoice.BalanceDue(),
+		AmountPaid:    invoice.AmountPaid,
+		VoidReason:    invoice.VoidReason,
+		ProjectID:     invoice.ProjectID,
+		ProjectName:   invoice.ProjectName,
+		ClientID:      invoice.ClientID,
+		ClientName:    invoice.ClientName,
+		CreatedAt:     invoice.Created.UTC().Format(time.RFC3339),
+		UpdatedAt:     invoice.Updated.UTC().Format(time.RFC3339),
+	}
+}
+
+// apiPagination reads the cursor and limit query parameters
+func apiPagination(req *http.Request) (cursor, limit int, err error) {
+	limit = API_DEFAULT_LIMIT
+	if value := req.URL.Query().Get("cursor"); value != "" {
+		cursor, err = strconv.Atoi(value)
+		if err != nil || cursor < 0 {
+			return 0, 0, errors.New("cursor must be a value returned as next_cursor")
+		}
+	}
+	if value := req.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > API_MAX_LIMIT {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", API_MAX_LIMIT)
+		}
+	}
+	return cursor, limit, nil
+}
+
+// newAPIPage builds a page from items fetched with one more than the limit, so that a
+// full page can tell whether anything follows it
+func newAPIPage[T any](items []T, limit int, cursorOf func(T) int) apiPage {
+	page := apiPage{Data: items}
+	if len(items) > limit {
+		items = items[:limit]
+		cursor := strconv.Itoa(cursorOf(items[len(items)-1]))
+		page.Data = items
+		page.NextCursor = &cursor
+		page.HasMore = true
+	}
+	return page
+}
+
+// apiInvoicesList handles a GET request for invoices in ID order, paged by cursor
+func (app *application) apiInvoicesList(res http.ResponseWriter, req *http.Request) {
+	cursor, limit, err := apiPagination(req)
 	if err != nil {
-		app.serverError(res, req, err)
+		app.apiError(res, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// Parse form
-	err = req.ParseForm()
+	invoices, err := app.invoices.GetWithClientAfter(cursor, limit+1)
 	if err != nil {
-		app.clientError(res, http.StatusBadRequest)
+		app.serverError(res, req, err)
 		return
 	}
 
-	var form settingsForm
-	form.Settings = make(map[string]string)
+	items := make([]apiInvoice, len(invoices))
+	for i, invoice := range invoices {
+		items[i] = newAPIInvoice(invoice)
+	}
+	app.writeJSON(res, req, http.StatusOK, newAPIPage(items, limit, func(item apiInvoice) int { return item.ID }))
+}
 
-	// Extract values from form for each setting
-	for _, setting := range settings {
-		if value := req.PostForm.Get(setting.Key); value != "" {
-			form.Settings[setting.Key] = value
-		}
+// apiInvoiceView handles a GET request for a single invoice
+func (app *application) apiInvoiceView(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil || id < 0 {
+		app.apiError(res, http.StatusNotFound, "invoice not found")
+		return
 	}
 
-	// Validate each setting based on its data type
-	for _, setting := range settings {
-		value, exists := form.Settings[setting.Key]
-		if !exists {
-			form.AddFieldError(setting.Key, "This field is required")
-			continue
+	invoice, err := app.invoices.GetWithClient(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.apiError(res, http.StatusNotFound, "invoice not found")
+		} else {
+			app.serverError(res, req, err)
 		}
+		return
+	}
 
-		switch setting.DataType {
-		case "decimal", "float":
-			if _, err := strconv.ParseFloat(value, 64); err != nil {
-				form.AddFieldError(setting.Key, "Must be a valid number")
-			}
-		case "int":
-			if _, err := strconv.Atoi(value); err != nil {
-				form.AddFieldError(setting.Key, "Must be a valid integer")
-			}
-		case "bool":
-			if value != "true" && value != "false" {
-				form.AddFieldError(setting.Key, "Must be true or false")
-			}
-		}
+	app.writeJSON(res, req, http.StatusOK, newAPIInvoice(invoice))
+}
+
+// apiInvoiceEventsList handles a GET request for invoice lifecycle events, oldest first and
+// paged by cursor, so that polling triggers can pick up where they left off
+func (app *application) apiInvoiceEventsList(res http.ResponseWriter, req *http.Request) {
+	cursor, limit, err := apiPagination(req)
+	if err != nil {
+		app.apiError(res, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// If there are validation errors, redisplay the form
-	if !form.Valid() {
-		data := app.newTemplateData(req)
-		data.Settings = settings
-		data.Form = form
-		app.render(res, req, http.StatusUnprocessableEntity, "settings_edit.html", data)
+	query := req.URL.Query().Get("event_type")
+	if query != "" && !models.IsInvoiceEventType(query) {
+		app.apiError(res, http.StatusBadRequest, "event_type must be created, updated, paid, voided or deleted")
 		return
 	}
 
-	// Update each setting value
-	for _, setting := range settings {
-		if newValue, exists := form.Settings[setting.Key]; exists {
-			err = app.settings.UpdateValue(setting.Key, newValue)
-			if err != nil {
-				app.serverError(res, req, err)
-				return
+	events, err := app.invoiceEvents.GetAfter(cursor, limit+1)
+	if err != nil {
+		app.serverError(res, req, err)
+		return
+	}
+
+	items := make([]apiInvoiceEvent, 0, len(events))
+	for _, event := range events {
+		items = append(items, apiInvoiceEvent{
+			EventID:    event.ID,
+			EventType:  event.Type,
+			OccurredAt: event.Occurred.UTC().Format(time.RFC3339),
+			apiInvoice: newAPIInvoice(event.Invoice),
+		})
+	}
+
+	page := newAPIPage(items, limit, func(item apiInvoiceEvent) int { return item.EventID })
+	if query != "" {
+		// Filter after paging so the cursor still advances past events of other types
+		filtered := []apiInvoiceEvent{}
+		for _, item := range page.Data.([]apiInvoiceEvent) {
+			if item.EventType == query {
+				filtered = append(filtered, item)
 			}
 		}
+		page.Data = filtered
 	}
+	app.writeJSON(res, req, http.StatusOK, page)
+}
 
-	// Redirect to settings view
-	http.Redirect(res, req, "/settings", http.StatusSeeOther)
+// apiExternalID is a client, project or invoice found by the ID it has in another system
+type apiExternalID struct {
+	Kind       string `json:"kind"`
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Source     string `json:"source"`
+	ExternalID string `json:"external_id"`
 }
 
-// projectsList handles a GET request which displays all projects
-func (app *application) projectsList(res http.ResponseWriter, req *http.Request) {
-	// Get page size setting with fallback
-	pageSize := 10 // Default fallback
-	if pageSizeSetting, err := app.settings.GetString("list_page_size"); err == nil {
-		if ps, err := strconv.Atoi(pageSizeSetting); err == nil && ps > 0 {
-			pageSize = ps
-		}
+// apiExternalIDView handles a GET request which finds the client, project or invoice that has
+// an ID in another system, so that a sync can tell whether it already created the record
+// instead of creating it again. Records that have since been deleted aren't found.
+func (app *application) apiExternalIDView(res http.ResponseWriter, req *http.Request) {
+	kind := req.PathValue("kind")
+	if !models.IsExternalKind(kind) {
+		app.apiError(res, http.StatusNotFound, "kind must be client, project or invoice")
+		return
 	}
-
-	// Get current page from query parameter
-	currentPage := 1
-	if pageParam := req.URL.Query().Get("page"); pageParam != "" {
-		if p, err := strconv.Atoi(pageParam); err == nil && p > 0 {
-			currentPage = p
+	source := models.NormalizeExternalSource(req.PathValue("source"))
+	externalID := req.PathValue("external_id")
+
+	id, err := app.externalIDs.Find(kind, source, externalID)
+	if err == nil {
+		var target externalIDTarget
+		target, err = app.externalIDTargetFor(kind, id)
+		if err == nil {
+			app.writeJSON(res, req, http.StatusOK, apiExternalID{
+				Kind:       kind,
+				ID:         id,
+				Name:       target.Name,
+				Source:     source,
+				ExternalID: externalID,
+			})
+			return
 		}
 	}
-
-	// Calculate offset
-	offset := int64((currentPage - 1) * pageSize)
-
-	// Get paginated projects and total count
-	projects, err := app.projects.GetWithPagination(int64(pageSize), offset)
-	if err != nil {
-		app.serverError(res, req, err)
+	if errors.Is(err, models.ErrNoRecord) {
+		app.apiError(res, http.StatusNotFound, fmt.Sprintf("no %s has that ID in %s", kind, source))
 		return
 	}
+	app.serverError(res, req, err)
+}
 
-	totalCount, err := app.projects.GetCount()
-	if err != nil {
-		app.serverError(res, req, err)
+// notifyInvoicePaid posts the paid invoice to the webhook_invoice_paid_url setting, if one
+// is configured. Delivery happens in the background so a slow endpoint can't hold up the page.
+func (app *application) notifyInvoicePaid(invoiceID int) {
+	url, err := app.settings.GetString("webhook_invoice_paid_url")
+	if err != nil || url == "" {
 		return
 	}
 
-	// Calculate pagination info
-	totalPages := int((totalCount + int64(pageSize) - 1) / int64(pageSize))
+	app.background(func() {
+		invoice, err := app.invoices.GetWithClient(invoiceID)
+		if err != nil {
+			app.logger.Error("loading paid invoice for webhook", "invoice_id", invoiceID, "error", err.Error())
+			return
+		}
+
+		payload := apiInvoiceEvent{
+			EventType:  models.InvoiceEventPaid,
+			OccurredAt: time.Now().UTC().Format(time.RFC3339),
+			apiInvoice: newAPIInvoice(invoice),
+		}
 
-	pagination := &paginationData{
-		CurrentPage: currentPage,
-		TotalPages:  totalPages,
-		HasPrev:     currentPage > 1,
-		HasNext:     currentPage < totalPages,
-		PrevPage:    currentPage - 1,
-		NextPage:    currentPage + 1,
-		PageSize:    pageSize,
-	}
+		ctx, cancel := context.WithTimeout(context.Background(), webhook.DefaultTimeout)
+		defer cancel()
 
-	data := app.newTemplateData(req)
-	data.ProjectsWithClient = projects
-	data.Pagination = pagination
-	app.render(res, req, http.StatusOK, "projects.html", data)
+		err = app.webhooks.Post(ctx, url, payload)
+		if err != nil {
+			app.logger.Error("delivering invoice paid webhook", "invoice_id", invoiceID, "error", err.Error())
+		}
+	})
 }
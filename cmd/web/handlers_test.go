@@ -1,19 +1,39 @@
 package main
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"image"
+	"image/gif"
+	"image/png"
 	"log/slog"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/alexedwards/scs/v2"
 	"github.com/go-playground/form/v4"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/database"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/jobs"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/mailer"
 	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/storage"
 	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -30,7 +50,7 @@ func createTestApp(t *testing.T) (*application, *testutil.TestDatabase) {
 			<html><body>
 				<h1>Clients</h1>
 				{{range .Clients}}
-					<div>{{.Name}}</div>
+					<div>{{.Name}}{{range index $.ClientTags .ID}} [{{.Name}}]{{end}}</div>
 				{{end}}
 				{{template "pagination" .}}
 			</body></html>
@@ -60,17 +80,44 @@ func createTestApp(t *testing.T) (*application, *testutil.TestDatabase) {
 		"client.html": template.Must(template.New("base").Parse(`
 			{{define "base"}}
 			<html><body>
+				{{with .Flash}}<div class="flash">{{.}}</div>{{end}}
 				<h1>{{.Client.Name}}</h1>
 				<p>ID: {{.Client.ID}}</p>
+				{{if .Client.PrepayOnly}}<span class="badge">Prepay only</span>{{end}}
+				{{with .PaymentBehavior}}<span class="badge">{{.Label}}</span>{{end}}
+				{{range .PinnedNotes}}<div class="pinned">{{.Body}}</div>{{end}}
+				{{range .ClientNotes}}<div class="note">{{.Body}}</div>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"client_note_create.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<textarea name="body">{{.Form.Body}}</textarea>
+				{{if .Form.FieldErrors.body}}<span>{{.Form.FieldErrors.body}}</span>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"project_defaults.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<select name="status">{{range .ProjectStatuses}}<option{{if eq $.Form.Status .}} selected{{end}}>{{.}}</option>{{end}}</select>
+				<input type="text" name="currency_display" value="{{.Form.CurrencyDisplay}}">
+				<input type="number" name="discount_percent" value="{{.Form.DiscountPercent}}">
+				{{range .Form.FieldErrors}}<span class="error">{{.}}</span>{{end}}
+				{{if .ProjectDefaults}}<form class="clear-defaults"></form>{{end}}
 			</body></html>
 			{{end}}
 		`)),
 		"client_create.html": template.Must(template.New("base").Parse(`
 			{{define "base"}}
 			<html><body>
+				{{with .Draft}}<div class="draft" data-draft="{{.}}"></div>{{end}}
 				<form method="POST">
 					<input type="text" name="name" value="{{.Form.Name}}">
 					{{if .Form.FieldErrors.name}}<span>{{.Form.FieldErrors.name}}</span>{{end}}
+					{{with .Form.FieldErrors.next_invoice_number}}<span>{{.}}</span>{{end}}
+					{{range .Form.Warnings}}<span class="warning">{{.}}</span>{{end}}
 					<button type="submit">Create</button>
 				</form>
 			</body></html>
@@ -87,7 +134,9 @@ func createTestApp(t *testing.T) (*application, *testutil.TestDatabase) {
 								<td>{{.ID}}</td>
 								<td>{{.Name}}</td>
 								<td>{{.ClientName}}</td>
+								<td>{{range index $.ProjectTags .ID}}[{{.Name}}]{{end}}</td>
 								<td>{{.Status}}</td>
+								<td class="variance">{{with .Estimate}}{{if .HasHours}}{{printf "%+.2f" .HoursVariance}} h{{end}}{{end}}</td>
 							</tr>
 						{{end}}
 					</table>
@@ -125,6 +174,15 @@ func createTestApp(t *testing.T) (*application, *testutil.TestDatabase) {
 				<h1>{{.Project.Name}}</h1>
 				<p>ID: {{.Project.ID}}</p>
 				<p>Client: {{.Client.Name}}</p>
+				{{range .Milestones}}<p class="milestone">{{.Name}}: {{.Status}}</p>{{end}}
+				{{with .Estimate}}<p class="estimate">{{printf "%.2f" .ActualHours}} of {{printf "%.2f" .EstimatedHours}} hours{{if .HoursOver}} (over){{end}}</p>{{end}}
+				{{range .ProjectChecklist}}<p class="checklist">{{.Label}}: {{if .Checked}}checked{{else}}to do{{end}}</p>{{end}}
+				{{range .Timesheets}}<p class="timesheet">{{.Description}}</p>{{end}}
+				{{with .TimesheetPages}}<p class="timesheet-pages">{{.CurrentPage}} of {{.TotalPages}} ?{{with .Query}}{{.}}&amp;{{end}}{{.Param}}={{.NextPage}}</p>{{end}}
+				{{range .Invoices}}<p class="invoice">{{printf "%.2f" .AmountDue}}</p>{{end}}
+				{{with .InvoicePages}}<p class="invoice-pages">{{.CurrentPage}} of {{.TotalPages}}</p>{{end}}
+				{{with .Margin}}<p class="margin">{{printf "%.2f" .Billed}}</p>{{end}}
+				{{range .ClientApprovals}}<p class="client-approval">/approve/{{.Token}} {{if .Approved}}approved by {{.ApprovedBy}}{{else}}waiting{{end}}</p>{{end}}
 			</body></html>
 			{{end}}
 		`)),
@@ -135,10 +193,14 @@ func createTestApp(t *testing.T) (*application, *testutil.TestDatabase) {
 					<input type="text" name="name" value="{{.Form.Name}}">
 					{{if .Form.FieldErrors.name}}<span>{{.Form.FieldErrors.name}}</span>{{end}}
 					<input type="number" name="hourly_rate" value="{{.Form.HourlyRate}}">
+					<input type="text" name="status" value="{{.Form.Status}}">
+					<input type="text" name="currency_display" value="{{.Form.CurrencyDisplay}}">
+					<input type="number" name="discount_percent" value="{{.Form.DiscountPercent}}">
 					<input type="text" name="additional_info" value="{{.Form.AdditionalInfo}}">
 					<input type="text" name="additional_info2" value="{{.Form.AdditionalInfo2}}">
 					<input type="email" name="invoice_cc_email" value="{{.Form.InvoiceCCEmail}}">
 					<input type="text" name="invoice_cc_description" value="{{.Form.InvoiceCCDescription}}">
+					{{range .Form.Warnings}}<span class="warning">{{.}}</span>{{end}}
 					<button type="submit">Create</button>
 				</form>
 			</body></html>
@@ -153,39 +215,479 @@ func createTestApp(t *testing.T) (*application, *testutil.TestDatabase) {
 					<input type="number" name="hours_worked" value="{{.Form.HoursWorked}}">
 					{{if .Form.FieldErrors.hours_worked}}<span>{{.Form.FieldErrors.hours_worked}}</span>{{end}}
 					<input type="number" name="hourly_rate" value="{{.Form.HourlyRate}}">
-					<input type="text" name="description" value="{{.Form.Description}}">
+					<select name="service_id">{{range .Services}}<option value="{{.ID}}">{{.Name}}</option>{{end}}</select>
+					{{if .Form.FieldErrors.service_id}}<span>{{.Form.FieldErrors.service_id}}</span>{{end}}
+					<select name="work_type">{{range .WorkTypes}}<option{{if eq $.Form.WorkType .}} selected{{end}}>{{.}}</option>{{end}}</select>
+					{{if .Form.FieldErrors.work_type}}<span>{{.Form.FieldErrors.work_type}}</span>{{end}}
+					<textarea name="description" maxlength="{{.Form.MaxDescription}}">{{.Form.Description}}</textarea>
+					{{if .Form.FieldErrors.description}}<span>{{.Form.FieldErrors.description}}</span>{{end}}
+					{{range .LockingInvoices}}<span class="locked">Invoice #{{.DisplayNumber}}</span>{{end}}
+					{{if .Form.FieldErrors.override_lock}}<span>{{.Form.FieldErrors.override_lock}}</span>{{end}}
+					{{range .Form.Warnings}}<span class="warning">{{.}}</span>{{end}}
+					<button type="submit">Create</button>
+				</form>
+			</body></html>
+			{{end}}
+		`)),
+		"settings_edit.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{range $key, $error := .Form.FieldErrors}}<span class="error">{{$key}}: {{$error}}</span>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"settings.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{if .LogoPath}}<img src="/settings/logo">{{end}}
+				{{with .Form.FieldErrors.logo}}<span class="error">{{.}}</span>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"project_shared.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<h1>{{.Project.Name}}</h1>
+				{{range .Timesheets}}<div class="timesheet">{{.Description}}</div>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"user_create.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<form method="POST">
+					<input type="text" name="name" value="{{.Form.Name}}">
+					<input type="email" name="email" value="{{.Form.Email}}">
+					{{if .Form.FieldErrors.email}}<span>{{.Form.FieldErrors.email}}</span>{{end}}
+					{{if .Form.FieldErrors.role}}<span>{{.Form.FieldErrors.role}}</span>{{end}}
 					<button type="submit">Create</button>
 				</form>
 			</body></html>
 			{{end}}
 		`)),
+		"user_preferences.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html{{with .Theme}} data-theme="{{.}}"{{end}}><body>
+				<form method="POST">
+					<input type="number" name="page_size" value="{{.Form.PageSize}}">
+					{{if .Form.FieldErrors.page_size}}<span>{{.Form.FieldErrors.page_size}}</span>{{end}}
+					<select name="landing_page">{{range .LandingPages}}<option value="{{.Path}}">{{.Name}}</option>{{end}}</select>
+					{{if .Form.FieldErrors.landing_page}}<span>{{.Form.FieldErrors.landing_page}}</span>{{end}}
+					{{if .Form.FieldErrors.date_format}}<span>{{.Form.FieldErrors.date_format}}</span>{{end}}
+					{{if .Form.FieldErrors.theme}}<span>{{.Form.FieldErrors.theme}}</span>{{end}}
+				</form>
+			</body></html>
+			{{end}}
+		`)),
+		"login.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{with .Form.FieldErrors.credentials}}<span>{{.}}</span>{{end}}
+				{{with .RememberMeFor}}<input type="checkbox" name="remember_me"> Stay logged in for {{.}}{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"user_sessions.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{with .Flash}}<div class="flash">{{.}}</div>{{end}}
+				{{range .UserSessions}}<div class="session">{{.UserAgent}}{{if eq .ID $.CurrentSessionID}} (this device){{end}}</div>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"invoice_email.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{with .Flash}}<div class="flash">{{.}}</div>{{end}}
+				{{range $field, $error := .Form.FieldErrors}}<span class="error">{{$error}}</span>{{end}}
+				<input name="to" value="{{.Form.To}}"><input name="cc" value="{{.Form.CC}}"><input name="subject" value="{{.Form.Subject}}">
+				{{with .DeliverySummary}}<p class="summary">Sent {{.Sent}}, failed {{.Failed}}, opened {{.Opens}}</p>{{end}}
+				{{range .InvoiceDeliveries}}<div class="delivery">{{.Status}} {{.Recipients}} {{.SMTPResponse}}</div>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"invoices_overdue.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{range $field, $error := .Form.FieldErrors}}<span class="error">{{$error}}</span>{{end}}
+				{{range .OverdueInvoices}}<div class="overdue">{{.DisplayNumber}} {{.ClientName}} {{printf "%.2f" .Outstanding}}{{if index $.RecentlyReminded .ID}} recent{{end}}</div>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"invoice_template_help.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{range .TemplateFuncs}}<div class="func">{{.Name}} {{.Signature}}</div>{{end}}
+				{{range .TemplateFields}}<div class="field">{{.Path}} = {{.Sample}}</div>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"import.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{range $field, $error := .Form.FieldErrors}}<span class="error">{{$error}}</span>{{end}}
+				{{with .ImportSummary}}
+				<div class="summary">clients={{.ClientsCreated}} projects={{.ProjectsCreated}} timesheets={{.TimesheetsCreated}} skipped={{.Skipped}}</div>
+				{{range .Problems}}<div class="problem">{{.}}</div>{{end}}
+				{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"reconcile.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{range $field, $error := .Form.FieldErrors}}<span class="error">{{$error}}</span>{{end}}
+				{{with .BankStatement}}{{range .Problems}}<div class="problem">{{.}}</div>{{end}}{{end}}
+				{{range .DepositMatches}}<div class="match">row={{.Deposit.Row}} invoice={{with .Invoice}}{{.ID}}{{end}} confident={{.Confident}}</div>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"forecast.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{with .Forecast}}
+				{{range .Weeks}}<div class="week">{{.Start.Format "2006-01-02"}} {{printf "%.2f" .Total}}{{range .Invoices}} {{.ClientName}}{{end}}</div>{{end}}
+				<div class="total">{{printf "%.2f" .Total}}</div>
+				{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"wip.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{with .WorkInProgress}}
+				{{range .Projects}}<div class="project">{{.ProjectName}} {{.Entries}} {{printf "%.2f" .Amount}} {{.AgeDays}}</div>{{end}}
+				<div class="total">{{printf "%.2f" .Total}}</div>
+				{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"client_approval.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{if not .Public}}<nav>Owner links</nav>{{end}}
+				<h1>{{.Project.Name}}</h1>
+				{{range .Timesheets}}<div class="timesheet">{{.Description}}</div>{{end}}
+				<p class="total">{{printf "%.2f" .ApprovalHours}}</p>
+				{{with .ClientApproval}}{{if .Approved}}<p class="approved">{{.ApprovedBy}}</p>{{end}}{{end}}
+				{{with .Form.FieldErrors.name}}<span class="error">{{.}}</span>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"trends.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{with .Trends}}
+				{{range .Measures}}<div class="measure">{{.Measure}} {{printf "%.2f" .Month.Current}} {{printf "%.2f" .Year.Current}} {{printf "%.2f" (index .Series 11)}}</div>{{end}}
+				{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"client_values.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<div class="sort">{{.ClientValueSort}}</div>
+				{{range .ClientValues}}<div class="client">{{.ClientName}} {{.Invoices}} {{printf "%.2f" .Billed}} {{printf "%.2f" .Paid}}</div>{{end}}
+				{{with .ClientValue}}<div class="total">{{printf "%.2f" .Billed}}</div>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"pipeline.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{range .Pipeline}}<div class="stage">{{.Stage}}: {{range .Prospects}}<span class="prospect">{{.Name}}</span>{{end}}</div>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"payment_terms.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{range $field, $error := .Form.FieldErrors}}<span class="error">{{$error}}</span>{{end}}
+				{{range .TermsPresets}}<div class="preset">{{.Terms}}</div>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"external_ids.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<h2>{{.ExternalIDTarget.Name}}</h2>
+				{{range $field, $error := .Form.FieldErrors}}<span class="error">{{$error}}</span>{{end}}
+				{{range .ExternalIDs}}<div class="external-id">{{.Source}}={{.ExternalID}}</div>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"checklist.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{range $field, $error := .Form.FieldErrors}}<span class="error">{{$error}}</span>{{end}}
+				{{range .ChecklistItems}}<div class="item">{{.Label}} {{.TagName}}</div>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"pending_timesheets.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{range .PendingTimesheets}}<div>{{.ProjectName}} {{printf "%.2f" .HoursWorked}} {{.Description}}</div>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"timesheet_approvals.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{range .TimesheetApprovals}}<div>{{.ProjectName}} {{.UserName}} {{.Quantity}} {{.Description}}</div>{{end}}
+			</body></html>
+			{{end}}
+		`)),
 		"invoice_create.html": template.Must(template.New("base").Parse(`
 			{{define "base"}}
 			<html><body>
+				{{range .PinnedNotes}}<div class="pinned">{{.Body}}</div>{{end}}
 				<form method="POST">
 					<input type="date" name="invoice_date" value="{{.Form.InvoiceDate}}">
 					{{if .Form.FieldErrors.invoice_date}}<span>{{.Form.FieldErrors.invoice_date}}</span>{{end}}
 					<input type="number" name="amount_due" value="{{.Form.AmountDue}}">
 					{{if .Form.FieldErrors.amount_due}}<span>{{.Form.FieldErrors.amount_due}}</span>{{end}}
+					<select name="payment_terms_preset">{{range .TermsPresets}}<option{{if eq $.Form.PaymentTermsPreset .Terms}} selected{{end}}>{{.Terms}}</option>{{end}}</select>
 					<input type="text" name="payment_terms" value="{{.Form.PaymentTerms}}">
 					<input type="date" name="date_paid" value="{{.Form.DatePaid}}">
+					<input type="text" name="invoice_number" value="{{.Form.InvoiceNumber}}">
+					{{if .Form.FieldErrors.invoice_number}}<span>{{.Form.FieldErrors.invoice_number}}</span>{{end}}
+					<input type="date" name="due_date" value="{{.Form.DueDate}}">
+					{{if .Form.FieldErrors.due_date}}<span>{{.Form.FieldErrors.due_date}}</span>{{end}}
+					<input type="email" name="cc_email" value="{{.Form.CCEmail}}">
+					{{if .Form.FieldErrors.cc_email}}<span>{{.Form.FieldErrors.cc_email}}</span>{{end}}
+					{{with .InvoiceCC.Email}}<span class="cc">Copies {{.}} from the {{$.InvoiceCC.Source}}</span>{{end}}
+					<input type="text" name="budget_code" value="{{.Form.BudgetCode}}">
+					{{if .Form.FieldErrors.budget_code}}<span>{{.Form.FieldErrors.budget_code}}</span>{{end}}
+					{{with .BudgetCode}}<span class="budget-code">Prints {{.}}</span>{{end}}
+					<input type="date" name="timesheets_from" value="{{.Form.TimesheetsFrom}}">
+					<input type="date" name="timesheets_to" value="{{.Form.TimesheetsTo}}">
+					{{if .Form.FieldErrors.timesheets_to}}<span>{{.Form.FieldErrors.timesheets_to}}</span>{{end}}
+					<input type="number" name="discount_percent" value="{{.Form.DiscountPercent}}">
+					{{if .Form.FieldErrors.discount_percent}}<span>{{.Form.FieldErrors.discount_percent}}</span>{{end}}
+					<input type="number" name="discount_amount" value="{{.Form.DiscountAmount}}">
+					{{if .Form.FieldErrors.discount_amount}}<span>{{.Form.FieldErrors.discount_amount}}</span>{{end}}
+					<input type="text" name="discount_reason" value="{{.Form.DiscountReason}}">
+					<input type="checkbox" name="display_details"{{if .Form.DisplayDetails}} checked{{end}}>
+					{{range .Form.Warnings}}<span class="warning">{{.}}</span>{{end}}
+					{{with .Invoice}}<span class="signature">{{with .PDFSignedAt}}Signed by {{$.Invoice.PDFSignedBy}}{{else}}Not signed{{end}}</span>{{end}}
 					<button type="submit">Create</button>
 				</form>
 			</body></html>
 			{{end}}
 		`)),
+		"invoice_print_warning.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<p>About {{.PDFPages}} pages, more than {{.MaxPDFPages}}</p>
+				<a href="/invoice/print/{{.Invoice.ID}}?confirm=1">Generate anyway</a>
+			</body></html>
+			{{end}}
+		`)),
+		"job.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<div id="job-status" data-status="{{.Job.Status}}">{{.Job.Description}} {{.Job.Error}}</div>
+			</body></html>
+			{{end}}
+		`)),
+		"invoice_void.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<form method="POST">
+					<input type="text" name="void_reason" value="{{.Form.VoidReason}}">
+					{{if .Form.FieldErrors.void_reason}}<span>{{.Form.FieldErrors.void_reason}}</span>{{end}}
+					<button type="submit">Void</button>
+				</form>
+			</body></html>
+			{{end}}
+		`)),
+		"clients_sync.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{with .Form.FieldErrors.source}}<span>{{.}}</span>{{end}}
+				{{if .ContactSync}}
+					{{range .ContactSync.Changes}}
+						<div>change {{.Client.Name}}{{range .Fields}} {{.Field}}={{.NewValue}}{{end}}</div>
+					{{end}}
+					{{range .ContactSync.NewContacts}}
+						<div>new {{.Email}}</div>
+					{{end}}
+				{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"profiles.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<h2>Business Profiles</h2>
+				{{range .BusinessProfiles}}
+					<div>{{.Name}} {{.InvoicePrefix}}</div>
+				{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"service_create.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<form method="POST">
+					<input type="text" name="name" value="{{.Form.Name}}">
+					{{if .Form.FieldErrors.name}}<span>{{.Form.FieldErrors.name}}</span>{{end}}
+					<input type="number" name="default_rate" value="{{.Form.DefaultRate}}">
+					{{if .Form.FieldErrors.default_rate}}<span>{{.Form.FieldErrors.default_rate}}</span>{{end}}
+					<input type="text" name="unit" value="{{.Form.Unit}}">
+					{{if .Form.FieldErrors.unit}}<span>{{.Form.FieldErrors.unit}}</span>{{end}}
+					<button type="submit">Save</button>
+				</form>
+			</body></html>
+			{{end}}
+		`)),
+		"tags.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{range .Tags}}
+					<div>{{.Name}} {{.Color}}</div>
+				{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"admin_migrations.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<p>Maintenance: {{.Maintenance}}</p>
+				{{range .Migrations}}
+					<div>{{.Name}} {{if .Applied}}applied{{else}}pending{{end}}</div>
+				{{end}}
+				{{range .PlannedMigrations}}
+					<div>Would {{if $.RollingBack}}roll back{{else}}apply{{end}} {{.Name}}</div>
+				{{end}}
+				{{if .Form.FieldErrors.steps}}<span>{{.Form.FieldErrors.steps}}</span>{{end}}
+				{{with .DatabaseSize}}<p>Database: {{.Bytes}} bytes</p>{{end}}
+				{{range .MaintenanceTasks}}<input type="checkbox" name="tasks" value="{{.}}">{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"admin_integrity.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				{{if .Integrity.OK}}<p>No problems found</p>{{end}}
+				{{range .Integrity.Orphans}}
+					<div>{{.Check.Name}}: {{range .IDs}}{{.}} {{end}}</div>
+				{{end}}
+				{{if .Form.FieldErrors.parent_id}}<span>{{.Form.FieldErrors.parent_id}}</span>{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"project_pick.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<form action="/{{.PickAction}}/create"><input name="q" value="{{.Form.Query}}"></form>
+				{{range .ProjectMatches}}
+					<a href="/project/{{.ID}}/{{$.PickAction}}/create">{{.Name}} ({{.ClientName}})</a>
+				{{end}}
+			</body></html>
+			{{end}}
+		`)),
+		"maintenance.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body><h1>Down for maintenance</h1></body></html>
+			{{end}}
+		`)),
+		"pdf_unavailable.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body><h1>Invoice PDFs Unavailable</h1></body></html>
+			{{end}}
+		`)),
+		"tag_create.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<form method="POST">
+					<input type="text" name="name" value="{{.Form.Name}}">
+					{{if .Form.FieldErrors.name}}<span>{{.Form.FieldErrors.name}}</span>{{end}}
+					<input type="color" name="color" value="{{.Form.Color}}">
+					{{if .Form.FieldErrors.color}}<span>{{.Form.FieldErrors.color}}</span>{{end}}
+					<button type="submit">Save</button>
+				</form>
+			</body></html>
+			{{end}}
+		`)),
+		"milestone_create.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<form method="POST">
+					<input type="text" name="name" value="{{.Form.Name}}">
+					{{if .Form.FieldErrors.name}}<span>{{.Form.FieldErrors.name}}</span>{{end}}
+					<input type="text" name="due_date" value="{{.Form.DueDate}}">
+					{{if .Form.FieldErrors.due_date}}<span>{{.Form.FieldErrors.due_date}}</span>{{end}}
+					<input type="number" name="fee" value="{{.Form.Fee}}">
+					{{if .Form.FieldErrors.fee}}<span>{{.Form.FieldErrors.fee}}</span>{{end}}
+					<input type="text" name="status" value="{{.Form.Status}}">
+					{{if .Form.FieldErrors.status}}<span>{{.Form.FieldErrors.status}}</span>{{end}}
+					<button type="submit">Save</button>
+				</form>
+			</body></html>
+			{{end}}
+		`)),
+		"profile_create.html": template.Must(template.New("base").Parse(`
+			{{define "base"}}
+			<html><body>
+				<form method="POST">
+					<input type="text" name="name" value="{{.Form.Name}}">
+					{{if .Form.FieldErrors.name}}<span>{{.Form.FieldErrors.name}}</span>{{end}}
+					<input type="text" name="invoice_prefix" value="{{.Form.InvoicePrefix}}">
+					<input type="number" name="next_invoice_number" value="{{.Form.NextInvoiceNumber}}">
+					{{if .Form.FieldErrors.next_invoice_number}}<span>{{.Form.FieldErrors.next_invoice_number}}</span>{{end}}
+					<button type="submit">Save</button>
+				</form>
+			</body></html>
+			{{end}}
+		`)),
 	}
 
 	app := &application{
-		logger:        slog.New(slog.NewTextHandler(os.Stdout, nil)),
-		clients:       models.NewClientModel(testDB.DB),
-		projects:      models.NewProjectModel(testDB.DB),
-		timesheets:    models.NewTimesheetModel(testDB.DB),
-		invoices:      models.NewInvoiceModel(testDB.DB),
-		settings:      models.NewAppSettingModel(testDB.DB),
-		templateCache: templateCache,
-		formDecoder:   form.NewDecoder(),
+		logger:             slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		clients:            models.NewClientModel(testDB.DB),
+		projects:           models.NewProjectModel(testDB.DB),
+		timesheets:         models.NewTimesheetModel(testDB.DB),
+		invoices:           models.NewInvoiceModel(testDB.DB),
+		settings:           models.NewAppSettingModel(testDB.DB),
+		businessProfiles:   models.NewBusinessProfileModel(testDB.DB),
+		credits:            models.NewClientCreditModel(testDB.DB),
+		clientNotes:        models.NewClientNoteModel(testDB.DB),
+		users:              models.NewUserModel(testDB.DB),
+		preferences:        models.NewUserPreferencesModel(testDB.DB),
+		userSessions:       models.NewUserSessionModel(testDB.DB),
+		reports:            models.NewReportModel(testDB.DB),
+		exports:            models.NewExportModel(testDB.DB),
+		integrity:          models.NewIntegrityModel(testDB.DB),
+		invoiceEvents:      models.NewInvoiceEventModel(testDB.DB),
+		deliveries:         models.NewInvoiceDeliveryModel(testDB.DB),
+		consolidated:       models.NewConsolidatedInvoiceModel(testDB.DB),
+		pendingTimesheets:  models.NewPendingTimesheetModel(testDB.DB),
+		services:           models.NewServiceModel(testDB.DB),
+		milestones:         models.NewMilestoneModel(testDB.DB),
+		tags:               models.NewTagModel(testDB.DB),
+		savedViews:         models.NewSavedViewModel(testDB.DB),
+		listFilters:        models.NewListFilterModel(testDB.DB),
+		paymentTerms:       models.NewPaymentTermsPresetModel(testDB.DB),
+		checklists:         models.NewChecklistModel(testDB.DB),
+		notifications:      models.NewNotificationModel(testDB.DB),
+		jobs:               models.NewJobModel(testDB.DB),
+		exchangeRates:      models.NewExchangeRateModel(testDB.DB),
+		projectDefaults:    models.NewProjectDefaultsModel(testDB.DB),
+		projectAttachments: models.NewProjectAttachmentModel(testDB.DB),
+		approvals:          models.NewClientApprovalModel(testDB.DB),
+		visits:             models.NewRecentVisitModel(testDB.DB),
+		externalIDs:        models.NewExternalIDModel(testDB.DB),
+		drafts:             models.NewFormDraftModel(testDB.DB),
+		transactions:       models.NewTxManager(testDB.DB),
+		templateCache:      templateCache,
+		formDecoder:        form.NewDecoder(),
+		db:                 testDB.DB,
 	}
+	app.queue = jobs.New(app.jobs, app.logger)
+	app.scheduler = jobs.NewScheduler(app.queue, models.NewScheduledTaskModel(testDB.DB), app.settings, app.logger)
+	app.scheduleTasks()
 
 	return app, testDB
 }
@@ -425,22 +927,199 @@ func TestClientViewHandler(t *testing.T) {
 	})
 }
 
-func TestClientCreateHandler(t *testing.T) {
+func TestClientNoteHandlers(t *testing.T) {
 	app, testDB := createTestApp(t)
 	defer testDB.Cleanup(t)
 
-	t.Run("show create form", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodGet, "/client/create", nil)
+	clientID := testDB.InsertTestClient(t, "Grant Client")
+	projectID := testDB.InsertTestProject(t, "Grant Report", clientID)
+
+	post := func(handler http.HandlerFunc, path string, id int, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(id))
 		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	get := func(handler http.HandlerFunc, id int) string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr.Body.String()
+	}
 
-		app.clientCreate(rr, req)
+	t.Run("a note needs text", func(t *testing.T) {
+		rr := post(app.clientNoteCreatePost, "/client/note/create", clientID, url.Values{"body": {"  "}})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Note is required")
+	})
 
-		assert.Equal(t, http.StatusOK, rr.Code)
-		body := rr.Body.String()
-		assert.Contains(t, body, "<form method=\"POST\">")
-		assert.Contains(t, body, "name=\"name\"")
+	t.Run("notes are added to the client", func(t *testing.T) {
+		rr := post(app.clientNoteCreatePost, "/client/note/create", clientID, url.Values{"body": {"Invoices must go to the grants office"}, "pinned": {"true"}})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, fmt.Sprintf("/client/view/%d", clientID), rr.Header().Get("Location"))
+		rr = post(app.clientNoteCreatePost, "/client/note/create", clientID, url.Values{"body": {"Prefers email"}})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		body := get(app.clientView, clientID)
+		assert.Contains(t, body, `<div class="pinned">Invoices must go to the grants office</div>`)
+		assert.NotContains(t, body, `<div class="pinned">Prefers email</div>`)
+		assert.Contains(t, body, `<div class="note">Prefers email</div>`)
 	})
-}
+
+	t.Run("pinned notes are shown on the invoice form", func(t *testing.T) {
+		body := get(app.invoiceCreate, projectID)
+		assert.Contains(t, body, `<div class="pinned">Invoices must go to the grants office</div>`)
+		assert.NotContains(t, body, "Prefers email")
+	})
+
+	t.Run("notes can be pinned, edited and deleted", func(t *testing.T) {
+		notes, err := app.clientNotes.GetByClient(clientID)
+		require.NoError(t, err)
+		require.Len(t, notes, 2)
+		pinned, general := notes[0], notes[1]
+
+		rr := post(app.clientNotePinPost, "/client/note/pin", pinned.ID, nil)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		rr = post(app.clientNotePinPost, "/client/note/pin", general.ID, nil)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		body := get(app.clientView, clientID)
+		assert.Contains(t, body, `<div class="pinned">Prefers email</div>`)
+		assert.NotContains(t, body, `<div class="pinned">Invoices must go to the grants office</div>`)
+
+		assert.Contains(t, get(app.clientNoteUpdate, general.ID), "Prefers email")
+		rr = post(app.clientNoteUpdatePost, "/client/note/update", general.ID, url.Values{"body": {"Prefers email, never phone"}, "pinned": {"true"}})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		note, err := app.clientNotes.Get(general.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Prefers email, never phone", note.Body)
+		assert.True(t, note.Pinned)
+
+		rr = post(app.clientNoteDelete, "/client/note/delete", pinned.ID, nil)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		_, err = app.clientNotes.Get(pinned.ID)
+		assert.ErrorIs(t, err, models.ErrNoRecord)
+	})
+
+	t.Run("unknown notes and clients are not found", func(t *testing.T) {
+		rr := post(app.clientNoteDelete, "/client/note/delete", 99999, nil)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		rr = post(app.clientNoteCreatePost, "/client/note/create", 99999, url.Values{"body": {"Nobody"}})
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestClientProjectDefaultsHandlers(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Euro Press")
+
+	post := func(handler http.HandlerFunc, id int, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	get := func(handler http.HandlerFunc, id int) string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr.Body.String()
+	}
+
+	t.Run("the form starts from the usual defaults", func(t *testing.T) {
+		body := get(app.clientProjectDefaultsEdit, clientID)
+		assert.Contains(t, body, "<option selected>Estimating</option>")
+		assert.Contains(t, body, `name="currency_display" value="USD"`)
+		assert.NotContains(t, body, "clear-defaults")
+	})
+
+	t.Run("invalid defaults are not saved", func(t *testing.T) {
+		rr := post(app.clientProjectDefaultsEditPost, clientID, url.Values{
+			"status":                   {"Archived"},
+			"currency_display":         {"EUR"},
+			"currency_conversion_rate": {"0"},
+			"discount_percent":         {"15"},
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		body := rr.Body.String()
+		assert.Contains(t, body, "Status must be one a new project can start in")
+		assert.Contains(t, body, "Conversion rate must be more than zero")
+		assert.Contains(t, body, "Discount percent is a fraction")
+
+		_, err := app.projectDefaults.Get(clientID)
+		assert.ErrorIs(t, err, models.ErrNoRecord)
+	})
+
+	t.Run("saved defaults fill in new projects", func(t *testing.T) {
+		rr := post(app.clientProjectDefaultsEditPost, clientID, url.Values{
+			"status":                   {"Scheduled"},
+			"currency_display":         {"eur"},
+			"currency_conversion_rate": {"0.92"},
+			"discount_percent":         {"0.1"},
+			"discount_reason":          {"Long-standing client"},
+			"flat_fee_invoice":         {"true"},
+		})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, fmt.Sprintf("/client/view/%d", clientID), rr.Header().Get("Location"))
+
+		defaults, err := app.projectDefaults.Get(clientID)
+		require.NoError(t, err)
+		assert.Equal(t, "Scheduled", defaults.Status)
+		assert.Equal(t, "EUR", defaults.CurrencyDisplay)
+		assert.Equal(t, 0.92, defaults.CurrencyConversionRate)
+		require.NotNil(t, defaults.DiscountPercent)
+		assert.Equal(t, 0.1, *defaults.DiscountPercent)
+		assert.True(t, defaults.FlatFeeInvoice)
+
+		body := get(app.projectCreate, clientID)
+		assert.Contains(t, body, `name="status" value="Scheduled"`)
+		assert.Contains(t, body, `name="currency_display" value="EUR"`)
+		assert.Contains(t, body, `name="discount_percent" value="0.1000"`)
+
+		assert.Contains(t, get(app.clientProjectDefaultsEdit, clientID), "clear-defaults")
+	})
+
+	t.Run("cleared defaults no longer apply", func(t *testing.T) {
+		rr := post(app.clientProjectDefaultsDelete, clientID, nil)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		_, err := app.projectDefaults.Get(clientID)
+		assert.ErrorIs(t, err, models.ErrNoRecord)
+
+		body := get(app.projectCreate, clientID)
+		assert.NotContains(t, body, `name="status" value="Scheduled"`)
+	})
+
+	t.Run("unknown clients are not found", func(t *testing.T) {
+		rr := post(app.clientProjectDefaultsEditPost, 99999, url.Values{"status": {"Estimating"}})
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestClientCreateHandler(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	t.Run("show create form", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/client/create", nil)
+		rr := httptest.NewRecorder()
+
+		app.clientCreate(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		body := rr.Body.String()
+		assert.Contains(t, body, "<form method=\"POST\">")
+		assert.Contains(t, body, "name=\"name\"")
+	})
+}
 
 func TestClientCreatePostHandler(t *testing.T) {
 	app, testDB := createTestApp(t)
@@ -495,6 +1174,94 @@ func TestClientCreatePostHandler(t *testing.T) {
 		assert.Empty(t, clients)
 	})
 
+	t.Run("country is saved", func(t *testing.T) {
+		testDB.TruncateTable(t, "client")
+
+		form := url.Values{}
+		form.Add("name", "Berlin Client")
+		form.Add("email", "berlin@example.com")
+		form.Add("hourly_rate", "75.00")
+		form.Add("country", "DE")
+
+		req := httptest.NewRequest(http.MethodPost, "/client/create", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		app.clientCreatePost(rr, req)
+
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		clients, err := app.clients.GetAll()
+		require.NoError(t, err)
+		require.Len(t, clients, 1)
+		require.NotNil(t, clients[0].Country)
+		assert.Equal(t, "DE", *clients[0].Country)
+	})
+
+	t.Run("invoice language is saved", func(t *testing.T) {
+		testDB.TruncateTable(t, "client")
+
+		form := url.Values{}
+		form.Add("name", "Paris Client")
+		form.Add("email", "paris@example.com")
+		form.Add("hourly_rate", "75.00")
+		form.Add("invoice_language", "fr")
+
+		req := httptest.NewRequest(http.MethodPost, "/client/create", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		app.clientCreatePost(rr, req)
+
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		clients, err := app.clients.GetAll()
+		require.NoError(t, err)
+		require.Len(t, clients, 1)
+		require.NotNil(t, clients[0].InvoiceLanguage)
+		assert.Equal(t, "fr", *clients[0].InvoiceLanguage)
+	})
+
+	t.Run("validation error - unknown invoice language", func(t *testing.T) {
+		testDB.TruncateTable(t, "client")
+
+		form := url.Values{}
+		form.Add("name", "Babel Client")
+		form.Add("email", "babel@example.com")
+		form.Add("hourly_rate", "75.00")
+		form.Add("invoice_language", "Klingon")
+
+		req := httptest.NewRequest(http.MethodPost, "/client/create", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		app.clientCreatePost(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		clients, err := app.clients.GetAll()
+		require.NoError(t, err)
+		assert.Empty(t, clients)
+	})
+
+	t.Run("validation error - unknown country", func(t *testing.T) {
+		testDB.TruncateTable(t, "client")
+
+		form := url.Values{}
+		form.Add("name", "Nowhere Client")
+		form.Add("email", "nowhere@example.com")
+		form.Add("hourly_rate", "75.00")
+		form.Add("country", "Atlantis")
+
+		req := httptest.NewRequest(http.MethodPost, "/client/create", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		app.clientCreatePost(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		clients, err := app.clients.GetAll()
+		require.NoError(t, err)
+		assert.Empty(t, clients)
+	})
+
 	t.Run("validation error - name too long", func(t *testing.T) {
 		testDB.TruncateTable(t, "client")
 
@@ -521,6 +1288,46 @@ func TestClientCreatePostHandler(t *testing.T) {
 		assert.Empty(t, clients)
 	})
 
+	t.Run("client with its own invoice sequence", func(t *testing.T) {
+		testDB.TruncateTable(t, "client")
+
+		form := url.Values{}
+		form.Add("name", "Acme")
+		form.Add("email", "billing@acme.example")
+		form.Add("hourly_rate", "90.00")
+		form.Add("invoice_prefix", "ACME-")
+		form.Add("next_invoice_number", "")
+
+		post := func(form url.Values) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/client/create", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			app.clientCreatePost(rr, req)
+			return rr
+		}
+
+		rr := post(form)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Next invoice number is required to give the client its own sequence")
+
+		form.Set("next_invoice_number", "0")
+		rr = post(form)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Next invoice number must be a whole number of 1 or greater")
+
+		form.Set("next_invoice_number", "12")
+		rr = post(form)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		clients, err := app.clients.GetAll()
+		require.NoError(t, err)
+		require.Len(t, clients, 1)
+		require.NotNil(t, clients[0].InvoicePrefix)
+		assert.Equal(t, "ACME-", *clients[0].InvoicePrefix)
+		require.NotNil(t, clients[0].NextInvoiceNumber)
+		assert.Equal(t, 12, *clients[0].NextInvoiceNumber)
+	})
+
 	t.Run("malformed form data", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPost, "/client/create", strings.NewReader("invalid-form-data"))
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -857,6 +1664,63 @@ func TestProjectViewHandler(t *testing.T) {
 		assert.Contains(t, body, "Test Client")
 	})
 
+	t.Run("estimates are compared with the time logged", func(t *testing.T) {
+		clientID := testDB.InsertTestClient(t, "Estimate Client")
+		projectID := testDB.InsertTestProject(t, "Estimated Project", clientID)
+		_, err := testDB.DB.Exec("UPDATE project SET estimated_hours = 10 WHERE id = ?", projectID)
+		require.NoError(t, err)
+		testDB.InsertTestTimesheet(t, projectID, "2024-05-02", "6.5", "50.00", "Drafting")
+		testDB.InsertTestTimesheet(t, projectID, "2024-05-03", "5.0", "50.00", "Revisions")
+
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/project/view/%d", projectID), nil)
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+
+		app.projectView(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "11.50 of 10.00 hours (over)")
+	})
+
+	t.Run("timesheets and invoices are shown a page at a time", func(t *testing.T) {
+		_, err := testDB.DB.Exec(`INSERT INTO settings (key, value, data_type) VALUES ('list_page_size', '2', 'string')`)
+		require.NoError(t, err)
+		defer testDB.DB.Exec(`DELETE FROM settings WHERE key = 'list_page_size'`)
+
+		clientID := testDB.InsertTestClient(t, "Busy Client")
+		projectID := testDB.InsertTestProject(t, "Busy Project", clientID)
+		for day := 1; day <= 5; day++ {
+			testDB.InsertTestTimesheet(t, projectID, fmt.Sprintf("2024-05-%02d", day), "1.00", "100.00", fmt.Sprintf("Day %d", day))
+		}
+		testDB.InsertTestInvoice(t, projectID, "2024-05-31", "", "Net 30", "300.00")
+		testDB.InsertTestInvoice(t, projectID, "2024-06-30", "", "Net 30", "200.00")
+		testDB.InsertTestInvoice(t, projectID, "2024-07-31", "", "Net 30", "100.00")
+
+		view := func(query string) string {
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/project/view/%d?%s", projectID, query), nil)
+			req.SetPathValue("id", strconv.Itoa(projectID))
+			rr := httptest.NewRecorder()
+			app.projectView(rr, req)
+			require.Equal(t, http.StatusOK, rr.Code)
+			return rr.Body.String()
+		}
+
+		body := view("")
+		assert.Contains(t, body, `<p class="timesheet">Day 5</p>`)
+		assert.Contains(t, body, `<p class="timesheet">Day 4</p>`)
+		assert.NotContains(t, body, "Day 3")
+		assert.Contains(t, body, `<p class="timesheet-pages">1 of 3 ?timesheets_page=2</p>`)
+		assert.Contains(t, body, `<p class="invoice-pages">1 of 2</p>`)
+		assert.Contains(t, body, `<p class="margin">500.00</p>`, "the margin covers every page")
+
+		body = view("timesheets_page=3&invoices_page=2")
+		assert.Contains(t, body, `<p class="timesheet">Day 1</p>`)
+		assert.NotContains(t, body, "Day 2")
+		assert.Contains(t, body, `<p class="invoice">300.00</p>`)
+		assert.NotContains(t, body, `<p class="invoice">200.00</p>`)
+		assert.Contains(t, body, `?invoices_page=2&amp;timesheets_page=4`, "the invoice page is kept when paging timesheets")
+	})
+
 	t.Run("view non-existent project", func(t *testing.T) {
 		testDB.TruncateTable(t, "project")
 
@@ -880,6 +1744,158 @@ func TestProjectViewHandler(t *testing.T) {
 	})
 }
 
+// fakeResolver knows the mail servers of a few domains, and that any other doesn't exist.
+// Lookups of offline.test fail as if there were no network.
+type fakeResolver map[string][]*net.MX
+
+func (r fakeResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	if name == "offline.test" {
+		return nil, &net.DNSError{Err: "i/o timeout", Name: name, IsTimeout: true}
+	}
+	if servers, ok := r[name]; ok {
+		return servers, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestClientEmailCheck(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+	app.resolver = fakeResolver{"example.com": {{Host: "mx.example.com.", Pref: 10}}}
+
+	post := func(handler http.HandlerFunc, id int, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	client := func(email, ccEmail string) url.Values {
+		return url.Values{"name": {"Checked Client"}, "email": {email}, "invoice_cc_email": {ccEmail}, "hourly_rate": {"50"}}
+	}
+
+	t.Run("addresses at domains that receive mail are saved", func(t *testing.T) {
+		rr := post(app.clientCreatePost, 0, client("jane@example.com", "accounts@example.com"))
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+	})
+
+	t.Run("undeliverable addresses are flagged", func(t *testing.T) {
+		rr := post(app.clientCreatePost, 0, client("jane@exmaple.com", "accounts@example.con"))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		body := rr.Body.String()
+		assert.Contains(t, body, "Email jane@exmaple.com looks undeliverable")
+		assert.Contains(t, body, "Invoice CC email accounts@example.con looks undeliverable")
+	})
+
+	t.Run("flagged addresses are saved once acknowledged", func(t *testing.T) {
+		form := client("jane@exmaple.com", "")
+		form.Set("acknowledge_warnings", "true")
+		rr := post(app.clientCreatePost, 0, form)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+	})
+
+	t.Run("updates are checked too", func(t *testing.T) {
+		id := testDB.InsertTestClient(t, "Existing Client")
+		rr := post(app.clientUpdatePost, id, client("jane@exmaple.com", ""))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "looks undeliverable")
+	})
+
+	t.Run("a failed lookup doesn't flag the address", func(t *testing.T) {
+		rr := post(app.clientCreatePost, 0, client("jane@offline.test", ""))
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+	})
+
+	t.Run("the check can be turned off", func(t *testing.T) {
+		_, err := testDB.DB.Exec("UPDATE settings SET value = 'false' WHERE key = 'client_email_check'")
+		require.NoError(t, err)
+		rr := post(app.clientCreatePost, 0, client("jane@exmaple.com", ""))
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+	})
+}
+
+func TestClientPipeline(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	post := func(handler http.HandlerFunc, path string, id int, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if id > 0 {
+			req.SetPathValue("id", strconv.Itoa(id))
+		}
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	createProject := func(id int) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		app.projectCreate(rr, req)
+		return rr.Code
+	}
+
+	rr := post(app.clientCreatePost, "/client/create", 0, url.Values{
+		"name":           {"Prospective Press"},
+		"email":          {"hello@prospective.test"},
+		"hourly_rate":    {"80"},
+		"pipeline_stage": {models.PipelineStageLead},
+	})
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+	clients, err := app.clients.GetAll()
+	require.NoError(t, err)
+	require.Len(t, clients, 1)
+	prospect := clients[0]
+	assert.True(t, prospect.IsProspect())
+	assert.Equal(t, models.PipelineStageLead, prospect.PipelineStage)
+
+	t.Run("an unknown stage is rejected", func(t *testing.T) {
+		rr := post(app.clientCreatePost, "/client/create", 0, url.Values{
+			"name": {"Nobody"}, "email": {"nobody@example.test"}, "hourly_rate": {"80"}, "pipeline_stage": {"Maybe"},
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+		rr = post(app.clientStagePost, "/", prospect.ID, url.Values{"stage": {"Maybe"}})
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("prospects can't have projects", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, createProject(prospect.ID))
+		rr := post(app.projectCreatePost, "/", prospect.ID, url.Values{"name": {"Too Soon"}})
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("prospects move through the board", func(t *testing.T) {
+		rr := post(app.clientStagePost, "/", prospect.ID, url.Values{"stage": {models.PipelineStageProposalSent}})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		req := httptest.NewRequest(http.MethodGet, "/clients/pipeline", nil)
+		rr = httptest.NewRecorder()
+		app.clientsPipeline(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `Proposal Sent: <span class="prospect">Prospective Press</span>`)
+	})
+
+	t.Run("converting unlocks project creation", func(t *testing.T) {
+		rr := post(app.clientConvertPost, "/", prospect.ID, url.Values{})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		client, err := app.clients.Get(prospect.ID)
+		require.NoError(t, err)
+		assert.False(t, client.IsProspect())
+		assert.Equal(t, http.StatusOK, createProject(prospect.ID))
+
+		rr = post(app.clientStagePost, "/", prospect.ID, url.Values{"stage": {models.PipelineStageLead}})
+		assert.Equal(t, http.StatusConflict, rr.Code, "active clients don't go back into the pipeline")
+	})
+}
+
 func TestProjectCreateHandler(t *testing.T) {
 	app, testDB := createTestApp(t)
 	defer testDB.Cleanup(t)
@@ -930,6 +1946,7 @@ func TestProjectCreatePostHandler(t *testing.T) {
 		form.Add("name", "New Test Project")
 		form.Add("status", "Estimating")
 		form.Add("hourly_rate", "50.00")
+		form.Add("estimated_hours", "40")
 
 		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/client/%d/project/create", clientID), strings.NewReader(form.Encode()))
 		req.SetPathValue("id", strconv.Itoa(clientID))
@@ -949,17 +1966,22 @@ func TestProjectCreatePostHandler(t *testing.T) {
 		require.Len(t, projects, 1)
 		assert.Equal(t, "New Test Project", projects[0].Name)
 		assert.Equal(t, clientID, projects[0].ClientID)
+		require.NotNil(t, projects[0].EstimatedHours)
+		assert.Equal(t, 40.0, *projects[0].EstimatedHours)
+		assert.Nil(t, projects[0].EstimatedAmount)
 	})
 
-	t.Run("validation error - empty name", func(t *testing.T) {
+	t.Run("validation error - negative estimate", func(t *testing.T) {
 		testDB.TruncateTable(t, "project")
 		testDB.TruncateTable(t, "client")
 
-		// Insert test client
 		clientID := testDB.InsertTestClient(t, "Test Client")
 
 		form := url.Values{}
-		form.Add("name", "")
+		form.Add("name", "Estimated Project")
+		form.Add("status", "Estimating")
+		form.Add("hourly_rate", "50.00")
+		form.Add("estimated_amount", "-500")
 
 		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/client/%d/project/create", clientID), strings.NewReader(form.Encode()))
 		req.SetPathValue("id", strconv.Itoa(clientID))
@@ -968,23 +1990,46 @@ func TestProjectCreatePostHandler(t *testing.T) {
 
 		app.projectCreatePost(rr, req)
 
-		// Should return form with validation error
 		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
-		body := rr.Body.String()
-		assert.Contains(t, body, "Name is required")
-
-		// Verify no project was created
 		projects, err := app.projects.GetByClient(clientID)
 		require.NoError(t, err)
 		assert.Empty(t, projects)
 	})
 
-	t.Run("create project for non-existent client", func(t *testing.T) {
+	t.Run("validation error - empty name", func(t *testing.T) {
 		testDB.TruncateTable(t, "project")
 		testDB.TruncateTable(t, "client")
 
-		form := url.Values{}
-		form.Add("name", "Test Project")
+		// Insert test client
+		clientID := testDB.InsertTestClient(t, "Test Client")
+
+		form := url.Values{}
+		form.Add("name", "")
+
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/client/%d/project/create", clientID), strings.NewReader(form.Encode()))
+		req.SetPathValue("id", strconv.Itoa(clientID))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		app.projectCreatePost(rr, req)
+
+		// Should return form with validation error
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		body := rr.Body.String()
+		assert.Contains(t, body, "Name is required")
+
+		// Verify no project was created
+		projects, err := app.projects.GetByClient(clientID)
+		require.NoError(t, err)
+		assert.Empty(t, projects)
+	})
+
+	t.Run("create project for non-existent client", func(t *testing.T) {
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+
+		form := url.Values{}
+		form.Add("name", "Test Project")
 
 		req := httptest.NewRequest(http.MethodPost, "/client/999/project/create", strings.NewReader(form.Encode()))
 		req.SetPathValue("id", "999")
@@ -1104,6 +2149,40 @@ func TestTimesheetCreate(t *testing.T) {
 		assert.Contains(t, body, `value="95.75"`) // Hourly rate from project
 	})
 
+	t.Run("descriptions are limited to the length in settings", func(t *testing.T) {
+		clientID := testDB.InsertTestClient(t, "Long Notes Client")
+		projectID := testDB.InsertTestProject(t, "Long Notes Project", clientID)
+		post := func(description string) *httptest.ResponseRecorder {
+			form := url.Values{}
+			form.Add("work_date", "2024-01-15")
+			form.Add("hours_worked", "1.0")
+			form.Add("hourly_rate", "85.00")
+			form.Add("description", description)
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/project/%d/timesheet/create", projectID), strings.NewReader(form.Encode()))
+			req.SetPathValue("id", strconv.Itoa(projectID))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			app.timesheetCreatePost(rr, req)
+			return rr
+		}
+
+		long := "Reviewed:\n- " + strings.Repeat("a", 300)
+		rr := post(long)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Description must be shorter than 255 characters")
+		assert.Contains(t, rr.Body.String(), `maxlength="255"`)
+
+		require.NoError(t, app.settings.UpdateValue("timesheet_description_max_length", "1000"))
+		defer app.settings.UpdateValue("timesheet_description_max_length", "255")
+		rr = post(long)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		timesheets, err := app.timesheets.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, timesheets, 1)
+		assert.Equal(t, long, timesheets[0].Description, "the Markdown is stored as typed")
+	})
+
 	t.Run("timesheet create for non-existent project", func(t *testing.T) {
 		testDB.TruncateTable(t, "project")
 
@@ -1203,6 +2282,51 @@ func TestTimesheetCreatePost(t *testing.T) {
 
 		assert.Equal(t, http.StatusNotFound, rr.Code)
 	})
+
+	t.Run("repeated entry is logged on each weekday of the range", func(t *testing.T) {
+		testDB.TruncateTable(t, "timesheet")
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+
+		clientID := testDB.InsertTestClient(t, "Test Client")
+		projectID := testDB.InsertTestProject(t, "Test Project", clientID)
+
+		post := func(repeatUntil string) *httptest.ResponseRecorder {
+			form := url.Values{}
+			form.Add("work_date", "2024-01-12")
+			form.Add("repeat_until", repeatUntil)
+			form.Add("skip_weekends", "true")
+			form.Add("hours_worked", "2.0")
+			form.Add("hourly_rate", "85.00")
+			form.Add("description", "Daily standup")
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/project/%d/timesheet/create", projectID), strings.NewReader(form.Encode()))
+			req.SetPathValue("id", strconv.Itoa(projectID))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			app.timesheetCreatePost(rr, req)
+			return rr
+		}
+
+		rr := post("2024-01-10")
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, "repeat until can't be before the work date")
+		rr = post("2024-12-31")
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, "repeats are limited in length")
+
+		rr = post("2024-01-16")
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		timesheets, err := app.timesheets.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, timesheets, 3)
+		var dates []string
+		for _, timesheet := range timesheets {
+			dates = append(dates, timesheet.WorkDate.Format("2006-01-02"))
+			assert.Equal(t, 2.0, timesheet.HoursWorked)
+			assert.Equal(t, "Daily standup", timesheet.Description)
+		}
+		assert.ElementsMatch(t, []string{"2024-01-12", "2024-01-15", "2024-01-16"}, dates)
+	})
 }
 
 func TestProjectsList(t *testing.T) {
@@ -1240,6 +2364,25 @@ func TestProjectsList(t *testing.T) {
 		assert.Contains(t, body, "Estimating")
 	})
 
+	t.Run("variance against estimates", func(t *testing.T) {
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+
+		clientID := testDB.InsertTestClient(t, "Test Client")
+		projectID := testDB.InsertTestProject(t, "Estimated", clientID)
+		_, err := testDB.DB.Exec("UPDATE project SET estimated_hours = 8 WHERE id = ?", projectID)
+		require.NoError(t, err)
+		testDB.InsertTestTimesheet(t, projectID, "2024-05-02", "3.0", "50.00", "Drafting")
+
+		req := httptest.NewRequest(http.MethodGet, "/projects", nil)
+		rr := httptest.NewRecorder()
+
+		app.projectsList(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `<td class="variance">-5.00 h</td>`)
+	})
+
 	t.Run("show projects list when empty", func(t *testing.T) {
 		testDB.TruncateTable(t, "project")
 		testDB.TruncateTable(t, "client")
@@ -1359,6 +2502,127 @@ func TestProjectUpdatePostHandler(t *testing.T) {
 	})
 }
 
+func TestProjectCloneHandlers(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	setup := func(t *testing.T) (int, int) {
+		testDB.TruncateTable(t, "timesheet")
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+
+		clientID := testDB.InsertTestClient(t, "Clone Client")
+		projectID := testDB.InsertTestProject(t, "Monthly Retainer", clientID)
+		testDB.InsertTestTimesheet(t, projectID, "2024-01-02", "0", "50.00", "Status meeting")
+		testDB.InsertTestTimesheet(t, projectID, "2024-01-02", "0", "50.00", "Monthly report")
+		testDB.InsertTestTimesheet(t, projectID, "2024-01-03", "3.5", "50.00", "Fixed the build")
+		return clientID, projectID
+	}
+
+	clonePost := func(projectID int, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/project/clone/%d", projectID), strings.NewReader(form.Encode()))
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		app.projectClonePost(rr, req)
+		return rr
+	}
+
+	newProject := func(t *testing.T, clientID int, name string) models.Project {
+		projects, err := app.projects.GetByClient(clientID)
+		require.NoError(t, err)
+		for _, project := range projects {
+			if project.Name == name {
+				return project
+			}
+		}
+		t.Fatalf("project %s not found", name)
+		return models.Project{}
+	}
+
+	t.Run("form is filled in from the project", func(t *testing.T) {
+		_, projectID := setup(t)
+
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/project/clone/%d", projectID), nil)
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+		app.projectClone(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `value="Copy of Monthly Retainer"`)
+	})
+
+	t.Run("template rows are copied", func(t *testing.T) {
+		clientID, projectID := setup(t)
+
+		form := url.Values{}
+		form.Add("name", "February Retainer")
+		form.Add("status", "Estimating")
+		form.Add("hourly_rate", "75.00")
+		form.Add("copy_template_timesheets", "true")
+		rr := clonePost(projectID, form)
+
+		project := newProject(t, clientID, "February Retainer")
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, fmt.Sprintf("/project/view/%d", project.ID), rr.Header().Get("Location"))
+
+		timesheets, err := app.timesheets.GetByProject(project.ID)
+		require.NoError(t, err)
+		require.Len(t, timesheets, 2, "only the rows with no hours are templates")
+		var descriptions []string
+		for _, timesheet := range timesheets {
+			descriptions = append(descriptions, timesheet.Description)
+			assert.Zero(t, timesheet.HoursWorked)
+			assert.Equal(t, 75.0, timesheet.HourlyRate)
+		}
+		assert.ElementsMatch(t, []string{"Status meeting", "Monthly report"}, descriptions)
+
+		// The source project keeps its own entries
+		timesheets, err = app.timesheets.GetByProject(projectID)
+		require.NoError(t, err)
+		assert.Len(t, timesheets, 3)
+	})
+
+	t.Run("template rows are left behind unless asked for", func(t *testing.T) {
+		clientID, projectID := setup(t)
+
+		form := url.Values{}
+		form.Add("name", "Plain Copy")
+		form.Add("status", "Estimating")
+		form.Add("hourly_rate", "50.00")
+		rr := clonePost(projectID, form)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		timesheets, err := app.timesheets.GetByProject(newProject(t, clientID, "Plain Copy").ID)
+		require.NoError(t, err)
+		assert.Empty(t, timesheets)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		_, projectID := setup(t)
+
+		form := url.Values{}
+		form.Add("status", "Estimating")
+		form.Add("hourly_rate", "50.00")
+		rr := clonePost(projectID, form)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Name is required")
+	})
+
+	t.Run("non-existent project", func(t *testing.T) {
+		testDB.TruncateTable(t, "project")
+
+		req := httptest.NewRequest(http.MethodGet, "/project/clone/999", nil)
+		req.SetPathValue("id", "999")
+		rr := httptest.NewRecorder()
+		app.projectClone(rr, req)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		assert.Equal(t, http.StatusNotFound, clonePost(999, url.Values{}).Code)
+	})
+}
+
 func TestProjectDeleteHandler(t *testing.T) {
 	app, testDB := createTestApp(t)
 	defer testDB.Cleanup(t)
@@ -1532,3 +2796,5905 @@ func TestDeleteHandlersIntegration(t *testing.T) {
 		assert.Contains(t, body, "Client 1")
 	})
 }
+
+func TestBusinessProfileHandlers(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	t.Run("list profiles", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+		testDB.InsertTestBusinessProfile(t, "Editing Services", "ED-")
+
+		req := httptest.NewRequest(http.MethodGet, "/profiles", nil)
+		rr := httptest.NewRecorder()
+
+		app.businessProfilesList(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Editing Services ED-")
+	})
+
+	t.Run("successful profile creation", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+
+		form := url.Values{}
+		form.Add("name", "Consulting LLC")
+		form.Add("freelancer_name", "Consulting LLC")
+		form.Add("invoice_prefix", "CON-")
+		form.Add("next_invoice_number", "100")
+
+		req := httptest.NewRequest(http.MethodPost, "/profile/create", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		app.businessProfileCreatePost(rr, req)
+
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/profiles", rr.Header().Get("Location"))
+
+		profiles, err := app.businessProfiles.GetAll()
+		require.NoError(t, err)
+		require.Len(t, profiles, 1)
+		assert.Equal(t, "Consulting LLC", profiles[0].Name)
+		assert.Equal(t, "CON-", profiles[0].InvoicePrefix)
+		assert.Equal(t, 100, profiles[0].NextInvoiceNumber)
+	})
+
+	t.Run("validation errors on create", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+
+		form := url.Values{}
+		form.Add("name", "")
+		form.Add("next_invoice_number", "0")
+
+		req := httptest.NewRequest(http.MethodPost, "/profile/create", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		app.businessProfileCreatePost(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		body := rr.Body.String()
+		assert.Contains(t, body, "Name is required")
+		assert.Contains(t, body, "Next invoice number must be a whole number of 1 or greater")
+
+		profiles, err := app.businessProfiles.GetAll()
+		require.NoError(t, err)
+		assert.Empty(t, profiles)
+	})
+
+	t.Run("successful profile update", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+		id := testDB.InsertTestBusinessProfile(t, "Old Name", "OLD-")
+
+		form := url.Values{}
+		form.Add("name", "New Name")
+		form.Add("invoice_prefix", "NEW-")
+		form.Add("next_invoice_number", "5")
+
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/profile/update/%d", id), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+
+		app.businessProfileUpdatePost(rr, req)
+
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		profile, err := app.businessProfiles.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, "New Name", profile.Name)
+		assert.Equal(t, "NEW-", profile.InvoicePrefix)
+		assert.Equal(t, 5, profile.NextInvoiceNumber)
+	})
+
+	t.Run("delete profile", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+		id := testDB.InsertTestBusinessProfile(t, "To Delete", "")
+
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/profile/delete/%d", id), nil)
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+
+		app.businessProfileDelete(rr, req)
+
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		_, err := app.businessProfiles.Get(id)
+		assert.Equal(t, models.ErrNoRecord, err)
+	})
+
+	t.Run("delete non-existent profile", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+
+		req := httptest.NewRequest(http.MethodPost, "/profile/delete/999", nil)
+		req.SetPathValue("id", "999")
+		rr := httptest.NewRecorder()
+
+		app.businessProfileDelete(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestInvoiceCreatePostUsesBusinessProfileNumbering(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	testDB.TruncateTable(t, "invoice")
+	testDB.TruncateTable(t, "project")
+	testDB.TruncateTable(t, "client")
+	testDB.TruncateTable(t, "business_profile")
+
+	profileID := testDB.InsertTestBusinessProfile(t, "Translation Studio", "TS-")
+	clientID := testDB.InsertTestClient(t, "Profile Client")
+	projectID := testDB.InsertTestProject(t, "Profile Project", clientID)
+	_, err := testDB.DB.Exec("UPDATE client SET business_profile_id = ? WHERE id = ?", profileID, clientID)
+	require.NoError(t, err)
+
+	createInvoice := func() {
+		form := url.Values{}
+		form.Add("invoice_date", "2024-03-01")
+		form.Add("amount_due", "250.00")
+		form.Add("payment_terms", "Net 30")
+		// The second invoice is identical to the first, so it has to get past the duplicate warning
+		form.Add("acknowledge_warnings", "true")
+
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/create/%d", projectID), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+
+		app.invoiceCreatePost(rr, req)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+	}
+
+	createInvoice()
+	createInvoice()
+
+	invoices, err := app.invoices.GetByProject(projectID)
+	require.NoError(t, err)
+	require.Len(t, invoices, 2)
+
+	numbers := []string{invoices[0].InvoiceNumber, invoices[1].InvoiceNumber}
+	assert.ElementsMatch(t, []string{"TS-0001", "TS-0002"}, numbers)
+
+	profile, err := app.businessProfiles.Get(profileID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, profile.NextInvoiceNumber)
+}
+
+func TestPaymentTermsPresets(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	postForm := func(handler http.HandlerFunc, path string, form url.Values, pathValue string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", pathValue)
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	t.Run("presets are added and removed", func(t *testing.T) {
+		rr := postForm(app.paymentTermsCreatePost, "/payment-terms/create", url.Values{"terms": {" Net 45 "}}, "")
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		rr = postForm(app.paymentTermsCreatePost, "/payment-terms/create", url.Values{"terms": {"Net 45"}}, "")
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "These payment terms are already a preset")
+
+		rr = postForm(app.paymentTermsCreatePost, "/payment-terms/create", url.Values{"terms": {""}}, "")
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Payment terms are required")
+
+		presets, err := app.paymentTerms.GetAll()
+		require.NoError(t, err)
+		require.Len(t, presets, 1)
+		assert.Equal(t, "Net 45", presets[0].Terms)
+
+		rr = postForm(app.paymentTermsDelete, "/payment-terms/delete", nil, strconv.Itoa(presets[0].ID))
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		rr = postForm(app.paymentTermsDelete, "/payment-terms/delete", nil, strconv.Itoa(presets[0].ID))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("the invoice form takes a preset unless terms are typed in", func(t *testing.T) {
+		_, err := app.paymentTerms.Insert("Net 15")
+		require.NoError(t, err)
+		projectID := testDB.InsertTestProject(t, "Terms Project", testDB.InsertTestClient(t, "Terms Client"))
+
+		createInvoice := func(preset, typed, amount string) models.Invoice {
+			form := url.Values{
+				"invoice_date":         {"2024-03-01"},
+				"amount_due":           {amount},
+				"payment_terms_preset": {preset},
+				"payment_terms":        {typed},
+			}
+			rr := postForm(app.invoiceCreatePost, "/invoice/create", form, strconv.Itoa(projectID))
+			require.Equal(t, http.StatusSeeOther, rr.Code)
+
+			invoices, err := app.invoices.GetByProject(projectID)
+			require.NoError(t, err)
+			for _, invoice := range invoices {
+				if fmt.Sprintf("%.2f", invoice.AmountDue) == amount {
+					return invoice
+				}
+			}
+			t.Fatalf("no invoice for %s", amount)
+			return models.Invoice{}
+		}
+
+		invoice := createInvoice("Net 15", "", "100.00")
+		assert.Equal(t, "Net 15", invoice.PaymentTerms)
+		require.NotNil(t, invoice.DueDate)
+		assert.Equal(t, "2024-03-16", invoice.DueDate.Format("2006-01-02"))
+
+		invoice = createInvoice("Net 15", "Net 7, 2% discount", "200.00")
+		assert.Equal(t, "Net 7, 2% discount", invoice.PaymentTerms)
+
+		req := httptest.NewRequest(http.MethodGet, "/invoice/update", nil)
+		req.SetPathValue("id", strconv.Itoa(invoice.ID))
+		rr := httptest.NewRecorder()
+		app.invoiceUpdate(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `<option>Net 15</option>`)
+		assert.Contains(t, rr.Body.String(), `name="payment_terms" value="Net 7, 2% discount"`)
+
+		invoice = createInvoice("Net 15", "", "300.00")
+		req = httptest.NewRequest(http.MethodGet, "/invoice/update", nil)
+		req.SetPathValue("id", strconv.Itoa(invoice.ID))
+		rr = httptest.NewRecorder()
+		app.invoiceUpdate(rr, req)
+		assert.Contains(t, rr.Body.String(), `<option selected>Net 15</option>`)
+		assert.Contains(t, rr.Body.String(), `name="payment_terms" value=""`)
+	})
+}
+
+func TestProjectChecklist(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	post := func(handler http.HandlerFunc, path string, form url.Values, pathValues map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for name, value := range pathValues {
+			req.SetPathValue(name, value)
+		}
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	clientID := testDB.InsertTestClient(t, "Checklist Client")
+	bookID := testDB.InsertTestProject(t, "Book", clientID)
+	articleID := testDB.InsertTestProject(t, "Article", clientID)
+	editingID, err := app.tags.Insert(models.Tag{Name: "Editing", Color: models.DefaultTagColor})
+	require.NoError(t, err)
+	require.NoError(t, app.tags.SetProjectTags(bookID, []int{editingID}))
+
+	t.Run("items are added for all projects or a tag", func(t *testing.T) {
+		rr := post(app.checklistCreatePost, "/checklist/create", url.Values{"label": {" Signed agreement received "}, "tag_id": {"0"}}, nil)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		rr = post(app.checklistCreatePost, "/checklist/create", url.Values{"label": {"Style guide received"}, "tag_id": {strconv.Itoa(editingID)}}, nil)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		rr = post(app.checklistCreatePost, "/checklist/create", url.Values{"label": {""}}, nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Item is required")
+		rr = post(app.checklistCreatePost, "/checklist/create", url.Values{"label": {"Invoice sent"}, "tag_id": {"9999"}}, nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Choose a tag from the list")
+
+		items, err := app.checklists.GetItems()
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.Equal(t, "Signed agreement received", items[0].Label)
+	})
+
+	items, err := app.checklists.GetItems()
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	agreementID, styleGuideID := items[0].ID, items[1].ID
+
+	t.Run("items are ticked off on the project view", func(t *testing.T) {
+		path := map[string]string{"id": strconv.Itoa(bookID), "item": strconv.Itoa(styleGuideID)}
+		rr := post(app.projectChecklistPost, "/project/checklist", url.Values{"checked": {"true"}}, path)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/project/view/%d", bookID), nil)
+		req.SetPathValue("id", strconv.Itoa(bookID))
+		rr = httptest.NewRecorder()
+		app.projectView(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Signed agreement received: to do")
+		assert.Contains(t, rr.Body.String(), "Style guide received: checked")
+
+		rr = post(app.projectChecklistPost, "/project/checklist", url.Values{"checked": {"false"}}, path)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		checklist, err := app.checklists.GetForProject(bookID)
+		require.NoError(t, err)
+		assert.False(t, checklist[1].Checked())
+
+		rr = post(app.projectChecklistPost, "/project/checklist", url.Values{"checked": {"maybe"}}, path)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("only the project's own items can be ticked off", func(t *testing.T) {
+		path := map[string]string{"id": strconv.Itoa(articleID), "item": strconv.Itoa(styleGuideID)}
+		rr := post(app.projectChecklistPost, "/project/checklist", url.Values{"checked": {"true"}}, path)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("archived projects are left unchanged", func(t *testing.T) {
+		require.NoError(t, app.projects.SetStatus(articleID, models.ProjectStatusArchived))
+		path := map[string]string{"id": strconv.Itoa(articleID), "item": strconv.Itoa(agreementID)}
+		rr := post(app.projectChecklistPost, "/project/checklist", url.Values{"checked": {"true"}}, path)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("items are removed", func(t *testing.T) {
+		rr := post(app.checklistDelete, "/checklist/delete", nil, map[string]string{"id": strconv.Itoa(agreementID)})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		rr = post(app.checklistDelete, "/checklist/delete", nil, map[string]string{"id": strconv.Itoa(agreementID)})
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestInvoiceCreatePostCapturesProjectFinancials(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Snapshot Client")
+	projectID := testDB.InsertTestProject(t, "Snapshot Project", clientID)
+	_, err := testDB.DB.Exec("UPDATE project SET hourly_rate = 60, discount_percent = 5, discount_reason = 'Early bird', currency_display = 'GBP', currency_conversion_rate = 0.8 WHERE id = ?", projectID)
+	require.NoError(t, err)
+
+	form := url.Values{}
+	form.Add("invoice_date", "2024-03-01")
+	form.Add("amount_due", "300.00")
+	form.Add("payment_terms", "Net 30")
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetPathValue("id", strconv.Itoa(projectID))
+	rr := httptest.NewRecorder()
+	app.invoiceCreatePost(rr, req)
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+
+	_, err = testDB.DB.Exec("UPDATE project SET hourly_rate = 90, discount_percent = NULL, currency_display = 'USD' WHERE id = ?", projectID)
+	require.NoError(t, err)
+
+	invoices, err := app.invoices.GetByProject(projectID)
+	require.NoError(t, err)
+	require.Len(t, invoices, 1)
+	financials := invoices[0].Financials
+	require.NotNil(t, financials)
+	assert.Equal(t, 60.0, financials.HourlyRate)
+	require.NotNil(t, financials.DiscountPercent)
+	assert.Equal(t, 5.0, *financials.DiscountPercent)
+	assert.Equal(t, "Early bird", financials.DiscountReason)
+	assert.Equal(t, "GBP", financials.CurrencyDisplay)
+	assert.Equal(t, 0.8, financials.CurrencyConversionRate)
+}
+
+func TestInvoiceCreatePostUsesHistoricalExchangeRate(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Euro Client")
+	projectID := testDB.InsertTestProject(t, "Euro Project", clientID)
+	_, err := testDB.DB.Exec("UPDATE project SET currency_display = 'EUR', currency_conversion_rate = 0.8 WHERE id = ?", projectID)
+	require.NoError(t, err)
+
+	var fetches int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		if r.URL.Path != "/2024-03-09" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "USD", r.URL.Query().Get("from"))
+		assert.Equal(t, "EUR", r.URL.Query().Get("to"))
+		w.Write([]byte(`{"amount":1.0,"base":"USD","date":"2024-03-08","rates":{"EUR":0.9132}}`))
+	}))
+	defer server.Close()
+	app.rateFetcher.Client = server.Client()
+
+	day := func(value string) time.Time {
+		d, _ := time.Parse("2006-01-02", value)
+		return d
+	}
+	require.NoError(t, app.exchangeRates.Save(models.ExchangeRate{Currency: "EUR", Date: day("2024-02-01"), Rate: 0.92, Source: "ecb"}))
+
+	createInvoice := func(date string) models.InvoiceFinancials {
+		t.Helper()
+		form := url.Values{}
+		form.Add("invoice_date", date)
+		form.Add("amount_due", "100.00")
+		form.Add("acknowledge_warnings", "true")
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+		app.invoiceCreatePost(rr, req)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoices, err := app.invoices.GetByProject(projectID)
+		require.NoError(t, err)
+		for _, invoice := range invoices {
+			if invoice.InvoiceDate.Format("2006-01-02") == date {
+				require.NotNil(t, invoice.Financials)
+				return *invoice.Financials
+			}
+		}
+		t.Fatalf("no invoice dated %s", date)
+		return models.InvoiceFinancials{}
+	}
+
+	t.Run("stored rate of the invoice date", func(t *testing.T) {
+		financials := createInvoice("2024-02-01")
+		assert.Equal(t, 0.92, financials.CurrencyConversionRate)
+		assert.Equal(t, "ecb", financials.RateSource)
+		require.NotNil(t, financials.ConvertedAt)
+		assert.Equal(t, "2024-02-01", financials.ConvertedAt.Format("2006-01-02"))
+		assert.Zero(t, fetches, "no service is asked without an exchange rate URL")
+	})
+
+	t.Run("latest stored rate without a service", func(t *testing.T) {
+		financials := createInvoice("2024-02-05")
+		assert.Equal(t, 0.92, financials.CurrencyConversionRate)
+		assert.Equal(t, "2024-02-01", financials.ConvertedAt.Format("2006-01-02"))
+	})
+
+	require.NoError(t, app.settings.UpdateValue("exchange_rate_url", server.URL))
+
+	t.Run("fetched and stored for a backdated invoice", func(t *testing.T) {
+		financials := createInvoice("2024-03-09")
+		assert.Equal(t, 0.9132, financials.CurrencyConversionRate)
+		assert.Equal(t, server.Listener.Addr().String(), financials.RateSource)
+		assert.Equal(t, "2024-03-09", financials.ConvertedAt.Format("2006-01-02"))
+		assert.Equal(t, 1, fetches)
+
+		rate, err := app.exchangeRates.GetRate("EUR", day("2024-03-09"))
+		require.NoError(t, err)
+		assert.Equal(t, 0.9132, rate.Rate)
+	})
+
+	t.Run("latest stored rate when the fetch fails", func(t *testing.T) {
+		financials := createInvoice("2024-03-12")
+		assert.Equal(t, 2, fetches)
+		assert.Equal(t, 0.9132, financials.CurrencyConversionRate)
+		assert.Equal(t, "2024-03-09", financials.ConvertedAt.Format("2006-01-02"))
+	})
+
+	t.Run("project rate when no rate is known", func(t *testing.T) {
+		financials := createInvoice("2024-01-15")
+		assert.Equal(t, 0.8, financials.CurrencyConversionRate)
+		assert.Equal(t, models.ConversionRateSourceProject, financials.RateSource)
+	})
+}
+
+func TestInvoiceCreateDefaults(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Defaults Client")
+	projectID := testDB.InsertTestProject(t, "Defaults Project", clientID)
+	testDB.InsertTestTimesheet(t, projectID, "2024-03-04", "2.5", "40.00", "Copyediting")
+	testDB.InsertTestTimesheet(t, projectID, "2024-03-05", "1", "40.00", "Proofreading")
+
+	newInvoiceForm := func() string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+		app.invoiceCreate(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr.Body.String()
+	}
+
+	t.Run("the form is filled in as it always was", func(t *testing.T) {
+		body := newInvoiceForm()
+		assert.Contains(t, body, `name="invoice_date" value="`+time.Now().Format("2006-01-02")+`"`)
+		assert.Contains(t, body, `name="amount_due" value=""`)
+		assert.Contains(t, body, `name="payment_terms" value=""`)
+		assert.NotContains(t, body, `name="display_details" checked`)
+	})
+
+	t.Run("the settings change the defaults", func(t *testing.T) {
+		require.NoError(t, app.settings.UpdateValue("invoice_default_display_details", "true"))
+		require.NoError(t, app.settings.UpdateValue("invoice_default_date_today", "false"))
+		require.NoError(t, app.settings.UpdateValue("invoice_default_payment_terms", "Net 15"))
+		require.NoError(t, app.settings.UpdateValue("invoice_default_amount_auto", "true"))
+
+		body := newInvoiceForm()
+		assert.Contains(t, body, `name="invoice_date" value=""`)
+		assert.Contains(t, body, `name="due_date" value=""`, "no due date is worked out without an invoice date")
+		assert.Contains(t, body, `name="amount_due" value="140.00"`)
+		assert.Contains(t, body, `name="payment_terms" value="Net 15"`)
+		assert.Contains(t, body, `name="display_details" checked`)
+	})
+}
+
+func TestInvoiceDiscount(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Discount Client")
+	projectID := testDB.InsertTestProject(t, "Discount Project", clientID)
+	_, err := testDB.DB.Exec("UPDATE project SET discount_percent = 10, discount_reason = 'Loyalty' WHERE id = ?", projectID)
+	require.NoError(t, err)
+
+	post := func(handler http.HandlerFunc, id int, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	invoiceForm := func(discountPercent, discountAmount, reason string) url.Values {
+		return url.Values{
+			"invoice_date":     {"2024-03-01"},
+			"amount_due":       {"400.00"},
+			"payment_terms":    {"Net 30"},
+			"discount_percent": {discountPercent},
+			"discount_amount":  {discountAmount},
+			"discount_reason":  {reason},
+		}
+	}
+
+	t.Run("the form is prefilled with the project's discount", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+		app.invoiceCreate(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `name="discount_percent" value="10"`)
+		assert.Contains(t, rr.Body.String(), `name="discount_reason" value="Loyalty"`)
+	})
+
+	t.Run("percent and amount together are rejected", func(t *testing.T) {
+		rr := post(app.invoiceCreatePost, projectID, invoiceForm("10", "25.00", "Loyalty"))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Give either a discount percent or a discount amount, not both")
+
+		rr = post(app.invoiceCreatePost, projectID, invoiceForm("120", "", ""))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Discount percent can&#39;t be more than 100")
+
+		rr = post(app.invoiceCreatePost, projectID, invoiceForm("", "400.01", "Referral"))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Discount amount can&#39;t be more than the amount due")
+	})
+
+	t.Run("a fixed amount overrides the project's percentage", func(t *testing.T) {
+		rr := post(app.invoiceCreatePost, projectID, invoiceForm("", "25.00", "Referral"))
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoices, err := app.invoices.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, invoices, 1)
+		financials := invoices[0].Financials
+		require.NotNil(t, financials)
+		assert.Nil(t, financials.DiscountPercent)
+		require.NotNil(t, financials.DiscountAmount)
+		assert.Equal(t, 25.0, *financials.DiscountAmount)
+		assert.Equal(t, "Referral", financials.DiscountReason)
+
+		data, err := app.invoices.GetComprehensiveForPDF(invoices[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, 25.0, data.DiscountAmount)
+		assert.Equal(t, 375.0, data.FinalTotal)
+	})
+
+	t.Run("the discount can be changed or cleared on update", func(t *testing.T) {
+		invoices, err := app.invoices.GetByProject(projectID)
+		require.NoError(t, err)
+		id := invoices[0].ID
+
+		rr := post(app.invoiceUpdatePost, id, invoiceForm("5", "", "Referral"))
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		data, err := app.invoices.GetComprehensiveForPDF(id)
+		require.NoError(t, err)
+		assert.Equal(t, 20.0, data.DiscountAmount)
+
+		rr = post(app.invoiceUpdatePost, id, invoiceForm("", "", ""))
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		data, err = app.invoices.GetComprehensiveForPDF(id)
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, data.DiscountAmount)
+		assert.Equal(t, 400.0, data.FinalTotal)
+	})
+}
+
+func TestInvoiceCreatePostManualNumberAndDueDate(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	testDB.TruncateTable(t, "invoice")
+	testDB.TruncateTable(t, "project")
+	testDB.TruncateTable(t, "client")
+	testDB.TruncateTable(t, "business_profile")
+
+	profileID := testDB.InsertTestBusinessProfile(t, "Translation Studio", "TS-")
+	clientID := testDB.InsertTestClient(t, "Profile Client")
+	projectID := testDB.InsertTestProject(t, "Profile Project", clientID)
+	_, err := testDB.DB.Exec("UPDATE client SET business_profile_id = ? WHERE id = ?", profileID, clientID)
+	require.NoError(t, err)
+	_, err = testDB.DB.Exec("UPDATE business_profile SET next_invoice_number = 5 WHERE id = ?", profileID)
+	require.NoError(t, err)
+
+	createInvoice := func(invoiceNumber, dueDate string) *httptest.ResponseRecorder {
+		form := url.Values{}
+		form.Add("invoice_date", "2024-03-01")
+		form.Add("amount_due", "250.00")
+		form.Add("payment_terms", "Net 14")
+		form.Add("invoice_number", invoiceNumber)
+		form.Add("due_date", dueDate)
+		form.Add("acknowledge_warnings", "true")
+
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/create/%d", projectID), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+
+		app.invoiceCreatePost(rr, req)
+		return rr
+	}
+
+	t.Run("create form is prefilled", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/invoice/create/%d", projectID), nil)
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+
+		app.invoiceCreate(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `value="TS-0005"`)
+		assert.Contains(t, rr.Body.String(), time.Now().AddDate(0, 0, models.DefaultPaymentDays).Format("2006-01-02"))
+	})
+
+	t.Run("number ahead in the sequence is rejected", func(t *testing.T) {
+		rr := createInvoice("TS-0009", "")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "would be issued again")
+	})
+
+	t.Run("due date before invoice date is rejected", func(t *testing.T) {
+		rr := createInvoice("", "2024-02-01")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Due date can&#39;t be before the invoice date")
+	})
+
+	t.Run("manual number is kept and does not advance the sequence", func(t *testing.T) {
+		rr := createInvoice("LEGACY-17", "2024-04-15")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoices, err := app.invoices.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, invoices, 1)
+		assert.Equal(t, "LEGACY-17", invoices[0].InvoiceNumber)
+		require.NotNil(t, invoices[0].DueDate)
+		assert.Equal(t, "2024-04-15", invoices[0].DueDate.Format("2006-01-02"))
+
+		profile, err := app.businessProfiles.Get(profileID)
+		require.NoError(t, err)
+		assert.Equal(t, 5, profile.NextInvoiceNumber)
+	})
+
+	t.Run("number already in use is rejected", func(t *testing.T) {
+		rr := createInvoice("LEGACY-17", "")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Invoice number LEGACY-17 is already in use")
+	})
+
+	t.Run("blank due date follows the payment terms", func(t *testing.T) {
+		testDB.TruncateTable(t, "invoice")
+
+		rr := createInvoice("TS-0005", "")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoices, err := app.invoices.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, invoices, 1)
+		assert.Equal(t, "TS-0005", invoices[0].InvoiceNumber)
+		require.NotNil(t, invoices[0].DueDate)
+		assert.Equal(t, "2024-03-15", invoices[0].DueDate.Format("2006-01-02"))
+
+		profile, err := app.businessProfiles.Get(profileID)
+		require.NoError(t, err)
+		assert.Equal(t, 6, profile.NextInvoiceNumber)
+	})
+}
+
+func TestInvoiceCreatePostClientSequence(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	testDB.TruncateTable(t, "invoice")
+	testDB.TruncateTable(t, "project")
+	testDB.TruncateTable(t, "client")
+	testDB.TruncateTable(t, "business_profile")
+
+	profileID := testDB.InsertTestBusinessProfile(t, "Translation Studio", "TS-")
+	clientID := testDB.InsertTestClient(t, "Acme")
+	projectID := testDB.InsertTestProject(t, "Acme Manual", clientID)
+	_, err := testDB.DB.Exec("UPDATE client SET business_profile_id = ?, invoice_prefix = 'ACME-', next_invoice_number = 12 WHERE id = ?", profileID, clientID)
+	require.NoError(t, err)
+
+	createInvoice := func(invoiceNumber string) *httptest.ResponseRecorder {
+		form := url.Values{}
+		form.Add("invoice_date", "2024-03-01")
+		form.Add("amount_due", "250.00")
+		form.Add("payment_terms", "Net 14")
+		form.Add("invoice_number", invoiceNumber)
+		form.Add("acknowledge_warnings", "true")
+
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/create/%d", projectID), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+
+		app.invoiceCreatePost(rr, req)
+		return rr
+	}
+
+	t.Run("create form shows the client's next number", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/invoice/create/%d", projectID), nil)
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+
+		app.invoiceCreate(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `value="ACME-0012"`)
+	})
+
+	t.Run("number ahead in the client's sequence is rejected", func(t *testing.T) {
+		rr := createInvoice("ACME-0020")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "would be issued again by the Acme numbering sequence, which is at ACME-0012")
+	})
+
+	t.Run("invoice is numbered from the client's sequence", func(t *testing.T) {
+		rr := createInvoice("")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoices, err := app.invoices.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, invoices, 1)
+		assert.Equal(t, "ACME-0012", invoices[0].InvoiceNumber)
+
+		client, err := app.clients.Get(clientID)
+		require.NoError(t, err)
+		require.NotNil(t, client.NextInvoiceNumber)
+		assert.Equal(t, 13, *client.NextInvoiceNumber)
+
+		profile, err := app.businessProfiles.Get(profileID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, profile.NextInvoiceNumber, "the business profile's sequence is left alone")
+	})
+}
+
+func TestInvoiceCCOverride(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClientWithDefaults(t, "CC Client", 50, "", "", "client-cc@example.com", "")
+	projectID := testDB.InsertTestProject(t, "CC Project", clientID)
+
+	createInvoice := func(ccEmail string) *httptest.ResponseRecorder {
+		form := url.Values{}
+		form.Add("invoice_date", "2024-03-01")
+		form.Add("amount_due", "250.00")
+		form.Add("payment_terms", "Net 14")
+		form.Add("cc_email", ccEmail)
+		form.Add("acknowledge_warnings", "true")
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+
+		app.invoiceCreatePost(rr, req)
+		return rr
+	}
+
+	t.Run("form shows who is copied when left blank", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+
+		app.invoiceCreate(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Copies client-cc@example.com from the client")
+	})
+
+	t.Run("invalid address is rejected", func(t *testing.T) {
+		rr := createInvoice("not an email")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "CC email must be a valid email address")
+	})
+
+	t.Run("override is saved with the invoice", func(t *testing.T) {
+		rr := createInvoice("grants@example.com")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoices, err := app.invoices.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, invoices, 1)
+		assert.Equal(t, "grants@example.com", invoices[0].CCEmail)
+
+		project, err := app.projects.Get(projectID)
+		require.NoError(t, err)
+		client, err := app.clients.Get(clientID)
+		require.NoError(t, err)
+		assert.Equal(t, "invoice", models.ResolveInvoiceCC(invoices[0], project, client).Source)
+	})
+}
+
+func TestInvoiceBudgetCode(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "State University")
+	projectID := testDB.InsertTestProject(t, "Grant Report", clientID)
+
+	client, err := app.clients.Get(clientID)
+	require.NoError(t, err)
+	clientCode := "CC-1001"
+	client.BudgetCode = &clientCode
+	require.NoError(t, app.clients.Update(client))
+
+	createInvoice := func(budgetCode string) *httptest.ResponseRecorder {
+		form := url.Values{}
+		form.Add("invoice_date", "2024-03-01")
+		form.Add("amount_due", "250.00")
+		form.Add("payment_terms", "Net 14")
+		form.Add("budget_code", budgetCode)
+		form.Add("acknowledge_warnings", "true")
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+
+		app.invoiceCreatePost(rr, req)
+		return rr
+	}
+
+	t.Run("form shows the code printed when left blank", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+
+		app.invoiceCreate(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Prints CC-1001")
+	})
+
+	t.Run("overlong code is rejected", func(t *testing.T) {
+		rr := createInvoice(strings.Repeat("9", NAME_LENGTH+1))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Budget code must be shorter than")
+	})
+
+	t.Run("override is saved with the invoice and shown on the update form", func(t *testing.T) {
+		rr := createInvoice("ACCT-55120")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoices, err := app.invoices.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, invoices, 1)
+		assert.Equal(t, "ACCT-55120", invoices[0].BudgetCode)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(invoices[0].ID))
+		rr = httptest.NewRecorder()
+
+		app.invoiceUpdate(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `name="budget_code" value="ACCT-55120"`)
+	})
+}
+
+func TestInvoiceTimesheetRange(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Range Client")
+	projectID := testDB.InsertTestProject(t, "Range Project", clientID)
+
+	createInvoice := func(from, to string) *httptest.ResponseRecorder {
+		form := url.Values{}
+		form.Add("invoice_date", "2024-04-01")
+		form.Add("amount_due", "900.00")
+		form.Add("display_details", "true")
+		form.Add("timesheets_from", from)
+		form.Add("timesheets_to", to)
+		form.Add("acknowledge_warnings", "true")
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+
+		app.invoiceCreatePost(rr, req)
+		return rr
+	}
+
+	t.Run("range must not end before it starts", func(t *testing.T) {
+		rr := createInvoice("2024-03-31", "2024-03-01")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Timesheets to can&#39;t be before timesheets from")
+	})
+
+	t.Run("range is saved and shown on the update form", func(t *testing.T) {
+		rr := createInvoice("2024-03-01", "2024-03-31")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoices, err := app.invoices.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, invoices, 1)
+		require.NotNil(t, invoices[0].TimesheetsFrom)
+		require.NotNil(t, invoices[0].TimesheetsTo)
+		assert.Equal(t, "2024-03-01", invoices[0].TimesheetsFrom.Format("2006-01-02"))
+		assert.Equal(t, "2024-03-31", invoices[0].TimesheetsTo.Format("2006-01-02"))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(invoices[0].ID))
+		rr = httptest.NewRecorder()
+		app.invoiceUpdate(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `name="timesheets_from" value="2024-03-01"`)
+		assert.Contains(t, rr.Body.String(), `name="timesheets_to" value="2024-03-31"`)
+	})
+}
+
+func TestSignInvoicePDF(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Signing Client")
+	projectID := testDB.InsertTestProject(t, "Signing Project", clientID)
+	invoiceID, err := app.invoices.Insert(projectID, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), nil, "Net 30", 500.00, false)
+	require.NoError(t, err)
+	pdf := []byte("%PDF-1.4 unsigned")
+
+	showStatus := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(invoiceID))
+		rr := httptest.NewRecorder()
+		app.invoiceUpdate(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr.Body.String()
+	}
+
+	t.Run("leaves the PDF unsigned without a certificate", func(t *testing.T) {
+		result, err := app.signInvoicePDF(invoiceID, pdf)
+		require.NoError(t, err)
+		assert.Equal(t, pdf, result)
+		assert.Contains(t, showStatus(), "Not signed")
+	})
+
+	t.Run("fails when the certificate can't be loaded", func(t *testing.T) {
+		require.NoError(t, app.settings.UpdateValue("pdf_signing_certificate", filepath.Join(t.TempDir(), "missing.p12")))
+		defer app.settings.UpdateValue("pdf_signing_certificate", "")
+
+		_, err := app.signInvoicePDF(invoiceID, pdf)
+		assert.ErrorContains(t, err, "loading PDF signing certificate")
+		assert.Contains(t, showStatus(), "Not signed")
+	})
+
+	t.Run("shows who signed the invoice", func(t *testing.T) {
+		require.NoError(t, app.invoices.SetPDFSignature(invoiceID, "Jane Doe", time.Date(2024, 4, 2, 9, 0, 0, 0, time.UTC)))
+		assert.Contains(t, showStatus(), "Signed by Jane Doe")
+	})
+}
+
+func TestInvoicePrintPageWarning(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Print Client")
+	projectID := testDB.InsertTestProject(t, "Print Project", clientID)
+	invoiceID, err := app.invoices.Insert(projectID, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), nil, "Net 30", 2000.00, true)
+	require.NoError(t, err)
+	for day := 1; day <= 28; day++ {
+		testDB.InsertTestTimesheet(t, projectID, fmt.Sprintf("2024-02-%02d", day), "1.00", "50.00", "Editing")
+	}
+	require.NoError(t, app.settings.UpdateValue("invoice_max_pdf_pages", "1"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetPathValue("id", strconv.Itoa(invoiceID))
+	rr := httptest.NewRecorder()
+	app.invoicePrint(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "About 2 pages, more than 1")
+	assert.Contains(t, rr.Body.String(), fmt.Sprintf("/invoice/print/%d?confirm=1", invoiceID))
+
+	t.Run("missing invoice", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", "9999")
+		rr := httptest.NewRecorder()
+		app.invoicePrint(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestInvoicePrintJob(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Job Client")
+	projectID := testDB.InsertTestProject(t, "Job Project", clientID)
+	invoiceID := testDB.InsertTestInvoice(t, projectID, "2024-03-05", "", "Net 30", "100.00")
+
+	// Rendering a real PDF needs Chrome, so the job records which invoice it was given
+	app.queue.Handle(models.JobInvoicePDF, func(ctx context.Context, payload []byte) (jobs.Result, error) {
+		var params invoicePDFJobPayload
+		require.NoError(t, json.Unmarshal(payload, &params))
+		return jobs.Result{Data: []byte(fmt.Sprintf("%%PDF invoice %d", params.InvoiceID)), ContentType: "application/pdf", Filename: "invoice.pdf"}, nil
+	})
+
+	get := func(handler http.HandlerFunc, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", id)
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	rr := get(app.invoicePrint, strconv.Itoa(invoiceID))
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+	require.True(t, strings.HasPrefix(rr.Header().Get("Location"), "/job/view/"))
+	jobID := strings.TrimPrefix(rr.Header().Get("Location"), "/job/view/")
+
+	t.Run("job page waits for the queued job", func(t *testing.T) {
+		rr := get(app.jobView, jobID)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `data-status="queued"`)
+		assert.Contains(t, rr.Body.String(), "Invoice PDF")
+
+		rr = get(app.jobStatus, jobID)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, fmt.Sprintf(`{"id":%s,"status":"queued"}`, jobID), rr.Body.String())
+
+		rr = get(app.jobDownload, jobID)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("finished job can be downloaded", func(t *testing.T) {
+		ran, err := app.queue.RunNext(context.Background())
+		require.NoError(t, err)
+		require.True(t, ran)
+
+		rr := get(app.jobStatus, jobID)
+		assert.JSONEq(t, fmt.Sprintf(`{"id":%s,"status":"done","download_url":"/job/download/%s"}`, jobID, jobID), rr.Body.String())
+
+		rr = get(app.jobDownload, jobID)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/pdf", rr.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="invoice.pdf"`, rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, fmt.Sprintf("%%PDF invoice %d", invoiceID), rr.Body.String())
+	})
+
+	t.Run("missing invoice or job", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get(app.invoicePrint, "9999").Code)
+		assert.Equal(t, http.StatusNotFound, get(app.jobView, "9999").Code)
+		assert.Equal(t, http.StatusNotFound, get(app.jobStatus, "9999").Code)
+
+		_, err := app.invoicePDFJob(context.Background(), []byte(`{"invoice_id":9999}`))
+		assert.EqualError(t, err, "invoice 9999 no longer exists")
+	})
+}
+
+func TestTimesheetReport(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Report Client")
+	projectID := testDB.InsertTestProject(t, "Report Project", clientID)
+	secondProjectID := testDB.InsertTestProject(t, "Second Project", clientID)
+	testDB.InsertTestTimesheet(t, projectID, "2024-03-04", "2.00", "50.00", "Editing")
+	testDB.InsertTestTimesheet(t, secondProjectID, "2024-03-12", "1.50", "50.00", "Proofreading")
+	testDB.InsertTestTimesheet(t, projectID, "2024-04-02", "3.00", "50.00", "Next month")
+
+	get := func(handler http.HandlerFunc, id, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+		req.SetPathValue("id", id)
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	t.Run("project report is queued as a job", func(t *testing.T) {
+		rr := get(app.timesheetReport, strconv.Itoa(projectID), "start=2024-03-01&end=2024-03-31")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		require.True(t, strings.HasPrefix(rr.Header().Get("Location"), "/job/view/"))
+
+		jobID, err := strconv.Atoi(strings.TrimPrefix(rr.Header().Get("Location"), "/job/view/"))
+		require.NoError(t, err)
+		job, err := app.jobs.Get(jobID)
+		require.NoError(t, err)
+		assert.Equal(t, models.JobTimesheetReport, job.Kind)
+		assert.JSONEq(t, fmt.Sprintf(`{"project_id":%d,"start":"2024-03-01","end":"2024-03-31"}`, projectID), job.Payload)
+	})
+
+	t.Run("project report covers the project's time in the period", func(t *testing.T) {
+		params := timesheetReportJobPayload{ProjectID: projectID, Start: "2024-03-01", End: "2024-03-31"}
+		report, filename, err := app.timesheetReportFor(params, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, "Report Project", report.Title)
+		assert.Equal(t, "Report Client", report.ClientName)
+		assert.False(t, report.ShowProjects)
+		assert.Equal(t, 2.0, report.Hours)
+		assert.Equal(t, fmt.Sprintf("timesheets_project_%d_2024-03-01_2024-03-31.pdf", projectID), filename)
+	})
+
+	t.Run("client report covers all of the client's projects", func(t *testing.T) {
+		rr := get(app.clientTimesheetReport, strconv.Itoa(clientID), "start=2024-03-01&end=2024-03-31")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		params := timesheetReportJobPayload{ClientID: clientID, Start: "2024-03-01", End: "2024-03-31"}
+		report, filename, err := app.timesheetReportFor(params, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.True(t, report.ShowProjects)
+		assert.Len(t, report.Weeks, 2)
+		assert.Equal(t, 3.5, report.Hours)
+		assert.Equal(t, fmt.Sprintf("timesheets_client_%d_2024-03-01_2024-03-31.pdf", clientID), filename)
+	})
+
+	t.Run("invalid periods are rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get(app.timesheetReport, strconv.Itoa(projectID), "start=2024-03-01").Code)
+		assert.Equal(t, http.StatusBadRequest, get(app.timesheetReport, strconv.Itoa(projectID), "start=03/01/2024&end=2024-03-31").Code)
+		assert.Equal(t, http.StatusBadRequest, get(app.clientTimesheetReport, strconv.Itoa(clientID), "start=2024-03-31&end=2024-03-01").Code)
+	})
+
+	t.Run("missing project or client", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get(app.timesheetReport, "9999", "start=2024-03-01&end=2024-03-31").Code)
+		assert.Equal(t, http.StatusNotFound, get(app.clientTimesheetReport, "9999", "start=2024-03-01&end=2024-03-31").Code)
+	})
+}
+
+func TestClientPaymentBehavior(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	form := url.Values{}
+	form.Add("name", "Late Payer")
+	form.Add("email", "late@example.com")
+	form.Add("hourly_rate", "50.00")
+	form.Add("prepay_only", "true")
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	app.clientCreatePost(rr, req)
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+
+	clients, err := app.clients.GetAll()
+	require.NoError(t, err)
+	require.Len(t, clients, 1)
+	clientID := clients[0].ID
+	assert.True(t, clients[0].PrepayOnly)
+
+	viewClient := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(clientID))
+		rr := httptest.NewRecorder()
+		app.clientView(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr.Body.String()
+	}
+
+	t.Run("badges before any payment", func(t *testing.T) {
+		body := viewClient()
+		assert.Contains(t, body, "Prepay only")
+		assert.Contains(t, body, "No payment history")
+	})
+
+	t.Run("badge reflects late payments", func(t *testing.T) {
+		projectID := testDB.InsertTestProject(t, "Paid Late", clientID)
+		testDB.InsertTestInvoice(t, projectID, "2024-03-01", "2024-04-20", "Net 30", "100.00")
+
+		assert.Contains(t, viewClient(), "Often pays late")
+	})
+
+	t.Run("new projects warn about prepay only clients", func(t *testing.T) {
+		createProject := func(acknowledge bool) *httptest.ResponseRecorder {
+			form := url.Values{}
+			form.Add("name", "Next Project")
+			form.Add("status", "Estimating")
+			form.Add("hourly_rate", "50.00")
+			if acknowledge {
+				form.Add("acknowledge_warnings", "true")
+			}
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetPathValue("id", strconv.Itoa(clientID))
+			rr := httptest.NewRecorder()
+			app.projectCreatePost(rr, req)
+			return rr
+		}
+
+		rr := createProject(false)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Late Payer is flagged as prepay only, so take payment before starting work")
+
+		rr = createProject(true)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+	})
+}
+
+func TestArchivedProjectIsReadOnly(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Archive Client")
+	projectID := testDB.InsertTestProject(t, "Archive Project", clientID)
+	timesheetID := testDB.InsertTestTimesheet(t, projectID, "2024-03-01", "2.00", "50.00", "Done")
+	invoiceID := testDB.InsertTestInvoice(t, projectID, "2024-03-05", "", "Net 30", "100.00")
+	require.NoError(t, app.projects.SetStatus(projectID, models.ProjectStatusArchived))
+
+	post := func(handler http.HandlerFunc, id int, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	timesheetForm := url.Values{"work_date": {"2024-03-02"}, "hours_worked": {"1.00"}, "hourly_rate": {"50.00"}}
+	invoiceForm := url.Values{"invoice_date": {"2024-03-06"}, "amount_due": {"50.00"}, "void_reason": {"Typo"}}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		id      int
+		form    url.Values
+	}{
+		{"create timesheet", app.timesheetCreatePost, projectID, timesheetForm},
+		{"update timesheet", app.timesheetUpdatePost, timesheetID, timesheetForm},
+		{"delete timesheet", app.timesheetDelete, timesheetID, nil},
+		{"create invoice", app.invoiceCreatePost, projectID, invoiceForm},
+		{"update invoice", app.invoiceUpdatePost, invoiceID, invoiceForm},
+		{"void invoice", app.invoiceVoidPost, invoiceID, invoiceForm},
+		{"delete invoice", app.invoiceDelete, invoiceID, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := post(tt.handler, tt.id, tt.form)
+			assert.Equal(t, http.StatusConflict, rr.Code)
+		})
+	}
+
+	timesheets, err := app.timesheets.GetByProject(projectID)
+	require.NoError(t, err)
+	require.Len(t, timesheets, 1)
+	assert.Equal(t, 2.0, timesheets[0].HoursWorked)
+
+	invoice, err := app.invoices.Get(invoiceID)
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, invoice.AmountDue)
+	assert.False(t, invoice.IsVoided())
+
+	t.Run("reopen allows changes again", func(t *testing.T) {
+		rr := post(app.projectReopenPost, projectID, nil)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		project, err := app.projects.Get(projectID)
+		require.NoError(t, err)
+		assert.False(t, project.IsArchived())
+
+		// The entry is billed on the project's invoice, so deleting it overrides the lock
+		rr = post(app.timesheetDelete, timesheetID, url.Values{"override_lock": {"true"}})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+	})
+}
+
+func TestModelError(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{"missing record", models.ErrNoRecord, http.StatusNotFound, "404 page not found"},
+		{"foreign key", &models.ConstraintError{Kind: models.ErrForeignKeyViolation, Err: errors.New("driver")}, http.StatusConflict, "A related record no longer exists"},
+		{"duplicate", &models.ConstraintError{Kind: models.ErrDuplicate, Field: "invoice_number", Err: errors.New("driver")}, http.StatusConflict, "Invoice number is already in use"},
+		{"validation", &models.ConstraintError{Kind: models.ErrValidation, Field: "name", Err: errors.New("NOT NULL constraint failed: project.name")}, http.StatusUnprocessableEntity, "Name is required"},
+		{"paid invoice", models.ErrInvoicePaid, http.StatusConflict, "This invoice has been paid. Remove the payment to change its date or amount."},
+		{"other", errors.New("disk full"), http.StatusInternalServerError, "Internal Server Error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			app.modelError(rr, httptest.NewRequest(http.MethodPost, "/", nil), fmt.Errorf("saving: %w", tt.err))
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Equal(t, tt.expectedBody, strings.TrimSpace(rr.Body.String()))
+		})
+	}
+}
+
+func TestInboundEmailCapturesPendingTimesheets(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Inbound Client")
+	projectID := testDB.InsertTestProject(t, "Manuscript Review", clientID)
+
+	send := func(key, sender, body string) *httptest.ResponseRecorder {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte(timestamp + "token"))
+
+		form := url.Values{}
+		form.Add("timestamp", timestamp)
+		form.Add("token", "token")
+		form.Add("signature", hex.EncodeToString(mac.Sum(nil)))
+		form.Add("sender", sender)
+		form.Add("subject", "Timesheet")
+		form.Add("stripped-text", body)
+
+		req := httptest.NewRequest(http.MethodPost, "/inbound/email", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		app.inboundEmailPost(rr, req)
+		return rr
+	}
+
+	t.Run("disabled without a signing key", func(t *testing.T) {
+		rr := send("", "me@example.com", "log 2h Manuscript Review: chapter 1")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	require.NoError(t, app.settings.UpdateValue("inbound_email_signing_key", "secret"))
+	require.NoError(t, app.settings.UpdateValue("inbound_email_sender", "me@example.com"))
+
+	t.Run("bad signature is rejected", func(t *testing.T) {
+		rr := send("wrong", "me@example.com", "log 2h Manuscript Review: chapter 1")
+		assert.Equal(t, http.StatusNotAcceptable, rr.Code)
+	})
+
+	t.Run("other senders are rejected", func(t *testing.T) {
+		rr := send("secret", "someone@example.com", "log 2h Manuscript Review: chapter 1")
+		assert.Equal(t, http.StatusNotAcceptable, rr.Code)
+	})
+
+	t.Run("log lines become pending entries", func(t *testing.T) {
+		rr := send("secret", "Me <ME@example.com>", "log 2h manuscript review: chapter 1\nlog 1h Unknown Project: nope\nThanks")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"captured": 1, "skipped": 1}`, rr.Body.String())
+
+		pending, err := app.pendingTimesheets.GetAll()
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		assert.Equal(t, projectID, pending[0].ProjectID)
+		assert.Equal(t, 2.0, pending[0].HoursWorked)
+		assert.Equal(t, "chapter 1", pending[0].Description)
+
+		timesheets, err := app.timesheets.GetByProject(projectID)
+		require.NoError(t, err)
+		assert.Empty(t, timesheets, "nothing is logged until the entry is confirmed")
+	})
+}
+
+func TestPendingTimesheetConfirmAndDiscard(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Inbound Client")
+	projectID := testDB.InsertTestProject(t, "Manuscript Review", clientID)
+	workDate := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	confirmID, err := app.pendingTimesheets.Insert(projectID, workDate, 1.5, "chapter 2", "Timesheet")
+	require.NoError(t, err)
+	discardID, err := app.pendingTimesheets.Insert(projectID, workDate, 3, "duplicate", "Timesheet")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/timesheets/pending", nil)
+	rr := httptest.NewRecorder()
+	app.pendingTimesheetsList(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "chapter 2")
+	assert.Contains(t, rr.Body.String(), "duplicate")
+
+	post := func(handler http.HandlerFunc, id int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	rr = post(app.pendingTimesheetConfirmPost, confirmID)
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	rr = post(app.pendingTimesheetDiscardPost, discardID)
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+	timesheets, err := app.timesheets.GetByProject(projectID)
+	require.NoError(t, err)
+	require.Len(t, timesheets, 1)
+	assert.Equal(t, 1.5, timesheets[0].HoursWorked)
+	assert.Equal(t, 50.0, timesheets[0].HourlyRate)
+	assert.Equal(t, "chapter 2", timesheets[0].Description)
+
+	pending, err := app.pendingTimesheets.GetAll()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	rr = post(app.pendingTimesheetConfirmPost, confirmID)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestSoftWarningsRequireAcknowledgement(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	t.Run("project discount without a reason", func(t *testing.T) {
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+		clientID := testDB.InsertTestClient(t, "Warning Client")
+
+		postProject := func(acknowledge bool) *httptest.ResponseRecorder {
+			form := url.Values{}
+			form.Add("name", "Discounted Project")
+			form.Add("status", "Estimating")
+			form.Add("hourly_rate", "50.00")
+			form.Add("discount_percent", "10")
+			form.Add("adjustment_amount", "-25")
+			form.Add("adjustment_reason", "Rush fee waived")
+			if acknowledge {
+				form.Add("acknowledge_warnings", "true")
+			}
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/client/%d/project/create", clientID), strings.NewReader(form.Encode()))
+			req.SetPathValue("id", strconv.Itoa(clientID))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			app.projectCreatePost(rr, req)
+			return rr
+		}
+
+		rr := postProject(false)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "A discount is set without a reason")
+		assert.NotContains(t, rr.Body.String(), "An adjustment is set without a reason")
+
+		projects, err := app.projects.GetByClient(clientID)
+		require.NoError(t, err)
+		assert.Empty(t, projects)
+
+		rr = postProject(true)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		projects, err = app.projects.GetByClient(clientID)
+		require.NoError(t, err)
+		assert.Len(t, projects, 1)
+	})
+
+	t.Run("invoice paid before it was issued", func(t *testing.T) {
+		testDB.TruncateTable(t, "invoice")
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+		clientID := testDB.InsertTestClient(t, "Warning Client")
+		projectID := testDB.InsertTestProject(t, "Warning Project", clientID)
+
+		postInvoice := func(acknowledge bool) *httptest.ResponseRecorder {
+			form := url.Values{}
+			form.Add("invoice_date", "2024-03-10")
+			form.Add("date_paid", "2024-03-01")
+			form.Add("amount_due", "250.00")
+			if acknowledge {
+				form.Add("acknowledge_warnings", "true")
+			}
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/create/%d", projectID), strings.NewReader(form.Encode()))
+			req.SetPathValue("id", strconv.Itoa(projectID))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			app.invoiceCreatePost(rr, req)
+			return rr
+		}
+
+		rr := postInvoice(false)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Date paid is before the invoice date")
+
+		invoices, err := app.invoices.GetByProject(projectID)
+		require.NoError(t, err)
+		assert.Empty(t, invoices)
+
+		rr = postInvoice(true)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoices, err = app.invoices.GetByProject(projectID)
+		require.NoError(t, err)
+		assert.Len(t, invoices, 1)
+	})
+
+	t.Run("invoice that looks like a duplicate", func(t *testing.T) {
+		testDB.TruncateTable(t, "invoice")
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+		clientID := testDB.InsertTestClient(t, "Warning Client")
+		projectID := testDB.InsertTestProject(t, "Warning Project", clientID)
+		testDB.InsertTestInvoice(t, projectID, "2024-03-08", "", "Net 30", "250.00")
+
+		postInvoice := func(acknowledge bool) *httptest.ResponseRecorder {
+			form := url.Values{}
+			form.Add("invoice_date", "2024-03-10")
+			form.Add("amount_due", "250.00")
+			if acknowledge {
+				form.Add("acknowledge_warnings", "true")
+			}
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/create/%d", projectID), strings.NewReader(form.Encode()))
+			req.SetPathValue("id", strconv.Itoa(projectID))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			app.invoiceCreatePost(rr, req)
+			return rr
+		}
+
+		rr := postInvoice(false)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "An invoice for 250.00 on this project is already dated within 7 days of 2024-03-10")
+
+		rr = postInvoice(true)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoices, err := app.invoices.GetByProject(projectID)
+		require.NoError(t, err)
+		assert.Len(t, invoices, 2)
+	})
+
+	t.Run("invoice outside the client's PO period or fiscal year", func(t *testing.T) {
+		testDB.TruncateTable(t, "invoice")
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+		clientID := testDB.InsertTestClient(t, "University")
+		projectID := testDB.InsertTestProject(t, "Thesis", clientID)
+
+		client, err := app.clients.Get(clientID)
+		require.NoError(t, err)
+		yearEnd := "06-30"
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+		client.FiscalYearEnd = &yearEnd
+		client.POValidFrom = &from
+		client.POValidTo = &to
+		require.NoError(t, app.clients.Update(client))
+
+		postInvoice := func(invoiceDate, timesheetsFrom string) *httptest.ResponseRecorder {
+			form := url.Values{}
+			form.Add("invoice_date", invoiceDate)
+			form.Add("amount_due", "300.00")
+			form.Add("timesheets_from", timesheetsFrom)
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/create/%d", projectID), strings.NewReader(form.Encode()))
+			req.SetPathValue("id", strconv.Itoa(projectID))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			app.invoiceCreatePost(rr, req)
+			return rr
+		}
+
+		rr := postInvoice("2024-07-05", "2024-06-01")
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "The invoice date is outside University&#39;s purchase order period of 2024-01-01 to 2024-06-30")
+		assert.Contains(t, rr.Body.String(), "The work invoiced starts in University&#39;s fiscal year ending 2024-06-30, before the invoice date")
+
+		rr = postInvoice("2024-06-28", "2024-06-01")
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+	})
+
+	t.Run("timesheet over the client's monthly allowance", func(t *testing.T) {
+		testDB.TruncateTable(t, "timesheet")
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+		clientID := testDB.InsertTestClient(t, "Capped Client")
+		projectID := testDB.InsertTestProject(t, "Capped Project", clientID)
+		existingID := testDB.InsertTestTimesheet(t, projectID, "2024-03-05", "8.00", "100.00", "Earlier work")
+
+		client, err := app.clients.Get(clientID)
+		require.NoError(t, err)
+		allowance := 10.0
+		client.MonthlyHourAllowance = &allowance
+		require.NoError(t, app.clients.Update(client))
+
+		postTimesheet := func(hours string, acknowledge bool) *httptest.ResponseRecorder {
+			form := url.Values{}
+			form.Add("work_date", "2024-03-20")
+			form.Add("hours_worked", hours)
+			form.Add("hourly_rate", "100.00")
+			form.Add("description", "More work")
+			if acknowledge {
+				form.Add("acknowledge_warnings", "true")
+			}
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/timesheet/create/%d", projectID), strings.NewReader(form.Encode()))
+			req.SetPathValue("id", strconv.Itoa(projectID))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			app.timesheetCreatePost(rr, req)
+			return rr
+		}
+
+		rr := postTimesheet("3.00", false)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "over the monthly allowance of 10.00 hours")
+
+		rr = postTimesheet("2.00", false)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		rr = postTimesheet("3.00", true)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		timesheets, err := app.timesheets.GetByProject(projectID)
+		require.NoError(t, err)
+		assert.Len(t, timesheets, 3)
+
+		// Editing an entry only counts the change in its hours
+		form := url.Values{}
+		form.Add("work_date", "2024-03-05")
+		form.Add("hours_worked", "7.00")
+		form.Add("hourly_rate", "100.00")
+		form.Add("description", "Earlier work")
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/timesheet/update/%d", existingID), strings.NewReader(form.Encode()))
+		req.SetPathValue("id", strconv.Itoa(existingID))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr = httptest.NewRecorder()
+		app.timesheetUpdatePost(rr, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "to 12.00 hours in March 2024")
+	})
+}
+
+func TestInvoiceClientCredit(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	testDB.TruncateTable(t, "client_credit")
+	testDB.TruncateTable(t, "invoice")
+	testDB.TruncateTable(t, "project")
+	testDB.TruncateTable(t, "client")
+
+	clientID := testDB.InsertTestClient(t, "Credit Client")
+	projectID := testDB.InsertTestProject(t, "Credit Project", clientID)
+
+	createInvoice := func(form url.Values) {
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/create/%d", projectID), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+
+		app.invoiceCreatePost(rr, req)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+	}
+
+	// Paying 130 against 100 due leaves 30 of credit
+	createInvoice(url.Values{
+		"invoice_date": {"2024-03-01"},
+		"date_paid":    {"2024-03-05"},
+		"amount_due":   {"100.00"},
+		"amount_paid":  {"130.00"},
+	})
+
+	balance, err := app.credits.Balance(clientID)
+	require.NoError(t, err)
+	assert.InDelta(t, 30.0, balance, 0.001)
+
+	// The next invoice is paid partly from that credit
+	createInvoice(url.Values{
+		"invoice_date": {"2024-04-01"},
+		"amount_due":   {"80.00"},
+		"apply_credit": {"true"},
+	})
+
+	balance, err = app.credits.Balance(clientID)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.0, balance, 0.001)
+
+	invoices, err := app.invoices.GetByProject(projectID)
+	require.NoError(t, err)
+	require.Len(t, invoices, 2)
+	assert.InDelta(t, 30.0, invoices[0].CreditApplied, 0.001)
+	assert.InDelta(t, 50.0, invoices[0].BalanceDue(), 0.001)
+
+	t.Run("correcting the payment updates the credit", func(t *testing.T) {
+		overpaid := invoices[1]
+
+		form := url.Values{
+			"invoice_date": {"2024-03-01"},
+			"date_paid":    {"2024-03-05"},
+			"amount_due":   {"100.00"},
+			"amount_paid":  {"150.00"},
+		}
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/update/%d", overpaid.ID), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(overpaid.ID))
+		rr := httptest.NewRecorder()
+
+		app.invoiceUpdatePost(rr, req)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		// 50 of overpayment less the 30 already applied to the later invoice
+		balance, err := app.credits.Balance(clientID)
+		require.NoError(t, err)
+		assert.InDelta(t, 20.0, balance, 0.001)
+	})
+
+	t.Run("lowering the amount below the credit applied returns the difference", func(t *testing.T) {
+		later := invoices[0]
+
+		form := url.Values{
+			"invoice_date": {"2024-04-01"},
+			"amount_due":   {"20.00"},
+		}
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/update/%d", later.ID), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(later.ID))
+		rr := httptest.NewRecorder()
+
+		app.invoiceUpdatePost(rr, req)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		updated, err := app.invoices.Get(later.ID)
+		require.NoError(t, err)
+		assert.InDelta(t, 20.0, updated.CreditApplied, 0.001)
+		assert.InDelta(t, 0.0, updated.BalanceDue(), 0.001)
+
+		// 10 of the 30 applied is no longer needed
+		balance, err := app.credits.Balance(clientID)
+		require.NoError(t, err)
+		assert.InDelta(t, 30.0, balance, 0.001)
+	})
+}
+
+func TestInvoiceClientCreditDiscounted(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	testDB.TruncateTable(t, "client_credit")
+	testDB.TruncateTable(t, "invoice")
+	testDB.TruncateTable(t, "project")
+	testDB.TruncateTable(t, "client")
+
+	clientID := testDB.InsertTestClient(t, "Discount Client")
+	projectID := testDB.InsertTestProject(t, "Discount Project", clientID)
+
+	createInvoice := func(form url.Values) models.Invoice {
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/create/%d", projectID), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+
+		app.invoiceCreatePost(rr, req)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoices, err := app.invoices.GetByProject(projectID)
+		require.NoError(t, err)
+		require.NotEmpty(t, invoices)
+		return invoices[0]
+	}
+
+	// Paying 300 against 100 due leaves 200 of credit
+	createInvoice(url.Values{
+		"invoice_date": {"2024-03-01"},
+		"date_paid":    {"2024-03-05"},
+		"amount_due":   {"100.00"},
+		"amount_paid":  {"300.00"},
+	})
+
+	t.Run("credit applied stops at the discounted total", func(t *testing.T) {
+		invoice := createInvoice(url.Values{
+			"invoice_date":     {"2024-04-01"},
+			"amount_due":       {"100.00"},
+			"discount_percent": {"10"},
+			"discount_reason":  {"Loyalty"},
+			"apply_credit":     {"true"},
+		})
+		assert.InDelta(t, 90.0, invoice.BilledTotal(), 0.001)
+		assert.InDelta(t, 90.0, invoice.CreditApplied, 0.001)
+		assert.InDelta(t, 0.0, invoice.BalanceDue(), 0.001)
+
+		balance, err := app.credits.Balance(clientID)
+		require.NoError(t, err)
+		assert.InDelta(t, 110.0, balance, 0.001)
+	})
+
+	t.Run("paying the discounted total settles the invoice exactly", func(t *testing.T) {
+		invoice := createInvoice(url.Values{
+			"invoice_date":     {"2024-05-01"},
+			"date_paid":        {"2024-05-10"},
+			"amount_due":       {"200.00"},
+			"amount_paid":      {"180.00"},
+			"discount_percent": {"10"},
+			"discount_reason":  {"Loyalty"},
+		})
+		assert.InDelta(t, 0.0, invoice.BalanceDue()-invoice.TotalPaid(), 0.001)
+		assert.Zero(t, invoice.Overpayment())
+
+		balance, err := app.credits.Balance(clientID)
+		require.NoError(t, err)
+		assert.InDelta(t, 110.0, balance, 0.001)
+	})
+}
+
+func TestInvoiceClientCreditReleased(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	// setup overpays one invoice by 30 and spends that credit on a second one
+	setup := func(t *testing.T) (int, int, int) {
+		testDB.TruncateTable(t, "client_credit")
+		testDB.TruncateTable(t, "invoice")
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+
+		clientID := testDB.InsertTestClient(t, "Credit Client")
+		projectID := testDB.InsertTestProject(t, "Credit Project", clientID)
+
+		for _, form := range []url.Values{
+			{"invoice_date": {"2024-03-01"}, "date_paid": {"2024-03-05"}, "amount_due": {"100.00"}, "amount_paid": {"130.00"}},
+			{"invoice_date": {"2024-04-01"}, "amount_due": {"80.00"}, "apply_credit": {"true"}},
+		} {
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/create/%d", projectID), strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetPathValue("id", strconv.Itoa(projectID))
+			rr := httptest.NewRecorder()
+			app.invoiceCreatePost(rr, req)
+			require.Equal(t, http.StatusSeeOther, rr.Code)
+		}
+
+		invoices, err := app.invoices.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, invoices, 2)
+
+		balance, err := app.credits.Balance(clientID)
+		require.NoError(t, err)
+		require.InDelta(t, 0.0, balance, 0.001)
+
+		return clientID, invoices[1].ID, invoices[0].ID
+	}
+
+	deleteInvoice := func(invoiceID int) int {
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/delete/%d", invoiceID), nil)
+		req.SetPathValue("id", strconv.Itoa(invoiceID))
+		rr := httptest.NewRecorder()
+		app.invoiceDelete(rr, req)
+		return rr.Code
+	}
+
+	voidInvoice := func(invoiceID int) int {
+		form := url.Values{"void_reason": {"Sent in error"}}
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/void/%d", invoiceID), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(invoiceID))
+		rr := httptest.NewRecorder()
+		app.invoiceVoidPost(rr, req)
+		return rr.Code
+	}
+
+	for name, release := range map[string]func(int) int{"delete": deleteInvoice, "void": voidInvoice} {
+		t.Run(name, func(t *testing.T) {
+			clientID, overpaidID, appliedID := setup(t)
+
+			// The overpayment can't be withdrawn while its credit is spent on the second invoice
+			assert.Equal(t, http.StatusConflict, release(overpaidID))
+			invoice, err := app.invoices.Get(overpaidID)
+			require.NoError(t, err)
+			assert.False(t, invoice.IsVoided())
+			balance, err := app.credits.Balance(clientID)
+			require.NoError(t, err)
+			assert.InDelta(t, 0.0, balance, 0.001)
+
+			// The credit spent on the second invoice goes back to the client
+			require.Equal(t, http.StatusSeeOther, release(appliedID))
+			balance, err = app.credits.Balance(clientID)
+			require.NoError(t, err)
+			assert.InDelta(t, 30.0, balance, 0.001)
+
+			// after which the overpayment that earned it can be withdrawn
+			require.Equal(t, http.StatusSeeOther, release(overpaidID))
+			balance, err = app.credits.Balance(clientID)
+			require.NoError(t, err)
+			assert.InDelta(t, 0.0, balance, 0.001)
+		})
+	}
+}
+
+func TestInvoiceUpdatePostPaidInvoice(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	projectID := testDB.InsertTestProject(t, "Paid Project", testDB.InsertTestClient(t, "Paid Client"))
+	id := testDB.InsertTestInvoice(t, projectID, "2024-03-01", "2024-03-10", "Net 30", "500.00")
+
+	updateInvoice := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/update/%d", id), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		app.invoiceUpdatePost(rr, req)
+		return rr
+	}
+
+	t.Run("amount and date can't change while paid", func(t *testing.T) {
+		rr := updateInvoice(url.Values{"invoice_date": {"2024-03-01"}, "date_paid": {"2024-03-10"}, "amount_due": {"450.00"}})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "This invoice has been paid, so its amount can&#39;t be changed")
+
+		rr = updateInvoice(url.Values{"invoice_date": {"2024-02-28"}, "date_paid": {"2024-03-10"}, "amount_due": {"500.00"}})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "This invoice has been paid, so its date can&#39;t be changed")
+
+		invoice, err := app.invoices.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, 500.0, invoice.AmountDue)
+		assert.Equal(t, "2024-03-01", invoice.InvoiceDate.Format("2006-01-02"))
+	})
+
+	t.Run("other details can still change", func(t *testing.T) {
+		rr := updateInvoice(url.Values{"invoice_date": {"2024-03-01"}, "date_paid": {"2024-03-12"}, "amount_due": {"500.00"}, "payment_terms": {"Net 15"}})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoice, err := app.invoices.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, "Net 15", invoice.PaymentTerms)
+		assert.Equal(t, "2024-03-12", invoice.DatePaid.Format("2006-01-02"))
+	})
+
+	t.Run("removing the payment allows the change", func(t *testing.T) {
+		rr := updateInvoice(url.Values{"invoice_date": {"2024-03-01"}, "amount_due": {"450.00"}})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoice, err := app.invoices.Get(id)
+		require.NoError(t, err)
+		assert.Nil(t, invoice.DatePaid)
+		assert.Equal(t, 450.0, invoice.AmountDue)
+	})
+}
+
+func TestSettingsEditPostOptionalSettings(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	settings, err := app.settings.GetAllDetailed()
+	require.NoError(t, err)
+
+	form := url.Values{}
+	for _, setting := range settings {
+		form.Add(setting.Key, setting.Value)
+	}
+	form.Set("payment_iban", "DE89370400440532013000")
+	form.Set("payment_bank_name", "")
+
+	req := httptest.NewRequest(http.MethodPost, "/settings/edit", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+
+	app.settingsEditPost(rr, req)
+
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+	iban, err := app.settings.GetString("payment_iban")
+	require.NoError(t, err)
+	assert.Equal(t, "DE89370400440532013000", iban)
+
+	bankName, err := app.settings.GetString("payment_bank_name")
+	require.NoError(t, err)
+	assert.Empty(t, bankName)
+}
+
+func TestSettingsEditPostValidatesRules(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	settings, err := app.settings.GetAllDetailed()
+	require.NoError(t, err)
+
+	post := func(changes map[string]string) *httptest.ResponseRecorder {
+		form := url.Values{}
+		for _, setting := range settings {
+			form.Add(setting.Key, setting.Value)
+		}
+		for key, value := range changes {
+			if value == "" {
+				form.Del(key)
+			} else {
+				form.Set(key, value)
+			}
+		}
+		req := httptest.NewRequest(http.MethodPost, "/settings/edit", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		app.settingsEditPost(rr, req)
+		return rr
+	}
+
+	t.Run("values outside their rule are rejected", func(t *testing.T) {
+		rr := post(map[string]string{
+			"invoice_max_pdf_pages":     "0",
+			"payment_domestic_currency": "dollars",
+			"date_format":               "DD/MM/YY",
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		body := rr.Body.String()
+		assert.Contains(t, body, "invoice_max_pdf_pages: Must be at least 1")
+		assert.Contains(t, body, "payment_domestic_currency: Must be a three letter currency code")
+		assert.Contains(t, body, "date_format: Must be one of")
+
+		pages, err := app.settings.GetInt("invoice_max_pdf_pages")
+		require.NoError(t, err)
+		assert.Equal(t, 10, pages)
+	})
+
+	t.Run("unticked checkbox saves false", func(t *testing.T) {
+		_, err := testDB.DB.Exec(`INSERT INTO settings (key, value, data_type, description) VALUES ('invoice_show_individual_timesheets', 'true', 'bool', '')`)
+		require.NoError(t, err)
+		settings, err = app.settings.GetAllDetailed()
+		require.NoError(t, err)
+
+		rr := post(map[string]string{"invoice_show_individual_timesheets": ""})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		show, err := app.settings.GetBool("invoice_show_individual_timesheets")
+		require.NoError(t, err)
+		assert.False(t, show)
+	})
+}
+
+func TestSettingsSecrets(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+	cache, err := newTemplateCache("")
+	require.NoError(t, err)
+	app.templateCache = cache
+
+	require.NoError(t, app.settings.UpdateValue("contacts_carddav_password", "hunter2"))
+	require.NoError(t, app.settings.UpdateValue("contacts_google_access_token", "ya29.token"))
+
+	get := func(handler http.HandlerFunc) string {
+		rr := httptest.NewRecorder()
+		handler(rr, httptest.NewRequest(http.MethodGet, "/settings", nil))
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr.Body.String()
+	}
+	post := func(changes url.Values) {
+		settings, err := app.settings.GetAllDetailed()
+		require.NoError(t, err)
+		form := url.Values{}
+		for _, setting := range settings {
+			if !setting.Rule().Secret {
+				form.Add(setting.Key, setting.Value)
+			}
+		}
+		for key, values := range changes {
+			form[key] = values
+		}
+		req := httptest.NewRequest(http.MethodPost, "/settings/edit", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		app.settingsEditPost(rr, req)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+	}
+	setting := func(key string) string {
+		value, err := app.settings.GetString(key)
+		require.NoError(t, err)
+		return value
+	}
+
+	t.Run("are never shown", func(t *testing.T) {
+		for _, body := range []string{get(app.settingsView), get(app.settingsEdit)} {
+			assert.NotContains(t, body, "hunter2")
+			assert.NotContains(t, body, "ya29.token")
+		}
+		assert.Contains(t, get(app.settingsEdit), `type="password" id="contacts_carddav_password"`)
+	})
+
+	t.Run("are kept when left blank", func(t *testing.T) {
+		post(url.Values{})
+		assert.Equal(t, "hunter2", setting("contacts_carddav_password"))
+		assert.Equal(t, "ya29.token", setting("contacts_google_access_token"))
+	})
+
+	t.Run("can be replaced or cleared", func(t *testing.T) {
+		post(url.Values{
+			"contacts_google_access_token":    {"ya29.fresh"},
+			"contacts_carddav_password_clear": {"true"},
+		})
+		assert.Equal(t, "ya29.fresh", setting("contacts_google_access_token"))
+		assert.Empty(t, setting("contacts_carddav_password"))
+	})
+}
+
+func TestSettingsLogoUpload(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+	app.uploads = storage.Local{Dir: t.TempDir()}
+
+	pngOfSize := func(width, height int) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+		return buf.Bytes()
+	}
+
+	upload := func(filename string, data []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile("logo", filename)
+		require.NoError(t, err)
+		_, err = part.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/settings/logo", &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		rr := httptest.NewRecorder()
+		app.settingsLogoPost(rr, req)
+		return rr
+	}
+
+	rejected := []struct {
+		name     string
+		filename string
+		data     []byte
+		message  string
+	}{
+		{"not an image", "logo.png", []byte("plain text"), "Logo must be a PNG, JPEG or SVG image"},
+		{"too wide", "logo.png", pngOfSize(2400, 100), "this one is 2400x100"},
+		{"too small", "logo.png", pngOfSize(8, 8), "this one is 8x8"},
+		{"svg that isn't", "logo.svg", []byte("<html></html>"), "Logo is not a valid SVG image"},
+		{"too large", "logo.png", make([]byte, maxLogoBytes+1), "Logo must be smaller than 1024 KB"},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := upload(tt.filename, tt.data)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.message)
+		})
+	}
+
+	logoPath, err := app.settings.GetString("company_logo_path")
+	require.NoError(t, err)
+	assert.Equal(t, "./ui/static/img/logo.png", logoPath)
+
+	t.Run("png is stored and previewed", func(t *testing.T) {
+		data := pngOfSize(200, 80)
+		rr := upload("Company Logo.PNG", data)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		logoPath, err := app.settings.GetString("company_logo_path")
+		require.NoError(t, err)
+		assert.Equal(t, app.uploads.Dir, filepath.Dir(logoPath))
+		assert.Equal(t, ".png", filepath.Ext(logoPath))
+
+		req := httptest.NewRequest(http.MethodGet, "/settings/logo", nil)
+		rr = httptest.NewRecorder()
+		app.settingsLogo(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+		assert.Equal(t, data, rr.Body.Bytes())
+	})
+
+	t.Run("svg is stored", func(t *testing.T) {
+		rr := upload("logo.svg", []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg" width="10" height="10"></svg>`))
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		logoPath, err := app.settings.GetString("company_logo_path")
+		require.NoError(t, err)
+		assert.Equal(t, ".svg", filepath.Ext(logoPath))
+	})
+}
+
+func TestDateFormatSetting(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Date Client")
+	projectID := testDB.InsertTestProject(t, "Date Project", clientID)
+	require.NoError(t, app.settings.UpdateValue("date_format", string(models.DateFormatEuropean)))
+
+	postTimesheet := func(workDate string) *httptest.ResponseRecorder {
+		form := url.Values{}
+		form.Add("work_date", workDate)
+		form.Add("hours_worked", "2.00")
+		form.Add("hourly_rate", "100.00")
+		form.Add("description", "Dated work")
+
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/project/%d/timesheet/create", projectID), strings.NewReader(form.Encode()))
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		app.timesheetCreatePost(rr, req)
+		return rr
+	}
+
+	rr := postTimesheet("03/15/2024")
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Work date must be in DD.MM.YYYY format")
+
+	rr = postTimesheet("15.03.2024")
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+	timesheets, err := app.timesheets.GetByProject(projectID)
+	require.NoError(t, err)
+	require.Len(t, timesheets, 1)
+	assert.Equal(t, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), timesheets[0].WorkDate.UTC())
+
+	// Editing shows the date back in the configured format
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/timesheet/update/%d", timesheets[0].ID), nil)
+	req.SetPathValue("id", strconv.Itoa(timesheets[0].ID))
+	rr = httptest.NewRecorder()
+	app.timesheetUpdate(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `value="15.03.2024"`)
+
+	t.Run("setting only accepts supported formats", func(t *testing.T) {
+		settings, err := app.settings.GetAllDetailed()
+		require.NoError(t, err)
+
+		form := url.Values{}
+		for _, setting := range settings {
+			form.Add(setting.Key, setting.Value)
+		}
+		form.Set("date_format", "DD/MM/YY")
+
+		req := httptest.NewRequest(http.MethodPost, "/settings/edit", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		app.settingsEditPost(rr, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+		value, err := app.settings.GetString("date_format")
+		require.NoError(t, err)
+		assert.Equal(t, string(models.DateFormatEuropean), value)
+	})
+}
+
+func TestEmbeddedAssets(t *testing.T) {
+	t.Run("templates parse from outside the project root", func(t *testing.T) {
+		cache, err := newTemplateCache("/freelance")
+		require.NoError(t, err)
+		assert.Contains(t, cache, "home.html")
+		assert.Contains(t, cache, "invoice_create.html")
+	})
+
+	t.Run("static files are served", func(t *testing.T) {
+		app, testDB := createTestApp(t)
+		defer testDB.Cleanup(t)
+		app.sessionManager = scs.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/static/css/main.css", nil)
+		rr := httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Header().Get("Content-Type"), "text/css")
+	})
+}
+
+func TestJobsPage(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+	cache, err := newTemplateCache("")
+	require.NoError(t, err)
+	app.templateCache = cache
+
+	// On the first of the month the consolidated invoices are queued for the month just
+	// ended; reminders are switched off by a blank schedule
+	require.NoError(t, app.settings.UpdateValue("schedule_reminders", ""))
+	firstOfMonth := time.Date(time.Now().Year(), time.Now().Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, 1, app.scheduler.Check(firstOfMonth))
+	assert.Equal(t, consolidatedInvoicesJobPayload{Month: firstOfMonth.AddDate(0, -1, 0).Format("2006-01")}, consolidatedInvoicesPayload(firstOfMonth))
+	failedID, err := app.jobs.Insert(models.JobTimesheetReport, "{}")
+	require.NoError(t, err)
+	require.NoError(t, app.jobs.Fail(failedID, "Chrome crashed"))
+
+	req := httptest.NewRequest(http.MethodGet, "/jobs", nil)
+	rr := httptest.NewRecorder()
+	app.jobList(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	body := rr.Body.String()
+	assert.Contains(t, body, "<td>Consolidated invoices</td>")
+	assert.Contains(t, body, "<code>0 0 1 * *</code>")
+	assert.Contains(t, body, humanDate(firstOfMonth))
+	assert.Contains(t, body, humanDate(firstOfMonth.AddDate(0, 1, 0)), "the next run follows the last")
+	assert.Contains(t, body, "<td>Off</td>")
+	assert.Contains(t, body, "Timesheet report</a>")
+	assert.Contains(t, body, "failed: Chrome crashed")
+}
+
+func TestFormErrorSummary(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+	cache, err := newTemplateCache("")
+	require.NoError(t, err)
+	app.templateCache = cache
+
+	form := url.Values{}
+	form.Add("email", "not-an-email")
+	form.Add("hourly_rate", "50.00")
+	req := httptest.NewRequest(http.MethodPost, "/client/create", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	app.clientCreatePost(rr, req)
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	body := rr.Body.String()
+	assert.Contains(t, body, `<div class="error-summary" role="alert"`)
+	nameLink := strings.Index(body, `<a href="#name">`)
+	emailLink := strings.Index(body, `<a href="#email">`)
+	require.Positive(t, nameLink)
+	assert.Greater(t, emailLink, nameLink, "errors are listed in the order of the form's fields")
+
+	assert.Contains(t, body, `<label class="error" id="name-error">`)
+	assert.Contains(t, body, `id='name' aria-invalid="true" aria-describedby="name-error"`)
+	assert.Contains(t, body, `id='phone'  class="form-input"`, "valid fields get no aria attributes")
+
+	t.Run("pages without errors have no summary", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/client/create", nil)
+		rr := httptest.NewRecorder()
+		app.clientCreate(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "error-summary\"")
+	})
+
+	t.Run("fields follow the form's tags", func(t *testing.T) {
+		fields := formFields(clientForm{})
+		require.NotEmpty(t, fields)
+		assert.Equal(t, "name", fields[0])
+		assert.NotContains(t, fields, "-")
+		assert.Nil(t, formFields("not a form"))
+	})
+}
+
+func TestFlashMessages(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+	app.sessionManager = scs.New()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /client/create", app.clientCreatePost)
+	mux.HandleFunc("GET /client/view/{id}", app.clientView)
+	handler := app.sessionManager.LoadAndSave(mux)
+
+	form := url.Values{}
+	form.Add("name", "Flash Client")
+	form.Add("email", "flash@example.com")
+	form.Add("hourly_rate", "50.00")
+	req := httptest.NewRequest(http.MethodPost, "/client/create", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+	cookies := rr.Result().Cookies()
+	require.NotEmpty(t, cookies)
+
+	view := func() string {
+		req := httptest.NewRequest(http.MethodGet, rr.Header().Get("Location"), nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		require.Equal(t, http.StatusOK, res.Code)
+		return res.Body.String()
+	}
+
+	assert.Contains(t, view(), `<div class="flash">Client Flash Client created</div>`)
+
+	// The message is only shown once
+	assert.NotContains(t, view(), `class="flash"`)
+}
+
+func TestDeleteCSRFProtection(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+	app.sessionManager = scs.New()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /token/{id}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, app.newTemplateData(r).CSRFToken("/client/delete/", r.PathValue("id")))
+	})
+	mux.Handle("POST /client/delete/{id}", app.verifyCSRF(http.HandlerFunc(app.clientDelete)))
+	handler := app.sessionManager.LoadAndSave(mux)
+
+	// Loading a page gives the session its key and the form its token
+	req := httptest.NewRequest(http.MethodGet, "/token/1", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	cookies := rr.Result().Cookies()
+	require.NotEmpty(t, cookies)
+	token := rr.Body.String()
+
+	deleteClient := func(id int, token string, withSession bool) int {
+		form := url.Values{}
+		form.Add("csrf_token", token)
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/client/delete/%d", id), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if withSession {
+			for _, cookie := range cookies {
+				req.AddCookie(cookie)
+			}
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	testDB.TruncateTable(t, "client")
+	clientID := testDB.InsertTestClient(t, "Kept Client")
+	otherID := testDB.InsertTestClient(t, "Deleted Client")
+	require.Equal(t, 1, clientID)
+
+	t.Run("missing token", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, deleteClient(clientID, "", true))
+	})
+
+	t.Run("token for another record", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, deleteClient(otherID, token, true))
+	})
+
+	t.Run("token from another session", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, deleteClient(clientID, token, false))
+	})
+
+	t.Run("nothing was deleted", func(t *testing.T) {
+		_, err := app.clients.Get(clientID)
+		assert.NoError(t, err)
+		_, err = app.clients.Get(otherID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		assert.Equal(t, http.StatusSeeOther, deleteClient(clientID, token, true))
+		_, err := app.clients.Get(clientID)
+		assert.ErrorIs(t, err, models.ErrNoRecord)
+	})
+
+	t.Run("routes check the token on every delete", func(t *testing.T) {
+		for _, path := range []string{
+			fmt.Sprintf("/client/delete/%d", otherID),
+			"/project/1/unshare/2",
+			"/timesheet/pending/discard/1",
+		} {
+			req := httptest.NewRequest(http.MethodPost, path, nil)
+			rr := httptest.NewRecorder()
+			app.routes().ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusForbidden, rr.Code, path)
+		}
+	})
+}
+
+func TestInvoiceVoidHandlers(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	setup := func(t *testing.T) (int, int) {
+		testDB.TruncateTable(t, "invoice")
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+
+		clientID := testDB.InsertTestClient(t, "Void Client")
+		projectID := testDB.InsertTestProject(t, "Void Project", clientID)
+		invoiceID := testDB.InsertTestInvoice(t, projectID, "2024-01-15", "", "Net 30", "500.00")
+		return projectID, invoiceID
+	}
+
+	voidInvoice := func(invoiceID int, reason string) *httptest.ResponseRecorder {
+		form := url.Values{}
+		form.Add("void_reason", reason)
+
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/void/%d", invoiceID), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(invoiceID))
+		rr := httptest.NewRecorder()
+
+		app.invoiceVoidPost(rr, req)
+		return rr
+	}
+
+	t.Run("successful void", func(t *testing.T) {
+		projectID, invoiceID := setup(t)
+
+		rr := voidInvoice(invoiceID, "Sent to the wrong client")
+
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, fmt.Sprintf("/project/view/%d", projectID), rr.Header().Get("Location"))
+
+		invoice, err := app.invoices.Get(invoiceID)
+		require.NoError(t, err)
+		assert.True(t, invoice.IsVoided())
+		assert.Equal(t, "Sent to the wrong client", invoice.VoidReason)
+	})
+
+	t.Run("reason is required", func(t *testing.T) {
+		_, invoiceID := setup(t)
+
+		rr := voidInvoice(invoiceID, "   ")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "A reason is required to void an invoice")
+
+		invoice, err := app.invoices.Get(invoiceID)
+		require.NoError(t, err)
+		assert.False(t, invoice.IsVoided())
+	})
+
+	t.Run("voiding twice is a conflict", func(t *testing.T) {
+		_, invoiceID := setup(t)
+		require.NoError(t, app.invoices.Void(invoiceID, "Duplicate"))
+
+		rr := voidInvoice(invoiceID, "Again")
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("voided invoice cannot be edited or deleted", func(t *testing.T) {
+		_, invoiceID := setup(t)
+		require.NoError(t, app.invoices.Void(invoiceID, "Duplicate"))
+
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/invoice/update/%d", invoiceID), nil)
+		req.SetPathValue("id", strconv.Itoa(invoiceID))
+		rr := httptest.NewRecorder()
+		app.invoiceUpdate(rr, req)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/invoice/delete/%d", invoiceID), nil)
+		req.SetPathValue("id", strconv.Itoa(invoiceID))
+		rr = httptest.NewRecorder()
+		app.invoiceDelete(rr, req)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		_, err := app.invoices.Get(invoiceID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("void non-existent invoice", func(t *testing.T) {
+		testDB.TruncateTable(t, "invoice")
+
+		rr := voidInvoice(999, "Reason")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestClientsSyncHandlers(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	csvData := "Name,E-mail 1 - Value,Phone 1 - Value,Address 1 - City\n" +
+		"Acme Billing,billing@acme.edu,555-9999,Springfield\n" +
+		"New Person,new@example.com,,\n"
+
+	csvRequest := func(t *testing.T) *http.Request {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		require.NoError(t, writer.WriteField("source", "csv"))
+		part, err := writer.CreateFormFile("contacts_file", "contacts.csv")
+		require.NoError(t, err)
+		_, err = part.Write([]byte(csvData))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/clients/sync/preview", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req
+	}
+
+	t.Run("preview from csv upload", func(t *testing.T) {
+		testDB.TruncateTable(t, "client")
+		_, err := testDB.DB.Exec("INSERT INTO client (name, email, phone, hourly_rate) VALUES (?, ?, ?, ?)", "Acme University", "billing@acme.edu", "555-0000", 50.0)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		app.clientsSyncPreview(rr, csvRequest(t))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		body := rr.Body.String()
+		assert.Contains(t, body, "change Acme University Phone=555-9999 City=Springfield")
+		assert.Contains(t, body, "new new@example.com")
+
+		// Nothing is saved until the changes are applied
+		clients, err := app.clients.GetAll()
+		require.NoError(t, err)
+		require.Len(t, clients, 1)
+		assert.Equal(t, "555-0000", *clients[0].Phone)
+	})
+
+	t.Run("preview from carddav", func(t *testing.T) {
+		testDB.TruncateTable(t, "client")
+		testDB.InsertTestClient(t, "CardDAV Client")
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusMultiStatus)
+			w.Write([]byte(`<d:multistatus xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav"><d:response><d:propstat><d:prop>` +
+				`<card:address-data>BEGIN:VCARD&#13;
+FN:Test&#13;
+EMAIL:test@example.com&#13;
+TEL:555-0123&#13;
+END:VCARD&#13;
+</card:address-data></d:prop></d:propstat></d:response></d:multistatus>`))
+		}))
+		defer server.Close()
+		require.NoError(t, app.settings.UpdateValue("contacts_carddav_url", server.URL))
+		defer app.settings.UpdateValue("contacts_carddav_url", "")
+
+		form := url.Values{}
+		form.Add("source", "carddav")
+		req := httptest.NewRequest(http.MethodPost, "/clients/sync/preview", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		app.clientsSyncPreview(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "change CardDAV Client Phone=555-0123")
+	})
+
+	t.Run("source not configured", func(t *testing.T) {
+		form := url.Values{}
+		form.Add("source", "google")
+		req := httptest.NewRequest(http.MethodPost, "/clients/sync/preview", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		app.clientsSyncPreview(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Google Contacts access token")
+	})
+
+	t.Run("apply selected changes", func(t *testing.T) {
+		testDB.TruncateTable(t, "client")
+		_, err := testDB.DB.Exec("INSERT INTO client (name, email, phone, hourly_rate) VALUES (?, ?, ?, ?)", "Acme University", "billing@acme.edu", "555-0000", 50.0)
+		require.NoError(t, err)
+
+		form := url.Values{}
+		form.Add("changes[0].apply", "true")
+		form.Add("changes[0].email", "billing@acme.edu")
+		form.Add("changes[0].phone", "555-9999")
+		form.Add("changes[0].city", "Springfield")
+		form.Add("new[0].apply", "true")
+		form.Add("new[0].name", "New Person")
+		form.Add("new[0].email", "new@example.com")
+		form.Add("new[1].name", "Skipped Person")
+		form.Add("new[1].email", "skipped@example.com")
+
+		req := httptest.NewRequest(http.MethodPost, "/clients/sync/apply", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		app.clientsSyncApply(rr, req)
+
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		clients, err := app.clients.GetAll()
+		require.NoError(t, err)
+		require.Len(t, clients, 2)
+
+		byEmail := map[string]models.Client{}
+		for _, client := range clients {
+			byEmail[client.Email] = client
+		}
+		assert.Equal(t, "Acme University", byEmail["billing@acme.edu"].Name)
+		assert.Equal(t, "555-9999", *byEmail["billing@acme.edu"].Phone)
+		assert.Equal(t, "Springfield", *byEmail["billing@acme.edu"].City)
+		assert.Equal(t, "New Person", byEmail["new@example.com"].Name)
+		assert.NotContains(t, byEmail, "skipped@example.com")
+	})
+}
+
+func TestCSVImportPost(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	csvData := "Date,Client,Project,Task,Notes,Hours,Billable Rate\n" +
+		"2024-03-04,Acme Corp,Website,Design,Mockups,2.5,90\n" +
+		"2024-03-05,Acme Corp,Website,Design,,soon,90\n"
+
+	importRequest := func(t *testing.T, fields map[string]string, withFile bool) *http.Request {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		for name, value := range fields {
+			require.NoError(t, writer.WriteField(name, value))
+		}
+		if withFile {
+			part, err := writer.CreateFormFile("import_file", "time.csv")
+			require.NoError(t, err)
+			_, err = part.Write([]byte(csvData))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/import", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req
+	}
+
+	t.Run("preview shows what would be created without saving it", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.csvImportPost(rr, importRequest(t, map[string]string{"tool": "harvest", "kind": "time", "preview": "true"}, true))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "clients=1 projects=1 timesheets=1 skipped=0")
+		assert.Contains(t, rr.Body.String(), `Row 3: hours &#34;soon&#34; not recognised`)
+
+		clients, err := app.clients.GetAll()
+		require.NoError(t, err)
+		assert.Empty(t, clients)
+	})
+
+	t.Run("import saves the rows", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.csvImportPost(rr, importRequest(t, map[string]string{"tool": "harvest", "kind": "time"}, true))
+
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		clients, err := app.clients.GetAll()
+		require.NoError(t, err)
+		require.Len(t, clients, 1)
+		assert.Equal(t, "Acme Corp", clients[0].Name)
+		projects, err := app.projects.GetByClient(clients[0].ID)
+		require.NoError(t, err)
+		require.Len(t, projects, 1)
+		timesheets, err := app.timesheets.GetByProject(projects[0].ID)
+		require.NoError(t, err)
+		require.Len(t, timesheets, 1)
+		assert.Equal(t, "Design: Mockups", timesheets[0].Description)
+	})
+
+	t.Run("requires a known tool and a file", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.csvImportPost(rr, importRequest(t, map[string]string{"tool": "toggl", "kind": "time"}, true))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Choose the tool the export came from")
+
+		rr = httptest.NewRecorder()
+		app.csvImportPost(rr, importRequest(t, map[string]string{"tool": "harvest", "kind": "invoices"}, true))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "no issue date column found")
+
+		rr = httptest.NewRecorder()
+		app.csvImportPost(rr, importRequest(t, map[string]string{"tool": "harvest", "kind": "time"}, false))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Choose a CSV file to upload")
+	})
+}
+
+func TestBankReconciliation(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Acme Corp")
+	projectID := testDB.InsertTestProject(t, "Website", clientID)
+	acmeID := testDB.InsertTestInvoice(t, projectID, "2024-04-01", "", "Net 30", "1250.00")
+	otherID := testDB.InsertTestInvoice(t, projectID, "2024-04-15", "", "Net 30", "600.00")
+
+	statement := "Date,Description,Amount\n" +
+		fmt.Sprintf("2024-05-02,ACME CORP %04d,1250.00\n", acmeID) +
+		"2024-05-03,Interest,0.42\n" +
+		"2024-05-04,Refund,lots\n"
+
+	uploadRequest := func(t *testing.T, contents string) *http.Request {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		if contents != "" {
+			part, err := writer.CreateFormFile("statement_file", "statement.csv")
+			require.NoError(t, err)
+			_, err = part.Write([]byte(contents))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/invoices/reconcile", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req
+	}
+	confirmRequest := func(payments ...string) *http.Request {
+		form := url.Values{"payment": payments}
+		req := httptest.NewRequest(http.MethodPost, "/invoices/reconcile/confirm", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	t.Run("upload proposes payments without recording them", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.reconcilePost(rr, uploadRequest(t, statement))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), fmt.Sprintf("row=2 invoice=%d confident=true", acmeID))
+		assert.Contains(t, rr.Body.String(), "row=3 invoice= confident=false")
+		assert.Contains(t, rr.Body.String(), "Row 4: amount &#34;lots&#34; not recognised")
+
+		invoice, err := app.invoices.Get(acmeID)
+		require.NoError(t, err)
+		assert.Nil(t, invoice.DatePaid)
+	})
+
+	t.Run("requires a bank statement", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.reconcilePost(rr, uploadRequest(t, ""))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Choose a CSV file to upload")
+
+		rr = httptest.NewRecorder()
+		app.reconcilePost(rr, uploadRequest(t, "Name,Email\nAnn,ann@example.test\n"))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "no date column found")
+	})
+
+	t.Run("rejects malformed payments", func(t *testing.T) {
+		for _, payment := range []string{"nonsense", fmt.Sprintf("%d|someday|10.00", acmeID), fmt.Sprintf("%d|2024-05-02|-5", acmeID)} {
+			rr := httptest.NewRecorder()
+			app.reconcileConfirmPost(rr, confirmRequest(payment))
+			assert.Equal(t, http.StatusBadRequest, rr.Code, payment)
+		}
+
+		rr := httptest.NewRecorder()
+		payment := fmt.Sprintf("%d|2024-05-02|1250.00", acmeID)
+		app.reconcileConfirmPost(rr, confirmRequest(payment, payment))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "an invoice can only be paid once")
+	})
+
+	t.Run("confirming records the ticked payments together", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.reconcileConfirmPost(rr, confirmRequest(
+			fmt.Sprintf("%d|2024-05-02|1250.00", acmeID),
+			fmt.Sprintf("%d|2024-05-03|600.00", otherID),
+		))
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoice, err := app.invoices.Get(acmeID)
+		require.NoError(t, err)
+		require.NotNil(t, invoice.DatePaid)
+		assert.Equal(t, "2024-05-02", invoice.DatePaid.Format("2006-01-02"))
+		assert.Equal(t, 1250.0, invoice.TotalPaid())
+
+		invoice, err = app.invoices.Get(otherID)
+		require.NoError(t, err)
+		require.NotNil(t, invoice.DatePaid)
+		assert.Equal(t, 600.0, invoice.TotalPaid())
+	})
+
+	t.Run("nothing is recorded when an invoice is already paid", func(t *testing.T) {
+		openID := testDB.InsertTestInvoice(t, projectID, "2024-04-20", "", "Net 30", "300.00")
+
+		rr := httptest.NewRecorder()
+		app.reconcileConfirmPost(rr, confirmRequest(
+			fmt.Sprintf("%d|2024-05-05|300.00", openID),
+			fmt.Sprintf("%d|2024-05-05|1250.00", acmeID),
+		))
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoice, err := app.invoices.Get(openID)
+		require.NoError(t, err)
+		assert.Nil(t, invoice.DatePaid)
+	})
+}
+
+func TestSubcontractorAccess(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Private Client")
+	sharedID := testDB.InsertTestProject(t, "Shared Edit", clientID)
+	privateID := testDB.InsertTestProject(t, "Private Work", clientID)
+	ownerEntryID := testDB.InsertTestTimesheet(t, sharedID, "2024-01-10", "2.00", "150.00", "Owner entry")
+
+	editorID, err := app.users.Insert(models.User{Name: "Ed Itor", Email: "editor@example.com", Role: models.RoleSubcontractor}, "correct horse")
+	require.NoError(t, err)
+	editor, err := app.users.Get(editorID)
+	require.NoError(t, err)
+
+	asEditor := func(req *http.Request) *http.Request {
+		return req.WithContext(context.WithValue(req.Context(), authenticatedUserContextKey, &editor))
+	}
+
+	t.Run("owner shares a project with a subcontractor", func(t *testing.T) {
+		form := url.Values{}
+		form.Add("user_id", strconv.Itoa(editorID))
+
+		req := httptest.NewRequest(http.MethodPost, "/project/share/"+strconv.Itoa(sharedID), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(sharedID))
+		rr := httptest.NewRecorder()
+
+		app.projectSharePost(rr, req)
+
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		shared, err := app.users.HasProjectAccess(sharedID, editorID)
+		require.NoError(t, err)
+		assert.True(t, shared)
+	})
+
+	t.Run("unshared projects are not found", func(t *testing.T) {
+		req := asEditor(httptest.NewRequest(http.MethodGet, "/project/view/"+strconv.Itoa(privateID), nil))
+		req.SetPathValue("id", strconv.Itoa(privateID))
+		rr := httptest.NewRecorder()
+
+		app.projectView(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("time is logged at the project rate and recorded against the subcontractor", func(t *testing.T) {
+		form := url.Values{}
+		form.Add("work_date", "2024-01-11")
+		form.Add("hours_worked", "3.5")
+		form.Add("hourly_rate", "999.00")
+		form.Add("description", "Copy editing")
+
+		req := asEditor(httptest.NewRequest(http.MethodPost, "/project/"+strconv.Itoa(sharedID)+"/timesheet/create", strings.NewReader(form.Encode())))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(sharedID))
+		rr := httptest.NewRecorder()
+
+		app.timesheetCreatePost(rr, req)
+
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		timesheets, err := app.timesheets.GetByProject(sharedID)
+		require.NoError(t, err)
+		var logged *models.Timesheet
+		for i := range timesheets {
+			if timesheets[i].Description == "Copy editing" {
+				logged = &timesheets[i]
+			}
+		}
+		require.NotNil(t, logged)
+		assert.InDelta(t, 50.0, logged.HourlyRate, 0.001)
+		require.NotNil(t, logged.UserID)
+		assert.Equal(t, editorID, *logged.UserID)
+	})
+
+	t.Run("shared project only shows the subcontractor's own timesheets", func(t *testing.T) {
+		req := asEditor(httptest.NewRequest(http.MethodGet, "/project/view/"+strconv.Itoa(sharedID), nil))
+		req.SetPathValue("id", strconv.Itoa(sharedID))
+		rr := httptest.NewRecorder()
+
+		app.projectView(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Copy editing")
+		assert.NotContains(t, rr.Body.String(), "Owner entry")
+	})
+
+	t.Run("other people's timesheets can't be edited", func(t *testing.T) {
+		req := asEditor(httptest.NewRequest(http.MethodGet, "/timesheet/update/"+strconv.Itoa(ownerEntryID), nil))
+		req.SetPathValue("id", strconv.Itoa(ownerEntryID))
+		rr := httptest.NewRecorder()
+
+		app.timesheetUpdate(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestTimesheetApprovals(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Approval Client")
+	projectID := testDB.InsertTestProject(t, "Shared Edit", clientID)
+
+	editorID, err := app.users.Insert(models.User{Name: "Ed Itor", Email: "editor@example.com", Role: models.RoleSubcontractor}, "correct horse")
+	require.NoError(t, err)
+	editor, err := app.users.Get(editorID)
+	require.NoError(t, err)
+	require.NoError(t, app.users.ShareProject(projectID, editorID))
+
+	asEditor := func(req *http.Request) *http.Request {
+		return req.WithContext(context.WithValue(req.Context(), authenticatedUserContextKey, &editor))
+	}
+	post := func(handler http.HandlerFunc, path string, id int, form url.Values, editing bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path+strconv.Itoa(id), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(id))
+		if editing {
+			req = asEditor(req)
+		}
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	logged := func(description string) models.Timesheet {
+		timesheets, err := app.timesheets.GetByProject(projectID)
+		require.NoError(t, err)
+		for _, timesheet := range timesheets {
+			if timesheet.Description == description {
+				return timesheet
+			}
+		}
+		t.Fatalf("no timesheet %q", description)
+		return models.Timesheet{}
+	}
+	entry := func(description string) url.Values {
+		return url.Values{"work_date": {"2024-01-11"}, "hours_worked": {"3.5"}, "hourly_rate": {"50.00"}, "description": {description}}
+	}
+
+	t.Run("time logged by a subcontractor waits for approval", func(t *testing.T) {
+		rr := post(app.timesheetCreatePost, "/project/timesheet/create/", projectID, entry("Copy editing"), true)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.True(t, logged("Copy editing").PendingApproval)
+
+		req := httptest.NewRequest(http.MethodGet, "/timesheets/approvals", nil)
+		rr = httptest.NewRecorder()
+		app.timesheetApprovalsList(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Shared Edit Ed Itor 3.50 hours Copy editing")
+	})
+
+	t.Run("time logged by an owner is approved", func(t *testing.T) {
+		rr := post(app.timesheetCreatePost, "/project/timesheet/create/", projectID, entry("Owner work"), false)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.False(t, logged("Owner work").PendingApproval)
+
+		// Only entries waiting for approval can be approved or rejected
+		rr = post(app.timesheetApprovePost, "/timesheet/approve/", logged("Owner work").ID, nil, false)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		rr = post(app.timesheetRejectPost, "/timesheet/reject/", logged("Owner work").ID, nil, false)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("approving makes the entry invoiceable", func(t *testing.T) {
+		rr := post(app.timesheetApprovePost, "/timesheet/approve/", logged("Copy editing").ID, nil, false)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/timesheets/approvals", rr.Header().Get("Location"))
+		assert.False(t, logged("Copy editing").PendingApproval)
+	})
+
+	t.Run("a subcontractor's change needs approving again", func(t *testing.T) {
+		id := logged("Copy editing").ID
+		rr := post(app.timesheetUpdatePost, "/timesheet/update/", id, entry("Copy editing, second pass"), true)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.True(t, logged("Copy editing, second pass").PendingApproval)
+	})
+
+	t.Run("rejecting deletes the entry", func(t *testing.T) {
+		id := logged("Copy editing, second pass").ID
+		rr := post(app.timesheetRejectPost, "/timesheet/reject/", id, nil, false)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		_, err := app.timesheets.Get(id)
+		assert.ErrorIs(t, err, models.ErrNoRecord)
+	})
+}
+
+func TestUserCreatePostFirstUserMustBeOwner(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	form := url.Values{}
+	form.Add("name", "Ed Itor")
+	form.Add("email", "editor@example.com")
+	form.Add("password", "correct horse")
+	form.Add("role", models.RoleSubcontractor)
+
+	req := httptest.NewRequest(http.MethodPost, "/user/create", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+
+	app.userCreatePost(rr, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), "The first user must be an owner")
+
+	count, err := app.users.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestTimesheetCostRate(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Cost Client")
+	projectID := testDB.InsertTestProject(t, "Cost Project", clientID)
+
+	postTimesheet := func(t *testing.T, path string, costRate string, handler http.HandlerFunc, pathID int) *httptest.ResponseRecorder {
+		form := url.Values{}
+		form.Add("work_date", "2024-01-15")
+		form.Add("hours_worked", "4")
+		form.Add("hourly_rate", "100.00")
+		form.Add("cost_rate", costRate)
+		form.Add("description", "Subcontracted editing")
+
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(pathID))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	var timesheetID int
+
+	t.Run("cost rate is recorded on create", func(t *testing.T) {
+		rr := postTimesheet(t, "/project/"+strconv.Itoa(projectID)+"/timesheet/create", "60.00", app.timesheetCreatePost, projectID)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		timesheets, err := app.timesheets.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, timesheets, 1)
+		timesheetID = timesheets[0].ID
+		require.NotNil(t, timesheets[0].CostRate)
+		assert.InDelta(t, 60.0, *timesheets[0].CostRate, 0.001)
+		assert.InDelta(t, 240.0, timesheets[0].Cost(), 0.001)
+	})
+
+	t.Run("invalid cost rate is rejected", func(t *testing.T) {
+		rr := postTimesheet(t, "/timesheet/update/"+strconv.Itoa(timesheetID), "-5", app.timesheetUpdatePost, timesheetID)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("blank cost rate clears it on update", func(t *testing.T) {
+		rr := postTimesheet(t, "/timesheet/update/"+strconv.Itoa(timesheetID), "", app.timesheetUpdatePost, timesheetID)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		timesheet, err := app.timesheets.Get(timesheetID)
+		require.NoError(t, err)
+		assert.Nil(t, timesheet.CostRate)
+	})
+}
+
+func TestServiceCatalogTimesheets(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Publisher")
+	projectID := testDB.InsertTestProject(t, "Novel", clientID)
+	_, err := testDB.DB.Exec("UPDATE client SET monthly_hour_allowance = 10 WHERE id = ?", clientID)
+	require.NoError(t, err)
+
+	post := func(handler http.HandlerFunc, id int, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	t.Run("invalid service is rejected", func(t *testing.T) {
+		rr := post(app.serviceCreatePost, 0, url.Values{"name": {"Proofreading"}, "default_rate": {"0.025"}, "unit": {"line"}})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Unit must be hour, word or page")
+	})
+
+	rr := post(app.serviceCreatePost, 0, url.Values{"name": {"Proofreading"}, "default_rate": {"0.025"}, "unit": {"word"}})
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+
+	services, err := app.services.GetAll()
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	serviceID := services[0].ID
+
+	t.Run("words are billed per word and don't count towards the hour allowance", func(t *testing.T) {
+		rr := post(app.timesheetCreatePost, projectID, url.Values{
+			"work_date":    {"2024-03-04"},
+			"hours_worked": {"12000"},
+			"hourly_rate":  {"0.025"},
+			"service_id":   {strconv.Itoa(serviceID)},
+			"description":  {"Chapters 1-3"},
+		})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		timesheets, err := app.timesheets.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, timesheets, 1)
+		assert.Equal(t, models.UnitWord, timesheets[0].Unit)
+		assert.Equal(t, "Proofreading", timesheets[0].ServiceName)
+		assert.InDelta(t, 300.0, timesheets[0].Amount(), 0.001)
+	})
+
+	t.Run("hours still count towards the allowance", func(t *testing.T) {
+		rr := post(app.timesheetCreatePost, projectID, url.Values{
+			"work_date":    {"2024-03-05"},
+			"hours_worked": {"12"},
+			"hourly_rate":  {"50.00"},
+			"description":  {"Structural edit"},
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "over the monthly allowance")
+	})
+
+	t.Run("unknown service is rejected", func(t *testing.T) {
+		rr := post(app.timesheetCreatePost, projectID, url.Values{
+			"work_date":    {"2024-03-05"},
+			"hours_worked": {"1"},
+			"hourly_rate":  {"50.00"},
+			"service_id":   {"999"},
+			"description":  {"Structural edit"},
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Service must be one of the available services")
+	})
+
+	t.Run("entries keep their unit after the service is removed", func(t *testing.T) {
+		rr := post(app.serviceDelete, serviceID, nil)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		timesheets, err := app.timesheets.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, timesheets, 1)
+
+		rr = post(app.timesheetUpdatePost, timesheets[0].ID, url.Values{
+			"work_date":    {"2024-03-04"},
+			"hours_worked": {"12500"},
+			"hourly_rate":  {"0.025"},
+			"service_id":   {strconv.Itoa(serviceID)},
+			"description":  {"Chapters 1-3"},
+		})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		timesheet, err := app.timesheets.Get(timesheets[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.UnitWord, timesheet.Unit)
+		assert.Equal(t, 12500.0, timesheet.HoursWorked)
+	})
+}
+
+func TestTimesheetWorkTypes(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Publisher")
+	projectID := testDB.InsertTestProject(t, "Novel", clientID)
+
+	post := func(handler http.HandlerFunc, id int, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	entry := func(workType string) url.Values {
+		return url.Values{
+			"work_date":    {"2024-03-04"},
+			"hours_worked": {"2"},
+			"hourly_rate":  {"50.00"},
+			"work_type":    {workType},
+			"description":  {"Chapter 1"},
+		}
+	}
+
+	t.Run("the form offers the work types in settings", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+		app.timesheetCreate(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "<option>Editing</option><option>Formatting</option><option>Consultation</option><option>Meeting</option>")
+	})
+
+	t.Run("unknown work type is rejected", func(t *testing.T) {
+		rr := post(app.timesheetCreatePost, projectID, entry("Gardening"))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Work type must be one of the work types in settings")
+	})
+
+	rr := post(app.timesheetCreatePost, projectID, entry("Editing"))
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+	timesheets, err := app.timesheets.GetByProject(projectID)
+	require.NoError(t, err)
+	require.Len(t, timesheets, 1)
+	id := timesheets[0].ID
+	assert.Equal(t, "Editing", timesheets[0].WorkType)
+
+	t.Run("an entry keeps a work type taken out of settings", func(t *testing.T) {
+		_, err := testDB.DB.Exec("UPDATE settings SET value = 'Formatting, Meeting' WHERE key = 'timesheet_work_types'")
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		app.timesheetUpdate(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "<option selected>Editing</option>")
+
+		rr = post(app.timesheetUpdatePost, id, entry("Editing"))
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		rr = post(app.timesheetCreatePost, projectID, entry("Editing"))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, "new entries can't use it")
+	})
+
+	t.Run("updating without a work type clears it", func(t *testing.T) {
+		rr := post(app.timesheetUpdatePost, id, entry(""))
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		timesheet, err := app.timesheets.Get(id)
+		require.NoError(t, err)
+		assert.Empty(t, timesheet.WorkType)
+	})
+}
+
+func TestMilestoneHandlers(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Publisher")
+	projectID := testDB.InsertTestProject(t, "Novel", clientID)
+
+	post := func(handler http.HandlerFunc, id int, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	t.Run("invalid milestone is rejected", func(t *testing.T) {
+		rr := post(app.milestoneCreatePost, projectID, url.Values{"name": {""}, "due_date": {"soon"}, "fee": {"-5"}, "status": {"Invoiced"}})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		body := rr.Body.String()
+		assert.Contains(t, body, "Name is required")
+		assert.Contains(t, body, "Due date must be in")
+		assert.Contains(t, body, "Fee must be a positive number")
+		assert.Contains(t, body, "Status must be Planned, In Progress or Complete")
+	})
+
+	rr := post(app.milestoneCreatePost, projectID, url.Values{"name": {"First draft"}, "due_date": {"2024-05-01"}, "fee": {"1500"}, "status": {"Complete"}})
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+
+	milestones, err := app.milestones.GetByProject(projectID)
+	require.NoError(t, err)
+	require.Len(t, milestones, 1)
+	milestoneID := milestones[0].ID
+	assert.Equal(t, 1500.0, milestones[0].Fee)
+
+	t.Run("milestones are shown on the project", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+		app.projectView(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "First draft: Complete")
+	})
+
+	t.Run("hourly projects aren't invoiced by milestone", func(t *testing.T) {
+		rr := post(app.milestoneInvoicePost, milestoneID, url.Values{})
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	_, err = testDB.DB.Exec("UPDATE project SET flat_fee_invoice = 1 WHERE id = ?", projectID)
+	require.NoError(t, err)
+
+	var invoiceID int
+	t.Run("flat fee milestone is invoiced directly", func(t *testing.T) {
+		rr := post(app.milestoneInvoicePost, milestoneID, url.Values{})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoices, err := app.invoices.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, invoices, 1)
+		assert.Equal(t, 1500.0, invoices[0].AmountDue)
+		assert.NotNil(t, invoices[0].DueDate)
+		invoiceID = invoices[0].ID
+
+		milestone, err := app.milestones.Get(milestoneID)
+		require.NoError(t, err)
+		assert.Equal(t, models.MilestoneStatusInvoiced, milestone.Status)
+		require.NotNil(t, milestone.InvoiceID)
+		assert.Equal(t, invoiceID, *milestone.InvoiceID)
+	})
+
+	t.Run("invoiced milestones can't be invoiced again or changed", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, post(app.milestoneInvoicePost, milestoneID, url.Values{}).Code)
+		assert.Equal(t, http.StatusConflict, post(app.milestoneUpdatePost, milestoneID, url.Values{"name": {"Changed"}, "status": {"Planned"}}).Code)
+		assert.Equal(t, http.StatusConflict, post(app.milestoneDelete, milestoneID, url.Values{}).Code)
+	})
+
+	t.Run("voiding the invoice frees the milestone", func(t *testing.T) {
+		rr := post(app.invoiceVoidPost, invoiceID, url.Values{"void_reason": {"Wrong amount"}})
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		milestone, err := app.milestones.Get(milestoneID)
+		require.NoError(t, err)
+		assert.False(t, milestone.IsInvoiced())
+		assert.Equal(t, models.MilestoneStatusComplete, milestone.Status)
+
+		rr = post(app.milestoneUpdatePost, milestoneID, url.Values{"name": {"First draft"}, "fee": {"1200"}, "status": {"Complete"}})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		milestone, err = app.milestones.Get(milestoneID)
+		require.NoError(t, err)
+		assert.Equal(t, 1200.0, milestone.Fee)
+		assert.Nil(t, milestone.DueDate)
+	})
+
+	t.Run("deleted milestones are hidden", func(t *testing.T) {
+		rr := post(app.milestoneDelete, milestoneID, url.Values{})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		milestones, err := app.milestones.GetByProject(projectID)
+		require.NoError(t, err)
+		assert.Empty(t, milestones)
+	})
+}
+
+func TestBasePathAndTrustedProxies(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	proxies, err := parseTrustedProxies("10.0.0.0/8, 127.0.0.1")
+	require.NoError(t, err)
+	app.basePath = normalizeBasePath("freelance/")
+	app.trustedProxies = proxies
+	assert.Equal(t, "/freelance", app.basePath)
+
+	_, err = parseTrustedProxies("not-an-ip")
+	assert.Error(t, err)
+
+	t.Run("routes are served under the base path", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /projects", func(w http.ResponseWriter, r *http.Request) {
+			app.redirect(w, r, "/project/view/1", http.StatusSeeOther)
+		})
+		handler := app.mountAtBasePath(mux)
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/freelance/projects", nil))
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/freelance/project/view/1", rr.Header().Get("Location"))
+
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/projects", nil))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/freelance", nil))
+		assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+		assert.Equal(t, "/freelance/", rr.Header().Get("Location"))
+	})
+
+	t.Run("forwarded headers are honoured from trusted proxies", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/freelance/projects", nil)
+		req.RemoteAddr = "10.1.2.3:41000"
+		req.Host = "internal:8080"
+		req.Header.Set("X-Forwarded-For", "198.51.100.7, 203.0.113.9, 10.0.0.5")
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-Host", "tracker.example.com")
+
+		assert.Equal(t, "203.0.113.9", app.clientIP(req))
+		assert.Equal(t, "https", app.requestScheme(req))
+		assert.Equal(t, "https://tracker.example.com/freelance/invoice/print/3", app.absoluteURL(req, "/invoice/print/3"))
+	})
+
+	t.Run("forwarded headers are ignored from anyone else", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/freelance/projects", nil)
+		req.RemoteAddr = "192.0.2.44:41000"
+		req.Host = "tracker.example.com"
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-Host", "evil.example.com")
+
+		assert.Equal(t, "192.0.2.44", app.clientIP(req))
+		assert.Equal(t, "http", app.requestScheme(req))
+		assert.Equal(t, "http://tracker.example.com/freelance/", app.absoluteURL(req, "/"))
+	})
+}
+
+func TestInvoiceAPI(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "API Client")
+	projectID := testDB.InsertTestProject(t, "API Project", clientID)
+
+	var webhookPayload map[string]any
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&webhookPayload))
+	}))
+	defer hook.Close()
+	app.webhooks.Client = hook.Client()
+	require.NoError(t, app.settings.UpdateValue("webhook_invoice_paid_url", hook.URL))
+
+	apiGet := func(path string, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		rr := httptest.NewRecorder()
+		mux := http.NewServeMux()
+		mux.Handle("GET /api/v1/invoices", app.requireAPIKey(http.HandlerFunc(app.apiInvoicesList)))
+		mux.Handle("GET /api/v1/invoices/{id}", app.requireAPIKey(http.HandlerFunc(app.apiInvoiceView)))
+		mux.Handle("GET /api/v1/invoice-events", app.requireAPIKey(http.HandlerFunc(app.apiInvoiceEventsList)))
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	postInvoice := func(path string, handler http.HandlerFunc, id int, form url.Values) {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+	}
+
+	t.Run("API is disabled without a key", func(t *testing.T) {
+		rr := apiGet("/api/v1/invoices", "")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	require.NoError(t, app.settings.UpdateValue("api_key", "s3cret"))
+
+	t.Run("wrong key is rejected", func(t *testing.T) {
+		rr := apiGet("/api/v1/invoices", "guess")
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"error"`)
+	})
+
+	postInvoice("/project/create", app.invoiceCreatePost, projectID, url.Values{
+		"invoice_date": {"2024-03-01"},
+		"amount_due":   {"100.00"},
+	})
+	postInvoice("/project/create", app.invoiceCreatePost, projectID, url.Values{
+		"invoice_date": {"2024-04-01"},
+		"amount_due":   {"250.00"},
+	})
+
+	invoices, err := app.invoices.GetByProject(projectID)
+	require.NoError(t, err)
+	require.Len(t, invoices, 2)
+	firstID := min(invoices[0].ID, invoices[1].ID)
+
+	t.Run("invoices are paged by cursor", func(t *testing.T) {
+		rr := apiGet("/api/v1/invoices?limit=1", "s3cret")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+		var page struct {
+			Data       []apiInvoice `json:"data"`
+			NextCursor *string      `json:"next_cursor"`
+			HasMore    bool         `json:"has_more"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+		require.Len(t, page.Data, 1)
+		assert.True(t, page.HasMore)
+		require.NotNil(t, page.NextCursor)
+		assert.Equal(t, firstID, page.Data[0].ID)
+		assert.Equal(t, "unpaid", page.Data[0].Status)
+		assert.Equal(t, "API Client", page.Data[0].ClientName)
+		assert.Equal(t, "2024-03-01", page.Data[0].InvoiceDate)
+		assert.Nil(t, page.Data[0].DatePaid)
+
+		rr = apiGet("/api/v1/invoices?limit=1&cursor="+*page.NextCursor, "s3cret")
+		page.Data, page.NextCursor = nil, nil
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+		require.Len(t, page.Data, 1)
+		assert.False(t, page.HasMore)
+		assert.Nil(t, page.NextCursor)
+		assert.InDelta(t, 250.0, page.Data[0].AmountDue, 0.001)
+
+		rr = apiGet("/api/v1/invoices?limit=500", "s3cret")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("marking an invoice paid records an event and fires the webhook", func(t *testing.T) {
+		postInvoice("/invoice/update", app.invoiceUpdatePost, firstID, url.Values{
+			"invoice_date": {"2024-03-01"},
+			"date_paid":    {"2024-03-10"},
+			"amount_due":   {"100.00"},
+		})
+		app.wg.Wait()
+
+		require.NotNil(t, webhookPayload)
+		assert.Equal(t, "paid", webhookPayload["event_type"])
+		assert.Equal(t, float64(firstID), webhookPayload["id"])
+		assert.Equal(t, "2024-03-10", webhookPayload["date_paid"])
+
+		rr := apiGet("/api/v1/invoice-events?event_type=paid", "s3cret")
+		require.Equal(t, http.StatusOK, rr.Code)
+		var page struct {
+			Data []apiInvoiceEvent `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+		require.Len(t, page.Data, 1)
+		assert.Equal(t, firstID, page.Data[0].ID)
+		assert.Equal(t, "paid", page.Data[0].Status)
+
+		rr = apiGet("/api/v1/invoice-events", "s3cret")
+		page.Data = nil
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+		var types []string
+		for _, event := range page.Data {
+			types = append(types, event.EventType)
+		}
+		assert.Equal(t, []string{"created", "created", "updated", "paid"}, types)
+
+		rr = apiGet("/api/v1/invoice-events?event_type=voided", "s3cret")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"data":[]`, "no matching events is an empty list, not null")
+
+		rr = apiGet("/api/v1/invoice-events?event_type=refunded", "s3cret")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("single invoice", func(t *testing.T) {
+		rr := apiGet("/api/v1/invoices/"+strconv.Itoa(firstID), "s3cret")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"status":"paid"`)
+
+		rr = apiGet("/api/v1/invoices/9999", "s3cret")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestExternalIDs(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Synced Client")
+	otherID := testDB.InsertTestClient(t, "Other Client")
+	projectID := testDB.InsertTestProject(t, "Synced Project", clientID)
+	invoiceID := testDB.InsertTestInvoice(t, projectID, "2024-03-01", "", "Net 30", "100.00")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /external-ids/{kind}/{id}", app.externalIDsView)
+	mux.HandleFunc("POST /external-ids/{kind}/{id}", app.externalIDsPost)
+	mux.HandleFunc("POST /external-ids/{kind}/{id}/delete/{source}", app.externalIDDelete)
+	mux.Handle("GET /api/v1/external-ids/{kind}/{source}/{external_id...}", app.requireAPIKey(http.HandlerFunc(app.apiExternalIDView)))
+	require.NoError(t, app.settings.UpdateValue("api_key", "s3cret"))
+
+	post := func(path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("IDs are recorded per source", func(t *testing.T) {
+		rr := post(fmt.Sprintf("/external-ids/client/%d", clientID), url.Values{"source": {" QuickBooks "}, "external_id": {"QB-17"}})
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, fmt.Sprintf("/external-ids/client/%d", clientID), rr.Header().Get("Location"))
+		rr = post(fmt.Sprintf("/external-ids/invoice/%d", invoiceID), url.Values{"source": {"xero"}, "external_id": {"INV/0042"}})
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		rr = get(fmt.Sprintf("/external-ids/client/%d", clientID))
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Synced Client")
+		assert.Contains(t, rr.Body.String(), "quickbooks=QB-17")
+	})
+
+	t.Run("an ID belongs to one record per source", func(t *testing.T) {
+		rr := post(fmt.Sprintf("/external-ids/client/%d", otherID), url.Values{"source": {"quickbooks"}, "external_id": {"QB-17"}})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Another client already has this ID in quickbooks")
+
+		rr = post(fmt.Sprintf("/external-ids/project/%d", projectID), url.Values{"source": {"quickbooks"}, "external_id": {"QB-17"}})
+		assert.Equal(t, http.StatusSeeOther, rr.Code, "a project can share an ID with a client")
+	})
+
+	t.Run("invalid input is rejected", func(t *testing.T) {
+		rr := post(fmt.Sprintf("/external-ids/client/%d", clientID), url.Values{"source": {"quick books!"}, "external_id": {" "}})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Source must be")
+		assert.Contains(t, rr.Body.String(), "External ID is required")
+
+		rr = get("/external-ids/timesheet/1")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		rr = get("/external-ids/client/99999")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("API finds the record by its external ID", func(t *testing.T) {
+		rr := get("/api/v1/external-ids/invoice/xero/INV/0042")
+		require.Equal(t, http.StatusOK, rr.Code)
+		var found apiExternalID
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &found))
+		assert.Equal(t, apiExternalID{Kind: "invoice", ID: invoiceID, Name: found.Name, Source: "xero", ExternalID: "INV/0042"}, found)
+		assert.Contains(t, found.Name, "Invoice #")
+
+		rr = get("/api/v1/external-ids/client/QuickBooks/QB-17")
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &found))
+		assert.Equal(t, clientID, found.ID)
+
+		rr = get("/api/v1/external-ids/client/quickbooks/QB-99")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"error"`)
+		rr = get("/api/v1/external-ids/milestone/quickbooks/QB-17")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("IDs can be removed", func(t *testing.T) {
+		rr := post(fmt.Sprintf("/external-ids/project/%d/delete/quickbooks", projectID), nil)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		ids, err := app.externalIDs.GetAll(models.ExternalProject, projectID)
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+
+		rr = post(fmt.Sprintf("/external-ids/project/%d/delete/quickbooks", projectID), nil)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("deleted records aren't found", func(t *testing.T) {
+		require.NoError(t, app.clients.Delete(clientID))
+		rr := get("/api/v1/external-ids/client/quickbooks/QB-17")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestFormDrafts(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	ownerID, err := app.users.Insert(models.User{Name: "Olive Owner", Email: "owner@example.com", Role: models.RoleOwner}, "correct horse")
+	require.NoError(t, err)
+	owner, err := app.users.Get(ownerID)
+	require.NoError(t, err)
+	otherID, err := app.users.Insert(models.User{Name: "Oscar Owner", Email: "oscar@example.com", Role: models.RoleOwner}, "correct horse")
+	require.NoError(t, err)
+	other, err := app.users.Get(otherID)
+	require.NoError(t, err)
+
+	clientID := testDB.InsertTestClient(t, "Drafted Client")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /draft/{form}/{id}", app.formDraftView)
+	mux.HandleFunc("POST /draft/{form}/{id}", app.formDraftPost)
+	mux.HandleFunc("POST /draft/{form}/{id}/discard", app.formDraftDiscard)
+	mux.HandleFunc("GET /client/create", app.clientCreate)
+	mux.HandleFunc("POST /client/create", app.clientCreatePost)
+	mux.HandleFunc("POST /client/update/{id}", app.clientUpdatePost)
+
+	send := func(user *models.User, method, path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if user != nil {
+			req = req.WithContext(context.WithValue(req.Context(), authenticatedUserContextKey, user))
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+	draft := func(user *models.User, path string) (formDraftData, int) {
+		rr := send(user, http.MethodGet, path, nil)
+		var found formDraftData
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &found))
+		}
+		return found, rr.Code
+	}
+
+	t.Run("forms say where their draft is kept", func(t *testing.T) {
+		rr := send(&owner, http.MethodGet, "/client/create", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `data-draft="/draft/client/0"`)
+	})
+
+	t.Run("a draft is saved and read back by the same user", func(t *testing.T) {
+		rr := send(&owner, http.MethodPost, fmt.Sprintf("/draft/client/%d", clientID), url.Values{
+			"name":       {"Drafted Client Ltd"},
+			"tag_ids":    {"1", "2"},
+			"csrf_token": {"secret"},
+		})
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		found, code := draft(&owner, fmt.Sprintf("/draft/client/%d", clientID))
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, url.Values{"name": {"Drafted Client Ltd"}, "tag_ids": {"1", "2"}}, found.Fields)
+		assert.WithinDuration(t, time.Now(), found.Updated, time.Minute)
+
+		_, code = draft(&other, fmt.Sprintf("/draft/client/%d", clientID))
+		assert.Equal(t, http.StatusNotFound, code, "drafts belong to the user who typed them")
+		_, code = draft(nil, fmt.Sprintf("/draft/client/%d", clientID))
+		assert.Equal(t, http.StatusNotFound, code)
+		_, code = draft(&owner, "/draft/invoice/1")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("saving the form discards its draft", func(t *testing.T) {
+		send(&owner, http.MethodPost, "/draft/client/0", url.Values{"name": {"Brand New"}})
+		rr := send(&owner, http.MethodPost, "/client/create", url.Values{"name": {"Brand New"}, "email": {"new@example.com"}, "hourly_rate": {"75.00"}})
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		_, code := draft(&owner, "/draft/client/0")
+		assert.Equal(t, http.StatusNotFound, code)
+
+		rr = send(&owner, http.MethodPost, fmt.Sprintf("/client/update/%d", clientID), url.Values{"name": {""}})
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		_, code = draft(&owner, fmt.Sprintf("/draft/client/%d", clientID))
+		assert.Equal(t, http.StatusOK, code, "a form that didn't save keeps its draft")
+	})
+
+	t.Run("a draft can be discarded", func(t *testing.T) {
+		rr := send(&owner, http.MethodPost, fmt.Sprintf("/draft/client/%d/discard", clientID), nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		_, code := draft(&owner, fmt.Sprintf("/draft/client/%d", clientID))
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
+
+func TestTagHandlers(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	referralID := testDB.InsertTestClient(t, "Referred Client")
+	otherID := testDB.InsertTestClient(t, "Other Client")
+	projectID := testDB.InsertTestProject(t, "Thesis Edit", referralID)
+	testDB.InsertTestProject(t, "Journal Article", otherID)
+
+	post := func(handler http.HandlerFunc, id int, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	get := func(handler http.HandlerFunc, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	t.Run("invalid tag is rejected", func(t *testing.T) {
+		rr := post(app.tagCreatePost, 0, url.Values{"name": {"  "}, "color": {"red"}})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Name is required")
+		assert.Contains(t, rr.Body.String(), "Colour must be a hex colour")
+	})
+
+	rr := post(app.tagCreatePost, 0, url.Values{"name": {"Referral"}, "color": {"#ffcc00"}})
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+	rr = post(app.tagCreatePost, 0, url.Values{"name": {"Editing"}, "color": {"#112233"}})
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+
+	tags, err := app.tags.GetAll()
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+	editingID, referralTagID := tags[0].ID, tags[1].ID
+
+	t.Run("duplicate tag names conflict", func(t *testing.T) {
+		rr := post(app.tagCreatePost, 0, url.Values{"name": {"Referral"}, "color": {"#000000"}})
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("tags are assigned from the client and project forms", func(t *testing.T) {
+		rr := post(app.clientUpdatePost, referralID, url.Values{
+			"name":        {"Referred Client"},
+			"email":       {"referred@example.com"},
+			"hourly_rate": {"50"},
+			"tag_ids":     {strconv.Itoa(referralTagID)},
+		})
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		rr = post(app.projectUpdatePost, projectID, url.Values{
+			"name":                 {"Thesis Edit"},
+			"status":               {"In Progress"},
+			"hourly_rate":          {"50"},
+			"acknowledge_warnings": {"true"},
+			"tag_ids":              {strconv.Itoa(editingID), strconv.Itoa(referralTagID)},
+		})
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		clientTags, err := app.tags.ForClient(referralID)
+		require.NoError(t, err)
+		assert.Equal(t, []int{referralTagID}, models.TagIDs(clientTags))
+		projectTags, err := app.tags.ForProject(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, []int{editingID, referralTagID}, models.TagIDs(projectTags))
+	})
+
+	t.Run("client list shows chips and filters by tag", func(t *testing.T) {
+		rr := get(app.home, "/")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Referred Client [Referral]")
+		assert.Contains(t, rr.Body.String(), "Other Client")
+
+		rr = get(app.home, fmt.Sprintf("/?tag=%d", referralTagID))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Referred Client")
+		assert.NotContains(t, rr.Body.String(), "Other Client")
+
+		rr = get(app.home, "/?tag=999")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("project list shows chips and filters by tag", func(t *testing.T) {
+		rr := get(app.projectsList, fmt.Sprintf("/projects?tag=%d", editingID))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "[Editing][Referral]")
+		assert.NotContains(t, rr.Body.String(), "Journal Article")
+	})
+
+	t.Run("deleting a tag takes it off clients and projects", func(t *testing.T) {
+		rr := post(app.tagDelete, referralTagID, nil)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		clientTags, err := app.tags.ForClient(referralID)
+		require.NoError(t, err)
+		assert.Empty(t, clientTags)
+		projectTags, err := app.tags.ForProject(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, []int{editingID}, models.TagIDs(projectTags))
+
+		rr = post(app.tagDelete, referralTagID, nil)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestListFilters(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	bostonID := testDB.InsertTestClient(t, "Boston Client")
+	testDB.InsertTestClient(t, "Denver Client")
+	_, err := testDB.DB.Exec("UPDATE client SET city = 'Boston' WHERE id = ?", bostonID)
+	require.NoError(t, err)
+	testDB.InsertTestProject(t, "Thesis Edit", bostonID)
+	scheduledID := testDB.InsertTestProject(t, "Journal Article", bostonID)
+	require.NoError(t, app.projects.SetStatus(scheduledID, "Scheduled"))
+
+	get := func(handler http.HandlerFunc, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	post := func(handler http.HandlerFunc, id int, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	t.Run("client list is narrowed to the filter", func(t *testing.T) {
+		rr := get(app.home, "/?filter=city.eq.boston")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Boston Client")
+		assert.NotContains(t, rr.Body.String(), "Denver Client")
+	})
+
+	t.Run("project list is narrowed to the filter", func(t *testing.T) {
+		rr := get(app.projectsList, "/projects?filter=status.eq.Scheduled")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Journal Article")
+		assert.NotContains(t, rr.Body.String(), "Thesis Edit")
+	})
+
+	t.Run("a condition that doesn't fit the list is rejected", func(t *testing.T) {
+		rr := get(app.projectsList, "/projects?filter=hourly_rate.gt.lots")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Hourly rate must be a number")
+	})
+
+	t.Run("adding a condition redirects to the filtered list", func(t *testing.T) {
+		rr := get(app.projectsList, "/projects?filter=status.eq.Scheduled&field=client&op=contains&value=+boston+")
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/projects?filter=status.eq.Scheduled&filter=client.contains.boston", rr.Header().Get("Location"))
+
+		rr = get(app.projectsList, "/projects?field=client&op=lt&value=b")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("choosing a view redirects to its filter", func(t *testing.T) {
+		view := models.ProjectFilters.QuickFilters[0].Filter.Query()
+		rr := get(app.projectsList, "/projects?view="+url.QueryEscape(view))
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/projects?"+view, rr.Header().Get("Location"))
+
+		rr = get(app.home, "/?view=")
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/", rr.Header().Get("Location"))
+	})
+
+	t.Run("views are saved and deleted", func(t *testing.T) {
+		rr := post(app.savedViewCreatePost, 0, url.Values{"list": {"clients"}, "name": {"Bostonians"}, "query": {"filter=city.eq.Boston"}})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/?filter=city.eq.Boston", rr.Header().Get("Location"))
+
+		views, err := app.savedViews.GetForList(nil, "clients")
+		require.NoError(t, err)
+		require.Len(t, views, 1)
+		assert.Equal(t, "Bostonians", views[0].Name)
+
+		data, err := app.listFilterData(httptest.NewRequest(http.MethodGet, "/", nil), models.ClientFilters, models.Filter{{Field: "city", Operator: models.FilterEquals, Value: "Boston"}})
+		require.NoError(t, err)
+		require.NotNil(t, data.SavedView)
+		assert.Equal(t, views[0].ID, data.SavedView.ID)
+		assert.Equal(t, []filterConditionData{{Label: "City is Boston", RemoveQuery: ""}}, data.Conditions)
+
+		rr = post(app.savedViewDelete, views[0].ID, nil)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/", rr.Header().Get("Location"))
+		views, err = app.savedViews.GetForList(nil, "clients")
+		require.NoError(t, err)
+		assert.Empty(t, views)
+	})
+
+	t.Run("views of unknown lists or bad filters are not saved", func(t *testing.T) {
+		rr := post(app.savedViewCreatePost, 0, url.Values{"list": {"invoices"}, "name": {"Unpaid"}, "query": {"filter=city.eq.Boston"}})
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = post(app.savedViewCreatePost, 0, url.Values{"list": {"clients"}, "name": {"Bad"}, "query": {"filter=status.eq.Scheduled"}})
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = post(app.savedViewCreatePost, 0, url.Values{"list": {"clients"}, "name": {" "}, "query": {"filter=city.eq.Boston"}})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		views, err := app.savedViews.GetForList(nil, "clients")
+		require.NoError(t, err)
+		assert.Empty(t, views)
+	})
+
+	t.Run("other users' views can't be deleted", func(t *testing.T) {
+		userID, err := app.users.Insert(models.User{Name: "Owner", Email: "owner@example.com", Role: models.RoleOwner}, "correct horse")
+		require.NoError(t, err)
+		viewID, err := app.savedViews.Insert(&userID, "clients", "Mine", "filter=city.eq.Boston")
+		require.NoError(t, err)
+
+		rr := post(app.savedViewDelete, viewID, nil)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		_, err = app.savedViews.Get(viewID)
+		assert.NoError(t, err)
+	})
+}
+
+func TestAdminMigrationsHandlers(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	// The test schema isn't built by migrations, so run a couple of harmless ones instead
+	app.migrationsDir = t.TempDir()
+	for name, content := range map[string]string{
+		"001_create_scratch.sql":  "-- +goose Up\nCREATE TABLE scratch (id INTEGER);\n\n-- +goose Down\nDROP TABLE scratch;\n",
+		"002_create_scratch2.sql": "-- +goose Up\nCREATE TABLE scratch2 (id INTEGER);\n\n-- +goose Down\nDROP TABLE scratch2;\n",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(app.migrationsDir, name), []byte(content), 0o644))
+	}
+
+	post := func(handler http.HandlerFunc, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	get := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/admin/migrations", nil)
+		rr := httptest.NewRecorder()
+		app.adminMigrations(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr.Body.String()
+	}
+
+	t.Run("status lists pending migrations", func(t *testing.T) {
+		body := get()
+		assert.Contains(t, body, "001_create_scratch.sql pending")
+		assert.Contains(t, body, "002_create_scratch2.sql pending")
+	})
+
+	t.Run("dry run lists the migrations without applying them", func(t *testing.T) {
+		rr := post(app.adminMigrationsUpPost, url.Values{"dry_run": {"true"}})
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Would apply 001_create_scratch.sql")
+		assert.Contains(t, rr.Body.String(), "001_create_scratch.sql pending")
+	})
+
+	t.Run("up applies pending migrations and lifts maintenance", func(t *testing.T) {
+		rr := post(app.adminMigrationsUpPost, url.Values{})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/admin/migrations", rr.Header().Get("Location"))
+		assert.False(t, app.maintenance.Load())
+
+		body := get()
+		assert.Contains(t, body, "001_create_scratch.sql applied")
+		assert.Contains(t, body, "002_create_scratch2.sql applied")
+	})
+
+	t.Run("down needs at least one step", func(t *testing.T) {
+		rr := post(app.adminMigrationsDownPost, url.Values{"steps": {"0"}})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Roll back at least one migration")
+	})
+
+	t.Run("dry run down lists the newest migration", func(t *testing.T) {
+		rr := post(app.adminMigrationsDownPost, url.Values{"steps": {"1"}, "dry_run": {"true"}})
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Would roll back 002_create_scratch2.sql")
+		assert.NotContains(t, rr.Body.String(), "Would roll back 001_create_scratch.sql")
+		assert.Contains(t, rr.Body.String(), "002_create_scratch2.sql applied")
+	})
+
+	t.Run("down rolls back the newest migration", func(t *testing.T) {
+		rr := post(app.adminMigrationsDownPost, url.Values{"steps": {"1"}})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		body := get()
+		assert.Contains(t, body, "001_create_scratch.sql applied")
+		assert.Contains(t, body, "002_create_scratch2.sql pending")
+	})
+
+	t.Run("down leaves maintenance on until the migrations are applied again", func(t *testing.T) {
+		assert.True(t, app.maintenance.Load())
+
+		rr := post(app.adminMigrationsUpPost, url.Values{})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.False(t, app.maintenance.Load())
+		assert.Contains(t, get(), "002_create_scratch2.sql applied")
+	})
+
+	t.Run("migrations keep maintenance mode on if it was already on", func(t *testing.T) {
+		app.maintenance.Store(true)
+		defer app.maintenance.Store(false)
+
+		rr := post(app.adminMigrationsUpPost, url.Values{})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.True(t, app.maintenance.Load())
+	})
+
+	t.Run("a second run conflicts while one is in progress", func(t *testing.T) {
+		app.migrating.Lock()
+		defer app.migrating.Unlock()
+
+		rr := post(app.adminMigrationsUpPost, url.Values{})
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("database maintenance runs as a job", func(t *testing.T) {
+		app.queue.Handle(models.JobDatabaseMaintenance, app.databaseMaintenanceJob)
+		body := get()
+		assert.Regexp(t, `Database: [1-9][0-9]* bytes`, body)
+		assert.Contains(t, body, `<input type="checkbox" name="tasks" value="vacuum">`)
+
+		rr := post(app.adminDatabasePost, url.Values{})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/admin/migrations", rr.Header().Get("Location"), "nothing is queued without a task")
+
+		rr = post(app.adminDatabasePost, url.Values{"tasks": {"analyze", "vacuum"}})
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		jobID, err := strconv.Atoi(strings.TrimPrefix(rr.Header().Get("Location"), "/job/view/"))
+		require.NoError(t, err)
+
+		ran, err := app.queue.RunNext(context.Background())
+		require.NoError(t, err)
+		require.True(t, ran)
+		job, err := app.jobs.Get(jobID)
+		require.NoError(t, err)
+		require.Equal(t, models.JobStatusDone, job.Status, job.Error)
+		assert.Equal(t, "Database maintenance", job.Description())
+		lines := strings.Split(strings.TrimSpace(string(job.Result)), "\n")
+		require.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[0], "vacuum: rebuilt the file"), lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], "analyze: refreshed"), lines[1])
+	})
+
+	t.Run("maintenance mode toggles", func(t *testing.T) {
+		rr := post(app.adminMaintenancePost, nil)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.True(t, app.maintenance.Load())
+		assert.Contains(t, get(), "Maintenance: true")
+
+		post(app.adminMaintenancePost, nil)
+		assert.False(t, app.maintenance.Load())
+	})
+}
+
+func TestAdminIntegrityHandlers(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Integrity Client")
+	deletedID := testDB.InsertTestProject(t, "Deleted Project", clientID)
+	targetID := testDB.InsertTestProject(t, "Target Project", clientID)
+	timesheetID := testDB.InsertTestTimesheet(t, deletedID, "2024-05-02", "1.0", "50.00", "Left behind")
+	_, err := testDB.DB.Exec("UPDATE project SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?", deletedID)
+	require.NoError(t, err)
+
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/integrity/repair", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		app.adminIntegrityRepairPost(rr, req)
+		return rr
+	}
+	get := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/admin/integrity", nil)
+		rr := httptest.NewRecorder()
+		app.adminIntegrity(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr.Body.String()
+	}
+
+	t.Run("the report lists orphaned rows", func(t *testing.T) {
+		assert.Contains(t, get(), fmt.Sprintf("timesheet_project: %d ", timesheetID))
+	})
+
+	t.Run("reattaching needs a parent that exists", func(t *testing.T) {
+		rr := post(url.Values{"check": {"timesheet_project"}, "action": {"reattach"}})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Enter the project ID to reattach the rows to")
+
+		rr = post(url.Values{"check": {"timesheet_project"}, "action": {"reattach"}, "parent_id": {strconv.Itoa(deletedID)}})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), fmt.Sprintf("There is no project with ID %d", deletedID))
+	})
+
+	t.Run("unknown checks and disallowed repairs are rejected", func(t *testing.T) {
+		rr := post(url.Values{"check": {"nonsense"}, "action": {"soft_delete"}})
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = post(url.Values{"check": {"invoice_event_invoice"}, "action": {"soft_delete"}})
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("reattaching moves the rows to the parent", func(t *testing.T) {
+		rr := post(url.Values{"check": {"timesheet_project"}, "action": {"reattach"}, "parent_id": {strconv.Itoa(targetID)}})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/admin/integrity", rr.Header().Get("Location"))
+
+		timesheet, err := app.timesheets.Get(timesheetID)
+		require.NoError(t, err)
+		assert.Equal(t, targetID, timesheet.ProjectID)
+		assert.Contains(t, get(), "No problems found")
+	})
+
+	t.Run("soft deleting hides the rows", func(t *testing.T) {
+		_, err := testDB.DB.Exec("UPDATE timesheet SET project_id = ? WHERE id = ?", deletedID, timesheetID)
+		require.NoError(t, err)
+
+		rr := post(url.Values{"check": {"timesheet_project"}, "action": {"soft_delete"}})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		_, err = app.timesheets.Get(timesheetID)
+		assert.ErrorIs(t, err, models.ErrNoRecord)
+		assert.Contains(t, get(), "No problems found")
+	})
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	handler := app.maintenanceMode(mux)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("requests are served normally outside maintenance", func(t *testing.T) {
+		rr := serve("/projects")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "ok", rr.Body.String())
+	})
+
+	app.maintenance.Store(true)
+
+	t.Run("pages show the maintenance notice", func(t *testing.T) {
+		rr := serve("/projects")
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+		assert.Contains(t, rr.Body.String(), "Down for maintenance")
+	})
+
+	t.Run("static files, login and admin pages stay reachable", func(t *testing.T) {
+		for _, path := range []string{"/static/css/main.css", "/user/login", "/admin/migrations"} {
+			rr := serve(path)
+			assert.Equal(t, http.StatusOK, rr.Code, path)
+		}
+	})
+}
+
+func TestRequirePDF(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	handler := app.requirePDF(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/invoice/print/1", nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("PDF pages are served when Chrome was found", func(t *testing.T) {
+		rr := serve()
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "ok", rr.Body.String())
+	})
+
+	t.Run("PDF pages explain when Chrome is missing", func(t *testing.T) {
+		app.pdfUnavailable = models.ErrPDFUnavailable
+
+		rr := serve()
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Invoice PDFs Unavailable")
+	})
+}
+
+func TestProjectPicker(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Dr. Smith")
+	thesisID := testDB.InsertTestProject(t, "Thesis Edit", clientID)
+	testDB.InsertTestProject(t, "Grant Proposal", clientID)
+
+	get := func(handler http.HandlerFunc, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	t.Run("search returns matching projects as JSON", func(t *testing.T) {
+		rr := get(app.projectSearch, "/projects/search?q=thesis")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+		var matches []models.ProjectMatch
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &matches))
+		require.Len(t, matches, 1)
+		assert.Equal(t, thesisID, matches[0].ID)
+		assert.Equal(t, "Dr. Smith", matches[0].ClientName)
+	})
+
+	t.Run("search with no matches returns an empty list", func(t *testing.T) {
+		rr := get(app.projectSearch, "/projects/search?q=nothing")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, "[]", rr.Body.String())
+	})
+
+	t.Run("picker page starts empty", func(t *testing.T) {
+		rr := get(app.timesheetQuickCreate, "/timesheet/create")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `action="/timesheet/create"`)
+		assert.NotContains(t, rr.Body.String(), "Thesis Edit")
+	})
+
+	t.Run("submitting a search lists the matches", func(t *testing.T) {
+		rr := get(app.invoiceQuickCreate, "/invoice/create?q=smith")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), fmt.Sprintf(`href="/project/%d/invoice/create">Thesis Edit (Dr. Smith)`, thesisID))
+		assert.Contains(t, rr.Body.String(), "Grant Proposal")
+	})
+
+	t.Run("picking a project goes to its create form", func(t *testing.T) {
+		rr := get(app.timesheetQuickCreate, fmt.Sprintf("/timesheet/create?q=Thesis&project_id=%d", thesisID))
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, fmt.Sprintf("/project/%d/timesheet/create", thesisID), rr.Header().Get("Location"))
+
+		rr = get(app.invoiceQuickCreate, fmt.Sprintf("/invoice/create?project_id=%d", thesisID))
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, fmt.Sprintf("/project/%d/invoice/create", thesisID), rr.Header().Get("Location"))
+	})
+}
+
+func TestPalette(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	ownerID, err := app.users.Insert(models.User{Name: "Olive Owner", Email: "owner@example.com", Role: models.RoleOwner}, "correct horse")
+	require.NoError(t, err)
+	owner, err := app.users.Get(ownerID)
+	require.NoError(t, err)
+
+	smithID := testDB.InsertTestClient(t, "Dr. Smith")
+	jonesID := testDB.InsertTestClient(t, "Prof. Jones")
+	thesisID := testDB.InsertTestProject(t, "Thesis Edit", smithID)
+	testDB.InsertTestProject(t, "Grant Proposal", jonesID)
+
+	asOwner := func(req *http.Request) *http.Request {
+		return req.WithContext(context.WithValue(req.Context(), authenticatedUserContextKey, &owner))
+	}
+	search := func(t *testing.T, q string) []paletteItem {
+		req := httptest.NewRequest(http.MethodGet, "/palette?q="+url.QueryEscape(q), nil)
+		rr := httptest.NewRecorder()
+		app.palette(rr, asOwner(req))
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var found struct {
+			Items []paletteItem `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &found))
+		return found.Items
+	}
+
+	t.Run("viewing clients and projects records visits", func(t *testing.T) {
+		view := func(handler http.HandlerFunc, target string, id int) {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			req.SetPathValue("id", strconv.Itoa(id))
+			rr := httptest.NewRecorder()
+			handler(rr, asOwner(req))
+			require.Equal(t, http.StatusOK, rr.Code)
+		}
+		view(app.projectView, fmt.Sprintf("/project/view/%d", thesisID), thesisID)
+		view(app.projectView, fmt.Sprintf("/project/view/%d", thesisID), thesisID)
+		view(app.clientView, fmt.Sprintf("/client/view/%d", jonesID), jonesID)
+
+		visits, err := app.visits.GetTop(ownerID, 10, time.Now())
+		require.NoError(t, err)
+		require.Len(t, visits, 2)
+		assert.Equal(t, "Thesis Edit", visits[0].Name)
+		assert.Equal(t, 2, visits[0].Count)
+		assert.Equal(t, "Prof. Jones", visits[1].Name)
+	})
+
+	t.Run("without a query the most visited come before every action", func(t *testing.T) {
+		items := search(t, "")
+		require.Len(t, items, 2+len(paletteActions))
+		assert.Equal(t, paletteItem{Kind: "project", Label: "Thesis Edit", Detail: "Dr. Smith", URL: fmt.Sprintf("/project/view/%d", thesisID)}, items[0])
+		assert.Equal(t, paletteItem{Kind: "client", Label: "Prof. Jones", URL: fmt.Sprintf("/client/view/%d", jonesID)}, items[1])
+		assert.Equal(t, "action", items[2].Kind)
+		assert.Equal(t, "Log time", items[2].Label)
+	})
+
+	t.Run("a query matches actions, then visits, then everything else", func(t *testing.T) {
+		items := search(t, "s")
+		require.NotEmpty(t, items)
+		assert.Equal(t, "action", items[0].Kind)
+
+		var labels []string
+		for _, item := range items {
+			if item.Kind != "action" {
+				labels = append(labels, item.Label)
+			}
+		}
+		assert.Equal(t, []string{"Thesis Edit", "Prof. Jones", "Dr. Smith", "Grant Proposal"}, labels, "visited first and nothing twice")
+	})
+
+	t.Run("a query with no matches returns an empty list", func(t *testing.T) {
+		assert.Empty(t, search(t, "nothing like this"))
+	})
+
+	t.Run("links include the base path", func(t *testing.T) {
+		app.basePath = "/tracker"
+		defer func() { app.basePath = "" }()
+		items := search(t, "thesis")
+		require.Len(t, items, 1)
+		assert.Equal(t, fmt.Sprintf("/tracker/project/view/%d", thesisID), items[0].URL)
+	})
+}
+
+func TestUserSessions(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+	app.sessionManager = scs.New()
+	app.sessionManager.Cookie.Persist = false
+	app.sessionLifetime = time.Hour
+	app.rememberMeFor = 30 * 24 * time.Hour
+
+	annID, err := app.users.Insert(models.User{Name: "Ann Owner", Email: "ann@example.com", Role: models.RoleOwner}, "correct horse")
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /user/login", app.userLogin)
+	mux.HandleFunc("POST /user/login", app.userLoginPost)
+	mux.HandleFunc("POST /user/logout", app.userLogoutPost)
+	mux.HandleFunc("GET /user/sessions", app.userSessionsList)
+	mux.HandleFunc("POST /user/sessions/logout-others", app.userSessionsLogoutOthersPost)
+	handler := app.sessionManager.LoadAndSave(app.authenticate(mux))
+
+	serve := func(method, path string, form url.Values, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("User-Agent", "Browser "+form.Get("browser"))
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	login := func(browser string, rememberMe bool) *http.Cookie {
+		form := url.Values{"email": {"ann@example.com"}, "password": {"correct horse"}, "browser": {browser}}
+		if rememberMe {
+			form.Set("remember_me", "true")
+		}
+		rr := serve(http.MethodPost, "/user/login", form, nil)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		cookies := rr.Result().Cookies()
+		require.Len(t, cookies, 1)
+		return cookies[0]
+	}
+
+	t.Run("the login form offers to remember the login", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/user/login", url.Values{}, nil)
+		assert.Contains(t, rr.Body.String(), "Stay logged in for 30 days")
+	})
+
+	laptop := login("laptop", true)
+	phone := login("phone", false)
+
+	t.Run("only a remembered login keeps its cookie when the browser closes", func(t *testing.T) {
+		assert.False(t, laptop.Expires.IsZero())
+		assert.True(t, phone.Expires.IsZero())
+	})
+
+	t.Run("logins are listed with the current one marked", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/user/sessions", url.Values{}, []*http.Cookie{laptop})
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Browser laptop (this device)")
+		assert.Contains(t, rr.Body.String(), `<div class="session">Browser phone</div>`)
+	})
+
+	t.Run("a login unused for longer than the session lifetime is logged out", func(t *testing.T) {
+		tablet := login("tablet", false)
+		sessions, err := app.userSessions.GetByUser(annID, time.Now())
+		require.NoError(t, err)
+		for _, session := range sessions {
+			if session.UserAgent == "Browser tablet" {
+				require.NoError(t, app.userSessions.Touch(session.ID, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)))
+			}
+		}
+
+		rr := serve(http.MethodGet, "/user/sessions", url.Values{}, []*http.Cookie{tablet})
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("logging out other sessions leaves this one logged in", func(t *testing.T) {
+		rr := serve(http.MethodPost, "/user/sessions/logout-others", url.Values{}, []*http.Cookie{laptop})
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		rr = serve(http.MethodGet, "/user/sessions", url.Values{}, []*http.Cookie{laptop})
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Logged out of 1 other session")
+		assert.NotContains(t, rr.Body.String(), "Browser phone")
+
+		rr = serve(http.MethodGet, "/user/sessions", url.Values{}, []*http.Cookie{phone})
+		assert.Equal(t, http.StatusNotFound, rr.Code, "the phone was logged out")
+	})
+
+	t.Run("logging out ends the login", func(t *testing.T) {
+		rr := serve(http.MethodPost, "/user/logout", url.Values{}, []*http.Cookie{laptop})
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		sessions, err := app.userSessions.GetByUser(annID, time.Now())
+		require.NoError(t, err)
+		assert.Empty(t, sessions)
+	})
+}
+
+func TestUserPreferences(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	editorID, err := app.users.Insert(models.User{Name: "Ed Itor", Email: "editor@example.com", Role: models.RoleSubcontractor}, "correct horse")
+	require.NoError(t, err)
+	editor, err := app.users.Get(editorID)
+	require.NoError(t, err)
+
+	// asEditor logs the editor in the way the authenticate middleware does
+	asEditor := func(req *http.Request) *http.Request {
+		preferences, err := app.preferences.Get(editorID)
+		require.NoError(t, err)
+		ctx := context.WithValue(req.Context(), authenticatedUserContextKey, &editor)
+		ctx = context.WithValue(ctx, userPreferencesContextKey, preferences)
+		return req.WithContext(ctx)
+	}
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/user/preferences", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		app.userPreferencesEditPost(rr, asEditor(req))
+		return rr
+	}
+
+	t.Run("not available until someone is logged in", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.userPreferencesEdit(rr, httptest.NewRequest(http.MethodGet, "/user/preferences", nil))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("subcontractors are only offered pages they can see", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.userPreferencesEdit(rr, asEditor(httptest.NewRequest(http.MethodGet, "/user/preferences", nil)))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `value="/projects"`)
+		assert.NotContains(t, rr.Body.String(), `value="/reports/margins"`)
+	})
+
+	t.Run("invalid preferences are rejected", func(t *testing.T) {
+		form := url.Values{}
+		form.Add("page_size", "500")
+		form.Add("landing_page", "/reports/margins")
+		form.Add("date_format", "YYYY/DD/MM")
+		form.Add("theme", "neon")
+
+		rr := post(form)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Items per page must be a whole number from 1 to 100")
+		assert.Contains(t, rr.Body.String(), "Choose one of the listed pages")
+		assert.Contains(t, rr.Body.String(), "Date format must be one of")
+		assert.Contains(t, rr.Body.String(), "Choose one of the listed themes")
+	})
+
+	t.Run("saved preferences override the global settings", func(t *testing.T) {
+		form := url.Values{}
+		form.Add("page_size", "3")
+		form.Add("landing_page", "/projects")
+		form.Add("date_format", string(models.DateFormatEuropean))
+		form.Add("theme", models.ThemeDark)
+
+		rr := post(form)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/user/preferences", rr.Header().Get("Location"))
+
+		req := asEditor(httptest.NewRequest(http.MethodGet, "/user/preferences", nil))
+		assert.Equal(t, 3, app.pageSize(req))
+		assert.Equal(t, models.DateFormatEuropean, app.dateFormat(req))
+
+		rr = httptest.NewRecorder()
+		app.userPreferencesEdit(rr, req)
+		assert.Contains(t, rr.Body.String(), `data-theme="dark"`)
+	})
+
+	t.Run("blank preferences fall back to the global settings", func(t *testing.T) {
+		rr := post(url.Values{})
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+
+		req := asEditor(httptest.NewRequest(http.MethodGet, "/user/preferences", nil))
+		globalReq := httptest.NewRequest(http.MethodGet, "/", nil)
+		assert.Equal(t, app.pageSize(globalReq), app.pageSize(req))
+		assert.Equal(t, app.dateFormat(globalReq), app.dateFormat(req))
+	})
+}
+
+func TestInvoiceTemplateHelp(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	t.Run("lists the template's functions and fields with sample values", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.invoiceTemplateHelp(rr, httptest.NewRequest(http.MethodGet, "/settings/invoice-template/help", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		body := rr.Body.String()
+		assert.Contains(t, body, "mul func(float64, float64) float64")
+		assert.Contains(t, body, ".Invoice.InvoiceNumber = INV-2024-0001")
+		assert.Contains(t, body, ".Timesheets[].Description = Chapter 1 copyedit")
+	})
+
+	t.Run("sample invoice can be framed by the help page", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.invoiceTemplateSample(rr, httptest.NewRequest(http.MethodGet, "/settings/invoice-template/sample", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/html; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Equal(t, "sameorigin", rr.Header().Get("X-Frame-Options"))
+		assert.Contains(t, rr.Header().Get("Content-Security-Policy"), "frame-ancestors 'self'")
+		assert.Contains(t, rr.Body.String(), "INV-2024-0001")
+	})
+}
+
+func TestInvoiceHTML(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	projectID := testDB.InsertTestProject(t, "Book", testDB.InsertTestClient(t, "Printed Client"))
+	invoiceID := testDB.InsertTestInvoice(t, projectID, "2024-03-01", "", "Net 30", "1234.50")
+
+	view := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/invoice/html/"+id, nil)
+		req.SetPathValue("id", id)
+		rr := httptest.NewRecorder()
+		app.invoiceHTML(rr, req)
+		return rr
+	}
+
+	t.Run("renders the invoice template for the browser", func(t *testing.T) {
+		rr := view(strconv.Itoa(invoiceID))
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/html; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Security-Policy"), "default-src 'none'")
+		body := rr.Body.String()
+		assert.Contains(t, body, "Printed Client")
+		assert.Contains(t, body, "1234.50")
+		assert.Contains(t, body, "@media screen")
+	})
+
+	t.Run("viewing keeps the first printing like the PDF does", func(t *testing.T) {
+		data, err := app.invoices.GetComprehensiveForPDF(invoiceID)
+		require.NoError(t, err)
+		assert.NotNil(t, data.Snapshot)
+	})
+
+	t.Run("missing invoices are not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, view("99999").Code)
+		assert.Equal(t, http.StatusNotFound, view("abc").Code)
+	})
+}
+
+func TestReceivablesForecast(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	projectID := testDB.InsertTestProject(t, "Book", testDB.InsertTestClient(t, "Overdue Client"))
+	testDB.InsertTestInvoice(t, projectID, "2024-01-01", "", "Net 30", "150.00")
+
+	rr := httptest.NewRecorder()
+	app.receivablesForecast(rr, httptest.NewRequest(http.MethodGet, "/reports/forecast", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	body := rr.Body.String()
+	now := time.Now()
+	thisWeek := now.AddDate(0, 0, -(int(now.Weekday())+6)%7).Format("2006-01-02")
+	assert.Contains(t, body, thisWeek+" 150.00 Overdue Client", "overdue invoices are expected this week")
+	assert.Contains(t, body, `<div class="total">150.00</div>`)
+}
+
+func TestWorkInProgressReport(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	projectID := testDB.InsertTestProject(t, "Book", testDB.InsertTestClient(t, "Acme"))
+	workDate := time.Now().AddDate(0, 0, -10).Format("2006-01-02")
+	testDB.InsertTestTimesheet(t, projectID, workDate, "2.5", "80.00", "Editing")
+
+	rr := httptest.NewRecorder()
+	app.workInProgressReport(rr, httptest.NewRequest(http.MethodGet, "/reports/wip", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	body := rr.Body.String()
+	assert.Contains(t, body, `<div class="project">Book 1 200.00 10</div>`)
+	assert.Contains(t, body, `<div class="total">200.00</div>`)
+}
+
+func TestTrendsReport(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	projectID := testDB.InsertTestProject(t, "Book", testDB.InsertTestClient(t, "Acme"))
+	today := time.Now().Format("2006-01-02")
+	testDB.InsertTestTimesheet(t, projectID, today, "2.5", "80.00", "Editing")
+	testDB.InsertTestInvoice(t, projectID, today, today, "Net 30", "150.00")
+
+	rr := httptest.NewRecorder()
+	app.trendsReport(rr, httptest.NewRequest(http.MethodGet, "/reports/trends", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	body := rr.Body.String()
+	assert.Contains(t, body, `<div class="measure">Hours logged 2.50 2.50 2.50</div>`)
+	assert.Contains(t, body, `<div class="measure">Work logged 200.00 200.00 200.00</div>`)
+	assert.Contains(t, body, `<div class="measure">Invoiced 150.00 150.00 150.00</div>`)
+	assert.Contains(t, body, `<div class="measure">Payments received 150.00 150.00 150.00</div>`)
+}
+
+func TestClientValuesReport(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	testDB.InsertTestInvoice(t, testDB.InsertTestProject(t, "Book", testDB.InsertTestClient(t, "Acme")), "2024-01-01", "2024-01-20", "Net 30", "150.00")
+	testDB.InsertTestInvoice(t, testDB.InsertTestProject(t, "Thesis", testDB.InsertTestClient(t, "Zenith")), "2024-01-01", "", "Net 30", "900.00")
+
+	rr := httptest.NewRecorder()
+	app.clientValuesReport(rr, httptest.NewRequest(http.MethodGet, "/reports/clients", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	body := rr.Body.String()
+	assert.Contains(t, body, `<div class="sort">billed</div>`)
+	assert.Less(t, strings.Index(body, "Zenith 1 900.00 0.00"), strings.Index(body, "Acme 1 150.00 150.00"), "the largest billed client comes first")
+	assert.Contains(t, body, `<div class="total">1050.00</div>`)
+
+	rr = httptest.NewRecorder()
+	app.clientValuesReport(rr, httptest.NewRequest(http.MethodGet, "/reports/clients?sort=paid", nil))
+	body = rr.Body.String()
+	assert.Contains(t, body, `<div class="sort">paid</div>`)
+	assert.Less(t, strings.Index(body, "Acme"), strings.Index(body, "Zenith"))
+}
+
+func TestExportFull(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	// The test schema isn't built by migrations, so apply a harmless one to give it a version
+	app.migrationsDir = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(app.migrationsDir, "007_create_scratch.sql"),
+		[]byte("-- +goose Up\nCREATE TABLE scratch (id INTEGER);\n\n-- +goose Down\nDROP TABLE scratch;\n"), 0o644))
+	_, err := app.migrate(database.MigrateOptions{})
+	require.NoError(t, err)
+
+	clientID := testDB.InsertTestClient(t, "Acme")
+	testDB.InsertTestProject(t, "Book", clientID)
+
+	t.Run("downloads everything as JSON", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.exportFull(rr, httptest.NewRequest(http.MethodGet, "/export/full", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), `filename="freelance-tracker-export-`)
+
+		var export models.Export
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &export))
+		assert.Equal(t, int64(7), export.SchemaVersion)
+		require.Len(t, export.Tables["client"], 1)
+		assert.Equal(t, "Acme", export.Tables["client"][0]["name"])
+		assert.Len(t, export.Tables["project"], 1)
+	})
+
+	t.Run("downloads a ZIP of one file per table", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.exportFull(rr, httptest.NewRequest(http.MethodGet, "/export/full?format=zip", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/zip", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), `.zip"`)
+
+		archive, err := zip.NewReader(bytes.NewReader(rr.Body.Bytes()), int64(rr.Body.Len()))
+		require.NoError(t, err)
+		assert.Len(t, archive.File, len(models.ExportTables)+1)
+	})
+}
+
+func TestEntityExport(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Acme")
+	projectID := testDB.InsertTestProject(t, "Book", clientID)
+	testDB.InsertTestTimesheet(t, projectID, "2024-03-04", "2.5", "40.00", "Copyediting")
+	invoiceID := testDB.InsertTestInvoice(t, projectID, "2024-03-31", "", "Net 30", "100.00")
+
+	download := func(handler http.HandlerFunc, id int) entityExport {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "attachment")
+
+		var export entityExport
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &export))
+		assert.Equal(t, ENTITY_EXPORT_VERSION, export.Version)
+		return export
+	}
+
+	t.Run("client with its projects and their children", func(t *testing.T) {
+		export := download(app.clientExport, clientID)
+		assert.Equal(t, "client", export.Type)
+		require.NotNil(t, export.Client)
+		assert.Nil(t, export.Project)
+		assert.Equal(t, "Acme", export.Client.Name)
+		require.Len(t, export.Client.Projects, 1)
+		project := export.Client.Projects[0]
+		assert.Equal(t, "Book", project.Name)
+		require.Len(t, project.Timesheets, 1)
+		assert.Equal(t, "2024-03-04", project.Timesheets[0].WorkDate)
+		assert.Equal(t, 100.0, project.Timesheets[0].Amount)
+		require.Len(t, project.Invoices, 1)
+		assert.Equal(t, "unpaid", project.Invoices[0].Status)
+		assert.Nil(t, project.Invoices[0].Totals, "totals are only given when the invoice itself is exported")
+	})
+
+	t.Run("project", func(t *testing.T) {
+		export := download(app.projectExport, projectID)
+		assert.Equal(t, "project", export.Type)
+		require.NotNil(t, export.Project)
+		assert.Equal(t, clientID, export.Project.ClientID)
+		assert.Len(t, export.Project.Timesheets, 1)
+		assert.NotNil(t, export.Project.Milestones)
+	})
+
+	t.Run("invoice with its totals and timesheets", func(t *testing.T) {
+		export := download(app.invoiceExport, invoiceID)
+		assert.Equal(t, "invoice", export.Type)
+		require.NotNil(t, export.Invoice)
+		assert.Equal(t, "2024-03-31", export.Invoice.InvoiceDate)
+		require.NotNil(t, export.Invoice.Totals)
+		assert.Equal(t, 100.0, export.Invoice.Totals.Total)
+		assert.Len(t, export.Invoice.Timesheets, 1)
+	})
+
+	t.Run("missing entities", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", "999")
+		rr := httptest.NewRecorder()
+		app.invoiceExport(rr, req)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+// fakeMailer records the messages it is asked to send, failing them when err is set
+type fakeMailer struct {
+	sent []mailer.Message
+	err  error
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg mailer.Message) (string, error) {
+	if m.err != nil {
+		return "550 5.1.1 User unknown", m.err
+	}
+	m.sent = append(m.sent, msg)
+	return "250 2.0.0 Ok: queued as 4Hx2", nil
+}
+
+func TestInvoiceEmail(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	mail := &fakeMailer{}
+	app.mailer = mail
+	clientID := testDB.InsertTestClient(t, "Mail Client")
+	projectID := testDB.InsertTestProject(t, "Mail Project", clientID)
+	invoiceID := testDB.InsertTestInvoice(t, projectID, "2024-05-01", "", "Net 30", "100.00")
+	id := strconv.Itoa(invoiceID)
+
+	post := func(values url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", id)
+		rr := httptest.NewRecorder()
+		app.invoiceEmailPost(rr, req)
+		return rr
+	}
+	values := url.Values{"to": {"ap@client.test, billing@client.test"}, "subject": {"Invoice 1"}, "message": {"Please pay <soon>"}}
+
+	t.Run("form is prefilled for the client", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", id)
+		rr := httptest.NewRecorder()
+		app.invoiceEmail(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `name="subject" value="Invoice #`)
+		assert.Contains(t, rr.Body.String(), "Sent 0, failed 0")
+	})
+
+	t.Run("an SMTP server and valid recipients are required", func(t *testing.T) {
+		rr := post(values)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Set an SMTP server in settings")
+
+		require.NoError(t, app.settings.UpdateValue("smtp_host", "smtp.example.test"))
+		rr = post(url.Values{"to": {"ap@client.test, nobody"}, "subject": {"Invoice 1"}})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "nobody is not a valid email address")
+	})
+
+	t.Run("sending is queued and logged", func(t *testing.T) {
+		require.NoError(t, app.settings.UpdateValue("email_open_tracking", "true"))
+		rr := post(values)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/invoice/email/"+id, rr.Header().Get("Location"))
+
+		job, err := app.jobs.ClaimNext()
+		require.NoError(t, err)
+		assert.Equal(t, models.JobInvoiceEmail, job.Kind)
+		var params invoiceEmailJobPayload
+		require.NoError(t, json.Unmarshal([]byte(job.Payload), &params))
+		assert.Equal(t, []string{"ap@client.test", "billing@client.test"}, params.To)
+		assert.Equal(t, "http://example.com/email/open/", params.TrackingURL)
+
+		// Rendering a real PDF needs Chrome, so the email is sent with a stand-in
+		attachments, err := app.invoiceEmailAttachments(params, []byte("%PDF"))
+		require.NoError(t, err)
+		require.NoError(t, app.sendInvoiceEmail(context.Background(), params, attachments))
+		require.Len(t, mail.sent, 1)
+		msg := mail.sent[0]
+		assert.Equal(t, "Invoice 1", msg.Subject)
+		require.Len(t, msg.Attachments, 1)
+		assert.Equal(t, "application/pdf", msg.Attachments[0].ContentType)
+		assert.Contains(t, msg.HTML, "Please pay &lt;soon&gt;")
+
+		deliveries, err := app.deliveries.GetByInvoice(invoiceID)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.True(t, deliveries[0].IsSent())
+		assert.Equal(t, "250 2.0.0 Ok: queued as 4Hx2", deliveries[0].SMTPResponse)
+		require.NotEmpty(t, deliveries[0].TrackingToken)
+		assert.Contains(t, msg.HTML, `src="http://example.com/email/open/`+deliveries[0].TrackingToken+`"`)
+
+		mail.err = errors.New("recipient refused")
+		assert.Error(t, app.sendInvoiceEmail(context.Background(), params, attachments))
+		mail.err = nil
+	})
+
+	t.Run("opening the email is recorded", func(t *testing.T) {
+		deliveries, err := app.deliveries.GetByInvoice(invoiceID)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+		assert.False(t, deliveries[0].IsSent())
+		assert.Equal(t, "550 5.1.1 User unknown", deliveries[0].SMTPResponse)
+
+		for _, token := range []string{deliveries[1].TrackingToken, "unknown"} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.SetPathValue("token", token)
+			rr := httptest.NewRecorder()
+			app.emailOpen(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "image/gif", rr.Header().Get("Content-Type"))
+			_, err := gif.Decode(bytes.NewReader(rr.Body.Bytes()))
+			assert.NoError(t, err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", id)
+		rr := httptest.NewRecorder()
+		app.invoiceEmail(rr, req)
+		assert.Contains(t, rr.Body.String(), "Sent 1, failed 1, opened 1")
+	})
+}
+
+func TestInvoiceReminders(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	app.mailer = &fakeMailer{}
+	recentID := testDB.InsertTestInvoice(t, testDB.InsertTestProject(t, "Recent Project", testDB.InsertTestClient(t, "Recent Client")), "2024-05-01", "", "Net 30", "100.00")
+	silentClientID := testDB.InsertTestClient(t, "Silent Client")
+	_, err := testDB.DB.Exec("UPDATE client SET email = '' WHERE id = ?", silentClientID)
+	require.NoError(t, err)
+	silentID := testDB.InsertTestInvoice(t, testDB.InsertTestProject(t, "Silent Project", silentClientID), "2024-05-01", "", "Net 30", "200.00")
+	currentID := testDB.InsertTestInvoice(t, testDB.InsertTestProject(t, "Current Project", testDB.InsertTestClient(t, "Current Client")), time.Now().Format("2006-01-02"), "", "Net 30", "300.00")
+
+	_, err = app.deliveries.Record(models.InvoiceDelivery{InvoiceID: recentID, Recipients: "test@example.com", Subject: "Invoice", Status: models.DeliveryStatusSent})
+	require.NoError(t, err)
+
+	post := func(values url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/invoices/remind", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		app.invoiceRemindersPost(rr, req)
+		return rr
+	}
+
+	t.Run("overdue invoices are listed with those emailed recently marked", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/invoices/overdue", nil)
+		rr := httptest.NewRecorder()
+		app.overdueInvoices(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		body := rr.Body.String()
+		assert.Contains(t, body, "Recent Client 100.00 recent")
+		assert.Contains(t, body, "Silent Client 200.00<")
+		assert.NotContains(t, body, "Current Client", "invoices not yet due are left out")
+	})
+
+	t.Run("an SMTP server and at least one invoice are required", func(t *testing.T) {
+		rr := post(url.Values{})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Set an SMTP server in settings")
+		assert.Contains(t, rr.Body.String(), "Choose at least one invoice")
+	})
+
+	t.Run("reminders are sent by a job that reports on each invoice", func(t *testing.T) {
+		require.NoError(t, app.settings.UpdateValue("smtp_host", "smtp.example.test"))
+		picked := []int{recentID, silentID, currentID, 9999}
+		values := url.Values{}
+		for _, id := range picked {
+			values.Add("invoice", strconv.Itoa(id))
+		}
+		rr := post(values)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		job, err := app.jobs.ClaimNext()
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("/job/view/%d", job.ID), rr.Header().Get("Location"))
+		assert.Equal(t, models.JobInvoiceReminders, job.Kind)
+		var params invoiceRemindersJobPayload
+		require.NoError(t, json.Unmarshal([]byte(job.Payload), &params))
+		assert.Equal(t, picked, params.InvoiceIDs)
+
+		result, err := app.invoiceRemindersJob(context.Background(), []byte(job.Payload))
+		require.NoError(t, err)
+		summary := string(result.Data)
+		assert.True(t, strings.HasPrefix(summary, "Sent 0, failed 1, skipped 3\n"), summary)
+		assert.Contains(t, summary, "to Recent Client: skipped, reminded recently on ")
+		assert.Contains(t, summary, "to Silent Client: failed, the client has no email address")
+		assert.Contains(t, summary, "to Current Client: skipped, it isn't overdue")
+		assert.Contains(t, summary, "Invoice 9999: skipped, it no longer exists")
+	})
+
+	t.Run("a reminder interval of zero reminds regardless", func(t *testing.T) {
+		require.NoError(t, app.settings.UpdateValue("invoice_reminder_interval_days", "0"))
+		defer app.settings.UpdateValue("invoice_reminder_interval_days", "7")
+
+		outcome, detail := app.sendInvoiceReminder(context.Background(), recentID, "", app.reminderInterval(), models.DateFormatISO)
+		assert.NotEqual(t, reminderSkipped, outcome, detail)
+	})
+}
+
+func TestProjectAttachments(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+	app.uploads = storage.Local{Dir: t.TempDir()}
+
+	mail := &fakeMailer{}
+	app.mailer = mail
+	require.NoError(t, app.settings.UpdateValue("smtp_host", "smtp.example.test"))
+	clientID := testDB.InsertTestClient(t, "Receipt Client")
+	projectID := testDB.InsertTestProject(t, "Receipt Project", clientID)
+	otherProjectID := testDB.InsertTestProject(t, "Other Project", clientID)
+	invoiceID := testDB.InsertTestInvoice(t, projectID, "2024-05-31", "", "Net 30", "100.00")
+
+	upload := func(projectID int, filename string, data []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile("attachment", filename)
+		require.NoError(t, err)
+		_, err = part.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/", &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+		app.projectAttachmentCreatePost(rr, req)
+		return rr
+	}
+	email := func(values url.Values) *httptest.ResponseRecorder {
+		values.Set("to", "ap@client.test")
+		values.Set("subject", "Invoice with receipts")
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(invoiceID))
+		rr := httptest.NewRecorder()
+		app.invoiceEmailPost(rr, req)
+		return rr
+	}
+	receipt := append([]byte("%PDF-1.4\n"), make([]byte, 2048)...)
+
+	t.Run("receipts are uploaded and downloaded", func(t *testing.T) {
+		rr := upload(projectID, "Taxi receipt.pdf", receipt)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, fmt.Sprintf("/project/view/%d", projectID), rr.Header().Get("Location"))
+
+		attachments, err := app.projectAttachments.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, attachments, 1)
+		assert.Equal(t, "Taxi receipt.pdf", attachments[0].Filename)
+		assert.Equal(t, "application/pdf", attachments[0].ContentType)
+		assert.Equal(t, int64(len(receipt)), attachments[0].Size)
+		assert.Equal(t, app.uploads.Dir, filepath.Dir(attachments[0].Path))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(attachments[0].ID))
+		rr = httptest.NewRecorder()
+		app.projectAttachmentDownload(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/pdf", rr.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="Taxi receipt.pdf"`, rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, receipt, rr.Body.Bytes())
+	})
+
+	t.Run("only small PDFs and images are accepted", func(t *testing.T) {
+		upload(projectID, "notes.txt", []byte("plain text"))
+		upload(projectID, "huge.pdf", append([]byte("%PDF-1.4\n"), make([]byte, maxProjectAttachmentBytes)...))
+
+		attachments, err := app.projectAttachments.GetByProject(projectID)
+		require.NoError(t, err)
+		assert.Len(t, attachments, 1)
+	})
+
+	t.Run("chosen attachments are sent with the invoice", func(t *testing.T) {
+		attachments, err := app.projectAttachments.GetByProject(projectID)
+		require.NoError(t, err)
+		id := strconv.Itoa(attachments[0].ID)
+
+		rr := email(url.Values{"attachment": {id}})
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		job, err := app.jobs.ClaimNext()
+		require.NoError(t, err)
+		var params invoiceEmailJobPayload
+		require.NoError(t, json.Unmarshal([]byte(job.Payload), &params))
+		assert.Equal(t, []int{attachments[0].ID}, params.AttachmentIDs)
+		assert.False(t, params.TimesheetReport)
+
+		files, err := app.invoiceEmailAttachments(params, []byte("%PDF"))
+		require.NoError(t, err)
+		require.NoError(t, app.sendInvoiceEmail(context.Background(), params, files))
+		require.Len(t, mail.sent, 1)
+		sent := mail.sent[0].Attachments
+		require.Len(t, sent, 2)
+		assert.Equal(t, fmt.Sprintf("invoice_%d.pdf", invoiceID), sent[0].Filename)
+		assert.Equal(t, "Taxi receipt.pdf", sent[1].Filename)
+		assert.Equal(t, receipt, sent[1].Data)
+	})
+
+	t.Run("attachments must belong to the project and fit in an email", func(t *testing.T) {
+		other, err := app.projectAttachments.Insert(models.ProjectAttachment{ProjectID: otherProjectID, Filename: "other.pdf", ContentType: "application/pdf", Size: 10, Path: filepath.Join(app.uploads.Dir, "other.pdf")})
+		require.NoError(t, err)
+		rr := email(url.Values{"attachment": {strconv.Itoa(other)}})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Attachments must belong to the invoice&#39;s project")
+
+		var ids []string
+		for i := 0; i < 2; i++ {
+			id, err := app.projectAttachments.Insert(models.ProjectAttachment{ProjectID: projectID, Filename: "scan.png", ContentType: "image/png", Size: 10 << 20, Path: filepath.Join(app.uploads.Dir, "scan.png")})
+			require.NoError(t, err)
+			ids = append(ids, strconv.Itoa(id))
+		}
+		rr = email(url.Values{"attachment": ids})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Attachments total 20.0 MB, more than the 18.0 MB an email can carry")
+
+		rr = email(url.Values{"timesheet_report": {"true"}})
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "There are no timesheets on this invoice to report")
+	})
+
+	t.Run("an email too large to send is logged as failed", func(t *testing.T) {
+		params := invoiceEmailJobPayload{InvoiceID: invoiceID, From: "me@example.test", To: []string{"ap@client.test"}, Subject: "Too big"}
+		files := []mailer.Attachment{{Filename: "invoice.pdf", ContentType: "application/pdf", Data: make([]byte, maxEmailAttachmentBytes+1)}}
+		err := app.sendInvoiceEmail(context.Background(), params, files)
+		require.Error(t, err)
+		assert.Len(t, mail.sent, 1, "nothing more was sent")
+
+		deliveries, err := app.deliveries.GetByInvoice(invoiceID)
+		require.NoError(t, err)
+		require.NotEmpty(t, deliveries)
+		assert.False(t, deliveries[0].IsSent())
+		assert.Contains(t, deliveries[0].SMTPResponse, "more than the 18.0 MB an email can carry")
+	})
+
+	t.Run("the timesheet report covers the invoice's timesheets", func(t *testing.T) {
+		testDB.InsertTestTimesheet(t, projectID, "2024-05-20", "1.0", "50.00", "Late")
+		testDB.InsertTestTimesheet(t, projectID, "2024-05-02", "1.0", "50.00", "Early")
+		invoice, err := app.invoices.Get(invoiceID)
+		require.NoError(t, err)
+
+		start, end, ok, err := app.invoiceTimesheetPeriod(invoice)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, "2024-05-02", start.Format("2006-01-02"))
+		assert.Equal(t, "2024-05-20", end.Format("2006-01-02"))
+	})
+
+	t.Run("deleting an attachment removes its file", func(t *testing.T) {
+		attachments, err := app.projectAttachments.GetByProject(projectID)
+		require.NoError(t, err)
+		uploaded := attachments[len(attachments)-1]
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(uploaded.ID))
+		rr := httptest.NewRecorder()
+		app.projectAttachmentDelete(rr, req)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		_, err = app.projectAttachments.Get(uploaded.ID)
+		assert.ErrorIs(t, err, models.ErrNoRecord)
+		_, err = os.Stat(uploaded.Path)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestDiskSpaceChecks(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+	app.uploads = storage.Local{Dir: t.TempDir()}
+	app.dataDir = t.TempDir()
+	var free uint64
+	app.freeSpace = func(dir string) (uint64, error) { return free, nil }
+
+	clientID := testDB.InsertTestClient(t, "Disk Client")
+	projectID := testDB.InsertTestProject(t, "Disk Project", clientID)
+	invoiceID := testDB.InsertTestInvoice(t, projectID, "2024-05-31", "", "Net 30", "100.00")
+
+	upload := func(field string, handler http.HandlerFunc, data []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile(field, "upload.png")
+		require.NoError(t, err)
+		_, err = part.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/", &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	var logo bytes.Buffer
+	require.NoError(t, png.Encode(&logo, image.NewRGBA(image.Rect(0, 0, 200, 80))))
+
+	t.Run("uploads are refused when the disk is nearly full", func(t *testing.T) {
+		free = 50 << 20
+
+		rr := upload("attachment", app.projectAttachmentCreatePost, logo.Bytes())
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		attachments, err := app.projectAttachments.GetByProject(projectID)
+		require.NoError(t, err)
+		assert.Empty(t, attachments)
+
+		rr = upload("logo", app.settingsLogoPost, logo.Bytes())
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "there isn&#39;t enough free disk space")
+
+		entries, err := os.ReadDir(app.uploads.Dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "nothing is written")
+	})
+
+	t.Run("PDFs are refused when the disk is nearly full", func(t *testing.T) {
+		free = 50 << 20
+
+		_, err := app.invoicePDF(invoiceID)
+		assert.ErrorIs(t, err, storage.ErrDiskFull)
+		_, _, err = app.timesheetReportPDF(timesheetReportJobPayload{ProjectID: projectID, Start: "2024-05-01", End: "2024-05-31"})
+		assert.ErrorIs(t, err, storage.ErrDiskFull)
+	})
+
+	t.Run("thresholds come from settings", func(t *testing.T) {
+		free = 50 << 20
+		require.NoError(t, app.settings.UpdateValue("disk_space_min_mb", "10"))
+		defer app.settings.UpdateValue("disk_space_min_mb", "100")
+
+		rr := upload("attachment", app.projectAttachmentCreatePost, logo.Bytes())
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		attachments, err := app.projectAttachments.GetByProject(projectID)
+		require.NoError(t, err)
+		assert.Len(t, attachments, 1, "low space only warns above the minimum")
+
+		low, err := app.checkDiskSpace(0)
+		require.NoError(t, err)
+		require.NotNil(t, low)
+		assert.Contains(t, low.String(), "only 50 MB of disk space is left")
+	})
+}
+
+func TestClientApprovals(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Approving Client")
+	projectID := testDB.InsertTestProject(t, "Approved Project", clientID)
+	testDB.InsertTestTimesheet(t, projectID, "2024-05-02", "3.00", "100.00", "Design review")
+	testDB.InsertTestTimesheet(t, projectID, "2024-05-20", "1.50", "100.00", "Fixes")
+	testDB.InsertTestTimesheet(t, projectID, "2024-06-03", "2.00", "100.00", "Next month")
+
+	create := func(projectID int, start, end string) *httptest.ResponseRecorder {
+		form := url.Values{"start": {start}, "end": {end}}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr := httptest.NewRecorder()
+		app.clientApprovalCreatePost(rr, req)
+		return rr
+	}
+	approve := func(token, name string) *httptest.ResponseRecorder {
+		form := url.Values{"name": {name}}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = "203.0.113.9:41000"
+		req.SetPathValue("token", token)
+		rr := httptest.NewRecorder()
+		app.clientApprovalPost(rr, req)
+		return rr
+	}
+	view := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("token", token)
+		rr := httptest.NewRecorder()
+		app.clientApprovalView(rr, req)
+		return rr
+	}
+	remove := func(id int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		app.clientApprovalDelete(rr, req)
+		return rr
+	}
+
+	var approval models.ClientApproval
+	t.Run("a link is created for a range of dates", func(t *testing.T) {
+		rr := create(projectID, "2024-05-20", "2024-05-01")
+		require.Equal(t, http.StatusSeeOther, rr.Code, "dates running backwards are turned away")
+		rr = create(projectID, "", "2024-05-31")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		approvals, err := app.approvals.GetByProject(projectID)
+		require.NoError(t, err)
+		assert.Empty(t, approvals)
+
+		rr = create(projectID, "2024-05-01", "2024-05-31")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, fmt.Sprintf("/project/view/%d", projectID), rr.Header().Get("Location"))
+
+		approvals, err = app.approvals.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, approvals, 1)
+		approval = approvals[0]
+		assert.Equal(t, "2024-05-01", approval.Start.Format("2006-01-02"))
+		assert.Equal(t, "2024-05-31", approval.End.Format("2006-01-02"))
+		assert.False(t, approval.Approved())
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(projectID))
+		rr = httptest.NewRecorder()
+		app.projectView(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "/approve/"+approval.Token+" waiting")
+	})
+
+	t.Run("the client sees the time in range without the owner's navigation", func(t *testing.T) {
+		rr := view(approval.Token)
+		require.Equal(t, http.StatusOK, rr.Code)
+		body := rr.Body.String()
+		assert.Contains(t, body, "Design review")
+		assert.Contains(t, body, "Fixes")
+		assert.NotContains(t, body, "Next month")
+		assert.Contains(t, body, `<p class="total">4.50</p>`)
+		assert.NotContains(t, body, "Owner links")
+
+		assert.Equal(t, http.StatusNotFound, view("not-a-token").Code)
+	})
+
+	t.Run("approving needs a name and records who, when and where from", func(t *testing.T) {
+		rr := approve(approval.Token, "  ")
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Enter your name to approve the time")
+
+		rr = approve(approval.Token, "Pat Client")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/approve/"+approval.Token, rr.Header().Get("Location"))
+
+		approved, err := app.approvals.Get(approval.ID)
+		require.NoError(t, err)
+		require.True(t, approved.Approved())
+		assert.Equal(t, "Pat Client", approved.ApprovedBy)
+		assert.Equal(t, "203.0.113.9", approved.ApprovedIP)
+		assert.Contains(t, view(approval.Token).Body.String(), `<p class="approved">Pat Client</p>`)
+
+		rr = approve(approval.Token, "Someone Else")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		approved, err = app.approvals.Get(approval.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Pat Client", approved.ApprovedBy, "a second approval changes nothing")
+	})
+
+	t.Run("only links not yet approved can be withdrawn", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, remove(approval.ID).Code)
+
+		rr := create(projectID, "2024-06-01", "2024-06-30")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		approvals, err := app.approvals.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, approvals, 2)
+		unused := approvals[0]
+
+		rr = remove(unused.ID)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		_, err = app.approvals.Get(unused.ID)
+		assert.ErrorIs(t, err, models.ErrNoRecord)
+		assert.Equal(t, http.StatusNotFound, view(unused.Token).Code)
+	})
+}
+
+func TestConsolidatedInvoice(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Monthly Client")
+	thesis := testDB.InsertTestProject(t, "Thesis", clientID)
+	article := testDB.InsertTestProject(t, "Article", clientID)
+	testDB.InsertTestTimesheet(t, thesis, "2024-05-02", "2.0", "50.00", "Chapter 1")
+	testDB.InsertTestTimesheet(t, article, "2024-05-10", "1.0", "40.00", "Abstract")
+	testDB.InsertTestTimesheet(t, thesis, "2024-06-03", "1.0", "50.00", "Chapter 2")
+
+	post := func(month string) *httptest.ResponseRecorder {
+		values := url.Values{"month": {month}}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(clientID))
+		rr := httptest.NewRecorder()
+		app.consolidatedInvoicePost(rr, req)
+		return rr
+	}
+
+	t.Run("a month's time on every project goes on one invoice", func(t *testing.T) {
+		rr := post("2024-05")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoices, err := app.invoices.GetByProject(article)
+		require.NoError(t, err)
+		require.Len(t, invoices, 1)
+		invoice := invoices[0]
+		assert.Equal(t, fmt.Sprintf("/invoice/update/%d", invoice.ID), rr.Header().Get("Location"))
+		assert.Equal(t, 140.0, invoice.AmountDue)
+		require.NotNil(t, invoice.TimesheetsFrom)
+		assert.Equal(t, "2024-05-01", invoice.TimesheetsFrom.Format("2006-01-02"))
+		require.NotNil(t, invoice.TimesheetsTo)
+		assert.Equal(t, "2024-05-31", invoice.TimesheetsTo.Format("2006-01-02"))
+
+		projects, err := app.consolidated.GetProjects(invoice.ID)
+		require.NoError(t, err)
+		require.Len(t, projects, 2)
+		assert.Equal(t, "Article", projects[0].ProjectName)
+		assert.Equal(t, 100.0, projects[1].Amount)
+	})
+
+	t.Run("a month already invoiced has nothing left to bill", func(t *testing.T) {
+		rr := post("2024-05")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, fmt.Sprintf("/client/view/%d", clientID), rr.Header().Get("Location"))
+
+		rr = post("May 2024")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("the monthly job bills clients flagged for it", func(t *testing.T) {
+		payload := []byte(`{"month":"2024-06"}`)
+		result, err := app.consolidatedInvoicesJob(context.Background(), payload)
+		require.NoError(t, err)
+		assert.Equal(t, "No unbilled time\n", string(result.Data))
+
+		_, err = testDB.DB.Exec("UPDATE client SET consolidated_invoicing = true WHERE id = ?", clientID)
+		require.NoError(t, err)
+		result, err = app.consolidatedInvoicesJob(context.Background(), payload)
+		require.NoError(t, err)
+		assert.Contains(t, string(result.Data), "Monthly Client")
+
+		invoices, err := app.invoices.GetByProject(thesis)
+		require.NoError(t, err)
+		require.Len(t, invoices, 1)
+		assert.Equal(t, 50.0, invoices[0].AmountDue)
+	})
+}
+
+func TestAgingReportJob(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	projectID := testDB.InsertTestProject(t, "Aged Project", testDB.InsertTestClient(t, "Aged Client"))
+	overdueID := testDB.InsertTestInvoice(t, projectID, "2024-01-01", "", "Net 30", "250.00")
+	testDB.InsertTestInvoice(t, projectID, "2024-05-20", "", "Net 30", "100.00")
+	payload, err := json.Marshal(agingReportPayload(time.Date(2024, 6, 1, 6, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+
+	require.NoError(t, app.settings.UpdateValue("freelancer_email", "me@example.com"))
+	require.NoError(t, app.settings.UpdateValue("freelancer_name", "Jo Freelancer"))
+
+	t.Run("nothing is sent without an SMTP server", func(t *testing.T) {
+		result, err := app.agingReportJob(context.Background(), payload)
+		require.NoError(t, err)
+		assert.Contains(t, string(result.Data), "No SMTP server is set")
+	})
+
+	mail := &fakeMailer{}
+	app.mailer = mail
+
+	t.Run("the summary is emailed with the detail attached", func(t *testing.T) {
+		result, err := app.agingReportJob(context.Background(), payload)
+		require.NoError(t, err)
+		assert.Equal(t, "aging_2024-06-01.csv", result.Filename)
+
+		require.Len(t, mail.sent, 1)
+		msg := mail.sent[0]
+		assert.Equal(t, []string{`"Jo Freelancer" <me@example.com>`}, msg.To)
+		assert.Equal(t, "Invoice aging as of 2024-06-01", msg.Subject)
+		assert.Contains(t, msg.Text, "Current             $100.00  1 invoice")
+		assert.Contains(t, msg.Text, "Over 90 days        $250.00  1 invoice")
+		assert.Contains(t, msg.Text, "Total               $350.00  2 invoices")
+
+		require.Len(t, msg.Attachments, 1)
+		assert.Equal(t, "aging_2024-06-01.csv", msg.Attachments[0].Filename)
+		assert.Equal(t, result.Data, msg.Attachments[0].Data)
+		assert.Contains(t, string(msg.Attachments[0].Data), fmt.Sprintf("Over 90 days,%04d,Aged Client,Aged Project,2024-01-01,2024-01-31,122,250.00", overdueID))
+	})
+
+	t.Run("a failed send fails the job", func(t *testing.T) {
+		mail.err = errors.New("connection refused")
+		_, err := app.agingReportJob(context.Background(), payload)
+		assert.Error(t, err)
+	})
+}
+
+func TestReminders(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	var messages []string
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		messages = append(messages, body["text"])
+	}))
+	defer server.Close()
+
+	clientID := testDB.InsertTestClient(t, "Reminder Client")
+	projectID := testDB.InsertTestProject(t, "Reminder Project", clientID)
+	invoiceID := testDB.InsertTestInvoice(t, projectID, "2024-01-01", "", "Net 30", "250.00")
+	project, err := app.projects.Get(projectID)
+	require.NoError(t, err)
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	project.Deadline = &tomorrow
+	require.NoError(t, app.projects.Update(project))
+
+	t.Run("nothing is sent until a chat app is set up", func(t *testing.T) {
+		result, err := app.remindersJob(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Contains(t, string(result.Data), "No Slack webhook or Telegram bot")
+
+		req := httptest.NewRequest(http.MethodPost, "/settings/notifications/test", nil)
+		rr := httptest.NewRecorder()
+		app.settingsNotifyTestPost(rr, req)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Empty(t, messages)
+	})
+
+	require.NoError(t, app.settings.UpdateValue("slack_webhook_url", server.URL+"/services/hook"))
+
+	t.Run("a test alert is sent", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/settings/notifications/test", nil)
+		rr := httptest.NewRecorder()
+		app.settingsNotifyTestPost(rr, req)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+		require.Len(t, messages, 1)
+		assert.Contains(t, messages[0], "test alert")
+		messages = nil
+	})
+
+	t.Run("a failed reminder is tried again", func(t *testing.T) {
+		failing = true
+		_, err := app.remindersJob(context.Background(), nil)
+		assert.Error(t, err)
+		failing = false
+	})
+
+	t.Run("overdue invoices and deadlines are reminded of once", func(t *testing.T) {
+		result, err := app.remindersJob(context.Background(), nil)
+		require.NoError(t, err)
+		require.Len(t, messages, 2)
+		assert.Contains(t, messages[0], fmt.Sprintf("Invoice #%04d to Reminder Client", invoiceID))
+		assert.Contains(t, messages[0], "250.00 was due on 2024-01-31")
+		assert.Contains(t, messages[1], "Reminder Project for Reminder Client is due tomorrow")
+		assert.Contains(t, string(result.Data), "Reminder Project")
+
+		result, err = app.remindersJob(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Len(t, messages, 2)
+		assert.Equal(t, "Nothing to remind of\n", string(result.Data))
+	})
+}
+
+func TestContentNegotiation(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "JSON Client")
+	projectID := testDB.InsertTestProject(t, "JSON Project", clientID)
+	testDB.InsertTestTimesheet(t, projectID, "2024-03-01", "2.00", "50.00", "Drafting")
+
+	get := func(handler http.HandlerFunc, target, accept string, id int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept", accept)
+		req.SetPathValue("id", strconv.Itoa(id))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	t.Run("accept header decides between JSON and HTML", func(t *testing.T) {
+		tests := map[string]bool{
+			"application/json":                  true,
+			"application/json, text/plain, */*": true,
+			"text/html;q=0.5, application/json": true,
+			"":                                  false,
+			"*/*":                               false,
+			"text/html,application/xhtml+xml,*/*;q=0.8": false,
+			"text/html, application/json":               false,
+			"application/json;q=0":                      false,
+		}
+		for accept, want := range tests {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", accept)
+			assert.Equal(t, want, wantsJSON(req), accept)
+		}
+	})
+
+	t.Run("list page as JSON", func(t *testing.T) {
+		rr := get(app.home, "/", "application/json", 0)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		assert.Equal(t, "Accept", rr.Header().Get("Vary"))
+
+		var data struct {
+			Clients    []models.Client
+			Pagination *paginationData
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &data))
+		require.Len(t, data.Clients, 1)
+		assert.Equal(t, "JSON Client", data.Clients[0].Name)
+		require.NotNil(t, data.Pagination)
+		assert.Equal(t, 1, data.Pagination.CurrentPage)
+
+		var fields map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &fields))
+		assert.NotContains(t, fields, "CurrentYear")
+		assert.NotContains(t, fields, "Invoices", "fields the handler didn't set are left out")
+	})
+
+	t.Run("view pages as JSON", func(t *testing.T) {
+		rr := get(app.projectView, "/project/view/1", "application/json", projectID)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var data struct {
+			Project    models.Project
+			Timesheets []models.Timesheet
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &data))
+		assert.Equal(t, "JSON Project", data.Project.Name)
+		require.Len(t, data.Timesheets, 1)
+		assert.Equal(t, "Drafting", data.Timesheets[0].Description)
+
+		rr = get(app.clientView, "/client/view/1", "application/json", clientID)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"Name":"JSON Client"`)
+	})
+
+	t.Run("browsers get HTML", func(t *testing.T) {
+		rr := get(app.home, "/", "text/html,application/xhtml+xml,*/*;q=0.8", 0)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "<h1>Clients</h1>")
+	})
+
+	t.Run("subcontractors always get HTML", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "application/json")
+		rr := httptest.NewRecorder()
+		app.render(rr, req, http.StatusOK, "home.html", templateData{IsSubcontractor: true})
+		assert.Contains(t, rr.Body.String(), "<h1>Clients</h1>")
+	})
+}
+
+func TestTimesheetLock(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	clientID := testDB.InsertTestClient(t, "Lock Client")
+	projectID := testDB.InsertTestProject(t, "Lock Project", clientID)
+	billedID := testDB.InsertTestTimesheet(t, projectID, "2024-03-10", "2.00", "50.00", "Billed work")
+	unbilledID := testDB.InsertTestTimesheet(t, projectID, "2024-04-05", "1.00", "50.00", "April work")
+
+	invoiceID, err := app.invoices.Insert(projectID, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), nil, "Net 30", 100.00, true)
+	require.NoError(t, err)
+	from, to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, app.invoices.SetTimesheetRange(invoiceID, &from, &to))
+
+	subID, err := app.users.Insert(models.User{Name: "Sub Contractor", Email: "sub@example.com", Role: models.RoleSubcontractor}, "correct horse")
+	require.NoError(t, err)
+	sub, err := app.users.Get(subID)
+	require.NoError(t, err)
+	require.NoError(t, app.users.ShareProject(projectID, subID))
+	require.NoError(t, app.timesheets.SetUser(billedID, subID))
+
+	post := func(handler http.HandlerFunc, id int, form url.Values, user *models.User) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", strconv.Itoa(id))
+		if user != nil {
+			req = req.WithContext(context.WithValue(req.Context(), authenticatedUserContextKey, user))
+		}
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	entry := func(hours string) url.Values {
+		return url.Values{"work_date": {"2024-03-10"}, "hours_worked": {hours}, "hourly_rate": {"50.00"}, "description": {"Billed work"}}
+	}
+
+	t.Run("only invoiced entries are locked", func(t *testing.T) {
+		locked, err := app.timesheets.GetLockedIDs(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]bool{billedID: true}, locked)
+	})
+
+	t.Run("update form shows the invoice", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", strconv.Itoa(billedID))
+		rr := httptest.NewRecorder()
+		app.timesheetUpdate(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Invoice #0001")
+	})
+
+	t.Run("changes need the lock overridden", func(t *testing.T) {
+		rr := post(app.timesheetUpdatePost, billedID, entry("3.00"), nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "This entry has been invoiced")
+
+		rr = post(app.timesheetDelete, billedID, url.Values{}, nil)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		timesheet, err := app.timesheets.Get(billedID)
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, timesheet.HoursWorked)
+	})
+
+	t.Run("subcontractors can't override the lock", func(t *testing.T) {
+		form := entry("3.00")
+		form.Set("override_lock", "true")
+		rr := post(app.timesheetUpdatePost, billedID, form, &sub)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		rr = post(app.timesheetDelete, billedID, url.Values{"override_lock": {"true"}}, &sub)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("overriding the lock flags the invoice", func(t *testing.T) {
+		form := entry("3.00")
+		form.Set("override_lock", "true")
+		rr := post(app.timesheetUpdatePost, billedID, form, nil)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		timesheet, err := app.timesheets.Get(billedID)
+		require.NoError(t, err)
+		assert.Equal(t, 3.0, timesheet.HoursWorked)
+
+		invoice, err := app.invoices.Get(invoiceID)
+		require.NoError(t, err)
+		assert.True(t, invoice.NeedsRegeneration())
+	})
+
+	t.Run("saving the invoice clears the flag", func(t *testing.T) {
+		form := url.Values{"invoice_date": {"2024-04-01"}, "amount_due": {"150.00"}, "payment_terms": {"Net 30"},
+			"timesheets_from": {"2024-03-01"}, "timesheets_to": {"2024-03-31"}, "acknowledge_warnings": {"true"}}
+		rr := post(app.invoiceUpdatePost, invoiceID, form, nil)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		invoice, err := app.invoices.Get(invoiceID)
+		require.NoError(t, err)
+		assert.False(t, invoice.NeedsRegeneration())
+	})
+
+	t.Run("unbilled entries are deleted without an override", func(t *testing.T) {
+		rr := post(app.timesheetDelete, unbilledID, url.Values{}, nil)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+	})
+
+	t.Run("voiding the invoice releases the lock", func(t *testing.T) {
+		require.NoError(t, app.invoices.Void(invoiceID, "Reissued"))
+
+		rr := post(app.timesheetDelete, billedID, url.Values{}, nil)
+		assert.Equal(t, http.StatusSeeOther, rr.Code)
+	})
+}
@@ -2,13 +2,37 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"html/template"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"mime"
+	"net"
 	"net/http"
+	"net/netip"
+	"path/filepath"
+	"reflect"
 	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-playground/form/v4"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/database"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/mailer"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/storage"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/validator"
 )
 
 func (app *application) serverError(resp http.ResponseWriter, req *http.Request, err error) {
@@ -26,7 +50,238 @@ func (app *application) clientError(resp http.ResponseWriter, status int) {
 	http.Error(resp, http.StatusText(status), status)
 }
 
+// modelError responds to an error from saving a model. Constraint failures and setting values
+// rejected by their rule are caused by the request rather than the server, so they get a 4xx
+// status and say what was wrong.
+func (app *application) modelError(resp http.ResponseWriter, req *http.Request, err error) {
+	var constraintErr *models.ConstraintError
+	var settingErr *models.SettingError
+	var filterErr *models.FilterError
+	switch {
+	case errors.Is(err, models.ErrNoRecord):
+		http.NotFound(resp, req)
+	case errors.As(err, &settingErr):
+		app.logger.Warn(err.Error(), "method", req.Method, "uri", req.URL.RequestURI())
+		http.Error(resp, settingErr.Message, http.StatusUnprocessableEntity)
+	case errors.As(err, &filterErr):
+		app.logger.Warn(err.Error(), "method", req.Method, "uri", req.URL.RequestURI())
+		http.Error(resp, filterErr.Error(), http.StatusBadRequest)
+	case errors.Is(err, models.ErrInvoicePaid):
+		app.logger.Warn(err.Error(), "method", req.Method, "uri", req.URL.RequestURI())
+		http.Error(resp, "This invoice has been paid. Remove the payment to change its date or amount.", http.StatusConflict)
+	case errors.Is(err, models.ErrDiscountTooLarge):
+		app.logger.Warn(err.Error(), "method", req.Method, "uri", req.URL.RequestURI())
+		http.Error(resp, "The discount can't be more than the amount due.", http.StatusUnprocessableEntity)
+	case errors.Is(err, models.ErrCreditSpent):
+		app.logger.Warn(err.Error(), "method", req.Method, "uri", req.URL.RequestURI())
+		http.Error(resp, "Credit from this invoice's overpayment has been applied to other invoices. Delete or void those first.", http.StatusConflict)
+	case errors.As(err, &constraintErr):
+		status := http.StatusConflict
+		if errors.Is(err, models.ErrValidation) {
+			status = http.StatusUnprocessableEntity
+		}
+		app.logger.Warn(err.Error(), "method", req.Method, "uri", req.URL.RequestURI())
+		http.Error(resp, constraintErr.Message(), status)
+	default:
+		app.serverError(resp, req, err)
+	}
+}
+
+// redirect sends the client to a path within the application, allowing for the base path
+// it is mounted under
+func (app *application) redirect(resp http.ResponseWriter, req *http.Request, path string, status int) {
+	http.Redirect(resp, req, app.basePath+path, status)
+}
+
+// normalizeBasePath turns the -base-path flag into either "" or a path with a leading
+// slash and no trailing slash, ready to be prefixed to application paths
+func normalizeBasePath(basePath string) string {
+	basePath = strings.Trim(strings.TrimSpace(basePath), "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
+
+// parseTrustedProxies parses a comma separated list of IP addresses and CIDR ranges
+func parseTrustedProxies(list string) ([]netip.Prefix, error) {
+	var proxies []netip.Prefix
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+			}
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return proxies, nil
+}
+
+// isTrustedProxy reports whether an address belongs to one of the trusted proxies
+func (app *application) isTrustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range app.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// fromTrustedProxy reports whether the request came directly from a trusted proxy, so that
+// its X-Forwarded-* headers can be believed
+func (app *application) fromTrustedProxy(req *http.Request) bool {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	return app.isTrustedProxy(host)
+}
+
+// clientIP returns the address of the client, taken from X-Forwarded-For when the request
+// came through a trusted proxy. The list is read from the right, skipping other trusted
+// proxies, since anything further left could have been sent by the client itself.
+func (app *application) clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	if !app.isTrustedProxy(host) {
+		return host
+	}
+
+	forwarded := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+		if ip == "" {
+			continue
+		}
+		if !app.isTrustedProxy(ip) {
+			return ip
+		}
+		host = ip
+	}
+	return host
+}
+
+// requestScheme returns "https" or "http" for the request as the client made it
+func (app *application) requestScheme(req *http.Request) string {
+	if app.fromTrustedProxy(req) {
+		proto := strings.ToLower(strings.TrimSpace(req.Header.Get("X-Forwarded-Proto")))
+		if proto == "http" || proto == "https" {
+			return proto
+		}
+	}
+	if req.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// absoluteURL builds a full URL to a path within the application, for links that are
+// used outside the browser such as in emails
+func (app *application) absoluteURL(req *http.Request, path string) string {
+	host := req.Host
+	if app.fromTrustedProxy(req) {
+		if forwardedHost := strings.TrimSpace(req.Header.Get("X-Forwarded-Host")); forwardedHost != "" {
+			host = forwardedHost
+		}
+	}
+	return app.requestScheme(req) + "://" + host + app.basePath + path
+}
+
+// writeJSON sends data as a JSON response
+func (app *application) writeJSON(resp http.ResponseWriter, req *http.Request, status int, data any) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		app.serverError(resp, req, err)
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(status)
+	resp.Write(body)
+}
+
+// wantsJSON reports whether the request's Accept header prefers JSON to HTML. Wildcards
+// don't count, so browsers and clients that accept anything get HTML.
+func wantsJSON(req *http.Request) bool {
+	var jsonQuality, htmlQuality float64
+	for _, accepted := range strings.Split(req.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil {
+			quality = q
+		}
+		switch mediaType {
+		case "application/json":
+			jsonQuality = max(jsonQuality, quality)
+		case "text/html":
+			htmlQuality = max(htmlQuality, quality)
+		}
+	}
+	return jsonQuality > 0 && jsonQuality > htmlQuality
+}
+
+// apiError sends an error message as a JSON response
+func (app *application) apiError(resp http.ResponseWriter, status int, message string) {
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(status)
+	json.NewEncoder(resp).Encode(map[string]string{"error": message})
+}
+
+// background runs fn in a goroutine that is tracked by app.wg, logging rather than
+// crashing if it panics
+func (app *application) background(fn func()) {
+	app.wg.Add(1)
+	go func() {
+		defer app.wg.Done()
+		defer func() {
+			if err := recover(); err != nil {
+				app.logger.Error(fmt.Sprint(err))
+			}
+		}()
+		fn()
+	}()
+}
+
+// render writes a page, or for a request that asks for JSON the data the page would be
+// rendered with. Subcontractors always get the page, since its template leaves out the rates
+// and client details the data holds that they aren't allowed to see.
 func (app *application) render(resp http.ResponseWriter, req *http.Request, status int, page string, data templateData) {
+	resp.Header().Add("Vary", "Accept")
+	if wantsJSON(req) && !data.IsSubcontractor {
+		app.writeJSON(resp, req, status, data.jsonFields())
+		return
+	}
+
+	// A form's errors are also listed together above it, in the order of its fields
+	if form, ok := data.Form.(interface {
+		ErrorSummary(fields ...string) []validator.FieldError
+	}); ok {
+		data.ErrorSummary = form.ErrorSummary(formFields(data.Form)...)
+	}
+
 	ts, ok := app.templateCache[page]
 	if !ok {
 		err := fmt.Errorf("the template page %s does not exist", page)
@@ -49,10 +304,600 @@ func (app *application) render(resp http.ResponseWriter, req *http.Request, stat
 	}
 }
 
+// formFields returns the names a form's fields are posted under, in the order they are
+// declared, which is the order most forms show them in
+func formFields(form any) []string {
+	t := reflect.TypeOf(form)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("form"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
 func (app *application) newTemplateData(req *http.Request) templateData {
 	return templateData{
-		CurrentYear: time.Now().Year(),
+		CurrentYear:     time.Now().Year(),
+		CurrentUser:     app.currentUser(req),
+		IsSubcontractor: app.isSubcontractor(req),
+		DateFormat:      app.dateFormat(req),
+		Theme:           app.userPreferences(req).Theme,
+		Flash:           app.popFlash(req),
+		CSRFSecret:      app.csrfSecret(req),
+	}
+}
+
+// csrfSecret returns the session's key for signing form tokens, creating it the first time a
+// page needs one. Without a session manager, as in handler tests, there is no key.
+func (app *application) csrfSecret(req *http.Request) string {
+	if app.sessionManager == nil {
+		return ""
+	}
+	secret := app.sessionManager.GetString(req.Context(), "csrfSecret")
+	if secret == "" {
+		key := make([]byte, 32)
+		rand.Read(key)
+		secret = base64.RawURLEncoding.EncodeToString(key)
+		app.sessionManager.Put(req.Context(), "csrfSecret", secret)
+	}
+	return secret
+}
+
+// csrfToken signs the path a form posts to with the session's key, so that a token only
+// works for that one form and only in the session it was given to
+func csrfToken(secret, action string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(action))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// flash stores a confirmation message in the session to show on the page the user is
+// redirected to. Without a session manager, as in handler tests, the message is dropped.
+func (app *application) flash(req *http.Request, message string) {
+	if app.sessionManager == nil {
+		return
+	}
+	app.sessionManager.Put(req.Context(), "flash", message)
+}
+
+// popFlash returns the pending flash message, if any, and removes it from the session
+func (app *application) popFlash(req *http.Request) string {
+	if app.sessionManager == nil {
+		return ""
+	}
+	return app.sessionManager.PopString(req.Context(), "flash")
+}
+
+// userPreferences returns the logged in user's preferences, which are empty when nobody is
+// logged in or the user hasn't chosen any
+func (app *application) userPreferences(req *http.Request) models.UserPreferences {
+	preferences, _ := req.Context().Value(userPreferencesContextKey).(models.UserPreferences)
+	return preferences
+}
+
+// dateFormat returns the date format chosen by the user or else in settings, falling back to
+// ISO if it can't be read
+func (app *application) dateFormat(req *http.Request) models.DateFormat {
+	if preference := app.userPreferences(req).DateFormat; preference != "" {
+		return models.ParseDateFormat(preference)
+	}
+	value, err := app.settings.GetString("date_format")
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.logger.Warn("reading date_format setting", "error", err.Error())
+	}
+	return models.ParseDateFormat(value)
+}
+
+// descriptionLength returns the longest timesheet description that can be entered, from
+// settings, falling back to NAME_LENGTH if it can't be read
+func (app *application) descriptionLength() int {
+	length, err := app.settings.GetInt("timesheet_description_max_length")
+	if err != nil || length <= 0 {
+		if err != nil && !errors.Is(err, models.ErrNoRecord) {
+			app.logger.Warn("reading timesheet_description_max_length setting", "error", err.Error())
+		}
+		return NAME_LENGTH
+	}
+	return length
+}
+
+// timesheetWorkTypes returns the work types offered on a timesheet form, from the
+// timesheet_work_types setting, including current when the entry being updated has a work
+// type that has since been taken out of the setting
+func (app *application) timesheetWorkTypes(current string) []string {
+	setting, err := app.settings.GetString("timesheet_work_types")
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.logger.Warn("reading timesheet_work_types setting", "error", err.Error())
+	}
+	workTypes := models.ParseWorkTypes(setting)
+	if current != "" && !slices.Contains(workTypes, current) {
+		workTypes = append(workTypes, current)
+	}
+	return workTypes
+}
+
+// emailCheckTimeout bounds the DNS lookups made when a client is saved, so a slow resolver
+// doesn't hold up the form
+const emailCheckTimeout = 5 * time.Second
+
+// checkClientEmails warns about a client's email addresses whose domains can't receive mail,
+// so that a mistyped address is caught before an invoice sent to it bounces. Addresses that
+// already failed validation aren't looked up, nor is anything when the client_email_check
+// setting is off or there is no resolver. A lookup that fails, such as when there is no
+// network, is only logged.
+func (app *application) checkClientEmails(ctx context.Context, form *clientForm) {
+	if app.resolver == nil {
+		return
+	}
+	check, err := app.settings.GetBool("client_email_check")
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.logger.Warn("reading client_email_check setting", "error", err.Error())
+	}
+	if err == nil && !check {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, emailCheckTimeout)
+	defer cancel()
+	for _, field := range []struct{ name, label, address string }{
+		{"email", "Email", form.Email},
+		{"invoice_cc_email", "Invoice CC email", form.InvoiceCCEmail},
+	} {
+		if field.address == "" || form.FieldErrors[field.name] != "" {
+			continue
+		}
+		err := mailer.CheckDomain(ctx, app.resolver, field.address)
+		switch {
+		case errors.Is(err, mailer.ErrUndeliverable):
+			form.AddWarning(field.name, fmt.Sprintf("%s %s looks undeliverable: its domain can't receive mail", field.label, field.address))
+		case err != nil:
+			app.logger.Warn("checking email domain", "address", field.address, "error", err.Error())
+		}
+	}
+}
+
+// checkDiskSpace measures the disk space that would be left for the database and uploads
+// after writing need more bytes. It returns an error wrapping storage.ErrDiskFull when that
+// is below the disk_space_min_mb setting, so that the write can be refused before SQLite
+// runs out of room mid-transaction, and a warning when it is below disk_space_warn_mb.
+// Without app.freeSpace nothing is measured.
+func (app *application) checkDiskSpace(need int64) (*storage.LowSpace, error) {
+	if app.freeSpace == nil {
+		return nil, nil
+	}
+	quota := storage.Quota{
+		Warn:      app.diskSpaceSetting("disk_space_warn_mb", 1024) << 20,
+		Deny:      app.diskSpaceSetting("disk_space_min_mb", 100) << 20,
+		FreeSpace: app.freeSpace,
+	}
+	return quota.Check(uint64(max(need, 0)), app.dataDir, app.uploads.Dir)
+}
+
+// diskSpaceSetting reads a disk space threshold in MB from settings, falling back to
+// fallback if it can't be read
+func (app *application) diskSpaceSetting(key string, fallback uint64) uint64 {
+	mb, err := app.settings.GetInt(key)
+	if err != nil || mb < 0 {
+		if err != nil && !errors.Is(err, models.ErrNoRecord) {
+			app.logger.Warn("reading "+key+" setting", "error", err.Error())
+		}
+		return fallback
+	}
+	return uint64(mb)
+}
+
+// diskSpaceMessage explains to the user why a write was refused for lack of disk space
+func diskSpaceMessage(low *storage.LowSpace) string {
+	if low == nil {
+		return "there isn't enough free disk space"
+	}
+	return fmt.Sprintf("there isn't enough free disk space, %s", low)
+}
+
+// checkPDFDiskSpace checks there is room to generate a PDF and keep it with its job,
+// logging a warning when space is running low
+func (app *application) checkPDFDiskSpace() error {
+	low, err := app.checkDiskSpace(pdfSpaceNeeded)
+	if err != nil {
+		return fmt.Errorf("not generating the PDF: %w", err)
+	}
+	if low != nil {
+		app.logger.Warn("disk space is running low", "dir", low.Dir, "free_bytes", low.Free)
+	}
+	return nil
+}
+
+// reminderInterval returns how many days must pass after an invoice is emailed before a
+// reminder of it is sent, from settings, falling back to a week if it can't be read
+func (app *application) reminderInterval() int {
+	days, err := app.settings.GetInt("invoice_reminder_interval_days")
+	if err != nil || days < 0 {
+		if err != nil && !errors.Is(err, models.ErrNoRecord) {
+			app.logger.Warn("reading invoice_reminder_interval_days setting", "error", err.Error())
+		}
+		return 7
+	}
+	return days
+}
+
+// pageSize returns how many items list pages show, chosen by the user or else in settings
+func (app *application) pageSize(req *http.Request) int {
+	if preference := app.userPreferences(req).PageSize; preference != nil && *preference > 0 {
+		return *preference
+	}
+	pageSize := 10 // Default fallback
+	if pageSizeSetting, err := app.settings.GetString("list_page_size"); err == nil {
+		if ps, err := strconv.Atoi(pageSizeSetting); err == nil && ps > 0 {
+			pageSize = ps
+		}
+	}
+	return pageSize
+}
+
+// currentPage returns the page of a list asked for in the named query parameter, the first
+// when it is missing or invalid
+func currentPage(req *http.Request, param string) int {
+	if page, err := strconv.Atoi(req.URL.Query().Get(param)); err == nil && page > 0 {
+		return page
 	}
+	return 1
+}
+
+// newPagination describes page currentPage of a list of totalCount items shown pageSize at
+// a time
+func newPagination(currentPage, pageSize int, totalCount int64) *paginationData {
+	totalPages := int((totalCount + int64(pageSize) - 1) / int64(pageSize))
+	return &paginationData{
+		CurrentPage: currentPage,
+		TotalPages:  totalPages,
+		HasPrev:     currentPage > 1,
+		HasNext:     currentPage < totalPages,
+		PrevPage:    currentPage - 1,
+		NextPage:    currentPage + 1,
+		PageSize:    pageSize,
+	}
+}
+
+// newListPagination is newPagination for one of several lists on a page, each paged by its
+// own query parameter
+func newListPagination(req *http.Request, param string, currentPage, pageSize int, totalCount int64) *paginationData {
+	pagination := newPagination(currentPage, pageSize, totalCount)
+	query := req.URL.Query()
+	query.Del(param)
+	pagination.Param = param
+	pagination.Query = template.URL(query.Encode())
+	return pagination
+}
+
+// tagFilter returns the tag a list page is filtered on with ?tag=ID, or nil if it isn't
+// filtered. An ID that isn't a tag gives ErrNoRecord.
+func (app *application) tagFilter(req *http.Request) (*models.Tag, error) {
+	param := req.URL.Query().Get("tag")
+	if param == "" {
+		return nil, nil
+	}
+	id, err := strconv.Atoi(param)
+	if err != nil || id < 1 {
+		return nil, models.ErrNoRecord
+	}
+	tag, err := app.tags.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// listFilter reads the filter of a list page from its filter query parameters. Choosing a
+// view from the dropdown or adding a condition redirects to the list with the resulting filter
+// in the URL, so it can be bookmarked; ok is false once a response has been written.
+func (app *application) listFilter(res http.ResponseWriter, req *http.Request, list models.FilterList) (models.Filter, bool) {
+	query := req.URL.Query()
+	if query.Has("view") {
+		filter, err := models.ParseFilterQuery(query.Get("view"))
+		if err != nil {
+			app.modelError(res, req, err)
+			return nil, false
+		}
+		app.redirect(res, req, filterPath(list, filter), http.StatusSeeOther)
+		return nil, false
+	}
+
+	filter, err := models.ParseFilter(query["filter"])
+	if err != nil {
+		app.modelError(res, req, err)
+		return nil, false
+	}
+	if query.Has("field") {
+		filter = append(filter, models.FilterCondition{
+			Field:    query.Get("field"),
+			Operator: query.Get("op"),
+			Value:    strings.TrimSpace(query.Get("value")),
+		})
+		if err := list.Validate(filter); err != nil {
+			app.modelError(res, req, err)
+			return nil, false
+		}
+		app.redirect(res, req, filterPath(list, filter), http.StatusSeeOther)
+		return nil, false
+	}
+	return filter, true
+}
+
+// filterPath is the path of a list page showing the rows matching a filter
+func filterPath(list models.FilterList, filter models.Filter) string {
+	if len(filter) == 0 {
+		return list.Path
+	}
+	return list.Path + "?" + filter.Query()
+}
+
+// listFilterData describes the filter of a list page for its template, along with the quick
+// filters and the views the current user has saved of the list
+func (app *application) listFilterData(req *http.Request, list models.FilterList, filter models.Filter) (*listFilterData, error) {
+	saved, err := app.savedViews.GetForList(app.currentUserID(req), list.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	query := filter.Query()
+	data := &listFilterData{
+		List:   list,
+		Filter: filter,
+		Query:  template.URL(query),
+	}
+	for i, condition := range filter {
+		data.Conditions = append(data.Conditions, filterConditionData{
+			Label:       list.Describe(condition),
+			RemoveQuery: template.URL(filter.Without(i).Query()),
+		})
+	}
+	for _, quick := range list.QuickFilters {
+		viewQuery := quick.Filter.Query()
+		data.QuickViews = append(data.QuickViews, filterViewData{Name: quick.Name, Query: viewQuery, Selected: query != "" && viewQuery == query})
+	}
+	for _, view := range saved {
+		data.SavedViews = append(data.SavedViews, filterViewData{Name: view.Name, Query: view.Filter, Selected: query != "" && view.Filter == query})
+		if data.SavedView == nil && query != "" && view.Filter == query {
+			data.SavedView = &view
+		}
+	}
+	return data, nil
+}
+
+// currentUserID returns the ID of the logged in user, or nil if nobody is logged in
+func (app *application) currentUserID(req *http.Request) *int {
+	if user := app.currentUser(req); user != nil {
+		return &user.ID
+	}
+	return nil
+}
+
+// currentUser returns the logged in user, or nil if nobody is logged in
+func (app *application) currentUser(req *http.Request) *models.User {
+	user, _ := req.Context().Value(authenticatedUserContextKey).(*models.User)
+	return user
+}
+
+// recordVisit counts a visit by the logged in user to a client or project page, so the
+// command palette can offer the ones they use most. Failing to is logged rather than shown.
+func (app *application) recordVisit(req *http.Request, kind string, id int) {
+	user := app.currentUser(req)
+	if user == nil {
+		return
+	}
+	if err := app.visits.Record(user.ID, kind, id, time.Now()); err != nil {
+		app.logger.Error("recording visit", "kind", kind, "id", id, "error", err.Error())
+	}
+}
+
+// draftPath returns where the page script keeps its draft of a client or project form, with
+// id 0 for a form creating a new one
+func draftPath(form string, id int) string {
+	return fmt.Sprintf("/draft/%s/%d", form, id)
+}
+
+// discardDraft throws away the logged in user's draft of a form once the form has been
+// saved. Failing to is logged rather than shown, as the save itself succeeded.
+func (app *application) discardDraft(req *http.Request, form string, id int) {
+	user := app.currentUser(req)
+	if user == nil {
+		return
+	}
+	if err := app.drafts.Delete(user.ID, form, id); err != nil {
+		app.logger.Error("discarding form draft", "form", form, "id", id, "error", err.Error())
+	}
+}
+
+// sessionTouchInterval is how long a login goes between writes of its last use, so that
+// most requests don't have to write to the database
+const sessionTouchInterval = time.Minute
+
+// maxUserAgentLength is how much of a browser's user agent is kept with a login
+const maxUserAgentLength = 255
+
+// currentUserSession returns the login the request belongs to, if anyone is logged in
+func (app *application) currentUserSession(req *http.Request) (models.UserSession, bool) {
+	session, ok := req.Context().Value(userSessionContextKey).(models.UserSession)
+	return session, ok
+}
+
+// startUserSession records a login for the session just authenticated. A remembered login
+// keeps its cookie when the browser is closed and lasts its full term however little it is
+// used; any other ends with the browser or after the session lifetime without requests.
+func (app *application) startUserSession(req *http.Request, userID int, rememberMe bool) error {
+	if app.maintenance.Load() {
+		// The user_session table may not exist until the migrations have run
+		return nil
+	}
+
+	now := time.Now()
+	if err := app.userSessions.DeleteExpired(now); err != nil {
+		return err
+	}
+
+	lifetime := app.sessionLifetime
+	if rememberMe {
+		lifetime = app.rememberMeFor
+	}
+	userAgent := req.UserAgent()
+	if runes := []rune(userAgent); len(runes) > maxUserAgentLength {
+		userAgent = string(runes[:maxUserAgentLength])
+	}
+	session, err := app.userSessions.Start(models.UserSession{
+		UserID:     userID,
+		RememberMe: rememberMe,
+		UserAgent:  userAgent,
+		IPAddress:  app.clientIP(req),
+		LastSeen:   now,
+		ExpiresAt:  now.Add(lifetime),
+	})
+	if err != nil {
+		return err
+	}
+
+	app.sessionManager.RememberMe(req.Context(), rememberMe)
+	app.sessionManager.Put(req.Context(), "userSessionToken", session.Token)
+	return nil
+}
+
+// loadUserSession finds the login a request belongs to and, unless it was remembered, moves
+// its expiry along. It reports false when the login has expired or was logged out from
+// another session, and for sessions started before logins were recorded. Behind the
+// maintenance page, where the user_session table may not exist yet, every login is let through
+// so that an owner can follow the migrations; logins started then end once it is lifted.
+func (app *application) loadUserSession(req *http.Request, userID int) (models.UserSession, bool, error) {
+	if app.maintenance.Load() {
+		return models.UserSession{}, true, nil
+	}
+
+	token := app.sessionManager.GetString(req.Context(), "userSessionToken")
+	if token == "" {
+		return models.UserSession{}, false, nil
+	}
+	session, err := app.userSessions.GetByToken(token)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			return models.UserSession{}, false, nil
+		}
+		return models.UserSession{}, false, err
+	}
+
+	now := time.Now()
+	if session.UserID != userID {
+		return models.UserSession{}, false, nil
+	}
+	if session.Expired(now) {
+		return models.UserSession{}, false, app.userSessions.Delete(session.ID)
+	}
+
+	if now.Sub(session.LastSeen) >= sessionTouchInterval {
+		session.LastSeen = now
+		if !session.RememberMe {
+			session.ExpiresAt = now.Add(app.sessionLifetime)
+		}
+		if err := app.userSessions.Touch(session.ID, session.LastSeen, session.ExpiresAt); err != nil {
+			return models.UserSession{}, false, err
+		}
+	}
+	return session, true, nil
+}
+
+// describeLifetime writes a login lifetime the way the login form offers it, such as "30 days"
+func describeLifetime(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	case d >= 2*time.Hour:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	default:
+		return d.String()
+	}
+}
+
+// isSubcontractor reports whether the logged in user is a subcontractor with restricted access
+func (app *application) isSubcontractor(req *http.Request) bool {
+	user := app.currentUser(req)
+	return user != nil && !user.IsOwner()
+}
+
+// canAccessProject reports whether the current user may see a project. Owners, and anyone
+// while no accounts exist, can see every project; subcontractors only those shared with them.
+func (app *application) canAccessProject(req *http.Request, projectID int) (bool, error) {
+	user := app.currentUser(req)
+	if user == nil || user.IsOwner() {
+		return true, nil
+	}
+	return app.users.HasProjectAccess(projectID, user.ID)
+}
+
+// canEditTimesheet reports whether the current user may change a timesheet entry.
+// Subcontractors can only change their own entries on projects still shared with them.
+func (app *application) canEditTimesheet(req *http.Request, timesheet models.Timesheet) (bool, error) {
+	user := app.currentUser(req)
+	if user == nil || user.IsOwner() {
+		return true, nil
+	}
+	if timesheet.UserID == nil || *timesheet.UserID != user.ID {
+		return false, nil
+	}
+	return app.users.HasProjectAccess(timesheet.ProjectID, user.ID)
+}
+
+// timesheetLockingInvoices returns the invoices that bill a timesheet entry. A billed entry can
+// only be changed by an owner overriding the lock, which flags the invoices as needing to be
+// regenerated.
+func (app *application) timesheetLockingInvoices(id int) ([]models.Invoice, error) {
+	ids, err := app.timesheets.GetLockingInvoices(id)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := make([]models.Invoice, len(ids))
+	for i, invoiceID := range ids {
+		invoices[i], err = app.invoices.Get(invoiceID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return invoices, nil
+}
+
+// flagLockingInvoices marks the invoices billing a changed timesheet entry as needing to be
+// regenerated
+func flagLockingInvoices(tx models.TxModels, invoices []models.Invoice) error {
+	for _, invoice := range invoices {
+		err := tx.Invoices.FlagTimesheetsChanged(invoice.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// staleInvoicesNote names the invoices left needing regeneration by a change to a billed
+// timesheet entry, for adding to the message confirming the change
+func staleInvoicesNote(invoices []models.Invoice) string {
+	if len(invoices) == 0 {
+		return ""
+	}
+	numbers := make([]string, len(invoices))
+	for i, invoice := range invoices {
+		numbers[i] = "#" + invoice.DisplayNumber()
+	}
+	if len(numbers) == 1 {
+		return fmt.Sprintf("; invoice %s needs regenerating", numbers[0])
+	}
+	return fmt.Sprintf("; invoices %s need regenerating", strings.Join(numbers, ", "))
 }
 
 func (app *application) decodePostForm(r *http.Request, dst any) error {
@@ -72,3 +917,100 @@ func (app *application) decodePostForm(r *http.Request, dst any) error {
 	return nil
 
 }
+
+// Limits for an uploaded company logo. The invoice shows it 22.5mm wide, so anything larger
+// than a couple of thousand pixels only bloats the PDF.
+const (
+	maxLogoBytes     = 1024 * 1024
+	minLogoDimension = 16
+	maxLogoDimension = 2000
+)
+
+// checkLogoImage validates an uploaded logo and returns the file extension to store it with.
+// PNG and JPEG files must decode and fit the dimension limits; SVG files must be an svg document.
+func checkLogoImage(filename string, data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("Choose a logo to upload")
+	}
+	if len(data) > maxLogoBytes {
+		return "", fmt.Errorf("Logo must be smaller than %d KB", maxLogoBytes/1024)
+	}
+
+	if strings.EqualFold(filepath.Ext(filename), ".svg") {
+		if !isSVG(data) {
+			return "", errors.New("Logo is not a valid SVG image")
+		}
+		return ".svg", nil
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "png" && format != "jpeg") {
+		return "", errors.New("Logo must be a PNG, JPEG or SVG image")
+	}
+	if config.Width < minLogoDimension || config.Height < minLogoDimension ||
+		config.Width > maxLogoDimension || config.Height > maxLogoDimension {
+		return "", fmt.Errorf("Logo must be between %d and %d pixels wide and high, this one is %dx%d",
+			minLogoDimension, maxLogoDimension, config.Width, config.Height)
+	}
+
+	if format == "jpeg" {
+		return ".jpg", nil
+	}
+	return ".png", nil
+}
+
+// isSVG reports whether data is an XML document whose root element is svg
+func isSVG(data []byte) bool {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local == "svg"
+		}
+	}
+}
+
+// errMigrationsRunning is returned when migrations are started while others are still running
+var errMigrationsRunning = errors.New("migrations are already running")
+
+// migrate applies or rolls back migrations with the application in maintenance mode, so
+// that no request is served against a half migrated schema. Maintenance mode is lifted
+// afterwards only if the migrations succeeded and it was off beforehand or only on because
+// of an earlier rollback. A rollback leaves it on, since this binary expects the newer
+// schema, until the migrations are applied again. Dry runs change nothing and so run as
+// they are.
+func (app *application) migrate(opts database.MigrateOptions) ([]database.Migration, error) {
+	if opts.DryRun {
+		return database.Migrate(app.db, app.migrationsDir, opts)
+	}
+	if !app.migrating.TryLock() {
+		return nil, errMigrationsRunning
+	}
+	defer app.migrating.Unlock()
+
+	wasInMaintenance := app.maintenance.Swap(true)
+	migrations, err := database.Migrate(app.db, app.migrationsDir, opts)
+	if err != nil {
+		return migrations, err
+	}
+	if opts.Down > 0 {
+		app.rolledBack.Store(true)
+		return migrations, nil
+	}
+
+	rolledBack := app.rolledBack.Swap(false)
+	if !wasInMaintenance || rolledBack {
+		app.maintenance.Store(false)
+	}
+	return migrations, nil
+}
+
+// setReportPeriod fills in last month as the period timesheet report forms start out with
+func setReportPeriod(data *templateData, now time.Time) {
+	start, end := models.MonthRange(now.AddDate(0, 0, -now.Day()))
+	data.ReportStart = start.Format("2006-01-02")
+	data.ReportEnd = end.AddDate(0, 0, -1).Format("2006-01-02")
+}
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"flag"
 	"html/template"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
 	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/alexedwards/scs/sqlite3store"
@@ -13,45 +20,170 @@ import (
 	"github.com/go-playground/form/v4"
 
 	"github.com/paulboeck/FreelanceTrackerGo/internal/database"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/exchangerate"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/jobs"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/mailer"
 	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/notify"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/storage"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/webhook"
 )
 
 type application struct {
-	logger         *slog.Logger
-	clients        models.ClientModelInterface
-	projects       models.ProjectModelInterface
-	timesheets     models.TimesheetModelInterface
-	invoices       models.InvoiceModelInterface
-	settings       models.AppSettingModelInterface
-	templateCache  map[string]*template.Template
-	formDecoder    *form.Decoder
-	sessionManager *scs.SessionManager
+	logger             *slog.Logger
+	clients            models.ClientModelInterface
+	projects           models.ProjectModelInterface
+	timesheets         models.TimesheetModelInterface
+	invoices           models.InvoiceModelInterface
+	settings           models.AppSettingModelInterface
+	businessProfiles   models.BusinessProfileModelInterface
+	credits            models.ClientCreditModelInterface
+	clientNotes        models.ClientNoteModelInterface
+	users              models.UserModelInterface
+	preferences        models.UserPreferencesModelInterface
+	userSessions       models.UserSessionModelInterface
+	reports            models.ReportModelInterface
+	exports            models.ExportModelInterface
+	integrity          models.IntegrityModelInterface
+	invoiceEvents      models.InvoiceEventModelInterface
+	deliveries         models.InvoiceDeliveryModelInterface
+	consolidated       models.ConsolidatedInvoiceModelInterface
+	pendingTimesheets  models.PendingTimesheetModelInterface
+	services           models.ServiceModelInterface
+	milestones         models.MilestoneModelInterface
+	tags               models.TagModelInterface
+	savedViews         models.SavedViewModelInterface
+	listFilters        models.ListFilterModelInterface
+	paymentTerms       models.PaymentTermsPresetModelInterface
+	checklists         models.ChecklistModelInterface
+	notifications      models.NotificationModelInterface
+	jobs               models.JobModelInterface
+	exchangeRates      models.ExchangeRateModelInterface
+	projectDefaults    models.ProjectDefaultsModelInterface
+	projectAttachments models.ProjectAttachmentModelInterface
+	approvals          models.ClientApprovalModelInterface
+	visits             models.RecentVisitModelInterface
+	externalIDs        models.ExternalIDModelInterface
+	drafts             models.FormDraftModelInterface
+	scheduler          *jobs.Scheduler
+	queue              *jobs.Queue
+	webhooks           webhook.Sender
+	rateFetcher        exchangerate.Fetcher
+	mailer             mailer.Sender
+	resolver           mailer.Resolver
+	notifier           notify.Notifier
+	uploads            storage.Local
+	dataDir            string
+	freeSpace          func(dir string) (uint64, error)
+	transactions       models.TxManagerInterface
+	templateCache      map[string]*template.Template
+	formDecoder        *form.Decoder
+	sessionManager     *scs.SessionManager
+	sessionLifetime    time.Duration
+	rememberMeFor      time.Duration
+	basePath           string
+	trustedProxies     []netip.Prefix
+	db                 *sql.DB
+	queryStats         *database.QueryStats
+	migrationsDir      string
+	pdfUnavailable     error
+	maintenance        atomic.Bool
+	rolledBack         atomic.Bool
+	migrating          sync.Mutex
+	wg                 sync.WaitGroup
 }
 
 func main() {
 	addr := flag.String("addr", ":8080", "http service address")
 	dsn := flag.String("dsn", "./freelance_tracker.db", "SQLite database file path")
+	basePathFlag := flag.String("base-path", "", "URL path the application is mounted under behind a reverse proxy, e.g. /freelance")
+	trustedProxiesFlag := flag.String("trusted-proxies", "", "Comma separated IPs or CIDR ranges of proxies whose X-Forwarded-* headers are trusted")
+	uploadDir := flag.String("upload-dir", "./ui/static/uploads", "Directory where uploaded files such as the company logo and project attachments are stored")
+	maintenance := flag.Bool("maintenance", false, "Serve a maintenance page while migrations run in the background instead of waiting for them before listening")
+	migrateDryRun := flag.Bool("migrate-dry-run", false, "List the migrations that would run, or be rolled back with -migrate-down, and exit")
+	migrateDown := flag.Int("migrate-down", 0, "Roll back this many of the most recently applied migrations and exit")
+	maintain := flag.String("maintain", "", "Run these comma separated database maintenance tasks, from checkpoint, vacuum and analyze, and exit")
+	importPath := flag.String("import", "", "Load a full export, as downloaded from /export/full, into an empty database and exit")
+	sessionLifetime := flag.Duration("session-lifetime", 12*time.Hour, "How long a login lasts without any requests before it is logged out")
+	rememberMeFor := flag.Duration("remember-me", 30*24*time.Hour, "How long a login lasts when \"Remember me\" is ticked, however little it is used; 0 turns the option off")
+	slowQuery := flag.Duration("slow-query", 200*time.Millisecond, "Log database queries that take at least this long; 0 turns the log off")
 	flag.Parse()
 
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
+	basePath := normalizeBasePath(*basePathFlag)
+	trustedProxies, err := parseTrustedProxies(*trustedProxiesFlag)
+	if err != nil {
+		logger.Error("Invalid -trusted-proxies flag", "error", err.Error())
+		os.Exit(1)
+	}
+
 	// Open SQLite database
-	db, err := database.OpenDB(*dsn)
+	queryStats := &database.QueryStats{}
+	db, err := database.OpenInstrumentedDB(*dsn, database.Instrumentation{
+		Logger:    logger,
+		SlowQuery: *slowQuery,
+		Stats:     queryStats,
+	})
 	if err != nil {
 		logger.Error("Failed to open database", "error", err.Error())
 		os.Exit(1)
 	}
 	defer db.Close()
 
-	// Run migrations
-	if database.RunMigrations(db, "./migrations"); err != nil {
-		logger.Error("Failed to run migrations", "error", err.Error())
-		os.Exit(1)
+	const migrationsDir = "./migrations"
+
+	// Previewing or rolling back migrations is a one-off task run instead of the server
+	if *migrateDryRun || *migrateDown != 0 {
+		opts := database.MigrateOptions{DryRun: *migrateDryRun, Down: *migrateDown}
+		migrations, err := database.Migrate(db, migrationsDir, opts)
+		for _, m := range migrations {
+			logger.Info("Migration", "version", m.Version, "name", m.Name, "down", opts.Down > 0, "dryRun", opts.DryRun)
+		}
+		if err != nil {
+			logger.Error("Failed to run migrations", "error", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	// So is maintaining the database, reporting each task as it finishes
+	if *maintain != "" {
+		tasks, err := database.ParseMaintenanceTasks(*maintain)
+		if err != nil {
+			logger.Error("Invalid -maintain flag", "error", err.Error())
+			os.Exit(1)
+		}
+		_, err = database.Maintain(context.Background(), db, tasks, func(step database.MaintenanceStep) {
+			logger.Info("Database maintenance", "task", step.Task, "detail", step.Detail, "before", step.Before.Bytes, "after", step.After.Bytes, "duration", step.Duration)
+		})
+		if err != nil {
+			logger.Error("Failed to maintain the database", "error", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Importing an export is a one-off task too, run once the empty database is migrated
+	if *importPath != "" {
+		if err := importExport(db, migrationsDir, *importPath, logger); err != nil {
+			logger.Error("Failed to import", "file", *importPath, "error", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Run migrations before listening, unless they are to run behind the maintenance page
+	if !*maintenance {
+		if err := database.RunMigrations(db, migrationsDir); err != nil {
+			logger.Error("Failed to run migrations", "error", err.Error())
+			os.Exit(1)
+		}
 	}
 
 	logger.Info("Database initialized", "dsn", *dsn)
 
-	templateCache, err := newTemplateCache()
+	templateCache, err := newTemplateCache(basePath)
 	if err != nil {
 		logger.Error("Failed to create template cache", "error", err.Error())
 		os.Exit(1)
@@ -60,7 +192,14 @@ func main() {
 
 	sessionManager := scs.New()
 	sessionManager.Store = sqlite3store.New(db)
-	sessionManager.Lifetime = 12 * time.Hour
+	// Logins expire as recorded in the user_session table. The session cookie only outlives the
+	// browser for logins that asked to be remembered, so scs just needs to keep sessions as long
+	// as the longest login can last.
+	sessionManager.Lifetime = max(*sessionLifetime, *rememberMeFor)
+	sessionManager.Cookie.Persist = false
+	if basePath != "" {
+		sessionManager.Cookie.Path = basePath
+	}
 
 	// Create SQLite models
 	clientModel := models.NewClientModel(db)
@@ -68,21 +207,133 @@ func main() {
 	timesheetModel := models.NewTimesheetModel(db)
 	invoiceModel := models.NewInvoiceModel(db)
 	settingModel := models.NewAppSettingModel(db)
+	businessProfileModel := models.NewBusinessProfileModel(db)
+	creditModel := models.NewClientCreditModel(db)
+	clientNoteModel := models.NewClientNoteModel(db)
+	userModel := models.NewUserModel(db)
+	userPreferencesModel := models.NewUserPreferencesModel(db)
+	userSessionModel := models.NewUserSessionModel(db)
+	reportModel := models.NewReportModel(db)
+	exportModel := models.NewExportModel(db)
+	integrityModel := models.NewIntegrityModel(db)
+	invoiceEventModel := models.NewInvoiceEventModel(db)
+	deliveryModel := models.NewInvoiceDeliveryModel(db)
+	consolidatedInvoiceModel := models.NewConsolidatedInvoiceModel(db)
+	pendingTimesheetModel := models.NewPendingTimesheetModel(db)
+	serviceModel := models.NewServiceModel(db)
+	milestoneModel := models.NewMilestoneModel(db)
+	tagModel := models.NewTagModel(db)
+	savedViewModel := models.NewSavedViewModel(db)
+	listFilterModel := models.NewListFilterModel(db)
+	paymentTermsModel := models.NewPaymentTermsPresetModel(db)
+	checklistModel := models.NewChecklistModel(db)
+	notificationModel := models.NewNotificationModel(db)
+	jobModel := models.NewJobModel(db)
+	exchangeRateModel := models.NewExchangeRateModel(db)
+	projectDefaultsModel := models.NewProjectDefaultsModel(db)
+	scheduledTaskModel := models.NewScheduledTaskModel(db)
+	txManager := models.NewTxManager(db)
 	logger.Info("Using SQLite models")
 
 	app := &application{
-		logger:         logger,
-		clients:        clientModel,
-		projects:       projectModel,
-		timesheets:     timesheetModel,
-		invoices:       invoiceModel,
-		settings:       settingModel,
-		templateCache:  templateCache,
-		formDecoder:    formDecoder,
-		sessionManager: sessionManager,
+		logger:             logger,
+		queryStats:         queryStats,
+		clients:            clientModel,
+		projects:           projectModel,
+		timesheets:         timesheetModel,
+		invoices:           invoiceModel,
+		settings:           settingModel,
+		businessProfiles:   businessProfileModel,
+		credits:            creditModel,
+		clientNotes:        clientNoteModel,
+		users:              userModel,
+		preferences:        userPreferencesModel,
+		userSessions:       userSessionModel,
+		reports:            reportModel,
+		exports:            exportModel,
+		integrity:          integrityModel,
+		invoiceEvents:      invoiceEventModel,
+		deliveries:         deliveryModel,
+		consolidated:       consolidatedInvoiceModel,
+		pendingTimesheets:  pendingTimesheetModel,
+		services:           serviceModel,
+		milestones:         milestoneModel,
+		tags:               tagModel,
+		savedViews:         savedViewModel,
+		listFilters:        listFilterModel,
+		paymentTerms:       paymentTermsModel,
+		checklists:         checklistModel,
+		notifications:      notificationModel,
+		jobs:               jobModel,
+		exchangeRates:      exchangeRateModel,
+		projectDefaults:    projectDefaultsModel,
+		projectAttachments: models.NewProjectAttachmentModel(db),
+		approvals:          models.NewClientApprovalModel(db),
+		visits:             models.NewRecentVisitModel(db),
+		externalIDs:        models.NewExternalIDModel(db),
+		drafts:             models.NewFormDraftModel(db),
+		queue:              jobs.New(jobModel, logger),
+		uploads:            storage.Local{Dir: *uploadDir},
+		dataDir:            filepath.Dir(*dsn),
+		freeSpace:          storage.FreeSpace,
+		resolver:           net.DefaultResolver,
+		transactions:       txManager,
+		templateCache:      templateCache,
+		formDecoder:        formDecoder,
+		sessionManager:     sessionManager,
+		sessionLifetime:    *sessionLifetime,
+		rememberMeFor:      *rememberMeFor,
+		basePath:           basePath,
+		trustedProxies:     trustedProxies,
+		db:                 db,
+		migrationsDir:      migrationsDir,
+	}
+
+	// Check for the browser invoice PDFs are rendered with now, rather than when someone
+	// first tries to print one
+	if chromePath, err := models.FindChrome(); err != nil {
+		app.pdfUnavailable = err
+		logger.Warn("Chrome or Chromium not found, printing and emailing invoices is disabled", "error", err.Error())
+	} else {
+		logger.Info("Rendering invoice PDFs with Chrome", "path", chromePath)
+	}
+
+	// Long-running tasks such as PDF generation are processed in the background
+	app.queue.Handle(models.JobInvoicePDF, app.invoicePDFJob)
+	app.queue.Handle(models.JobInvoiceEmail, app.invoiceEmailJob)
+	app.queue.Handle(models.JobConsolidatedInvoices, app.consolidatedInvoicesJob)
+	app.queue.Handle(models.JobTimesheetReport, app.timesheetReportJob)
+	app.queue.Handle(models.JobReminders, app.remindersJob)
+	app.queue.Handle(models.JobDatabaseMaintenance, app.databaseMaintenanceJob)
+	app.queue.Handle(models.JobInvoiceReminders, app.invoiceRemindersJob)
+	app.queue.Handle(models.JobAgingReport, app.agingReportJob)
+
+	// Recurring work is queued on the cron schedules kept in settings
+	app.scheduler = jobs.NewScheduler(app.queue, scheduledTaskModel, settingModel, logger)
+	app.scheduleTasks()
+
+	if *maintenance {
+		// Listen straight away and serve the maintenance page until the schema is up to date.
+		// If a migration fails the application stays in maintenance mode so that an owner
+		// can look into it from the admin migrations page.
+		app.maintenance.Store(true)
+		go func() {
+			migrations, err := app.migrate(database.MigrateOptions{})
+			if err != nil {
+				logger.Error("Failed to run migrations, staying in maintenance mode", "error", err.Error())
+				return
+			}
+			app.maintenance.Store(false)
+			logger.Info("Migrations applied, leaving maintenance mode", "count", len(migrations))
+			go app.scheduler.Run(context.Background())
+			app.queue.Run(context.Background())
+		}()
+	} else {
+		go app.scheduler.Run(context.Background())
+		go app.queue.Run(context.Background())
 	}
 
-	logger.Info("Starting server", slog.String("addr", *addr))
+	logger.Info("Starting server", slog.String("addr", *addr), slog.String("basePath", basePath))
 
 	err = http.ListenAndServe(*addr, app.routes())
 	if err != nil {
@@ -90,3 +341,36 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// importExport migrates the database and loads a full export file into it, logging how many
+// rows went into each table or every problem that stopped the import
+func importExport(db *sql.DB, migrationsDir, path string, logger *slog.Logger) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	export, err := models.ReadExport(data)
+	if err != nil {
+		return err
+	}
+
+	if err := database.RunMigrations(db, migrationsDir); err != nil {
+		return err
+	}
+	schemaVersion, err := database.SchemaVersion(db, migrationsDir)
+	if err != nil {
+		return err
+	}
+
+	result, err := models.NewExportModel(db).Import(export, schemaVersion)
+	for _, problem := range result.Problems {
+		logger.Error("Import problem", "problem", problem)
+	}
+	if err != nil {
+		return err
+	}
+	for _, table := range models.ExportTables {
+		logger.Info("Imported", "table", table, "rows", result.Rows[table])
+	}
+	return nil
+}
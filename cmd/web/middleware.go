@@ -1,8 +1,16 @@
 package main
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
 )
 
 func commonHeaders(next http.Handler) http.Handler {
@@ -18,21 +26,169 @@ func commonHeaders(next http.Handler) http.Handler {
 	})
 }
 
+// logRequest logs each request as it arrives and, when the database is instrumented, the
+// queries it ran once it is served
 func (app *application) logRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var (
-			ip     = r.RemoteAddr
+			ip     = app.clientIP(r)
 			proto  = r.Proto
+			scheme = app.requestScheme(r)
 			method = r.Method
 			uri    = r.URL.RequestURI()
 		)
 
-		app.logger.Info("received request", "ip", ip, "proto", proto, "method", method, "uri", uri)
+		app.logger.Info("received request", "ip", ip, "proto", proto, "scheme", scheme, "method", method, "uri", uri)
+
+		if app.queryStats == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Queries are counted across the whole database, so those of requests served at the
+		// same time are included too; on a single user's tracker that is rarely many
+		start := time.Now()
+		before := app.queryStats.Snapshot()
+		next.ServeHTTP(w, r)
+		queries := app.queryStats.Snapshot().Sub(before)
+
+		app.logger.Info("completed request", "method", method, "uri", uri,
+			"duration", time.Since(start).String(), "queries", queries.Queries, "queryTime", queries.Duration.String())
+	})
+}
+
+// authenticate loads the logged in user and their preferences, if any, into the request context
+func (app *application) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := app.sessionManager.GetInt(r.Context(), "authenticatedUserID")
+		if id == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		session, ok, err := app.loadUserSession(r, id)
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+		if !ok {
+			// The login expired or was logged out from another session
+			app.sessionManager.Remove(r.Context(), "authenticatedUserID")
+			app.sessionManager.Remove(r.Context(), "userSessionToken")
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := app.users.Get(id)
+		if err != nil {
+			if errors.Is(err, models.ErrNoRecord) {
+				// The account was deleted since the user logged in
+				app.sessionManager.Remove(r.Context(), "authenticatedUserID")
+				next.ServeHTTP(w, r)
+			} else {
+				app.serverError(w, r, err)
+			}
+			return
+		}
+
+		preferences, err := app.preferences.Get(user.ID)
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), authenticatedUserContextKey, &user)
+		ctx = context.WithValue(ctx, userPreferencesContextKey, preferences)
+		ctx = context.WithValue(ctx, userSessionContextKey, session)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requireAuthentication redirects to the login page when nobody is logged in. Until the first
+// user account is created the application stays open, as it was before accounts existed.
+func (app *application) requireAuthentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.currentUser(r) == nil {
+			count, err := app.users.Count()
+			if err != nil {
+				app.serverError(w, r, err)
+				return
+			}
+			if count > 0 {
+				app.redirect(w, r, "/user/login", http.StatusSeeOther)
+				return
+			}
+		}
+
+		// Pages behind a login shouldn't be cached by the browser
+		w.Header().Add("Cache-Control", "no-store")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireOwner rejects subcontractors from pages that expose clients or financials
+func (app *application) requireOwner(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user := app.currentUser(r); user != nil && !user.IsOwner() {
+			app.clientError(w, http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requirePDF answers requests for pages that render invoice PDFs with a page explaining
+// that PDFs are unavailable when no browser to render them was found at startup
+func (app *application) requirePDF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.pdfUnavailable == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		app.render(w, r, http.StatusServiceUnavailable, "pdf_unavailable.html", app.newTemplateData(r))
+	})
+}
 
+// verifyCSRF rejects posts that don't carry the token signed for their path in this session,
+// so that another site can't make a logged in browser submit the form
+func (app *application) verifyCSRF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret := app.sessionManager.GetString(r.Context(), "csrfSecret")
+		token := r.PostFormValue("csrf_token")
+		if secret == "" || !hmac.Equal([]byte(token), []byte(csrfToken(secret, r.URL.Path))) {
+			app.clientError(w, http.StatusForbidden)
+			return
+		}
 		next.ServeHTTP(w, r)
 	})
 }
 
+// maintenanceMode answers every request with a 503 maintenance page while the application is
+// in maintenance mode, such as while migrations run. Static files, logging in and out and the
+// admin pages stay reachable so that an owner can follow the maintenance and end it.
+func (app *application) maintenanceMode(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !app.maintenance.Load() || allowedDuringMaintenance(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Retry-After", "60")
+		w.Header().Set("Cache-Control", "no-store")
+		// The page is rendered without the session or settings, as their tables may be
+		// mid-migration
+		app.render(w, r, http.StatusServiceUnavailable, "maintenance.html", templateData{CurrentYear: time.Now().Year()})
+	})
+}
+
+// allowedDuringMaintenance reports whether a path is still served in maintenance mode
+func allowedDuringMaintenance(path string) bool {
+	return strings.HasPrefix(path, "/static/") ||
+		strings.HasPrefix(path, "/admin/") ||
+		path == "/user/login" ||
+		path == "/user/logout"
+}
+
 func (app *application) recoverPanic(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
@@ -46,3 +202,31 @@ func (app *application) recoverPanic(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// requireAPIKey guards the JSON API. The API is disabled until an api_key setting is saved,
+// after which requests must send it as a Bearer token or in an X-API-Key header.
+func (app *application) requireAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, err := app.settings.GetString("api_key")
+		if err != nil && !errors.Is(err, models.ErrNoRecord) {
+			app.serverError(w, r, err)
+			return
+		}
+		if key == "" {
+			app.apiError(w, http.StatusNotFound, "the API is disabled until an api_key setting is saved")
+			return
+		}
+
+		sent := r.Header.Get("X-API-Key")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			sent = strings.TrimSpace(bearer)
+		}
+		if subtle.ConstantTimeCompare([]byte(sent), []byte(key)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			app.apiError(w, http.StatusUnauthorized, "missing or invalid API key")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
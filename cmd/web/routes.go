@@ -2,48 +2,229 @@ package main
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/justinas/alice"
+	"github.com/paulboeck/FreelanceTrackerGo/ui"
 )
 
 func (app *application) routes() http.Handler {
 	mux := http.NewServeMux()
 
-	fileServer := http.FileServer(http.Dir("./ui/static"))
-
-	mux.Handle("GET /static/", http.StripPrefix("/static", fileServer))
-
-	dynamic := alice.New(app.sessionManager.LoadAndSave)
-
-	mux.Handle("GET /{$}", dynamic.ThenFunc(app.home))
-	mux.Handle("GET /projects", dynamic.ThenFunc(app.projectsList))
-	mux.Handle("GET /client/view/{id}", dynamic.ThenFunc(app.clientView))
-	mux.Handle("GET /client/create", dynamic.ThenFunc(app.clientCreate))
-	mux.Handle("POST /client/create", dynamic.ThenFunc(app.clientCreatePost))
-	mux.Handle("GET /client/update/{id}", dynamic.ThenFunc(app.clientUpdate))
-	mux.Handle("POST /client/update/{id}", dynamic.ThenFunc(app.clientUpdatePost))
-	mux.Handle("POST /client/delete/{id}", dynamic.ThenFunc(app.clientDelete))
-	mux.Handle("GET /client/{id}/project/create", dynamic.ThenFunc(app.projectCreate))
-	mux.Handle("POST /client/{id}/project/create", dynamic.ThenFunc(app.projectCreatePost))
-	mux.Handle("GET /project/view/{id}", dynamic.ThenFunc(app.projectView))
-	mux.Handle("GET /project/update/{id}", dynamic.ThenFunc(app.projectUpdate))
-	mux.Handle("POST /project/update/{id}", dynamic.ThenFunc(app.projectUpdatePost))
-	mux.Handle("POST /project/delete/{id}", dynamic.ThenFunc(app.projectDelete))
-	mux.Handle("GET /project/{id}/timesheet/create", dynamic.ThenFunc(app.timesheetCreate))
-	mux.Handle("POST /project/{id}/timesheet/create", dynamic.ThenFunc(app.timesheetCreatePost))
-	mux.Handle("GET /timesheet/update/{id}", dynamic.ThenFunc(app.timesheetUpdate))
-	mux.Handle("POST /timesheet/update/{id}", dynamic.ThenFunc(app.timesheetUpdatePost))
-	mux.Handle("POST /timesheet/delete/{id}", dynamic.ThenFunc(app.timesheetDelete))
-	mux.Handle("GET /project/{id}/invoice/create", dynamic.ThenFunc(app.invoiceCreate))
-	mux.Handle("POST /project/{id}/invoice/create", dynamic.ThenFunc(app.invoiceCreatePost))
-	mux.Handle("GET /invoice/update/{id}", dynamic.ThenFunc(app.invoiceUpdate))
-	mux.Handle("POST /invoice/update/{id}", dynamic.ThenFunc(app.invoiceUpdatePost))
-	mux.Handle("POST /invoice/delete/{id}", dynamic.ThenFunc(app.invoiceDelete))
-	mux.Handle("GET /invoice/print/{id}", dynamic.ThenFunc(app.invoicePrint))
-	mux.Handle("GET /settings", dynamic.ThenFunc(app.settingsView))
-	mux.Handle("GET /settings/edit", dynamic.ThenFunc(app.settingsEdit))
-	mux.Handle("POST /settings/edit", dynamic.ThenFunc(app.settingsEditPost))
+	// Static assets are embedded under static/, matching the URL path they are served from
+	mux.Handle("GET /static/", http.FileServerFS(ui.Files))
+
+	// Deleting is only done from forms carrying a token for the record being deleted
+	deleteRoute := func(chain alice.Chain, entity string, handler http.HandlerFunc) {
+		mux.Handle("POST /"+entity+"/delete/{id}", chain.Append(app.verifyCSRF).Then(handler))
+	}
+
+	dynamic := alice.New(app.sessionManager.LoadAndSave, app.authenticate)
+
+	mux.Handle("GET /user/login", dynamic.ThenFunc(app.userLogin))
+	mux.Handle("POST /user/login", dynamic.ThenFunc(app.userLoginPost))
+	mux.Handle("POST /user/logout", dynamic.ThenFunc(app.userLogoutPost))
+
+	// Pages a client opens from a link they were sent, without logging in
+	mux.Handle("GET /approve/{token}", dynamic.ThenFunc(app.clientApprovalView))
+	mux.Handle("POST /approve/{token}", dynamic.ThenFunc(app.clientApprovalPost))
+
+	// Routes subcontractors can use, limited by the handlers to projects shared with them
+	protected := dynamic.Append(app.requireAuthentication)
+
+	mux.Handle("GET /projects", protected.ThenFunc(app.projectsList))
+	mux.Handle("GET /project/view/{id}", protected.ThenFunc(app.projectView))
+	mux.Handle("GET /project/{id}/timesheet/create", protected.ThenFunc(app.timesheetCreate))
+	mux.Handle("POST /project/{id}/timesheet/create", protected.ThenFunc(app.timesheetCreatePost))
+	mux.Handle("GET /timesheet/update/{id}", protected.ThenFunc(app.timesheetUpdate))
+	mux.Handle("POST /timesheet/update/{id}", protected.ThenFunc(app.timesheetUpdatePost))
+	deleteRoute(protected, "timesheet", app.timesheetDelete)
+	mux.Handle("GET /user/preferences", protected.ThenFunc(app.userPreferencesEdit))
+	mux.Handle("POST /user/preferences", protected.ThenFunc(app.userPreferencesEditPost))
+	mux.Handle("GET /user/sessions", protected.ThenFunc(app.userSessionsList))
+	mux.Handle("POST /user/sessions/logout-others", protected.ThenFunc(app.userSessionsLogoutOthersPost))
+
+	// Everything else exposes clients or financials and is for owners only
+	owner := protected.Append(app.requireOwner)
+
+	// Printing and emailing invoices and printing timesheet reports render PDFs, which needs Chrome on the server
+	pdf := owner.Append(app.requirePDF)
+
+	mux.Handle("GET /{$}", owner.ThenFunc(app.home))
+	mux.Handle("GET /projects/search", owner.ThenFunc(app.projectSearch))
+	mux.Handle("GET /palette", owner.ThenFunc(app.palette))
+	mux.Handle("GET /draft/{form}/{id}", owner.ThenFunc(app.formDraftView))
+	mux.Handle("POST /draft/{form}/{id}", owner.ThenFunc(app.formDraftPost))
+	mux.Handle("POST /draft/{form}/{id}/discard", owner.ThenFunc(app.formDraftDiscard))
+	mux.Handle("GET /timesheet/create", owner.ThenFunc(app.timesheetQuickCreate))
+	mux.Handle("GET /invoice/create", owner.ThenFunc(app.invoiceQuickCreate))
+	mux.Handle("GET /client/view/{id}", owner.ThenFunc(app.clientView))
+	mux.Handle("GET /clients/sync", owner.ThenFunc(app.clientsSync))
+	mux.Handle("POST /clients/sync/preview", owner.ThenFunc(app.clientsSyncPreview))
+	mux.Handle("POST /clients/sync/apply", owner.ThenFunc(app.clientsSyncApply))
+	mux.Handle("GET /client/create", owner.ThenFunc(app.clientCreate))
+	mux.Handle("POST /client/create", owner.ThenFunc(app.clientCreatePost))
+	mux.Handle("GET /client/update/{id}", owner.ThenFunc(app.clientUpdate))
+	mux.Handle("POST /client/update/{id}", owner.ThenFunc(app.clientUpdatePost))
+	deleteRoute(owner, "client", app.clientDelete)
+	mux.Handle("GET /client/export/{id}", owner.ThenFunc(app.clientExport))
+	mux.Handle("GET /clients/pipeline", owner.ThenFunc(app.clientsPipeline))
+	mux.Handle("POST /client/stage/{id}", owner.ThenFunc(app.clientStagePost))
+	mux.Handle("POST /client/convert/{id}", owner.ThenFunc(app.clientConvertPost))
+	mux.Handle("GET /client/{id}/project/create", owner.ThenFunc(app.projectCreate))
+	mux.Handle("POST /client/{id}/project/create", owner.ThenFunc(app.projectCreatePost))
+	mux.Handle("GET /client/{id}/project/defaults", owner.ThenFunc(app.clientProjectDefaultsEdit))
+	mux.Handle("POST /client/{id}/project/defaults", owner.ThenFunc(app.clientProjectDefaultsEditPost))
+	deleteRoute(owner, "client/project-defaults", app.clientProjectDefaultsDelete)
+	mux.Handle("POST /client/{id}/invoice/consolidated", owner.ThenFunc(app.consolidatedInvoicePost))
+	mux.Handle("GET /client/{id}/note/create", owner.ThenFunc(app.clientNoteCreate))
+	mux.Handle("POST /client/{id}/note/create", owner.ThenFunc(app.clientNoteCreatePost))
+	mux.Handle("GET /client/note/update/{id}", owner.ThenFunc(app.clientNoteUpdate))
+	mux.Handle("POST /client/note/update/{id}", owner.ThenFunc(app.clientNoteUpdatePost))
+	mux.Handle("POST /client/note/pin/{id}", owner.ThenFunc(app.clientNotePinPost))
+	deleteRoute(owner, "client/note", app.clientNoteDelete)
+	mux.Handle("GET /project/update/{id}", owner.ThenFunc(app.projectUpdate))
+	mux.Handle("POST /project/update/{id}", owner.ThenFunc(app.projectUpdatePost))
+	deleteRoute(owner, "project", app.projectDelete)
+	mux.Handle("GET /project/clone/{id}", owner.ThenFunc(app.projectClone))
+	mux.Handle("POST /project/clone/{id}", owner.ThenFunc(app.projectClonePost))
+	mux.Handle("GET /project/export/{id}", owner.ThenFunc(app.projectExport))
+	mux.Handle("POST /project/reopen/{id}", owner.ThenFunc(app.projectReopenPost))
+	mux.Handle("GET /project/{id}/milestone/create", owner.ThenFunc(app.milestoneCreate))
+	mux.Handle("POST /project/{id}/milestone/create", owner.ThenFunc(app.milestoneCreatePost))
+	mux.Handle("GET /milestone/update/{id}", owner.ThenFunc(app.milestoneUpdate))
+	mux.Handle("POST /milestone/update/{id}", owner.ThenFunc(app.milestoneUpdatePost))
+	deleteRoute(owner, "milestone", app.milestoneDelete)
+	mux.Handle("POST /milestone/invoice/{id}", owner.ThenFunc(app.milestoneInvoicePost))
+	mux.Handle("POST /project/{id}/attachment/create", owner.ThenFunc(app.projectAttachmentCreatePost))
+	mux.Handle("GET /attachment/download/{id}", owner.ThenFunc(app.projectAttachmentDownload))
+	deleteRoute(owner, "attachment", app.projectAttachmentDelete)
+	mux.Handle("POST /project/{id}/client-approval/create", owner.ThenFunc(app.clientApprovalCreatePost))
+	deleteRoute(owner, "client-approval", app.clientApprovalDelete)
+	mux.Handle("POST /project/{id}/checklist/{item}", owner.ThenFunc(app.projectChecklistPost))
+	mux.Handle("GET /project/{id}/invoice/create", owner.ThenFunc(app.invoiceCreate))
+	mux.Handle("POST /project/{id}/invoice/create", owner.ThenFunc(app.invoiceCreatePost))
+	mux.Handle("GET /invoice/update/{id}", owner.ThenFunc(app.invoiceUpdate))
+	mux.Handle("POST /invoice/update/{id}", owner.ThenFunc(app.invoiceUpdatePost))
+	deleteRoute(owner, "invoice", app.invoiceDelete)
+	mux.Handle("GET /invoice/export/{id}", owner.ThenFunc(app.invoiceExport))
+	mux.Handle("GET /invoice/void/{id}", owner.ThenFunc(app.invoiceVoid))
+	mux.Handle("POST /invoice/void/{id}", owner.ThenFunc(app.invoiceVoidPost))
+	mux.Handle("GET /invoice/print/{id}", pdf.ThenFunc(app.invoicePrint))
+	mux.Handle("GET /invoice/html/{id}", owner.ThenFunc(app.invoiceHTML))
+	mux.Handle("GET /invoice/email/{id}", pdf.ThenFunc(app.invoiceEmail))
+	mux.Handle("POST /invoice/email/{id}", pdf.ThenFunc(app.invoiceEmailPost))
+	mux.Handle("POST /invoices/remind", pdf.ThenFunc(app.invoiceRemindersPost))
+	mux.Handle("GET /project/timesheet-report/{id}", pdf.ThenFunc(app.timesheetReport))
+	mux.Handle("GET /client/timesheet-report/{id}", pdf.ThenFunc(app.clientTimesheetReport))
+	mux.Handle("GET /jobs", owner.ThenFunc(app.jobList))
+	mux.Handle("GET /job/view/{id}", owner.ThenFunc(app.jobView))
+	mux.Handle("GET /job/status/{id}", owner.ThenFunc(app.jobStatus))
+	mux.Handle("GET /job/download/{id}", owner.ThenFunc(app.jobDownload))
+	mux.Handle("GET /profiles", owner.ThenFunc(app.businessProfilesList))
+	mux.Handle("GET /profile/create", owner.ThenFunc(app.businessProfileCreate))
+	mux.Handle("POST /profile/create", owner.ThenFunc(app.businessProfileCreatePost))
+	mux.Handle("GET /profile/update/{id}", owner.ThenFunc(app.businessProfileUpdate))
+	mux.Handle("POST /profile/update/{id}", owner.ThenFunc(app.businessProfileUpdatePost))
+	deleteRoute(owner, "profile", app.businessProfileDelete)
+	mux.Handle("GET /services", owner.ThenFunc(app.servicesList))
+	mux.Handle("GET /service/create", owner.ThenFunc(app.serviceCreate))
+	mux.Handle("POST /service/create", owner.ThenFunc(app.serviceCreatePost))
+	mux.Handle("GET /service/update/{id}", owner.ThenFunc(app.serviceUpdate))
+	mux.Handle("POST /service/update/{id}", owner.ThenFunc(app.serviceUpdatePost))
+	deleteRoute(owner, "service", app.serviceDelete)
+	mux.Handle("GET /payment-terms", owner.ThenFunc(app.paymentTermsList))
+	mux.Handle("POST /payment-terms/create", owner.ThenFunc(app.paymentTermsCreatePost))
+	deleteRoute(owner, "payment-terms", app.paymentTermsDelete)
+	mux.Handle("GET /checklist", owner.ThenFunc(app.checklistList))
+	mux.Handle("POST /checklist/create", owner.ThenFunc(app.checklistCreatePost))
+	deleteRoute(owner, "checklist", app.checklistDelete)
+	mux.Handle("GET /external-ids/{kind}/{id}", owner.ThenFunc(app.externalIDsView))
+	mux.Handle("POST /external-ids/{kind}/{id}", owner.ThenFunc(app.externalIDsPost))
+	mux.Handle("POST /external-ids/{kind}/{id}/delete/{source}", owner.Append(app.verifyCSRF).ThenFunc(app.externalIDDelete))
+	mux.Handle("GET /tags", owner.ThenFunc(app.tagsList))
+	mux.Handle("GET /tag/create", owner.ThenFunc(app.tagCreate))
+	mux.Handle("POST /tag/create", owner.ThenFunc(app.tagCreatePost))
+	mux.Handle("GET /tag/update/{id}", owner.ThenFunc(app.tagUpdate))
+	mux.Handle("POST /tag/update/{id}", owner.ThenFunc(app.tagUpdatePost))
+	deleteRoute(owner, "tag", app.tagDelete)
+	mux.Handle("POST /view/create", owner.ThenFunc(app.savedViewCreatePost))
+	deleteRoute(owner, "view", app.savedViewDelete)
+	mux.Handle("GET /users", owner.ThenFunc(app.usersList))
+	mux.Handle("GET /user/create", owner.ThenFunc(app.userCreate))
+	mux.Handle("POST /user/create", owner.ThenFunc(app.userCreatePost))
+	deleteRoute(owner, "user", app.userDelete)
+	mux.Handle("POST /project/share/{id}", owner.ThenFunc(app.projectSharePost))
+	mux.Handle("POST /project/{id}/unshare/{userID}", owner.Append(app.verifyCSRF).ThenFunc(app.projectUnsharePost))
+	mux.Handle("GET /timesheets/pending", owner.ThenFunc(app.pendingTimesheetsList))
+	mux.Handle("GET /timesheets/approvals", owner.ThenFunc(app.timesheetApprovalsList))
+	mux.Handle("POST /timesheet/approve/{id}", owner.ThenFunc(app.timesheetApprovePost))
+	mux.Handle("POST /timesheet/reject/{id}", owner.ThenFunc(app.timesheetRejectPost))
+	mux.Handle("POST /timesheet/pending/confirm/{id}", owner.ThenFunc(app.pendingTimesheetConfirmPost))
+	mux.Handle("POST /timesheet/pending/discard/{id}", owner.Append(app.verifyCSRF).ThenFunc(app.pendingTimesheetDiscardPost))
+	mux.Handle("GET /reports/margins", owner.ThenFunc(app.marginsReport))
+	mux.Handle("GET /reports/forecast", owner.ThenFunc(app.receivablesForecast))
+	mux.Handle("GET /reports/wip", owner.ThenFunc(app.workInProgressReport))
+	mux.Handle("GET /reports/clients", owner.ThenFunc(app.clientValuesReport))
+	mux.Handle("GET /reports/trends", owner.ThenFunc(app.trendsReport))
+	mux.Handle("GET /settings", owner.ThenFunc(app.settingsView))
+	mux.Handle("GET /settings/edit", owner.ThenFunc(app.settingsEdit))
+	mux.Handle("POST /settings/edit", owner.ThenFunc(app.settingsEditPost))
+	mux.Handle("GET /settings/logo", owner.ThenFunc(app.settingsLogo))
+	mux.Handle("POST /settings/logo", owner.ThenFunc(app.settingsLogoPost))
+	mux.Handle("POST /settings/notifications/test", owner.ThenFunc(app.settingsNotifyTestPost))
+	mux.Handle("GET /settings/invoice-template/help", owner.ThenFunc(app.invoiceTemplateHelp))
+	mux.Handle("GET /settings/invoice-template/sample", owner.ThenFunc(app.invoiceTemplateSample))
+	mux.Handle("GET /admin/migrations", owner.ThenFunc(app.adminMigrations))
+	mux.Handle("POST /admin/migrations/up", owner.ThenFunc(app.adminMigrationsUpPost))
+	mux.Handle("POST /admin/migrations/down", owner.ThenFunc(app.adminMigrationsDownPost))
+	mux.Handle("POST /admin/maintenance", owner.ThenFunc(app.adminMaintenancePost))
+	mux.Handle("POST /admin/database", owner.ThenFunc(app.adminDatabasePost))
+	mux.Handle("GET /admin/integrity", owner.ThenFunc(app.adminIntegrity))
+	mux.Handle("POST /admin/integrity/repair", owner.ThenFunc(app.adminIntegrityRepairPost))
+	mux.Handle("GET /export/full", owner.ThenFunc(app.exportFull))
+	mux.Handle("GET /import", owner.ThenFunc(app.csvImport))
+	mux.Handle("POST /import", owner.ThenFunc(app.csvImportPost))
+	mux.Handle("GET /invoices/overdue", owner.ThenFunc(app.overdueInvoices))
+	mux.Handle("GET /invoices/reconcile", owner.ThenFunc(app.reconcile))
+	mux.Handle("POST /invoices/reconcile", owner.ThenFunc(app.reconcilePost))
+	mux.Handle("POST /invoices/reconcile/confirm", owner.ThenFunc(app.reconcileConfirmPost))
+
+	// JSON API for no-code tools like Zapier and Make, authenticated by API key instead of a session
+	api := alice.New(app.requireAPIKey)
+
+	mux.Handle("GET /api/v1/invoices", api.ThenFunc(app.apiInvoicesList))
+	mux.Handle("GET /api/v1/invoices/{id}", api.ThenFunc(app.apiInvoiceView))
+	mux.Handle("GET /api/v1/invoice-events", api.ThenFunc(app.apiInvoiceEventsList))
+	mux.Handle("GET /api/v1/external-ids/{kind}/{source}/{external_id...}", api.ThenFunc(app.apiExternalIDView))
+
+	// Inbound email from Mailgun, authenticated by the signature on each request
+	mux.HandleFunc("POST /inbound/email", app.inboundEmailPost)
+
+	// Tracking image in invoice emails, loaded by the client's mail program without logging in
+	mux.HandleFunc("GET /email/open/{token}", app.emailOpen)
 
 	standardChain := alice.New(app.recoverPanic, app.logRequest, commonHeaders)
-	return standardChain.Then(mux)
+	return standardChain.Then(app.mountAtBasePath(app.maintenanceMode(mux)))
+}
+
+// mountAtBasePath serves the application under the -base-path prefix, so that it can sit
+// behind a reverse proxy at e.g. /freelance. Requests outside the prefix are not found.
+func (app *application) mountAtBasePath(mux http.Handler) http.Handler {
+	if app.basePath == "" {
+		return mux
+	}
+
+	stripped := http.StripPrefix(app.basePath, mux)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == app.basePath {
+			http.Redirect(w, r, app.basePath+"/", http.StatusMovedPermanently)
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, app.basePath+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		stripped.ServeHTTP(w, r)
+	})
 }
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutes(t *testing.T) {
+	app, testDB := createTestApp(t)
+	defer testDB.Cleanup(t)
+
+	// ServeMux panics when two patterns conflict, which would stop the server starting
+	assert.NotPanics(t, func() { app.routes() })
+}
@@ -1,11 +1,21 @@
 package main
 
 import (
+	"fmt"
 	"html/template"
-	"path/filepath"
+	"io/fs"
+	"path"
+	"reflect"
 	"time"
 
+	"github.com/paulboeck/FreelanceTrackerGo/internal/bankstatement"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/contacts"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/database"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/importer"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/jobs"
 	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/validator"
+	"github.com/paulboeck/FreelanceTrackerGo/ui"
 )
 
 type paginationData struct {
@@ -16,20 +26,179 @@ type paginationData struct {
 	PrevPage    int
 	NextPage    int
 	PageSize    int
+	// Set for one of several lists on a page: the query parameter it is paged by, and the
+	// rest of the query string, kept when moving between its pages
+	Param string
+	Query template.URL
 }
 
+// listFilterData is the filter applied to a list page, with the views that can be chosen
+// and the links that change it. Queries are already URL encoded.
+type listFilterData struct {
+	List       models.FilterList
+	Filter     models.Filter
+	Query      template.URL
+	Conditions []filterConditionData
+	QuickViews []filterViewData
+	SavedViews []filterViewData
+	SavedView  *models.SavedView
+}
+
+// filterConditionData is a condition of a list filter in words, with the query of the filter
+// without it
+type filterConditionData struct {
+	Label       string
+	RemoveQuery template.URL
+}
+
+// filterViewData is a quick filter or saved view offered in the view dropdown of a list page
+type filterViewData struct {
+	Name     string
+	Query    string
+	Selected bool
+}
+
+// templateData holds everything a page template is rendered with. Pages asked for as JSON
+// are answered with the fields the handler set, so fields only of use to the HTML layout are
+// left out of JSON.
 type templateData struct {
-	CurrentYear        int
+	CurrentYear        int               `json:"-"`
+	DateFormat         models.DateFormat `json:"-"`
+	Theme              string            `json:"-"`
+	Flash              string            `json:"-"`
+	CSRFSecret         string            `json:"-"`
+	Public             bool              `json:"-"`
+	CurrentUser        *models.User
+	IsSubcontractor    bool
+	Users              []models.User
+	UserSessions       []models.UserSession
+	CurrentSessionID   int
+	RememberMeFor      string
+	SharedWith         []models.User
 	Client             *models.Client
 	Clients            []models.Client
 	Project            *models.Project
 	Projects           []models.Project
 	ProjectsWithClient []models.ProjectWithClient
+	ProjectMatches     []models.ProjectMatch
+	PickAction         string
 	Timesheets         []models.Timesheet
+	PendingTimesheets  []models.PendingTimesheet
+	TimesheetApprovals []models.TimesheetApproval
+	Timesheet          *models.Timesheet
+	LockingInvoices    []models.Invoice
+	LockedTimesheets   map[int]bool
+	Milestone          *models.Milestone
+	Milestones         []models.Milestone
+	MilestoneFees      *models.MilestoneFees
+	Invoice            *models.Invoice
 	Invoices           []models.Invoice
+	InvoiceCC          models.InvoiceCC
+	BudgetCode         string // The project's or client's budget code, printed unless the invoice has its own
+	InvoiceDeliveries  []models.InvoiceDelivery
+	DeliverySummary    *models.DeliverySummary
+	SMTPConfigured     bool
+	OverdueInvoices    []models.OverdueInvoice
+	RecentlyReminded   map[int]bool
+	ReminderInterval   int
+	PDFPages           int
+	MaxPDFPages        int
 	Settings           []models.AppSetting
+	LogoPath           string
+	BusinessProfile    *models.BusinessProfile
+	BusinessProfiles   []models.BusinessProfile
+	Service            *models.Service
+	Services           []models.Service
+	WorkTypes          []string
+	ExternalIDTarget   *externalIDTarget
+	ExternalIDs        []models.ExternalID
+	Draft              string
+	Tag                *models.Tag
+	Tags               []models.Tag
+	TagFilter          *models.Tag
+	ListFilter         *listFilterData
+	ClientTags         map[int][]models.Tag
+	ProjectTags        map[int][]models.Tag
+	Countries          []models.Country
+	InvoiceLanguages   []models.InvoiceLanguage
+	LandingPages       []models.LandingPage
+	Themes             []models.Theme
+	DateFormats        []models.DateFormat
+	TemplateFields     []models.TemplateField
+	TemplateFuncs      []models.TemplateFunc
+	ContactSync        *contacts.SyncPreview
+	ImportPresets      []importer.Preset
+	ImportKinds        []importer.Kind
+	ImportSummary      *importer.Summary
+	BankStatement      *bankstatement.Statement
+	DepositMatches     []bankstatement.Match
+	Job                *models.Job
+	ScheduledTasks     []jobs.TaskStatus
+	RecentJobs         []models.Job
+	ClientCredit       float64
+	ClientCredits      []models.ClientCredit
+	ClientNote         *models.ClientNote
+	ClientNotes        []models.ClientNote
+	PinnedNotes        []models.ClientNote
+	HourUsage          *models.HourUsage
+	PaymentBehavior    *models.PaymentBehavior
+	ClientValue        *models.ClientValue
+	ConsolidatedMonth  string
+	ReportStart        string
+	ReportEnd          string
+	Margin             *models.Margin
+	Estimate           *models.ProjectEstimate
+	ProjectMargins     []models.ProjectMargin
+	ClientMargins      []models.ClientMargin
+	Forecast           *models.ReceivablesForecast
+	WorkInProgress     *models.WorkInProgress
+	Trends             *models.Trends
+	ClientValues       []models.ClientValue
+	ClientValueSort    models.ClientValueSort
+	Pipeline           []models.PipelineColumn
+	PipelineStages     []string
+	ProjectDefaults    *models.ProjectDefaults
+	ProjectStatuses    []string
+	TermsPresets       []models.PaymentTermsPreset
+	ChecklistItems     []models.ChecklistItem
+	ProjectChecklist   []models.ProjectChecklistItem
+	ProjectAttachments []models.ProjectAttachment
+	ClientApprovals    []models.ClientApproval
+	ClientApproval     *models.ClientApproval
+	ApprovalHours      float64
+	Migrations         []database.Migration
+	PlannedMigrations  []database.Migration
+	RollingBack        bool
+	Integrity          *models.IntegrityReport
+	Maintenance        bool
+	DatabaseSize       *database.Size
+	MaintenanceTasks   []database.MaintenanceTask
 	Form               any
+	ErrorSummary       []validator.FieldError `json:"-"`
 	Pagination         *paginationData
+	TimesheetPages     *paginationData
+	InvoicePages       *paginationData
+}
+
+// jsonFields returns the fields of the data that were set, keyed by field name, for
+// answering a request for a page as JSON
+func (data templateData) jsonFields() map[string]any {
+	fields := make(map[string]any)
+	value := reflect.ValueOf(data)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Tag.Get("json") == "-" || value.Field(i).IsZero() {
+			continue
+		}
+		fields[field.Name] = value.Field(i).Interface()
+	}
+	return fields
+}
+
+// CSRFToken returns the token a form must send as csrf_token when posting to the path made by
+// joining parts, as in {{$.CSRFToken "/client/delete/" .ID}}
+func (data templateData) CSRFToken(parts ...any) string {
+	return csrfToken(data.CSRFSecret, fmt.Sprint(parts...))
 }
 
 func humanDate(t time.Time) string {
@@ -38,30 +207,33 @@ func humanDate(t time.Time) string {
 
 var functions = template.FuncMap{
 	"humanDate": humanDate,
+	"fileSize":  models.FormatFileSize,
 }
 
-func newTemplateCache() (map[string]*template.Template, error) {
+// newTemplateCache parses every embedded page along with the base layout and partials. Links
+// in the templates are written as {{base}}/path so they still work when mounted under basePath.
+func newTemplateCache(basePath string) (map[string]*template.Template, error) {
 	cache := map[string]*template.Template{}
+	base := template.FuncMap{
+		"base": func() string { return basePath },
+	}
 
-	pages, err := filepath.Glob("./ui/html/pages/*.html")
+	pages, err := fs.Glob(ui.Files, "html/pages/*.html")
 	if err != nil {
 		return nil, err
 	}
 
 	// For each page, create a template set containing the base html, all partials, and the page itself
 	for _, page := range pages {
-		name := filepath.Base(page)
+		name := path.Base(page)
 
-		ts, err := template.New(name).Funcs(functions).ParseFiles("./ui/html/base.html")
-		if err != nil {
-			return nil, err
+		patterns := []string{
+			"html/base.html",
+			"html/partials/*.html",
+			page,
 		}
 
-		ts, err = ts.ParseGlob("./ui/html/partials/*.html")
-		if err != nil {
-
-		}
-		ts, err = ts.ParseFiles(page)
+		ts, err := template.New(name).Funcs(functions).Funcs(base).ParseFS(ui.Files, patterns...)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,342 @@
+// Package bankstatement reads the CSV statements banks export and matches the deposits on
+// them to the invoices they pay, by amount and by the invoice number or client name given as
+// the payment's reference
+package bankstatement
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
+)
+
+// columnNames lists the header names each field is read from, matched case insensitively and
+// taking the first name found. Banks export either a single signed amount column or separate
+// columns for money in and out; only money in is read.
+var columnNames = map[string][]string{
+	"date":      {"date", "booking date", "transaction date", "posting date", "posted date", "value date"},
+	"amount":    {"amount", "transaction amount", "amount (usd)", "amount (eur)", "amount (gbp)"},
+	"credit":    {"credit", "credit amount", "deposit", "deposits", "paid in", "money in"},
+	"reference": {"reference", "description", "details", "memo", "narrative", "payment reference", "transaction description"},
+	"payer":     {"payer", "payee", "name", "counterparty", "counter party", "from"},
+}
+
+// Deposit is money paid into the account, as listed on a statement
+type Deposit struct {
+	Row       int
+	Date      time.Time
+	Amount    float64
+	Reference string
+}
+
+// Statement is the deposits read from a bank statement. Rows that couldn't be read are left
+// out and described in Problems; withdrawals are skipped without comment.
+type Statement struct {
+	Deposits []Deposit
+	Problems []string
+}
+
+// Read parses a bank statement CSV. Dates are read as YYYY-MM-DD or in the given format.
+func Read(r io.Reader, dateFormat models.DateFormat) (Statement, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return Statement{}, fmt.Errorf("reading header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if _, exists := index[column]; !exists {
+			index[column] = i
+		}
+	}
+
+	columns := make(map[string]int, len(columnNames))
+	for field, names := range columnNames {
+		columns[field] = -1
+		for _, name := range names {
+			if i, ok := index[name]; ok {
+				columns[field] = i
+				break
+			}
+		}
+	}
+	if columns["date"] < 0 {
+		return Statement{}, fmt.Errorf("no date column found; is this a bank statement?")
+	}
+	if columns["amount"] < 0 && columns["credit"] < 0 {
+		return Statement{}, fmt.Errorf("no amount or credit column found; is this a bank statement?")
+	}
+
+	var statement Statement
+	// Rows are numbered as a spreadsheet would show them, counting the header
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Statement{}, fmt.Errorf("row %d: %w", row, err)
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		value := func(field string) string {
+			i := columns[field]
+			if i < 0 || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		deposit, ok, problem := readDeposit(row, value, dateFormat)
+		if problem != "" {
+			statement.Problems = append(statement.Problems, fmt.Sprintf("Row %d: %s", row, problem))
+		}
+		if ok {
+			statement.Deposits = append(statement.Deposits, deposit)
+		}
+	}
+	return statement, nil
+}
+
+// readDeposit reads a statement row, reporting whether it is a deposit and any problem
+// reading it
+func readDeposit(row int, value func(string) string, dateFormat models.DateFormat) (Deposit, bool, string) {
+	text := value("credit")
+	if text == "" {
+		text = value("amount")
+	}
+	amount, err := parseAmount(text)
+	if err != nil {
+		return Deposit{}, false, err.Error()
+	}
+	if amount <= 0 {
+		return Deposit{}, false, ""
+	}
+
+	date, err := dateFormat.Parse(value("date"))
+	if err != nil {
+		return Deposit{}, false, fmt.Sprintf("date %q not recognised", value("date"))
+	}
+
+	reference := value("reference")
+	if payer := value("payer"); payer != "" && !strings.Contains(strings.ToLower(reference), strings.ToLower(payer)) {
+		reference = strings.TrimSpace(payer + " " + reference)
+	}
+	return Deposit{Row: row, Date: date, Amount: amount, Reference: reference}, true, ""
+}
+
+// parseAmount reads an amount of money, ignoring currency symbols and thousands separators.
+// Amounts in parentheses are negative, as accountants write them. A blank amount is zero.
+func parseAmount(text string) (float64, error) {
+	negative := strings.HasPrefix(text, "(") && strings.HasSuffix(text, ")")
+	cleaned := strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			return r
+		}
+		return -1
+	}, text)
+	if strings.TrimSpace(text) == "" {
+		return 0, nil
+	}
+	amount, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, fmt.Errorf("amount %q not recognised", text)
+	}
+	if negative {
+		amount = -math.Abs(amount)
+	}
+	return amount, nil
+}
+
+// Scores given to the ways a deposit can resemble an invoice
+const (
+	scoreExactAmount = 50
+	scoreNearAmount  = 20
+	scoreNumber      = 40
+	scoreClientName  = 15
+)
+
+// minimumScore is the least resemblance for a deposit to be proposed as paying an invoice: it
+// has to match the amount, give the invoice number, or come close to the amount and name the
+// client
+const minimumScore = scoreNearAmount + scoreClientName
+
+// nearAmountTolerance is how far below the amount outstanding a deposit can fall, as a
+// fraction of it, and still be taken as paying it less bank charges
+const nearAmountTolerance = 0.02
+
+// ConfidentScore is the score from which a proposed match is ticked for recording by
+// default: the amount is right and the reference names the invoice or client, or the
+// reference gives the invoice number and the amount is close
+const ConfidentScore = scoreNearAmount + scoreNumber
+
+// Match proposes that a deposit pays an invoice, of which Outstanding was still to be paid.
+// Deposits that resemble no open invoice are listed without one.
+type Match struct {
+	Deposit     Deposit
+	Invoice     *models.InvoiceWithClient
+	Outstanding float64
+	Score       int
+	Reasons     []string
+}
+
+// Confident reports whether the match is likely enough to be recorded without checking
+func (m Match) Confident() bool {
+	return m.Invoice != nil && m.Score >= ConfidentScore
+}
+
+// MatchDeposits pairs each deposit with the open invoice it most resembles. Each invoice is
+// paired at most once, the closest resemblances being paired first, and a deposit is never
+// taken to pay an invoice issued after it was received.
+func MatchDeposits(deposits []Deposit, invoices []models.InvoiceWithClient) []Match {
+	type candidate struct {
+		deposit int
+		invoice int
+		score   int
+		reasons []string
+	}
+	var candidates []candidate
+	for d, deposit := range deposits {
+		for i, invoice := range invoices {
+			if deposit.Date.Before(invoice.InvoiceDate) {
+				continue
+			}
+			score, reasons := resemblance(deposit, invoice)
+			if score >= minimumScore {
+				candidates = append(candidates, candidate{d, i, score, reasons})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		if candidates[a].score != candidates[b].score {
+			return candidates[a].score > candidates[b].score
+		}
+		// Of equally good matches the oldest invoice is most likely the one being paid
+		return invoices[candidates[a].invoice].InvoiceDate.Before(invoices[candidates[b].invoice].InvoiceDate)
+	})
+
+	matches := make([]Match, len(deposits))
+	for d, deposit := range deposits {
+		matches[d] = Match{Deposit: deposit}
+	}
+	paired := make(map[int]bool)
+	for _, c := range candidates {
+		if matches[c.deposit].Invoice != nil || paired[c.invoice] {
+			continue
+		}
+		invoice := invoices[c.invoice]
+		matches[c.deposit].Invoice = &invoice
+		matches[c.deposit].Outstanding = Outstanding(invoice.Invoice)
+		matches[c.deposit].Score = c.score
+		matches[c.deposit].Reasons = c.reasons
+		paired[c.invoice] = true
+	}
+	return matches
+}
+
+// Outstanding is what is still to be paid of an invoice: its balance after client credit,
+// less any part payment already recorded
+func Outstanding(invoice models.Invoice) float64 {
+	return invoice.BalanceDue() - invoice.TotalPaid()
+}
+
+// resemblance scores how much a deposit looks like the payment of an invoice, and says why
+func resemblance(deposit Deposit, invoice models.InvoiceWithClient) (int, []string) {
+	var score int
+	var reasons []string
+
+	balance := Outstanding(invoice.Invoice)
+	switch {
+	case math.Abs(deposit.Amount-balance) < 0.005:
+		score += scoreExactAmount
+		reasons = append(reasons, "amount matches")
+	case deposit.Amount < balance && balance-deposit.Amount <= balance*nearAmountTolerance:
+		score += scoreNearAmount
+		reasons = append(reasons, fmt.Sprintf("amount is %.2f short", balance-deposit.Amount))
+	}
+
+	words := referenceWords(deposit.Reference)
+	if containsSequence(words, referenceWords(invoice.DisplayNumber())) || mentionsNumber(words, invoice.DisplayNumber()) {
+		score += scoreNumber
+		reasons = append(reasons, "reference gives invoice "+invoice.DisplayNumber())
+	}
+	if mentionsName(words, referenceWords(invoice.ClientName)) {
+		score += scoreClientName
+		reasons = append(reasons, "reference names "+invoice.ClientName)
+	}
+	return score, reasons
+}
+
+// referenceWords splits text into lower case words of letters and digits
+func referenceWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// containsSequence reports whether words holds every word of seq, one after another
+func containsSequence(words, seq []string) bool {
+	if len(seq) == 0 {
+		return false
+	}
+	for start := 0; start+len(seq) <= len(words); start++ {
+		found := true
+		for i, word := range seq {
+			if words[start+i] != word {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// mentionsNumber reports whether an invoice number appears in the words with its separators
+// left out, as in INV2024007 for INV-2024-007, or with leading zeros dropped, as in 7 for
+// 0007. Numbers of fewer than three digits are too easily mistaken for other figures.
+func mentionsNumber(words []string, number string) bool {
+	joined := strings.Join(referenceWords(number), "")
+	trimmed := strings.TrimLeft(joined, "0")
+	for _, word := range words {
+		if word == joined || (len(trimmed) >= 3 && strings.TrimLeft(word, "0") == trimmed) {
+			return true
+		}
+	}
+	return false
+}
+
+// mentionsName reports whether the words include most of a client's name. Short words such
+// as "of" or "and" don't count, so "Univ Chicago Press" still names "University of Chicago
+// Press" through its longer words.
+func mentionsName(words, name []string) bool {
+	var significant, found int
+	for _, part := range name {
+		if len(part) < 3 {
+			continue
+		}
+		significant++
+		for _, word := range words {
+			if word == part || (len(word) >= 4 && strings.HasPrefix(part, word)) {
+				found++
+				break
+			}
+		}
+	}
+	return significant > 0 && found*2 > significant
+}
@@ -0,0 +1,139 @@
+package bankstatement
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const signedStatement = "\ufeffDate,Description,Amount,Balance\n" +
+	"2024-05-02,ACME CORP INV-2024-007,\"1,250.00\",5250.00\n" +
+	"2024-05-03,Coffee,-4.50,5245.50\n" +
+	"2024-05-04,Globex payment,(20.00),5225.50\n" +
+	",,,\n" +
+	"yesterday,Transfer,100.00,5325.50\n" +
+	"2024-05-06,Refund,lots,5325.50\n"
+
+const creditStatement = `Posted Date,Payee,Memo,Debit,Credit
+03/05/2024,Initech,March invoice,,$800.00
+03/06/2024,Office Supplies,,45.00,
+`
+
+func TestRead(t *testing.T) {
+	t.Run("signed amount column", func(t *testing.T) {
+		statement, err := Read(strings.NewReader(signedStatement), models.DateFormatISO)
+		require.NoError(t, err)
+		require.Len(t, statement.Deposits, 1, "withdrawals and unreadable rows are left out")
+
+		deposit := statement.Deposits[0]
+		assert.Equal(t, 2, deposit.Row)
+		assert.Equal(t, "2024-05-02", deposit.Date.Format("2006-01-02"))
+		assert.Equal(t, 1250.0, deposit.Amount)
+		assert.Equal(t, "ACME CORP INV-2024-007", deposit.Reference)
+		assert.Equal(t, []string{`Row 6: date "yesterday" not recognised`, `Row 7: amount "lots" not recognised`}, statement.Problems)
+	})
+
+	t.Run("credit column and payer", func(t *testing.T) {
+		statement, err := Read(strings.NewReader(creditStatement), models.DateFormatUS)
+		require.NoError(t, err)
+		require.Len(t, statement.Deposits, 1)
+		assert.Equal(t, "2024-03-05", statement.Deposits[0].Date.Format("2006-01-02"))
+		assert.Equal(t, 800.0, statement.Deposits[0].Amount)
+		assert.Equal(t, "Initech March invoice", statement.Deposits[0].Reference)
+		assert.Empty(t, statement.Problems)
+	})
+
+	t.Run("not a bank statement", func(t *testing.T) {
+		_, err := Read(strings.NewReader("Name,Email\nAnn,ann@example.test\n"), models.DateFormatISO)
+		assert.Error(t, err)
+
+		_, err = Read(strings.NewReader("Date,Description\n2024-05-02,Nothing\n"), models.DateFormatISO)
+		assert.Error(t, err)
+	})
+}
+
+func TestMatchDeposits(t *testing.T) {
+	date := func(value string) time.Time {
+		t, _ := time.Parse("2006-01-02", value)
+		return t
+	}
+	invoice := func(id int, number, client, invoiceDate string, amount float64) models.InvoiceWithClient {
+		return models.InvoiceWithClient{
+			Invoice:    models.Invoice{ID: id, InvoiceNumber: number, InvoiceDate: date(invoiceDate), AmountDue: amount},
+			ClientName: client,
+		}
+	}
+
+	acme := invoice(1, "INV-2024-007", "Acme Corp", "2024-04-01", 1250)
+	globex := invoice(2, "", "Globex Corporation", "2024-04-10", 600)
+	olderAcme := invoice(3, "INV-2024-003", "Acme Corp", "2024-03-01", 400)
+	newerAcme := invoice(4, "INV-2024-009", "Acme Corp", "2024-04-20", 400)
+	later := invoice(5, "INV-2024-012", "Initech", "2024-06-01", 300)
+	invoices := []models.InvoiceWithClient{acme, globex, olderAcme, newerAcme, later}
+
+	t.Run("amount and invoice number", func(t *testing.T) {
+		matches := MatchDeposits([]Deposit{{Row: 2, Date: date("2024-05-02"), Amount: 1250, Reference: "ACME INV2024007"}}, invoices)
+		require.Len(t, matches, 1)
+		require.NotNil(t, matches[0].Invoice)
+		assert.Equal(t, 1, matches[0].Invoice.ID)
+		assert.Equal(t, 1250.0, matches[0].Outstanding)
+		assert.Equal(t, scoreExactAmount+scoreNumber, matches[0].Score)
+		assert.True(t, matches[0].Confident())
+	})
+
+	t.Run("near amount and client name", func(t *testing.T) {
+		matches := MatchDeposits([]Deposit{{Date: date("2024-05-02"), Amount: 595, Reference: "GLOBEX CORP"}}, invoices)
+		require.NotNil(t, matches[0].Invoice)
+		assert.Equal(t, 2, matches[0].Invoice.ID)
+		assert.Equal(t, []string{"amount is 5.00 short", "reference names Globex Corporation"}, matches[0].Reasons)
+		assert.False(t, matches[0].Confident(), "a short payment naming only the client is left for checking")
+	})
+
+	t.Run("number with leading zeros dropped", func(t *testing.T) {
+		globexNumbered := invoice(6, "0123", "Globex Corporation", "2024-04-10", 600)
+		matches := MatchDeposits([]Deposit{{Date: date("2024-05-02"), Amount: 250, Reference: "Payment for invoice 123"}},
+			[]models.InvoiceWithClient{globexNumbered})
+		require.NotNil(t, matches[0].Invoice)
+		assert.Equal(t, 6, matches[0].Invoice.ID)
+	})
+
+	t.Run("oldest invoice first and each invoice once", func(t *testing.T) {
+		deposits := []Deposit{
+			{Row: 2, Date: date("2024-05-02"), Amount: 400, Reference: "Acme Corp"},
+			{Row: 3, Date: date("2024-05-03"), Amount: 400, Reference: "Acme Corp"},
+			{Row: 4, Date: date("2024-05-04"), Amount: 400, Reference: "Acme Corp"},
+		}
+		matches := MatchDeposits(deposits, invoices)
+		require.Len(t, matches, 3)
+		require.NotNil(t, matches[0].Invoice)
+		require.NotNil(t, matches[1].Invoice)
+		assert.Equal(t, 3, matches[0].Invoice.ID)
+		assert.Equal(t, 4, matches[1].Invoice.ID)
+		assert.Nil(t, matches[2].Invoice, "no open invoice is left for the third deposit")
+	})
+
+	t.Run("no resemblance", func(t *testing.T) {
+		matches := MatchDeposits([]Deposit{{Date: date("2024-05-02"), Amount: 77.77, Reference: "Interest"}}, invoices)
+		assert.Nil(t, matches[0].Invoice)
+	})
+
+	t.Run("invoice issued after the deposit", func(t *testing.T) {
+		matches := MatchDeposits([]Deposit{{Date: date("2024-05-02"), Amount: 300, Reference: "INV-2024-012"}}, invoices)
+		assert.Nil(t, matches[0].Invoice)
+	})
+
+	t.Run("part payment already recorded", func(t *testing.T) {
+		paid := 1000.0
+		partlyPaid := acme
+		partlyPaid.AmountPaid = &paid
+		matches := MatchDeposits([]Deposit{{Date: date("2024-05-02"), Amount: 250, Reference: "INV-2024-007"}},
+			[]models.InvoiceWithClient{partlyPaid})
+		require.NotNil(t, matches[0].Invoice)
+		assert.Equal(t, 250.0, matches[0].Outstanding)
+		assert.True(t, matches[0].Confident())
+	})
+}
@@ -0,0 +1,158 @@
+package contacts
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const addressBookQuery = `<?xml version="1.0" encoding="utf-8"?>
+<C:addressbook-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:carddav">
+  <D:prop>
+    <D:getetag/>
+    <C:address-data/>
+  </D:prop>
+</C:addressbook-query>`
+
+// CardDAVSource fetches contacts from an address book collection on a CardDAV server
+type CardDAVSource struct {
+	URL      string
+	Username string
+	Password string
+	Client   *http.Client
+}
+
+type multistatus struct {
+	Responses []struct {
+		Propstats []struct {
+			AddressData string `xml:"prop>address-data"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// FetchContacts runs an addressbook-query REPORT against the collection and parses the returned vCards
+func (s CardDAVSource) FetchContacts(ctx context.Context) ([]Contact, error) {
+	if s.URL == "" {
+		return nil, fmt.Errorf("carddav: address book URL is not configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "REPORT", s.URL, strings.NewReader(addressBookQuery))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	req.Header.Set("Depth", "1")
+	if s.Username != "" {
+		req.SetBasicAuth(s.Username, s.Password)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("carddav: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMultiStatus && resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("carddav: unexpected response status %s", resp.Status)
+	}
+
+	var result multistatus
+	err = xml.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, fmt.Errorf("carddav: failed to parse response: %w", err)
+	}
+
+	var contacts []Contact
+	for _, response := range result.Responses {
+		for _, propstat := range response.Propstats {
+			if strings.TrimSpace(propstat.AddressData) == "" {
+				continue
+			}
+			contacts = append(contacts, ParseVCards(propstat.AddressData)...)
+		}
+	}
+	return contacts, nil
+}
+
+// ParseVCards parses one or more vCards into contacts, using the first email,
+// phone number and address of each card
+func ParseVCards(data string) []Contact {
+	// Unfold continuation lines as described in RFC 6350 section 3.2
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\n ", "")
+	data = strings.ReplaceAll(data, "\n\t", "")
+
+	var contacts []Contact
+	var current *Contact
+	var structuredName string
+	for _, line := range strings.Split(data, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// Drop parameters (e.g. EMAIL;TYPE=work) and group prefixes (e.g. item1.EMAIL)
+		name, _, _ = strings.Cut(name, ";")
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+
+		if name == "BEGIN" && strings.EqualFold(value, "VCARD") {
+			current = &Contact{}
+			structuredName = ""
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch name {
+		case "END":
+			if current.Name == "" {
+				current.Name = structuredName
+			}
+			contacts = append(contacts, *current)
+			current = nil
+		case "FN":
+			current.Name = unescapeVCard(value)
+		case "N":
+			parts := strings.Split(value, ";")
+			if len(parts) >= 2 {
+				structuredName = strings.TrimSpace(unescapeVCard(parts[1]) + " " + unescapeVCard(parts[0]))
+			}
+		case "EMAIL":
+			if current.Email == "" {
+				current.Email = value
+			}
+		case "TEL":
+			if current.Phone == "" {
+				current.Phone = strings.TrimPrefix(value, "tel:")
+			}
+		case "ADR":
+			if current.Address1 == "" {
+				// ADR components: PO box; extended; street; locality; region; postal code; country
+				parts := strings.Split(value, ";")
+				for len(parts) < 7 {
+					parts = append(parts, "")
+				}
+				current.Address1 = unescapeVCard(parts[2])
+				current.City = unescapeVCard(parts[3])
+				current.State = unescapeVCard(parts[4])
+				current.ZipCode = unescapeVCard(parts[5])
+			}
+		}
+	}
+	return contacts
+}
+
+func unescapeVCard(value string) string {
+	replacer := strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return strings.TrimSpace(replacer.Replace(value))
+}
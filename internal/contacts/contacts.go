@@ -0,0 +1,141 @@
+package contacts
+
+import (
+	"context"
+	"strings"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
+)
+
+// Contact represents a person from an external address book
+type Contact struct {
+	Name     string
+	Email    string
+	Phone    string
+	Address1 string
+	City     string
+	State    string
+	ZipCode  string
+}
+
+// Source is an external address book contacts can be fetched from
+type Source interface {
+	FetchContacts(ctx context.Context) ([]Contact, error)
+}
+
+// FieldChange describes a single client field that differs from the address book
+type FieldChange struct {
+	Field    string
+	OldValue string
+	NewValue string
+}
+
+// ClientChange describes the updates an address book contact would make to an existing client
+type ClientChange struct {
+	Client  models.Client
+	Contact Contact
+	Fields  []FieldChange
+}
+
+// SyncPreview is the result of comparing address book contacts against existing clients
+type SyncPreview struct {
+	Changes     []ClientChange
+	NewContacts []Contact
+	Unchanged   int
+}
+
+// Preview matches contacts to clients on email address and returns the changes that
+// applying them would make. Contacts without an email address are ignored.
+func Preview(clients []models.Client, contacts []Contact) SyncPreview {
+	byEmail := make(map[string]models.Client, len(clients))
+	for _, client := range clients {
+		if email := normalizeEmail(client.Email); email != "" {
+			byEmail[email] = client
+		}
+	}
+
+	var preview SyncPreview
+	seen := make(map[string]bool)
+	for _, contact := range contacts {
+		email := normalizeEmail(contact.Email)
+		if email == "" || seen[email] {
+			continue
+		}
+		seen[email] = true
+
+		client, ok := byEmail[email]
+		if !ok {
+			preview.NewContacts = append(preview.NewContacts, contact)
+			continue
+		}
+
+		fields := diffClient(client, contact)
+		if len(fields) == 0 {
+			preview.Unchanged++
+			continue
+		}
+		preview.Changes = append(preview.Changes, ClientChange{
+			Client:  client,
+			Contact: contact,
+			Fields:  fields,
+		})
+	}
+
+	return preview
+}
+
+// ApplyToClient returns a copy of the client with the contact's non-empty details applied
+func ApplyToClient(client models.Client, contact Contact) models.Client {
+	setIfPresent := func(target **string, value string) {
+		if value != "" {
+			v := value
+			*target = &v
+		}
+	}
+	setIfPresent(&client.Phone, contact.Phone)
+	setIfPresent(&client.Address1, contact.Address1)
+	setIfPresent(&client.City, contact.City)
+	setIfPresent(&client.State, contact.State)
+	setIfPresent(&client.ZipCode, contact.ZipCode)
+	return client
+}
+
+// NewClient converts an address book contact into a new client
+func NewClient(contact Contact, hourlyRate float64) models.Client {
+	name := contact.Name
+	if name == "" {
+		name = contact.Email
+	}
+	client := models.Client{
+		Name:                    name,
+		Email:                   strings.TrimSpace(contact.Email),
+		HourlyRate:              hourlyRate,
+		IncludeAddressOnInvoice: true,
+	}
+	return ApplyToClient(client, contact)
+}
+
+// diffClient lists the client fields which the contact would change. Empty contact
+// values never clear existing client data.
+func diffClient(client models.Client, contact Contact) []FieldChange {
+	var fields []FieldChange
+	compare := func(field string, current *string, value string) {
+		old := ""
+		if current != nil {
+			old = *current
+		}
+		if value != "" && value != old {
+			fields = append(fields, FieldChange{Field: field, OldValue: old, NewValue: value})
+		}
+	}
+	compare("Phone", client.Phone, contact.Phone)
+	compare("Address", client.Address1, contact.Address1)
+	compare("City", client.City, contact.City)
+	compare("State", client.State, contact.State)
+	compare("ZIP Code", client.ZipCode, contact.ZipCode)
+	return fields
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
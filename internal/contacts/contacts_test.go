@@ -0,0 +1,193 @@
+package contacts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestPreview(t *testing.T) {
+	clients := []models.Client{
+		{ID: 1, Name: "Acme University", Email: "Billing@Acme.edu", Phone: strPtr("555-0000"), City: strPtr("Springfield")},
+		{ID: 2, Name: "Up To Date", Email: "same@example.com", Phone: strPtr("555-1111")},
+	}
+	fetched := []Contact{
+		{Name: "Acme Billing", Email: "billing@acme.edu", Phone: "555-9999", City: "Springfield", State: "IL"},
+		{Name: "Same", Email: "same@example.com", Phone: "555-1111"},
+		{Name: "New Person", Email: "new@example.com"},
+		{Name: "No Email"},
+		{Name: "Duplicate", Email: "NEW@example.com"},
+	}
+
+	preview := Preview(clients, fetched)
+
+	require.Len(t, preview.Changes, 1)
+	change := preview.Changes[0]
+	assert.Equal(t, 1, change.Client.ID)
+	assert.Equal(t, []FieldChange{
+		{Field: "Phone", OldValue: "555-0000", NewValue: "555-9999"},
+		{Field: "State", OldValue: "", NewValue: "IL"},
+	}, change.Fields)
+
+	require.Len(t, preview.NewContacts, 1)
+	assert.Equal(t, "new@example.com", preview.NewContacts[0].Email)
+	assert.Equal(t, 1, preview.Unchanged)
+}
+
+func TestApplyToClient(t *testing.T) {
+	client := models.Client{ID: 1, Name: "Acme", Email: "a@acme.edu", Phone: strPtr("555-0000"), City: strPtr("Springfield")}
+
+	updated := ApplyToClient(client, Contact{Phone: "555-9999", State: "IL"})
+
+	assert.Equal(t, "555-9999", *updated.Phone)
+	assert.Equal(t, "Springfield", *updated.City)
+	assert.Equal(t, "IL", *updated.State)
+	assert.Equal(t, "555-0000", *client.Phone, "original client should not be modified")
+}
+
+func TestParseVCards(t *testing.T) {
+	data := "BEGIN:VCARD\r\n" +
+		"VERSION:3.0\r\n" +
+		"N:Doe;Jane;;;\r\n" +
+		"EMAIL;TYPE=work:jane@example.com\r\n" +
+		"EMAIL;TYPE=home:jane.home@example.com\r\n" +
+		"item1.TEL;TYPE=cell:+1 555 0100\r\n" +
+		"ADR;TYPE=work:;;123 Main St\\, Suite 4;Springfield;IL;62701;USA\r\n" +
+		"END:VCARD\r\n" +
+		"BEGIN:VCARD\r\n" +
+		"VERSION:4.0\r\n" +
+		"FN:Long Name Wi\r\n" +
+		" th Folding\r\n" +
+		"EMAIL:long@example.com\r\n" +
+		"TEL;VALUE=uri:tel:+1-555-0199\r\n" +
+		"END:VCARD\r\n"
+
+	contacts := ParseVCards(data)
+
+	require.Len(t, contacts, 2)
+	assert.Equal(t, Contact{
+		Name:     "Jane Doe",
+		Email:    "jane@example.com",
+		Phone:    "+1 555 0100",
+		Address1: "123 Main St, Suite 4",
+		City:     "Springfield",
+		State:    "IL",
+		ZipCode:  "62701",
+	}, contacts[0])
+	assert.Equal(t, "Long Name With Folding", contacts[1].Name)
+	assert.Equal(t, "+1-555-0199", contacts[1].Phone)
+}
+
+func TestCardDAVSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "REPORT", r.Method)
+		assert.Equal(t, "1", r.Header.Get("Depth"))
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "secret", password)
+
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusMultiStatus)
+		w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?>
+<d:multistatus xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">
+  <d:response>
+    <d:href>/addressbooks/user/contacts/1.vcf</d:href>
+    <d:propstat>
+      <d:prop>
+        <d:getetag>"1"</d:getetag>
+        <card:address-data>BEGIN:VCARD
+VERSION:3.0
+FN:Jane Doe
+EMAIL:jane@example.com
+END:VCARD
+</card:address-data>
+      </d:prop>
+      <d:status>HTTP/1.1 200 OK</d:status>
+    </d:propstat>
+  </d:response>
+</d:multistatus>`))
+	}))
+	defer server.Close()
+
+	source := CardDAVSource{URL: server.URL, Username: "user", Password: "secret"}
+	contacts, err := source.FetchContacts(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, contacts, 1)
+	assert.Equal(t, "Jane Doe", contacts[0].Name)
+	assert.Equal(t, "jane@example.com", contacts[0].Email)
+
+	t.Run("server error", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer failing.Close()
+
+		_, err := CardDAVSource{URL: failing.URL}.FetchContacts(context.Background())
+		assert.ErrorContains(t, err, "401")
+	})
+}
+
+func TestGoogleSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/people/me/connections", r.URL.Path)
+		assert.Equal(t, "Bearer token123", r.Header.Get("Authorization"))
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("pageToken") == "" {
+			w.Write([]byte(`{"connections":[{"names":[{"displayName":"Jane Doe"}],"emailAddresses":[{"value":"jane@example.com"}],
+				"addresses":[{"streetAddress":"1 Main St","city":"Springfield","region":"IL","postalCode":"62701"}]}],
+				"nextPageToken":"page2"}`))
+			return
+		}
+		w.Write([]byte(`{"connections":[{"names":[{"displayName":"John Roe"}],"emailAddresses":[{"value":"john@example.com"}],"phoneNumbers":[{"value":"555-0101"}]}]}`))
+	}))
+	defer server.Close()
+
+	source := GoogleSource{AccessToken: "token123", BaseURL: server.URL}
+	contacts, err := source.FetchContacts(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, contacts, 2)
+	assert.Equal(t, Contact{Name: "Jane Doe", Email: "jane@example.com", Address1: "1 Main St", City: "Springfield", State: "IL", ZipCode: "62701"}, contacts[0])
+	assert.Equal(t, "555-0101", contacts[1].Phone)
+
+	t.Run("expired token", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer failing.Close()
+
+		_, err := GoogleSource{AccessToken: "stale", BaseURL: failing.URL}.FetchContacts(context.Background())
+		assert.ErrorContains(t, err, "it may have expired")
+	})
+}
+
+func TestCSVSource(t *testing.T) {
+	t.Run("google contacts export", func(t *testing.T) {
+		data := "First Name,Last Name,E-mail 1 - Value,Phone 1 - Value,Address 1 - Street,Address 1 - City,Address 1 - Region,Address 1 - Postal Code\n" +
+			"Jane,Doe,jane@example.com,555-0100,1 Main St,Springfield,IL,62701\n"
+
+		contacts, err := CSVSource{Reader: strings.NewReader(data)}.FetchContacts(context.Background())
+
+		require.NoError(t, err)
+		require.Len(t, contacts, 1)
+		assert.Equal(t, Contact{Name: "Jane Doe", Email: "jane@example.com", Phone: "555-0100", Address1: "1 Main St", City: "Springfield", State: "IL", ZipCode: "62701"}, contacts[0])
+	})
+
+	t.Run("missing email column", func(t *testing.T) {
+		_, err := CSVSource{Reader: strings.NewReader("Name,Phone\nJane,555\n")}.FetchContacts(context.Background())
+		assert.ErrorContains(t, err, "no email column")
+	})
+}
@@ -0,0 +1,97 @@
+package contacts
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CSVSource reads contacts from a CSV address book export, such as the Google Contacts
+// or Outlook CSV formats. Columns are matched by header name.
+type CSVSource struct {
+	Reader io.Reader
+}
+
+// csvColumns lists the header names recognised for each contact field, in order of preference
+var csvColumns = map[string][]string{
+	"name":       {"name", "full name", "display name"},
+	"first_name": {"first name", "given name"},
+	"last_name":  {"last name", "family name"},
+	"email":      {"email", "e-mail", "e-mail 1 - value", "email address", "e-mail address"},
+	"phone":      {"phone", "phone 1 - value", "mobile phone", "business phone", "primary phone"},
+	"address":    {"address", "street", "address 1 - street", "business street", "home street"},
+	"city":       {"city", "address 1 - city", "business city", "home city"},
+	"state":      {"state", "region", "address 1 - region", "business state", "home state"},
+	"zip":        {"zip", "zip code", "postal code", "address 1 - postal code", "business postal code", "home postal code"},
+}
+
+// FetchContacts parses the CSV data into contacts
+func (s CSVSource) FetchContacts(ctx context.Context) ([]Contact, error) {
+	reader := csv.NewReader(s.Reader)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv contacts: failed to read header: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if _, exists := index[column]; !exists {
+			index[column] = i
+		}
+	}
+
+	columns := make(map[string]int, len(csvColumns))
+	for field, names := range csvColumns {
+		columns[field] = -1
+		for _, name := range names {
+			if i, ok := index[name]; ok {
+				columns[field] = i
+				break
+			}
+		}
+	}
+	if columns["email"] < 0 {
+		return nil, fmt.Errorf("csv contacts: no email column found")
+	}
+
+	var contacts []Contact
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv contacts: %w", err)
+		}
+
+		value := func(field string) string {
+			i := columns[field]
+			if i < 0 || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		name := value("name")
+		if name == "" {
+			name = strings.TrimSpace(value("first_name") + " " + value("last_name"))
+		}
+
+		contacts = append(contacts, Contact{
+			Name:     name,
+			Email:    value("email"),
+			Phone:    value("phone"),
+			Address1: value("address"),
+			City:     value("city"),
+			State:    value("state"),
+			ZipCode:  value("zip"),
+		})
+	}
+	return contacts, nil
+}
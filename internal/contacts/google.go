@@ -0,0 +1,125 @@
+package contacts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const googlePeopleAPI = "https://people.googleapis.com"
+
+// GoogleSource fetches contacts from Google Contacts through the People API using an OAuth access token.
+// Google's access tokens expire after about an hour, after which a fresh one is needed.
+type GoogleSource struct {
+	AccessToken string
+	BaseURL     string
+	Client      *http.Client
+}
+
+type googleConnections struct {
+	Connections []struct {
+		Names []struct {
+			DisplayName string `json:"displayName"`
+		} `json:"names"`
+		EmailAddresses []struct {
+			Value string `json:"value"`
+		} `json:"emailAddresses"`
+		PhoneNumbers []struct {
+			Value string `json:"value"`
+		} `json:"phoneNumbers"`
+		Addresses []struct {
+			StreetAddress string `json:"streetAddress"`
+			City          string `json:"city"`
+			Region        string `json:"region"`
+			PostalCode    string `json:"postalCode"`
+		} `json:"addresses"`
+	} `json:"connections"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// FetchContacts retrieves all of the authenticated user's contacts, following pagination
+func (s GoogleSource) FetchContacts(ctx context.Context) ([]Contact, error) {
+	if s.AccessToken == "" {
+		return nil, fmt.Errorf("google contacts: access token is not configured")
+	}
+
+	baseURL := s.BaseURL
+	if baseURL == "" {
+		baseURL = googlePeopleAPI
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var contacts []Contact
+	pageToken := ""
+	for {
+		query := url.Values{}
+		query.Set("personFields", "names,emailAddresses,phoneNumbers,addresses")
+		query.Set("pageSize", "1000")
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/v1/people/me/connections?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+s.AccessToken)
+
+		page, err := s.fetchPage(client, req)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, person := range page.Connections {
+			var contact Contact
+			if len(person.Names) > 0 {
+				contact.Name = person.Names[0].DisplayName
+			}
+			if len(person.EmailAddresses) > 0 {
+				contact.Email = person.EmailAddresses[0].Value
+			}
+			if len(person.PhoneNumbers) > 0 {
+				contact.Phone = person.PhoneNumbers[0].Value
+			}
+			if len(person.Addresses) > 0 {
+				contact.Address1 = person.Addresses[0].StreetAddress
+				contact.City = person.Addresses[0].City
+				contact.State = person.Addresses[0].Region
+				contact.ZipCode = person.Addresses[0].PostalCode
+			}
+			contacts = append(contacts, contact)
+		}
+
+		if page.NextPageToken == "" {
+			return contacts, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+func (s GoogleSource) fetchPage(client *http.Client, req *http.Request) (googleConnections, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return googleConnections{}, fmt.Errorf("google contacts: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return googleConnections{}, fmt.Errorf("google contacts: the access token was rejected, it may have expired")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return googleConnections{}, fmt.Errorf("google contacts: unexpected response status %s", resp.Status)
+	}
+
+	var page googleConnections
+	err = json.NewDecoder(resp.Body).Decode(&page)
+	if err != nil {
+		return googleConnections{}, fmt.Errorf("google contacts: failed to parse response: %w", err)
+	}
+	return page, nil
+}
@@ -0,0 +1,219 @@
+// Package cron parses the five field cron expressions recurring tasks are scheduled with,
+// such as "0 0 1 * *" for midnight on the first of every month, and works out when they
+// next fall due
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// macros are the shorthand expressions accepted in place of the five fields
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field describes one of the five fields of an expression
+type field struct {
+	name  string
+	min   int
+	max   int
+	names []string
+}
+
+var fields = [5]field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// searchLimit is how far ahead Next looks before deciding an expression never falls due,
+// as "0 0 30 2 *" never does
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+// Schedule is a parsed cron expression. Each field is a bit set of the values it matches.
+type Schedule struct {
+	expr   string
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// A day matches when either day field does if both are restricted, as in classic cron
+	domAny bool
+	dowAny bool
+}
+
+// Parse reads a cron expression of five space separated fields: minute, hour, day of
+// month, month and day of week. Each field is *, a value, a range such as 1-5, a step such
+// as */15 or 1-30/2, or a comma separated list of these. Months and days of the week can
+// also be given by their three letter English names, and Sunday is either 0 or 7. The
+// macros @hourly, @daily, @midnight, @weekly, @monthly, @yearly and @annually are accepted
+// too.
+func Parse(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	text := expr
+	if strings.HasPrefix(text, "@") {
+		expanded, ok := macros[strings.ToLower(text)]
+		if !ok {
+			return Schedule{}, fmt.Errorf("unknown macro %s", text)
+		}
+		text = expanded
+	}
+
+	parts := strings.Fields(text)
+	if len(parts) != len(fields) {
+		return Schedule{}, fmt.Errorf("expected 5 fields (minute hour day month weekday), found %d", len(parts))
+	}
+
+	var sets [5]uint64
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return Schedule{}, err
+		}
+		sets[i] = set
+	}
+
+	// Sunday can be written as 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] = sets[4]&^(1<<7) | 1
+	}
+	return Schedule{
+		expr:   expr,
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		domAny: strings.HasPrefix(parts[2], "*"),
+		dowAny: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// Validate returns the reason Parse rejects expr, or nil when it is a valid expression
+func Validate(expr string) error {
+	_, err := Parse(expr)
+	return err
+}
+
+// parseField reads one field of an expression into a bit set of the values it matches
+func parseField(text string, f field) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(text, ",") {
+		rangeText, stepText, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("%s step %q must be a positive number", f.name, stepText)
+			}
+			step = n
+		}
+
+		var low, high int
+		switch {
+		case rangeText == "*":
+			low, high = f.min, f.max
+		case strings.Contains(rangeText, "-"):
+			lowText, highText, _ := strings.Cut(rangeText, "-")
+			var err error
+			if low, err = parseValue(lowText, f); err != nil {
+				return 0, err
+			}
+			if high, err = parseValue(highText, f); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("%s range %q runs backwards", f.name, rangeText)
+			}
+		default:
+			value, err := parseValue(rangeText, f)
+			if err != nil {
+				return 0, err
+			}
+			low, high = value, value
+			// A step from a single value runs to the end of the field, as in 5/15
+			if hasStep {
+				high = f.max
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// parseValue reads a single number or name in a field
+func parseValue(text string, f field) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(text, name) {
+			return i + f.min, nil
+		}
+	}
+	value, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("%s %q is not a number", f.name, text)
+	}
+	if value < f.min || value > f.max {
+		return 0, fmt.Errorf("%s %d must be between %d and %d", f.name, value, f.min, f.max)
+	}
+	return value, nil
+}
+
+// String returns the expression the schedule was parsed from
+func (s Schedule) String() string {
+	return s.expr
+}
+
+// Next returns the first time after t that the schedule falls due, in t's location, or
+// the zero time when it never does
+func (s Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(searchLimit)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay reports whether the schedule runs on t's day
+func (s Schedule) matchesDay(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,82 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"":             "expected 5 fields",
+		"0 0 * *":      "expected 5 fields",
+		"60 * * * *":   "minute 60 must be between 0 and 59",
+		"* 24 * * *":   "hour 24 must be between 0 and 23",
+		"* * 0 * *":    "day of month 0 must be between 1 and 31",
+		"* * * 13 *":   "month 13 must be between 1 and 12",
+		"* * * * 8":    "day of week 8 must be between 0 and 7",
+		"*/0 * * * *":  "must be a positive number",
+		"5-1 * * * *":  "runs backwards",
+		"a * * * *":    `minute "a" is not a number`,
+		"@fortnightly": "unknown macro",
+	}
+	for expr, message := range tests {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Parse(expr)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), message)
+			assert.Error(t, Validate(expr))
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	at := func(value string) time.Time {
+		t, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+
+	tests := []struct {
+		expr  string
+		after string
+		want  string
+	}{
+		{"* * * * *", "2024-05-02 10:15", "2024-05-02 10:16"},
+		{"0 0 1 * *", "2024-05-02 10:15", "2024-06-01 00:00"},
+		{"@monthly", "2024-12-31 23:59", "2025-01-01 00:00"},
+		{"@daily", "2024-05-02 00:00", "2024-05-03 00:00"},
+		{"*/15 9-17 * * *", "2024-05-02 10:16", "2024-05-02 10:30"},
+		{"*/15 9-17 * * *", "2024-05-02 17:50", "2024-05-03 09:00"},
+		{"30 8 * * mon-fri", "2024-05-03 09:00", "2024-05-06 08:30"},
+		{"0 12 * * 7", "2024-05-02 00:00", "2024-05-05 12:00"},
+		{"0 0 29 feb *", "2024-03-01 00:00", "2028-02-29 00:00"},
+		{"5/20 * * * *", "2024-05-02 10:26", "2024-05-02 10:45"},
+		{"0 0 1,15 * *", "2024-05-02 00:00", "2024-05-15 00:00"},
+		// With both day fields restricted either one matching is enough
+		{"0 0 13 * fri", "2024-05-02 00:00", "2024-05-03 00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr+" after "+tt.after, func(t *testing.T) {
+			schedule, err := Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, at(tt.want), schedule.Next(at(tt.after)))
+		})
+	}
+
+	t.Run("never due", func(t *testing.T) {
+		schedule, err := Parse("0 0 30 2 *")
+		require.NoError(t, err)
+		assert.True(t, schedule.Next(at("2024-01-01 00:00")).IsZero())
+	})
+
+	t.Run("keeps the expression", func(t *testing.T) {
+		schedule, err := Parse(" @weekly ")
+		require.NoError(t, err)
+		assert.Equal(t, "@weekly", schedule.String())
+	})
+}
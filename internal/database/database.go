@@ -1,17 +1,23 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/pressly/goose/v3"
 
 	_ "modernc.org/sqlite"
 )
 
-// OpenDB opens a SQLite database connection
+// OpenDB opens a SQLite database connection with foreign keys enforced
 func OpenDB(dsn string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", dsn)
+	db, err := sql.Open("sqlite", withPragmas(dsn))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -25,15 +31,152 @@ func OpenDB(dsn string) (*sql.DB, error) {
 	return db, nil
 }
 
-// RunMigrations runs database migrations using goose for SQLite
+// withPragmas adds the pragmas that enforce foreign keys and wait for locks to dsn. SQLite
+// leaves foreign keys off by default, and without a busy timeout a write fails at once while
+// the job queue or session store is writing. Both are per connection, so the driver applies
+// them from the DSN.
+func withPragmas(dsn string) string {
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return dsn + separator + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+}
+
+// Migration describes one migration file and whether it has been applied to the database
+type Migration struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// MigrateOptions controls how Migrate moves the schema. Down rolls back that many of the
+// most recently applied migrations instead of applying pending ones, and DryRun reports
+// the migrations that would run without touching the database.
+type MigrateOptions struct {
+	DryRun bool
+	Down   int
+}
+
+// RunMigrations applies every pending migration using goose for SQLite
 func RunMigrations(db *sql.DB, migrationsDir string) error {
-	if err := goose.SetDialect("sqlite3"); err != nil {
-		return fmt.Errorf("failed to set goose dialect: %w", err)
+	_, err := Migrate(db, migrationsDir, MigrateOptions{})
+	return err
+}
+
+// Migrate applies pending migrations, or rolls back applied ones when opts.Down is set, and
+// returns the migrations it ran in the order it ran them. With opts.DryRun it returns the
+// same list without running anything.
+func Migrate(db *sql.DB, migrationsDir string, opts MigrateOptions) ([]Migration, error) {
+	if opts.Down < 0 {
+		return nil, fmt.Errorf("cannot roll back %d migrations", opts.Down)
 	}
 
-	if err := goose.Up(db, migrationsDir); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+	provider, err := newProvider(db, migrationsDir)
+	if err != nil {
+		return nil, err
 	}
+	ctx := context.Background()
 
-	return nil
+	status, err := migrationStatus(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	planned := plannedMigrations(status, opts.Down)
+	if opts.DryRun {
+		return planned, nil
+	}
+
+	if opts.Down == 0 {
+		if _, err := provider.Up(ctx); err != nil {
+			return nil, fmt.Errorf("failed to run migrations: %w", err)
+		}
+		return planned, nil
+	}
+
+	for i := range planned {
+		if _, err := provider.Down(ctx); err != nil {
+			if errors.Is(err, goose.ErrNoNextVersion) {
+				break
+			}
+			return planned[:i], fmt.Errorf("failed to roll back migration %d: %w", planned[i].Version, err)
+		}
+	}
+	return planned, nil
+}
+
+// MigrationStatus lists every migration in migrationsDir, oldest first, with whether it
+// has been applied
+func MigrationStatus(db *sql.DB, migrationsDir string) ([]Migration, error) {
+	provider, err := newProvider(db, migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	return migrationStatus(context.Background(), provider)
+}
+
+// SchemaVersion returns the version of the newest migration applied to the database, or
+// zero before any have run
+func SchemaVersion(db *sql.DB, migrationsDir string) (int64, error) {
+	migrations, err := MigrationStatus(db, migrationsDir)
+	if err != nil {
+		return 0, err
+	}
+	var version int64
+	for _, m := range migrations {
+		if m.Applied && m.Version > version {
+			version = m.Version
+		}
+	}
+	return version, nil
+}
+
+// newProvider creates a goose provider for the SQLite migrations in migrationsDir
+func newProvider(db *sql.DB, migrationsDir string) (*goose.Provider, error) {
+	provider, err := goose.NewProvider(goose.DialectSQLite3, db, os.DirFS(migrationsDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return provider, nil
+}
+
+// migrationStatus converts the provider's status into Migrations
+func migrationStatus(ctx context.Context, provider *goose.Provider) ([]Migration, error) {
+	status, err := provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration status: %w", err)
+	}
+
+	migrations := make([]Migration, len(status))
+	for i, s := range status {
+		migrations[i] = Migration{
+			Version:   s.Source.Version,
+			Name:      filepath.Base(s.Source.Path),
+			Applied:   s.State == goose.StateApplied,
+			AppliedAt: s.AppliedAt,
+		}
+	}
+	return migrations, nil
+}
+
+// plannedMigrations picks the migrations an up or down run would touch: every pending
+// migration oldest first, or the last down applied migrations newest first
+func plannedMigrations(status []Migration, down int) []Migration {
+	var planned []Migration
+	if down == 0 {
+		for _, m := range status {
+			if !m.Applied {
+				planned = append(planned, m)
+			}
+		}
+		return planned
+	}
+
+	for i := len(status) - 1; i >= 0 && len(planned) < down; i-- {
+		if status[i].Applied {
+			planned = append(planned, status[i])
+		}
+	}
+	return planned
 }
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeMigrations creates a migrations directory holding three small goose migrations
+func writeMigrations(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"001_create_a.sql": "-- +goose Up\nCREATE TABLE a (id INTEGER);\n\n-- +goose Down\nDROP TABLE a;\n",
+		"002_create_b.sql": "-- +goose Up\nCREATE TABLE b (id INTEGER);\n\n-- +goose Down\nDROP TABLE b;\n",
+		"003_create_c.sql": "-- +goose Up\nCREATE TABLE c (id INTEGER);\n\n-- +goose Down\nDROP TABLE c;\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return dir
+}
+
+func versions(migrations []Migration) []int64 {
+	result := make([]int64, len(migrations))
+	for i, m := range migrations {
+		result[i] = m.Version
+	}
+	return result
+}
+
+func tableExists(t *testing.T, dsn string, name string) bool {
+	db, err := OpenDB(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count)
+	require.NoError(t, err)
+	return count > 0
+}
+
+func TestMigrate(t *testing.T) {
+	dir := writeMigrations(t)
+	dsn := filepath.Join(t.TempDir(), "test.db")
+	db, err := OpenDB(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	t.Run("status before migrating lists everything as pending", func(t *testing.T) {
+		status, err := MigrationStatus(db, dir)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1, 2, 3}, versions(status))
+		assert.Equal(t, "001_create_a.sql", status[0].Name)
+		for _, m := range status {
+			assert.False(t, m.Applied)
+		}
+
+		version, err := SchemaVersion(db, dir)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), version)
+	})
+
+	t.Run("dry run reports pending migrations without applying them", func(t *testing.T) {
+		planned, err := Migrate(db, dir, MigrateOptions{DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1, 2, 3}, versions(planned))
+		assert.False(t, tableExists(t, dsn, "a"))
+	})
+
+	t.Run("up applies pending migrations", func(t *testing.T) {
+		require.NoError(t, RunMigrations(db, dir))
+		assert.True(t, tableExists(t, dsn, "c"))
+
+		status, err := MigrationStatus(db, dir)
+		require.NoError(t, err)
+		for _, m := range status {
+			assert.True(t, m.Applied)
+			assert.False(t, m.AppliedAt.IsZero())
+		}
+
+		planned, err := Migrate(db, dir, MigrateOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, planned)
+
+		version, err := SchemaVersion(db, dir)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), version)
+	})
+
+	t.Run("dry run down reports the newest applied migrations", func(t *testing.T) {
+		planned, err := Migrate(db, dir, MigrateOptions{DryRun: true, Down: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{3, 2}, versions(planned))
+		assert.True(t, tableExists(t, dsn, "c"))
+	})
+
+	t.Run("down rolls back the newest applied migrations", func(t *testing.T) {
+		rolledBack, err := Migrate(db, dir, MigrateOptions{Down: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{3, 2}, versions(rolledBack))
+		assert.True(t, tableExists(t, dsn, "a"))
+		assert.False(t, tableExists(t, dsn, "b"))
+
+		status, err := MigrationStatus(db, dir)
+		require.NoError(t, err)
+		assert.True(t, status[0].Applied)
+		assert.False(t, status[1].Applied)
+		assert.False(t, status[2].Applied)
+	})
+
+	t.Run("down stops when nothing is left to roll back", func(t *testing.T) {
+		rolledBack, err := Migrate(db, dir, MigrateOptions{Down: 5})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1}, versions(rolledBack))
+		assert.False(t, tableExists(t, dsn, "a"))
+	})
+
+	t.Run("negative down is rejected", func(t *testing.T) {
+		_, err := Migrate(db, dir, MigrateOptions{Down: -1})
+		assert.Error(t, err)
+	})
+}
+
+func TestOpenInstrumentedDB(t *testing.T) {
+	var logs bytes.Buffer
+	stats := &QueryStats{}
+	db, err := OpenInstrumentedDB(filepath.Join(t.TempDir(), "test.db"), Instrumentation{
+		Logger:    slog.New(slog.NewJSONHandler(&logs, nil)),
+		SlowQuery: time.Nanosecond,
+		Stats:     stats,
+	})
+	require.NoError(t, err)
+	defer db.Close()
+
+	before := stats.Snapshot()
+	_, err = db.Exec("CREATE TABLE client (name TEXT)")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO client (name) VALUES (?)", "Secret Client Ltd")
+	require.NoError(t, err)
+
+	var name string
+	require.NoError(t, db.QueryRow("SELECT name FROM client WHERE name = ?", "Secret Client Ltd").Scan(&name))
+	assert.Equal(t, "Secret Client Ltd", name)
+
+	stmt, err := db.Prepare("SELECT COUNT(*) FROM client")
+	require.NoError(t, err)
+	defer stmt.Close()
+	var count int
+	require.NoError(t, stmt.QueryRow().Scan(&count))
+
+	ran := stats.Snapshot().Sub(before)
+	assert.Equal(t, int64(4), ran.Queries, "statements and prepared statements are counted")
+	assert.Greater(t, ran.Duration, time.Duration(0))
+
+	assert.Contains(t, logs.String(), `"msg":"slow query"`)
+	assert.Contains(t, logs.String(), `"query":"INSERT INTO client (name) VALUES (?)"`)
+	assert.Contains(t, logs.String(), `"args":1`)
+	assert.NotContains(t, logs.String(), "Secret Client", "parameters are left out of the log")
+}
+
+func TestOpenInstrumentedDBSlowQueryOff(t *testing.T) {
+	var logs bytes.Buffer
+	db, err := OpenInstrumentedDB(filepath.Join(t.TempDir(), "test.db"), Instrumentation{
+		Logger: slog.New(slog.NewJSONHandler(&logs, nil)),
+	})
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE TABLE client (name TEXT)")
+	require.NoError(t, err)
+	assert.Empty(t, logs.String())
+}
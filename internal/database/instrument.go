@@ -0,0 +1,242 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// QueryStats counts the queries run through an instrumented database and the time they took.
+// It is safe for concurrent use.
+type QueryStats struct {
+	queries  atomic.Int64
+	duration atomic.Int64
+}
+
+// QuerySnapshot is the running totals of a QueryStats at one moment
+type QuerySnapshot struct {
+	Queries  int64
+	Duration time.Duration
+}
+
+// Snapshot returns the totals so far. Subtracting an earlier snapshot gives the queries run
+// in between.
+func (s *QueryStats) Snapshot() QuerySnapshot {
+	return QuerySnapshot{
+		Queries:  s.queries.Load(),
+		Duration: time.Duration(s.duration.Load()),
+	}
+}
+
+// Sub returns the queries run between an earlier snapshot and this one
+func (s QuerySnapshot) Sub(earlier QuerySnapshot) QuerySnapshot {
+	return QuerySnapshot{
+		Queries:  s.Queries - earlier.Queries,
+		Duration: s.Duration - earlier.Duration,
+	}
+}
+
+func (s *QueryStats) record(elapsed time.Duration) {
+	s.queries.Add(1)
+	s.duration.Add(int64(elapsed))
+}
+
+// Instrumentation controls what an instrumented database records. Queries taking at least
+// SlowQuery are logged to Logger, with the statement but not its parameters, which can hold
+// client details; zero logs none. Every query is counted in Stats when it is set.
+type Instrumentation struct {
+	Logger    *slog.Logger
+	SlowQuery time.Duration
+	Stats     *QueryStats
+}
+
+// OpenInstrumentedDB opens a SQLite database as OpenDB does, timing every statement run on it
+func OpenInstrumentedDB(dsn string, inst Instrumentation) (*sql.DB, error) {
+	db := sql.OpenDB(&instrumentedConnector{
+		dsn:    withPragmas(dsn),
+		driver: &sqlite.Driver{},
+		inst:   inst,
+	})
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return db, nil
+}
+
+// observe counts a statement that took elapsed to run and logs it if it was slow
+func (inst Instrumentation) observe(ctx context.Context, query string, args int, elapsed time.Duration, err error) {
+	if inst.Stats != nil {
+		inst.Stats.record(elapsed)
+	}
+	if inst.Logger == nil || inst.SlowQuery <= 0 || elapsed < inst.SlowQuery {
+		return
+	}
+	attrs := []any{
+		"query", strings.Join(strings.Fields(query), " "),
+		"args", args,
+		"duration", elapsed.String(),
+	}
+	if err != nil {
+		attrs = append(attrs, "error", err.Error())
+	}
+	inst.Logger.WarnContext(ctx, "slow query", attrs...)
+}
+
+// instrumentedConnector opens connections to the SQLite driver wrapped in instrumentedConn
+type instrumentedConnector struct {
+	dsn    string
+	driver *sqlite.Driver
+	inst   Instrumentation
+}
+
+func (c *instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{Conn: conn, inst: c.inst}, nil
+}
+
+func (c *instrumentedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// instrumentedConn times the statements run on a driver connection. It passes everything
+// else straight through; the context interfaces fall back to the driver's ErrSkip so
+// database/sql takes its slower route when the underlying connection lacks them.
+type instrumentedConn struct {
+	driver.Conn
+	inst Instrumentation
+}
+
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		c.inst.observe(ctx, query, len(args), time.Since(start), err)
+	}
+	return result, err
+}
+
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		c.inst.observe(ctx, query, len(args), time.Since(start), err)
+	}
+	return rows, err
+}
+
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedStmt{Stmt: stmt, query: query, inst: c.inst}, nil
+}
+
+func (c *instrumentedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// instrumentedStmt times the runs of a prepared statement
+type instrumentedStmt struct {
+	driver.Stmt
+	query string
+	inst  Instrumentation
+}
+
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var result driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			result, err = s.Stmt.Exec(values)
+		}
+	}
+	s.inst.observe(ctx, s.query, len(args), time.Since(start), err)
+	return result, err
+}
+
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			rows, err = s.Stmt.Query(values)
+		}
+	}
+	s.inst.observe(ctx, s.query, len(args), time.Since(start), err)
+	return rows, err
+}
+
+// namedValues converts positional arguments for drivers that don't take named ones
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, fmt.Errorf("driver does not support named parameter %q", arg.Name)
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
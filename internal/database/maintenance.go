@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaintenanceTask is one of the housekeeping commands SQLite can be given while the
+// application is running
+type MaintenanceTask string
+
+// The maintenance tasks, in the order they are best run together: checkpointing first so
+// that VACUUM doesn't copy the write-ahead log, and ANALYZE last so its statistics describe
+// the vacuumed file.
+const (
+	TaskCheckpoint MaintenanceTask = "checkpoint"
+	TaskVacuum     MaintenanceTask = "vacuum"
+	TaskAnalyze    MaintenanceTask = "analyze"
+)
+
+// MaintenanceTasks lists every maintenance task in the order Maintain runs them
+var MaintenanceTasks = []MaintenanceTask{TaskCheckpoint, TaskVacuum, TaskAnalyze}
+
+// ParseMaintenanceTasks reads a comma separated list of maintenance tasks, such as
+// "checkpoint,vacuum", and returns them in the order they should run
+func ParseMaintenanceTasks(list string) ([]MaintenanceTask, error) {
+	asked := map[MaintenanceTask]bool{}
+	for _, name := range strings.Split(list, ",") {
+		task := MaintenanceTask(strings.ToLower(strings.TrimSpace(name)))
+		if task == "" {
+			continue
+		}
+		if !task.valid() {
+			return nil, fmt.Errorf("unknown maintenance task %q, expected checkpoint, vacuum or analyze", name)
+		}
+		asked[task] = true
+	}
+
+	var tasks []MaintenanceTask
+	for _, task := range MaintenanceTasks {
+		if asked[task] {
+			tasks = append(tasks, task)
+		}
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("no maintenance tasks given")
+	}
+	return tasks, nil
+}
+
+// valid reports whether t is one of MaintenanceTasks
+func (t MaintenanceTask) valid() bool {
+	for _, task := range MaintenanceTasks {
+		if t == task {
+			return true
+		}
+	}
+	return false
+}
+
+// Size is how much of the database file is in use and how much is free pages that
+// VACUUM would give back
+type Size struct {
+	Bytes     int64
+	FreeBytes int64
+}
+
+// MaintenanceStep reports how one maintenance task went
+type MaintenanceStep struct {
+	Task     MaintenanceTask
+	Detail   string
+	Before   Size
+	After    Size
+	Duration time.Duration
+}
+
+// String describes the step on one line, for logs and job results
+func (s MaintenanceStep) String() string {
+	return fmt.Sprintf("%s: %s; %d bytes before, %d after, in %s",
+		s.Task, s.Detail, s.Before.Bytes, s.After.Bytes, s.Duration.Round(time.Millisecond))
+}
+
+// DatabaseSize measures the database file from its page counts
+func DatabaseSize(ctx context.Context, db *sql.DB) (Size, error) {
+	var pageSize, pages, freePages int64
+	if err := db.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+		return Size{}, fmt.Errorf("reading page size: %w", err)
+	}
+	if err := db.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pages); err != nil {
+		return Size{}, fmt.Errorf("reading page count: %w", err)
+	}
+	if err := db.QueryRowContext(ctx, "PRAGMA freelist_count").Scan(&freePages); err != nil {
+		return Size{}, fmt.Errorf("reading free page count: %w", err)
+	}
+	return Size{Bytes: pages * pageSize, FreeBytes: freePages * pageSize}, nil
+}
+
+// Maintain runs the given maintenance tasks one after another, calling progress as each
+// finishes so that a long VACUUM can be followed, and returns the steps that ran. It stops
+// at the first task that fails.
+func Maintain(ctx context.Context, db *sql.DB, tasks []MaintenanceTask, progress func(MaintenanceStep)) ([]MaintenanceStep, error) {
+	var steps []MaintenanceStep
+	for _, task := range tasks {
+		step, err := maintain(ctx, db, task)
+		if err != nil {
+			return steps, fmt.Errorf("%s: %w", task, err)
+		}
+		steps = append(steps, step)
+		if progress != nil {
+			progress(step)
+		}
+	}
+	return steps, nil
+}
+
+// maintain runs a single maintenance task, measuring the file around it
+func maintain(ctx context.Context, db *sql.DB, task MaintenanceTask) (MaintenanceStep, error) {
+	step := MaintenanceStep{Task: task}
+	before, err := DatabaseSize(ctx, db)
+	if err != nil {
+		return step, err
+	}
+	step.Before = before
+	started := time.Now()
+
+	switch task {
+	case TaskCheckpoint:
+		// TRUNCATE copies every frame of the write-ahead log into the database and empties
+		// the log file, waiting on busy_timeout for readers to finish. A database that isn't
+		// in WAL mode reports -1 frames.
+		var busy, frames, checkpointed int64
+		err := db.QueryRowContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)").Scan(&busy, &frames, &checkpointed)
+		if err != nil {
+			return step, err
+		}
+		switch {
+		case frames < 0:
+			step.Detail = "the database isn't in WAL mode, there is no log to checkpoint"
+		case busy != 0:
+			step.Detail = fmt.Sprintf("checkpointed %d of %d log frames, the rest are still being read", checkpointed, frames)
+		default:
+			step.Detail = fmt.Sprintf("checkpointed %d log frames and emptied the log", checkpointed)
+		}
+	case TaskVacuum:
+		if _, err := db.ExecContext(ctx, "VACUUM"); err != nil {
+			return step, err
+		}
+		step.Detail = fmt.Sprintf("rebuilt the file, reclaiming %d bytes of free pages", before.FreeBytes)
+	case TaskAnalyze:
+		if _, err := db.ExecContext(ctx, "ANALYZE"); err != nil {
+			return step, err
+		}
+		step.Detail = "refreshed the statistics the query planner uses"
+	default:
+		return step, fmt.Errorf("unknown maintenance task")
+	}
+
+	step.Duration = time.Since(started)
+	after, err := DatabaseSize(ctx, db)
+	if err != nil {
+		return step, err
+	}
+	step.After = after
+	return step, nil
+}
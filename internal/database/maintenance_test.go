@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMaintenanceTasks(t *testing.T) {
+	tasks, err := ParseMaintenanceTasks(" Analyze, checkpoint ,,vacuum,analyze")
+	require.NoError(t, err)
+	assert.Equal(t, []MaintenanceTask{TaskCheckpoint, TaskVacuum, TaskAnalyze}, tasks, "tasks run in their own order, once each")
+
+	_, err = ParseMaintenanceTasks("checkpoint,defrag")
+	assert.ErrorContains(t, err, `"defrag"`)
+
+	_, err = ParseMaintenanceTasks(" , ")
+	assert.Error(t, err)
+}
+
+func TestMaintain(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	_, err = db.Exec("CREATE TABLE note (body TEXT)")
+	require.NoError(t, err)
+	for i := 0; i < 200; i++ {
+		_, err = db.Exec("INSERT INTO note (body) VALUES (?)", strings.Repeat("x", 1000))
+		require.NoError(t, err)
+	}
+	_, err = db.Exec("DELETE FROM note")
+	require.NoError(t, err)
+
+	size, err := DatabaseSize(ctx, db)
+	require.NoError(t, err)
+	require.Greater(t, size.FreeBytes, int64(0), "deleting rows leaves free pages behind")
+
+	t.Run("runs each task and reports progress as it goes", func(t *testing.T) {
+		var reported []MaintenanceTask
+		steps, err := Maintain(ctx, db, MaintenanceTasks, func(step MaintenanceStep) {
+			reported = append(reported, step.Task)
+		})
+		require.NoError(t, err)
+		require.Len(t, steps, 3)
+		assert.Equal(t, MaintenanceTasks, reported)
+
+		assert.Contains(t, steps[0].Detail, "isn't in WAL mode")
+		vacuum := steps[1]
+		assert.Equal(t, size, vacuum.Before)
+		assert.Less(t, vacuum.After.Bytes, vacuum.Before.Bytes, "VACUUM shrinks the file")
+		assert.Zero(t, vacuum.After.FreeBytes)
+		assert.Contains(t, vacuum.String(), "vacuum: rebuilt the file")
+
+		var stats int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'sqlite_stat1'").Scan(&stats))
+		assert.Equal(t, 1, stats, "ANALYZE stores its statistics")
+	})
+
+	t.Run("checkpoints the log of a WAL database", func(t *testing.T) {
+		var mode string
+		require.NoError(t, db.QueryRow("PRAGMA journal_mode=WAL").Scan(&mode))
+		require.Equal(t, "wal", mode)
+		_, err := db.Exec("INSERT INTO note (body) VALUES ('logged')")
+		require.NoError(t, err)
+
+		steps, err := Maintain(ctx, db, []MaintenanceTask{TaskCheckpoint}, nil)
+		require.NoError(t, err)
+		require.Len(t, steps, 1)
+		assert.Contains(t, steps[0].Detail, "emptied the log")
+	})
+}
@@ -8,9 +8,32 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 )
 
+const claimClientInvoiceNumber = `-- name: ClaimClientInvoiceNumber :one
+UPDATE client 
+SET next_invoice_number = next_invoice_number + 1 
+WHERE id = ? AND next_invoice_number IS NOT NULL AND deleted_at IS NULL
+RETURNING invoice_prefix, next_invoice_number
+`
+
+type ClaimClientInvoiceNumberRow struct {
+	InvoicePrefix     sql.NullString `json:"invoice_prefix"`
+	NextInvoiceNumber sql.NullInt64  `json:"next_invoice_number"`
+}
+
+func (q *Queries) ClaimClientInvoiceNumber(ctx context.Context, id int64) (ClaimClientInvoiceNumberRow, error) {
+	row := q.db.QueryRowContext(ctx, claimClientInvoiceNumber, id)
+	var i ClaimClientInvoiceNumberRow
+	err := row.Scan(
+		&i.InvoicePrefix,
+		&i.NextInvoiceNumber,
+	)
+	return i, err
+}
+
 const deleteClient = `-- name: DeleteClient :exec
 UPDATE client 
 SET deleted_at = CURRENT_TIMESTAMP 
@@ -23,35 +46,48 @@ func (q *Queries) DeleteClient(ctx context.Context, id int64) error {
 }
 
 const getAllClients = `-- name: GetAllClients :many
-SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, hourly_rate, notes, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, updated_at, created_at, deleted_at 
+SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, country, hourly_rate, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, business_profile_id, monthly_hour_allowance, prepay_only, consolidated_invoicing, fiscal_year_end, po_number, po_valid_from, po_valid_to, invoice_language, invoice_prefix, next_invoice_number, budget_code, pipeline_stage, updated_at, created_at, deleted_at 
 FROM client 
 WHERE deleted_at IS NULL
 ORDER BY updated_at DESC
 `
 
 type GetAllClientsRow struct {
-	ID                      int64          `json:"id"`
-	Name                    string         `json:"name"`
-	Email                   string         `json:"email"`
-	Phone                   sql.NullString `json:"phone"`
-	Address1                sql.NullString `json:"address1"`
-	Address2                sql.NullString `json:"address2"`
-	Address3                sql.NullString `json:"address3"`
-	City                    sql.NullString `json:"city"`
-	State                   sql.NullString `json:"state"`
-	ZipCode                 sql.NullString `json:"zip_code"`
-	HourlyRate              float64        `json:"hourly_rate"`
-	Notes                   sql.NullString `json:"notes"`
-	AdditionalInfo          sql.NullString `json:"additional_info"`
-	AdditionalInfo2         sql.NullString `json:"additional_info2"`
-	BillTo                  sql.NullString `json:"bill_to"`
-	IncludeAddressOnInvoice bool           `json:"include_address_on_invoice"`
-	InvoiceCcEmail          sql.NullString `json:"invoice_cc_email"`
-	InvoiceCcDescription    sql.NullString `json:"invoice_cc_description"`
-	UniversityAffiliation   sql.NullString `json:"university_affiliation"`
-	UpdatedAt               time.Time      `json:"updated_at"`
-	CreatedAt               time.Time      `json:"created_at"`
-	DeletedAt               interface{}    `json:"deleted_at"`
+	ID                      int64           `json:"id"`
+	Name                    string          `json:"name"`
+	Email                   string          `json:"email"`
+	Phone                   sql.NullString  `json:"phone"`
+	Address1                sql.NullString  `json:"address1"`
+	Address2                sql.NullString  `json:"address2"`
+	Address3                sql.NullString  `json:"address3"`
+	City                    sql.NullString  `json:"city"`
+	State                   sql.NullString  `json:"state"`
+	ZipCode                 sql.NullString  `json:"zip_code"`
+	Country                 sql.NullString  `json:"country"`
+	HourlyRate              float64         `json:"hourly_rate"`
+	AdditionalInfo          sql.NullString  `json:"additional_info"`
+	AdditionalInfo2         sql.NullString  `json:"additional_info2"`
+	BillTo                  sql.NullString  `json:"bill_to"`
+	IncludeAddressOnInvoice bool            `json:"include_address_on_invoice"`
+	InvoiceCcEmail          sql.NullString  `json:"invoice_cc_email"`
+	InvoiceCcDescription    sql.NullString  `json:"invoice_cc_description"`
+	UniversityAffiliation   sql.NullString  `json:"university_affiliation"`
+	BusinessProfileID       sql.NullInt64   `json:"business_profile_id"`
+	MonthlyHourAllowance    sql.NullFloat64 `json:"monthly_hour_allowance"`
+	PrepayOnly              bool            `json:"prepay_only"`
+	ConsolidatedInvoicing   bool            `json:"consolidated_invoicing"`
+	FiscalYearEnd           sql.NullString  `json:"fiscal_year_end"`
+	PoNumber                sql.NullString  `json:"po_number"`
+	PoValidFrom             sql.NullString  `json:"po_valid_from"`
+	PoValidTo               sql.NullString  `json:"po_valid_to"`
+	InvoiceLanguage         sql.NullString  `json:"invoice_language"`
+	InvoicePrefix           sql.NullString  `json:"invoice_prefix"`
+	NextInvoiceNumber       sql.NullInt64   `json:"next_invoice_number"`
+	BudgetCode              sql.NullString  `json:"budget_code"`
+	PipelineStage           sql.NullString  `json:"pipeline_stage"`
+	UpdatedAt               time.Time       `json:"updated_at"`
+	CreatedAt               time.Time       `json:"created_at"`
+	DeletedAt               interface{}     `json:"deleted_at"`
 }
 
 func (q *Queries) GetAllClients(ctx context.Context) ([]GetAllClientsRow, error) {
@@ -74,8 +110,8 @@ func (q *Queries) GetAllClients(ctx context.Context) ([]GetAllClientsRow, error)
 			&i.City,
 			&i.State,
 			&i.ZipCode,
+			&i.Country,
 			&i.HourlyRate,
-			&i.Notes,
 			&i.AdditionalInfo,
 			&i.AdditionalInfo2,
 			&i.BillTo,
@@ -83,6 +119,19 @@ func (q *Queries) GetAllClients(ctx context.Context) ([]GetAllClientsRow, error)
 			&i.InvoiceCcEmail,
 			&i.InvoiceCcDescription,
 			&i.UniversityAffiliation,
+			&i.BusinessProfileID,
+			&i.MonthlyHourAllowance,
+			&i.PrepayOnly,
+			&i.ConsolidatedInvoicing,
+			&i.FiscalYearEnd,
+			&i.PoNumber,
+			&i.PoValidFrom,
+			&i.PoValidTo,
+			&i.InvoiceLanguage,
+			&i.InvoicePrefix,
+			&i.NextInvoiceNumber,
+			&i.BudgetCode,
+			&i.PipelineStage,
 			&i.UpdatedAt,
 			&i.CreatedAt,
 			&i.DeletedAt,
@@ -101,34 +150,47 @@ func (q *Queries) GetAllClients(ctx context.Context) ([]GetAllClientsRow, error)
 }
 
 const getClient = `-- name: GetClient :one
-SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, hourly_rate, notes, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, updated_at, created_at, deleted_at 
+SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, country, hourly_rate, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, business_profile_id, monthly_hour_allowance, prepay_only, consolidated_invoicing, fiscal_year_end, po_number, po_valid_from, po_valid_to, invoice_language, invoice_prefix, next_invoice_number, budget_code, pipeline_stage, updated_at, created_at, deleted_at 
 FROM client 
 WHERE id = ? AND deleted_at IS NULL
 `
 
 type GetClientRow struct {
-	ID                      int64          `json:"id"`
-	Name                    string         `json:"name"`
-	Email                   string         `json:"email"`
-	Phone                   sql.NullString `json:"phone"`
-	Address1                sql.NullString `json:"address1"`
-	Address2                sql.NullString `json:"address2"`
-	Address3                sql.NullString `json:"address3"`
-	City                    sql.NullString `json:"city"`
-	State                   sql.NullString `json:"state"`
-	ZipCode                 sql.NullString `json:"zip_code"`
-	HourlyRate              float64        `json:"hourly_rate"`
-	Notes                   sql.NullString `json:"notes"`
-	AdditionalInfo          sql.NullString `json:"additional_info"`
-	AdditionalInfo2         sql.NullString `json:"additional_info2"`
-	BillTo                  sql.NullString `json:"bill_to"`
-	IncludeAddressOnInvoice bool           `json:"include_address_on_invoice"`
-	InvoiceCcEmail          sql.NullString `json:"invoice_cc_email"`
-	InvoiceCcDescription    sql.NullString `json:"invoice_cc_description"`
-	UniversityAffiliation   sql.NullString `json:"university_affiliation"`
-	UpdatedAt               time.Time      `json:"updated_at"`
-	CreatedAt               time.Time      `json:"created_at"`
-	DeletedAt               interface{}    `json:"deleted_at"`
+	ID                      int64           `json:"id"`
+	Name                    string          `json:"name"`
+	Email                   string          `json:"email"`
+	Phone                   sql.NullString  `json:"phone"`
+	Address1                sql.NullString  `json:"address1"`
+	Address2                sql.NullString  `json:"address2"`
+	Address3                sql.NullString  `json:"address3"`
+	City                    sql.NullString  `json:"city"`
+	State                   sql.NullString  `json:"state"`
+	ZipCode                 sql.NullString  `json:"zip_code"`
+	Country                 sql.NullString  `json:"country"`
+	HourlyRate              float64         `json:"hourly_rate"`
+	AdditionalInfo          sql.NullString  `json:"additional_info"`
+	AdditionalInfo2         sql.NullString  `json:"additional_info2"`
+	BillTo                  sql.NullString  `json:"bill_to"`
+	IncludeAddressOnInvoice bool            `json:"include_address_on_invoice"`
+	InvoiceCcEmail          sql.NullString  `json:"invoice_cc_email"`
+	InvoiceCcDescription    sql.NullString  `json:"invoice_cc_description"`
+	UniversityAffiliation   sql.NullString  `json:"university_affiliation"`
+	BusinessProfileID       sql.NullInt64   `json:"business_profile_id"`
+	MonthlyHourAllowance    sql.NullFloat64 `json:"monthly_hour_allowance"`
+	PrepayOnly              bool            `json:"prepay_only"`
+	ConsolidatedInvoicing   bool            `json:"consolidated_invoicing"`
+	FiscalYearEnd           sql.NullString  `json:"fiscal_year_end"`
+	PoNumber                sql.NullString  `json:"po_number"`
+	PoValidFrom             sql.NullString  `json:"po_valid_from"`
+	PoValidTo               sql.NullString  `json:"po_valid_to"`
+	InvoiceLanguage         sql.NullString  `json:"invoice_language"`
+	InvoicePrefix           sql.NullString  `json:"invoice_prefix"`
+	NextInvoiceNumber       sql.NullInt64   `json:"next_invoice_number"`
+	BudgetCode              sql.NullString  `json:"budget_code"`
+	PipelineStage           sql.NullString  `json:"pipeline_stage"`
+	UpdatedAt               time.Time       `json:"updated_at"`
+	CreatedAt               time.Time       `json:"created_at"`
+	DeletedAt               interface{}     `json:"deleted_at"`
 }
 
 func (q *Queries) GetClient(ctx context.Context, id int64) (GetClientRow, error) {
@@ -145,8 +207,8 @@ func (q *Queries) GetClient(ctx context.Context, id int64) (GetClientRow, error)
 		&i.City,
 		&i.State,
 		&i.ZipCode,
+		&i.Country,
 		&i.HourlyRate,
-		&i.Notes,
 		&i.AdditionalInfo,
 		&i.AdditionalInfo2,
 		&i.BillTo,
@@ -154,6 +216,19 @@ func (q *Queries) GetClient(ctx context.Context, id int64) (GetClientRow, error)
 		&i.InvoiceCcEmail,
 		&i.InvoiceCcDescription,
 		&i.UniversityAffiliation,
+		&i.BusinessProfileID,
+		&i.MonthlyHourAllowance,
+		&i.PrepayOnly,
+		&i.ConsolidatedInvoicing,
+		&i.FiscalYearEnd,
+		&i.PoNumber,
+		&i.PoValidFrom,
+		&i.PoValidTo,
+		&i.InvoiceLanguage,
+		&i.InvoicePrefix,
+		&i.NextInvoiceNumber,
+		&i.BudgetCode,
+		&i.PipelineStage,
 		&i.UpdatedAt,
 		&i.CreatedAt,
 		&i.DeletedAt,
@@ -161,6 +236,244 @@ func (q *Queries) GetClient(ctx context.Context, id int64) (GetClientRow, error)
 	return i, err
 }
 
+const getClientsByIDs = `-- name: GetClientsByIDs :many
+SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, country, hourly_rate, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, business_profile_id, monthly_hour_allowance, prepay_only, consolidated_invoicing, fiscal_year_end, po_number, po_valid_from, po_valid_to, invoice_language, invoice_prefix, next_invoice_number, budget_code, pipeline_stage, updated_at, created_at, deleted_at 
+FROM client 
+WHERE deleted_at IS NULL AND id IN (/*SLICE:ids*/?)
+ORDER BY updated_at DESC
+`
+
+type GetClientsByIDsRow struct {
+	ID                      int64           `json:"id"`
+	Name                    string          `json:"name"`
+	Email                   string          `json:"email"`
+	Phone                   sql.NullString  `json:"phone"`
+	Address1                sql.NullString  `json:"address1"`
+	Address2                sql.NullString  `json:"address2"`
+	Address3                sql.NullString  `json:"address3"`
+	City                    sql.NullString  `json:"city"`
+	State                   sql.NullString  `json:"state"`
+	ZipCode                 sql.NullString  `json:"zip_code"`
+	Country                 sql.NullString  `json:"country"`
+	HourlyRate              float64         `json:"hourly_rate"`
+	AdditionalInfo          sql.NullString  `json:"additional_info"`
+	AdditionalInfo2         sql.NullString  `json:"additional_info2"`
+	BillTo                  sql.NullString  `json:"bill_to"`
+	IncludeAddressOnInvoice bool            `json:"include_address_on_invoice"`
+	InvoiceCcEmail          sql.NullString  `json:"invoice_cc_email"`
+	InvoiceCcDescription    sql.NullString  `json:"invoice_cc_description"`
+	UniversityAffiliation   sql.NullString  `json:"university_affiliation"`
+	BusinessProfileID       sql.NullInt64   `json:"business_profile_id"`
+	MonthlyHourAllowance    sql.NullFloat64 `json:"monthly_hour_allowance"`
+	PrepayOnly              bool            `json:"prepay_only"`
+	ConsolidatedInvoicing   bool            `json:"consolidated_invoicing"`
+	FiscalYearEnd           sql.NullString  `json:"fiscal_year_end"`
+	PoNumber                sql.NullString  `json:"po_number"`
+	PoValidFrom             sql.NullString  `json:"po_valid_from"`
+	PoValidTo               sql.NullString  `json:"po_valid_to"`
+	InvoiceLanguage         sql.NullString  `json:"invoice_language"`
+	InvoicePrefix           sql.NullString  `json:"invoice_prefix"`
+	NextInvoiceNumber       sql.NullInt64   `json:"next_invoice_number"`
+	BudgetCode              sql.NullString  `json:"budget_code"`
+	PipelineStage           sql.NullString  `json:"pipeline_stage"`
+	UpdatedAt               time.Time       `json:"updated_at"`
+	CreatedAt               time.Time       `json:"created_at"`
+	DeletedAt               interface{}     `json:"deleted_at"`
+}
+
+func (q *Queries) GetClientsByIDs(ctx context.Context, ids []int64) ([]GetClientsByIDsRow, error) {
+	query := getClientsByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetClientsByIDsRow{}
+	for rows.Next() {
+		var i GetClientsByIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.Phone,
+			&i.Address1,
+			&i.Address2,
+			&i.Address3,
+			&i.City,
+			&i.State,
+			&i.ZipCode,
+			&i.Country,
+			&i.HourlyRate,
+			&i.AdditionalInfo,
+			&i.AdditionalInfo2,
+			&i.BillTo,
+			&i.IncludeAddressOnInvoice,
+			&i.InvoiceCcEmail,
+			&i.InvoiceCcDescription,
+			&i.UniversityAffiliation,
+			&i.BusinessProfileID,
+			&i.MonthlyHourAllowance,
+			&i.PrepayOnly,
+			&i.ConsolidatedInvoicing,
+			&i.FiscalYearEnd,
+			&i.PoNumber,
+			&i.PoValidFrom,
+			&i.PoValidTo,
+			&i.InvoiceLanguage,
+			&i.InvoicePrefix,
+			&i.NextInvoiceNumber,
+			&i.BudgetCode,
+			&i.PipelineStage,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getClientsByTagCount = `-- name: GetClientsByTagCount :one
+SELECT COUNT(*) 
+FROM client 
+WHERE deleted_at IS NULL AND id IN (SELECT client_id FROM client_tag WHERE tag_id = ?)
+`
+
+func (q *Queries) GetClientsByTagCount(ctx context.Context, tagID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getClientsByTagCount, tagID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getClientsByTagWithPagination = `-- name: GetClientsByTagWithPagination :many
+SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, country, hourly_rate, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, business_profile_id, monthly_hour_allowance, prepay_only, consolidated_invoicing, fiscal_year_end, po_number, po_valid_from, po_valid_to, invoice_language, invoice_prefix, next_invoice_number, budget_code, pipeline_stage, updated_at, created_at, deleted_at 
+FROM client 
+WHERE deleted_at IS NULL AND id IN (SELECT client_id FROM client_tag WHERE tag_id = ?)
+ORDER BY updated_at DESC
+LIMIT ? OFFSET ?
+`
+
+type GetClientsByTagWithPaginationParams struct {
+	TagID  int64 `json:"tag_id"`
+	Limit  int64 `json:"limit"`
+	Offset int64 `json:"offset"`
+}
+
+type GetClientsByTagWithPaginationRow struct {
+	ID                      int64           `json:"id"`
+	Name                    string          `json:"name"`
+	Email                   string          `json:"email"`
+	Phone                   sql.NullString  `json:"phone"`
+	Address1                sql.NullString  `json:"address1"`
+	Address2                sql.NullString  `json:"address2"`
+	Address3                sql.NullString  `json:"address3"`
+	City                    sql.NullString  `json:"city"`
+	State                   sql.NullString  `json:"state"`
+	ZipCode                 sql.NullString  `json:"zip_code"`
+	Country                 sql.NullString  `json:"country"`
+	HourlyRate              float64         `json:"hourly_rate"`
+	AdditionalInfo          sql.NullString  `json:"additional_info"`
+	AdditionalInfo2         sql.NullString  `json:"additional_info2"`
+	BillTo                  sql.NullString  `json:"bill_to"`
+	IncludeAddressOnInvoice bool            `json:"include_address_on_invoice"`
+	InvoiceCcEmail          sql.NullString  `json:"invoice_cc_email"`
+	InvoiceCcDescription    sql.NullString  `json:"invoice_cc_description"`
+	UniversityAffiliation   sql.NullString  `json:"university_affiliation"`
+	BusinessProfileID       sql.NullInt64   `json:"business_profile_id"`
+	MonthlyHourAllowance    sql.NullFloat64 `json:"monthly_hour_allowance"`
+	PrepayOnly              bool            `json:"prepay_only"`
+	ConsolidatedInvoicing   bool            `json:"consolidated_invoicing"`
+	FiscalYearEnd           sql.NullString  `json:"fiscal_year_end"`
+	PoNumber                sql.NullString  `json:"po_number"`
+	PoValidFrom             sql.NullString  `json:"po_valid_from"`
+	PoValidTo               sql.NullString  `json:"po_valid_to"`
+	InvoiceLanguage         sql.NullString  `json:"invoice_language"`
+	InvoicePrefix           sql.NullString  `json:"invoice_prefix"`
+	NextInvoiceNumber       sql.NullInt64   `json:"next_invoice_number"`
+	BudgetCode              sql.NullString  `json:"budget_code"`
+	PipelineStage           sql.NullString  `json:"pipeline_stage"`
+	UpdatedAt               time.Time       `json:"updated_at"`
+	CreatedAt               time.Time       `json:"created_at"`
+	DeletedAt               interface{}     `json:"deleted_at"`
+}
+
+func (q *Queries) GetClientsByTagWithPagination(ctx context.Context, arg GetClientsByTagWithPaginationParams) ([]GetClientsByTagWithPaginationRow, error) {
+	rows, err := q.db.QueryContext(ctx, getClientsByTagWithPagination, arg.TagID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetClientsByTagWithPaginationRow{}
+	for rows.Next() {
+		var i GetClientsByTagWithPaginationRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.Phone,
+			&i.Address1,
+			&i.Address2,
+			&i.Address3,
+			&i.City,
+			&i.State,
+			&i.ZipCode,
+			&i.Country,
+			&i.HourlyRate,
+			&i.AdditionalInfo,
+			&i.AdditionalInfo2,
+			&i.BillTo,
+			&i.IncludeAddressOnInvoice,
+			&i.InvoiceCcEmail,
+			&i.InvoiceCcDescription,
+			&i.UniversityAffiliation,
+			&i.BusinessProfileID,
+			&i.MonthlyHourAllowance,
+			&i.PrepayOnly,
+			&i.ConsolidatedInvoicing,
+			&i.FiscalYearEnd,
+			&i.PoNumber,
+			&i.PoValidFrom,
+			&i.PoValidTo,
+			&i.InvoiceLanguage,
+			&i.InvoicePrefix,
+			&i.NextInvoiceNumber,
+			&i.BudgetCode,
+			&i.PipelineStage,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getClientsCount = `-- name: GetClientsCount :one
 SELECT COUNT(*) 
 FROM client 
@@ -175,7 +488,7 @@ func (q *Queries) GetClientsCount(ctx context.Context) (int64, error) {
 }
 
 const getClientsWithPagination = `-- name: GetClientsWithPagination :many
-SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, hourly_rate, notes, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, updated_at, created_at, deleted_at 
+SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, country, hourly_rate, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, business_profile_id, monthly_hour_allowance, prepay_only, consolidated_invoicing, fiscal_year_end, po_number, po_valid_from, po_valid_to, invoice_language, invoice_prefix, next_invoice_number, budget_code, pipeline_stage, updated_at, created_at, deleted_at 
 FROM client 
 WHERE deleted_at IS NULL
 ORDER BY updated_at DESC
@@ -188,28 +501,41 @@ type GetClientsWithPaginationParams struct {
 }
 
 type GetClientsWithPaginationRow struct {
-	ID                      int64          `json:"id"`
-	Name                    string         `json:"name"`
-	Email                   string         `json:"email"`
-	Phone                   sql.NullString `json:"phone"`
-	Address1                sql.NullString `json:"address1"`
-	Address2                sql.NullString `json:"address2"`
-	Address3                sql.NullString `json:"address3"`
-	City                    sql.NullString `json:"city"`
-	State                   sql.NullString `json:"state"`
-	ZipCode                 sql.NullString `json:"zip_code"`
-	HourlyRate              float64        `json:"hourly_rate"`
-	Notes                   sql.NullString `json:"notes"`
-	AdditionalInfo          sql.NullString `json:"additional_info"`
-	AdditionalInfo2         sql.NullString `json:"additional_info2"`
-	BillTo                  sql.NullString `json:"bill_to"`
-	IncludeAddressOnInvoice bool           `json:"include_address_on_invoice"`
-	InvoiceCcEmail          sql.NullString `json:"invoice_cc_email"`
-	InvoiceCcDescription    sql.NullString `json:"invoice_cc_description"`
-	UniversityAffiliation   sql.NullString `json:"university_affiliation"`
-	UpdatedAt               time.Time      `json:"updated_at"`
-	CreatedAt               time.Time      `json:"created_at"`
-	DeletedAt               interface{}    `json:"deleted_at"`
+	ID                      int64           `json:"id"`
+	Name                    string          `json:"name"`
+	Email                   string          `json:"email"`
+	Phone                   sql.NullString  `json:"phone"`
+	Address1                sql.NullString  `json:"address1"`
+	Address2                sql.NullString  `json:"address2"`
+	Address3                sql.NullString  `json:"address3"`
+	City                    sql.NullString  `json:"city"`
+	State                   sql.NullString  `json:"state"`
+	ZipCode                 sql.NullString  `json:"zip_code"`
+	Country                 sql.NullString  `json:"country"`
+	HourlyRate              float64         `json:"hourly_rate"`
+	AdditionalInfo          sql.NullString  `json:"additional_info"`
+	AdditionalInfo2         sql.NullString  `json:"additional_info2"`
+	BillTo                  sql.NullString  `json:"bill_to"`
+	IncludeAddressOnInvoice bool            `json:"include_address_on_invoice"`
+	InvoiceCcEmail          sql.NullString  `json:"invoice_cc_email"`
+	InvoiceCcDescription    sql.NullString  `json:"invoice_cc_description"`
+	UniversityAffiliation   sql.NullString  `json:"university_affiliation"`
+	BusinessProfileID       sql.NullInt64   `json:"business_profile_id"`
+	MonthlyHourAllowance    sql.NullFloat64 `json:"monthly_hour_allowance"`
+	PrepayOnly              bool            `json:"prepay_only"`
+	ConsolidatedInvoicing   bool            `json:"consolidated_invoicing"`
+	FiscalYearEnd           sql.NullString  `json:"fiscal_year_end"`
+	PoNumber                sql.NullString  `json:"po_number"`
+	PoValidFrom             sql.NullString  `json:"po_valid_from"`
+	PoValidTo               sql.NullString  `json:"po_valid_to"`
+	InvoiceLanguage         sql.NullString  `json:"invoice_language"`
+	InvoicePrefix           sql.NullString  `json:"invoice_prefix"`
+	NextInvoiceNumber       sql.NullInt64   `json:"next_invoice_number"`
+	BudgetCode              sql.NullString  `json:"budget_code"`
+	PipelineStage           sql.NullString  `json:"pipeline_stage"`
+	UpdatedAt               time.Time       `json:"updated_at"`
+	CreatedAt               time.Time       `json:"created_at"`
+	DeletedAt               interface{}     `json:"deleted_at"`
 }
 
 func (q *Queries) GetClientsWithPagination(ctx context.Context, arg GetClientsWithPaginationParams) ([]GetClientsWithPaginationRow, error) {
@@ -232,8 +558,8 @@ func (q *Queries) GetClientsWithPagination(ctx context.Context, arg GetClientsWi
 			&i.City,
 			&i.State,
 			&i.ZipCode,
+			&i.Country,
 			&i.HourlyRate,
-			&i.Notes,
 			&i.AdditionalInfo,
 			&i.AdditionalInfo2,
 			&i.BillTo,
@@ -241,6 +567,19 @@ func (q *Queries) GetClientsWithPagination(ctx context.Context, arg GetClientsWi
 			&i.InvoiceCcEmail,
 			&i.InvoiceCcDescription,
 			&i.UniversityAffiliation,
+			&i.BusinessProfileID,
+			&i.MonthlyHourAllowance,
+			&i.PrepayOnly,
+			&i.ConsolidatedInvoicing,
+			&i.FiscalYearEnd,
+			&i.PoNumber,
+			&i.PoValidFrom,
+			&i.PoValidTo,
+			&i.InvoiceLanguage,
+			&i.InvoicePrefix,
+			&i.NextInvoiceNumber,
+			&i.BudgetCode,
+			&i.PipelineStage,
 			&i.UpdatedAt,
 			&i.CreatedAt,
 			&i.DeletedAt,
@@ -259,29 +598,41 @@ func (q *Queries) GetClientsWithPagination(ctx context.Context, arg GetClientsWi
 }
 
 const insertClient = `-- name: InsertClient :execlastid
-INSERT INTO client (name, email, phone, address1, address2, address3, city, state, zip_code, hourly_rate, notes, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation) 
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO client (name, email, phone, address1, address2, address3, city, state, zip_code, country, hourly_rate, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, business_profile_id, monthly_hour_allowance, prepay_only, consolidated_invoicing, fiscal_year_end, po_number, po_valid_from, po_valid_to, invoice_language, invoice_prefix, next_invoice_number, budget_code) 
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type InsertClientParams struct {
-	Name                    string         `json:"name"`
-	Email                   string         `json:"email"`
-	Phone                   sql.NullString `json:"phone"`
-	Address1                sql.NullString `json:"address1"`
-	Address2                sql.NullString `json:"address2"`
-	Address3                sql.NullString `json:"address3"`
-	City                    sql.NullString `json:"city"`
-	State                   sql.NullString `json:"state"`
-	ZipCode                 sql.NullString `json:"zip_code"`
-	HourlyRate              float64        `json:"hourly_rate"`
-	Notes                   sql.NullString `json:"notes"`
-	AdditionalInfo          sql.NullString `json:"additional_info"`
-	AdditionalInfo2         sql.NullString `json:"additional_info2"`
-	BillTo                  sql.NullString `json:"bill_to"`
-	IncludeAddressOnInvoice bool           `json:"include_address_on_invoice"`
-	InvoiceCcEmail          sql.NullString `json:"invoice_cc_email"`
-	InvoiceCcDescription    sql.NullString `json:"invoice_cc_description"`
-	UniversityAffiliation   sql.NullString `json:"university_affiliation"`
+	Name                    string          `json:"name"`
+	Email                   string          `json:"email"`
+	Phone                   sql.NullString  `json:"phone"`
+	Address1                sql.NullString  `json:"address1"`
+	Address2                sql.NullString  `json:"address2"`
+	Address3                sql.NullString  `json:"address3"`
+	City                    sql.NullString  `json:"city"`
+	State                   sql.NullString  `json:"state"`
+	ZipCode                 sql.NullString  `json:"zip_code"`
+	Country                 sql.NullString  `json:"country"`
+	HourlyRate              float64         `json:"hourly_rate"`
+	AdditionalInfo          sql.NullString  `json:"additional_info"`
+	AdditionalInfo2         sql.NullString  `json:"additional_info2"`
+	BillTo                  sql.NullString  `json:"bill_to"`
+	IncludeAddressOnInvoice bool            `json:"include_address_on_invoice"`
+	InvoiceCcEmail          sql.NullString  `json:"invoice_cc_email"`
+	InvoiceCcDescription    sql.NullString  `json:"invoice_cc_description"`
+	UniversityAffiliation   sql.NullString  `json:"university_affiliation"`
+	BusinessProfileID       sql.NullInt64   `json:"business_profile_id"`
+	MonthlyHourAllowance    sql.NullFloat64 `json:"monthly_hour_allowance"`
+	PrepayOnly              bool            `json:"prepay_only"`
+	ConsolidatedInvoicing   bool            `json:"consolidated_invoicing"`
+	FiscalYearEnd           sql.NullString  `json:"fiscal_year_end"`
+	PoNumber                sql.NullString  `json:"po_number"`
+	PoValidFrom             sql.NullString  `json:"po_valid_from"`
+	PoValidTo               sql.NullString  `json:"po_valid_to"`
+	InvoiceLanguage         sql.NullString  `json:"invoice_language"`
+	InvoicePrefix           sql.NullString  `json:"invoice_prefix"`
+	NextInvoiceNumber       sql.NullInt64   `json:"next_invoice_number"`
+	BudgetCode              sql.NullString  `json:"budget_code"`
 }
 
 func (q *Queries) InsertClient(ctx context.Context, arg InsertClientParams) (int64, error) {
@@ -295,8 +646,8 @@ func (q *Queries) InsertClient(ctx context.Context, arg InsertClientParams) (int
 		arg.City,
 		arg.State,
 		arg.ZipCode,
+		arg.Country,
 		arg.HourlyRate,
-		arg.Notes,
 		arg.AdditionalInfo,
 		arg.AdditionalInfo2,
 		arg.BillTo,
@@ -304,6 +655,18 @@ func (q *Queries) InsertClient(ctx context.Context, arg InsertClientParams) (int
 		arg.InvoiceCcEmail,
 		arg.InvoiceCcDescription,
 		arg.UniversityAffiliation,
+		arg.BusinessProfileID,
+		arg.MonthlyHourAllowance,
+		arg.PrepayOnly,
+		arg.ConsolidatedInvoicing,
+		arg.FiscalYearEnd,
+		arg.PoNumber,
+		arg.PoValidFrom,
+		arg.PoValidTo,
+		arg.InvoiceLanguage,
+		arg.InvoicePrefix,
+		arg.NextInvoiceNumber,
+		arg.BudgetCode,
 	)
 	if err != nil {
 		return 0, err
@@ -311,32 +674,115 @@ func (q *Queries) InsertClient(ctx context.Context, arg InsertClientParams) (int
 	return result.LastInsertId()
 }
 
+const restoreClient = `-- name: RestoreClient :exec
+UPDATE client 
+SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreClient(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, restoreClient, id)
+	return err
+}
+
+const searchClients = `-- name: SearchClients :many
+SELECT id, name
+FROM client
+WHERE deleted_at IS NULL AND name LIKE ? ESCAPE '\'
+ORDER BY updated_at DESC, name
+LIMIT ?
+`
+
+type SearchClientsParams struct {
+	Pattern interface{} `json:"pattern"`
+	Limit   int64       `json:"limit"`
+}
+
+type SearchClientsRow struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (q *Queries) SearchClients(ctx context.Context, arg SearchClientsParams) ([]SearchClientsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchClients, arg.Pattern, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchClientsRow{}
+	for rows.Next() {
+		var i SearchClientsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setClientPipelineStage = `-- name: SetClientPipelineStage :exec
+UPDATE client 
+SET pipeline_stage = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL
+`
+
+type SetClientPipelineStageParams struct {
+	PipelineStage sql.NullString `json:"pipeline_stage"`
+	ID            int64          `json:"id"`
+}
+
+func (q *Queries) SetClientPipelineStage(ctx context.Context, arg SetClientPipelineStageParams) error {
+	_, err := q.db.ExecContext(ctx, setClientPipelineStage, arg.PipelineStage, arg.ID)
+	return err
+}
+
 const updateClient = `-- name: UpdateClient :exec
 UPDATE client 
-SET name = ?, email = ?, phone = ?, address1 = ?, address2 = ?, address3 = ?, city = ?, state = ?, zip_code = ?, hourly_rate = ?, notes = ?, additional_info = ?, additional_info2 = ?, bill_to = ?, include_address_on_invoice = ?, invoice_cc_email = ?, invoice_cc_description = ?, university_affiliation = ?, updated_at = CURRENT_TIMESTAMP 
+SET name = ?, email = ?, phone = ?, address1 = ?, address2 = ?, address3 = ?, city = ?, state = ?, zip_code = ?, country = ?, hourly_rate = ?, additional_info = ?, additional_info2 = ?, bill_to = ?, include_address_on_invoice = ?, invoice_cc_email = ?, invoice_cc_description = ?, university_affiliation = ?, business_profile_id = ?, monthly_hour_allowance = ?, prepay_only = ?, consolidated_invoicing = ?, fiscal_year_end = ?, po_number = ?, po_valid_from = ?, po_valid_to = ?, invoice_language = ?, invoice_prefix = ?, next_invoice_number = ?, budget_code = ?, updated_at = CURRENT_TIMESTAMP 
 WHERE id = ? AND deleted_at IS NULL
 `
 
 type UpdateClientParams struct {
-	Name                    string         `json:"name"`
-	Email                   string         `json:"email"`
-	Phone                   sql.NullString `json:"phone"`
-	Address1                sql.NullString `json:"address1"`
-	Address2                sql.NullString `json:"address2"`
-	Address3                sql.NullString `json:"address3"`
-	City                    sql.NullString `json:"city"`
-	State                   sql.NullString `json:"state"`
-	ZipCode                 sql.NullString `json:"zip_code"`
-	HourlyRate              float64        `json:"hourly_rate"`
-	Notes                   sql.NullString `json:"notes"`
-	AdditionalInfo          sql.NullString `json:"additional_info"`
-	AdditionalInfo2         sql.NullString `json:"additional_info2"`
-	BillTo                  sql.NullString `json:"bill_to"`
-	IncludeAddressOnInvoice bool           `json:"include_address_on_invoice"`
-	InvoiceCcEmail          sql.NullString `json:"invoice_cc_email"`
-	InvoiceCcDescription    sql.NullString `json:"invoice_cc_description"`
-	UniversityAffiliation   sql.NullString `json:"university_affiliation"`
-	ID                      int64          `json:"id"`
+	Name                    string          `json:"name"`
+	Email                   string          `json:"email"`
+	Phone                   sql.NullString  `json:"phone"`
+	Address1                sql.NullString  `json:"address1"`
+	Address2                sql.NullString  `json:"address2"`
+	Address3                sql.NullString  `json:"address3"`
+	City                    sql.NullString  `json:"city"`
+	State                   sql.NullString  `json:"state"`
+	ZipCode                 sql.NullString  `json:"zip_code"`
+	Country                 sql.NullString  `json:"country"`
+	HourlyRate              float64         `json:"hourly_rate"`
+	AdditionalInfo          sql.NullString  `json:"additional_info"`
+	AdditionalInfo2         sql.NullString  `json:"additional_info2"`
+	BillTo                  sql.NullString  `json:"bill_to"`
+	IncludeAddressOnInvoice bool            `json:"include_address_on_invoice"`
+	InvoiceCcEmail          sql.NullString  `json:"invoice_cc_email"`
+	InvoiceCcDescription    sql.NullString  `json:"invoice_cc_description"`
+	UniversityAffiliation   sql.NullString  `json:"university_affiliation"`
+	BusinessProfileID       sql.NullInt64   `json:"business_profile_id"`
+	MonthlyHourAllowance    sql.NullFloat64 `json:"monthly_hour_allowance"`
+	PrepayOnly              bool            `json:"prepay_only"`
+	ConsolidatedInvoicing   bool            `json:"consolidated_invoicing"`
+	FiscalYearEnd           sql.NullString  `json:"fiscal_year_end"`
+	PoNumber                sql.NullString  `json:"po_number"`
+	PoValidFrom             sql.NullString  `json:"po_valid_from"`
+	PoValidTo               sql.NullString  `json:"po_valid_to"`
+	InvoiceLanguage         sql.NullString  `json:"invoice_language"`
+	InvoicePrefix           sql.NullString  `json:"invoice_prefix"`
+	NextInvoiceNumber       sql.NullInt64   `json:"next_invoice_number"`
+	BudgetCode              sql.NullString  `json:"budget_code"`
+	ID                      int64           `json:"id"`
 }
 
 func (q *Queries) UpdateClient(ctx context.Context, arg UpdateClientParams) error {
@@ -350,8 +796,8 @@ func (q *Queries) UpdateClient(ctx context.Context, arg UpdateClientParams) erro
 		arg.City,
 		arg.State,
 		arg.ZipCode,
+		arg.Country,
 		arg.HourlyRate,
-		arg.Notes,
 		arg.AdditionalInfo,
 		arg.AdditionalInfo2,
 		arg.BillTo,
@@ -359,6 +805,18 @@ func (q *Queries) UpdateClient(ctx context.Context, arg UpdateClientParams) erro
 		arg.InvoiceCcEmail,
 		arg.InvoiceCcDescription,
 		arg.UniversityAffiliation,
+		arg.BusinessProfileID,
+		arg.MonthlyHourAllowance,
+		arg.PrepayOnly,
+		arg.ConsolidatedInvoicing,
+		arg.FiscalYearEnd,
+		arg.PoNumber,
+		arg.PoValidFrom,
+		arg.PoValidTo,
+		arg.InvoiceLanguage,
+		arg.InvoicePrefix,
+		arg.NextInvoiceNumber,
+		arg.BudgetCode,
 		arg.ID,
 	)
 	return err
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// dateLayout is how date-only columns are stored, so that SQLite's date functions and
+// plain string comparisons both work on them
+const dateLayout = "2006-01-02"
+
+// Date is a calendar date held in a DATE column. It is written as YYYY-MM-DD and read back
+// as midnight UTC on that day, whatever time of day or zone it was given with, so a date
+// never moves to the day before or after when it is converted between zones.
+type Date struct {
+	Time time.Time
+}
+
+// NewDate returns the calendar date of t in t's own location
+func NewDate(t time.Time) Date {
+	return Date{Time: dateOf(t)}
+}
+
+// Scan reads a date stored as text, including timestamps written before dates were stored
+// as YYYY-MM-DD, or parsed into a time.Time by the driver
+func (d *Date) Scan(value any) error {
+	t, err := scanDate(value)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return fmt.Errorf("date: cannot scan NULL into a Date")
+	}
+	d.Time = *t
+	return nil
+}
+
+// Value writes the date as YYYY-MM-DD
+func (d Date) Value() (driver.Value, error) {
+	return d.Time.Format(dateLayout), nil
+}
+
+// NullDate is a Date that may be NULL
+type NullDate struct {
+	Time  time.Time
+	Valid bool
+}
+
+// NewNullDate returns the calendar date of t, or a NULL date when t is nil
+func NewNullDate(t *time.Time) NullDate {
+	if t == nil {
+		return NullDate{}
+	}
+	return NullDate{Time: dateOf(*t), Valid: true}
+}
+
+// Ptr returns the date, or nil when it is NULL
+func (d NullDate) Ptr() *time.Time {
+	if !d.Valid {
+		return nil
+	}
+	t := d.Time
+	return &t
+}
+
+// Scan reads a date the way Date does, with NULL leaving it invalid
+func (d *NullDate) Scan(value any) error {
+	t, err := scanDate(value)
+	if err != nil {
+		return err
+	}
+	d.Time, d.Valid = time.Time{}, t != nil
+	if t != nil {
+		d.Time = *t
+	}
+	return nil
+}
+
+// Value writes the date as YYYY-MM-DD, or NULL
+func (d NullDate) Value() (driver.Value, error) {
+	if !d.Valid {
+		return nil, nil
+	}
+	return d.Time.Format(dateLayout), nil
+}
+
+// dateOf returns midnight UTC on t's calendar day in t's own location
+func dateOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// scanDate converts a DATE column value to a date, or nil for NULL. Text only needs to
+// start with YYYY-MM-DD, which covers both dates and the timestamps Go used to write.
+func scanDate(value any) (*time.Time, error) {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		t := dateOf(v)
+		return &t, nil
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return nil, fmt.Errorf("date: cannot scan %T", value)
+	}
+
+	if len(text) < len(dateLayout) {
+		return nil, fmt.Errorf("date: cannot parse %q", text)
+	}
+	t, err := time.Parse(dateLayout, text[:len(dateLayout)])
+	if err != nil {
+		return nil, fmt.Errorf("date: cannot parse %q: %w", text, err)
+	}
+	return &t, nil
+}
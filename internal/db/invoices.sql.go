@@ -7,9 +7,59 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
+const countInvoicesWithNumber = `-- name: CountInvoicesWithNumber :one
+SELECT COUNT(*) FROM invoice
+WHERE invoice_number = ? AND deleted_at IS NULL
+`
+
+func (q *Queries) CountInvoicesWithNumber(ctx context.Context, invoiceNumber sql.NullString) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countInvoicesWithNumber, invoiceNumber)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSimilarInvoices = `-- name: CountSimilarInvoices :one
+SELECT COUNT(*) FROM invoice
+WHERE project_id = ? AND abs(amount_due - ?) < 0.005
+  AND abs(julianday(substr(invoice_date, 1, 10)) - julianday(?)) <= ?
+  AND deleted_at IS NULL AND voided_at IS NULL
+`
+
+type CountSimilarInvoicesParams struct {
+	ProjectID   int64       `json:"project_id"`
+	AmountDue   interface{} `json:"amount_due"`
+	InvoiceDate interface{} `json:"invoice_date"`
+	WithinDays  interface{} `json:"within_days"`
+}
+
+func (q *Queries) CountSimilarInvoices(ctx context.Context, arg CountSimilarInvoicesParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSimilarInvoices,
+		arg.ProjectID,
+		arg.AmountDue,
+		arg.InvoiceDate,
+		arg.WithinDays,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteClientInvoices = `-- name: DeleteClientInvoices :exec
+UPDATE invoice 
+SET deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = invoice.project_id) 
+WHERE project_id IN (SELECT id FROM project WHERE client_id = ? AND deleted_at IS NOT NULL) AND deleted_at IS NULL
+`
+
+func (q *Queries) DeleteClientInvoices(ctx context.Context, clientID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteClientInvoices, clientID)
+	return err
+}
+
 const deleteInvoice = `-- name: DeleteInvoice :exec
 UPDATE invoice 
 SET deleted_at = CURRENT_TIMESTAMP 
@@ -21,23 +71,112 @@ func (q *Queries) DeleteInvoice(ctx context.Context, id int64) error {
 	return err
 }
 
+const deleteProjectInvoices = `-- name: DeleteProjectInvoices :exec
+UPDATE invoice 
+SET deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = invoice.project_id) 
+WHERE project_id = ? AND deleted_at IS NULL
+`
+
+func (q *Queries) DeleteProjectInvoices(ctx context.Context, projectID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteProjectInvoices, projectID)
+	return err
+}
+
+const flagInvoiceTimesheetsChanged = `-- name: FlagInvoiceTimesheetsChanged :exec
+UPDATE invoice 
+SET timesheets_changed_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL
+`
+
+func (q *Queries) FlagInvoiceTimesheetsChanged(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, flagInvoiceTimesheetsChanged, id)
+	return err
+}
+
+const getClientPaidInvoices = `-- name: GetClientPaidInvoices :many
+SELECT i.invoice_date, i.due_date, i.date_paid, i.payment_terms
+FROM invoice i
+JOIN project p ON p.id = i.project_id
+WHERE p.client_id = ? AND i.date_paid IS NOT NULL AND i.deleted_at IS NULL AND i.voided_at IS NULL
+ORDER BY i.invoice_date
+`
+
+type GetClientPaidInvoicesRow struct {
+	InvoiceDate  Date     `json:"invoice_date"`
+	DueDate      NullDate `json:"due_date"`
+	DatePaid     NullDate `json:"date_paid"`
+	PaymentTerms string   `json:"payment_terms"`
+}
+
+func (q *Queries) GetClientPaidInvoices(ctx context.Context, clientID int64) ([]GetClientPaidInvoicesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getClientPaidInvoices, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetClientPaidInvoicesRow{}
+	for rows.Next() {
+		var i GetClientPaidInvoicesRow
+		if err := rows.Scan(
+			&i.InvoiceDate,
+			&i.DueDate,
+			&i.DatePaid,
+			&i.PaymentTerms,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getInvoice = `-- name: GetInvoice :one
-SELECT id, project_id, invoice_date, date_paid, payment_terms, amount_due, display_details, updated_at, created_at, deleted_at 
+SELECT id, project_id, invoice_date, date_paid, payment_terms, amount_due, display_details, invoice_number, voided_at, void_reason, amount_paid, credit_applied, due_date, cc_email, timesheets_from, timesheets_to, hourly_rate, discount_percent, discount_amount, discount_reason, adjustment_amount, adjustment_reason, currency_display, currency_conversion_rate, financials_captured_at, conversion_rate_source, converted_at, pdf_signed_at, pdf_signed_by, timesheets_changed_at, budget_code, updated_at, created_at, deleted_at 
 FROM invoice 
 WHERE id = ? AND deleted_at IS NULL
 `
 
 type GetInvoiceRow struct {
-	ID             int64       `json:"id"`
-	ProjectID      int64       `json:"project_id"`
-	InvoiceDate    time.Time   `json:"invoice_date"`
-	DatePaid       interface{} `json:"date_paid"`
-	PaymentTerms   string      `json:"payment_terms"`
-	AmountDue      float64     `json:"amount_due"`
-	DisplayDetails bool        `json:"display_details"`
-	UpdatedAt      time.Time   `json:"updated_at"`
-	CreatedAt      time.Time   `json:"created_at"`
-	DeletedAt      interface{} `json:"deleted_at"`
+	ID                     int64           `json:"id"`
+	ProjectID              int64           `json:"project_id"`
+	InvoiceDate            Date            `json:"invoice_date"`
+	DatePaid               NullDate        `json:"date_paid"`
+	PaymentTerms           string          `json:"payment_terms"`
+	AmountDue              float64         `json:"amount_due"`
+	DisplayDetails         bool            `json:"display_details"`
+	InvoiceNumber          sql.NullString  `json:"invoice_number"`
+	VoidedAt               sql.NullTime    `json:"voided_at"`
+	VoidReason             sql.NullString  `json:"void_reason"`
+	AmountPaid             sql.NullFloat64 `json:"amount_paid"`
+	CreditApplied          float64         `json:"credit_applied"`
+	DueDate                NullDate        `json:"due_date"`
+	CcEmail                sql.NullString  `json:"cc_email"`
+	TimesheetsFrom         NullDate        `json:"timesheets_from"`
+	TimesheetsTo           NullDate        `json:"timesheets_to"`
+	HourlyRate             sql.NullFloat64 `json:"hourly_rate"`
+	DiscountPercent        sql.NullFloat64 `json:"discount_percent"`
+	DiscountAmount         sql.NullFloat64 `json:"discount_amount"`
+	DiscountReason         sql.NullString  `json:"discount_reason"`
+	AdjustmentAmount       sql.NullFloat64 `json:"adjustment_amount"`
+	AdjustmentReason       sql.NullString  `json:"adjustment_reason"`
+	CurrencyDisplay        sql.NullString  `json:"currency_display"`
+	CurrencyConversionRate sql.NullFloat64 `json:"currency_conversion_rate"`
+	FinancialsCapturedAt   sql.NullTime    `json:"financials_captured_at"`
+	ConversionRateSource   sql.NullString  `json:"conversion_rate_source"`
+	ConvertedAt            sql.NullTime    `json:"converted_at"`
+	PdfSignedAt            sql.NullTime    `json:"pdf_signed_at"`
+	PdfSignedBy            sql.NullString  `json:"pdf_signed_by"`
+	TimesheetsChangedAt    sql.NullTime    `json:"timesheets_changed_at"`
+	BudgetCode             sql.NullString  `json:"budget_code"`
+	UpdatedAt              time.Time       `json:"updated_at"`
+	CreatedAt              time.Time       `json:"created_at"`
+	DeletedAt              interface{}     `json:"deleted_at"`
 }
 
 func (q *Queries) GetInvoice(ctx context.Context, id int64) (GetInvoiceRow, error) {
@@ -51,6 +190,30 @@ func (q *Queries) GetInvoice(ctx context.Context, id int64) (GetInvoiceRow, erro
 		&i.PaymentTerms,
 		&i.AmountDue,
 		&i.DisplayDetails,
+		&i.InvoiceNumber,
+		&i.VoidedAt,
+		&i.VoidReason,
+		&i.AmountPaid,
+		&i.CreditApplied,
+		&i.DueDate,
+		&i.CcEmail,
+		&i.TimesheetsFrom,
+		&i.TimesheetsTo,
+		&i.HourlyRate,
+		&i.DiscountPercent,
+		&i.DiscountAmount,
+		&i.DiscountReason,
+		&i.AdjustmentAmount,
+		&i.AdjustmentReason,
+		&i.CurrencyDisplay,
+		&i.CurrencyConversionRate,
+		&i.FinancialsCapturedAt,
+		&i.ConversionRateSource,
+		&i.ConvertedAt,
+		&i.PdfSignedAt,
+		&i.PdfSignedBy,
+		&i.TimesheetsChangedAt,
+		&i.BudgetCode,
 		&i.UpdatedAt,
 		&i.CreatedAt,
 		&i.DeletedAt,
@@ -58,10 +221,130 @@ func (q *Queries) GetInvoice(ctx context.Context, id int64) (GetInvoiceRow, erro
 	return i, err
 }
 
-const getInvoiceForPDF = `-- name: GetInvoiceForPDF :one
+const getInvoiceComprehensiveForPDF = `-- name: GetInvoiceComprehensiveForPDF :one
 SELECT 
-    i.id, i.project_id, i.invoice_date, i.date_paid, i.payment_terms, i.amount_due, i.display_details,
+    i.id, i.project_id, i.invoice_date, i.date_paid, i.payment_terms, i.amount_due, i.display_details, i.invoice_number, i.voided_at, i.void_reason, i.amount_paid, i.credit_applied, i.due_date,
     i.updated_at, i.created_at, i.deleted_at,
+    p.name as project_name, p.status as project_status, p.hourly_rate as project_hourly_rate,
+    p.discount_percent, p.discount_reason, p.adjustment_amount, p.adjustment_reason,
+    p.currency_display, p.currency_conversion_rate, p.flat_fee_invoice,
+    p.additional_info as project_additional_info, p.additional_info2 as project_additional_info2,
+    c.id as client_id, c.name as client_name, c.email as client_email,
+    c.phone as client_phone, c.address1 as client_address1, c.address2 as client_address2, 
+    c.address3 as client_address3, c.city as client_city, c.state as client_state, 
+    c.zip_code as client_zip_code, c.country as client_country, c.bill_to as client_bill_to,
+    c.include_address_on_invoice, c.university_affiliation,
+    c.additional_info as client_additional_info, c.additional_info2 as client_additional_info2
+FROM invoice i
+JOIN project p ON i.project_id = p.id
+JOIN client c ON p.client_id = c.id
+WHERE i.id = ? AND i.deleted_at IS NULL
+`
+
+type GetInvoiceComprehensiveForPDFRow struct {
+	ID                      int64           `json:"id"`
+	ProjectID               int64           `json:"project_id"`
+	InvoiceDate             Date            `json:"invoice_date"`
+	DatePaid                NullDate        `json:"date_paid"`
+	PaymentTerms            string          `json:"payment_terms"`
+	AmountDue               float64         `json:"amount_due"`
+	DisplayDetails          bool            `json:"display_details"`
+	InvoiceNumber           sql.NullString  `json:"invoice_number"`
+	VoidedAt                sql.NullTime    `json:"voided_at"`
+	VoidReason              sql.NullString  `json:"void_reason"`
+	AmountPaid              sql.NullFloat64 `json:"amount_paid"`
+	CreditApplied           float64         `json:"credit_applied"`
+	DueDate                 NullDate        `json:"due_date"`
+	UpdatedAt               time.Time       `json:"updated_at"`
+	CreatedAt               time.Time       `json:"created_at"`
+	DeletedAt               interface{}     `json:"deleted_at"`
+	ProjectName             string          `json:"project_name"`
+	ProjectStatus           string          `json:"project_status"`
+	ProjectHourlyRate       float64         `json:"project_hourly_rate"`
+	DiscountPercent         sql.NullFloat64 `json:"discount_percent"`
+	DiscountReason          sql.NullString  `json:"discount_reason"`
+	AdjustmentAmount        sql.NullFloat64 `json:"adjustment_amount"`
+	AdjustmentReason        sql.NullString  `json:"adjustment_reason"`
+	CurrencyDisplay         string          `json:"currency_display"`
+	CurrencyConversionRate  float64         `json:"currency_conversion_rate"`
+	FlatFeeInvoice          int64           `json:"flat_fee_invoice"`
+	ProjectAdditionalInfo   sql.NullString  `json:"project_additional_info"`
+	ProjectAdditionalInfo2  sql.NullString  `json:"project_additional_info2"`
+	ClientID                int64           `json:"client_id"`
+	ClientName              string          `json:"client_name"`
+	ClientEmail             string          `json:"client_email"`
+	ClientPhone             sql.NullString  `json:"client_phone"`
+	ClientAddress1          sql.NullString  `json:"client_address1"`
+	ClientAddress2          sql.NullString  `json:"client_address2"`
+	ClientAddress3          sql.NullString  `json:"client_address3"`
+	ClientCity              sql.NullString  `json:"client_city"`
+	ClientState             sql.NullString  `json:"client_state"`
+	ClientZipCode           sql.NullString  `json:"client_zip_code"`
+	ClientCountry           sql.NullString  `json:"client_country"`
+	ClientBillTo            sql.NullString  `json:"client_bill_to"`
+	IncludeAddressOnInvoice bool            `json:"include_address_on_invoice"`
+	UniversityAffiliation   sql.NullString  `json:"university_affiliation"`
+	ClientAdditionalInfo    sql.NullString  `json:"client_additional_info"`
+	ClientAdditionalInfo2   sql.NullString  `json:"client_additional_info2"`
+}
+
+func (q *Queries) GetInvoiceComprehensiveForPDF(ctx context.Context, id int64) (GetInvoiceComprehensiveForPDFRow, error) {
+	row := q.db.QueryRowContext(ctx, getInvoiceComprehensiveForPDF, id)
+	var i GetInvoiceComprehensiveForPDFRow
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.InvoiceDate,
+		&i.DatePaid,
+		&i.PaymentTerms,
+		&i.AmountDue,
+		&i.DisplayDetails,
+		&i.InvoiceNumber,
+		&i.VoidedAt,
+		&i.VoidReason,
+		&i.AmountPaid,
+		&i.CreditApplied,
+		&i.DueDate,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.ProjectName,
+		&i.ProjectStatus,
+		&i.ProjectHourlyRate,
+		&i.DiscountPercent,
+		&i.DiscountReason,
+		&i.AdjustmentAmount,
+		&i.AdjustmentReason,
+		&i.CurrencyDisplay,
+		&i.CurrencyConversionRate,
+		&i.FlatFeeInvoice,
+		&i.ProjectAdditionalInfo,
+		&i.ProjectAdditionalInfo2,
+		&i.ClientID,
+		&i.ClientName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress1,
+		&i.ClientAddress2,
+		&i.ClientAddress3,
+		&i.ClientCity,
+		&i.ClientState,
+		&i.ClientZipCode,
+		&i.ClientCountry,
+		&i.ClientBillTo,
+		&i.IncludeAddressOnInvoice,
+		&i.UniversityAffiliation,
+		&i.ClientAdditionalInfo,
+		&i.ClientAdditionalInfo2,
+	)
+	return i, err
+}
+
+const getInvoiceForPDF = `-- name: GetInvoiceForPDF :one
+SELECT 
+    i.id, i.project_id, i.invoice_date, i.date_paid, i.payment_terms, i.amount_due, i.display_details, i.invoice_number, i.voided_at, i.void_reason, i.amount_paid, i.credit_applied, i.due_date,
+    i.timesheets_from, i.timesheets_to, i.hourly_rate, i.discount_percent, i.discount_amount, i.discount_reason, i.adjustment_amount, i.adjustment_reason,
+    i.currency_display, i.currency_conversion_rate, i.financials_captured_at, i.conversion_rate_source, i.converted_at, i.budget_code, i.updated_at, i.created_at, i.deleted_at,
     p.name as project_name,
     c.name as client_name
 FROM invoice i
@@ -71,18 +354,38 @@ WHERE i.id = ? AND i.deleted_at IS NULL
 `
 
 type GetInvoiceForPDFRow struct {
-	ID             int64       `json:"id"`
-	ProjectID      int64       `json:"project_id"`
-	InvoiceDate    time.Time   `json:"invoice_date"`
-	DatePaid       interface{} `json:"date_paid"`
-	PaymentTerms   string      `json:"payment_terms"`
-	AmountDue      float64     `json:"amount_due"`
-	DisplayDetails bool        `json:"display_details"`
-	UpdatedAt      time.Time   `json:"updated_at"`
-	CreatedAt      time.Time   `json:"created_at"`
-	DeletedAt      interface{} `json:"deleted_at"`
-	ProjectName    string      `json:"project_name"`
-	ClientName     string      `json:"client_name"`
+	ID                     int64           `json:"id"`
+	ProjectID              int64           `json:"project_id"`
+	InvoiceDate            Date            `json:"invoice_date"`
+	DatePaid               NullDate        `json:"date_paid"`
+	PaymentTerms           string          `json:"payment_terms"`
+	AmountDue              float64         `json:"amount_due"`
+	DisplayDetails         bool            `json:"display_details"`
+	InvoiceNumber          sql.NullString  `json:"invoice_number"`
+	VoidedAt               sql.NullTime    `json:"voided_at"`
+	VoidReason             sql.NullString  `json:"void_reason"`
+	AmountPaid             sql.NullFloat64 `json:"amount_paid"`
+	CreditApplied          float64         `json:"credit_applied"`
+	DueDate                NullDate        `json:"due_date"`
+	TimesheetsFrom         NullDate        `json:"timesheets_from"`
+	TimesheetsTo           NullDate        `json:"timesheets_to"`
+	HourlyRate             sql.NullFloat64 `json:"hourly_rate"`
+	DiscountPercent        sql.NullFloat64 `json:"discount_percent"`
+	DiscountAmount         sql.NullFloat64 `json:"discount_amount"`
+	DiscountReason         sql.NullString  `json:"discount_reason"`
+	AdjustmentAmount       sql.NullFloat64 `json:"adjustment_amount"`
+	AdjustmentReason       sql.NullString  `json:"adjustment_reason"`
+	CurrencyDisplay        sql.NullString  `json:"currency_display"`
+	CurrencyConversionRate sql.NullFloat64 `json:"currency_conversion_rate"`
+	FinancialsCapturedAt   sql.NullTime    `json:"financials_captured_at"`
+	ConversionRateSource   sql.NullString  `json:"conversion_rate_source"`
+	ConvertedAt            sql.NullTime    `json:"converted_at"`
+	BudgetCode             sql.NullString  `json:"budget_code"`
+	UpdatedAt              time.Time       `json:"updated_at"`
+	CreatedAt              time.Time       `json:"created_at"`
+	DeletedAt              interface{}     `json:"deleted_at"`
+	ProjectName            string          `json:"project_name"`
+	ClientName             string          `json:"client_name"`
 }
 
 func (q *Queries) GetInvoiceForPDF(ctx context.Context, id int64) (GetInvoiceForPDFRow, error) {
@@ -96,33 +399,160 @@ func (q *Queries) GetInvoiceForPDF(ctx context.Context, id int64) (GetInvoiceFor
 		&i.PaymentTerms,
 		&i.AmountDue,
 		&i.DisplayDetails,
+		&i.InvoiceNumber,
+		&i.VoidedAt,
+		&i.VoidReason,
+		&i.AmountPaid,
+		&i.CreditApplied,
+		&i.DueDate,
+		&i.TimesheetsFrom,
+		&i.TimesheetsTo,
+		&i.HourlyRate,
+		&i.DiscountPercent,
+		&i.DiscountAmount,
+		&i.DiscountReason,
+		&i.AdjustmentAmount,
+		&i.AdjustmentReason,
+		&i.CurrencyDisplay,
+		&i.CurrencyConversionRate,
+		&i.FinancialsCapturedAt,
+		&i.ConversionRateSource,
+		&i.ConvertedAt,
+		&i.BudgetCode,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.ProjectName,
+		&i.ClientName,
+	)
+	return i, err
+}
+
+const getInvoiceWithClient = `-- name: GetInvoiceWithClient :one
+SELECT i.id, i.project_id, i.invoice_date, i.date_paid, i.payment_terms, i.amount_due, i.invoice_number,
+       i.voided_at, i.void_reason, i.amount_paid, i.credit_applied, i.due_date, i.updated_at, i.created_at, i.deleted_at,
+       p.name AS project_name, p.currency_display, p.client_id, c.name AS client_name,
+       i.hourly_rate, i.discount_percent, i.discount_amount, i.discount_reason, i.adjustment_amount, i.adjustment_reason,
+       i.currency_display AS invoice_currency_display, i.currency_conversion_rate, i.financials_captured_at, i.conversion_rate_source, i.converted_at
+FROM invoice i
+JOIN project p ON p.id = i.project_id
+JOIN client c ON c.id = p.client_id
+WHERE i.id = ? AND i.deleted_at IS NULL
+`
+
+type GetInvoiceWithClientRow struct {
+	ID                     int64           `json:"id"`
+	ProjectID              int64           `json:"project_id"`
+	InvoiceDate            Date            `json:"invoice_date"`
+	DatePaid               NullDate        `json:"date_paid"`
+	PaymentTerms           string          `json:"payment_terms"`
+	AmountDue              float64         `json:"amount_due"`
+	InvoiceNumber          sql.NullString  `json:"invoice_number"`
+	VoidedAt               sql.NullTime    `json:"voided_at"`
+	VoidReason             sql.NullString  `json:"void_reason"`
+	AmountPaid             sql.NullFloat64 `json:"amount_paid"`
+	CreditApplied          float64         `json:"credit_applied"`
+	DueDate                NullDate        `json:"due_date"`
+	UpdatedAt              time.Time       `json:"updated_at"`
+	CreatedAt              time.Time       `json:"created_at"`
+	DeletedAt              interface{}     `json:"deleted_at"`
+	ProjectName            string          `json:"project_name"`
+	CurrencyDisplay        string          `json:"currency_display"`
+	ClientID               int64           `json:"client_id"`
+	ClientName             string          `json:"client_name"`
+	HourlyRate             sql.NullFloat64 `json:"hourly_rate"`
+	DiscountPercent        sql.NullFloat64 `json:"discount_percent"`
+	DiscountAmount         sql.NullFloat64 `json:"discount_amount"`
+	DiscountReason         sql.NullString  `json:"discount_reason"`
+	AdjustmentAmount       sql.NullFloat64 `json:"adjustment_amount"`
+	AdjustmentReason       sql.NullString  `json:"adjustment_reason"`
+	InvoiceCurrencyDisplay sql.NullString  `json:"invoice_currency_display"`
+	CurrencyConversionRate sql.NullFloat64 `json:"currency_conversion_rate"`
+	FinancialsCapturedAt   sql.NullTime    `json:"financials_captured_at"`
+	ConversionRateSource   sql.NullString  `json:"conversion_rate_source"`
+	ConvertedAt            sql.NullTime    `json:"converted_at"`
+}
+
+func (q *Queries) GetInvoiceWithClient(ctx context.Context, id int64) (GetInvoiceWithClientRow, error) {
+	row := q.db.QueryRowContext(ctx, getInvoiceWithClient, id)
+	var i GetInvoiceWithClientRow
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.InvoiceDate,
+		&i.DatePaid,
+		&i.PaymentTerms,
+		&i.AmountDue,
+		&i.InvoiceNumber,
+		&i.VoidedAt,
+		&i.VoidReason,
+		&i.AmountPaid,
+		&i.CreditApplied,
+		&i.DueDate,
 		&i.UpdatedAt,
 		&i.CreatedAt,
 		&i.DeletedAt,
 		&i.ProjectName,
+		&i.CurrencyDisplay,
+		&i.ClientID,
 		&i.ClientName,
+		&i.HourlyRate,
+		&i.DiscountPercent,
+		&i.DiscountAmount,
+		&i.DiscountReason,
+		&i.AdjustmentAmount,
+		&i.AdjustmentReason,
+		&i.InvoiceCurrencyDisplay,
+		&i.CurrencyConversionRate,
+		&i.FinancialsCapturedAt,
+		&i.ConversionRateSource,
+		&i.ConvertedAt,
 	)
 	return i, err
 }
 
 const getInvoicesByProject = `-- name: GetInvoicesByProject :many
-SELECT id, project_id, invoice_date, date_paid, payment_terms, amount_due, display_details, updated_at, created_at, deleted_at 
+SELECT id, project_id, invoice_date, date_paid, payment_terms, amount_due, display_details, invoice_number, voided_at, void_reason, amount_paid, credit_applied, due_date, cc_email, timesheets_from, timesheets_to, hourly_rate, discount_percent, discount_amount, discount_reason, adjustment_amount, adjustment_reason, currency_display, currency_conversion_rate, financials_captured_at, conversion_rate_source, converted_at, pdf_signed_at, pdf_signed_by, timesheets_changed_at, budget_code, updated_at, created_at, deleted_at 
 FROM invoice 
 WHERE project_id = ? AND deleted_at IS NULL
 ORDER BY invoice_date DESC, created_at DESC
 `
 
 type GetInvoicesByProjectRow struct {
-	ID             int64       `json:"id"`
-	ProjectID      int64       `json:"project_id"`
-	InvoiceDate    time.Time   `json:"invoice_date"`
-	DatePaid       interface{} `json:"date_paid"`
-	PaymentTerms   string      `json:"payment_terms"`
-	AmountDue      float64     `json:"amount_due"`
-	DisplayDetails bool        `json:"display_details"`
-	UpdatedAt      time.Time   `json:"updated_at"`
-	CreatedAt      time.Time   `json:"created_at"`
-	DeletedAt      interface{} `json:"deleted_at"`
+	ID                     int64           `json:"id"`
+	ProjectID              int64           `json:"project_id"`
+	InvoiceDate            Date            `json:"invoice_date"`
+	DatePaid               NullDate        `json:"date_paid"`
+	PaymentTerms           string          `json:"payment_terms"`
+	AmountDue              float64         `json:"amount_due"`
+	DisplayDetails         bool            `json:"display_details"`
+	InvoiceNumber          sql.NullString  `json:"invoice_number"`
+	VoidedAt               sql.NullTime    `json:"voided_at"`
+	VoidReason             sql.NullString  `json:"void_reason"`
+	AmountPaid             sql.NullFloat64 `json:"amount_paid"`
+	CreditApplied          float64         `json:"credit_applied"`
+	DueDate                NullDate        `json:"due_date"`
+	CcEmail                sql.NullString  `json:"cc_email"`
+	TimesheetsFrom         NullDate        `json:"timesheets_from"`
+	TimesheetsTo           NullDate        `json:"timesheets_to"`
+	HourlyRate             sql.NullFloat64 `json:"hourly_rate"`
+	DiscountPercent        sql.NullFloat64 `json:"discount_percent"`
+	DiscountAmount         sql.NullFloat64 `json:"discount_amount"`
+	DiscountReason         sql.NullString  `json:"discount_reason"`
+	AdjustmentAmount       sql.NullFloat64 `json:"adjustment_amount"`
+	AdjustmentReason       sql.NullString  `json:"adjustment_reason"`
+	CurrencyDisplay        sql.NullString  `json:"currency_display"`
+	CurrencyConversionRate sql.NullFloat64 `json:"currency_conversion_rate"`
+	FinancialsCapturedAt   sql.NullTime    `json:"financials_captured_at"`
+	ConversionRateSource   sql.NullString  `json:"conversion_rate_source"`
+	ConvertedAt            sql.NullTime    `json:"converted_at"`
+	PdfSignedAt            sql.NullTime    `json:"pdf_signed_at"`
+	PdfSignedBy            sql.NullString  `json:"pdf_signed_by"`
+	TimesheetsChangedAt    sql.NullTime    `json:"timesheets_changed_at"`
+	BudgetCode             sql.NullString  `json:"budget_code"`
+	UpdatedAt              time.Time       `json:"updated_at"`
+	CreatedAt              time.Time       `json:"created_at"`
+	DeletedAt              interface{}     `json:"deleted_at"`
 }
 
 func (q *Queries) GetInvoicesByProject(ctx context.Context, projectID int64) ([]GetInvoicesByProjectRow, error) {
@@ -142,9 +572,361 @@ func (q *Queries) GetInvoicesByProject(ctx context.Context, projectID int64) ([]
 			&i.PaymentTerms,
 			&i.AmountDue,
 			&i.DisplayDetails,
+			&i.InvoiceNumber,
+			&i.VoidedAt,
+			&i.VoidReason,
+			&i.AmountPaid,
+			&i.CreditApplied,
+			&i.DueDate,
+			&i.CcEmail,
+			&i.TimesheetsFrom,
+			&i.TimesheetsTo,
+			&i.HourlyRate,
+			&i.DiscountPercent,
+			&i.DiscountAmount,
+			&i.DiscountReason,
+			&i.AdjustmentAmount,
+			&i.AdjustmentReason,
+			&i.CurrencyDisplay,
+			&i.CurrencyConversionRate,
+			&i.FinancialsCapturedAt,
+			&i.ConversionRateSource,
+			&i.ConvertedAt,
+			&i.PdfSignedAt,
+			&i.PdfSignedBy,
+			&i.TimesheetsChangedAt,
+			&i.BudgetCode,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInvoicesByProjectCount = `-- name: GetInvoicesByProjectCount :one
+SELECT COUNT(*)
+FROM invoice
+WHERE project_id = ? AND deleted_at IS NULL
+`
+
+func (q *Queries) GetInvoicesByProjectCount(ctx context.Context, projectID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getInvoicesByProjectCount, projectID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getInvoicesByProjectWithPagination = `-- name: GetInvoicesByProjectWithPagination :many
+SELECT id, project_id, invoice_date, date_paid, payment_terms, amount_due, display_details, invoice_number, voided_at, void_reason, amount_paid, credit_applied, due_date, cc_email, timesheets_from, timesheets_to, hourly_rate, discount_percent, discount_amount, discount_reason, adjustment_amount, adjustment_reason, currency_display, currency_conversion_rate, financials_captured_at, conversion_rate_source, converted_at, pdf_signed_at, pdf_signed_by, timesheets_changed_at, budget_code, updated_at, created_at, deleted_at 
+FROM invoice 
+WHERE project_id = ? AND deleted_at IS NULL
+ORDER BY invoice_date DESC, created_at DESC, id DESC
+LIMIT ? OFFSET ?
+`
+
+type GetInvoicesByProjectWithPaginationParams struct {
+	ProjectID int64 `json:"project_id"`
+	Limit     int64 `json:"limit"`
+	Offset    int64 `json:"offset"`
+}
+
+type GetInvoicesByProjectWithPaginationRow struct {
+	ID                     int64           `json:"id"`
+	ProjectID              int64           `json:"project_id"`
+	InvoiceDate            Date            `json:"invoice_date"`
+	DatePaid               NullDate        `json:"date_paid"`
+	PaymentTerms           string          `json:"payment_terms"`
+	AmountDue              float64         `json:"amount_due"`
+	DisplayDetails         bool            `json:"display_details"`
+	InvoiceNumber          sql.NullString  `json:"invoice_number"`
+	VoidedAt               sql.NullTime    `json:"voided_at"`
+	VoidReason             sql.NullString  `json:"void_reason"`
+	AmountPaid             sql.NullFloat64 `json:"amount_paid"`
+	CreditApplied          float64         `json:"credit_applied"`
+	DueDate                NullDate        `json:"due_date"`
+	CcEmail                sql.NullString  `json:"cc_email"`
+	TimesheetsFrom         NullDate        `json:"timesheets_from"`
+	TimesheetsTo           NullDate        `json:"timesheets_to"`
+	HourlyRate             sql.NullFloat64 `json:"hourly_rate"`
+	DiscountPercent        sql.NullFloat64 `json:"discount_percent"`
+	DiscountAmount         sql.NullFloat64 `json:"discount_amount"`
+	DiscountReason         sql.NullString  `json:"discount_reason"`
+	AdjustmentAmount       sql.NullFloat64 `json:"adjustment_amount"`
+	AdjustmentReason       sql.NullString  `json:"adjustment_reason"`
+	CurrencyDisplay        sql.NullString  `json:"currency_display"`
+	CurrencyConversionRate sql.NullFloat64 `json:"currency_conversion_rate"`
+	FinancialsCapturedAt   sql.NullTime    `json:"financials_captured_at"`
+	ConversionRateSource   sql.NullString  `json:"conversion_rate_source"`
+	ConvertedAt            sql.NullTime    `json:"converted_at"`
+	PdfSignedAt            sql.NullTime    `json:"pdf_signed_at"`
+	PdfSignedBy            sql.NullString  `json:"pdf_signed_by"`
+	TimesheetsChangedAt    sql.NullTime    `json:"timesheets_changed_at"`
+	BudgetCode             sql.NullString  `json:"budget_code"`
+	UpdatedAt              time.Time       `json:"updated_at"`
+	CreatedAt              time.Time       `json:"created_at"`
+	DeletedAt              interface{}     `json:"deleted_at"`
+}
+
+func (q *Queries) GetInvoicesByProjectWithPagination(ctx context.Context, arg GetInvoicesByProjectWithPaginationParams) ([]GetInvoicesByProjectWithPaginationRow, error) {
+	rows, err := q.db.QueryContext(ctx, getInvoicesByProjectWithPagination, arg.ProjectID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetInvoicesByProjectWithPaginationRow{}
+	for rows.Next() {
+		var i GetInvoicesByProjectWithPaginationRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.InvoiceDate,
+			&i.DatePaid,
+			&i.PaymentTerms,
+			&i.AmountDue,
+			&i.DisplayDetails,
+			&i.InvoiceNumber,
+			&i.VoidedAt,
+			&i.VoidReason,
+			&i.AmountPaid,
+			&i.CreditApplied,
+			&i.DueDate,
+			&i.CcEmail,
+			&i.TimesheetsFrom,
+			&i.TimesheetsTo,
+			&i.HourlyRate,
+			&i.DiscountPercent,
+			&i.DiscountAmount,
+			&i.DiscountReason,
+			&i.AdjustmentAmount,
+			&i.AdjustmentReason,
+			&i.CurrencyDisplay,
+			&i.CurrencyConversionRate,
+			&i.FinancialsCapturedAt,
+			&i.ConversionRateSource,
+			&i.ConvertedAt,
+			&i.PdfSignedAt,
+			&i.PdfSignedBy,
+			&i.TimesheetsChangedAt,
+			&i.BudgetCode,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInvoicesWithClientAfter = `-- name: GetInvoicesWithClientAfter :many
+SELECT i.id, i.project_id, i.invoice_date, i.date_paid, i.payment_terms, i.amount_due, i.invoice_number,
+       i.voided_at, i.void_reason, i.amount_paid, i.credit_applied, i.due_date, i.updated_at, i.created_at, i.deleted_at,
+       p.name AS project_name, p.currency_display, p.client_id, c.name AS client_name,
+       i.hourly_rate, i.discount_percent, i.discount_amount, i.discount_reason, i.adjustment_amount, i.adjustment_reason,
+       i.currency_display AS invoice_currency_display, i.currency_conversion_rate, i.financials_captured_at, i.conversion_rate_source, i.converted_at
+FROM invoice i
+JOIN project p ON p.id = i.project_id
+JOIN client c ON c.id = p.client_id
+WHERE i.id > ? AND i.deleted_at IS NULL
+ORDER BY i.id
+LIMIT ?
+`
+
+type GetInvoicesWithClientAfterParams struct {
+	ID    int64 `json:"id"`
+	Limit int64 `json:"limit"`
+}
+
+type GetInvoicesWithClientAfterRow struct {
+	ID                     int64           `json:"id"`
+	ProjectID              int64           `json:"project_id"`
+	InvoiceDate            Date            `json:"invoice_date"`
+	DatePaid               NullDate        `json:"date_paid"`
+	PaymentTerms           string          `json:"payment_terms"`
+	AmountDue              float64         `json:"amount_due"`
+	InvoiceNumber          sql.NullString  `json:"invoice_number"`
+	VoidedAt               sql.NullTime    `json:"voided_at"`
+	VoidReason             sql.NullString  `json:"void_reason"`
+	AmountPaid             sql.NullFloat64 `json:"amount_paid"`
+	CreditApplied          float64         `json:"credit_applied"`
+	DueDate                NullDate        `json:"due_date"`
+	UpdatedAt              time.Time       `json:"updated_at"`
+	CreatedAt              time.Time       `json:"created_at"`
+	DeletedAt              interface{}     `json:"deleted_at"`
+	ProjectName            string          `json:"project_name"`
+	CurrencyDisplay        string          `json:"currency_display"`
+	ClientID               int64           `json:"client_id"`
+	ClientName             string          `json:"client_name"`
+	HourlyRate             sql.NullFloat64 `json:"hourly_rate"`
+	DiscountPercent        sql.NullFloat64 `json:"discount_percent"`
+	DiscountAmount         sql.NullFloat64 `json:"discount_amount"`
+	DiscountReason         sql.NullString  `json:"discount_reason"`
+	AdjustmentAmount       sql.NullFloat64 `json:"adjustment_amount"`
+	AdjustmentReason       sql.NullString  `json:"adjustment_reason"`
+	InvoiceCurrencyDisplay sql.NullString  `json:"invoice_currency_display"`
+	CurrencyConversionRate sql.NullFloat64 `json:"currency_conversion_rate"`
+	FinancialsCapturedAt   sql.NullTime    `json:"financials_captured_at"`
+	ConversionRateSource   sql.NullString  `json:"conversion_rate_source"`
+	ConvertedAt            sql.NullTime    `json:"converted_at"`
+}
+
+func (q *Queries) GetInvoicesWithClientAfter(ctx context.Context, arg GetInvoicesWithClientAfterParams) ([]GetInvoicesWithClientAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, getInvoicesWithClientAfter, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetInvoicesWithClientAfterRow{}
+	for rows.Next() {
+		var i GetInvoicesWithClientAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.InvoiceDate,
+			&i.DatePaid,
+			&i.PaymentTerms,
+			&i.AmountDue,
+			&i.InvoiceNumber,
+			&i.VoidedAt,
+			&i.VoidReason,
+			&i.AmountPaid,
+			&i.CreditApplied,
+			&i.DueDate,
 			&i.UpdatedAt,
 			&i.CreatedAt,
 			&i.DeletedAt,
+			&i.ProjectName,
+			&i.CurrencyDisplay,
+			&i.ClientID,
+			&i.ClientName,
+			&i.HourlyRate,
+			&i.DiscountPercent,
+			&i.DiscountAmount,
+			&i.DiscountReason,
+			&i.AdjustmentAmount,
+			&i.AdjustmentReason,
+			&i.InvoiceCurrencyDisplay,
+			&i.CurrencyConversionRate,
+			&i.FinancialsCapturedAt,
+			&i.ConversionRateSource,
+			&i.ConvertedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOpenInvoicesWithClient = `-- name: GetOpenInvoicesWithClient :many
+SELECT i.id, i.project_id, i.invoice_date, i.date_paid, i.payment_terms, i.amount_due, i.invoice_number,
+       i.voided_at, i.void_reason, i.amount_paid, i.credit_applied, i.due_date, i.updated_at, i.created_at, i.deleted_at,
+       p.name AS project_name, p.currency_display, p.client_id, c.name AS client_name,
+       i.hourly_rate, i.discount_percent, i.discount_amount, i.discount_reason, i.adjustment_amount, i.adjustment_reason,
+       i.currency_display AS invoice_currency_display, i.currency_conversion_rate, i.financials_captured_at, i.conversion_rate_source, i.converted_at
+FROM invoice i
+JOIN project p ON p.id = i.project_id
+JOIN client c ON c.id = p.client_id
+WHERE i.date_paid IS NULL AND i.voided_at IS NULL AND i.deleted_at IS NULL AND p.deleted_at IS NULL
+ORDER BY i.invoice_date, i.id
+`
+
+type GetOpenInvoicesWithClientRow struct {
+	ID                     int64           `json:"id"`
+	ProjectID              int64           `json:"project_id"`
+	InvoiceDate            Date            `json:"invoice_date"`
+	DatePaid               NullDate        `json:"date_paid"`
+	PaymentTerms           string          `json:"payment_terms"`
+	AmountDue              float64         `json:"amount_due"`
+	InvoiceNumber          sql.NullString  `json:"invoice_number"`
+	VoidedAt               sql.NullTime    `json:"voided_at"`
+	VoidReason             sql.NullString  `json:"void_reason"`
+	AmountPaid             sql.NullFloat64 `json:"amount_paid"`
+	CreditApplied          float64         `json:"credit_applied"`
+	DueDate                NullDate        `json:"due_date"`
+	UpdatedAt              time.Time       `json:"updated_at"`
+	CreatedAt              time.Time       `json:"created_at"`
+	DeletedAt              interface{}     `json:"deleted_at"`
+	ProjectName            string          `json:"project_name"`
+	CurrencyDisplay        string          `json:"currency_display"`
+	ClientID               int64           `json:"client_id"`
+	ClientName             string          `json:"client_name"`
+	HourlyRate             sql.NullFloat64 `json:"hourly_rate"`
+	DiscountPercent        sql.NullFloat64 `json:"discount_percent"`
+	DiscountAmount         sql.NullFloat64 `json:"discount_amount"`
+	DiscountReason         sql.NullString  `json:"discount_reason"`
+	AdjustmentAmount       sql.NullFloat64 `json:"adjustment_amount"`
+	AdjustmentReason       sql.NullString  `json:"adjustment_reason"`
+	InvoiceCurrencyDisplay sql.NullString  `json:"invoice_currency_display"`
+	CurrencyConversionRate sql.NullFloat64 `json:"currency_conversion_rate"`
+	FinancialsCapturedAt   sql.NullTime    `json:"financials_captured_at"`
+	ConversionRateSource   sql.NullString  `json:"conversion_rate_source"`
+	ConvertedAt            sql.NullTime    `json:"converted_at"`
+}
+
+func (q *Queries) GetOpenInvoicesWithClient(ctx context.Context) ([]GetOpenInvoicesWithClientRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOpenInvoicesWithClient)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetOpenInvoicesWithClientRow{}
+	for rows.Next() {
+		var i GetOpenInvoicesWithClientRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.InvoiceDate,
+			&i.DatePaid,
+			&i.PaymentTerms,
+			&i.AmountDue,
+			&i.InvoiceNumber,
+			&i.VoidedAt,
+			&i.VoidReason,
+			&i.AmountPaid,
+			&i.CreditApplied,
+			&i.DueDate,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.ProjectName,
+			&i.CurrencyDisplay,
+			&i.ClientID,
+			&i.ClientName,
+			&i.HourlyRate,
+			&i.DiscountPercent,
+			&i.DiscountAmount,
+			&i.DiscountReason,
+			&i.AdjustmentAmount,
+			&i.AdjustmentReason,
+			&i.InvoiceCurrencyDisplay,
+			&i.CurrencyConversionRate,
+			&i.FinancialsCapturedAt,
+			&i.ConversionRateSource,
+			&i.ConvertedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -165,12 +947,12 @@ VALUES (?, ?, ?, ?, ?, ?)
 `
 
 type InsertInvoiceParams struct {
-	ProjectID      int64       `json:"project_id"`
-	InvoiceDate    time.Time   `json:"invoice_date"`
-	DatePaid       interface{} `json:"date_paid"`
-	PaymentTerms   string      `json:"payment_terms"`
-	AmountDue      float64     `json:"amount_due"`
-	DisplayDetails bool        `json:"display_details"`
+	ProjectID      int64    `json:"project_id"`
+	InvoiceDate    Date     `json:"invoice_date"`
+	DatePaid       NullDate `json:"date_paid"`
+	PaymentTerms   string   `json:"payment_terms"`
+	AmountDue      float64  `json:"amount_due"`
+	DisplayDetails bool     `json:"display_details"`
 }
 
 func (q *Queries) InsertInvoice(ctx context.Context, arg InsertInvoiceParams) (int64, error) {
@@ -188,19 +970,213 @@ func (q *Queries) InsertInvoice(ctx context.Context, arg InsertInvoiceParams) (i
 	return result.LastInsertId()
 }
 
-const updateInvoice = `-- name: UpdateInvoice :exec
+const markInvoicePaid = `-- name: MarkInvoicePaid :execrows
+UPDATE invoice 
+SET date_paid = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND date_paid IS NULL AND deleted_at IS NULL AND voided_at IS NULL
+`
+
+type MarkInvoicePaidParams struct {
+	DatePaid NullDate `json:"date_paid"`
+	ID       int64    `json:"id"`
+}
+
+func (q *Queries) MarkInvoicePaid(ctx context.Context, arg MarkInvoicePaidParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markInvoicePaid, arg.DatePaid, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreClientInvoices = `-- name: RestoreClientInvoices :exec
+UPDATE invoice 
+SET deleted_at = NULL 
+WHERE project_id IN (SELECT p.id FROM project p WHERE p.client_id = ? AND p.deleted_at = (SELECT c.deleted_at FROM client c WHERE c.id = p.client_id)) 
+  AND deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = invoice.project_id)
+`
+
+func (q *Queries) RestoreClientInvoices(ctx context.Context, clientID int64) error {
+	_, err := q.db.ExecContext(ctx, restoreClientInvoices, clientID)
+	return err
+}
+
+const restoreProjectInvoices = `-- name: RestoreProjectInvoices :exec
+UPDATE invoice 
+SET deleted_at = NULL 
+WHERE project_id = ? AND deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = invoice.project_id)
+`
+
+func (q *Queries) RestoreProjectInvoices(ctx context.Context, projectID int64) error {
+	_, err := q.db.ExecContext(ctx, restoreProjectInvoices, projectID)
+	return err
+}
+
+const setInvoiceBudgetCode = `-- name: SetInvoiceBudgetCode :exec
+UPDATE invoice 
+SET budget_code = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL
+`
+
+type SetInvoiceBudgetCodeParams struct {
+	BudgetCode sql.NullString `json:"budget_code"`
+	ID         int64          `json:"id"`
+}
+
+func (q *Queries) SetInvoiceBudgetCode(ctx context.Context, arg SetInvoiceBudgetCodeParams) error {
+	_, err := q.db.ExecContext(ctx, setInvoiceBudgetCode, arg.BudgetCode, arg.ID)
+	return err
+}
+
+const setInvoiceCCEmail = `-- name: SetInvoiceCCEmail :exec
+UPDATE invoice 
+SET cc_email = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL
+`
+
+type SetInvoiceCCEmailParams struct {
+	CcEmail sql.NullString `json:"cc_email"`
+	ID      int64          `json:"id"`
+}
+
+func (q *Queries) SetInvoiceCCEmail(ctx context.Context, arg SetInvoiceCCEmailParams) error {
+	_, err := q.db.ExecContext(ctx, setInvoiceCCEmail, arg.CcEmail, arg.ID)
+	return err
+}
+
+const setInvoiceDueDate = `-- name: SetInvoiceDueDate :exec
+UPDATE invoice 
+SET due_date = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL
+`
+
+type SetInvoiceDueDateParams struct {
+	DueDate NullDate `json:"due_date"`
+	ID      int64    `json:"id"`
+}
+
+func (q *Queries) SetInvoiceDueDate(ctx context.Context, arg SetInvoiceDueDateParams) error {
+	_, err := q.db.ExecContext(ctx, setInvoiceDueDate, arg.DueDate, arg.ID)
+	return err
+}
+
+const setInvoiceFinancials = `-- name: SetInvoiceFinancials :exec
 UPDATE invoice 
-SET invoice_date = ?, date_paid = ?, payment_terms = ?, amount_due = ?, display_details = ?, updated_at = CURRENT_TIMESTAMP 
+SET hourly_rate = ?, discount_percent = ?, discount_amount = ?, discount_reason = ?, adjustment_amount = ?, adjustment_reason = ?, currency_display = ?, currency_conversion_rate = ?, conversion_rate_source = ?, converted_at = ?, financials_captured_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP 
 WHERE id = ? AND deleted_at IS NULL
 `
 
+type SetInvoiceFinancialsParams struct {
+	HourlyRate             sql.NullFloat64 `json:"hourly_rate"`
+	DiscountPercent        sql.NullFloat64 `json:"discount_percent"`
+	DiscountAmount         sql.NullFloat64 `json:"discount_amount"`
+	DiscountReason         sql.NullString  `json:"discount_reason"`
+	AdjustmentAmount       sql.NullFloat64 `json:"adjustment_amount"`
+	AdjustmentReason       sql.NullString  `json:"adjustment_reason"`
+	CurrencyDisplay        sql.NullString  `json:"currency_display"`
+	CurrencyConversionRate sql.NullFloat64 `json:"currency_conversion_rate"`
+	ConversionRateSource   sql.NullString  `json:"conversion_rate_source"`
+	ConvertedAt            sql.NullTime    `json:"converted_at"`
+	ID                     int64           `json:"id"`
+}
+
+func (q *Queries) SetInvoiceFinancials(ctx context.Context, arg SetInvoiceFinancialsParams) error {
+	_, err := q.db.ExecContext(ctx, setInvoiceFinancials,
+		arg.HourlyRate,
+		arg.DiscountPercent,
+		arg.DiscountAmount,
+		arg.DiscountReason,
+		arg.AdjustmentAmount,
+		arg.AdjustmentReason,
+		arg.CurrencyDisplay,
+		arg.CurrencyConversionRate,
+		arg.ConversionRateSource,
+		arg.ConvertedAt,
+		arg.ID,
+	)
+	return err
+}
+
+const setInvoiceNumber = `-- name: SetInvoiceNumber :exec
+UPDATE invoice 
+SET invoice_number = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL
+`
+
+type SetInvoiceNumberParams struct {
+	InvoiceNumber sql.NullString `json:"invoice_number"`
+	ID            int64          `json:"id"`
+}
+
+func (q *Queries) SetInvoiceNumber(ctx context.Context, arg SetInvoiceNumberParams) error {
+	_, err := q.db.ExecContext(ctx, setInvoiceNumber, arg.InvoiceNumber, arg.ID)
+	return err
+}
+
+const setInvoicePDFSignature = `-- name: SetInvoicePDFSignature :exec
+UPDATE invoice 
+SET pdf_signed_at = ?, pdf_signed_by = ? 
+WHERE id = ? AND deleted_at IS NULL
+`
+
+type SetInvoicePDFSignatureParams struct {
+	PdfSignedAt sql.NullTime   `json:"pdf_signed_at"`
+	PdfSignedBy sql.NullString `json:"pdf_signed_by"`
+	ID          int64          `json:"id"`
+}
+
+func (q *Queries) SetInvoicePDFSignature(ctx context.Context, arg SetInvoicePDFSignatureParams) error {
+	_, err := q.db.ExecContext(ctx, setInvoicePDFSignature, arg.PdfSignedAt, arg.PdfSignedBy, arg.ID)
+	return err
+}
+
+const setInvoicePayment = `-- name: SetInvoicePayment :exec
+UPDATE invoice 
+SET amount_paid = ?, credit_applied = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL
+`
+
+type SetInvoicePaymentParams struct {
+	AmountPaid    sql.NullFloat64 `json:"amount_paid"`
+	CreditApplied float64         `json:"credit_applied"`
+	ID            int64           `json:"id"`
+}
+
+func (q *Queries) SetInvoicePayment(ctx context.Context, arg SetInvoicePaymentParams) error {
+	_, err := q.db.ExecContext(ctx, setInvoicePayment, arg.AmountPaid, arg.CreditApplied, arg.ID)
+	return err
+}
+
+const setInvoiceTimesheetRange = `-- name: SetInvoiceTimesheetRange :exec
+UPDATE invoice 
+SET timesheets_from = ?, timesheets_to = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL
+`
+
+type SetInvoiceTimesheetRangeParams struct {
+	TimesheetsFrom NullDate `json:"timesheets_from"`
+	TimesheetsTo   NullDate `json:"timesheets_to"`
+	ID             int64    `json:"id"`
+}
+
+func (q *Queries) SetInvoiceTimesheetRange(ctx context.Context, arg SetInvoiceTimesheetRangeParams) error {
+	_, err := q.db.ExecContext(ctx, setInvoiceTimesheetRange, arg.TimesheetsFrom, arg.TimesheetsTo, arg.ID)
+	return err
+}
+
+const updateInvoice = `-- name: UpdateInvoice :exec
+UPDATE invoice 
+SET invoice_date = ?, date_paid = ?, payment_terms = ?, amount_due = ?, display_details = ?, timesheets_changed_at = NULL, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL AND voided_at IS NULL
+`
+
 type UpdateInvoiceParams struct {
-	InvoiceDate    time.Time   `json:"invoice_date"`
-	DatePaid       interface{} `json:"date_paid"`
-	PaymentTerms   string      `json:"payment_terms"`
-	AmountDue      float64     `json:"amount_due"`
-	DisplayDetails bool        `json:"display_details"`
-	ID             int64       `json:"id"`
+	InvoiceDate    Date     `json:"invoice_date"`
+	DatePaid       NullDate `json:"date_paid"`
+	PaymentTerms   string   `json:"payment_terms"`
+	AmountDue      float64  `json:"amount_due"`
+	DisplayDetails bool     `json:"display_details"`
+	ID             int64    `json:"id"`
 }
 
 func (q *Queries) UpdateInvoice(ctx context.Context, arg UpdateInvoiceParams) error {
@@ -214,3 +1190,22 @@ func (q *Queries) UpdateInvoice(ctx context.Context, arg UpdateInvoiceParams) er
 	)
 	return err
 }
+
+const voidInvoice = `-- name: VoidInvoice :execrows
+UPDATE invoice 
+SET voided_at = CURRENT_TIMESTAMP, void_reason = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL AND voided_at IS NULL
+`
+
+type VoidInvoiceParams struct {
+	VoidReason sql.NullString `json:"void_reason"`
+	ID         int64          `json:"id"`
+}
+
+func (q *Queries) VoidInvoice(ctx context.Context, arg VoidInvoiceParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, voidInvoice, arg.VoidReason, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
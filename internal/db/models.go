@@ -9,42 +9,264 @@ import (
 	"time"
 )
 
+type BusinessProfile struct {
+	ID                     int64       `json:"id"`
+	Name                   string      `json:"name"`
+	FreelancerName         string      `json:"freelancer_name"`
+	FreelancerAddress      string      `json:"freelancer_address"`
+	FreelancerCityStateZip string      `json:"freelancer_city_state_zip"`
+	FreelancerPhone        string      `json:"freelancer_phone"`
+	FreelancerEmail        string      `json:"freelancer_email"`
+	LogoPath               string      `json:"logo_path"`
+	BankDetails            string      `json:"bank_details"`
+	InvoicePrefix          string      `json:"invoice_prefix"`
+	NextInvoiceNumber      int64       `json:"next_invoice_number"`
+	CreatedAt              time.Time   `json:"created_at"`
+	UpdatedAt              time.Time   `json:"updated_at"`
+	DeletedAt              interface{} `json:"deleted_at"`
+}
+
+type ChecklistItem struct {
+	ID        int64         `json:"id"`
+	TagID     sql.NullInt64 `json:"tag_id"`
+	Label     string        `json:"label"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
 type Client struct {
-	ID                      int64          `json:"id"`
-	Name                    string         `json:"name"`
-	CreatedAt               time.Time      `json:"created_at"`
-	UpdatedAt               time.Time      `json:"updated_at"`
-	DeletedAt               interface{}    `json:"deleted_at"`
-	Email                   string         `json:"email"`
-	Phone                   sql.NullString `json:"phone"`
-	HourlyRate              float64        `json:"hourly_rate"`
-	Notes                   sql.NullString `json:"notes"`
-	AdditionalInfo          sql.NullString `json:"additional_info"`
-	AdditionalInfo2         sql.NullString `json:"additional_info2"`
-	BillTo                  sql.NullString `json:"bill_to"`
-	IncludeAddressOnInvoice bool           `json:"include_address_on_invoice"`
-	InvoiceCcEmail          sql.NullString `json:"invoice_cc_email"`
-	InvoiceCcDescription    sql.NullString `json:"invoice_cc_description"`
-	UniversityAffiliation   sql.NullString `json:"university_affiliation"`
-	Address1                sql.NullString `json:"address1"`
-	Address2                sql.NullString `json:"address2"`
-	Address3                sql.NullString `json:"address3"`
-	City                    sql.NullString `json:"city"`
-	State                   sql.NullString `json:"state"`
-	ZipCode                 sql.NullString `json:"zip_code"`
+	ID                      int64           `json:"id"`
+	Name                    string          `json:"name"`
+	CreatedAt               time.Time       `json:"created_at"`
+	UpdatedAt               time.Time       `json:"updated_at"`
+	DeletedAt               interface{}     `json:"deleted_at"`
+	Email                   string          `json:"email"`
+	Phone                   sql.NullString  `json:"phone"`
+	HourlyRate              float64         `json:"hourly_rate"`
+	AdditionalInfo          sql.NullString  `json:"additional_info"`
+	AdditionalInfo2         sql.NullString  `json:"additional_info2"`
+	BillTo                  sql.NullString  `json:"bill_to"`
+	IncludeAddressOnInvoice bool            `json:"include_address_on_invoice"`
+	InvoiceCcEmail          sql.NullString  `json:"invoice_cc_email"`
+	InvoiceCcDescription    sql.NullString  `json:"invoice_cc_description"`
+	UniversityAffiliation   sql.NullString  `json:"university_affiliation"`
+	Address1                sql.NullString  `json:"address1"`
+	Address2                sql.NullString  `json:"address2"`
+	Address3                sql.NullString  `json:"address3"`
+	City                    sql.NullString  `json:"city"`
+	State                   sql.NullString  `json:"state"`
+	ZipCode                 sql.NullString  `json:"zip_code"`
+	BusinessProfileID       sql.NullInt64   `json:"business_profile_id"`
+	MonthlyHourAllowance    sql.NullFloat64 `json:"monthly_hour_allowance"`
+	PrepayOnly              bool            `json:"prepay_only"`
+	Country                 sql.NullString  `json:"country"`
+	ConsolidatedInvoicing   bool            `json:"consolidated_invoicing"`
+	FiscalYearEnd           sql.NullString  `json:"fiscal_year_end"`
+	PoNumber                sql.NullString  `json:"po_number"`
+	PoValidFrom             sql.NullString  `json:"po_valid_from"`
+	PoValidTo               sql.NullString  `json:"po_valid_to"`
+	InvoiceLanguage         sql.NullString  `json:"invoice_language"`
+	InvoicePrefix           sql.NullString  `json:"invoice_prefix"`
+	NextInvoiceNumber       sql.NullInt64   `json:"next_invoice_number"`
+	BudgetCode              sql.NullString  `json:"budget_code"`
+	PipelineStage           sql.NullString  `json:"pipeline_stage"`
+}
+
+type ClientCredit struct {
+	ID          int64         `json:"id"`
+	ClientID    int64         `json:"client_id"`
+	InvoiceID   sql.NullInt64 `json:"invoice_id"`
+	Amount      float64       `json:"amount"`
+	Description string        `json:"description"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+type ClientNote struct {
+	ID        int64     `json:"id"`
+	ClientID  int64     `json:"client_id"`
+	Body      string    `json:"body"`
+	Pinned    bool      `json:"pinned"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ClientApproval struct {
+	ID         int64          `json:"id"`
+	ProjectID  int64          `json:"project_id"`
+	Token      string         `json:"token"`
+	StartDate  time.Time      `json:"start_date"`
+	EndDate    time.Time      `json:"end_date"`
+	ApprovedAt sql.NullTime   `json:"approved_at"`
+	ApprovedBy sql.NullString `json:"approved_by"`
+	ApprovedIp sql.NullString `json:"approved_ip"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type ClientProjectDefault struct {
+	ClientID               int64           `json:"client_id"`
+	Status                 string          `json:"status"`
+	CurrencyDisplay        string          `json:"currency_display"`
+	CurrencyConversionRate float64         `json:"currency_conversion_rate"`
+	DiscountPercent        sql.NullFloat64 `json:"discount_percent"`
+	DiscountReason         string          `json:"discount_reason"`
+	FlatFeeInvoice         bool            `json:"flat_fee_invoice"`
+	UpdatedAt              time.Time       `json:"updated_at"`
+}
+
+type ClientTag struct {
+	ClientID int64 `json:"client_id"`
+	TagID    int64 `json:"tag_id"`
+}
+
+type ExchangeRate struct {
+	Currency  string    `json:"currency"`
+	RateDate  string    `json:"rate_date"`
+	Rate      float64   `json:"rate"`
+	Source    string    `json:"source"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+type ExternalID struct {
+	Kind       string    `json:"kind"`
+	ItemID     int64     `json:"item_id"`
+	Source     string    `json:"source"`
+	ExternalID string    `json:"external_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type FormDraft struct {
+	UserID    int64     `json:"user_id"`
+	Form      string    `json:"form"`
+	ItemID    int64     `json:"item_id"`
+	Fields    string    `json:"fields"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type Invoice struct {
-	ID             int64       `json:"id"`
-	ProjectID      int64       `json:"project_id"`
-	InvoiceDate    time.Time   `json:"invoice_date"`
-	DatePaid       interface{} `json:"date_paid"`
-	PaymentTerms   string      `json:"payment_terms"`
-	AmountDue      float64     `json:"amount_due"`
-	CreatedAt      time.Time   `json:"created_at"`
-	UpdatedAt      time.Time   `json:"updated_at"`
-	DeletedAt      interface{} `json:"deleted_at"`
-	DisplayDetails bool        `json:"display_details"`
+	ID                     int64           `json:"id"`
+	ProjectID              int64           `json:"project_id"`
+	InvoiceDate            Date            `json:"invoice_date"`
+	DatePaid               NullDate        `json:"date_paid"`
+	PaymentTerms           string          `json:"payment_terms"`
+	AmountDue              float64         `json:"amount_due"`
+	CreatedAt              time.Time       `json:"created_at"`
+	UpdatedAt              time.Time       `json:"updated_at"`
+	DeletedAt              interface{}     `json:"deleted_at"`
+	DisplayDetails         bool            `json:"display_details"`
+	InvoiceNumber          sql.NullString  `json:"invoice_number"`
+	VoidedAt               sql.NullTime    `json:"voided_at"`
+	VoidReason             sql.NullString  `json:"void_reason"`
+	AmountPaid             sql.NullFloat64 `json:"amount_paid"`
+	CreditApplied          float64         `json:"credit_applied"`
+	DueDate                NullDate        `json:"due_date"`
+	CcEmail                sql.NullString  `json:"cc_email"`
+	TimesheetsFrom         NullDate        `json:"timesheets_from"`
+	TimesheetsTo           NullDate        `json:"timesheets_to"`
+	HourlyRate             sql.NullFloat64 `json:"hourly_rate"`
+	DiscountPercent        sql.NullFloat64 `json:"discount_percent"`
+	DiscountReason         sql.NullString  `json:"discount_reason"`
+	AdjustmentAmount       sql.NullFloat64 `json:"adjustment_amount"`
+	AdjustmentReason       sql.NullString  `json:"adjustment_reason"`
+	CurrencyDisplay        sql.NullString  `json:"currency_display"`
+	CurrencyConversionRate sql.NullFloat64 `json:"currency_conversion_rate"`
+	FinancialsCapturedAt   sql.NullTime    `json:"financials_captured_at"`
+	ConversionRateSource   sql.NullString  `json:"conversion_rate_source"`
+	ConvertedAt            sql.NullTime    `json:"converted_at"`
+	PdfSignedAt            sql.NullTime    `json:"pdf_signed_at"`
+	PdfSignedBy            sql.NullString  `json:"pdf_signed_by"`
+	TimesheetsChangedAt    sql.NullTime    `json:"timesheets_changed_at"`
+	DiscountAmount         sql.NullFloat64 `json:"discount_amount"`
+	BudgetCode             sql.NullString  `json:"budget_code"`
+}
+
+type InvoiceDelivery struct {
+	ID            int64          `json:"id"`
+	InvoiceID     int64          `json:"invoice_id"`
+	Recipients    string         `json:"recipients"`
+	Subject       string         `json:"subject"`
+	Status        string         `json:"status"`
+	SmtpResponse  string         `json:"smtp_response"`
+	TrackingToken sql.NullString `json:"tracking_token"`
+	OpenCount     int64          `json:"open_count"`
+	FirstOpenedAt sql.NullTime   `json:"first_opened_at"`
+	LastOpenedAt  sql.NullTime   `json:"last_opened_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+type InvoiceEvent struct {
+	ID        int64     `json:"id"`
+	InvoiceID int64     `json:"invoice_id"`
+	EventType string    `json:"event_type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type InvoiceProject struct {
+	InvoiceID int64   `json:"invoice_id"`
+	ProjectID int64   `json:"project_id"`
+	Hours     float64 `json:"hours"`
+	Amount    float64 `json:"amount"`
+}
+
+type InvoiceSnapshot struct {
+	InvoiceID              int64     `json:"invoice_id"`
+	BillTo                 string    `json:"bill_to"`
+	FreelancerName         string    `json:"freelancer_name"`
+	FreelancerAddress      string    `json:"freelancer_address"`
+	FreelancerCityStateZip string    `json:"freelancer_city_state_zip"`
+	FreelancerPhone        string    `json:"freelancer_phone"`
+	FreelancerEmail        string    `json:"freelancer_email"`
+	LogoDataUrl            string    `json:"logo_data_url"`
+	CreatedAt              time.Time `json:"created_at"`
+}
+
+type Job struct {
+	ID         int64        `json:"id"`
+	Kind       string       `json:"kind"`
+	Payload    string       `json:"payload"`
+	Status     string       `json:"status"`
+	Result     []byte       `json:"result"`
+	ResultType string       `json:"result_type"`
+	ResultName string       `json:"result_name"`
+	Error      string       `json:"error"`
+	StartedAt  sql.NullTime `json:"started_at"`
+	FinishedAt sql.NullTime `json:"finished_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+type Milestone struct {
+	ID        int64         `json:"id"`
+	ProjectID int64         `json:"project_id"`
+	Name      string        `json:"name"`
+	DueDate   sql.NullTime  `json:"due_date"`
+	Fee       float64       `json:"fee"`
+	Status    string        `json:"status"`
+	InvoiceID sql.NullInt64 `json:"invoice_id"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	DeletedAt interface{}   `json:"deleted_at"`
+}
+
+type NotificationSent struct {
+	Kind      string    `json:"kind"`
+	SubjectID int64     `json:"subject_id"`
+	DueOn     string    `json:"due_on"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+type PaymentTermsPreset struct {
+	ID        int64     `json:"id"`
+	Terms     string    `json:"terms"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PendingTimesheet struct {
+	ID          int64     `json:"id"`
+	ProjectID   int64     `json:"project_id"`
+	WorkDate    time.Time `json:"work_date"`
+	HoursWorked float64   `json:"hours_worked"`
+	Description string    `json:"description"`
+	Source      string    `json:"source"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type Project struct {
@@ -71,6 +293,73 @@ type Project struct {
 	CurrencyConversionRate float64         `json:"currency_conversion_rate"`
 	FlatFeeInvoice         int64           `json:"flat_fee_invoice"`
 	Notes                  sql.NullString  `json:"notes"`
+	BusinessProfileID      sql.NullInt64   `json:"business_profile_id"`
+	EstimatedHours         sql.NullFloat64 `json:"estimated_hours"`
+	EstimatedAmount        sql.NullFloat64 `json:"estimated_amount"`
+	BudgetCode             sql.NullString  `json:"budget_code"`
+}
+
+type ProjectAttachment struct {
+	ID          int64     `json:"id"`
+	ProjectID   int64     `json:"project_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Path        string    `json:"path"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type ProjectChecklist struct {
+	ProjectID       int64     `json:"project_id"`
+	ChecklistItemID int64     `json:"checklist_item_id"`
+	CheckedAt       time.Time `json:"checked_at"`
+}
+
+type ProjectShare struct {
+	ProjectID int64     `json:"project_id"`
+	UserID    int64     `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ProjectTag struct {
+	ProjectID int64 `json:"project_id"`
+	TagID     int64 `json:"tag_id"`
+}
+
+type RecentVisit struct {
+	UserID        int64     `json:"user_id"`
+	Kind          string    `json:"kind"`
+	ItemID        int64     `json:"item_id"`
+	VisitCount    int64     `json:"visit_count"`
+	LastVisitedAt time.Time `json:"last_visited_at"`
+}
+
+type SavedView struct {
+	ID        int64         `json:"id"`
+	UserID    sql.NullInt64 `json:"user_id"`
+	List      string        `json:"list"`
+	Name      string        `json:"name"`
+	Filter    string        `json:"filter"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+type ScheduledTask struct {
+	Name      string        `json:"name"`
+	LastRunAt sql.NullTime  `json:"last_run_at"`
+	LastJobID sql.NullInt64 `json:"last_job_id"`
+	LastError string        `json:"last_error"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+type Service struct {
+	ID          int64       `json:"id"`
+	Name        string      `json:"name"`
+	DefaultRate float64     `json:"default_rate"`
+	Unit        string      `json:"unit"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	DeletedAt   interface{} `json:"deleted_at"`
 }
 
 type Session struct {
@@ -88,14 +377,61 @@ type Setting struct {
 	UpdatedAt   sql.NullTime   `json:"updated_at"`
 }
 
+type Tag struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type Timesheet struct {
-	ID          int64          `json:"id"`
-	ProjectID   int64          `json:"project_id"`
-	WorkDate    time.Time      `json:"work_date"`
-	HoursWorked float64        `json:"hours_worked"`
+	ID              int64           `json:"id"`
+	ProjectID       int64           `json:"project_id"`
+	WorkDate        time.Time       `json:"work_date"`
+	HoursWorked     float64         `json:"hours_worked"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	DeletedAt       interface{}     `json:"deleted_at"`
+	Description     sql.NullString  `json:"description"`
+	HourlyRate      float64         `json:"hourly_rate"`
+	UserID          sql.NullInt64   `json:"user_id"`
+	CostRate        sql.NullFloat64 `json:"cost_rate"`
+	ServiceID       sql.NullInt64   `json:"service_id"`
+	Unit            string          `json:"unit"`
+	PendingApproval bool            `json:"pending_approval"`
+	WorkType        string          `json:"work_type"`
+}
+
+type User struct {
+	ID             int64       `json:"id"`
+	Name           string      `json:"name"`
+	Email          string      `json:"email"`
+	HashedPassword string      `json:"hashed_password"`
+	Role           string      `json:"role"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+	DeletedAt      interface{} `json:"deleted_at"`
+}
+
+type UserPreference struct {
+	UserID      int64          `json:"user_id"`
+	PageSize    sql.NullInt64  `json:"page_size"`
+	LandingPage sql.NullString `json:"landing_page"`
+	DateFormat  sql.NullString `json:"date_format"`
+	Theme       sql.NullString `json:"theme"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   interface{}    `json:"deleted_at"`
-	Description sql.NullString `json:"description"`
-	HourlyRate  float64        `json:"hourly_rate"`
+}
+
+type UserSession struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	Token      string    `json:"token"`
+	RememberMe bool      `json:"remember_me"`
+	UserAgent  string    `json:"user_agent"`
+	IpAddress  string    `json:"ip_address"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
 }
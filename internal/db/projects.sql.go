@@ -8,9 +8,21 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 )
 
+const deleteClientProjects = `-- name: DeleteClientProjects :exec
+UPDATE project 
+SET deleted_at = (SELECT c.deleted_at FROM client c WHERE c.id = project.client_id) 
+WHERE client_id = ? AND deleted_at IS NULL
+`
+
+func (q *Queries) DeleteClientProjects(ctx context.Context, clientID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteClientProjects, clientID)
+	return err
+}
+
 const deleteProject = `-- name: DeleteProject :exec
 UPDATE project 
 SET deleted_at = CURRENT_TIMESTAMP 
@@ -116,7 +128,8 @@ SELECT id, name, client_id, status, hourly_rate, deadline, scheduled_start,
        invoice_cc_email, invoice_cc_description, schedule_comments,
        additional_info, additional_info2, discount_percent, discount_reason,
        adjustment_amount, adjustment_reason, currency_display, 
-       currency_conversion_rate, flat_fee_invoice, notes,
+       currency_conversion_rate, flat_fee_invoice, notes, business_profile_id,
+       estimated_hours, estimated_amount, budget_code,
        updated_at, created_at, deleted_at 
 FROM project 
 WHERE id = ? AND deleted_at IS NULL
@@ -143,6 +156,10 @@ type GetProjectRow struct {
 	CurrencyConversionRate float64         `json:"currency_conversion_rate"`
 	FlatFeeInvoice         int64           `json:"flat_fee_invoice"`
 	Notes                  sql.NullString  `json:"notes"`
+	BusinessProfileID      sql.NullInt64   `json:"business_profile_id"`
+	EstimatedHours         sql.NullFloat64 `json:"estimated_hours"`
+	EstimatedAmount        sql.NullFloat64 `json:"estimated_amount"`
+	BudgetCode             sql.NullString  `json:"budget_code"`
 	UpdatedAt              time.Time       `json:"updated_at"`
 	CreatedAt              time.Time       `json:"created_at"`
 	DeletedAt              interface{}     `json:"deleted_at"`
@@ -172,6 +189,10 @@ func (q *Queries) GetProject(ctx context.Context, id int64) (GetProjectRow, erro
 		&i.CurrencyConversionRate,
 		&i.FlatFeeInvoice,
 		&i.Notes,
+		&i.BusinessProfileID,
+		&i.EstimatedHours,
+		&i.EstimatedAmount,
+		&i.BudgetCode,
 		&i.UpdatedAt,
 		&i.CreatedAt,
 		&i.DeletedAt,
@@ -184,7 +205,8 @@ SELECT id, name, client_id, status, hourly_rate, deadline, scheduled_start,
        invoice_cc_email, invoice_cc_description, schedule_comments,
        additional_info, additional_info2, discount_percent, discount_reason,
        adjustment_amount, adjustment_reason, currency_display, 
-       currency_conversion_rate, flat_fee_invoice, notes,
+       currency_conversion_rate, flat_fee_invoice, notes, business_profile_id,
+       estimated_hours, estimated_amount, budget_code,
        updated_at, created_at, deleted_at 
 FROM project 
 WHERE client_id = ? AND deleted_at IS NULL
@@ -212,6 +234,10 @@ type GetProjectsByClientRow struct {
 	CurrencyConversionRate float64         `json:"currency_conversion_rate"`
 	FlatFeeInvoice         int64           `json:"flat_fee_invoice"`
 	Notes                  sql.NullString  `json:"notes"`
+	BusinessProfileID      sql.NullInt64   `json:"business_profile_id"`
+	EstimatedHours         sql.NullFloat64 `json:"estimated_hours"`
+	EstimatedAmount        sql.NullFloat64 `json:"estimated_amount"`
+	BudgetCode             sql.NullString  `json:"budget_code"`
 	UpdatedAt              time.Time       `json:"updated_at"`
 	CreatedAt              time.Time       `json:"created_at"`
 	DeletedAt              interface{}     `json:"deleted_at"`
@@ -247,6 +273,10 @@ func (q *Queries) GetProjectsByClient(ctx context.Context, clientID int64) ([]Ge
 			&i.CurrencyConversionRate,
 			&i.FlatFeeInvoice,
 			&i.Notes,
+			&i.BusinessProfileID,
+			&i.EstimatedHours,
+			&i.EstimatedAmount,
+			&i.BudgetCode,
 			&i.UpdatedAt,
 			&i.CreatedAt,
 			&i.DeletedAt,
@@ -264,6 +294,136 @@ func (q *Queries) GetProjectsByClient(ctx context.Context, clientID int64) ([]Ge
 	return items, nil
 }
 
+const getProjectsByTagCount = `-- name: GetProjectsByTagCount :one
+SELECT COUNT(*) 
+FROM project p
+JOIN client c ON p.client_id = c.id
+WHERE p.deleted_at IS NULL AND c.deleted_at IS NULL
+  AND p.id IN (SELECT project_id FROM project_tag WHERE tag_id = ?)
+`
+
+func (q *Queries) GetProjectsByTagCount(ctx context.Context, tagID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getProjectsByTagCount, tagID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getProjectsByTagWithClientPagination = `-- name: GetProjectsByTagWithClientPagination :many
+SELECT p.id, p.name, p.client_id, p.status, p.hourly_rate, p.deadline, p.scheduled_start,
+       p.invoice_cc_email, p.invoice_cc_description, p.schedule_comments,
+       p.additional_info, p.additional_info2, p.discount_percent, p.discount_reason,
+       p.adjustment_amount, p.adjustment_reason, p.currency_display, 
+       p.currency_conversion_rate, p.flat_fee_invoice, p.notes,
+       p.estimated_hours, p.estimated_amount,
+       CAST(COALESCE(ts.hours, 0) AS REAL) AS actual_hours,
+       CAST(COALESCE(ts.amount, 0) AS REAL) AS actual_amount,
+       p.updated_at, p.created_at, p.deleted_at,
+       c.name as client_name
+FROM project p
+JOIN client c ON p.client_id = c.id
+LEFT JOIN (
+    SELECT project_id, SUM(CASE WHEN unit = 'hour' THEN hours_worked ELSE 0 END) AS hours,
+        SUM(hours_worked * hourly_rate) AS amount
+    FROM timesheet
+    WHERE deleted_at IS NULL
+    GROUP BY project_id
+) ts ON ts.project_id = p.id
+WHERE p.deleted_at IS NULL AND c.deleted_at IS NULL
+  AND p.id IN (SELECT project_id FROM project_tag WHERE tag_id = ?)
+ORDER BY p.updated_at DESC
+LIMIT ? OFFSET ?
+`
+
+type GetProjectsByTagWithClientPaginationParams struct {
+	TagID  int64 `json:"tag_id"`
+	Limit  int64 `json:"limit"`
+	Offset int64 `json:"offset"`
+}
+
+type GetProjectsByTagWithClientPaginationRow struct {
+	ID                     int64           `json:"id"`
+	Name                   string          `json:"name"`
+	ClientID               int64           `json:"client_id"`
+	Status                 string          `json:"status"`
+	HourlyRate             float64         `json:"hourly_rate"`
+	Deadline               sql.NullString  `json:"deadline"`
+	ScheduledStart         sql.NullString  `json:"scheduled_start"`
+	InvoiceCcEmail         sql.NullString  `json:"invoice_cc_email"`
+	InvoiceCcDescription   sql.NullString  `json:"invoice_cc_description"`
+	ScheduleComments       sql.NullString  `json:"schedule_comments"`
+	AdditionalInfo         sql.NullString  `json:"additional_info"`
+	AdditionalInfo2        sql.NullString  `json:"additional_info2"`
+	DiscountPercent        sql.NullFloat64 `json:"discount_percent"`
+	DiscountReason         sql.NullString  `json:"discount_reason"`
+	AdjustmentAmount       sql.NullFloat64 `json:"adjustment_amount"`
+	AdjustmentReason       sql.NullString  `json:"adjustment_reason"`
+	CurrencyDisplay        string          `json:"currency_display"`
+	CurrencyConversionRate float64         `json:"currency_conversion_rate"`
+	FlatFeeInvoice         int64           `json:"flat_fee_invoice"`
+	Notes                  sql.NullString  `json:"notes"`
+	EstimatedHours         sql.NullFloat64 `json:"estimated_hours"`
+	EstimatedAmount        sql.NullFloat64 `json:"estimated_amount"`
+	ActualHours            float64         `json:"actual_hours"`
+	ActualAmount           float64         `json:"actual_amount"`
+	UpdatedAt              time.Time       `json:"updated_at"`
+	CreatedAt              time.Time       `json:"created_at"`
+	DeletedAt              interface{}     `json:"deleted_at"`
+	ClientName             string          `json:"client_name"`
+}
+
+func (q *Queries) GetProjectsByTagWithClientPagination(ctx context.Context, arg GetProjectsByTagWithClientPaginationParams) ([]GetProjectsByTagWithClientPaginationRow, error) {
+	rows, err := q.db.QueryContext(ctx, getProjectsByTagWithClientPagination, arg.TagID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetProjectsByTagWithClientPaginationRow{}
+	for rows.Next() {
+		var i GetProjectsByTagWithClientPaginationRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.ClientID,
+			&i.Status,
+			&i.HourlyRate,
+			&i.Deadline,
+			&i.ScheduledStart,
+			&i.InvoiceCcEmail,
+			&i.InvoiceCcDescription,
+			&i.ScheduleComments,
+			&i.AdditionalInfo,
+			&i.AdditionalInfo2,
+			&i.DiscountPercent,
+			&i.DiscountReason,
+			&i.AdjustmentAmount,
+			&i.AdjustmentReason,
+			&i.CurrencyDisplay,
+			&i.CurrencyConversionRate,
+			&i.FlatFeeInvoice,
+			&i.Notes,
+			&i.EstimatedHours,
+			&i.EstimatedAmount,
+			&i.ActualHours,
+			&i.ActualAmount,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.ClientName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getProjectsCount = `-- name: GetProjectsCount :one
 SELECT COUNT(*) 
 FROM project p
@@ -278,16 +438,144 @@ func (q *Queries) GetProjectsCount(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const getProjectsWithClientByIDs = `-- name: GetProjectsWithClientByIDs :many
+SELECT p.id, p.name, p.client_id, p.status, p.hourly_rate, p.deadline, p.scheduled_start,
+       p.invoice_cc_email, p.invoice_cc_description, p.schedule_comments,
+       p.additional_info, p.additional_info2, p.discount_percent, p.discount_reason,
+       p.adjustment_amount, p.adjustment_reason, p.currency_display, 
+       p.currency_conversion_rate, p.flat_fee_invoice, p.notes,
+       p.estimated_hours, p.estimated_amount,
+       CAST(COALESCE(ts.hours, 0) AS REAL) AS actual_hours,
+       CAST(COALESCE(ts.amount, 0) AS REAL) AS actual_amount,
+       p.updated_at, p.created_at, p.deleted_at,
+       c.name as client_name
+FROM project p
+JOIN client c ON p.client_id = c.id
+LEFT JOIN (
+    SELECT project_id, SUM(CASE WHEN unit = 'hour' THEN hours_worked ELSE 0 END) AS hours,
+        SUM(hours_worked * hourly_rate) AS amount
+    FROM timesheet
+    WHERE deleted_at IS NULL
+    GROUP BY project_id
+) ts ON ts.project_id = p.id
+WHERE p.deleted_at IS NULL AND c.deleted_at IS NULL
+  AND p.id IN (/*SLICE:ids*/?)
+ORDER BY p.updated_at DESC
+`
+
+type GetProjectsWithClientByIDsRow struct {
+	ID                     int64           `json:"id"`
+	Name                   string          `json:"name"`
+	ClientID               int64           `json:"client_id"`
+	Status                 string          `json:"status"`
+	HourlyRate             float64         `json:"hourly_rate"`
+	Deadline               sql.NullString  `json:"deadline"`
+	ScheduledStart         sql.NullString  `json:"scheduled_start"`
+	InvoiceCcEmail         sql.NullString  `json:"invoice_cc_email"`
+	InvoiceCcDescription   sql.NullString  `json:"invoice_cc_description"`
+	ScheduleComments       sql.NullString  `json:"schedule_comments"`
+	AdditionalInfo         sql.NullString  `json:"additional_info"`
+	AdditionalInfo2        sql.NullString  `json:"additional_info2"`
+	DiscountPercent        sql.NullFloat64 `json:"discount_percent"`
+	DiscountReason         sql.NullString  `json:"discount_reason"`
+	AdjustmentAmount       sql.NullFloat64 `json:"adjustment_amount"`
+	AdjustmentReason       sql.NullString  `json:"adjustment_reason"`
+	CurrencyDisplay        string          `json:"currency_display"`
+	CurrencyConversionRate float64         `json:"currency_conversion_rate"`
+	FlatFeeInvoice         int64           `json:"flat_fee_invoice"`
+	Notes                  sql.NullString  `json:"notes"`
+	EstimatedHours         sql.NullFloat64 `json:"estimated_hours"`
+	EstimatedAmount        sql.NullFloat64 `json:"estimated_amount"`
+	ActualHours            float64         `json:"actual_hours"`
+	ActualAmount           float64         `json:"actual_amount"`
+	UpdatedAt              time.Time       `json:"updated_at"`
+	CreatedAt              time.Time       `json:"created_at"`
+	DeletedAt              interface{}     `json:"deleted_at"`
+	ClientName             string          `json:"client_name"`
+}
+
+func (q *Queries) GetProjectsWithClientByIDs(ctx context.Context, ids []int64) ([]GetProjectsWithClientByIDsRow, error) {
+	query := getProjectsWithClientByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetProjectsWithClientByIDsRow{}
+	for rows.Next() {
+		var i GetProjectsWithClientByIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.ClientID,
+			&i.Status,
+			&i.HourlyRate,
+			&i.Deadline,
+			&i.ScheduledStart,
+			&i.InvoiceCcEmail,
+			&i.InvoiceCcDescription,
+			&i.ScheduleComments,
+			&i.AdditionalInfo,
+			&i.AdditionalInfo2,
+			&i.DiscountPercent,
+			&i.DiscountReason,
+			&i.AdjustmentAmount,
+			&i.AdjustmentReason,
+			&i.CurrencyDisplay,
+			&i.CurrencyConversionRate,
+			&i.FlatFeeInvoice,
+			&i.Notes,
+			&i.EstimatedHours,
+			&i.EstimatedAmount,
+			&i.ActualHours,
+			&i.ActualAmount,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.ClientName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getProjectsWithClientPagination = `-- name: GetProjectsWithClientPagination :many
 SELECT p.id, p.name, p.client_id, p.status, p.hourly_rate, p.deadline, p.scheduled_start,
        p.invoice_cc_email, p.invoice_cc_description, p.schedule_comments,
        p.additional_info, p.additional_info2, p.discount_percent, p.discount_reason,
        p.adjustment_amount, p.adjustment_reason, p.currency_display, 
        p.currency_conversion_rate, p.flat_fee_invoice, p.notes,
+       p.estimated_hours, p.estimated_amount,
+       CAST(COALESCE(ts.hours, 0) AS REAL) AS actual_hours,
+       CAST(COALESCE(ts.amount, 0) AS REAL) AS actual_amount,
        p.updated_at, p.created_at, p.deleted_at,
        c.name as client_name
 FROM project p
 JOIN client c ON p.client_id = c.id
+LEFT JOIN (
+    SELECT project_id, SUM(CASE WHEN unit = 'hour' THEN hours_worked ELSE 0 END) AS hours,
+        SUM(hours_worked * hourly_rate) AS amount
+    FROM timesheet
+    WHERE deleted_at IS NULL
+    GROUP BY project_id
+) ts ON ts.project_id = p.id
 WHERE p.deleted_at IS NULL AND c.deleted_at IS NULL
 ORDER BY p.updated_at DESC
 LIMIT ? OFFSET ?
@@ -319,6 +607,10 @@ type GetProjectsWithClientPaginationRow struct {
 	CurrencyConversionRate float64         `json:"currency_conversion_rate"`
 	FlatFeeInvoice         int64           `json:"flat_fee_invoice"`
 	Notes                  sql.NullString  `json:"notes"`
+	EstimatedHours         sql.NullFloat64 `json:"estimated_hours"`
+	EstimatedAmount        sql.NullFloat64 `json:"estimated_amount"`
+	ActualHours            float64         `json:"actual_hours"`
+	ActualAmount           float64         `json:"actual_amount"`
 	UpdatedAt              time.Time       `json:"updated_at"`
 	CreatedAt              time.Time       `json:"created_at"`
 	DeletedAt              interface{}     `json:"deleted_at"`
@@ -355,6 +647,10 @@ func (q *Queries) GetProjectsWithClientPagination(ctx context.Context, arg GetPr
 			&i.CurrencyConversionRate,
 			&i.FlatFeeInvoice,
 			&i.Notes,
+			&i.EstimatedHours,
+			&i.EstimatedAmount,
+			&i.ActualHours,
+			&i.ActualAmount,
 			&i.UpdatedAt,
 			&i.CreatedAt,
 			&i.DeletedAt,
@@ -379,9 +675,10 @@ INSERT INTO project (
     invoice_cc_email, invoice_cc_description, schedule_comments,
     additional_info, additional_info2, discount_percent, discount_reason,
     adjustment_amount, adjustment_reason, currency_display, 
-    currency_conversion_rate, flat_fee_invoice, notes
+    currency_conversion_rate, flat_fee_invoice, notes, business_profile_id,
+    estimated_hours, estimated_amount, budget_code
 ) 
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type InsertProjectParams struct {
@@ -404,6 +701,10 @@ type InsertProjectParams struct {
 	CurrencyConversionRate float64         `json:"currency_conversion_rate"`
 	FlatFeeInvoice         int64           `json:"flat_fee_invoice"`
 	Notes                  sql.NullString  `json:"notes"`
+	BusinessProfileID      sql.NullInt64   `json:"business_profile_id"`
+	EstimatedHours         sql.NullFloat64 `json:"estimated_hours"`
+	EstimatedAmount        sql.NullFloat64 `json:"estimated_amount"`
+	BudgetCode             sql.NullString  `json:"budget_code"`
 }
 
 func (q *Queries) InsertProject(ctx context.Context, arg InsertProjectParams) (int64, error) {
@@ -427,6 +728,10 @@ func (q *Queries) InsertProject(ctx context.Context, arg InsertProjectParams) (i
 		arg.CurrencyConversionRate,
 		arg.FlatFeeInvoice,
 		arg.Notes,
+		arg.BusinessProfileID,
+		arg.EstimatedHours,
+		arg.EstimatedAmount,
+		arg.BudgetCode,
 	)
 	if err != nil {
 		return 0, err
@@ -434,13 +739,108 @@ func (q *Queries) InsertProject(ctx context.Context, arg InsertProjectParams) (i
 	return result.LastInsertId()
 }
 
+const restoreClientProjects = `-- name: RestoreClientProjects :exec
+UPDATE project 
+SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP 
+WHERE client_id = ? AND deleted_at = (SELECT c.deleted_at FROM client c WHERE c.id = project.client_id)
+`
+
+func (q *Queries) RestoreClientProjects(ctx context.Context, clientID int64) error {
+	_, err := q.db.ExecContext(ctx, restoreClientProjects, clientID)
+	return err
+}
+
+const restoreProject = `-- name: RestoreProject :exec
+UPDATE project 
+SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreProject(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, restoreProject, id)
+	return err
+}
+
+const searchProjectsWithClient = `-- name: SearchProjectsWithClient :many
+SELECT p.id, p.name, p.client_id, p.status, c.name as client_name
+FROM project p
+JOIN client c ON p.client_id = c.id
+WHERE p.deleted_at IS NULL AND c.deleted_at IS NULL AND p.status != 'Archived'
+  AND (p.name || ' ' || c.name) LIKE ? ESCAPE '\'
+ORDER BY MAX(
+    p.updated_at,
+    COALESCE((SELECT MAX(t.updated_at) FROM timesheet t WHERE t.project_id = p.id AND t.deleted_at IS NULL), p.updated_at),
+    COALESCE((SELECT MAX(i.updated_at) FROM invoice i WHERE i.project_id = p.id AND i.deleted_at IS NULL), p.updated_at)
+) DESC, p.name
+LIMIT ?
+`
+
+type SearchProjectsWithClientParams struct {
+	Pattern interface{} `json:"pattern"`
+	Limit   int64       `json:"limit"`
+}
+
+type SearchProjectsWithClientRow struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	ClientID   int64  `json:"client_id"`
+	Status     string `json:"status"`
+	ClientName string `json:"client_name"`
+}
+
+func (q *Queries) SearchProjectsWithClient(ctx context.Context, arg SearchProjectsWithClientParams) ([]SearchProjectsWithClientRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchProjectsWithClient, arg.Pattern, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchProjectsWithClientRow{}
+	for rows.Next() {
+		var i SearchProjectsWithClientRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.ClientID,
+			&i.Status,
+			&i.ClientName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setProjectStatus = `-- name: SetProjectStatus :exec
+UPDATE project 
+SET status = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL
+`
+
+type SetProjectStatusParams struct {
+	Status string `json:"status"`
+	ID     int64  `json:"id"`
+}
+
+func (q *Queries) SetProjectStatus(ctx context.Context, arg SetProjectStatusParams) error {
+	_, err := q.db.ExecContext(ctx, setProjectStatus, arg.Status, arg.ID)
+	return err
+}
+
 const updateProject = `-- name: UpdateProject :exec
 UPDATE project 
 SET name = ?, status = ?, hourly_rate = ?, deadline = ?, scheduled_start = ?,
     invoice_cc_email = ?, invoice_cc_description = ?, schedule_comments = ?,
     additional_info = ?, additional_info2 = ?, discount_percent = ?, discount_reason = ?,
     adjustment_amount = ?, adjustment_reason = ?, currency_display = ?, 
-    currency_conversion_rate = ?, flat_fee_invoice = ?, notes = ?,
+    currency_conversion_rate = ?, flat_fee_invoice = ?, notes = ?, business_profile_id = ?,
+    estimated_hours = ?, estimated_amount = ?, budget_code = ?,
     updated_at = CURRENT_TIMESTAMP 
 WHERE id = ? AND deleted_at IS NULL
 `
@@ -464,6 +864,10 @@ type UpdateProjectParams struct {
 	CurrencyConversionRate float64         `json:"currency_conversion_rate"`
 	FlatFeeInvoice         int64           `json:"flat_fee_invoice"`
 	Notes                  sql.NullString  `json:"notes"`
+	BusinessProfileID      sql.NullInt64   `json:"business_profile_id"`
+	EstimatedHours         sql.NullFloat64 `json:"estimated_hours"`
+	EstimatedAmount        sql.NullFloat64 `json:"estimated_amount"`
+	BudgetCode             sql.NullString  `json:"budget_code"`
 	ID                     int64           `json:"id"`
 }
 
@@ -487,6 +891,10 @@ func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) er
 		arg.CurrencyConversionRate,
 		arg.FlatFeeInvoice,
 		arg.Notes,
+		arg.BusinessProfileID,
+		arg.EstimatedHours,
+		arg.EstimatedAmount,
+		arg.BudgetCode,
 		arg.ID,
 	)
 	return err
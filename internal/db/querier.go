@@ -6,38 +6,244 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 type Querier interface {
+	ApproveClientApproval(ctx context.Context, arg ApproveClientApprovalParams) (int64, error)
+	CheckProjectChecklistItem(ctx context.Context, arg CheckProjectChecklistItemParams) error
+	ClaimBusinessProfileInvoiceNumber(ctx context.Context, id int64) (ClaimBusinessProfileInvoiceNumberRow, error)
+	ClaimClientInvoiceNumber(ctx context.Context, id int64) (ClaimClientInvoiceNumberRow, error)
+	ClaimJob(ctx context.Context, id int64) (int64, error)
+	CompleteJob(ctx context.Context, arg CompleteJobParams) error
+	CountInvoicesWithNumber(ctx context.Context, invoiceNumber sql.NullString) (int64, error)
+	CountSimilarInvoices(ctx context.Context, arg CountSimilarInvoicesParams) (int64, error)
+	DeleteBusinessProfile(ctx context.Context, id int64) error
+	DeleteChecklistItem(ctx context.Context, id int64) (int64, error)
+	DeleteChecklistItemsByTag(ctx context.Context, tagID sql.NullInt64) error
 	DeleteClient(ctx context.Context, id int64) error
+	DeleteClientApproval(ctx context.Context, id int64) error
+	DeleteClientInvoices(ctx context.Context, clientID int64) error
+	DeleteClientMilestones(ctx context.Context, clientID int64) error
+	DeleteClientNote(ctx context.Context, id int64) error
+	DeleteClientProjectDefaults(ctx context.Context, clientID int64) error
+	DeleteClientProjects(ctx context.Context, clientID int64) error
+	DeleteClientTags(ctx context.Context, clientID int64) error
+	DeleteClientTagsByTag(ctx context.Context, tagID int64) error
+	DeleteClientTimesheets(ctx context.Context, clientID int64) error
+	DeleteExpiredUserSessions(ctx context.Context, expiresAt time.Time) error
+	DeleteExternalID(ctx context.Context, arg DeleteExternalIDParams) (int64, error)
+	DeleteFinishedJobsBefore(ctx context.Context, finishedAt sql.NullTime) (int64, error)
+	DeleteFormDraft(ctx context.Context, arg DeleteFormDraftParams) error
 	DeleteInvoice(ctx context.Context, id int64) error
+	DeleteInvoiceAppliedCredit(ctx context.Context, invoiceID sql.NullInt64) error
+	DeleteInvoiceOverpaymentCredit(ctx context.Context, invoiceID sql.NullInt64) error
+	DeleteMilestone(ctx context.Context, id int64) error
+	DeleteNotificationSent(ctx context.Context, arg DeleteNotificationSentParams) error
+	DeleteOtherUserSessions(ctx context.Context, arg DeleteOtherUserSessionsParams) (int64, error)
+	DeletePaymentTermsPreset(ctx context.Context, id int64) (int64, error)
+	DeletePendingTimesheet(ctx context.Context, id int64) error
 	DeleteProject(ctx context.Context, id int64) error
+	DeleteProjectAttachment(ctx context.Context, id int64) error
+	DeleteProjectChecksByItem(ctx context.Context, checklistItemID int64) error
+	DeleteProjectChecksByTag(ctx context.Context, tagID sql.NullInt64) error
+	DeleteProjectInvoices(ctx context.Context, projectID int64) error
+	DeleteProjectMilestones(ctx context.Context, projectID int64) error
+	DeleteProjectTags(ctx context.Context, projectID int64) error
+	DeleteProjectTagsByTag(ctx context.Context, tagID int64) error
+	DeleteProjectTimesheets(ctx context.Context, projectID int64) error
+	DeleteSavedView(ctx context.Context, id int64) error
+	DeleteService(ctx context.Context, id int64) error
+	DeleteTag(ctx context.Context, id int64) error
 	DeleteTimesheet(ctx context.Context, id int64) error
+	DeleteUser(ctx context.Context, id int64) error
+	DeleteUserSession(ctx context.Context, id int64) error
+	FailJob(ctx context.Context, arg FailJobParams) error
+	FindExternalID(ctx context.Context, arg FindExternalIDParams) (int64, error)
+	FlagInvoiceTimesheetsChanged(ctx context.Context, id int64) error
+	GetAllBusinessProfiles(ctx context.Context) ([]GetAllBusinessProfilesRow, error)
+	GetAllClientTags(ctx context.Context) ([]GetAllClientTagsRow, error)
 	GetAllClients(ctx context.Context) ([]GetAllClientsRow, error)
+	GetAllPendingTimesheets(ctx context.Context) ([]GetAllPendingTimesheetsRow, error)
+	GetAllProjectTags(ctx context.Context) ([]GetAllProjectTagsRow, error)
 	GetAllProjectsWithClient(ctx context.Context) ([]GetAllProjectsWithClientRow, error)
+	GetAllServices(ctx context.Context) ([]GetAllServicesRow, error)
 	GetAllSettings(ctx context.Context) ([]Setting, error)
+	GetAllTags(ctx context.Context) ([]GetAllTagsRow, error)
+	GetAllUsers(ctx context.Context) ([]GetAllUsersRow, error)
+	GetBusinessProfile(ctx context.Context, id int64) (GetBusinessProfileRow, error)
+	GetChecklistItems(ctx context.Context) ([]GetChecklistItemsRow, error)
 	GetClient(ctx context.Context, id int64) (GetClientRow, error)
+	GetClientApproval(ctx context.Context, id int64) (ClientApproval, error)
+	GetClientApprovalByToken(ctx context.Context, token string) (ClientApproval, error)
+	GetClientApprovalsByProject(ctx context.Context, projectID int64) ([]ClientApproval, error)
+	GetClientCreditBalance(ctx context.Context, clientID int64) (float64, error)
+	GetClientCreditsByClient(ctx context.Context, clientID int64) ([]ClientCredit, error)
+	GetClientHoursBetween(ctx context.Context, arg GetClientHoursBetweenParams) (float64, error)
+	GetClientLifetimeValue(ctx context.Context, id int64) (GetClientLifetimeValueRow, error)
+	GetClientLifetimeValues(ctx context.Context) ([]GetClientLifetimeValuesRow, error)
+	GetClientNote(ctx context.Context, id int64) (GetClientNoteRow, error)
+	GetClientNotesByClient(ctx context.Context, clientID int64) ([]GetClientNotesByClientRow, error)
+	GetClientPaidInvoices(ctx context.Context, clientID int64) ([]GetClientPaidInvoicesRow, error)
+	GetClientProjectDefaults(ctx context.Context, clientID int64) (ClientProjectDefault, error)
+	GetClientsByIDs(ctx context.Context, ids []int64) ([]GetClientsByIDsRow, error)
+	GetClientsByTagCount(ctx context.Context, tagID int64) (int64, error)
+	GetClientsByTagWithPagination(ctx context.Context, arg GetClientsByTagWithPaginationParams) ([]GetClientsByTagWithPaginationRow, error)
 	GetClientsCount(ctx context.Context) (int64, error)
 	GetClientsWithPagination(ctx context.Context, arg GetClientsWithPaginationParams) ([]GetClientsWithPaginationRow, error)
+	GetConsolidatedInvoicingClientIDs(ctx context.Context) ([]int64, error)
+	GetDailyInvoiceTotals(ctx context.Context, since interface{}) ([]GetDailyInvoiceTotalsRow, error)
+	GetDailyPaymentTotals(ctx context.Context, since interface{}) ([]GetDailyPaymentTotalsRow, error)
+	GetDailyTimesheetTotals(ctx context.Context, since interface{}) ([]GetDailyTimesheetTotalsRow, error)
+	GetExchangeRate(ctx context.Context, arg GetExchangeRateParams) (ExchangeRate, error)
+	GetExternalIDs(ctx context.Context, arg GetExternalIDsParams) ([]ExternalID, error)
+	GetFormDraft(ctx context.Context, arg GetFormDraftParams) (FormDraft, error)
 	GetInvoice(ctx context.Context, id int64) (GetInvoiceRow, error)
+	GetInvoiceComprehensiveForPDF(ctx context.Context, id int64) (GetInvoiceComprehensiveForPDFRow, error)
+	GetInvoiceDeliveries(ctx context.Context, invoiceID int64) ([]InvoiceDelivery, error)
+	GetInvoiceEventsAfter(ctx context.Context, arg GetInvoiceEventsAfterParams) ([]GetInvoiceEventsAfterRow, error)
 	GetInvoiceForPDF(ctx context.Context, id int64) (GetInvoiceForPDFRow, error)
+	GetInvoiceOverpaymentCredit(ctx context.Context, invoiceID sql.NullInt64) ([]GetInvoiceOverpaymentCreditRow, error)
+	GetInvoiceProjects(ctx context.Context, invoiceID int64) ([]GetInvoiceProjectsRow, error)
+	GetInvoiceSnapshot(ctx context.Context, invoiceID int64) (InvoiceSnapshot, error)
+	GetInvoiceWithClient(ctx context.Context, id int64) (GetInvoiceWithClientRow, error)
 	GetInvoicesByProject(ctx context.Context, projectID int64) ([]GetInvoicesByProjectRow, error)
+	GetInvoicesByProjectCount(ctx context.Context, projectID int64) (int64, error)
+	GetInvoicesByProjectWithPagination(ctx context.Context, arg GetInvoicesByProjectWithPaginationParams) ([]GetInvoicesByProjectWithPaginationRow, error)
+	GetInvoicesWithClientAfter(ctx context.Context, arg GetInvoicesWithClientAfterParams) ([]GetInvoicesWithClientAfterRow, error)
+	GetJob(ctx context.Context, id int64) (Job, error)
+	GetLockedTimesheetIDs(ctx context.Context, projectID int64) ([]int64, error)
+	GetMilestone(ctx context.Context, id int64) (GetMilestoneRow, error)
+	GetMilestonesByProject(ctx context.Context, projectID int64) ([]GetMilestonesByProjectRow, error)
+	GetNextQueuedJobID(ctx context.Context) (int64, error)
+	GetOpenInvoicesWithClient(ctx context.Context) ([]GetOpenInvoicesWithClientRow, error)
+	GetPaidInvoiceHistory(ctx context.Context) ([]GetPaidInvoiceHistoryRow, error)
+	GetPaymentTermsPresets(ctx context.Context) ([]PaymentTermsPreset, error)
+	GetPendingTimesheet(ctx context.Context, id int64) (GetPendingTimesheetRow, error)
+	GetPinnedClientNotes(ctx context.Context, clientID int64) ([]GetPinnedClientNotesRow, error)
 	GetProject(ctx context.Context, id int64) (GetProjectRow, error)
+	GetProjectAttachment(ctx context.Context, id int64) (ProjectAttachment, error)
+	GetProjectAttachmentsByProject(ctx context.Context, projectID int64) ([]ProjectAttachment, error)
+	GetProjectChecklist(ctx context.Context, id int64) ([]GetProjectChecklistRow, error)
+	GetProjectMargins(ctx context.Context) ([]GetProjectMarginsRow, error)
+	GetProjectSharedUsers(ctx context.Context, projectID int64) ([]GetProjectSharedUsersRow, error)
 	GetProjectsByClient(ctx context.Context, clientID int64) ([]GetProjectsByClientRow, error)
+	GetProjectsByTagCount(ctx context.Context, tagID int64) (int64, error)
+	GetProjectsByTagWithClientPagination(ctx context.Context, arg GetProjectsByTagWithClientPaginationParams) ([]GetProjectsByTagWithClientPaginationRow, error)
 	GetProjectsCount(ctx context.Context) (int64, error)
+	GetProjectsWithClientByIDs(ctx context.Context, ids []int64) ([]GetProjectsWithClientByIDsRow, error)
 	GetProjectsWithClientPagination(ctx context.Context, arg GetProjectsWithClientPaginationParams) ([]GetProjectsWithClientPaginationRow, error)
+	GetRecentClientVisits(ctx context.Context, arg GetRecentClientVisitsParams) ([]GetRecentClientVisitsRow, error)
+	GetRecentProjectVisits(ctx context.Context, arg GetRecentProjectVisitsParams) ([]GetRecentProjectVisitsRow, error)
+	GetSavedView(ctx context.Context, id int64) (GetSavedViewRow, error)
+	GetSavedViewsForList(ctx context.Context, arg GetSavedViewsForListParams) ([]GetSavedViewsForListRow, error)
+	GetScheduledTask(ctx context.Context, name string) (ScheduledTask, error)
+	GetService(ctx context.Context, id int64) (GetServiceRow, error)
 	GetSetting(ctx context.Context, key string) (Setting, error)
+	GetSharedProjectIDs(ctx context.Context, userID int64) ([]int64, error)
+	GetTag(ctx context.Context, id int64) (GetTagRow, error)
+	GetTagsForClient(ctx context.Context, clientID int64) ([]GetTagsForClientRow, error)
+	GetTagsForProject(ctx context.Context, projectID int64) ([]GetTagsForProjectRow, error)
 	GetTimesheet(ctx context.Context, id int64) (GetTimesheetRow, error)
+	GetTimesheetLockingInvoices(ctx context.Context, id int64) ([]int64, error)
+	GetTimesheetTotalsByProject(ctx context.Context, projectID int64) (GetTimesheetTotalsByProjectRow, error)
 	GetTimesheetsByProject(ctx context.Context, projectID int64) ([]GetTimesheetsByProjectRow, error)
+	GetTimesheetsByProjectWithPagination(ctx context.Context, arg GetTimesheetsByProjectWithPaginationParams) ([]GetTimesheetsByProjectWithPaginationRow, error)
+	GetTimesheetsForReport(ctx context.Context, arg GetTimesheetsForReportParams) ([]GetTimesheetsForReportRow, error)
+	GetTimesheetsPendingApproval(ctx context.Context) ([]GetTimesheetsPendingApprovalRow, error)
+	GetUnbilledProjectTotals(ctx context.Context, arg GetUnbilledProjectTotalsParams) ([]GetUnbilledProjectTotalsRow, error)
+	GetUnbilledWork(ctx context.Context) ([]GetUnbilledWorkRow, error)
+	GetUnpaidInvoices(ctx context.Context) ([]GetUnpaidInvoicesRow, error)
+	GetUser(ctx context.Context, id int64) (GetUserRow, error)
+	GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error)
+	GetUserPreferences(ctx context.Context, userID int64) (GetUserPreferencesRow, error)
+	GetUserSessionByToken(ctx context.Context, token string) (UserSession, error)
+	GetUserSessionsByUser(ctx context.Context, arg GetUserSessionsByUserParams) ([]UserSession, error)
+	GetUsersCount(ctx context.Context) (int64, error)
+	InsertBusinessProfile(ctx context.Context, arg InsertBusinessProfileParams) (int64, error)
+	InsertChecklistItem(ctx context.Context, arg InsertChecklistItemParams) (int64, error)
 	InsertClient(ctx context.Context, arg InsertClientParams) (int64, error)
+	InsertClientApproval(ctx context.Context, arg InsertClientApprovalParams) (int64, error)
+	InsertClientCredit(ctx context.Context, arg InsertClientCreditParams) (int64, error)
+	InsertClientNote(ctx context.Context, arg InsertClientNoteParams) (int64, error)
+	InsertClientTag(ctx context.Context, arg InsertClientTagParams) error
 	InsertInvoice(ctx context.Context, arg InsertInvoiceParams) (int64, error)
+	InsertInvoiceDelivery(ctx context.Context, arg InsertInvoiceDeliveryParams) (int64, error)
+	InsertInvoiceEvent(ctx context.Context, arg InsertInvoiceEventParams) (int64, error)
+	InsertInvoiceProject(ctx context.Context, arg InsertInvoiceProjectParams) error
+	InsertInvoiceSnapshot(ctx context.Context, arg InsertInvoiceSnapshotParams) error
+	InsertJob(ctx context.Context, arg InsertJobParams) (int64, error)
+	InsertMilestone(ctx context.Context, arg InsertMilestoneParams) (int64, error)
+	InsertNotificationSent(ctx context.Context, arg InsertNotificationSentParams) (int64, error)
+	InsertPaymentTermsPreset(ctx context.Context, terms string) (int64, error)
+	InsertPendingTimesheet(ctx context.Context, arg InsertPendingTimesheetParams) (int64, error)
 	InsertProject(ctx context.Context, arg InsertProjectParams) (int64, error)
+	InsertProjectAttachment(ctx context.Context, arg InsertProjectAttachmentParams) (int64, error)
+	InsertProjectTag(ctx context.Context, arg InsertProjectTagParams) error
+	InsertSavedView(ctx context.Context, arg InsertSavedViewParams) (int64, error)
+	InsertService(ctx context.Context, arg InsertServiceParams) (int64, error)
+	InsertTag(ctx context.Context, arg InsertTagParams) (int64, error)
 	InsertTimesheet(ctx context.Context, arg InsertTimesheetParams) (int64, error)
+	InsertUser(ctx context.Context, arg InsertUserParams) (int64, error)
+	InsertUserSession(ctx context.Context, arg InsertUserSessionParams) (int64, error)
+	IsProjectShared(ctx context.Context, arg IsProjectSharedParams) (int64, error)
+	ListRecentJobs(ctx context.Context, limit int64) ([]ListRecentJobsRow, error)
+	MarkInvoicePaid(ctx context.Context, arg MarkInvoicePaidParams) (int64, error)
+	RecordInvoiceDeliveryOpen(ctx context.Context, arg RecordInvoiceDeliveryOpenParams) (int64, error)
+	RecordScheduledTaskError(ctx context.Context, arg RecordScheduledTaskErrorParams) error
+	RecordScheduledTaskRun(ctx context.Context, arg RecordScheduledTaskRunParams) error
+	RecordVisit(ctx context.Context, arg RecordVisitParams) error
+	ReleaseMilestonesForInvoice(ctx context.Context, invoiceID sql.NullInt64) error
+	RequeueRunningJobs(ctx context.Context) (int64, error)
+	RestoreClient(ctx context.Context, id int64) error
+	RestoreClientInvoices(ctx context.Context, clientID int64) error
+	RestoreClientMilestones(ctx context.Context, clientID int64) error
+	RestoreClientProjects(ctx context.Context, clientID int64) error
+	RestoreClientTimesheets(ctx context.Context, clientID int64) error
+	RestoreProject(ctx context.Context, id int64) error
+	RestoreProjectInvoices(ctx context.Context, projectID int64) error
+	RestoreProjectMilestones(ctx context.Context, projectID int64) error
+	RestoreProjectTimesheets(ctx context.Context, projectID int64) error
+	SaveClientProjectDefaults(ctx context.Context, arg SaveClientProjectDefaultsParams) error
+	SaveExchangeRate(ctx context.Context, arg SaveExchangeRateParams) error
+	SaveFormDraft(ctx context.Context, arg SaveFormDraftParams) error
+	SaveUserPreferences(ctx context.Context, arg SaveUserPreferencesParams) error
+	SearchClients(ctx context.Context, arg SearchClientsParams) ([]SearchClientsRow, error)
+	SearchProjectsWithClient(ctx context.Context, arg SearchProjectsWithClientParams) ([]SearchProjectsWithClientRow, error)
+	SetClientNotePinned(ctx context.Context, arg SetClientNotePinnedParams) error
+	SetClientPipelineStage(ctx context.Context, arg SetClientPipelineStageParams) error
+	SetExternalID(ctx context.Context, arg SetExternalIDParams) error
+	SetInvoiceBudgetCode(ctx context.Context, arg SetInvoiceBudgetCodeParams) error
+	SetInvoiceCCEmail(ctx context.Context, arg SetInvoiceCCEmailParams) error
+	SetInvoiceDueDate(ctx context.Context, arg SetInvoiceDueDateParams) error
+	SetInvoiceFinancials(ctx context.Context, arg SetInvoiceFinancialsParams) error
+	SetInvoiceNumber(ctx context.Context, arg SetInvoiceNumberParams) error
+	SetInvoicePDFSignature(ctx context.Context, arg SetInvoicePDFSignatureParams) error
+	SetInvoicePayment(ctx context.Context, arg SetInvoicePaymentParams) error
+	SetInvoiceTimesheetRange(ctx context.Context, arg SetInvoiceTimesheetRangeParams) error
+	SetMilestoneInvoice(ctx context.Context, arg SetMilestoneInvoiceParams) error
+	SetProjectStatus(ctx context.Context, arg SetProjectStatusParams) error
+	SetTimesheetCostRate(ctx context.Context, arg SetTimesheetCostRateParams) error
+	SetTimesheetPendingApproval(ctx context.Context, arg SetTimesheetPendingApprovalParams) error
+	SetTimesheetService(ctx context.Context, arg SetTimesheetServiceParams) error
+	SetTimesheetUser(ctx context.Context, arg SetTimesheetUserParams) error
+	SetTimesheetWorkType(ctx context.Context, arg SetTimesheetWorkTypeParams) error
+	ShareProject(ctx context.Context, arg ShareProjectParams) error
+	TouchUserSession(ctx context.Context, arg TouchUserSessionParams) error
+	UncheckProjectChecklistItem(ctx context.Context, arg UncheckProjectChecklistItemParams) error
+	UnshareProject(ctx context.Context, arg UnshareProjectParams) error
+	UpdateBusinessProfile(ctx context.Context, arg UpdateBusinessProfileParams) error
 	UpdateClient(ctx context.Context, arg UpdateClientParams) error
+	UpdateClientNote(ctx context.Context, arg UpdateClientNoteParams) error
 	UpdateInvoice(ctx context.Context, arg UpdateInvoiceParams) error
+	UpdateMilestone(ctx context.Context, arg UpdateMilestoneParams) error
 	UpdateProject(ctx context.Context, arg UpdateProjectParams) error
+	UpdateService(ctx context.Context, arg UpdateServiceParams) error
 	UpdateSetting(ctx context.Context, arg UpdateSettingParams) error
+	UpdateTag(ctx context.Context, arg UpdateTagParams) error
 	UpdateTimesheet(ctx context.Context, arg UpdateTimesheetParams) error
+	VoidInvoice(ctx context.Context, arg VoidInvoiceParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
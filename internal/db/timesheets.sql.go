@@ -11,6 +11,28 @@ import (
 	"time"
 )
 
+const deleteClientTimesheets = `-- name: DeleteClientTimesheets :exec
+UPDATE timesheet 
+SET deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = timesheet.project_id) 
+WHERE project_id IN (SELECT id FROM project WHERE client_id = ? AND deleted_at IS NOT NULL) AND deleted_at IS NULL
+`
+
+func (q *Queries) DeleteClientTimesheets(ctx context.Context, clientID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteClientTimesheets, clientID)
+	return err
+}
+
+const deleteProjectTimesheets = `-- name: DeleteProjectTimesheets :exec
+UPDATE timesheet 
+SET deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = timesheet.project_id) 
+WHERE project_id = ? AND deleted_at IS NULL
+`
+
+func (q *Queries) DeleteProjectTimesheets(ctx context.Context, projectID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteProjectTimesheets, projectID)
+	return err
+}
+
 const deleteTimesheet = `-- name: DeleteTimesheet :exec
 UPDATE timesheet 
 SET deleted_at = CURRENT_TIMESTAMP 
@@ -22,22 +44,86 @@ func (q *Queries) DeleteTimesheet(ctx context.Context, id int64) error {
 	return err
 }
 
+const getClientHoursBetween = `-- name: GetClientHoursBetween :one
+SELECT CAST(COALESCE(SUM(t.hours_worked), 0) AS REAL) AS hours
+FROM timesheet t
+JOIN project p ON p.id = t.project_id
+WHERE p.client_id = ? AND substr(t.work_date, 1, 10) >= ? AND substr(t.work_date, 1, 10) < ?
+  AND t.unit = 'hour' AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+`
+
+type GetClientHoursBetweenParams struct {
+	ClientID  int64       `json:"client_id"`
+	StartDate interface{} `json:"start_date"`
+	EndDate   interface{} `json:"end_date"`
+}
+
+func (q *Queries) GetClientHoursBetween(ctx context.Context, arg GetClientHoursBetweenParams) (float64, error) {
+	row := q.db.QueryRowContext(ctx, getClientHoursBetween, arg.ClientID, arg.StartDate, arg.EndDate)
+	var hours float64
+	err := row.Scan(&hours)
+	return hours, err
+}
+
+const getLockedTimesheetIDs = `-- name: GetLockedTimesheetIDs :many
+SELECT DISTINCT t.id
+FROM timesheet t
+JOIN invoice i ON i.project_id = t.project_id
+    OR i.id IN (SELECT ip.invoice_id FROM invoice_project ip WHERE ip.project_id = t.project_id)
+WHERE t.project_id = ? AND t.deleted_at IS NULL AND i.deleted_at IS NULL AND i.voided_at IS NULL AND i.created_at >= t.created_at
+  AND (i.timesheets_from IS NULL OR substr(i.timesheets_from, 1, 10) <= substr(t.work_date, 1, 10))
+  AND (i.timesheets_to IS NULL OR substr(i.timesheets_to, 1, 10) >= substr(t.work_date, 1, 10))
+  AND NOT EXISTS (SELECT 1 FROM milestone m WHERE m.invoice_id = i.id)
+ORDER BY t.id
+`
+
+func (q *Queries) GetLockedTimesheetIDs(ctx context.Context, projectID int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getLockedTimesheetIDs, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTimesheet = `-- name: GetTimesheet :one
-SELECT id, project_id, work_date, hours_worked, hourly_rate, description, updated_at, created_at, deleted_at 
-FROM timesheet 
-WHERE id = ? AND deleted_at IS NULL
+SELECT t.id, t.project_id, t.work_date, t.hours_worked, t.hourly_rate, t.cost_rate, t.description, t.user_id, t.service_id, s.name AS service_name, t.unit, t.work_type, t.pending_approval, t.updated_at, t.created_at, t.deleted_at 
+FROM timesheet t
+LEFT JOIN service s ON s.id = t.service_id
+WHERE t.id = ? AND t.deleted_at IS NULL
 `
 
 type GetTimesheetRow struct {
-	ID          int64          `json:"id"`
-	ProjectID   int64          `json:"project_id"`
-	WorkDate    time.Time      `json:"work_date"`
-	HoursWorked float64        `json:"hours_worked"`
-	HourlyRate  float64        `json:"hourly_rate"`
-	Description sql.NullString `json:"description"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	CreatedAt   time.Time      `json:"created_at"`
-	DeletedAt   interface{}    `json:"deleted_at"`
+	ID              int64           `json:"id"`
+	ProjectID       int64           `json:"project_id"`
+	WorkDate        time.Time       `json:"work_date"`
+	HoursWorked     float64         `json:"hours_worked"`
+	HourlyRate      float64         `json:"hourly_rate"`
+	CostRate        sql.NullFloat64 `json:"cost_rate"`
+	Description     sql.NullString  `json:"description"`
+	UserID          sql.NullInt64   `json:"user_id"`
+	ServiceID       sql.NullInt64   `json:"service_id"`
+	ServiceName     sql.NullString  `json:"service_name"`
+	Unit            string          `json:"unit"`
+	WorkType        string          `json:"work_type"`
+	PendingApproval bool            `json:"pending_approval"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+	DeletedAt       interface{}     `json:"deleted_at"`
 }
 
 func (q *Queries) GetTimesheet(ctx context.Context, id int64) (GetTimesheetRow, error) {
@@ -49,7 +135,14 @@ func (q *Queries) GetTimesheet(ctx context.Context, id int64) (GetTimesheetRow,
 		&i.WorkDate,
 		&i.HoursWorked,
 		&i.HourlyRate,
+		&i.CostRate,
 		&i.Description,
+		&i.UserID,
+		&i.ServiceID,
+		&i.ServiceName,
+		&i.Unit,
+		&i.WorkType,
+		&i.PendingApproval,
 		&i.UpdatedAt,
 		&i.CreatedAt,
 		&i.DeletedAt,
@@ -57,23 +150,97 @@ func (q *Queries) GetTimesheet(ctx context.Context, id int64) (GetTimesheetRow,
 	return i, err
 }
 
-const getTimesheetsByProject = `-- name: GetTimesheetsByProject :many
-SELECT id, project_id, work_date, hours_worked, hourly_rate, description, updated_at, created_at, deleted_at 
-FROM timesheet 
+const getTimesheetLockingInvoices = `-- name: GetTimesheetLockingInvoices :many
+SELECT DISTINCT i.id
+FROM timesheet t
+JOIN invoice i ON i.project_id = t.project_id
+    OR i.id IN (SELECT ip.invoice_id FROM invoice_project ip WHERE ip.project_id = t.project_id)
+WHERE t.id = ? AND t.deleted_at IS NULL AND i.deleted_at IS NULL AND i.voided_at IS NULL AND i.created_at >= t.created_at
+  AND (i.timesheets_from IS NULL OR substr(i.timesheets_from, 1, 10) <= substr(t.work_date, 1, 10))
+  AND (i.timesheets_to IS NULL OR substr(i.timesheets_to, 1, 10) >= substr(t.work_date, 1, 10))
+  AND NOT EXISTS (SELECT 1 FROM milestone m WHERE m.invoice_id = i.id)
+ORDER BY i.id
+`
+
+// Invoices that bill a timesheet entry: those not voided or deleted, made after the entry was
+// logged, whose timesheet range takes in its work date, including consolidated invoices.
+// Milestone invoices bill a fixed fee rather than the hours worked, so they never lock.
+func (q *Queries) GetTimesheetLockingInvoices(ctx context.Context, id int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getTimesheetLockingInvoices, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTimesheetTotalsByProject = `-- name: GetTimesheetTotalsByProject :one
+SELECT COUNT(*) AS entries,
+    CAST(COALESCE(SUM(CASE WHEN unit = 'hour' THEN hours_worked ELSE 0 END), 0) AS REAL) AS hours,
+    CAST(COALESCE(SUM(hours_worked * hourly_rate), 0) AS REAL) AS billed,
+    CAST(COALESCE(SUM(hours_worked * COALESCE(cost_rate, 0)), 0) AS REAL) AS cost
+FROM timesheet
 WHERE project_id = ? AND deleted_at IS NULL
-ORDER BY work_date DESC, created_at DESC
+`
+
+type GetTimesheetTotalsByProjectRow struct {
+	Entries int64   `json:"entries"`
+	Hours   float64 `json:"hours"`
+	Billed  float64 `json:"billed"`
+	Cost    float64 `json:"cost"`
+}
+
+func (q *Queries) GetTimesheetTotalsByProject(ctx context.Context, projectID int64) (GetTimesheetTotalsByProjectRow, error) {
+	row := q.db.QueryRowContext(ctx, getTimesheetTotalsByProject, projectID)
+	var i GetTimesheetTotalsByProjectRow
+	err := row.Scan(
+		&i.Entries,
+		&i.Hours,
+		&i.Billed,
+		&i.Cost,
+	)
+	return i, err
+}
+
+const getTimesheetsByProject = `-- name: GetTimesheetsByProject :many
+SELECT t.id, t.project_id, t.work_date, t.hours_worked, t.hourly_rate, t.cost_rate, t.description, t.user_id, t.service_id, s.name AS service_name, t.unit, t.work_type, t.pending_approval, t.updated_at, t.created_at, t.deleted_at 
+FROM timesheet t
+LEFT JOIN service s ON s.id = t.service_id
+WHERE t.project_id = ? AND t.deleted_at IS NULL
+ORDER BY t.work_date DESC, t.created_at DESC
 `
 
 type GetTimesheetsByProjectRow struct {
-	ID          int64          `json:"id"`
-	ProjectID   int64          `json:"project_id"`
-	WorkDate    time.Time      `json:"work_date"`
-	HoursWorked float64        `json:"hours_worked"`
-	HourlyRate  float64        `json:"hourly_rate"`
-	Description sql.NullString `json:"description"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	CreatedAt   time.Time      `json:"created_at"`
-	DeletedAt   interface{}    `json:"deleted_at"`
+	ID              int64           `json:"id"`
+	ProjectID       int64           `json:"project_id"`
+	WorkDate        time.Time       `json:"work_date"`
+	HoursWorked     float64         `json:"hours_worked"`
+	HourlyRate      float64         `json:"hourly_rate"`
+	CostRate        sql.NullFloat64 `json:"cost_rate"`
+	Description     sql.NullString  `json:"description"`
+	UserID          sql.NullInt64   `json:"user_id"`
+	ServiceID       sql.NullInt64   `json:"service_id"`
+	ServiceName     sql.NullString  `json:"service_name"`
+	Unit            string          `json:"unit"`
+	WorkType        string          `json:"work_type"`
+	PendingApproval bool            `json:"pending_approval"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+	DeletedAt       interface{}     `json:"deleted_at"`
 }
 
 func (q *Queries) GetTimesheetsByProject(ctx context.Context, projectID int64) ([]GetTimesheetsByProjectRow, error) {
@@ -91,7 +258,88 @@ func (q *Queries) GetTimesheetsByProject(ctx context.Context, projectID int64) (
 			&i.WorkDate,
 			&i.HoursWorked,
 			&i.HourlyRate,
+			&i.CostRate,
+			&i.Description,
+			&i.UserID,
+			&i.ServiceID,
+			&i.ServiceName,
+			&i.Unit,
+			&i.WorkType,
+			&i.PendingApproval,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTimesheetsByProjectWithPagination = `-- name: GetTimesheetsByProjectWithPagination :many
+SELECT t.id, t.project_id, t.work_date, t.hours_worked, t.hourly_rate, t.cost_rate, t.description, t.user_id, t.service_id, s.name AS service_name, t.unit, t.work_type, t.pending_approval, t.updated_at, t.created_at, t.deleted_at 
+FROM timesheet t
+LEFT JOIN service s ON s.id = t.service_id
+WHERE t.project_id = ? AND t.deleted_at IS NULL
+ORDER BY t.work_date DESC, t.created_at DESC, t.id DESC
+LIMIT ? OFFSET ?
+`
+
+type GetTimesheetsByProjectWithPaginationParams struct {
+	ProjectID int64 `json:"project_id"`
+	Limit     int64 `json:"limit"`
+	Offset    int64 `json:"offset"`
+}
+
+type GetTimesheetsByProjectWithPaginationRow struct {
+	ID              int64           `json:"id"`
+	ProjectID       int64           `json:"project_id"`
+	WorkDate        time.Time       `json:"work_date"`
+	HoursWorked     float64         `json:"hours_worked"`
+	HourlyRate      float64         `json:"hourly_rate"`
+	CostRate        sql.NullFloat64 `json:"cost_rate"`
+	Description     sql.NullString  `json:"description"`
+	UserID          sql.NullInt64   `json:"user_id"`
+	ServiceID       sql.NullInt64   `json:"service_id"`
+	ServiceName     sql.NullString  `json:"service_name"`
+	Unit            string          `json:"unit"`
+	WorkType        string          `json:"work_type"`
+	PendingApproval bool            `json:"pending_approval"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+	DeletedAt       interface{}     `json:"deleted_at"`
+}
+
+func (q *Queries) GetTimesheetsByProjectWithPagination(ctx context.Context, arg GetTimesheetsByProjectWithPaginationParams) ([]GetTimesheetsByProjectWithPaginationRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTimesheetsByProjectWithPagination, arg.ProjectID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTimesheetsByProjectWithPaginationRow{}
+	for rows.Next() {
+		var i GetTimesheetsByProjectWithPaginationRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.WorkDate,
+			&i.HoursWorked,
+			&i.HourlyRate,
+			&i.CostRate,
 			&i.Description,
+			&i.UserID,
+			&i.ServiceID,
+			&i.ServiceName,
+			&i.Unit,
+			&i.WorkType,
+			&i.PendingApproval,
 			&i.UpdatedAt,
 			&i.CreatedAt,
 			&i.DeletedAt,
@@ -109,6 +357,127 @@ func (q *Queries) GetTimesheetsByProject(ctx context.Context, projectID int64) (
 	return items, nil
 }
 
+const getTimesheetsForReport = `-- name: GetTimesheetsForReport :many
+SELECT t.id, t.project_id, p.name AS project_name, t.work_date, t.hours_worked, t.description, t.unit, t.work_type
+FROM timesheet t
+JOIN project p ON p.id = t.project_id
+WHERE (p.id = ? OR p.client_id = ?)
+  AND substr(t.work_date, 1, 10) >= ? AND substr(t.work_date, 1, 10) <= ?
+  AND t.pending_approval = false AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+ORDER BY t.work_date, t.created_at
+`
+
+type GetTimesheetsForReportParams struct {
+	ProjectID int64       `json:"project_id"`
+	ClientID  int64       `json:"client_id"`
+	StartDate interface{} `json:"start_date"`
+	EndDate   interface{} `json:"end_date"`
+}
+
+type GetTimesheetsForReportRow struct {
+	ID          int64          `json:"id"`
+	ProjectID   int64          `json:"project_id"`
+	ProjectName string         `json:"project_name"`
+	WorkDate    time.Time      `json:"work_date"`
+	HoursWorked float64        `json:"hours_worked"`
+	Description sql.NullString `json:"description"`
+	Unit        string         `json:"unit"`
+	WorkType    string         `json:"work_type"`
+}
+
+func (q *Queries) GetTimesheetsForReport(ctx context.Context, arg GetTimesheetsForReportParams) ([]GetTimesheetsForReportRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTimesheetsForReport,
+		arg.ProjectID,
+		arg.ClientID,
+		arg.StartDate,
+		arg.EndDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTimesheetsForReportRow{}
+	for rows.Next() {
+		var i GetTimesheetsForReportRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.ProjectName,
+			&i.WorkDate,
+			&i.HoursWorked,
+			&i.Description,
+			&i.Unit,
+			&i.WorkType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTimesheetsPendingApproval = `-- name: GetTimesheetsPendingApproval :many
+SELECT t.id, t.project_id, p.name AS project_name, t.work_date, t.hours_worked, t.description, t.unit, t.user_id, u.name AS user_name, t.created_at
+FROM timesheet t
+JOIN project p ON p.id = t.project_id
+LEFT JOIN user u ON u.id = t.user_id
+WHERE t.pending_approval = true AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+ORDER BY t.work_date, t.created_at
+`
+
+type GetTimesheetsPendingApprovalRow struct {
+	ID          int64          `json:"id"`
+	ProjectID   int64          `json:"project_id"`
+	ProjectName string         `json:"project_name"`
+	WorkDate    time.Time      `json:"work_date"`
+	HoursWorked float64        `json:"hours_worked"`
+	Description sql.NullString `json:"description"`
+	Unit        string         `json:"unit"`
+	UserID      sql.NullInt64  `json:"user_id"`
+	UserName    sql.NullString `json:"user_name"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+func (q *Queries) GetTimesheetsPendingApproval(ctx context.Context) ([]GetTimesheetsPendingApprovalRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTimesheetsPendingApproval)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTimesheetsPendingApprovalRow{}
+	for rows.Next() {
+		var i GetTimesheetsPendingApprovalRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.ProjectName,
+			&i.WorkDate,
+			&i.HoursWorked,
+			&i.Description,
+			&i.Unit,
+			&i.UserID,
+			&i.UserName,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertTimesheet = `-- name: InsertTimesheet :execlastid
 INSERT INTO timesheet (project_id, work_date, hours_worked, hourly_rate, description) 
 VALUES (?, ?, ?, ?, ?)
@@ -136,6 +505,110 @@ func (q *Queries) InsertTimesheet(ctx context.Context, arg InsertTimesheetParams
 	return result.LastInsertId()
 }
 
+const restoreClientTimesheets = `-- name: RestoreClientTimesheets :exec
+UPDATE timesheet 
+SET deleted_at = NULL 
+WHERE project_id IN (SELECT p.id FROM project p WHERE p.client_id = ? AND p.deleted_at = (SELECT c.deleted_at FROM client c WHERE c.id = p.client_id)) 
+  AND deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = timesheet.project_id)
+`
+
+func (q *Queries) RestoreClientTimesheets(ctx context.Context, clientID int64) error {
+	_, err := q.db.ExecContext(ctx, restoreClientTimesheets, clientID)
+	return err
+}
+
+const restoreProjectTimesheets = `-- name: RestoreProjectTimesheets :exec
+UPDATE timesheet 
+SET deleted_at = NULL 
+WHERE project_id = ? AND deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = timesheet.project_id)
+`
+
+func (q *Queries) RestoreProjectTimesheets(ctx context.Context, projectID int64) error {
+	_, err := q.db.ExecContext(ctx, restoreProjectTimesheets, projectID)
+	return err
+}
+
+const setTimesheetCostRate = `-- name: SetTimesheetCostRate :exec
+UPDATE timesheet 
+SET cost_rate = ? 
+WHERE id = ? AND deleted_at IS NULL
+`
+
+type SetTimesheetCostRateParams struct {
+	CostRate sql.NullFloat64 `json:"cost_rate"`
+	ID       int64           `json:"id"`
+}
+
+func (q *Queries) SetTimesheetCostRate(ctx context.Context, arg SetTimesheetCostRateParams) error {
+	_, err := q.db.ExecContext(ctx, setTimesheetCostRate, arg.CostRate, arg.ID)
+	return err
+}
+
+const setTimesheetPendingApproval = `-- name: SetTimesheetPendingApproval :exec
+UPDATE timesheet 
+SET pending_approval = ? 
+WHERE id = ? AND deleted_at IS NULL
+`
+
+type SetTimesheetPendingApprovalParams struct {
+	PendingApproval bool  `json:"pending_approval"`
+	ID              int64 `json:"id"`
+}
+
+func (q *Queries) SetTimesheetPendingApproval(ctx context.Context, arg SetTimesheetPendingApprovalParams) error {
+	_, err := q.db.ExecContext(ctx, setTimesheetPendingApproval, arg.PendingApproval, arg.ID)
+	return err
+}
+
+const setTimesheetService = `-- name: SetTimesheetService :exec
+UPDATE timesheet 
+SET service_id = ?, unit = ? 
+WHERE id = ? AND deleted_at IS NULL
+`
+
+type SetTimesheetServiceParams struct {
+	ServiceID sql.NullInt64 `json:"service_id"`
+	Unit      string        `json:"unit"`
+	ID        int64         `json:"id"`
+}
+
+func (q *Queries) SetTimesheetService(ctx context.Context, arg SetTimesheetServiceParams) error {
+	_, err := q.db.ExecContext(ctx, setTimesheetService, arg.ServiceID, arg.Unit, arg.ID)
+	return err
+}
+
+const setTimesheetUser = `-- name: SetTimesheetUser :exec
+UPDATE timesheet 
+SET user_id = ? 
+WHERE id = ? AND deleted_at IS NULL
+`
+
+type SetTimesheetUserParams struct {
+	UserID sql.NullInt64 `json:"user_id"`
+	ID     int64         `json:"id"`
+}
+
+func (q *Queries) SetTimesheetUser(ctx context.Context, arg SetTimesheetUserParams) error {
+	_, err := q.db.ExecContext(ctx, setTimesheetUser, arg.UserID, arg.ID)
+	return err
+}
+
+const setTimesheetWorkType = `-- name: SetTimesheetWorkType :exec
+UPDATE timesheet 
+SET work_type = ? 
+WHERE id = ? AND deleted_at IS NULL
+`
+
+type SetTimesheetWorkTypeParams struct {
+	WorkType string `json:"work_type"`
+	ID       int64  `json:"id"`
+}
+
+func (q *Queries) SetTimesheetWorkType(ctx context.Context, arg SetTimesheetWorkTypeParams) error {
+	_, err := q.db.ExecContext(ctx, setTimesheetWorkType, arg.WorkType, arg.ID)
+	return err
+}
+
 const updateTimesheet = `-- name: UpdateTimesheet :exec
 UPDATE timesheet 
 SET work_date = ?, hours_worked = ?, hourly_rate = ?, description = ?, updated_at = CURRENT_TIMESTAMP 
@@ -0,0 +1,100 @@
+// Package exchangerate fetches the exchange rates published for a day from a
+// Frankfurter-compatible service, such as https://api.frankfurter.app
+package exchangerate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds how long a fetch may take when no client is configured
+const DefaultTimeout = 10 * time.Second
+
+// Rates are the units of each currency bought by one unit of Base, as published for Date.
+// Services publish no rates for weekends and holidays, so Date can be earlier than the day
+// asked for.
+type Rates struct {
+	Base   string
+	Date   time.Time
+	Rates  map[string]float64
+	Source string
+}
+
+// Fetcher requests exchange rates over HTTP
+type Fetcher struct {
+	Client *http.Client
+}
+
+// response is the JSON body returned by Frankfurter-compatible services
+type response struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// Fetch requests the rates of the given currencies against base on a date from the service at
+// baseURL. Source is set to the service's host name. A currency missing from the response is
+// an error.
+func (f Fetcher) Fetch(ctx context.Context, baseURL, base string, date time.Time, currencies ...string) (Rates, error) {
+	service, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil || (service.Scheme != "http" && service.Scheme != "https") {
+		return Rates{}, fmt.Errorf("exchangerate: invalid service URL %q", baseURL)
+	}
+	wanted := make([]string, len(currencies))
+	for i, currency := range currencies {
+		wanted[i] = strings.ToUpper(currency)
+	}
+	service = service.JoinPath(date.Format("2006-01-02"))
+	query := url.Values{}
+	query.Set("from", strings.ToUpper(base))
+	query.Set("to", strings.Join(wanted, ","))
+	service.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, service.String(), nil)
+	if err != nil {
+		return Rates{}, fmt.Errorf("exchangerate: building request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "FreelanceTracker/1.0")
+
+	client := f.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Rates{}, fmt.Errorf("exchangerate: fetching from %s: %w", service.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return Rates{}, fmt.Errorf("exchangerate: %s responded with %s", service.Host, resp.Status)
+	}
+
+	var body response
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return Rates{}, fmt.Errorf("exchangerate: decoding response: %w", err)
+	}
+	published, err := time.Parse("2006-01-02", body.Date)
+	if err != nil {
+		return Rates{}, fmt.Errorf("exchangerate: response date %q not recognised", body.Date)
+	}
+	for _, currency := range wanted {
+		if body.Rates[currency] <= 0 {
+			return Rates{}, fmt.Errorf("exchangerate: %s gave no rate for %s", service.Host, currency)
+		}
+	}
+
+	return Rates{
+		Base:   body.Base,
+		Date:   published,
+		Rates:  body.Rates,
+		Source: service.Host,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package exchangerate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetcherFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		switch r.URL.Path {
+		case "/v1/2024-03-09":
+			assert.Equal(t, "USD", r.URL.Query().Get("from"))
+			assert.Equal(t, "EUR,GBP", r.URL.Query().Get("to"))
+			w.Write([]byte(`{"amount":1.0,"base":"USD","date":"2024-03-08","rates":{"EUR":0.9132,"GBP":0.7811}}`))
+		case "/v1/2024-03-10":
+			w.Write([]byte(`{"amount":1.0,"base":"USD","date":"2024-03-08","rates":{}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := Fetcher{Client: server.Client()}
+	day := func(value string) time.Time {
+		d, _ := time.Parse("2006-01-02", value)
+		return d
+	}
+
+	rates, err := fetcher.Fetch(context.Background(), server.URL+"/v1/", "usd", day("2024-03-09"), "eur", "GBP")
+	require.NoError(t, err)
+	assert.Equal(t, "USD", rates.Base)
+	assert.Equal(t, "2024-03-08", rates.Date.Format("2006-01-02"), "a weekend gives the rates of the Friday before")
+	assert.Equal(t, 0.9132, rates.Rates["EUR"])
+	assert.Equal(t, 0.7811, rates.Rates["GBP"])
+	assert.Equal(t, server.Listener.Addr().String(), rates.Source)
+
+	_, err = fetcher.Fetch(context.Background(), server.URL+"/v1", "USD", day("2024-03-10"), "EUR")
+	assert.ErrorContains(t, err, "no rate for EUR")
+
+	_, err = fetcher.Fetch(context.Background(), server.URL+"/v2", "USD", day("2024-03-09"), "EUR")
+	assert.ErrorContains(t, err, "404")
+
+	_, err = fetcher.Fetch(context.Background(), "ftp://rates.example", "USD", day("2024-03-09"), "EUR")
+	assert.ErrorContains(t, err, "invalid service URL")
+}
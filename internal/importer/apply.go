@@ -0,0 +1,313 @@
+package importer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
+)
+
+// Options are the defaults for clients and projects an import creates, and the longest
+// timesheet description it keeps
+type Options struct {
+	HourlyRate     float64
+	Currency       string
+	MaxDescription int
+}
+
+// Summary counts what an import created. Rows matching a client, time entry or invoice
+// already in the database are skipped so that importing the same export twice is harmless.
+type Summary struct {
+	ClientsCreated    int
+	ProjectsCreated   int
+	TimesheetsCreated int
+	InvoicesCreated   int
+	Skipped           int
+	Problems          []string
+}
+
+// importedProjectStatus is given to projects an import creates, since the work in another
+// tool's export has already been done
+const importedProjectStatus = "Work Complete"
+
+// defaultMaxDescription is how much of a time entry's notes fits in a timesheet description
+// when Options doesn't say
+const defaultMaxDescription = 255
+
+// Apply adds a batch read from an export using models bound to one transaction, matching
+// clients by name and projects by name within their client, and creating any that are missing
+func Apply(tx models.TxModels, preset Preset, batch Batch, options Options) (Summary, error) {
+	clients, err := tx.Clients.GetAll()
+	if err != nil {
+		return Summary{}, err
+	}
+
+	a := &applier{
+		tx:         tx,
+		preset:     preset,
+		options:    options,
+		clients:    make(map[string]models.Client, len(clients)),
+		projects:   map[int]map[string]models.Project{},
+		timesheets: map[int]map[string]bool{},
+		summary:    Summary{Problems: batch.Problems},
+	}
+	for _, client := range clients {
+		a.clients[nameKey(client.Name)] = client
+	}
+
+	for _, record := range batch.Clients {
+		if err := a.addClient(record); err != nil {
+			return Summary{}, err
+		}
+	}
+	for _, entry := range batch.TimeEntries {
+		if err := a.addTimeEntry(entry); err != nil {
+			return Summary{}, fmt.Errorf("row %d: %w", entry.Row, err)
+		}
+	}
+	for _, invoice := range batch.Invoices {
+		if err := a.addInvoice(invoice); err != nil {
+			return Summary{}, fmt.Errorf("row %d: %w", invoice.Row, err)
+		}
+	}
+	return a.summary, nil
+}
+
+// applier holds what an import has found or created so far
+type applier struct {
+	tx         models.TxModels
+	preset     Preset
+	options    Options
+	clients    map[string]models.Client
+	projects   map[int]map[string]models.Project
+	timesheets map[int]map[string]bool
+	summary    Summary
+}
+
+// nameKey normalises a name for matching, ignoring case and surrounding space
+func nameKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// stringPtr returns nil for blank values so that optional client fields stay empty
+func stringPtr(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// addClient creates a client unless one with the same name exists
+func (a *applier) addClient(record ClientRecord) error {
+	if _, ok := a.clients[nameKey(record.Name)]; ok {
+		a.summary.Skipped++
+		return nil
+	}
+	_, err := a.createClient(models.Client{
+		Name:                    record.Name,
+		Email:                   record.Email,
+		Phone:                   stringPtr(record.Phone),
+		Address1:                stringPtr(record.Address1),
+		City:                    stringPtr(record.City),
+		State:                   stringPtr(record.State),
+		ZipCode:                 stringPtr(record.ZipCode),
+		Country:                 stringPtr(record.Country),
+		HourlyRate:              a.options.HourlyRate,
+		IncludeAddressOnInvoice: true,
+	})
+	return err
+}
+
+// createClient inserts a client and remembers it for later rows
+func (a *applier) createClient(client models.Client) (models.Client, error) {
+	id, err := a.tx.Clients.Insert(client)
+	if err != nil {
+		return models.Client{}, err
+	}
+	client.ID = id
+	a.clients[nameKey(client.Name)] = client
+	a.summary.ClientsCreated++
+	return client, nil
+}
+
+// client finds the client with a name, creating it if there isn't one
+func (a *applier) client(name string) (models.Client, error) {
+	if client, ok := a.clients[nameKey(name)]; ok {
+		return client, nil
+	}
+	return a.createClient(models.Client{
+		Name:                    strings.TrimSpace(name),
+		HourlyRate:              a.options.HourlyRate,
+		IncludeAddressOnInvoice: true,
+	})
+}
+
+// project finds the client's project with a name, creating it if there isn't one. Rows
+// without a project go into a project named after the tool they came from.
+func (a *applier) project(client models.Client, name string, hourlyRate float64) (models.Project, error) {
+	if strings.TrimSpace(name) == "" {
+		name = a.preset.Label + " import"
+	}
+
+	projects, ok := a.projects[client.ID]
+	if !ok {
+		existing, err := a.tx.Projects.GetByClient(client.ID)
+		if err != nil {
+			return models.Project{}, err
+		}
+		projects = make(map[string]models.Project, len(existing))
+		for _, project := range existing {
+			projects[nameKey(project.Name)] = project
+		}
+		a.projects[client.ID] = projects
+	}
+	if project, ok := projects[nameKey(name)]; ok {
+		return project, nil
+	}
+
+	if hourlyRate <= 0 {
+		hourlyRate = client.HourlyRate
+	}
+	project := models.Project{
+		Name:                   strings.TrimSpace(name),
+		ClientID:               client.ID,
+		Status:                 importedProjectStatus,
+		HourlyRate:             hourlyRate,
+		CurrencyDisplay:        a.options.Currency,
+		CurrencyConversionRate: 1.0,
+	}
+	id, err := a.tx.Projects.Insert(project)
+	if err != nil {
+		return models.Project{}, err
+	}
+	project.ID = id
+	projects[nameKey(project.Name)] = project
+	a.summary.ProjectsCreated++
+	return project, nil
+}
+
+// timesheetKey identifies a time entry for spotting ones already imported
+func timesheetKey(date time.Time, hours float64, description string) string {
+	return fmt.Sprintf("%s|%.2f|%s", date.Format(time.DateOnly), hours, description)
+}
+
+// addTimeEntry adds a timesheet entry unless the project already has the same one
+func (a *applier) addTimeEntry(entry TimeEntry) error {
+	client, err := a.client(entry.Client)
+	if err != nil {
+		return err
+	}
+	project, err := a.project(client, entry.Project, entry.Rate)
+	if err != nil {
+		return err
+	}
+
+	existing, ok := a.timesheets[project.ID]
+	if !ok {
+		timesheets, err := a.tx.Timesheets.GetByProject(project.ID)
+		if err != nil {
+			return err
+		}
+		existing = make(map[string]bool, len(timesheets))
+		for _, timesheet := range timesheets {
+			existing[timesheetKey(timesheet.WorkDate, timesheet.HoursWorked, timesheet.Description)] = true
+		}
+		a.timesheets[project.ID] = existing
+	}
+
+	maxDescription := a.options.MaxDescription
+	if maxDescription <= 0 {
+		maxDescription = defaultMaxDescription
+	}
+	description := entry.Description
+	if runes := []rune(description); len(runes) > maxDescription {
+		description = string(runes[:maxDescription])
+	}
+	key := timesheetKey(entry.Date, entry.Hours, description)
+	if existing[key] {
+		a.summary.Skipped++
+		return nil
+	}
+
+	rate := entry.Rate
+	if rate <= 0 {
+		rate = project.HourlyRate
+	}
+	_, err = a.tx.Timesheets.Insert(project.ID, entry.Date, entry.Hours, rate, description)
+	if err != nil {
+		return err
+	}
+	existing[key] = true
+	a.summary.TimesheetsCreated++
+	return nil
+}
+
+// addInvoice adds an invoice unless one with the same number exists. An invoice counts as
+// paid when it has a paid date and the export doesn't show it as only partly paid.
+func (a *applier) addInvoice(record InvoiceRecord) error {
+	if record.Number != "" {
+		exists, err := a.tx.Invoices.NumberExists(record.Number)
+		if err != nil {
+			return err
+		}
+		if exists {
+			a.summary.Skipped++
+			return nil
+		}
+	}
+
+	client, err := a.client(record.Client)
+	if err != nil {
+		return err
+	}
+	project, err := a.project(client, record.Project, 0)
+	if err != nil {
+		return err
+	}
+
+	var datePaid *time.Time
+	if record.PaidDate != nil && (record.AmountPaid == 0 || record.AmountPaid >= record.Amount-0.005) {
+		datePaid = record.PaidDate
+	}
+	paymentTerms := record.PaymentTerms
+	if paymentTerms == "" && record.DueDate != nil {
+		days := int(math.Round(record.DueDate.Sub(record.IssueDate).Hours() / 24))
+		paymentTerms = fmt.Sprintf("Net %d", days)
+	}
+
+	id, err := a.tx.Invoices.Insert(project.ID, record.IssueDate, datePaid, paymentTerms, record.Amount, false)
+	if err != nil {
+		return err
+	}
+	if record.Number != "" {
+		if err := a.tx.Invoices.SetInvoiceNumber(id, record.Number); err != nil {
+			return err
+		}
+	}
+	if record.DueDate != nil {
+		if err := a.tx.Invoices.SetDueDate(id, record.DueDate); err != nil {
+			return err
+		}
+	}
+	if record.AmountPaid > 0 {
+		amountPaid := record.AmountPaid
+		if err := a.tx.Invoices.SetPayment(id, &amountPaid, 0); err != nil {
+			return err
+		}
+	}
+	if err := a.tx.Invoices.SetFinancials(id, models.FinancialsFromProject(project)); err != nil {
+		return err
+	}
+	if err := a.tx.InvoiceEvents.Record(id, models.InvoiceEventCreated); err != nil {
+		return err
+	}
+	if datePaid != nil {
+		if err := a.tx.InvoiceEvents.Record(id, models.InvoiceEventPaid); err != nil {
+			return err
+		}
+	}
+	a.summary.InvoicesCreated++
+	return nil
+}
@@ -0,0 +1,420 @@
+// Package importer reads the CSV exports of other time tracking and invoicing tools, such as
+// Harvest and FreshBooks, so their clients, time entries and invoices can be brought over
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the type of records a CSV export holds
+type Kind string
+
+const (
+	KindClients     Kind = "clients"
+	KindTimeEntries Kind = "time"
+	KindInvoices    Kind = "invoices"
+)
+
+// Kinds lists the kinds of export that can be imported, in the order they are best imported
+var Kinds = []Kind{KindClients, KindTimeEntries, KindInvoices}
+
+// Label returns the kind's name as shown to users
+func (k Kind) Label() string {
+	switch k {
+	case KindClients:
+		return "Clients"
+	case KindTimeEntries:
+		return "Time entries"
+	case KindInvoices:
+		return "Invoices"
+	}
+	return string(k)
+}
+
+// Preset maps the columns of one tool's CSV exports onto the fields imported. Columns are
+// matched by header name, case insensitively, taking the first name found for each field.
+type Preset struct {
+	Name    string
+	Label   string
+	Columns map[Kind]map[string][]string
+	// DateLayouts are tried in order for every date in the export
+	DateLayouts []string
+}
+
+// Presets lists the tools whose exports can be imported
+var Presets = []Preset{
+	{
+		Name:  "harvest",
+		Label: "Harvest",
+		Columns: map[Kind]map[string][]string{
+			KindClients: {
+				"name":    {"client name", "client", "name"},
+				"email":   {"email", "contact email"},
+				"phone":   {"phone", "office phone", "contact phone"},
+				"address": {"address", "client address"},
+			},
+			KindTimeEntries: {
+				"date":    {"date", "spent date"},
+				"client":  {"client"},
+				"project": {"project"},
+				"task":    {"task"},
+				"notes":   {"notes"},
+				"hours":   {"hours", "hours rounded"},
+				"rate":    {"billable rate", "rate"},
+			},
+			KindInvoices: {
+				"number":       {"number", "invoice id", "id"},
+				"client":       {"client"},
+				"project":      {"project", "projects"},
+				"issue_date":   {"issue date", "issued"},
+				"due_date":     {"due date"},
+				"paid_date":    {"paid date", "last payment date"},
+				"amount":       {"amount", "invoice amount", "total"},
+				"paid_amount":  {"paid amount", "paid"},
+				"payment_term": {"payment term", "terms"},
+			},
+		},
+		DateLayouts: []string{"2006-01-02", "01/02/2006", "1/2/2006"},
+	},
+	{
+		Name:  "freshbooks",
+		Label: "FreshBooks",
+		Columns: map[Kind]map[string][]string{
+			KindClients: {
+				"name":       {"organization", "company name", "company", "client name"},
+				"first_name": {"first name"},
+				"last_name":  {"last name"},
+				"email":      {"email", "email address"},
+				"phone":      {"phone", "business phone", "mobile phone"},
+				"address":    {"street", "address", "address line 1", "street address"},
+				"city":       {"city"},
+				"state":      {"province/state", "state", "province"},
+				"zip":        {"postal code", "zip code", "zip/postal code"},
+				"country":    {"country"},
+			},
+			KindTimeEntries: {
+				"date":    {"date"},
+				"client":  {"client", "client name", "organization"},
+				"project": {"project"},
+				"task":    {"service", "task"},
+				"notes":   {"note", "notes", "description"},
+				"hours":   {"hours", "duration (hours)", "duration", "time"},
+				"rate":    {"rate", "hourly rate"},
+			},
+			KindInvoices: {
+				"number":       {"invoice #", "invoice number", "invoice"},
+				"client":       {"client name", "organization", "client"},
+				"project":      {"project"},
+				"issue_date":   {"date issued", "invoice date", "issue date", "date"},
+				"due_date":     {"due date"},
+				"paid_date":    {"date paid", "paid date", "last payment date"},
+				"amount":       {"amount", "total", "invoice total"},
+				"paid_amount":  {"paid", "amount paid", "paid amount"},
+				"payment_term": {"terms", "payment terms"},
+			},
+		},
+		DateLayouts: []string{"2006-01-02", "01/02/2006", "1/2/2006", "2006-01-02 15:04:05"},
+	},
+}
+
+// PresetByName finds a preset by its name
+func PresetByName(name string) (Preset, bool) {
+	for _, preset := range Presets {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return Preset{}, false
+}
+
+// ClientRecord is a client read from an export
+type ClientRecord struct {
+	Name     string
+	Email    string
+	Phone    string
+	Address1 string
+	City     string
+	State    string
+	ZipCode  string
+	Country  string
+}
+
+// TimeEntry is a time entry read from an export
+type TimeEntry struct {
+	Row         int
+	Date        time.Time
+	Client      string
+	Project     string
+	Hours       float64
+	Rate        float64
+	Description string
+}
+
+// InvoiceRecord is an invoice read from an export
+type InvoiceRecord struct {
+	Row          int
+	Number       string
+	Client       string
+	Project      string
+	IssueDate    time.Time
+	DueDate      *time.Time
+	PaidDate     *time.Time
+	Amount       float64
+	AmountPaid   float64
+	PaymentTerms string
+}
+
+// Batch is everything read from one export. Rows that couldn't be read are left out and
+// described in Problems.
+type Batch struct {
+	Kind        Kind
+	Clients     []ClientRecord
+	TimeEntries []TimeEntry
+	Invoices    []InvoiceRecord
+	Problems    []string
+}
+
+// requiredColumns lists the fields an export of each kind must have a column for
+var requiredColumns = map[Kind][]string{
+	KindClients:     {"name"},
+	KindTimeEntries: {"date", "client", "hours"},
+	KindInvoices:    {"client", "issue_date", "amount"},
+}
+
+// Read parses a CSV export of the given kind using the preset's column names
+func Read(preset Preset, kind Kind, r io.Reader) (Batch, error) {
+	columnNames, ok := preset.Columns[kind]
+	if !ok {
+		return Batch{}, fmt.Errorf("%s exports of %s can't be imported", preset.Label, strings.ToLower(kind.Label()))
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return Batch{}, fmt.Errorf("reading header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if _, exists := index[column]; !exists {
+			index[column] = i
+		}
+	}
+
+	columns := make(map[string]int, len(columnNames))
+	for field, names := range columnNames {
+		columns[field] = -1
+		for _, name := range names {
+			if i, ok := index[name]; ok {
+				columns[field] = i
+				break
+			}
+		}
+	}
+	found := func(field string) bool {
+		i, ok := columns[field]
+		return ok && i >= 0
+	}
+	for _, field := range requiredColumns[kind] {
+		// Clients may be named by their contact's first and last name instead
+		if !found(field) && !(field == "name" && found("first_name")) {
+			return Batch{}, fmt.Errorf("no %s column found; is this a %s export of %s?",
+				strings.ReplaceAll(field, "_", " "), preset.Label, strings.ToLower(kind.Label()))
+		}
+	}
+
+	batch := Batch{Kind: kind}
+	// Rows are numbered as a spreadsheet would show them, counting the header
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Batch{}, fmt.Errorf("row %d: %w", row, err)
+		}
+
+		value := func(field string) string {
+			if !found(field) || columns[field] >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[columns[field]])
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		var problem string
+		switch kind {
+		case KindClients:
+			problem = batch.addClient(value)
+		case KindTimeEntries:
+			problem = batch.addTimeEntry(preset, row, value)
+		case KindInvoices:
+			problem = batch.addInvoice(preset, row, value)
+		}
+		if problem != "" {
+			batch.Problems = append(batch.Problems, fmt.Sprintf("Row %d: %s", row, problem))
+		}
+	}
+	return batch, nil
+}
+
+// addClient adds a client row, returning a problem if it can't be read
+func (b *Batch) addClient(value func(string) string) string {
+	name := value("name")
+	if name == "" {
+		name = strings.TrimSpace(value("first_name") + " " + value("last_name"))
+	}
+	if name == "" {
+		return "no client name"
+	}
+	b.Clients = append(b.Clients, ClientRecord{
+		Name:     name,
+		Email:    value("email"),
+		Phone:    value("phone"),
+		Address1: value("address"),
+		City:     value("city"),
+		State:    value("state"),
+		ZipCode:  value("zip"),
+		Country:  value("country"),
+	})
+	return ""
+}
+
+// addTimeEntry adds a time entry row, returning a problem if it can't be read
+func (b *Batch) addTimeEntry(preset Preset, row int, value func(string) string) string {
+	date, err := parseDate(preset, value("date"))
+	if err != nil {
+		return err.Error()
+	}
+	if value("client") == "" {
+		return "no client"
+	}
+	hours, err := parseHours(value("hours"))
+	if err != nil {
+		return err.Error()
+	}
+	rate, err := parseAmount(value("rate"))
+	if err != nil {
+		return err.Error()
+	}
+
+	description := value("notes")
+	if task := value("task"); task != "" {
+		if description == "" {
+			description = task
+		} else {
+			description = task + ": " + description
+		}
+	}
+
+	b.TimeEntries = append(b.TimeEntries, TimeEntry{
+		Row:         row,
+		Date:        date,
+		Client:      value("client"),
+		Project:     value("project"),
+		Hours:       hours,
+		Rate:        rate,
+		Description: description,
+	})
+	return ""
+}
+
+// addInvoice adds an invoice row, returning a problem if it can't be read
+func (b *Batch) addInvoice(preset Preset, row int, value func(string) string) string {
+	if value("client") == "" {
+		return "no client"
+	}
+	issueDate, err := parseDate(preset, value("issue_date"))
+	if err != nil {
+		return err.Error()
+	}
+	amount, err := parseAmount(value("amount"))
+	if err != nil {
+		return err.Error()
+	}
+	amountPaid, err := parseAmount(value("paid_amount"))
+	if err != nil {
+		return err.Error()
+	}
+
+	invoice := InvoiceRecord{
+		Row:          row,
+		Number:       value("number"),
+		Client:       value("client"),
+		Project:      value("project"),
+		IssueDate:    issueDate,
+		Amount:       amount,
+		AmountPaid:   amountPaid,
+		PaymentTerms: value("payment_term"),
+	}
+	for field, target := range map[string]**time.Time{"due_date": &invoice.DueDate, "paid_date": &invoice.PaidDate} {
+		if text := value(field); text != "" {
+			date, err := parseDate(preset, text)
+			if err != nil {
+				return err.Error()
+			}
+			*target = &date
+		}
+	}
+	b.Invoices = append(b.Invoices, invoice)
+	return ""
+}
+
+// parseDate reads a date in any of the preset's layouts
+func parseDate(preset Preset, text string) (time.Time, error) {
+	if text == "" {
+		return time.Time{}, fmt.Errorf("no date")
+	}
+	for _, layout := range preset.DateLayouts {
+		if date, err := time.Parse(layout, text); err == nil {
+			return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("date %q not recognised", text)
+}
+
+// parseHours reads hours written as a decimal, such as 1.5, or as hours and minutes, such as 1:30
+func parseHours(text string) (float64, error) {
+	if hours, minutes, ok := strings.Cut(text, ":"); ok {
+		h, errHours := strconv.Atoi(hours)
+		m, errMinutes := strconv.Atoi(minutes)
+		if errHours != nil || errMinutes != nil || m < 0 || m >= 60 {
+			return 0, fmt.Errorf("hours %q not recognised", text)
+		}
+		return float64(h) + float64(m)/60, nil
+	}
+	hours, err := strconv.ParseFloat(text, 64)
+	if err != nil || hours <= 0 {
+		return 0, fmt.Errorf("hours %q not recognised", text)
+	}
+	return hours, nil
+}
+
+// parseAmount reads an amount of money, ignoring currency symbols and thousands separators.
+// A blank amount is zero.
+func parseAmount(text string) (float64, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			return r
+		}
+		return -1
+	}, text)
+	if cleaned == "" {
+		return 0, nil
+	}
+	amount, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, fmt.Errorf("amount %q not recognised", text)
+	}
+	return amount, nil
+}
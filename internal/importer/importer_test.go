@@ -0,0 +1,195 @@
+package importer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const harvestTime = `Date,Client,Project,Project Code,Task,Notes,Hours,Hours Rounded,Billable?,Invoiced?,First Name,Last Name,Billable Rate,Billable Amount,Currency
+2024-03-04,Acme Corp,Website,,Design,Homepage mockups,2.5,2.5,Yes,No,Jane,Doe,90,225,US Dollar - USD
+2024-03-05,Acme Corp,Website,,Development,,1.25,1.25,Yes,No,Jane,Doe,90,112.5,US Dollar - USD
+2024-03-06,Globex,,,Meeting,Kickoff,1,1,Yes,No,Jane,Doe,,,US Dollar - USD
+2024-03-07,Globex,,,Meeting,Bad hours,lots,1,Yes,No,Jane,Doe,,,US Dollar - USD
+`
+
+const freshBooksClients = "\ufeffOrganization,First Name,Last Name,Email,Phone,Street,City,Province/State,Country,Postal Code\n" +
+	"Acme Corp,Wile,Coyote,wile@acme.test,555-0100,1 Desert Rd,Tucson,AZ,United States,85701\n" +
+	",Ann,Smith,ann@example.test,,,,,,\n" +
+	",,,,,,,,,\n"
+
+const freshBooksInvoices = `Invoice #,Client Name,Date Issued,Due Date,Amount,Paid,Date Paid,Status
+0001,Acme Corp,03/01/2024,03/31/2024,"$1,250.00","$1,250.00",03/20/2024,paid
+0002,Acme Corp,04/01/2024,05/01/2024,$800.00,$300.00,04/15/2024,partial
+0003,Initech,2024-04-02,,$100.00,,,sent
+`
+
+func TestRead(t *testing.T) {
+	harvest, _ := PresetByName("harvest")
+	freshBooks, _ := PresetByName("freshbooks")
+
+	t.Run("Harvest time entries", func(t *testing.T) {
+		batch, err := Read(harvest, KindTimeEntries, strings.NewReader(harvestTime))
+		require.NoError(t, err)
+		require.Len(t, batch.TimeEntries, 3)
+
+		entry := batch.TimeEntries[0]
+		assert.Equal(t, "2024-03-04", entry.Date.Format("2006-01-02"))
+		assert.Equal(t, "Acme Corp", entry.Client)
+		assert.Equal(t, "Website", entry.Project)
+		assert.Equal(t, 2.5, entry.Hours)
+		assert.Equal(t, 90.0, entry.Rate)
+		assert.Equal(t, "Design: Homepage mockups", entry.Description)
+		assert.Equal(t, "Development", batch.TimeEntries[1].Description)
+		assert.Equal(t, []string{`Row 5: hours "lots" not recognised`}, batch.Problems)
+	})
+
+	t.Run("FreshBooks clients", func(t *testing.T) {
+		batch, err := Read(freshBooks, KindClients, strings.NewReader(freshBooksClients))
+		require.NoError(t, err)
+		require.Len(t, batch.Clients, 2)
+		assert.Equal(t, ClientRecord{Name: "Acme Corp", Email: "wile@acme.test", Phone: "555-0100", Address1: "1 Desert Rd",
+			City: "Tucson", State: "AZ", ZipCode: "85701", Country: "United States"}, batch.Clients[0])
+		assert.Equal(t, "Ann Smith", batch.Clients[1].Name, "contacts without an organization are named after the person")
+		assert.Empty(t, batch.Problems)
+	})
+
+	t.Run("FreshBooks invoices", func(t *testing.T) {
+		batch, err := Read(freshBooks, KindInvoices, strings.NewReader(freshBooksInvoices))
+		require.NoError(t, err)
+		require.Len(t, batch.Invoices, 3)
+
+		invoice := batch.Invoices[0]
+		assert.Equal(t, "0001", invoice.Number)
+		assert.Equal(t, "2024-03-01", invoice.IssueDate.Format("2006-01-02"))
+		require.NotNil(t, invoice.DueDate)
+		assert.Equal(t, "2024-03-31", invoice.DueDate.Format("2006-01-02"))
+		require.NotNil(t, invoice.PaidDate)
+		assert.Equal(t, 1250.0, invoice.Amount)
+		assert.Equal(t, 1250.0, invoice.AmountPaid)
+		assert.Nil(t, batch.Invoices[2].DueDate)
+	})
+
+	t.Run("rejects an export of the wrong kind", func(t *testing.T) {
+		_, err := Read(harvest, KindInvoices, strings.NewReader(harvestTime))
+		assert.ErrorContains(t, err, "no issue date column found")
+	})
+
+	t.Run("reads hours and amounts in other notations", func(t *testing.T) {
+		hours, err := parseHours("1:30")
+		require.NoError(t, err)
+		assert.Equal(t, 1.5, hours)
+		_, err = parseHours("0")
+		assert.Error(t, err)
+
+		amount, err := parseAmount("€ 1,234.50")
+		require.NoError(t, err)
+		assert.Equal(t, 1234.5, amount)
+	})
+}
+
+func TestApply(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	transactions := models.NewTxManager(testDB.DB)
+	existingID := testDB.InsertTestClient(t, "ACME Corp")
+	options := Options{HourlyRate: 75, Currency: "USD"}
+
+	apply := func(preset, data string, kind Kind) Summary {
+		p, _ := PresetByName(preset)
+		batch, err := Read(p, kind, strings.NewReader(data))
+		require.NoError(t, err)
+		var summary Summary
+		err = transactions.WithinTx(context.Background(), func(tx models.TxModels) error {
+			summary, err = Apply(tx, p, batch, options)
+			return err
+		})
+		require.NoError(t, err)
+		return summary
+	}
+
+	t.Run("clients are matched by name", func(t *testing.T) {
+		summary := apply("freshbooks", freshBooksClients, KindClients)
+		assert.Equal(t, 1, summary.ClientsCreated)
+		assert.Equal(t, 1, summary.Skipped)
+
+		clients, err := models.NewClientModel(testDB.DB).GetAll()
+		require.NoError(t, err)
+		require.Len(t, clients, 2)
+	})
+
+	t.Run("time entries create missing clients and projects", func(t *testing.T) {
+		summary := apply("harvest", harvestTime, KindTimeEntries)
+		assert.Equal(t, 1, summary.ClientsCreated)
+		assert.Equal(t, 2, summary.ProjectsCreated)
+		assert.Equal(t, 3, summary.TimesheetsCreated)
+		assert.Len(t, summary.Problems, 1)
+
+		projects, err := models.NewProjectModel(testDB.DB).GetByClient(existingID)
+		require.NoError(t, err)
+		require.Len(t, projects, 1)
+		assert.Equal(t, "Website", projects[0].Name)
+		assert.Equal(t, 90.0, projects[0].HourlyRate)
+
+		timesheets, err := models.NewTimesheetModel(testDB.DB).GetByProject(projects[0].ID)
+		require.NoError(t, err)
+		assert.Len(t, timesheets, 2)
+	})
+
+	t.Run("entries already imported are skipped", func(t *testing.T) {
+		summary := apply("harvest", harvestTime, KindTimeEntries)
+		assert.Zero(t, summary.TimesheetsCreated)
+		assert.Equal(t, 3, summary.Skipped)
+	})
+
+	t.Run("invoices keep their numbers, dates and payments", func(t *testing.T) {
+		summary := apply("freshbooks", freshBooksInvoices, KindInvoices)
+		assert.Equal(t, 3, summary.InvoicesCreated)
+		assert.Equal(t, 1, summary.ClientsCreated)
+
+		projects, err := models.NewProjectModel(testDB.DB).GetByClient(existingID)
+		require.NoError(t, err)
+		require.Len(t, projects, 2)
+		assert.Equal(t, "FreshBooks import", projects[1].Name)
+
+		invoices, err := models.NewInvoiceModel(testDB.DB).GetByProject(projects[1].ID)
+		require.NoError(t, err)
+		require.Len(t, invoices, 2)
+		paid, partial := invoices[1], invoices[0]
+		assert.Equal(t, "0001", paid.InvoiceNumber)
+		assert.Equal(t, "Net 30", paid.PaymentTerms)
+		require.NotNil(t, paid.DatePaid)
+		assert.Equal(t, "2024-03-20", paid.DatePaid.Format("2006-01-02"))
+		assert.Nil(t, partial.DatePaid, "a partly paid invoice isn't marked paid")
+		require.NotNil(t, partial.AmountPaid)
+		assert.Equal(t, 300.0, *partial.AmountPaid)
+
+		summary = apply("freshbooks", freshBooksInvoices, KindInvoices)
+		assert.Zero(t, summary.InvoicesCreated)
+		assert.Equal(t, 3, summary.Skipped)
+	})
+
+	t.Run("notes are cut to the longest description", func(t *testing.T) {
+		options.MaxDescription = 10
+		defer func() { options.MaxDescription = 0 }()
+
+		summary := apply("harvest", strings.Join([]string{
+			strings.SplitN(harvestTime, "\n", 2)[0],
+			"2024-03-08,Acme Corp,Website,,Design,Footer and contact page,1,1,Yes,No,Jane,Doe,90,90,US Dollar - USD",
+		}, "\n"), KindTimeEntries)
+		require.Equal(t, 1, summary.TimesheetsCreated)
+
+		projects, err := models.NewProjectModel(testDB.DB).GetByClient(existingID)
+		require.NoError(t, err)
+		timesheets, err := models.NewTimesheetModel(testDB.DB).GetByProject(projects[0].ID)
+		require.NoError(t, err)
+		require.Len(t, timesheets, 3)
+		assert.Equal(t, "Design: Fo", timesheets[0].Description, "the task and notes are cut together")
+	})
+}
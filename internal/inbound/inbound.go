@@ -0,0 +1,123 @@
+// Package inbound turns email sent to the application into timesheet entries. Messages
+// reach it through a Mailgun inbound route that forwards them to a webhook.
+package inbound
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSignature is returned for webhook requests that weren't signed with the signing key
+var ErrInvalidSignature = errors.New("inbound: invalid signature")
+
+// MaxSignatureAge bounds how old a signed request may be, so a captured request can't be replayed later
+const MaxSignatureAge = 15 * time.Minute
+
+// Entry is one log command found in an email, e.g. "log 2h ProjectX: reviewed manuscript"
+type Entry struct {
+	Hours       float64
+	Project     string
+	Description string
+}
+
+// commandPattern matches "log <duration> <project>: <description>", the description being optional
+var commandPattern = regexp.MustCompile(`(?i)^\s*log\s+(\S+)\s+([^:]+?)\s*(?::\s*(.*?))?\s*$`)
+
+// durationPattern matches durations such as "2h", "1.5h", "2h30m" and "45m", or a plain number of hours
+var durationPattern = regexp.MustCompile(`(?i)^(?:(\d+(?:\.\d+)?)h)?(?:(\d+)m)?$|^(\d+(?:\.\d+)?)$`)
+
+// Parse finds the log commands in text, one per line. Anything else, such as a greeting,
+// a signature or a command with an unreadable duration, is ignored.
+func Parse(text string) []Entry {
+	var entries []Entry
+	for _, line := range strings.Split(text, "\n") {
+		match := commandPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		hours, err := ParseHours(match[1])
+		if err != nil {
+			continue
+		}
+		entries = append(entries, Entry{
+			Hours:       hours,
+			Project:     match[2],
+			Description: match[3],
+		})
+	}
+	return entries
+}
+
+// ParseHours reads a duration like "2h", "1.5h", "2h30m", "45m" or "2" as a number of hours,
+// rounded to the nearest minute
+func ParseHours(value string) (float64, error) {
+	match := durationPattern.FindStringSubmatch(value)
+	if match == nil || (match[1] == "" && match[2] == "" && match[3] == "") {
+		return 0, fmt.Errorf("inbound: invalid duration %q", value)
+	}
+
+	var hours float64
+	if whole := match[1] + match[3]; whole != "" {
+		h, err := strconv.ParseFloat(whole, 64)
+		if err != nil {
+			return 0, fmt.Errorf("inbound: invalid duration %q", value)
+		}
+		hours = h
+	}
+	if match[2] != "" {
+		minutes, err := strconv.Atoi(match[2])
+		if err != nil {
+			return 0, fmt.Errorf("inbound: invalid duration %q", value)
+		}
+		hours += float64(minutes) / 60
+	}
+	if hours <= 0 {
+		return 0, fmt.Errorf("inbound: invalid duration %q", value)
+	}
+	return math.Round(hours*60) / 60, nil
+}
+
+// VerifyMailgun checks the signature Mailgun sends with each webhook, an HMAC-SHA256 of the
+// timestamp and token keyed with the account's webhook signing key
+func VerifyMailgun(signingKey, timestamp, token, signature string, now time.Time) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || token == "" {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > MaxSignatureAge || age < -MaxSignatureAge {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(timestamp + token))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// SameAddress reports whether two email addresses match, ignoring case and any display
+// name, so that "Paul <Paul@Example.com>" matches "paul@example.com"
+func SameAddress(a, b string) bool {
+	a, b = address(a), address(b)
+	return a != "" && a == b
+}
+
+// address returns the bare, lower-cased address from an email address or From header
+func address(value string) string {
+	parsed, err := mail.ParseAddress(value)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Address)
+}
@@ -0,0 +1,82 @@
+package inbound
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	text := `Hi,
+
+log 2h ProjectX: reviewed manuscript
+LOG 1h30m Website Redesign: call with client
+log 45m ProjectX
+log lots ProjectX: not a duration
+please log 2h ProjectX: not at the start of the line
+
+--
+Paul`
+
+	entries := Parse(text)
+	require.Len(t, entries, 3)
+	assert.Equal(t, Entry{Hours: 2, Project: "ProjectX", Description: "reviewed manuscript"}, entries[0])
+	assert.Equal(t, Entry{Hours: 1.5, Project: "Website Redesign", Description: "call with client"}, entries[1])
+	assert.Equal(t, Entry{Hours: 0.75, Project: "ProjectX"}, entries[2])
+}
+
+func TestParseHours(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected float64
+	}{
+		{"2h", 2},
+		{"1.5h", 1.5},
+		{"2h30m", 2.5},
+		{"45m", 0.75},
+		{"3", 3},
+		{"1H15M", 1.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			hours, err := ParseHours(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, hours)
+		})
+	}
+
+	for _, value := range []string{"", "h", "0h", "two", "2x", "Inf", "1e3"} {
+		_, err := ParseHours(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestVerifyMailgun(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	sign := func(key, timestamp, token string) string {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte(timestamp + token))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	assert.NoError(t, VerifyMailgun("key", timestamp, "token", sign("key", timestamp, "token"), now))
+	assert.ErrorIs(t, VerifyMailgun("key", timestamp, "token", sign("other", timestamp, "token"), now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyMailgun("key", timestamp, "token", "", now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyMailgun("key", "soon", "token", sign("key", "soon", "token"), now), ErrInvalidSignature)
+
+	stale := strconv.FormatInt(now.Add(-time.Hour).Unix(), 10)
+	assert.ErrorIs(t, VerifyMailgun("key", stale, "token", sign("key", stale, "token"), now), ErrInvalidSignature)
+}
+
+func TestSameAddress(t *testing.T) {
+	assert.True(t, SameAddress("Paul <Paul@Example.com>", "paul@example.com"))
+	assert.False(t, SameAddress("someone@example.com", "paul@example.com"))
+	assert.False(t, SameAddress("", ""))
+}
@@ -0,0 +1,161 @@
+// Package jobs runs long-running tasks, such as PDF generation, outside the request that
+// asked for them. Jobs are persisted so that those interrupted by a restart are run again.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
+)
+
+// DefaultPollInterval is how often an idle queue checks for jobs it wasn't told about
+const DefaultPollInterval = 30 * time.Second
+
+// DefaultRetention is how long finished jobs and their results are kept
+const DefaultRetention = 24 * time.Hour
+
+// Result is the output of a finished job, such as a generated PDF
+type Result struct {
+	Data        []byte
+	ContentType string
+	Filename    string
+}
+
+// HandlerFunc runs a job, given the JSON payload it was enqueued with
+type HandlerFunc func(ctx context.Context, payload []byte) (Result, error)
+
+// Queue hands queued jobs to the handler registered for their kind, one at a time
+type Queue struct {
+	jobs         models.JobModelInterface
+	logger       *slog.Logger
+	handlers     map[string]HandlerFunc
+	mu           sync.RWMutex
+	wake         chan struct{}
+	PollInterval time.Duration
+	Retention    time.Duration
+}
+
+// New creates a Queue that stores its jobs in jobs
+func New(jobs models.JobModelInterface, logger *slog.Logger) *Queue {
+	return &Queue{
+		jobs:         jobs,
+		logger:       logger,
+		handlers:     map[string]HandlerFunc{},
+		wake:         make(chan struct{}, 1),
+		PollInterval: DefaultPollInterval,
+		Retention:    DefaultRetention,
+	}
+}
+
+// Handle registers the handler that runs jobs of the given kind
+func (q *Queue) Handle(kind string, handler HandlerFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[kind] = handler
+}
+
+// Enqueue stores a job with payload encoded as JSON, wakes the queue and returns the job's ID
+func (q *Queue) Enqueue(kind string, payload any) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("jobs: encoding payload: %w", err)
+	}
+
+	id, err := q.jobs.Insert(kind, string(body))
+	if err != nil {
+		return 0, err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return id, nil
+}
+
+// RunNext claims the oldest queued job and runs it, reporting false when there was
+// nothing to run. A job whose handler fails, or panics, is marked failed.
+func (q *Queue) RunNext(ctx context.Context) (bool, error) {
+	job, err := q.jobs.ClaimNext()
+	if errors.Is(err, models.ErrNoRecord) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	result, err := q.run(ctx, job)
+	if err != nil {
+		q.logger.Error("job failed", "job_id", job.ID, "kind", job.Kind, "error", err.Error())
+		return true, q.jobs.Fail(job.ID, err.Error())
+	}
+	return true, q.jobs.Complete(job.ID, result.Data, result.ContentType, result.Filename)
+}
+
+// run calls the handler for a job, turning a panic into an error
+func (q *Queue) run(ctx context.Context, job models.Job) (result Result, err error) {
+	q.mu.RLock()
+	handler, ok := q.handlers[job.Kind]
+	q.mu.RUnlock()
+	if !ok {
+		return Result{}, fmt.Errorf("no handler for %s jobs", job.Kind)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, []byte(job.Payload))
+}
+
+// Run processes jobs until ctx is cancelled. Jobs left running by a previous server are
+// queued again first, and finished jobs older than the retention period are removed.
+func (q *Queue) Run(ctx context.Context) {
+	requeued, err := q.jobs.RequeueRunning()
+	if err != nil {
+		q.logger.Error("requeueing interrupted jobs", "error", err.Error())
+	} else if requeued > 0 {
+		q.logger.Info("requeued interrupted jobs", "count", requeued)
+	}
+
+	ticker := time.NewTicker(q.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		q.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-ticker.C:
+			removed, err := q.jobs.DeleteFinishedBefore(time.Now().Add(-q.Retention))
+			if err != nil {
+				q.logger.Error("removing finished jobs", "error", err.Error())
+			} else if removed > 0 {
+				q.logger.Info("removed finished jobs", "count", removed)
+			}
+		}
+	}
+}
+
+// drain runs queued jobs until there are none left
+func (q *Queue) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		ran, err := q.RunNext(ctx)
+		if err != nil {
+			q.logger.Error("running job", "error", err.Error())
+			return
+		}
+		if !ran {
+			return
+		}
+	}
+}
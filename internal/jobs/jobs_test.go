@@ -0,0 +1,131 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQueue(t *testing.T) (*Queue, *models.JobModel) {
+	testDB := testutil.SetupTestSQLite(t)
+	t.Cleanup(func() { testDB.Cleanup(t) })
+
+	store := models.NewJobModel(testDB.DB)
+	return New(store, slog.New(slog.NewTextHandler(io.Discard, nil))), store
+}
+
+func TestQueueRunNext(t *testing.T) {
+	queue, store := newTestQueue(t)
+
+	queue.Handle("echo", func(ctx context.Context, payload []byte) (Result, error) {
+		var params struct{ Text string }
+		require.NoError(t, json.Unmarshal(payload, &params))
+		return Result{Data: []byte(params.Text), ContentType: "text/plain", Filename: "echo.txt"}, nil
+	})
+	queue.Handle("fail", func(ctx context.Context, payload []byte) (Result, error) {
+		return Result{}, errors.New("out of paper")
+	})
+	queue.Handle("panic", func(ctx context.Context, payload []byte) (Result, error) {
+		panic("printer on fire")
+	})
+
+	run := func(kind string, payload any) models.Job {
+		id, err := queue.Enqueue(kind, payload)
+		require.NoError(t, err)
+
+		ran, err := queue.RunNext(context.Background())
+		require.NoError(t, err)
+		assert.True(t, ran)
+
+		job, err := store.Get(id)
+		require.NoError(t, err)
+		return job
+	}
+
+	t.Run("successful job stores its result", func(t *testing.T) {
+		job := run("echo", map[string]string{"text": "hello"})
+		assert.Equal(t, models.JobStatusDone, job.Status)
+		assert.Equal(t, []byte("hello"), job.Result)
+		assert.Equal(t, "text/plain", job.ResultType)
+		assert.Equal(t, "echo.txt", job.ResultName)
+	})
+
+	t.Run("failed job records the error", func(t *testing.T) {
+		job := run("fail", nil)
+		assert.Equal(t, models.JobStatusFailed, job.Status)
+		assert.Equal(t, "out of paper", job.Error)
+	})
+
+	t.Run("panicking job fails", func(t *testing.T) {
+		job := run("panic", nil)
+		assert.Equal(t, models.JobStatusFailed, job.Status)
+		assert.Equal(t, "panic: printer on fire", job.Error)
+	})
+
+	t.Run("job without a handler fails", func(t *testing.T) {
+		job := run("unknown", nil)
+		assert.Equal(t, models.JobStatusFailed, job.Status)
+		assert.Equal(t, "no handler for unknown jobs", job.Error)
+	})
+
+	t.Run("empty queue", func(t *testing.T) {
+		ran, err := queue.RunNext(context.Background())
+		require.NoError(t, err)
+		assert.False(t, ran)
+	})
+}
+
+func TestQueueRun(t *testing.T) {
+	queue, store := newTestQueue(t)
+
+	done := make(chan int, 2)
+	queue.Handle("echo", func(ctx context.Context, payload []byte) (Result, error) {
+		done <- len(payload)
+		return Result{Data: payload}, nil
+	})
+
+	// A job left running by a previous server is picked up again on start
+	interruptedID, err := store.Insert("echo", "{}")
+	require.NoError(t, err)
+	_, err = store.ClaimNext()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		queue.Run(ctx)
+		close(stopped)
+	}()
+
+	waitForJob := func() {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("job was not run")
+		}
+	}
+	waitForJob()
+
+	// Enqueueing wakes the idle queue rather than waiting for the next poll
+	id, err := queue.Enqueue("echo", map[string]int{"invoice_id": 3})
+	require.NoError(t, err)
+	waitForJob()
+
+	cancel()
+	<-stopped
+
+	for _, jobID := range []int{interruptedID, id} {
+		job, err := store.Get(jobID)
+		require.NoError(t, err)
+		assert.Equal(t, models.JobStatusDone, job.Status)
+	}
+}
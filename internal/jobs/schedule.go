@@ -0,0 +1,244 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/cron"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
+)
+
+// DefaultCheckInterval is how often the scheduler looks for tasks that have fallen due. Cron
+// schedules go no finer than a minute.
+const DefaultCheckInterval = time.Minute
+
+// Task is a job queued on a cron schedule. The schedule is read from the Setting each time
+// the scheduler checks, so a change made on the settings page applies straight away, and a
+// blank schedule switches the task off. Payload returns the job's payload for the time the
+// task fell due.
+type Task struct {
+	Kind    string
+	Setting string
+	Payload func(due time.Time) any
+}
+
+// ScheduleSettings is where the scheduler reads the schedules of its tasks from
+type ScheduleSettings interface {
+	GetString(key string) (string, error)
+}
+
+// TaskStatus describes a scheduled task for display: its schedule, when it last fell due
+// and the job it queued then, and when it next falls due. Next is nil while the task is
+// switched off or its schedule can't be read, which Error then explains.
+type TaskStatus struct {
+	Task
+	Schedule string
+	LastRun  *time.Time
+	LastJob  *models.Job
+	Next     *time.Time
+	Error    string
+}
+
+// Description names the job the task queues
+func (s TaskStatus) Description() string {
+	return models.Job{Kind: s.Kind}.Description()
+}
+
+// Scheduler queues jobs when the cron schedules of its tasks fall due. Schedules are in UTC.
+// When a task fell due while the server was stopped it is run once on starting, however
+// many times it was missed.
+type Scheduler struct {
+	queue    *Queue
+	runs     models.ScheduledTaskModelInterface
+	settings ScheduleSettings
+	logger   *slog.Logger
+	mu       sync.RWMutex
+	tasks    []Task
+	started  time.Time
+	Interval time.Duration
+}
+
+// NewScheduler creates a Scheduler that queues its jobs on queue, recording the runs of its
+// tasks in runs
+func NewScheduler(queue *Queue, runs models.ScheduledTaskModelInterface, settings ScheduleSettings, logger *slog.Logger) *Scheduler {
+	return &Scheduler{
+		queue:    queue,
+		runs:     runs,
+		settings: settings,
+		logger:   logger,
+		started:  time.Now().UTC(),
+		Interval: DefaultCheckInterval,
+	}
+}
+
+// Add schedules a task
+func (s *Scheduler) Add(task Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks = append(s.tasks, task)
+}
+
+// Tasks returns the scheduled tasks in the order they were added
+func (s *Scheduler) Tasks() []Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Task(nil), s.tasks...)
+}
+
+// Run checks for tasks that have fallen due until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.Check(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check queues a job for every task that has fallen due by now and returns how many it
+// queued. Tasks that can't be run have the reason recorded and are tried again next time.
+func (s *Scheduler) Check(now time.Time) int {
+	queued := 0
+	for _, task := range s.Tasks() {
+		ran, err := s.check(task, now.UTC())
+		if err != nil {
+			s.logger.Error("running scheduled task", "kind", task.Kind, "error", err.Error())
+			s.recordError(task, err)
+			continue
+		}
+		if ran {
+			queued++
+		}
+	}
+	return queued
+}
+
+// check queues the task's job when it has fallen due, reporting whether it did
+func (s *Scheduler) check(task Task, now time.Time) (bool, error) {
+	schedule, err := s.schedule(task)
+	if err != nil || schedule == nil {
+		return false, err
+	}
+	record, err := s.record(task)
+	if err != nil {
+		return false, err
+	}
+
+	due := schedule.Next(s.from(record))
+	if due.IsZero() || due.After(now) {
+		return false, nil
+	}
+	// Of the times missed, only the latest is run
+	for next := schedule.Next(due); !next.IsZero() && !next.After(now); next = schedule.Next(due) {
+		due = next
+	}
+
+	id, err := s.queue.Enqueue(task.Kind, task.Payload(due))
+	if err != nil {
+		return false, fmt.Errorf("queueing job: %w", err)
+	}
+	if err := s.runs.RecordRun(task.Kind, due, id); err != nil {
+		return true, err
+	}
+	s.logger.Info("queued scheduled task", "kind", task.Kind, "due", due.Format(time.RFC3339), "job_id", id)
+	return true, nil
+}
+
+// schedule reads and parses a task's schedule, returning nil when it is switched off
+func (s *Scheduler) schedule(task Task) (*cron.Schedule, error) {
+	expr, err := s.settings.GetString(task.Setting)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", task.Setting, err)
+	}
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	schedule, err := cron.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", task.Setting, err)
+	}
+	return &schedule, nil
+}
+
+// record returns the record of a task's runs, which is empty before its first
+func (s *Scheduler) record(task Task) (models.ScheduledTask, error) {
+	record, err := s.runs.Get(task.Kind)
+	if errors.Is(err, models.ErrNoRecord) {
+		return models.ScheduledTask{Name: task.Kind}, nil
+	}
+	return record, err
+}
+
+// from returns the time a task's schedule is counted from: its last run, or when the
+// scheduler started for a task that has never run
+func (s *Scheduler) from(record models.ScheduledTask) time.Time {
+	if record.LastRun != nil {
+		return record.LastRun.UTC()
+	}
+	return s.started
+}
+
+// recordError stores why a task couldn't be run, unless it is already stored, so a bad
+// schedule isn't written again every time the scheduler checks
+func (s *Scheduler) recordError(task Task, cause error) {
+	record, err := s.record(task)
+	if err == nil && record.LastError == cause.Error() {
+		return
+	}
+	if err := s.runs.RecordError(task.Kind, cause.Error()); err != nil {
+		s.logger.Error("recording scheduled task error", "kind", task.Kind, "error", err.Error())
+	}
+}
+
+// Status describes every scheduled task as of now
+func (s *Scheduler) Status(now time.Time) ([]TaskStatus, error) {
+	var statuses []TaskStatus
+	for _, task := range s.Tasks() {
+		record, err := s.record(task)
+		if err != nil {
+			return nil, err
+		}
+		status := TaskStatus{Task: task, LastRun: record.LastRun, Error: record.LastError}
+
+		if record.LastJobID != nil {
+			job, err := s.queue.jobs.Get(*record.LastJobID)
+			switch {
+			case err == nil:
+				status.LastJob = &job
+			case !errors.Is(err, models.ErrNoRecord):
+				return nil, err
+			}
+		}
+
+		status.Schedule, err = s.settings.GetString(task.Setting)
+		if err != nil {
+			return nil, err
+		}
+		schedule, err := s.schedule(task)
+		if err != nil {
+			status.Error = err.Error()
+		} else if schedule != nil {
+			from := s.from(record)
+			if next := schedule.Next(from); !next.IsZero() {
+				// A time already passed is run at the next check
+				if next.Before(now) {
+					next = now.UTC().Truncate(time.Minute).Add(s.Interval)
+				}
+				status.Next = &next
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
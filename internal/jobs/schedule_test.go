@@ -0,0 +1,110 @@
+package jobs
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/models"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSettings holds schedules in memory
+type stubSettings map[string]string
+
+func (s stubSettings) GetString(key string) (string, error) {
+	return s[key], nil
+}
+
+func TestScheduler(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	t.Cleanup(func() { testDB.Cleanup(t) })
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := models.NewJobModel(testDB.DB)
+	runs := models.NewScheduledTaskModel(testDB.DB)
+	settings := stubSettings{"schedule_monthly": "0 0 1 * *", "schedule_off": "", "schedule_bad": "0 0 32 * *"}
+
+	at := func(value string) time.Time {
+		t, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+
+	scheduler := NewScheduler(New(store, logger), runs, settings, logger)
+	scheduler.started = at("2024-04-20 10:00")
+	monthly := Task{Kind: "monthly", Setting: "schedule_monthly", Payload: func(due time.Time) any {
+		return map[string]string{"month": due.AddDate(0, -1, 0).Format("2006-01")}
+	}}
+	scheduler.Add(monthly)
+	scheduler.Add(Task{Kind: "off", Setting: "schedule_off", Payload: func(time.Time) any { return nil }})
+	scheduler.Add(Task{Kind: "bad", Setting: "schedule_bad", Payload: func(time.Time) any { return nil }})
+
+	lastJob := func(t *testing.T, kind string) models.Job {
+		record, err := runs.Get(kind)
+		require.NoError(t, err)
+		require.NotNil(t, record.LastJobID)
+		job, err := store.Get(*record.LastJobID)
+		require.NoError(t, err)
+		return job
+	}
+
+	t.Run("nothing is queued before a task falls due", func(t *testing.T) {
+		assert.Equal(t, 0, scheduler.Check(at("2024-04-30 23:59")))
+		_, err := runs.Get("monthly")
+		assert.ErrorIs(t, err, models.ErrNoRecord)
+	})
+
+	t.Run("a task is queued once when it falls due", func(t *testing.T) {
+		assert.Equal(t, 1, scheduler.Check(at("2024-05-01 00:00")))
+		assert.Equal(t, 0, scheduler.Check(at("2024-05-01 00:01")))
+
+		job := lastJob(t, "monthly")
+		assert.Equal(t, "monthly", job.Kind)
+		assert.JSONEq(t, `{"month": "2024-04"}`, job.Payload)
+
+		record, err := runs.Get("monthly")
+		require.NoError(t, err)
+		assert.Equal(t, at("2024-05-01 00:00"), record.LastRun.UTC())
+	})
+
+	t.Run("missed runs are made up once", func(t *testing.T) {
+		assert.Equal(t, 1, scheduler.Check(at("2024-08-15 09:30")))
+		assert.JSONEq(t, `{"month": "2024-07"}`, lastJob(t, "monthly").Payload)
+		assert.Equal(t, 0, scheduler.Check(at("2024-08-15 09:31")))
+	})
+
+	t.Run("a bad schedule is recorded", func(t *testing.T) {
+		record, err := runs.Get("bad")
+		require.NoError(t, err)
+		assert.Contains(t, record.LastError, "schedule_bad: day of month 32")
+		assert.Nil(t, record.LastRun)
+
+		_, err = runs.Get("off")
+		assert.ErrorIs(t, err, models.ErrNoRecord, "a task switched off is left alone")
+	})
+
+	t.Run("status", func(t *testing.T) {
+		statuses, err := scheduler.Status(at("2024-08-15 09:31"))
+		require.NoError(t, err)
+		require.Len(t, statuses, 3)
+
+		assert.Equal(t, "0 0 1 * *", statuses[0].Schedule)
+		require.NotNil(t, statuses[0].Next)
+		assert.Equal(t, at("2024-09-01 00:00"), *statuses[0].Next)
+		require.NotNil(t, statuses[0].LastJob)
+		assert.Equal(t, models.JobStatusQueued, statuses[0].LastJob.Status)
+		assert.Empty(t, statuses[0].Error)
+
+		assert.Nil(t, statuses[1].Next, "a blank schedule switches the task off")
+		assert.Empty(t, statuses[1].Error)
+
+		assert.Nil(t, statuses[2].Next)
+		assert.Contains(t, statuses[2].Error, "day of month 32")
+	})
+}
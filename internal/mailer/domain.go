@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ErrUndeliverable is returned by CheckDomain when an address's domain can't receive mail
+var ErrUndeliverable = errors.New("mailer: the domain can't receive mail")
+
+// Resolver looks up the DNS records CheckDomain needs. *net.Resolver satisfies it.
+type Resolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// CheckDomain looks up whether the domain of address can receive mail. It returns an error
+// wrapping ErrUndeliverable when the domain doesn't exist, publishes a null MX record saying
+// it takes no mail, or has neither mail servers nor an address that mail could be delivered
+// to directly. Any other error means the lookup itself failed, such as the DNS server timing
+// out, and says nothing about the address.
+func CheckDomain(ctx context.Context, resolver Resolver, address string) error {
+	at := strings.LastIndex(address, "@")
+	if at < 0 || at == len(address)-1 {
+		return fmt.Errorf("%w: %q has no domain", ErrUndeliverable, address)
+	}
+	domain := strings.TrimSuffix(address[at+1:], ".")
+
+	servers, err := resolver.LookupMX(ctx, domain)
+	if err != nil && !notFound(err) {
+		return fmt.Errorf("mailer: looking up mail servers for %s: %w", domain, err)
+	}
+	if len(servers) == 1 && (servers[0].Host == "." || servers[0].Host == "") {
+		return fmt.Errorf("%w: %s says it accepts no mail", ErrUndeliverable, domain)
+	}
+	if len(servers) > 0 {
+		return nil
+	}
+
+	// Without MX records mail is delivered to the domain's own address, if it has one
+	hosts, err := resolver.LookupHost(ctx, domain)
+	if err != nil && !notFound(err) {
+		return fmt.Errorf("mailer: looking up %s: %w", domain, err)
+	}
+	if len(hosts) == 0 {
+		return fmt.Errorf("%w: %s has no mail servers", ErrUndeliverable, domain)
+	}
+	return nil
+}
+
+// notFound reports whether a lookup failed because there are no such records, rather than
+// because the DNS server couldn't be asked
+func notFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeResolver answers lookups from its maps, with a not found error for any other name
+type fakeResolver struct {
+	mx    map[string][]*net.MX
+	hosts map[string][]string
+	err   error
+}
+
+func (r fakeResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if servers, ok := r.mx[name]; ok {
+		return servers, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if addresses, ok := r.hosts[host]; ok {
+		return addresses, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestCheckDomain(t *testing.T) {
+	resolver := fakeResolver{
+		mx: map[string][]*net.MX{
+			"example.com":  {{Host: "mx1.example.com.", Pref: 10}},
+			"nomail.test":  {{Host: ".", Pref: 0}},
+			"hostonly.net": {},
+		},
+		hosts: map[string][]string{"hostonly.net": {"192.0.2.1"}},
+	}
+	ctx := context.Background()
+
+	assert.NoError(t, CheckDomain(ctx, resolver, "jane@example.com"))
+	assert.NoError(t, CheckDomain(ctx, resolver, "jane@example.com."), "a trailing dot is the same domain")
+	assert.NoError(t, CheckDomain(ctx, resolver, "jane@hostonly.net"), "mail goes to the domain's address without MX records")
+
+	for _, address := range []string{"jane@exmaple.com", "jane@nomail.test", "jane@", "jane"} {
+		err := CheckDomain(ctx, resolver, address)
+		assert.ErrorIs(t, err, ErrUndeliverable, address)
+	}
+
+	t.Run("a failed lookup says nothing about the address", func(t *testing.T) {
+		failing := fakeResolver{err: &net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true}}
+		err := CheckDomain(ctx, failing, "jane@example.com")
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrUndeliverable))
+	})
+}
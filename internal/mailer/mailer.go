@@ -0,0 +1,299 @@
+// Package mailer sends email, such as invoices to clients, through an SMTP server
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds how long sending one message may take
+const DefaultTimeout = 30 * time.Second
+
+// Attachment is a file sent along with a message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is an email to send. HTML is optional; Text is always sent, for mail programs
+// that don't show HTML.
+type Message struct {
+	From        string
+	To          []string
+	Cc          []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Recipients returns everyone the message is addressed to
+func (m Message) Recipients() []string {
+	return append(append([]string{}, m.To...), m.Cc...)
+}
+
+// Sender delivers messages. Send returns the server's reply to the message, such as
+// "250 2.0.0 Ok: queued as 4Hx2", which is also set when the server refused it.
+type Sender interface {
+	Send(ctx context.Context, msg Message) (string, error)
+}
+
+// SMTP sends messages through an SMTP server. Port 465 is spoken to over TLS from the start;
+// on other ports the connection is upgraded with STARTTLS whenever the server offers it.
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Timeout  time.Duration
+}
+
+// Send delivers msg through the server
+func (s SMTP) Send(ctx context.Context, msg Message) (string, error) {
+	body, err := msg.Bytes()
+	if err != nil {
+		return "", err
+	}
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return "", fmt.Errorf("mailer: from address: %w", err)
+	}
+
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	port := s.Port
+	if port == 0 {
+		port = 587
+	}
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.Host, strconv.Itoa(port)))
+	if err != nil {
+		return "", fmt.Errorf("mailer: connecting to %s: %w", s.Host, err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	if port == 465 {
+		conn = tls.Client(conn, &tls.Config{ServerName: s.Host})
+	}
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return reply(err), fmt.Errorf("mailer: %w", err)
+	}
+	defer client.Close()
+
+	if port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: s.Host}); err != nil {
+				return reply(err), fmt.Errorf("mailer: starting TLS: %w", err)
+			}
+		}
+	}
+	if s.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return reply(err), fmt.Errorf("mailer: logging in: %w", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return reply(err), fmt.Errorf("mailer: sender refused: %w", err)
+	}
+	for _, recipient := range msg.Recipients() {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return "", fmt.Errorf("mailer: recipient %q: %w", recipient, err)
+		}
+		if err := client.Rcpt(address.Address); err != nil {
+			return reply(err), fmt.Errorf("mailer: recipient %s refused: %w", address.Address, err)
+		}
+	}
+
+	response, err := data(client.Text, body)
+	if err != nil {
+		return reply(err), fmt.Errorf("mailer: message refused: %w", err)
+	}
+	client.Quit()
+	return response, nil
+}
+
+// data sends the message itself, returning the server's reply. smtp.Client.Data discards
+// the reply, which holds the queue ID needed to trace a message through the server's logs.
+func data(text *textproto.Conn, body []byte) (string, error) {
+	id, err := text.Cmd("DATA")
+	if err != nil {
+		return "", err
+	}
+	text.StartResponse(id)
+	_, _, err = text.ReadResponse(354)
+	text.EndResponse(id)
+	if err != nil {
+		return "", err
+	}
+
+	w := text.DotWriter()
+	if _, err := w.Write(body); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	code, message, err := text.ReadResponse(250)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d %s", code, message), nil
+}
+
+// reply returns the server's reply carried by an error, if it has one
+func reply(err error) string {
+	var protocolErr *textproto.Error
+	if errors.As(err, &protocolErr) {
+		return fmt.Sprintf("%d %s", protocolErr.Code, protocolErr.Msg)
+	}
+	return ""
+}
+
+// Bytes encodes the message as a MIME document ready to send
+func (m Message) Bytes() ([]byte, error) {
+	if len(m.To) == 0 {
+		return nil, errors.New("mailer: message has no recipients")
+	}
+
+	var buf bytes.Buffer
+	header := textproto.MIMEHeader{}
+	header.Set("From", m.From)
+	header.Set("To", strings.Join(m.To, ", "))
+	if len(m.Cc) > 0 {
+		header.Set("Cc", strings.Join(m.Cc, ", "))
+	}
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	messageID, err := newMessageID(m.From)
+	if err != nil {
+		return nil, err
+	}
+	header.Set("Message-ID", messageID)
+	header.Set("MIME-Version", "1.0")
+
+	mixed := multipart.NewWriter(&buf)
+	header.Set("Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	writeHeader(&buf, header, []string{"From", "To", "Cc", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type"})
+
+	// The text and HTML bodies are alternatives, of which mail programs show the best they can
+	var alternative bytes.Buffer
+	bodies := multipart.NewWriter(&alternative)
+	if err := writeText(bodies, "text/plain; charset=utf-8", m.Text); err != nil {
+		return nil, err
+	}
+	if m.HTML != "" {
+		if err := writeText(bodies, "text/html; charset=utf-8", m.HTML); err != nil {
+			return nil, err
+		}
+	}
+	if err := bodies.Close(); err != nil {
+		return nil, err
+	}
+	part, err := mixed.CreatePart(textproto.MIMEHeader{"Content-Type": {"multipart/alternative; boundary=" + bodies.Boundary()}})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(alternative.Bytes()); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range m.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(part, attachment.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeHeader writes header fields in the given order, followed by the blank line ending them
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader, order []string) {
+	for _, key := range order {
+		if value := header.Get(key); value != "" {
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+// writeText adds a quoted-printable text part
+func writeText(w *multipart.Writer, contentType, text string) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeBase64 writes data base64 encoded in lines of 76 characters, as MIME requires
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := min(76, len(encoded))
+		if _, err := fmt.Fprintf(w, "%s\r\n", encoded[:n]); err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
+
+// newMessageID makes a unique Message-ID in the domain of the sender's address
+func newMessageID(from string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	domain := "localhost"
+	if address, err := mail.ParseAddress(from); err == nil {
+		if _, host, ok := strings.Cut(address.Address, "@"); ok {
+			domain = host
+		}
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(random), domain), nil
+}
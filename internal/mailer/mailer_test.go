@@ -0,0 +1,166 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer answers just enough SMTP to take one message, refusing recipients at refuse.test
+type fakeServer struct {
+	listener   net.Listener
+	recipients []string
+	data       string
+}
+
+func startFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &fakeServer{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		write := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+		write("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"):
+				write("250-fake")
+				write("250 8BITMIME")
+			case strings.HasPrefix(command, "MAIL FROM"):
+				write("250 2.1.0 Ok")
+			case strings.HasPrefix(command, "RCPT TO"):
+				if strings.Contains(command, "REFUSE.TEST") {
+					write("550 5.1.1 Recipient address rejected: User unknown")
+					continue
+				}
+				server.recipients = append(server.recipients, strings.TrimSpace(line[len("RCPT TO:"):]))
+				write("250 2.1.5 Ok")
+			case command == "DATA":
+				write("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				server.data = data.String()
+				write("250 2.0.0 Ok: queued as 4Hx2")
+			case command == "QUIT":
+				write("221 2.0.0 Bye")
+				return
+			default:
+				write("502 5.5.2 Error: command not recognized")
+			}
+		}
+	}()
+	return server
+}
+
+func (s *fakeServer) smtp() SMTP {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return SMTP{Host: host, Port: p}
+}
+
+func testMessage() Message {
+	return Message{
+		From:        (&mail.Address{Name: "Jane Doe", Address: "jane@example.com"}).String(),
+		To:          []string{"client@example.com"},
+		Cc:          []string{"accounts@example.com"},
+		Subject:     "Invoice INV-0042 – March",
+		Text:        "Please find the invoice attached.",
+		HTML:        "<p>Please find the invoice attached.</p>",
+		Attachments: []Attachment{{Filename: "INV-0042.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4 test")}},
+	}
+}
+
+func TestSMTP_Send(t *testing.T) {
+	t.Run("delivers the message and returns the server's reply", func(t *testing.T) {
+		server := startFakeServer(t)
+		response, err := server.smtp().Send(context.Background(), testMessage())
+		require.NoError(t, err)
+
+		assert.Equal(t, "250 2.0.0 Ok: queued as 4Hx2", response)
+		assert.Equal(t, []string{"<client@example.com>", "<accounts@example.com>"}, server.recipients)
+		assert.Contains(t, server.data, "Subject: =?utf-8?q?Invoice_INV-0042_=E2=80=93_March?=")
+	})
+
+	t.Run("a refused recipient returns the server's reply with the error", func(t *testing.T) {
+		server := startFakeServer(t)
+		msg := testMessage()
+		msg.To = []string{"nobody@refuse.test"}
+
+		response, err := server.smtp().Send(context.Background(), msg)
+		assert.Error(t, err)
+		assert.Equal(t, "550 5.1.1 Recipient address rejected: User unknown", response)
+	})
+
+	t.Run("an unreachable server is an error without a reply", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().String()
+		listener.Close()
+		host, port, _ := net.SplitHostPort(addr)
+		p, _ := strconv.Atoi(port)
+
+		response, err := SMTP{Host: host, Port: p}.Send(context.Background(), testMessage())
+		assert.Error(t, err)
+		assert.Empty(t, response)
+	})
+}
+
+func TestMessage_Bytes(t *testing.T) {
+	body, err := testMessage().Bytes()
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(body)))
+	require.NoError(t, err)
+	assert.Equal(t, `"Jane Doe" <jane@example.com>`, msg.Header.Get("From"))
+	assert.Equal(t, "accounts@example.com", msg.Header.Get("Cc"))
+	assert.Contains(t, msg.Header.Get("Message-ID"), "@example.com>")
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	bodies, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.Contains(t, bodies.Header.Get("Content-Type"), "multipart/alternative")
+
+	attachment, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "INV-0042.pdf", attachment.FileName())
+	data, err := io.ReadAll(attachment)
+	require.NoError(t, err)
+	assert.Equal(t, "JVBERi0xLjQgdGVzdA==\r\n", string(data))
+
+	_, err = Message{From: "jane@example.com"}.Bytes()
+	assert.Error(t, err, "a message needs a recipient")
+}
@@ -0,0 +1,148 @@
+// Package markdown formats the small part of Markdown that timesheet descriptions are
+// written in: paragraphs and line breaks, bulleted and numbered lists, and **bold**,
+// *italic* and `code` spans. Anything else is shown as the text it was typed as, and
+// HTML in the text is always escaped.
+package markdown
+
+import (
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kind is what sort of line a Line is
+type Kind string
+
+// The kinds of line a description is made of
+const (
+	Text     Kind = "text"
+	Bullet   Kind = "bullet"
+	Numbered Kind = "numbered"
+)
+
+// Line is one line of formatted text. Number is the number a numbered list item was
+// written with, and HTML is the line's text with its spans formatted and the list
+// marker removed.
+type Line struct {
+	Kind   Kind
+	Number int
+	HTML   template.HTML
+}
+
+var (
+	bulletPattern   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	numberedPattern = regexp.MustCompile(`^\s*(\d{1,9})[.)]\s+(.*)$`)
+	boldPattern     = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`)
+	italicPattern   = regexp.MustCompile(`\*(\S(?:.*?\S)?)\*`)
+)
+
+// Lines splits text into formatted lines, leaving out blank ones, for layouts such as an
+// invoice table cell that place each line themselves
+func Lines(text string) []Line {
+	var lines []Line
+	for _, line := range parse(text) {
+		if line != nil {
+			lines = append(lines, *line)
+		}
+	}
+	return lines
+}
+
+// HTML formats text as HTML: lines separated by blank lines become paragraphs, with line
+// breaks kept inside them, and list items are gathered into lists
+func HTML(text string) template.HTML {
+	var b strings.Builder
+	var open Kind
+	closeBlock := func() {
+		switch open {
+		case Text:
+			b.WriteString("</p>")
+		case Bullet:
+			b.WriteString("</ul>")
+		case Numbered:
+			b.WriteString("</ol>")
+		}
+		open = ""
+	}
+
+	for _, line := range parse(text) {
+		if line == nil {
+			closeBlock()
+			continue
+		}
+		if line.Kind != open {
+			closeBlock()
+			switch line.Kind {
+			case Text:
+				b.WriteString("<p>")
+			case Bullet:
+				b.WriteString("<ul>")
+			case Numbered:
+				if line.Number == 1 {
+					b.WriteString("<ol>")
+				} else {
+					b.WriteString(`<ol start="` + strconv.Itoa(line.Number) + `">`)
+				}
+			}
+			open = line.Kind
+		} else if line.Kind == Text {
+			b.WriteString("<br>")
+		}
+
+		if line.Kind == Text {
+			b.WriteString(string(line.HTML))
+		} else {
+			b.WriteString("<li>" + string(line.HTML) + "</li>")
+		}
+	}
+	closeBlock()
+	return template.HTML(b.String())
+}
+
+// parse reads text a line at a time, giving nil for blank lines
+func parse(text string) []*Line {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var lines []*Line
+	for _, raw := range strings.Split(text, "\n") {
+		if strings.TrimSpace(raw) == "" {
+			lines = append(lines, nil)
+			continue
+		}
+		if m := bulletPattern.FindStringSubmatch(raw); m != nil {
+			lines = append(lines, &Line{Kind: Bullet, HTML: inline(m[1])})
+			continue
+		}
+		if m := numberedPattern.FindStringSubmatch(raw); m != nil {
+			number, _ := strconv.Atoi(m[1])
+			lines = append(lines, &Line{Kind: Numbered, Number: number, HTML: inline(m[2])})
+			continue
+		}
+		lines = append(lines, &Line{Kind: Text, HTML: inline(strings.TrimSpace(raw))})
+	}
+	return lines
+}
+
+// inline escapes a line and formats its code, bold and italic spans. Text inside a code
+// span is left as typed.
+func inline(text string) template.HTML {
+	parts := strings.Split(text, "`")
+	if len(parts)%2 == 0 {
+		// The last backtick has no partner, so it is just a backtick
+		parts[len(parts)-2] += "`" + parts[len(parts)-1]
+		parts = parts[:len(parts)-1]
+	}
+
+	var b strings.Builder
+	for i, part := range parts {
+		escaped := template.HTMLEscapeString(part)
+		if i%2 == 1 {
+			b.WriteString("<code>" + escaped + "</code>")
+			continue
+		}
+		escaped = boldPattern.ReplaceAllString(escaped, "<strong>$1</strong>")
+		escaped = italicPattern.ReplaceAllString(escaped, "<em>$1</em>")
+		b.WriteString(escaped)
+	}
+	return template.HTML(b.String())
+}
@@ -0,0 +1,45 @@
+package markdown
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want template.HTML
+	}{
+		{"plain text is one paragraph", "Edited chapter 3", "<p>Edited chapter 3</p>"},
+		{"line breaks are kept", "Edited chapter 3\nChecked references", "<p>Edited chapter 3<br>Checked references</p>"},
+		{"blank lines separate paragraphs", "Morning\n\nAfternoon", "<p>Morning</p><p>Afternoon</p>"},
+		{"bullets become a list", "Did:\n- intro\n* methods\n+ results", "<p>Did:</p><ul><li>intro</li><li>methods</li><li>results</li></ul>"},
+		{"numbered lists keep their start", "3. third\n4) fourth", `<ol start="3"><li>third</li><li>fourth</li></ol>`},
+		{"lists switch kind", "1. one\n- loose", "<ol><li>one</li></ol><ul><li>loose</li></ul>"},
+		{"spans are formatted", "**Bold** and *italic* and `co*de*`", "<p><strong>Bold</strong> and <em>italic</em> and <code>co*de*</code></p>"},
+		{"italic inside bold", "**very *much* so**", "<p><strong>very <em>much</em> so</strong></p>"},
+		{"lone markers are left alone", "5 * 3 and a ` tick", "<p>5 * 3 and a ` tick</p>"},
+		{"HTML is escaped", "<script>alert(1)</script> & `<b>`", "<p>&lt;script&gt;alert(1)&lt;/script&gt; &amp; <code>&lt;b&gt;</code></p>"},
+		{"windows line endings", "a\r\nb", "<p>a<br>b</p>"},
+		{"empty text", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, HTML(tt.text))
+		})
+	}
+}
+
+func TestLines(t *testing.T) {
+	lines := Lines("Reviewed **draft**\n\n- fixed tables\n2. sent back")
+	assert.Equal(t, []Line{
+		{Kind: Text, HTML: "Reviewed <strong>draft</strong>"},
+		{Kind: Bullet, HTML: "fixed tables"},
+		{Kind: Numbered, Number: 2, HTML: "sent back"},
+	}, lines)
+
+	assert.Empty(t, Lines(" \n "))
+}
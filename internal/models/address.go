@@ -0,0 +1,173 @@
+package models
+
+import "strings"
+
+// addressLayout is the way a country lays out the city, state and postcode of an address
+type addressLayout int
+
+const (
+	// layoutCityStatePostcode puts "City, ST 12345" on one line, as in the US and Canada
+	layoutCityStatePostcode addressLayout = iota
+	// layoutCityStatePostcodeNoComma puts "City ST 1234" on one line, as in Australia
+	layoutCityStatePostcodeNoComma
+	// layoutCityPostcode puts "City 1234" on one line and leaves out the state
+	layoutCityPostcode
+	// layoutPostcodeCity puts "12345 City" on one line and leaves out the state, as in most
+	// of continental Europe
+	layoutPostcodeCity
+	// layoutPostcodeCityProvince puts "12345 City PR" on one line, as in Italy
+	layoutPostcodeCityProvince
+	// layoutCityThenPostcode puts the city and the postcode on lines of their own and leaves
+	// out the county, as in the UK and Ireland
+	layoutCityThenPostcode
+)
+
+// Country is a country a client's address can be in
+type Country struct {
+	Code   string
+	Name   string
+	layout addressLayout
+}
+
+// Countries lists the countries a client can be given, ordered by name
+var Countries = []Country{
+	{"AU", "Australia", layoutCityStatePostcodeNoComma},
+	{"AT", "Austria", layoutPostcodeCity},
+	{"BE", "Belgium", layoutPostcodeCity},
+	{"BG", "Bulgaria", layoutPostcodeCity},
+	{"CA", "Canada", layoutCityStatePostcode},
+	{"HR", "Croatia", layoutPostcodeCity},
+	{"CZ", "Czechia", layoutPostcodeCity},
+	{"DK", "Denmark", layoutPostcodeCity},
+	{"EE", "Estonia", layoutPostcodeCity},
+	{"FI", "Finland", layoutPostcodeCity},
+	{"FR", "France", layoutPostcodeCity},
+	{"DE", "Germany", layoutPostcodeCity},
+	{"GR", "Greece", layoutPostcodeCity},
+	{"HU", "Hungary", layoutPostcodeCity},
+	{"IS", "Iceland", layoutPostcodeCity},
+	{"IE", "Ireland", layoutCityThenPostcode},
+	{"IL", "Israel", layoutPostcodeCity},
+	{"IT", "Italy", layoutPostcodeCityProvince},
+	{"LV", "Latvia", layoutPostcodeCity},
+	{"LT", "Lithuania", layoutPostcodeCity},
+	{"LU", "Luxembourg", layoutPostcodeCity},
+	{"NL", "Netherlands", layoutPostcodeCity},
+	{"NZ", "New Zealand", layoutCityPostcode},
+	{"NO", "Norway", layoutPostcodeCity},
+	{"PL", "Poland", layoutPostcodeCity},
+	{"PT", "Portugal", layoutPostcodeCity},
+	{"RO", "Romania", layoutPostcodeCity},
+	{"SG", "Singapore", layoutCityPostcode},
+	{"SK", "Slovakia", layoutPostcodeCity},
+	{"SI", "Slovenia", layoutPostcodeCity},
+	{"ZA", "South Africa", layoutCityThenPostcode},
+	{"ES", "Spain", layoutPostcodeCity},
+	{"SE", "Sweden", layoutPostcodeCity},
+	{"CH", "Switzerland", layoutPostcodeCity},
+	{"GB", "United Kingdom", layoutCityThenPostcode},
+	{"US", "United States", layoutCityStatePostcode},
+}
+
+// LookupCountry finds a country by its ISO 3166 code
+func LookupCountry(code string) (Country, bool) {
+	for _, country := range Countries {
+		if strings.EqualFold(country.Code, code) {
+			return country, true
+		}
+	}
+	return Country{}, false
+}
+
+// FormatAddress returns the lines of a postal address laid out the way the given country
+// expects. Blank parts are left out. Addresses without a country keep the US layout they
+// have always been printed with, while those with one end with the country's name.
+func FormatAddress(street []string, city, state, postcode, countryCode string) []string {
+	var lines []string
+	for _, line := range street {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	city, state, postcode = strings.TrimSpace(city), strings.TrimSpace(state), strings.TrimSpace(postcode)
+
+	country, known := LookupCountry(strings.TrimSpace(countryCode))
+	if !known {
+		country.layout = layoutCityStatePostcode
+	}
+
+	switch country.layout {
+	case layoutCityStatePostcode:
+		locality := city
+		if city != "" && state != "" {
+			locality += ", "
+		}
+		lines = appendLine(lines, locality+state, postcode)
+	case layoutCityStatePostcodeNoComma:
+		lines = appendLine(lines, city, state, postcode)
+	case layoutCityPostcode:
+		lines = appendLine(lines, city, postcode)
+	case layoutPostcodeCity:
+		lines = appendLine(lines, postcode, city)
+	case layoutPostcodeCityProvince:
+		lines = appendLine(lines, postcode, city, state)
+	case layoutCityThenPostcode:
+		lines = appendLine(lines, city)
+		lines = appendLine(lines, postcode)
+	}
+
+	switch {
+	case known:
+		lines = append(lines, country.Name)
+	case strings.TrimSpace(countryCode) != "":
+		lines = append(lines, strings.TrimSpace(countryCode))
+	}
+	return lines
+}
+
+// appendLine joins the non-blank parts with spaces and appends them as a line, if any
+func appendLine(lines []string, parts ...string) []string {
+	var nonBlank []string
+	for _, part := range parts {
+		if part != "" {
+			nonBlank = append(nonBlank, part)
+		}
+	}
+	if len(nonBlank) == 0 {
+		return lines
+	}
+	return append(lines, strings.Join(nonBlank, " "))
+}
+
+// AddressLines returns the client's postal address laid out for its country
+func (c Client) AddressLines() []string {
+	return FormatAddress(
+		[]string{derefString(c.Address1), derefString(c.Address2), derefString(c.Address3)},
+		derefString(c.City), derefString(c.State), derefString(c.ZipCode), derefString(c.Country),
+	)
+}
+
+// BillToLines returns the Bill To block of the client's invoices: the bill-to text when they
+// have some, otherwise their name and university followed by their address if it is to be
+// included
+func (c Client) BillToLines() []string {
+	if billTo := strings.TrimSpace(derefString(c.BillTo)); billTo != "" {
+		return strings.Split(billTo, "\n")
+	}
+	lines := []string{c.Name}
+	if affiliation := derefString(c.UniversityAffiliation); affiliation != "" {
+		lines = append(lines, affiliation)
+	}
+	if c.IncludeAddressOnInvoice {
+		lines = append(lines, c.AddressLines()...)
+	}
+	return lines
+}
+
+// derefString returns the string s points to, or "" when it is nil
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		street   []string
+		city     string
+		state    string
+		postcode string
+		country  string
+		expected []string
+	}{
+		{
+			name:     "no country keeps the US layout without a country line",
+			street:   []string{"123 Main St", "Suite 4"},
+			city:     "Springfield",
+			state:    "IL",
+			postcode: "62704",
+			expected: []string{"123 Main St", "Suite 4", "Springfield, IL 62704"},
+		},
+		{
+			name:     "United States",
+			street:   []string{"123 Main St"},
+			city:     "Springfield",
+			state:    "IL",
+			postcode: "62704",
+			country:  "US",
+			expected: []string{"123 Main St", "Springfield, IL 62704", "United States"},
+		},
+		{
+			name:     "Germany puts the postcode first and leaves out the state",
+			street:   []string{"Unter den Linden 5"},
+			city:     "Berlin",
+			state:    "Berlin",
+			postcode: "10117",
+			country:  "DE",
+			expected: []string{"Unter den Linden 5", "10117 Berlin", "Germany"},
+		},
+		{
+			name:     "United Kingdom puts the postcode on its own line",
+			street:   []string{"10 Downing Street"},
+			city:     "London",
+			state:    "Greater London",
+			postcode: "SW1A 2AA",
+			country:  "gb",
+			expected: []string{"10 Downing Street", "London", "SW1A 2AA", "United Kingdom"},
+		},
+		{
+			name:     "Australia has no comma before the state",
+			street:   []string{"1 Macquarie St"},
+			city:     "Sydney",
+			state:    "NSW",
+			postcode: "2000",
+			country:  "AU",
+			expected: []string{"1 Macquarie St", "Sydney NSW 2000", "Australia"},
+		},
+		{
+			name:     "Italy follows the city with the province",
+			street:   []string{"Via del Corso 1"},
+			city:     "Roma",
+			state:    "RM",
+			postcode: "00186",
+			country:  "IT",
+			expected: []string{"Via del Corso 1", "00186 Roma RM", "Italy"},
+		},
+		{
+			name:     "New Zealand follows the city with the postcode",
+			city:     "Auckland",
+			postcode: "1010",
+			country:  "NZ",
+			expected: []string{"Auckland 1010", "New Zealand"},
+		},
+		{
+			name:     "blank parts are left out",
+			street:   []string{"", "  Flat 2 ", ""},
+			city:     "Paris",
+			country:  "FR",
+			expected: []string{"Flat 2", "Paris", "France"},
+		},
+		{
+			name:     "unknown country uses the US layout and prints its code",
+			city:     "Somewhere",
+			state:    "XY",
+			postcode: "999",
+			country:  "QQ",
+			expected: []string{"Somewhere, XY 999", "QQ"},
+		},
+		{
+			name: "empty address",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatAddress(tt.street, tt.city, tt.state, tt.postcode, tt.country))
+		})
+	}
+}
+
+func TestClient_AddressLines(t *testing.T) {
+	street, city, postcode, country := "Keizersgracht 1", "Amsterdam", "1015 CJ", "NL"
+	client := Client{Address1: &street, City: &city, ZipCode: &postcode, Country: &country}
+
+	assert.Equal(t, []string{"Keizersgracht 1", "1015 CJ Amsterdam", "Netherlands"}, client.AddressLines())
+	assert.Empty(t, Client{}.AddressLines())
+}
+
+func TestClient_BillToLines(t *testing.T) {
+	street, city, postcode, country := "Keizersgracht 1", "Amsterdam", "1015 CJ", "NL"
+	university := "University of Amsterdam"
+	client := Client{Name: "Anna Jansen", UniversityAffiliation: &university, Address1: &street, City: &city, ZipCode: &postcode, Country: &country}
+
+	assert.Equal(t, []string{"Anna Jansen", "University of Amsterdam"}, client.BillToLines())
+
+	client.IncludeAddressOnInvoice = true
+	assert.Equal(t, []string{"Anna Jansen", "University of Amsterdam", "Keizersgracht 1", "1015 CJ Amsterdam", "Netherlands"}, client.BillToLines())
+
+	billTo := "Accounts Payable\nFaculty of Humanities\n"
+	client.BillTo = &billTo
+	assert.Equal(t, []string{"Accounts Payable", "Faculty of Humanities"}, client.BillToLines())
+}
+
+func TestLookupCountry(t *testing.T) {
+	country, ok := LookupCountry("de")
+	assert.True(t, ok)
+	assert.Equal(t, "Germany", country.Name)
+
+	_, ok = LookupCountry("")
+	assert.False(t, ok)
+}
@@ -0,0 +1,227 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// BusinessProfile represents an identity invoices can be issued under, with its
+// own name, address, logo, bank details and invoice numbering sequence
+type BusinessProfile struct {
+	ID                     int
+	Name                   string
+	FreelancerName         string
+	FreelancerAddress      string
+	FreelancerCityStateZip string
+	FreelancerPhone        string
+	FreelancerEmail        string
+	LogoPath               string
+	BankDetails            string
+	InvoicePrefix          string
+	NextInvoiceNumber      int
+	Updated                time.Time
+	Created                time.Time
+	DeletedAt              *time.Time
+}
+
+// BusinessProfileModel wraps the generated SQLC Queries for business profile operations
+type BusinessProfileModel struct {
+	queries *db.Queries
+}
+
+// NewBusinessProfileModel creates a new BusinessProfileModel
+func NewBusinessProfileModel(database *sql.DB) *BusinessProfileModel {
+	return &BusinessProfileModel{
+		queries: newQueries(database),
+	}
+}
+
+// NewBusinessProfileModelWithTx creates a BusinessProfileModel whose queries run inside the given transaction
+func NewBusinessProfileModelWithTx(tx *sql.Tx) *BusinessProfileModel {
+	return &BusinessProfileModel{
+		queries: newQueries(tx),
+	}
+}
+
+// Insert adds a new business profile to the database and returns its ID
+func (b *BusinessProfileModel) Insert(profile BusinessProfile) (int, error) {
+	ctx := context.Background()
+
+	nextInvoiceNumber := profile.NextInvoiceNumber
+	if nextInvoiceNumber < 1 {
+		nextInvoiceNumber = 1
+	}
+
+	id, err := b.queries.InsertBusinessProfile(ctx, db.InsertBusinessProfileParams{
+		Name:                   profile.Name,
+		FreelancerName:         profile.FreelancerName,
+		FreelancerAddress:      profile.FreelancerAddress,
+		FreelancerCityStateZip: profile.FreelancerCityStateZip,
+		FreelancerPhone:        profile.FreelancerPhone,
+		FreelancerEmail:        profile.FreelancerEmail,
+		LogoPath:               profile.LogoPath,
+		BankDetails:            profile.BankDetails,
+		InvoicePrefix:          profile.InvoicePrefix,
+		NextInvoiceNumber:      int64(nextInvoiceNumber),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// Get retrieves a business profile by ID
+func (b *BusinessProfileModel) Get(id int) (BusinessProfile, error) {
+	ctx := context.Background()
+	row, err := b.queries.GetBusinessProfile(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return BusinessProfile{}, ErrNoRecord
+		}
+		return BusinessProfile{}, err
+	}
+
+	var deletedAt *time.Time
+	if row.DeletedAt != nil {
+		if dt, ok := row.DeletedAt.(time.Time); ok {
+			deletedAt = &dt
+		}
+	}
+
+	return BusinessProfile{
+		ID:                     int(row.ID),
+		Name:                   row.Name,
+		FreelancerName:         row.FreelancerName,
+		FreelancerAddress:      row.FreelancerAddress,
+		FreelancerCityStateZip: row.FreelancerCityStateZip,
+		FreelancerPhone:        row.FreelancerPhone,
+		FreelancerEmail:        row.FreelancerEmail,
+		LogoPath:               row.LogoPath,
+		BankDetails:            row.BankDetails,
+		InvoicePrefix:          row.InvoicePrefix,
+		NextInvoiceNumber:      int(row.NextInvoiceNumber),
+		Updated:                row.UpdatedAt,
+		Created:                row.CreatedAt,
+		DeletedAt:              deletedAt,
+	}, nil
+}
+
+// GetAll retrieves all business profiles ordered by name
+func (b *BusinessProfileModel) GetAll() ([]BusinessProfile, error) {
+	ctx := context.Background()
+	rows, err := b.queries.GetAllBusinessProfiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]BusinessProfile, len(rows))
+	for i, row := range rows {
+		profiles[i] = BusinessProfile{
+			ID:                     int(row.ID),
+			Name:                   row.Name,
+			FreelancerName:         row.FreelancerName,
+			FreelancerAddress:      row.FreelancerAddress,
+			FreelancerCityStateZip: row.FreelancerCityStateZip,
+			FreelancerPhone:        row.FreelancerPhone,
+			FreelancerEmail:        row.FreelancerEmail,
+			LogoPath:               row.LogoPath,
+			BankDetails:            row.BankDetails,
+			InvoicePrefix:          row.InvoicePrefix,
+			NextInvoiceNumber:      int(row.NextInvoiceNumber),
+			Updated:                row.UpdatedAt,
+			Created:                row.CreatedAt,
+		}
+	}
+
+	return profiles, nil
+}
+
+// Update modifies an existing business profile in the database
+func (b *BusinessProfileModel) Update(profile BusinessProfile) error {
+	ctx := context.Background()
+	return b.queries.UpdateBusinessProfile(ctx, db.UpdateBusinessProfileParams{
+		Name:                   profile.Name,
+		FreelancerName:         profile.FreelancerName,
+		FreelancerAddress:      profile.FreelancerAddress,
+		FreelancerCityStateZip: profile.FreelancerCityStateZip,
+		FreelancerPhone:        profile.FreelancerPhone,
+		FreelancerEmail:        profile.FreelancerEmail,
+		LogoPath:               profile.LogoPath,
+		BankDetails:            profile.BankDetails,
+		InvoicePrefix:          profile.InvoicePrefix,
+		NextInvoiceNumber:      int64(profile.NextInvoiceNumber),
+		ID:                     int64(profile.ID),
+	})
+}
+
+// Delete soft deletes a business profile by setting the deleted_at timestamp
+func (b *BusinessProfileModel) Delete(id int) error {
+	ctx := context.Background()
+	return b.queries.DeleteBusinessProfile(ctx, int64(id))
+}
+
+// NextInvoiceNumber returns the next invoice number in the profile's sequence,
+// formatted with the profile's prefix, and advances the sequence. The number is claimed and
+// the sequence advanced in a single statement, so invoices created at the same time can
+// never be given the same number.
+func (b *BusinessProfileModel) NextInvoiceNumber(id int) (string, error) {
+	ctx := context.Background()
+	row, err := b.queries.ClaimBusinessProfileInvoiceNumber(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNoRecord
+		}
+		return "", err
+	}
+
+	profile := BusinessProfile{InvoicePrefix: row.InvoicePrefix}
+	return profile.FormatInvoiceNumber(int(row.NextInvoiceNumber) - 1), nil
+}
+
+// FormatInvoiceNumber formats a number in the profile's sequence with its prefix
+func (p BusinessProfile) FormatInvoiceNumber(number int) string {
+	return p.invoiceSequence().Format(number)
+}
+
+// UpcomingInvoiceNumber returns the number the sequence will give the next invoice, without advancing it
+func (p BusinessProfile) UpcomingInvoiceNumber() string {
+	return p.invoiceSequence().Upcoming()
+}
+
+// ClashesWithSequence reports whether a manually entered invoice number would be issued
+// again by the profile's sequence later. Numbers the sequence has already passed are safe
+// to reuse.
+func (p BusinessProfile) ClashesWithSequence(invoiceNumber string) bool {
+	return p.invoiceSequence().Clashes(invoiceNumber)
+}
+
+// invoiceSequence returns the profile's numbering sequence
+func (p BusinessProfile) invoiceSequence() InvoiceSequence {
+	return *ResolveInvoiceSequence(Client{}, &p)
+}
+
+// ResolveBusinessProfileID returns the business profile a project's invoices are
+// issued under: the project's own profile if set, otherwise the client's
+func ResolveBusinessProfileID(project Project, client Client) *int {
+	if project.BusinessProfileID != nil {
+		return project.BusinessProfileID
+	}
+	return client.BusinessProfileID
+}
+
+// BusinessProfileModelInterface defines the interface for business profile operations
+type BusinessProfileModelInterface interface {
+	Insert(profile BusinessProfile) (int, error)
+	Get(id int) (BusinessProfile, error)
+	GetAll() ([]BusinessProfile, error)
+	Update(profile BusinessProfile) error
+	Delete(id int) error
+	NextInvoiceNumber(id int) (string, error)
+}
+
+// Ensure implementation satisfies the interface
+var _ BusinessProfileModelInterface = (*BusinessProfileModel)(nil)
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusinessProfileModel_InsertAndGet(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewBusinessProfileModel(testDB.DB)
+
+	t.Run("successful insert", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+
+		id, err := model.Insert(BusinessProfile{
+			Name:              "Editing Services",
+			FreelancerName:    "Jane Editor",
+			FreelancerEmail:   "jane@editing.example.com",
+			BankDetails:       "Bank: First National\nAccount: 12345",
+			InvoicePrefix:     "ED-",
+			NextInvoiceNumber: 42,
+		})
+		require.NoError(t, err)
+		assert.Greater(t, id, 0)
+
+		profile, err := model.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, "Editing Services", profile.Name)
+		assert.Equal(t, "Jane Editor", profile.FreelancerName)
+		assert.Equal(t, "jane@editing.example.com", profile.FreelancerEmail)
+		assert.Equal(t, "Bank: First National\nAccount: 12345", profile.BankDetails)
+		assert.Equal(t, "ED-", profile.InvoicePrefix)
+		assert.Equal(t, 42, profile.NextInvoiceNumber)
+		assert.False(t, profile.Created.IsZero())
+	})
+
+	t.Run("next invoice number defaults to one", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+
+		id, err := model.Insert(BusinessProfile{Name: "Defaults"})
+		require.NoError(t, err)
+
+		profile, err := model.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, 1, profile.NextInvoiceNumber)
+	})
+
+	t.Run("get non-existent profile", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+
+		_, err := model.Get(999)
+		assert.Equal(t, ErrNoRecord, err)
+	})
+}
+
+func TestBusinessProfileModel_UpdateAndDelete(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewBusinessProfileModel(testDB.DB)
+
+	t.Run("update profile", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+		id := testDB.InsertTestBusinessProfile(t, "Original", "OR-")
+
+		err := model.Update(BusinessProfile{
+			ID:                id,
+			Name:              "Renamed",
+			InvoicePrefix:     "RN-",
+			NextInvoiceNumber: 7,
+		})
+		require.NoError(t, err)
+
+		profile, err := model.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", profile.Name)
+		assert.Equal(t, "RN-", profile.InvoicePrefix)
+		assert.Equal(t, 7, profile.NextInvoiceNumber)
+	})
+
+	t.Run("delete excludes profile from lists", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+		keepID := testDB.InsertTestBusinessProfile(t, "Alpha", "")
+		deleteID := testDB.InsertTestBusinessProfile(t, "Beta", "")
+
+		err := model.Delete(deleteID)
+		require.NoError(t, err)
+
+		profiles, err := model.GetAll()
+		require.NoError(t, err)
+		require.Len(t, profiles, 1)
+		assert.Equal(t, keepID, profiles[0].ID)
+
+		_, err = model.Get(deleteID)
+		assert.Equal(t, ErrNoRecord, err)
+	})
+}
+
+func TestBusinessProfileModel_NextInvoiceNumber(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewBusinessProfileModel(testDB.DB)
+
+	t.Run("sequence advances per call", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+		id := testDB.InsertTestBusinessProfile(t, "Numbered", "INV-")
+
+		first, err := model.NextInvoiceNumber(id)
+		require.NoError(t, err)
+		second, err := model.NextInvoiceNumber(id)
+		require.NoError(t, err)
+
+		assert.Equal(t, "INV-0001", first)
+		assert.Equal(t, "INV-0002", second)
+	})
+
+	t.Run("sequences are independent per profile", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+		idA := testDB.InsertTestBusinessProfile(t, "Brand A", "A-")
+		idB := testDB.InsertTestBusinessProfile(t, "Brand B", "B-")
+
+		_, err := model.NextInvoiceNumber(idA)
+		require.NoError(t, err)
+		numberB, err := model.NextInvoiceNumber(idB)
+		require.NoError(t, err)
+
+		assert.Equal(t, "B-0001", numberB)
+	})
+
+	t.Run("non-existent profile", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+
+		_, err := model.NextInvoiceNumber(999)
+		assert.Equal(t, ErrNoRecord, err)
+	})
+
+	t.Run("simultaneous calls never share a number", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+		id := testDB.InsertTestBusinessProfile(t, "Busy", "INV-")
+
+		const callers = 20
+		numbers := make(chan string, callers)
+		var wg sync.WaitGroup
+		for range callers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				number, err := model.NextInvoiceNumber(id)
+				assert.NoError(t, err)
+				numbers <- number
+			}()
+		}
+		wg.Wait()
+		close(numbers)
+
+		seen := map[string]bool{}
+		for number := range numbers {
+			assert.False(t, seen[number], "%s given out twice", number)
+			seen[number] = true
+		}
+		assert.Len(t, seen, callers)
+
+		profile, err := model.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, callers+1, profile.NextInvoiceNumber)
+	})
+}
+
+func TestBusinessProfile_ClashesWithSequence(t *testing.T) {
+	profile := BusinessProfile{InvoicePrefix: "TS-", NextInvoiceNumber: 5}
+
+	assert.Equal(t, "TS-0005", profile.UpcomingInvoiceNumber())
+	assert.True(t, profile.ClashesWithSequence("TS-0005"))
+	assert.True(t, profile.ClashesWithSequence("TS-0120"))
+	assert.False(t, profile.ClashesWithSequence("TS-0004"))
+	assert.False(t, profile.ClashesWithSequence("TS-OLD"))
+	assert.False(t, profile.ClashesWithSequence("AB-0009"))
+}
+
+func TestResolveBusinessProfileID(t *testing.T) {
+	clientProfile := 1
+	projectProfile := 2
+
+	assert.Nil(t, ResolveBusinessProfileID(Project{}, Client{}))
+	assert.Equal(t, &clientProfile, ResolveBusinessProfileID(Project{}, Client{BusinessProfileID: &clientProfile}))
+	assert.Equal(t, &projectProfile, ResolveBusinessProfileID(Project{BusinessProfileID: &projectProfile}, Client{BusinessProfileID: &clientProfile}))
+}
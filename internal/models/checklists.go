@@ -0,0 +1,154 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// ChecklistItem is something to keep track of on projects, such as a signed agreement
+// received or the final files delivered. Items kept for a tag apply to the projects carrying
+// it, tags being how projects are grouped by type of work; items without a tag apply to
+// every project.
+type ChecklistItem struct {
+	ID      int
+	TagID   *int
+	TagName string
+	Label   string
+	Created time.Time
+}
+
+// ProjectChecklistItem is a checklist item as it stands on a project. CheckedAt is when it was
+// ticked off, or nil while it is still to do.
+type ProjectChecklistItem struct {
+	ChecklistItem
+	CheckedAt *time.Time
+}
+
+// Checked reports whether the item has been ticked off on the project
+func (i ProjectChecklistItem) Checked() bool {
+	return i.CheckedAt != nil
+}
+
+// ChecklistModel wraps the generated SQLC Queries for project checklist operations
+type ChecklistModel struct {
+	queries *db.Queries
+}
+
+// NewChecklistModel creates a new ChecklistModel
+func NewChecklistModel(database *sql.DB) *ChecklistModel {
+	return &ChecklistModel{
+		queries: newQueries(database),
+	}
+}
+
+// InsertItem adds an item to the checklist of projects carrying the given tag, or of every
+// project when tagID is nil, and returns the item's ID
+func (m *ChecklistModel) InsertItem(tagID *int, label string) (int, error) {
+	ctx := context.Background()
+	id, err := m.queries.InsertChecklistItem(ctx, db.InsertChecklistItemParams{
+		TagID: convertIntPtr(tagID),
+		Label: label,
+	})
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return int(id), nil
+}
+
+// GetItems retrieves every checklist item, those for all projects first and then by tag name
+func (m *ChecklistModel) GetItems() ([]ChecklistItem, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetChecklistItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]ChecklistItem, len(rows))
+	for i, row := range rows {
+		items[i] = newChecklistItem(row)
+	}
+	return items, nil
+}
+
+// DeleteItem removes an item from the checklist, along with the record of it being ticked
+// off on any project
+func (m *ChecklistModel) DeleteItem(id int) error {
+	ctx := context.Background()
+	if err := m.queries.DeleteProjectChecksByItem(ctx, int64(id)); err != nil {
+		return err
+	}
+	rows, err := m.queries.DeleteChecklistItem(ctx, int64(id))
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
+
+// GetForProject retrieves the checklist of a project: the items for every project and those
+// for the project's tags, in the order they were added, each with when it was ticked off
+func (m *ChecklistModel) GetForProject(projectID int) ([]ProjectChecklistItem, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetProjectChecklist(ctx, int64(projectID))
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]ProjectChecklistItem, len(rows))
+	for i, row := range rows {
+		items[i] = ProjectChecklistItem{
+			ChecklistItem: ChecklistItem{
+				ID:      int(row.ID),
+				TagID:   convertNullInt64(row.TagID),
+				TagName: row.TagName,
+				Label:   row.Label,
+				Created: row.CreatedAt,
+			},
+			CheckedAt: convertNullTime(row.CheckedAt),
+		}
+	}
+	return items, nil
+}
+
+// SetChecked ticks an item off on a project, stamped with the current time, or clears it.
+// Ticking off an item already checked keeps the time it was first checked.
+func (m *ChecklistModel) SetChecked(projectID, itemID int, checked bool) error {
+	ctx := context.Background()
+	if checked {
+		return translateError(m.queries.CheckProjectChecklistItem(ctx, db.CheckProjectChecklistItemParams{
+			ProjectID:       int64(projectID),
+			ChecklistItemID: int64(itemID),
+		}))
+	}
+	return m.queries.UncheckProjectChecklistItem(ctx, db.UncheckProjectChecklistItemParams{
+		ProjectID:       int64(projectID),
+		ChecklistItemID: int64(itemID),
+	})
+}
+
+func newChecklistItem(row db.GetChecklistItemsRow) ChecklistItem {
+	return ChecklistItem{
+		ID:      int(row.ID),
+		TagID:   convertNullInt64(row.TagID),
+		TagName: row.TagName,
+		Label:   row.Label,
+		Created: row.CreatedAt,
+	}
+}
+
+// ChecklistModelInterface defines the interface for project checklist operations
+type ChecklistModelInterface interface {
+	InsertItem(tagID *int, label string) (int, error)
+	GetItems() ([]ChecklistItem, error)
+	DeleteItem(id int) error
+	GetForProject(projectID int) ([]ProjectChecklistItem, error)
+	SetChecked(projectID, itemID int, checked bool) error
+}
+
+// Ensure implementation satisfies the interface
+var _ ChecklistModelInterface = (*ChecklistModel)(nil)
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecklistModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewChecklistModel(testDB.DB)
+	tags := NewTagModel(testDB.DB)
+	clientID := testDB.InsertTestClient(t, "Checklist Client")
+	bookID := testDB.InsertTestProject(t, "Book", clientID)
+	articleID := testDB.InsertTestProject(t, "Article", clientID)
+
+	editingID, err := tags.Insert(Tag{Name: "Editing", Color: DefaultTagColor})
+	require.NoError(t, err)
+	require.NoError(t, tags.SetProjectTags(bookID, []int{editingID}))
+
+	agreementID, err := model.InsertItem(nil, "Signed agreement received")
+	require.NoError(t, err)
+	styleGuideID, err := model.InsertItem(&editingID, "Style guide received")
+	require.NoError(t, err)
+
+	t.Run("items for every project come first", func(t *testing.T) {
+		items, err := model.GetItems()
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.Equal(t, agreementID, items[0].ID)
+		assert.Nil(t, items[0].TagID)
+		assert.Equal(t, "Editing", items[1].TagName)
+	})
+
+	t.Run("projects get the items for their tags", func(t *testing.T) {
+		book, err := model.GetForProject(bookID)
+		require.NoError(t, err)
+		require.Len(t, book, 2)
+		assert.Equal(t, "Style guide received", book[1].Label)
+		assert.False(t, book[1].Checked())
+
+		article, err := model.GetForProject(articleID)
+		require.NoError(t, err)
+		require.Len(t, article, 1)
+		assert.Equal(t, agreementID, article[0].ID)
+	})
+
+	t.Run("checking an item stamps it on that project alone", func(t *testing.T) {
+		require.NoError(t, model.SetChecked(bookID, styleGuideID, true))
+		book, err := model.GetForProject(bookID)
+		require.NoError(t, err)
+		require.True(t, book[1].Checked())
+		checkedAt := *book[1].CheckedAt
+
+		require.NoError(t, model.SetChecked(bookID, styleGuideID, true))
+		book, err = model.GetForProject(bookID)
+		require.NoError(t, err)
+		assert.Equal(t, checkedAt, *book[1].CheckedAt, "checking again keeps the first time")
+
+		article, err := model.GetForProject(articleID)
+		require.NoError(t, err)
+		assert.False(t, article[0].Checked())
+
+		require.NoError(t, model.SetChecked(bookID, styleGuideID, false))
+		book, err = model.GetForProject(bookID)
+		require.NoError(t, err)
+		assert.False(t, book[1].Checked())
+	})
+
+	t.Run("deleting the tag removes its items", func(t *testing.T) {
+		require.NoError(t, model.SetChecked(bookID, styleGuideID, true))
+		require.NoError(t, tags.Delete(editingID))
+
+		items, err := model.GetItems()
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, agreementID, items[0].ID)
+	})
+
+	t.Run("delete item", func(t *testing.T) {
+		require.NoError(t, model.SetChecked(articleID, agreementID, true))
+		require.NoError(t, model.DeleteItem(agreementID))
+		assert.Equal(t, ErrNoRecord, model.DeleteItem(agreementID))
+
+		article, err := model.GetForProject(articleID)
+		require.NoError(t, err)
+		assert.Empty(t, article)
+	})
+}
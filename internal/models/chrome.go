@@ -0,0 +1,158 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// ErrPDFUnavailable is returned when invoice PDFs can't be rendered because no Chrome or
+// Chromium browser is installed
+var ErrPDFUnavailable = errors.New("models: PDF rendering unavailable, install Chrome or Chromium")
+
+// chromeLocations lists where Chrome is looked for, in the order chromedp itself tries them
+func chromeLocations() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{
+			"/Applications/Chromium.app/Contents/MacOS/Chromium",
+			"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+		}
+	case "windows":
+		return []string{
+			"chrome",
+			"chrome.exe",
+			`C:\Program Files (x86)\Google\Chrome\Application\chrome.exe`,
+			`C:\Program Files\Google\Chrome\Application\chrome.exe`,
+			filepath.Join(os.Getenv("USERPROFILE"), `AppData\Local\Google\Chrome\Application\chrome.exe`),
+			filepath.Join(os.Getenv("USERPROFILE"), `AppData\Local\Chromium\Application\chrome.exe`),
+		}
+	default:
+		return []string{
+			"headless_shell",
+			"headless-shell",
+			"chromium",
+			"chromium-browser",
+			"google-chrome",
+			"google-chrome-stable",
+			"google-chrome-beta",
+			"google-chrome-unstable",
+			"/usr/bin/google-chrome",
+			"/usr/local/bin/chrome",
+			"/snap/bin/chromium",
+			"chrome",
+		}
+	}
+}
+
+// FindChrome returns the path of the browser invoice PDFs are rendered with, or
+// ErrPDFUnavailable when none is installed
+func FindChrome() (string, error) {
+	for _, location := range chromeLocations() {
+		if path, err := exec.LookPath(location); err == nil {
+			return path, nil
+		}
+	}
+	return "", ErrPDFUnavailable
+}
+
+// PDFHeaderFooter is the header and footer Chrome prints in the margins of every page of a
+// PDF. Each is a fragment of HTML, in which Chrome fills in elements with the classes
+// pageNumber and totalPages; a blank one leaves its margin empty.
+type PDFHeaderFooter struct {
+	Header string
+	Footer string
+}
+
+// pdfMarginTemplate wraps a header or footer for printing in a page margin. Chrome prints it
+// at a tiny default size and without the document's styles, so it is styled in place and
+// inset to line up with the page content.
+func pdfMarginTemplate(content string) string {
+	if strings.TrimSpace(content) == "" {
+		return "<span></span>"
+	}
+	return `<div style="width: 100%; padding: 0 20mm; box-sizing: border-box; font-family: Arial, sans-serif; font-size: 8px; color: #555; text-align: center;">` + content + `</div>`
+}
+
+// RenderPDF prints an HTML document to an A4 PDF with Chrome
+func RenderPDF(html []byte) ([]byte, error) {
+	return RenderPDFWithHeaderFooter(html, nil)
+}
+
+// RenderPDFWithHeaderFooter prints an HTML document to an A4 PDF with Chrome, with the given
+// header and footer on every page, or none when headerFooter is nil
+func RenderPDFWithHeaderFooter(html []byte, headerFooter *PDFHeaderFooter) ([]byte, error) {
+	// Fail with a clear error rather than chromedp's when there is no browser to render with
+	chromePath, err := FindChrome()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create context for chromedp
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(),
+		append(chromedp.DefaultExecAllocatorOptions[:], chromedp.ExecPath(chromePath))...)
+	defer cancelAlloc()
+	ctx, cancel := chromedp.NewContext(allocCtx)
+	defer cancel()
+
+	// Generate PDF using chromedp with temporary file approach
+	var pdfBytes []byte
+
+	// Write HTML to temporary file to avoid URL encoding issues
+	tmpFile, err := os.CreateTemp("", "pdf_*.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	_, err = tmpFile.Write(html)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	tmpFile.Close()
+
+	// Use file:// URL instead of data URI
+	fileURL := "file://" + tmpFile.Name()
+
+	err = chromedp.Run(ctx,
+		chromedp.Navigate(fileURL),
+		chromedp.WaitReady("body", chromedp.ByQuery),
+		chromedp.Sleep(2*time.Second), // Give more time for rendering
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			params := page.PrintToPDF().
+				WithPrintBackground(true). // Enable background printing
+				WithPaperWidth(8.27).      // A4 width in inches
+				WithPaperHeight(11.7).     // A4 height in inches
+				WithMarginTop(0.79).       // 20mm in inches
+				WithMarginBottom(0.79).
+				WithMarginLeft(0.79).
+				WithMarginRight(0.79).
+				WithDisplayHeaderFooter(headerFooter != nil).
+				WithScale(1.0) // Ensure proper scaling
+			if headerFooter != nil {
+				params = params.
+					WithHeaderTemplate(pdfMarginTemplate(headerFooter.Header)).
+					WithFooterTemplate(pdfMarginTemplate(headerFooter.Footer))
+			}
+
+			var err error
+			pdfBytes, _, err = params.Do(ctx)
+			return err
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return pdfBytes, nil
+}
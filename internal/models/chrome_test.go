@@ -0,0 +1,40 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindChrome(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("browser locations are only faked on Linux")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+
+	t.Run("found on the path", func(t *testing.T) {
+		chromium := filepath.Join(dir, "chromium")
+		require.NoError(t, os.WriteFile(chromium, []byte("#!/bin/sh\n"), 0755))
+		defer os.Remove(chromium)
+
+		path, err := FindChrome()
+		require.NoError(t, err)
+		assert.Equal(t, chromium, path)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		path, err := FindChrome()
+		if err == nil {
+			// A browser installed at a fixed location is found regardless of the path
+			assert.True(t, filepath.IsAbs(path))
+			return
+		}
+		assert.ErrorIs(t, err, ErrPDFUnavailable)
+	})
+}
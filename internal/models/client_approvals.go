@@ -0,0 +1,197 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// ClientApproval is a link a client can open without logging in to review the timesheets of a
+// project worked between Start and End, both included, and approve them. Once approved it
+// records who approved, when and from which IP address.
+type ClientApproval struct {
+	ID         int
+	ProjectID  int
+	Token      string
+	Start      time.Time
+	End        time.Time
+	ApprovedAt *time.Time
+	ApprovedBy string
+	ApprovedIP string
+	Created    time.Time
+}
+
+// Approved reports whether the client has approved the timesheets
+func (a ClientApproval) Approved() bool {
+	return a.ApprovedAt != nil
+}
+
+// Includes reports whether work done on a date is among the timesheets the link covers
+func (a ClientApproval) Includes(workDate time.Time) bool {
+	day := workDate.Format(isoLayout)
+	return day >= a.Start.Format(isoLayout) && day <= a.End.Format(isoLayout)
+}
+
+// ApprovalCovering returns the approved link among approvals whose dates include every one
+// of workDates, the most recently approved when there are several, or nil when none does
+func ApprovalCovering(approvals []ClientApproval, workDates []time.Time) *ClientApproval {
+	var covering *ClientApproval
+	for i, approval := range approvals {
+		if !approval.Approved() {
+			continue
+		}
+		covers := true
+		for _, workDate := range workDates {
+			if !approval.Includes(workDate) {
+				covers = false
+				break
+			}
+		}
+		if covers && (covering == nil || approval.ApprovedAt.After(*covering.ApprovedAt)) {
+			covering = &approvals[i]
+		}
+	}
+	return covering
+}
+
+// newApprovalToken returns a random token for the link a client approves timesheets with
+func newApprovalToken() (string, error) {
+	token := make([]byte, 24)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// ClientApprovalModel wraps the generated SQLC Queries for client timesheet approval operations
+type ClientApprovalModel struct {
+	queries *db.Queries
+}
+
+// NewClientApprovalModel creates a new ClientApprovalModel
+func NewClientApprovalModel(database *sql.DB) *ClientApprovalModel {
+	return &ClientApprovalModel{
+		queries: newQueries(database),
+	}
+}
+
+// Insert creates a link for approving a project's timesheets between two dates and returns
+// its ID
+func (m *ClientApprovalModel) Insert(projectID int, start, end time.Time) (int, error) {
+	ctx := context.Background()
+	token, err := newApprovalToken()
+	if err != nil {
+		return 0, err
+	}
+	id, err := m.queries.InsertClientApproval(ctx, db.InsertClientApprovalParams{
+		ProjectID: int64(projectID),
+		Token:     token,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return int(id), nil
+}
+
+// Get retrieves a client approval link by ID
+func (m *ClientApprovalModel) Get(id int) (ClientApproval, error) {
+	ctx := context.Background()
+	row, err := m.queries.GetClientApproval(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ClientApproval{}, ErrNoRecord
+		}
+		return ClientApproval{}, err
+	}
+	return newClientApproval(row), nil
+}
+
+// GetByToken retrieves the client approval link with the given token
+func (m *ClientApprovalModel) GetByToken(token string) (ClientApproval, error) {
+	ctx := context.Background()
+	row, err := m.queries.GetClientApprovalByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ClientApproval{}, ErrNoRecord
+		}
+		return ClientApproval{}, err
+	}
+	return newClientApproval(row), nil
+}
+
+// GetByProject retrieves a project's client approval links, newest first
+func (m *ClientApprovalModel) GetByProject(projectID int) ([]ClientApproval, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetClientApprovalsByProject(ctx, int64(projectID))
+	if err != nil {
+		return nil, err
+	}
+
+	approvals := make([]ClientApproval, len(rows))
+	for i, row := range rows {
+		approvals[i] = newClientApproval(row)
+	}
+	return approvals, nil
+}
+
+// Approve records that the timesheets were approved by the named person from an IP address.
+// A link can only be approved once; ErrAlreadyApproved is returned after that.
+func (m *ClientApprovalModel) Approve(id int, approvedBy, ip string, at time.Time) error {
+	ctx := context.Background()
+	rows, err := m.queries.ApproveClientApproval(ctx, db.ApproveClientApprovalParams{
+		ApprovedAt: sql.NullTime{Time: at, Valid: true},
+		ApprovedBy: sql.NullString{String: approvedBy, Valid: true},
+		ApprovedIp: sql.NullString{String: ip, Valid: ip != ""},
+		ID:         int64(id),
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		if _, err := m.Get(id); err != nil {
+			return err
+		}
+		return ErrAlreadyApproved
+	}
+	return nil
+}
+
+// Delete removes a client approval link, so it can no longer be opened
+func (m *ClientApprovalModel) Delete(id int) error {
+	ctx := context.Background()
+	return m.queries.DeleteClientApproval(ctx, int64(id))
+}
+
+func newClientApproval(row db.ClientApproval) ClientApproval {
+	return ClientApproval{
+		ID:         int(row.ID),
+		ProjectID:  int(row.ProjectID),
+		Token:      row.Token,
+		Start:      row.StartDate,
+		End:        row.EndDate,
+		ApprovedAt: convertNullTime(row.ApprovedAt),
+		ApprovedBy: row.ApprovedBy.String,
+		ApprovedIP: row.ApprovedIp.String,
+		Created:    row.CreatedAt,
+	}
+}
+
+// ClientApprovalModelInterface defines the interface for client timesheet approval operations
+type ClientApprovalModelInterface interface {
+	Insert(projectID int, start, end time.Time) (int, error)
+	Get(id int) (ClientApproval, error)
+	GetByToken(token string) (ClientApproval, error)
+	GetByProject(projectID int) ([]ClientApproval, error)
+	Approve(id int, approvedBy, ip string, at time.Time) error
+	Delete(id int) error
+}
+
+// Ensure implementation satisfies the interface
+var _ ClientApprovalModelInterface = (*ClientApprovalModel)(nil)
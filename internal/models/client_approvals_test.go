@@ -0,0 +1,114 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientApprovalModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewClientApprovalModel(testDB.DB)
+	projectID := testDB.InsertTestProject(t, "Thesis", testDB.InsertTestClient(t, "Approval Client"))
+	april := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	endOfApril := time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)
+
+	aprilID, err := model.Insert(projectID, april, endOfApril)
+	require.NoError(t, err)
+	mayID, err := model.Insert(projectID, endOfApril.AddDate(0, 0, 1), endOfApril.AddDate(0, 1, 0))
+	require.NoError(t, err)
+
+	t.Run("each link gets its own token", func(t *testing.T) {
+		approval, err := model.Get(aprilID)
+		require.NoError(t, err)
+		assert.Equal(t, projectID, approval.ProjectID)
+		assert.Len(t, approval.Token, 32)
+		assert.Equal(t, "2024-04-01", approval.Start.Format(time.DateOnly))
+		assert.Equal(t, "2024-04-30", approval.End.Format(time.DateOnly))
+		assert.False(t, approval.Approved())
+
+		byToken, err := model.GetByToken(approval.Token)
+		require.NoError(t, err)
+		assert.Equal(t, aprilID, byToken.ID)
+
+		may, err := model.Get(mayID)
+		require.NoError(t, err)
+		assert.NotEqual(t, approval.Token, may.Token)
+
+		_, err = model.GetByToken("not-a-token")
+		assert.ErrorIs(t, err, ErrNoRecord)
+	})
+
+	t.Run("a project's links are listed newest first", func(t *testing.T) {
+		approvals, err := model.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, approvals, 2)
+		assert.Equal(t, mayID, approvals[0].ID)
+		assert.Equal(t, aprilID, approvals[1].ID)
+	})
+
+	t.Run("approving records who, when and where from, once", func(t *testing.T) {
+		at := time.Date(2024, 5, 2, 14, 30, 0, 0, time.UTC)
+		require.NoError(t, model.Approve(aprilID, "Jane Doe", "203.0.113.7", at))
+
+		approval, err := model.Get(aprilID)
+		require.NoError(t, err)
+		assert.True(t, approval.Approved())
+		assert.Equal(t, at, approval.ApprovedAt.UTC())
+		assert.Equal(t, "Jane Doe", approval.ApprovedBy)
+		assert.Equal(t, "203.0.113.7", approval.ApprovedIP)
+
+		err = model.Approve(aprilID, "Someone Else", "198.51.100.1", at.Add(time.Hour))
+		assert.ErrorIs(t, err, ErrAlreadyApproved)
+		approval, err = model.Get(aprilID)
+		require.NoError(t, err)
+		assert.Equal(t, "Jane Doe", approval.ApprovedBy)
+
+		assert.ErrorIs(t, model.Approve(99999, "Jane Doe", "", at), ErrNoRecord)
+	})
+
+	t.Run("links can be deleted", func(t *testing.T) {
+		require.NoError(t, model.Delete(mayID))
+		_, err := model.Get(mayID)
+		assert.ErrorIs(t, err, ErrNoRecord)
+	})
+
+	t.Run("a link needs an existing project", func(t *testing.T) {
+		_, err := model.Insert(99999, april, endOfApril)
+		assert.ErrorIs(t, err, ErrForeignKeyViolation)
+	})
+}
+
+func TestApprovalCovering(t *testing.T) {
+	day := func(value string) time.Time {
+		date, err := time.Parse(time.DateOnly, value)
+		require.NoError(t, err)
+		return date
+	}
+	approvedAt := func(value string) *time.Time {
+		date := day(value)
+		return &date
+	}
+
+	approvals := []ClientApproval{
+		{ID: 1, Start: day("2024-04-01"), End: day("2024-04-30"), ApprovedAt: approvedAt("2024-05-02")},
+		{ID: 2, Start: day("2024-04-01"), End: day("2024-05-31"), ApprovedAt: approvedAt("2024-06-03")},
+		{ID: 3, Start: day("2024-06-01"), End: day("2024-06-30")},
+	}
+
+	covering := ApprovalCovering(approvals, []time.Time{day("2024-04-08"), day("2024-04-30").Add(15 * time.Hour)})
+	require.NotNil(t, covering)
+	assert.Equal(t, 2, covering.ID, "the latest approval covering every date")
+
+	covering = ApprovalCovering(approvals, []time.Time{day("2024-04-08"), day("2024-05-20")})
+	require.NotNil(t, covering)
+	assert.Equal(t, 2, covering.ID)
+
+	assert.Nil(t, ApprovalCovering(approvals, []time.Time{day("2024-05-20"), day("2024-06-02")}), "no one approval covers both")
+	assert.Nil(t, ApprovalCovering(approvals, []time.Time{day("2024-06-02")}), "a link not yet approved doesn't count")
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// ClientCredit is an entry in a client's credit ledger. Overpayments add a positive amount
+// and applying credit to an invoice adds a negative amount, so the balance is their sum.
+type ClientCredit struct {
+	ID          int
+	ClientID    int
+	InvoiceID   *int
+	Amount      float64
+	Description string
+	Created     time.Time
+}
+
+// ClientCreditModel wraps the generated SQLC Queries for client credit operations
+type ClientCreditModel struct {
+	queries *db.Queries
+}
+
+// NewClientCreditModel creates a new ClientCreditModel
+func NewClientCreditModel(database *sql.DB) *ClientCreditModel {
+	return &ClientCreditModel{
+		queries: newQueries(database),
+	}
+}
+
+// NewClientCreditModelWithTx creates a ClientCreditModel whose queries run inside the given transaction
+func NewClientCreditModelWithTx(tx *sql.Tx) *ClientCreditModel {
+	return &ClientCreditModel{
+		queries: newQueries(tx),
+	}
+}
+
+// Balance returns the credit a client currently has available
+func (c *ClientCreditModel) Balance(clientID int) (float64, error) {
+	ctx := context.Background()
+	return c.queries.GetClientCreditBalance(ctx, int64(clientID))
+}
+
+// GetByClient retrieves a client's credit ledger, newest entries first
+func (c *ClientCreditModel) GetByClient(clientID int) ([]ClientCredit, error) {
+	ctx := context.Background()
+	rows, err := c.queries.GetClientCreditsByClient(ctx, int64(clientID))
+	if err != nil {
+		return nil, err
+	}
+
+	credits := make([]ClientCredit, len(rows))
+	for i, row := range rows {
+		credits[i] = ClientCredit{
+			ID:          int(row.ID),
+			ClientID:    int(row.ClientID),
+			InvoiceID:   convertNullInt64(row.InvoiceID),
+			Amount:      row.Amount,
+			Description: row.Description,
+			Created:     row.CreatedAt,
+		}
+	}
+	return credits, nil
+}
+
+// RecordOverpayment sets the credit earned by overpaying an invoice, replacing any amount
+// recorded for it before so that correcting a payment doesn't count the excess twice
+func (c *ClientCreditModel) RecordOverpayment(clientID, invoiceID int, amount float64) error {
+	ctx := context.Background()
+	invoice := sql.NullInt64{Int64: int64(invoiceID), Valid: true}
+
+	err := c.queries.DeleteInvoiceOverpaymentCredit(ctx, invoice)
+	if err != nil {
+		return err
+	}
+	if amount <= 0 {
+		return nil
+	}
+
+	_, err = c.queries.InsertClientCredit(ctx, db.InsertClientCreditParams{
+		ClientID:    int64(clientID),
+		InvoiceID:   invoice,
+		Amount:      amount,
+		Description: "Overpayment",
+	})
+	return err
+}
+
+// Apply uses up some of a client's credit to pay towards an invoice
+func (c *ClientCreditModel) Apply(clientID, invoiceID int, amount float64) error {
+	ctx := context.Background()
+	_, err := c.queries.InsertClientCredit(ctx, db.InsertClientCreditParams{
+		ClientID:    int64(clientID),
+		InvoiceID:   sql.NullInt64{Int64: int64(invoiceID), Valid: true},
+		Amount:      -amount,
+		Description: "Applied to invoice",
+	})
+	return err
+}
+
+// SetApplied sets how much of a client's credit is applied to an invoice, replacing what was
+// applied to it before, so that lowering the amount gives the difference back to the client
+func (c *ClientCreditModel) SetApplied(clientID, invoiceID int, amount float64) error {
+	ctx := context.Background()
+	invoice := sql.NullInt64{Int64: int64(invoiceID), Valid: true}
+
+	err := c.queries.DeleteInvoiceAppliedCredit(ctx, invoice)
+	if err != nil {
+		return err
+	}
+	if amount <= 0 {
+		return nil
+	}
+	return c.Apply(clientID, invoiceID, amount)
+}
+
+// ReleaseInvoice takes an invoice off the ledger when it is deleted or voided: credit applied
+// to it goes back to the client and the credit its overpayment earned is withdrawn. It returns
+// ErrCreditSpent, leaving the overpayment in place, when that credit has since been used up.
+func (c *ClientCreditModel) ReleaseInvoice(invoiceID int) error {
+	ctx := context.Background()
+	invoice := sql.NullInt64{Int64: int64(invoiceID), Valid: true}
+
+	err := c.queries.DeleteInvoiceAppliedCredit(ctx, invoice)
+	if err != nil {
+		return err
+	}
+
+	overpayments, err := c.queries.GetInvoiceOverpaymentCredit(ctx, invoice)
+	if err != nil {
+		return err
+	}
+	for _, overpayment := range overpayments {
+		balance, err := c.queries.GetClientCreditBalance(ctx, overpayment.ClientID)
+		if err != nil {
+			return err
+		}
+		if balance-overpayment.Amount < -0.005 {
+			return ErrCreditSpent
+		}
+	}
+	return c.queries.DeleteInvoiceOverpaymentCredit(ctx, invoice)
+}
+
+// ClientCreditModelInterface defines the interface for client credit operations
+type ClientCreditModelInterface interface {
+	Balance(clientID int) (float64, error)
+	GetByClient(clientID int) ([]ClientCredit, error)
+	RecordOverpayment(clientID, invoiceID int, amount float64) error
+	Apply(clientID, invoiceID int, amount float64) error
+	SetApplied(clientID, invoiceID int, amount float64) error
+	ReleaseInvoice(invoiceID int) error
+}
+
+// Ensure implementation satisfies the interface
+var _ ClientCreditModelInterface = (*ClientCreditModel)(nil)
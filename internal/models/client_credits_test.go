@@ -0,0 +1,84 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCreditModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewClientCreditModel(testDB.DB)
+
+	testDB.TruncateTable(t, "client_credit")
+	testDB.TruncateTable(t, "invoice")
+	testDB.TruncateTable(t, "project")
+	testDB.TruncateTable(t, "client")
+
+	clientID := testDB.InsertTestClient(t, "Credit Client")
+	projectID := testDB.InsertTestProject(t, "Credit Project", clientID)
+	overpaidID := testDB.InsertTestInvoice(t, projectID, "2024-01-15", "2024-01-20", "Net 30", "100.00")
+	laterID := testDB.InsertTestInvoice(t, projectID, "2024-02-15", "", "Net 30", "80.00")
+
+	t.Run("overpayment adds credit", func(t *testing.T) {
+		require.NoError(t, model.RecordOverpayment(clientID, overpaidID, 25))
+
+		balance, err := model.Balance(clientID)
+		require.NoError(t, err)
+		assert.InDelta(t, 25.0, balance, 0.001)
+	})
+
+	t.Run("recording an overpayment again replaces the earlier amount", func(t *testing.T) {
+		require.NoError(t, model.RecordOverpayment(clientID, overpaidID, 40))
+
+		balance, err := model.Balance(clientID)
+		require.NoError(t, err)
+		assert.InDelta(t, 40.0, balance, 0.001)
+	})
+
+	t.Run("applying credit uses it up", func(t *testing.T) {
+		require.NoError(t, model.Apply(clientID, laterID, 30))
+
+		balance, err := model.Balance(clientID)
+		require.NoError(t, err)
+		assert.InDelta(t, 10.0, balance, 0.001)
+
+		credits, err := model.GetByClient(clientID)
+		require.NoError(t, err)
+		require.Len(t, credits, 2)
+		assert.InDelta(t, -30.0, credits[0].Amount, 0.001)
+		require.NotNil(t, credits[0].InvoiceID)
+		assert.Equal(t, laterID, *credits[0].InvoiceID)
+	})
+
+	t.Run("clearing an overpayment removes only its credit", func(t *testing.T) {
+		require.NoError(t, model.RecordOverpayment(clientID, overpaidID, 0))
+
+		balance, err := model.Balance(clientID)
+		require.NoError(t, err)
+		assert.InDelta(t, -30.0, balance, 0.001)
+	})
+
+	t.Run("lowering the credit applied gives the difference back", func(t *testing.T) {
+		require.NoError(t, model.SetApplied(clientID, laterID, 10))
+
+		balance, err := model.Balance(clientID)
+		require.NoError(t, err)
+		assert.InDelta(t, -10.0, balance, 0.001)
+	})
+}
+
+func TestInvoice_Overpayment(t *testing.T) {
+	paid := 120.0
+	assert.InDelta(t, 40.0, Invoice{AmountDue: 100, CreditApplied: 20, AmountPaid: &paid}.Overpayment(), 0.001)
+	assert.Zero(t, Invoice{AmountDue: 200, AmountPaid: &paid}.Overpayment())
+	assert.Zero(t, Invoice{AmountDue: 100}.Overpayment())
+
+	// A discounted invoice is overpaid by what is paid beyond its discounted total
+	percent := 10.0
+	assert.InDelta(t, 30.0, Invoice{AmountDue: 100, Financials: &InvoiceFinancials{DiscountPercent: &percent}, AmountPaid: &paid}.Overpayment(), 0.001)
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// ClientNote is one of a client's notes. Pinned notes hold what must not be missed, e.g.
+// "always include the PO number", and are shown at the top of the client page and on the
+// invoice form.
+type ClientNote struct {
+	ID       int
+	ClientID int
+	Body     string
+	Pinned   bool
+	Updated  time.Time
+	Created  time.Time
+}
+
+// ClientNoteModel wraps the generated SQLC Queries for client note operations
+type ClientNoteModel struct {
+	queries *db.Queries
+}
+
+// NewClientNoteModel creates a new ClientNoteModel
+func NewClientNoteModel(database *sql.DB) *ClientNoteModel {
+	return &ClientNoteModel{
+		queries: newQueries(database),
+	}
+}
+
+// NewClientNoteModelWithTx creates a ClientNoteModel whose queries run inside the given transaction
+func NewClientNoteModelWithTx(tx *sql.Tx) *ClientNoteModel {
+	return &ClientNoteModel{
+		queries: newQueries(tx),
+	}
+}
+
+// Insert adds a note to a client and returns its ID
+func (m *ClientNoteModel) Insert(clientID int, body string, pinned bool) (int, error) {
+	ctx := context.Background()
+	id, err := m.queries.InsertClientNote(ctx, db.InsertClientNoteParams{
+		ClientID: int64(clientID),
+		Body:     body,
+		Pinned:   pinned,
+	})
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return int(id), nil
+}
+
+// Get retrieves a client note by ID
+func (m *ClientNoteModel) Get(id int) (ClientNote, error) {
+	ctx := context.Background()
+	row, err := m.queries.GetClientNote(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ClientNote{}, ErrNoRecord
+		}
+		return ClientNote{}, err
+	}
+	return newClientNote(row.ID, row.ClientID, row.Body, row.Pinned, row.UpdatedAt, row.CreatedAt), nil
+}
+
+// GetByClient retrieves a client's notes, pinned ones first and then the newest first
+func (m *ClientNoteModel) GetByClient(clientID int) ([]ClientNote, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetClientNotesByClient(ctx, int64(clientID))
+	if err != nil {
+		return nil, err
+	}
+
+	notes := make([]ClientNote, len(rows))
+	for i, row := range rows {
+		notes[i] = newClientNote(row.ID, row.ClientID, row.Body, row.Pinned, row.UpdatedAt, row.CreatedAt)
+	}
+	return notes, nil
+}
+
+// GetPinned retrieves a client's pinned notes, newest first
+func (m *ClientNoteModel) GetPinned(clientID int) ([]ClientNote, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetPinnedClientNotes(ctx, int64(clientID))
+	if err != nil {
+		return nil, err
+	}
+
+	notes := make([]ClientNote, len(rows))
+	for i, row := range rows {
+		notes[i] = newClientNote(row.ID, row.ClientID, row.Body, row.Pinned, row.UpdatedAt, row.CreatedAt)
+	}
+	return notes, nil
+}
+
+// Update changes the text of a note and whether it is pinned
+func (m *ClientNoteModel) Update(id int, body string, pinned bool) error {
+	ctx := context.Background()
+	return m.queries.UpdateClientNote(ctx, db.UpdateClientNoteParams{
+		Body:   body,
+		Pinned: pinned,
+		ID:     int64(id),
+	})
+}
+
+// SetPinned pins a note or unpins it
+func (m *ClientNoteModel) SetPinned(id int, pinned bool) error {
+	ctx := context.Background()
+	return m.queries.SetClientNotePinned(ctx, db.SetClientNotePinnedParams{
+		Pinned: pinned,
+		ID:     int64(id),
+	})
+}
+
+// Delete removes a note
+func (m *ClientNoteModel) Delete(id int) error {
+	ctx := context.Background()
+	return m.queries.DeleteClientNote(ctx, int64(id))
+}
+
+func newClientNote(id, clientID int64, body string, pinned bool, updated, created time.Time) ClientNote {
+	return ClientNote{
+		ID:       int(id),
+		ClientID: int(clientID),
+		Body:     body,
+		Pinned:   pinned,
+		Updated:  updated,
+		Created:  created,
+	}
+}
+
+// ClientNoteModelInterface defines the interface for client note operations
+type ClientNoteModelInterface interface {
+	Insert(clientID int, body string, pinned bool) (int, error)
+	Get(id int) (ClientNote, error)
+	GetByClient(clientID int) ([]ClientNote, error)
+	GetPinned(clientID int) ([]ClientNote, error)
+	Update(id int, body string, pinned bool) error
+	SetPinned(id int, pinned bool) error
+	Delete(id int) error
+}
+
+// Ensure implementation satisfies the interface
+var _ ClientNoteModelInterface = (*ClientNoteModel)(nil)
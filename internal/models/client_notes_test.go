@@ -0,0 +1,73 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientNoteModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewClientNoteModel(testDB.DB)
+	clientID := testDB.InsertTestClient(t, "Noted Client")
+	otherClientID := testDB.InsertTestClient(t, "Other Client")
+
+	generalID, err := model.Insert(clientID, "Prefers email to phone calls", false)
+	require.NoError(t, err)
+	poID, err := model.Insert(clientID, "Always include the PO number", true)
+	require.NoError(t, err)
+	_, err = model.Insert(otherClientID, "Invoices go to the grants office", true)
+	require.NoError(t, err)
+
+	t.Run("get returns the note", func(t *testing.T) {
+		note, err := model.Get(poID)
+		require.NoError(t, err)
+		assert.Equal(t, clientID, note.ClientID)
+		assert.Equal(t, "Always include the PO number", note.Body)
+		assert.True(t, note.Pinned)
+
+		_, err = model.Get(99999)
+		assert.ErrorIs(t, err, ErrNoRecord)
+	})
+
+	t.Run("pinned notes are listed first", func(t *testing.T) {
+		notes, err := model.GetByClient(clientID)
+		require.NoError(t, err)
+		require.Len(t, notes, 2)
+		assert.Equal(t, poID, notes[0].ID)
+		assert.Equal(t, generalID, notes[1].ID)
+
+		pinned, err := model.GetPinned(clientID)
+		require.NoError(t, err)
+		require.Len(t, pinned, 1)
+		assert.Equal(t, poID, pinned[0].ID)
+	})
+
+	t.Run("notes can be pinned, edited and deleted", func(t *testing.T) {
+		require.NoError(t, model.SetPinned(generalID, true))
+		require.NoError(t, model.Update(poID, "Always include PO 4711", false))
+
+		pinned, err := model.GetPinned(clientID)
+		require.NoError(t, err)
+		require.Len(t, pinned, 1)
+		assert.Equal(t, generalID, pinned[0].ID)
+
+		note, err := model.Get(poID)
+		require.NoError(t, err)
+		assert.Equal(t, "Always include PO 4711", note.Body)
+		assert.False(t, note.Pinned)
+
+		require.NoError(t, model.Delete(poID))
+		_, err = model.Get(poID)
+		assert.ErrorIs(t, err, ErrNoRecord)
+	})
+
+	t.Run("a note needs an existing client", func(t *testing.T) {
+		_, err := model.Insert(99999, "Nobody", false)
+		assert.Error(t, err)
+	})
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"math"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
@@ -21,8 +24,8 @@ type Client struct {
 	City                    *string
 	State                   *string
 	ZipCode                 *string
+	Country                 *string
 	HourlyRate              float64
-	Notes                   *string
 	AdditionalInfo          *string
 	AdditionalInfo2         *string
 	BillTo                  *string
@@ -30,46 +33,293 @@ type Client struct {
 	InvoiceCCEmail          *string
 	InvoiceCCDescription    *string
 	UniversityAffiliation   *string
+	BusinessProfileID       *int
+	MonthlyHourAllowance    *float64
+	PrepayOnly              bool
+	ConsolidatedInvoicing   bool
+	FiscalYearEnd           *string
+	PONumber                *string
+	POValidFrom             *time.Time
+	POValidTo               *time.Time
+	InvoiceLanguage         *string
+	InvoicePrefix           *string
+	NextInvoiceNumber       *int
+	BudgetCode              *string
+	PipelineStage           string
 	Updated                 time.Time
 	Created                 time.Time
 	DeletedAt               *time.Time
 }
 
+// The stages of the sales pipeline a prospect moves through before it becomes a client
+const (
+	PipelineStageLead         = "Lead"
+	PipelineStageProposalSent = "Proposal Sent"
+	PipelineStageWon          = "Won"
+	PipelineStageLost         = "Lost"
+)
+
+// PipelineStages lists the pipeline stages in the order prospects move through them
+var PipelineStages = []string{PipelineStageLead, PipelineStageProposalSent, PipelineStageWon, PipelineStageLost}
+
+// IsPipelineStage reports whether stage is one of the PipelineStages
+func IsPipelineStage(stage string) bool {
+	return slices.Contains(PipelineStages, stage)
+}
+
+// IsProspect reports whether the client is still a prospect in the sales pipeline. Projects
+// can't be created for a prospect until it is converted to a client.
+func (c Client) IsProspect() bool {
+	return c.PipelineStage != ""
+}
+
+// PipelineColumn is one stage of the pipeline board and the prospects at that stage
+type PipelineColumn struct {
+	Stage     string
+	Prospects []Client
+}
+
+// NewPipeline sorts prospects into a column for each pipeline stage, keeping their order.
+// Active clients are left out.
+func NewPipeline(clients []Client) []PipelineColumn {
+	columns := make([]PipelineColumn, len(PipelineStages))
+	for i, stage := range PipelineStages {
+		columns[i].Stage = stage
+	}
+	for _, client := range clients {
+		if i := slices.Index(PipelineStages, client.PipelineStage); i >= 0 {
+			columns[i].Prospects = append(columns[i].Prospects, client)
+		}
+	}
+	return columns
+}
+
+// fiscalYearEndLayout is how a fiscal year end is written, as a month and day
+const fiscalYearEndLayout = "01-02"
+
+// ParseFiscalYearEnd reads a fiscal year end written as MM-DD, e.g. 06-30
+func ParseFiscalYearEnd(value string) (time.Month, int, error) {
+	t, err := time.Parse(fiscalYearEndLayout, value)
+	if err != nil {
+		return 0, 0, err
+	}
+	return t.Month(), t.Day(), nil
+}
+
+// FiscalYearEndFor returns the last day of the client's fiscal year that date falls in, or
+// nil when the client has no fiscal year end. A year ending on 29 February ends on the 28th
+// in other years.
+func (c Client) FiscalYearEndFor(date time.Time) *time.Time {
+	if c.FiscalYearEnd == nil {
+		return nil
+	}
+	month, day, err := ParseFiscalYearEnd(*c.FiscalYearEnd)
+	if err != nil {
+		return nil
+	}
+
+	endIn := func(year int) time.Time {
+		lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		return time.Date(year, month, min(day, lastDay), 0, 0, 0, 0, time.UTC)
+	}
+	end := endIn(date.Year())
+	if daysBetween(end, date) > 0 {
+		end = endIn(date.Year() + 1)
+	}
+	return &end
+}
+
+// HasPOPeriod reports whether the client's purchase order is only valid between certain dates
+func (c Client) HasPOPeriod() bool {
+	return c.POValidFrom != nil || c.POValidTo != nil
+}
+
+// InPOPeriod reports whether date falls within the dates the client's purchase order is
+// valid for. Either end may be left open, and a client without PO dates accepts any date.
+func (c Client) InPOPeriod(date time.Time) bool {
+	if c.POValidFrom != nil && daysBetween(*c.POValidFrom, date) < 0 {
+		return false
+	}
+	if c.POValidTo != nil && daysBetween(*c.POValidTo, date) > 0 {
+		return false
+	}
+	return true
+}
+
+// HourUsage compares the hours logged for a client in a month against its allowance
+type HourUsage struct {
+	Month     time.Time
+	Used      float64
+	Allowance float64
+}
+
+// NewHourUsage returns the usage for a client, or nil when it has no monthly allowance
+func NewHourUsage(client Client, month time.Time, used float64) *HourUsage {
+	if client.MonthlyHourAllowance == nil {
+		return nil
+	}
+	return &HourUsage{Month: month, Used: used, Allowance: *client.MonthlyHourAllowance}
+}
+
+// Remaining returns the hours left in the allowance, which is negative once over the cap
+func (u HourUsage) Remaining() float64 {
+	return u.Allowance - u.Used
+}
+
+// Over reports whether the hours used exceed the allowance
+func (u HourUsage) Over() bool {
+	return u.Used > u.Allowance
+}
+
+// Percent returns the share of the allowance used, capped at 100 for display
+func (u HourUsage) Percent() float64 {
+	if u.Allowance <= 0 {
+		return 0
+	}
+	return math.Min(u.Used/u.Allowance*100, 100)
+}
+
+// Payment ratings, from a client's average days late across its paid invoices
+const (
+	PaymentRatingPrompt = "prompt"
+	PaymentRatingLate   = "late"
+	PaymentRatingSlow   = "slow"
+)
+
+// Average days late up to which a client still counts as paying promptly, or as paying late
+// rather than slowly
+const (
+	promptPaymentDays = 3
+	latePaymentDays   = 30
+)
+
+// PaymentBehavior summarises how promptly a client has paid its invoices
+type PaymentBehavior struct {
+	PaidInvoices int
+	LateInvoices int
+	AvgDaysToPay float64
+	AvgDaysLate  float64
+}
+
+// NewPaymentBehavior works out a client's payment behavior from its paid invoices. An
+// invoice without a due date is due according to its payment terms.
+func NewPaymentBehavior(invoices []Invoice) PaymentBehavior {
+	var behavior PaymentBehavior
+	var daysToPay, daysLate int
+	for _, invoice := range invoices {
+		if invoice.DatePaid == nil {
+			continue
+		}
+		dueDate := DueDateFor(invoice.InvoiceDate, invoice.PaymentTerms)
+		if invoice.DueDate != nil {
+			dueDate = *invoice.DueDate
+		}
+
+		behavior.PaidInvoices++
+		daysToPay += max(daysBetween(invoice.InvoiceDate, *invoice.DatePaid), 0)
+		if late := daysBetween(dueDate, *invoice.DatePaid); late > 0 {
+			behavior.LateInvoices++
+			daysLate += late
+		}
+	}
+
+	if behavior.PaidInvoices > 0 {
+		behavior.AvgDaysToPay = float64(daysToPay) / float64(behavior.PaidInvoices)
+		behavior.AvgDaysLate = float64(daysLate) / float64(behavior.PaidInvoices)
+	}
+	return behavior
+}
+
+// daysBetween returns the number of calendar days from one date to another
+func daysBetween(from, to time.Time) int {
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(math.Round(toDay.Sub(fromDay).Hours() / 24))
+}
+
+// Rating classifies the client's payments, or is blank when nothing has been paid yet
+func (b PaymentBehavior) Rating() string {
+	switch {
+	case b.PaidInvoices == 0:
+		return ""
+	case b.AvgDaysLate <= promptPaymentDays:
+		return PaymentRatingPrompt
+	case b.AvgDaysLate <= latePaymentDays:
+		return PaymentRatingLate
+	default:
+		return PaymentRatingSlow
+	}
+}
+
+// Label describes the rating for display
+func (b PaymentBehavior) Label() string {
+	switch b.Rating() {
+	case PaymentRatingPrompt:
+		return "Pays on time"
+	case PaymentRatingLate:
+		return "Often pays late"
+	case PaymentRatingSlow:
+		return "Slow payer"
+	}
+	return "No payment history"
+}
+
 // ClientModel wraps the generated SQLC Queries for client operations
 type ClientModel struct {
+	db      *sql.DB
 	queries *db.Queries
 }
 
 // NewClientModel creates a new ClientModel
 func NewClientModel(database *sql.DB) *ClientModel {
 	return &ClientModel{
-		queries: db.New(database),
+		db:      database,
+		queries: newQueries(database),
+	}
+}
+
+// NewClientModelWithTx creates a ClientModel whose queries run inside the given transaction
+func NewClientModelWithTx(tx *sql.Tx) *ClientModel {
+	return &ClientModel{
+		queries: newQueries(tx),
 	}
 }
 
 // Insert adds a new client to the database and returns its ID
-func (c *ClientModel) Insert(name, email string, phone, address1, address2, address3, city, state, zipCode *string, hourlyRate float64, notes, additionalInfo, additionalInfo2, billTo *string, includeAddressOnInvoice bool, invoiceCCEmail, invoiceCCDescription, universityAffiliation *string) (int, error) {
+func (c *ClientModel) Insert(client Client) (int, error) {
 	ctx := context.Background()
 
 	params := db.InsertClientParams{
-		Name:                    name,
-		Email:                   email,
-		Phone:                   convertStringPtr(phone),
-		Address1:                convertStringPtr(address1),
-		Address2:                convertStringPtr(address2),
-		Address3:                convertStringPtr(address3),
-		City:                    convertStringPtr(city),
-		State:                   convertStringPtr(state),
-		ZipCode:                 convertStringPtr(zipCode),
-		HourlyRate:              hourlyRate,
-		Notes:                   convertStringPtr(notes),
-		AdditionalInfo:          convertStringPtr(additionalInfo),
-		AdditionalInfo2:         convertStringPtr(additionalInfo2),
-		BillTo:                  convertStringPtr(billTo),
-		IncludeAddressOnInvoice: includeAddressOnInvoice,
-		InvoiceCcEmail:          convertStringPtr(invoiceCCEmail),
-		InvoiceCcDescription:    convertStringPtr(invoiceCCDescription),
-		UniversityAffiliation:   convertStringPtr(universityAffiliation),
+		Name:                    client.Name,
+		Email:                   client.Email,
+		Phone:                   convertStringPtr(client.Phone),
+		Address1:                convertStringPtr(client.Address1),
+		Address2:                convertStringPtr(client.Address2),
+		Address3:                convertStringPtr(client.Address3),
+		City:                    convertStringPtr(client.City),
+		State:                   convertStringPtr(client.State),
+		ZipCode:                 convertStringPtr(client.ZipCode),
+		Country:                 convertStringPtr(client.Country),
+		HourlyRate:              client.HourlyRate,
+		AdditionalInfo:          convertStringPtr(client.AdditionalInfo),
+		AdditionalInfo2:         convertStringPtr(client.AdditionalInfo2),
+		BillTo:                  convertStringPtr(client.BillTo),
+		IncludeAddressOnInvoice: client.IncludeAddressOnInvoice,
+		InvoiceCcEmail:          convertStringPtr(client.InvoiceCCEmail),
+		InvoiceCcDescription:    convertStringPtr(client.InvoiceCCDescription),
+		UniversityAffiliation:   convertStringPtr(client.UniversityAffiliation),
+		BusinessProfileID:       convertIntPtr(client.BusinessProfileID),
+		MonthlyHourAllowance:    convertFloatPtr(client.MonthlyHourAllowance),
+		PrepayOnly:              client.PrepayOnly,
+		ConsolidatedInvoicing:   client.ConsolidatedInvoicing,
+		FiscalYearEnd:           convertStringPtr(client.FiscalYearEnd),
+		PoNumber:                convertStringPtr(client.PONumber),
+		PoValidFrom:             convertDatePtr(client.POValidFrom),
+		PoValidTo:               convertDatePtr(client.POValidTo),
+		InvoiceLanguage:         convertStringPtr(client.InvoiceLanguage),
+		InvoicePrefix:           convertStringPtr(client.InvoicePrefix),
+		NextInvoiceNumber:       convertIntPtr(client.NextInvoiceNumber),
+		BudgetCode:              convertStringPtr(client.BudgetCode),
 	}
 
 	id, err := c.queries.InsertClient(ctx, params)
@@ -95,6 +345,67 @@ func convertNullString(ns sql.NullString) *string {
 	return &ns.String
 }
 
+// Helper function to convert *int to sql.NullInt64
+func convertIntPtr(i *int) sql.NullInt64 {
+	if i == nil {
+		return sql.NullInt64{Valid: false}
+	}
+	return sql.NullInt64{Int64: int64(*i), Valid: true}
+}
+
+// Helper function to convert sql.NullInt64 to *int
+func convertNullInt64(ni sql.NullInt64) *int {
+	if !ni.Valid {
+		return nil
+	}
+	i := int(ni.Int64)
+	return &i
+}
+
+// Helper function to convert *float64 to sql.NullFloat64
+func convertFloatPtr(f *float64) sql.NullFloat64 {
+	if f == nil {
+		return sql.NullFloat64{Valid: false}
+	}
+	return sql.NullFloat64{Float64: *f, Valid: true}
+}
+
+// Helper function to convert sql.NullFloat64 to *float64
+func convertNullFloat64(nf sql.NullFloat64) *float64 {
+	if !nf.Valid {
+		return nil
+	}
+	return &nf.Float64
+}
+
+// Helper function to convert sql.NullTime to *time.Time
+func convertNullTime(nt sql.NullTime) *time.Time {
+	if !nt.Valid {
+		return nil
+	}
+	return &nt.Time
+}
+
+// Helper function to convert a *time.Time date to sql.NullString
+func convertDatePtr(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{Valid: false}
+	}
+	return sql.NullString{String: t.Format(isoLayout), Valid: true}
+}
+
+// Helper function to convert a sql.NullString date to *time.Time
+func convertNullDate(ns sql.NullString) *time.Time {
+	if !ns.Valid || ns.String == "" {
+		return nil
+	}
+	t, err := time.Parse(isoLayout, ns.String)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
 // Get retrieves a client by ID
 func (c *ClientModel) Get(id int) (Client, error) {
 	ctx := context.Background()
@@ -124,8 +435,8 @@ func (c *ClientModel) Get(id int) (Client, error) {
 		City:                    convertNullString(row.City),
 		State:                   convertNullString(row.State),
 		ZipCode:                 convertNullString(row.ZipCode),
+		Country:                 convertNullString(row.Country),
 		HourlyRate:              row.HourlyRate,
-		Notes:                   convertNullString(row.Notes),
 		AdditionalInfo:          convertNullString(row.AdditionalInfo),
 		AdditionalInfo2:         convertNullString(row.AdditionalInfo2),
 		BillTo:                  convertNullString(row.BillTo),
@@ -133,6 +444,19 @@ func (c *ClientModel) Get(id int) (Client, error) {
 		InvoiceCCEmail:          convertNullString(row.InvoiceCcEmail),
 		InvoiceCCDescription:    convertNullString(row.InvoiceCcDescription),
 		UniversityAffiliation:   convertNullString(row.UniversityAffiliation),
+		BusinessProfileID:       convertNullInt64(row.BusinessProfileID),
+		MonthlyHourAllowance:    convertNullFloat64(row.MonthlyHourAllowance),
+		PrepayOnly:              row.PrepayOnly,
+		ConsolidatedInvoicing:   row.ConsolidatedInvoicing,
+		FiscalYearEnd:           convertNullString(row.FiscalYearEnd),
+		PONumber:                convertNullString(row.PoNumber),
+		POValidFrom:             convertNullDate(row.PoValidFrom),
+		POValidTo:               convertNullDate(row.PoValidTo),
+		InvoiceLanguage:         convertNullString(row.InvoiceLanguage),
+		InvoicePrefix:           convertNullString(row.InvoicePrefix),
+		NextInvoiceNumber:       convertNullInt64(row.NextInvoiceNumber),
+		BudgetCode:              convertNullString(row.BudgetCode),
+		PipelineStage:           row.PipelineStage.String,
 		Updated:                 row.UpdatedAt,
 		Created:                 row.CreatedAt,
 		DeletedAt:               deletedAt,
@@ -169,8 +493,8 @@ func (c *ClientModel) GetAll() ([]Client, error) {
 			City:                    convertNullString(row.City),
 			State:                   convertNullString(row.State),
 			ZipCode:                 convertNullString(row.ZipCode),
+			Country:                 convertNullString(row.Country),
 			HourlyRate:              row.HourlyRate,
-			Notes:                   convertNullString(row.Notes),
 			AdditionalInfo:          convertNullString(row.AdditionalInfo),
 			AdditionalInfo2:         convertNullString(row.AdditionalInfo2),
 			BillTo:                  convertNullString(row.BillTo),
@@ -178,6 +502,19 @@ func (c *ClientModel) GetAll() ([]Client, error) {
 			InvoiceCCEmail:          convertNullString(row.InvoiceCcEmail),
 			InvoiceCCDescription:    convertNullString(row.InvoiceCcDescription),
 			UniversityAffiliation:   convertNullString(row.UniversityAffiliation),
+			BusinessProfileID:       convertNullInt64(row.BusinessProfileID),
+			MonthlyHourAllowance:    convertNullFloat64(row.MonthlyHourAllowance),
+			PrepayOnly:              row.PrepayOnly,
+			ConsolidatedInvoicing:   row.ConsolidatedInvoicing,
+			FiscalYearEnd:           convertNullString(row.FiscalYearEnd),
+			PONumber:                convertNullString(row.PoNumber),
+			POValidFrom:             convertNullDate(row.PoValidFrom),
+			POValidTo:               convertNullDate(row.PoValidTo),
+			InvoiceLanguage:         convertNullString(row.InvoiceLanguage),
+			InvoicePrefix:           convertNullString(row.InvoicePrefix),
+			NextInvoiceNumber:       convertNullInt64(row.NextInvoiceNumber),
+			BudgetCode:              convertNullString(row.BudgetCode),
+			PipelineStage:           row.PipelineStage.String,
 			Updated:                 row.UpdatedAt,
 			Created:                 row.CreatedAt,
 			DeletedAt:               deletedAt,
@@ -188,36 +525,111 @@ func (c *ClientModel) GetAll() ([]Client, error) {
 }
 
 // Update modifies an existing client in the database
-func (c *ClientModel) Update(id int, name, email string, phone, address1, address2, address3, city, state, zipCode *string, hourlyRate float64, notes, additionalInfo, additionalInfo2, billTo *string, includeAddressOnInvoice bool, invoiceCCEmail, invoiceCCDescription, universityAffiliation *string) error {
+func (c *ClientModel) Update(client Client) error {
 	ctx := context.Background()
 	params := db.UpdateClientParams{
-		ID:                      int64(id),
-		Name:                    name,
-		Email:                   email,
-		Phone:                   convertStringPtr(phone),
-		Address1:                convertStringPtr(address1),
-		Address2:                convertStringPtr(address2),
-		Address3:                convertStringPtr(address3),
-		City:                    convertStringPtr(city),
-		State:                   convertStringPtr(state),
-		ZipCode:                 convertStringPtr(zipCode),
-		HourlyRate:              hourlyRate,
-		Notes:                   convertStringPtr(notes),
-		AdditionalInfo:          convertStringPtr(additionalInfo),
-		AdditionalInfo2:         convertStringPtr(additionalInfo2),
-		BillTo:                  convertStringPtr(billTo),
-		IncludeAddressOnInvoice: includeAddressOnInvoice,
-		InvoiceCcEmail:          convertStringPtr(invoiceCCEmail),
-		InvoiceCcDescription:    convertStringPtr(invoiceCCDescription),
-		UniversityAffiliation:   convertStringPtr(universityAffiliation),
+		ID:                      int64(client.ID),
+		Name:                    client.Name,
+		Email:                   client.Email,
+		Phone:                   convertStringPtr(client.Phone),
+		Address1:                convertStringPtr(client.Address1),
+		Address2:                convertStringPtr(client.Address2),
+		Address3:                convertStringPtr(client.Address3),
+		City:                    convertStringPtr(client.City),
+		State:                   convertStringPtr(client.State),
+		ZipCode:                 convertStringPtr(client.ZipCode),
+		Country:                 convertStringPtr(client.Country),
+		HourlyRate:              client.HourlyRate,
+		AdditionalInfo:          convertStringPtr(client.AdditionalInfo),
+		AdditionalInfo2:         convertStringPtr(client.AdditionalInfo2),
+		BillTo:                  convertStringPtr(client.BillTo),
+		IncludeAddressOnInvoice: client.IncludeAddressOnInvoice,
+		InvoiceCcEmail:          convertStringPtr(client.InvoiceCCEmail),
+		InvoiceCcDescription:    convertStringPtr(client.InvoiceCCDescription),
+		UniversityAffiliation:   convertStringPtr(client.UniversityAffiliation),
+		BusinessProfileID:       convertIntPtr(client.BusinessProfileID),
+		MonthlyHourAllowance:    convertFloatPtr(client.MonthlyHourAllowance),
+		PrepayOnly:              client.PrepayOnly,
+		ConsolidatedInvoicing:   client.ConsolidatedInvoicing,
+		FiscalYearEnd:           convertStringPtr(client.FiscalYearEnd),
+		PoNumber:                convertStringPtr(client.PONumber),
+		PoValidFrom:             convertDatePtr(client.POValidFrom),
+		PoValidTo:               convertDatePtr(client.POValidTo),
+		InvoiceLanguage:         convertStringPtr(client.InvoiceLanguage),
+		InvoicePrefix:           convertStringPtr(client.InvoicePrefix),
+		NextInvoiceNumber:       convertIntPtr(client.NextInvoiceNumber),
+		BudgetCode:              convertStringPtr(client.BudgetCode),
 	}
 	return c.queries.UpdateClient(ctx, params)
 }
 
-// Delete soft deletes a client by setting the deleted_at timestamp
+// SetPipelineStage moves a prospect to another stage of the sales pipeline. An empty stage
+// converts the prospect to an active client.
+func (c *ClientModel) SetPipelineStage(id int, stage string) error {
+	ctx := context.Background()
+	return c.queries.SetClientPipelineStage(ctx, db.SetClientPipelineStageParams{
+		PipelineStage: sql.NullString{String: stage, Valid: stage != ""},
+		ID:            int64(id),
+	})
+}
+
+// NextInvoiceNumber returns the next invoice number in the client's own sequence, formatted
+// with the client's prefix, and advances the sequence in the same statement. It returns
+// ErrNoRecord when the client has no sequence of its own.
+func (c *ClientModel) NextInvoiceNumber(id int) (string, error) {
+	ctx := context.Background()
+	row, err := c.queries.ClaimClientInvoiceNumber(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNoRecord
+		}
+		return "", err
+	}
+
+	sequence := InvoiceSequence{Prefix: row.InvoicePrefix.String}
+	return sequence.Format(int(row.NextInvoiceNumber.Int64) - 1), nil
+}
+
+// Delete soft deletes a client along with its projects and their timesheets, invoices and
+// milestones, all of which are given the client's deleted_at timestamp
 func (c *ClientModel) Delete(id int) error {
 	ctx := context.Background()
-	return c.queries.DeleteClient(ctx, int64(id))
+	return inTx(ctx, c.db, c.queries, func(q *db.Queries) error {
+		if err := q.DeleteClient(ctx, int64(id)); err != nil {
+			return err
+		}
+		if err := q.DeleteClientProjects(ctx, int64(id)); err != nil {
+			return err
+		}
+		if err := q.DeleteClientTimesheets(ctx, int64(id)); err != nil {
+			return err
+		}
+		if err := q.DeleteClientInvoices(ctx, int64(id)); err != nil {
+			return err
+		}
+		return q.DeleteClientMilestones(ctx, int64(id))
+	})
+}
+
+// Restore undeletes a client along with the projects, timesheets, invoices and milestones
+// deleted with it
+func (c *ClientModel) Restore(id int) error {
+	ctx := context.Background()
+	return inTx(ctx, c.db, c.queries, func(q *db.Queries) error {
+		if err := q.RestoreClientTimesheets(ctx, int64(id)); err != nil {
+			return err
+		}
+		if err := q.RestoreClientInvoices(ctx, int64(id)); err != nil {
+			return err
+		}
+		if err := q.RestoreClientMilestones(ctx, int64(id)); err != nil {
+			return err
+		}
+		if err := q.RestoreClientProjects(ctx, int64(id)); err != nil {
+			return err
+		}
+		return q.RestoreClient(ctx, int64(id))
+	})
 }
 
 // GetWithPagination retrieves clients with pagination
@@ -233,60 +645,177 @@ func (c *ClientModel) GetWithPagination(limit, offset int64) ([]Client, error) {
 
 	var clients []Client
 	for _, row := range rows {
-		var deletedAt *time.Time
-		if row.DeletedAt != nil {
-			if timeStr, ok := row.DeletedAt.(string); ok {
-				parsedTime, err := time.Parse("2006-01-02 15:04:05", timeStr)
-				if err == nil {
-					deletedAt = &parsedTime
-				}
-			}
-		}
+		clients = append(clients, clientFromPaginationRow(row))
+	}
 
-		clients = append(clients, Client{
-			ID:                      int(row.ID),
-			Name:                    row.Name,
-			Email:                   row.Email,
-			Phone:                   convertNullString(row.Phone),
-			Address1:                convertNullString(row.Address1),
-			Address2:                convertNullString(row.Address2),
-			Address3:                convertNullString(row.Address3),
-			City:                    convertNullString(row.City),
-			State:                   convertNullString(row.State),
-			ZipCode:                 convertNullString(row.ZipCode),
-			HourlyRate:              row.HourlyRate,
-			Notes:                   convertNullString(row.Notes),
-			AdditionalInfo:          convertNullString(row.AdditionalInfo),
-			AdditionalInfo2:         convertNullString(row.AdditionalInfo2),
-			BillTo:                  convertNullString(row.BillTo),
-			IncludeAddressOnInvoice: row.IncludeAddressOnInvoice,
-			InvoiceCCEmail:          convertNullString(row.InvoiceCcEmail),
-			InvoiceCCDescription:    convertNullString(row.InvoiceCcDescription),
-			UniversityAffiliation:   convertNullString(row.UniversityAffiliation),
-			Updated:                 row.UpdatedAt,
-			Created:                 row.CreatedAt,
-			DeletedAt:               deletedAt,
-		})
+	return clients, nil
+}
+
+// GetWithPaginationByTag retrieves a page of the clients carrying the given tag
+func (c *ClientModel) GetWithPaginationByTag(tagID int, limit, offset int64) ([]Client, error) {
+	ctx := context.Background()
+	rows, err := c.queries.GetClientsByTagWithPagination(ctx, db.GetClientsByTagWithPaginationParams{
+		TagID:  int64(tagID),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var clients []Client
+	for _, row := range rows {
+		clients = append(clients, clientFromPaginationRow(db.GetClientsWithPaginationRow(row)))
 	}
 
 	return clients, nil
 }
 
+// ClientMatch is a client found by Search
+type ClientMatch struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Search finds up to limit clients whose name contains query, the most recently updated
+// first. A blank query returns the most recently updated clients.
+func (c *ClientModel) Search(query string, limit int) ([]ClientMatch, error) {
+	ctx := context.Background()
+	rows, err := c.queries.SearchClients(ctx, db.SearchClientsParams{
+		Pattern: "%" + likeEscaper.Replace(strings.TrimSpace(query)) + "%",
+		Limit:   int64(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]ClientMatch, len(rows))
+	for i, row := range rows {
+		matches[i] = ClientMatch{ID: int(row.ID), Name: row.Name}
+	}
+	return matches, nil
+}
+
+// GetByIDs retrieves the non-deleted clients with the given IDs, in the order of the IDs
+func (c *ClientModel) GetByIDs(ids []int) ([]Client, error) {
+	ctx := context.Background()
+	rows, err := c.queries.GetClientsByIDs(ctx, toInt64s(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make([]Client, len(rows))
+	for i, row := range rows {
+		clients[i] = clientFromPaginationRow(db.GetClientsWithPaginationRow(row))
+	}
+	sortByIDs(clients, ids, func(client Client) int { return client.ID })
+	return clients, nil
+}
+
+// clientFromPaginationRow converts a client list row to a Client
+func clientFromPaginationRow(row db.GetClientsWithPaginationRow) Client {
+	var deletedAt *time.Time
+	if row.DeletedAt != nil {
+		if timeStr, ok := row.DeletedAt.(string); ok {
+			parsedTime, err := time.Parse("2006-01-02 15:04:05", timeStr)
+			if err == nil {
+				deletedAt = &parsedTime
+			}
+		}
+	}
+
+	return Client{
+		ID:                      int(row.ID),
+		Name:                    row.Name,
+		Email:                   row.Email,
+		Phone:                   convertNullString(row.Phone),
+		Address1:                convertNullString(row.Address1),
+		Address2:                convertNullString(row.Address2),
+		Address3:                convertNullString(row.Address3),
+		City:                    convertNullString(row.City),
+		State:                   convertNullString(row.State),
+		ZipCode:                 convertNullString(row.ZipCode),
+		Country:                 convertNullString(row.Country),
+		HourlyRate:              row.HourlyRate,
+		AdditionalInfo:          convertNullString(row.AdditionalInfo),
+		AdditionalInfo2:         convertNullString(row.AdditionalInfo2),
+		BillTo:                  convertNullString(row.BillTo),
+		IncludeAddressOnInvoice: row.IncludeAddressOnInvoice,
+		InvoiceCCEmail:          convertNullString(row.InvoiceCcEmail),
+		InvoiceCCDescription:    convertNullString(row.InvoiceCcDescription),
+		UniversityAffiliation:   convertNullString(row.UniversityAffiliation),
+		BusinessProfileID:       convertNullInt64(row.BusinessProfileID),
+		MonthlyHourAllowance:    convertNullFloat64(row.MonthlyHourAllowance),
+		PrepayOnly:              row.PrepayOnly,
+		ConsolidatedInvoicing:   row.ConsolidatedInvoicing,
+		FiscalYearEnd:           convertNullString(row.FiscalYearEnd),
+		PONumber:                convertNullString(row.PoNumber),
+		POValidFrom:             convertNullDate(row.PoValidFrom),
+		POValidTo:               convertNullDate(row.PoValidTo),
+		InvoiceLanguage:         convertNullString(row.InvoiceLanguage),
+		InvoicePrefix:           convertNullString(row.InvoicePrefix),
+		NextInvoiceNumber:       convertNullInt64(row.NextInvoiceNumber),
+		BudgetCode:              convertNullString(row.BudgetCode),
+		PipelineStage:           row.PipelineStage.String,
+		Updated:                 row.UpdatedAt,
+		Created:                 row.CreatedAt,
+		DeletedAt:               deletedAt,
+	}
+}
+
 // GetCount returns the total count of non-deleted clients
 func (c *ClientModel) GetCount() (int64, error) {
 	ctx := context.Background()
 	return c.queries.GetClientsCount(ctx)
 }
 
+// GetCountByTag returns the number of non-deleted clients carrying the given tag
+func (c *ClientModel) GetCountByTag(tagID int) (int64, error) {
+	ctx := context.Background()
+	return c.queries.GetClientsByTagCount(ctx, int64(tagID))
+}
+
+// PaymentBehavior works out how promptly a client pays from the invoices it has paid.
+// Voided invoices are left out.
+func (c *ClientModel) PaymentBehavior(id int) (PaymentBehavior, error) {
+	ctx := context.Background()
+	rows, err := c.queries.GetClientPaidInvoices(ctx, int64(id))
+	if err != nil {
+		return PaymentBehavior{}, err
+	}
+
+	invoices := make([]Invoice, 0, len(rows))
+	for _, row := range rows {
+		if !row.DatePaid.Valid {
+			continue
+		}
+		invoices = append(invoices, Invoice{
+			InvoiceDate:  row.InvoiceDate.Time,
+			DatePaid:     row.DatePaid.Ptr(),
+			PaymentTerms: row.PaymentTerms,
+			DueDate:      row.DueDate.Ptr(),
+		})
+	}
+	return NewPaymentBehavior(invoices), nil
+}
+
 // ClientModelInterface defines the interface for client operations
 type ClientModelInterface interface {
-	Insert(name, email string, phone, address1, address2, address3, city, state, zipCode *string, hourlyRate float64, notes, additionalInfo, additionalInfo2, billTo *string, includeAddressOnInvoice bool, invoiceCCEmail, invoiceCCDescription, universityAffiliation *string) (int, error)
+	Insert(client Client) (int, error)
 	Get(id int) (Client, error)
 	GetAll() ([]Client, error)
 	GetWithPagination(limit, offset int64) ([]Client, error)
 	GetCount() (int64, error)
-	Update(id int, name, email string, phone, address1, address2, address3, city, state, zipCode *string, hourlyRate float64, notes, additionalInfo, additionalInfo2, billTo *string, includeAddressOnInvoice bool, invoiceCCEmail, invoiceCCDescription, universityAffiliation *string) error
+	GetWithPaginationByTag(tagID int, limit, offset int64) ([]Client, error)
+	GetCountByTag(tagID int) (int64, error)
+	GetByIDs(ids []int) ([]Client, error)
+	Search(query string, limit int) ([]ClientMatch, error)
+	Update(client Client) error
+	SetPipelineStage(id int, stage string) error
+	NextInvoiceNumber(id int) (string, error)
 	Delete(id int) error
+	Restore(id int) error
+	PaymentBehavior(id int) (PaymentBehavior, error)
 }
 
 // Ensure implementation satisfies the interface
@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
 	"github.com/stretchr/testify/assert"
@@ -22,7 +23,12 @@ func TestClientModel_Insert(t *testing.T) {
 		name := "Test Client"
 		email := "test@example.com"
 		hourlyRate := 50.0
-		id, err := model.Insert(name, email, nil, nil, nil, nil, nil, nil, nil, hourlyRate, nil, nil, nil, nil, true, nil, nil, nil)
+		id, err := model.Insert(Client{
+			Name:                    name,
+			Email:                   email,
+			HourlyRate:              hourlyRate,
+			IncludeAddressOnInvoice: true,
+		})
 
 		require.NoError(t, err)
 		assert.Greater(t, id, 0)
@@ -37,7 +43,12 @@ func TestClientModel_Insert(t *testing.T) {
 	t.Run("insert empty name", func(t *testing.T) {
 		testDB.TruncateTable(t, "client")
 
-		id, err := model.Insert("", "test@example.com", nil, nil, nil, nil, nil, nil, nil, 50.0, nil, nil, nil, nil, true, nil, nil, nil)
+		id, err := model.Insert(Client{
+			Name:                    "",
+			Email:                   "test@example.com",
+			HourlyRate:              50.0,
+			IncludeAddressOnInvoice: true,
+		})
 
 		// Should succeed at database level (validation happens at handler level)
 		require.NoError(t, err)
@@ -145,7 +156,12 @@ func TestClientModel_Integration(t *testing.T) {
 		clientName := "Integration Test Client"
 		email := "integration@example.com"
 		hourlyRate := 75.0
-		id, err := model.Insert(clientName, email, nil, nil, nil, nil, nil, nil, nil, hourlyRate, nil, nil, nil, nil, true, nil, nil, nil)
+		id, err := model.Insert(Client{
+			Name:                    clientName,
+			Email:                   email,
+			HourlyRate:              hourlyRate,
+			IncludeAddressOnInvoice: true,
+		})
 		require.NoError(t, err)
 		assert.Greater(t, id, 0)
 
@@ -184,7 +200,12 @@ func TestClientModelInterface(t *testing.T) {
 			name := "Interface Test Client"
 
 			// Insert
-			id, err := test.impl.Insert(name, "interface@example.com", nil, nil, nil, nil, nil, nil, nil, 60.0, nil, nil, nil, nil, true, nil, nil, nil)
+			id, err := test.impl.Insert(Client{
+				Name:                    name,
+				Email:                   "interface@example.com",
+				HourlyRate:              60.0,
+				IncludeAddressOnInvoice: true,
+			})
 			require.NoError(t, err)
 			assert.Greater(t, id, 0)
 
@@ -223,7 +244,13 @@ func TestClientModel_Update(t *testing.T) {
 		newName := "Updated Client"
 		newEmail := "updated@example.com"
 		newHourlyRate := 65.0
-		err := model.Update(id, newName, newEmail, nil, nil, nil, nil, nil, nil, nil, newHourlyRate, nil, nil, nil, nil, true, nil, nil, nil)
+		err := model.Update(Client{
+			ID:                      id,
+			Name:                    newName,
+			Email:                   newEmail,
+			HourlyRate:              newHourlyRate,
+			IncludeAddressOnInvoice: true,
+		})
 		require.NoError(t, err)
 
 		// Verify the client was updated
@@ -237,10 +264,35 @@ func TestClientModel_Update(t *testing.T) {
 		assert.True(t, client.Updated.After(client.Created) || client.Updated.Equal(client.Created))
 	})
 
+	t.Run("country is saved", func(t *testing.T) {
+		testDB.TruncateTable(t, "client")
+		id := testDB.InsertTestClient(t, "Berlin Client")
+
+		country := "DE"
+		err := model.Update(Client{ID: id, Name: "Berlin Client", Email: "berlin@example.com", Country: &country})
+		require.NoError(t, err)
+
+		client, err := model.Get(id)
+		require.NoError(t, err)
+		require.NotNil(t, client.Country)
+		assert.Equal(t, "DE", *client.Country)
+
+		clients, err := model.GetAll()
+		require.NoError(t, err)
+		require.Len(t, clients, 1)
+		assert.Equal(t, &country, clients[0].Country)
+	})
+
 	t.Run("update non-existent client", func(t *testing.T) {
 		testDB.TruncateTable(t, "client")
 
-		err := model.Update(999, "New Name", "new@example.com", nil, nil, nil, nil, nil, nil, nil, 45.0, nil, nil, nil, nil, true, nil, nil, nil)
+		err := model.Update(Client{
+			ID:                      999,
+			Name:                    "New Name",
+			Email:                   "new@example.com",
+			HourlyRate:              45.0,
+			IncludeAddressOnInvoice: true,
+		})
 
 		// Should not return an error (MySQL UPDATE doesn't fail for non-existent rows)
 		require.NoError(t, err)
@@ -259,7 +311,13 @@ func TestClientModel_Update(t *testing.T) {
 		id := testDB.InsertTestClient(t, originalName)
 
 		// Update with empty name (should succeed at database level)
-		err := model.Update(id, "", "empty@example.com", nil, nil, nil, nil, nil, nil, nil, 35.0, nil, nil, nil, nil, true, nil, nil, nil)
+		err := model.Update(Client{
+			ID:                      id,
+			Name:                    "",
+			Email:                   "empty@example.com",
+			HourlyRate:              35.0,
+			IncludeAddressOnInvoice: true,
+		})
 		require.NoError(t, err)
 
 		// Verify the client was updated
@@ -289,13 +347,24 @@ func TestClientModelInterface_Update(t *testing.T) {
 			originalName := "Interface Test Client"
 
 			// Insert
-			id, err := test.impl.Insert(originalName, "interface2@example.com", nil, nil, nil, nil, nil, nil, nil, 70.0, nil, nil, nil, nil, true, nil, nil, nil)
+			id, err := test.impl.Insert(Client{
+				Name:                    originalName,
+				Email:                   "interface2@example.com",
+				HourlyRate:              70.0,
+				IncludeAddressOnInvoice: true,
+			})
 			require.NoError(t, err)
 			assert.Greater(t, id, 0)
 
 			// Update
 			newName := "Updated Interface Test Client"
-			err = test.impl.Update(id, newName, "updated_interface@example.com", nil, nil, nil, nil, nil, nil, nil, 80.0, nil, nil, nil, nil, true, nil, nil, nil)
+			err = test.impl.Update(Client{
+				ID:                      id,
+				Name:                    newName,
+				Email:                   "updated_interface@example.com",
+				HourlyRate:              80.0,
+				IncludeAddressOnInvoice: true,
+			})
 			require.NoError(t, err)
 
 			// Get and verify update
@@ -430,3 +499,336 @@ func TestClientModel_SoftDeleteIntegration(t *testing.T) {
 		assert.Equal(t, ErrNoRecord, err)
 	})
 }
+
+func TestClientModel_CascadingDelete(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewClientModel(testDB.DB)
+	projectModel := NewProjectModel(testDB.DB)
+	timesheetModel := NewTimesheetModel(testDB.DB)
+	invoiceModel := NewInvoiceModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Test Client")
+	projectID := testDB.InsertTestProject(t, "Active Project", clientID)
+	timesheetID := testDB.InsertTestTimesheet(t, projectID, "2024-01-15", "2.0", "50.00", "Editing")
+	invoiceID := testDB.InsertTestInvoice(t, projectID, "2024-01-31", "", "Net 30", "100.00")
+	removedProjectID := testDB.InsertTestProject(t, "Removed Project", clientID)
+	_, err := testDB.DB.Exec("UPDATE project SET deleted_at = '2024-01-20 09:00:00' WHERE id = ?", removedProjectID)
+	require.NoError(t, err)
+
+	require.NoError(t, model.Delete(clientID))
+
+	_, err = projectModel.Get(projectID)
+	assert.Equal(t, ErrNoRecord, err)
+	_, err = timesheetModel.Get(timesheetID)
+	assert.Equal(t, ErrNoRecord, err)
+	_, err = invoiceModel.Get(invoiceID)
+	assert.Equal(t, ErrNoRecord, err)
+
+	require.NoError(t, model.Restore(clientID))
+
+	_, err = model.Get(clientID)
+	assert.NoError(t, err)
+	_, err = projectModel.Get(projectID)
+	assert.NoError(t, err)
+	_, err = timesheetModel.Get(timesheetID)
+	assert.NoError(t, err)
+	_, err = invoiceModel.Get(invoiceID)
+	assert.NoError(t, err)
+	_, err = projectModel.Get(removedProjectID)
+	assert.Equal(t, ErrNoRecord, err)
+}
+
+func TestNewPaymentBehavior(t *testing.T) {
+	date := func(s string) *time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &d
+	}
+	invoice := func(invoiceDate, datePaid string) Invoice {
+		inv := Invoice{InvoiceDate: *date(invoiceDate), PaymentTerms: "Net 30"}
+		if datePaid != "" {
+			inv.DatePaid = date(datePaid)
+		}
+		return inv
+	}
+
+	tests := []struct {
+		name        string
+		invoices    []Invoice
+		paid        int
+		avgDaysPaid float64
+		avgDaysLate float64
+		rating      string
+		label       string
+	}{
+		{"no history", []Invoice{invoice("2024-03-01", "")}, 0, 0, 0, "", "No payment history"},
+		{"on time", []Invoice{invoice("2024-03-01", "2024-03-20"), invoice("2024-04-01", "2024-05-02")}, 2, 25, 0.5, PaymentRatingPrompt, "Pays on time"},
+		{"late", []Invoice{invoice("2024-03-01", "2024-04-20")}, 1, 50, 20, PaymentRatingLate, "Often pays late"},
+		{"slow", []Invoice{invoice("2024-03-01", "2024-06-01")}, 1, 92, 62, PaymentRatingSlow, "Slow payer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			behavior := NewPaymentBehavior(tt.invoices)
+			assert.Equal(t, tt.paid, behavior.PaidInvoices)
+			assert.Equal(t, tt.avgDaysPaid, behavior.AvgDaysToPay)
+			assert.Equal(t, tt.avgDaysLate, behavior.AvgDaysLate)
+			assert.Equal(t, tt.rating, behavior.Rating())
+			assert.Equal(t, tt.label, behavior.Label())
+		})
+	}
+
+	t.Run("due date takes precedence over payment terms", func(t *testing.T) {
+		inv := invoice("2024-03-01", "2024-03-20")
+		inv.DueDate = date("2024-03-10")
+
+		behavior := NewPaymentBehavior([]Invoice{inv})
+		assert.Equal(t, 1, behavior.LateInvoices)
+		assert.Equal(t, 10.0, behavior.AvgDaysLate)
+	})
+}
+
+func TestClientModel_PaymentBehavior(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewClientModel(testDB.DB)
+	clientID := testDB.InsertTestClient(t, "Payer")
+	projectID := testDB.InsertTestProject(t, "Project", clientID)
+	otherProjectID := testDB.InsertTestProject(t, "Other", testDB.InsertTestClient(t, "Someone Else"))
+
+	testDB.InsertTestInvoice(t, projectID, "2024-03-01", "2024-04-10", "Net 30", "100.00")
+	testDB.InsertTestInvoice(t, projectID, "2024-05-01", "", "Net 30", "100.00")
+	voidedID := testDB.InsertTestInvoice(t, projectID, "2024-01-01", "2024-12-01", "Net 30", "100.00")
+	require.NoError(t, NewInvoiceModel(testDB.DB).Void(voidedID, "Duplicate"))
+	testDB.InsertTestInvoice(t, otherProjectID, "2024-01-01", "2024-12-01", "Net 30", "100.00")
+
+	behavior, err := model.PaymentBehavior(clientID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, behavior.PaidInvoices)
+	assert.Equal(t, 40.0, behavior.AvgDaysToPay)
+	assert.Equal(t, 10.0, behavior.AvgDaysLate)
+	assert.Equal(t, PaymentRatingLate, behavior.Rating())
+
+	t.Run("prepay only flag is saved", func(t *testing.T) {
+		client, err := model.Get(clientID)
+		require.NoError(t, err)
+		assert.False(t, client.PrepayOnly)
+
+		client.PrepayOnly = true
+		require.NoError(t, model.Update(client))
+
+		client, err = model.Get(clientID)
+		require.NoError(t, err)
+		assert.True(t, client.PrepayOnly)
+	})
+}
+
+func TestClient_POPeriod(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		require.NoError(t, err)
+		return d
+	}
+	from, to := date("2024-01-01"), date("2024-06-30")
+
+	t.Run("without PO dates any date is accepted", func(t *testing.T) {
+		client := Client{}
+		assert.False(t, client.HasPOPeriod())
+		assert.True(t, client.InPOPeriod(date("1999-12-31")))
+	})
+
+	t.Run("dates within the period are accepted", func(t *testing.T) {
+		client := Client{POValidFrom: &from, POValidTo: &to}
+		assert.True(t, client.HasPOPeriod())
+		assert.True(t, client.InPOPeriod(from))
+		assert.True(t, client.InPOPeriod(to))
+		assert.False(t, client.InPOPeriod(date("2023-12-31")))
+		assert.False(t, client.InPOPeriod(date("2024-07-01")))
+	})
+
+	t.Run("either end can be open", func(t *testing.T) {
+		client := Client{POValidTo: &to}
+		assert.True(t, client.InPOPeriod(date("2000-01-01")))
+		assert.False(t, client.InPOPeriod(date("2024-07-01")))
+	})
+}
+
+func TestClient_FiscalYearEndFor(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		require.NoError(t, err)
+		return d
+	}
+	fiscalYearEnd := func(s string) *string { return &s }
+
+	assert.Nil(t, Client{}.FiscalYearEndFor(date("2024-05-01")))
+
+	tests := []struct {
+		yearEnd string
+		date    string
+		end     string
+	}{
+		{"06-30", "2024-05-01", "2024-06-30"},
+		{"06-30", "2024-06-30", "2024-06-30"},
+		{"06-30", "2024-07-01", "2025-06-30"},
+		{"12-31", "2024-12-31", "2024-12-31"},
+		{"02-29", "2025-01-15", "2025-02-28"},
+		{"02-29", "2024-01-15", "2024-02-29"},
+	}
+	for _, tt := range tests {
+		client := Client{FiscalYearEnd: fiscalYearEnd(tt.yearEnd)}
+		end := client.FiscalYearEndFor(date(tt.date))
+		require.NotNil(t, end)
+		assert.Equal(t, date(tt.end), *end, "%s in a year ending %s", tt.date, tt.yearEnd)
+	}
+
+	_, _, err := ParseFiscalYearEnd("13-01")
+	assert.Error(t, err)
+}
+
+func TestClientModel_POFields(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewClientModel(testDB.DB)
+	clientID := testDB.InsertTestClient(t, "University")
+
+	client, err := model.Get(clientID)
+	require.NoError(t, err)
+	assert.Nil(t, client.FiscalYearEnd)
+	assert.False(t, client.HasPOPeriod())
+
+	yearEnd, poNumber := "06-30", "PO-4471"
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	client.FiscalYearEnd = &yearEnd
+	client.PONumber = &poNumber
+	client.POValidFrom = &from
+	client.POValidTo = &to
+	require.NoError(t, model.Update(client))
+
+	client, err = model.Get(clientID)
+	require.NoError(t, err)
+	require.NotNil(t, client.FiscalYearEnd)
+	assert.Equal(t, "06-30", *client.FiscalYearEnd)
+	require.NotNil(t, client.PONumber)
+	assert.Equal(t, "PO-4471", *client.PONumber)
+	require.NotNil(t, client.POValidFrom)
+	assert.Equal(t, from, *client.POValidFrom)
+	require.NotNil(t, client.POValidTo)
+	assert.Equal(t, to, *client.POValidTo)
+}
+
+func TestClientModel_NextInvoiceNumber(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewClientModel(testDB.DB)
+	clientID := testDB.InsertTestClient(t, "Acme")
+	otherID := testDB.InsertTestClient(t, "Globex")
+
+	_, err := model.NextInvoiceNumber(clientID)
+	assert.Equal(t, ErrNoRecord, err, "a client without a sequence of its own has no number to give")
+
+	client, err := model.Get(clientID)
+	require.NoError(t, err)
+	prefix, next := "ACME-", 41
+	client.InvoicePrefix = &prefix
+	client.NextInvoiceNumber = &next
+	require.NoError(t, model.Update(client))
+
+	first, err := model.NextInvoiceNumber(clientID)
+	require.NoError(t, err)
+	second, err := model.NextInvoiceNumber(clientID)
+	require.NoError(t, err)
+	assert.Equal(t, "ACME-0041", first)
+	assert.Equal(t, "ACME-0042", second)
+
+	client, err = model.Get(clientID)
+	require.NoError(t, err)
+	require.NotNil(t, client.NextInvoiceNumber)
+	assert.Equal(t, 43, *client.NextInvoiceNumber)
+
+	_, err = model.NextInvoiceNumber(otherID)
+	assert.Equal(t, ErrNoRecord, err, "other clients are unaffected")
+}
+
+func TestClientModel_PipelineStage(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewClientModel(testDB.DB)
+	clientID := testDB.InsertTestClient(t, "Prospect")
+	testDB.InsertTestClient(t, "Active")
+
+	client, err := model.Get(clientID)
+	require.NoError(t, err)
+	assert.False(t, client.IsProspect())
+
+	require.NoError(t, model.SetPipelineStage(clientID, PipelineStageProposalSent))
+	client, err = model.Get(clientID)
+	require.NoError(t, err)
+	assert.True(t, client.IsProspect())
+	assert.Equal(t, PipelineStageProposalSent, client.PipelineStage)
+
+	// Editing the client's details leaves it in the pipeline
+	require.NoError(t, model.Update(client))
+	clients, err := model.GetAll()
+	require.NoError(t, err)
+	pipeline := NewPipeline(clients)
+	require.Len(t, pipeline, len(PipelineStages))
+	assert.Equal(t, PipelineStageLead, pipeline[0].Stage)
+	assert.Empty(t, pipeline[0].Prospects)
+	require.Len(t, pipeline[1].Prospects, 1, "active clients are left off the board")
+	assert.Equal(t, clientID, pipeline[1].Prospects[0].ID)
+
+	require.NoError(t, model.SetPipelineStage(clientID, ""))
+	client, err = model.Get(clientID)
+	require.NoError(t, err)
+	assert.False(t, client.IsProspect())
+
+	assert.True(t, IsPipelineStage(PipelineStageLost))
+	assert.False(t, IsPipelineStage(""))
+}
+
+func TestClientModel_Search(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewClientModel(testDB.DB)
+	smithID := testDB.InsertTestClient(t, "Dr. Smith")
+	jonesID := testDB.InsertTestClient(t, "Jones Lab")
+	percentID := testDB.InsertTestClient(t, "100% Editing")
+	deletedID := testDB.InsertTestClient(t, "Smithson Gone")
+
+	_, err := testDB.DB.Exec("UPDATE client SET updated_at = '2024-01-01 00:00:00'")
+	require.NoError(t, err)
+	_, err = testDB.DB.Exec("UPDATE client SET updated_at = '2024-03-01 00:00:00' WHERE id = ?", jonesID)
+	require.NoError(t, err)
+	require.NoError(t, model.Delete(deletedID))
+
+	t.Run("blank query lists the most recently updated first", func(t *testing.T) {
+		matches, err := model.Search("", 10)
+		require.NoError(t, err)
+		require.Len(t, matches, 3)
+		assert.Equal(t, ClientMatch{ID: jonesID, Name: "Jones Lab"}, matches[0])
+
+		matches, err = model.Search("", 1)
+		require.NoError(t, err)
+		assert.Len(t, matches, 1)
+	})
+
+	t.Run("matches the name, leaving out deleted clients", func(t *testing.T) {
+		matches, err := model.Search(" SMITH ", 10)
+		require.NoError(t, err)
+		assert.Equal(t, []ClientMatch{{ID: smithID, Name: "Dr. Smith"}}, matches)
+
+		matches, err = model.Search("%", 10)
+		require.NoError(t, err)
+		assert.Equal(t, []ClientMatch{{ID: percentID, Name: "100% Editing"}}, matches)
+	})
+}
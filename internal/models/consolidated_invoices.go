@@ -0,0 +1,149 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// ErrNothingToInvoice is returned when a consolidated invoice is asked for a month in which
+// the client has no unbilled time
+var ErrNothingToInvoice = errors.New("models: no unbilled time to invoice")
+
+// ProjectSubtotal is one project's share of a consolidated invoice
+type ProjectSubtotal struct {
+	ProjectID   int
+	ProjectName string
+	Hours       float64
+	Amount      float64
+}
+
+// MonthRange returns the first day of the month a date falls in and the first day of the next
+func MonthRange(month time.Time) (time.Time, time.Time) {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// TotalSubtotals adds up the hours and amounts of a consolidated invoice's projects, the
+// amount rounded to cents
+func TotalSubtotals(subtotals []ProjectSubtotal) (float64, float64) {
+	var hours, amount float64
+	for _, subtotal := range subtotals {
+		hours += subtotal.Hours
+		amount += subtotal.Amount
+	}
+	return hours, math.Round(amount*100) / 100
+}
+
+// ConsolidatedInvoiceModel wraps the generated SQLC Queries for invoices that cover all of a
+// client's projects at once
+type ConsolidatedInvoiceModel struct {
+	queries *db.Queries
+}
+
+// NewConsolidatedInvoiceModel creates a new ConsolidatedInvoiceModel
+func NewConsolidatedInvoiceModel(database *sql.DB) *ConsolidatedInvoiceModel {
+	return &ConsolidatedInvoiceModel{
+		queries: newQueries(database),
+	}
+}
+
+// NewConsolidatedInvoiceModelWithTx creates a ConsolidatedInvoiceModel whose queries run inside the given transaction
+func NewConsolidatedInvoiceModelWithTx(tx *sql.Tx) *ConsolidatedInvoiceModel {
+	return &ConsolidatedInvoiceModel{
+		queries: newQueries(tx),
+	}
+}
+
+// Unbilled totals the time logged on each of a client's projects in the month a date falls in
+// that no invoice covers yet. Only invoices with a timesheet range are known to cover time.
+func (m *ConsolidatedInvoiceModel) Unbilled(clientID int, month time.Time) ([]ProjectSubtotal, error) {
+	ctx := context.Background()
+	start, end := MonthRange(month)
+	rows, err := m.queries.GetUnbilledProjectTotals(ctx, db.GetUnbilledProjectTotalsParams{
+		ClientID:  int64(clientID),
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   end.Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subtotals := make([]ProjectSubtotal, len(rows))
+	for i, row := range rows {
+		subtotals[i] = ProjectSubtotal{
+			ProjectID:   int(row.ProjectID),
+			ProjectName: row.ProjectName,
+			Hours:       row.Hours,
+			Amount:      math.Round(row.Amount*100) / 100,
+		}
+	}
+	return subtotals, nil
+}
+
+// SetProjects records the projects a consolidated invoice covers
+func (m *ConsolidatedInvoiceModel) SetProjects(invoiceID int, subtotals []ProjectSubtotal) error {
+	ctx := context.Background()
+	for _, subtotal := range subtotals {
+		err := m.queries.InsertInvoiceProject(ctx, db.InsertInvoiceProjectParams{
+			InvoiceID: int64(invoiceID),
+			ProjectID: int64(subtotal.ProjectID),
+			Hours:     subtotal.Hours,
+			Amount:    subtotal.Amount,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetProjects returns the projects a consolidated invoice covers, or none for an invoice of a single project
+func (m *ConsolidatedInvoiceModel) GetProjects(invoiceID int) ([]ProjectSubtotal, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetInvoiceProjects(ctx, int64(invoiceID))
+	if err != nil {
+		return nil, err
+	}
+
+	subtotals := make([]ProjectSubtotal, len(rows))
+	for i, row := range rows {
+		subtotals[i] = ProjectSubtotal{
+			ProjectID:   int(row.ProjectID),
+			ProjectName: row.ProjectName,
+			Hours:       row.Hours,
+			Amount:      row.Amount,
+		}
+	}
+	return subtotals, nil
+}
+
+// ClientIDs returns the clients billed with a consolidated invoice every month
+func (m *ConsolidatedInvoiceModel) ClientIDs() ([]int, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetConsolidatedInvoicingClientIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(rows))
+	for i, id := range rows {
+		ids[i] = int(id)
+	}
+	return ids, nil
+}
+
+// ConsolidatedInvoiceModelInterface defines the interface for consolidated invoice operations
+type ConsolidatedInvoiceModelInterface interface {
+	Unbilled(clientID int, month time.Time) ([]ProjectSubtotal, error)
+	SetProjects(invoiceID int, subtotals []ProjectSubtotal) error
+	GetProjects(invoiceID int) ([]ProjectSubtotal, error)
+	ClientIDs() ([]int, error)
+}
+
+// Ensure implementation satisfies the interface
+var _ ConsolidatedInvoiceModelInterface = (*ConsolidatedInvoiceModel)(nil)
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsolidatedInvoiceModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewConsolidatedInvoiceModel(testDB.DB)
+	invoices := NewInvoiceModel(testDB.DB)
+	clientID := testDB.InsertTestClient(t, "Consolidated Client")
+	otherClientID := testDB.InsertTestClient(t, "Other Client")
+	thesis := testDB.InsertTestProject(t, "Thesis", clientID)
+	article := testDB.InsertTestProject(t, "Article", clientID)
+	grant := testDB.InsertTestProject(t, "Grant", clientID)
+	other := testDB.InsertTestProject(t, "Other", otherClientID)
+
+	testDB.InsertTestTimesheet(t, thesis, "2024-05-02", "2.0", "50.00", "Chapter 1")
+	testDB.InsertTestTimesheet(t, thesis, "2024-05-20", "1.5", "50.00", "Chapter 2")
+	testDB.InsertTestTimesheet(t, thesis, "2024-06-01", "3.0", "50.00", "Next month")
+	testDB.InsertTestTimesheet(t, article, "2024-05-10", "1.0", "40.00", "Abstract")
+	testDB.InsertTestTimesheet(t, grant, "2024-05-12", "4.0", "60.00", "Already billed")
+	testDB.InsertTestTimesheet(t, other, "2024-05-12", "4.0", "60.00", "Another client")
+
+	// Time waiting for approval isn't invoiceable yet
+	pendingID := testDB.InsertTestTimesheet(t, article, "2024-05-11", "5.0", "40.00", "Not approved")
+	require.NoError(t, NewTimesheetModel(testDB.DB).SetPendingApproval(pendingID, true))
+
+	// The grant's May time is already covered by an invoice of its own
+	grantInvoice := testDB.InsertTestInvoice(t, grant, "2024-05-31", "", "Net 30", "240.00")
+	from, to := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, invoices.SetTimesheetRange(grantInvoice, &from, &to))
+
+	may := time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC)
+
+	t.Run("unbilled time is totalled per project", func(t *testing.T) {
+		subtotals, err := model.Unbilled(clientID, may)
+		require.NoError(t, err)
+		require.Len(t, subtotals, 2)
+		assert.Equal(t, ProjectSubtotal{ProjectID: article, ProjectName: "Article", Hours: 1, Amount: 40}, subtotals[0])
+		assert.Equal(t, ProjectSubtotal{ProjectID: thesis, ProjectName: "Thesis", Hours: 3.5, Amount: 175}, subtotals[1])
+
+		hours, amount := TotalSubtotals(subtotals)
+		assert.Equal(t, 4.5, hours)
+		assert.Equal(t, 215.0, amount)
+	})
+
+	t.Run("a consolidated invoice covers the projects it lists", func(t *testing.T) {
+		subtotals, err := model.Unbilled(clientID, may)
+		require.NoError(t, err)
+		_, amount := TotalSubtotals(subtotals)
+
+		invoiceID := testDB.InsertTestInvoice(t, article, "2024-06-01", "", "Net 30", "215.00")
+		require.NoError(t, invoices.SetTimesheetRange(invoiceID, &from, &to))
+		require.NoError(t, model.SetProjects(invoiceID, subtotals))
+
+		projects, err := model.GetProjects(invoiceID)
+		require.NoError(t, err)
+		assert.Equal(t, subtotals, projects)
+
+		unbilled, err := model.Unbilled(clientID, may)
+		require.NoError(t, err)
+		assert.Empty(t, unbilled)
+
+		data, err := invoices.GetComprehensiveForPDF(invoiceID)
+		require.NoError(t, err)
+		assert.Len(t, data.Timesheets, 3)
+		assert.Equal(t, 4.5, data.TotalHours)
+		assert.Equal(t, amount, data.FinalTotal)
+
+		html, err := RenderInvoiceHTML(NewInvoiceTemplateData(data, nil))
+		require.NoError(t, err)
+		assert.Contains(t, string(html), "Project (May 2024)")
+		assert.Contains(t, string(html), "Thesis")
+		assert.Contains(t, string(html), "175.00")
+	})
+
+	t.Run("an invoice of a single project lists no projects", func(t *testing.T) {
+		projects, err := model.GetProjects(grantInvoice)
+		require.NoError(t, err)
+		assert.Empty(t, projects)
+	})
+
+	t.Run("clients billed monthly", func(t *testing.T) {
+		ids, err := model.ClientIDs()
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+
+		_, err = testDB.DB.Exec("UPDATE client SET consolidated_invoicing = true WHERE id = ?", clientID)
+		require.NoError(t, err)
+		ids, err = model.ClientIDs()
+		require.NoError(t, err)
+		assert.Equal(t, []int{clientID}, ids)
+	})
+}
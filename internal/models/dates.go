@@ -0,0 +1,80 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// DateFormat is how dates are shown and entered across forms, lists and invoices,
+// chosen by the date_format setting
+type DateFormat string
+
+const (
+	DateFormatISO      DateFormat = "YYYY-MM-DD"
+	DateFormatEuropean DateFormat = "DD.MM.YYYY"
+	DateFormatUS       DateFormat = "MM/DD/YYYY"
+)
+
+// DateFormats lists the supported date formats in the order they are offered
+var DateFormats = []DateFormat{DateFormatISO, DateFormatEuropean, DateFormatUS}
+
+// isoLayout is the layout browsers submit from date inputs and the API uses
+const isoLayout = "2006-01-02"
+
+// ParseDateFormat returns the DateFormat named by value, falling back to ISO for anything unknown
+func ParseDateFormat(value string) DateFormat {
+	for _, format := range DateFormats {
+		if strings.EqualFold(value, string(format)) {
+			return format
+		}
+	}
+	return DateFormatISO
+}
+
+// Layout returns the Go time layout for the format
+func (f DateFormat) Layout() string {
+	switch f {
+	case DateFormatEuropean:
+		return "02.01.2006"
+	case DateFormatUS:
+		return "01/02/2006"
+	default:
+		return isoLayout
+	}
+}
+
+// IsISO reports whether the format is the one native date inputs use
+func (f DateFormat) IsISO() bool {
+	return f.Layout() == isoLayout
+}
+
+// Format formats t as a date in the format
+func (f DateFormat) Format(t time.Time) string {
+	return t.Format(f.Layout())
+}
+
+// Parse reads a date entered in the format. ISO dates are always accepted too, since
+// they are what date pickers submit and can't be mistaken for the other formats.
+func (f DateFormat) Parse(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	t, err := time.Parse(f.Layout(), value)
+	if err != nil && !f.IsISO() {
+		if iso, isoErr := time.Parse(isoLayout, value); isoErr == nil {
+			return iso, nil
+		}
+	}
+	return t, err
+}
+
+// DatesBetween returns every date from one day to another, both included, leaving out
+// Saturdays and Sundays when skipWeekends is set
+func DatesBetween(from, to time.Time, skipWeekends bool) []time.Time {
+	var dates []time.Time
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		if skipWeekends && (date.Weekday() == time.Saturday || date.Weekday() == time.Sunday) {
+			continue
+		}
+		dates = append(dates, date)
+	}
+	return dates
+}
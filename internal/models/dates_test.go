@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDateFormat(t *testing.T) {
+	date := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		format    DateFormat
+		formatted string
+	}{
+		{DateFormatISO, "2024-03-05"},
+		{DateFormatEuropean, "05.03.2024"},
+		{DateFormatUS, "03/05/2024"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			assert.Equal(t, tt.format, ParseDateFormat(string(tt.format)))
+			assert.Equal(t, tt.formatted, tt.format.Format(date))
+
+			parsed, err := tt.format.Parse(tt.formatted)
+			require.NoError(t, err)
+			assert.Equal(t, date, parsed)
+
+			// Date pickers always submit ISO dates
+			parsed, err = tt.format.Parse("2024-03-05")
+			require.NoError(t, err)
+			assert.Equal(t, date, parsed)
+
+			_, err = tt.format.Parse("5 March 2024")
+			assert.Error(t, err)
+		})
+	}
+
+	assert.Equal(t, DateFormatISO, ParseDateFormat(""))
+	assert.Equal(t, DateFormatISO, ParseDateFormat("DD/MM/YY"))
+	assert.Equal(t, DateFormatEuropean, ParseDateFormat("dd.mm.yyyy"))
+	assert.False(t, DateFormatUS.IsISO())
+
+	_, err := DateFormatISO.Parse("05.03.2024")
+	assert.Error(t, err)
+}
+
+func TestDatesBetween(t *testing.T) {
+	friday := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tuesday := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	format := func(dates []time.Time) []string {
+		formatted := make([]string, len(dates))
+		for i, date := range dates {
+			formatted[i] = date.Format("2006-01-02")
+		}
+		return formatted
+	}
+
+	assert.Equal(t, []string{"2024-03-01", "2024-03-02", "2024-03-03", "2024-03-04", "2024-03-05"}, format(DatesBetween(friday, tuesday, false)))
+	assert.Equal(t, []string{"2024-03-01", "2024-03-04", "2024-03-05"}, format(DatesBetween(friday, tuesday, true)))
+	assert.Equal(t, []string{"2024-03-01"}, format(DatesBetween(friday, friday, true)))
+	assert.Empty(t, DatesBetween(friday.AddDate(0, 0, 1), friday.AddDate(0, 0, 2), true))
+	assert.Empty(t, DatesBetween(tuesday, friday, false))
+}
@@ -1,7 +1,149 @@
 package models
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
 )
 
 var ErrNoRecord = errors.New("models: no matching record found")
+
+var ErrInvoiceVoided = errors.New("models: invoice has been voided")
+
+// ErrInvoicePaid is returned when a payment is recorded for an invoice already marked paid,
+// or when a paid invoice's date or amount would be changed without removing the payment
+var ErrInvoicePaid = errors.New("models: invoice has already been paid")
+
+// ErrDiscountTooLarge is returned when an invoice is given a fixed discount larger than its
+// amount due, which would bill the client a negative total
+var ErrDiscountTooLarge = errors.New("models: discount is larger than the amount due")
+
+// ErrCreditSpent is returned when an invoice can't be deleted or voided because the credit
+// its overpayment earned has already been applied to other invoices
+var ErrCreditSpent = errors.New("models: overpayment credit already spent")
+
+// ErrAlreadyApproved is returned when a client approves timesheets through a link that has
+// already been used to approve them
+var ErrAlreadyApproved = errors.New("models: timesheets have already been approved")
+
+var ErrInvalidCredentials = errors.New("models: invalid credentials")
+
+var ErrDuplicateEmail = errors.New("models: duplicate email")
+
+// ErrImportRejected is returned when an export can't be imported, along with the problems found
+var ErrImportRejected = errors.New("models: import rejected")
+
+// ErrForeignKeyViolation is returned when a write refers to a record that doesn't exist
+var ErrForeignKeyViolation = errors.New("models: foreign key violation")
+
+// ErrDuplicate is returned when a write would break a uniqueness constraint
+var ErrDuplicate = errors.New("models: duplicate record")
+
+// ErrValidation is returned when the database rejects a value, e.g. a missing required
+// column or one that fails a CHECK constraint
+var ErrValidation = errors.New("models: invalid value")
+
+// ConstraintError is a constraint failure reported by the database. It matches one of
+// ErrForeignKeyViolation, ErrDuplicate or ErrValidation with errors.Is, as well as the
+// underlying driver error, and names the column at fault when the driver reports one.
+type ConstraintError struct {
+	Kind  error
+	Field string
+	Err   error
+}
+
+func (e *ConstraintError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("%s: %s", e.Kind, e.Field)
+	}
+	return e.Kind.Error()
+}
+
+func (e *ConstraintError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Message describes the failure in words that can be shown to the user
+func (e *ConstraintError) Message() string {
+	field := "A value"
+	if e.Field != "" {
+		field = strings.ReplaceAll(e.Field, "_", " ")
+		field = strings.ToUpper(field[:1]) + field[1:]
+	}
+
+	switch e.Kind {
+	case ErrForeignKeyViolation:
+		return "A related record no longer exists"
+	case ErrDuplicate:
+		return fmt.Sprintf("%s is already in use", field)
+	case ErrValidation:
+		if strings.Contains(e.Err.Error(), "NOT NULL") {
+			return fmt.Sprintf("%s is required", field)
+		}
+		return fmt.Sprintf("%s is not allowed", field)
+	}
+	return e.Error()
+}
+
+// constraintColumnPattern finds the column in messages like "NOT NULL constraint failed:
+// project.name" and "CHECK constraint failed: role IN ('owner', 'subcontractor')"
+var constraintColumnPattern = regexp.MustCompile(`constraint failed: (?:\w+\.)?(\w+)`)
+
+// translateError maps constraint failures from the SQLite driver onto the typed errors
+// above and returns any other error unchanged
+func translateError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	var kind error
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return &ConstraintError{Kind: ErrForeignKeyViolation, Err: err}
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		kind = ErrDuplicate
+	case sqlite3.SQLITE_CONSTRAINT_NOTNULL, sqlite3.SQLITE_CONSTRAINT_CHECK:
+		kind = ErrValidation
+	default:
+		return err
+	}
+
+	// The driver prefixes SQLite's own message with "constraint failed: " as well, so the
+	// column is in the last match
+	var field string
+	if matches := constraintColumnPattern.FindAllStringSubmatch(sqliteErr.Error(), -1); matches != nil {
+		field = matches[len(matches)-1][1]
+	}
+	return &ConstraintError{Kind: kind, Field: field, Err: err}
+}
+
+// errorTranslatingDB runs the generated queries and translates the errors from writes.
+// Errors from single row queries only surface on Scan, but writes never use those.
+type errorTranslatingDB struct {
+	db.DBTX
+}
+
+func (d errorTranslatingDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := d.DBTX.ExecContext(ctx, query, args...)
+	return result, translateError(err)
+}
+
+func (d errorTranslatingDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := d.DBTX.QueryContext(ctx, query, args...)
+	return rows, translateError(err)
+}
+
+// newQueries returns the generated queries for a model, running against a database or
+// transaction, with constraint failures translated into ConstraintErrors
+func newQueries(conn db.DBTX) *db.Queries {
+	return db.New(errorTranslatingDB{conn})
+}
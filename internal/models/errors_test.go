@@ -0,0 +1,69 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraintErrors(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	t.Run("missing parent is a foreign key violation", func(t *testing.T) {
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+
+		_, err := NewProjectModel(testDB.DB).Insert(Project{Name: "Orphan", ClientID: 999, Status: "Estimating", CurrencyDisplay: "USD", CurrencyConversionRate: 1})
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrForeignKeyViolation)
+
+		var constraintErr *ConstraintError
+		require.True(t, errors.As(err, &constraintErr))
+		assert.Equal(t, "A related record no longer exists", constraintErr.Message())
+	})
+
+	t.Run("check constraint is a validation error", func(t *testing.T) {
+		testDB.TruncateTable(t, "user")
+
+		_, err := NewUserModel(testDB.DB).Insert(User{Name: "Admin", Email: "admin@example.com", Role: "admin"}, "password123")
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrValidation)
+
+		var constraintErr *ConstraintError
+		require.True(t, errors.As(err, &constraintErr))
+		assert.Equal(t, "role", constraintErr.Field)
+		assert.Equal(t, "Role is not allowed", constraintErr.Message())
+	})
+
+	t.Run("repeated key is a duplicate", func(t *testing.T) {
+		testDB.TruncateTable(t, "project_share")
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+		testDB.TruncateTable(t, "user")
+
+		projectID := testDB.InsertTestProject(t, "Shared", testDB.InsertTestClient(t, "Client"))
+		userID, err := NewUserModel(testDB.DB).Insert(User{Name: "Sub", Email: "sub@example.com", Role: "subcontractor"}, "password123")
+		require.NoError(t, err)
+
+		insert := "INSERT INTO project_share (project_id, user_id) VALUES (?, ?)"
+		_, err = testDB.DB.Exec(insert, projectID, userID)
+		require.NoError(t, err)
+		_, err = testDB.DB.Exec(insert, projectID, userID)
+
+		err = translateError(err)
+		assert.ErrorIs(t, err, ErrDuplicate)
+		assert.NotErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("other errors are unchanged", func(t *testing.T) {
+		err := errors.New("boom")
+		assert.Same(t, err, translateError(err))
+		assert.NoError(t, translateError(nil))
+	})
+}
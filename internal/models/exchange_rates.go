@@ -0,0 +1,82 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// ExchangeRate is the units of a currency bought by one unit of the domestic currency on a
+// day, as fetched from Source
+type ExchangeRate struct {
+	Currency  string
+	Date      time.Time
+	Rate      float64
+	Source    string
+	FetchedAt time.Time
+}
+
+// ExchangeRateModel wraps the generated SQLC Queries for the history of exchange rates
+type ExchangeRateModel struct {
+	queries *db.Queries
+}
+
+// NewExchangeRateModel creates a new ExchangeRateModel
+func NewExchangeRateModel(database *sql.DB) *ExchangeRateModel {
+	return &ExchangeRateModel{
+		queries: newQueries(database),
+	}
+}
+
+// Save stores a currency's rate for a day, replacing any rate already stored for that day
+func (m *ExchangeRateModel) Save(rate ExchangeRate) error {
+	ctx := context.Background()
+	return m.queries.SaveExchangeRate(ctx, db.SaveExchangeRateParams{
+		Currency: strings.ToUpper(rate.Currency),
+		RateDate: rate.Date.Format(isoLayout),
+		Rate:     rate.Rate,
+		Source:   rate.Source,
+	})
+}
+
+// GetRate returns the rate of a currency on a date, or the latest stored before it when there
+// is none for the day itself, as over weekends when no rates are published. It returns
+// ErrNoRecord when no rate on or before the date is stored.
+func (m *ExchangeRateModel) GetRate(currency string, date time.Time) (ExchangeRate, error) {
+	ctx := context.Background()
+	row, err := m.queries.GetExchangeRate(ctx, db.GetExchangeRateParams{
+		Currency: strings.ToUpper(currency),
+		RateDate: date.Format(isoLayout),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ExchangeRate{}, ErrNoRecord
+		}
+		return ExchangeRate{}, err
+	}
+
+	rateDate, err := time.Parse(isoLayout, row.RateDate)
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+	return ExchangeRate{
+		Currency:  row.Currency,
+		Date:      rateDate,
+		Rate:      row.Rate,
+		Source:    row.Source,
+		FetchedAt: row.FetchedAt,
+	}, nil
+}
+
+// ExchangeRateModelInterface defines the interface for exchange rate history operations
+type ExchangeRateModelInterface interface {
+	Save(rate ExchangeRate) error
+	GetRate(currency string, date time.Time) (ExchangeRate, error)
+}
+
+// Ensure implementation satisfies the interface
+var _ ExchangeRateModelInterface = (*ExchangeRateModel)(nil)
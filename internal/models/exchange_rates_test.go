@@ -0,0 +1,50 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchangeRateModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewExchangeRateModel(testDB.DB)
+	day := func(value string) time.Time {
+		d, _ := time.Parse(isoLayout, value)
+		return d
+	}
+
+	require.NoError(t, model.Save(ExchangeRate{Currency: "eur", Date: day("2024-03-01"), Rate: 0.92, Source: "api.frankfurter.app"}))
+	require.NoError(t, model.Save(ExchangeRate{Currency: "EUR", Date: day("2024-03-08"), Rate: 0.91, Source: "api.frankfurter.app"}))
+	require.NoError(t, model.Save(ExchangeRate{Currency: "GBP", Date: day("2024-03-08"), Rate: 0.78}))
+
+	rate, err := model.GetRate("EUR", day("2024-03-08"))
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", rate.Currency)
+	assert.Equal(t, "2024-03-08", rate.Date.Format(isoLayout))
+	assert.Equal(t, 0.91, rate.Rate)
+	assert.Equal(t, "api.frankfurter.app", rate.Source)
+	assert.False(t, rate.FetchedAt.IsZero())
+
+	rate, err = model.GetRate("eur", day("2024-03-05"))
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-01", rate.Date.Format(isoLayout), "the latest rate before the date is used")
+	assert.Equal(t, 0.92, rate.Rate)
+
+	require.NoError(t, model.Save(ExchangeRate{Currency: "EUR", Date: day("2024-03-08"), Rate: 0.915, Source: "ecb"}))
+	rate, err = model.GetRate("EUR", day("2024-03-31"))
+	require.NoError(t, err)
+	assert.Equal(t, 0.915, rate.Rate, "saving a day again replaces its rate")
+	assert.Equal(t, "ecb", rate.Source)
+
+	_, err = model.GetRate("EUR", day("2024-02-29"))
+	assert.True(t, errors.Is(err, ErrNoRecord), "no rate is known before the first one stored")
+	_, err = model.GetRate("JPY", day("2024-03-08"))
+	assert.True(t, errors.Is(err, ErrNoRecord))
+}
@@ -0,0 +1,206 @@
+package models
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ExportFormat identifies a full account export, so an import can tell one from any other JSON
+const ExportFormat = "freelance-tracker-export"
+
+// ExportFormatVersion is bumped whenever the layout of an export changes in a way an older
+// import wouldn't understand. Changes to the tables themselves are tracked by SchemaVersion.
+const ExportFormatVersion = 1
+
+// ExportTables lists the tables in a full export, each after the tables it refers to so an
+// import can insert them in this order. The tables in exportSkippedTables are left out.
+var ExportTables = []string{
+	"settings",
+	"exchange_rate",
+	"user",
+	"user_preferences",
+	"saved_view",
+	"business_profile",
+	"service",
+	"payment_terms_preset",
+	"tag",
+	"checklist_item",
+	"client",
+	"client_tag",
+	"client_note",
+	"client_project_defaults",
+	"project",
+	"client_approval",
+	"project_share",
+	"project_tag",
+	"project_checklist",
+	"timesheet",
+	"pending_timesheet",
+	"invoice",
+	"invoice_snapshot",
+	"invoice_event",
+	"invoice_delivery",
+	"invoice_project",
+	"client_credit",
+	"milestone",
+	"notification_sent",
+	"external_id",
+}
+
+// exportSkippedTables are the tables a full export leaves out. Sessions, jobs, recent visits
+// and form drafts are only of use to the running application, and project attachments
+// refer to files kept outside the database.
+var exportSkippedTables = []string{
+	"sessions",
+	"user_session",
+	"job",
+	"scheduled_task",
+	"recent_visit",
+	"form_draft",
+	"project_attachment",
+}
+
+// ExportRow is one row of a table, keyed by column name
+type ExportRow map[string]any
+
+// Export is every row of every table in ExportTables, along with the migration the schema
+// was at, so an import can check the rows fit its own tables. It holds users' password
+// hashes and so should be kept as safe as the database itself.
+type Export struct {
+	Format        string                 `json:"format"`
+	Version       int                    `json:"version"`
+	SchemaVersion int64                  `json:"schema_version"`
+	ExportedAt    time.Time              `json:"exported_at"`
+	Tables        map[string][]ExportRow `json:"tables"`
+}
+
+// WriteJSON writes the export as a single JSON document
+func (e Export) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(e)
+}
+
+// WriteZip writes the export as a ZIP archive holding a manifest.json with everything but
+// the rows, and a JSON file of rows for each table
+func (e Export) WriteZip(w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	manifest := e
+	manifest.Tables = nil
+	if err := writeZipJSON(archive, "manifest.json", manifest); err != nil {
+		return err
+	}
+	for _, table := range ExportTables {
+		rows := e.Tables[table]
+		if rows == nil {
+			rows = []ExportRow{}
+		}
+		if err := writeZipJSON(archive, table+".json", rows); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// writeZipJSON adds a file holding value as JSON to archive
+func writeZipJSON(archive *zip.Writer, name string, value any) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// ExportModel reads and loads whole tables for full account exports and imports. Unlike
+// the other models it doesn't go through the generated queries, so that columns added by
+// later migrations are handled without having to remember to add them here.
+type ExportModel struct {
+	database *sql.DB
+}
+
+// NewExportModel creates a new ExportModel
+func NewExportModel(database *sql.DB) *ExportModel {
+	return &ExportModel{
+		database: database,
+	}
+}
+
+// Export reads every row of the exported tables, soft deleted ones included, in one
+// transaction so the tables are consistent with each other
+func (m *ExportModel) Export(schemaVersion int64) (Export, error) {
+	ctx := context.Background()
+	tx, err := m.database.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return Export{}, err
+	}
+	defer tx.Rollback()
+
+	export := Export{
+		Format:        ExportFormat,
+		Version:       ExportFormatVersion,
+		SchemaVersion: schemaVersion,
+		ExportedAt:    time.Now().UTC(),
+		Tables:        make(map[string][]ExportRow, len(ExportTables)),
+	}
+	for _, table := range ExportTables {
+		rows, err := exportTable(ctx, tx, table)
+		if err != nil {
+			return Export{}, fmt.Errorf("exporting %s: %w", table, err)
+		}
+		export.Tables[table] = rows
+	}
+	return export, nil
+}
+
+// exportTable reads every row of a table in the order they were inserted
+func exportTable(ctx context.Context, tx *sql.Tx, table string) ([]ExportRow, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT * FROM "%s" ORDER BY rowid`, table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := []ExportRow{}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(ExportRow, len(columns))
+		for i, column := range columns {
+			// Text comes back as bytes from some column types, which JSON would otherwise encode as base64
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[column] = values[i]
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// ExportModelInterface defines the interface for full account exports and imports
+type ExportModelInterface interface {
+	Export(schemaVersion int64) (Export, error)
+	Import(export Export, schemaVersion int64) (ImportResult, error)
+}
+
+// Ensure implementation satisfies the interface
+var _ ExportModelInterface = (*ExportModel)(nil)
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/database"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportModel_Export(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewExportModel(testDB.DB)
+	clientID := testDB.InsertTestClient(t, "Acme")
+	projectID := testDB.InsertTestProject(t, "Book", clientID)
+	testDB.InsertTestTimesheet(t, projectID, "2024-01-10", "2.50", "80.00", "Copyedit")
+	invoiceID := testDB.InsertTestInvoice(t, projectID, "2024-02-01", "2024-02-20", "Net 30", "200.00")
+	deletedID := testDB.InsertTestClient(t, "Gone")
+	require.NoError(t, NewClientModel(testDB.DB).Delete(deletedID))
+
+	export, err := model.Export(39)
+	require.NoError(t, err)
+	assert.Equal(t, ExportFormat, export.Format)
+	assert.Equal(t, ExportFormatVersion, export.Version)
+	assert.Equal(t, int64(39), export.SchemaVersion)
+	assert.False(t, export.ExportedAt.IsZero())
+
+	for _, table := range ExportTables {
+		assert.Contains(t, export.Tables, table)
+	}
+	assert.NotContains(t, export.Tables, "sessions")
+	assert.NotContains(t, export.Tables, "job")
+
+	clients := export.Tables["client"]
+	require.Len(t, clients, 2, "soft deleted clients are exported too")
+	assert.Equal(t, "Acme", clients[0]["name"])
+	assert.Equal(t, int64(clientID), clients[0]["id"])
+	assert.NotNil(t, clients[1]["deleted_at"])
+
+	require.Len(t, export.Tables["timesheet"], 1)
+	assert.Equal(t, "Copyedit", export.Tables["timesheet"][0]["description"])
+	require.Len(t, export.Tables["invoice"], 1)
+	assert.Equal(t, int64(invoiceID), export.Tables["invoice"][0]["id"])
+	assert.EqualValues(t, 200, export.Tables["invoice"][0]["amount_due"])
+
+	t.Run("JSON holds every table", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, export.WriteJSON(&buf))
+
+		var decoded Export
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+		assert.Equal(t, ExportFormat, decoded.Format)
+		assert.Len(t, decoded.Tables, len(ExportTables))
+		assert.Equal(t, "Acme", decoded.Tables["client"][0]["name"])
+	})
+
+	t.Run("ZIP holds a manifest and a file per table", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, export.WriteZip(&buf))
+
+		archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		require.Len(t, archive.File, len(ExportTables)+1)
+		assert.Equal(t, "manifest.json", archive.File[0].Name)
+
+		files := map[string]*zip.File{}
+		for _, file := range archive.File {
+			files[file.Name] = file
+		}
+		readJSON := func(name string, value any) {
+			require.Contains(t, files, name)
+			r, err := files[name].Open()
+			require.NoError(t, err)
+			defer r.Close()
+			content, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(content, value))
+		}
+
+		var manifest Export
+		readJSON("manifest.json", &manifest)
+		assert.Equal(t, int64(39), manifest.SchemaVersion)
+		assert.Empty(t, manifest.Tables)
+
+		var clients []ExportRow
+		readJSON("client.json", &clients)
+		assert.Len(t, clients, 2)
+
+		var milestones []ExportRow
+		readJSON("milestone.json", &milestones)
+		assert.NotNil(t, milestones, "empty tables are written as empty lists")
+	})
+}
+
+func TestExportTables_CoverMigrations(t *testing.T) {
+	db, err := database.OpenDB(filepath.Join(t.TempDir(), "export.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, database.RunMigrations(db, "../../migrations"))
+
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'goose_db_version'`)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var table string
+		require.NoError(t, rows.Scan(&table))
+		assert.True(t, slices.Contains(ExportTables, table) || slices.Contains(exportSkippedTables, table),
+			"table %s is neither exported nor in exportSkippedTables", table)
+	}
+	require.NoError(t, rows.Err())
+}
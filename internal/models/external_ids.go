@@ -0,0 +1,139 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// Kinds of record that can have external IDs
+const (
+	ExternalClient  = "client"
+	ExternalProject = "project"
+	ExternalInvoice = "invoice"
+)
+
+// ExternalSourcePattern is what the name of a source system, such as "quickbooks" or
+// "harvest", looks like once normalized by NormalizeExternalSource
+var ExternalSourcePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,49}$`)
+
+// ExternalID is the ID a client, project or invoice has in another system. Kind is one of the
+// External kinds and ItemID the record's own ID.
+type ExternalID struct {
+	Kind       string
+	ItemID     int
+	Source     string
+	ExternalID string
+	Created    time.Time
+}
+
+// IsExternalKind reports whether kind is a kind of record that can have external IDs
+func IsExternalKind(kind string) bool {
+	return kind == ExternalClient || kind == ExternalProject || kind == ExternalInvoice
+}
+
+// NormalizeExternalSource trims and lowercases a source name, so that "QuickBooks" and
+// "quickbooks " are the same source
+func NormalizeExternalSource(source string) string {
+	return strings.ToLower(strings.TrimSpace(source))
+}
+
+// ExternalIDModel wraps the generated SQLC Queries for the IDs records have in other systems
+type ExternalIDModel struct {
+	queries *db.Queries
+}
+
+// NewExternalIDModel creates a new ExternalIDModel
+func NewExternalIDModel(database *sql.DB) *ExternalIDModel {
+	return &ExternalIDModel{
+		queries: newQueries(database),
+	}
+}
+
+// Set records a record's ID in a source system, replacing any ID it had there before. An ID
+// that another record of the same kind already has in that source is rejected with a
+// ConstraintError matching ErrDuplicate, so that a sync can't link two records to one.
+func (m *ExternalIDModel) Set(kind string, itemID int, source, externalID string) error {
+	ctx := context.Background()
+	return m.queries.SetExternalID(ctx, db.SetExternalIDParams{
+		Kind:       kind,
+		ItemID:     int64(itemID),
+		Source:     source,
+		ExternalID: externalID,
+	})
+}
+
+// GetAll returns a record's external IDs, ordered by source
+func (m *ExternalIDModel) GetAll(kind string, itemID int) ([]ExternalID, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetExternalIDs(ctx, db.GetExternalIDsParams{
+		Kind:   kind,
+		ItemID: int64(itemID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]ExternalID, len(rows))
+	for i, row := range rows {
+		ids[i] = ExternalID{
+			Kind:       row.Kind,
+			ItemID:     int(row.ItemID),
+			Source:     row.Source,
+			ExternalID: row.ExternalID,
+			Created:    row.CreatedAt,
+		}
+	}
+	return ids, nil
+}
+
+// Find returns the ID of the record of the given kind that has externalID in source, or
+// ErrNoRecord when there is none. The record itself may since have been deleted.
+func (m *ExternalIDModel) Find(kind, source, externalID string) (int, error) {
+	ctx := context.Background()
+	id, err := m.queries.FindExternalID(ctx, db.FindExternalIDParams{
+		Kind:       kind,
+		Source:     source,
+		ExternalID: externalID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNoRecord
+		}
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// Delete removes a record's ID in a source system
+func (m *ExternalIDModel) Delete(kind string, itemID int, source string) error {
+	ctx := context.Background()
+	rows, err := m.queries.DeleteExternalID(ctx, db.DeleteExternalIDParams{
+		Kind:   kind,
+		ItemID: int64(itemID),
+		Source: source,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
+
+// ExternalIDModelInterface defines the interface for external ID operations
+type ExternalIDModelInterface interface {
+	Set(kind string, itemID int, source, externalID string) error
+	GetAll(kind string, itemID int) ([]ExternalID, error)
+	Find(kind, source, externalID string) (int, error)
+	Delete(kind string, itemID int, source string) error
+}
+
+// Ensure implementation satisfies the interface
+var _ ExternalIDModelInterface = (*ExternalIDModel)(nil)
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+)
+
+func TestExternalIDModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewExternalIDModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Synced Client")
+	otherClientID := testDB.InsertTestClient(t, "Other Client")
+	projectID := testDB.InsertTestProject(t, "Synced Project", clientID)
+
+	require.NoError(t, model.Set(ExternalClient, clientID, "quickbooks", "QB-17"))
+	require.NoError(t, model.Set(ExternalClient, clientID, "harvest", "H-3"))
+
+	t.Run("records are found by their ID in a source", func(t *testing.T) {
+		id, err := model.Find(ExternalClient, "quickbooks", "QB-17")
+		require.NoError(t, err)
+		assert.Equal(t, clientID, id)
+
+		_, err = model.Find(ExternalClient, "harvest", "QB-17")
+		assert.ErrorIs(t, err, ErrNoRecord, "IDs are looked up within their source")
+		_, err = model.Find(ExternalProject, "quickbooks", "QB-17")
+		assert.ErrorIs(t, err, ErrNoRecord, "and for one kind of record")
+	})
+
+	t.Run("a record has one ID per source", func(t *testing.T) {
+		require.NoError(t, model.Set(ExternalClient, clientID, "quickbooks", "QB-18"))
+
+		ids, err := model.GetAll(ExternalClient, clientID)
+		require.NoError(t, err)
+		require.Len(t, ids, 2)
+		assert.Equal(t, "harvest", ids[0].Source)
+		assert.Equal(t, "QB-18", ids[1].ExternalID)
+
+		_, err = model.Find(ExternalClient, "quickbooks", "QB-17")
+		assert.ErrorIs(t, err, ErrNoRecord)
+	})
+
+	t.Run("an ID belongs to one record of each kind in a source", func(t *testing.T) {
+		err := model.Set(ExternalClient, otherClientID, "quickbooks", "QB-18")
+		assert.ErrorIs(t, err, ErrDuplicate)
+
+		require.NoError(t, model.Set(ExternalProject, projectID, "quickbooks", "QB-18"))
+	})
+
+	t.Run("IDs can be removed", func(t *testing.T) {
+		require.NoError(t, model.Delete(ExternalClient, clientID, "harvest"))
+		assert.ErrorIs(t, model.Delete(ExternalClient, clientID, "harvest"), ErrNoRecord)
+
+		ids, err := model.GetAll(ExternalClient, clientID)
+		require.NoError(t, err)
+		assert.Len(t, ids, 1)
+	})
+}
+
+func TestNormalizeExternalSource(t *testing.T) {
+	assert.Equal(t, "quickbooks", NormalizeExternalSource(" QuickBooks "))
+	assert.True(t, ExternalSourcePattern.MatchString("zoho-books"))
+	assert.False(t, ExternalSourcePattern.MatchString("zoho books"))
+	assert.False(t, ExternalSourcePattern.MatchString(""))
+	assert.True(t, IsExternalKind(ExternalInvoice))
+	assert.False(t, IsExternalKind("timesheet"))
+}
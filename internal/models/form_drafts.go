@@ -0,0 +1,104 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// Forms whose unsaved changes are kept as drafts
+const (
+	DraftClient  = "client"
+	DraftProject = "project"
+)
+
+// FormDraft is the unsaved state of a client or project form. ItemID is the client or project
+// being edited, or 0 for a form creating a new one, and Fields holds the form's values as
+// they would be posted.
+type FormDraft struct {
+	Form    string
+	ItemID  int
+	Fields  url.Values
+	Updated time.Time
+}
+
+// IsDraftForm reports whether form is one of the forms drafts are kept for
+func IsDraftForm(form string) bool {
+	return form == DraftClient || form == DraftProject
+}
+
+// FormDraftModel wraps the generated SQLC Queries for each user's form drafts
+type FormDraftModel struct {
+	queries *db.Queries
+}
+
+// NewFormDraftModel creates a new FormDraftModel
+func NewFormDraftModel(database *sql.DB) *FormDraftModel {
+	return &FormDraftModel{
+		queries: newQueries(database),
+	}
+}
+
+// Save keeps a user's draft of a form, replacing the one saved before
+func (m *FormDraftModel) Save(userID int, form string, itemID int, fields url.Values, updated time.Time) error {
+	ctx := context.Background()
+	return m.queries.SaveFormDraft(ctx, db.SaveFormDraftParams{
+		UserID:    int64(userID),
+		Form:      form,
+		ItemID:    int64(itemID),
+		Fields:    fields.Encode(),
+		UpdatedAt: updated.UTC(),
+	})
+}
+
+// Get returns a user's draft of a form, or ErrNoRecord when there is none
+func (m *FormDraftModel) Get(userID int, form string, itemID int) (FormDraft, error) {
+	ctx := context.Background()
+	row, err := m.queries.GetFormDraft(ctx, db.GetFormDraftParams{
+		UserID: int64(userID),
+		Form:   form,
+		ItemID: int64(itemID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return FormDraft{}, ErrNoRecord
+		}
+		return FormDraft{}, err
+	}
+
+	fields, err := url.ParseQuery(row.Fields)
+	if err != nil {
+		return FormDraft{}, err
+	}
+	return FormDraft{
+		Form:    row.Form,
+		ItemID:  int(row.ItemID),
+		Fields:  fields,
+		Updated: row.UpdatedAt,
+	}, nil
+}
+
+// Delete discards a user's draft of a form, as happens once the form is saved. Discarding a
+// draft that doesn't exist isn't an error.
+func (m *FormDraftModel) Delete(userID int, form string, itemID int) error {
+	ctx := context.Background()
+	return m.queries.DeleteFormDraft(ctx, db.DeleteFormDraftParams{
+		UserID: int64(userID),
+		Form:   form,
+		ItemID: int64(itemID),
+	})
+}
+
+// FormDraftModelInterface defines the interface for form draft operations
+type FormDraftModelInterface interface {
+	Save(userID int, form string, itemID int, fields url.Values, updated time.Time) error
+	Get(userID int, form string, itemID int) (FormDraft, error)
+	Delete(userID int, form string, itemID int) error
+}
+
+// Ensure implementation satisfies the interface
+var _ FormDraftModelInterface = (*FormDraftModel)(nil)
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+)
+
+func TestFormDraftModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewFormDraftModel(testDB.DB)
+	users := NewUserModel(testDB.DB)
+	userID, err := users.Insert(User{Name: "Ann Owner", Email: "ann@example.com", Role: RoleOwner}, "correct horse")
+	require.NoError(t, err)
+	otherUserID, err := users.Insert(User{Name: "Bob Owner", Email: "bob@example.com", Role: RoleOwner}, "correct horse")
+	require.NoError(t, err)
+
+	clientID := testDB.InsertTestClient(t, "Acme")
+	saved := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("a draft is kept per user, form and item", func(t *testing.T) {
+		require.NoError(t, model.Save(userID, DraftClient, clientID, url.Values{"name": {"Acme Ltd"}, "tags": {"1", "3"}}, saved))
+		require.NoError(t, model.Save(userID, DraftClient, 0, url.Values{"name": {"New Client"}}, saved))
+
+		draft, err := model.Get(userID, DraftClient, clientID)
+		require.NoError(t, err)
+		assert.Equal(t, "Acme Ltd", draft.Fields.Get("name"))
+		assert.Equal(t, []string{"1", "3"}, draft.Fields["tags"])
+		assert.True(t, saved.Equal(draft.Updated))
+
+		draft, err = model.Get(userID, DraftClient, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "New Client", draft.Fields.Get("name"))
+
+		_, err = model.Get(otherUserID, DraftClient, clientID)
+		assert.ErrorIs(t, err, ErrNoRecord)
+		_, err = model.Get(userID, DraftProject, clientID)
+		assert.ErrorIs(t, err, ErrNoRecord)
+	})
+
+	t.Run("saving again replaces the draft", func(t *testing.T) {
+		require.NoError(t, model.Save(userID, DraftClient, clientID, url.Values{"name": {"Acme Inc"}}, saved.Add(time.Minute)))
+
+		draft, err := model.Get(userID, DraftClient, clientID)
+		require.NoError(t, err)
+		assert.Equal(t, url.Values{"name": {"Acme Inc"}}, draft.Fields)
+		assert.True(t, saved.Add(time.Minute).Equal(draft.Updated))
+	})
+
+	t.Run("drafts are discarded", func(t *testing.T) {
+		require.NoError(t, model.Delete(userID, DraftClient, clientID))
+		_, err := model.Get(userID, DraftClient, clientID)
+		assert.ErrorIs(t, err, ErrNoRecord)
+
+		assert.NoError(t, model.Delete(userID, DraftClient, clientID), "discarding twice is harmless")
+	})
+
+	assert.True(t, IsDraftForm(DraftProject))
+	assert.False(t, IsDraftForm("invoice"))
+}
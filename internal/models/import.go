@@ -0,0 +1,403 @@
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// importReplaceTables are filled with defaults by the migrations, so an import replaces
+// their rows rather than requiring them to be empty
+var importReplaceTables = map[string]bool{
+	"settings":             true,
+	"payment_terms_preset": true,
+}
+
+// importItemReferences are tables whose item_id refers to a row of the table their kind
+// column names, which can't be declared as a foreign key
+var importItemReferences = map[string]bool{
+	"external_id": true,
+}
+
+// ImportResult reports how many rows were imported into each table, or, when the import
+// was rejected, the problems that stopped it
+type ImportResult struct {
+	Rows     map[string]int
+	Problems []string
+}
+
+// ReadExport reads a full export written by WriteJSON or WriteZip
+func ReadExport(data []byte) (Export, error) {
+	var export Export
+	var err error
+	if bytes.HasPrefix(data, []byte("PK")) {
+		export, err = readExportZip(data)
+	} else {
+		err = decodeExportJSON(data, &export)
+	}
+	if err != nil {
+		return Export{}, fmt.Errorf("reading export: %w", err)
+	}
+
+	// Whole numbers such as IDs are kept as integers rather than the floats JSON numbers
+	// otherwise become
+	for _, rows := range export.Tables {
+		for _, row := range rows {
+			for column, value := range row {
+				if number, ok := value.(json.Number); ok {
+					row[column] = importNumber(number)
+				}
+			}
+		}
+	}
+	return export, nil
+}
+
+// readExportZip reads an export from its manifest and the JSON file of each table
+func readExportZip(data []byte) (Export, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Export{}, err
+	}
+
+	var export Export
+	tables := map[string][]ExportRow{}
+	for _, file := range archive.File {
+		content, err := readZipFile(file)
+		if err != nil {
+			return Export{}, fmt.Errorf("%s: %w", file.Name, err)
+		}
+		if file.Name == "manifest.json" {
+			err = decodeExportJSON(content, &export)
+		} else {
+			var rows []ExportRow
+			err = decodeExportJSON(content, &rows)
+			tables[strings.TrimSuffix(path.Base(file.Name), ".json")] = rows
+		}
+		if err != nil {
+			return Export{}, fmt.Errorf("%s: %w", file.Name, err)
+		}
+	}
+	export.Tables = tables
+	return export, nil
+}
+
+// readZipFile returns the uncompressed content of a file in a ZIP archive
+func readZipFile(file *zip.File) ([]byte, error) {
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// decodeExportJSON decodes export JSON into value, leaving numbers as json.Number
+func decodeExportJSON(data []byte, value any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
+
+// importNumber converts a JSON number to an int64 if it is whole, or a float64 otherwise
+func importNumber(number json.Number) any {
+	if i, err := number.Int64(); err == nil {
+		return i
+	}
+	f, _ := number.Float64()
+	return f
+}
+
+// importColumn is a column of a table being imported into
+type importColumn struct {
+	Name       string
+	Type       string
+	PrimaryKey bool
+}
+
+// importForeignKey is a column that refers to a row of another table
+type importForeignKey struct {
+	Column string
+	Table  string
+}
+
+// importTable describes a table being imported into, as read from the database itself
+type importTable struct {
+	Name        string
+	Columns     map[string]importColumn
+	ForeignKeys []importForeignKey
+	// RemappedKey is the integer primary key that the database assigns afresh on import,
+	// or blank when rows keep their own key
+	RemappedKey string
+}
+
+// Import loads a full export into the database in one transaction. The export must come
+// from a database at the same schemaVersion, refer only to rows it contains, and the
+// database must hold no clients, projects or anything else the export does, apart from
+// the default settings the migrations add. Rows get new IDs and the references between
+// them are updated to match. An export that fails any of these checks is rejected with
+// ErrImportRejected and the problems found, without anything being imported.
+func (m *ExportModel) Import(export Export, schemaVersion int64) (ImportResult, error) {
+	ctx := context.Background()
+	tx, err := m.database.BeginTx(ctx, nil)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer tx.Rollback()
+
+	tables := make(map[string]importTable, len(ExportTables))
+	for _, name := range ExportTables {
+		table, err := describeImportTable(ctx, tx, name)
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("reading %s: %w", name, err)
+		}
+		tables[name] = table
+	}
+
+	problems, err := checkImport(ctx, tx, export, schemaVersion, tables)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	if len(problems) > 0 {
+		return ImportResult{Problems: problems}, ErrImportRejected
+	}
+
+	result := ImportResult{Rows: make(map[string]int, len(ExportTables))}
+	newIDs := map[string]map[int64]int64{}
+	for _, name := range ExportTables {
+		table := tables[name]
+		newIDs[name] = map[int64]int64{}
+		for i, row := range export.Tables[name] {
+			id, err := insertImportRow(ctx, tx, table, row, newIDs)
+			if err != nil {
+				return ImportResult{}, fmt.Errorf("importing %s row %d: %w", name, i+1, err)
+			}
+			if table.RemappedKey != "" {
+				oldID, _ := row[table.RemappedKey].(int64)
+				newIDs[name][oldID] = id
+			}
+		}
+		result.Rows[name] = len(export.Tables[name])
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ImportResult{}, err
+	}
+	return result, nil
+}
+
+// describeImportTable reads the columns, primary key and foreign keys of a table
+func describeImportTable(ctx context.Context, tx *sql.Tx, name string) (importTable, error) {
+	table := importTable{Name: name, Columns: map[string]importColumn{}}
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT name, type, pk FROM pragma_table_info('%s')`, name))
+	if err != nil {
+		return importTable{}, err
+	}
+	var primaryKeys []importColumn
+	for rows.Next() {
+		var column importColumn
+		var pk int
+		if err := rows.Scan(&column.Name, &column.Type, &pk); err != nil {
+			rows.Close()
+			return importTable{}, err
+		}
+		column.Type = strings.ToUpper(column.Type)
+		column.PrimaryKey = pk > 0
+		table.Columns[column.Name] = column
+		if column.PrimaryKey {
+			primaryKeys = append(primaryKeys, column)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return importTable{}, err
+	}
+
+	rows, err = tx.QueryContext(ctx, fmt.Sprintf(`SELECT "from", "table" FROM pragma_foreign_key_list('%s')`, name))
+	if err != nil {
+		return importTable{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key importForeignKey
+		if err := rows.Scan(&key.Column, &key.Table); err != nil {
+			return importTable{}, err
+		}
+		table.ForeignKeys = append(table.ForeignKeys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return importTable{}, err
+	}
+
+	// A single integer key is assigned by the database, unless it also refers to another
+	// table, as user_preferences' user_id does
+	if len(primaryKeys) == 1 && primaryKeys[0].Type == "INTEGER" && !table.isForeignKey(primaryKeys[0].Name) {
+		table.RemappedKey = primaryKeys[0].Name
+	}
+	return table, nil
+}
+
+// isForeignKey reports whether a column refers to another table
+func (t importTable) isForeignKey(column string) bool {
+	for _, key := range t.ForeignKeys {
+		if key.Column == column {
+			return true
+		}
+	}
+	return false
+}
+
+// checkImport lists everything that stops an export from being imported: a different
+// format or schema, tables or columns the database doesn't have, clashing or missing IDs,
+// and data already in the database
+func checkImport(ctx context.Context, tx *sql.Tx, export Export, schemaVersion int64, tables map[string]importTable) ([]string, error) {
+	var problems []string
+	if export.Format != ExportFormat {
+		return []string{"The file is not a Freelance Tracker export"}, nil
+	}
+	if export.Version > ExportFormatVersion {
+		problems = append(problems, fmt.Sprintf("The export is in format version %d, but only versions up to %d can be imported", export.Version, ExportFormatVersion))
+	}
+	if export.SchemaVersion != schemaVersion {
+		problems = append(problems, fmt.Sprintf("The export is from schema version %d but this database is at version %d; run the same migrations on both first", export.SchemaVersion, schemaVersion))
+	}
+
+	for name := range export.Tables {
+		if _, ok := tables[name]; !ok {
+			problems = append(problems, fmt.Sprintf("Table %s can't be imported", name))
+		}
+	}
+
+	ids := map[string]map[int64]bool{}
+	for _, name := range ExportTables {
+		table := tables[name]
+		ids[name] = map[int64]bool{}
+		for i, row := range export.Tables[name] {
+			for column := range row {
+				if _, ok := table.Columns[column]; !ok {
+					problems = append(problems, fmt.Sprintf("%s row %d has column %s, which the database doesn't", name, i+1, column))
+				}
+			}
+			if table.RemappedKey == "" {
+				continue
+			}
+			id, ok := row[table.RemappedKey].(int64)
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("%s row %d has no %s", name, i+1, table.RemappedKey))
+			case ids[name][id]:
+				problems = append(problems, fmt.Sprintf("%s %d appears more than once", name, id))
+			default:
+				ids[name][id] = true
+			}
+		}
+	}
+
+	for _, name := range ExportTables {
+		table := tables[name]
+		for _, key := range table.ForeignKeys {
+			parent, ok := ids[key.Table]
+			if !ok || tables[key.Table].RemappedKey == "" {
+				continue
+			}
+			for i, row := range export.Tables[name] {
+				value, ok := row[key.Column]
+				if !ok || value == nil {
+					continue
+				}
+				if id, ok := value.(int64); !ok || !parent[id] {
+					problems = append(problems, fmt.Sprintf("%s row %d refers to %s %v, which isn't in the export", name, i+1, key.Table, value))
+				}
+			}
+		}
+		if !importItemReferences[name] {
+			continue
+		}
+		for i, row := range export.Tables[name] {
+			kind, _ := row["kind"].(string)
+			if id, ok := row["item_id"].(int64); !ok || !ids[kind][id] {
+				problems = append(problems, fmt.Sprintf("%s row %d refers to %s %v, which isn't in the export", name, i+1, kind, row["item_id"]))
+			}
+		}
+	}
+
+	for _, name := range ExportTables {
+		if importReplaceTables[name] {
+			continue
+		}
+		var count int
+		err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM "%s"`, name)).Scan(&count)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			problems = append(problems, fmt.Sprintf("Table %s already has %d rows; imports only go into an empty database", name, count))
+		}
+	}
+	return problems, nil
+}
+
+// insertImportRow inserts one exported row, pointing its references at the new IDs of the
+// rows they refer to, and returns the ID the database gave it
+func insertImportRow(ctx context.Context, tx *sql.Tx, table importTable, row ExportRow, newIDs map[string]map[int64]int64) (int64, error) {
+	references := make(map[string]string, len(table.ForeignKeys))
+	for _, key := range table.ForeignKeys {
+		references[key.Column] = key.Table
+	}
+	if kind, ok := row["kind"].(string); ok && importItemReferences[table.Name] {
+		references["item_id"] = kind
+	}
+
+	var columns, placeholders []string
+	var args []any
+	for column, value := range row {
+		if column == table.RemappedKey {
+			continue
+		}
+		value = importValue(table.Columns[column], value)
+		if parent, ok := references[column]; ok {
+			if id, ok := value.(int64); ok {
+				if newID, ok := newIDs[parent][id]; ok {
+					value = newID
+				}
+			}
+		}
+		columns = append(columns, fmt.Sprintf(`"%s"`, column))
+		placeholders = append(placeholders, "?")
+		args = append(args, value)
+	}
+
+	verb := "INSERT"
+	if importReplaceTables[table.Name] {
+		verb = "INSERT OR REPLACE"
+	}
+	query := fmt.Sprintf(`%s INTO "%s" (%s) VALUES (%s)`, verb, table.Name, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// importValue turns dates, which an export holds as text, back into times so they are
+// stored the same way the application stores them
+func importValue(column importColumn, value any) any {
+	text, ok := value.(string)
+	if !ok || !(strings.HasPrefix(column.Type, "DATE") || column.Type == "TIMESTAMP") {
+		return value
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t
+		}
+	}
+	return value
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportModel_Import(t *testing.T) {
+	source := testutil.SetupTestSQLite(t)
+	defer source.Cleanup(t)
+
+	// Remove a client first so IDs in the source don't simply start at one
+	gone := source.InsertTestClient(t, "Gone")
+	_, err := source.DB.Exec("DELETE FROM client WHERE id = ?", gone)
+	require.NoError(t, err)
+
+	clientID := source.InsertTestClient(t, "Acme")
+	projectID := source.InsertTestProject(t, "Book", clientID)
+	source.InsertTestTimesheet(t, projectID, "2024-01-10", "2.50", "80.00", "Copyedit")
+	approvals := NewClientApprovalModel(source.DB)
+	approvalID, err := approvals.Insert(projectID, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.NoError(t, approvals.Approve(approvalID, "Wile E.", "203.0.113.7", time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)))
+	invoiceID := source.InsertTestInvoice(t, projectID, "2024-02-01", "2024-02-20", "Net 30", "200.00")
+	require.NoError(t, NewInvoiceModel(source.DB).saveSnapshot(InvoiceSnapshot{
+		InvoiceID:      invoiceID,
+		BillTo:         []string{"Acme", "1 Old Road"},
+		FreelancerName: "Jane Doe",
+	}))
+	tagID, err := NewTagModel(source.DB).Insert(Tag{Name: "Priority", Color: "#ff0000"})
+	require.NoError(t, err)
+	require.NoError(t, NewTagModel(source.DB).SetClientTags(clientID, []int{tagID}))
+	userID, err := NewUserModel(source.DB).Insert(User{Name: "Owner", Email: "owner@example.com", Role: "owner"}, "secret password")
+	require.NoError(t, err)
+	pageSize := 25
+	require.NoError(t, NewUserPreferencesModel(source.DB).Save(UserPreferences{UserID: userID, PageSize: &pageSize}))
+	require.NoError(t, NewAppSettingModel(source.DB).UpdateValue("freelancer_name", "Jane Doe"))
+	require.NoError(t, NewExchangeRateModel(source.DB).Save(ExchangeRate{Currency: "EUR", Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Rate: 0.92, Source: "ECB"}))
+	require.NoError(t, NewProjectDefaultsModel(source.DB).Save(ProjectDefaults{ClientID: clientID, Status: "Estimating", CurrencyDisplay: "EUR", CurrencyConversionRate: 0.92}))
+	require.NoError(t, NewExternalIDModel(source.DB).Set(ExternalProject, projectID, "harvest", "P-17"))
+
+	export, err := NewExportModel(source.DB).Export(39)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteZip(&buf))
+	read, err := ReadExport(buf.Bytes())
+	require.NoError(t, err)
+
+	t.Run("loads everything into an empty database with new IDs", func(t *testing.T) {
+		target := testutil.SetupTestSQLite(t)
+		defer target.Cleanup(t)
+
+		result, err := NewExportModel(target.DB).Import(read, 39)
+		require.NoError(t, err)
+		assert.Empty(t, result.Problems)
+		assert.Equal(t, 1, result.Rows["client"])
+		assert.Equal(t, 1, result.Rows["timesheet"])
+
+		clients, err := NewClientModel(target.DB).GetAll()
+		require.NoError(t, err)
+		require.Len(t, clients, 1)
+		assert.Equal(t, "Acme", clients[0].Name)
+		assert.NotEqual(t, clientID, clients[0].ID)
+
+		projects, err := NewProjectModel(target.DB).GetByClient(clients[0].ID)
+		require.NoError(t, err)
+		require.Len(t, projects, 1)
+		timesheets, err := NewTimesheetModel(target.DB).GetByProject(projects[0].ID)
+		require.NoError(t, err)
+		require.Len(t, timesheets, 1)
+		assert.Equal(t, "2024-01-10", timesheets[0].WorkDate.Format("2006-01-02"))
+		assert.Equal(t, 2.5, timesheets[0].HoursWorked)
+
+		approvals, err := NewClientApprovalModel(target.DB).GetByProject(projects[0].ID)
+		require.NoError(t, err)
+		require.Len(t, approvals, 1, "timesheet approvals carry over")
+		assert.True(t, approvals[0].Approved())
+		assert.Equal(t, "Wile E.", approvals[0].ApprovedBy)
+
+		invoices, err := NewInvoiceModel(target.DB).GetByProject(projects[0].ID)
+		require.NoError(t, err)
+		require.Len(t, invoices, 1)
+		require.NotNil(t, invoices[0].DatePaid)
+		assert.Equal(t, "2024-02-20", invoices[0].DatePaid.Format("2006-01-02"))
+		snapshot, err := NewInvoiceModel(target.DB).snapshot(invoices[0].ID)
+		require.NoError(t, err)
+		require.NotNil(t, snapshot, "the billing details the invoice was printed with carry over")
+		assert.Equal(t, []string{"Acme", "1 Old Road"}, snapshot.BillTo)
+
+		tags, err := NewTagModel(target.DB).ForClient(clients[0].ID)
+		require.NoError(t, err)
+		require.Len(t, tags, 1)
+		assert.Equal(t, "Priority", tags[0].Name)
+
+		users := NewUserModel(target.DB)
+		user, err := users.Authenticate("owner@example.com", "secret password")
+		require.NoError(t, err, "password hashes carry over")
+		preferences, err := NewUserPreferencesModel(target.DB).Get(user)
+		require.NoError(t, err)
+		require.NotNil(t, preferences.PageSize)
+		assert.Equal(t, 25, *preferences.PageSize)
+
+		name, err := NewAppSettingModel(target.DB).GetString("freelancer_name")
+		require.NoError(t, err)
+		assert.Equal(t, "Jane Doe", name, "exported settings replace the defaults")
+
+		rate, err := NewExchangeRateModel(target.DB).GetRate("EUR", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, 0.92, rate.Rate)
+
+		defaults, err := NewProjectDefaultsModel(target.DB).Get(clients[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "EUR", defaults.CurrencyDisplay)
+
+		itemID, err := NewExternalIDModel(target.DB).Find(ExternalProject, "harvest", "P-17")
+		require.NoError(t, err)
+		assert.Equal(t, projects[0].ID, itemID, "external IDs follow their records to their new IDs")
+	})
+
+	t.Run("rejects a database that already has data", func(t *testing.T) {
+		target := testutil.SetupTestSQLite(t)
+		defer target.Cleanup(t)
+		target.InsertTestClient(t, "Existing")
+
+		result, err := NewExportModel(target.DB).Import(read, 39)
+		assert.ErrorIs(t, err, ErrImportRejected)
+		assert.Contains(t, result.Problems, "Table client already has 1 rows; imports only go into an empty database")
+	})
+
+	t.Run("rejects a different schema version", func(t *testing.T) {
+		target := testutil.SetupTestSQLite(t)
+		defer target.Cleanup(t)
+
+		result, err := NewExportModel(target.DB).Import(read, 40)
+		assert.ErrorIs(t, err, ErrImportRejected)
+		require.Len(t, result.Problems, 1)
+		assert.Contains(t, result.Problems[0], "schema version 39")
+	})
+
+	t.Run("rejects broken references and clashing IDs without importing anything", func(t *testing.T) {
+		target := testutil.SetupTestSQLite(t)
+		defer target.Cleanup(t)
+
+		broken, err := ReadExport(buf.Bytes())
+		require.NoError(t, err)
+		broken.Tables["project"][0]["client_id"] = int64(999)
+		broken.Tables["external_id"][0]["item_id"] = int64(999)
+		broken.Tables["tag"] = append(broken.Tables["tag"], ExportRow{"id": int64(tagID), "name": "Copy", "color": "#000000"})
+		broken.Tables["mystery"] = []ExportRow{{"id": int64(1)}}
+
+		result, err := NewExportModel(target.DB).Import(broken, 39)
+		assert.ErrorIs(t, err, ErrImportRejected)
+		assert.Contains(t, result.Problems, "project row 1 refers to client 999, which isn't in the export")
+		assert.Contains(t, result.Problems, "external_id row 1 refers to project 999, which isn't in the export")
+		assert.Contains(t, result.Problems, "tag 1 appears more than once")
+		assert.Contains(t, result.Problems, "Table mystery can't be imported")
+
+		clients, err := NewClientModel(target.DB).GetAll()
+		require.NoError(t, err)
+		assert.Empty(t, clients)
+	})
+
+	t.Run("rejects files that aren't exports", func(t *testing.T) {
+		_, err := ReadExport([]byte("not json"))
+		assert.Error(t, err)
+
+		export, err := ReadExport([]byte(`{"format": "something-else"}`))
+		require.NoError(t, err)
+		result, err := NewExportModel(source.DB).Import(export, 39)
+		assert.ErrorIs(t, err, ErrImportRejected)
+		assert.Equal(t, []string{"The file is not a Freelance Tracker export"}, result.Problems)
+	})
+}
@@ -0,0 +1,204 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Ways of repairing orphaned rows
+const (
+	RepairReattach   = "reattach"
+	RepairSoftDelete = "soft_delete"
+)
+
+// OrphanCheck describes rows whose reference to a parent row points at one that is missing,
+// or that has been deleted while they haven't. Rows of tables without soft deletes are only
+// orphaned when the parent is missing altogether.
+type OrphanCheck struct {
+	Name        string
+	Description string
+	Table       string
+	Column      string
+	Parent      string
+	SoftDelete  bool
+	Reattach    bool
+}
+
+// OrphanChecks lists the references the integrity check follows. Invoice history can only be
+// reported, since moving it to another invoice would misrepresent both.
+var OrphanChecks = []OrphanCheck{
+	{Name: "project_client", Description: "Projects whose client is missing or deleted", Table: "project", Column: "client_id", Parent: "client", SoftDelete: true, Reattach: true},
+	{Name: "timesheet_project", Description: "Timesheets whose project is missing or deleted", Table: "timesheet", Column: "project_id", Parent: "project", SoftDelete: true, Reattach: true},
+	{Name: "invoice_project", Description: "Invoices whose project is missing or deleted", Table: "invoice", Column: "project_id", Parent: "project", SoftDelete: true, Reattach: true},
+	{Name: "milestone_project", Description: "Milestones whose project is missing or deleted", Table: "milestone", Column: "project_id", Parent: "project", SoftDelete: true, Reattach: true},
+	{Name: "pending_timesheet_project", Description: "Pending timesheets whose project is missing", Table: "pending_timesheet", Column: "project_id", Parent: "project", Reattach: true},
+	{Name: "client_credit_client", Description: "Credits whose client is missing", Table: "client_credit", Column: "client_id", Parent: "client", Reattach: true},
+	{Name: "client_note_client", Description: "Notes whose client is missing", Table: "client_note", Column: "client_id", Parent: "client", Reattach: true},
+	{Name: "invoice_event_invoice", Description: "Invoice history for an invoice that is missing", Table: "invoice_event", Column: "invoice_id", Parent: "invoice"},
+}
+
+// LookupOrphanCheck returns the orphan check with the given name
+func LookupOrphanCheck(name string) (OrphanCheck, bool) {
+	for _, check := range OrphanChecks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return OrphanCheck{}, false
+}
+
+// ParentLabel names the kind of row orphans can be reattached to, for display
+func (c OrphanCheck) ParentLabel() string {
+	return strings.ToUpper(c.Parent[:1]) + c.Parent[1:]
+}
+
+// orphanQuery selects the IDs of the check's orphaned rows
+func (c OrphanCheck) orphanQuery() string {
+	condition := "p.id IS NULL"
+	if c.SoftDelete {
+		condition = "c.deleted_at IS NULL AND (p.id IS NULL OR p.deleted_at IS NOT NULL)"
+	}
+	return fmt.Sprintf(`SELECT c.id FROM "%s" c LEFT JOIN "%s" p ON p.id = c."%s" WHERE %s ORDER BY c.id`,
+		c.Table, c.Parent, c.Column, condition)
+}
+
+// Orphans are the rows one check found orphaned
+type Orphans struct {
+	Check OrphanCheck
+	IDs   []int
+}
+
+// IntegrityReport is the outcome of a database integrity check. Problems holds what SQLite's
+// own integrity check reported, which is nothing for a sound database file.
+type IntegrityReport struct {
+	Problems []string
+	Orphans  []Orphans
+}
+
+// OK reports whether the check found nothing wrong
+func (r IntegrityReport) OK() bool {
+	return len(r.Problems) == 0 && len(r.Orphans) == 0
+}
+
+// IntegrityModel checks the database for corruption and for rows left pointing at missing or
+// deleted parents, and repairs the latter. Like ExportModel it works on tables directly
+// rather than through the generated queries, since the checks are the same for each table.
+type IntegrityModel struct {
+	database *sql.DB
+}
+
+// NewIntegrityModel creates a new IntegrityModel
+func NewIntegrityModel(database *sql.DB) *IntegrityModel {
+	return &IntegrityModel{
+		database: database,
+	}
+}
+
+// Check runs SQLite's integrity check and every orphan check
+func (m *IntegrityModel) Check() (IntegrityReport, error) {
+	ctx := context.Background()
+	var report IntegrityReport
+
+	rows, err := m.database.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return IntegrityReport{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var message string
+		if err := rows.Scan(&message); err != nil {
+			return IntegrityReport{}, err
+		}
+		if message != "ok" {
+			report.Problems = append(report.Problems, message)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return IntegrityReport{}, err
+	}
+
+	for _, check := range OrphanChecks {
+		ids, err := orphanIDs(ctx, m.database, check)
+		if err != nil {
+			return IntegrityReport{}, fmt.Errorf("checking %s: %w", check.Name, err)
+		}
+		if len(ids) > 0 {
+			report.Orphans = append(report.Orphans, Orphans{Check: check, IDs: ids})
+		}
+	}
+	return report, nil
+}
+
+// orphanIDs returns the IDs of the rows a check finds orphaned
+func orphanIDs(ctx context.Context, database *sql.DB, check OrphanCheck) ([]int, error) {
+	rows, err := database.QueryContext(ctx, check.orphanQuery())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Repair fixes the rows a check finds orphaned, either by pointing them at parentID or by
+// soft deleting them, and returns how many rows it changed. Rows soft deleted because their
+// parent was take the parent's deletion time, so restoring the parent restores them too.
+// It returns ErrValidation for a repair the check doesn't allow, and ErrNoRecord when
+// parentID isn't a row they can be reattached to.
+func (m *IntegrityModel) Repair(checkName, action string, parentID int) (int, error) {
+	check, ok := LookupOrphanCheck(checkName)
+	if !ok {
+		return 0, ErrValidation
+	}
+
+	var query string
+	var args []any
+	switch {
+	case action == RepairReattach && check.Reattach:
+		var exists bool
+		err := m.database.QueryRow(fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM "%s" WHERE id = ? AND deleted_at IS NULL)`, check.Parent),
+			parentID).Scan(&exists)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			return 0, ErrNoRecord
+		}
+		query = fmt.Sprintf(`UPDATE "%s" SET "%s" = ? WHERE id IN (%s)`, check.Table, check.Column, check.orphanQuery())
+		args = []any{parentID}
+	case action == RepairSoftDelete && check.SoftDelete:
+		query = fmt.Sprintf(`UPDATE "%s" SET deleted_at = COALESCE((SELECT p.deleted_at FROM "%s" p WHERE p.id = "%s"."%s"), CURRENT_TIMESTAMP) WHERE id IN (%s)`,
+			check.Table, check.Parent, check.Table, check.Column, check.orphanQuery())
+	default:
+		return 0, ErrValidation
+	}
+
+	result, err := m.database.Exec(query, args...)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	changed, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(changed), nil
+}
+
+// IntegrityModelInterface defines the interface for database integrity checks
+type IntegrityModelInterface interface {
+	Check() (IntegrityReport, error)
+	Repair(checkName, action string, parentID int) (int, error)
+}
+
+// Ensure implementation satisfies the interface
+var _ IntegrityModelInterface = (*IntegrityModel)(nil)
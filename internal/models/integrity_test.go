@@ -0,0 +1,92 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegrityModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewIntegrityModel(testDB.DB)
+	projects := NewProjectModel(testDB.DB)
+	timesheets := NewTimesheetModel(testDB.DB)
+
+	t.Run("a consistent database passes", func(t *testing.T) {
+		clientID := testDB.InsertTestClient(t, "Sound Client")
+		projectID := testDB.InsertTestProject(t, "Sound Project", clientID)
+		testDB.InsertTestTimesheet(t, projectID, "2024-05-02", "1.0", "50.00", "Editing")
+		require.NoError(t, projects.Delete(projectID))
+
+		report, err := model.Check()
+		require.NoError(t, err)
+		assert.True(t, report.OK())
+	})
+
+	clientID := testDB.InsertTestClient(t, "Orphan Client")
+	deletedID := testDB.InsertTestProject(t, "Deleted Project", clientID)
+	targetID := testDB.InsertTestProject(t, "Target Project", clientID)
+	strandedID := testDB.InsertTestTimesheet(t, deletedID, "2024-05-02", "1.0", "50.00", "Left behind")
+	missingID := testDB.InsertTestTimesheet(t, targetID, "2024-05-03", "2.0", "50.00", "Missing project")
+	_, err := testDB.DB.Exec("UPDATE project SET deleted_at = '2024-05-10 09:00:00' WHERE id = ?", deletedID)
+	require.NoError(t, err)
+	_, err = testDB.DB.Exec("PRAGMA foreign_keys = OFF")
+	require.NoError(t, err)
+	_, err = testDB.DB.Exec("UPDATE timesheet SET project_id = 9999 WHERE id = ?", missingID)
+	require.NoError(t, err)
+	_, err = testDB.DB.Exec("PRAGMA foreign_keys = ON")
+	require.NoError(t, err)
+
+	t.Run("rows left on missing or deleted parents are reported", func(t *testing.T) {
+		report, err := model.Check()
+		require.NoError(t, err)
+		assert.False(t, report.OK())
+		assert.Empty(t, report.Problems)
+		require.Len(t, report.Orphans, 1)
+		assert.Equal(t, "timesheet_project", report.Orphans[0].Check.Name)
+		assert.Equal(t, []int{strandedID, missingID}, report.Orphans[0].IDs)
+	})
+
+	t.Run("repairs the check doesn't allow are refused", func(t *testing.T) {
+		_, err := model.Repair("invoice_event_invoice", RepairSoftDelete, 0)
+		assert.ErrorIs(t, err, ErrValidation)
+		_, err = model.Repair("unknown", RepairReattach, targetID)
+		assert.ErrorIs(t, err, ErrValidation)
+		_, err = model.Repair("timesheet_project", RepairReattach, deletedID)
+		assert.ErrorIs(t, err, ErrNoRecord)
+	})
+
+	t.Run("orphans are reattached or soft deleted", func(t *testing.T) {
+		_, err := testDB.DB.Exec("PRAGMA foreign_keys = OFF")
+		require.NoError(t, err)
+		changed, err := model.Repair("timesheet_project", RepairReattach, targetID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, changed)
+		_, err = testDB.DB.Exec("PRAGMA foreign_keys = ON")
+		require.NoError(t, err)
+		timesheet, err := timesheets.Get(missingID)
+		require.NoError(t, err)
+		assert.Equal(t, targetID, timesheet.ProjectID)
+
+		_, err = testDB.DB.Exec("UPDATE timesheet SET project_id = ? WHERE id = ?", deletedID, strandedID)
+		require.NoError(t, err)
+		changed, err = model.Repair("timesheet_project", RepairSoftDelete, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 1, changed)
+		_, err = timesheets.Get(strandedID)
+		assert.ErrorIs(t, err, ErrNoRecord)
+
+		// Restoring the project brings back the timesheet deleted along with it
+		require.NoError(t, projects.Restore(deletedID))
+		_, err = timesheets.Get(strandedID)
+		assert.NoError(t, err)
+
+		report, err := model.Check()
+		require.NoError(t, err)
+		assert.True(t, report.OK())
+	})
+}
@@ -0,0 +1,173 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// Delivery statuses, recording whether the mail server accepted an invoice email
+const (
+	DeliveryStatusSent   = "sent"
+	DeliveryStatusFailed = "failed"
+)
+
+// InvoiceDelivery is one attempt to email an invoice. SMTPResponse holds the mail server's
+// reply, or the reason the attempt failed before reaching the server. Opens are only
+// recorded for deliveries sent with a tracking token.
+type InvoiceDelivery struct {
+	ID            int
+	InvoiceID     int
+	Recipients    string
+	Subject       string
+	Status        string
+	SMTPResponse  string
+	TrackingToken string
+	OpenCount     int
+	FirstOpened   *time.Time
+	LastOpened    *time.Time
+	Created       time.Time
+}
+
+// IsSent reports whether the mail server accepted the email
+func (d InvoiceDelivery) IsSent() bool {
+	return d.Status == DeliveryStatusSent
+}
+
+// DeliverySummary totals an invoice's deliveries, for a line such as "sent 3 times, last opened May 4"
+type DeliverySummary struct {
+	Sent       int
+	Failed     int
+	LastSent   *time.Time
+	Opens      int
+	LastOpened *time.Time
+}
+
+// SummarizeDeliveries totals deliveries
+func SummarizeDeliveries(deliveries []InvoiceDelivery) DeliverySummary {
+	var summary DeliverySummary
+	for _, delivery := range deliveries {
+		if !delivery.IsSent() {
+			summary.Failed++
+			continue
+		}
+		summary.Sent++
+		if summary.LastSent == nil || delivery.Created.After(*summary.LastSent) {
+			created := delivery.Created
+			summary.LastSent = &created
+		}
+		summary.Opens += delivery.OpenCount
+		if delivery.LastOpened != nil && (summary.LastOpened == nil || delivery.LastOpened.After(*summary.LastOpened)) {
+			summary.LastOpened = delivery.LastOpened
+		}
+	}
+	return summary
+}
+
+// NewTrackingToken returns a random token identifying a delivery in the image that tracks opens
+func NewTrackingToken() (string, error) {
+	token := make([]byte, 18)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// InvoiceDeliveryModel wraps the generated SQLC Queries for invoice delivery operations
+type InvoiceDeliveryModel struct {
+	queries *db.Queries
+}
+
+// NewInvoiceDeliveryModel creates a new InvoiceDeliveryModel
+func NewInvoiceDeliveryModel(database *sql.DB) *InvoiceDeliveryModel {
+	return &InvoiceDeliveryModel{
+		queries: newQueries(database),
+	}
+}
+
+// NewInvoiceDeliveryModelWithTx creates an InvoiceDeliveryModel whose queries run inside the given transaction
+func NewInvoiceDeliveryModelWithTx(tx *sql.Tx) *InvoiceDeliveryModel {
+	return &InvoiceDeliveryModel{
+		queries: newQueries(tx),
+	}
+}
+
+// Record logs a delivery attempt and returns its ID
+func (m *InvoiceDeliveryModel) Record(delivery InvoiceDelivery) (int, error) {
+	ctx := context.Background()
+	id, err := m.queries.InsertInvoiceDelivery(ctx, db.InsertInvoiceDeliveryParams{
+		InvoiceID:     int64(delivery.InvoiceID),
+		Recipients:    delivery.Recipients,
+		Subject:       delivery.Subject,
+		Status:        delivery.Status,
+		SmtpResponse:  delivery.SMTPResponse,
+		TrackingToken: sql.NullString{String: delivery.TrackingToken, Valid: delivery.TrackingToken != ""},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// GetByInvoice returns an invoice's delivery attempts, newest first
+func (m *InvoiceDeliveryModel) GetByInvoice(invoiceID int) ([]InvoiceDelivery, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetInvoiceDeliveries(ctx, int64(invoiceID))
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]InvoiceDelivery, len(rows))
+	for i, row := range rows {
+		deliveries[i] = InvoiceDelivery{
+			ID:            int(row.ID),
+			InvoiceID:     int(row.InvoiceID),
+			Recipients:    row.Recipients,
+			Subject:       row.Subject,
+			Status:        row.Status,
+			SMTPResponse:  row.SmtpResponse,
+			TrackingToken: row.TrackingToken.String,
+			OpenCount:     int(row.OpenCount),
+			FirstOpened:   convertNullTime(row.FirstOpenedAt),
+			LastOpened:    convertNullTime(row.LastOpenedAt),
+			Created:       row.CreatedAt,
+		}
+	}
+	return deliveries, nil
+}
+
+// RecordOpen counts an opening of the delivery with a tracking token, returning ErrNoRecord
+// for a token no delivery has
+func (m *InvoiceDeliveryModel) RecordOpen(token string, at time.Time) error {
+	if token == "" {
+		return ErrNoRecord
+	}
+	ctx := context.Background()
+	openedAt := at.UTC().Truncate(time.Second)
+	count, err := m.queries.RecordInvoiceDeliveryOpen(ctx, db.RecordInvoiceDeliveryOpenParams{
+		FirstOpenedAt: openedAt,
+		LastOpenedAt:  sql.NullTime{Time: openedAt, Valid: true},
+		TrackingToken: sql.NullString{String: token, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
+
+// InvoiceDeliveryModelInterface defines the interface for invoice delivery operations
+type InvoiceDeliveryModelInterface interface {
+	Record(delivery InvoiceDelivery) (int, error)
+	GetByInvoice(invoiceID int) ([]InvoiceDelivery, error)
+	RecordOpen(token string, at time.Time) error
+}
+
+// Ensure implementation satisfies the interface
+var _ InvoiceDeliveryModelInterface = (*InvoiceDeliveryModel)(nil)
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceDeliveryModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceDeliveryModel(testDB.DB)
+	clientID := testDB.InsertTestClient(t, "Delivery Client")
+	projectID := testDB.InsertTestProject(t, "Delivery Project", clientID)
+	invoiceID := testDB.InsertTestInvoice(t, projectID, "2024-05-01", "", "Net 30", "100.00")
+
+	token, err := NewTrackingToken()
+	require.NoError(t, err)
+	_, err = model.Record(InvoiceDelivery{InvoiceID: invoiceID, Recipients: "ap@client.test", Subject: "Invoice 1",
+		Status: DeliveryStatusFailed, SMTPResponse: "550 5.1.1 User unknown"})
+	require.NoError(t, err)
+	_, err = model.Record(InvoiceDelivery{InvoiceID: invoiceID, Recipients: "billing@client.test", Subject: "Invoice 1",
+		Status: DeliveryStatusSent, SMTPResponse: "250 2.0.0 Ok: queued as 4Hx2", TrackingToken: token})
+	require.NoError(t, err)
+	_, err = model.Record(InvoiceDelivery{InvoiceID: invoiceID, Recipients: "billing@client.test", Subject: "Invoice 1",
+		Status: DeliveryStatusSent, SMTPResponse: "250 2.0.0 Ok: queued as 9Zq1"})
+	require.NoError(t, err)
+
+	t.Run("deliveries are listed newest first", func(t *testing.T) {
+		deliveries, err := model.GetByInvoice(invoiceID)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 3)
+		assert.Equal(t, "250 2.0.0 Ok: queued as 9Zq1", deliveries[0].SMTPResponse)
+		assert.Empty(t, deliveries[0].TrackingToken)
+		assert.Equal(t, token, deliveries[1].TrackingToken)
+		assert.False(t, deliveries[2].IsSent())
+		assert.Nil(t, deliveries[1].FirstOpened)
+	})
+
+	t.Run("opens are counted against the tracking token", func(t *testing.T) {
+		first := time.Date(2024, 5, 3, 8, 0, 0, 0, time.UTC)
+		second := time.Date(2024, 5, 4, 17, 30, 0, 0, time.UTC)
+		require.NoError(t, model.RecordOpen(token, first))
+		require.NoError(t, model.RecordOpen(token, second))
+		assert.ErrorIs(t, model.RecordOpen("unknown", second), ErrNoRecord)
+		assert.ErrorIs(t, model.RecordOpen("", second), ErrNoRecord)
+
+		deliveries, err := model.GetByInvoice(invoiceID)
+		require.NoError(t, err)
+		opened := deliveries[1]
+		assert.Equal(t, 2, opened.OpenCount)
+		require.NotNil(t, opened.FirstOpened)
+		assert.True(t, opened.FirstOpened.Equal(first))
+		require.NotNil(t, opened.LastOpened)
+		assert.True(t, opened.LastOpened.Equal(second))
+
+		summary := SummarizeDeliveries(deliveries)
+		assert.Equal(t, 2, summary.Sent)
+		assert.Equal(t, 1, summary.Failed)
+		assert.Equal(t, 2, summary.Opens)
+		require.NotNil(t, summary.LastOpened)
+		assert.True(t, summary.LastOpened.Equal(second))
+		assert.NotNil(t, summary.LastSent)
+	})
+}
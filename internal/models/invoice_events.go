@@ -0,0 +1,131 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// Invoice lifecycle event types
+const (
+	InvoiceEventCreated = "created"
+	InvoiceEventUpdated = "updated"
+	InvoiceEventPaid    = "paid"
+	InvoiceEventVoided  = "voided"
+	InvoiceEventDeleted = "deleted"
+)
+
+// IsInvoiceEventType reports whether eventType is one of the invoice event types
+func IsInvoiceEventType(eventType string) bool {
+	switch eventType {
+	case InvoiceEventCreated, InvoiceEventUpdated, InvoiceEventPaid, InvoiceEventVoided, InvoiceEventDeleted:
+		return true
+	}
+	return false
+}
+
+// InvoiceEvent records a change to an invoice, along with the invoice as it is now
+type InvoiceEvent struct {
+	ID       int
+	Type     string
+	Occurred time.Time
+	Invoice  InvoiceWithClient
+}
+
+// InvoiceEventModel wraps the generated SQLC Queries for invoice event operations
+type InvoiceEventModel struct {
+	queries *db.Queries
+}
+
+// NewInvoiceEventModel creates a new InvoiceEventModel
+func NewInvoiceEventModel(database *sql.DB) *InvoiceEventModel {
+	return &InvoiceEventModel{
+		queries: newQueries(database),
+	}
+}
+
+// NewInvoiceEventModelWithTx creates an InvoiceEventModel whose queries run inside the given transaction
+func NewInvoiceEventModelWithTx(tx *sql.Tx) *InvoiceEventModel {
+	return &InvoiceEventModel{
+		queries: newQueries(tx),
+	}
+}
+
+// Record adds an event to an invoice's history
+func (e *InvoiceEventModel) Record(invoiceID int, eventType string) error {
+	ctx := context.Background()
+	_, err := e.queries.InsertInvoiceEvent(ctx, db.InsertInvoiceEventParams{
+		InvoiceID: int64(invoiceID),
+		EventType: eventType,
+	})
+	return err
+}
+
+// GetAfter retrieves up to limit events with an ID greater than afterID, oldest first
+func (e *InvoiceEventModel) GetAfter(afterID, limit int) ([]InvoiceEvent, error) {
+	ctx := context.Background()
+	rows, err := e.queries.GetInvoiceEventsAfter(ctx, db.GetInvoiceEventsAfterParams{
+		ID:    int64(afterID),
+		Limit: int64(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	rounding, err := invoiceRounding(e.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]InvoiceEvent, len(rows))
+	for i, row := range rows {
+		events[i] = InvoiceEvent{
+			ID:       int(row.EventID),
+			Type:     row.EventType,
+			Occurred: row.OccurredAt,
+			Invoice: convertInvoiceWithClientRow(db.GetInvoicesWithClientAfterRow{
+				ID:                     row.ID,
+				ProjectID:              row.ProjectID,
+				InvoiceDate:            row.InvoiceDate,
+				DatePaid:               row.DatePaid,
+				PaymentTerms:           row.PaymentTerms,
+				AmountDue:              row.AmountDue,
+				InvoiceNumber:          row.InvoiceNumber,
+				VoidedAt:               row.VoidedAt,
+				VoidReason:             row.VoidReason,
+				AmountPaid:             row.AmountPaid,
+				CreditApplied:          row.CreditApplied,
+				DueDate:                row.DueDate,
+				UpdatedAt:              row.UpdatedAt,
+				CreatedAt:              row.CreatedAt,
+				DeletedAt:              row.DeletedAt,
+				ProjectName:            row.ProjectName,
+				CurrencyDisplay:        row.CurrencyDisplay,
+				ClientID:               row.ClientID,
+				ClientName:             row.ClientName,
+				HourlyRate:             row.HourlyRate,
+				DiscountPercent:        row.DiscountPercent,
+				DiscountAmount:         row.DiscountAmount,
+				DiscountReason:         row.DiscountReason,
+				AdjustmentAmount:       row.AdjustmentAmount,
+				AdjustmentReason:       row.AdjustmentReason,
+				InvoiceCurrencyDisplay: row.InvoiceCurrencyDisplay,
+				CurrencyConversionRate: row.CurrencyConversionRate,
+				FinancialsCapturedAt:   row.FinancialsCapturedAt,
+				ConversionRateSource:   row.ConversionRateSource,
+				ConvertedAt:            row.ConvertedAt,
+			}, rounding),
+		}
+	}
+	return events, nil
+}
+
+// InvoiceEventModelInterface defines the interface for invoice event operations
+type InvoiceEventModelInterface interface {
+	Record(invoiceID int, eventType string) error
+	GetAfter(afterID, limit int) ([]InvoiceEvent, error)
+}
+
+// Ensure implementation satisfies the interface
+var _ InvoiceEventModelInterface = (*InvoiceEventModel)(nil)
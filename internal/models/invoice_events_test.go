@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceEventModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceEventModel(testDB.DB)
+	invoices := NewInvoiceModel(testDB.DB)
+
+	testDB.TruncateTable(t, "invoice_event")
+	testDB.TruncateTable(t, "invoice")
+	testDB.TruncateTable(t, "project")
+	testDB.TruncateTable(t, "client")
+
+	clientID := testDB.InsertTestClient(t, "Event Client")
+	projectID := testDB.InsertTestProject(t, "Event Project", clientID)
+	firstID := testDB.InsertTestInvoice(t, projectID, "2024-01-15", "", "Net 30", "100.00")
+	secondID := testDB.InsertTestInvoice(t, projectID, "2024-02-15", "2024-02-20", "Net 30", "80.00")
+
+	require.NoError(t, model.Record(firstID, InvoiceEventCreated))
+	require.NoError(t, model.Record(secondID, InvoiceEventCreated))
+	require.NoError(t, model.Record(secondID, InvoiceEventPaid))
+	require.NoError(t, invoices.Delete(firstID))
+	require.NoError(t, model.Record(firstID, InvoiceEventDeleted))
+
+	t.Run("events are returned oldest first after the cursor", func(t *testing.T) {
+		events, err := model.GetAfter(0, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 4)
+		assert.Equal(t, InvoiceEventCreated, events[0].Type)
+		assert.Equal(t, "Event Client", events[0].Invoice.ClientName)
+		assert.Equal(t, "Event Project", events[0].Invoice.ProjectName)
+
+		// Events for deleted invoices still carry the invoice
+		assert.Equal(t, InvoiceEventDeleted, events[3].Type)
+		assert.Equal(t, firstID, events[3].Invoice.ID)
+		assert.NotNil(t, events[3].Invoice.DeletedAt)
+
+		later, err := model.GetAfter(events[1].ID, 1)
+		require.NoError(t, err)
+		require.Len(t, later, 1)
+		assert.Equal(t, InvoiceEventPaid, later[0].Type)
+		assert.Equal(t, secondID, later[0].Invoice.ID)
+		assert.NotNil(t, later[0].Invoice.DatePaid)
+	})
+
+	t.Run("invoices with client skip deleted invoices", func(t *testing.T) {
+		page, err := invoices.GetWithClientAfter(0, 10)
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, secondID, page[0].ID)
+		assert.Equal(t, "USD", page[0].Currency)
+
+		_, err = invoices.GetWithClient(firstID)
+		assert.ErrorIs(t, err, ErrNoRecord)
+	})
+}
@@ -0,0 +1,413 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// InvoiceLabels are the fixed words printed on an invoice, in one language. Everything else
+// on the invoice, such as the title, the payment terms and the descriptions of the work, is
+// printed as it was entered.
+type InvoiceLabels struct {
+	// Lang is the language's code, for the lang attribute of the invoice's HTML
+	Lang                string
+	Void                string
+	Voided              string
+	InvoiceDate         string
+	DueDate             string
+	InvoiceNumber       string
+	BudgetCode          string
+	Project             string
+	Paid                string
+	BillTo              string
+	From                string
+	Date                string
+	Description         string
+	Quantity            string
+	Hours               string
+	Rate                string
+	Amount              string
+	FlatFee             string
+	OtherWork           string
+	Subtotal            string
+	Discount            string
+	Adjustment          string
+	Rounding            string
+	TotalDue            string
+	CreditApplied       string
+	BalanceDue          string
+	TimeApproved        string
+	ConvertedAt         string
+	On                  string
+	RateSource          string
+	PaymentTermsNotes   string
+	BankDetails         string
+	PaymentInstructions string
+	Bank                string
+	AccountName         string
+	RoutingNumber       string
+	AccountNumber       string
+	PayPal              string
+	ThankYou            string
+	// Months are the names of the months, January first, and MonthYearFormat is how a month
+	// and year are written with them, e.g. "%s %d" for "May 2024"
+	Months          [12]string
+	MonthYearFormat string
+}
+
+// MonthYear writes the month and year of t, as in "May 2024"
+func (l InvoiceLabels) MonthYear(t time.Time) string {
+	return fmt.Sprintf(l.MonthYearFormat, l.Months[t.Month()-1], t.Year())
+}
+
+// InvoiceLanguage is a language a client's invoices can be printed in
+type InvoiceLanguage struct {
+	Code   string
+	Name   string
+	labels InvoiceLabels
+}
+
+// InvoiceLanguages lists the languages invoices can be printed in, ordered by name
+var InvoiceLanguages = []InvoiceLanguage{
+	{"nl", "Dutch", dutchLabels},
+	{"en", "English", englishLabels},
+	{"fr", "French", frenchLabels},
+	{"de", "German", germanLabels},
+	{"it", "Italian", italianLabels},
+	{"pt", "Portuguese", portugueseLabels},
+	{"es", "Spanish", spanishLabels},
+}
+
+// LookupInvoiceLanguage finds an invoice language by its ISO 639-1 code
+func LookupInvoiceLanguage(code string) (InvoiceLanguage, bool) {
+	for _, language := range InvoiceLanguages {
+		if language.Code == code {
+			return language, true
+		}
+	}
+	return InvoiceLanguage{}, false
+}
+
+// InvoiceLabelsFor returns the invoice labels in the language with the given code, falling
+// back to English for clients without a language or with one no longer offered
+func InvoiceLabelsFor(code string) InvoiceLabels {
+	if language, ok := LookupInvoiceLanguage(code); ok {
+		return language.labels
+	}
+	return englishLabels
+}
+
+var englishLabels = InvoiceLabels{
+	Lang:                "en",
+	Void:                "VOID",
+	Voided:              "Voided",
+	InvoiceDate:         "Invoice Date",
+	DueDate:             "Due Date",
+	InvoiceNumber:       "Invoice #",
+	BudgetCode:          "Budget Code",
+	Project:             "Project",
+	Paid:                "Paid",
+	BillTo:              "Bill To",
+	From:                "From",
+	Date:                "Date",
+	Description:         "Description",
+	Quantity:            "Quantity",
+	Hours:               "Hours",
+	Rate:                "Rate",
+	Amount:              "Amount",
+	FlatFee:             "Flat Fee",
+	OtherWork:           "Other work",
+	Subtotal:            "Subtotal",
+	Discount:            "Discount",
+	Adjustment:          "Adjustment",
+	Rounding:            "Rounding",
+	TotalDue:            "Total Due",
+	CreditApplied:       "Credit Applied",
+	BalanceDue:          "Balance Due",
+	TimeApproved:        "Time approved by client on",
+	ConvertedAt:         "Converted at",
+	On:                  "on",
+	RateSource:          "Rate source",
+	PaymentTermsNotes:   "Payment Terms & Notes",
+	BankDetails:         "Bank Details",
+	PaymentInstructions: "Payment Instructions",
+	Bank:                "Bank",
+	AccountName:         "Account Name",
+	RoutingNumber:       "Routing Number",
+	AccountNumber:       "Account Number",
+	PayPal:              "PayPal",
+	ThankYou:            "Thank you for your business!",
+	Months: [12]string{"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December"},
+	MonthYearFormat: "%s %d",
+}
+
+var dutchLabels = InvoiceLabels{
+	Lang:                "nl",
+	Void:                "GEANNULEERD",
+	Voided:              "Geannuleerd op",
+	InvoiceDate:         "Factuurdatum",
+	DueDate:             "Vervaldatum",
+	InvoiceNumber:       "Factuurnr.",
+	BudgetCode:          "Kostenplaats",
+	Project:             "Project",
+	Paid:                "Betaald",
+	BillTo:              "Factuur aan",
+	From:                "Van",
+	Date:                "Datum",
+	Description:         "Omschrijving",
+	Quantity:            "Aantal",
+	Hours:               "Uren",
+	Rate:                "Tarief",
+	Amount:              "Bedrag",
+	FlatFee:             "Vast bedrag",
+	OtherWork:           "Overig werk",
+	Subtotal:            "Subtotaal",
+	Discount:            "Korting",
+	Adjustment:          "Correctie",
+	Rounding:            "Afronding",
+	TotalDue:            "Totaal te betalen",
+	CreditApplied:       "Verrekend tegoed",
+	BalanceDue:          "Openstaand saldo",
+	TimeApproved:        "Uren goedgekeurd door klant op",
+	ConvertedAt:         "Omgerekend tegen",
+	On:                  "op",
+	RateSource:          "Koersbron",
+	PaymentTermsNotes:   "Betalingsvoorwaarden en opmerkingen",
+	BankDetails:         "Bankgegevens",
+	PaymentInstructions: "Betalingsinstructies",
+	Bank:                "Bank",
+	AccountName:         "Rekeninghouder",
+	RoutingNumber:       "Routingnummer",
+	AccountNumber:       "Rekeningnummer",
+	PayPal:              "PayPal",
+	ThankYou:            "Bedankt voor uw opdracht!",
+	Months: [12]string{"januari", "februari", "maart", "april", "mei", "juni",
+		"juli", "augustus", "september", "oktober", "november", "december"},
+	MonthYearFormat: "%s %d",
+}
+
+var frenchLabels = InvoiceLabels{
+	Lang:                "fr",
+	Void:                "ANNULÉE",
+	Voided:              "Annulée le",
+	InvoiceDate:         "Date de facture",
+	DueDate:             "Date d'échéance",
+	InvoiceNumber:       "Facture n°",
+	BudgetCode:          "Centre de coûts",
+	Project:             "Projet",
+	Paid:                "Payée",
+	BillTo:              "Facturer à",
+	From:                "De",
+	Date:                "Date",
+	Description:         "Description",
+	Quantity:            "Quantité",
+	Hours:               "Heures",
+	Rate:                "Tarif",
+	Amount:              "Montant",
+	FlatFee:             "Forfait",
+	OtherWork:           "Autres travaux",
+	Subtotal:            "Sous-total",
+	Discount:            "Remise",
+	Adjustment:          "Ajustement",
+	Rounding:            "Arrondi",
+	TotalDue:            "Total à payer",
+	CreditApplied:       "Avoir déduit",
+	BalanceDue:          "Solde à payer",
+	TimeApproved:        "Heures approuvées par le client le",
+	ConvertedAt:         "Converti au taux de",
+	On:                  "le",
+	RateSource:          "Source du taux",
+	PaymentTermsNotes:   "Conditions de paiement et remarques",
+	BankDetails:         "Coordonnées bancaires",
+	PaymentInstructions: "Instructions de paiement",
+	Bank:                "Banque",
+	AccountName:         "Titulaire du compte",
+	RoutingNumber:       "Numéro d'acheminement",
+	AccountNumber:       "Numéro de compte",
+	PayPal:              "PayPal",
+	ThankYou:            "Merci de votre confiance !",
+	Months: [12]string{"janvier", "février", "mars", "avril", "mai", "juin",
+		"juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+	MonthYearFormat: "%s %d",
+}
+
+var germanLabels = InvoiceLabels{
+	Lang:                "de",
+	Void:                "STORNIERT",
+	Voided:              "Storniert am",
+	InvoiceDate:         "Rechnungsdatum",
+	DueDate:             "Fälligkeitsdatum",
+	InvoiceNumber:       "Rechnungsnr.",
+	BudgetCode:          "Kostenstelle",
+	Project:             "Projekt",
+	Paid:                "Bezahlt",
+	BillTo:              "Rechnungsempfänger",
+	From:                "Von",
+	Date:                "Datum",
+	Description:         "Beschreibung",
+	Quantity:            "Menge",
+	Hours:               "Stunden",
+	Rate:                "Satz",
+	Amount:              "Betrag",
+	FlatFee:             "Pauschale",
+	OtherWork:           "Sonstige Arbeiten",
+	Subtotal:            "Zwischensumme",
+	Discount:            "Rabatt",
+	Adjustment:          "Anpassung",
+	Rounding:            "Rundung",
+	TotalDue:            "Gesamtbetrag",
+	CreditApplied:       "Verrechnetes Guthaben",
+	BalanceDue:          "Restbetrag",
+	TimeApproved:        "Zeiten vom Kunden freigegeben am",
+	ConvertedAt:         "Umgerechnet zum Kurs",
+	On:                  "am",
+	RateSource:          "Kursquelle",
+	PaymentTermsNotes:   "Zahlungsbedingungen und Hinweise",
+	BankDetails:         "Bankverbindung",
+	PaymentInstructions: "Zahlungshinweise",
+	Bank:                "Bank",
+	AccountName:         "Kontoinhaber",
+	RoutingNumber:       "Bankleitzahl",
+	AccountNumber:       "Kontonummer",
+	PayPal:              "PayPal",
+	ThankYou:            "Vielen Dank für Ihren Auftrag!",
+	Months: [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni",
+		"Juli", "August", "September", "Oktober", "November", "Dezember"},
+	MonthYearFormat: "%s %d",
+}
+
+var italianLabels = InvoiceLabels{
+	Lang:                "it",
+	Void:                "ANNULLATA",
+	Voided:              "Annullata il",
+	InvoiceDate:         "Data fattura",
+	DueDate:             "Data di scadenza",
+	InvoiceNumber:       "Fattura n.",
+	BudgetCode:          "Centro di costo",
+	Project:             "Progetto",
+	Paid:                "Pagata",
+	BillTo:              "Intestatario",
+	From:                "Da",
+	Date:                "Data",
+	Description:         "Descrizione",
+	Quantity:            "Quantità",
+	Hours:               "Ore",
+	Rate:                "Tariffa",
+	Amount:              "Importo",
+	FlatFee:             "Forfait",
+	OtherWork:           "Altro lavoro",
+	Subtotal:            "Subtotale",
+	Discount:            "Sconto",
+	Adjustment:          "Rettifica",
+	Rounding:            "Arrotondamento",
+	TotalDue:            "Totale dovuto",
+	CreditApplied:       "Credito applicato",
+	BalanceDue:          "Saldo dovuto",
+	TimeApproved:        "Ore approvate dal cliente il",
+	ConvertedAt:         "Convertito al cambio di",
+	On:                  "il",
+	RateSource:          "Fonte del cambio",
+	PaymentTermsNotes:   "Termini di pagamento e note",
+	BankDetails:         "Coordinate bancarie",
+	PaymentInstructions: "Istruzioni di pagamento",
+	Bank:                "Banca",
+	AccountName:         "Intestatario del conto",
+	RoutingNumber:       "Codice di instradamento",
+	AccountNumber:       "Numero di conto",
+	PayPal:              "PayPal",
+	ThankYou:            "Grazie per la fiducia!",
+	Months: [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno",
+		"luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+	MonthYearFormat: "%s %d",
+}
+
+var portugueseLabels = InvoiceLabels{
+	Lang:                "pt",
+	Void:                "ANULADA",
+	Voided:              "Anulada em",
+	InvoiceDate:         "Data da fatura",
+	DueDate:             "Data de vencimento",
+	InvoiceNumber:       "Fatura n.º",
+	BudgetCode:          "Centro de custo",
+	Project:             "Projeto",
+	Paid:                "Paga",
+	BillTo:              "Faturar a",
+	From:                "De",
+	Date:                "Data",
+	Description:         "Descrição",
+	Quantity:            "Quantidade",
+	Hours:               "Horas",
+	Rate:                "Taxa",
+	Amount:              "Valor",
+	FlatFee:             "Valor fixo",
+	OtherWork:           "Outros trabalhos",
+	Subtotal:            "Subtotal",
+	Discount:            "Desconto",
+	Adjustment:          "Ajuste",
+	Rounding:            "Arredondamento",
+	TotalDue:            "Total a pagar",
+	CreditApplied:       "Crédito aplicado",
+	BalanceDue:          "Saldo a pagar",
+	TimeApproved:        "Horas aprovadas pelo cliente em",
+	ConvertedAt:         "Convertido à taxa de",
+	On:                  "em",
+	RateSource:          "Fonte da taxa",
+	PaymentTermsNotes:   "Condições de pagamento e notas",
+	BankDetails:         "Dados bancários",
+	PaymentInstructions: "Instruções de pagamento",
+	Bank:                "Banco",
+	AccountName:         "Titular da conta",
+	RoutingNumber:       "Número de roteamento",
+	AccountNumber:       "Número da conta",
+	PayPal:              "PayPal",
+	ThankYou:            "Obrigado pela preferência!",
+	Months: [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho",
+		"julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+	MonthYearFormat: "%s de %d",
+}
+
+var spanishLabels = InvoiceLabels{
+	Lang:                "es",
+	Void:                "ANULADA",
+	Voided:              "Anulada el",
+	InvoiceDate:         "Fecha de factura",
+	DueDate:             "Fecha de vencimiento",
+	InvoiceNumber:       "Factura n.º",
+	BudgetCode:          "Centro de coste",
+	Project:             "Proyecto",
+	Paid:                "Pagada",
+	BillTo:              "Facturar a",
+	From:                "De",
+	Date:                "Fecha",
+	Description:         "Descripción",
+	Quantity:            "Cantidad",
+	Hours:               "Horas",
+	Rate:                "Tarifa",
+	Amount:              "Importe",
+	FlatFee:             "Tarifa fija",
+	OtherWork:           "Otros trabajos",
+	Subtotal:            "Subtotal",
+	Discount:            "Descuento",
+	Adjustment:          "Ajuste",
+	Rounding:            "Redondeo",
+	TotalDue:            "Total a pagar",
+	CreditApplied:       "Crédito aplicado",
+	BalanceDue:          "Saldo pendiente",
+	TimeApproved:        "Horas aprobadas por el cliente el",
+	ConvertedAt:         "Convertido al tipo de",
+	On:                  "el",
+	RateSource:          "Fuente del tipo de cambio",
+	PaymentTermsNotes:   "Condiciones de pago y notas",
+	BankDetails:         "Datos bancarios",
+	PaymentInstructions: "Instrucciones de pago",
+	Bank:                "Banco",
+	AccountName:         "Titular de la cuenta",
+	RoutingNumber:       "Número de ruta",
+	AccountNumber:       "Número de cuenta",
+	PayPal:              "PayPal",
+	ThankYou:            "¡Gracias por su confianza!",
+	Months: [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio",
+		"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+	MonthYearFormat: "%s de %d",
+}
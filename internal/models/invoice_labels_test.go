@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceLabelsFor(t *testing.T) {
+	assert.Equal(t, "Rechnungsdatum", InvoiceLabelsFor("de").InvoiceDate)
+	assert.Equal(t, "Invoice Date", InvoiceLabelsFor("").InvoiceDate, "clients without a language get English")
+	assert.Equal(t, "Invoice Date", InvoiceLabelsFor("xx").InvoiceDate)
+
+	for _, language := range InvoiceLanguages {
+		labels := InvoiceLabelsFor(language.Code)
+		assert.Equal(t, language.Code, labels.Lang)
+		assert.NotEmpty(t, labels.ThankYou, "%s has no thank-you message", language.Name)
+		assert.NotEmpty(t, labels.BudgetCode, "%s has no budget code label", language.Name)
+		for i, month := range labels.Months {
+			assert.NotEmpty(t, month, "%s has no name for month %d", language.Name, i+1)
+		}
+	}
+}
+
+func TestInvoiceLabels_MonthYear(t *testing.T) {
+	may := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "May 2024", InvoiceLabelsFor("en").MonthYear(may))
+	assert.Equal(t, "Mai 2024", InvoiceLabelsFor("de").MonthYear(may))
+	assert.Equal(t, "mayo de 2024", InvoiceLabelsFor("es").MonthYear(may))
+}
+
+func TestRenderInvoiceHTML_Language(t *testing.T) {
+	data := SampleInvoiceData()
+	german := "de"
+	data.Client.InvoiceLanguage = &german
+
+	t.Run("labels in the client's language", func(t *testing.T) {
+		html, err := RenderInvoiceHTML(NewInvoiceTemplateData(data, nil))
+		require.NoError(t, err)
+
+		assert.Contains(t, string(html), `<html lang="de">`)
+		assert.Contains(t, string(html), "Rechnungsdatum:")
+		assert.Contains(t, string(html), "Gesamtbetrag:")
+		assert.Contains(t, string(html), "Vielen Dank für Ihren Auftrag!")
+		assert.NotContains(t, string(html), "Invoice Date:")
+	})
+
+	t.Run("a thank-you message the user wrote is kept", func(t *testing.T) {
+		settings := map[string]AppSettingValue{
+			"invoice_thank_you_message": {Value: "Danke!", DataType: "string"},
+		}
+		html, err := RenderInvoiceHTML(NewInvoiceTemplateData(data, settings))
+		require.NoError(t, err)
+		assert.Contains(t, string(html), "Danke!")
+	})
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// InvoiceSequence is the numbering sequence a project's invoices are numbered from: the
+// client's own sequence when it has one, otherwise that of the business profile the
+// invoices are issued under
+type InvoiceSequence struct {
+	Name      string
+	Prefix    string
+	Next      int
+	clientID  int
+	profileID int
+}
+
+// ResolveInvoiceSequence returns the sequence that numbers a client's invoices, or nil
+// when neither the client nor the business profile has one
+func ResolveInvoiceSequence(client Client, profile *BusinessProfile) *InvoiceSequence {
+	if client.NextInvoiceNumber != nil {
+		return &InvoiceSequence{
+			Name:     client.Name,
+			Prefix:   derefString(client.InvoicePrefix),
+			Next:     *client.NextInvoiceNumber,
+			clientID: client.ID,
+		}
+	}
+	if profile != nil {
+		return &InvoiceSequence{
+			Name:      profile.Name,
+			Prefix:    profile.InvoicePrefix,
+			Next:      profile.NextInvoiceNumber,
+			profileID: profile.ID,
+		}
+	}
+	return nil
+}
+
+// Format formats a number in the sequence with its prefix
+func (s InvoiceSequence) Format(number int) string {
+	return fmt.Sprintf("%s%04d", s.Prefix, number)
+}
+
+// Upcoming returns the number the sequence will give the next invoice, without advancing it
+func (s InvoiceSequence) Upcoming() string {
+	return s.Format(s.Next)
+}
+
+// Clashes reports whether a manually entered invoice number would be issued again by the
+// sequence later, i.e. it has the sequence's prefix and a number at or beyond the next one.
+// Numbers the sequence has already passed are safe to reuse.
+func (s InvoiceSequence) Clashes(invoiceNumber string) bool {
+	digits, ok := strings.CutPrefix(invoiceNumber, s.Prefix)
+	if !ok {
+		return false
+	}
+	number, err := strconv.Atoi(digits)
+	return err == nil && number >= s.Next
+}
+
+// Claim takes the next number from the sequence inside tx and advances it. It returns ""
+// if the sequence has gone since it was resolved, e.g. the client's was switched off or the
+// profile deleted, leaving the invoice to be shown by its ID.
+func (s InvoiceSequence) Claim(tx TxModels) (string, error) {
+	var number string
+	var err error
+	if s.clientID != 0 {
+		number, err = tx.Clients.NextInvoiceNumber(s.clientID)
+	} else {
+		number, err = tx.BusinessProfiles.NextInvoiceNumber(s.profileID)
+	}
+	if errors.Is(err, ErrNoRecord) {
+		return "", nil
+	}
+	return number, err
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveInvoiceSequence(t *testing.T) {
+	profile := &BusinessProfile{ID: 3, Name: "Translation Studio", InvoicePrefix: "TS-", NextInvoiceNumber: 5}
+	prefix, next := "ACME-", 12
+	own := Client{ID: 7, Name: "Acme", InvoicePrefix: &prefix, NextInvoiceNumber: &next}
+
+	sequence := ResolveInvoiceSequence(own, profile)
+	require.NotNil(t, sequence)
+	assert.Equal(t, "Acme", sequence.Name)
+	assert.Equal(t, "ACME-0012", sequence.Upcoming(), "the client's own sequence comes first")
+
+	sequence = ResolveInvoiceSequence(Client{ID: 8, Name: "Globex"}, profile)
+	require.NotNil(t, sequence)
+	assert.Equal(t, "Translation Studio", sequence.Name)
+	assert.Equal(t, "TS-0005", sequence.Upcoming())
+
+	assert.Nil(t, ResolveInvoiceSequence(Client{ID: 8}, nil))
+
+	// A sequence without a prefix is just numbers
+	next = 3
+	sequence = ResolveInvoiceSequence(Client{NextInvoiceNumber: &next}, nil)
+	require.NotNil(t, sequence)
+	assert.Equal(t, "0003", sequence.Upcoming())
+}
+
+func TestInvoiceSequence_Clashes(t *testing.T) {
+	sequence := InvoiceSequence{Prefix: "ACME-", Next: 12}
+
+	assert.True(t, sequence.Clashes("ACME-0012"))
+	assert.True(t, sequence.Clashes("ACME-0100"))
+	assert.False(t, sequence.Clashes("ACME-0011"))
+	assert.False(t, sequence.Clashes("ACME-OLD"))
+	assert.False(t, sequence.Clashes("TS-0012"))
+}
+
+func TestInvoiceSequence_Claim(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	clients := NewClientModel(testDB.DB)
+	profiles := NewBusinessProfileModel(testDB.DB)
+	transactions := NewTxManager(testDB.DB)
+
+	profileID := testDB.InsertTestBusinessProfile(t, "Translation Studio", "TS-")
+	profile, err := profiles.Get(profileID)
+	require.NoError(t, err)
+	clientID := testDB.InsertTestClient(t, "Acme")
+	client, err := clients.Get(clientID)
+	require.NoError(t, err)
+
+	claim := func(t *testing.T, sequence *InvoiceSequence) string {
+		var number string
+		err := transactions.WithinTx(context.Background(), func(tx TxModels) error {
+			var err error
+			number, err = sequence.Claim(tx)
+			return err
+		})
+		require.NoError(t, err)
+		return number
+	}
+
+	t.Run("falls back to the business profile", func(t *testing.T) {
+		assert.Equal(t, "TS-0001", claim(t, ResolveInvoiceSequence(client, &profile)))
+	})
+
+	t.Run("uses the client's own sequence", func(t *testing.T) {
+		prefix, next := "ACME-", 12
+		client.InvoicePrefix = &prefix
+		client.NextInvoiceNumber = &next
+		require.NoError(t, clients.Update(client))
+
+		sequence := ResolveInvoiceSequence(client, &profile)
+		assert.Equal(t, "ACME-0012", claim(t, sequence))
+		assert.Equal(t, "ACME-0013", claim(t, sequence), "each claim advances the stored sequence")
+
+		profile, err := profiles.Get(profileID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, profile.NextInvoiceNumber, "the profile's sequence is left alone")
+	})
+
+	t.Run("a sequence that has gone gives no number", func(t *testing.T) {
+		sequence := ResolveInvoiceSequence(client, &profile)
+		client.InvoicePrefix = nil
+		client.NextInvoiceNumber = nil
+		require.NoError(t, clients.Update(client))
+
+		assert.Equal(t, "", claim(t, sequence))
+	})
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// InvoiceSnapshot is who an invoice was billed to and from, and the logo printed on it, as they
+// stood when the invoice's PDF was first generated. Later PDFs of the invoice print these
+// rather than the current details, so an invoice reprinted after the client moves still
+// shows the address it was sent to.
+type InvoiceSnapshot struct {
+	InvoiceID              int
+	BillTo                 []string
+	FreelancerName         string
+	FreelancerAddress      string
+	FreelancerCityStateZip string
+	FreelancerPhone        string
+	FreelancerEmail        string
+	LogoDataURL            string
+	Created                time.Time
+}
+
+// NewInvoiceSnapshot captures the billing details an invoice is about to be printed with
+func NewInvoiceSnapshot(data InvoiceTemplateData) InvoiceSnapshot {
+	return InvoiceSnapshot{
+		InvoiceID:              data.Invoice.ID,
+		BillTo:                 data.BillTo,
+		FreelancerName:         data.Settings.FreelancerName,
+		FreelancerAddress:      data.Settings.FreelancerAddress,
+		FreelancerCityStateZip: data.Settings.FreelancerCityStateZip,
+		FreelancerPhone:        data.Settings.FreelancerPhone,
+		FreelancerEmail:        data.Settings.FreelancerEmail,
+		LogoDataURL:            data.Settings.CompanyLogoDataURL,
+	}
+}
+
+// applyTo prints the snapshot's details on an invoice in place of the current ones
+func (s InvoiceSnapshot) applyTo(data *InvoiceTemplateData) {
+	data.BillTo = s.BillTo
+	data.Settings.FreelancerName = s.FreelancerName
+	data.Settings.FreelancerAddress = s.FreelancerAddress
+	data.Settings.FreelancerCityStateZip = s.FreelancerCityStateZip
+	data.Settings.FreelancerPhone = s.FreelancerPhone
+	data.Settings.FreelancerEmail = s.FreelancerEmail
+	data.Settings.CompanyLogoDataURL = s.LogoDataURL
+}
+
+// snapshot returns the billing details captured when an invoice was first printed, or nil
+// when it hasn't been yet
+func (i *InvoiceModel) snapshot(id int) (*InvoiceSnapshot, error) {
+	row, err := i.queries.GetInvoiceSnapshot(context.Background(), int64(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &InvoiceSnapshot{
+		InvoiceID:              int(row.InvoiceID),
+		BillTo:                 strings.Split(row.BillTo, "\n"),
+		FreelancerName:         row.FreelancerName,
+		FreelancerAddress:      row.FreelancerAddress,
+		FreelancerCityStateZip: row.FreelancerCityStateZip,
+		FreelancerPhone:        row.FreelancerPhone,
+		FreelancerEmail:        row.FreelancerEmail,
+		LogoDataURL:            row.LogoDataUrl,
+		Created:                row.CreatedAt,
+	}, nil
+}
+
+// saveSnapshot stores an invoice's billing details unless some were stored already, so the
+// details of the first printing are the ones kept
+func (i *InvoiceModel) saveSnapshot(snapshot InvoiceSnapshot) error {
+	return i.queries.InsertInvoiceSnapshot(context.Background(), db.InsertInvoiceSnapshotParams{
+		InvoiceID:              int64(snapshot.InvoiceID),
+		BillTo:                 strings.Join(snapshot.BillTo, "\n"),
+		FreelancerName:         snapshot.FreelancerName,
+		FreelancerAddress:      snapshot.FreelancerAddress,
+		FreelancerCityStateZip: snapshot.FreelancerCityStateZip,
+		FreelancerPhone:        snapshot.FreelancerPhone,
+		FreelancerEmail:        snapshot.FreelancerEmail,
+		LogoDataUrl:            snapshot.LogoDataURL,
+	})
+}
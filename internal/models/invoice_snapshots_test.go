@@ -0,0 +1,86 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceModel_Snapshot(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceModel(testDB.DB)
+	clients := NewClientModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Anna Jansen")
+	projectID := testDB.InsertTestProject(t, "Thesis", clientID)
+	id, err := model.Insert(projectID, time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC), nil, "Net 30", 150.00, true)
+	require.NoError(t, err)
+	otherID, err := model.Insert(projectID, time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), nil, "Net 30", 80.00, true)
+	require.NoError(t, err)
+
+	moveClient := func(t *testing.T, billTo string) {
+		client, err := clients.Get(clientID)
+		require.NoError(t, err)
+		client.BillTo = &billTo
+		require.NoError(t, clients.Update(client))
+	}
+	settings := func(name string) map[string]AppSettingValue {
+		return map[string]AppSettingValue{"freelancer_name": {Value: name, DataType: "string"}}
+	}
+
+	t.Run("nothing is kept before the invoice is printed", func(t *testing.T) {
+		data, err := model.GetComprehensiveForPDF(id)
+		require.NoError(t, err)
+		assert.Nil(t, data.Snapshot)
+	})
+
+	t.Run("the first printing is kept", func(t *testing.T) {
+		moveClient(t, "Anna Jansen\nKeizersgracht 1\nAmsterdam")
+		printed, err := model.printData(id, settings("Old Name Editing"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Anna Jansen", "Keizersgracht 1", "Amsterdam"}, printed.BillTo)
+
+		data, err := model.GetComprehensiveForPDF(id)
+		require.NoError(t, err)
+		require.NotNil(t, data.Snapshot)
+		assert.Equal(t, []string{"Anna Jansen", "Keizersgracht 1", "Amsterdam"}, data.Snapshot.BillTo)
+		assert.Equal(t, "Old Name Editing", data.Snapshot.FreelancerName)
+		assert.False(t, data.Snapshot.Created.IsZero())
+	})
+
+	t.Run("reprinting after the client moves shows the old address", func(t *testing.T) {
+		moveClient(t, "Anna Jansen\nHerengracht 2\nAmsterdam")
+
+		printed, err := model.printData(id, settings("New Name Editing"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Anna Jansen", "Keizersgracht 1", "Amsterdam"}, printed.BillTo)
+		assert.Equal(t, "Old Name Editing", printed.Settings.FreelancerName)
+
+		html, err := RenderInvoiceHTML(printed)
+		require.NoError(t, err)
+		assert.Contains(t, string(html), "<div>Keizersgracht 1</div>")
+		assert.NotContains(t, string(html), "Herengracht")
+	})
+
+	t.Run("viewing the HTML invoice shows the kept printing", func(t *testing.T) {
+		html, err := model.GenerateHTML(id, settings("New Name Editing"))
+		require.NoError(t, err)
+		assert.Contains(t, string(html), "<div>Keizersgracht 1</div>")
+		assert.NotContains(t, string(html), "New Name Editing")
+
+		_, err = model.GenerateHTML(999999, nil)
+		assert.ErrorIs(t, err, ErrNoRecord)
+	})
+
+	t.Run("an invoice printed for the first time shows the current address", func(t *testing.T) {
+		printed, err := model.printData(otherID, settings("New Name Editing"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Anna Jansen", "Herengracht 2", "Amsterdam"}, printed.BillTo)
+		assert.Equal(t, "New Name Editing", printed.Settings.FreelancerName)
+	})
+}
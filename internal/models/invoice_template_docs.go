@@ -0,0 +1,253 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TemplateField is a value the invoice template can use, such as .Invoice.AmountDue. Fields
+// under a list, written like .Timesheets[].WorkDate, are available inside {{range .Timesheets}}.
+type TemplateField struct {
+	Path   string
+	Type   string
+	Sample string
+	Method bool
+}
+
+// TemplateFunc is a function the invoice template can call
+type TemplateFunc struct {
+	Name        string
+	Signature   string
+	Description string
+}
+
+// invoiceTemplateFuncDescriptions explains what each of invoiceTemplateFuncs does
+var invoiceTemplateFuncDescriptions = map[string]string{
+	"split":      `Splits text on a separator, e.g. {{range split .Settings.FreelancerAddress ","}}`,
+	"mul":        `Multiplies two numbers, e.g. {{printf "%.2f" (mul .HoursWorked .HourlyRate)}}`,
+	"safeURL":    `Marks a URL as safe to use in src or href, as needed for the logo's data URL`,
+	"isPositive": `Reports whether a number is above zero`,
+	"isNonZero":  `Reports whether a number is anything other than zero`,
+}
+
+// InvoiceTemplateFuncs lists the functions available to the invoice template, ordered by name.
+// Go's built-in template functions such as printf, len and eq are available as well.
+func InvoiceTemplateFuncs() []TemplateFunc {
+	funcs := make([]TemplateFunc, 0, len(invoiceTemplateFuncs))
+	for name, fn := range invoiceTemplateFuncs {
+		funcs = append(funcs, TemplateFunc{
+			Name:        name,
+			Signature:   typeName(reflect.TypeOf(fn)),
+			Description: invoiceTemplateFuncDescriptions[name],
+		})
+	}
+	sort.Slice(funcs, func(i, j int) bool { return funcs[i].Name < funcs[j].Name })
+	return funcs
+}
+
+// SampleInvoiceData is a made-up invoice with every section of the invoice template filled in
+func SampleInvoiceData() ComprehensiveInvoiceData {
+	invoiceDate := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	dueDate := invoiceDate.AddDate(0, 0, 30)
+	discount := 10.0
+	street, city, postcode, country := "Keizersgracht 1", "Amsterdam", "1015 CJ", "NL"
+
+	financials := &InvoiceFinancials{
+		HourlyRate:             60,
+		DiscountPercent:        &discount,
+		DiscountReason:         "Returning client",
+		CurrencyDisplay:        "EUR",
+		CurrencyConversionRate: 0.92,
+		RateSource:             ConversionRateSourceProject,
+		ConvertedAt:            &invoiceDate,
+	}
+	project := Project{
+		ID:                     1,
+		Name:                   "Dissertation edit",
+		ClientID:               1,
+		Status:                 "In Progress",
+		Notes:                  "Chapters 1 to 3",
+		CurrencyConversionRate: 1,
+	}
+	financials.ApplyTo(&project)
+
+	timesheets := []Timesheet{
+		{ID: 1, ProjectID: 1, WorkDate: time.Date(2024, 4, 8, 0, 0, 0, 0, time.UTC), HoursWorked: 2.5, HourlyRate: 60, Description: "Chapter 1 copyedit", WorkType: "Editing"},
+		{ID: 2, ProjectID: 1, WorkDate: time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC), HoursWorked: 3, HourlyRate: 60, Description: "Chapter 2 copyedit", WorkType: "Editing"},
+		{ID: 3, ProjectID: 1, WorkDate: time.Date(2024, 4, 22, 0, 0, 0, 0, time.UTC), HoursWorked: 1.5, HourlyRate: 60, Description: "Reference check:\n- **APA** style\n- DOIs added", WorkType: "Formatting"},
+	}
+	var totalHours float64
+	for _, timesheet := range timesheets {
+		totalHours += timesheet.HoursWorked
+	}
+	amountDue := totalHours * financials.HourlyRate
+	discountAmount := amountDue * discount / 100
+	approvedAt := time.Date(2024, 4, 26, 9, 30, 0, 0, time.UTC)
+
+	return ComprehensiveInvoiceData{
+		Invoice: Invoice{
+			ID:             1,
+			ProjectID:      1,
+			InvoiceDate:    invoiceDate,
+			PaymentTerms:   "Payment is due within 30 days of receipt of this invoice.",
+			AmountDue:      amountDue,
+			DisplayDetails: true,
+			InvoiceNumber:  "INV-2024-0001",
+			DueDate:        &dueDate,
+			Financials:     financials,
+		},
+		Project: project,
+		Client: Client{
+			ID:       1,
+			Name:     "Dr. Jane Doe",
+			Address1: &street,
+			City:     &city,
+			ZipCode:  &postcode,
+			Country:  &country,
+		},
+		Timesheets:     timesheets,
+		TotalHours:     totalHours,
+		Subtotal:       amountDue - discountAmount,
+		DiscountAmount: discountAmount,
+		FinalTotal:     amountDue - discountAmount,
+		ClientApproval: &ClientApproval{
+			ID:         1,
+			ProjectID:  1,
+			Start:      time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			End:        time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+			ApprovedAt: &approvedAt,
+			ApprovedBy: "Jane Doe",
+		},
+	}
+}
+
+// DescribeTemplateData lists the fields and methods of the data passed to a template along
+// with their values, for documenting what a template can use
+func DescribeTemplateData(data any) []TemplateField {
+	var fields []TemplateField
+	describeValue(reflect.ValueOf(data), "", 0, &fields)
+	return fields
+}
+
+// maxTemplateDepth stops describing data nested further than any template reasonably needs
+const maxTemplateDepth = 4
+
+// describeValue appends the fields and methods of v, found at path, to fields
+func describeValue(v reflect.Value, path string, depth int, fields *[]TemplateField) {
+	if depth > maxTemplateDepth {
+		return
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v = reflect.Zero(v.Type().Elem())
+		} else {
+			v = v.Elem()
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || field.Anonymous {
+				continue
+			}
+			fieldPath := path + "." + field.Name
+			*fields = append(*fields, TemplateField{
+				Path:   fieldPath,
+				Type:   typeName(field.Type),
+				Sample: sampleText(v.Field(i)),
+			})
+			describeValue(v.Field(i), fieldPath, depth+1, fields)
+		}
+	case reflect.Slice:
+		elem := reflect.Zero(v.Type().Elem())
+		if v.Len() > 0 {
+			elem = v.Index(0)
+		}
+		describeValue(elem, path+"[]", depth+1, fields)
+		return
+	}
+
+	describeMethods(v, path, fields)
+}
+
+// describeMethods appends the exported methods of v, found at path, to fields. Methods that
+// take no arguments are called for a sample value.
+func describeMethods(v reflect.Value, path string, fields *[]TemplateField) {
+	if path == "" || v.Kind() == reflect.Slice || v.Kind() == reflect.Map {
+		return
+	}
+	for i := 0; i < v.NumMethod(); i++ {
+		method := v.Type().Method(i)
+		methodType := v.Method(i).Type()
+		field := TemplateField{
+			Path:   path + "." + method.Name,
+			Type:   typeName(methodType),
+			Method: true,
+		}
+		if methodType.NumIn() == 0 && methodType.NumOut() > 0 {
+			field.Sample = callForSample(v.Method(i))
+		}
+		*fields = append(*fields, field)
+	}
+}
+
+// callForSample calls a method without arguments and formats its result, giving up quietly
+// on methods that can't cope with the sample data
+func callForSample(method reflect.Value) (sample string) {
+	defer func() {
+		if recover() != nil {
+			sample = ""
+		}
+	}()
+	return sampleText(method.Call(nil)[0])
+}
+
+// maxSampleLength keeps long values such as the logo's data URL from swamping the listing
+const maxSampleLength = 60
+
+// sampleText formats a value the way it would print in a template, leaving out structs and
+// lists whose fields are listed on their own
+func sampleText(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "nil"
+		}
+		v = v.Elem()
+	}
+
+	var text string
+	switch value := v.Interface().(type) {
+	case time.Time:
+		text = value.Format(time.DateOnly)
+	default:
+		switch v.Kind() {
+		case reflect.Struct, reflect.Slice, reflect.Map:
+			return ""
+		case reflect.Float32, reflect.Float64:
+			text = fmt.Sprintf("%.2f", v.Float())
+		default:
+			text = fmt.Sprint(value)
+		}
+	}
+
+	if len(text) > maxSampleLength {
+		text = text[:maxSampleLength] + "…"
+	}
+	return text
+}
+
+// typeName returns a Go type's name without this package's prefix
+func typeName(t reflect.Type) string {
+	return strings.ReplaceAll(t.String(), "models.", "")
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceTemplateFuncs(t *testing.T) {
+	funcs := InvoiceTemplateFuncs()
+	require.Len(t, funcs, len(invoiceTemplateFuncs))
+	for _, fn := range funcs {
+		assert.NotEmpty(t, fn.Description, "%s is not described", fn.Name)
+	}
+	assert.Equal(t, "isNonZero", funcs[0].Name)
+	assert.Equal(t, "func(float64) bool", funcs[0].Signature)
+}
+
+func TestDescribeTemplateData(t *testing.T) {
+	fields := DescribeTemplateData(NewInvoiceTemplateData(SampleInvoiceData(), nil))
+
+	byPath := make(map[string]TemplateField)
+	for _, field := range fields {
+		byPath[field.Path] = field
+	}
+
+	assert.Equal(t, TemplateField{Path: ".Invoice.InvoiceNumber", Type: "string", Sample: "INV-2024-0001"}, byPath[".Invoice.InvoiceNumber"])
+	assert.Equal(t, "2024-04-08", byPath[".Timesheets[].WorkDate"].Sample)
+	assert.Equal(t, "0.92", byPath[".Conversion.RateText"].Sample)
+	assert.True(t, byPath[".Conversion.RateText"].Method)
+	assert.Equal(t, "func(time.Time) string", byPath[".Settings.DateFormat.Format"].Type)
+	assert.Contains(t, byPath, ".Client.AddressLines")
+}
+
+func TestRenderInvoiceHTML_SampleInvoice(t *testing.T) {
+	html, err := RenderInvoiceHTML(NewInvoiceTemplateData(SampleInvoiceData(), nil))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(html), "INV-2024-0001")
+	assert.Contains(t, string(html), "Chapter 2 copyedit")
+	assert.Contains(t, string(html), `<div class="description-line bullet">&bull; <strong>APA</strong> style</div>`, "descriptions are formatted a line at a time")
+	assert.Contains(t, string(html), "Converted at 1 USD = 0.92 EUR")
+}
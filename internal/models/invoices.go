@@ -7,16 +7,20 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"html"
 	"html/template"
+	"io/fs"
+	"math"
 	"os"
+	"path"
 	"path/filepath"
-	"runtime"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/chromedp/cdproto/page"
-	"github.com/chromedp/chromedp"
 	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+	"github.com/paulboeck/FreelanceTrackerGo/ui"
 )
 
 // Invoice represents an invoice in the system
@@ -28,11 +32,285 @@ type Invoice struct {
 	PaymentTerms   string
 	AmountDue      float64
 	DisplayDetails bool
+	InvoiceNumber  string
+	VoidedAt       *time.Time
+	VoidReason     string
+	AmountPaid     *float64
+	CreditApplied  float64
+	DueDate        *time.Time
+	CCEmail        string
+	BudgetCode     string
+	TimesheetsFrom *time.Time
+	TimesheetsTo   *time.Time
+	Financials     *InvoiceFinancials
+	Rounding       RoundingPolicy
+	PDFSignedAt    *time.Time
+	PDFSignedBy    string
+	StaleSince     *time.Time
 	Updated        time.Time
 	Created        time.Time
 	DeletedAt      *time.Time
 }
 
+// InvoiceFinancials are the project's rate, discount, adjustment and currency as they stood
+// when an invoice was created. Invoices keep their own copy so that later edits to the
+// project don't change what an invoice already issued says. The discount can be changed on
+// the invoice itself, to a different percentage or to a fixed DiscountAmount.
+type InvoiceFinancials struct {
+	HourlyRate             float64
+	DiscountPercent        *float64
+	DiscountAmount         *float64
+	DiscountReason         string
+	AdjustmentAmount       *float64
+	AdjustmentReason       string
+	CurrencyDisplay        string
+	CurrencyConversionRate float64
+	RateSource             string
+	ConvertedAt            *time.Time
+}
+
+// ConversionRateSourceProject marks a conversion rate taken from the project's settings
+const ConversionRateSourceProject = "project"
+
+// FinancialsFromProject copies the project's current financial terms for a new invoice
+func FinancialsFromProject(project Project) InvoiceFinancials {
+	financials := InvoiceFinancials{
+		HourlyRate:             project.HourlyRate,
+		DiscountPercent:        project.DiscountPercent,
+		DiscountReason:         project.DiscountReason,
+		AdjustmentAmount:       project.AdjustmentAmount,
+		AdjustmentReason:       project.AdjustmentReason,
+		CurrencyDisplay:        project.CurrencyDisplay,
+		CurrencyConversionRate: project.CurrencyConversionRate,
+	}
+	if financials.IsConverted() {
+		financials.RateSource = ConversionRateSourceProject
+	}
+	return financials
+}
+
+// IsConverted reports whether the invoice's amounts are converted into another currency
+func (f InvoiceFinancials) IsConverted() bool {
+	return f.CurrencyConversionRate > 0 && f.CurrencyConversionRate != 1
+}
+
+// UseExchangeRate converts the invoice at a rate from the exchange rate history in place of
+// the project's, recording where the rate came from and the day it applied to
+func (f *InvoiceFinancials) UseExchangeRate(rate ExchangeRate) {
+	f.CurrencyConversionRate = rate.Rate
+	f.RateSource = rate.Source
+	f.ConvertedAt = &rate.Date
+}
+
+// CurrencyConversion describes how an invoice's total was converted into the client's currency
+type CurrencyConversion struct {
+	From        string
+	To          string
+	Rate        float64
+	Source      string
+	ConvertedAt *time.Time
+	Total       float64
+}
+
+// NewCurrencyConversion returns the conversion applied to an invoice's total, or nil when the
+// invoice isn't converted into another currency
+func NewCurrencyConversion(financials *InvoiceFinancials, domesticCurrency string, total float64) *CurrencyConversion {
+	if financials == nil || !financials.IsConverted() || strings.TrimSpace(financials.CurrencyDisplay) == "" {
+		return nil
+	}
+	return &CurrencyConversion{
+		From:        domesticCurrency,
+		To:          strings.TrimSpace(financials.CurrencyDisplay),
+		Rate:        financials.CurrencyConversionRate,
+		Source:      financials.RateSource,
+		ConvertedAt: financials.ConvertedAt,
+		Total:       total * financials.CurrencyConversionRate,
+	}
+}
+
+// RateText returns the conversion rate without trailing zeros
+func (c CurrencyConversion) RateText() string {
+	return strconv.FormatFloat(c.Rate, 'f', -1, 64)
+}
+
+// SourceLabel describes where the conversion rate came from
+func (c CurrencyConversion) SourceLabel() string {
+	switch c.Source {
+	case ConversionRateSourceProject:
+		return "project conversion rate"
+	case "":
+		return ""
+	default:
+		return c.Source
+	}
+}
+
+// SetDiscount gives the invoice its own discount in place of the project's: a percentage of
+// the amount due or a fixed amount, or neither for no discount
+func (f *InvoiceFinancials) SetDiscount(percent, amount *float64, reason string) {
+	f.DiscountPercent = percent
+	f.DiscountAmount = amount
+	f.DiscountReason = reason
+}
+
+// Discount returns the discount taken off an invoice's amount due: the fixed amount if one
+// is given, otherwise the percentage of the amount due
+func (f InvoiceFinancials) Discount(amountDue float64) float64 {
+	if f.DiscountAmount != nil {
+		return *f.DiscountAmount
+	}
+	if f.DiscountPercent != nil && *f.DiscountPercent > 0 {
+		return amountDue * (*f.DiscountPercent / 100.0)
+	}
+	return 0
+}
+
+// InvoiceBill is how an invoice's total is made up from its amount due
+type InvoiceBill struct {
+	Discount           float64
+	Adjustment         float64
+	RoundingAdjustment float64
+	Total              float64
+}
+
+// Bill works out what an invoice for amountDue bills under these terms: its discount taken
+// off and its adjustment added on, with the total rounded under policy
+func (f InvoiceFinancials) Bill(amountDue float64, policy RoundingPolicy) InvoiceBill {
+	bill := InvoiceBill{Discount: f.Discount(amountDue)}
+	if f.AdjustmentAmount != nil {
+		bill.Adjustment = *f.AdjustmentAmount
+	}
+	bill.Total, bill.RoundingAdjustment = policy.Total(amountDue, bill.Discount, bill.Adjustment)
+	return bill
+}
+
+// ApplyTo replaces the project's financial terms with the ones captured on the invoice
+func (f InvoiceFinancials) ApplyTo(project *Project) {
+	project.HourlyRate = f.HourlyRate
+	project.DiscountPercent = f.DiscountPercent
+	project.DiscountReason = f.DiscountReason
+	project.AdjustmentAmount = f.AdjustmentAmount
+	project.AdjustmentReason = f.AdjustmentReason
+	project.CurrencyDisplay = f.CurrencyDisplay
+	project.CurrencyConversionRate = f.CurrencyConversionRate
+}
+
+// convertInvoiceFinancials reads the financials captured on an invoice row, returning nil
+// for invoices created before they were captured
+func convertInvoiceFinancials(capturedAt sql.NullTime, hourlyRate, discountPercent, discountAmount sql.NullFloat64, discountReason sql.NullString,
+	adjustmentAmount sql.NullFloat64, adjustmentReason, currencyDisplay sql.NullString, currencyConversionRate sql.NullFloat64,
+	rateSource sql.NullString, convertedAt sql.NullTime) *InvoiceFinancials {
+	if !capturedAt.Valid {
+		return nil
+	}
+	return &InvoiceFinancials{
+		HourlyRate:             hourlyRate.Float64,
+		DiscountPercent:        convertNullFloat64(discountPercent),
+		DiscountAmount:         convertNullFloat64(discountAmount),
+		DiscountReason:         discountReason.String,
+		AdjustmentAmount:       convertNullFloat64(adjustmentAmount),
+		AdjustmentReason:       adjustmentReason.String,
+		CurrencyDisplay:        currencyDisplay.String,
+		CurrencyConversionRate: currencyConversionRate.Float64,
+		RateSource:             rateSource.String,
+		ConvertedAt:            convertNullTime(convertedAt),
+	}
+}
+
+// invoiceRounding reads how invoice totals are rounded from the invoice rounding settings
+func invoiceRounding(queries *db.Queries) (RoundingPolicy, error) {
+	settings, err := (&AppSettingModel{queries: queries}).GetAll()
+	if err != nil {
+		return RoundingPolicy{}, err
+	}
+	return NewRoundingPolicy(settings), nil
+}
+
+// BilledTotal returns what the invoice bills the client: its amount due less the discount,
+// plus the adjustment, rounded the way the invoice rounding settings ask for. This is the
+// total printed on the invoice, and client credit and payments are set against it.
+func (inv Invoice) BilledTotal() float64 {
+	var terms InvoiceFinancials
+	if inv.Financials != nil {
+		terms = *inv.Financials
+	}
+	policy := inv.Rounding
+	if policy.Increment == 0 {
+		policy = DefaultRoundingPolicy
+	}
+	return terms.Bill(inv.AmountDue, policy).Total
+}
+
+// BalanceDue returns the amount still owed after any client credit applied to the invoice
+func (inv Invoice) BalanceDue() float64 {
+	return inv.BilledTotal() - inv.CreditApplied
+}
+
+// ChangesPaidFigures reports whether giving the invoice this invoice date and amount due would
+// alter a paid invoice. Once paid, an invoice's date and amount are part of the financial
+// record and stay fixed unless the payment is removed.
+func (inv Invoice) ChangesPaidFigures(invoiceDate time.Time, amountDue float64) bool {
+	if inv.DatePaid == nil {
+		return false
+	}
+	return inv.InvoiceDate.Format(isoLayout) != invoiceDate.Format(isoLayout) ||
+		math.Round(inv.AmountDue*100) != math.Round(amountDue*100)
+}
+
+// TotalPaid returns the amount received for the invoice, or zero if none has been recorded
+func (inv Invoice) TotalPaid() float64 {
+	if inv.AmountPaid == nil {
+		return 0
+	}
+	return *inv.AmountPaid
+}
+
+// Overpayment returns how much the amount paid exceeds the balance due, or zero
+func (inv Invoice) Overpayment() float64 {
+	if inv.AmountPaid == nil {
+		return 0
+	}
+	return max(inv.TotalPaid()-inv.BalanceDue(), 0)
+}
+
+// DisplayNumber returns the invoice number, or the zero padded ID that invoices without one are printed with
+func (inv Invoice) DisplayNumber() string {
+	if inv.InvoiceNumber != "" {
+		return inv.InvoiceNumber
+	}
+	return fmt.Sprintf("%04d", inv.ID)
+}
+
+// HasTimesheetRange reports whether the invoice lists only the timesheets worked within a date range
+func (inv Invoice) HasTimesheetRange() bool {
+	return inv.TimesheetsFrom != nil || inv.TimesheetsTo != nil
+}
+
+// IncludesWorkDate reports whether a timesheet worked on the given date is listed on the
+// invoice. Either end of the range may be left open, and without a range every timesheet
+// on the project is included.
+func (inv Invoice) IncludesWorkDate(workDate time.Time) bool {
+	day := workDate.Format("2006-01-02")
+	if inv.TimesheetsFrom != nil && day < inv.TimesheetsFrom.Format("2006-01-02") {
+		return false
+	}
+	if inv.TimesheetsTo != nil && day > inv.TimesheetsTo.Format("2006-01-02") {
+		return false
+	}
+	return true
+}
+
+// NeedsRegeneration reports whether a timesheet entry billed on the invoice has changed since
+// it was last saved, so that its amount and PDF may no longer match the time recorded
+func (inv Invoice) NeedsRegeneration() bool {
+	return inv.StaleSince != nil
+}
+
+// IsVoided reports whether the invoice has been voided
+func (inv Invoice) IsVoided() bool {
+	return inv.VoidedAt != nil
+}
+
 // InvoiceModel wraps the generated SQLC Queries for invoice operations
 type InvoiceModel struct {
 	queries *db.Queries
@@ -41,7 +319,14 @@ type InvoiceModel struct {
 // NewInvoiceModel creates a new InvoiceModel
 func NewInvoiceModel(database *sql.DB) *InvoiceModel {
 	return &InvoiceModel{
-		queries: db.New(database),
+		queries: newQueries(database),
+	}
+}
+
+// NewInvoiceModelWithTx creates a InvoiceModel whose queries run inside the given transaction
+func NewInvoiceModelWithTx(tx *sql.Tx) *InvoiceModel {
+	return &InvoiceModel{
+		queries: newQueries(tx),
 	}
 }
 
@@ -49,15 +334,10 @@ func NewInvoiceModel(database *sql.DB) *InvoiceModel {
 func (i *InvoiceModel) Insert(projectID int, invoiceDate time.Time, datePaid *time.Time, paymentTerms string, amountDue float64, displayDetails bool) (int, error) {
 	ctx := context.Background()
 
-	var datePaidPtr interface{}
-	if datePaid != nil {
-		datePaidPtr = *datePaid
-	}
-
 	params := db.InsertInvoiceParams{
 		ProjectID:      int64(projectID),
-		InvoiceDate:    invoiceDate,
-		DatePaid:       datePaidPtr,
+		InvoiceDate:    db.NewDate(invoiceDate),
+		DatePaid:       db.NewNullDate(datePaid),
 		PaymentTerms:   paymentTerms,
 		AmountDue:      amountDue,
 		DisplayDetails: displayDetails,
@@ -87,21 +367,38 @@ func (i *InvoiceModel) Get(id int) (Invoice, error) {
 		}
 	}
 
-	var datePaid *time.Time
-	if row.DatePaid != nil {
-		if dp, ok := row.DatePaid.(time.Time); ok {
-			datePaid = &dp
-		}
+	financials := convertInvoiceFinancials(row.FinancialsCapturedAt, row.HourlyRate, row.DiscountPercent, row.DiscountAmount, row.DiscountReason,
+		row.AdjustmentAmount, row.AdjustmentReason, row.CurrencyDisplay, row.CurrencyConversionRate,
+		row.ConversionRateSource, row.ConvertedAt)
+
+	rounding, err := invoiceRounding(i.queries)
+	if err != nil {
+		return Invoice{}, err
 	}
 
 	invoice := Invoice{
 		ID:             int(row.ID),
 		ProjectID:      int(row.ProjectID),
-		InvoiceDate:    row.InvoiceDate,
-		DatePaid:       datePaid,
+		InvoiceDate:    row.InvoiceDate.Time,
+		DatePaid:       row.DatePaid.Ptr(),
 		PaymentTerms:   row.PaymentTerms,
 		AmountDue:      row.AmountDue,
 		DisplayDetails: row.DisplayDetails,
+		InvoiceNumber:  row.InvoiceNumber.String,
+		VoidedAt:       convertNullTime(row.VoidedAt),
+		VoidReason:     row.VoidReason.String,
+		AmountPaid:     convertNullFloat64(row.AmountPaid),
+		CreditApplied:  row.CreditApplied,
+		DueDate:        row.DueDate.Ptr(),
+		CCEmail:        row.CcEmail.String,
+		BudgetCode:     row.BudgetCode.String,
+		TimesheetsFrom: row.TimesheetsFrom.Ptr(),
+		TimesheetsTo:   row.TimesheetsTo.Ptr(),
+		Financials:     financials,
+		Rounding:       rounding,
+		PDFSignedAt:    convertNullTime(row.PdfSignedAt),
+		PDFSignedBy:    row.PdfSignedBy.String,
+		StaleSince:     convertNullTime(row.TimesheetsChangedAt),
 		Updated:        row.UpdatedAt,
 		Created:        row.CreatedAt,
 		DeletedAt:      deletedAt,
@@ -117,53 +414,110 @@ func (i *InvoiceModel) GetByProject(projectID int) ([]Invoice, error) {
 	if err != nil {
 		return nil, err
 	}
+	rounding, err := invoiceRounding(i.queries)
+	if err != nil {
+		return nil, err
+	}
 
 	invoices := make([]Invoice, len(rows))
 	for j, row := range rows {
-		var deletedAt *time.Time
-		if row.DeletedAt != nil {
-			if dt, ok := row.DeletedAt.(time.Time); ok {
-				deletedAt = &dt
-			}
-		}
+		invoices[j] = invoiceFromProjectRow(row, rounding)
+	}
 
-		var datePaid *time.Time
-		if row.DatePaid != nil {
-			if dp, ok := row.DatePaid.(time.Time); ok {
-				datePaid = &dp
-			}
-		}
+	return invoices, nil
+}
 
-		invoices[j] = Invoice{
-			ID:             int(row.ID),
-			ProjectID:      int(row.ProjectID),
-			InvoiceDate:    row.InvoiceDate,
-			DatePaid:       datePaid,
-			PaymentTerms:   row.PaymentTerms,
-			AmountDue:      row.AmountDue,
-			DisplayDetails: row.DisplayDetails,
-			Updated:        row.UpdatedAt,
-			Created:        row.CreatedAt,
-			DeletedAt:      deletedAt,
-		}
+// GetByProjectWithPagination retrieves a page of a project's invoices, most recent first
+func (i *InvoiceModel) GetByProjectWithPagination(projectID int, limit, offset int64) ([]Invoice, error) {
+	ctx := context.Background()
+	rows, err := i.queries.GetInvoicesByProjectWithPagination(ctx, db.GetInvoicesByProjectWithPaginationParams{
+		ProjectID: int64(projectID),
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rounding, err := invoiceRounding(i.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := make([]Invoice, len(rows))
+	for j, row := range rows {
+		invoices[j] = invoiceFromProjectRow(db.GetInvoicesByProjectRow(row), rounding)
 	}
 
 	return invoices, nil
 }
 
+// GetCountByProject returns the number of non-deleted invoices on a project
+func (i *InvoiceModel) GetCountByProject(projectID int) (int64, error) {
+	ctx := context.Background()
+	return i.queries.GetInvoicesByProjectCount(ctx, int64(projectID))
+}
+
+// invoiceFromProjectRow converts a row of a project's invoices, totalled under rounding
+func invoiceFromProjectRow(row db.GetInvoicesByProjectRow, rounding RoundingPolicy) Invoice {
+	var deletedAt *time.Time
+	if row.DeletedAt != nil {
+		if dt, ok := row.DeletedAt.(time.Time); ok {
+			deletedAt = &dt
+		}
+	}
+
+	financials := convertInvoiceFinancials(row.FinancialsCapturedAt, row.HourlyRate, row.DiscountPercent, row.DiscountAmount, row.DiscountReason,
+		row.AdjustmentAmount, row.AdjustmentReason, row.CurrencyDisplay, row.CurrencyConversionRate,
+		row.ConversionRateSource, row.ConvertedAt)
+
+	return Invoice{
+		ID:             int(row.ID),
+		ProjectID:      int(row.ProjectID),
+		InvoiceDate:    row.InvoiceDate.Time,
+		DatePaid:       row.DatePaid.Ptr(),
+		PaymentTerms:   row.PaymentTerms,
+		AmountDue:      row.AmountDue,
+		DisplayDetails: row.DisplayDetails,
+		InvoiceNumber:  row.InvoiceNumber.String,
+		VoidedAt:       convertNullTime(row.VoidedAt),
+		VoidReason:     row.VoidReason.String,
+		AmountPaid:     convertNullFloat64(row.AmountPaid),
+		CreditApplied:  row.CreditApplied,
+		DueDate:        row.DueDate.Ptr(),
+		CCEmail:        row.CcEmail.String,
+		BudgetCode:     row.BudgetCode.String,
+		TimesheetsFrom: row.TimesheetsFrom.Ptr(),
+		TimesheetsTo:   row.TimesheetsTo.Ptr(),
+		Financials:     financials,
+		Rounding:       rounding,
+		PDFSignedAt:    convertNullTime(row.PdfSignedAt),
+		PDFSignedBy:    row.PdfSignedBy.String,
+		StaleSince:     convertNullTime(row.TimesheetsChangedAt),
+		Updated:        row.UpdatedAt,
+		Created:        row.CreatedAt,
+		DeletedAt:      deletedAt,
+	}
+}
+
 // Update modifies an existing invoice in the database
 func (i *InvoiceModel) Update(id int, invoiceDate time.Time, datePaid *time.Time, paymentTerms string, amountDue float64, displayDetails bool) error {
 	ctx := context.Background()
 
-	var datePaidPtr interface{}
+	// A paid invoice keeps its date and amount unless this update removes the payment
 	if datePaid != nil {
-		datePaidPtr = *datePaid
+		current, err := i.Get(id)
+		if err != nil && !errors.Is(err, ErrNoRecord) {
+			return err
+		}
+		if err == nil && current.ChangesPaidFigures(invoiceDate, amountDue) {
+			return ErrInvoicePaid
+		}
 	}
 
 	params := db.UpdateInvoiceParams{
 		ID:             int64(id),
-		InvoiceDate:    invoiceDate,
-		DatePaid:       datePaidPtr,
+		InvoiceDate:    db.NewDate(invoiceDate),
+		DatePaid:       db.NewNullDate(datePaid),
 		PaymentTerms:   paymentTerms,
 		AmountDue:      amountDue,
 		DisplayDetails: displayDetails,
@@ -171,38 +525,414 @@ func (i *InvoiceModel) Update(id int, invoiceDate time.Time, datePaid *time.Time
 	return i.queries.UpdateInvoice(ctx, params)
 }
 
+// SetInvoiceNumber records the number an invoice was issued under
+func (i *InvoiceModel) SetInvoiceNumber(id int, invoiceNumber string) error {
+	ctx := context.Background()
+	return i.queries.SetInvoiceNumber(ctx, db.SetInvoiceNumberParams{
+		InvoiceNumber: sql.NullString{String: invoiceNumber, Valid: invoiceNumber != ""},
+		ID:            int64(id),
+	})
+}
+
+// SetPayment records the amount received for an invoice and the client credit applied to it
+func (i *InvoiceModel) SetPayment(id int, amountPaid *float64, creditApplied float64) error {
+	ctx := context.Background()
+	return i.queries.SetInvoicePayment(ctx, db.SetInvoicePaymentParams{
+		AmountPaid:    convertFloatPtr(amountPaid),
+		CreditApplied: creditApplied,
+		ID:            int64(id),
+	})
+}
+
+// SetDueDate records when payment of an invoice is due, or clears it when dueDate is nil
+func (i *InvoiceModel) SetDueDate(id int, dueDate *time.Time) error {
+	ctx := context.Background()
+	return i.queries.SetInvoiceDueDate(ctx, db.SetInvoiceDueDateParams{
+		DueDate: db.NewNullDate(dueDate),
+		ID:      int64(id),
+	})
+}
+
+// SetCCEmail records who to copy on an invoice in place of the project's or client's invoice
+// CC email, or clears the override when ccEmail is empty
+func (i *InvoiceModel) SetCCEmail(id int, ccEmail string) error {
+	ctx := context.Background()
+	return i.queries.SetInvoiceCCEmail(ctx, db.SetInvoiceCCEmailParams{
+		CcEmail: sql.NullString{String: ccEmail, Valid: ccEmail != ""},
+		ID:      int64(id),
+	})
+}
+
+// SetBudgetCode records the cost center or account code printed on an invoice in place of
+// the project's or client's budget code, or clears the override when budgetCode is empty
+func (i *InvoiceModel) SetBudgetCode(id int, budgetCode string) error {
+	ctx := context.Background()
+	return i.queries.SetInvoiceBudgetCode(ctx, db.SetInvoiceBudgetCodeParams{
+		BudgetCode: sql.NullString{String: budgetCode, Valid: budgetCode != ""},
+		ID:         int64(id),
+	})
+}
+
+// SetPDFSignature records that the invoice's PDF was digitally signed by signedBy at signedAt
+func (i *InvoiceModel) SetPDFSignature(id int, signedBy string, signedAt time.Time) error {
+	ctx := context.Background()
+	return i.queries.SetInvoicePDFSignature(ctx, db.SetInvoicePDFSignatureParams{
+		PdfSignedAt: sql.NullTime{Time: signedAt, Valid: true},
+		PdfSignedBy: sql.NullString{String: signedBy, Valid: true},
+		ID:          int64(id),
+	})
+}
+
+// SetFinancials records the financial terms an invoice was issued under. When the invoice is
+// converted into another currency the rate's source and the time of conversion are recorded
+// with it, the time defaulting to now. A fixed discount can't be more than the amount due.
+func (i *InvoiceModel) SetFinancials(id int, financials InvoiceFinancials) error {
+	ctx := context.Background()
+	if financials.DiscountAmount != nil {
+		invoice, err := i.queries.GetInvoice(ctx, int64(id))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNoRecord
+			}
+			return err
+		}
+		if *financials.DiscountAmount > invoice.AmountDue {
+			return ErrDiscountTooLarge
+		}
+	}
+
+	var rateSource sql.NullString
+	var convertedAt sql.NullTime
+	if financials.IsConverted() {
+		rateSource = sql.NullString{String: financials.RateSource, Valid: financials.RateSource != ""}
+		convertedAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+		if financials.ConvertedAt != nil {
+			convertedAt.Time = *financials.ConvertedAt
+		}
+	}
+	return i.queries.SetInvoiceFinancials(ctx, db.SetInvoiceFinancialsParams{
+		HourlyRate:             sql.NullFloat64{Float64: financials.HourlyRate, Valid: true},
+		DiscountPercent:        convertFloatPtr(financials.DiscountPercent),
+		DiscountAmount:         convertFloatPtr(financials.DiscountAmount),
+		DiscountReason:         sql.NullString{String: financials.DiscountReason, Valid: financials.DiscountReason != ""},
+		AdjustmentAmount:       convertFloatPtr(financials.AdjustmentAmount),
+		AdjustmentReason:       sql.NullString{String: financials.AdjustmentReason, Valid: financials.AdjustmentReason != ""},
+		CurrencyDisplay:        sql.NullString{String: financials.CurrencyDisplay, Valid: true},
+		CurrencyConversionRate: sql.NullFloat64{Float64: financials.CurrencyConversionRate, Valid: true},
+		ConversionRateSource:   rateSource,
+		ConvertedAt:            convertedAt,
+		ID:                     int64(id),
+	})
+}
+
+// FlagTimesheetsChanged marks an invoice as needing regeneration because a timesheet entry it
+// bills was changed
+func (i *InvoiceModel) FlagTimesheetsChanged(id int) error {
+	ctx := context.Background()
+	return i.queries.FlagInvoiceTimesheetsChanged(ctx, int64(id))
+}
+
+// SetTimesheetRange limits the timesheets listed on an invoice to those worked between from
+// and to. Either may be nil to leave that end of the range open.
+func (i *InvoiceModel) SetTimesheetRange(id int, from, to *time.Time) error {
+	ctx := context.Background()
+	return i.queries.SetInvoiceTimesheetRange(ctx, db.SetInvoiceTimesheetRangeParams{
+		TimesheetsFrom: db.NewNullDate(from),
+		TimesheetsTo:   db.NewNullDate(to),
+		ID:             int64(id),
+	})
+}
+
+// NumberExists reports whether an invoice, including a voided one, already uses invoiceNumber
+func (i *InvoiceModel) NumberExists(invoiceNumber string) (bool, error) {
+	ctx := context.Background()
+	count, err := i.queries.CountInvoicesWithNumber(ctx, sql.NullString{String: invoiceNumber, Valid: true})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// DefaultPaymentDays is how long a client has to pay when the payment terms don't say
+const DefaultPaymentDays = 30
+
+var paymentDaysPattern = regexp.MustCompile(`\d+`)
+
+// InvoiceCC is who gets a copy of an invoice and which record the address was taken from
+type InvoiceCC struct {
+	Email       string
+	Description string
+	Source      string // "invoice", "project" or "client"
+}
+
+// ResolveInvoiceCC returns who to copy on an invoice: the invoice's own CC email if it has one,
+// otherwise the project's, otherwise the client's. An empty Email means nobody is copied.
+func ResolveInvoiceCC(invoice Invoice, project Project, client Client) InvoiceCC {
+	if invoice.CCEmail != "" {
+		return InvoiceCC{Email: invoice.CCEmail, Source: "invoice"}
+	}
+	if project.InvoiceCCEmail != "" {
+		return InvoiceCC{Email: project.InvoiceCCEmail, Description: project.InvoiceCCDescription, Source: "project"}
+	}
+	if client.InvoiceCCEmail != nil && *client.InvoiceCCEmail != "" {
+		var description string
+		if client.InvoiceCCDescription != nil {
+			description = *client.InvoiceCCDescription
+		}
+		return InvoiceCC{Email: *client.InvoiceCCEmail, Description: description, Source: "client"}
+	}
+	return InvoiceCC{}
+}
+
+// ResolveBudgetCode returns the cost center or account code to print on an invoice: the
+// invoice's own budget code if it has one, otherwise the project's, otherwise the client's.
+// An empty result means the invoice shows no budget code.
+func ResolveBudgetCode(invoice Invoice, project Project, client Client) string {
+	if invoice.BudgetCode != "" {
+		return invoice.BudgetCode
+	}
+	if project.BudgetCode != "" {
+		return project.BudgetCode
+	}
+	if client.BudgetCode != nil {
+		return *client.BudgetCode
+	}
+	return ""
+}
+
+// DueDateFor computes when an invoice falls due from its payment terms, reading the number
+// of days from terms such as "Net 15" or "30 days". Invoices due on receipt are due on the
+// invoice date and anything else gets DefaultPaymentDays.
+func DueDateFor(invoiceDate time.Time, paymentTerms string) time.Time {
+	if strings.Contains(strings.ToLower(paymentTerms), "receipt") {
+		return invoiceDate
+	}
+	if days, err := strconv.Atoi(paymentDaysPattern.FindString(paymentTerms)); err == nil {
+		return invoiceDate.AddDate(0, 0, days)
+	}
+	return invoiceDate.AddDate(0, 0, DefaultPaymentDays)
+}
+
 // Delete soft deletes an invoice by setting the deleted_at timestamp
 func (i *InvoiceModel) Delete(id int) error {
 	ctx := context.Background()
 	return i.queries.DeleteInvoice(ctx, int64(id))
 }
 
+// Void marks an invoice as voided with the given reason. A voided invoice stays on
+// record to keep its number but can no longer be changed and does not count as revenue.
+func (i *InvoiceModel) Void(id int, reason string) error {
+	ctx := context.Background()
+	rows, err := i.queries.VoidInvoice(ctx, db.VoidInvoiceParams{
+		VoidReason: sql.NullString{String: reason, Valid: true},
+		ID:         int64(id),
+	})
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		// Either the invoice doesn't exist or it was already voided
+		if _, err := i.Get(id); err != nil {
+			return err
+		}
+		return ErrInvoiceVoided
+	}
+	return nil
+}
+
+// MarkPaid records the date an open invoice was paid. It returns ErrInvoicePaid if the
+// invoice has been marked paid already and ErrInvoiceVoided if it has been voided.
+func (i *InvoiceModel) MarkPaid(id int, datePaid time.Time) error {
+	ctx := context.Background()
+	rows, err := i.queries.MarkInvoicePaid(ctx, db.MarkInvoicePaidParams{
+		DatePaid: db.NewNullDate(&datePaid),
+		ID:       int64(id),
+	})
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		invoice, err := i.Get(id)
+		if err != nil {
+			return err
+		}
+		if invoice.IsVoided() {
+			return ErrInvoiceVoided
+		}
+		return ErrInvoicePaid
+	}
+	return nil
+}
+
+// GetOpen retrieves the invoices still waiting to be paid, oldest first, along with their
+// project and client names. Voided invoices and those of deleted projects are left out.
+func (i *InvoiceModel) GetOpen() ([]InvoiceWithClient, error) {
+	ctx := context.Background()
+	rows, err := i.queries.GetOpenInvoicesWithClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rounding, err := invoiceRounding(i.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := make([]InvoiceWithClient, len(rows))
+	for idx, row := range rows {
+		invoices[idx] = convertInvoiceWithClientRow(db.GetInvoicesWithClientAfterRow(row), rounding)
+	}
+	return invoices, nil
+}
+
+// SimilarInvoiceWindowDays is how far apart two invoices for the same project and amount
+// can be dated before they are no longer treated as a possible duplicate
+const SimilarInvoiceWindowDays = 7
+
+// ExistsSimilar reports whether the project already has an invoice, other than a voided one,
+// for the same amount dated within SimilarInvoiceWindowDays of invoiceDate
+func (i *InvoiceModel) ExistsSimilar(projectID int, amountDue float64, invoiceDate time.Time) (bool, error) {
+	ctx := context.Background()
+	count, err := i.queries.CountSimilarInvoices(ctx, db.CountSimilarInvoicesParams{
+		ProjectID:   int64(projectID),
+		AmountDue:   amountDue,
+		InvoiceDate: db.NewDate(invoiceDate),
+		WithinDays:  SimilarInvoiceWindowDays,
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// InvoiceWithClient represents an invoice along with the project and client it belongs to
+type InvoiceWithClient struct {
+	Invoice
+	ProjectName string
+	Currency    string
+	ClientID    int
+	ClientName  string
+}
+
+// GetWithClient retrieves an invoice by ID along with its project and client names
+func (i *InvoiceModel) GetWithClient(id int) (InvoiceWithClient, error) {
+	ctx := context.Background()
+	row, err := i.queries.GetInvoiceWithClient(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return InvoiceWithClient{}, ErrNoRecord
+		}
+		return InvoiceWithClient{}, err
+	}
+	rounding, err := invoiceRounding(i.queries)
+	if err != nil {
+		return InvoiceWithClient{}, err
+	}
+	return convertInvoiceWithClientRow(db.GetInvoicesWithClientAfterRow(row), rounding), nil
+}
+
+// GetWithClientAfter retrieves up to limit invoices with an ID greater than afterID, in ID
+// order, so that callers can page through every invoice with a stable cursor
+func (i *InvoiceModel) GetWithClientAfter(afterID, limit int) ([]InvoiceWithClient, error) {
+	ctx := context.Background()
+	rows, err := i.queries.GetInvoicesWithClientAfter(ctx, db.GetInvoicesWithClientAfterParams{
+		ID:    int64(afterID),
+		Limit: int64(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	rounding, err := invoiceRounding(i.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := make([]InvoiceWithClient, len(rows))
+	for idx, row := range rows {
+		invoices[idx] = convertInvoiceWithClientRow(row, rounding)
+	}
+	return invoices, nil
+}
+
+// convertInvoiceWithClientRow converts a row of an invoice with its client, totalled under rounding
+func convertInvoiceWithClientRow(row db.GetInvoicesWithClientAfterRow, rounding RoundingPolicy) InvoiceWithClient {
+	var deletedAt *time.Time
+	if dt, ok := row.DeletedAt.(time.Time); ok {
+		deletedAt = &dt
+	}
+
+	financials := convertInvoiceFinancials(row.FinancialsCapturedAt, row.HourlyRate, row.DiscountPercent, row.DiscountAmount, row.DiscountReason,
+		row.AdjustmentAmount, row.AdjustmentReason, row.InvoiceCurrencyDisplay, row.CurrencyConversionRate,
+		row.ConversionRateSource, row.ConvertedAt)
+
+	return InvoiceWithClient{
+		Invoice: Invoice{
+			ID:            int(row.ID),
+			ProjectID:     int(row.ProjectID),
+			InvoiceDate:   row.InvoiceDate.Time,
+			DatePaid:      row.DatePaid.Ptr(),
+			PaymentTerms:  row.PaymentTerms,
+			AmountDue:     row.AmountDue,
+			InvoiceNumber: row.InvoiceNumber.String,
+			VoidedAt:      convertNullTime(row.VoidedAt),
+			VoidReason:    row.VoidReason.String,
+			AmountPaid:    convertNullFloat64(row.AmountPaid),
+			CreditApplied: row.CreditApplied,
+			DueDate:       row.DueDate.Ptr(),
+			Financials:    financials,
+			Rounding:      rounding,
+			Updated:       row.UpdatedAt,
+			Created:       row.CreatedAt,
+			DeletedAt:     deletedAt,
+		},
+		ProjectName: row.ProjectName,
+		Currency:    row.CurrencyDisplay,
+		ClientID:    int(row.ClientID),
+		ClientName:  row.ClientName,
+	}
+}
+
 // ComprehensiveInvoiceData represents complete invoice data with all related information for professional PDF generation
 type ComprehensiveInvoiceData struct {
-	Invoice          Invoice
-	Project          Project
-	Client           Client
-	Timesheets       []Timesheet
-	TotalHours       float64
-	Subtotal         float64
-	DiscountAmount   float64
-	AdjustmentAmount float64
-	FinalTotal       float64
+	Invoice            Invoice
+	Project            Project
+	Client             Client
+	BusinessProfile    *BusinessProfile
+	Timesheets         []Timesheet
+	TotalHours         float64
+	Subtotal           float64
+	DiscountAmount     float64
+	AdjustmentAmount   float64
+	RoundingAdjustment float64
+	FinalTotal         float64
+	ProjectSubtotals   []ProjectSubtotal
+	ClientApproval     *ClientApproval
+	Snapshot           *InvoiceSnapshot
 }
 
 // InvoiceTemplateData represents the data structure for HTML template rendering
 type InvoiceTemplateData struct {
-	Invoice          Invoice
-	Project          Project
-	Client           Client
-	Timesheets       []Timesheet
-	TotalHours       float64
-	AvgRate          float64
-	Subtotal         float64
-	DiscountAmount   float64
-	AdjustmentAmount float64
-	FinalTotal       float64
-	Settings         InvoiceTemplateSettings
+	Invoice            Invoice
+	Project            Project
+	Client             Client
+	BillTo             []string // Lines of the Bill To block
+	BudgetCode         string   // Cost center or account code the client charges the invoice to
+	Timesheets         []Timesheet
+	TotalHours         float64
+	AvgRate            float64
+	Subtotal           float64
+	DiscountAmount     float64
+	AdjustmentAmount   float64
+	RoundingAdjustment float64
+	FinalTotal         float64
+	CreditApplied      float64 // Client credit set off against the total
+	BalanceDue         float64 // What the client still has to pay after the credit
+	Conversion         *CurrencyConversion
+	ProjectSubtotals   []ProjectSubtotal
+	ClientApproval     *ClientApproval // Set when the client approved all the time listed
+	Settings           InvoiceTemplateSettings
+	Labels             InvoiceLabels
 }
 
 // InvoiceTemplateSettings represents settings for the HTML template
@@ -216,9 +946,71 @@ type InvoiceTemplateSettings struct {
 	FreelancerPhone          string
 	FreelancerEmail          string
 	CurrencySymbol           string
+	DateFormat               DateFormat
 	ShowIndividualTimesheets bool
+	GroupByWorkType          bool
 	DefaultPaymentTerms      string
 	ThankYouMessage          string
+	BankDetails              string
+	PaymentInstructions      PaymentInstructions
+	PageHeaderFooter         bool
+	PageHeader               string
+	PageFooter               string
+}
+
+// PaymentInstructions represents the bank transfer and PayPal details shown on an invoice
+type PaymentInstructions struct {
+	BankName      string
+	AccountName   string
+	RoutingNumber string
+	AccountNumber string
+	IBAN          string
+	SWIFT         string
+	PayPalAddress string
+	International bool
+}
+
+// HasBankTransfer reports whether there are bank account details to display
+func (p PaymentInstructions) HasBankTransfer() bool {
+	return p.RoutingNumber != "" || p.AccountNumber != "" || p.IBAN != "" || p.SWIFT != ""
+}
+
+// HasAny reports whether there are any payment instructions to display
+func (p PaymentInstructions) HasAny() bool {
+	return p.HasBankTransfer() || p.PayPalAddress != ""
+}
+
+// NewPaymentInstructions builds the payment instructions for an invoice in the given currency.
+// Invoices in the domestic currency get routing/account numbers, all others get IBAN/SWIFT details.
+func NewPaymentInstructions(settings map[string]AppSettingValue, currency string) PaymentInstructions {
+	getSetting := func(key string) string {
+		if setting, exists := settings[key]; exists {
+			return strings.TrimSpace(setting.AsString())
+		}
+		return ""
+	}
+
+	domesticCurrency := getSetting("payment_domestic_currency")
+	if domesticCurrency == "" {
+		domesticCurrency = "USD"
+	}
+
+	instructions := PaymentInstructions{
+		BankName:      getSetting("payment_bank_name"),
+		AccountName:   getSetting("payment_account_name"),
+		PayPalAddress: getSetting("payment_paypal_address"),
+	}
+
+	if currency == "" || strings.EqualFold(currency, domesticCurrency) {
+		instructions.RoutingNumber = getSetting("payment_routing_number")
+		instructions.AccountNumber = getSetting("payment_account_number")
+	} else {
+		instructions.International = true
+		instructions.IBAN = getSetting("payment_iban")
+		instructions.SWIFT = getSetting("payment_swift")
+	}
+
+	return instructions
 }
 
 // GetComprehensiveForPDF retrieves comprehensive invoice data with all related information for professional PDF generation
@@ -244,21 +1036,34 @@ func (i *InvoiceModel) GetComprehensiveForPDF(id int) (ComprehensiveInvoiceData,
 		}
 	}
 
-	var datePaid *time.Time
-	if row.DatePaid != nil {
-		if dp, ok := row.DatePaid.(time.Time); ok {
-			datePaid = &dp
-		}
+	financials := convertInvoiceFinancials(row.FinancialsCapturedAt, row.HourlyRate, row.DiscountPercent, row.DiscountAmount, row.DiscountReason,
+		row.AdjustmentAmount, row.AdjustmentReason, row.CurrencyDisplay, row.CurrencyConversionRate,
+		row.ConversionRateSource, row.ConvertedAt)
+
+	rounding, err := invoiceRounding(i.queries)
+	if err != nil {
+		return ComprehensiveInvoiceData{}, fmt.Errorf("failed to get settings: %w", err)
 	}
 
 	invoice := Invoice{
 		ID:             int(row.ID),
 		ProjectID:      int(row.ProjectID),
-		InvoiceDate:    row.InvoiceDate,
-		DatePaid:       datePaid,
+		InvoiceDate:    row.InvoiceDate.Time,
+		DatePaid:       row.DatePaid.Ptr(),
 		PaymentTerms:   row.PaymentTerms,
 		AmountDue:      row.AmountDue,
 		DisplayDetails: row.DisplayDetails,
+		InvoiceNumber:  row.InvoiceNumber.String,
+		VoidedAt:       convertNullTime(row.VoidedAt),
+		VoidReason:     row.VoidReason.String,
+		AmountPaid:     convertNullFloat64(row.AmountPaid),
+		CreditApplied:  row.CreditApplied,
+		DueDate:        row.DueDate.Ptr(),
+		BudgetCode:     row.BudgetCode.String,
+		TimesheetsFrom: row.TimesheetsFrom.Ptr(),
+		TimesheetsTo:   row.TimesheetsTo.Ptr(),
+		Financials:     financials,
+		Rounding:       rounding,
 		Updated:        row.UpdatedAt,
 		Created:        row.CreatedAt,
 		DeletedAt:      deletedAt,
@@ -281,15 +1086,51 @@ func (i *InvoiceModel) GetComprehensiveForPDF(id int) (ComprehensiveInvoiceData,
 		return ComprehensiveInvoiceData{}, fmt.Errorf("failed to get client: %w", err)
 	}
 
-	// Get timesheets for the project
-	timesheetRows, err := i.queries.GetTimesheetsByProject(ctx, int64(row.ProjectID))
+	// Get the business profile the invoice is issued under, if any
+	var businessProfile *BusinessProfile
+	if profileID := ResolveBusinessProfileID(project, client); profileID != nil {
+		profileModel := &BusinessProfileModel{queries: i.queries}
+		profile, err := profileModel.Get(*profileID)
+		if err != nil && !errors.Is(err, ErrNoRecord) {
+			return ComprehensiveInvoiceData{}, fmt.Errorf("failed to get business profile: %w", err)
+		}
+		if err == nil {
+			businessProfile = &profile
+		}
+	}
+
+	// A consolidated invoice covers each of the projects it lists rather than only its own
+	consolidatedModel := &ConsolidatedInvoiceModel{queries: i.queries}
+	projectSubtotals, err := consolidatedModel.GetProjects(id)
 	if err != nil {
-		return ComprehensiveInvoiceData{}, fmt.Errorf("failed to get timesheets: %w", err)
+		return ComprehensiveInvoiceData{}, fmt.Errorf("failed to get invoice projects: %w", err)
+	}
+	projectIDs := []int64{row.ProjectID}
+	if len(projectSubtotals) > 0 {
+		projectIDs = projectIDs[:0]
+		for _, subtotal := range projectSubtotals {
+			projectIDs = append(projectIDs, int64(subtotal.ProjectID))
+		}
+	}
+
+	// Get timesheets for the project
+	var timesheetRows []db.GetTimesheetsByProjectRow
+	for _, projectID := range projectIDs {
+		projectRows, err := i.queries.GetTimesheetsByProject(ctx, projectID)
+		if err != nil {
+			return ComprehensiveInvoiceData{}, fmt.Errorf("failed to get timesheets: %w", err)
+		}
+		timesheetRows = append(timesheetRows, projectRows...)
 	}
 
-	timesheets := make([]Timesheet, len(timesheetRows))
+	// Only the approved timesheets within the invoice's date range, if it has one, are listed
+	timesheets := make([]Timesheet, 0, len(timesheetRows))
 	totalHours := 0.0
-	for j, tsRow := range timesheetRows {
+	for _, tsRow := range timesheetRows {
+		if tsRow.PendingApproval || !invoice.IncludesWorkDate(tsRow.WorkDate) {
+			continue
+		}
+
 		var tsDeletedAt *time.Time
 		if tsRow.DeletedAt != nil {
 			if dt, ok := tsRow.DeletedAt.(time.Time); ok {
@@ -302,81 +1143,213 @@ func (i *InvoiceModel) GetComprehensiveForPDF(id int) (ComprehensiveInvoiceData,
 			description = tsRow.Description.String
 		}
 
-		timesheets[j] = Timesheet{
+		timesheet := Timesheet{
 			ID:          int(tsRow.ID),
 			ProjectID:   int(tsRow.ProjectID),
 			WorkDate:    tsRow.WorkDate,
 			HoursWorked: tsRow.HoursWorked,
 			HourlyRate:  tsRow.HourlyRate,
 			Description: description,
+			ServiceID:   convertNullInt64(tsRow.ServiceID),
+			ServiceName: tsRow.ServiceName.String,
+			Unit:        tsRow.Unit,
+			WorkType:    tsRow.WorkType,
 			Updated:     tsRow.UpdatedAt,
 			Created:     tsRow.CreatedAt,
 			DeletedAt:   tsDeletedAt,
 		}
+		timesheets = append(timesheets, timesheet)
 
-		totalHours += tsRow.HoursWorked
+		// Words and pages aren't time, so they are left out of the total hours
+		if timesheet.IsHourly() {
+			totalHours += tsRow.HoursWorked
+		}
 	}
 
-	// Calculate amounts
-	subtotal := invoice.AmountDue
-	discountAmount := 0.0
-	adjustmentAmountValue := 0.0
+	// The invoice notes the client's approval when they approved all the time it lists
+	clientApproval, err := i.clientApprovalCovering(timesheets)
+	if err != nil {
+		return ComprehensiveInvoiceData{}, fmt.Errorf("failed to get client approvals: %w", err)
+	}
 
-	// Apply project-level discount if applicable
-	if project.DiscountPercent != nil && *project.DiscountPercent > 0 {
-		discountAmount = subtotal * (*project.DiscountPercent / 100.0)
-		subtotal -= discountAmount
+	// Print who the invoice is to and from as they were when it was first printed
+	snapshot, err := i.snapshot(id)
+	if err != nil {
+		return ComprehensiveInvoiceData{}, fmt.Errorf("failed to get invoice snapshot: %w", err)
 	}
 
-	// Apply project-level adjustment if applicable
-	if project.AdjustmentAmount != nil {
-		adjustmentAmountValue = *project.AdjustmentAmount
-		subtotal += adjustmentAmountValue
+	// Bill on the terms the invoice was issued under rather than the project's current ones
+	if invoice.Financials != nil {
+		invoice.Financials.ApplyTo(&project)
 	}
 
+	// Bill on the invoice's terms, or the project's for invoices from before they were captured,
+	// working the total out the same way as Invoice.BilledTotal
+	terms := FinancialsFromProject(project)
+	if invoice.Financials != nil {
+		terms = *invoice.Financials
+	}
+	bill := terms.Bill(invoice.AmountDue, rounding)
+
 	return ComprehensiveInvoiceData{
-		Invoice:          invoice,
-		Project:          project,
-		Client:           client,
-		Timesheets:       timesheets,
-		TotalHours:       totalHours,
-		Subtotal:         subtotal,
-		DiscountAmount:   discountAmount,
-		AdjustmentAmount: adjustmentAmountValue,
-		FinalTotal:       subtotal, // After discounts and adjustments
+		Invoice:            invoice,
+		Project:            project,
+		Client:             client,
+		BusinessProfile:    businessProfile,
+		Timesheets:         timesheets,
+		TotalHours:         totalHours,
+		Subtotal:           invoice.AmountDue - bill.Discount + bill.Adjustment,
+		DiscountAmount:     bill.Discount,
+		AdjustmentAmount:   bill.Adjustment,
+		RoundingAdjustment: bill.RoundingAdjustment,
+		FinalTotal:         bill.Total, // After discounts, adjustments and rounding
+		ProjectSubtotals:   projectSubtotals,
+		ClientApproval:     clientApproval,
+		Snapshot:           snapshot,
 	}, nil
 }
 
+// clientApprovalCovering returns the client's approval of the time on an invoice when every
+// project's timesheets were approved through one link, the latest approval when there are
+// several, or nil when any of them wasn't approved
+func (i *InvoiceModel) clientApprovalCovering(timesheets []Timesheet) (*ClientApproval, error) {
+	if len(timesheets) == 0 {
+		return nil, nil
+	}
+	workDates := map[int][]time.Time{}
+	for _, timesheet := range timesheets {
+		workDates[timesheet.ProjectID] = append(workDates[timesheet.ProjectID], timesheet.WorkDate)
+	}
+
+	approvalModel := &ClientApprovalModel{queries: i.queries}
+	var latest *ClientApproval
+	for projectID, dates := range workDates {
+		approvals, err := approvalModel.GetByProject(projectID)
+		if err != nil {
+			return nil, err
+		}
+		approval := ApprovalCovering(approvals, dates)
+		if approval == nil {
+			return nil, nil
+		}
+		if latest == nil || approval.ApprovedAt.After(*latest.ApprovedAt) {
+			latest = approval
+		}
+	}
+	return latest, nil
+}
+
 // GenerateComprehensivePDF generates a professional PDF invoice using chromedp HTML template
 func (i *InvoiceModel) GenerateComprehensivePDF(id int, settings map[string]AppSettingValue) ([]byte, error) {
 	// Use the new HTML-based PDF generation
 	return i.GenerateHTMLPDF(id, settings)
 }
 
+// Rough capacity of the invoice PDF layout, used to estimate its length without rendering it
+const (
+	invoicePDFFirstPageTimesheets = 12
+	invoicePDFTimesheetsPerPage   = 30
+)
+
+// DefaultMaxPDFPages is how long an invoice PDF can be estimated to run before printing it
+// asks for confirmation, when the invoice_max_pdf_pages setting is missing or invalid
+const DefaultMaxPDFPages = 10
+
+// EstimatePDFPages estimates how many pages an invoice's PDF will run to. Only invoices that
+// list individual timesheets grow beyond a single page.
+func (i *InvoiceModel) EstimatePDFPages(id int, settings map[string]AppSettingValue) (int, error) {
+	data, err := i.GetComprehensiveForPDF(id)
+	if err != nil {
+		return 0, err
+	}
+
+	showTimesheets := true
+	if setting, exists := settings["invoice_show_individual_timesheets"]; exists {
+		if val, err := setting.AsBool(); err == nil {
+			showTimesheets = val
+		}
+	}
+	if !showTimesheets || !data.Invoice.DisplayDetails {
+		return 1, nil
+	}
+
+	overflow := len(data.Timesheets) - invoicePDFFirstPageTimesheets
+	if overflow <= 0 {
+		return 1, nil
+	}
+	return 1 + (overflow+invoicePDFTimesheetsPerPage-1)/invoicePDFTimesheetsPerPage, nil
+}
+
+// MaxPDFPages returns the estimated page count above which printing an invoice asks for confirmation
+func MaxPDFPages(settings map[string]AppSettingValue) int {
+	if setting, exists := settings["invoice_max_pdf_pages"]; exists {
+		if val, err := setting.AsInt(); err == nil && val > 0 {
+			return val
+		}
+	}
+	return DefaultMaxPDFPages
+}
+
+// InvoiceDefaults decide how the form for a new invoice is filled in
+type InvoiceDefaults struct {
+	DisplayDetails bool
+	DateToday      bool
+	PaymentTerms   string
+	AutoAmount     bool
+}
+
+// NewInvoiceDefaults reads the new invoice defaults from the settings. Settings that are
+// missing or can't be read leave the form as it always was: dated today, without details,
+// terms or an amount.
+func NewInvoiceDefaults(settings map[string]AppSettingValue) InvoiceDefaults {
+	getBool := func(key string, fallback bool) bool {
+		if setting, exists := settings[key]; exists {
+			if val, err := setting.AsBool(); err == nil {
+				return val
+			}
+		}
+		return fallback
+	}
+	return InvoiceDefaults{
+		DisplayDetails: getBool("invoice_default_display_details", false),
+		DateToday:      getBool("invoice_default_date_today", true),
+		PaymentTerms:   strings.TrimSpace(settings["invoice_default_payment_terms"].AsString()),
+		AutoAmount:     getBool("invoice_default_amount_auto", false),
+	}
+}
+
+// UnbilledAmount totals the timesheet entries that are neither waiting for approval nor
+// billed on an invoice already, given the IDs of the billed ones
+func UnbilledAmount(timesheets []Timesheet, billed map[int]bool) float64 {
+	var amount float64
+	for _, timesheet := range timesheets {
+		if timesheet.PendingApproval || billed[timesheet.ID] {
+			continue
+		}
+		amount += timesheet.Amount()
+	}
+	return math.Round(amount*100) / 100
+}
+
 // getLogoDataURL reads the logo file and converts it to a base64 data URL
 func getLogoDataURL(logoPath string) (string, error) {
 	if logoPath == "" {
 		return "", nil
 	}
 
-	// Get absolute path from project root
-	_, filename, _, _ := runtime.Caller(0)
-	projectRoot := filepath.Dir(filepath.Dir(filepath.Dir(filename)))
-	fullPath := filepath.Join(projectRoot, logoPath)
-
-	// Check if file exists
-	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
-		return "", nil // Return empty string if file doesn't exist
+	// Relative paths are resolved from the working directory, falling back to the embedded
+	// assets so that the default logo is found wherever the server runs from
+	imageData, err := os.ReadFile(logoPath)
+	if err != nil && !filepath.IsAbs(logoPath) {
+		embeddedPath := strings.TrimPrefix(path.Clean(filepath.ToSlash(logoPath)), "ui/")
+		imageData, err = fs.ReadFile(ui.Files, embeddedPath)
 	}
-
-	// Read the image file
-	imageData, err := os.ReadFile(fullPath)
 	if err != nil {
-		return "", nil // Return empty string on error rather than failing
+		return "", nil // Return empty string if the file is missing or unreadable rather than failing
 	}
 
 	// Determine MIME type based on file extension
-	ext := filepath.Ext(fullPath)
+	ext := filepath.Ext(logoPath)
 	var mimeType string
 	switch strings.ToLower(ext) {
 	case ".png":
@@ -396,13 +1369,9 @@ func getLogoDataURL(logoPath string) (string, error) {
 	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64Data), nil
 }
 
-// GenerateHTMLPDF generates a PDF invoice using chromedp with HTML template
-func (i *InvoiceModel) GenerateHTMLPDF(id int, settings map[string]AppSettingValue) ([]byte, error) {
-	data, err := i.GetComprehensiveForPDF(id)
-	if err != nil {
-		return nil, err
-	}
-
+// NewInvoiceTemplateData prepares an invoice's data and the invoice settings for rendering
+// with the invoice template
+func NewInvoiceTemplateData(data ComprehensiveInvoiceData, settings map[string]AppSettingValue) InvoiceTemplateData {
 	// Helper to get setting value with fallback
 	getSetting := func(key, fallback string) string {
 		if setting, exists := settings[key]; exists {
@@ -427,18 +1396,33 @@ func (i *InvoiceModel) GenerateHTMLPDF(id int, settings map[string]AppSettingVal
 		avgRate = data.Invoice.AmountDue / data.TotalHours
 	}
 
+	// The invoice's fixed wording is printed in the client's language
+	labels := InvoiceLabelsFor(derefString(data.Client.InvoiceLanguage))
+
+	// Client credit applied to the invoice is set off against the total, never beyond it
+	creditApplied := min(max(data.Invoice.CreditApplied, 0), data.FinalTotal)
+	balanceDue := data.FinalTotal - creditApplied
+
 	// Prepare template data
 	templateData := InvoiceTemplateData{
-		Invoice:          data.Invoice,
-		Project:          data.Project,
-		Client:           data.Client,
-		Timesheets:       data.Timesheets,
-		TotalHours:       data.TotalHours,
-		AvgRate:          avgRate,
-		Subtotal:         data.Subtotal,
-		DiscountAmount:   data.DiscountAmount,
-		AdjustmentAmount: data.AdjustmentAmount,
-		FinalTotal:       data.FinalTotal,
+		Invoice:            data.Invoice,
+		Project:            data.Project,
+		Client:             data.Client,
+		BillTo:             data.Client.BillToLines(),
+		BudgetCode:         ResolveBudgetCode(data.Invoice, data.Project, data.Client),
+		Timesheets:         data.Timesheets,
+		TotalHours:         data.TotalHours,
+		AvgRate:            avgRate,
+		Subtotal:           data.Subtotal,
+		DiscountAmount:     data.DiscountAmount,
+		AdjustmentAmount:   data.AdjustmentAmount,
+		RoundingAdjustment: data.RoundingAdjustment,
+		FinalTotal:         data.FinalTotal,
+		CreditApplied:      creditApplied,
+		BalanceDue:         balanceDue,
+		Conversion:         NewCurrencyConversion(data.Invoice.Financials, getSetting("payment_domestic_currency", "USD"), balanceDue),
+		ProjectSubtotals:   data.ProjectSubtotals,
+		ClientApproval:     data.ClientApproval,
 		Settings: InvoiceTemplateSettings{
 			InvoiceTitle:             getSetting("invoice_title", "Invoice for Academic Editing"),
 			CompanyLogoPath:          getSetting("company_logo_path", "./ui/static/img/logo.png"),
@@ -449,10 +1433,38 @@ func (i *InvoiceModel) GenerateHTMLPDF(id int, settings map[string]AppSettingVal
 			FreelancerPhone:          getSetting("freelancer_phone", "Your Phone"),
 			FreelancerEmail:          getSetting("freelancer_email", "your.email@example.com"),
 			CurrencySymbol:           getSetting("invoice_currency_symbol", "$"),
+			DateFormat:               ParseDateFormat(getSetting("date_format", string(DateFormatISO))),
 			ShowIndividualTimesheets: getBoolSetting("invoice_show_individual_timesheets", true),
+			GroupByWorkType:          getBoolSetting("invoice_group_by_work_type", false),
 			DefaultPaymentTerms:      getSetting("invoice_payment_terms_default", "Payment is due within 30 days of receipt of this invoice."),
-			ThankYouMessage:          getSetting("invoice_thank_you_message", "Thank you for your business!"),
+			ThankYouMessage:          getSetting("invoice_thank_you_message", englishLabels.ThankYou),
+			PaymentInstructions:      NewPaymentInstructions(settings, data.Project.CurrencyDisplay),
+			PageHeaderFooter:         getBoolSetting("invoice_page_header_footer", true),
+			PageHeader:               getSetting("invoice_page_header", "Invoice #{invoice} · {client}"),
+			PageFooter:               getSetting("invoice_page_footer", "Page {page} of {pages}"),
 		},
+		Labels: labels,
+	}
+
+	// The stock thank-you message is translated too; one the user has written is printed as is
+	if templateData.Settings.ThankYouMessage == englishLabels.ThankYou {
+		templateData.Settings.ThankYouMessage = labels.ThankYou
+	}
+
+	// Values from the invoice's business profile take precedence over the global settings
+	if profile := data.BusinessProfile; profile != nil {
+		overrideSetting := func(target *string, value string) {
+			if value != "" {
+				*target = value
+			}
+		}
+		overrideSetting(&templateData.Settings.FreelancerName, profile.FreelancerName)
+		overrideSetting(&templateData.Settings.FreelancerAddress, profile.FreelancerAddress)
+		overrideSetting(&templateData.Settings.FreelancerCityStateZip, profile.FreelancerCityStateZip)
+		overrideSetting(&templateData.Settings.FreelancerPhone, profile.FreelancerPhone)
+		overrideSetting(&templateData.Settings.FreelancerEmail, profile.FreelancerEmail)
+		overrideSetting(&templateData.Settings.CompanyLogoPath, profile.LogoPath)
+		templateData.Settings.BankDetails = profile.BankDetails
 	}
 
 	// Convert logo path to base64 data URL if it exists
@@ -460,31 +1472,79 @@ func (i *InvoiceModel) GenerateHTMLPDF(id int, settings map[string]AppSettingVal
 		templateData.Settings.CompanyLogoDataURL = logoDataURL
 	}
 
-	// Create template with helper functions using embedded template
-	tmpl := template.New("invoice")
-	tmpl = tmpl.Funcs(template.FuncMap{
-		"split": strings.Split,
-		"mul": func(a, b float64) float64 {
-			return a * b
-		},
-		"safeURL": func(s string) template.URL {
-			return template.URL(s)
-		},
-		"isPositive": func(val float64) bool {
-			return val > 0
-		},
-		"isNonZero": func(val float64) bool {
-			return val != 0
-		},
-	})
+	if data.Snapshot != nil {
+		data.Snapshot.applyTo(&templateData)
+	}
+
+	return templateData
+}
+
+// GroupedByWorkType reports whether the invoice lists its timesheet entries under their work
+// types: the invoice_group_by_work_type setting is on and at least one entry has a work type
+func (d InvoiceTemplateData) GroupedByWorkType() bool {
+	if !d.Settings.GroupByWorkType {
+		return false
+	}
+	for _, timesheet := range d.Timesheets {
+		if timesheet.WorkType != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// TimesheetGroups returns the invoice's timesheet entries grouped by work type when
+// GroupedByWorkType, or all of them as a single group otherwise
+func (d InvoiceTemplateData) TimesheetGroups() []WorkTypeGroup {
+	if !d.GroupedByWorkType() {
+		return []WorkTypeGroup{{Timesheets: d.Timesheets}}
+	}
+	return GroupByWorkType(d.Timesheets)
+}
+
+// PageHeaderFooter returns the header and footer printed on every page of the invoice's PDF,
+// or nil when the invoice_page_header_footer setting turns them off
+func (d InvoiceTemplateData) PageHeaderFooter() *PDFHeaderFooter {
+	if !d.Settings.PageHeaderFooter {
+		return nil
+	}
+	replacer := strings.NewReplacer(
+		"{invoice}", html.EscapeString(d.Invoice.DisplayNumber()),
+		"{client}", html.EscapeString(d.Client.Name),
+		"{date}", html.EscapeString(d.Settings.DateFormat.Format(d.Invoice.InvoiceDate)),
+		"{page}", `<span class="pageNumber"></span>`,
+		"{pages}", `<span class="totalPages"></span>`,
+	)
+	return &PDFHeaderFooter{
+		Header: replacer.Replace(html.EscapeString(d.Settings.PageHeader)),
+		Footer: replacer.Replace(html.EscapeString(d.Settings.PageFooter)),
+	}
+}
+
+// invoiceTemplateFuncs are the functions available to the invoice template. Each is described
+// in InvoiceTemplateFuncs.
+var invoiceTemplateFuncs = template.FuncMap{
+	"split": strings.Split,
+	"mul": func(a, b float64) float64 {
+		return a * b
+	},
+	"safeURL": func(s string) template.URL {
+		return template.URL(s)
+	},
+	"isPositive": func(val float64) bool {
+		return val > 0
+	},
+	"isNonZero": func(val float64) bool {
+		return val != 0
+	},
+}
 
-	// Get the current file's directory to find project root
-	_, filename, _, _ := runtime.Caller(0)
-	projectRoot := filepath.Dir(filepath.Dir(filepath.Dir(filename))) // Go up 3 levels from internal/models
-	templatePath := filepath.Join(projectRoot, "ui", "html", "invoice.html")
+// RenderInvoiceHTML renders the embedded invoice template with the given data
+func RenderInvoiceHTML(templateData InvoiceTemplateData) ([]byte, error) {
+	tmpl := template.New("invoice").Funcs(invoiceTemplateFuncs)
 
-	// Read template file
-	templateBytes, err := os.ReadFile(templatePath)
+	// Read the template embedded in the binary
+	templateBytes, err := ui.Files.ReadFile("html/invoice.html")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read template file: %w", err)
 	}
@@ -500,61 +1560,54 @@ func (i *InvoiceModel) GenerateHTMLPDF(id int, settings map[string]AppSettingVal
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute template: %w", err)
 	}
+	return htmlBuffer.Bytes(), nil
+}
 
-	// Debug: Write HTML to file for inspection
-	if os.Getenv("DEBUG_HTML") == "1" {
-		os.WriteFile("/tmp/debug_invoice.html", htmlBuffer.Bytes(), 0644)
+// GenerateHTML renders an invoice as the HTML page its PDF is printed from, for viewing or
+// printing in a browser. Like the PDF, it keeps the invoice's first printing.
+func (i *InvoiceModel) GenerateHTML(id int, settings map[string]AppSettingValue) ([]byte, error) {
+	templateData, err := i.printData(id, settings)
+	if err != nil {
+		return nil, err
 	}
+	return RenderInvoiceHTML(templateData)
+}
 
-	// Create context for chromedp
-	ctx, cancel := chromedp.NewContext(context.Background())
-	defer cancel()
-
-	// Generate PDF using chromedp with temporary file approach
-	var pdfBytes []byte
-
-	// Write HTML to temporary file to avoid URL encoding issues
-	tmpFile, err := os.CreateTemp("", "invoice_*.html")
+// GenerateHTMLPDF generates a PDF invoice using chromedp with HTML template
+func (i *InvoiceModel) GenerateHTMLPDF(id int, settings map[string]AppSettingValue) ([]byte, error) {
+	templateData, err := i.printData(id, settings)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
+		return nil, err
 	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
 
-	_, err = tmpFile.Write(htmlBuffer.Bytes())
+	html, err := RenderInvoiceHTML(templateData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to write temp file: %w", err)
+		return nil, err
 	}
-	tmpFile.Close()
 
-	// Use file:// URL instead of data URI
-	fileURL := "file://" + tmpFile.Name()
+	// Debug: Write HTML to file for inspection
+	if os.Getenv("DEBUG_HTML") == "1" {
+		os.WriteFile("/tmp/debug_invoice.html", html, 0644)
+	}
 
-	err = chromedp.Run(ctx,
-		chromedp.Navigate(fileURL),
-		chromedp.WaitReady("body", chromedp.ByQuery),
-		chromedp.Sleep(2*time.Second), // Give more time for rendering
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			var err error
-			pdfBytes, _, err = page.PrintToPDF().
-				WithPrintBackground(true). // Enable background printing
-				WithPaperWidth(8.27).      // A4 width in inches
-				WithPaperHeight(11.7).     // A4 height in inches
-				WithMarginTop(0.79).       // 20mm in inches
-				WithMarginBottom(0.79).
-				WithMarginLeft(0.79).
-				WithMarginRight(0.79).
-				WithDisplayHeaderFooter(false).
-				WithScale(1.0). // Ensure proper scaling
-				Do(ctx)
-			return err
-		}),
-	)
+	return RenderPDFWithHeaderFooter(html, templateData.PageHeaderFooter())
+}
+
+// printData prepares an invoice for printing. The first time it is printed, who it is to and
+// from and the logo are kept, and every later printing shows those.
+func (i *InvoiceModel) printData(id int, settings map[string]AppSettingValue) (InvoiceTemplateData, error) {
+	data, err := i.GetComprehensiveForPDF(id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+		return InvoiceTemplateData{}, err
 	}
 
-	return pdfBytes, nil
+	templateData := NewInvoiceTemplateData(data, settings)
+	if data.Snapshot == nil {
+		if err := i.saveSnapshot(NewInvoiceSnapshot(templateData)); err != nil {
+			return InvoiceTemplateData{}, fmt.Errorf("failed to save invoice snapshot: %w", err)
+		}
+	}
+	return templateData, nil
 }
 
 // InvoiceModelInterface defines the interface for invoice operations
@@ -562,11 +1615,31 @@ type InvoiceModelInterface interface {
 	Insert(projectID int, invoiceDate time.Time, datePaid *time.Time, paymentTerms string, amountDue float64, displayDetails bool) (int, error)
 	Get(id int) (Invoice, error)
 	GetByProject(projectID int) ([]Invoice, error)
+	GetByProjectWithPagination(projectID int, limit, offset int64) ([]Invoice, error)
+	GetCountByProject(projectID int) (int64, error)
 	Update(id int, invoiceDate time.Time, datePaid *time.Time, paymentTerms string, amountDue float64, displayDetails bool) error
+	SetInvoiceNumber(id int, invoiceNumber string) error
+	SetPayment(id int, amountPaid *float64, creditApplied float64) error
+	SetDueDate(id int, dueDate *time.Time) error
+	SetCCEmail(id int, ccEmail string) error
+	SetBudgetCode(id int, budgetCode string) error
+	SetPDFSignature(id int, signedBy string, signedAt time.Time) error
+	SetFinancials(id int, financials InvoiceFinancials) error
+	SetTimesheetRange(id int, from, to *time.Time) error
+	FlagTimesheetsChanged(id int) error
+	NumberExists(invoiceNumber string) (bool, error)
 	Delete(id int) error
+	Void(id int, reason string) error
+	MarkPaid(id int, datePaid time.Time) error
+	GetOpen() ([]InvoiceWithClient, error)
+	ExistsSimilar(projectID int, amountDue float64, invoiceDate time.Time) (bool, error)
+	GetWithClient(id int) (InvoiceWithClient, error)
+	GetWithClientAfter(afterID, limit int) ([]InvoiceWithClient, error)
 	GetComprehensiveForPDF(id int) (ComprehensiveInvoiceData, error)
 	GenerateComprehensivePDF(id int, settings map[string]AppSettingValue) ([]byte, error)
+	EstimatePDFPages(id int, settings map[string]AppSettingValue) (int, error)
 	GenerateHTMLPDF(id int, settings map[string]AppSettingValue) ([]byte, error)
+	GenerateHTML(id int, settings map[string]AppSettingValue) ([]byte, error)
 }
 
 // Ensure implementation satisfies the interface
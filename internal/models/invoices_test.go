@@ -1,6 +1,10 @@
 package models
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -274,6 +278,38 @@ func TestInvoiceModel_GetByProject(t *testing.T) {
 	})
 }
 
+func TestInvoiceModel_GetByProjectWithPagination(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Test Client")
+	projectID := testDB.InsertTestProject(t, "Long Project", clientID)
+	otherProjectID := testDB.InsertTestProject(t, "Other Project", clientID)
+	for month := 1; month <= 3; month++ {
+		testDB.InsertTestInvoice(t, projectID, fmt.Sprintf("2024-%02d-28", month), "", "Net 30", fmt.Sprintf("%d00.00", month))
+	}
+	deletedID := testDB.InsertTestInvoice(t, projectID, "2024-04-28", "", "Net 30", "400.00")
+	require.NoError(t, model.Delete(deletedID))
+	testDB.InsertTestInvoice(t, otherProjectID, "2024-02-28", "", "Net 30", "900.00")
+
+	first, err := model.GetByProjectWithPagination(projectID, 2, 0)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	assert.Equal(t, 300.0, first[0].AmountDue)
+	assert.Equal(t, 200.0, first[1].AmountDue)
+
+	second, err := model.GetByProjectWithPagination(projectID, 2, 2)
+	require.NoError(t, err)
+	require.Len(t, second, 1)
+	assert.Equal(t, 100.0, second[0].AmountDue)
+
+	count, err := model.GetCountByProject(projectID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
 func TestInvoiceModel_Update(t *testing.T) {
 	// Setup test database
 	testDB := testutil.SetupTestSQLite(t)
@@ -352,6 +388,32 @@ func TestInvoiceModel_Update(t *testing.T) {
 		assert.Equal(t, newAmountDue, invoice.AmountDue)
 	})
 
+	t.Run("paid invoice keeps its date and amount", func(t *testing.T) {
+		testDB.TruncateTable(t, "invoice")
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+
+		clientID := testDB.InsertTestClient(t, "Test Client")
+		projectID := testDB.InsertTestProject(t, "Test Project", clientID)
+		id := testDB.InsertTestInvoice(t, projectID, "2024-01-15", "2024-01-25", "Net 30", "1250.00")
+
+		invoiceDate := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+		datePaid := time.Date(2024, 1, 25, 0, 0, 0, 0, time.UTC)
+		err := model.Update(id, invoiceDate, &datePaid, "Net 30", 950.00, false)
+		assert.Equal(t, ErrInvoicePaid, err)
+		err = model.Update(id, invoiceDate.AddDate(0, 0, 1), &datePaid, "Net 30", 1250.00, false)
+		assert.Equal(t, ErrInvoicePaid, err)
+
+		// Other details can change while the invoice stays paid
+		err = model.Update(id, invoiceDate, &datePaid, "Net 15", 1250.00, true)
+		require.NoError(t, err)
+
+		invoice, err := model.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, 1250.00, invoice.AmountDue)
+		assert.Equal(t, "Net 15", invoice.PaymentTerms)
+	})
+
 	t.Run("update non-existent invoice", func(t *testing.T) {
 		testDB.TruncateTable(t, "invoice")
 
@@ -481,6 +543,853 @@ func TestInvoiceModel_Delete(t *testing.T) {
 	})
 }
 
+func TestInvoiceModel_Void(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceModel(testDB.DB)
+
+	t.Run("void existing invoice", func(t *testing.T) {
+		testDB.TruncateTable(t, "invoice")
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+
+		clientID := testDB.InsertTestClient(t, "Test Client")
+		projectID := testDB.InsertTestProject(t, "Test Project", clientID)
+		id := testDB.InsertTestInvoice(t, projectID, "2024-01-15", "", "Net 30", "500.00")
+
+		err := model.Void(id, "Issued to the wrong client")
+		require.NoError(t, err)
+
+		// Voided invoices remain visible with their reason
+		invoice, err := model.Get(id)
+		require.NoError(t, err)
+		assert.True(t, invoice.IsVoided())
+		assert.Equal(t, "Issued to the wrong client", invoice.VoidReason)
+
+		invoices, err := model.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, invoices, 1)
+		assert.True(t, invoices[0].IsVoided())
+	})
+
+	t.Run("void already voided invoice", func(t *testing.T) {
+		testDB.TruncateTable(t, "invoice")
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+
+		clientID := testDB.InsertTestClient(t, "Test Client")
+		projectID := testDB.InsertTestProject(t, "Test Project", clientID)
+		id := testDB.InsertTestInvoice(t, projectID, "2024-01-15", "", "Net 30", "500.00")
+
+		require.NoError(t, model.Void(id, "First reason"))
+
+		err := model.Void(id, "Second reason")
+		assert.Equal(t, ErrInvoiceVoided, err)
+
+		invoice, err := model.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, "First reason", invoice.VoidReason)
+	})
+
+	t.Run("void non-existent invoice", func(t *testing.T) {
+		testDB.TruncateTable(t, "invoice")
+
+		err := model.Void(999, "Reason")
+		assert.Equal(t, ErrNoRecord, err)
+	})
+
+	t.Run("voided invoice cannot be updated", func(t *testing.T) {
+		testDB.TruncateTable(t, "invoice")
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+
+		clientID := testDB.InsertTestClient(t, "Test Client")
+		projectID := testDB.InsertTestProject(t, "Test Project", clientID)
+		id := testDB.InsertTestInvoice(t, projectID, "2024-01-15", "", "Net 30", "500.00")
+		require.NoError(t, model.Void(id, "Duplicate"))
+
+		err := model.Update(id, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), nil, "Net 15", 999.0, true)
+		require.NoError(t, err)
+
+		invoice, err := model.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, 500.0, invoice.AmountDue)
+		assert.Equal(t, "Net 30", invoice.PaymentTerms)
+	})
+}
+
+func TestInvoiceModel_MarkPaid(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceModel(testDB.DB)
+	datePaid := time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("open invoices are listed and marked paid once", func(t *testing.T) {
+		testDB.TruncateTable(t, "invoice")
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+
+		clientID := testDB.InsertTestClient(t, "Test Client")
+		projectID := testDB.InsertTestProject(t, "Test Project", clientID)
+		later := testDB.InsertTestInvoice(t, projectID, "2024-01-20", "", "Net 30", "300.00")
+		earlier := testDB.InsertTestInvoice(t, projectID, "2024-01-15", "", "Net 30", "500.00")
+		testDB.InsertTestInvoice(t, projectID, "2024-01-10", "2024-01-30", "Net 30", "100.00")
+		voided := testDB.InsertTestInvoice(t, projectID, "2024-01-05", "", "Net 30", "200.00")
+		require.NoError(t, model.Void(voided, "Issued twice"))
+
+		open, err := model.GetOpen()
+		require.NoError(t, err)
+		require.Len(t, open, 2)
+		assert.Equal(t, earlier, open[0].ID)
+		assert.Equal(t, later, open[1].ID)
+		assert.Equal(t, "Test Client", open[0].ClientName)
+
+		require.NoError(t, model.MarkPaid(earlier, datePaid))
+		invoice, err := model.Get(earlier)
+		require.NoError(t, err)
+		require.NotNil(t, invoice.DatePaid)
+		assert.Equal(t, "2024-02-10", invoice.DatePaid.Format("2006-01-02"))
+
+		assert.Equal(t, ErrInvoicePaid, model.MarkPaid(earlier, datePaid))
+		assert.Equal(t, ErrInvoiceVoided, model.MarkPaid(voided, datePaid))
+		assert.Equal(t, ErrNoRecord, model.MarkPaid(999, datePaid))
+
+		open, err = model.GetOpen()
+		require.NoError(t, err)
+		require.Len(t, open, 1)
+		assert.Equal(t, later, open[0].ID)
+	})
+}
+
+func TestInvoiceModel_DateOnlyFields(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceModel(testDB.DB)
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	clientID := testDB.InsertTestClient(t, "Test Client")
+	projectID := testDB.InsertTestProject(t, "Test Project", clientID)
+
+	t.Run("dates are stored as the calendar day they were given on", func(t *testing.T) {
+		// Midnight on the day clocks go forward, and late evening on the day they go back,
+		// which is already the next day in UTC
+		invoiceDate := time.Date(2024, 3, 10, 0, 0, 0, 0, newYork)
+		datePaid := time.Date(2024, 11, 3, 23, 30, 0, 0, newYork)
+		id, err := model.Insert(projectID, invoiceDate, &datePaid, "Net 30", 100.0, false)
+		require.NoError(t, err)
+
+		var storedDate, storedPaid string
+		var julian *float64
+		err = testDB.DB.QueryRow("SELECT invoice_date || '', date_paid || '', julianday(invoice_date) FROM invoice WHERE id = ?", id).Scan(&storedDate, &storedPaid, &julian)
+		require.NoError(t, err)
+		assert.Equal(t, "2024-03-10", storedDate)
+		assert.Equal(t, "2024-11-03", storedPaid)
+		assert.NotNil(t, julian, "SQLite's date functions can read the stored date")
+
+		invoice, err := model.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), invoice.InvoiceDate)
+		require.NotNil(t, invoice.DatePaid)
+		assert.Equal(t, time.Date(2024, 11, 3, 0, 0, 0, 0, time.UTC), *invoice.DatePaid)
+	})
+
+	t.Run("due dates and timesheet ranges are stored as calendar days", func(t *testing.T) {
+		id, err := model.Insert(projectID, time.Date(2024, 10, 10, 0, 0, 0, 0, time.UTC), nil, "Net 30", 100.0, false)
+		require.NoError(t, err)
+
+		dueDate := time.Date(2024, 11, 3, 23, 30, 0, 0, newYork)
+		from := time.Date(2024, 10, 1, 0, 0, 0, 0, newYork)
+		to := time.Date(2024, 10, 31, 0, 0, 0, 0, newYork)
+		require.NoError(t, model.SetDueDate(id, &dueDate))
+		require.NoError(t, model.SetTimesheetRange(id, &from, &to))
+
+		var storedDue, storedFrom, storedTo string
+		err = testDB.DB.QueryRow("SELECT due_date || '', timesheets_from || '', timesheets_to || '' FROM invoice WHERE id = ?", id).Scan(&storedDue, &storedFrom, &storedTo)
+		require.NoError(t, err)
+		assert.Equal(t, "2024-11-03", storedDue)
+		assert.Equal(t, "2024-10-01", storedFrom)
+		assert.Equal(t, "2024-10-31", storedTo)
+
+		invoice, err := model.Get(id)
+		require.NoError(t, err)
+		require.NotNil(t, invoice.DueDate)
+		assert.Equal(t, time.Date(2024, 11, 3, 0, 0, 0, 0, time.UTC), *invoice.DueDate)
+		require.NotNil(t, invoice.TimesheetsFrom)
+		assert.Equal(t, time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), *invoice.TimesheetsFrom)
+		require.NotNil(t, invoice.TimesheetsTo)
+		assert.Equal(t, time.Date(2024, 10, 31, 0, 0, 0, 0, time.UTC), *invoice.TimesheetsTo)
+
+		require.NoError(t, model.Delete(id))
+	})
+
+	t.Run("timestamps written before keep their calendar day", func(t *testing.T) {
+		result, err := testDB.DB.Exec("INSERT INTO invoice (project_id, invoice_date, date_paid, payment_terms, amount_due) VALUES (?, ?, ?, ?, ?)",
+			projectID, "2024-03-31 00:00:00 -0400 EDT", "2024-04-20 23:30:00 -0400 EDT", "Net 30", 40.0)
+		require.NoError(t, err)
+		id, err := result.LastInsertId()
+		require.NoError(t, err)
+
+		invoice, err := model.Get(int(id))
+		require.NoError(t, err)
+		assert.Equal(t, "2024-03-31", invoice.InvoiceDate.Format("2006-01-02"))
+		require.NotNil(t, invoice.DatePaid)
+		assert.Equal(t, "2024-04-20", invoice.DatePaid.Format("2006-01-02"))
+
+		behavior, err := NewClientModel(testDB.DB).PaymentBehavior(clientID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, behavior.PaidInvoices)
+	})
+}
+
+func TestInvoiceModel_ExistsSimilar(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Test Client")
+	projectID := testDB.InsertTestProject(t, "Test Project", clientID)
+	otherProjectID := testDB.InsertTestProject(t, "Other Project", clientID)
+	_, err := model.Insert(projectID, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), nil, "Net 30", 500.00, false)
+	require.NoError(t, err)
+	voidedID := testDB.InsertTestInvoice(t, projectID, "2024-06-10", "", "Net 30", "750.00")
+	require.NoError(t, model.Void(voidedID, "Duplicate"))
+
+	tests := []struct {
+		name        string
+		projectID   int
+		amountDue   float64
+		invoiceDate time.Time
+		expected    bool
+	}{
+		{"same day", projectID, 500.00, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), true},
+		{"seven days later", projectID, 500.00, time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC), true},
+		{"seven days earlier", projectID, 500.00, time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC), true},
+		{"eight days later", projectID, 500.00, time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC), false},
+		{"different amount", projectID, 500.50, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), false},
+		{"different project", otherProjectID, 500.00, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), false},
+		{"voided invoice", projectID, 750.00, time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			similar, err := model.ExistsSimilar(tt.projectID, tt.amountDue, tt.invoiceDate)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, similar)
+		})
+	}
+}
+
+func TestInvoiceModel_NumberAndDueDate(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Test Client")
+	projectID := testDB.InsertTestProject(t, "Test Project", clientID)
+	id, err := model.Insert(projectID, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), nil, "Net 30", 500.00, false)
+	require.NoError(t, err)
+
+	exists, err := model.NumberExists("INV-0001")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, model.SetInvoiceNumber(id, "INV-0001"))
+	exists, err = model.NumberExists("INV-0001")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	invoice, err := model.Get(id)
+	require.NoError(t, err)
+	assert.Nil(t, invoice.DueDate)
+
+	dueDate := time.Date(2024, 4, 9, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, model.SetDueDate(id, &dueDate))
+	invoice, err = model.Get(id)
+	require.NoError(t, err)
+	require.NotNil(t, invoice.DueDate)
+	assert.True(t, dueDate.Equal(*invoice.DueDate))
+
+	require.NoError(t, model.Delete(id))
+	exists, err = model.NumberExists("INV-0001")
+	require.NoError(t, err)
+	assert.False(t, exists, "deleted invoices free up their number")
+}
+
+func TestInvoiceModel_SetCCEmail(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Test Client")
+	projectID := testDB.InsertTestProject(t, "Test Project", clientID)
+	id, err := model.Insert(projectID, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), nil, "Net 30", 500.00, false)
+	require.NoError(t, err)
+
+	require.NoError(t, model.SetCCEmail(id, "accounts@example.com"))
+	invoice, err := model.Get(id)
+	require.NoError(t, err)
+	assert.Equal(t, "accounts@example.com", invoice.CCEmail)
+
+	invoices, err := model.GetByProject(projectID)
+	require.NoError(t, err)
+	require.Len(t, invoices, 1)
+	assert.Equal(t, "accounts@example.com", invoices[0].CCEmail)
+
+	require.NoError(t, model.SetCCEmail(id, ""))
+	invoice, err = model.Get(id)
+	require.NoError(t, err)
+	assert.Empty(t, invoice.CCEmail)
+}
+
+func TestInvoiceModel_BudgetCode(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceModel(testDB.DB)
+	clients := NewClientModel(testDB.DB)
+	projects := NewProjectModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "State University")
+	projectID := testDB.InsertTestProject(t, "Grant Report", clientID)
+	id, err := model.Insert(projectID, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), nil, "Net 30", 500.00, false)
+	require.NoError(t, err)
+
+	printedCode := func(t *testing.T) (string, string) {
+		data, err := model.GetComprehensiveForPDF(id)
+		require.NoError(t, err)
+		templateData := NewInvoiceTemplateData(data, nil)
+		html, err := RenderInvoiceHTML(templateData)
+		require.NoError(t, err)
+		return templateData.BudgetCode, string(html)
+	}
+
+	t.Run("nothing is printed without a code", func(t *testing.T) {
+		code, html := printedCode(t)
+		assert.Empty(t, code)
+		assert.NotContains(t, html, "Budget Code:")
+	})
+
+	t.Run("the client's code", func(t *testing.T) {
+		client, err := clients.Get(clientID)
+		require.NoError(t, err)
+		clientCode := "CC-1001"
+		client.BudgetCode = &clientCode
+		require.NoError(t, clients.Update(client))
+
+		client, err = clients.Get(clientID)
+		require.NoError(t, err)
+		require.NotNil(t, client.BudgetCode)
+		assert.Equal(t, "CC-1001", *client.BudgetCode)
+
+		code, html := printedCode(t)
+		assert.Equal(t, "CC-1001", code)
+		assert.Contains(t, html, "Budget Code:</span>")
+		assert.Contains(t, html, "CC-1001")
+	})
+
+	t.Run("the project's code in place of the client's", func(t *testing.T) {
+		project, err := projects.Get(projectID)
+		require.NoError(t, err)
+		project.BudgetCode = "GR-2024-07"
+		require.NoError(t, projects.Update(project))
+
+		byClient, err := projects.GetByClient(clientID)
+		require.NoError(t, err)
+		require.Len(t, byClient, 1)
+		assert.Equal(t, "GR-2024-07", byClient[0].BudgetCode)
+
+		code, _ := printedCode(t)
+		assert.Equal(t, "GR-2024-07", code)
+	})
+
+	t.Run("the invoice's own code in place of both", func(t *testing.T) {
+		require.NoError(t, model.SetBudgetCode(id, "ACCT-55120"))
+		invoices, err := model.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, invoices, 1)
+		assert.Equal(t, "ACCT-55120", invoices[0].BudgetCode)
+
+		code, _ := printedCode(t)
+		assert.Equal(t, "ACCT-55120", code)
+	})
+
+	t.Run("clearing the override falls back to the project", func(t *testing.T) {
+		require.NoError(t, model.SetBudgetCode(id, ""))
+		invoice, err := model.Get(id)
+		require.NoError(t, err)
+		assert.Empty(t, invoice.BudgetCode)
+
+		code, _ := printedCode(t)
+		assert.Equal(t, "GR-2024-07", code)
+	})
+}
+
+func TestInvoiceModel_SetPDFSignature(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Test Client")
+	projectID := testDB.InsertTestProject(t, "Test Project", clientID)
+	id, err := model.Insert(projectID, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), nil, "Net 30", 500.00, false)
+	require.NoError(t, err)
+
+	invoice, err := model.Get(id)
+	require.NoError(t, err)
+	assert.Nil(t, invoice.PDFSignedAt)
+	assert.Empty(t, invoice.PDFSignedBy)
+
+	signedAt := time.Date(2024, 3, 11, 14, 30, 0, 0, time.UTC)
+	require.NoError(t, model.SetPDFSignature(id, "Jane Doe", signedAt))
+	invoice, err = model.Get(id)
+	require.NoError(t, err)
+	require.NotNil(t, invoice.PDFSignedAt)
+	assert.True(t, signedAt.Equal(*invoice.PDFSignedAt))
+	assert.Equal(t, "Jane Doe", invoice.PDFSignedBy)
+
+	invoices, err := model.GetByProject(projectID)
+	require.NoError(t, err)
+	require.Len(t, invoices, 1)
+	assert.Equal(t, "Jane Doe", invoices[0].PDFSignedBy)
+}
+
+func TestResolveInvoiceCC(t *testing.T) {
+	clientEmail, clientDescription := "client-cc@example.com", "Client's accountant"
+	client := Client{InvoiceCCEmail: &clientEmail, InvoiceCCDescription: &clientDescription}
+	project := Project{InvoiceCCEmail: "project-cc@example.com", InvoiceCCDescription: "Grant office"}
+
+	tests := []struct {
+		name     string
+		invoice  Invoice
+		project  Project
+		client   Client
+		expected InvoiceCC
+	}{
+		{"invoice override wins", Invoice{CCEmail: "once@example.com"}, project, client, InvoiceCC{Email: "once@example.com", Source: "invoice"}},
+		{"project before client", Invoice{}, project, client, InvoiceCC{Email: "project-cc@example.com", Description: "Grant office", Source: "project"}},
+		{"client as a last resort", Invoice{}, Project{}, client, InvoiceCC{Email: "client-cc@example.com", Description: "Client's accountant", Source: "client"}},
+		{"nobody", Invoice{}, Project{}, Client{}, InvoiceCC{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ResolveInvoiceCC(tt.invoice, tt.project, tt.client))
+		})
+	}
+}
+
+func TestResolveBudgetCode(t *testing.T) {
+	clientCode := "CC-1001"
+	client := Client{BudgetCode: &clientCode}
+	project := Project{BudgetCode: "GR-2024-07"}
+
+	tests := []struct {
+		name     string
+		invoice  Invoice
+		project  Project
+		client   Client
+		expected string
+	}{
+		{"invoice override wins", Invoice{BudgetCode: "ACCT-55120"}, project, client, "ACCT-55120"},
+		{"project before client", Invoice{}, project, client, "GR-2024-07"},
+		{"client as a last resort", Invoice{}, Project{}, client, "CC-1001"},
+		{"none", Invoice{}, Project{}, Client{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ResolveBudgetCode(tt.invoice, tt.project, tt.client))
+		})
+	}
+}
+
+func TestInvoiceModel_TimesheetRange(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceModel(testDB.DB)
+	timesheetModel := NewTimesheetModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Test Client")
+	projectID := testDB.InsertTestProject(t, "Long Project", clientID)
+	id, err := model.Insert(projectID, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), nil, "Net 30", 300.00, true)
+	require.NoError(t, err)
+
+	for _, month := range []time.Month{time.January, time.February, time.March} {
+		_, err := timesheetModel.Insert(projectID, time.Date(2024, month, 15, 0, 0, 0, 0, time.UTC), 2, 50, month.String())
+		require.NoError(t, err)
+	}
+
+	t.Run("without a range every timesheet is included", func(t *testing.T) {
+		data, err := model.GetComprehensiveForPDF(id)
+		require.NoError(t, err)
+		assert.False(t, data.Invoice.HasTimesheetRange())
+		assert.Len(t, data.Timesheets, 3)
+		assert.Equal(t, 6.0, data.TotalHours)
+	})
+
+	t.Run("a range limits the timesheets and hours", func(t *testing.T) {
+		from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, model.SetTimesheetRange(id, &from, &to))
+
+		invoice, err := model.Get(id)
+		require.NoError(t, err)
+		require.NotNil(t, invoice.TimesheetsFrom)
+		require.NotNil(t, invoice.TimesheetsTo)
+		assert.True(t, from.Equal(*invoice.TimesheetsFrom))
+		assert.True(t, to.Equal(*invoice.TimesheetsTo))
+
+		data, err := model.GetComprehensiveForPDF(id)
+		require.NoError(t, err)
+		require.Len(t, data.Timesheets, 2)
+		assert.Equal(t, "March", data.Timesheets[0].Description)
+		assert.Equal(t, "February", data.Timesheets[1].Description)
+		assert.Equal(t, 4.0, data.TotalHours)
+	})
+
+	t.Run("an open ended range", func(t *testing.T) {
+		to := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, model.SetTimesheetRange(id, nil, &to))
+
+		data, err := model.GetComprehensiveForPDF(id)
+		require.NoError(t, err)
+		require.Len(t, data.Timesheets, 1)
+		assert.Equal(t, "January", data.Timesheets[0].Description)
+	})
+
+	t.Run("clearing the range", func(t *testing.T) {
+		require.NoError(t, model.SetTimesheetRange(id, nil, nil))
+
+		invoice, err := model.Get(id)
+		require.NoError(t, err)
+		assert.Nil(t, invoice.TimesheetsFrom)
+		assert.Nil(t, invoice.TimesheetsTo)
+	})
+}
+
+func TestInvoiceModel_ClientApproval(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceModel(testDB.DB)
+	approvals := NewClientApprovalModel(testDB.DB)
+
+	projectID := testDB.InsertTestProject(t, "Thesis", testDB.InsertTestClient(t, "Test Client"))
+	testDB.InsertTestTimesheet(t, projectID, "2024-04-08", "2", "50.00", "Chapter 1")
+	testDB.InsertTestTimesheet(t, projectID, "2024-05-06", "1", "50.00", "Chapter 2")
+	id, err := model.Insert(projectID, time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC), nil, "Net 30", 150.00, true)
+	require.NoError(t, err)
+
+	aprilID, err := approvals.Insert(projectID, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.NoError(t, approvals.Approve(aprilID, "Jane Doe", "203.0.113.7", time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)))
+
+	t.Run("time only partly approved isn't noted", func(t *testing.T) {
+		data, err := model.GetComprehensiveForPDF(id)
+		require.NoError(t, err)
+		assert.Nil(t, data.ClientApproval)
+	})
+
+	t.Run("an approval covering all the time listed is noted on the invoice", func(t *testing.T) {
+		from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, model.SetTimesheetRange(id, &from, &to))
+
+		data, err := model.GetComprehensiveForPDF(id)
+		require.NoError(t, err)
+		require.NotNil(t, data.ClientApproval)
+		assert.Equal(t, aprilID, data.ClientApproval.ID)
+
+		html, err := RenderInvoiceHTML(NewInvoiceTemplateData(data, nil))
+		require.NoError(t, err)
+		assert.Contains(t, string(html), "Time approved by client on 2024-05-02 (Jane Doe)")
+	})
+}
+
+func TestInvoiceModel_GetComprehensiveForPDFRounding(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceModel(testDB.DB)
+	settingModel := NewAppSettingModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Test Client")
+	projectID := testDB.InsertTestProject(t, "Rounded Project", clientID)
+	id, err := model.Insert(projectID, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), nil, "Net 30", 123.46, false)
+	require.NoError(t, err)
+
+	t.Run("cent rounding leaves whole cents alone", func(t *testing.T) {
+		data, err := model.GetComprehensiveForPDF(id)
+		require.NoError(t, err)
+		assert.Equal(t, 123.46, data.FinalTotal)
+		assert.Zero(t, data.RoundingAdjustment)
+	})
+
+	t.Run("rounding to whole units adds an adjustment", func(t *testing.T) {
+		require.NoError(t, settingModel.UpdateValue("invoice_rounding_increment", "1.00"))
+
+		data, err := model.GetComprehensiveForPDF(id)
+		require.NoError(t, err)
+		assert.Equal(t, 123.0, data.FinalTotal)
+		assert.Equal(t, -0.46, data.RoundingAdjustment)
+		assert.Equal(t, 123.46, data.Subtotal)
+	})
+}
+
+func TestInvoiceModel_Financials(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceModel(testDB.DB)
+	projectModel := NewProjectModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Test Client")
+	projectID := testDB.InsertTestProject(t, "Discounted Project", clientID)
+	project, err := projectModel.Get(projectID)
+	require.NoError(t, err)
+	discount := 10.0
+	project.DiscountPercent = &discount
+	project.DiscountReason = "Loyalty"
+	project.CurrencyDisplay = "EUR"
+	require.NoError(t, projectModel.Update(project))
+
+	id, err := model.Insert(projectID, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), nil, "Net 30", 200.00, false)
+	require.NoError(t, err)
+
+	t.Run("invoices without captured financials use the project's", func(t *testing.T) {
+		invoice, err := model.Get(id)
+		require.NoError(t, err)
+		assert.Nil(t, invoice.Financials)
+
+		data, err := model.GetComprehensiveForPDF(id)
+		require.NoError(t, err)
+		assert.Equal(t, 20.0, data.DiscountAmount)
+	})
+
+	require.NoError(t, model.SetFinancials(id, FinancialsFromProject(project)))
+
+	t.Run("later project edits don't change the invoice", func(t *testing.T) {
+		newDiscount := 50.0
+		adjustment := 25.0
+		project.DiscountPercent = &newDiscount
+		project.DiscountReason = "Clearance"
+		project.AdjustmentAmount = &adjustment
+		project.CurrencyDisplay = "USD"
+		require.NoError(t, projectModel.Update(project))
+
+		invoice, err := model.Get(id)
+		require.NoError(t, err)
+		require.NotNil(t, invoice.Financials)
+		require.NotNil(t, invoice.Financials.DiscountPercent)
+		assert.Equal(t, 10.0, *invoice.Financials.DiscountPercent)
+		assert.Nil(t, invoice.Financials.AdjustmentAmount)
+
+		data, err := model.GetComprehensiveForPDF(id)
+		require.NoError(t, err)
+		assert.Equal(t, 20.0, data.DiscountAmount)
+		assert.Equal(t, 0.0, data.AdjustmentAmount)
+		assert.Equal(t, 180.0, data.FinalTotal)
+		assert.Equal(t, "Loyalty", data.Project.DiscountReason)
+		assert.Equal(t, "EUR", data.Project.CurrencyDisplay)
+	})
+
+	t.Run("no conversion is recorded at a rate of 1", func(t *testing.T) {
+		invoice, err := model.Get(id)
+		require.NoError(t, err)
+		assert.Empty(t, invoice.Financials.RateSource)
+		assert.Nil(t, invoice.Financials.ConvertedAt)
+	})
+
+	t.Run("converted invoices record the rate, its source and when it was applied", func(t *testing.T) {
+		project.CurrencyDisplay = "EUR"
+		project.CurrencyConversionRate = 0.92
+		require.NoError(t, projectModel.Update(project))
+
+		convertedID, err := model.Insert(projectID, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), nil, "Net 30", 100.00, false)
+		require.NoError(t, err)
+		before := time.Now().UTC().Add(-time.Second)
+		require.NoError(t, model.SetFinancials(convertedID, FinancialsFromProject(project)))
+
+		invoice, err := model.Get(convertedID)
+		require.NoError(t, err)
+		require.NotNil(t, invoice.Financials)
+		assert.Equal(t, 0.92, invoice.Financials.CurrencyConversionRate)
+		assert.Equal(t, ConversionRateSourceProject, invoice.Financials.RateSource)
+		require.NotNil(t, invoice.Financials.ConvertedAt)
+		assert.False(t, invoice.Financials.ConvertedAt.Before(before))
+	})
+
+	t.Run("a fixed discount can't be more than the amount due", func(t *testing.T) {
+		terms := FinancialsFromProject(project)
+		amount := 200.01
+		terms.SetDiscount(nil, &amount, "Referral")
+		assert.ErrorIs(t, model.SetFinancials(id, terms), ErrDiscountTooLarge)
+
+		amount = 200.00
+		assert.NoError(t, model.SetFinancials(id, terms))
+	})
+}
+
+func TestInvoiceFinancials_Discount(t *testing.T) {
+	percent := 10.0
+	amount := 15.0
+
+	assert.Equal(t, 0.0, InvoiceFinancials{}.Discount(200))
+	assert.Equal(t, 20.0, InvoiceFinancials{DiscountPercent: &percent}.Discount(200))
+	assert.Equal(t, 15.0, InvoiceFinancials{DiscountAmount: &amount}.Discount(200))
+
+	var financials InvoiceFinancials
+	financials.SetDiscount(&percent, nil, "Loyalty")
+	financials.SetDiscount(nil, &amount, "Referral")
+	assert.Nil(t, financials.DiscountPercent)
+	assert.Equal(t, &amount, financials.DiscountAmount)
+	assert.Equal(t, "Referral", financials.DiscountReason)
+}
+
+func TestInvoice_BilledTotal(t *testing.T) {
+	percent := 10.0
+	adjustment := 2.5
+
+	// 200 less 10% plus 2.50, rounded to the nearest whole unit
+	financials := &InvoiceFinancials{DiscountPercent: &percent, AdjustmentAmount: &adjustment}
+	bill := financials.Bill(200, RoundingPolicy{Increment: 1, Mode: RoundTotal})
+	assert.Equal(t, 20.0, bill.Discount)
+	assert.Equal(t, 2.5, bill.Adjustment)
+	assert.Equal(t, 183.0, bill.Total)
+	assert.Equal(t, 0.5, bill.RoundingAdjustment)
+
+	invoice := Invoice{AmountDue: 200, Financials: financials, Rounding: RoundingPolicy{Increment: 1, Mode: RoundTotal}}
+	assert.Equal(t, 183.0, invoice.BilledTotal())
+	invoice.CreditApplied = 50
+	assert.Equal(t, 133.0, invoice.BalanceDue())
+
+	// Invoices without captured terms or a rounding policy bill their amount due to the cent
+	assert.Equal(t, 100.0, Invoice{AmountDue: 99.999}.BilledTotal())
+}
+
+func TestNewCurrencyConversion(t *testing.T) {
+	convertedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	financials := &InvoiceFinancials{
+		CurrencyDisplay:        "EUR",
+		CurrencyConversionRate: 0.92,
+		RateSource:             ConversionRateSourceProject,
+		ConvertedAt:            &convertedAt,
+	}
+
+	conversion := NewCurrencyConversion(financials, "USD", 100)
+	require.NotNil(t, conversion)
+	assert.Equal(t, "USD", conversion.From)
+	assert.Equal(t, "EUR", conversion.To)
+	assert.Equal(t, "0.92", conversion.RateText())
+	assert.Equal(t, "project conversion rate", conversion.SourceLabel())
+	assert.InDelta(t, 92.0, conversion.Total, 0.001)
+
+	assert.Nil(t, NewCurrencyConversion(nil, "USD", 100))
+	assert.Nil(t, NewCurrencyConversion(&InvoiceFinancials{CurrencyDisplay: "EUR", CurrencyConversionRate: 1}, "USD", 100))
+	assert.Nil(t, NewCurrencyConversion(&InvoiceFinancials{CurrencyConversionRate: 0.92}, "USD", 100))
+}
+
+func TestInvoiceModel_EstimatePDFPages(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewInvoiceModel(testDB.DB)
+	timesheetModel := NewTimesheetModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Test Client")
+	projectID := testDB.InsertTestProject(t, "Multi-year Project", clientID)
+	detailedID, err := model.Insert(projectID, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), nil, "Net 30", 5000.00, true)
+	require.NoError(t, err)
+	summaryID, err := model.Insert(projectID, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), nil, "Net 30", 5000.00, false)
+	require.NoError(t, err)
+
+	start := time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC)
+	for day := 0; day < 100; day++ {
+		_, err := timesheetModel.Insert(projectID, start.AddDate(0, 0, day*7), 1, 50, "Editing")
+		require.NoError(t, err)
+	}
+	settings := map[string]AppSettingValue{}
+
+	pages, err := model.EstimatePDFPages(detailedID, settings)
+	require.NoError(t, err)
+	assert.Equal(t, 4, pages)
+
+	pages, err = model.EstimatePDFPages(summaryID, settings)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pages)
+
+	settings["invoice_show_individual_timesheets"] = AppSettingValue{Value: "false", DataType: "bool"}
+	pages, err = model.EstimatePDFPages(detailedID, settings)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pages)
+	delete(settings, "invoice_show_individual_timesheets")
+
+	from := start
+	to := start.AddDate(0, 0, 7*9)
+	require.NoError(t, model.SetTimesheetRange(detailedID, &from, &to))
+	pages, err = model.EstimatePDFPages(detailedID, settings)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pages)
+
+	_, err = model.EstimatePDFPages(9999, settings)
+	assert.Equal(t, ErrNoRecord, err)
+
+	t.Run("max pages setting", func(t *testing.T) {
+		assert.Equal(t, DefaultMaxPDFPages, MaxPDFPages(map[string]AppSettingValue{}))
+		assert.Equal(t, 25, MaxPDFPages(map[string]AppSettingValue{"invoice_max_pdf_pages": {Value: "25", DataType: "int"}}))
+		assert.Equal(t, DefaultMaxPDFPages, MaxPDFPages(map[string]AppSettingValue{"invoice_max_pdf_pages": {Value: "lots", DataType: "int"}}))
+	})
+}
+
+func TestGetLogoDataURL(t *testing.T) {
+	t.Run("default logo is read from the embedded assets", func(t *testing.T) {
+		dataURL, err := getLogoDataURL("./ui/static/img/logo.png")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(dataURL, "data:image/png;base64,"))
+	})
+
+	t.Run("files on disk take precedence", func(t *testing.T) {
+		logoPath := filepath.Join(t.TempDir(), "logo.svg")
+		require.NoError(t, os.WriteFile(logoPath, []byte("<svg/>"), 0644))
+
+		dataURL, err := getLogoDataURL(logoPath)
+		require.NoError(t, err)
+		assert.Equal(t, "data:image/svg+xml;base64,PHN2Zy8+", dataURL)
+	})
+
+	t.Run("missing logo", func(t *testing.T) {
+		dataURL, err := getLogoDataURL("./non/existent/logo.png")
+		require.NoError(t, err)
+		assert.Empty(t, dataURL)
+	})
+}
+
+func TestDueDateFor(t *testing.T) {
+	invoiceDate := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		terms    string
+		expected time.Time
+	}{
+		{"Net 14", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"Payment within 45 days", time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC)},
+		{"Due on receipt", invoiceDate},
+		{"", time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"Bank transfer", time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.terms, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DueDateFor(invoiceDate, tt.terms))
+		})
+	}
+}
+
 func TestInvoiceModel_Integration(t *testing.T) {
 	// Setup test database
 	testDB := testutil.SetupTestSQLite(t)
@@ -776,14 +1685,23 @@ func TestInvoiceModel_GetComprehensiveForPDF(t *testing.T) {
 		state := "CA"
 		zipCode := "90210"
 		hourlyRate := 85.0
-		notes := "Test client notes"
 		billTo := "Custom Bill To Address\nLine 2\nLine 3"
 		universityAff := "Test University Department"
 
-		clientID, err := clientModel.Insert(
-			clientName, clientEmail, &phone, &address1, &address2, nil, &city, &state, &zipCode,
-			hourlyRate, &notes, nil, nil, &billTo, true, nil, nil, &universityAff,
-		)
+		clientID, err := clientModel.Insert(Client{
+			Name:                    clientName,
+			Email:                   clientEmail,
+			Phone:                   &phone,
+			Address1:                &address1,
+			Address2:                &address2,
+			City:                    &city,
+			State:                   &state,
+			ZipCode:                 &zipCode,
+			HourlyRate:              hourlyRate,
+			BillTo:                  &billTo,
+			IncludeAddressOnInvoice: true,
+			UniversityAffiliation:   &universityAff,
+		})
 		require.NoError(t, err)
 
 		// Create test project with attributes
@@ -980,10 +1898,13 @@ func TestInvoiceModel_GenerateComprehensivePDF(t *testing.T) {
 		// Create comprehensive test data
 		clientName := "Test Corporation"
 		billTo := "Test Corporation\nAttn: Accounting\n456 Corporate Blvd\nBusiness City, CA 90210"
-		clientID, err := clientModel.Insert(
-			clientName, "accounting@testcorp.com", nil, nil, nil, nil, nil, nil, nil,
-			100.0, nil, nil, nil, &billTo, true, nil, nil, nil,
-		)
+		clientID, err := clientModel.Insert(Client{
+			Name:                    clientName,
+			Email:                   "accounting@testcorp.com",
+			HourlyRate:              100.0,
+			BillTo:                  &billTo,
+			IncludeAddressOnInvoice: true,
+		})
 		require.NoError(t, err)
 
 		project := Project{
@@ -1169,10 +2090,17 @@ func TestInvoiceModel_GenerateComprehensivePDF(t *testing.T) {
 		city := "Metro City"
 		state := "NY"
 		zipCode := "10001"
-		clientID, err := clientModel.Insert(
-			"Address Test Client", "test@company.com", &phone, &address1, nil, nil, &city, &state, &zipCode,
-			80.0, nil, nil, nil, nil, false, nil, nil, nil, // IncludeAddressOnInvoice = false
-		)
+		clientID, err := clientModel.Insert(Client{
+			Name:                    "Address Test Client",
+			Email:                   "test@company.com",
+			Phone:                   &phone,
+			Address1:                &address1,
+			City:                    &city,
+			State:                   &state,
+			ZipCode:                 &zipCode,
+			HourlyRate:              80.0,
+			IncludeAddressOnInvoice: false,
+		})
 		require.NoError(t, err)
 
 		project := Project{
@@ -1255,7 +2183,6 @@ func TestInvoiceModel_ComprehensiveIntegration(t *testing.T) {
 		state := "TX"
 		zipCode := "78712"
 		hourlyRate := 95.0
-		notes := "Comprehensive test client for invoice system"
 		additionalInfo := "Grant funded project"
 		additionalInfo2 := "Requires detailed invoicing"
 		billTo := "University Accounting Department\nAttn: Dr. Jane Smith\n999 Research Blvd, Bldg C\nUniversity City, TX 78712"
@@ -1263,11 +2190,25 @@ func TestInvoiceModel_ComprehensiveIntegration(t *testing.T) {
 		invoiceCCDesc := "Grant administrator"
 		universityAff := "Department of Computer Science"
 
-		clientID, err := clientModel.Insert(
-			clientName, clientEmail, &phone, &address1, &address2, &address3, &city, &state, &zipCode,
-			hourlyRate, &notes, &additionalInfo, &additionalInfo2, &billTo, true,
-			&invoiceCCEmail, &invoiceCCDesc, &universityAff,
-		)
+		clientID, err := clientModel.Insert(Client{
+			Name:                    clientName,
+			Email:                   clientEmail,
+			Phone:                   &phone,
+			Address1:                &address1,
+			Address2:                &address2,
+			Address3:                &address3,
+			City:                    &city,
+			State:                   &state,
+			ZipCode:                 &zipCode,
+			HourlyRate:              hourlyRate,
+			AdditionalInfo:          &additionalInfo,
+			AdditionalInfo2:         &additionalInfo2,
+			BillTo:                  &billTo,
+			IncludeAddressOnInvoice: true,
+			InvoiceCCEmail:          &invoiceCCEmail,
+			InvoiceCCDescription:    &invoiceCCDesc,
+			UniversityAffiliation:   &universityAff,
+		})
 		require.NoError(t, err)
 
 		// Step 2: Create a comprehensive project with all attributes
@@ -1565,3 +2506,204 @@ func TestInvoiceModel_DisplayDetailsInsertAndUpdate(t *testing.T) {
 		assert.False(t, updatedInvoice.DisplayDetails)
 	})
 }
+
+func TestNewPaymentInstructions(t *testing.T) {
+	settings := map[string]AppSettingValue{
+		"payment_domestic_currency": {Value: "USD", DataType: "string"},
+		"payment_bank_name":         {Value: "First National Bank", DataType: "string"},
+		"payment_account_name":      {Value: "Jane Freelancer", DataType: "string"},
+		"payment_routing_number":    {Value: "021000021", DataType: "string"},
+		"payment_account_number":    {Value: "123456789", DataType: "string"},
+		"payment_iban":              {Value: "DE89370400440532013000", DataType: "string"},
+		"payment_swift":             {Value: "FNBKUS33", DataType: "string"},
+		"payment_paypal_address":    {Value: "pay@example.com", DataType: "string"},
+	}
+
+	t.Run("domestic currency uses routing and account number", func(t *testing.T) {
+		instructions := NewPaymentInstructions(settings, "USD")
+
+		assert.False(t, instructions.International)
+		assert.Equal(t, "First National Bank", instructions.BankName)
+		assert.Equal(t, "021000021", instructions.RoutingNumber)
+		assert.Equal(t, "123456789", instructions.AccountNumber)
+		assert.Empty(t, instructions.IBAN)
+		assert.Empty(t, instructions.SWIFT)
+		assert.Equal(t, "pay@example.com", instructions.PayPalAddress)
+		assert.True(t, instructions.HasBankTransfer())
+	})
+
+	t.Run("foreign currency uses IBAN and SWIFT", func(t *testing.T) {
+		instructions := NewPaymentInstructions(settings, "EUR")
+
+		assert.True(t, instructions.International)
+		assert.Equal(t, "DE89370400440532013000", instructions.IBAN)
+		assert.Equal(t, "FNBKUS33", instructions.SWIFT)
+		assert.Empty(t, instructions.RoutingNumber)
+		assert.Empty(t, instructions.AccountNumber)
+	})
+
+	t.Run("no payment settings configured", func(t *testing.T) {
+		instructions := NewPaymentInstructions(map[string]AppSettingValue{}, "USD")
+
+		assert.False(t, instructions.HasAny())
+	})
+
+	t.Run("paypal only", func(t *testing.T) {
+		instructions := NewPaymentInstructions(map[string]AppSettingValue{
+			"payment_paypal_address": {Value: "pay@example.com", DataType: "string"},
+		}, "GBP")
+
+		assert.False(t, instructions.HasBankTransfer())
+		assert.True(t, instructions.HasAny())
+	})
+}
+
+func TestInvoiceTemplateData_PageHeaderFooter(t *testing.T) {
+	data := SampleInvoiceData()
+	data.Client.Name = "Smith & Sons"
+
+	t.Run("defaults", func(t *testing.T) {
+		headerFooter := NewInvoiceTemplateData(data, nil).PageHeaderFooter()
+		require.NotNil(t, headerFooter)
+		assert.Equal(t, "Invoice #INV-2024-0001 · Smith &amp; Sons", headerFooter.Header)
+		assert.Equal(t, `Page <span class="pageNumber"></span> of <span class="totalPages"></span>`, headerFooter.Footer)
+	})
+
+	t.Run("from settings", func(t *testing.T) {
+		settings := map[string]AppSettingValue{
+			"invoice_page_header": {Value: "<b>{client}</b> {date}", DataType: "string"},
+			"invoice_page_footer": {Value: "", DataType: "string"},
+			"date_format":         {Value: "DD.MM.YYYY", DataType: "string"},
+		}
+		templateData := NewInvoiceTemplateData(data, settings)
+		headerFooter := templateData.PageHeaderFooter()
+		require.NotNil(t, headerFooter)
+		assert.Equal(t, "&lt;b&gt;Smith &amp; Sons&lt;/b&gt; "+templateData.Settings.DateFormat.Format(data.Invoice.InvoiceDate), headerFooter.Header)
+		assert.Equal(t, "<span></span>", pdfMarginTemplate(headerFooter.Footer), "a blank footer leaves the margin empty")
+		assert.Contains(t, pdfMarginTemplate(headerFooter.Header), "font-size: 8px")
+	})
+
+	t.Run("turned off", func(t *testing.T) {
+		settings := map[string]AppSettingValue{"invoice_page_header_footer": {Value: "false", DataType: "bool"}}
+		assert.Nil(t, NewInvoiceTemplateData(data, settings).PageHeaderFooter())
+	})
+}
+
+func TestInvoiceTemplateData_TimesheetGroups(t *testing.T) {
+	data := SampleInvoiceData()
+	data.Timesheets = append(data.Timesheets, Timesheet{ID: 4, WorkDate: time.Date(2024, 4, 23, 0, 0, 0, 0, time.UTC), HoursWorked: 1, HourlyRate: 60, Description: "Call"})
+	grouped := map[string]AppSettingValue{"invoice_group_by_work_type": {Value: "true", DataType: "bool"}}
+
+	t.Run("ungrouped by default", func(t *testing.T) {
+		templateData := NewInvoiceTemplateData(data, nil)
+		assert.False(t, templateData.GroupedByWorkType())
+		groups := templateData.TimesheetGroups()
+		require.Len(t, groups, 1)
+		assert.Len(t, groups[0].Timesheets, 4)
+
+		html, err := RenderInvoiceHTML(templateData)
+		require.NoError(t, err)
+		assert.NotContains(t, string(html), `<tr class="work-type-heading">`)
+	})
+
+	t.Run("grouped with subtotals", func(t *testing.T) {
+		templateData := NewInvoiceTemplateData(data, grouped)
+		require.True(t, templateData.GroupedByWorkType())
+		groups := templateData.TimesheetGroups()
+		require.Len(t, groups, 3)
+		assert.Equal(t, "Editing", groups[0].WorkType)
+		assert.Equal(t, 5.5, groups[0].Hours)
+		assert.Equal(t, 330.0, groups[0].Amount)
+		assert.Equal(t, "Formatting", groups[1].WorkType)
+		assert.Empty(t, groups[2].WorkType)
+
+		html, err := RenderInvoiceHTML(templateData)
+		require.NoError(t, err)
+		body := string(html)
+		assert.Contains(t, body, "Editing</td>")
+		assert.Contains(t, body, "Other work</td>")
+		assert.Contains(t, body, "$330.00")
+	})
+
+	t.Run("nothing to group without work types", func(t *testing.T) {
+		for i := range data.Timesheets {
+			data.Timesheets[i].WorkType = ""
+		}
+		templateData := NewInvoiceTemplateData(data, grouped)
+		assert.False(t, templateData.GroupedByWorkType())
+		assert.Len(t, templateData.TimesheetGroups(), 1)
+	})
+}
+
+func TestInvoiceTemplateData_CreditApplied(t *testing.T) {
+	t.Run("no credit", func(t *testing.T) {
+		templateData := NewInvoiceTemplateData(SampleInvoiceData(), nil)
+		assert.Zero(t, templateData.CreditApplied)
+		assert.Equal(t, templateData.FinalTotal, templateData.BalanceDue)
+
+		html, err := RenderInvoiceHTML(templateData)
+		require.NoError(t, err)
+		assert.NotContains(t, string(html), "Credit Applied")
+		assert.NotContains(t, string(html), "Balance Due")
+	})
+
+	t.Run("credit set off against the total", func(t *testing.T) {
+		data := SampleInvoiceData()
+		data.Invoice.CreditApplied = 100
+		templateData := NewInvoiceTemplateData(data, nil)
+		assert.Equal(t, 100.0, templateData.CreditApplied)
+		assert.InDelta(t, data.FinalTotal-100, templateData.BalanceDue, 0.001)
+		require.NotNil(t, templateData.Conversion)
+		assert.InDelta(t, templateData.BalanceDue*templateData.Conversion.Rate, templateData.Conversion.Total, 0.001,
+			"the converted amount is what is left to pay")
+
+		html, err := RenderInvoiceHTML(templateData)
+		require.NoError(t, err)
+		assert.Contains(t, string(html), "Credit Applied:")
+		assert.Contains(t, string(html), "-$100.00")
+		assert.Contains(t, string(html), "Balance Due:")
+	})
+
+	t.Run("credit beyond the discounted total", func(t *testing.T) {
+		data := SampleInvoiceData()
+		data.Invoice.CreditApplied = data.Invoice.AmountDue
+		templateData := NewInvoiceTemplateData(data, nil)
+		assert.Equal(t, data.FinalTotal, templateData.CreditApplied)
+		assert.Zero(t, templateData.BalanceDue)
+	})
+}
+
+func TestNewInvoiceDefaults(t *testing.T) {
+	t.Run("missing settings keep the old behaviour", func(t *testing.T) {
+		assert.Equal(t, InvoiceDefaults{DateToday: true}, NewInvoiceDefaults(map[string]AppSettingValue{}))
+	})
+
+	t.Run("settings are read", func(t *testing.T) {
+		defaults := NewInvoiceDefaults(map[string]AppSettingValue{
+			"invoice_default_display_details": {Value: "true", DataType: "bool"},
+			"invoice_default_date_today":      {Value: "false", DataType: "bool"},
+			"invoice_default_payment_terms":   {Value: " Net 30 ", DataType: "string"},
+			"invoice_default_amount_auto":     {Value: "true", DataType: "bool"},
+		})
+		assert.Equal(t, InvoiceDefaults{DisplayDetails: true, PaymentTerms: "Net 30", AutoAmount: true}, defaults)
+	})
+
+	t.Run("unreadable settings fall back", func(t *testing.T) {
+		defaults := NewInvoiceDefaults(map[string]AppSettingValue{
+			"invoice_default_date_today": {Value: "sometimes", DataType: "bool"},
+		})
+		assert.True(t, defaults.DateToday)
+	})
+}
+
+func TestUnbilledAmount(t *testing.T) {
+	timesheets := []Timesheet{
+		{ID: 1, HoursWorked: 2, HourlyRate: 50},
+		{ID: 2, HoursWorked: 1.5, HourlyRate: 40},
+		{ID: 3, HoursWorked: 3, HourlyRate: 50, PendingApproval: true},
+		{ID: 4, HoursWorked: 0.333, HourlyRate: 45},
+	}
+	assert.Equal(t, 174.99, UnbilledAmount(timesheets, nil))
+	assert.Equal(t, 74.99, UnbilledAmount(timesheets, map[int]bool{1: true}), "billed entries are left out")
+	assert.Equal(t, 0.0, UnbilledAmount(nil, nil))
+}
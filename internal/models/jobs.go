@@ -0,0 +1,243 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// Job statuses, in the order a job moves through them
+const (
+	JobStatusQueued  = "queued"
+	JobStatusRunning = "running"
+	JobStatusDone    = "done"
+	JobStatusFailed  = "failed"
+)
+
+// JobInvoicePDF is the kind of job that generates an invoice PDF
+const JobInvoicePDF = "invoice_pdf"
+
+// JobInvoiceEmail is the kind of job that emails an invoice
+const JobInvoiceEmail = "invoice_email"
+
+// JobConsolidatedInvoices is the kind of job that invoices a month for every client billed monthly
+const JobConsolidatedInvoices = "consolidated_invoices"
+
+// JobTimesheetReport is the kind of job that generates a timesheet report PDF
+const JobTimesheetReport = "timesheet_report"
+
+// JobReminders is the kind of job that sends overdue invoice and deadline reminders to chat apps
+const JobReminders = "reminders"
+
+// JobInvoiceReminders is the kind of job that emails overdue invoices to their clients again
+const JobInvoiceReminders = "invoice_reminders"
+
+// JobAgingReport is the kind of job that emails the invoice aging report
+const JobAgingReport = "aging_report"
+
+// JobDatabaseMaintenance is the kind of job that checkpoints, vacuums or analyzes the database
+const JobDatabaseMaintenance = "database_maintenance"
+
+// Job is a long-running task processed in the background, such as generating a PDF.
+// Payload holds the JSON the job was enqueued with; a finished job keeps its result.
+type Job struct {
+	ID         int
+	Kind       string
+	Payload    string
+	Status     string
+	Result     []byte
+	ResultType string
+	ResultName string
+	Error      string
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+	Created    time.Time
+	Updated    time.Time
+}
+
+// IsFinished reports whether the job has either completed or failed
+func (j Job) IsFinished() bool {
+	return j.Status == JobStatusDone || j.Status == JobStatusFailed
+}
+
+// Description names what the job produces, for display
+func (j Job) Description() string {
+	switch j.Kind {
+	case JobInvoicePDF:
+		return "Invoice PDF"
+	case JobInvoiceEmail:
+		return "Invoice email"
+	case JobConsolidatedInvoices:
+		return "Consolidated invoices"
+	case JobTimesheetReport:
+		return "Timesheet report"
+	case JobReminders:
+		return "Reminders"
+	case JobInvoiceReminders:
+		return "Invoice reminders"
+	case JobAgingReport:
+		return "Aging report"
+	case JobDatabaseMaintenance:
+		return "Database maintenance"
+	}
+	return j.Kind
+}
+
+// JobModel wraps the generated SQLC Queries for job operations
+type JobModel struct {
+	queries *db.Queries
+}
+
+// NewJobModel creates a new JobModel
+func NewJobModel(database *sql.DB) *JobModel {
+	return &JobModel{
+		queries: newQueries(database),
+	}
+}
+
+// NewJobModelWithTx creates a JobModel whose queries run inside the given transaction
+func NewJobModelWithTx(tx *sql.Tx) *JobModel {
+	return &JobModel{
+		queries: newQueries(tx),
+	}
+}
+
+// Insert queues a new job and returns its ID
+func (j *JobModel) Insert(kind, payload string) (int, error) {
+	ctx := context.Background()
+	id, err := j.queries.InsertJob(ctx, db.InsertJobParams{
+		Kind:    kind,
+		Payload: payload,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// Get retrieves a job by ID
+func (j *JobModel) Get(id int) (Job, error) {
+	ctx := context.Background()
+	row, err := j.queries.GetJob(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Job{}, ErrNoRecord
+		}
+		return Job{}, err
+	}
+
+	return Job{
+		ID:         int(row.ID),
+		Kind:       row.Kind,
+		Payload:    row.Payload,
+		Status:     row.Status,
+		Result:     row.Result,
+		ResultType: row.ResultType,
+		ResultName: row.ResultName,
+		Error:      row.Error,
+		StartedAt:  convertNullTime(row.StartedAt),
+		FinishedAt: convertNullTime(row.FinishedAt),
+		Created:    row.CreatedAt,
+		Updated:    row.UpdatedAt,
+	}, nil
+}
+
+// ClaimNext marks the oldest queued job as running and returns it, or ErrNoRecord when
+// the queue is empty. A job already claimed by another worker is skipped.
+func (j *JobModel) ClaimNext() (Job, error) {
+	ctx := context.Background()
+	for {
+		id, err := j.queries.GetNextQueuedJobID(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return Job{}, ErrNoRecord
+			}
+			return Job{}, err
+		}
+
+		claimed, err := j.queries.ClaimJob(ctx, id)
+		if err != nil {
+			return Job{}, err
+		}
+		if claimed > 0 {
+			return j.Get(int(id))
+		}
+	}
+}
+
+// Complete records a job's result and marks it done
+func (j *JobModel) Complete(id int, result []byte, resultType, resultName string) error {
+	ctx := context.Background()
+	return j.queries.CompleteJob(ctx, db.CompleteJobParams{
+		Result:     result,
+		ResultType: resultType,
+		ResultName: resultName,
+		ID:         int64(id),
+	})
+}
+
+// Fail records why a job couldn't be completed and marks it failed
+func (j *JobModel) Fail(id int, message string) error {
+	ctx := context.Background()
+	return j.queries.FailJob(ctx, db.FailJobParams{
+		Error: message,
+		ID:    int64(id),
+	})
+}
+
+// RequeueRunning puts jobs left running by a stopped server back in the queue and returns
+// how many there were
+func (j *JobModel) RequeueRunning() (int, error) {
+	ctx := context.Background()
+	count, err := j.queries.RequeueRunningJobs(ctx)
+	return int(count), err
+}
+
+// DeleteFinishedBefore removes jobs, along with their results, that finished before cutoff
+// and returns how many were removed
+func (j *JobModel) DeleteFinishedBefore(cutoff time.Time) (int, error) {
+	ctx := context.Background()
+	count, err := j.queries.DeleteFinishedJobsBefore(ctx, sql.NullTime{Time: cutoff.UTC(), Valid: true})
+	return int(count), err
+}
+
+// ListRecent returns the most recently queued jobs, newest first, without their results
+func (j *JobModel) ListRecent(limit int) ([]Job, error) {
+	ctx := context.Background()
+	rows, err := j.queries.ListRecentJobs(ctx, int64(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]Job, len(rows))
+	for i, row := range rows {
+		jobs[i] = Job{
+			ID:         int(row.ID),
+			Kind:       row.Kind,
+			Status:     row.Status,
+			Error:      row.Error,
+			StartedAt:  convertNullTime(row.StartedAt),
+			FinishedAt: convertNullTime(row.FinishedAt),
+			Created:    row.CreatedAt,
+		}
+	}
+	return jobs, nil
+}
+
+// JobModelInterface defines the interface for job operations
+type JobModelInterface interface {
+	Insert(kind, payload string) (int, error)
+	Get(id int) (Job, error)
+	ClaimNext() (Job, error)
+	Complete(id int, result []byte, resultType, resultName string) error
+	Fail(id int, message string) error
+	RequeueRunning() (int, error)
+	DeleteFinishedBefore(cutoff time.Time) (int, error)
+	ListRecent(limit int) ([]Job, error)
+}
+
+// Ensure implementation satisfies the interface
+var _ JobModelInterface = (*JobModel)(nil)
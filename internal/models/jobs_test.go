@@ -0,0 +1,99 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewJobModel(testDB.DB)
+
+	t.Run("jobs are claimed oldest first", func(t *testing.T) {
+		testDB.TruncateTable(t, "job")
+
+		firstID, err := model.Insert(JobInvoicePDF, `{"invoice_id":1}`)
+		require.NoError(t, err)
+		secondID, err := model.Insert(JobInvoicePDF, `{"invoice_id":2}`)
+		require.NoError(t, err)
+
+		job, err := model.Get(firstID)
+		require.NoError(t, err)
+		assert.Equal(t, JobStatusQueued, job.Status)
+		assert.Equal(t, "Invoice PDF", job.Description())
+
+		job, err = model.ClaimNext()
+		require.NoError(t, err)
+		assert.Equal(t, firstID, job.ID)
+		assert.Equal(t, JobStatusRunning, job.Status)
+		assert.Equal(t, `{"invoice_id":1}`, job.Payload)
+		assert.NotNil(t, job.StartedAt)
+
+		job, err = model.ClaimNext()
+		require.NoError(t, err)
+		assert.Equal(t, secondID, job.ID)
+
+		_, err = model.ClaimNext()
+		assert.Equal(t, ErrNoRecord, err)
+	})
+
+	t.Run("finished jobs keep their result or error", func(t *testing.T) {
+		testDB.TruncateTable(t, "job")
+
+		doneID, err := model.Insert(JobInvoicePDF, "{}")
+		require.NoError(t, err)
+		failedID, err := model.Insert(JobInvoicePDF, "{}")
+		require.NoError(t, err)
+
+		require.NoError(t, model.Complete(doneID, []byte("%PDF"), "application/pdf", "invoice_1.pdf"))
+		require.NoError(t, model.Fail(failedID, "Chrome is not installed"))
+
+		done, err := model.Get(doneID)
+		require.NoError(t, err)
+		assert.True(t, done.IsFinished())
+		assert.Equal(t, JobStatusDone, done.Status)
+		assert.Equal(t, []byte("%PDF"), done.Result)
+		assert.Equal(t, "application/pdf", done.ResultType)
+		assert.Equal(t, "invoice_1.pdf", done.ResultName)
+		assert.NotNil(t, done.FinishedAt)
+
+		failed, err := model.Get(failedID)
+		require.NoError(t, err)
+		assert.True(t, failed.IsFinished())
+		assert.Equal(t, "Chrome is not installed", failed.Error)
+
+		removed, err := model.DeleteFinishedBefore(time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, removed)
+
+		removed, err = model.DeleteFinishedBefore(time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 2, removed)
+
+		_, err = model.Get(doneID)
+		assert.Equal(t, ErrNoRecord, err)
+	})
+
+	t.Run("interrupted jobs are queued again", func(t *testing.T) {
+		testDB.TruncateTable(t, "job")
+
+		id, err := model.Insert(JobInvoicePDF, "{}")
+		require.NoError(t, err)
+		_, err = model.ClaimNext()
+		require.NoError(t, err)
+
+		requeued, err := model.RequeueRunning()
+		require.NoError(t, err)
+		assert.Equal(t, 1, requeued)
+
+		job, err := model.ClaimNext()
+		require.NoError(t, err)
+		assert.Equal(t, id, job.ID)
+	})
+}
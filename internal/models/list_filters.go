@@ -0,0 +1,487 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of value a filter field holds, which decide the operators it can be compared with
+const (
+	FilterKindText   = "text"
+	FilterKindNumber = "number"
+	FilterKindDate   = "date"
+)
+
+// Filter operators, as they appear in filter conditions
+const (
+	FilterEquals      = "eq"
+	FilterNotEquals   = "ne"
+	FilterContains    = "contains"
+	FilterLessThan    = "lt"
+	FilterAtMost      = "lte"
+	FilterGreaterThan = "gt"
+	FilterAtLeast     = "gte"
+	FilterIsEmpty     = "empty"
+	FilterIsNotEmpty  = "set"
+)
+
+// filterConditionSep separates the field, operator and value of a condition written out
+const filterConditionSep = "."
+
+// FilterOperator is a way of comparing a field with a value. Operators without a value test
+// whether the field is set at all.
+type FilterOperator struct {
+	Value   string
+	Label   string
+	NoValue bool
+}
+
+// filterOperators lists the operators in the order they are offered, each with the SQL
+// comparison it builds
+var filterOperators = []struct {
+	FilterOperator
+	sql   string
+	kinds []string
+}{
+	{FilterOperator{FilterEquals, "is", false}, "=", []string{FilterKindText, FilterKindNumber, FilterKindDate}},
+	{FilterOperator{FilterNotEquals, "is not", false}, "IS NOT", []string{FilterKindText, FilterKindNumber, FilterKindDate}},
+	{FilterOperator{FilterContains, "contains", false}, "LIKE", []string{FilterKindText}},
+	{FilterOperator{FilterLessThan, "is less than", false}, "<", []string{FilterKindNumber}},
+	{FilterOperator{FilterAtMost, "is at most", false}, "<=", []string{FilterKindNumber}},
+	{FilterOperator{FilterGreaterThan, "is more than", false}, ">", []string{FilterKindNumber}},
+	{FilterOperator{FilterAtLeast, "is at least", false}, ">=", []string{FilterKindNumber}},
+	{FilterOperator{FilterLessThan, "is before", false}, "<", []string{FilterKindDate}},
+	{FilterOperator{FilterAtMost, "is on or before", false}, "<=", []string{FilterKindDate}},
+	{FilterOperator{FilterGreaterThan, "is after", false}, ">", []string{FilterKindDate}},
+	{FilterOperator{FilterAtLeast, "is on or after", false}, ">=", []string{FilterKindDate}},
+	{FilterOperator{FilterIsEmpty, "is empty", true}, "IS NULL", []string{FilterKindText, FilterKindNumber, FilterKindDate}},
+	{FilterOperator{FilterIsNotEmpty, "is set", true}, "IS NOT NULL", []string{FilterKindText, FilterKindNumber, FilterKindDate}},
+}
+
+// FilterField is a column a list can be filtered on. Expr is the SQL expression giving its
+// value for a row of the list; date expressions give the date as YYYY-MM-DD.
+type FilterField struct {
+	Name  string
+	Label string
+	Kind  string
+	Expr  string
+}
+
+// Operators lists the operators the field can be compared with
+func (f FilterField) Operators() []FilterOperator {
+	var operators []FilterOperator
+	for _, op := range filterOperators {
+		for _, kind := range op.kinds {
+			if kind == f.Kind {
+				operators = append(operators, op.FilterOperator)
+			}
+		}
+	}
+	return operators
+}
+
+// FilterError is returned for a filter condition that can't be applied to a list
+type FilterError struct {
+	Condition string
+	Message   string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("filter %q: %s", e.Condition, e.Message)
+}
+
+func (e *FilterError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// FilterCondition compares one field of a list with a value. Dates are given as YYYY-MM-DD or
+// relative to the day the filter is applied: "today", "today-30", "today+7", "month_start"
+// or "month_end".
+type FilterCondition struct {
+	Field    string
+	Operator string
+	Value    string
+}
+
+// String writes the condition as field.operator.value, the form it takes in URLs
+func (c FilterCondition) String() string {
+	return c.Field + filterConditionSep + c.Operator + filterConditionSep + c.Value
+}
+
+// ParseFilterCondition reads a condition written as field.operator.value
+func ParseFilterCondition(value string) (FilterCondition, error) {
+	parts := strings.SplitN(value, filterConditionSep, 3)
+	if len(parts) < 2 {
+		return FilterCondition{}, &FilterError{Condition: value, Message: "expected field.operator.value"}
+	}
+	condition := FilterCondition{Field: parts[0], Operator: parts[1]}
+	if len(parts) == 3 {
+		condition.Value = parts[2]
+	}
+	return condition, nil
+}
+
+// Filter is a set of conditions a row has to meet all of
+type Filter []FilterCondition
+
+// ParseFilter reads the conditions of a filter from the values of the filter query parameter
+func ParseFilter(values []string) (Filter, error) {
+	var filter Filter
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		condition, err := ParseFilterCondition(value)
+		if err != nil {
+			return nil, err
+		}
+		filter = append(filter, condition)
+	}
+	return filter, nil
+}
+
+// ParseFilterQuery reads a filter saved as a URL query
+func ParseFilterQuery(query string) (Filter, error) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, &FilterError{Condition: query, Message: "not a valid query"}
+	}
+	return ParseFilter(values["filter"])
+}
+
+// Query writes the filter as a URL query, e.g. filter=status.eq.Scheduled&filter=...
+func (f Filter) Query() string {
+	values := url.Values{}
+	for _, condition := range f {
+		values.Add("filter", condition.String())
+	}
+	return values.Encode()
+}
+
+// Without returns the filter with its i-th condition left out
+func (f Filter) Without(i int) Filter {
+	without := make(Filter, 0, len(f))
+	without = append(without, f[:i]...)
+	return append(without, f[i+1:]...)
+}
+
+// QuickFilter is a ready-made filter offered on a list page
+type QuickFilter struct {
+	Name   string
+	Filter Filter
+}
+
+// FilterList is a list page that can be filtered: the rows it shows, the order it shows them
+// in and the fields they can be filtered on
+type FilterList struct {
+	Name         string
+	Path         string
+	Table        string
+	Where        string
+	OrderBy      string
+	Fields       []FilterField
+	QuickFilters []QuickFilter
+}
+
+// oldestUnpaidInvoice is the date of a client's oldest invoice still waiting to be paid
+const oldestUnpaidInvoice = `(SELECT substr(MIN(i.invoice_date), 1, 10) FROM invoice i JOIN project ip ON ip.id = i.project_id
+	WHERE ip.client_id = c.id AND ip.deleted_at IS NULL AND i.date_paid IS NULL AND i.deleted_at IS NULL AND i.voided_at IS NULL)`
+
+// ClientFilters describes the client list
+var ClientFilters = FilterList{
+	Name:    "clients",
+	Path:    "/",
+	Table:   "client c",
+	Where:   "c.deleted_at IS NULL",
+	OrderBy: "c.updated_at DESC",
+	Fields: []FilterField{
+		{Name: "name", Label: "Name", Kind: FilterKindText, Expr: "c.name"},
+		{Name: "email", Label: "Email", Kind: FilterKindText, Expr: "c.email"},
+		{Name: "city", Label: "City", Kind: FilterKindText, Expr: "c.city"},
+		{Name: "country", Label: "Country", Kind: FilterKindText, Expr: "c.country"},
+		{Name: "hourly_rate", Label: "Hourly rate", Kind: FilterKindNumber, Expr: "c.hourly_rate"},
+		{Name: "oldest_unpaid", Label: "Oldest unpaid invoice", Kind: FilterKindDate, Expr: oldestUnpaidInvoice},
+		{Name: "created", Label: "Created", Kind: FilterKindDate, Expr: "substr(c.created_at, 1, 10)"},
+	},
+	QuickFilters: []QuickFilter{
+		{Name: "Unpaid invoices over 30 days", Filter: Filter{{"oldest_unpaid", FilterLessThan, "today-30"}}},
+		{Name: "Added this month", Filter: Filter{{"created", FilterAtLeast, "month_start"}}},
+	},
+}
+
+// ProjectFilters describes the project list
+var ProjectFilters = FilterList{
+	Name:    "projects",
+	Path:    "/projects",
+	Table:   "project p JOIN client c ON c.id = p.client_id",
+	Where:   "p.deleted_at IS NULL AND c.deleted_at IS NULL",
+	OrderBy: "p.updated_at DESC",
+	Fields: []FilterField{
+		{Name: "name", Label: "Name", Kind: FilterKindText, Expr: "p.name"},
+		{Name: "client", Label: "Client", Kind: FilterKindText, Expr: "c.name"},
+		{Name: "status", Label: "Status", Kind: FilterKindText, Expr: "p.status"},
+		{Name: "hourly_rate", Label: "Hourly rate", Kind: FilterKindNumber, Expr: "p.hourly_rate"},
+		{Name: "deadline", Label: "Deadline", Kind: FilterKindDate, Expr: "NULLIF(substr(p.deadline, 1, 10), '')"},
+		{Name: "scheduled_start", Label: "Scheduled start", Kind: FilterKindDate, Expr: "NULLIF(substr(p.scheduled_start, 1, 10), '')"},
+		{Name: "created", Label: "Created", Kind: FilterKindDate, Expr: "substr(p.created_at, 1, 10)"},
+	},
+	QuickFilters: []QuickFilter{
+		{Name: "Due this month", Filter: Filter{{"deadline", FilterAtLeast, "month_start"}, {"deadline", FilterAtMost, "month_end"}}},
+		{Name: "Due in the next 7 days", Filter: Filter{{"deadline", FilterAtLeast, "today"}, {"deadline", FilterAtMost, "today+7"}}},
+		{Name: "In progress", Filter: Filter{{"status", FilterEquals, "In Progress"}}},
+	},
+}
+
+// FilterLists lists the lists that can be filtered
+var FilterLists = []FilterList{ClientFilters, ProjectFilters}
+
+// LookupFilterList finds a filterable list by name
+func LookupFilterList(name string) (FilterList, bool) {
+	for _, list := range FilterLists {
+		if list.Name == name {
+			return list, true
+		}
+	}
+	return FilterList{}, false
+}
+
+// Field finds one of the list's fields by name
+func (l FilterList) Field(name string) (FilterField, bool) {
+	for _, field := range l.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return FilterField{}, false
+}
+
+// Describe writes a condition in words, e.g. "Deadline is on or before end of this month"
+func (l FilterList) Describe(condition FilterCondition) string {
+	field, ok := l.Field(condition.Field)
+	if !ok {
+		return condition.String()
+	}
+	for _, op := range filterOperators {
+		if op.Value != condition.Operator || !containsKind(op.kinds, field.Kind) {
+			continue
+		}
+		if op.NoValue {
+			return field.Label + " " + op.Label
+		}
+		value := condition.Value
+		if field.Kind == FilterKindDate {
+			value = describeFilterDate(value)
+		}
+		return fmt.Sprintf("%s %s %s", field.Label, op.Label, value)
+	}
+	return condition.String()
+}
+
+// Validate checks that every condition of a filter fits the list, returning a *FilterError
+// for the first one that doesn't
+func (l FilterList) Validate(filter Filter) error {
+	_, _, err := l.where(filter, time.Now())
+	return err
+}
+
+// where builds the SQL condition for a filter applied on today, along with its arguments
+func (l FilterList) where(filter Filter, today time.Time) (string, []any, error) {
+	clauses := []string{l.Where}
+	var args []any
+	for _, condition := range filter {
+		field, ok := l.Field(condition.Field)
+		if !ok {
+			return "", nil, &FilterError{Condition: condition.String(), Message: "unknown field"}
+		}
+
+		var comparison string
+		for _, op := range filterOperators {
+			if op.Value == condition.Operator && containsKind(op.kinds, field.Kind) {
+				comparison = op.sql
+				break
+			}
+		}
+		if comparison == "" {
+			return "", nil, &FilterError{Condition: condition.String(), Message: "unknown operator"}
+		}
+
+		switch {
+		case comparison == "IS NULL":
+			clauses = append(clauses, fmt.Sprintf("(%s IS NULL OR %s = '')", field.Expr, field.Expr))
+			continue
+		case comparison == "IS NOT NULL":
+			clauses = append(clauses, fmt.Sprintf("(%s IS NOT NULL AND %s != '')", field.Expr, field.Expr))
+			continue
+		case comparison == "LIKE":
+			clauses = append(clauses, fmt.Sprintf(`%s LIKE ? ESCAPE '\'`, field.Expr))
+			args = append(args, "%"+likeEscaper.Replace(condition.Value)+"%")
+			continue
+		}
+
+		value, err := filterValue(field, condition.Value, today)
+		if err != nil {
+			return "", nil, &FilterError{Condition: condition.String(), Message: err.Error()}
+		}
+		// Rows without a value differ from any value too, hence IS NOT rather than !=, and
+		// text matches whatever its case
+		clause := fmt.Sprintf("%s %s ?", field.Expr, comparison)
+		if field.Kind == FilterKindText {
+			clause += " COLLATE NOCASE"
+		}
+		clauses = append(clauses, clause)
+		args = append(args, value)
+	}
+	return strings.Join(clauses, " AND "), args, nil
+}
+
+// filterValue converts the value a field is compared with to the type of the field
+func filterValue(field FilterField, value string, today time.Time) (any, error) {
+	switch field.Kind {
+	case FilterKindNumber:
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", field.Label)
+		}
+		return number, nil
+	case FilterKindDate:
+		date, err := resolveFilterDate(value, today)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a date", field.Label)
+		}
+		return date.Format(isoLayout), nil
+	}
+	return value, nil
+}
+
+// resolveFilterDate reads a date given as YYYY-MM-DD or relative to today
+func resolveFilterDate(value string, today time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	day := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	switch {
+	case value == "month_start":
+		start, _ := MonthRange(day)
+		return start, nil
+	case value == "month_end":
+		_, end := MonthRange(day)
+		return end.AddDate(0, 0, -1), nil
+	case strings.HasPrefix(value, "today"):
+		offset := strings.TrimPrefix(value, "today")
+		if offset == "" {
+			return day, nil
+		}
+		days, err := strconv.Atoi(offset)
+		if err != nil || (offset[0] != '+' && offset[0] != '-') {
+			return time.Time{}, fmt.Errorf("invalid relative date %q", value)
+		}
+		return day.AddDate(0, 0, days), nil
+	}
+	return time.Parse(isoLayout, value)
+}
+
+// describeFilterDate writes a relative date in words, leaving other dates as they are
+func describeFilterDate(value string) string {
+	switch {
+	case value == "month_start":
+		return "the start of this month"
+	case value == "month_end":
+		return "the end of this month"
+	case value == "today":
+		return "today"
+	case strings.HasPrefix(value, "today-"):
+		return strings.TrimPrefix(value, "today-") + " days ago"
+	case strings.HasPrefix(value, "today+"):
+		return strings.TrimPrefix(value, "today+") + " days from now"
+	}
+	return value
+}
+
+// containsKind reports whether kinds includes kind
+func containsKind(kinds []string, kind string) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// toInt64s converts IDs to the type the generated queries take
+func toInt64s(ids []int) []int64 {
+	converted := make([]int64, len(ids))
+	for i, id := range ids {
+		converted[i] = int64(id)
+	}
+	return converted
+}
+
+// sortByIDs puts rows in the order their IDs appear in ids
+func sortByIDs[T any](rows []T, ids []int, id func(T) int) {
+	position := make(map[int]int, len(ids))
+	for i, id := range ids {
+		position[id] = i
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return position[id(rows[i])] < position[id(rows[j])] })
+}
+
+// ListFilterModel finds the rows of a list that match a filter. Like IntegrityModel it builds
+// its queries rather than using the generated ones, since the conditions are chosen by the
+// user; only the field expressions of the list are ever written into the SQL.
+type ListFilterModel struct {
+	database *sql.DB
+}
+
+// NewListFilterModel creates a new ListFilterModel
+func NewListFilterModel(database *sql.DB) *ListFilterModel {
+	return &ListFilterModel{
+		database: database,
+	}
+}
+
+// Find returns the IDs of a page of the rows of a list matching a filter applied on today, in
+// the list's order, along with the number of rows matching in total. It returns a
+// *FilterError for a condition that doesn't fit the list.
+func (m *ListFilterModel) Find(list FilterList, filter Filter, today time.Time, limit, offset int64) ([]int, int64, error) {
+	ctx := context.Background()
+	where, args, err := list.where(filter, today)
+	if err != nil {
+		return nil, 0, err
+	}
+	alias := strings.Fields(list.Table)[1]
+
+	var total int64
+	err = m.database.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", list.Table, where), args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT %s.id FROM %s WHERE %s ORDER BY %s, %s.id DESC LIMIT ? OFFSET ?", alias, list.Table, where, list.OrderBy, alias)
+	rows, err := m.database.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, 0, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, total, rows.Err()
+}
+
+// ListFilterModelInterface defines the interface for filtering lists
+type ListFilterModelInterface interface {
+	Find(list FilterList, filter Filter, today time.Time, limit, offset int64) ([]int, int64, error)
+}
+
+// Ensure implementation satisfies the interface
+var _ ListFilterModelInterface = (*ListFilterModel)(nil)
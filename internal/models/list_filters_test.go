@@ -0,0 +1,127 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	filter, err := ParseFilter([]string{"status.eq.In Progress", "", "deadline.empty", "name.contains.a.b"})
+	require.NoError(t, err)
+	assert.Equal(t, Filter{
+		{Field: "status", Operator: FilterEquals, Value: "In Progress"},
+		{Field: "deadline", Operator: FilterIsEmpty},
+		{Field: "name", Operator: FilterContains, Value: "a.b"},
+	}, filter)
+
+	parsed, err := ParseFilterQuery(filter.Query())
+	require.NoError(t, err)
+	assert.Equal(t, filter, parsed)
+	assert.Equal(t, Filter{filter[0], filter[2]}, filter.Without(1))
+
+	_, err = ParseFilter([]string{"status"})
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestResolveFilterDate(t *testing.T) {
+	today := time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC)
+	tests := map[string]string{
+		"today":       "2024-03-15",
+		"today-30":    "2024-02-14",
+		"today+7":     "2024-03-22",
+		"month_start": "2024-03-01",
+		"month_end":   "2024-03-31",
+		"2023-12-24":  "2023-12-24",
+	}
+	for value, want := range tests {
+		date, err := resolveFilterDate(value, today)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, date.Format(isoLayout), value)
+	}
+
+	for _, value := range []string{"today30", "yesterday", "15.03.2024"} {
+		_, err := resolveFilterDate(value, today)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestFilterList_Describe(t *testing.T) {
+	assert.Equal(t, "Oldest unpaid invoice is before 30 days ago", ClientFilters.Describe(FilterCondition{"oldest_unpaid", FilterLessThan, "today-30"}))
+	assert.Equal(t, "Hourly rate is at least 50", ClientFilters.Describe(FilterCondition{"hourly_rate", FilterAtLeast, "50"}))
+	assert.Equal(t, "Deadline is empty", ProjectFilters.Describe(FilterCondition{"deadline", FilterIsEmpty, ""}))
+	assert.Equal(t, "colour.eq.red", ProjectFilters.Describe(FilterCondition{"colour", FilterEquals, "red"}))
+}
+
+func TestFilterList_Validate(t *testing.T) {
+	assert.NoError(t, ProjectFilters.Validate(Filter{{"deadline", FilterAtMost, "month_end"}}))
+
+	invalid := []Filter{
+		{{"colour", FilterEquals, "red"}},
+		{{"name", FilterLessThan, "b"}},
+		{{"hourly_rate", FilterContains, "5"}},
+		{{"hourly_rate", FilterEquals, "fifty"}},
+		{{"deadline", FilterAtMost, "soon"}},
+	}
+	for _, filter := range invalid {
+		err := ProjectFilters.Validate(filter)
+		var filterErr *FilterError
+		assert.ErrorAs(t, err, &filterErr, filter[0].String())
+	}
+}
+
+func TestListFilterModel_Find(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewListFilterModel(testDB.DB)
+	today := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	overdueID := testDB.InsertTestClient(t, "Overdue Client")
+	recentID := testDB.InsertTestClient(t, "Recent Client")
+	paidID := testDB.InsertTestClient(t, "Paid Client")
+	testDB.InsertTestInvoice(t, testDB.InsertTestProject(t, "Old Work", overdueID), "2024-01-10", "", "Net 30", "100.00")
+	testDB.InsertTestInvoice(t, testDB.InsertTestProject(t, "New Work", recentID), "2024-03-01", "", "Net 30", "100.00")
+	testDB.InsertTestInvoice(t, testDB.InsertTestProject(t, "Paid Work", paidID), "2024-01-10", "2024-02-01", "Net 30", "100.00")
+
+	t.Run("clients with invoices unpaid for over 30 days", func(t *testing.T) {
+		ids, total, err := model.Find(ClientFilters, ClientFilters.QuickFilters[0].Filter, today, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, []int{overdueID}, ids)
+	})
+
+	t.Run("text matches whatever its case", func(t *testing.T) {
+		ids, total, err := model.Find(ClientFilters, Filter{{"name", FilterContains, "CLIENT"}, {"name", FilterNotEquals, "paid client"}}, today, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.ElementsMatch(t, []int{overdueID, recentID}, ids)
+	})
+
+	t.Run("projects due this month are paged", func(t *testing.T) {
+		_, err := testDB.DB.Exec("UPDATE project SET deadline = '2024-03-20' WHERE name IN ('Old Work', 'New Work')")
+		require.NoError(t, err)
+		_, err = testDB.DB.Exec("UPDATE project SET deadline = '2024-04-02' WHERE name = 'Paid Work'")
+		require.NoError(t, err)
+
+		filter := ProjectFilters.QuickFilters[0].Filter
+		ids, total, err := model.Find(ProjectFilters, filter, today, 1, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		require.Len(t, ids, 1)
+
+		rest, _, err := model.Find(ProjectFilters, filter, today, 1, 1)
+		require.NoError(t, err)
+		require.Len(t, rest, 1)
+		assert.NotEqual(t, ids[0], rest[0])
+	})
+
+	t.Run("a condition that doesn't fit the list is rejected", func(t *testing.T) {
+		_, _, err := model.Find(ClientFilters, Filter{{"status", FilterEquals, "Scheduled"}}, today, 10, 0)
+		var filterErr *FilterError
+		assert.ErrorAs(t, err, &filterErr)
+	})
+}
@@ -0,0 +1,228 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// Milestone statuses, in the order a milestone moves through them
+const (
+	MilestoneStatusPlanned    = "Planned"
+	MilestoneStatusInProgress = "In Progress"
+	MilestoneStatusComplete   = "Complete"
+	MilestoneStatusInvoiced   = "Invoiced"
+)
+
+// MilestoneStatuses lists the statuses that can be chosen on the milestone form. Invoiced is
+// set only by invoicing the milestone.
+var MilestoneStatuses = []string{MilestoneStatusPlanned, MilestoneStatusInProgress, MilestoneStatusComplete}
+
+// ValidMilestoneStatus reports whether status can be chosen on the milestone form
+func ValidMilestoneStatus(status string) bool {
+	for _, s := range MilestoneStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Milestone represents a phase of a project, with the fee charged for it on fixed-bid work
+type Milestone struct {
+	ID        int
+	ProjectID int
+	Name      string
+	DueDate   *time.Time
+	Fee       float64
+	Status    string
+	InvoiceID *int
+	Updated   time.Time
+	Created   time.Time
+	DeletedAt *time.Time
+}
+
+// IsInvoiced reports whether the milestone has been billed on an invoice
+func (m Milestone) IsInvoiced() bool {
+	return m.InvoiceID != nil
+}
+
+// CanInvoice reports whether the milestone has a fee that hasn't been billed yet
+func (m Milestone) CanInvoice() bool {
+	return m.Fee > 0 && !m.IsInvoiced()
+}
+
+// MilestoneFees summarises what a project's milestones are billed at
+type MilestoneFees struct {
+	Total    float64
+	Invoiced float64
+}
+
+// Remaining returns the fees that have yet to be invoiced
+func (f MilestoneFees) Remaining() float64 {
+	return f.Total - f.Invoiced
+}
+
+// TotalMilestoneFees adds up the fees of a project's milestones and how much of them has been invoiced
+func TotalMilestoneFees(milestones []Milestone) MilestoneFees {
+	var fees MilestoneFees
+	for _, m := range milestones {
+		fees.Total += m.Fee
+		if m.IsInvoiced() {
+			fees.Invoiced += m.Fee
+		}
+	}
+	return fees
+}
+
+// MilestoneModel wraps the generated SQLC Queries for milestone operations
+type MilestoneModel struct {
+	queries *db.Queries
+}
+
+// NewMilestoneModel creates a new MilestoneModel
+func NewMilestoneModel(database *sql.DB) *MilestoneModel {
+	return &MilestoneModel{
+		queries: newQueries(database),
+	}
+}
+
+// NewMilestoneModelWithTx creates a MilestoneModel whose queries run inside the given transaction
+func NewMilestoneModelWithTx(tx *sql.Tx) *MilestoneModel {
+	return &MilestoneModel{
+		queries: newQueries(tx),
+	}
+}
+
+// timeToNullTime converts an optional date to its database representation
+func timeToNullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+// Insert adds a new milestone to a project and returns its ID
+func (m *MilestoneModel) Insert(milestone Milestone) (int, error) {
+	ctx := context.Background()
+	id, err := m.queries.InsertMilestone(ctx, db.InsertMilestoneParams{
+		ProjectID: int64(milestone.ProjectID),
+		Name:      milestone.Name,
+		DueDate:   timeToNullTime(milestone.DueDate),
+		Fee:       milestone.Fee,
+		Status:    milestone.Status,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// Get retrieves a milestone by ID
+func (m *MilestoneModel) Get(id int) (Milestone, error) {
+	ctx := context.Background()
+	row, err := m.queries.GetMilestone(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Milestone{}, ErrNoRecord
+		}
+		return Milestone{}, err
+	}
+
+	var deletedAt *time.Time
+	if row.DeletedAt != nil {
+		if dt, ok := row.DeletedAt.(time.Time); ok {
+			deletedAt = &dt
+		}
+	}
+
+	return Milestone{
+		ID:        int(row.ID),
+		ProjectID: int(row.ProjectID),
+		Name:      row.Name,
+		DueDate:   convertNullTime(row.DueDate),
+		Fee:       row.Fee,
+		Status:    row.Status,
+		InvoiceID: convertNullInt64(row.InvoiceID),
+		Updated:   row.UpdatedAt,
+		Created:   row.CreatedAt,
+		DeletedAt: deletedAt,
+	}, nil
+}
+
+// GetByProject retrieves a project's milestones ordered by due date, with undated ones last
+func (m *MilestoneModel) GetByProject(projectID int) ([]Milestone, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetMilestonesByProject(ctx, int64(projectID))
+	if err != nil {
+		return nil, err
+	}
+
+	milestones := make([]Milestone, len(rows))
+	for i, row := range rows {
+		milestones[i] = Milestone{
+			ID:        int(row.ID),
+			ProjectID: int(row.ProjectID),
+			Name:      row.Name,
+			DueDate:   convertNullTime(row.DueDate),
+			Fee:       row.Fee,
+			Status:    row.Status,
+			InvoiceID: convertNullInt64(row.InvoiceID),
+			Updated:   row.UpdatedAt,
+			Created:   row.CreatedAt,
+		}
+	}
+
+	return milestones, nil
+}
+
+// Update modifies an existing milestone
+func (m *MilestoneModel) Update(milestone Milestone) error {
+	ctx := context.Background()
+	return m.queries.UpdateMilestone(ctx, db.UpdateMilestoneParams{
+		Name:    milestone.Name,
+		DueDate: timeToNullTime(milestone.DueDate),
+		Fee:     milestone.Fee,
+		Status:  milestone.Status,
+		ID:      int64(milestone.ID),
+	})
+}
+
+// Delete soft deletes a milestone by setting the deleted_at timestamp
+func (m *MilestoneModel) Delete(id int) error {
+	ctx := context.Background()
+	return m.queries.DeleteMilestone(ctx, int64(id))
+}
+
+// SetInvoice records the invoice a milestone was billed on and marks it invoiced
+func (m *MilestoneModel) SetInvoice(id, invoiceID int) error {
+	ctx := context.Background()
+	return m.queries.SetMilestoneInvoice(ctx, db.SetMilestoneInvoiceParams{
+		InvoiceID: sql.NullInt64{Int64: int64(invoiceID), Valid: true},
+		ID:        int64(id),
+	})
+}
+
+// ReleaseInvoice returns milestones billed on an invoice that was deleted or voided to
+// complete, so that they can be invoiced again
+func (m *MilestoneModel) ReleaseInvoice(invoiceID int) error {
+	ctx := context.Background()
+	return m.queries.ReleaseMilestonesForInvoice(ctx, sql.NullInt64{Int64: int64(invoiceID), Valid: true})
+}
+
+// MilestoneModelInterface defines the interface for milestone operations
+type MilestoneModelInterface interface {
+	Insert(milestone Milestone) (int, error)
+	Get(id int) (Milestone, error)
+	GetByProject(projectID int) ([]Milestone, error)
+	Update(milestone Milestone) error
+	Delete(id int) error
+	SetInvoice(id, invoiceID int) error
+	ReleaseInvoice(invoiceID int) error
+}
+
+// Ensure implementation satisfies the interface
+var _ MilestoneModelInterface = (*MilestoneModel)(nil)
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMilestoneModel_CRUD(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewMilestoneModel(testDB.DB)
+	clientID := testDB.InsertTestClient(t, "Publisher")
+	projectID := testDB.InsertTestProject(t, "Novel", clientID)
+
+	dueDate := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	laterID, err := model.Insert(Milestone{ProjectID: projectID, Name: "Final draft", Fee: 2000, Status: MilestoneStatusPlanned})
+	require.NoError(t, err)
+	firstID, err := model.Insert(Milestone{ProjectID: projectID, Name: "Outline", DueDate: &dueDate, Fee: 500, Status: MilestoneStatusInProgress})
+	require.NoError(t, err)
+
+	t.Run("undated milestones come last", func(t *testing.T) {
+		milestones, err := model.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, milestones, 2)
+		assert.Equal(t, firstID, milestones[0].ID)
+		require.NotNil(t, milestones[0].DueDate)
+		assert.True(t, dueDate.Equal(*milestones[0].DueDate))
+		assert.Equal(t, laterID, milestones[1].ID)
+		assert.Nil(t, milestones[1].DueDate)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		milestone, err := model.Get(firstID)
+		require.NoError(t, err)
+		milestone.Status = MilestoneStatusComplete
+		milestone.Fee = 750
+		require.NoError(t, model.Update(milestone))
+
+		milestone, err = model.Get(firstID)
+		require.NoError(t, err)
+		assert.Equal(t, MilestoneStatusComplete, milestone.Status)
+		assert.Equal(t, 750.0, milestone.Fee)
+		assert.True(t, milestone.CanInvoice())
+	})
+
+	t.Run("unknown status is rejected", func(t *testing.T) {
+		_, err := model.Insert(Milestone{ProjectID: projectID, Name: "Index", Status: "Done"})
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("invoice and release", func(t *testing.T) {
+		invoiceID := testDB.InsertTestInvoice(t, projectID, "2024-06-02", "", "Net 30", "750.00")
+		require.NoError(t, model.SetInvoice(firstID, invoiceID))
+
+		milestone, err := model.Get(firstID)
+		require.NoError(t, err)
+		assert.Equal(t, MilestoneStatusInvoiced, milestone.Status)
+		assert.True(t, milestone.IsInvoiced())
+		assert.False(t, milestone.CanInvoice())
+
+		milestones, err := model.GetByProject(projectID)
+		require.NoError(t, err)
+		fees := TotalMilestoneFees(milestones)
+		assert.Equal(t, 2750.0, fees.Total)
+		assert.Equal(t, 750.0, fees.Invoiced)
+		assert.Equal(t, 2000.0, fees.Remaining())
+
+		require.NoError(t, model.ReleaseInvoice(invoiceID))
+		milestone, err = model.Get(firstID)
+		require.NoError(t, err)
+		assert.Equal(t, MilestoneStatusComplete, milestone.Status)
+		assert.Nil(t, milestone.InvoiceID)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, model.Delete(laterID))
+		_, err := model.Get(laterID)
+		assert.Equal(t, ErrNoRecord, err)
+	})
+}
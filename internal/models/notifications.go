@@ -0,0 +1,198 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// Kinds of reminder sent to the chat apps configured in settings
+const (
+	ReminderInvoiceOverdue  = "invoice_overdue"
+	ReminderProjectDeadline = "project_deadline"
+)
+
+// deadlineDoneStatuses are the project statuses past caring about the deadline, the work
+// having been delivered
+var deadlineDoneStatuses = map[string]bool{
+	"Work Complete":       true,
+	"Invoice Sent":        true,
+	ProjectStatusArchived: true,
+}
+
+// Reminder is an alert about something falling due. Kind, SubjectID and DueOn identify it, so
+// it is sent once however often the reminders are checked, and again if the date it is about
+// changes.
+type Reminder struct {
+	Kind      string
+	SubjectID int
+	DueOn     time.Time
+	Text      string
+}
+
+// OverdueInvoice is an invoice with money still owed after the day it was due. LastEmailed
+// is when it was last emailed to the client, for callers that look it up.
+type OverdueInvoice struct {
+	InvoiceWithClient
+	DueOn       time.Time
+	DaysOverdue int
+	Outstanding float64
+	LastEmailed *time.Time
+}
+
+// RemindedRecently reports whether the invoice was emailed less than the given number of days
+// before now, so that the client isn't sent another reminder yet
+func (o OverdueInvoice) RemindedRecently(now time.Time, days int) bool {
+	return o.LastEmailed != nil && daysBetween(*o.LastEmailed, now) < days
+}
+
+// OverdueInvoices returns the invoices with money still owed after the day they were due,
+// worked out from their payment terms when they have no due date
+func OverdueInvoices(invoices []InvoiceWithClient, today time.Time) []OverdueInvoice {
+	today = startOfDay(today)
+	var overdue []OverdueInvoice
+	for _, invoice := range invoices {
+		if invoice.DatePaid != nil || invoice.IsVoided() {
+			continue
+		}
+		outstanding := invoice.BalanceDue() - invoice.TotalPaid()
+		if outstanding < 0.005 {
+			continue
+		}
+		dueOn := DueDateFor(invoice.InvoiceDate, invoice.PaymentTerms)
+		if invoice.DueDate != nil {
+			dueOn = *invoice.DueDate
+		}
+		dueOn = startOfDay(dueOn)
+		if !dueOn.Before(today) {
+			continue
+		}
+
+		overdue = append(overdue, OverdueInvoice{
+			InvoiceWithClient: invoice,
+			DueOn:             dueOn,
+			DaysOverdue:       daysBetween(dueOn, today),
+			Outstanding:       outstanding,
+		})
+	}
+	return overdue
+}
+
+// OverdueInvoiceReminders returns a reminder for each invoice with money still owed after the
+// day it was due, worked out from its payment terms when it has no due date
+func OverdueInvoiceReminders(invoices []InvoiceWithClient, today time.Time, dateFormat DateFormat) []Reminder {
+	var reminders []Reminder
+	for _, invoice := range OverdueInvoices(invoices, today) {
+		reminders = append(reminders, Reminder{
+			Kind:      ReminderInvoiceOverdue,
+			SubjectID: invoice.ID,
+			DueOn:     invoice.DueOn,
+			Text: fmt.Sprintf("Invoice #%s to %s is %s overdue: %.2f was due on %s",
+				invoice.DisplayNumber(), invoice.ClientName, pluralDays(invoice.DaysOverdue), invoice.Outstanding, dateFormat.Format(invoice.DueOn)),
+		})
+	}
+	return reminders
+}
+
+// DeadlineReminders returns a reminder for each project still being worked on whose deadline
+// falls within the given number of days, counting today. Projects already past their deadline
+// are left out, having been reminded of as it came up.
+func DeadlineReminders(projects []ProjectWithClient, today time.Time, days int, dateFormat DateFormat) []Reminder {
+	if days <= 0 {
+		return nil
+	}
+	today = startOfDay(today)
+	last := today.AddDate(0, 0, days)
+	var reminders []Reminder
+	for _, project := range projects {
+		if project.Deadline == nil || deadlineDoneStatuses[project.Status] {
+			continue
+		}
+		deadline := startOfDay(*project.Deadline)
+		if deadline.Before(today) || deadline.After(last) {
+			continue
+		}
+
+		var when string
+		switch left := daysBetween(today, deadline); left {
+		case 0:
+			when = "today"
+		case 1:
+			when = "tomorrow"
+		default:
+			when = "in " + pluralDays(left)
+		}
+		reminders = append(reminders, Reminder{
+			Kind:      ReminderProjectDeadline,
+			SubjectID: project.ID,
+			DueOn:     deadline,
+			Text: fmt.Sprintf("%s for %s is due %s, on %s",
+				project.Name, project.ClientName, when, dateFormat.Format(deadline)),
+		})
+	}
+	sort.SliceStable(reminders, func(i, j int) bool { return reminders[i].DueOn.Before(reminders[j].DueOn) })
+	return reminders
+}
+
+// startOfDay drops the time of day, keeping the date as it reads
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// pluralDays writes a number of days, such as "1 day" or "3 days"
+func pluralDays(days int) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+// NotificationModel wraps the generated SQLC Queries recording which reminders have been sent
+type NotificationModel struct {
+	queries *db.Queries
+}
+
+// NewNotificationModel creates a new NotificationModel
+func NewNotificationModel(database *sql.DB) *NotificationModel {
+	return &NotificationModel{
+		queries: newQueries(database),
+	}
+}
+
+// Claim records a reminder as sent, reporting false when it already was. Claiming before
+// sending keeps a reminder from going out twice when checks overlap; Release it if sending fails.
+func (m *NotificationModel) Claim(reminder Reminder) (bool, error) {
+	ctx := context.Background()
+	rows, err := m.queries.InsertNotificationSent(ctx, db.InsertNotificationSentParams{
+		Kind:      reminder.Kind,
+		SubjectID: int64(reminder.SubjectID),
+		DueOn:     reminder.DueOn.Format("2006-01-02"),
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// Release forgets that a reminder was sent, so the next check tries it again
+func (m *NotificationModel) Release(reminder Reminder) error {
+	ctx := context.Background()
+	return m.queries.DeleteNotificationSent(ctx, db.DeleteNotificationSentParams{
+		Kind:      reminder.Kind,
+		SubjectID: int64(reminder.SubjectID),
+		DueOn:     reminder.DueOn.Format("2006-01-02"),
+	})
+}
+
+// NotificationModelInterface defines the interface for recording sent reminders
+type NotificationModelInterface interface {
+	Claim(reminder Reminder) (bool, error)
+	Release(reminder Reminder) error
+}
+
+// Ensure implementation satisfies the interface
+var _ NotificationModelInterface = (*NotificationModel)(nil)
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverdueInvoiceReminders(t *testing.T) {
+	date := func(value string) time.Time {
+		d, _ := time.Parse("2006-01-02", value)
+		return d
+	}
+	dueDate := date("2024-05-20")
+	paid := date("2024-05-01")
+	partPaid, fullyPaid := 400.0, 500.0
+	invoices := []InvoiceWithClient{
+		{Invoice: Invoice{ID: 1, InvoiceNumber: "INV-7", InvoiceDate: date("2024-04-01"), PaymentTerms: "Net 30", AmountDue: 500, AmountPaid: &partPaid}, ClientName: "Acme"},
+		{Invoice: Invoice{ID: 2, InvoiceDate: date("2024-04-01"), PaymentTerms: "Net 30", AmountDue: 500, DueDate: &dueDate}, ClientName: "Globex"},
+		{Invoice: Invoice{ID: 3, InvoiceDate: date("2024-04-01"), PaymentTerms: "Net 30", AmountDue: 500, DatePaid: &paid}, ClientName: "Initech"},
+		{Invoice: Invoice{ID: 4, InvoiceDate: date("2024-04-01"), PaymentTerms: "Net 30", AmountDue: 500, AmountPaid: &fullyPaid}, ClientName: "Umbrella"},
+	}
+
+	reminders := OverdueInvoiceReminders(invoices, date("2024-05-02").Add(15*time.Hour), DateFormatISO)
+	require.Len(t, reminders, 1, "invoices paid or not yet due are left out")
+	assert.Equal(t, ReminderInvoiceOverdue, reminders[0].Kind)
+	assert.Equal(t, 1, reminders[0].SubjectID)
+	assert.Equal(t, date("2024-05-01"), reminders[0].DueOn)
+	assert.Equal(t, "Invoice #INV-7 to Acme is 1 day overdue: 100.00 was due on 2024-05-01", reminders[0].Text)
+
+	reminders = OverdueInvoiceReminders(invoices, date("2024-05-25"), DateFormatUS)
+	require.Len(t, reminders, 2)
+	assert.Equal(t, "Invoice #0002 to Globex is 5 days overdue: 500.00 was due on 05/20/2024", reminders[1].Text)
+}
+
+func TestOverdueInvoices(t *testing.T) {
+	date := func(value string) time.Time {
+		d, _ := time.Parse("2006-01-02", value)
+		return d
+	}
+	partPaid := 150.0
+	invoices := []InvoiceWithClient{
+		{Invoice: Invoice{ID: 1, InvoiceDate: date("2024-04-01"), PaymentTerms: "Net 30", AmountDue: 500, AmountPaid: &partPaid}, ClientName: "Acme"},
+		{Invoice: Invoice{ID: 2, InvoiceDate: date("2024-05-01"), PaymentTerms: "Net 30", AmountDue: 500}, ClientName: "Globex"},
+	}
+
+	overdue := OverdueInvoices(invoices, date("2024-05-11"))
+	require.Len(t, overdue, 1)
+	assert.Equal(t, 1, overdue[0].ID)
+	assert.Equal(t, date("2024-05-01"), overdue[0].DueOn)
+	assert.Equal(t, 10, overdue[0].DaysOverdue)
+	assert.InDelta(t, 350.0, overdue[0].Outstanding, 0.001)
+
+	now := date("2024-05-11").Add(9 * time.Hour)
+	assert.False(t, overdue[0].RemindedRecently(now, 7), "never emailed")
+	emailed := date("2024-05-05").Add(18 * time.Hour)
+	overdue[0].LastEmailed = &emailed
+	assert.True(t, overdue[0].RemindedRecently(now, 7))
+	assert.False(t, overdue[0].RemindedRecently(now, 6), "emailed six days ago")
+	assert.False(t, overdue[0].RemindedRecently(now, 0), "no interval always reminds")
+}
+
+func TestDeadlineReminders(t *testing.T) {
+	date := func(value string) *time.Time {
+		d, _ := time.Parse("2006-01-02", value)
+		return &d
+	}
+	projects := []ProjectWithClient{
+		{ID: 1, Name: "Thesis", ClientName: "Ann", Status: "In Progress", Deadline: date("2024-05-05")},
+		{ID: 2, Name: "Article", ClientName: "Bob", Status: "Scheduled", Deadline: date("2024-05-02")},
+		{ID: 3, Name: "Book", ClientName: "Cat", Status: "Work Complete", Deadline: date("2024-05-03")},
+		{ID: 4, Name: "Grant", ClientName: "Dan", Status: "In Progress", Deadline: date("2024-05-06")},
+		{ID: 5, Name: "Essay", ClientName: "Eve", Status: "In Progress", Deadline: date("2024-05-01")},
+		{ID: 6, Name: "Poster", ClientName: "Fay", Status: "In Progress"},
+		{ID: 7, Name: "Review", ClientName: "Gus", Status: "Estimating", Deadline: date("2024-05-03")},
+	}
+
+	reminders := DeadlineReminders(projects, *date("2024-05-02"), 3, DateFormatISO)
+	require.Len(t, reminders, 3)
+	assert.Equal(t, "Article for Bob is due today, on 2024-05-02", reminders[0].Text)
+	assert.Equal(t, "Review for Gus is due tomorrow, on 2024-05-03", reminders[1].Text)
+	assert.Equal(t, "Thesis for Ann is due in 3 days, on 2024-05-05", reminders[2].Text)
+	assert.Equal(t, ReminderProjectDeadline, reminders[2].Kind)
+	assert.Equal(t, 1, reminders[2].SubjectID)
+
+	assert.Empty(t, DeadlineReminders(projects, *date("2024-05-02"), 0, DateFormatISO), "0 days turns deadline reminders off")
+}
+
+func TestNotificationModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewNotificationModel(testDB.DB)
+	reminder := Reminder{Kind: ReminderInvoiceOverdue, SubjectID: 7, DueOn: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+
+	claimed, err := model.Claim(reminder)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = model.Claim(reminder)
+	require.NoError(t, err)
+	assert.False(t, claimed, "a reminder is only sent once")
+
+	moved := reminder
+	moved.DueOn = moved.DueOn.AddDate(0, 0, 14)
+	claimed, err = model.Claim(moved)
+	require.NoError(t, err)
+	assert.True(t, claimed, "a new due date is reminded of again")
+
+	require.NoError(t, model.Release(reminder))
+	claimed, err = model.Claim(reminder)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// PaymentTermsPreset is payment terms offered for choosing on the invoice form. Invoices copy
+// the terms they are given, so changing the presets doesn't alter invoices already issued.
+type PaymentTermsPreset struct {
+	ID      int
+	Terms   string
+	Created time.Time
+}
+
+// MatchPaymentTermsPreset returns the preset with exactly the given terms, if there is one
+func MatchPaymentTermsPreset(presets []PaymentTermsPreset, terms string) (PaymentTermsPreset, bool) {
+	for _, preset := range presets {
+		if preset.Terms == terms {
+			return preset, true
+		}
+	}
+	return PaymentTermsPreset{}, false
+}
+
+// PaymentTermsPresetModel wraps the generated SQLC Queries for payment terms preset operations
+type PaymentTermsPresetModel struct {
+	queries *db.Queries
+}
+
+// NewPaymentTermsPresetModel creates a new PaymentTermsPresetModel
+func NewPaymentTermsPresetModel(database *sql.DB) *PaymentTermsPresetModel {
+	return &PaymentTermsPresetModel{
+		queries: newQueries(database),
+	}
+}
+
+// Insert adds payment terms to the presets and returns the preset's ID. Terms already
+// offered are rejected with a ConstraintError matching ErrDuplicate.
+func (m *PaymentTermsPresetModel) Insert(terms string) (int, error) {
+	ctx := context.Background()
+	id, err := m.queries.InsertPaymentTermsPreset(ctx, terms)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return int(id), nil
+}
+
+// GetAll retrieves the presets in the order they were added
+func (m *PaymentTermsPresetModel) GetAll() ([]PaymentTermsPreset, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetPaymentTermsPresets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	presets := make([]PaymentTermsPreset, len(rows))
+	for i, row := range rows {
+		presets[i] = PaymentTermsPreset{
+			ID:      int(row.ID),
+			Terms:   row.Terms,
+			Created: row.CreatedAt,
+		}
+	}
+	return presets, nil
+}
+
+// Delete removes a preset. Invoices already given its terms keep them.
+func (m *PaymentTermsPresetModel) Delete(id int) error {
+	ctx := context.Background()
+	rows, err := m.queries.DeletePaymentTermsPreset(ctx, int64(id))
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
+
+// PaymentTermsPresetModelInterface defines the interface for payment terms preset operations
+type PaymentTermsPresetModelInterface interface {
+	Insert(terms string) (int, error)
+	GetAll() ([]PaymentTermsPreset, error)
+	Delete(id int) error
+}
+
+// Ensure implementation satisfies the interface
+var _ PaymentTermsPresetModelInterface = (*PaymentTermsPresetModel)(nil)
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentTermsPresetModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewPaymentTermsPresetModel(testDB.DB)
+
+	netID, err := model.Insert("Net 30")
+	require.NoError(t, err)
+	_, err = model.Insert("Due on receipt")
+	require.NoError(t, err)
+
+	_, err = model.Insert("Net 30")
+	assert.True(t, errors.Is(err, ErrDuplicate), "the same terms can't be a preset twice")
+
+	presets, err := model.GetAll()
+	require.NoError(t, err)
+	require.Len(t, presets, 2)
+	assert.Equal(t, "Net 30", presets[0].Terms, "presets keep the order they were added in")
+	assert.Equal(t, "Due on receipt", presets[1].Terms)
+
+	preset, ok := MatchPaymentTermsPreset(presets, "Due on receipt")
+	assert.True(t, ok)
+	assert.Equal(t, presets[1], preset)
+	_, ok = MatchPaymentTermsPreset(presets, "net 30")
+	assert.False(t, ok, "only exactly the same terms match")
+
+	require.NoError(t, model.Delete(netID))
+	assert.Equal(t, ErrNoRecord, model.Delete(netID))
+
+	presets, err = model.GetAll()
+	require.NoError(t, err)
+	require.Len(t, presets, 1)
+}
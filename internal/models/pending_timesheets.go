@@ -0,0 +1,122 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// PendingTimesheet is a timesheet entry captured from inbound email that hasn't been
+// confirmed yet. Source records where it came from, e.g. the email subject.
+type PendingTimesheet struct {
+	ID          int
+	ProjectID   int
+	ProjectName string
+	WorkDate    time.Time
+	HoursWorked float64
+	Description string
+	Source      string
+	Created     time.Time
+}
+
+// PendingTimesheetModel wraps the generated SQLC Queries for pending timesheet operations
+type PendingTimesheetModel struct {
+	queries *db.Queries
+}
+
+// NewPendingTimesheetModel creates a new PendingTimesheetModel
+func NewPendingTimesheetModel(database *sql.DB) *PendingTimesheetModel {
+	return &PendingTimesheetModel{
+		queries: newQueries(database),
+	}
+}
+
+// NewPendingTimesheetModelWithTx creates a PendingTimesheetModel whose queries run inside the given transaction
+func NewPendingTimesheetModelWithTx(tx *sql.Tx) *PendingTimesheetModel {
+	return &PendingTimesheetModel{
+		queries: newQueries(tx),
+	}
+}
+
+// Insert adds a pending timesheet entry and returns its ID
+func (p *PendingTimesheetModel) Insert(projectID int, workDate time.Time, hoursWorked float64, description, source string) (int, error) {
+	ctx := context.Background()
+	id, err := p.queries.InsertPendingTimesheet(ctx, db.InsertPendingTimesheetParams{
+		ProjectID:   int64(projectID),
+		WorkDate:    workDate,
+		HoursWorked: hoursWorked,
+		Description: description,
+		Source:      source,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// Get retrieves a pending timesheet entry by ID
+func (p *PendingTimesheetModel) Get(id int) (PendingTimesheet, error) {
+	ctx := context.Background()
+	row, err := p.queries.GetPendingTimesheet(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return PendingTimesheet{}, ErrNoRecord
+		}
+		return PendingTimesheet{}, err
+	}
+
+	return PendingTimesheet{
+		ID:          int(row.ID),
+		ProjectID:   int(row.ProjectID),
+		ProjectName: row.ProjectName,
+		WorkDate:    row.WorkDate,
+		HoursWorked: row.HoursWorked,
+		Description: row.Description,
+		Source:      row.Source,
+		Created:     row.CreatedAt,
+	}, nil
+}
+
+// GetAll retrieves every pending timesheet entry, oldest work first
+func (p *PendingTimesheetModel) GetAll() ([]PendingTimesheet, error) {
+	ctx := context.Background()
+	rows, err := p.queries.GetAllPendingTimesheets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]PendingTimesheet, len(rows))
+	for i, row := range rows {
+		pending[i] = PendingTimesheet{
+			ID:          int(row.ID),
+			ProjectID:   int(row.ProjectID),
+			ProjectName: row.ProjectName,
+			WorkDate:    row.WorkDate,
+			HoursWorked: row.HoursWorked,
+			Description: row.Description,
+			Source:      row.Source,
+			Created:     row.CreatedAt,
+		}
+	}
+	return pending, nil
+}
+
+// Delete removes a pending timesheet entry once it has been confirmed or discarded
+func (p *PendingTimesheetModel) Delete(id int) error {
+	ctx := context.Background()
+	return p.queries.DeletePendingTimesheet(ctx, int64(id))
+}
+
+// PendingTimesheetModelInterface defines the interface for pending timesheet operations
+type PendingTimesheetModelInterface interface {
+	Insert(projectID int, workDate time.Time, hoursWorked float64, description, source string) (int, error)
+	Get(id int) (PendingTimesheet, error)
+	GetAll() ([]PendingTimesheet, error)
+	Delete(id int) error
+}
+
+// Ensure implementation satisfies the interface
+var _ PendingTimesheetModelInterface = (*PendingTimesheetModel)(nil)
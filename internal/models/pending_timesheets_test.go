@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingTimesheetModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewPendingTimesheetModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Inbound Client")
+	projectID := testDB.InsertTestProject(t, "Inbound Project", clientID)
+	later := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	earlier := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	laterID, err := model.Insert(projectID, later, 2, "chapter 2", "Timesheet")
+	require.NoError(t, err)
+	earlierID, err := model.Insert(projectID, earlier, 1.25, "chapter 1", "Timesheet")
+	require.NoError(t, err)
+
+	pending, err := model.Get(laterID)
+	require.NoError(t, err)
+	assert.Equal(t, "Inbound Project", pending.ProjectName)
+	assert.Equal(t, 2.0, pending.HoursWorked)
+	assert.Equal(t, "chapter 2", pending.Description)
+	assert.Equal(t, "Timesheet", pending.Source)
+	assert.True(t, later.Equal(pending.WorkDate))
+
+	all, err := model.GetAll()
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, earlierID, all[0].ID, "oldest work comes first")
+
+	require.NoError(t, model.Delete(laterID))
+	_, err = model.Get(laterID)
+	assert.Equal(t, ErrNoRecord, err)
+}
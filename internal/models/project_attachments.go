@@ -0,0 +1,125 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// ProjectAttachment is a supporting document kept with a project, such as a receipt, that can
+// be emailed along with the project's invoices. Path is where the file is stored.
+type ProjectAttachment struct {
+	ID          int
+	ProjectID   int
+	Filename    string
+	ContentType string
+	Size        int64
+	Path        string
+	Created     time.Time
+}
+
+// SizeLabel returns the attachment's size for display, e.g. "240 KB"
+func (a ProjectAttachment) SizeLabel() string {
+	return FormatFileSize(a.Size)
+}
+
+// FormatFileSize returns a number of bytes in the largest unit that keeps it at least one
+func FormatFileSize(bytes int64) string {
+	switch {
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%d KB", bytes/(1<<10))
+	default:
+		return fmt.Sprintf("%d bytes", bytes)
+	}
+}
+
+// ProjectAttachmentModel wraps the generated SQLC Queries for project attachment operations
+type ProjectAttachmentModel struct {
+	queries *db.Queries
+}
+
+// NewProjectAttachmentModel creates a new ProjectAttachmentModel
+func NewProjectAttachmentModel(database *sql.DB) *ProjectAttachmentModel {
+	return &ProjectAttachmentModel{
+		queries: newQueries(database),
+	}
+}
+
+// Insert records a file stored for a project and returns its ID
+func (m *ProjectAttachmentModel) Insert(attachment ProjectAttachment) (int, error) {
+	ctx := context.Background()
+	id, err := m.queries.InsertProjectAttachment(ctx, db.InsertProjectAttachmentParams{
+		ProjectID:   int64(attachment.ProjectID),
+		Filename:    attachment.Filename,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		Path:        attachment.Path,
+	})
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return int(id), nil
+}
+
+// Get retrieves a project attachment by ID
+func (m *ProjectAttachmentModel) Get(id int) (ProjectAttachment, error) {
+	ctx := context.Background()
+	row, err := m.queries.GetProjectAttachment(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ProjectAttachment{}, ErrNoRecord
+		}
+		return ProjectAttachment{}, err
+	}
+	return newProjectAttachment(row), nil
+}
+
+// GetByProject retrieves a project's attachments, newest first
+func (m *ProjectAttachmentModel) GetByProject(projectID int) ([]ProjectAttachment, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetProjectAttachmentsByProject(ctx, int64(projectID))
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make([]ProjectAttachment, len(rows))
+	for i, row := range rows {
+		attachments[i] = newProjectAttachment(row)
+	}
+	return attachments, nil
+}
+
+// Delete removes the record of an attachment. The stored file is left to the caller.
+func (m *ProjectAttachmentModel) Delete(id int) error {
+	ctx := context.Background()
+	return m.queries.DeleteProjectAttachment(ctx, int64(id))
+}
+
+func newProjectAttachment(row db.ProjectAttachment) ProjectAttachment {
+	return ProjectAttachment{
+		ID:          int(row.ID),
+		ProjectID:   int(row.ProjectID),
+		Filename:    row.Filename,
+		ContentType: row.ContentType,
+		Size:        row.Size,
+		Path:        row.Path,
+		Created:     row.CreatedAt,
+	}
+}
+
+// ProjectAttachmentModelInterface defines the interface for project attachment operations
+type ProjectAttachmentModelInterface interface {
+	Insert(attachment ProjectAttachment) (int, error)
+	Get(id int) (ProjectAttachment, error)
+	GetByProject(projectID int) ([]ProjectAttachment, error)
+	Delete(id int) error
+}
+
+// Ensure implementation satisfies the interface
+var _ ProjectAttachmentModelInterface = (*ProjectAttachmentModel)(nil)
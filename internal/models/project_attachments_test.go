@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectAttachmentModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewProjectAttachmentModel(testDB.DB)
+	clientID := testDB.InsertTestClient(t, "Attachment Client")
+	projectID := testDB.InsertTestProject(t, "Receipts", clientID)
+	otherProjectID := testDB.InsertTestProject(t, "Other", clientID)
+
+	receiptID, err := model.Insert(ProjectAttachment{ProjectID: projectID, Filename: "taxi.pdf", ContentType: "application/pdf", Size: 2048, Path: "/uploads/a.pdf"})
+	require.NoError(t, err)
+	scanID, err := model.Insert(ProjectAttachment{ProjectID: projectID, Filename: "scan.png", ContentType: "image/png", Size: 3 << 20, Path: "/uploads/b.png"})
+	require.NoError(t, err)
+	_, err = model.Insert(ProjectAttachment{ProjectID: otherProjectID, Filename: "other.pdf", ContentType: "application/pdf", Size: 10, Path: "/uploads/c.pdf"})
+	require.NoError(t, err)
+
+	t.Run("get returns the attachment", func(t *testing.T) {
+		attachment, err := model.Get(receiptID)
+		require.NoError(t, err)
+		assert.Equal(t, projectID, attachment.ProjectID)
+		assert.Equal(t, "taxi.pdf", attachment.Filename)
+		assert.Equal(t, "application/pdf", attachment.ContentType)
+		assert.Equal(t, int64(2048), attachment.Size)
+		assert.Equal(t, "/uploads/a.pdf", attachment.Path)
+		assert.Equal(t, "2 KB", attachment.SizeLabel())
+
+		_, err = model.Get(99999)
+		assert.ErrorIs(t, err, ErrNoRecord)
+	})
+
+	t.Run("a project's attachments are listed newest first", func(t *testing.T) {
+		attachments, err := model.GetByProject(projectID)
+		require.NoError(t, err)
+		require.Len(t, attachments, 2)
+		assert.Equal(t, scanID, attachments[0].ID)
+		assert.Equal(t, receiptID, attachments[1].ID)
+		assert.Equal(t, "3.0 MB", attachments[0].SizeLabel())
+	})
+
+	t.Run("attachments can be deleted", func(t *testing.T) {
+		require.NoError(t, model.Delete(receiptID))
+		_, err := model.Get(receiptID)
+		assert.ErrorIs(t, err, ErrNoRecord)
+	})
+
+	t.Run("an attachment needs an existing project", func(t *testing.T) {
+		_, err := model.Insert(ProjectAttachment{ProjectID: 99999, Filename: "x.pdf", ContentType: "application/pdf", Path: "/uploads/x.pdf"})
+		assert.Error(t, err)
+	})
+}
+
+func TestFormatFileSize(t *testing.T) {
+	assert.Equal(t, "512 bytes", FormatFileSize(512))
+	assert.Equal(t, "1 KB", FormatFileSize(1024))
+	assert.Equal(t, "20.0 MB", FormatFileSize(20<<20))
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// ProjectDefaults are the terms a client's new projects start from. They are kept apart from
+// the client's own fields and only fill in the new project form; existing projects don't
+// follow later changes.
+type ProjectDefaults struct {
+	ClientID               int
+	Status                 string
+	CurrencyDisplay        string
+	CurrencyConversionRate float64
+	DiscountPercent        *float64
+	DiscountReason         string
+	FlatFeeInvoice         bool
+	Updated                time.Time
+}
+
+// ProjectDefaultsModel wraps the generated SQLC Queries for clients' project defaults
+type ProjectDefaultsModel struct {
+	queries *db.Queries
+}
+
+// NewProjectDefaultsModel creates a new ProjectDefaultsModel
+func NewProjectDefaultsModel(database *sql.DB) *ProjectDefaultsModel {
+	return &ProjectDefaultsModel{
+		queries: newQueries(database),
+	}
+}
+
+// Get retrieves a client's project defaults, returning ErrNoRecord when none are set
+func (m *ProjectDefaultsModel) Get(clientID int) (ProjectDefaults, error) {
+	ctx := context.Background()
+	row, err := m.queries.GetClientProjectDefaults(ctx, int64(clientID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ProjectDefaults{}, ErrNoRecord
+		}
+		return ProjectDefaults{}, err
+	}
+	return ProjectDefaults{
+		ClientID:               int(row.ClientID),
+		Status:                 row.Status,
+		CurrencyDisplay:        row.CurrencyDisplay,
+		CurrencyConversionRate: row.CurrencyConversionRate,
+		DiscountPercent:        convertNullFloat64(row.DiscountPercent),
+		DiscountReason:         row.DiscountReason,
+		FlatFeeInvoice:         row.FlatFeeInvoice,
+		Updated:                row.UpdatedAt,
+	}, nil
+}
+
+// Save sets a client's project defaults, replacing any set before
+func (m *ProjectDefaultsModel) Save(defaults ProjectDefaults) error {
+	ctx := context.Background()
+	return m.queries.SaveClientProjectDefaults(ctx, db.SaveClientProjectDefaultsParams{
+		ClientID:               int64(defaults.ClientID),
+		Status:                 defaults.Status,
+		CurrencyDisplay:        defaults.CurrencyDisplay,
+		CurrencyConversionRate: defaults.CurrencyConversionRate,
+		DiscountPercent:        convertFloatPtr(defaults.DiscountPercent),
+		DiscountReason:         defaults.DiscountReason,
+		FlatFeeInvoice:         defaults.FlatFeeInvoice,
+	})
+}
+
+// Delete clears a client's project defaults, so its new projects start from the usual ones
+func (m *ProjectDefaultsModel) Delete(clientID int) error {
+	ctx := context.Background()
+	return m.queries.DeleteClientProjectDefaults(ctx, int64(clientID))
+}
+
+// ProjectDefaultsModelInterface defines the interface for clients' project defaults
+type ProjectDefaultsModelInterface interface {
+	Get(clientID int) (ProjectDefaults, error)
+	Save(defaults ProjectDefaults) error
+	Delete(clientID int) error
+}
+
+// Ensure implementation satisfies the interface
+var _ ProjectDefaultsModelInterface = (*ProjectDefaultsModel)(nil)
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectDefaultsModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewProjectDefaultsModel(testDB.DB)
+	clientID := testDB.InsertTestClient(t, "Defaults Client")
+
+	_, err := model.Get(clientID)
+	assert.True(t, errors.Is(err, ErrNoRecord), "clients start without project defaults")
+
+	discount := 0.1
+	require.NoError(t, model.Save(ProjectDefaults{
+		ClientID:               clientID,
+		Status:                 "Scheduled",
+		CurrencyDisplay:        "EUR",
+		CurrencyConversionRate: 0.92,
+		DiscountPercent:        &discount,
+		DiscountReason:         "Long-standing client",
+		FlatFeeInvoice:         true,
+	}))
+
+	defaults, err := model.Get(clientID)
+	require.NoError(t, err)
+	assert.Equal(t, "Scheduled", defaults.Status)
+	assert.Equal(t, "EUR", defaults.CurrencyDisplay)
+	assert.Equal(t, 0.92, defaults.CurrencyConversionRate)
+	require.NotNil(t, defaults.DiscountPercent)
+	assert.Equal(t, 0.1, *defaults.DiscountPercent)
+	assert.Equal(t, "Long-standing client", defaults.DiscountReason)
+	assert.True(t, defaults.FlatFeeInvoice)
+
+	defaults.DiscountPercent = nil
+	defaults.FlatFeeInvoice = false
+	require.NoError(t, model.Save(defaults))
+	defaults, err = model.Get(clientID)
+	require.NoError(t, err)
+	assert.Nil(t, defaults.DiscountPercent, "saving again replaces the defaults")
+	assert.False(t, defaults.FlatFeeInvoice)
+
+	require.NoError(t, model.Delete(clientID))
+	_, err = model.Get(clientID)
+	assert.True(t, errors.Is(err, ErrNoRecord))
+}
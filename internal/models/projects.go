@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
@@ -31,11 +33,120 @@ type Project struct {
 	CurrencyConversionRate float64
 	FlatFeeInvoice         bool
 	Notes                  string
+	BusinessProfileID      *int
+	EstimatedHours         *float64
+	EstimatedAmount        *float64
+	BudgetCode             string
 	Updated                time.Time
 	Created                time.Time
 	DeletedAt              *time.Time
 }
 
+// ProjectStatusArchived marks a finished project whose timesheets and invoices are kept
+// read-only until it is reopened
+const ProjectStatusArchived = "Archived"
+
+// ProjectStatusReopened is the status an archived project returns to when reopened
+const ProjectStatusReopened = "Work Complete"
+
+// NewProjectStatuses lists the statuses a new project can start in, in the order work moves
+// through them
+var NewProjectStatuses = []string{"Estimating", "Scheduled", "In Progress", "Work Complete", "Invoice Sent"}
+
+// IsArchived reports whether the project's timesheets and invoices are read-only
+func (p Project) IsArchived() bool {
+	return p.Status == ProjectStatusArchived
+}
+
+// ProjectEstimate compares the hours and fee estimated for a project with the time actually
+// logged against it. Either estimate may be missing, as HasHours and HasAmount record.
+type ProjectEstimate struct {
+	HasHours        bool
+	EstimatedHours  float64
+	ActualHours     float64
+	HasAmount       bool
+	EstimatedAmount float64
+	ActualAmount    float64
+}
+
+// NewProjectEstimate returns the comparison for a project, or nil when it has no estimates
+func NewProjectEstimate(estimatedHours, estimatedAmount *float64, actualHours, actualAmount float64) *ProjectEstimate {
+	if estimatedHours == nil && estimatedAmount == nil {
+		return nil
+	}
+	estimate := &ProjectEstimate{ActualHours: actualHours, ActualAmount: actualAmount}
+	if estimatedHours != nil {
+		estimate.HasHours, estimate.EstimatedHours = true, *estimatedHours
+	}
+	if estimatedAmount != nil {
+		estimate.HasAmount, estimate.EstimatedAmount = true, *estimatedAmount
+	}
+	return estimate
+}
+
+// HoursVariance returns the hours logged beyond the estimate, which is negative while under it
+func (e ProjectEstimate) HoursVariance() float64 {
+	if !e.HasHours {
+		return 0
+	}
+	return e.ActualHours - e.EstimatedHours
+}
+
+// AmountVariance returns the amount billable beyond the estimated fee, which is negative while under it
+func (e ProjectEstimate) AmountVariance() float64 {
+	if !e.HasAmount {
+		return 0
+	}
+	return e.ActualAmount - e.EstimatedAmount
+}
+
+// HoursRemaining returns the estimated hours not yet logged, which is negative once over
+func (e ProjectEstimate) HoursRemaining() float64 {
+	return -e.HoursVariance()
+}
+
+// AmountRemaining returns the estimated fee not yet billable, which is negative once over
+func (e ProjectEstimate) AmountRemaining() float64 {
+	return -e.AmountVariance()
+}
+
+// HoursOver reports whether more hours have been logged than were estimated
+func (e ProjectEstimate) HoursOver() bool {
+	return e.HoursVariance() > 0
+}
+
+// AmountOver reports whether more is billable than the estimated fee
+func (e ProjectEstimate) AmountOver() bool {
+	return e.AmountVariance() > 0
+}
+
+// HoursPercent returns the share of the estimated hours used, capped at 100 for display
+func (e ProjectEstimate) HoursPercent() float64 {
+	if !e.HasHours {
+		return 0
+	}
+	return estimatePercent(e.ActualHours, e.EstimatedHours)
+}
+
+// AmountPercent returns the share of the estimated fee used, capped at 100 for display
+func (e ProjectEstimate) AmountPercent() float64 {
+	if !e.HasAmount {
+		return 0
+	}
+	return estimatePercent(e.ActualAmount, e.EstimatedAmount)
+}
+
+// estimatePercent returns actual as a share of estimate, capped at 100
+func estimatePercent(actual, estimate float64) float64 {
+	if estimate <= 0 {
+		if actual > 0 {
+			return 100
+		}
+		return 0
+	}
+	return math.Min(actual/estimate*100, 100)
+}
+
 // ProjectWithClient represents a project with client information for list views
 type ProjectWithClient struct {
 	ID                     int
@@ -59,20 +170,39 @@ type ProjectWithClient struct {
 	CurrencyConversionRate float64
 	FlatFeeInvoice         bool
 	Notes                  string
+	EstimatedHours         *float64
+	EstimatedAmount        *float64
+	ActualHours            float64
+	ActualAmount           float64
 	Updated                time.Time
 	Created                time.Time
 	DeletedAt              *time.Time
 }
 
+// Estimate returns how the time logged on the project compares with its estimates, or nil
+// when it has none
+func (p ProjectWithClient) Estimate() *ProjectEstimate {
+	return NewProjectEstimate(p.EstimatedHours, p.EstimatedAmount, p.ActualHours, p.ActualAmount)
+}
+
 // ProjectModel wraps the generated SQLC Queries for project operations
 type ProjectModel struct {
+	db      *sql.DB
 	queries *db.Queries
 }
 
 // NewProjectModel creates a new ProjectModel
 func NewProjectModel(database *sql.DB) *ProjectModel {
 	return &ProjectModel{
-		queries: db.New(database),
+		db:      database,
+		queries: newQueries(database),
+	}
+}
+
+// NewProjectModelWithTx creates a ProjectModel whose queries run inside the given transaction
+func NewProjectModelWithTx(tx *sql.Tx) *ProjectModel {
+	return &ProjectModel{
+		queries: newQueries(tx),
 	}
 }
 
@@ -124,6 +254,10 @@ func (p *ProjectModel) Insert(project Project) (int, error) {
 		CurrencyConversionRate: project.CurrencyConversionRate,
 		FlatFeeInvoice:         0, // Convert bool to int64 (0 = false, 1 = true)
 		Notes:                  stringToNullString(project.Notes),
+		BusinessProfileID:      convertIntPtr(project.BusinessProfileID),
+		EstimatedHours:         floatToNullFloat64(project.EstimatedHours),
+		EstimatedAmount:        floatToNullFloat64(project.EstimatedAmount),
+		BudgetCode:             stringToNullString(project.BudgetCode),
 	}
 
 	// Convert bool to int64 for SQLite
@@ -196,6 +330,10 @@ func (p *ProjectModel) Get(id int) (Project, error) {
 		CurrencyConversionRate: row.CurrencyConversionRate,
 		FlatFeeInvoice:         row.FlatFeeInvoice != 0,
 		Notes:                  row.Notes.String,
+		BusinessProfileID:      convertNullInt64(row.BusinessProfileID),
+		EstimatedHours:         nullFloat64ToFloat(row.EstimatedHours),
+		EstimatedAmount:        nullFloat64ToFloat(row.EstimatedAmount),
+		BudgetCode:             row.BudgetCode.String,
 		Updated:                row.UpdatedAt,
 		Created:                row.CreatedAt,
 		DeletedAt:              deletedAt,
@@ -260,6 +398,10 @@ func (p *ProjectModel) GetByClient(clientID int) ([]Project, error) {
 			CurrencyConversionRate: row.CurrencyConversionRate,
 			FlatFeeInvoice:         row.FlatFeeInvoice != 0,
 			Notes:                  row.Notes.String,
+			BusinessProfileID:      convertNullInt64(row.BusinessProfileID),
+			EstimatedHours:         nullFloat64ToFloat(row.EstimatedHours),
+			EstimatedAmount:        nullFloat64ToFloat(row.EstimatedAmount),
+			BudgetCode:             row.BudgetCode.String,
 			Updated:                row.UpdatedAt,
 			Created:                row.CreatedAt,
 			DeletedAt:              deletedAt,
@@ -314,6 +456,10 @@ func (p *ProjectModel) Update(project Project) error {
 		CurrencyConversionRate: project.CurrencyConversionRate,
 		FlatFeeInvoice:         0,
 		Notes:                  stringToNullString(project.Notes),
+		BusinessProfileID:      convertIntPtr(project.BusinessProfileID),
+		EstimatedHours:         floatToNullFloat64(project.EstimatedHours),
+		EstimatedAmount:        floatToNullFloat64(project.EstimatedAmount),
+		BudgetCode:             stringToNullString(project.BudgetCode),
 		ID:                     int64(project.ID),
 	}
 
@@ -325,10 +471,49 @@ func (p *ProjectModel) Update(project Project) error {
 	return p.queries.UpdateProject(ctx, params)
 }
 
-// Delete soft deletes a project by setting the deleted_at timestamp
+// SetStatus changes only a project's status, e.g. to archive or reopen it
+func (p *ProjectModel) SetStatus(id int, status string) error {
+	ctx := context.Background()
+	return p.queries.SetProjectStatus(ctx, db.SetProjectStatusParams{
+		Status: status,
+		ID:     int64(id),
+	})
+}
+
+// Delete soft deletes a project along with its timesheets, invoices and milestones. They are
+// all given the project's deleted_at timestamp so that Restore can tell them apart from those
+// deleted on their own beforehand.
 func (p *ProjectModel) Delete(id int) error {
 	ctx := context.Background()
-	return p.queries.DeleteProject(ctx, int64(id))
+	return inTx(ctx, p.db, p.queries, func(q *db.Queries) error {
+		if err := q.DeleteProject(ctx, int64(id)); err != nil {
+			return err
+		}
+		if err := q.DeleteProjectTimesheets(ctx, int64(id)); err != nil {
+			return err
+		}
+		if err := q.DeleteProjectInvoices(ctx, int64(id)); err != nil {
+			return err
+		}
+		return q.DeleteProjectMilestones(ctx, int64(id))
+	})
+}
+
+// Restore undeletes a project along with the timesheets, invoices and milestones deleted with it
+func (p *ProjectModel) Restore(id int) error {
+	ctx := context.Background()
+	return inTx(ctx, p.db, p.queries, func(q *db.Queries) error {
+		if err := q.RestoreProjectTimesheets(ctx, int64(id)); err != nil {
+			return err
+		}
+		if err := q.RestoreProjectInvoices(ctx, int64(id)); err != nil {
+			return err
+		}
+		if err := q.RestoreProjectMilestones(ctx, int64(id)); err != nil {
+			return err
+		}
+		return q.RestoreProject(ctx, int64(id))
+	})
 }
 
 // GetWithPagination retrieves projects with client information using pagination
@@ -354,6 +539,51 @@ func (p *ProjectModel) GetWithPagination(limit, offset int64) ([]ProjectWithClie
 	return projects, nil
 }
 
+// GetWithPaginationByTag retrieves a page of the projects carrying the given tag, with client information
+func (p *ProjectModel) GetWithPaginationByTag(tagID int, limit, offset int64) ([]ProjectWithClient, error) {
+	ctx := context.Background()
+	rows, err := p.queries.GetProjectsByTagWithClientPagination(ctx, db.GetProjectsByTagWithClientPaginationParams{
+		TagID:  int64(tagID),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]ProjectWithClient, len(rows))
+	for i, row := range rows {
+		project, err := p.convertPaginationRowToProjectWithClient(db.GetProjectsWithClientPaginationRow(row))
+		if err != nil {
+			return nil, err
+		}
+		projects[i] = project
+	}
+
+	return projects, nil
+}
+
+// GetByIDs retrieves the non-deleted projects with the given IDs, with client information, in
+// the order of the IDs
+func (p *ProjectModel) GetByIDs(ids []int) ([]ProjectWithClient, error) {
+	ctx := context.Background()
+	rows, err := p.queries.GetProjectsWithClientByIDs(ctx, toInt64s(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]ProjectWithClient, len(rows))
+	for i, row := range rows {
+		project, err := p.convertPaginationRowToProjectWithClient(db.GetProjectsWithClientPaginationRow(row))
+		if err != nil {
+			return nil, err
+		}
+		projects[i] = project
+	}
+	sortByIDs(projects, ids, func(project ProjectWithClient) int { return project.ID })
+	return projects, nil
+}
+
 // convertPaginationRowToProjectWithClient converts a pagination database row to a ProjectWithClient struct
 func (p *ProjectModel) convertPaginationRowToProjectWithClient(row db.GetProjectsWithClientPaginationRow) (ProjectWithClient, error) {
 	// Helper function to convert sql.NullString to string
@@ -405,6 +635,10 @@ func (p *ProjectModel) convertPaginationRowToProjectWithClient(row db.GetProject
 		CurrencyConversionRate: row.CurrencyConversionRate,
 		FlatFeeInvoice:         row.FlatFeeInvoice == 1,
 		Notes:                  nullStringToString(row.Notes),
+		EstimatedHours:         nullFloat64ToFloat(row.EstimatedHours),
+		EstimatedAmount:        nullFloat64ToFloat(row.EstimatedAmount),
+		ActualHours:            row.ActualHours,
+		ActualAmount:           row.ActualAmount,
 		Updated:                row.UpdatedAt,
 		Created:                row.CreatedAt,
 	}, nil
@@ -416,6 +650,50 @@ func (p *ProjectModel) GetCount() (int64, error) {
 	return p.queries.GetProjectsCount(ctx)
 }
 
+// GetCountByTag returns the number of non-deleted projects carrying the given tag
+func (p *ProjectModel) GetCountByTag(tagID int) (int64, error) {
+	ctx := context.Background()
+	return p.queries.GetProjectsByTagCount(ctx, int64(tagID))
+}
+
+// ProjectMatch is a project found by Search, with the client it belongs to
+type ProjectMatch struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	ClientID   int    `json:"client_id"`
+	ClientName string `json:"client_name"`
+	Status     string `json:"status"`
+}
+
+// likeEscaper escapes the LIKE wildcards in text typed by the user
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Search finds up to limit projects whose name or client name contains query, with the
+// projects most recently worked on or invoiced first. Archived projects are left out as
+// nothing can be added to them, and a blank query returns the most recent projects.
+func (p *ProjectModel) Search(query string, limit int) ([]ProjectMatch, error) {
+	ctx := context.Background()
+	rows, err := p.queries.SearchProjectsWithClient(ctx, db.SearchProjectsWithClientParams{
+		Pattern: "%" + likeEscaper.Replace(strings.TrimSpace(query)) + "%",
+		Limit:   int64(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]ProjectMatch, len(rows))
+	for i, row := range rows {
+		matches[i] = ProjectMatch{
+			ID:         int(row.ID),
+			Name:       row.Name,
+			ClientID:   int(row.ClientID),
+			ClientName: row.ClientName,
+			Status:     row.Status,
+		}
+	}
+	return matches, nil
+}
+
 // GetAll retrieves all projects with their client information
 func (p *ProjectModel) GetAll() ([]ProjectWithClient, error) {
 	ctx := context.Background()
@@ -500,8 +778,14 @@ type ProjectModelInterface interface {
 	GetAll() ([]ProjectWithClient, error)
 	GetWithPagination(limit, offset int64) ([]ProjectWithClient, error)
 	GetCount() (int64, error)
+	GetWithPaginationByTag(tagID int, limit, offset int64) ([]ProjectWithClient, error)
+	GetCountByTag(tagID int) (int64, error)
+	GetByIDs(ids []int) ([]ProjectWithClient, error)
+	Search(query string, limit int) ([]ProjectMatch, error)
 	Update(project Project) error
+	SetStatus(id int, status string) error
 	Delete(id int) error
+	Restore(id int) error
 }
 
 // Ensure implementation satisfies the interface
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
@@ -379,6 +381,89 @@ func TestProjectModel_Delete(t *testing.T) {
 	})
 }
 
+func TestProjectModel_CascadingDelete(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewProjectModel(testDB.DB)
+	timesheetModel := NewTimesheetModel(testDB.DB)
+	invoiceModel := NewInvoiceModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Test Client")
+	projectID := testDB.InsertTestProject(t, "Cascading Project", clientID)
+	timesheetID := testDB.InsertTestTimesheet(t, projectID, "2024-01-15", "2.0", "50.00", "Editing")
+	removedTimesheetID := testDB.InsertTestTimesheet(t, projectID, "2024-01-16", "1.0", "50.00", "Removed earlier")
+	invoiceID := testDB.InsertTestInvoice(t, projectID, "2024-01-31", "", "Net 30", "100.00")
+	_, err := testDB.DB.Exec("UPDATE timesheet SET deleted_at = '2024-01-20 09:00:00' WHERE id = ?", removedTimesheetID)
+	require.NoError(t, err)
+
+	t.Run("deleting a project deletes its timesheets and invoices", func(t *testing.T) {
+		require.NoError(t, model.Delete(projectID))
+
+		_, err := timesheetModel.Get(timesheetID)
+		assert.Equal(t, ErrNoRecord, err)
+		_, err = invoiceModel.Get(invoiceID)
+		assert.Equal(t, ErrNoRecord, err)
+
+		invoices, err := invoiceModel.GetWithClientAfter(0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, invoices)
+	})
+
+	t.Run("restoring a project restores only what was deleted with it", func(t *testing.T) {
+		require.NoError(t, model.Restore(projectID))
+
+		project, err := model.Get(projectID)
+		require.NoError(t, err)
+		assert.Nil(t, project.DeletedAt)
+
+		_, err = timesheetModel.Get(timesheetID)
+		assert.NoError(t, err)
+		_, err = invoiceModel.Get(invoiceID)
+		assert.NoError(t, err)
+		_, err = timesheetModel.Get(removedTimesheetID)
+		assert.Equal(t, ErrNoRecord, err)
+	})
+
+	t.Run("deleting inside a transaction that rolls back leaves everything in place", func(t *testing.T) {
+		errRollback := errors.New("roll back")
+		err := NewTxManager(testDB.DB).WithinTx(context.Background(), func(tx TxModels) error {
+			require.NoError(t, tx.Projects.Delete(projectID))
+			return errRollback
+		})
+		require.ErrorIs(t, err, errRollback)
+
+		_, err = model.Get(projectID)
+		assert.NoError(t, err)
+		_, err = timesheetModel.Get(timesheetID)
+		assert.NoError(t, err)
+	})
+}
+
+func TestProjectModel_SetStatus(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewProjectModel(testDB.DB)
+	clientID := testDB.InsertTestClient(t, "Test Client")
+	id := testDB.InsertTestProject(t, "Test Project", clientID)
+
+	project, err := model.Get(id)
+	require.NoError(t, err)
+	assert.False(t, project.IsArchived())
+
+	require.NoError(t, model.SetStatus(id, ProjectStatusArchived))
+	project, err = model.Get(id)
+	require.NoError(t, err)
+	assert.True(t, project.IsArchived())
+
+	require.NoError(t, model.SetStatus(id, ProjectStatusReopened))
+	project, err = model.Get(id)
+	require.NoError(t, err)
+	assert.False(t, project.IsArchived())
+	assert.Equal(t, "Work Complete", project.Status)
+}
+
 func TestProjectModel_Integration(t *testing.T) {
 	// Setup test database
 	testDB := testutil.SetupTestSQLite(t)
@@ -527,3 +612,136 @@ func TestProjectModelInterface(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectModel_Search(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewProjectModel(testDB.DB)
+	smithID := testDB.InsertTestClient(t, "Dr. Smith")
+	jonesID := testDB.InsertTestClient(t, "Jones Lab")
+	thesisID := testDB.InsertTestProject(t, "Thesis Edit", smithID)
+	articleID := testDB.InsertTestProject(t, "Journal Article", jonesID)
+	grantID := testDB.InsertTestProject(t, "Grant 100%_Proposal", smithID)
+	archivedID := testDB.InsertTestProject(t, "Old Thesis", smithID)
+
+	_, err := testDB.DB.Exec("UPDATE project SET updated_at = '2024-01-01 00:00:00'")
+	require.NoError(t, err)
+	_, err = testDB.DB.Exec("UPDATE project SET status = ? WHERE id = ?", ProjectStatusArchived, archivedID)
+	require.NoError(t, err)
+
+	// Work logged on the article and an invoice on the grant make them the most recent
+	timesheetID := testDB.InsertTestTimesheet(t, articleID, "2024-03-01", "2", "50", "Edits")
+	_, err = testDB.DB.Exec("UPDATE timesheet SET updated_at = '2024-03-01 00:00:00' WHERE id = ?", timesheetID)
+	require.NoError(t, err)
+	invoiceID := testDB.InsertTestInvoice(t, grantID, "2024-02-01", "", "Net 30", "100")
+	_, err = testDB.DB.Exec("UPDATE invoice SET updated_at = '2024-02-01 00:00:00' WHERE id = ?", invoiceID)
+	require.NoError(t, err)
+
+	ids := func(matches []ProjectMatch) []int {
+		result := make([]int, len(matches))
+		for i, match := range matches {
+			result[i] = match.ID
+		}
+		return result
+	}
+
+	t.Run("blank query ranks by recent activity and leaves out archived projects", func(t *testing.T) {
+		matches, err := model.Search("", 10)
+		require.NoError(t, err)
+		assert.Equal(t, []int{articleID, grantID, thesisID}, ids(matches))
+		assert.Equal(t, "Jones Lab", matches[0].ClientName)
+		assert.Equal(t, jonesID, matches[0].ClientID)
+	})
+
+	t.Run("matches project or client name", func(t *testing.T) {
+		matches, err := model.Search("smith", 10)
+		require.NoError(t, err)
+		assert.Equal(t, []int{grantID, thesisID}, ids(matches))
+
+		matches, err = model.Search(" thesis ", 10)
+		require.NoError(t, err)
+		assert.Equal(t, []int{thesisID}, ids(matches))
+	})
+
+	t.Run("wildcards are matched literally", func(t *testing.T) {
+		matches, err := model.Search("100%_", 10)
+		require.NoError(t, err)
+		assert.Equal(t, []int{grantID}, ids(matches))
+
+		matches, err = model.Search("%", 10)
+		require.NoError(t, err)
+		assert.Equal(t, []int{grantID}, ids(matches))
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		matches, err := model.Search("", 1)
+		require.NoError(t, err)
+		assert.Equal(t, []int{articleID}, ids(matches))
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		matches, err := model.Search("nothing like this", 10)
+		require.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+}
+
+func TestProjectEstimate(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewProjectModel(testDB.DB)
+	clientID := testDB.InsertTestClient(t, "Estimate Client")
+	hours, amount := 10.0, 400.0
+	projectID, err := model.Insert(Project{Name: "Estimated", ClientID: clientID, Status: "In Progress", HourlyRate: 50,
+		CurrencyDisplay: "USD", CurrencyConversionRate: 1, EstimatedHours: &hours, EstimatedAmount: &amount})
+	require.NoError(t, err)
+	testDB.InsertTestTimesheet(t, projectID, "2024-05-02", "6.0", "50.00", "Drafting")
+	testDB.InsertTestTimesheet(t, projectID, "2024-05-03", "3.0", "50.00", "Revisions")
+
+	t.Run("estimates are saved with the project", func(t *testing.T) {
+		project, err := model.Get(projectID)
+		require.NoError(t, err)
+		require.NotNil(t, project.EstimatedHours)
+		assert.Equal(t, 10.0, *project.EstimatedHours)
+		require.NotNil(t, project.EstimatedAmount)
+		assert.Equal(t, 400.0, *project.EstimatedAmount)
+
+		project.EstimatedAmount = nil
+		require.NoError(t, model.Update(project))
+		project, err = model.Get(projectID)
+		require.NoError(t, err)
+		assert.Nil(t, project.EstimatedAmount)
+		require.NoError(t, model.Update(Project{ID: projectID, Name: "Estimated", Status: "In Progress", HourlyRate: 50,
+			CurrencyDisplay: "USD", CurrencyConversionRate: 1, EstimatedHours: &hours, EstimatedAmount: &amount}))
+	})
+
+	t.Run("the projects list compares them with the time logged", func(t *testing.T) {
+		projects, err := model.GetWithPagination(10, 0)
+		require.NoError(t, err)
+		require.Len(t, projects, 1)
+		assert.Equal(t, 9.0, projects[0].ActualHours)
+		assert.Equal(t, 450.0, projects[0].ActualAmount)
+
+		estimate := projects[0].Estimate()
+		require.NotNil(t, estimate)
+		assert.Equal(t, -1.0, estimate.HoursVariance())
+		assert.Equal(t, 1.0, estimate.HoursRemaining())
+		assert.False(t, estimate.HoursOver())
+		assert.Equal(t, 90.0, estimate.HoursPercent())
+		assert.Equal(t, 50.0, estimate.AmountVariance())
+		assert.True(t, estimate.AmountOver())
+		assert.Equal(t, 100.0, estimate.AmountPercent())
+	})
+
+	t.Run("a project without estimates has nothing to compare", func(t *testing.T) {
+		assert.Nil(t, NewProjectEstimate(nil, nil, 5, 250))
+
+		estimate := NewProjectEstimate(nil, &amount, 5, 250)
+		require.NotNil(t, estimate)
+		assert.False(t, estimate.HasHours)
+		assert.Equal(t, 0.0, estimate.HoursVariance())
+		assert.Equal(t, -150.0, estimate.AmountVariance())
+	})
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// Kinds of page whose visits are recorded
+const (
+	VisitClient  = "client"
+	VisitProject = "project"
+)
+
+// visitCandidates is how many of the latest visits of each kind are ranked when finding a
+// user's top visits. Anything visited less recently isn't worth offering.
+const visitCandidates = 50
+
+// Visit is a client or project a user has opened, with how often and when they last did.
+// ClientName is only set for projects.
+type Visit struct {
+	Kind        string
+	ItemID      int
+	Name        string
+	ClientName  string
+	Count       int
+	LastVisited time.Time
+}
+
+// Score ranks a visit by how often and how recently it was made, so that a project opened
+// every day outranks one opened many times months ago
+func (v Visit) Score(now time.Time) float64 {
+	age := now.Sub(v.LastVisited)
+	weight := 0.25
+	switch {
+	case age < 24*time.Hour:
+		weight = 4
+	case age < 7*24*time.Hour:
+		weight = 2
+	case age < 30*24*time.Hour:
+		weight = 1
+	}
+	return float64(v.Count) * weight
+}
+
+// RankVisits orders visits by their score, the most recent first among equals
+func RankVisits(visits []Visit, now time.Time) {
+	slices.SortStableFunc(visits, func(a, b Visit) int {
+		if c := cmp.Compare(b.Score(now), a.Score(now)); c != 0 {
+			return c
+		}
+		return b.LastVisited.Compare(a.LastVisited)
+	})
+}
+
+// RecentVisitModel wraps the generated SQLC Queries for recording the clients and projects
+// each user opens
+type RecentVisitModel struct {
+	queries *db.Queries
+}
+
+// NewRecentVisitModel creates a new RecentVisitModel
+func NewRecentVisitModel(database *sql.DB) *RecentVisitModel {
+	return &RecentVisitModel{
+		queries: newQueries(database),
+	}
+}
+
+// Record counts a visit by a user to a client or project at the given time
+func (m *RecentVisitModel) Record(userID int, kind string, itemID int, at time.Time) error {
+	ctx := context.Background()
+	err := m.queries.RecordVisit(ctx, db.RecordVisitParams{
+		UserID:        int64(userID),
+		Kind:          kind,
+		ItemID:        int64(itemID),
+		LastVisitedAt: at.UTC(),
+	})
+	return translateError(err)
+}
+
+// GetTop returns up to limit of the clients and projects a user visits most, as ranked by
+// RankVisits. Deleted clients and projects are left out.
+func (m *RecentVisitModel) GetTop(userID, limit int, now time.Time) ([]Visit, error) {
+	ctx := context.Background()
+	clients, err := m.queries.GetRecentClientVisits(ctx, db.GetRecentClientVisitsParams{
+		UserID: int64(userID),
+		Limit:  visitCandidates,
+	})
+	if err != nil {
+		return nil, err
+	}
+	projects, err := m.queries.GetRecentProjectVisits(ctx, db.GetRecentProjectVisitsParams{
+		UserID: int64(userID),
+		Limit:  visitCandidates,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	visits := make([]Visit, 0, len(clients)+len(projects))
+	for _, row := range clients {
+		visits = append(visits, Visit{
+			Kind:        VisitClient,
+			ItemID:      int(row.ItemID),
+			Name:        row.Name,
+			Count:       int(row.VisitCount),
+			LastVisited: row.LastVisitedAt,
+		})
+	}
+	for _, row := range projects {
+		visits = append(visits, Visit{
+			Kind:        VisitProject,
+			ItemID:      int(row.ItemID),
+			Name:        row.Name,
+			ClientName:  row.ClientName,
+			Count:       int(row.VisitCount),
+			LastVisited: row.LastVisitedAt,
+		})
+	}
+
+	RankVisits(visits, now)
+	if len(visits) > limit {
+		visits = visits[:limit]
+	}
+	return visits, nil
+}
+
+// RecentVisitModelInterface defines the interface for recent visit operations
+type RecentVisitModelInterface interface {
+	Record(userID int, kind string, itemID int, at time.Time) error
+	GetTop(userID, limit int, now time.Time) ([]Visit, error)
+}
+
+// Ensure implementation satisfies the interface
+var _ RecentVisitModelInterface = (*RecentVisitModel)(nil)
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecentVisitModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewRecentVisitModel(testDB.DB)
+	users := NewUserModel(testDB.DB)
+	userID, err := users.Insert(User{Name: "Ann Owner", Email: "ann@example.com", Role: RoleOwner}, "correct horse")
+	require.NoError(t, err)
+	otherUserID, err := users.Insert(User{Name: "Bob Owner", Email: "bob@example.com", Role: RoleOwner}, "correct horse")
+	require.NoError(t, err)
+
+	clientID := testDB.InsertTestClient(t, "Acme")
+	bookID := testDB.InsertTestProject(t, "Book", clientID)
+	articleID := testDB.InsertTestProject(t, "Article", clientID)
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	// The book was opened often but months ago, the article and client this morning
+	for i := 0; i < 5; i++ {
+		require.NoError(t, model.Record(userID, VisitProject, bookID, now.AddDate(0, -3, 0)))
+	}
+	require.NoError(t, model.Record(userID, VisitProject, articleID, now.Add(-2*time.Hour)))
+	require.NoError(t, model.Record(userID, VisitClient, clientID, now.Add(-time.Hour)))
+	require.NoError(t, model.Record(otherUserID, VisitProject, bookID, now))
+
+	t.Run("visits are ranked by how often and how recently they were made", func(t *testing.T) {
+		visits, err := model.GetTop(userID, 10, now)
+		require.NoError(t, err)
+		require.Len(t, visits, 3)
+
+		assert.Equal(t, Visit{Kind: VisitClient, ItemID: clientID, Name: "Acme", Count: 1, LastVisited: now.Add(-time.Hour)}, visits[0])
+		assert.Equal(t, articleID, visits[1].ItemID)
+		assert.Equal(t, "Acme", visits[1].ClientName)
+		assert.Equal(t, bookID, visits[2].ItemID)
+		assert.Equal(t, 5, visits[2].Count)
+
+		visits, err = model.GetTop(userID, 1, now)
+		require.NoError(t, err)
+		assert.Len(t, visits, 1)
+	})
+
+	t.Run("each user has their own visits", func(t *testing.T) {
+		visits, err := model.GetTop(otherUserID, 10, now)
+		require.NoError(t, err)
+		require.Len(t, visits, 1)
+		assert.Equal(t, bookID, visits[0].ItemID)
+	})
+
+	t.Run("deleted projects are left out", func(t *testing.T) {
+		require.NoError(t, NewProjectModel(testDB.DB).Delete(articleID))
+		visits, err := model.GetTop(userID, 10, now)
+		require.NoError(t, err)
+		assert.Len(t, visits, 2)
+	})
+}
+
+func TestRankVisits(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	visits := []Visit{
+		{Name: "old favourite", Count: 10, LastVisited: now.AddDate(-1, 0, 0)},
+		{Name: "this week", Count: 2, LastVisited: now.AddDate(0, 0, -3)},
+		{Name: "today", Count: 1, LastVisited: now.Add(-time.Hour)},
+		{Name: "this morning", Count: 1, LastVisited: now.Add(-3 * time.Hour)},
+	}
+	RankVisits(visits, now)
+
+	var names []string
+	for _, visit := range visits {
+		names = append(names, visit.Name)
+	}
+	assert.Equal(t, []string{"today", "this morning", "this week", "old favourite"}, names)
+}
@@ -0,0 +1,796 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// Margin compares what logged time is billed at with what it cost to deliver
+type Margin struct {
+	Hours  float64
+	Billed float64
+	Cost   float64
+}
+
+// Profit returns the billed amount less the cost
+func (m Margin) Profit() float64 {
+	return m.Billed - m.Cost
+}
+
+// Percent returns the profit as a percentage of the billed amount
+func (m Margin) Percent() float64 {
+	if m.Billed == 0 {
+		return 0
+	}
+	return m.Profit() / m.Billed * 100
+}
+
+// TimesheetMargin totals the billed amount and cost of a set of timesheet entries, and the
+// hours of those that are billed by the hour
+func TimesheetMargin(timesheets []Timesheet) Margin {
+	var margin Margin
+	for _, timesheet := range timesheets {
+		if timesheet.IsHourly() {
+			margin.Hours += timesheet.HoursWorked
+		}
+		margin.Billed += timesheet.Amount()
+		margin.Cost += timesheet.Cost()
+	}
+	return margin
+}
+
+// ProjectMargin is the margin on the time logged against one project
+type ProjectMargin struct {
+	ProjectID   int
+	ProjectName string
+	ClientID    int
+	ClientName  string
+	Margin
+}
+
+// ClientMargin is the margin on the time logged across all of a client's projects
+type ClientMargin struct {
+	ClientID   int
+	ClientName string
+	Projects   int
+	Margin
+}
+
+// ReportModel wraps the generated SQLC Queries for reporting across clients and projects
+type ReportModel struct {
+	queries *db.Queries
+}
+
+// NewReportModel creates a new ReportModel
+func NewReportModel(database *sql.DB) *ReportModel {
+	return &ReportModel{
+		queries: newQueries(database),
+	}
+}
+
+// ProjectMargins retrieves the billed amount and cost of the time logged on each project,
+// ordered by client and project name. Projects without any timesheets are left out.
+func (r *ReportModel) ProjectMargins() ([]ProjectMargin, error) {
+	ctx := context.Background()
+	rows, err := r.queries.GetProjectMargins(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	margins := make([]ProjectMargin, len(rows))
+	for i, row := range rows {
+		margins[i] = ProjectMargin{
+			ProjectID:   int(row.ID),
+			ProjectName: row.Name,
+			ClientID:    int(row.ClientID),
+			ClientName:  row.ClientName,
+			Margin: Margin{
+				Hours:  row.Hours,
+				Billed: row.Billed,
+				Cost:   row.Cost,
+			},
+		}
+	}
+	return margins, nil
+}
+
+// ClientMargins totals project margins per client, keeping the order of the project list
+func ClientMargins(projects []ProjectMargin) []ClientMargin {
+	var clients []ClientMargin
+	index := map[int]int{}
+	for _, project := range projects {
+		i, ok := index[project.ClientID]
+		if !ok {
+			i = len(clients)
+			index[project.ClientID] = i
+			clients = append(clients, ClientMargin{ClientID: project.ClientID, ClientName: project.ClientName})
+		}
+		clients[i].Projects++
+		clients[i].Hours += project.Hours
+		clients[i].Billed += project.Billed
+		clients[i].Cost += project.Cost
+	}
+	return clients
+}
+
+// ForecastInvoice is an unpaid invoice and when its client can be expected to pay it. DueOn is
+// the invoice's due date, worked out from its payment terms when it doesn't have one.
+type ForecastInvoice struct {
+	Invoice
+	ClientID     int
+	ClientName   string
+	ProjectName  string
+	DueOn        time.Time
+	ExpectedDate time.Time
+	DaysLate     int
+}
+
+// Outstanding returns what is still to be received on the invoice
+func (f ForecastInvoice) Outstanding() float64 {
+	return max(f.BalanceDue()-f.TotalPaid(), 0)
+}
+
+// ForecastWeek is the income expected in the week starting on Monday Start
+type ForecastWeek struct {
+	Start    time.Time
+	Invoices []ForecastInvoice
+	Total    float64
+}
+
+// End returns the Sunday the week ends on
+func (w ForecastWeek) End() time.Time {
+	return w.Start.AddDate(0, 0, 6)
+}
+
+// ReceivablesForecast spreads the money owed on unpaid invoices over the weeks it is expected in
+type ReceivablesForecast struct {
+	Weeks []ForecastWeek
+	Total float64
+}
+
+// weekStart returns the Monday of the week a date falls in
+func weekStart(date time.Time) time.Time {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// NewReceivablesForecast works out when each unpaid invoice is likely to be paid: on its due
+// date, pushed back by the number of days its client pays late on average. Invoices expected
+// before today are counted in the current week. Weeks without any expected income are left out.
+func NewReceivablesForecast(invoices []ForecastInvoice, behaviors map[int]PaymentBehavior, today time.Time) ReceivablesForecast {
+	thisWeek := weekStart(today)
+	var forecast ReceivablesForecast
+	index := map[time.Time]int{}
+	for _, invoice := range invoices {
+		outstanding := invoice.Outstanding()
+		if outstanding <= 0 {
+			continue
+		}
+		invoice.DaysLate = int(math.Round(behaviors[invoice.ClientID].AvgDaysLate))
+		invoice.ExpectedDate = invoice.DueOn.AddDate(0, 0, invoice.DaysLate)
+
+		week := weekStart(invoice.ExpectedDate)
+		if week.Before(thisWeek) {
+			week = thisWeek
+		}
+		i, ok := index[week]
+		if !ok {
+			i = len(forecast.Weeks)
+			index[week] = i
+			forecast.Weeks = append(forecast.Weeks, ForecastWeek{Start: week})
+		}
+		forecast.Weeks[i].Invoices = append(forecast.Weeks[i].Invoices, invoice)
+		forecast.Weeks[i].Total += outstanding
+		forecast.Total += outstanding
+	}
+
+	sort.Slice(forecast.Weeks, func(i, j int) bool { return forecast.Weeks[i].Start.Before(forecast.Weeks[j].Start) })
+	for _, week := range forecast.Weeks {
+		sort.SliceStable(week.Invoices, func(i, j int) bool {
+			return week.Invoices[i].ExpectedDate.Before(week.Invoices[j].ExpectedDate)
+		})
+	}
+	return forecast
+}
+
+// ReceivablesForecast forecasts the income from every unpaid invoice of active clients and
+// projects, using each client's history of paid invoices to estimate how late it will pay
+func (r *ReportModel) ReceivablesForecast(today time.Time) (ReceivablesForecast, error) {
+	ctx := context.Background()
+	history, err := r.queries.GetPaidInvoiceHistory(ctx)
+	if err != nil {
+		return ReceivablesForecast{}, err
+	}
+	paid := map[int][]Invoice{}
+	for _, row := range history {
+		if !row.DatePaid.Valid {
+			continue
+		}
+		clientID := int(row.ClientID)
+		paid[clientID] = append(paid[clientID], Invoice{
+			InvoiceDate:  row.InvoiceDate.Time,
+			DatePaid:     row.DatePaid.Ptr(),
+			PaymentTerms: row.PaymentTerms,
+			DueDate:      row.DueDate.Ptr(),
+		})
+	}
+	behaviors := make(map[int]PaymentBehavior, len(paid))
+	for clientID, invoices := range paid {
+		behaviors[clientID] = NewPaymentBehavior(invoices)
+	}
+
+	invoices, err := r.unpaidInvoices(ctx)
+	if err != nil {
+		return ReceivablesForecast{}, err
+	}
+	return NewReceivablesForecast(invoices, behaviors, today), nil
+}
+
+// unpaidInvoices retrieves every unpaid invoice of active clients and projects with the date
+// it falls due
+func (r *ReportModel) unpaidInvoices(ctx context.Context) ([]ForecastInvoice, error) {
+	rows, err := r.queries.GetUnpaidInvoices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	invoices := make([]ForecastInvoice, len(rows))
+	for i, row := range rows {
+		invoice := Invoice{
+			ID:            int(row.ID),
+			ProjectID:     int(row.ProjectID),
+			InvoiceDate:   row.InvoiceDate.Time,
+			PaymentTerms:  row.PaymentTerms,
+			AmountDue:     row.AmountDue,
+			AmountPaid:    convertNullFloat64(row.AmountPaid),
+			CreditApplied: row.CreditApplied,
+			InvoiceNumber: row.InvoiceNumber.String,
+			DueDate:       row.DueDate.Ptr(),
+		}
+		dueDate := DueDateFor(invoice.InvoiceDate, invoice.PaymentTerms)
+		if invoice.DueDate != nil {
+			dueDate = *invoice.DueDate
+		}
+		invoices[i] = ForecastInvoice{
+			Invoice:     invoice,
+			ClientID:    int(row.ClientID),
+			ClientName:  row.ClientName,
+			ProjectName: row.ProjectName,
+			DueOn:       dueDate,
+		}
+	}
+	return invoices, nil
+}
+
+// AgingInvoice is an unpaid invoice with how many days it is past its due date, 0 when it
+// isn't due yet
+type AgingInvoice struct {
+	Invoice
+	ClientName  string
+	ProjectName string
+	DueOn       time.Time
+	DaysOverdue int
+	Outstanding float64
+}
+
+// AgingBucket gathers the unpaid invoices that are overdue by up to MaxDays days, and more
+// than the bucket before it allows
+type AgingBucket struct {
+	Label    string
+	MaxDays  int
+	Invoices []AgingInvoice
+	Total    float64
+}
+
+// agingBuckets are the usual ranges of days overdue receivables are aged in
+var agingBuckets = []AgingBucket{
+	{Label: "Current", MaxDays: 0},
+	{Label: "1-30 days", MaxDays: 30},
+	{Label: "31-60 days", MaxDays: 60},
+	{Label: "61-90 days", MaxDays: 90},
+	{Label: "Over 90 days", MaxDays: math.MaxInt},
+}
+
+// AgingReport sorts the money owed on unpaid invoices by how long it is overdue. Every bucket
+// is listed, including those without any invoices.
+type AgingReport struct {
+	AsOf    time.Time
+	Buckets []AgingBucket
+	Total   float64
+}
+
+// NewAgingReport ages unpaid invoices as of today, listing the most overdue first within each
+// bucket. Invoices with nothing left to pay are left out.
+func NewAgingReport(invoices []ForecastInvoice, today time.Time) AgingReport {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	report := AgingReport{AsOf: today, Buckets: make([]AgingBucket, len(agingBuckets))}
+	copy(report.Buckets, agingBuckets)
+	for _, invoice := range invoices {
+		outstanding := invoice.Outstanding()
+		if outstanding <= 0 {
+			continue
+		}
+		dueOn := time.Date(invoice.DueOn.Year(), invoice.DueOn.Month(), invoice.DueOn.Day(), 0, 0, 0, 0, time.UTC)
+		days := max(int(today.Sub(dueOn).Hours()/24), 0)
+
+		i := 0
+		for days > report.Buckets[i].MaxDays {
+			i++
+		}
+		report.Buckets[i].Invoices = append(report.Buckets[i].Invoices, AgingInvoice{
+			Invoice:     invoice.Invoice,
+			ClientName:  invoice.ClientName,
+			ProjectName: invoice.ProjectName,
+			DueOn:       invoice.DueOn,
+			DaysOverdue: days,
+			Outstanding: outstanding,
+		})
+		report.Buckets[i].Total += outstanding
+		report.Total += outstanding
+	}
+
+	for _, bucket := range report.Buckets {
+		sort.SliceStable(bucket.Invoices, func(i, j int) bool {
+			return bucket.Invoices[i].DaysOverdue > bucket.Invoices[j].DaysOverdue
+		})
+	}
+	return report
+}
+
+// WriteCSV writes every invoice in the report as a row of CSV, bucket by bucket, with dates
+// as YYYY-MM-DD so that spreadsheets read them as dates
+func (a AgingReport) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"Bucket", "Invoice", "Client", "Project", "Invoice date", "Due date", "Days overdue", "Outstanding"})
+	for _, bucket := range a.Buckets {
+		for _, invoice := range bucket.Invoices {
+			out.Write([]string{
+				bucket.Label,
+				invoice.DisplayNumber(),
+				invoice.ClientName,
+				invoice.ProjectName,
+				invoice.InvoiceDate.Format(time.DateOnly),
+				invoice.DueOn.Format(time.DateOnly),
+				strconv.Itoa(invoice.DaysOverdue),
+				strconv.FormatFloat(invoice.Outstanding, 'f', 2, 64),
+			})
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// Aging ages the money owed on every unpaid invoice of active clients and projects as of today
+func (r *ReportModel) Aging(today time.Time) (AgingReport, error) {
+	invoices, err := r.unpaidInvoices(context.Background())
+	if err != nil {
+		return AgingReport{}, err
+	}
+	return NewAgingReport(invoices, today), nil
+}
+
+// UnbilledWork is the approved work logged on a project that no invoice bills yet. Oldest is
+// the work date of the oldest unbilled entry and AgeDays how long ago that was.
+type UnbilledWork struct {
+	ProjectID   int
+	ProjectName string
+	ClientID    int
+	ClientName  string
+	Entries     int
+	Hours       float64
+	Amount      float64
+	Oldest      time.Time
+	AgeDays     int
+}
+
+// WIPAge totals the unbilled work of the projects whose oldest entry falls within an age range
+type WIPAge struct {
+	Label    string
+	Projects int
+	Amount   float64
+}
+
+// wipAges are the age ranges unbilled work is grouped into, by the most days old it can be
+var wipAges = []struct {
+	label   string
+	maxDays int
+}{
+	{"0-30 days", 30},
+	{"31-60 days", 60},
+	{"61-90 days", 90},
+	{"Over 90 days", math.MaxInt},
+}
+
+// WorkInProgress is the revenue waiting to be invoiced, per project and by how long it has
+// been waiting
+type WorkInProgress struct {
+	Projects []UnbilledWork
+	Ages     []WIPAge
+	Hours    float64
+	Total    float64
+}
+
+// NewWorkInProgress ages each project's unbilled work from its oldest entry and totals it by
+// age range. Every range is listed, even when empty, so reports line up from one day to the next.
+func NewWorkInProgress(projects []UnbilledWork, today time.Time) WorkInProgress {
+	wip := WorkInProgress{Projects: projects, Ages: make([]WIPAge, len(wipAges))}
+	for i, age := range wipAges {
+		wip.Ages[i].Label = age.label
+	}
+	for i := range wip.Projects {
+		project := &wip.Projects[i]
+		project.AgeDays = max(daysBetween(project.Oldest, today), 0)
+		for j, age := range wipAges {
+			if project.AgeDays <= age.maxDays {
+				wip.Ages[j].Projects++
+				wip.Ages[j].Amount += project.Amount
+				break
+			}
+		}
+		wip.Hours += project.Hours
+		wip.Total += project.Amount
+	}
+	return wip
+}
+
+// WorkInProgress retrieves the unbilled work on every project, oldest first. Entries waiting
+// for approval and template rows without hours are left out.
+func (r *ReportModel) WorkInProgress(today time.Time) (WorkInProgress, error) {
+	ctx := context.Background()
+	rows, err := r.queries.GetUnbilledWork(ctx)
+	if err != nil {
+		return WorkInProgress{}, err
+	}
+
+	projects := make([]UnbilledWork, len(rows))
+	for i, row := range rows {
+		oldest, err := time.Parse(isoLayout, row.OldestWorkDate)
+		if err != nil {
+			return WorkInProgress{}, err
+		}
+		projects[i] = UnbilledWork{
+			ProjectID:   int(row.ID),
+			ProjectName: row.Name,
+			ClientID:    int(row.ClientID),
+			ClientName:  row.ClientName,
+			Entries:     int(row.Entries),
+			Hours:       row.Hours,
+			Amount:      math.Round(row.Amount*100) / 100,
+			Oldest:      oldest,
+		}
+	}
+	return NewWorkInProgress(projects, today), nil
+}
+
+// ClientValue is what a client has been worth over the whole relationship: when its first
+// project was set up, and what it has been invoiced and has paid. Voided invoices don't count.
+type ClientValue struct {
+	ClientID     int
+	ClientName   string
+	FirstProject *time.Time
+	Invoices     int
+	Billed       float64
+	Paid         float64
+}
+
+// AverageInvoice returns the average amount invoiced, or zero if the client has no invoices
+func (v ClientValue) AverageInvoice() float64 {
+	if v.Invoices == 0 {
+		return 0
+	}
+	return v.Billed / float64(v.Invoices)
+}
+
+// ClientValueSort is the order the top clients report is listed in
+type ClientValueSort string
+
+const (
+	ClientValueByBilled  ClientValueSort = "billed"
+	ClientValueByPaid    ClientValueSort = "paid"
+	ClientValueByAverage ClientValueSort = "average"
+	ClientValueBySince   ClientValueSort = "since"
+	ClientValueByName    ClientValueSort = "name"
+)
+
+// ParseClientValueSort returns the ClientValueSort named by value, falling back to ordering by
+// amount billed for anything unknown
+func ParseClientValueSort(value string) ClientValueSort {
+	switch sort := ClientValueSort(value); sort {
+	case ClientValueByPaid, ClientValueByAverage, ClientValueBySince, ClientValueByName:
+		return sort
+	default:
+		return ClientValueByBilled
+	}
+}
+
+// SortClientValues orders clients by the given sort: amounts largest first, the longest
+// standing clients first, or by name. Clients without a project sort last by date, and ties
+// keep their order, which is by name as ClientValues returns them.
+func SortClientValues(values []ClientValue, by ClientValueSort) {
+	sort.SliceStable(values, func(i, j int) bool {
+		a, b := values[i], values[j]
+		switch by {
+		case ClientValueByPaid:
+			return a.Paid > b.Paid
+		case ClientValueByAverage:
+			return a.AverageInvoice() > b.AverageInvoice()
+		case ClientValueBySince:
+			if a.FirstProject == nil || b.FirstProject == nil {
+				return a.FirstProject != nil && b.FirstProject == nil
+			}
+			return a.FirstProject.Before(*b.FirstProject)
+		case ClientValueByName:
+			return false
+		default:
+			return a.Billed > b.Billed
+		}
+	})
+}
+
+// ClientValues retrieves the lifetime value of every client, ordered by name
+func (r *ReportModel) ClientValues() ([]ClientValue, error) {
+	ctx := context.Background()
+	rows, err := r.queries.GetClientLifetimeValues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]ClientValue, len(rows))
+	for i, row := range rows {
+		values[i] = newClientValue(db.GetClientLifetimeValueRow(row))
+	}
+	return values, nil
+}
+
+// ClientValue retrieves the lifetime value of one client
+func (r *ReportModel) ClientValue(clientID int) (ClientValue, error) {
+	ctx := context.Background()
+	row, err := r.queries.GetClientLifetimeValue(ctx, int64(clientID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ClientValue{}, ErrNoRecord
+		}
+		return ClientValue{}, err
+	}
+	return newClientValue(row), nil
+}
+
+func newClientValue(row db.GetClientLifetimeValueRow) ClientValue {
+	return ClientValue{
+		ClientID:     int(row.ID),
+		ClientName:   row.Name,
+		FirstProject: convertNullDate(sql.NullString{String: row.FirstProjectDate, Valid: true}),
+		Invoices:     int(row.Invoices),
+		Billed:       row.Billed,
+		Paid:         row.Paid,
+	}
+}
+
+// Change compares a measure over one period with the period before it
+type Change struct {
+	Current  float64
+	Previous float64
+}
+
+// Delta returns how much the measure rose, or fell when negative
+func (c Change) Delta() float64 {
+	return c.Current - c.Previous
+}
+
+// Percent returns the delta as a percentage of the previous period, or zero when nothing was
+// recorded then
+func (c Change) Percent() float64 {
+	if c.Previous == 0 {
+		return 0
+	}
+	return c.Delta() / math.Abs(c.Previous) * 100
+}
+
+// Trend follows one measure over time: this month so far against the same days of last month,
+// this year so far against the same part of last year, and the total of each of the last
+// twelve months, oldest first, for drawing a sparkline
+type Trend struct {
+	Measure string
+	Money   bool
+	Month   Change
+	Year    Change
+	Series  []float64
+}
+
+// SparklinePoints returns the Series as the points of an SVG polyline filling a box of the
+// given size, with zero along the bottom and the largest total along the top
+func (t Trend) SparklinePoints(width, height float64) string {
+	if len(t.Series) == 0 {
+		return ""
+	}
+	top := 0.0
+	for _, value := range t.Series {
+		top = max(top, value)
+	}
+	step := 0.0
+	if len(t.Series) > 1 {
+		step = width / float64(len(t.Series)-1)
+	}
+
+	points := make([]string, len(t.Series))
+	for i, value := range t.Series {
+		y := height
+		if top > 0 {
+			y = height - max(value, 0)/top*height
+		}
+		points[i] = strconv.FormatFloat(float64(i)*step, 'f', 1, 64) + "," + strconv.FormatFloat(y, 'f', 1, 64)
+	}
+	return strings.Join(points, " ")
+}
+
+// DailyTotals is what was recorded on one day: the hours and value of the work logged, the
+// amount invoiced and the payments received
+type DailyTotals struct {
+	Day      time.Time
+	Hours    float64
+	Billed   float64
+	Invoiced float64
+	Received float64
+}
+
+// Trends compares the work logged, invoiced and paid for over this month and this year so
+// far with the same stretches of the month and year before. Months are the first days of the
+// months each Series covers.
+type Trends struct {
+	Month     Period
+	LastMonth Period
+	Year      Period
+	LastYear  Period
+	Months    []time.Time
+	Measures  []Trend
+}
+
+// Period is a run of days, Start and End included
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// contains reports whether day falls within the period
+func (p Period) contains(day time.Time) bool {
+	return !day.Before(p.Start) && !day.After(p.End)
+}
+
+// trendMonths is how many months of totals a Trend's Series holds
+const trendMonths = 12
+
+// sameDayIn returns the given day of a month, or the month's last day when it is shorter
+func sameDayIn(year int, month time.Month, day int) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	return time.Date(year, month, min(day, last), 0, 0, 0, 0, time.UTC)
+}
+
+// NewTrends totals the daily figures into each period compared, and into the months of each
+// Series. Days outside them are ignored.
+func NewTrends(days []DailyTotals, today time.Time) Trends {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	lastMonthStart := monthStart.AddDate(0, -1, 0)
+	yearStart := time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	trends := Trends{
+		Month:     Period{Start: monthStart, End: today},
+		LastMonth: Period{Start: lastMonthStart, End: sameDayIn(lastMonthStart.Year(), lastMonthStart.Month(), today.Day())},
+		Year:      Period{Start: yearStart, End: today},
+		LastYear:  Period{Start: yearStart.AddDate(-1, 0, 0), End: sameDayIn(today.Year()-1, today.Month(), today.Day())},
+		Months:    make([]time.Time, trendMonths),
+	}
+	for i := range trends.Months {
+		trends.Months[i] = monthStart.AddDate(0, i-trendMonths+1, 0)
+	}
+
+	measures := []struct {
+		name  string
+		money bool
+		value func(DailyTotals) float64
+	}{
+		{"Hours logged", false, func(d DailyTotals) float64 { return d.Hours }},
+		{"Work logged", true, func(d DailyTotals) float64 { return d.Billed }},
+		{"Invoiced", true, func(d DailyTotals) float64 { return d.Invoiced }},
+		{"Payments received", true, func(d DailyTotals) float64 { return d.Received }},
+	}
+	trends.Measures = make([]Trend, len(measures))
+	for i, measure := range measures {
+		trend := Trend{Measure: measure.name, Money: measure.money, Series: make([]float64, trendMonths)}
+		for _, day := range days {
+			value := measure.value(day)
+			if trends.Month.contains(day.Day) {
+				trend.Month.Current += value
+			}
+			if trends.LastMonth.contains(day.Day) {
+				trend.Month.Previous += value
+			}
+			if trends.Year.contains(day.Day) {
+				trend.Year.Current += value
+			}
+			if trends.LastYear.contains(day.Day) {
+				trend.Year.Previous += value
+			}
+			if !day.Day.After(today) {
+				month := (day.Day.Year()-monthStart.Year())*12 + int(day.Day.Month()-monthStart.Month()) + trendMonths - 1
+				if month >= 0 && month < trendMonths {
+					trend.Series[month] += value
+				}
+			}
+		}
+		trends.Measures[i] = trend
+	}
+	return trends
+}
+
+// Trends retrieves the daily totals since the start of last year and compares this month and
+// this year with the same stretches of the month and year before
+func (r *ReportModel) Trends(today time.Time) (Trends, error) {
+	ctx := context.Background()
+	since := time.Date(today.Year()-1, time.January, 1, 0, 0, 0, 0, time.UTC).Format(isoLayout)
+
+	totals := map[string]*DailyTotals{}
+	day := func(value string) *DailyTotals {
+		if totals[value] == nil {
+			totals[value] = &DailyTotals{}
+		}
+		return totals[value]
+	}
+
+	timesheets, err := r.queries.GetDailyTimesheetTotals(ctx, since)
+	if err != nil {
+		return Trends{}, err
+	}
+	for _, row := range timesheets {
+		day(row.Day).Hours += row.Hours
+		day(row.Day).Billed += row.Billed
+	}
+	invoices, err := r.queries.GetDailyInvoiceTotals(ctx, since)
+	if err != nil {
+		return Trends{}, err
+	}
+	for _, row := range invoices {
+		day(row.Day).Invoiced += row.Invoiced
+	}
+	payments, err := r.queries.GetDailyPaymentTotals(ctx, since)
+	if err != nil {
+		return Trends{}, err
+	}
+	for _, row := range payments {
+		day(row.Day).Received += row.Received
+	}
+
+	days := make([]DailyTotals, 0, len(totals))
+	for value, total := range totals {
+		date, err := time.Parse(isoLayout, value)
+		if err != nil {
+			return Trends{}, err
+		}
+		total.Day = date
+		days = append(days, *total)
+	}
+	return NewTrends(days, today), nil
+}
+
+// ReportModelInterface defines the interface for reporting operations
+type ReportModelInterface interface {
+	ProjectMargins() ([]ProjectMargin, error)
+	ReceivablesForecast(today time.Time) (ReceivablesForecast, error)
+	Aging(today time.Time) (AgingReport, error)
+	ClientValues() ([]ClientValue, error)
+	ClientValue(clientID int) (ClientValue, error)
+	WorkInProgress(today time.Time) (WorkInProgress, error)
+	Trends(today time.Time) (Trends, error)
+}
+
+// Ensure implementation satisfies the interface
+var _ ReportModelInterface = (*ReportModel)(nil)
@@ -0,0 +1,434 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportModel_ProjectMargins(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewReportModel(testDB.DB)
+	timesheets := NewTimesheetModel(testDB.DB)
+
+	testDB.TruncateTable(t, "timesheet")
+	testDB.TruncateTable(t, "project")
+	testDB.TruncateTable(t, "client")
+
+	acmeID := testDB.InsertTestClient(t, "Acme")
+	zenithID := testDB.InsertTestClient(t, "Zenith")
+	bookID := testDB.InsertTestProject(t, "Book", acmeID)
+	paperID := testDB.InsertTestProject(t, "Paper", acmeID)
+	thesisID := testDB.InsertTestProject(t, "Thesis", zenithID)
+	testDB.InsertTestProject(t, "Idle", zenithID)
+
+	farmedOut := testDB.InsertTestTimesheet(t, bookID, "2024-01-10", "10.00", "100.00", "Subcontracted edit")
+	costRate := 60.0
+	require.NoError(t, timesheets.SetCostRate(farmedOut, &costRate))
+	testDB.InsertTestTimesheet(t, bookID, "2024-01-11", "2.00", "100.00", "Own review")
+	testDB.InsertTestTimesheet(t, paperID, "2024-01-12", "4.00", "50.00", "Proofread")
+	thesisEntry := testDB.InsertTestTimesheet(t, thesisID, "2024-01-13", "5.00", "40.00", "Rush job")
+	overCost := 50.0
+	require.NoError(t, timesheets.SetCostRate(thesisEntry, &overCost))
+
+	margins, err := model.ProjectMargins()
+	require.NoError(t, err)
+	require.Len(t, margins, 3, "projects without time are left out")
+
+	assert.Equal(t, "Book", margins[0].ProjectName)
+	assert.InDelta(t, 12.0, margins[0].Hours, 0.001)
+	assert.InDelta(t, 1200.0, margins[0].Billed, 0.001)
+	assert.InDelta(t, 600.0, margins[0].Cost, 0.001)
+	assert.InDelta(t, 50.0, margins[0].Percent(), 0.001)
+
+	assert.Equal(t, "Paper", margins[1].ProjectName)
+	assert.InDelta(t, 0.0, margins[1].Cost, 0.001)
+	assert.InDelta(t, 100.0, margins[1].Percent(), 0.001)
+
+	assert.Equal(t, "Thesis", margins[2].ProjectName)
+	assert.InDelta(t, -50.0, margins[2].Profit(), 0.001)
+
+	clients := ClientMargins(margins)
+	require.Len(t, clients, 2)
+	assert.Equal(t, "Acme", clients[0].ClientName)
+	assert.Equal(t, 2, clients[0].Projects)
+	assert.InDelta(t, 1400.0, clients[0].Billed, 0.001)
+	assert.InDelta(t, 800.0, clients[0].Profit(), 0.001)
+	assert.Equal(t, "Zenith", clients[1].ClientName)
+	assert.InDelta(t, 200.0, clients[1].Billed, 0.001)
+}
+
+func TestTimesheetMargin(t *testing.T) {
+	costRate := 30.0
+	margin := TimesheetMargin([]Timesheet{
+		{HoursWorked: 2, HourlyRate: 100, CostRate: &costRate},
+		{HoursWorked: 1, HourlyRate: 100},
+	})
+
+	assert.InDelta(t, 3.0, margin.Hours, 0.001)
+	assert.InDelta(t, 300.0, margin.Billed, 0.001)
+	assert.InDelta(t, 60.0, margin.Cost, 0.001)
+	assert.InDelta(t, 80.0, margin.Percent(), 0.001)
+	assert.Equal(t, 0.0, Margin{}.Percent())
+}
+
+func TestReportModel_ClientValues(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewReportModel(testDB.DB)
+	invoices := NewInvoiceModel(testDB.DB)
+
+	testDB.TruncateTable(t, "invoice")
+	testDB.TruncateTable(t, "project")
+	testDB.TruncateTable(t, "client")
+
+	acmeID := testDB.InsertTestClient(t, "Acme")
+	zenithID := testDB.InsertTestClient(t, "Zenith")
+	bookID := testDB.InsertTestProject(t, "Book", acmeID)
+	paperID := testDB.InsertTestProject(t, "Paper", acmeID)
+	_, err := testDB.DB.Exec("UPDATE project SET created_at = '2021-03-04 09:30:00' WHERE id = ?", paperID)
+	require.NoError(t, err)
+
+	testDB.InsertTestInvoice(t, bookID, "2024-01-10", "2024-02-01", "Net 30", "500.00")
+	partID := testDB.InsertTestInvoice(t, bookID, "2024-02-10", "", "Net 30", "300.00")
+	partPaid := 100.0
+	require.NoError(t, invoices.SetPayment(partID, &partPaid, 0))
+	testDB.InsertTestInvoice(t, paperID, "2024-03-10", "", "Net 30", "400.00")
+	voidedID := testDB.InsertTestInvoice(t, paperID, "2024-03-11", "", "Net 30", "1000.00")
+	require.NoError(t, invoices.Void(voidedID, "Duplicate"))
+
+	values, err := model.ClientValues()
+	require.NoError(t, err)
+	require.Len(t, values, 2)
+
+	acme := values[0]
+	assert.Equal(t, "Acme", acme.ClientName)
+	require.NotNil(t, acme.FirstProject)
+	assert.Equal(t, "2021-03-04", acme.FirstProject.Format("2006-01-02"))
+	assert.Equal(t, 3, acme.Invoices, "voided invoices don't count")
+	assert.InDelta(t, 1200.0, acme.Billed, 0.001)
+	assert.InDelta(t, 600.0, acme.Paid, 0.001, "paid without an amount counts in full, part payments as recorded")
+	assert.InDelta(t, 400.0, acme.AverageInvoice(), 0.001)
+
+	zenith := values[1]
+	assert.Nil(t, zenith.FirstProject)
+	assert.Equal(t, 0, zenith.Invoices)
+	assert.Equal(t, 0.0, zenith.AverageInvoice())
+
+	value, err := model.ClientValue(acmeID)
+	require.NoError(t, err)
+	assert.Equal(t, acme, value)
+
+	_, err = model.ClientValue(zenithID + 100)
+	assert.Equal(t, ErrNoRecord, err)
+}
+
+func TestSortClientValues(t *testing.T) {
+	date := func(value string) *time.Time {
+		t, _ := time.Parse("2006-01-02", value)
+		return &t
+	}
+	values := []ClientValue{
+		{ClientName: "Acme", FirstProject: date("2022-01-01"), Invoices: 4, Billed: 1000, Paid: 900},
+		{ClientName: "Globex", Invoices: 0},
+		{ClientName: "Initech", FirstProject: date("2020-06-01"), Invoices: 1, Billed: 800, Paid: 800},
+		{ClientName: "Zenith", FirstProject: date("2023-01-01"), Invoices: 2, Billed: 1500, Paid: 0},
+	}
+	names := func(by ClientValueSort) []string {
+		sorted := append([]ClientValue(nil), values...)
+		SortClientValues(sorted, by)
+		var names []string
+		for _, value := range sorted {
+			names = append(names, value.ClientName)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"Zenith", "Acme", "Initech", "Globex"}, names(ClientValueByBilled))
+	assert.Equal(t, []string{"Acme", "Initech", "Globex", "Zenith"}, names(ClientValueByPaid))
+	assert.Equal(t, []string{"Initech", "Zenith", "Acme", "Globex"}, names(ClientValueByAverage))
+	assert.Equal(t, []string{"Initech", "Acme", "Zenith", "Globex"}, names(ClientValueBySince))
+	assert.Equal(t, []string{"Acme", "Globex", "Initech", "Zenith"}, names(ClientValueByName))
+
+	assert.Equal(t, ClientValueByPaid, ParseClientValueSort("paid"))
+	assert.Equal(t, ClientValueByBilled, ParseClientValueSort("profit"))
+	assert.Equal(t, ClientValueByBilled, ParseClientValueSort(""))
+}
+
+func TestReportModel_ReceivablesForecast(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewReportModel(testDB.DB)
+	latePayerID := testDB.InsertTestClient(t, "Late Payer")
+	newClientID := testDB.InsertTestClient(t, "New Client")
+	lateProjectID := testDB.InsertTestProject(t, "Book", latePayerID)
+	newProjectID := testDB.InsertTestProject(t, "Paper", newClientID)
+
+	// Paid 10 days after its due date, so the client's other invoices are expected 10 days late
+	testDB.InsertTestInvoice(t, lateProjectID, "2024-03-01", "2024-04-10", "Net 30", "100.00")
+	lateID := testDB.InsertTestInvoice(t, lateProjectID, "2024-05-01", "", "Net 30", "100.00")
+	newID := testDB.InsertTestInvoice(t, newProjectID, "2024-05-20", "", "Net 30", "250.00")
+	overdueID := testDB.InsertTestInvoice(t, newProjectID, "2024-01-01", "", "Net 30", "50.00")
+	voidedID := testDB.InsertTestInvoice(t, newProjectID, "2024-05-20", "", "Net 30", "999.00")
+	require.NoError(t, NewInvoiceModel(testDB.DB).Void(voidedID, "Duplicate"))
+
+	today := time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)
+	forecast, err := model.ReceivablesForecast(today)
+	require.NoError(t, err)
+	assert.InDelta(t, 400.0, forecast.Total, 0.001)
+	require.Len(t, forecast.Weeks, 3)
+
+	assert.Equal(t, "2024-06-03", forecast.Weeks[0].Start.Format(time.DateOnly), "overdue invoices are expected this week")
+	require.Len(t, forecast.Weeks[0].Invoices, 1)
+	assert.Equal(t, overdueID, forecast.Weeks[0].Invoices[0].ID)
+
+	assert.Equal(t, "2024-06-10", forecast.Weeks[1].Start.Format(time.DateOnly))
+	assert.Equal(t, "2024-06-16", forecast.Weeks[1].End().Format(time.DateOnly))
+	late := forecast.Weeks[1].Invoices[0]
+	assert.Equal(t, lateID, late.ID)
+	assert.Equal(t, "Late Payer", late.ClientName)
+	assert.Equal(t, "2024-05-31", late.DueOn.Format(time.DateOnly))
+	assert.Equal(t, 10, late.DaysLate)
+	assert.Equal(t, "2024-06-10", late.ExpectedDate.Format(time.DateOnly))
+	assert.InDelta(t, 100.0, forecast.Weeks[1].Total, 0.001)
+
+	assert.Equal(t, "2024-06-17", forecast.Weeks[2].Start.Format(time.DateOnly))
+	assert.Equal(t, newID, forecast.Weeks[2].Invoices[0].ID)
+	assert.Equal(t, 0, forecast.Weeks[2].Invoices[0].DaysLate, "clients without history are expected on time")
+}
+
+func TestNewReceivablesForecast(t *testing.T) {
+	today := time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)
+	due := time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC)
+	partPaid := 40.0
+	paidInFull := 100.0
+
+	forecast := NewReceivablesForecast([]ForecastInvoice{
+		{Invoice: Invoice{ID: 1, AmountDue: 100, CreditApplied: 10, AmountPaid: &partPaid}, ClientID: 1, DueOn: due},
+		{Invoice: Invoice{ID: 2, AmountDue: 100, AmountPaid: &paidInFull}, ClientID: 1, DueOn: due},
+		{Invoice: Invoice{ID: 3, AmountDue: 20}, ClientID: 2, DueOn: due},
+	}, map[int]PaymentBehavior{2: {PaidInvoices: 3, AvgDaysLate: 6.6}}, today)
+
+	require.Len(t, forecast.Weeks, 2, "fully paid invoices are left out")
+	assert.Equal(t, 1, forecast.Weeks[0].Invoices[0].ID)
+	assert.InDelta(t, 50.0, forecast.Weeks[0].Total, 0.001, "credit and part payments are taken off")
+	assert.Equal(t, 7, forecast.Weeks[1].Invoices[0].DaysLate)
+	assert.Equal(t, "2024-06-19", forecast.Weeks[1].Invoices[0].ExpectedDate.Format(time.DateOnly))
+	assert.InDelta(t, 70.0, forecast.Total, 0.001)
+}
+
+func TestNewAgingReport(t *testing.T) {
+	today := time.Date(2024, 6, 5, 15, 0, 0, 0, time.UTC)
+	partPaid := 40.0
+	paidInFull := 100.0
+	dueDaysAgo := func(days int) time.Time {
+		return time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -days)
+	}
+
+	report := NewAgingReport([]ForecastInvoice{
+		{Invoice: Invoice{ID: 1, AmountDue: 100}, DueOn: dueDaysAgo(-10)},
+		{Invoice: Invoice{ID: 2, AmountDue: 100, AmountPaid: &partPaid}, DueOn: dueDaysAgo(30)},
+		{Invoice: Invoice{ID: 3, AmountDue: 20}, DueOn: dueDaysAgo(31)},
+		{Invoice: Invoice{ID: 4, AmountDue: 70}, DueOn: dueDaysAgo(45)},
+		{Invoice: Invoice{ID: 5, AmountDue: 100, AmountPaid: &paidInFull}, DueOn: dueDaysAgo(60)},
+		{Invoice: Invoice{ID: 6, AmountDue: 5}, DueOn: dueDaysAgo(400)},
+		{Invoice: Invoice{ID: 7, AmountDue: 15}, DueOn: dueDaysAgo(0)},
+	}, today)
+
+	require.Len(t, report.Buckets, 5)
+	assert.Equal(t, "2024-06-05", report.AsOf.Format(time.DateOnly))
+	ids := func(bucket AgingBucket) []int {
+		var ids []int
+		for _, invoice := range bucket.Invoices {
+			ids = append(ids, invoice.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []int{1, 7}, ids(report.Buckets[0]), "invoices due today or later are current")
+	assert.Equal(t, []int{2}, ids(report.Buckets[1]))
+	assert.Equal(t, 30, report.Buckets[1].Invoices[0].DaysOverdue)
+	assert.InDelta(t, 60.0, report.Buckets[1].Total, 0.001, "part payments are taken off")
+	assert.Equal(t, []int{4, 3}, ids(report.Buckets[2]), "the most overdue come first")
+	assert.Empty(t, report.Buckets[3].Invoices, "paid invoices are left out, empty buckets kept")
+	assert.Equal(t, "Over 90 days", report.Buckets[4].Label)
+	assert.Equal(t, []int{6}, ids(report.Buckets[4]))
+	assert.InDelta(t, 270.0, report.Total, 0.001)
+}
+
+func TestReportModel_Aging(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewReportModel(testDB.DB)
+	projectID := testDB.InsertTestProject(t, "Book", testDB.InsertTestClient(t, "Acme, Inc."))
+	overdueID := testDB.InsertTestInvoice(t, projectID, "2024-01-01", "", "Net 30", "50.00")
+	testDB.InsertTestInvoice(t, projectID, "2024-05-20", "", "Net 30", "250.00")
+	testDB.InsertTestInvoice(t, projectID, "2024-01-01", "2024-02-01", "Net 30", "80.00")
+
+	report, err := model.Aging(time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.InDelta(t, 300.0, report.Total, 0.001)
+	require.Len(t, report.Buckets[4].Invoices, 1)
+	assert.Equal(t, overdueID, report.Buckets[4].Invoices[0].ID)
+	assert.Equal(t, 126, report.Buckets[4].Invoices[0].DaysOverdue)
+
+	var csv strings.Builder
+	require.NoError(t, report.WriteCSV(&csv))
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "Bucket,Invoice,Client,Project,Invoice date,Due date,Days overdue,Outstanding", lines[0])
+	assert.Contains(t, lines[1], `Current,`)
+	assert.Equal(t, fmt.Sprintf(`Over 90 days,%04d,"Acme, Inc.",Book,2024-01-01,2024-01-31,126,50.00`, overdueID), lines[2])
+}
+
+func TestReportModel_WorkInProgress(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewReportModel(testDB.DB)
+	bookID := testDB.InsertTestProject(t, "Book", testDB.InsertTestClient(t, "Acme"))
+	billedID := testDB.InsertTestProject(t, "Billed", testDB.InsertTestClient(t, "Globex"))
+	thesisID := testDB.InsertTestProject(t, "Thesis", testDB.InsertTestClient(t, "Initech"))
+
+	testDB.InsertTestTimesheet(t, bookID, "2024-05-01", "3", "100.00", "Chapter 1")
+	testDB.InsertTestTimesheet(t, bookID, "2024-05-20", "2", "100.00", "Chapter 2")
+	testDB.InsertTestTimesheet(t, bookID, "2024-04-01", "0", "100.00", "Template row")
+	pendingID := testDB.InsertTestTimesheet(t, bookID, "2024-03-01", "1", "100.00", "Waiting for approval")
+	require.NoError(t, NewTimesheetModel(testDB.DB).SetPendingApproval(pendingID, true))
+	testDB.InsertTestTimesheet(t, billedID, "2024-02-01", "1", "50.00", "Invoiced")
+	testDB.InsertTestInvoice(t, billedID, "2024-02-28", "", "Net 30", "50.00")
+	testDB.InsertTestTimesheet(t, thesisID, "2024-01-15", "4", "25.00", "Proofreading")
+
+	wip, err := model.WorkInProgress(time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, wip.Projects, 2, "billed work isn't waiting to be invoiced")
+
+	thesis := wip.Projects[0]
+	assert.Equal(t, thesisID, thesis.ProjectID, "the oldest unbilled work comes first")
+	assert.Equal(t, "Initech", thesis.ClientName)
+	assert.Equal(t, 142, thesis.AgeDays)
+
+	book := wip.Projects[1]
+	assert.Equal(t, bookID, book.ProjectID)
+	assert.Equal(t, 2, book.Entries, "pending entries and template rows are left out")
+	assert.InDelta(t, 5.0, book.Hours, 0.001)
+	assert.InDelta(t, 500.0, book.Amount, 0.001)
+	assert.Equal(t, "2024-05-01", book.Oldest.Format(time.DateOnly))
+
+	assert.InDelta(t, 600.0, wip.Total, 0.001)
+	assert.InDelta(t, 9.0, wip.Hours, 0.001)
+}
+
+func TestNewWorkInProgress(t *testing.T) {
+	today := time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)
+	wip := NewWorkInProgress([]UnbilledWork{
+		{ProjectID: 1, Amount: 100, Oldest: today.AddDate(0, 0, -120)},
+		{ProjectID: 2, Amount: 40, Oldest: today.AddDate(0, 0, -61)},
+		{ProjectID: 3, Amount: 25, Oldest: today.AddDate(0, 0, -30)},
+		{ProjectID: 4, Amount: 10, Oldest: today},
+	}, today)
+
+	assert.Equal(t, 120, wip.Projects[0].AgeDays)
+	assert.Equal(t, 0, wip.Projects[3].AgeDays)
+	assert.Equal(t, []WIPAge{
+		{Label: "0-30 days", Projects: 2, Amount: 35},
+		{Label: "31-60 days"},
+		{Label: "61-90 days", Projects: 1, Amount: 40},
+		{Label: "Over 90 days", Projects: 1, Amount: 100},
+	}, wip.Ages)
+	assert.InDelta(t, 175.0, wip.Total, 0.001)
+}
+
+func TestReportModel_Trends(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewReportModel(testDB.DB)
+	bookID := testDB.InsertTestProject(t, "Book", testDB.InsertTestClient(t, "Acme"))
+
+	testDB.InsertTestTimesheet(t, bookID, "2024-05-10", "3", "100.00", "This month")
+	testDB.InsertTestTimesheet(t, bookID, "2024-04-08", "2", "100.00", "Last month, same days")
+	testDB.InsertTestTimesheet(t, bookID, "2024-04-20", "5", "100.00", "Last month, after today's date")
+	testDB.InsertTestTimesheet(t, bookID, "2023-03-01", "4", "50.00", "Last year")
+	pendingID := testDB.InsertTestTimesheet(t, bookID, "2024-05-11", "1", "100.00", "Waiting for approval")
+	require.NoError(t, NewTimesheetModel(testDB.DB).SetPendingApproval(pendingID, true))
+	testDB.InsertTestTimesheet(t, bookID, "2022-12-31", "8", "100.00", "Too old to count")
+
+	testDB.InsertTestInvoice(t, bookID, "2024-05-01", "2024-05-12", "Net 30", "300.00")
+	testDB.InsertTestInvoice(t, bookID, "2023-05-01", "", "Net 30", "200.00")
+
+	trends, err := model.Trends(time.Date(2024, 5, 15, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, trends.Measures, 4)
+	assert.Equal(t, "2024-04-15", trends.LastMonth.End.Format(time.DateOnly))
+
+	hours := trends.Measures[0]
+	assert.Equal(t, Change{Current: 3, Previous: 2}, hours.Month, "pending entries and later days of last month are left out")
+	assert.Equal(t, Change{Current: 10, Previous: 4}, hours.Year)
+	assert.Equal(t, []float64{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 7, 3}, hours.Series)
+
+	work := trends.Measures[1]
+	assert.InDelta(t, 1000.0, work.Year.Current, 0.001)
+	assert.InDelta(t, 200.0, work.Year.Previous, 0.001)
+
+	invoiced := trends.Measures[2]
+	assert.Equal(t, Change{Current: 300, Previous: 0}, invoiced.Month)
+	assert.Equal(t, Change{Current: 300, Previous: 200}, invoiced.Year)
+
+	received := trends.Measures[3]
+	assert.Equal(t, Change{Current: 300, Previous: 0}, received.Month)
+}
+
+func TestNewTrends(t *testing.T) {
+	day := func(value string) time.Time {
+		date, err := time.Parse(time.DateOnly, value)
+		require.NoError(t, err)
+		return date
+	}
+
+	t.Run("periods", func(t *testing.T) {
+		trends := NewTrends(nil, day("2024-03-31"))
+		assert.Equal(t, Period{Start: day("2024-03-01"), End: day("2024-03-31")}, trends.Month)
+		assert.Equal(t, Period{Start: day("2024-02-01"), End: day("2024-02-29")}, trends.LastMonth, "last month ends early when it is shorter")
+		assert.Equal(t, Period{Start: day("2024-01-01"), End: day("2024-03-31")}, trends.Year)
+		assert.Equal(t, Period{Start: day("2023-01-01"), End: day("2023-03-31")}, trends.LastYear)
+		require.Len(t, trends.Months, 12)
+		assert.Equal(t, day("2023-04-01"), trends.Months[0])
+		assert.Equal(t, day("2024-03-01"), trends.Months[11])
+	})
+
+	t.Run("january compares with december", func(t *testing.T) {
+		trends := NewTrends([]DailyTotals{
+			{Day: day("2023-12-05"), Received: 100},
+			{Day: day("2024-01-03"), Received: 40},
+			{Day: day("2024-01-09"), Received: 60},
+		}, day("2024-01-05"))
+		received := trends.Measures[3]
+		assert.Equal(t, Change{Current: 40, Previous: 100}, received.Month)
+		assert.Equal(t, Change{Current: 40, Previous: 0}, received.Year)
+		assert.InDelta(t, -60.0, received.Month.Percent(), 0.001)
+		assert.Equal(t, 100.0, received.Series[10])
+		assert.Equal(t, 40.0, received.Series[11], "days after today are left out")
+	})
+}
+
+func TestChange(t *testing.T) {
+	assert.InDelta(t, 50.0, Change{Current: 150, Previous: 100}.Percent(), 0.001)
+	assert.Equal(t, -25.0, Change{Current: 75, Previous: 100}.Delta())
+	assert.Equal(t, 0.0, Change{Current: 75, Previous: 0}.Percent(), "there is no percentage change from nothing")
+}
+
+func TestTrend_SparklinePoints(t *testing.T) {
+	assert.Equal(t, "0.0,20.0 50.0,0.0 100.0,10.0", Trend{Series: []float64{0, 4, 2}}.SparklinePoints(100, 20))
+	assert.Equal(t, "0.0,20.0 100.0,20.0", Trend{Series: []float64{0, 0}}.SparklinePoints(100, 20), "nothing recorded is a flat line")
+	assert.Empty(t, Trend{}.SparklinePoints(100, 20))
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"math"
+	"strconv"
+)
+
+// RoundingMode decides whether an invoice's final total is rounded or each of its lines,
+// chosen by the invoice_rounding_mode setting
+type RoundingMode string
+
+const (
+	RoundTotal RoundingMode = "total"
+	RoundLines RoundingMode = "line"
+)
+
+// RoundingIncrements lists the amounts invoice totals can be rounded to, in the order they are offered
+var RoundingIncrements = []string{"0.01", "0.05", "1.00"}
+
+// roundingModeOptions lists the rounding modes for the invoice_rounding_mode setting
+func roundingModeOptions() []string {
+	return []string{string(RoundTotal), string(RoundLines)}
+}
+
+// RoundingPolicy is how invoice amounts are rounded
+type RoundingPolicy struct {
+	Increment float64
+	Mode      RoundingMode
+}
+
+// DefaultRoundingPolicy rounds only the final total, to the nearest cent
+var DefaultRoundingPolicy = RoundingPolicy{Increment: 0.01, Mode: RoundTotal}
+
+// NewRoundingPolicy reads the rounding policy from the invoice rounding settings, falling
+// back to the default for anything missing or unknown
+func NewRoundingPolicy(settings map[string]AppSettingValue) RoundingPolicy {
+	policy := DefaultRoundingPolicy
+	if setting, exists := settings["invoice_rounding_increment"]; exists {
+		for _, increment := range RoundingIncrements {
+			if setting.AsString() == increment {
+				policy.Increment, _ = strconv.ParseFloat(increment, 64)
+			}
+		}
+	}
+	if setting, exists := settings["invoice_rounding_mode"]; exists && RoundingMode(setting.AsString()) == RoundLines {
+		policy.Mode = RoundLines
+	}
+	return policy
+}
+
+// Round rounds amount to the nearest increment, halves away from zero
+func (p RoundingPolicy) Round(amount float64) float64 {
+	rounded := math.Round(amount/p.Increment) * p.Increment
+	// Keep to whole cents so that multiplying back doesn't leave 0.15000000000000002
+	return math.Round(rounded*100) / 100
+}
+
+// Total adds up an invoice's amount, discount and adjustment under the policy, returning the
+// rounded total and how far rounding moved it from the exact one
+func (p RoundingPolicy) Total(amount, discount, adjustment float64) (total, roundingAdjustment float64) {
+	exact := amount - discount + adjustment
+	if p.Mode == RoundLines {
+		total = p.Round(amount) - p.Round(discount) + p.Round(adjustment)
+	} else {
+		total = p.Round(exact)
+	}
+	total = math.Round(total*100) / 100
+	return total, math.Round((total-exact)*100) / 100
+}
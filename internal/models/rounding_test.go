@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundingPolicy(t *testing.T) {
+	t.Run("settings", func(t *testing.T) {
+		assert.Equal(t, DefaultRoundingPolicy, NewRoundingPolicy(nil))
+		assert.Equal(t, RoundingPolicy{Increment: 0.05, Mode: RoundLines}, NewRoundingPolicy(map[string]AppSettingValue{
+			"invoice_rounding_increment": {Value: "0.05", DataType: "string"},
+			"invoice_rounding_mode":      {Value: "line", DataType: "string"},
+		}))
+		assert.Equal(t, DefaultRoundingPolicy, NewRoundingPolicy(map[string]AppSettingValue{
+			"invoice_rounding_increment": {Value: "0.25", DataType: "string"},
+			"invoice_rounding_mode":      {Value: "banker", DataType: "string"},
+		}))
+	})
+
+	t.Run("rounding to an increment", func(t *testing.T) {
+		tests := []struct {
+			increment float64
+			amount    float64
+			rounded   float64
+		}{
+			{0.01, 123.455, 123.46},
+			{0.05, 10.12, 10.10},
+			{0.05, 10.13, 10.15},
+			{0.05, 0.149, 0.15},
+			{1.00, 99.50, 100.00},
+			{1.00, 99.49, 99.00},
+			{1.00, -2.5, -3.00},
+		}
+		for _, tt := range tests {
+			policy := RoundingPolicy{Increment: tt.increment, Mode: RoundTotal}
+			assert.Equal(t, tt.rounded, policy.Round(tt.amount), "%.3f to %.2f", tt.amount, tt.increment)
+		}
+	})
+
+	t.Run("rounding the total", func(t *testing.T) {
+		policy := RoundingPolicy{Increment: 1.00, Mode: RoundTotal}
+		total, adjustment := policy.Total(100.40, 10.04, 0.30)
+		assert.Equal(t, 91.0, total)
+		assert.Equal(t, 0.34, adjustment)
+	})
+
+	t.Run("rounding each line", func(t *testing.T) {
+		policy := RoundingPolicy{Increment: 1.00, Mode: RoundLines}
+		total, adjustment := policy.Total(100.40, 10.04, 0.30)
+		assert.Equal(t, 90.0, total)
+		assert.Equal(t, -0.66, adjustment)
+	})
+
+	t.Run("totals already on the increment aren't adjusted", func(t *testing.T) {
+		total, adjustment := DefaultRoundingPolicy.Total(200, 20, 0)
+		assert.Equal(t, 180.0, total)
+		assert.Zero(t, adjustment)
+	})
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// SavedView is a filter of a list page saved under a name. Views belong to the user who saved
+// them; UserID is nil for views saved while nobody was logged in, before any accounts existed.
+type SavedView struct {
+	ID      int
+	UserID  *int
+	List    string
+	Name    string
+	Filter  string
+	Updated time.Time
+	Created time.Time
+}
+
+// BelongsTo reports whether the view was saved by the given user, or by nobody when userID is nil
+func (v SavedView) BelongsTo(userID *int) bool {
+	if v.UserID == nil || userID == nil {
+		return v.UserID == nil && userID == nil
+	}
+	return *v.UserID == *userID
+}
+
+// SavedViewModel wraps the generated SQLC Queries for saved view operations
+type SavedViewModel struct {
+	queries *db.Queries
+}
+
+// NewSavedViewModel creates a new SavedViewModel
+func NewSavedViewModel(database *sql.DB) *SavedViewModel {
+	return &SavedViewModel{
+		queries: newQueries(database),
+	}
+}
+
+// NewSavedViewModelWithTx creates a SavedViewModel whose queries run inside the given transaction
+func NewSavedViewModelWithTx(tx *sql.Tx) *SavedViewModel {
+	return &SavedViewModel{
+		queries: newQueries(tx),
+	}
+}
+
+// Insert saves a filter of a list under a name for a user and returns the view's ID
+func (m *SavedViewModel) Insert(userID *int, list, name, filter string) (int, error) {
+	ctx := context.Background()
+	id, err := m.queries.InsertSavedView(ctx, db.InsertSavedViewParams{
+		UserID: convertIntPtr(userID),
+		List:   list,
+		Name:   name,
+		Filter: filter,
+	})
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return int(id), nil
+}
+
+// Get retrieves a saved view by ID
+func (m *SavedViewModel) Get(id int) (SavedView, error) {
+	ctx := context.Background()
+	row, err := m.queries.GetSavedView(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return SavedView{}, ErrNoRecord
+		}
+		return SavedView{}, err
+	}
+	return newSavedView(db.GetSavedViewsForListRow(row)), nil
+}
+
+// GetForList retrieves the views a user has saved of a list, ordered by name
+func (m *SavedViewModel) GetForList(userID *int, list string) ([]SavedView, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetSavedViewsForList(ctx, db.GetSavedViewsForListParams{
+		List:   list,
+		UserID: convertIntPtr(userID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]SavedView, len(rows))
+	for i, row := range rows {
+		views[i] = newSavedView(row)
+	}
+	return views, nil
+}
+
+// Delete removes a saved view
+func (m *SavedViewModel) Delete(id int) error {
+	ctx := context.Background()
+	return m.queries.DeleteSavedView(ctx, int64(id))
+}
+
+func newSavedView(row db.GetSavedViewsForListRow) SavedView {
+	return SavedView{
+		ID:      int(row.ID),
+		UserID:  convertNullInt64(row.UserID),
+		List:    row.List,
+		Name:    row.Name,
+		Filter:  row.Filter,
+		Updated: row.UpdatedAt,
+		Created: row.CreatedAt,
+	}
+}
+
+// SavedViewModelInterface defines the interface for saved view operations
+type SavedViewModelInterface interface {
+	Insert(userID *int, list, name, filter string) (int, error)
+	Get(id int) (SavedView, error)
+	GetForList(userID *int, list string) ([]SavedView, error)
+	Delete(id int) error
+}
+
+// Ensure implementation satisfies the interface
+var _ SavedViewModelInterface = (*SavedViewModel)(nil)
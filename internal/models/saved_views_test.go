@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedViewModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewSavedViewModel(testDB.DB)
+	userID, err := NewUserModel(testDB.DB).Insert(User{Name: "Owner", Email: "owner@example.com", Role: RoleOwner}, "correct horse")
+	require.NoError(t, err)
+
+	overdueID, err := model.Insert(&userID, "clients", "Overdue", "filter=oldest_unpaid.lt.today-30")
+	require.NoError(t, err)
+	abroadID, err := model.Insert(&userID, "clients", "Abroad", "filter=country.ne.US")
+	require.NoError(t, err)
+	_, err = model.Insert(&userID, "projects", "Scheduled", "filter=status.eq.Scheduled")
+	require.NoError(t, err)
+	anonymousID, err := model.Insert(nil, "clients", "Before accounts", "filter=city.eq.Boston")
+	require.NoError(t, err)
+
+	t.Run("get returns the view", func(t *testing.T) {
+		view, err := model.Get(overdueID)
+		require.NoError(t, err)
+		require.NotNil(t, view.UserID)
+		assert.Equal(t, userID, *view.UserID)
+		assert.Equal(t, "clients", view.List)
+		assert.Equal(t, "filter=oldest_unpaid.lt.today-30", view.Filter)
+		assert.True(t, view.BelongsTo(&userID))
+		assert.False(t, view.BelongsTo(nil))
+
+		_, err = model.Get(99999)
+		assert.ErrorIs(t, err, ErrNoRecord)
+	})
+
+	t.Run("views are listed per user and list by name", func(t *testing.T) {
+		views, err := model.GetForList(&userID, "clients")
+		require.NoError(t, err)
+		require.Len(t, views, 2)
+		assert.Equal(t, abroadID, views[0].ID)
+		assert.Equal(t, overdueID, views[1].ID)
+
+		views, err = model.GetForList(nil, "clients")
+		require.NoError(t, err)
+		require.Len(t, views, 1)
+		assert.Equal(t, anonymousID, views[0].ID)
+		assert.True(t, views[0].BelongsTo(nil))
+	})
+
+	t.Run("delete removes the view", func(t *testing.T) {
+		require.NoError(t, model.Delete(abroadID))
+		_, err := model.Get(abroadID)
+		assert.ErrorIs(t, err, ErrNoRecord)
+	})
+}
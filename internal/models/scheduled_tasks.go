@@ -0,0 +1,84 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// ScheduledTask is the record of a task run on a cron schedule: when it last fell due, the
+// job it queued then, and why it couldn't be run if it last failed to
+type ScheduledTask struct {
+	Name      string
+	LastRun   *time.Time
+	LastJobID *int
+	LastError string
+	Updated   time.Time
+}
+
+// ScheduledTaskModel wraps the generated SQLC Queries for the runs of scheduled tasks
+type ScheduledTaskModel struct {
+	queries *db.Queries
+}
+
+// NewScheduledTaskModel creates a new ScheduledTaskModel
+func NewScheduledTaskModel(database *sql.DB) *ScheduledTaskModel {
+	return &ScheduledTaskModel{
+		queries: newQueries(database),
+	}
+}
+
+// Get returns the record of a scheduled task, or ErrNoRecord when it has never run
+func (m *ScheduledTaskModel) Get(name string) (ScheduledTask, error) {
+	ctx := context.Background()
+	row, err := m.queries.GetScheduledTask(ctx, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ScheduledTask{}, ErrNoRecord
+		}
+		return ScheduledTask{}, err
+	}
+	return scheduledTaskFromRow(row), nil
+}
+
+// RecordRun records that a task due at the given time queued a job, clearing any error
+func (m *ScheduledTaskModel) RecordRun(name string, at time.Time, jobID int) error {
+	ctx := context.Background()
+	return m.queries.RecordScheduledTaskRun(ctx, db.RecordScheduledTaskRunParams{
+		Name:      name,
+		LastRunAt: sql.NullTime{Time: at.UTC(), Valid: true},
+		LastJobID: sql.NullInt64{Int64: int64(jobID), Valid: true},
+	})
+}
+
+// RecordError records why a task couldn't be run, such as a schedule that doesn't parse
+func (m *ScheduledTaskModel) RecordError(name, message string) error {
+	ctx := context.Background()
+	return m.queries.RecordScheduledTaskError(ctx, db.RecordScheduledTaskErrorParams{
+		Name:      name,
+		LastError: message,
+	})
+}
+
+func scheduledTaskFromRow(row db.ScheduledTask) ScheduledTask {
+	return ScheduledTask{
+		Name:      row.Name,
+		LastRun:   convertNullTime(row.LastRunAt),
+		LastJobID: convertNullInt64(row.LastJobID),
+		LastError: row.LastError,
+		Updated:   row.UpdatedAt,
+	}
+}
+
+// ScheduledTaskModelInterface defines the interface for scheduled task operations
+type ScheduledTaskModelInterface interface {
+	Get(name string) (ScheduledTask, error)
+	RecordRun(name string, at time.Time, jobID int) error
+	RecordError(name, message string) error
+}
+
+// Ensure implementation satisfies the interface
+var _ ScheduledTaskModelInterface = (*ScheduledTaskModel)(nil)
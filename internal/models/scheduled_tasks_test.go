@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledTaskModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewScheduledTaskModel(testDB.DB)
+
+	_, err := model.Get(JobReminders)
+	assert.ErrorIs(t, err, ErrNoRecord)
+
+	require.NoError(t, model.RecordError(JobReminders, "schedule_reminders: hour 25 must be between 0 and 23"))
+	task, err := model.Get(JobReminders)
+	require.NoError(t, err)
+	assert.Nil(t, task.LastRun)
+	assert.Nil(t, task.LastJobID)
+	assert.Contains(t, task.LastError, "hour 25")
+
+	due := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, model.RecordRun(JobReminders, due, 7))
+	task, err = model.Get(JobReminders)
+	require.NoError(t, err)
+	require.NotNil(t, task.LastRun)
+	assert.True(t, due.Equal(*task.LastRun))
+	require.NotNil(t, task.LastJobID)
+	assert.Equal(t, 7, *task.LastJobID)
+	assert.Empty(t, task.LastError, "a run clears the last error")
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// Units a service can be billed in
+const (
+	UnitHour = "hour"
+	UnitWord = "word"
+	UnitPage = "page"
+)
+
+// Units lists the billing units in the order they are offered on forms
+var Units = []string{UnitHour, UnitWord, UnitPage}
+
+// ValidUnit reports whether unit is one of the billing units
+func ValidUnit(unit string) bool {
+	for _, u := range Units {
+		if u == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatRate formats a rate to the cent, or with more decimal places when it has them, as
+// per-word rates often do, e.g. 0.025
+func FormatRate(rate float64) string {
+	if cents := math.Round(rate * 100); math.Abs(rate*100-cents) < 1e-9 {
+		return strconv.FormatFloat(rate, 'f', 2, 64)
+	}
+	return strconv.FormatFloat(rate, 'f', -1, 64)
+}
+
+// Service represents an entry in the service catalog, such as hourly editing or per-word
+// proofreading, with the rate it is billed at by default
+type Service struct {
+	ID          int
+	Name        string
+	DefaultRate float64
+	Unit        string
+	Updated     time.Time
+	Created     time.Time
+	DeletedAt   *time.Time
+}
+
+// Rate returns the default rate formatted for display
+func (s Service) Rate() string {
+	return FormatRate(s.DefaultRate)
+}
+
+// ServiceModel wraps the generated SQLC Queries for service operations
+type ServiceModel struct {
+	queries *db.Queries
+}
+
+// NewServiceModel creates a new ServiceModel
+func NewServiceModel(database *sql.DB) *ServiceModel {
+	return &ServiceModel{
+		queries: newQueries(database),
+	}
+}
+
+// NewServiceModelWithTx creates a ServiceModel whose queries run inside the given transaction
+func NewServiceModelWithTx(tx *sql.Tx) *ServiceModel {
+	return &ServiceModel{
+		queries: newQueries(tx),
+	}
+}
+
+// Insert adds a new service to the catalog and returns its ID
+func (s *ServiceModel) Insert(service Service) (int, error) {
+	ctx := context.Background()
+	id, err := s.queries.InsertService(ctx, db.InsertServiceParams{
+		Name:        service.Name,
+		DefaultRate: service.DefaultRate,
+		Unit:        service.Unit,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// Get retrieves a service by ID
+func (s *ServiceModel) Get(id int) (Service, error) {
+	ctx := context.Background()
+	row, err := s.queries.GetService(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Service{}, ErrNoRecord
+		}
+		return Service{}, err
+	}
+
+	var deletedAt *time.Time
+	if row.DeletedAt != nil {
+		if dt, ok := row.DeletedAt.(time.Time); ok {
+			deletedAt = &dt
+		}
+	}
+
+	return Service{
+		ID:          int(row.ID),
+		Name:        row.Name,
+		DefaultRate: row.DefaultRate,
+		Unit:        row.Unit,
+		Updated:     row.UpdatedAt,
+		Created:     row.CreatedAt,
+		DeletedAt:   deletedAt,
+	}, nil
+}
+
+// GetAll retrieves all services ordered by name
+func (s *ServiceModel) GetAll() ([]Service, error) {
+	ctx := context.Background()
+	rows, err := s.queries.GetAllServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]Service, len(rows))
+	for i, row := range rows {
+		services[i] = Service{
+			ID:          int(row.ID),
+			Name:        row.Name,
+			DefaultRate: row.DefaultRate,
+			Unit:        row.Unit,
+			Updated:     row.UpdatedAt,
+			Created:     row.CreatedAt,
+		}
+	}
+
+	return services, nil
+}
+
+// Update modifies an existing service. Entries already logged keep the unit they were logged in.
+func (s *ServiceModel) Update(service Service) error {
+	ctx := context.Background()
+	return s.queries.UpdateService(ctx, db.UpdateServiceParams{
+		Name:        service.Name,
+		DefaultRate: service.DefaultRate,
+		Unit:        service.Unit,
+		ID:          int64(service.ID),
+	})
+}
+
+// Delete soft deletes a service by setting the deleted_at timestamp
+func (s *ServiceModel) Delete(id int) error {
+	ctx := context.Background()
+	return s.queries.DeleteService(ctx, int64(id))
+}
+
+// ServiceModelInterface defines the interface for service operations
+type ServiceModelInterface interface {
+	Insert(service Service) (int, error)
+	Get(id int) (Service, error)
+	GetAll() ([]Service, error)
+	Update(service Service) error
+	Delete(id int) error
+}
+
+// Ensure implementation satisfies the interface
+var _ ServiceModelInterface = (*ServiceModel)(nil)
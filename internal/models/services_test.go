@@ -0,0 +1,130 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceModel_CRUD(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewServiceModel(testDB.DB)
+
+	t.Run("insert, update and delete", func(t *testing.T) {
+		testDB.TruncateTable(t, "service")
+
+		id, err := model.Insert(Service{Name: "Proofreading", DefaultRate: 0.025, Unit: UnitWord})
+		require.NoError(t, err)
+
+		service, err := model.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, "Proofreading", service.Name)
+		assert.Equal(t, 0.025, service.DefaultRate)
+		assert.Equal(t, UnitWord, service.Unit)
+		assert.Equal(t, "0.025", service.Rate())
+
+		service.Unit = UnitPage
+		service.DefaultRate = 4
+		require.NoError(t, model.Update(service))
+
+		service, err = model.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, UnitPage, service.Unit)
+		assert.Equal(t, "4.00", service.Rate())
+
+		require.NoError(t, model.Delete(id))
+		_, err = model.Get(id)
+		assert.Equal(t, ErrNoRecord, err)
+
+		services, err := model.GetAll()
+		require.NoError(t, err)
+		assert.Empty(t, services)
+	})
+
+	t.Run("unknown unit is rejected", func(t *testing.T) {
+		testDB.TruncateTable(t, "service")
+
+		_, err := model.Insert(Service{Name: "Translation", DefaultRate: 0.1, Unit: "line"})
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+}
+
+func TestTimesheetModel_SetService(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	testDB.TruncateTable(t, "timesheet")
+	testDB.TruncateTable(t, "service")
+	testDB.TruncateTable(t, "project")
+	testDB.TruncateTable(t, "client")
+
+	clientID := testDB.InsertTestClient(t, "Publisher")
+	projectID := testDB.InsertTestProject(t, "Novel", clientID)
+
+	serviceID, err := NewServiceModel(testDB.DB).Insert(Service{Name: "Proofreading", DefaultRate: 0.025, Unit: UnitWord})
+	require.NoError(t, err)
+
+	model := NewTimesheetModel(testDB.DB)
+	workDate := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	hourlyID, err := model.Insert(projectID, workDate, 2, 50, "Structural edit")
+	require.NoError(t, err)
+	wordsID, err := model.Insert(projectID, workDate, 4000, 0.025, "Chapters 1-3")
+	require.NoError(t, err)
+	require.NoError(t, model.SetService(wordsID, &serviceID, UnitWord))
+
+	words, err := model.Get(wordsID)
+	require.NoError(t, err)
+	require.NotNil(t, words.ServiceID)
+	assert.Equal(t, serviceID, *words.ServiceID)
+	assert.Equal(t, "Proofreading", words.ServiceName)
+	assert.False(t, words.IsHourly())
+	assert.Equal(t, 100.0, words.Amount())
+	assert.Equal(t, "4000 words", words.Quantity())
+	assert.Equal(t, "$0.025/word", "$"+words.Rate()+"/"+words.RateUnit())
+
+	hourly, err := model.Get(hourlyID)
+	require.NoError(t, err)
+	assert.Nil(t, hourly.ServiceID)
+	assert.True(t, hourly.IsHourly())
+	assert.Equal(t, "2.00 hours", hourly.Quantity())
+
+	t.Run("words are left out of hour totals", func(t *testing.T) {
+		hours, err := model.GetClientHoursForMonth(clientID, workDate)
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, hours)
+
+		timesheets, err := model.GetByProject(projectID)
+		require.NoError(t, err)
+		margin := TimesheetMargin(timesheets)
+		assert.Equal(t, 2.0, margin.Hours)
+		assert.Equal(t, 200.0, margin.Billed)
+
+		margins, err := NewReportModel(testDB.DB).ProjectMargins()
+		require.NoError(t, err)
+		require.Len(t, margins, 1)
+		assert.Equal(t, 2.0, margins[0].Hours)
+		assert.Equal(t, 200.0, margins[0].Billed)
+	})
+
+	t.Run("clearing the service makes the entry hourly again", func(t *testing.T) {
+		require.NoError(t, model.SetService(wordsID, nil, UnitWord))
+
+		words, err := model.Get(wordsID)
+		require.NoError(t, err)
+		assert.Nil(t, words.ServiceID)
+		assert.True(t, words.IsHourly())
+	})
+}
+
+func TestFormatRate(t *testing.T) {
+	assert.Equal(t, "125.00", FormatRate(125))
+	assert.Equal(t, "0.10", FormatRate(0.1))
+	assert.Equal(t, "0.025", FormatRate(0.025))
+	assert.Equal(t, "0.00", FormatRate(0))
+}
@@ -0,0 +1,221 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/cron"
+)
+
+// SettingRule declares the values a setting accepts. Type is one of the setting data types
+// and decides the input shown for it; the other checks apply only when they are set. Check
+// is for values a pattern can't describe, returning why the value isn't accepted. Secret
+// values are never shown or sent back to the browser, so leaving one blank keeps it.
+type SettingRule struct {
+	Type     string
+	Optional bool
+	Secret   bool
+	Min      *float64
+	Max      *float64
+	Pattern  *regexp.Regexp
+	Hint     string
+	Options  []string
+	Check    func(value string) error
+}
+
+// Step returns the step attribute for the rule's number input
+func (r SettingRule) Step() string {
+	switch r.Type {
+	case "int":
+		return "1"
+	case "decimal":
+		return "0.01"
+	}
+	return "any"
+}
+
+// IsNumber reports whether the setting is entered as a number
+func (r SettingRule) IsNumber() bool {
+	return r.Type == "int" || r.Type == "decimal" || r.Type == "float"
+}
+
+// SettingError reports a setting value its rule doesn't accept. It matches ErrValidation
+// with errors.Is.
+type SettingError struct {
+	Key     string
+	Message string
+}
+
+func (e *SettingError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Key, e.Message)
+}
+
+func (e *SettingError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+func bound(v float64) *float64 {
+	return &v
+}
+
+var (
+	emailPattern        = regexp.MustCompile(`^[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}$`)
+	urlPattern          = regexp.MustCompile(`^https?://\S+$`)
+	currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+	swiftPattern        = regexp.MustCompile(`^[A-Z0-9]{8}([A-Z0-9]{3})?$`)
+	ibanPattern         = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+	telegramChatPattern = regexp.MustCompile(`^(-?[0-9]+|@[A-Za-z][A-Za-z0-9_]{4,})$`)
+)
+
+// dateFormatOptions lists the supported date formats for the date_format setting
+func dateFormatOptions() []string {
+	options := make([]string, len(DateFormats))
+	for i, format := range DateFormats {
+		options[i] = string(format)
+	}
+	return options
+}
+
+// SettingRules declares how each known setting is validated and entered. Settings added
+// without a rule are checked against their data type alone.
+var SettingRules = map[string]SettingRule{
+	"default_hourly_rate":                {Type: "decimal", Min: bound(0)},
+	"list_page_size":                     {Type: "int", Min: bound(1), Max: bound(100)},
+	"invoice_max_pdf_pages":              {Type: "int", Min: bound(1), Max: bound(500)},
+	"timesheet_description_max_length":   {Type: "int", Min: bound(1), Max: bound(10000)},
+	"invoice_show_individual_timesheets": {Type: "bool"},
+	"invoice_group_by_work_type":         {Type: "bool"},
+	"timesheet_work_types":               {Type: "string", Optional: true},
+	"invoice_reminder_interval_days":     {Type: "int", Min: bound(0), Max: bound(365)},
+	"disk_space_warn_mb":                 {Type: "int", Min: bound(0)},
+	"disk_space_min_mb":                  {Type: "int", Min: bound(0)},
+	"date_format":                        {Type: "string", Options: dateFormatOptions()},
+	"freelancer_email":                   {Type: "string", Pattern: emailPattern, Hint: "Must be a valid email address"},
+	"payment_domestic_currency":          {Type: "string", Pattern: currencyCodePattern, Hint: "Must be a three letter currency code, e.g. USD"},
+	"payment_bank_name":                  {Type: "string", Optional: true},
+	"payment_account_name":               {Type: "string", Optional: true},
+	"payment_routing_number":             {Type: "string", Optional: true},
+	"payment_account_number":             {Type: "string", Optional: true},
+	"payment_iban":                       {Type: "string", Optional: true, Pattern: ibanPattern, Hint: "Must be an IBAN in capitals without spaces, e.g. DE89370400440532013000"},
+	"payment_swift":                      {Type: "string", Optional: true, Pattern: swiftPattern, Hint: "Must be an 8 or 11 character SWIFT/BIC code"},
+	"payment_paypal_address":             {Type: "string", Optional: true, Pattern: emailPattern, Hint: "Must be a valid email address"},
+	"contacts_carddav_url":               {Type: "string", Optional: true, Pattern: urlPattern, Hint: "Must be an http or https URL"},
+	"contacts_carddav_username":          {Type: "string", Optional: true},
+	"contacts_carddav_password":          {Type: "string", Optional: true, Secret: true},
+	"contacts_google_access_token":       {Type: "string", Optional: true, Secret: true},
+	"api_key":                            {Type: "string", Optional: true},
+	"webhook_invoice_paid_url":           {Type: "string", Optional: true, Pattern: urlPattern, Hint: "Must be an http or https URL"},
+	"inbound_email_signing_key":          {Type: "string", Optional: true},
+	"inbound_email_sender":               {Type: "string", Optional: true, Pattern: emailPattern, Hint: "Must be a valid email address"},
+	"pdf_signing_certificate":            {Type: "string", Optional: true},
+	"pdf_signing_password":               {Type: "string", Optional: true},
+	"smtp_host":                          {Type: "string", Optional: true},
+	"smtp_port":                          {Type: "int", Min: bound(1), Max: bound(65535)},
+	"smtp_username":                      {Type: "string", Optional: true},
+	"smtp_password":                      {Type: "string", Optional: true},
+	"email_open_tracking":                {Type: "bool"},
+	"client_email_check":                 {Type: "bool"},
+	"invoice_rounding_increment":         {Type: "string", Options: RoundingIncrements},
+	"invoice_rounding_mode":              {Type: "string", Options: roundingModeOptions()},
+	"slack_webhook_url":                  {Type: "string", Optional: true, Pattern: urlPattern, Hint: "Must be an http or https URL"},
+	"telegram_bot_token":                 {Type: "string", Optional: true},
+	"telegram_chat_id":                   {Type: "string", Optional: true, Pattern: telegramChatPattern, Hint: "Must be a chat ID number or a channel @username"},
+	"deadline_reminder_days":             {Type: "int", Min: bound(0), Max: bound(365)},
+	"invoice_page_header_footer":         {Type: "bool"},
+	"invoice_page_header":                {Type: "string", Optional: true},
+	"invoice_page_footer":                {Type: "string", Optional: true},
+	"invoice_default_display_details":    {Type: "bool"},
+	"invoice_default_date_today":         {Type: "bool"},
+	"invoice_default_payment_terms":      {Type: "string", Optional: true},
+	"invoice_default_amount_auto":        {Type: "bool"},
+	"exchange_rate_url":                  {Type: "string", Optional: true, Pattern: urlPattern, Hint: "Must be an http or https URL"},
+	"schedule_consolidated_invoices":     {Type: "string", Optional: true, Check: cron.Validate, Hint: "Must be a cron schedule such as 0 0 1 * *"},
+	"schedule_reminders":                 {Type: "string", Optional: true, Check: cron.Validate, Hint: "Must be a cron schedule such as 0 0 * * *"},
+	"schedule_aging_report":              {Type: "string", Optional: true, Check: cron.Validate, Hint: "Must be a cron schedule such as 0 6 1 * *"},
+}
+
+// RuleFor returns the rule for a setting, falling back to one that only checks the data type
+func RuleFor(key, dataType string) SettingRule {
+	if rule, ok := SettingRules[key]; ok {
+		return rule
+	}
+	return SettingRule{Type: dataType}
+}
+
+// Rule returns the rule the setting's value is validated against
+func (s AppSetting) Rule() SettingRule {
+	return RuleFor(s.Key, s.DataType)
+}
+
+// ValidateSetting checks value against the rule for the setting, returning a SettingError
+// describing the first check it fails
+func ValidateSetting(key, dataType, value string) error {
+	rule := RuleFor(key, dataType)
+	invalid := func(message string) error {
+		return &SettingError{Key: key, Message: message}
+	}
+
+	if strings.TrimSpace(value) == "" {
+		if rule.Optional {
+			return nil
+		}
+		return invalid("This field is required")
+	}
+
+	switch rule.Type {
+	case "int", "decimal", "float":
+		var number float64
+		if rule.Type == "int" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return invalid("Must be a valid integer")
+			}
+			number = float64(n)
+		} else {
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return invalid("Must be a valid number")
+			}
+			number = n
+		}
+		if rule.Min != nil && number < *rule.Min {
+			return invalid(fmt.Sprintf("Must be at least %s", strconv.FormatFloat(*rule.Min, 'f', -1, 64)))
+		}
+		if rule.Max != nil && number > *rule.Max {
+			return invalid(fmt.Sprintf("Must be at most %s", strconv.FormatFloat(*rule.Max, 'f', -1, 64)))
+		}
+	case "bool":
+		if value != "true" && value != "false" {
+			return invalid("Must be true or false")
+		}
+	}
+
+	if rule.Options != nil {
+		found := false
+		for _, option := range rule.Options {
+			found = found || option == value
+		}
+		if !found {
+			return invalid(fmt.Sprintf("Must be one of %s", strings.Join(rule.Options, ", ")))
+		}
+	}
+
+	if rule.Pattern != nil && !rule.Pattern.MatchString(value) {
+		if rule.Hint != "" {
+			return invalid(rule.Hint)
+		}
+		return invalid("Is not in the expected format")
+	}
+
+	if rule.Check != nil {
+		if err := rule.Check(value); err != nil {
+			if rule.Hint != "" {
+				return invalid(fmt.Sprintf("%s (%s)", rule.Hint, err))
+			}
+			return invalid(err.Error())
+		}
+	}
+	return nil
+}
@@ -68,7 +68,14 @@ type AppSettingModel struct {
 // NewAppSettingModel creates a new AppSettingModel
 func NewAppSettingModel(database *sql.DB) *AppSettingModel {
 	return &AppSettingModel{
-		queries: db.New(database),
+		queries: newQueries(database),
+	}
+}
+
+// NewAppSettingModelWithTx creates a AppSettingModel whose queries run inside the given transaction
+func NewAppSettingModelWithTx(tx *sql.Tx) *AppSettingModel {
+	return &AppSettingModel{
+		queries: newQueries(tx),
 	}
 }
 
@@ -220,8 +227,18 @@ func (s *AppSettingModel) GetAllDetailed() ([]AppSetting, error) {
 	return settings, nil
 }
 
-// UpdateValue modifies only the value of an existing setting
+// UpdateValue modifies only the value of an existing setting, after checking it against the
+// setting's rule. A value the rule rejects is returned as a SettingError.
 func (s *AppSettingModel) UpdateValue(key, value string) error {
+	setting, err := s.Get(key)
+	if err != nil {
+		return err
+	}
+	err = ValidateSetting(key, setting.DataType, value)
+	if err != nil {
+		return err
+	}
+
 	ctx := context.Background()
 	params := db.UpdateSettingParams{
 		Key:   key,
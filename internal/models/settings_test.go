@@ -1,9 +1,12 @@
 package models
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAppSettingModel_GetString(t *testing.T) {
@@ -104,3 +107,62 @@ func TestAppSettingModel_UpdateValue(t *testing.T) {
 		t.Errorf("Expected rate to be 95.00, got %f", rate)
 	}
 }
+
+func TestValidateSetting(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		dataType string
+		value    string
+		message  string
+	}{
+		{"decimal", "default_hourly_rate", "decimal", "95.50", ""},
+		{"negative decimal", "default_hourly_rate", "decimal", "-1", "Must be at least 0"},
+		{"not a number", "default_hourly_rate", "decimal", "lots", "Must be a valid number"},
+		{"int stored as string", "list_page_size", "string", "25", ""},
+		{"int out of range", "list_page_size", "string", "500", "Must be at most 100"},
+		{"not an int", "invoice_max_pdf_pages", "int", "2.5", "Must be a valid integer"},
+		{"bool", "invoice_show_individual_timesheets", "bool", "maybe", "Must be true or false"},
+		{"enum", "date_format", "string", "DD/MM/YY", "Must be one of YYYY-MM-DD, DD.MM.YYYY, MM/DD/YYYY"},
+		{"required", "invoice_title", "string", " ", "This field is required"},
+		{"optional left blank", "payment_swift", "string", "", ""},
+		{"pattern", "payment_swift", "string", "DEUT", "Must be an 8 or 11 character SWIFT/BIC code"},
+		{"email", "freelancer_email", "string", "me@example", "Must be a valid email address"},
+		{"url", "webhook_invoice_paid_url", "string", "hooks.example.com/paid", "Must be an http or https URL"},
+		{"telegram chat id", "telegram_chat_id", "string", "-1001234567890", ""},
+		{"telegram channel", "telegram_chat_id", "string", "freelance_alerts", "Must be a chat ID number or a channel @username"},
+		{"cron schedule", "schedule_reminders", "string", "30 8 * * mon-fri", ""},
+		{"cron schedule left blank", "schedule_reminders", "string", "", ""},
+		{"bad cron schedule", "schedule_reminders", "string", "0 25 * * *", "Must be a cron schedule such as 0 0 * * * (hour 25 must be between 0 and 23)"},
+		{"unknown setting checked by data type", "new_setting", "int", "x", "Must be a valid integer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSetting(tt.key, tt.dataType, tt.value)
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var settingErr *SettingError
+			require.True(t, errors.As(err, &settingErr), "expected a SettingError, got %v", err)
+			assert.Equal(t, tt.message, settingErr.Message)
+			assert.ErrorIs(t, err, ErrValidation)
+		})
+	}
+}
+
+func TestAppSettingModel_UpdateValueEnforcesRules(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+	model := NewAppSettingModel(testDB.DB)
+
+	err := model.UpdateValue("invoice_max_pdf_pages", "0")
+	assert.ErrorIs(t, err, ErrValidation)
+
+	pages, err := model.GetInt("invoice_max_pdf_pages")
+	require.NoError(t, err)
+	assert.Equal(t, 10, pages)
+
+	err = model.UpdateValue("no_such_setting", "1")
+	assert.Equal(t, ErrNoRecord, err)
+}
@@ -0,0 +1,291 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// DefaultTagColor is the colour given to tags created without one
+const DefaultTagColor = "#6c757d"
+
+// TagColorPattern matches the #rrggbb colours a tag can be shown in
+var TagColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Tag represents a coloured label that clients and projects can be grouped by
+type Tag struct {
+	ID      int
+	Name    string
+	Color   string
+	Updated time.Time
+	Created time.Time
+}
+
+// TextColor returns black or white, whichever reads better on the tag's colour
+func (t Tag) TextColor() string {
+	if !TagColorPattern.MatchString(t.Color) {
+		return "#fff"
+	}
+	r, _ := strconv.ParseUint(t.Color[1:3], 16, 8)
+	g, _ := strconv.ParseUint(t.Color[3:5], 16, 8)
+	b, _ := strconv.ParseUint(t.Color[5:7], 16, 8)
+	// Perceived brightness, weighting green highest as the eye is most sensitive to it
+	if (299*r+587*g+114*b)/1000 > 150 {
+		return "#000"
+	}
+	return "#fff"
+}
+
+// TagModel wraps the generated SQLC Queries for tag operations
+type TagModel struct {
+	queries *db.Queries
+}
+
+// NewTagModel creates a new TagModel
+func NewTagModel(database *sql.DB) *TagModel {
+	return &TagModel{
+		queries: newQueries(database),
+	}
+}
+
+// NewTagModelWithTx creates a TagModel whose queries run inside the given transaction
+func NewTagModelWithTx(tx *sql.Tx) *TagModel {
+	return &TagModel{
+		queries: newQueries(tx),
+	}
+}
+
+// tagFromRow converts a tag row to a Tag
+func tagFromRow(row db.GetTagRow) Tag {
+	return Tag{
+		ID:      int(row.ID),
+		Name:    row.Name,
+		Color:   row.Color,
+		Updated: row.UpdatedAt,
+		Created: row.CreatedAt,
+	}
+}
+
+// Insert adds a new tag and returns its ID
+func (m *TagModel) Insert(tag Tag) (int, error) {
+	ctx := context.Background()
+	id, err := m.queries.InsertTag(ctx, db.InsertTagParams{
+		Name:  tag.Name,
+		Color: tag.Color,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// Get retrieves a tag by ID
+func (m *TagModel) Get(id int) (Tag, error) {
+	ctx := context.Background()
+	row, err := m.queries.GetTag(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Tag{}, ErrNoRecord
+		}
+		return Tag{}, err
+	}
+	return tagFromRow(row), nil
+}
+
+// GetAll retrieves all tags ordered by name
+func (m *TagModel) GetAll() ([]Tag, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetAllTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]Tag, len(rows))
+	for i, row := range rows {
+		tags[i] = tagFromRow(db.GetTagRow(row))
+	}
+	return tags, nil
+}
+
+// Update modifies an existing tag
+func (m *TagModel) Update(tag Tag) error {
+	ctx := context.Background()
+	return m.queries.UpdateTag(ctx, db.UpdateTagParams{
+		Name:  tag.Name,
+		Color: tag.Color,
+		ID:    int64(tag.ID),
+	})
+}
+
+// Delete removes a tag from every client and project carrying it, along with the checklist
+// items kept for projects of that type, and then deletes it.
+// Run it through TxManager so that the tag is never left half removed.
+func (m *TagModel) Delete(id int) error {
+	ctx := context.Background()
+	if err := m.queries.DeleteClientTagsByTag(ctx, int64(id)); err != nil {
+		return err
+	}
+	if err := m.queries.DeleteProjectTagsByTag(ctx, int64(id)); err != nil {
+		return err
+	}
+	tagID := sql.NullInt64{Int64: int64(id), Valid: true}
+	if err := m.queries.DeleteProjectChecksByTag(ctx, tagID); err != nil {
+		return err
+	}
+	if err := m.queries.DeleteChecklistItemsByTag(ctx, tagID); err != nil {
+		return err
+	}
+	return m.queries.DeleteTag(ctx, int64(id))
+}
+
+// SetClientTags replaces the tags on a client with the given ones, ignoring repeats
+func (m *TagModel) SetClientTags(clientID int, tagIDs []int) error {
+	ctx := context.Background()
+	if err := m.queries.DeleteClientTags(ctx, int64(clientID)); err != nil {
+		return err
+	}
+	seen := make(map[int]bool)
+	for _, tagID := range tagIDs {
+		if seen[tagID] {
+			continue
+		}
+		seen[tagID] = true
+		err := m.queries.InsertClientTag(ctx, db.InsertClientTagParams{
+			ClientID: int64(clientID),
+			TagID:    int64(tagID),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetProjectTags replaces the tags on a project with the given ones, ignoring repeats
+func (m *TagModel) SetProjectTags(projectID int, tagIDs []int) error {
+	ctx := context.Background()
+	if err := m.queries.DeleteProjectTags(ctx, int64(projectID)); err != nil {
+		return err
+	}
+	seen := make(map[int]bool)
+	for _, tagID := range tagIDs {
+		if seen[tagID] {
+			continue
+		}
+		seen[tagID] = true
+		err := m.queries.InsertProjectTag(ctx, db.InsertProjectTagParams{
+			ProjectID: int64(projectID),
+			TagID:     int64(tagID),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForClient retrieves the tags on a client ordered by name
+func (m *TagModel) ForClient(clientID int) ([]Tag, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetTagsForClient(ctx, int64(clientID))
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]Tag, len(rows))
+	for i, row := range rows {
+		tags[i] = tagFromRow(db.GetTagRow(row))
+	}
+	return tags, nil
+}
+
+// ForProject retrieves the tags on a project ordered by name
+func (m *TagModel) ForProject(projectID int) ([]Tag, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetTagsForProject(ctx, int64(projectID))
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]Tag, len(rows))
+	for i, row := range rows {
+		tags[i] = tagFromRow(db.GetTagRow(row))
+	}
+	return tags, nil
+}
+
+// ClientTagMap returns the tags on every client, keyed by client ID, for showing tag
+// chips on list pages
+func (m *TagModel) ClientTagMap() (map[int][]Tag, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetAllClientTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[int][]Tag)
+	for _, row := range rows {
+		tags[int(row.ClientID)] = append(tags[int(row.ClientID)], Tag{
+			ID:      int(row.ID),
+			Name:    row.Name,
+			Color:   row.Color,
+			Updated: row.UpdatedAt,
+			Created: row.CreatedAt,
+		})
+	}
+	return tags, nil
+}
+
+// ProjectTagMap returns the tags on every project, keyed by project ID, for showing tag
+// chips on list pages
+func (m *TagModel) ProjectTagMap() (map[int][]Tag, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetAllProjectTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[int][]Tag)
+	for _, row := range rows {
+		tags[int(row.ProjectID)] = append(tags[int(row.ProjectID)], Tag{
+			ID:      int(row.ID),
+			Name:    row.Name,
+			Color:   row.Color,
+			Updated: row.UpdatedAt,
+			Created: row.CreatedAt,
+		})
+	}
+	return tags, nil
+}
+
+// TagIDs returns the IDs of the given tags
+func TagIDs(tags []Tag) []int {
+	ids := make([]int, len(tags))
+	for i, tag := range tags {
+		ids[i] = tag.ID
+	}
+	return ids
+}
+
+// TagModelInterface defines the interface for tag operations
+type TagModelInterface interface {
+	Insert(tag Tag) (int, error)
+	Get(id int) (Tag, error)
+	GetAll() ([]Tag, error)
+	Update(tag Tag) error
+	Delete(id int) error
+	SetClientTags(clientID int, tagIDs []int) error
+	SetProjectTags(projectID int, tagIDs []int) error
+	ForClient(clientID int) ([]Tag, error)
+	ForProject(projectID int) ([]Tag, error)
+	ClientTagMap() (map[int][]Tag, error)
+	ProjectTagMap() (map[int][]Tag, error)
+}
+
+// Ensure implementation satisfies the interface
+var _ TagModelInterface = (*TagModel)(nil)
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewTagModel(testDB.DB)
+	clients := NewClientModel(testDB.DB)
+	projects := NewProjectModel(testDB.DB)
+	referredID := testDB.InsertTestClient(t, "Referred")
+	otherID := testDB.InsertTestClient(t, "Other")
+	projectID := testDB.InsertTestProject(t, "Thesis", referredID)
+	testDB.InsertTestProject(t, "Article", otherID)
+
+	referralID, err := model.Insert(Tag{Name: "Referral", Color: "#ffcc00"})
+	require.NoError(t, err)
+	editingID, err := model.Insert(Tag{Name: "Editing", Color: DefaultTagColor})
+	require.NoError(t, err)
+
+	t.Run("names are unique", func(t *testing.T) {
+		_, err := model.Insert(Tag{Name: "Referral", Color: "#000000"})
+		assert.True(t, errors.Is(err, ErrDuplicate))
+	})
+
+	t.Run("all tags are ordered by name", func(t *testing.T) {
+		tags, err := model.GetAll()
+		require.NoError(t, err)
+		assert.Equal(t, []int{editingID, referralID}, TagIDs(tags))
+	})
+
+	t.Run("update", func(t *testing.T) {
+		err := model.Update(Tag{ID: editingID, Name: "Copy editing", Color: "#112233"})
+		require.NoError(t, err)
+		tag, err := model.Get(editingID)
+		require.NoError(t, err)
+		assert.Equal(t, "Copy editing", tag.Name)
+		assert.Equal(t, "#112233", tag.Color)
+	})
+
+	t.Run("tags are set on clients and projects", func(t *testing.T) {
+		require.NoError(t, model.SetClientTags(referredID, []int{referralID, editingID, referralID}))
+		require.NoError(t, model.SetClientTags(referredID, []int{referralID}))
+		require.NoError(t, model.SetProjectTags(projectID, []int{editingID}))
+
+		tags, err := model.ForClient(referredID)
+		require.NoError(t, err)
+		assert.Equal(t, []int{referralID}, TagIDs(tags))
+
+		clientTags, err := model.ClientTagMap()
+		require.NoError(t, err)
+		assert.Len(t, clientTags[referredID], 1)
+		assert.Empty(t, clientTags[otherID])
+
+		projectTags, err := model.ProjectTagMap()
+		require.NoError(t, err)
+		assert.Equal(t, []int{editingID}, TagIDs(projectTags[projectID]))
+	})
+
+	t.Run("lists filter by tag", func(t *testing.T) {
+		tagged, err := clients.GetWithPaginationByTag(referralID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, tagged, 1)
+		assert.Equal(t, referredID, tagged[0].ID)
+		count, err := clients.GetCountByTag(referralID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		taggedProjects, err := projects.GetWithPaginationByTag(editingID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, taggedProjects, 1)
+		assert.Equal(t, "Referred", taggedProjects[0].ClientName)
+		count, err = projects.GetCountByTag(referralID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("delete removes the tag everywhere", func(t *testing.T) {
+		require.NoError(t, model.Delete(referralID))
+		_, err := model.Get(referralID)
+		assert.ErrorIs(t, err, ErrNoRecord)
+		tags, err := model.ForClient(referredID)
+		require.NoError(t, err)
+		assert.Empty(t, tags)
+	})
+}
+
+func TestTag_TextColor(t *testing.T) {
+	assert.Equal(t, "#000", Tag{Color: "#ffcc00"}.TextColor())
+	assert.Equal(t, "#fff", Tag{Color: "#112233"}.TextColor())
+	assert.Equal(t, "#fff", Tag{Color: "not a colour"}.TextColor())
+}
@@ -0,0 +1,200 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/markdown"
+	"github.com/paulboeck/FreelanceTrackerGo/ui"
+)
+
+// TimesheetReportEntry is a timesheet entry listed on a timesheet report
+type TimesheetReportEntry struct {
+	ID          int
+	ProjectID   int
+	ProjectName string
+	WorkDate    time.Time
+	HoursWorked float64
+	Description string
+	Unit        string
+	WorkType    string
+}
+
+// IsHourly reports whether the entry is time worked rather than a quantity of words or pages
+func (e TimesheetReportEntry) IsHourly() bool {
+	return Timesheet{Unit: e.Unit}.IsHourly()
+}
+
+// Quantity describes the amount logged with its unit, e.g. "2.50 hours" or "4000 words"
+func (e TimesheetReportEntry) Quantity() string {
+	return Timesheet{HoursWorked: e.HoursWorked, Unit: e.Unit}.Quantity()
+}
+
+// DescriptionLines returns the description's Markdown a line at a time
+func (e TimesheetReportEntry) DescriptionLines() []markdown.Line {
+	return markdown.Lines(e.Description)
+}
+
+// TimesheetReportWeek is the time logged in the week starting on Monday Start
+type TimesheetReportWeek struct {
+	Start   time.Time
+	Entries []TimesheetReportEntry
+	Hours   float64
+}
+
+// End returns the Sunday the week ends on
+func (w TimesheetReportWeek) End() time.Time {
+	return w.Start.AddDate(0, 0, 6)
+}
+
+// TimesheetReport lists the time logged on a project, or across a client's projects, between
+// two dates for the client to sign off. Hours only total entries billed by the hour. WorkTypes
+// totals the time by work type, and is empty when no entry has one.
+type TimesheetReport struct {
+	Title        string
+	ClientName   string
+	Start        time.Time
+	End          time.Time
+	ShowProjects bool
+	Weeks        []TimesheetReportWeek
+	Hours        float64
+	WorkTypes    []WorkTypeTotal
+}
+
+// NewTimesheetReport groups entries, ordered by work date, into the weeks they fall in.
+// Weeks without any entries are left out.
+func NewTimesheetReport(title, clientName string, start, end time.Time, entries []TimesheetReportEntry) TimesheetReport {
+	report := TimesheetReport{
+		Title:      title,
+		ClientName: clientName,
+		Start:      start,
+		End:        end,
+	}
+	for _, entry := range entries {
+		week := weekStart(entry.WorkDate)
+		if len(report.Weeks) == 0 || !report.Weeks[len(report.Weeks)-1].Start.Equal(week) {
+			report.Weeks = append(report.Weeks, TimesheetReportWeek{Start: week})
+		}
+		current := &report.Weeks[len(report.Weeks)-1]
+		current.Entries = append(current.Entries, entry)
+		if entry.IsHourly() {
+			current.Hours += entry.HoursWorked
+			report.Hours += entry.HoursWorked
+		}
+	}
+	report.WorkTypes = workTypeTotals(entries)
+	return report
+}
+
+// workTypeTotals totals entries by work type, in the order each type first appears with
+// entries that have no work type last. It returns nil when no entry has a work type.
+func workTypeTotals(entries []TimesheetReportEntry) []WorkTypeTotal {
+	var totals []WorkTypeTotal
+	index := map[string]int{}
+	typed := false
+	for _, entry := range entries {
+		if entry.WorkType != "" {
+			typed = true
+		}
+		i, ok := index[entry.WorkType]
+		if !ok {
+			i = len(totals)
+			index[entry.WorkType] = i
+			totals = append(totals, WorkTypeTotal{WorkType: entry.WorkType})
+		}
+		totals[i].Entries++
+		if entry.IsHourly() {
+			totals[i].Hours += entry.HoursWorked
+		}
+	}
+	if !typed {
+		return nil
+	}
+	if i, ok := index[""]; ok {
+		untyped := totals[i]
+		totals = append(append(totals[:i:i], totals[i+1:]...), untyped)
+	}
+	return totals
+}
+
+// TimesheetReportTemplateData is a timesheet report with the settings used to render it
+type TimesheetReportTemplateData struct {
+	TimesheetReport
+	FreelancerName string
+	DateFormat     DateFormat
+}
+
+// RenderTimesheetReportHTML renders the embedded timesheet report template, naming the
+// freelancer and formatting dates as the settings say
+func RenderTimesheetReportHTML(report TimesheetReport, settings map[string]AppSettingValue) ([]byte, error) {
+	data := TimesheetReportTemplateData{
+		TimesheetReport: report,
+		FreelancerName:  "Your Name Here",
+		DateFormat:      DateFormatISO,
+	}
+	if setting, exists := settings["freelancer_name"]; exists {
+		data.FreelancerName = setting.AsString()
+	}
+	if setting, exists := settings["date_format"]; exists {
+		data.DateFormat = ParseDateFormat(setting.AsString())
+	}
+
+	templateBytes, err := ui.Files.ReadFile("html/timesheet_report.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template file: %w", err)
+	}
+
+	tmpl, err := template.New("timesheet_report").Parse(string(templateBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var htmlBuffer bytes.Buffer
+	err = tmpl.Execute(&htmlBuffer, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	return htmlBuffer.Bytes(), nil
+}
+
+// GetProjectReport retrieves the approved entries logged on a project between two dates,
+// both included, for a timesheet report
+func (t *TimesheetModel) GetProjectReport(projectID int, start, end time.Time) ([]TimesheetReportEntry, error) {
+	return t.getReport(db.GetTimesheetsForReportParams{ProjectID: int64(projectID)}, start, end)
+}
+
+// GetClientReport retrieves the approved entries logged across all of a client's projects
+// between two dates, both included, for a timesheet report
+func (t *TimesheetModel) GetClientReport(clientID int, start, end time.Time) ([]TimesheetReportEntry, error) {
+	return t.getReport(db.GetTimesheetsForReportParams{ClientID: int64(clientID)}, start, end)
+}
+
+// getReport runs the timesheet report query for a project or client over the given dates
+func (t *TimesheetModel) getReport(params db.GetTimesheetsForReportParams, start, end time.Time) ([]TimesheetReportEntry, error) {
+	ctx := context.Background()
+	params.StartDate = start.Format("2006-01-02")
+	params.EndDate = end.Format("2006-01-02")
+	rows, err := t.queries.GetTimesheetsForReport(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]TimesheetReportEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = TimesheetReportEntry{
+			ID:          int(row.ID),
+			ProjectID:   int(row.ProjectID),
+			ProjectName: row.ProjectName,
+			WorkDate:    row.WorkDate,
+			HoursWorked: row.HoursWorked,
+			Description: row.Description.String,
+			Unit:        row.Unit,
+			WorkType:    row.WorkType,
+		}
+	}
+	return entries, nil
+}
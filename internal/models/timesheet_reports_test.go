@@ -0,0 +1,119 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimesheetModel_Reports(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewTimesheetModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Report Client")
+	otherClientID := testDB.InsertTestClient(t, "Other Client")
+	projectID := testDB.InsertTestProject(t, "First Project", clientID)
+	secondProjectID := testDB.InsertTestProject(t, "Second Project", clientID)
+	otherProjectID := testDB.InsertTestProject(t, "Other Project", otherClientID)
+
+	testDB.InsertTestTimesheet(t, projectID, "2024-02-29", "1.00", "100.00", "Before the period")
+	firstID := testDB.InsertTestTimesheet(t, projectID, "2024-03-01", "2.00", "100.00", "First day")
+	secondID := testDB.InsertTestTimesheet(t, secondProjectID, "2024-03-12", "3.50", "100.00", "Second project")
+	lastID := testDB.InsertTestTimesheet(t, projectID, "2024-03-31", "1.50", "100.00", "Last day")
+	testDB.InsertTestTimesheet(t, projectID, "2024-04-01", "4.00", "100.00", "After the period")
+	testDB.InsertTestTimesheet(t, otherProjectID, "2024-03-15", "8.00", "100.00", "Another client")
+
+	pendingID := testDB.InsertTestTimesheet(t, projectID, "2024-03-05", "5.00", "100.00", "Waiting for approval")
+	require.NoError(t, model.SetPendingApproval(pendingID, true))
+	deletedID := testDB.InsertTestTimesheet(t, projectID, "2024-03-06", "6.00", "100.00", "Deleted entry")
+	require.NoError(t, model.Delete(deletedID))
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	t.Run("project report lists approved entries in the period", func(t *testing.T) {
+		entries, err := model.GetProjectReport(projectID, start, end)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, firstID, entries[0].ID)
+		assert.Equal(t, "First Project", entries[0].ProjectName)
+		assert.Equal(t, lastID, entries[1].ID)
+	})
+
+	t.Run("client report covers all of the client's projects", func(t *testing.T) {
+		entries, err := model.GetClientReport(clientID, start, end)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		assert.Equal(t, firstID, entries[0].ID)
+		assert.Equal(t, secondID, entries[1].ID)
+		assert.Equal(t, "Second Project", entries[1].ProjectName)
+		assert.Equal(t, lastID, entries[2].ID)
+	})
+}
+
+func TestNewTimesheetReport(t *testing.T) {
+	date := func(day int) time.Time { return time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC) }
+	entries := []TimesheetReportEntry{
+		{ID: 1, WorkDate: date(1), HoursWorked: 2},   // Friday
+		{ID: 2, WorkDate: date(3), HoursWorked: 1},   // Sunday, same week
+		{ID: 3, WorkDate: date(4), HoursWorked: 1.5}, // Monday
+		{ID: 4, WorkDate: date(5), HoursWorked: 4000, Unit: UnitWord},
+		{ID: 5, WorkDate: date(20), HoursWorked: 3},
+	}
+
+	report := NewTimesheetReport("Project", "Client", date(1), date(31), entries)
+
+	require.Len(t, report.Weeks, 3)
+	assert.Equal(t, time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC), report.Weeks[0].Start)
+	assert.Equal(t, date(3), report.Weeks[0].End())
+	assert.Len(t, report.Weeks[0].Entries, 2)
+	assert.Equal(t, 3.0, report.Weeks[0].Hours)
+
+	assert.Equal(t, date(4), report.Weeks[1].Start)
+	assert.Len(t, report.Weeks[1].Entries, 2)
+	assert.Equal(t, 1.5, report.Weeks[1].Hours, "words aren't counted as hours")
+
+	assert.Equal(t, date(18), report.Weeks[2].Start)
+	assert.Equal(t, 7.5, report.Hours)
+	assert.Nil(t, report.WorkTypes, "no entry has a work type")
+
+	entries[0].WorkType = "Meeting"
+	entries[2].WorkType = "Editing"
+	entries[3].WorkType = "Editing"
+	entries[4].WorkType = "Meeting"
+	report = NewTimesheetReport("Project", "Client", date(1), date(31), entries)
+	assert.Equal(t, []WorkTypeTotal{
+		{WorkType: "Meeting", Entries: 2, Hours: 5},
+		{WorkType: "Editing", Entries: 2, Hours: 1.5},
+		{WorkType: "", Entries: 1, Hours: 1},
+	}, report.WorkTypes)
+}
+
+func TestRenderTimesheetReportHTML(t *testing.T) {
+	entries := []TimesheetReportEntry{
+		{ProjectName: "Thesis", WorkDate: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), HoursWorked: 2, Description: "Chapter one", WorkType: "Editing"},
+	}
+	report := NewTimesheetReport("Report Client", "Report Client", entries[0].WorkDate, entries[0].WorkDate, entries)
+	report.ShowProjects = true
+	settings := map[string]AppSettingValue{
+		"freelancer_name": {Value: "Jane Editor", DataType: "string"},
+		"date_format":     {Value: string(DateFormatEuropean), DataType: "string"},
+	}
+
+	html, err := RenderTimesheetReportHTML(report, settings)
+	require.NoError(t, err)
+
+	body := string(html)
+	assert.Contains(t, body, "Jane Editor")
+	assert.Contains(t, body, "04.03.2024")
+	assert.Contains(t, body, "Thesis")
+	assert.Contains(t, body, "Chapter one")
+	assert.Contains(t, body, "Total: 2.00 hours")
+	assert.Contains(t, body, "Time by work type")
+	assert.Contains(t, body, "Approved for Report Client")
+}
@@ -4,22 +4,55 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"html/template"
+	"strconv"
 	"time"
 
 	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+	"github.com/paulboeck/FreelanceTrackerGo/internal/markdown"
 )
 
-// Timesheet represents a timesheet in the system
+// Timesheet represents a timesheet in the system. Entries for a service billed per word or
+// page hold the quantity in HoursWorked and the rate per unit in HourlyRate. Entries pending
+// approval aren't invoiced until an owner approves them.
 type Timesheet struct {
+	ID              int
+	ProjectID       int
+	WorkDate        time.Time
+	HoursWorked     float64
+	HourlyRate      float64
+	CostRate        *float64
+	Description     string
+	UserID          *int
+	ServiceID       *int
+	ServiceName     string
+	Unit            string
+	WorkType        string
+	PendingApproval bool
+	Updated         time.Time
+	Created         time.Time
+	DeletedAt       *time.Time
+}
+
+// TimesheetApproval is a timesheet entry waiting for approval, with who logged it and on
+// which project
+type TimesheetApproval struct {
 	ID          int
 	ProjectID   int
+	ProjectName string
 	WorkDate    time.Time
 	HoursWorked float64
-	HourlyRate  float64
 	Description string
-	Updated     time.Time
+	Unit        string
+	UserID      *int
+	UserName    string
 	Created     time.Time
-	DeletedAt   *time.Time
+}
+
+// Quantity describes the amount logged with its unit, e.g. "2.50 hours" or "4000 words"
+func (a TimesheetApproval) Quantity() string {
+	return Timesheet{HoursWorked: a.HoursWorked, Unit: a.Unit}.Quantity()
 }
 
 // TimesheetModel wraps the generated SQLC Queries for timesheet operations
@@ -30,7 +63,14 @@ type TimesheetModel struct {
 // NewTimesheetModel creates a new TimesheetModel
 func NewTimesheetModel(database *sql.DB) *TimesheetModel {
 	return &TimesheetModel{
-		queries: db.New(database),
+		queries: newQueries(database),
+	}
+}
+
+// NewTimesheetModelWithTx creates a TimesheetModel whose queries run inside the given transaction
+func NewTimesheetModelWithTx(tx *sql.Tx) *TimesheetModel {
+	return &TimesheetModel{
+		queries: newQueries(tx),
 	}
 }
 
@@ -70,15 +110,22 @@ func (t *TimesheetModel) Get(id int) (Timesheet, error) {
 	}
 
 	timesheet := Timesheet{
-		ID:          int(row.ID),
-		ProjectID:   int(row.ProjectID),
-		WorkDate:    row.WorkDate,
-		HoursWorked: row.HoursWorked,
-		HourlyRate:  row.HourlyRate,
-		Description: row.Description.String,
-		Updated:     row.UpdatedAt,
-		Created:     row.CreatedAt,
-		DeletedAt:   deletedAt,
+		ID:              int(row.ID),
+		ProjectID:       int(row.ProjectID),
+		WorkDate:        row.WorkDate,
+		HoursWorked:     row.HoursWorked,
+		HourlyRate:      row.HourlyRate,
+		CostRate:        convertNullFloat64(row.CostRate),
+		Description:     row.Description.String,
+		UserID:          convertNullInt64(row.UserID),
+		ServiceID:       convertNullInt64(row.ServiceID),
+		ServiceName:     row.ServiceName.String,
+		Unit:            row.Unit,
+		WorkType:        row.WorkType,
+		PendingApproval: row.PendingApproval,
+		Updated:         row.UpdatedAt,
+		Created:         row.CreatedAt,
+		DeletedAt:       deletedAt,
 	}
 
 	return timesheet, nil
@@ -94,29 +141,153 @@ func (t *TimesheetModel) GetByProject(projectID int) ([]Timesheet, error) {
 
 	timesheets := make([]Timesheet, len(rows))
 	for i, row := range rows {
-		var deletedAt *time.Time
-		if row.DeletedAt != nil {
-			if dt, ok := row.DeletedAt.(time.Time); ok {
-				deletedAt = &dt
-			}
-		}
+		timesheets[i] = timesheetFromProjectRow(row)
+	}
 
-		timesheets[i] = Timesheet{
-			ID:          int(row.ID),
-			ProjectID:   int(row.ProjectID),
-			WorkDate:    row.WorkDate,
-			HoursWorked: row.HoursWorked,
-			HourlyRate:  row.HourlyRate,
-			Description: row.Description.String,
-			Updated:     row.UpdatedAt,
-			Created:     row.CreatedAt,
-			DeletedAt:   deletedAt,
-		}
+	return timesheets, nil
+}
+
+// GetByProjectWithPagination retrieves a page of a project's timesheets, most recent first
+func (t *TimesheetModel) GetByProjectWithPagination(projectID int, limit, offset int64) ([]Timesheet, error) {
+	ctx := context.Background()
+	rows, err := t.queries.GetTimesheetsByProjectWithPagination(ctx, db.GetTimesheetsByProjectWithPaginationParams{
+		ProjectID: int64(projectID),
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	timesheets := make([]Timesheet, len(rows))
+	for i, row := range rows {
+		timesheets[i] = timesheetFromProjectRow(db.GetTimesheetsByProjectRow(row))
 	}
 
 	return timesheets, nil
 }
 
+// TimesheetTotals is how many timesheet entries a project has and the margin on all of them
+type TimesheetTotals struct {
+	Entries int64
+	Margin
+}
+
+// GetTotalsByProject counts a project's timesheets and totals them the way TimesheetMargin
+// does, without loading every entry
+func (t *TimesheetModel) GetTotalsByProject(projectID int) (TimesheetTotals, error) {
+	ctx := context.Background()
+	row, err := t.queries.GetTimesheetTotalsByProject(ctx, int64(projectID))
+	if err != nil {
+		return TimesheetTotals{}, err
+	}
+	return TimesheetTotals{
+		Entries: row.Entries,
+		Margin:  Margin{Hours: row.Hours, Billed: row.Billed, Cost: row.Cost},
+	}, nil
+}
+
+// timesheetFromProjectRow converts a row of a project's timesheets
+func timesheetFromProjectRow(row db.GetTimesheetsByProjectRow) Timesheet {
+	var deletedAt *time.Time
+	if row.DeletedAt != nil {
+		if dt, ok := row.DeletedAt.(time.Time); ok {
+			deletedAt = &dt
+		}
+	}
+
+	return Timesheet{
+		ID:              int(row.ID),
+		ProjectID:       int(row.ProjectID),
+		WorkDate:        row.WorkDate,
+		HoursWorked:     row.HoursWorked,
+		HourlyRate:      row.HourlyRate,
+		CostRate:        convertNullFloat64(row.CostRate),
+		Description:     row.Description.String,
+		UserID:          convertNullInt64(row.UserID),
+		ServiceID:       convertNullInt64(row.ServiceID),
+		ServiceName:     row.ServiceName.String,
+		Unit:            row.Unit,
+		WorkType:        row.WorkType,
+		PendingApproval: row.PendingApproval,
+		Updated:         row.UpdatedAt,
+		Created:         row.CreatedAt,
+		DeletedAt:       deletedAt,
+	}
+}
+
+// Amount returns what the entry is billed at
+func (t Timesheet) Amount() float64 {
+	return t.HoursWorked * t.HourlyRate
+}
+
+// IsHourly reports whether the entry is time worked rather than a quantity of words or pages
+func (t Timesheet) IsHourly() bool {
+	return t.Unit == "" || t.Unit == UnitHour
+}
+
+// Quantity describes the amount billed with its unit, e.g. "2.50 hours" or "4000 words"
+func (t Timesheet) Quantity() string {
+	if t.IsHourly() {
+		return fmt.Sprintf("%.2f hours", t.HoursWorked)
+	}
+	quantity := strconv.FormatFloat(t.HoursWorked, 'f', -1, 64)
+	if t.HoursWorked == 1 {
+		return quantity + " " + t.Unit
+	}
+	return quantity + " " + t.Unit + "s"
+}
+
+// Rate returns the rate formatted for display
+func (t Timesheet) Rate() string {
+	return FormatRate(t.HourlyRate)
+}
+
+// RateUnit returns what the rate is charged per, for display as e.g. "$0.02/word"
+func (t Timesheet) RateUnit() string {
+	if t.IsHourly() {
+		return "hr"
+	}
+	return t.Unit
+}
+
+// DescriptionHTML returns the description's Markdown formatted for a web page
+func (t Timesheet) DescriptionHTML() template.HTML {
+	return markdown.HTML(t.Description)
+}
+
+// DescriptionLines returns the description's Markdown a line at a time, for the invoice
+// to lay out each list item on its own line
+func (t Timesheet) DescriptionLines() []markdown.Line {
+	return markdown.Lines(t.Description)
+}
+
+// Cost returns the internal cost of the entry, which is zero when no cost rate was recorded
+func (t Timesheet) Cost() float64 {
+	if t.CostRate == nil {
+		return 0
+	}
+	return t.HoursWorked * *t.CostRate
+}
+
+// IsTemplate reports whether the entry is a template row: a task described on a project with
+// no time or quantity logged against it, kept so that a clone of the project starts with the
+// same task breakdown
+func (t Timesheet) IsTemplate() bool {
+	return t.HoursWorked == 0
+}
+
+// TemplateTimesheets returns the template rows among a project's timesheets
+func TemplateTimesheets(timesheets []Timesheet) []Timesheet {
+	var templates []Timesheet
+	for _, timesheet := range timesheets {
+		if timesheet.IsTemplate() {
+			templates = append(templates, timesheet)
+		}
+	}
+	return templates
+}
+
 // Update modifies an existing timesheet in the database
 func (t *TimesheetModel) Update(id int, workDate time.Time, hoursWorked float64, hourlyRate float64, description string) error {
 	ctx := context.Background()
@@ -130,18 +301,151 @@ func (t *TimesheetModel) Update(id int, workDate time.Time, hoursWorked float64,
 	return t.queries.UpdateTimesheet(ctx, params)
 }
 
+// SetUser records which user logged a timesheet entry
+func (t *TimesheetModel) SetUser(id, userID int) error {
+	ctx := context.Background()
+	return t.queries.SetTimesheetUser(ctx, db.SetTimesheetUserParams{
+		UserID: sql.NullInt64{Int64: int64(userID), Valid: true},
+		ID:     int64(id),
+	})
+}
+
+// SetCostRate records the internal cost rate of a timesheet entry, or clears it when nil
+func (t *TimesheetModel) SetCostRate(id int, costRate *float64) error {
+	ctx := context.Background()
+	return t.queries.SetTimesheetCostRate(ctx, db.SetTimesheetCostRateParams{
+		CostRate: convertFloatPtr(costRate),
+		ID:       int64(id),
+	})
+}
+
+// SetService records the service an entry is for and the unit it is billed in. A nil service
+// makes it an ordinary hourly entry.
+func (t *TimesheetModel) SetService(id int, serviceID *int, unit string) error {
+	ctx := context.Background()
+	if serviceID == nil || unit == "" {
+		unit = UnitHour
+	}
+	return t.queries.SetTimesheetService(ctx, db.SetTimesheetServiceParams{
+		ServiceID: convertIntPtr(serviceID),
+		Unit:      unit,
+		ID:        int64(id),
+	})
+}
+
+// SetWorkType records the kind of work an entry is, or that it has none when workType is blank
+func (t *TimesheetModel) SetWorkType(id int, workType string) error {
+	ctx := context.Background()
+	return t.queries.SetTimesheetWorkType(ctx, db.SetTimesheetWorkTypeParams{
+		WorkType: workType,
+		ID:       int64(id),
+	})
+}
+
+// SetPendingApproval marks a timesheet entry as waiting for approval, or approves it
+func (t *TimesheetModel) SetPendingApproval(id int, pending bool) error {
+	ctx := context.Background()
+	return t.queries.SetTimesheetPendingApproval(ctx, db.SetTimesheetPendingApprovalParams{
+		PendingApproval: pending,
+		ID:              int64(id),
+	})
+}
+
+// GetPendingApproval retrieves every entry waiting for approval, oldest work first
+func (t *TimesheetModel) GetPendingApproval() ([]TimesheetApproval, error) {
+	ctx := context.Background()
+	rows, err := t.queries.GetTimesheetsPendingApproval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	approvals := make([]TimesheetApproval, len(rows))
+	for i, row := range rows {
+		approvals[i] = TimesheetApproval{
+			ID:          int(row.ID),
+			ProjectID:   int(row.ProjectID),
+			ProjectName: row.ProjectName,
+			WorkDate:    row.WorkDate,
+			HoursWorked: row.HoursWorked,
+			Description: row.Description.String,
+			Unit:        row.Unit,
+			UserID:      convertNullInt64(row.UserID),
+			UserName:    row.UserName.String,
+			Created:     row.CreatedAt,
+		}
+	}
+	return approvals, nil
+}
+
 // Delete soft deletes a timesheet by setting the deleted_at timestamp
 func (t *TimesheetModel) Delete(id int) error {
 	ctx := context.Background()
 	return t.queries.DeleteTimesheet(ctx, int64(id))
 }
 
+// GetLockingInvoices returns the IDs of the invoices that bill a timesheet entry, which keep
+// it from being changed: invoices made after the entry was logged whose timesheet range takes
+// in its work date, leaving out voided ones and milestone invoices
+func (t *TimesheetModel) GetLockingInvoices(id int) ([]int, error) {
+	ctx := context.Background()
+	rows, err := t.queries.GetTimesheetLockingInvoices(ctx, int64(id))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(rows))
+	for i, row := range rows {
+		ids[i] = int(row)
+	}
+	return ids, nil
+}
+
+// GetLockedIDs returns the set of a project's timesheet entries billed on an invoice
+func (t *TimesheetModel) GetLockedIDs(projectID int) (map[int]bool, error) {
+	ctx := context.Background()
+	rows, err := t.queries.GetLockedTimesheetIDs(ctx, int64(projectID))
+	if err != nil {
+		return nil, err
+	}
+
+	locked := make(map[int]bool, len(rows))
+	for _, row := range rows {
+		locked[int(row)] = true
+	}
+	return locked, nil
+}
+
+// GetClientHoursForMonth returns the hours, excluding entries billed per word or page, logged across all of a client's projects in the
+// calendar month containing month
+func (t *TimesheetModel) GetClientHoursForMonth(clientID int, month time.Time) (float64, error) {
+	ctx := context.Background()
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return t.queries.GetClientHoursBetween(ctx, db.GetClientHoursBetweenParams{
+		ClientID:  int64(clientID),
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   start.AddDate(0, 1, 0).Format("2006-01-02"),
+	})
+}
+
 // TimesheetModelInterface defines the interface for timesheet operations
 type TimesheetModelInterface interface {
 	Insert(projectID int, workDate time.Time, hoursWorked float64, hourlyRate float64, description string) (int, error)
 	Get(id int) (Timesheet, error)
 	GetByProject(projectID int) ([]Timesheet, error)
+	GetByProjectWithPagination(projectID int, limit, offset int64) ([]Timesheet, error)
+	GetTotalsByProject(projectID int) (TimesheetTotals, error)
 	Update(id int, workDate time.Time, hoursWorked float64, hourlyRate float64, description string) error
+	SetUser(id, userID int) error
+	SetCostRate(id int, costRate *float64) error
+	SetService(id int, serviceID *int, unit string) error
+	SetWorkType(id int, workType string) error
+	SetPendingApproval(id int, pending bool) error
+	GetPendingApproval() ([]TimesheetApproval, error)
+	GetClientHoursForMonth(clientID int, month time.Time) (float64, error)
+	GetProjectReport(projectID int, start, end time.Time) ([]TimesheetReportEntry, error)
+	GetClientReport(clientID int, start, end time.Time) ([]TimesheetReportEntry, error)
+	GetLockingInvoices(id int) ([]int, error)
+	GetLockedIDs(projectID int) (map[int]bool, error)
 	Delete(id int) error
 }
 
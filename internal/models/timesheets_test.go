@@ -1,9 +1,12 @@
 package models
 
 import (
+	"fmt"
+	"html/template"
 	"testing"
 	"time"
 
+	"github.com/paulboeck/FreelanceTrackerGo/internal/markdown"
 	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -241,6 +244,72 @@ func TestTimesheetModel_GetByProject(t *testing.T) {
 	})
 }
 
+func TestTimesheetModel_GetByProjectWithPagination(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewTimesheetModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Test Client")
+	projectID := testDB.InsertTestProject(t, "Long Project", clientID)
+	otherProjectID := testDB.InsertTestProject(t, "Other Project", clientID)
+	for day := 1; day <= 5; day++ {
+		testDB.InsertTestTimesheet(t, projectID, fmt.Sprintf("2024-01-%02d", day), "2.00", "100.00", fmt.Sprintf("Day %d", day))
+	}
+	wordsID := testDB.InsertTestTimesheet(t, projectID, "2024-01-06", "1000", "0.05", "Translation")
+	serviceID, err := NewServiceModel(testDB.DB).Insert(Service{Name: "Translation", DefaultRate: 0.05, Unit: UnitWord})
+	require.NoError(t, err)
+	require.NoError(t, model.SetService(wordsID, &serviceID, UnitWord))
+	costRate := 40.0
+	require.NoError(t, model.SetCostRate(wordsID-1, &costRate))
+	testDB.InsertTestTimesheet(t, otherProjectID, "2024-01-03", "9.00", "100.00", "Elsewhere")
+
+	t.Run("pages run from the most recent entry", func(t *testing.T) {
+		first, err := model.GetByProjectWithPagination(projectID, 4, 0)
+		require.NoError(t, err)
+		require.Len(t, first, 4)
+		assert.Equal(t, "Translation", first[0].Description)
+		assert.Equal(t, "Day 3", first[3].Description)
+
+		second, err := model.GetByProjectWithPagination(projectID, 4, 4)
+		require.NoError(t, err)
+		require.Len(t, second, 2)
+		assert.Equal(t, "Day 2", second[0].Description)
+		assert.Equal(t, "Day 1", second[1].Description)
+
+		past, err := model.GetByProjectWithPagination(projectID, 4, 8)
+		require.NoError(t, err)
+		assert.Empty(t, past)
+	})
+
+	t.Run("totals cover every entry", func(t *testing.T) {
+		all, err := model.GetByProject(projectID)
+		require.NoError(t, err)
+
+		totals, err := model.GetTotalsByProject(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), totals.Entries)
+		assert.Equal(t, TimesheetMargin(all), totals.Margin)
+		assert.Equal(t, 10.0, totals.Hours, "words aren't counted as hours")
+		assert.Equal(t, 80.0, totals.Cost)
+
+		totals, err = model.GetTotalsByProject(999)
+		require.NoError(t, err)
+		assert.Equal(t, TimesheetTotals{}, totals)
+	})
+}
+
+func TestTimesheet_Description(t *testing.T) {
+	timesheet := Timesheet{Description: "Edited *methods*\n1. tables\n2. figures <b>"}
+
+	assert.Equal(t, template.HTML("<p>Edited <em>methods</em></p><ol><li>tables</li><li>figures &lt;b&gt;</li></ol>"), timesheet.DescriptionHTML())
+
+	lines := timesheet.DescriptionLines()
+	require.Len(t, lines, 3)
+	assert.Equal(t, markdown.Numbered, lines[2].Kind)
+	assert.Equal(t, 2, lines[2].Number)
+}
+
 func TestTimesheetModel_Update(t *testing.T) {
 	// Setup test database
 	testDB := testutil.SetupTestSQLite(t)
@@ -421,6 +490,219 @@ func TestTimesheetModel_Delete(t *testing.T) {
 	})
 }
 
+func TestTimesheetModel_GetClientHoursForMonth(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewTimesheetModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Allowance Client")
+	otherClientID := testDB.InsertTestClient(t, "Other Client")
+	projectID := testDB.InsertTestProject(t, "First Project", clientID)
+	secondProjectID := testDB.InsertTestProject(t, "Second Project", clientID)
+	otherProjectID := testDB.InsertTestProject(t, "Other Project", otherClientID)
+
+	// Both boundary days of March count, the days either side don't
+	testDB.InsertTestTimesheet(t, projectID, "2024-02-29", "1.00", "100.00", "Last day of February")
+	testDB.InsertTestTimesheet(t, projectID, "2024-03-01", "2.00", "100.00", "First day of March")
+	_, err := model.Insert(secondProjectID, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), 3.5, 100.00, "Last day of March")
+	require.NoError(t, err)
+	testDB.InsertTestTimesheet(t, projectID, "2024-04-01", "4.00", "100.00", "First day of April")
+	testDB.InsertTestTimesheet(t, otherProjectID, "2024-03-15", "8.00", "100.00", "Another client")
+
+	deletedID := testDB.InsertTestTimesheet(t, projectID, "2024-03-10", "5.00", "100.00", "Deleted entry")
+	require.NoError(t, model.Delete(deletedID))
+
+	hours, err := model.GetClientHoursForMonth(clientID, time.Date(2024, 3, 17, 15, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 5.5, hours)
+
+	hours, err = model.GetClientHoursForMonth(clientID, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, hours)
+}
+
+func TestTimesheetModel_SetWorkType(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewTimesheetModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Test Client")
+	projectID := testDB.InsertTestProject(t, "Test Project", clientID)
+	id := testDB.InsertTestTimesheet(t, projectID, "2024-03-04", "2.00", "100.00", "Chapter 1")
+
+	timesheet, err := model.Get(id)
+	require.NoError(t, err)
+	assert.Empty(t, timesheet.WorkType, "entries start without a work type")
+
+	require.NoError(t, model.SetWorkType(id, "Editing"))
+	timesheet, err = model.Get(id)
+	require.NoError(t, err)
+	assert.Equal(t, "Editing", timesheet.WorkType)
+
+	timesheets, err := model.GetByProject(projectID)
+	require.NoError(t, err)
+	require.Len(t, timesheets, 1)
+	assert.Equal(t, "Editing", timesheets[0].WorkType)
+
+	entries, err := model.GetProjectReport(projectID, timesheet.WorkDate, timesheet.WorkDate)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Editing", entries[0].WorkType)
+
+	require.NoError(t, model.SetWorkType(id, ""))
+	timesheet, err = model.Get(id)
+	require.NoError(t, err)
+	assert.Empty(t, timesheet.WorkType)
+}
+
+func TestTimesheetModel_PendingApproval(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewTimesheetModel(testDB.DB)
+	users := NewUserModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Approval Client")
+	projectID := testDB.InsertTestProject(t, "Approval Project", clientID)
+	deletedProjectID := testDB.InsertTestProject(t, "Deleted Project", clientID)
+	userID, err := users.Insert(User{Name: "Sub Contractor", Email: "sub@example.com", Role: RoleSubcontractor}, "correct horse")
+	require.NoError(t, err)
+
+	laterID := testDB.InsertTestTimesheet(t, projectID, "2024-03-05", "2.00", "50.00", "Later work")
+	earlierID := testDB.InsertTestTimesheet(t, projectID, "2024-03-01", "1.50", "50.00", "Earlier work")
+	approvedID := testDB.InsertTestTimesheet(t, projectID, "2024-03-02", "3.00", "50.00", "Owner work")
+	orphanID := testDB.InsertTestTimesheet(t, deletedProjectID, "2024-03-03", "1.00", "50.00", "Deleted project")
+	require.NoError(t, model.SetUser(laterID, userID))
+	for _, id := range []int{laterID, earlierID, orphanID} {
+		require.NoError(t, model.SetPendingApproval(id, true))
+	}
+	_, err = testDB.DB.Exec("UPDATE project SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?", deletedProjectID)
+	require.NoError(t, err)
+
+	t.Run("new entries are approved", func(t *testing.T) {
+		timesheet, err := model.Get(approvedID)
+		require.NoError(t, err)
+		assert.False(t, timesheet.PendingApproval)
+	})
+
+	t.Run("entries waiting for approval are listed oldest first", func(t *testing.T) {
+		approvals, err := model.GetPendingApproval()
+		require.NoError(t, err)
+		require.Len(t, approvals, 2)
+		assert.Equal(t, earlierID, approvals[0].ID)
+		assert.Empty(t, approvals[0].UserName)
+		assert.Equal(t, laterID, approvals[1].ID)
+		assert.Equal(t, "Approval Project", approvals[1].ProjectName)
+		assert.Equal(t, "Sub Contractor", approvals[1].UserName)
+		assert.Equal(t, "2.00 hours", approvals[1].Quantity())
+	})
+
+	t.Run("approving an entry takes it off the list", func(t *testing.T) {
+		require.NoError(t, model.SetPendingApproval(earlierID, false))
+
+		timesheet, err := model.Get(earlierID)
+		require.NoError(t, err)
+		assert.False(t, timesheet.PendingApproval)
+
+		approvals, err := model.GetPendingApproval()
+		require.NoError(t, err)
+		require.Len(t, approvals, 1)
+		assert.Equal(t, laterID, approvals[0].ID)
+	})
+}
+
+func TestTimesheetModel_Locks(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewTimesheetModel(testDB.DB)
+	invoices := NewInvoiceModel(testDB.DB)
+
+	clientID := testDB.InsertTestClient(t, "Lock Client")
+	projectID := testDB.InsertTestProject(t, "Lock Project", clientID)
+	otherProjectID := testDB.InsertTestProject(t, "Other Project", clientID)
+	marchID := testDB.InsertTestTimesheet(t, projectID, "2024-03-10", "2.00", "50.00", "March work")
+	aprilID := testDB.InsertTestTimesheet(t, projectID, "2024-04-05", "1.00", "50.00", "April work")
+	otherID := testDB.InsertTestTimesheet(t, otherProjectID, "2024-03-12", "1.00", "50.00", "Other work")
+
+	rangedID, err := invoices.Insert(projectID, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), nil, "Net 30", 100.00, true)
+	require.NoError(t, err)
+	from, to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, invoices.SetTimesheetRange(rangedID, &from, &to))
+
+	t.Run("entries in an invoice's range are locked", func(t *testing.T) {
+		locking, err := model.GetLockingInvoices(marchID)
+		require.NoError(t, err)
+		assert.Equal(t, []int{rangedID}, locking)
+
+		locking, err = model.GetLockingInvoices(aprilID)
+		require.NoError(t, err)
+		assert.Empty(t, locking)
+
+		locked, err := model.GetLockedIDs(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]bool{marchID: true}, locked)
+	})
+
+	t.Run("consolidated invoices lock the entries of every project they cover", func(t *testing.T) {
+		_, err := testDB.DB.Exec("INSERT INTO invoice_project (invoice_id, project_id, hours, amount) VALUES (?, ?, 1, 50)", rangedID, otherProjectID)
+		require.NoError(t, err)
+
+		locking, err := model.GetLockingInvoices(otherID)
+		require.NoError(t, err)
+		assert.Equal(t, []int{rangedID}, locking)
+	})
+
+	t.Run("entries logged after the invoice aren't locked", func(t *testing.T) {
+		_, err := testDB.DB.Exec("UPDATE invoice SET created_at = datetime('now', '-1 day') WHERE id = ?", rangedID)
+		require.NoError(t, err)
+		defer testDB.DB.Exec("UPDATE invoice SET created_at = CURRENT_TIMESTAMP WHERE id = ?", rangedID)
+
+		locking, err := model.GetLockingInvoices(marchID)
+		require.NoError(t, err)
+		assert.Empty(t, locking)
+	})
+
+	t.Run("flagging an invoice lasts until it is saved", func(t *testing.T) {
+		require.NoError(t, invoices.FlagTimesheetsChanged(rangedID))
+		invoice, err := invoices.Get(rangedID)
+		require.NoError(t, err)
+		assert.True(t, invoice.NeedsRegeneration())
+
+		require.NoError(t, invoices.Update(rangedID, invoice.InvoiceDate, nil, invoice.PaymentTerms, 150.00, true))
+		invoice, err = invoices.Get(rangedID)
+		require.NoError(t, err)
+		assert.False(t, invoice.NeedsRegeneration())
+	})
+
+	t.Run("milestone invoices don't lock", func(t *testing.T) {
+		milestones := NewMilestoneModel(testDB.DB)
+		milestoneID, err := milestones.Insert(Milestone{ProjectID: projectID, Name: "Design", Fee: 500.00, Status: MilestoneStatusComplete})
+		require.NoError(t, err)
+		invoiceID, err := invoices.Insert(projectID, time.Date(2024, 4, 10, 0, 0, 0, 0, time.UTC), nil, "Net 30", 500.00, false)
+		require.NoError(t, err)
+		require.NoError(t, milestones.SetInvoice(milestoneID, invoiceID))
+
+		locking, err := model.GetLockingInvoices(aprilID)
+		require.NoError(t, err)
+		assert.Empty(t, locking)
+
+		locked, err := model.GetLockedIDs(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]bool{marchID: true}, locked)
+	})
+
+	t.Run("voided invoices don't lock", func(t *testing.T) {
+		require.NoError(t, invoices.Void(rangedID, "Reissued"))
+
+		locked, err := model.GetLockedIDs(projectID)
+		require.NoError(t, err)
+		assert.Empty(t, locked)
+	})
+}
+
 func TestTimesheetModel_Integration(t *testing.T) {
 	// Setup test database
 	testDB := testutil.SetupTestSQLite(t)
@@ -497,6 +779,20 @@ func TestTimesheetModel_Integration(t *testing.T) {
 	})
 }
 
+func TestTemplateTimesheets(t *testing.T) {
+	timesheets := []Timesheet{
+		{ID: 1, Description: "Status meeting"},
+		{ID: 2, HoursWorked: 2, Description: "Fixed the build"},
+		{ID: 3, Description: "Monthly report"},
+	}
+
+	templates := TemplateTimesheets(timesheets)
+	require.Len(t, templates, 2)
+	assert.Equal(t, 1, templates[0].ID)
+	assert.Equal(t, 3, templates[1].ID)
+	assert.Empty(t, TemplateTimesheets(timesheets[1:2]))
+}
+
 // TestInterface verifies that the implementation satisfies the interface
 func TestTimesheetModelInterface(t *testing.T) {
 	testDB := testutil.SetupTestSQLite(t)
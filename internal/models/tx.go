@@ -0,0 +1,110 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// TxModels holds a set of models which all run their queries inside the same transaction
+type TxModels struct {
+	Clients              ClientModelInterface
+	Projects             ProjectModelInterface
+	Timesheets           TimesheetModelInterface
+	Invoices             InvoiceModelInterface
+	Settings             AppSettingModelInterface
+	BusinessProfiles     BusinessProfileModelInterface
+	Credits              ClientCreditModelInterface
+	InvoiceEvents        InvoiceEventModelInterface
+	PendingTimesheets    PendingTimesheetModelInterface
+	Milestones           MilestoneModelInterface
+	Tags                 TagModelInterface
+	ConsolidatedInvoices ConsolidatedInvoiceModelInterface
+}
+
+// TxManager runs units of work that span several models atomically
+type TxManager struct {
+	db *sql.DB
+}
+
+// NewTxManager creates a new TxManager
+func NewTxManager(database *sql.DB) *TxManager {
+	return &TxManager{
+		db: database,
+	}
+}
+
+// WithinTx begins a transaction and passes models bound to it to fn. The transaction is
+// committed if fn returns nil and rolled back if it returns an error or panics.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(tx TxModels) error) (err error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	err = fn(TxModels{
+		Clients:              NewClientModelWithTx(tx),
+		Projects:             NewProjectModelWithTx(tx),
+		Timesheets:           NewTimesheetModelWithTx(tx),
+		Invoices:             NewInvoiceModelWithTx(tx),
+		Settings:             NewAppSettingModelWithTx(tx),
+		BusinessProfiles:     NewBusinessProfileModelWithTx(tx),
+		Credits:              NewClientCreditModelWithTx(tx),
+		InvoiceEvents:        NewInvoiceEventModelWithTx(tx),
+		PendingTimesheets:    NewPendingTimesheetModelWithTx(tx),
+		Milestones:           NewMilestoneModelWithTx(tx),
+		Tags:                 NewTagModelWithTx(tx),
+		ConsolidatedInvoices: NewConsolidatedInvoiceModelWithTx(tx),
+	})
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// inTx runs fn with queries bound to a new transaction on database, committing if it returns
+// nil. Models created inside a transaction have no database of their own, in which case fn
+// runs with their queries as part of the transaction they already belong to.
+func inTx(ctx context.Context, database *sql.DB, queries *db.Queries, fn func(q *db.Queries) error) error {
+	if database == nil {
+		return fn(queries)
+	}
+
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(newQueries(tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// TxManagerInterface defines the interface for running multi-model units of work
+type TxManagerInterface interface {
+	WithinTx(ctx context.Context, fn func(tx TxModels) error) error
+}
+
+// Ensure implementation satisfies the interface
+var _ TxManagerInterface = (*TxManager)(nil)
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxManager_WithinTx(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	manager := NewTxManager(testDB.DB)
+	clientModel := NewClientModel(testDB.DB)
+
+	t.Run("commits when the unit of work succeeds", func(t *testing.T) {
+		testDB.TruncateTable(t, "project")
+		testDB.TruncateTable(t, "client")
+
+		err := manager.WithinTx(context.Background(), func(tx TxModels) error {
+			clientID, err := tx.Clients.Insert(Client{Name: "Committed Client", Email: "c@example.com"})
+			if err != nil {
+				return err
+			}
+			_, err = tx.Projects.Insert(Project{Name: "Committed Project", ClientID: clientID, Status: "Estimating", CurrencyDisplay: "USD", CurrencyConversionRate: 1.0})
+			return err
+		})
+		require.NoError(t, err)
+
+		clients, err := clientModel.GetAll()
+		require.NoError(t, err)
+		require.Len(t, clients, 1)
+		assert.Equal(t, "Committed Client", clients[0].Name)
+
+		var projectCount int
+		require.NoError(t, testDB.DB.QueryRow("SELECT COUNT(*) FROM project").Scan(&projectCount))
+		assert.Equal(t, 1, projectCount)
+	})
+
+	t.Run("rolls back when the unit of work fails", func(t *testing.T) {
+		testDB.TruncateTable(t, "client")
+		failure := errors.New("something went wrong")
+
+		err := manager.WithinTx(context.Background(), func(tx TxModels) error {
+			_, err := tx.Clients.Insert(Client{Name: "Rolled Back Client"})
+			if err != nil {
+				return err
+			}
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+
+		clients, err := clientModel.GetAll()
+		require.NoError(t, err)
+		assert.Empty(t, clients)
+	})
+
+	t.Run("rolls back and re-panics on panic", func(t *testing.T) {
+		testDB.TruncateTable(t, "client")
+
+		assert.Panics(t, func() {
+			manager.WithinTx(context.Background(), func(tx TxModels) error {
+				tx.Clients.Insert(Client{Name: "Panicking Client"})
+				panic("boom")
+			})
+		})
+
+		clients, err := clientModel.GetAll()
+		require.NoError(t, err)
+		assert.Empty(t, clients)
+	})
+
+	t.Run("changes are visible inside the transaction", func(t *testing.T) {
+		testDB.TruncateTable(t, "business_profile")
+		profileID := testDB.InsertTestBusinessProfile(t, "Sequenced", "SQ-")
+
+		var numbers []string
+		err := manager.WithinTx(context.Background(), func(tx TxModels) error {
+			for range 2 {
+				number, err := tx.BusinessProfiles.NextInvoiceNumber(profileID)
+				if err != nil {
+					return err
+				}
+				numbers = append(numbers, number)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"SQ-0001", "SQ-0002"}, numbers)
+	})
+}
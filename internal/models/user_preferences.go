@@ -0,0 +1,141 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// Themes a user can choose between. ThemeSystem follows the light or dark mode of the user's
+// device, while ThemeLight is the look the application has always had.
+const (
+	ThemeLight  = ""
+	ThemeDark   = "dark"
+	ThemeSystem = "system"
+)
+
+// Theme is a colour scheme the application can be shown in
+type Theme struct {
+	Value string
+	Name  string
+}
+
+// Themes lists the themes in the order they are offered
+var Themes = []Theme{
+	{ThemeLight, "Light"},
+	{ThemeDark, "Dark"},
+	{ThemeSystem, "Match my device"},
+}
+
+// IsTheme reports whether value names one of the themes
+func IsTheme(value string) bool {
+	for _, theme := range Themes {
+		if theme.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// LandingPage is a page a user can choose to be taken to after logging in
+type LandingPage struct {
+	Path      string
+	Name      string
+	OwnerOnly bool
+}
+
+// LandingPages lists the pages a user can land on after logging in
+var LandingPages = []LandingPage{
+	{"/", "Clients", true},
+	{"/projects", "Projects", false},
+	{"/timesheet/create", "Log Time", true},
+	{"/timesheets/pending", "Pending timesheets", true},
+	{"/reports/margins", "Margins report", true},
+}
+
+// LookupLandingPage finds a landing page by its path
+func LookupLandingPage(path string) (LandingPage, bool) {
+	for _, page := range LandingPages {
+		if page.Path == path {
+			return page, true
+		}
+	}
+	return LandingPage{}, false
+}
+
+// UserPreferences are a user's own choices for how the application looks and behaves. Zero
+// values mean the user hasn't chosen and the global settings apply.
+type UserPreferences struct {
+	UserID      int
+	PageSize    *int
+	LandingPage string
+	DateFormat  string
+	Theme       string
+	Updated     time.Time
+	Created     time.Time
+}
+
+// UserPreferencesModel wraps the generated SQLC Queries for user preference operations
+type UserPreferencesModel struct {
+	queries *db.Queries
+}
+
+// NewUserPreferencesModel creates a new UserPreferencesModel
+func NewUserPreferencesModel(database *sql.DB) *UserPreferencesModel {
+	return &UserPreferencesModel{
+		queries: newQueries(database),
+	}
+}
+
+// NewUserPreferencesModelWithTx creates a UserPreferencesModel whose queries run inside the given transaction
+func NewUserPreferencesModelWithTx(tx *sql.Tx) *UserPreferencesModel {
+	return &UserPreferencesModel{
+		queries: newQueries(tx),
+	}
+}
+
+// Get returns a user's preferences. Users who have never saved any get empty preferences.
+func (m *UserPreferencesModel) Get(userID int) (UserPreferences, error) {
+	ctx := context.Background()
+	row, err := m.queries.GetUserPreferences(ctx, int64(userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return UserPreferences{UserID: userID}, nil
+		}
+		return UserPreferences{}, err
+	}
+
+	return UserPreferences{
+		UserID:      int(row.UserID),
+		PageSize:    convertNullInt64(row.PageSize),
+		LandingPage: row.LandingPage.String,
+		DateFormat:  row.DateFormat.String,
+		Theme:       row.Theme.String,
+		Updated:     row.UpdatedAt,
+		Created:     row.CreatedAt,
+	}, nil
+}
+
+// Save stores a user's preferences, replacing any saved before
+func (m *UserPreferencesModel) Save(preferences UserPreferences) error {
+	ctx := context.Background()
+	return m.queries.SaveUserPreferences(ctx, db.SaveUserPreferencesParams{
+		UserID:      int64(preferences.UserID),
+		PageSize:    convertIntPtr(preferences.PageSize),
+		LandingPage: sql.NullString{String: preferences.LandingPage, Valid: preferences.LandingPage != ""},
+		DateFormat:  sql.NullString{String: preferences.DateFormat, Valid: preferences.DateFormat != ""},
+		Theme:       sql.NullString{String: preferences.Theme, Valid: preferences.Theme != ""},
+	})
+}
+
+// UserPreferencesModelInterface defines the interface for user preference operations
+type UserPreferencesModelInterface interface {
+	Get(userID int) (UserPreferences, error)
+	Save(preferences UserPreferences) error
+}
+
+// Ensure implementation satisfies the interface
+var _ UserPreferencesModelInterface = (*UserPreferencesModel)(nil)
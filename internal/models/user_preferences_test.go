@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserPreferencesModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewUserPreferencesModel(testDB.DB)
+	userID, err := NewUserModel(testDB.DB).Insert(User{Name: "Ann Owner", Email: "ann@example.com", Role: RoleOwner}, "correct horse")
+	require.NoError(t, err)
+
+	t.Run("users who never saved any get empty preferences", func(t *testing.T) {
+		preferences, err := model.Get(userID)
+		require.NoError(t, err)
+		assert.Equal(t, userID, preferences.UserID)
+		assert.Nil(t, preferences.PageSize)
+		assert.Empty(t, preferences.DateFormat)
+		assert.Equal(t, ThemeLight, preferences.Theme)
+	})
+
+	t.Run("saving replaces earlier preferences", func(t *testing.T) {
+		pageSize := 25
+		require.NoError(t, model.Save(UserPreferences{UserID: userID, PageSize: &pageSize, LandingPage: "/projects", DateFormat: string(DateFormatUS), Theme: ThemeDark}))
+
+		preferences, err := model.Get(userID)
+		require.NoError(t, err)
+		require.NotNil(t, preferences.PageSize)
+		assert.Equal(t, 25, *preferences.PageSize)
+		assert.Equal(t, "/projects", preferences.LandingPage)
+		assert.Equal(t, string(DateFormatUS), preferences.DateFormat)
+		assert.Equal(t, ThemeDark, preferences.Theme)
+
+		require.NoError(t, model.Save(UserPreferences{UserID: userID, Theme: ThemeSystem}))
+
+		preferences, err = model.Get(userID)
+		require.NoError(t, err)
+		assert.Nil(t, preferences.PageSize)
+		assert.Empty(t, preferences.LandingPage)
+		assert.Empty(t, preferences.DateFormat)
+		assert.Equal(t, ThemeSystem, preferences.Theme)
+	})
+}
+
+func TestLookupLandingPage(t *testing.T) {
+	page, ok := LookupLandingPage("/projects")
+	assert.True(t, ok)
+	assert.False(t, page.OwnerOnly)
+
+	_, ok = LookupLandingPage("https://example.com")
+	assert.False(t, ok)
+}
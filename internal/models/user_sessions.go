@@ -0,0 +1,175 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+// UserSession is one login of a user, on one browser. Sessions remembered at login last until
+// they expire however long the browser is idle; others expire after a spell of inactivity.
+type UserSession struct {
+	ID         int
+	UserID     int
+	Token      string
+	RememberMe bool
+	UserAgent  string
+	IPAddress  string
+	ExpiresAt  time.Time
+	LastSeen   time.Time
+	Created    time.Time
+}
+
+// Expired reports whether the session has ended by now
+func (s UserSession) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// UserSessionModel wraps the generated SQLC Queries for user session operations
+type UserSessionModel struct {
+	queries *db.Queries
+}
+
+// NewUserSessionModel creates a new UserSessionModel
+func NewUserSessionModel(database *sql.DB) *UserSessionModel {
+	return &UserSessionModel{
+		queries: newQueries(database),
+	}
+}
+
+// NewUserSessionModelWithTx creates a UserSessionModel whose queries run inside the given transaction
+func NewUserSessionModelWithTx(tx *sql.Tx) *UserSessionModel {
+	return &UserSessionModel{
+		queries: newQueries(tx),
+	}
+}
+
+// sessionTime drops the parts of a time that don't survive a round trip through the database
+func sessionTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
+
+// Start records a new login, returning it with the random token that identifies it
+func (m *UserSessionModel) Start(session UserSession) (UserSession, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return UserSession{}, err
+	}
+	session.Token = base64.RawURLEncoding.EncodeToString(token)
+	session.ExpiresAt = sessionTime(session.ExpiresAt)
+	session.LastSeen = sessionTime(session.LastSeen)
+
+	ctx := context.Background()
+	id, err := m.queries.InsertUserSession(ctx, db.InsertUserSessionParams{
+		UserID:     int64(session.UserID),
+		Token:      session.Token,
+		RememberMe: session.RememberMe,
+		UserAgent:  session.UserAgent,
+		IpAddress:  session.IPAddress,
+		ExpiresAt:  session.ExpiresAt,
+		LastSeenAt: session.LastSeen,
+	})
+	if err != nil {
+		return UserSession{}, err
+	}
+	session.ID = int(id)
+	session.Created = session.LastSeen
+	return session, nil
+}
+
+// GetByToken returns the session a token identifies, expired or not
+func (m *UserSessionModel) GetByToken(token string) (UserSession, error) {
+	ctx := context.Background()
+	row, err := m.queries.GetUserSessionByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return UserSession{}, ErrNoRecord
+		}
+		return UserSession{}, err
+	}
+	return convertUserSession(row), nil
+}
+
+// GetByUser returns a user's sessions that haven't expired by now, most recently used first
+func (m *UserSessionModel) GetByUser(userID int, now time.Time) ([]UserSession, error) {
+	ctx := context.Background()
+	rows, err := m.queries.GetUserSessionsByUser(ctx, db.GetUserSessionsByUserParams{
+		UserID:    int64(userID),
+		ExpiresAt: sessionTime(now),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]UserSession, len(rows))
+	for i, row := range rows {
+		sessions[i] = convertUserSession(row)
+	}
+	return sessions, nil
+}
+
+// Touch records that a session was used and moves its expiry to a new time
+func (m *UserSessionModel) Touch(id int, lastSeen, expiresAt time.Time) error {
+	ctx := context.Background()
+	return m.queries.TouchUserSession(ctx, db.TouchUserSessionParams{
+		LastSeenAt: sessionTime(lastSeen),
+		ExpiresAt:  sessionTime(expiresAt),
+		ID:         int64(id),
+	})
+}
+
+// Delete ends a session
+func (m *UserSessionModel) Delete(id int) error {
+	ctx := context.Background()
+	return m.queries.DeleteUserSession(ctx, int64(id))
+}
+
+// DeleteOthers ends every session of a user except the one given, returning how many were ended
+func (m *UserSessionModel) DeleteOthers(userID, keepID int) (int, error) {
+	ctx := context.Background()
+	count, err := m.queries.DeleteOtherUserSessions(ctx, db.DeleteOtherUserSessionsParams{
+		UserID: int64(userID),
+		ID:     int64(keepID),
+	})
+	return int(count), err
+}
+
+// DeleteExpired removes the sessions that have expired by now
+func (m *UserSessionModel) DeleteExpired(now time.Time) error {
+	ctx := context.Background()
+	return m.queries.DeleteExpiredUserSessions(ctx, sessionTime(now))
+}
+
+// convertUserSession converts a generated row to a UserSession
+func convertUserSession(row db.UserSession) UserSession {
+	return UserSession{
+		ID:         int(row.ID),
+		UserID:     int(row.UserID),
+		Token:      row.Token,
+		RememberMe: row.RememberMe,
+		UserAgent:  row.UserAgent,
+		IPAddress:  row.IpAddress,
+		ExpiresAt:  row.ExpiresAt,
+		LastSeen:   row.LastSeenAt,
+		Created:    row.CreatedAt,
+	}
+}
+
+// UserSessionModelInterface defines the interface for user session operations
+type UserSessionModelInterface interface {
+	Start(session UserSession) (UserSession, error)
+	GetByToken(token string) (UserSession, error)
+	GetByUser(userID int, now time.Time) ([]UserSession, error)
+	Touch(id int, lastSeen, expiresAt time.Time) error
+	Delete(id int) error
+	DeleteOthers(userID, keepID int) (int, error)
+	DeleteExpired(now time.Time) error
+}
+
+// Ensure implementation satisfies the interface
+var _ UserSessionModelInterface = (*UserSessionModel)(nil)
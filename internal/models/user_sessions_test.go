@@ -0,0 +1,93 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserSessionModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewUserSessionModel(testDB.DB)
+	users := NewUserModel(testDB.DB)
+	annID, err := users.Insert(User{Name: "Ann Owner", Email: "ann@example.com", Role: RoleOwner}, "correct horse")
+	require.NoError(t, err)
+	bobID, err := users.Insert(User{Name: "Bob Sub", Email: "bob@example.com", Role: RoleSubcontractor}, "correct horse")
+	require.NoError(t, err)
+
+	now := time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC)
+	start := func(userID int, rememberMe bool, lifetime time.Duration) UserSession {
+		session, err := model.Start(UserSession{UserID: userID, RememberMe: rememberMe, UserAgent: "Firefox", IPAddress: "192.0.2.1",
+			LastSeen: now, ExpiresAt: now.Add(lifetime)})
+		require.NoError(t, err)
+		return session
+	}
+
+	t.Run("a session is found by its token", func(t *testing.T) {
+		started := start(annID, true, 30*24*time.Hour)
+		assert.NotEmpty(t, started.Token)
+
+		session, err := model.GetByToken(started.Token)
+		require.NoError(t, err)
+		assert.Equal(t, started.ID, session.ID)
+		assert.Equal(t, annID, session.UserID)
+		assert.True(t, session.RememberMe)
+		assert.Equal(t, "Firefox", session.UserAgent)
+		assert.True(t, session.ExpiresAt.Equal(now.Add(30*24*time.Hour)))
+
+		_, err = model.GetByToken("unknown")
+		assert.ErrorIs(t, err, ErrNoRecord)
+	})
+
+	t.Run("touching a session moves its expiry", func(t *testing.T) {
+		started := start(annID, false, time.Hour)
+		assert.True(t, started.Expired(now.Add(time.Hour)))
+
+		later := now.Add(50 * time.Minute)
+		require.NoError(t, model.Touch(started.ID, later, later.Add(time.Hour)))
+		session, err := model.GetByToken(started.Token)
+		require.NoError(t, err)
+		assert.True(t, session.LastSeen.Equal(later))
+		assert.False(t, session.Expired(now.Add(time.Hour)))
+	})
+
+	t.Run("only unexpired sessions are listed", func(t *testing.T) {
+		start(bobID, false, time.Hour)
+		start(bobID, false, -time.Minute)
+
+		sessions, err := model.GetByUser(bobID, now)
+		require.NoError(t, err)
+		assert.Len(t, sessions, 1)
+
+		require.NoError(t, model.DeleteExpired(now))
+		sessions, err = model.GetByUser(bobID, now.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Len(t, sessions, 1, "the expired session was removed")
+	})
+
+	t.Run("logging out other sessions keeps the current one and other users'", func(t *testing.T) {
+		current := start(annID, false, time.Hour)
+
+		count, err := model.DeleteOthers(annID, current.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		sessions, err := model.GetByUser(annID, now)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, current.ID, sessions[0].ID)
+
+		sessions, err = model.GetByUser(bobID, now)
+		require.NoError(t, err)
+		assert.Len(t, sessions, 1)
+
+		require.NoError(t, model.Delete(current.ID))
+		_, err = model.GetByToken(current.Token)
+		assert.ErrorIs(t, err, ErrNoRecord)
+	})
+}
@@ -0,0 +1,284 @@
+package models
+
+import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/db"
+)
+
+const (
+	RoleOwner         = "owner"
+	RoleSubcontractor = "subcontractor"
+)
+
+// passwordIterations is the PBKDF2 work factor used for new password hashes
+const passwordIterations = 600_000
+
+// User represents someone who can log in. Owners have full access; subcontractors
+// can only see and log time against projects that have been shared with them.
+type User struct {
+	ID      int
+	Name    string
+	Email   string
+	Role    string
+	Updated time.Time
+	Created time.Time
+}
+
+// IsOwner reports whether the user has full access to the application
+func (u User) IsOwner() bool {
+	return u.Role == RoleOwner
+}
+
+// UserModel wraps the generated SQLC Queries for user and project sharing operations
+type UserModel struct {
+	queries *db.Queries
+}
+
+// NewUserModel creates a new UserModel
+func NewUserModel(database *sql.DB) *UserModel {
+	return &UserModel{
+		queries: newQueries(database),
+	}
+}
+
+// NewUserModelWithTx creates a UserModel whose queries run inside the given transaction
+func NewUserModelWithTx(tx *sql.Tx) *UserModel {
+	return &UserModel{
+		queries: newQueries(tx),
+	}
+}
+
+// Insert adds a new user with the given password and returns its ID
+func (u *UserModel) Insert(user User, password string) (int, error) {
+	ctx := context.Background()
+
+	email := strings.ToLower(strings.TrimSpace(user.Email))
+	_, err := u.queries.GetUserByEmail(ctx, email)
+	if err == nil {
+		return 0, ErrDuplicateEmail
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := u.queries.InsertUser(ctx, db.InsertUserParams{
+		Name:           user.Name,
+		Email:          email,
+		HashedPassword: hashedPassword,
+		Role:           user.Role,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// Get retrieves a user by ID
+func (u *UserModel) Get(id int) (User, error) {
+	ctx := context.Background()
+	row, err := u.queries.GetUser(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return User{}, ErrNoRecord
+		}
+		return User{}, err
+	}
+	return newUser(row.ID, row.Name, row.Email, row.Role, row.UpdatedAt, row.CreatedAt), nil
+}
+
+// GetAll retrieves all users ordered by name
+func (u *UserModel) GetAll() ([]User, error) {
+	ctx := context.Background()
+	rows, err := u.queries.GetAllUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]User, len(rows))
+	for i, row := range rows {
+		users[i] = newUser(row.ID, row.Name, row.Email, row.Role, row.UpdatedAt, row.CreatedAt)
+	}
+	return users, nil
+}
+
+// Count returns the number of user accounts. While there are none the application
+// runs without requiring a login.
+func (u *UserModel) Count() (int, error) {
+	ctx := context.Background()
+	count, err := u.queries.GetUsersCount(ctx)
+	return int(count), err
+}
+
+// Authenticate checks an email and password and returns the matching user's ID
+func (u *UserModel) Authenticate(email, password string) (int, error) {
+	ctx := context.Background()
+	row, err := u.queries.GetUserByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrInvalidCredentials
+		}
+		return 0, err
+	}
+
+	ok, err := checkPassword(row.HashedPassword, password)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, ErrInvalidCredentials
+	}
+	return int(row.ID), nil
+}
+
+// Delete soft deletes a user by setting the deleted_at timestamp
+func (u *UserModel) Delete(id int) error {
+	ctx := context.Background()
+	return u.queries.DeleteUser(ctx, int64(id))
+}
+
+// ShareProject gives a user access to a project
+func (u *UserModel) ShareProject(projectID, userID int) error {
+	ctx := context.Background()
+	return u.queries.ShareProject(ctx, db.ShareProjectParams{
+		ProjectID: int64(projectID),
+		UserID:    int64(userID),
+	})
+}
+
+// UnshareProject removes a user's access to a project
+func (u *UserModel) UnshareProject(projectID, userID int) error {
+	ctx := context.Background()
+	return u.queries.UnshareProject(ctx, db.UnshareProjectParams{
+		ProjectID: int64(projectID),
+		UserID:    int64(userID),
+	})
+}
+
+// GetProjectUsers retrieves the users a project has been shared with
+func (u *UserModel) GetProjectUsers(projectID int) ([]User, error) {
+	ctx := context.Background()
+	rows, err := u.queries.GetProjectSharedUsers(ctx, int64(projectID))
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]User, len(rows))
+	for i, row := range rows {
+		users[i] = newUser(row.ID, row.Name, row.Email, row.Role, row.UpdatedAt, row.CreatedAt)
+	}
+	return users, nil
+}
+
+// HasProjectAccess reports whether a project has been shared with a user
+func (u *UserModel) HasProjectAccess(projectID, userID int) (bool, error) {
+	ctx := context.Background()
+	count, err := u.queries.IsProjectShared(ctx, db.IsProjectSharedParams{
+		ProjectID: int64(projectID),
+		UserID:    int64(userID),
+	})
+	return count > 0, err
+}
+
+// GetSharedProjectIDs retrieves the IDs of the projects shared with a user
+func (u *UserModel) GetSharedProjectIDs(userID int) ([]int, error) {
+	ctx := context.Background()
+	rows, err := u.queries.GetSharedProjectIDs(ctx, int64(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(rows))
+	for i, id := range rows {
+		ids[i] = int(id)
+	}
+	return ids, nil
+}
+
+func newUser(id int64, name, email, role string, updated, created time.Time) User {
+	return User{
+		ID:      int(id),
+		Name:    name,
+		Email:   email,
+		Role:    role,
+		Updated: updated,
+		Created: created,
+	}
+}
+
+// hashPassword derives a salted PBKDF2-SHA256 hash, stored as "pbkdf2-sha256$iterations$salt$hash"
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, 32)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", passwordIterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// checkPassword reports whether password matches a hash produced by hashPassword
+func checkPassword(hashed, password string) (bool, error) {
+	parts := strings.Split(hashed, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false, fmt.Errorf("unrecognised password hash format")
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false, fmt.Errorf("invalid password hash iterations: %w", err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false, fmt.Errorf("invalid password hash salt: %w", err)
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false, fmt.Errorf("invalid password hash: %w", err)
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(expected))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(key, expected) == 1, nil
+}
+
+// UserModelInterface defines the interface for user and project sharing operations
+type UserModelInterface interface {
+	Insert(user User, password string) (int, error)
+	Get(id int) (User, error)
+	GetAll() ([]User, error)
+	Count() (int, error)
+	Authenticate(email, password string) (int, error)
+	Delete(id int) error
+	ShareProject(projectID, userID int) error
+	UnshareProject(projectID, userID int) error
+	GetProjectUsers(projectID int) ([]User, error)
+	HasProjectAccess(projectID, userID int) (bool, error)
+	GetSharedProjectIDs(userID int) ([]int, error)
+}
+
+// Ensure implementation satisfies the interface
+var _ UserModelInterface = (*UserModel)(nil)
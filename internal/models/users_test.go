@@ -0,0 +1,101 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserModel(t *testing.T) {
+	testDB := testutil.SetupTestSQLite(t)
+	defer testDB.Cleanup(t)
+
+	model := NewUserModel(testDB.DB)
+
+	testDB.TruncateTable(t, "project_share")
+	testDB.TruncateTable(t, "user")
+	testDB.TruncateTable(t, "project")
+	testDB.TruncateTable(t, "client")
+
+	clientID := testDB.InsertTestClient(t, "Sharing Client")
+	sharedID := testDB.InsertTestProject(t, "Shared Project", clientID)
+	privateID := testDB.InsertTestProject(t, "Private Project", clientID)
+
+	var editorID int
+
+	t.Run("insert stores a normalised email", func(t *testing.T) {
+		count, err := model.Count()
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		id, err := model.Insert(User{Name: "Ed Itor", Email: " Editor@Example.com ", Role: RoleSubcontractor}, "correct horse")
+		require.NoError(t, err)
+		editorID = id
+
+		user, err := model.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, "editor@example.com", user.Email)
+		assert.False(t, user.IsOwner())
+
+		count, err = model.Count()
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("duplicate email is rejected", func(t *testing.T) {
+		_, err := model.Insert(User{Name: "Someone Else", Email: "editor@example.com", Role: RoleOwner}, "another password")
+		assert.ErrorIs(t, err, ErrDuplicateEmail)
+	})
+
+	t.Run("authenticate", func(t *testing.T) {
+		id, err := model.Authenticate("EDITOR@example.com", "correct horse")
+		require.NoError(t, err)
+		assert.Equal(t, editorID, id)
+
+		_, err = model.Authenticate("editor@example.com", "wrong password")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+		_, err = model.Authenticate("nobody@example.com", "correct horse")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("share and unshare projects", func(t *testing.T) {
+		require.NoError(t, model.ShareProject(sharedID, editorID))
+		// Sharing twice is harmless
+		require.NoError(t, model.ShareProject(sharedID, editorID))
+
+		shared, err := model.HasProjectAccess(sharedID, editorID)
+		require.NoError(t, err)
+		assert.True(t, shared)
+
+		shared, err = model.HasProjectAccess(privateID, editorID)
+		require.NoError(t, err)
+		assert.False(t, shared)
+
+		ids, err := model.GetSharedProjectIDs(editorID)
+		require.NoError(t, err)
+		assert.Equal(t, []int{sharedID}, ids)
+
+		users, err := model.GetProjectUsers(sharedID)
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, editorID, users[0].ID)
+
+		require.NoError(t, model.UnshareProject(sharedID, editorID))
+		shared, err = model.HasProjectAccess(sharedID, editorID)
+		require.NoError(t, err)
+		assert.False(t, shared)
+	})
+
+	t.Run("deleted users can no longer log in", func(t *testing.T) {
+		require.NoError(t, model.Delete(editorID))
+
+		_, err := model.Get(editorID)
+		assert.ErrorIs(t, err, ErrNoRecord)
+
+		_, err = model.Authenticate("editor@example.com", "correct horse")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+}
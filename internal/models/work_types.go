@@ -0,0 +1,73 @@
+package models
+
+import "strings"
+
+// ParseWorkTypes reads the comma separated list of work types in the timesheet_work_types
+// setting, leaving out blanks and repeats of a type already listed in any case
+func ParseWorkTypes(list string) []string {
+	var workTypes []string
+	seen := map[string]bool{}
+	for _, workType := range strings.Split(list, ",") {
+		workType = strings.TrimSpace(workType)
+		key := strings.ToLower(workType)
+		if workType == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		workTypes = append(workTypes, workType)
+	}
+	return workTypes
+}
+
+// WorkTypeTotal is the time logged as one kind of work. WorkType is blank for entries logged
+// without one. Hours only totals entries billed by the hour.
+type WorkTypeTotal struct {
+	WorkType string
+	Entries  int
+	Hours    float64
+}
+
+// WorkTypeGroup is the timesheet entries of one kind of work listed on an invoice, with their
+// subtotal. WorkType is blank for entries logged without one.
+type WorkTypeGroup struct {
+	WorkType   string
+	Timesheets []Timesheet
+	Hours      float64
+	Amount     float64
+}
+
+// GroupByWorkType groups timesheet entries by work type, keeping their order within each
+// group. Groups are in the order their first entry appears, with entries that have no work
+// type last.
+func GroupByWorkType(timesheets []Timesheet) []WorkTypeGroup {
+	var groups []WorkTypeGroup
+	index := map[string]int{}
+	var untyped *WorkTypeGroup
+	for _, timesheet := range timesheets {
+		var group *WorkTypeGroup
+		if timesheet.WorkType == "" {
+			if untyped == nil {
+				untyped = &WorkTypeGroup{}
+			}
+			group = untyped
+		} else {
+			i, ok := index[timesheet.WorkType]
+			if !ok {
+				i = len(groups)
+				index[timesheet.WorkType] = i
+				groups = append(groups, WorkTypeGroup{WorkType: timesheet.WorkType})
+			}
+			group = &groups[i]
+		}
+
+		group.Timesheets = append(group.Timesheets, timesheet)
+		group.Amount += timesheet.Amount()
+		if timesheet.IsHourly() {
+			group.Hours += timesheet.HoursWorked
+		}
+	}
+	if untyped != nil {
+		groups = append(groups, *untyped)
+	}
+	return groups
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorkTypes(t *testing.T) {
+	assert.Equal(t, []string{"Editing", "Formatting", "Meeting"}, ParseWorkTypes(" Editing, Formatting,,editing , Meeting "))
+	assert.Empty(t, ParseWorkTypes(""))
+	assert.Empty(t, ParseWorkTypes(" , "))
+}
+
+func TestGroupByWorkType(t *testing.T) {
+	date := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	timesheets := []Timesheet{
+		{ID: 1, WorkDate: date, HoursWorked: 2, HourlyRate: 50},
+		{ID: 2, WorkDate: date, HoursWorked: 1, HourlyRate: 60, WorkType: "Meeting"},
+		{ID: 3, WorkDate: date, HoursWorked: 3, HourlyRate: 50, WorkType: "Editing"},
+		{ID: 4, WorkDate: date, HoursWorked: 1000, HourlyRate: 0.05, Unit: UnitWord, WorkType: "Editing"},
+		{ID: 5, WorkDate: date, HoursWorked: 0.5, HourlyRate: 60, WorkType: "Meeting"},
+	}
+
+	groups := GroupByWorkType(timesheets)
+
+	require.Len(t, groups, 3)
+	assert.Equal(t, "Meeting", groups[0].WorkType)
+	assert.Equal(t, []int{2, 5}, timesheetIDs(groups[0].Timesheets))
+	assert.Equal(t, 1.5, groups[0].Hours)
+	assert.Equal(t, 90.0, groups[0].Amount)
+
+	assert.Equal(t, "Editing", groups[1].WorkType)
+	assert.Equal(t, []int{3, 4}, timesheetIDs(groups[1].Timesheets))
+	assert.Equal(t, 3.0, groups[1].Hours, "words aren't counted as hours")
+	assert.Equal(t, 200.0, groups[1].Amount)
+
+	assert.Empty(t, groups[2].WorkType, "entries without a work type come last")
+	assert.Equal(t, []int{1}, timesheetIDs(groups[2].Timesheets))
+
+	assert.Empty(t, GroupByWorkType(nil))
+}
+
+func timesheetIDs(timesheets []Timesheet) []int {
+	ids := make([]int, len(timesheets))
+	for i, timesheet := range timesheets {
+		ids[i] = timesheet.ID
+	}
+	return ids
+}
@@ -0,0 +1,109 @@
+// Package notify sends short alerts, such as an invoice falling overdue, to the chat apps
+// where they will be seen: a Slack channel through an incoming webhook, or a Telegram chat
+// through a bot.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/paulboeck/FreelanceTrackerGo/internal/webhook"
+)
+
+// TelegramAPIURL is where the Telegram Bot API is served
+const TelegramAPIURL = "https://api.telegram.org"
+
+// Notifier delivers an alert as a plain text message
+type Notifier interface {
+	Notify(ctx context.Context, text string) error
+}
+
+// Slack posts messages to a channel through an incoming webhook URL
+type Slack struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// Notify posts text to the webhook's channel
+func (s Slack) Notify(ctx context.Context, text string) error {
+	err := webhook.Sender{Client: s.Client}.Post(ctx, s.WebhookURL, map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+	return nil
+}
+
+// Telegram sends messages to a chat through a bot. ChatID is the chat's numeric ID or a
+// channel's @username; APIURL defaults to TelegramAPIURL.
+type Telegram struct {
+	BotToken string
+	ChatID   string
+	APIURL   string
+	Client   *http.Client
+}
+
+// Notify sends text to the chat. Errors leave out the request URL, since it holds the bot's token.
+func (t Telegram) Notify(ctx context.Context, text string) error {
+	apiURL := t.APIURL
+	if apiURL == "" {
+		apiURL = TelegramAPIURL
+	}
+	body, err := json.Marshal(map[string]string{"chat_id": t.ChatID, "text": text})
+	if err != nil {
+		return fmt.Errorf("telegram: encoding message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+"/bot"+t.BotToken+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return errors.New("telegram: building request: invalid bot token or API URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := t.Client
+	if client == nil {
+		client = &http.Client{Timeout: webhook.DefaultTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram: sending message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Failures are described in the response, e.g. "Bad Request: chat not found"
+	var reply struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&reply)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || !reply.OK {
+		if reply.Description == "" {
+			reply.Description = resp.Status
+		}
+		return fmt.Errorf("telegram: %s", reply.Description)
+	}
+	return nil
+}
+
+// Multi sends every message through each of its notifiers, carrying on past any that fail
+type Multi []Notifier
+
+// Notify sends text through each notifier, returning their errors joined together
+func (m Multi) Notify(ctx context.Context, text string) error {
+	var errs []error
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, text); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackNotify(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	slack := Slack{WebhookURL: server.URL + "/services/T000/B000/XXXX", Client: server.Client()}
+	require.NoError(t, slack.Notify(context.Background(), "Invoice #7 is overdue"))
+	assert.Equal(t, map[string]string{"text": "Invoice #7 is overdue"}, received)
+}
+
+func TestTelegramNotify(t *testing.T) {
+	var path string
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		if received["chat_id"] == "missing" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	defer server.Close()
+
+	telegram := Telegram{BotToken: "123:secret", ChatID: "4567", APIURL: server.URL, Client: server.Client()}
+	require.NoError(t, telegram.Notify(context.Background(), "Thesis is due in 3 days"))
+	assert.Equal(t, "/bot123:secret/sendMessage", path)
+	assert.Equal(t, map[string]string{"chat_id": "4567", "text": "Thesis is due in 3 days"}, received)
+
+	telegram.ChatID = "missing"
+	err := telegram.Notify(context.Background(), "Hello")
+	assert.EqualError(t, err, "telegram: Bad Request: chat not found")
+
+	server.Close()
+	err = telegram.Notify(context.Background(), "Hello")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret", "the bot token is kept out of errors")
+}
+
+type notifierFunc func(ctx context.Context, text string) error
+
+func (f notifierFunc) Notify(ctx context.Context, text string) error {
+	return f(ctx, text)
+}
+
+func TestMultiNotify(t *testing.T) {
+	var sent []string
+	record := notifierFunc(func(ctx context.Context, text string) error {
+		sent = append(sent, text)
+		return nil
+	})
+	fail := notifierFunc(func(ctx context.Context, text string) error {
+		return errors.New("unreachable")
+	})
+
+	err := Multi{fail, record}.Notify(context.Background(), "Reminder")
+	assert.EqualError(t, err, "unreachable")
+	assert.Equal(t, []string{"Reminder"}, sent, "a failing notifier doesn't stop the others")
+}
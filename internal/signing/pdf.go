@@ -0,0 +1,504 @@
+package signing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// ErrUnsupportedPDF is returned for PDFs whose structure the signer doesn't handle, such as
+// ones using cross-reference streams or that already have form fields
+var ErrUnsupportedPDF = errors.New("signing: unsupported PDF structure")
+
+// signatureSpace is the room reserved in the PDF for the signature, which has to be written
+// before the signature itself can be made. It leaves plenty for a certificate chain.
+const signatureSpace = 8192
+
+// SignPDF adds an invisible signature to a PDF as an incremental update, leaving the original
+// bytes untouched so viewers can show the document as it was signed. Reason says what the
+// signature is for, e.g. "Invoice INV-0042".
+func (s *Signer) SignPDF(pdf []byte, reason string, at time.Time) ([]byte, error) {
+	file, err := parsePDF(pdf)
+	if err != nil {
+		return nil, err
+	}
+
+	rootRef, ok := file.trailer.get("Root").(pdfRef)
+	if !ok {
+		return nil, fmt.Errorf("%w: trailer has no catalog", ErrUnsupportedPDF)
+	}
+	catalog, err := file.dictObject(rootRef)
+	if err != nil {
+		return nil, err
+	}
+	if catalog.get("AcroForm") != nil {
+		return nil, fmt.Errorf("%w: PDF already has form fields", ErrUnsupportedPDF)
+	}
+	pageRef, page, err := file.firstPage(catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := strconv.Atoi(fmt.Sprint(file.trailer.get("Size")))
+	if err != nil {
+		return nil, fmt.Errorf("%w: trailer has no size", ErrUnsupportedPDF)
+	}
+	signatureRef := pdfRef{Num: size}
+	fieldRef := pdfRef{Num: size + 1}
+
+	// The widget is the signature field on the first page, with an empty rectangle so that
+	// nothing is drawn
+	switch annots := page.get("Annots").(type) {
+	case nil:
+		page.set("Annots", pdfArray{fieldRef})
+	case pdfArray:
+		page.set("Annots", append(annots, fieldRef))
+	default:
+		return nil, fmt.Errorf("%w: page annotations are not an array", ErrUnsupportedPDF)
+	}
+	catalog.set("AcroForm", &pdfDict{entries: []pdfEntry{
+		{"Fields", pdfArray{fieldRef}},
+		{"SigFlags", pdfRaw("3")},
+	}})
+
+	out := bytes.NewBuffer(append([]byte{}, pdf...))
+	if !bytes.HasSuffix(pdf, []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	offsets := map[int]int{}
+
+	offsets[signatureRef.Num] = out.Len()
+	fmt.Fprintf(out, "%d 0 obj\n<< /Type /Sig /Filter /Adobe.PPKLite /SubFilter /adbe.pkcs7.detached /ByteRange ", signatureRef.Num)
+	byteRangeAt := out.Len()
+	fmt.Fprintf(out, "[0 %10d %10d %10d]", 0, 0, 0)
+	out.WriteString(" /Contents ")
+	contentsStart := out.Len()
+	out.WriteString("<" + strings.Repeat("0", signatureSpace*2) + ">")
+	contentsEnd := out.Len()
+	fmt.Fprintf(out, " /M %s /Name %s /Reason %s >>\nendobj\n",
+		pdfDate(at), pdfText(s.Name()), pdfText(reason))
+
+	offsets[fieldRef.Num] = out.Len()
+	field := &pdfDict{entries: []pdfEntry{
+		{"Type", pdfRaw("/Annot")},
+		{"Subtype", pdfRaw("/Widget")},
+		{"FT", pdfRaw("/Sig")},
+		{"T", pdfRaw(pdfText("Signature"))},
+		{"V", signatureRef},
+		{"F", pdfRaw("132")},
+		{"Rect", pdfArray{pdfRaw("0"), pdfRaw("0"), pdfRaw("0"), pdfRaw("0")}},
+		{"P", pageRef},
+	}}
+	writeObject(out, fieldRef, field)
+
+	offsets[rootRef.Num] = out.Len()
+	writeObject(out, rootRef, catalog)
+	offsets[pageRef.Num] = out.Len()
+	writeObject(out, pageRef, page)
+
+	generations := map[int]int{rootRef.Num: rootRef.Gen, pageRef.Num: pageRef.Gen}
+	xrefAt := out.Len()
+	writeXref(out, offsets, generations)
+
+	trailer := &pdfDict{}
+	for _, entry := range file.trailer.entries {
+		if entry.Key != "Size" && entry.Key != "Prev" {
+			trailer.set(entry.Key, entry.Value)
+		}
+	}
+	trailer.set("Size", pdfRaw(strconv.Itoa(size+2)))
+	trailer.set("Prev", pdfRaw(strconv.Itoa(file.startxref)))
+	out.WriteString("trailer\n")
+	trailer.write(out)
+	fmt.Fprintf(out, "\nstartxref\n%d\n%%%%EOF\n", xrefAt)
+
+	// Everything but the signature itself is signed, as described by the byte range
+	signed := out.Bytes()
+	byteRange := fmt.Sprintf("[0 %10d %10d %10d]", contentsStart, contentsEnd, len(signed)-contentsEnd)
+	copy(signed[byteRangeAt:], byteRange)
+
+	content := append(append([]byte{}, signed[:contentsStart]...), signed[contentsEnd:]...)
+	signature, err := s.signDetached(content, at)
+	if err != nil {
+		return nil, err
+	}
+	if len(signature) > signatureSpace {
+		return nil, fmt.Errorf("signing: signature of %d bytes doesn't fit the %d reserved", len(signature), signatureSpace)
+	}
+	hex.Encode(signed[contentsStart+1:], signature)
+	return signed, nil
+}
+
+// writeObject writes an indirect object holding a dictionary
+func writeObject(out *bytes.Buffer, ref pdfRef, dict *pdfDict) {
+	fmt.Fprintf(out, "%d %d obj\n", ref.Num, ref.Gen)
+	dict.write(out)
+	out.WriteString("\nendobj\n")
+}
+
+// writeXref writes a cross-reference table for the objects at offsets, grouping objects
+// with consecutive numbers into one subsection
+func writeXref(out *bytes.Buffer, offsets map[int]int, generations map[int]int) {
+	numbers := make([]int, 0, len(offsets))
+	for num := range offsets {
+		numbers = append(numbers, num)
+	}
+	sort.Ints(numbers)
+
+	out.WriteString("xref\n")
+	for start := 0; start < len(numbers); {
+		end := start + 1
+		for end < len(numbers) && numbers[end] == numbers[end-1]+1 {
+			end++
+		}
+		fmt.Fprintf(out, "%d %d\n", numbers[start], end-start)
+		for _, num := range numbers[start:end] {
+			fmt.Fprintf(out, "%010d %05d n \n", offsets[num], generations[num])
+		}
+		start = end
+	}
+}
+
+// pdfDate formats a time as a PDF date string
+func pdfDate(t time.Time) string {
+	return t.UTC().Format("(D:20060102150405Z)")
+}
+
+// pdfText formats text as a PDF string, as UTF-16 when it isn't plain ASCII
+func pdfText(text string) string {
+	ascii := true
+	for _, r := range text {
+		ascii = ascii && r < 128
+	}
+	if ascii {
+		replacer := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`, "\r", `\r`, "\n", `\n`)
+		return "(" + replacer.Replace(text) + ")"
+	}
+
+	encoded := []uint16{0xFEFF}
+	encoded = append(encoded, utf16.Encode([]rune(text))...)
+	var b strings.Builder
+	b.WriteString("<")
+	for _, unit := range encoded {
+		fmt.Fprintf(&b, "%04X", unit)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// pdfFile is a parsed PDF, as far as is needed to add a signature
+type pdfFile struct {
+	data      []byte
+	offsets   map[int]int
+	trailer   *pdfDict
+	startxref int
+}
+
+// parsePDF reads the cross-reference tables and trailer of a PDF, following earlier
+// incremental updates through the trailers' Prev entries
+func parsePDF(data []byte) (*pdfFile, error) {
+	at := bytes.LastIndex(data, []byte("startxref"))
+	if at < 0 {
+		return nil, fmt.Errorf("%w: no startxref", ErrUnsupportedPDF)
+	}
+	p := &pdfParser{data: data, pos: at + len("startxref")}
+	startxref, err := strconv.Atoi(p.token())
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad startxref", ErrUnsupportedPDF)
+	}
+
+	file := &pdfFile{data: data, offsets: map[int]int{}, startxref: startxref}
+	seen := map[int]bool{}
+	for xref := startxref; ; {
+		if seen[xref] || xref < 0 || xref >= len(data) {
+			return nil, fmt.Errorf("%w: bad cross-reference offset", ErrUnsupportedPDF)
+		}
+		seen[xref] = true
+
+		trailer, err := file.readXref(xref)
+		if err != nil {
+			return nil, err
+		}
+		if file.trailer == nil {
+			file.trailer = trailer
+		}
+		prev := trailer.get("Prev")
+		if prev == nil {
+			return file, nil
+		}
+		xref, err = strconv.Atoi(fmt.Sprint(prev))
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad Prev offset", ErrUnsupportedPDF)
+		}
+	}
+}
+
+// readXref reads one cross-reference table into the file's offsets, keeping any already
+// read from a later update, and returns the trailer that follows it
+func (f *pdfFile) readXref(at int) (*pdfDict, error) {
+	p := &pdfParser{data: f.data, pos: at}
+	if p.token() != "xref" {
+		return nil, fmt.Errorf("%w: cross-reference streams are not supported", ErrUnsupportedPDF)
+	}
+	for {
+		token := p.token()
+		if token == "trailer" {
+			break
+		}
+		start, err := strconv.Atoi(token)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad cross-reference table", ErrUnsupportedPDF)
+		}
+		count, err := strconv.Atoi(p.token())
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad cross-reference table", ErrUnsupportedPDF)
+		}
+		for i := 0; i < count; i++ {
+			offset, err := strconv.Atoi(p.token())
+			p.token() // generation
+			kind := p.token()
+			if err != nil || (kind != "n" && kind != "f") {
+				return nil, fmt.Errorf("%w: bad cross-reference entry", ErrUnsupportedPDF)
+			}
+			if _, ok := f.offsets[start+i]; !ok && kind == "n" {
+				f.offsets[start+i] = offset
+			}
+		}
+	}
+
+	trailer, ok := p.value().(*pdfDict)
+	if !ok {
+		return nil, fmt.Errorf("%w: bad trailer", ErrUnsupportedPDF)
+	}
+	return trailer, nil
+}
+
+// dictObject reads an indirect object that holds a dictionary
+func (f *pdfFile) dictObject(ref pdfRef) (*pdfDict, error) {
+	offset, ok := f.offsets[ref.Num]
+	if !ok || offset >= len(f.data) {
+		return nil, fmt.Errorf("%w: object %d not found", ErrUnsupportedPDF, ref.Num)
+	}
+	p := &pdfParser{data: f.data, pos: offset}
+	if p.token() != strconv.Itoa(ref.Num) || p.token() != strconv.Itoa(ref.Gen) || p.token() != "obj" {
+		return nil, fmt.Errorf("%w: object %d not at its offset", ErrUnsupportedPDF, ref.Num)
+	}
+	dict, ok := p.value().(*pdfDict)
+	if !ok {
+		return nil, fmt.Errorf("%w: object %d is not a dictionary", ErrUnsupportedPDF, ref.Num)
+	}
+	return dict, nil
+}
+
+// firstPage finds the first page of the document by descending the page tree
+func (f *pdfFile) firstPage(catalog *pdfDict) (pdfRef, *pdfDict, error) {
+	ref, ok := catalog.get("Pages").(pdfRef)
+	for depth := 0; ok && depth < 32; depth++ {
+		node, err := f.dictObject(ref)
+		if err != nil {
+			return pdfRef{}, nil, err
+		}
+		if node.get("Type") == pdfRaw("/Page") {
+			return ref, node, nil
+		}
+		kids, _ := node.get("Kids").(pdfArray)
+		if len(kids) == 0 {
+			break
+		}
+		ref, ok = kids[0].(pdfRef)
+	}
+	return pdfRef{}, nil, fmt.Errorf("%w: no pages found", ErrUnsupportedPDF)
+}
+
+// pdfRaw is a PDF value kept exactly as written, such as a name, number or string
+type pdfRaw string
+
+// pdfRef refers to an indirect object
+type pdfRef struct {
+	Num int
+	Gen int
+}
+
+// pdfArray is a PDF array
+type pdfArray []any
+
+// pdfEntry is one key and value of a dictionary, with the key written without its slash
+type pdfEntry struct {
+	Key   string
+	Value any
+}
+
+// pdfDict is a PDF dictionary, keeping its entries in order
+type pdfDict struct {
+	entries []pdfEntry
+}
+
+// get returns the value of key, or nil if the dictionary doesn't have it
+func (d *pdfDict) get(key string) any {
+	for _, entry := range d.entries {
+		if entry.Key == key {
+			return entry.Value
+		}
+	}
+	return nil
+}
+
+// set replaces the value of key, or adds it at the end
+func (d *pdfDict) set(key string, value any) {
+	for i, entry := range d.entries {
+		if entry.Key == key {
+			d.entries[i].Value = value
+			return
+		}
+	}
+	d.entries = append(d.entries, pdfEntry{key, value})
+}
+
+// write writes the dictionary in PDF syntax
+func (d *pdfDict) write(out *bytes.Buffer) {
+	out.WriteString("<<")
+	for _, entry := range d.entries {
+		out.WriteString(" /" + entry.Key + " ")
+		writeValue(out, entry.Value)
+	}
+	out.WriteString(" >>")
+}
+
+// writeValue writes any parsed PDF value in PDF syntax
+func writeValue(out *bytes.Buffer, value any) {
+	switch v := value.(type) {
+	case *pdfDict:
+		v.write(out)
+	case pdfArray:
+		out.WriteString("[")
+		for i, item := range v {
+			if i > 0 {
+				out.WriteString(" ")
+			}
+			writeValue(out, item)
+		}
+		out.WriteString("]")
+	case pdfRef:
+		fmt.Fprintf(out, "%d %d R", v.Num, v.Gen)
+	case pdfRaw:
+		out.WriteString(string(v))
+	}
+}
+
+// pdfParser reads PDF tokens and values from a position in a file
+type pdfParser struct {
+	data []byte
+	pos  int
+}
+
+// isPDFSpace reports whether c is PDF whitespace
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+// isPDFDelimiter reports whether c ends a regular token
+func isPDFDelimiter(c byte) bool {
+	return isPDFSpace(c) || strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// token reads the next token, skipping whitespace and comments, or returns "" at the end
+func (p *pdfParser) token() string {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if isPDFSpace(c) {
+			p.pos++
+		} else if c == '%' {
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+		} else {
+			break
+		}
+	}
+	if p.pos >= len(p.data) {
+		return ""
+	}
+
+	start := p.pos
+	switch c := p.data[p.pos]; {
+	case c == '<' || c == '>':
+		if p.pos+1 < len(p.data) && p.data[p.pos+1] == c {
+			p.pos += 2
+		} else if c == '<' {
+			end := bytes.IndexByte(p.data[p.pos:], '>')
+			if end < 0 {
+				p.pos = len(p.data)
+			} else {
+				p.pos += end + 1
+			}
+		} else {
+			p.pos++
+		}
+	case c == '(':
+		depth := 0
+		for p.pos < len(p.data) {
+			switch p.data[p.pos] {
+			case '\\':
+				p.pos++
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			p.pos++
+			if depth == 0 {
+				break
+			}
+		}
+	case c == '[' || c == ']' || c == '{' || c == '}':
+		p.pos++
+	default:
+		p.pos++
+		for p.pos < len(p.data) && !isPDFDelimiter(p.data[p.pos]) {
+			p.pos++
+		}
+	}
+	return string(p.data[start:p.pos])
+}
+
+// value reads the next value: a dictionary, array, reference, or any other token as is
+func (p *pdfParser) value() any {
+	token := p.token()
+	switch token {
+	case "<<":
+		dict := &pdfDict{}
+		for {
+			key := p.token()
+			if key == ">>" || key == "" {
+				return dict
+			}
+			dict.entries = append(dict.entries, pdfEntry{strings.TrimPrefix(key, "/"), p.value()})
+		}
+	case "[":
+		array := pdfArray{}
+		for {
+			save := p.pos
+			if next := p.token(); next == "]" || next == "" {
+				return array
+			}
+			p.pos = save
+			array = append(array, p.value())
+		}
+	}
+
+	// A number may be the start of a reference such as "12 0 R"
+	if num, err := strconv.Atoi(token); err == nil {
+		save := p.pos
+		if gen, err := strconv.Atoi(p.token()); err == nil && p.token() == "R" {
+			return pdfRef{Num: num, Gen: gen}
+		}
+		p.pos = save
+	}
+	return pdfRaw(token)
+}
@@ -0,0 +1,76 @@
+// Package signing adds digital signatures to PDFs using a certificate and private key
+// stored in a PKCS#12 (.p12 or .pfx) file
+package signing
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"go.mozilla.org/pkcs7"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// ErrCertificateExpired is returned when signing with a certificate outside its validity period
+var ErrCertificateExpired = errors.New("signing: certificate is not valid at the signing time")
+
+// Signer signs documents with one certificate and its private key
+type Signer struct {
+	key         crypto.PrivateKey
+	certificate *x509.Certificate
+	chain       []*x509.Certificate
+}
+
+// LoadPKCS12 reads a signer from a PKCS#12 file protected by password
+func LoadPKCS12(path, password string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePKCS12(data, password)
+}
+
+// ParsePKCS12 reads a signer from the contents of a PKCS#12 file protected by password. Any
+// intermediate certificates in the file are included in signatures so they can be verified.
+func ParsePKCS12(data []byte, password string) (*Signer, error) {
+	key, certificate, chain, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("reading PKCS#12 certificate: %w", err)
+	}
+	return &Signer{key: key, certificate: certificate, chain: chain}, nil
+}
+
+// Name returns who signs, taken from the certificate's common name
+func (s *Signer) Name() string {
+	if name := s.certificate.Subject.CommonName; name != "" {
+		return name
+	}
+	return s.certificate.Subject.String()
+}
+
+// Certificate returns the certificate signatures are made with
+func (s *Signer) Certificate() *x509.Certificate {
+	return s.certificate
+}
+
+// signDetached returns a detached PKCS#7 signature of content, as PDF signatures use
+func (s *Signer) signDetached(content []byte, at time.Time) ([]byte, error) {
+	if at.Before(s.certificate.NotBefore) || at.After(s.certificate.NotAfter) {
+		return nil, ErrCertificateExpired
+	}
+
+	signedData, err := pkcs7.NewSignedData(content)
+	if err != nil {
+		return nil, err
+	}
+	signedData.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+	err = signedData.AddSignerChain(s.certificate, s.key, s.chain, pkcs7.SignerInfoConfig{})
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+	signedData.Detach()
+	return signedData.Finish()
+}
@@ -0,0 +1,161 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mozilla.org/pkcs7"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// testPKCS12 returns a PKCS#12 file holding a self-signed certificate for name
+func testPKCS12(t *testing.T, name string, notAfter time.Time, password string) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pfx, err := pkcs12.Modern.Encode(key, certificate, nil, password)
+	require.NoError(t, err)
+	return pfx
+}
+
+// testPDF builds a one page PDF with a correct cross-reference table, as Chrome writes them
+func testPDF() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R >>",
+		"<< /Length 44 >>\nstream\nBT /F1 12 Tf 72 712 Td (Invoice (1)) Tj ET\nendstream",
+		"<< /Title (Invoice) /Producer (Skia/PDF) >>",
+	}
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// verifySignedPDF checks the signature covers everything outside its own contents and
+// returns the certificate that made it
+func verifySignedPDF(t *testing.T, signed []byte) *x509.Certificate {
+	t.Helper()
+	match := regexp.MustCompile(`/ByteRange \[0 +(\d+) +(\d+) +(\d+)\]`).FindSubmatch(signed)
+	require.NotNil(t, match, "signature has a byte range")
+	var byteRange [3]int
+	for i := range byteRange {
+		byteRange[i], _ = strconv.Atoi(string(match[i+1]))
+	}
+	require.Equal(t, len(signed), byteRange[1]+byteRange[2], "byte range reaches the end of the file")
+
+	contents := bytes.TrimRight(signed[byteRange[0]+1:byteRange[1]-1], "0")
+	if len(contents)%2 == 1 {
+		contents = append(contents, '0')
+	}
+	der := make([]byte, len(contents)/2)
+	_, err := fmt.Sscanf(string(contents), "%x", &der)
+	require.NoError(t, err)
+
+	p7, err := pkcs7.Parse(der)
+	require.NoError(t, err)
+	p7.Content = append(append([]byte{}, signed[:byteRange[0]]...), signed[byteRange[1]:]...)
+	require.NoError(t, p7.Verify())
+	return p7.GetOnlySigner()
+}
+
+func TestSigner_SignPDF(t *testing.T) {
+	pfx := testPKCS12(t, "Jane Doe", time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC), "secret")
+	signer, err := ParsePKCS12(pfx, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", signer.Name())
+
+	original := testPDF()
+	at := time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC)
+
+	t.Run("appends a verifiable signature without changing the original", func(t *testing.T) {
+		signed, err := signer.SignPDF(original, "Invoice INV-0042", at)
+		require.NoError(t, err)
+
+		assert.True(t, bytes.HasPrefix(signed, original), "the original bytes are kept as they were")
+		certificate := verifySignedPDF(t, signed)
+		assert.Equal(t, "Jane Doe", certificate.Subject.CommonName)
+
+		update := string(signed[len(original):])
+		assert.Contains(t, update, "/SubFilter /adbe.pkcs7.detached")
+		assert.Contains(t, update, "/M (D:20240305143000Z)")
+		assert.Contains(t, update, "/Reason (Invoice INV-0042)")
+		assert.Contains(t, update, "/AcroForm << /Fields [7 0 R] /SigFlags 3 >>")
+		assert.Contains(t, update, "/Annots [7 0 R]")
+		assert.Contains(t, update, "/Size 8 /Prev ")
+		assert.Contains(t, update, "/Info 5 0 R")
+	})
+
+	t.Run("signs a PDF again as a further update", func(t *testing.T) {
+		once, err := signer.SignPDF(original, "First", at)
+		require.NoError(t, err)
+		_, err = signer.SignPDF(once, "Second", at)
+		assert.ErrorIs(t, err, ErrUnsupportedPDF, "a signed PDF already has form fields")
+	})
+
+	t.Run("refuses an expired certificate", func(t *testing.T) {
+		expired, err := ParsePKCS12(testPKCS12(t, "Old", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), "secret"), "secret")
+		require.NoError(t, err)
+		_, err = expired.SignPDF(original, "Invoice", at)
+		assert.ErrorIs(t, err, ErrCertificateExpired)
+	})
+
+	t.Run("refuses files that aren't classic PDFs", func(t *testing.T) {
+		_, err := signer.SignPDF([]byte("not a pdf"), "Invoice", at)
+		assert.ErrorIs(t, err, ErrUnsupportedPDF)
+	})
+
+	t.Run("escapes text and encodes non-ASCII as UTF-16", func(t *testing.T) {
+		assert.Equal(t, `(Invoice \(draft\))`, pdfText("Invoice (draft)"))
+		assert.Equal(t, "<FEFF00E9>", pdfText("é"))
+	})
+}
+
+func TestLoadPKCS12(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signing.p12")
+	require.NoError(t, os.WriteFile(path, testPKCS12(t, "Jane Doe", time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC), "secret"), 0600))
+
+	signer, err := LoadPKCS12(path, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", signer.Certificate().Subject.CommonName)
+
+	_, err = LoadPKCS12(path, "wrong")
+	assert.Error(t, err)
+	_, err = LoadPKCS12(filepath.Join(t.TempDir(), "missing.p12"), "secret")
+	assert.Error(t, err)
+}
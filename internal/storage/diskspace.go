@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrDiskFull is returned when a write would leave less free disk space than the quota's
+// minimum. SQLite can corrupt its file if the disk fills up part way through a write, so it
+// is better to refuse the work beforehand.
+var ErrDiskFull = errors.New("storage: not enough free disk space")
+
+// Quota is the free disk space, in bytes, below which writes are warned about and refused
+type Quota struct {
+	Warn uint64
+	Deny uint64
+	// FreeSpace measures the filesystem holding a directory. It defaults to the package's
+	// FreeSpace and can be replaced to test how low space is handled.
+	FreeSpace func(dir string) (uint64, error)
+}
+
+// LowSpace reports a directory whose filesystem is running out of room
+type LowSpace struct {
+	Dir  string
+	Free uint64
+}
+
+// String describes the shortage for warnings and logs
+func (l LowSpace) String() string {
+	return fmt.Sprintf("only %d MB of disk space is left for %s", l.Free>>20, l.Dir)
+}
+
+// Check measures the free space left for each directory once need more bytes have been
+// written to it. It returns the directory with the least room left when that is below Warn,
+// and an error wrapping ErrDiskFull when it is below Deny. Directories whose space can't be
+// measured, such as on platforms without support, are skipped: the quota is a courtesy and
+// never stops work on its own account.
+func (q Quota) Check(need uint64, dirs ...string) (*LowSpace, error) {
+	measure := q.FreeSpace
+	if measure == nil {
+		measure = FreeSpace
+	}
+
+	var lowest *LowSpace
+	for _, dir := range dirs {
+		free, err := measure(existingParent(dir))
+		if err != nil {
+			continue
+		}
+		if free > need {
+			free -= need
+		} else {
+			free = 0
+		}
+		if lowest == nil || free < lowest.Free {
+			lowest = &LowSpace{Dir: dir, Free: free}
+		}
+	}
+
+	if lowest == nil || lowest.Free >= q.Warn {
+		return nil, nil
+	}
+	if lowest.Free < q.Deny {
+		return lowest, fmt.Errorf("%w: %s", ErrDiskFull, lowest)
+	}
+	return lowest, nil
+}
+
+// existingParent returns dir, or the nearest directory above it that exists, so that the
+// space of a directory that will be created on first use can still be measured
+func existingParent(dir string) string {
+	dir = filepath.Clean(dir)
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,68 @@
+// Package storage keeps files uploaded through the application, such as the company logo,
+// on the local filesystem.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidName is returned for names that would escape the storage directory
+var ErrInvalidName = errors.New("storage: invalid file name")
+
+// Local stores files in a directory on disk
+type Local struct {
+	Dir string
+}
+
+// Save writes data to a file called name in the storage directory, creating the directory
+// if needed, and returns the path it was written to
+func (l Local) Save(name string, data []byte) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", ErrInvalidName
+	}
+
+	err := os.MkdirAll(l.Dir, 0o755)
+	if err != nil {
+		return "", fmt.Errorf("storage: creating directory: %w", err)
+	}
+
+	// Write to a temporary file first so a failed upload never leaves a partial file behind
+	path := filepath.Join(l.Dir, name)
+	tmp, err := os.CreateTemp(l.Dir, ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("storage: creating file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("storage: writing file: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return "", fmt.Errorf("storage: saving file: %w", err)
+	}
+
+	return path, nil
+}
+
+// Remove deletes a file saved in the storage directory, given the path Save returned. A file
+// that is already gone isn't an error.
+func (l Local) Remove(path string) error {
+	if filepath.Dir(path) != filepath.Clean(l.Dir) {
+		return ErrInvalidName
+	}
+	err := os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("storage: removing file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalSave(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "uploads")
+	store := Local{Dir: dir}
+
+	path, err := store.Save("logo.png", []byte("first"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "logo.png"), path)
+
+	path, err = store.Save("logo.png", []byte("second"))
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	for _, name := range []string{"", "../logo.png", "nested/logo.png", ".hidden"} {
+		_, err = store.Save(name, []byte("x"))
+		assert.ErrorIs(t, err, ErrInvalidName, name)
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestLocalRemove(t *testing.T) {
+	dir := t.TempDir()
+	store := Local{Dir: dir}
+
+	path, err := store.Save("receipt.pdf", []byte("%PDF"))
+	require.NoError(t, err)
+	require.NoError(t, store.Remove(path))
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	assert.NoError(t, store.Remove(path), "removing a file twice is harmless")
+	assert.ErrorIs(t, store.Remove(filepath.Join(dir, "..", "other.pdf")), ErrInvalidName)
+}
+
+func TestQuotaCheck(t *testing.T) {
+	const mb = 1 << 20
+	free := map[string]uint64{}
+	quota := Quota{Warn: 500 * mb, Deny: 100 * mb, FreeSpace: func(dir string) (uint64, error) {
+		space, ok := free[dir]
+		if !ok {
+			return 0, errors.ErrUnsupported
+		}
+		return space, nil
+	}}
+	data, uploads := t.TempDir(), t.TempDir()
+
+	t.Run("plenty of space", func(t *testing.T) {
+		free[data], free[uploads] = 2000*mb, 900*mb
+		low, err := quota.Check(10*mb, data, uploads)
+		require.NoError(t, err)
+		assert.Nil(t, low)
+	})
+
+	t.Run("warns about the fullest disk", func(t *testing.T) {
+		free[data], free[uploads] = 450*mb, 300*mb
+		low, err := quota.Check(10*mb, data, uploads)
+		require.NoError(t, err)
+		require.NotNil(t, low)
+		assert.Equal(t, uploads, low.Dir)
+		assert.Equal(t, uint64(290*mb), low.Free, "the write itself is counted")
+		assert.Contains(t, low.String(), "only 290 MB of disk space is left")
+	})
+
+	t.Run("refuses a write that would go below the minimum", func(t *testing.T) {
+		free[data], free[uploads] = 2000*mb, 105*mb
+		low, err := quota.Check(10*mb, data, uploads)
+		assert.ErrorIs(t, err, ErrDiskFull)
+		require.NotNil(t, low)
+		assert.Equal(t, uploads, low.Dir)
+	})
+
+	t.Run("directories that don't exist yet are measured by their parent", func(t *testing.T) {
+		free[data] = 50 * mb
+		_, err := quota.Check(0, filepath.Join(data, "uploads", "logos"))
+		assert.ErrorIs(t, err, ErrDiskFull)
+	})
+
+	t.Run("space that can't be measured lets the write through", func(t *testing.T) {
+		low, err := quota.Check(10*mb, filepath.Join(t.TempDir(), "elsewhere"))
+		require.NoError(t, err)
+		assert.Nil(t, low)
+	})
+}
+
+func TestFreeSpace(t *testing.T) {
+	free, err := FreeSpace(t.TempDir())
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("free space can't be measured on this platform")
+	}
+	require.NoError(t, err)
+	assert.Greater(t, free, uint64(0))
+}
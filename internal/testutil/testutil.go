@@ -1,6 +1,7 @@
 package testutil
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
@@ -24,8 +25,8 @@ func SetupTestSQLite(t *testing.T) *TestDatabase {
 	tempDir := t.TempDir()
 	dbFile := filepath.Join(tempDir, "test.db")
 
-	// Open SQLite database
-	db, err := sql.Open("sqlite", dbFile)
+	// Open SQLite database, enforcing foreign keys and waiting for locks as the application does
+	db, err := sql.Open("sqlite", dbFile+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
 	require.NoError(t, err)
 
 	// Test the connection
@@ -55,6 +56,23 @@ func (td *TestDatabase) Cleanup(t *testing.T) {
 // createSchema creates the necessary tables for testing
 func createSchema(db *sql.DB) error {
 	schema := `
+		CREATE TABLE IF NOT EXISTS business_profile (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			freelancer_name TEXT NOT NULL DEFAULT '',
+			freelancer_address TEXT NOT NULL DEFAULT '',
+			freelancer_city_state_zip TEXT NOT NULL DEFAULT '',
+			freelancer_phone TEXT NOT NULL DEFAULT '',
+			freelancer_email TEXT NOT NULL DEFAULT '',
+			logo_path TEXT NOT NULL DEFAULT '',
+			bank_details TEXT NOT NULL DEFAULT '',
+			invoice_prefix TEXT NOT NULL DEFAULT '',
+			next_invoice_number INTEGER NOT NULL DEFAULT 1,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			deleted_at DATETIME NULL
+		);
+		
 		CREATE TABLE IF NOT EXISTS client (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			name TEXT NOT NULL,
@@ -66,8 +84,8 @@ func createSchema(db *sql.DB) error {
 			city TEXT,
 			state TEXT,
 			zip_code TEXT,
+			country TEXT,
 			hourly_rate DECIMAL(10,2) NOT NULL DEFAULT 0.00,
-			notes TEXT,
 			additional_info TEXT,
 			additional_info2 TEXT,
 			bill_to TEXT,
@@ -75,6 +93,19 @@ func createSchema(db *sql.DB) error {
 			invoice_cc_email TEXT,
 			invoice_cc_description TEXT,
 			university_affiliation TEXT,
+			business_profile_id INTEGER REFERENCES business_profile(id),
+			monthly_hour_allowance REAL,
+			prepay_only BOOLEAN NOT NULL DEFAULT false,
+			consolidated_invoicing BOOLEAN NOT NULL DEFAULT false,
+			fiscal_year_end TEXT,
+			po_number TEXT,
+			po_valid_from TEXT,
+			po_valid_to TEXT,
+			pipeline_stage TEXT,
+			invoice_language TEXT,
+			invoice_prefix TEXT,
+			next_invoice_number INTEGER,
+			budget_code TEXT,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			deleted_at DATETIME NULL
@@ -101,6 +132,10 @@ func createSchema(db *sql.DB) error {
 			currency_conversion_rate REAL NOT NULL DEFAULT 1.00000,
 			flat_fee_invoice INTEGER NOT NULL DEFAULT 0,
 			notes TEXT,
+			business_profile_id INTEGER REFERENCES business_profile(id),
+			estimated_hours REAL,
+			estimated_amount REAL,
+			budget_code TEXT,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			deleted_at DATETIME NULL,
@@ -113,13 +148,29 @@ func createSchema(db *sql.DB) error {
 			work_date DATE NOT NULL,
 			hours_worked DECIMAL(5,2) NOT NULL,
 			hourly_rate REAL NOT NULL DEFAULT 0.00,
+			cost_rate REAL,
 			description VARCHAR(255),
+			user_id INTEGER,
+			service_id INTEGER REFERENCES service(id),
+			unit TEXT NOT NULL DEFAULT 'hour',
+			pending_approval BOOLEAN NOT NULL DEFAULT false,
+			work_type TEXT NOT NULL DEFAULT '',
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			deleted_at DATETIME NULL,
 			FOREIGN KEY (project_id) REFERENCES project(id)
 		);
 		
+		CREATE TABLE IF NOT EXISTS service (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			default_rate REAL NOT NULL DEFAULT 0.00,
+			unit TEXT NOT NULL DEFAULT 'hour' CHECK (unit IN ('hour', 'word', 'page')),
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			deleted_at DATETIME NULL
+		);
+		
 		CREATE TABLE IF NOT EXISTS invoice (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			project_id INTEGER NOT NULL,
@@ -128,12 +179,311 @@ func createSchema(db *sql.DB) error {
 			payment_terms TEXT NOT NULL,
 			amount_due DECIMAL(10,2) NOT NULL,
 			display_details BOOLEAN NOT NULL DEFAULT false,
+			invoice_number TEXT,
+			voided_at DATETIME,
+			void_reason TEXT,
+			amount_paid REAL,
+			credit_applied REAL NOT NULL DEFAULT 0.00,
+			due_date DATE,
+			cc_email TEXT,
+			timesheets_from DATE,
+			timesheets_to DATE,
+			hourly_rate REAL,
+			discount_percent REAL,
+			discount_reason TEXT,
+			adjustment_amount REAL,
+			adjustment_reason TEXT,
+			currency_display TEXT,
+			currency_conversion_rate REAL,
+			financials_captured_at DATETIME,
+			conversion_rate_source TEXT,
+			converted_at DATETIME,
+			pdf_signed_at DATETIME,
+			pdf_signed_by TEXT,
+			timesheets_changed_at DATETIME,
+			discount_amount REAL,
+			budget_code TEXT,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			deleted_at DATETIME NULL,
 			FOREIGN KEY (project_id) REFERENCES project(id)
 		);
 		
+		CREATE TABLE IF NOT EXISTS user (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			email TEXT NOT NULL,
+			hashed_password TEXT NOT NULL,
+			role TEXT NOT NULL DEFAULT 'subcontractor' CHECK (role IN ('owner', 'subcontractor')),
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			deleted_at DATETIME NULL
+		);
+		
+		CREATE TABLE IF NOT EXISTS user_preferences (
+			user_id INTEGER PRIMARY KEY REFERENCES user(id),
+			page_size INTEGER,
+			landing_page TEXT,
+			date_format TEXT,
+			theme TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS saved_view (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER REFERENCES user(id),
+			list TEXT NOT NULL,
+			name TEXT NOT NULL,
+			filter TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS payment_terms_preset (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			terms TEXT NOT NULL UNIQUE,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS invoice_project (
+			invoice_id INTEGER NOT NULL REFERENCES invoice(id),
+			project_id INTEGER NOT NULL REFERENCES project(id),
+			hours REAL NOT NULL DEFAULT 0,
+			amount REAL NOT NULL DEFAULT 0,
+			PRIMARY KEY (invoice_id, project_id)
+		);
+		
+		CREATE TABLE IF NOT EXISTS invoice_delivery (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			invoice_id INTEGER NOT NULL REFERENCES invoice(id),
+			recipients TEXT NOT NULL,
+			subject TEXT NOT NULL,
+			status TEXT NOT NULL,
+			smtp_response TEXT NOT NULL DEFAULT '',
+			tracking_token TEXT UNIQUE,
+			open_count INTEGER NOT NULL DEFAULT 0,
+			first_opened_at DATETIME,
+			last_opened_at DATETIME,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS user_session (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL REFERENCES user(id),
+			token TEXT NOT NULL UNIQUE,
+			remember_me BOOLEAN NOT NULL DEFAULT false,
+			user_agent TEXT NOT NULL DEFAULT '',
+			ip_address TEXT NOT NULL DEFAULT '',
+			expires_at DATETIME NOT NULL,
+			last_seen_at DATETIME NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS project_share (
+			project_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (project_id, user_id),
+			FOREIGN KEY (project_id) REFERENCES project(id),
+			FOREIGN KEY (user_id) REFERENCES user(id)
+		);
+		
+		CREATE TABLE IF NOT EXISTS client_note (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			client_id INTEGER NOT NULL REFERENCES client(id),
+			body TEXT NOT NULL,
+			pinned BOOLEAN NOT NULL DEFAULT false,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS client_credit (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			client_id INTEGER NOT NULL REFERENCES client(id),
+			invoice_id INTEGER REFERENCES invoice(id),
+			amount REAL NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS invoice_event (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			invoice_id INTEGER NOT NULL REFERENCES invoice(id),
+			event_type TEXT NOT NULL CHECK (event_type IN ('created', 'updated', 'paid', 'voided', 'deleted')),
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS pending_timesheet (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			project_id INTEGER NOT NULL REFERENCES project(id),
+			work_date DATE NOT NULL,
+			hours_worked REAL NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			source TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS milestone (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			project_id INTEGER NOT NULL REFERENCES project(id),
+			name TEXT NOT NULL,
+			due_date DATE,
+			fee REAL NOT NULL DEFAULT 0.00,
+			status TEXT NOT NULL DEFAULT 'Planned' CHECK (status IN ('Planned', 'In Progress', 'Complete', 'Invoiced')),
+			invoice_id INTEGER REFERENCES invoice(id),
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			deleted_at DATETIME NULL
+		);
+		
+		CREATE TABLE IF NOT EXISTS tag (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			color TEXT NOT NULL DEFAULT '#6c757d',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS client_tag (
+			client_id INTEGER NOT NULL REFERENCES client(id),
+			tag_id INTEGER NOT NULL REFERENCES tag(id),
+			PRIMARY KEY (client_id, tag_id)
+		);
+		
+		CREATE TABLE IF NOT EXISTS project_tag (
+			project_id INTEGER NOT NULL REFERENCES project(id),
+			tag_id INTEGER NOT NULL REFERENCES tag(id),
+			PRIMARY KEY (project_id, tag_id)
+		);
+		
+		CREATE TABLE IF NOT EXISTS checklist_item (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			tag_id INTEGER REFERENCES tag(id),
+			label TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS project_checklist (
+			project_id INTEGER NOT NULL REFERENCES project(id),
+			checklist_item_id INTEGER NOT NULL REFERENCES checklist_item(id),
+			checked_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (project_id, checklist_item_id)
+		);
+		
+		CREATE TABLE IF NOT EXISTS notification_sent (
+			kind TEXT NOT NULL,
+			subject_id INTEGER NOT NULL,
+			due_on TEXT NOT NULL,
+			sent_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (kind, subject_id, due_on)
+		);
+		
+		CREATE TABLE IF NOT EXISTS exchange_rate (
+			currency TEXT NOT NULL,
+			rate_date TEXT NOT NULL,
+			rate REAL NOT NULL,
+			source TEXT NOT NULL DEFAULT '',
+			fetched_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (currency, rate_date)
+		);
+		
+		CREATE TABLE IF NOT EXISTS client_project_defaults (
+			client_id INTEGER PRIMARY KEY REFERENCES client(id),
+			status TEXT NOT NULL DEFAULT 'Estimating',
+			currency_display TEXT NOT NULL DEFAULT 'USD',
+			currency_conversion_rate REAL NOT NULL DEFAULT 1.0,
+			discount_percent REAL,
+			discount_reason TEXT NOT NULL DEFAULT '',
+			flat_fee_invoice BOOLEAN NOT NULL DEFAULT FALSE,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS job (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind TEXT NOT NULL,
+			payload TEXT NOT NULL DEFAULT '{}',
+			status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'done', 'failed')),
+			result BLOB,
+			result_type TEXT NOT NULL DEFAULT '',
+			result_name TEXT NOT NULL DEFAULT '',
+			error TEXT NOT NULL DEFAULT '',
+			started_at DATETIME,
+			finished_at DATETIME,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS scheduled_task (
+			name TEXT PRIMARY KEY,
+			last_run_at DATETIME,
+			last_job_id INTEGER,
+			last_error TEXT NOT NULL DEFAULT '',
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS project_attachment (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			project_id INTEGER NOT NULL REFERENCES project(id),
+			filename TEXT NOT NULL,
+			content_type TEXT NOT NULL,
+			size INTEGER NOT NULL,
+			path TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS recent_visit (
+			user_id INTEGER NOT NULL REFERENCES user(id),
+			kind TEXT NOT NULL,
+			item_id INTEGER NOT NULL,
+			visit_count INTEGER NOT NULL DEFAULT 1,
+			last_visited_at DATETIME NOT NULL,
+			PRIMARY KEY (user_id, kind, item_id)
+		);
+		
+		CREATE TABLE IF NOT EXISTS external_id (
+			kind TEXT NOT NULL CHECK (kind IN ('client', 'project', 'invoice')),
+			item_id INTEGER NOT NULL,
+			source TEXT NOT NULL,
+			external_id TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (kind, item_id, source),
+			UNIQUE (kind, source, external_id)
+		);
+		
+		CREATE TABLE IF NOT EXISTS form_draft (
+			user_id INTEGER NOT NULL REFERENCES user(id),
+			form TEXT NOT NULL CHECK (form IN ('client', 'project')),
+			item_id INTEGER NOT NULL,
+			fields TEXT NOT NULL,
+			updated_at DATETIME NOT NULL,
+			PRIMARY KEY (user_id, form, item_id)
+		);
+		
+		CREATE TABLE IF NOT EXISTS client_approval (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			project_id INTEGER NOT NULL REFERENCES project(id),
+			token TEXT NOT NULL UNIQUE,
+			start_date DATE NOT NULL,
+			end_date DATE NOT NULL,
+			approved_at DATETIME NULL,
+			approved_by TEXT NULL,
+			approved_ip TEXT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
+		CREATE TABLE IF NOT EXISTS invoice_snapshot (
+			invoice_id INTEGER PRIMARY KEY REFERENCES invoice(id),
+			bill_to TEXT NOT NULL,
+			freelancer_name TEXT NOT NULL,
+			freelancer_address TEXT NOT NULL,
+			freelancer_city_state_zip TEXT NOT NULL,
+			freelancer_phone TEXT NOT NULL,
+			freelancer_email TEXT NOT NULL,
+			logo_data_url TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		
 		CREATE TABLE IF NOT EXISTS settings (
 			key TEXT PRIMARY KEY,
 			value TEXT NOT NULL,
@@ -149,19 +499,91 @@ func createSchema(db *sql.DB) error {
 			('freelancer_name', 'Your Name Here', 'string', 'Freelancer name for invoices'),
 			('freelancer_address', 'Your Address', 'string', 'Freelancer address for invoices'),
 			('freelancer_phone', 'Your Phone', 'string', 'Freelancer phone for invoices'),
-			('freelancer_email', 'your.email@example.com', 'string', 'Freelancer email for invoices');
+			('freelancer_email', 'your.email@example.com', 'string', 'Freelancer email for invoices'),
+			('company_logo_path', './ui/static/img/logo.png', 'string', 'Path to company logo file for invoices (PNG format recommended, displayed at 22.5mm width)'),
+			('invoice_max_pdf_pages', '10', 'int', 'Estimated page count above which printing an invoice PDF asks for confirmation first'),
+			('timesheet_description_max_length', '255', 'int', 'Longest timesheet description, in characters, that can be entered'),
+			('timesheet_work_types', 'Editing, Formatting, Consultation, Meeting', 'string', 'Kinds of work a timesheet entry can be logged as, separated by commas. Leave blank to stop asking for a work type'),
+			('invoice_group_by_work_type', 'false', 'bool', 'Group the timesheet entries listed on detailed invoices by work type, with a subtotal for each'),
+			('disk_space_warn_mb', '1024', 'int', 'Free disk space, in MB, below which uploads and PDF generation warn that the disk is filling up'),
+			('disk_space_min_mb', '100', 'int', 'Free disk space, in MB, that uploads and PDF generation must leave, so the database always has room to write'),
+			('invoice_reminder_interval_days', '7', 'int', 'Days after an invoice is emailed before a reminder can be sent for it from the overdue invoices page'),
+			('client_email_check', 'true', 'bool', 'Look up whether the domains of a client''s email addresses can receive mail when the client is saved, and warn about those that can''t'),
+			('date_format', 'YYYY-MM-DD', 'string', 'How dates are shown and entered in forms, lists and invoices: YYYY-MM-DD, DD.MM.YYYY or MM/DD/YYYY'),
+			('payment_domestic_currency', 'USD', 'string', 'Currency code that receives domestic bank transfer instructions'),
+			('payment_bank_name', '', 'string', 'Bank name shown in invoice payment instructions'),
+			('payment_account_name', '', 'string', 'Bank account holder name shown in invoice payment instructions'),
+			('payment_routing_number', '', 'string', 'Bank routing number for domestic payments'),
+			('payment_account_number', '', 'string', 'Bank account number for domestic payments'),
+			('payment_iban', '', 'string', 'IBAN for international payments'),
+			('payment_swift', '', 'string', 'SWIFT/BIC code for international payments'),
+			('payment_paypal_address', '', 'string', 'PayPal address shown in invoice payment instructions'),
+			('contacts_carddav_url', '', 'string', 'CardDAV address book URL for syncing client contact details'),
+			('contacts_carddav_username', '', 'string', 'CardDAV username'),
+			('contacts_carddav_password', '', 'string', 'CardDAV password or app-specific password'),
+			('contacts_google_access_token', '', 'string', 'Google People API OAuth access token for syncing client contact details. Google tokens expire after about an hour, so paste a fresh one before each sync'),
+			('api_key', '', 'string', 'Secret key for the JSON API, sent as a Bearer token or X-API-Key header. Leave blank to disable the API'),
+			('webhook_invoice_paid_url', '', 'string', 'URL that receives a JSON POST whenever an invoice is marked paid, e.g. a Zapier or Make catch hook'),
+			('inbound_email_signing_key', '', 'string', 'Mailgun HTTP webhook signing key used to verify inbound email. Leave blank to disable timesheet capture by email'),
+			('inbound_email_sender', '', 'string', 'Only email from this address is turned into pending timesheet entries'),
+			('pdf_signing_certificate', '', 'string', 'Path on the server to a PKCS#12 (.p12 or .pfx) certificate used to digitally sign invoice PDFs. Leave blank to disable signing'),
+			('pdf_signing_password', '', 'string', 'Password of the PDF signing certificate'),
+			('smtp_host', '', 'string', 'SMTP server invoices are emailed through, e.g. smtp.example.com. Leave blank to disable emailing'),
+			('smtp_port', '587', 'int', 'Port of the SMTP server. 465 uses TLS from the start; other ports upgrade with STARTTLS when offered'),
+			('smtp_username', '', 'string', 'Username for the SMTP server, if it requires logging in'),
+			('smtp_password', '', 'string', 'Password for the SMTP server'),
+			('email_open_tracking', 'false', 'bool', 'Embed an invisible image in invoice emails to record when they are opened'),
+			('invoice_rounding_increment', '0.01', 'string', 'Invoice amounts are rounded to the nearest 0.01, 0.05 or 1.00'),
+			('invoice_rounding_mode', 'total', 'string', 'Round only the final total of an invoice (total) or each of its lines before adding them up (line)'),
+			('slack_webhook_url', '', 'string', 'Slack incoming webhook URL that overdue invoice and deadline reminders are posted to. Leave blank to disable Slack alerts'),
+			('telegram_bot_token', '', 'string', 'Token of the Telegram bot that sends overdue invoice and deadline reminders, as given by @BotFather. Leave blank to disable Telegram alerts'),
+			('telegram_chat_id', '', 'string', 'Telegram chat the bot sends reminders to: a chat ID number or a channel @username'),
+			('deadline_reminder_days', '3', 'int', 'How many days before a project deadline a reminder is sent. 0 disables deadline reminders'),
+			('invoice_page_header_footer', 'true', 'bool', 'Print the header and footer below on every page of invoice PDFs'),
+			('invoice_page_header', 'Invoice #{invoice} · {client}', 'string', 'Header on every invoice PDF page. {invoice}, {client}, {date}, {page} and {pages} are replaced with the invoice number, client name, invoice date, page number and page count'),
+			('invoice_page_footer', 'Page {page} of {pages}', 'string', 'Footer on every invoice PDF page, with the same replacements as the header'),
+			('invoice_default_display_details', 'false', 'bool', 'Tick Display Details on new invoices'),
+			('invoice_default_date_today', 'true', 'bool', 'Fill in today''s date as the invoice date of new invoices'),
+			('invoice_default_payment_terms', '', 'string', 'Payment terms filled in on new invoices, such as one of the payment terms presets'),
+			('invoice_default_amount_auto', 'false', 'bool', 'Fill in the amount due of new invoices from the project''s approved timesheets not yet billed'),
+			('exchange_rate_url', '', 'string', 'Address of a Frankfurter compatible exchange rate service, such as https://api.frankfurter.app, that the rates of converted invoices are fetched from. Leave blank to use each project''s conversion rate'),
+			('schedule_consolidated_invoices', '0 0 1 * *', 'string', 'Cron schedule (minute hour day month weekday, in UTC) on which the previous month is invoiced for every client billed monthly. Leave blank to stop invoicing them automatically'),
+			('schedule_reminders', '0 0 * * *', 'string', 'Cron schedule (minute hour day month weekday, in UTC) on which overdue invoice and deadline reminders are sent. Leave blank to stop sending reminders'),
+			('schedule_aging_report', '0 6 1 * *', 'string', 'Cron schedule (minute hour day month weekday, in UTC) on which the invoice aging report is emailed to freelancer_email, with every unpaid invoice attached as CSV. Leave blank to stop sending it');
 	`
 
 	_, err := db.Exec(schema)
 	return err
 }
 
-// TruncateTable truncates the specified table for test cleanup
+// TruncateTable truncates the specified table for test cleanup. Foreign keys are enforced,
+// so the delete runs on a connection with them switched off to leave child rows alone.
 func (td *TestDatabase) TruncateTable(t *testing.T, tableName string) {
-	_, err := td.DB.Exec(fmt.Sprintf("DELETE FROM %s", tableName))
+	ctx := context.Background()
+	conn, err := td.DB.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF")
+	require.NoError(t, err)
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+
+	_, err = conn.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", tableName))
 	require.NoError(t, err)
 }
 
+// InsertTestBusinessProfile inserts a test business profile and returns its ID
+func (td *TestDatabase) InsertTestBusinessProfile(t *testing.T, name, invoicePrefix string) int {
+	result, err := td.DB.Exec("INSERT INTO business_profile (name, freelancer_name, invoice_prefix) VALUES (?, ?, ?)",
+		name, name, invoicePrefix)
+	require.NoError(t, err)
+
+	id, err := result.LastInsertId()
+	require.NoError(t, err)
+
+	return int(id)
+}
+
 // InsertTestClient inserts a test client and returns its ID
 func (td *TestDatabase) InsertTestClient(t *testing.T, name string) int {
 	result, err := td.DB.Exec("INSERT INTO client (name, email, hourly_rate) VALUES (?, ?, ?)",
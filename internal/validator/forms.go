@@ -0,0 +1,76 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateParser reads dates typed into forms. Its string form names the format, as
+// models.DateFormat does, for use in error messages.
+type DateParser interface {
+	Parse(value string) (time.Time, error)
+}
+
+// Date parses a required date, recording "<label> must be in <format> format" against field
+// when it can't be read. A blank value is left to NotBlank to report.
+func (v *Validator) Date(value string, parser DateParser, field, label string) time.Time {
+	if strings.TrimSpace(value) == "" {
+		return time.Time{}
+	}
+	date, err := parser.Parse(value)
+	if err != nil {
+		v.AddFieldError(field, fmt.Sprintf("%s must be in %s format", label, parser))
+	}
+	return date
+}
+
+// OptionalDate parses a date which may be left blank, returning nil when it is blank or
+// can't be read
+func (v *Validator) OptionalDate(value string, parser DateParser, field, label string) *time.Time {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	date, err := parser.Parse(value)
+	if err != nil {
+		v.AddFieldError(field, fmt.Sprintf("%s must be in %s format", label, parser))
+		return nil
+	}
+	return &date
+}
+
+// Money parses a required amount, rate or quantity which can't be negative, recording
+// "<label> must be a positive number" against field when it isn't one
+func (v *Validator) Money(value, field, label string) float64 {
+	if strings.TrimSpace(value) == "" {
+		return 0
+	}
+	amount, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || !PositiveMoney(amount) {
+		v.AddFieldError(field, fmt.Sprintf("%s must be a positive number", label))
+		return 0
+	}
+	return amount
+}
+
+// OptionalMoney parses an amount which may be left blank, returning nil when it is blank or
+// isn't a positive number
+func (v *Validator) OptionalMoney(value, field, label string) *float64 {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	before := len(v.FieldErrors)
+	amount := v.Money(value, field, label)
+	if len(v.FieldErrors) > before {
+		return nil
+	}
+	return &amount
+}
+
+// CheckDateOrder records message against field when both dates are set and to falls before from
+func (v *Validator) CheckDateOrder(from, to *time.Time, field, message string) {
+	if from != nil && to != nil {
+		v.CheckField(NotBefore(*to, *from), field, message)
+	}
+}
@@ -1,13 +1,19 @@
 package validator
 
 import (
+	"fmt"
+	"html/template"
+	"math"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 	"unicode/utf8"
 )
 
 type Validator struct {
 	FieldErrors map[string]string
+	Warnings    map[string]string
 }
 
 func (v *Validator) Valid() bool {
@@ -30,6 +36,72 @@ func (v *Validator) CheckField(ok bool, field, message string) {
 	}
 }
 
+// FieldError is the error recorded against one field of a form
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ErrorSummary lists the form's field errors for the summary shown above it: those for the
+// given fields first, in the order given, then any others by field name. It takes a value
+// receiver so templates can call it on the form.
+func (v Validator) ErrorSummary(fields ...string) []FieldError {
+	if len(v.FieldErrors) == 0 {
+		return nil
+	}
+
+	summary := make([]FieldError, 0, len(v.FieldErrors))
+	listed := make(map[string]bool, len(v.FieldErrors))
+	for _, field := range fields {
+		if message, ok := v.FieldErrors[field]; ok && !listed[field] {
+			summary = append(summary, FieldError{Field: field, Message: message})
+			listed[field] = true
+		}
+	}
+
+	var others []string
+	for field := range v.FieldErrors {
+		if !listed[field] {
+			others = append(others, field)
+		}
+	}
+	slices.Sort(others)
+	for _, field := range others {
+		summary = append(summary, FieldError{Field: field, Message: v.FieldErrors[field]})
+	}
+	return summary
+}
+
+// Aria returns the attributes marking a form control invalid and tying it to its error
+// message, which templates label with the id "<field>-error". A valid control gets none.
+func (v Validator) Aria(field string) template.HTMLAttr {
+	if _, ok := v.FieldErrors[field]; !ok {
+		return ""
+	}
+	return template.HTMLAttr(fmt.Sprintf(`aria-invalid="true" aria-describedby="%s-error"`, template.HTMLEscapeString(field)))
+}
+
+// AddWarning records a soft warning which doesn't block saving once acknowledged
+func (v *Validator) AddWarning(field, message string) {
+	if v.Warnings == nil {
+		v.Warnings = make(map[string]string)
+	}
+
+	if _, exists := v.Warnings[field]; !exists {
+		v.Warnings[field] = message
+	}
+}
+
+func (v *Validator) CheckWarning(ok bool, field, message string) {
+	if !ok {
+		v.AddWarning(field, message)
+	}
+}
+
+func (v *Validator) HasWarnings() bool {
+	return len(v.Warnings) > 0
+}
+
 func NotBlank(value string) bool {
 	return strings.TrimSpace(value) != ""
 }
@@ -38,8 +110,42 @@ func MaxChars(value string, n int) bool {
 	return utf8.RuneCountInString(value) <= n
 }
 
+func MinChars(value string, n int) bool {
+	return utf8.RuneCountInString(value) >= n
+}
+
 var EmailRegex = regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}$`)
 
 func Matches(value string, rx *regexp.Regexp) bool {
 	return rx.MatchString(value)
 }
+
+// DecimalPlaces reports whether a number typed into a form has at most n digits after its
+// decimal point
+func DecimalPlaces(value string, n int) bool {
+	_, fraction, found := strings.Cut(strings.TrimSpace(value), ".")
+	return !found || len(fraction) <= n
+}
+
+// PositiveMoney reports whether an amount is a finite number that isn't negative. Zero is
+// allowed, as for a free entry or an invoice written off.
+func PositiveMoney(amount float64) bool {
+	return amount >= 0 && !math.IsInf(amount, 0) && !math.IsNaN(amount)
+}
+
+// NotBefore reports whether date falls on or after earliest
+func NotBefore(date, earliest time.Time) bool {
+	return !date.Before(earliest)
+}
+
+// DateBetween reports whether date falls within from and to, both included
+func DateBetween(date, from, to time.Time) bool {
+	return !date.Before(from) && !date.After(to)
+}
+
+// DateNotInFuture reports whether date falls on or before the day of now. Only the calendar
+// day is compared, so a date entered in a form is never in the future on its own day.
+func DateNotInFuture(date, now time.Time) bool {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, date.Location())
+	return !date.After(today)
+}
@@ -0,0 +1,118 @@
+package validator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// isoDates parses dates the way the ISO date format does
+type isoDates struct{}
+
+func (isoDates) Parse(value string) (time.Time, error) {
+	return time.Parse("2006-01-02", value)
+}
+
+func (isoDates) String() string {
+	return "YYYY-MM-DD"
+}
+
+func date(value string) time.Time {
+	d, _ := time.Parse("2006-01-02", value)
+	return d
+}
+
+func TestRules(t *testing.T) {
+	t.Run("DecimalPlaces", func(t *testing.T) {
+		assert.True(t, DecimalPlaces("12", 2))
+		assert.True(t, DecimalPlaces("12.5", 2))
+		assert.True(t, DecimalPlaces(" 12.50 ", 2))
+		assert.False(t, DecimalPlaces("12.505", 2))
+	})
+
+	t.Run("PositiveMoney", func(t *testing.T) {
+		assert.True(t, PositiveMoney(0))
+		assert.True(t, PositiveMoney(99.95))
+		assert.False(t, PositiveMoney(-0.01))
+		assert.False(t, PositiveMoney(math.Inf(1)))
+	})
+
+	t.Run("date ranges", func(t *testing.T) {
+		assert.True(t, NotBefore(date("2024-03-05"), date("2024-03-05")))
+		assert.False(t, NotBefore(date("2024-03-04"), date("2024-03-05")))
+		assert.True(t, DateBetween(date("2024-03-31"), date("2024-03-01"), date("2024-03-31")))
+		assert.False(t, DateBetween(date("2024-04-01"), date("2024-03-01"), date("2024-03-31")))
+	})
+
+	t.Run("DateNotInFuture compares calendar days", func(t *testing.T) {
+		now := time.Date(2024, 3, 5, 0, 30, 0, 0, time.FixedZone("CET", 3600))
+		assert.True(t, DateNotInFuture(date("2024-03-05"), now))
+		assert.False(t, DateNotInFuture(date("2024-03-06"), now))
+	})
+}
+
+func TestValidator_Parsing(t *testing.T) {
+	t.Run("dates", func(t *testing.T) {
+		var v Validator
+		assert.Equal(t, date("2024-03-05"), v.Date("2024-03-05", isoDates{}, "work_date", "Work date"))
+		assert.True(t, v.Date("", isoDates{}, "work_date", "Work date").IsZero())
+		assert.True(t, v.Valid(), "blank values are left to NotBlank")
+
+		v.Date("05.03.2024", isoDates{}, "work_date", "Work date")
+		assert.Equal(t, "Work date must be in YYYY-MM-DD format", v.FieldErrors["work_date"])
+
+		assert.Nil(t, v.OptionalDate("", isoDates{}, "date_paid", "Date paid"))
+		paid := v.OptionalDate("2024-03-06", isoDates{}, "date_paid", "Date paid")
+		require.NotNil(t, paid)
+		assert.Equal(t, date("2024-03-06"), *paid)
+	})
+
+	t.Run("money", func(t *testing.T) {
+		var v Validator
+		assert.Equal(t, 12.5, v.Money("12.5", "hourly_rate", "Hourly rate"))
+		assert.Zero(t, v.Money("-1", "hourly_rate", "Hourly rate"))
+		assert.Equal(t, "Hourly rate must be a positive number", v.FieldErrors["hourly_rate"])
+
+		assert.Nil(t, v.OptionalMoney("", "cost_rate", "Cost rate"))
+		assert.Nil(t, v.OptionalMoney("lots", "cost_rate", "Cost rate"))
+		assert.Equal(t, "Cost rate must be a positive number", v.FieldErrors["cost_rate"])
+		amount := v.OptionalMoney("40", "amount_paid", "Amount paid")
+		require.NotNil(t, amount)
+		assert.Equal(t, 40.0, *amount)
+	})
+
+	t.Run("CheckDateOrder", func(t *testing.T) {
+		var v Validator
+		from, to := date("2024-03-05"), date("2024-03-01")
+		v.CheckDateOrder(&from, nil, "timesheets_to", "Timesheets to can't be before timesheets from")
+		assert.True(t, v.Valid(), "an open ended range is fine")
+		v.CheckDateOrder(&from, &to, "timesheets_to", "Timesheets to can't be before timesheets from")
+		assert.Equal(t, "Timesheets to can't be before timesheets from", v.FieldErrors["timesheets_to"])
+	})
+}
+
+func TestValidator_ErrorSummary(t *testing.T) {
+	var v Validator
+	assert.Nil(t, v.ErrorSummary("name"))
+	assert.Empty(t, v.Aria("name"))
+
+	v.AddFieldError("zip_code", "Zip code is too long")
+	v.AddFieldError("name", "This field cannot be blank")
+	v.AddFieldError("email", "This field must be a valid email address")
+	v.AddFieldError("credentials", "Email or password is incorrect")
+
+	assert.Equal(t, []FieldError{
+		{Field: "name", Message: "This field cannot be blank"},
+		{Field: "email", Message: "This field must be a valid email address"},
+		{Field: "zip_code", Message: "Zip code is too long"},
+		{Field: "credentials", Message: "Email or password is incorrect"},
+	}, v.ErrorSummary("name", "email", "phone", "zip_code"), "errors follow the form's fields, others after them")
+
+	assert.Equal(t, "credentials", v.ErrorSummary()[0].Field, "without an order errors are listed by field")
+
+	assert.Equal(t, `aria-invalid="true" aria-describedby="email-error"`, string(v.Aria("email")))
+	assert.Empty(t, v.Aria("phone"))
+}
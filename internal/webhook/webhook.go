@@ -0,0 +1,51 @@
+// Package webhook delivers JSON notifications to URLs configured by the user, such as
+// the catch hooks provided by Zapier and Make.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultTimeout bounds how long a delivery may take when no client is configured
+const DefaultTimeout = 10 * time.Second
+
+// Sender posts JSON payloads to webhook URLs
+type Sender struct {
+	Client *http.Client
+}
+
+// Post sends payload as a JSON body to url. Any response outside the 2xx range is an error.
+func (s Sender) Post(ctx context.Context, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("webhook: encoding payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "FreelanceTracker-Webhook/1.0")
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: posting to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook: %s responded with %s", url, resp.Status)
+	}
+	return nil
+}
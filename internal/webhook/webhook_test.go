@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSenderPost(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusGone)
+		}
+	}))
+	defer server.Close()
+
+	sender := Sender{Client: server.Client()}
+
+	err := sender.Post(context.Background(), server.URL+"/hook", map[string]any{"id": 7, "event_type": "paid"})
+	require.NoError(t, err)
+	assert.Equal(t, "paid", received["event_type"])
+	assert.Equal(t, float64(7), received["id"])
+
+	err = sender.Post(context.Background(), server.URL+"/fail", map[string]any{"id": 8})
+	assert.ErrorContains(t, err, "410")
+}
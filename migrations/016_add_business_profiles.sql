@@ -0,0 +1,34 @@
+-- +goose Up
+-- Create business_profile table so invoices can be issued under more than one identity
+CREATE TABLE business_profile (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    freelancer_name TEXT NOT NULL DEFAULT '',
+    freelancer_address TEXT NOT NULL DEFAULT '',
+    freelancer_city_state_zip TEXT NOT NULL DEFAULT '',
+    freelancer_phone TEXT NOT NULL DEFAULT '',
+    freelancer_email TEXT NOT NULL DEFAULT '',
+    logo_path TEXT NOT NULL DEFAULT '',
+    bank_details TEXT NOT NULL DEFAULT '',
+    invoice_prefix TEXT NOT NULL DEFAULT '',
+    next_invoice_number INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME NULL
+);
+
+CREATE INDEX idx_business_profile_deleted_at ON business_profile(deleted_at);
+
+-- Allow a profile to be chosen per client, optionally overridden per project
+ALTER TABLE client ADD COLUMN business_profile_id INTEGER REFERENCES business_profile(id);
+ALTER TABLE project ADD COLUMN business_profile_id INTEGER REFERENCES business_profile(id);
+
+-- Invoice numbers are issued from the profile's numbering sequence
+ALTER TABLE invoice ADD COLUMN invoice_number TEXT;
+
+-- +goose Down
+ALTER TABLE invoice DROP COLUMN invoice_number;
+ALTER TABLE project DROP COLUMN business_profile_id;
+ALTER TABLE client DROP COLUMN business_profile_id;
+DROP INDEX IF EXISTS idx_business_profile_deleted_at;
+DROP TABLE IF EXISTS business_profile;
\ No newline at end of file
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Add structured bank and payment instruction settings for invoices.
+-- Domestic bank details (routing/account number) are shown for invoices in the
+-- domestic currency; IBAN/SWIFT details are shown for all other currencies.
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('payment_domestic_currency', 'USD', 'string', 'Currency code that receives domestic bank transfer instructions'),
+    ('payment_bank_name', '', 'string', 'Bank name shown in invoice payment instructions'),
+    ('payment_account_name', '', 'string', 'Bank account holder name shown in invoice payment instructions'),
+    ('payment_routing_number', '', 'string', 'Bank routing number for domestic payments'),
+    ('payment_account_number', '', 'string', 'Bank account number for domestic payments'),
+    ('payment_iban', '', 'string', 'IBAN for international payments'),
+    ('payment_swift', '', 'string', 'SWIFT/BIC code for international payments'),
+    ('payment_paypal_address', '', 'string', 'PayPal address shown in invoice payment instructions');
+
+-- +goose Down
+DELETE FROM settings WHERE key IN (
+    'payment_domestic_currency',
+    'payment_bank_name',
+    'payment_account_name',
+    'payment_routing_number',
+    'payment_account_number',
+    'payment_iban',
+    'payment_swift',
+    'payment_paypal_address'
+);
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Voided invoices stay on record for numbering continuity but are excluded from revenue
+ALTER TABLE invoice ADD COLUMN voided_at DATETIME;
+ALTER TABLE invoice ADD COLUMN void_reason TEXT;
+
+-- +goose Down
+ALTER TABLE invoice DROP COLUMN void_reason;
+ALTER TABLE invoice DROP COLUMN voided_at;
\ No newline at end of file
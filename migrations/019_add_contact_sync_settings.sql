@@ -0,0 +1,15 @@
+-- +goose Up
+-- Connection settings for importing client contact details from an external address book
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('contacts_carddav_url', '', 'string', 'CardDAV address book URL for syncing client contact details'),
+    ('contacts_carddav_username', '', 'string', 'CardDAV username'),
+    ('contacts_carddav_password', '', 'string', 'CardDAV password or app-specific password'),
+    ('contacts_google_access_token', '', 'string', 'Google People API OAuth access token for syncing client contact details');
+
+-- +goose Down
+DELETE FROM settings WHERE key IN (
+    'contacts_carddav_url',
+    'contacts_carddav_username',
+    'contacts_carddav_password',
+    'contacts_google_access_token'
+);
\ No newline at end of file
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Record the amount actually received and any client credit used to settle each invoice
+ALTER TABLE invoice ADD COLUMN amount_paid REAL;
+ALTER TABLE invoice ADD COLUMN credit_applied REAL NOT NULL DEFAULT 0.00;
+
+-- Ledger of client credit: overpayments add credit, applying it to an invoice uses it up
+CREATE TABLE client_credit (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    client_id INTEGER NOT NULL REFERENCES client(id),
+    invoice_id INTEGER REFERENCES invoice(id),
+    amount REAL NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_client_credit_client_id ON client_credit(client_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_client_credit_client_id;
+DROP TABLE IF EXISTS client_credit;
+ALTER TABLE invoice DROP COLUMN credit_applied;
+ALTER TABLE invoice DROP COLUMN amount_paid;
\ No newline at end of file
@@ -0,0 +1,33 @@
+-- +goose Up
+-- User accounts. Owners see everything; subcontractors only see projects shared with them.
+CREATE TABLE user (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    hashed_password TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'subcontractor' CHECK (role IN ('owner', 'subcontractor')),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME NULL
+);
+
+CREATE INDEX idx_user_email ON user(email);
+
+-- Projects a subcontractor has been given access to
+CREATE TABLE project_share (
+    project_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, user_id),
+    FOREIGN KEY (project_id) REFERENCES project(id),
+    FOREIGN KEY (user_id) REFERENCES user(id)
+);
+
+-- Who logged each timesheet entry, when it wasn't the owner working without an account
+ALTER TABLE timesheet ADD COLUMN user_id INTEGER REFERENCES user(id);
+
+-- +goose Down
+ALTER TABLE timesheet DROP COLUMN user_id;
+DROP TABLE IF EXISTS project_share;
+DROP INDEX IF EXISTS idx_user_email;
+DROP TABLE IF EXISTS user;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Optional internal cost of the time logged, e.g. what a subcontractor is paid, for margin reporting
+ALTER TABLE timesheet ADD COLUMN cost_rate REAL;
+
+-- +goose Down
+ALTER TABLE timesheet DROP COLUMN cost_rate;
\ No newline at end of file
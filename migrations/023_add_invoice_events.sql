@@ -0,0 +1,19 @@
+-- +goose Up
+-- Log of invoice lifecycle changes, read by no-code tools like Zapier and Make through the JSON API
+CREATE TABLE invoice_event (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    invoice_id INTEGER NOT NULL REFERENCES invoice(id),
+    event_type TEXT NOT NULL CHECK (event_type IN ('created', 'updated', 'paid', 'voided', 'deleted')),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_invoice_event_invoice_id ON invoice_event(invoice_id);
+
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('api_key', '', 'string', 'Secret key for the JSON API, sent as a Bearer token or X-API-Key header. Leave blank to disable the API'),
+    ('webhook_invoice_paid_url', '', 'string', 'URL that receives a JSON POST whenever an invoice is marked paid, e.g. a Zapier or Make catch hook');
+
+-- +goose Down
+DELETE FROM settings WHERE key IN ('api_key', 'webhook_invoice_paid_url');
+DROP INDEX IF EXISTS idx_invoice_event_invoice_id;
+DROP TABLE IF EXISTS invoice_event;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Cap on the hours a retainer client pays for each month
+ALTER TABLE client ADD COLUMN monthly_hour_allowance REAL;
+
+-- +goose Down
+ALTER TABLE client DROP COLUMN monthly_hour_allowance;
\ No newline at end of file
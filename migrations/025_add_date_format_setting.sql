@@ -0,0 +1,6 @@
+-- +goose Up
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('date_format', 'YYYY-MM-DD', 'string', 'How dates are shown and entered in forms, lists and invoices: YYYY-MM-DD, DD.MM.YYYY or MM/DD/YYYY');
+
+-- +goose Down
+DELETE FROM settings WHERE key = 'date_format';
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Optional due date, prefilled from the payment terms when an invoice is created
+ALTER TABLE invoice ADD COLUMN due_date DATE;
+
+-- +goose Down
+ALTER TABLE invoice DROP COLUMN due_date;
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Timesheet entries captured from inbound email, held until they are confirmed or discarded
+CREATE TABLE pending_timesheet (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INTEGER NOT NULL REFERENCES project(id),
+    work_date DATE NOT NULL,
+    hours_worked REAL NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('inbound_email_signing_key', '', 'string', 'Mailgun HTTP webhook signing key used to verify inbound email. Leave blank to disable timesheet capture by email'),
+    ('inbound_email_sender', '', 'string', 'Only email from this address is turned into pending timesheet entries');
+
+-- +goose Down
+DELETE FROM settings WHERE key IN ('inbound_email_signing_key', 'inbound_email_sender');
+DROP TABLE IF EXISTS pending_timesheet;
\ No newline at end of file
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Catalog of services billed by the hour, word or page, e.g. per-word proofreading alongside hourly editing
+CREATE TABLE service (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    default_rate REAL NOT NULL DEFAULT 0.00,
+    unit TEXT NOT NULL DEFAULT 'hour' CHECK (unit IN ('hour', 'word', 'page')),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME NULL
+);
+
+CREATE INDEX idx_service_deleted_at ON service(deleted_at);
+
+-- Timesheet entries may be for a service, in which case hours_worked holds the quantity in the
+-- entry's unit and hourly_rate the rate per unit. The unit is copied so that later changes to the
+-- service don't alter entries already logged.
+ALTER TABLE timesheet ADD COLUMN service_id INTEGER REFERENCES service(id);
+ALTER TABLE timesheet ADD COLUMN unit TEXT NOT NULL DEFAULT 'hour';
+
+-- +goose Down
+ALTER TABLE timesheet DROP COLUMN unit;
+ALTER TABLE timesheet DROP COLUMN service_id;
+DROP INDEX IF EXISTS idx_service_deleted_at;
+DROP TABLE IF EXISTS service;
\ No newline at end of file
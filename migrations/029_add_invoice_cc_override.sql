@@ -0,0 +1,6 @@
+-- +goose Up
+-- Optional CC address for one invoice, overriding the project's and client's invoice CC email
+ALTER TABLE invoice ADD COLUMN cc_email TEXT;
+
+-- +goose Down
+ALTER TABLE invoice DROP COLUMN cc_email;
\ No newline at end of file
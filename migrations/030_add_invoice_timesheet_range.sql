@@ -0,0 +1,12 @@
+-- +goose Up
+-- Optional work date range limiting which timesheet entries are listed on an invoice's PDF
+ALTER TABLE invoice ADD COLUMN timesheets_from DATE;
+ALTER TABLE invoice ADD COLUMN timesheets_to DATE;
+
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('invoice_max_pdf_pages', '10', 'int', 'Estimated page count above which printing an invoice PDF asks for confirmation first');
+
+-- +goose Down
+DELETE FROM settings WHERE key = 'invoice_max_pdf_pages';
+ALTER TABLE invoice DROP COLUMN timesheets_to;
+ALTER TABLE invoice DROP COLUMN timesheets_from;
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Long-running tasks such as PDF generation, processed in the background by the job queue
+CREATE TABLE job (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL,
+    payload TEXT NOT NULL DEFAULT '{}',
+    status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'done', 'failed')),
+    result BLOB,
+    result_type TEXT NOT NULL DEFAULT '',
+    result_name TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    started_at DATETIME,
+    finished_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_job_status ON job(status, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS job;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Clients who must pay up front, flagged so that new projects for them come with a warning
+ALTER TABLE client ADD COLUMN prepay_only BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE client DROP COLUMN prepay_only;
\ No newline at end of file
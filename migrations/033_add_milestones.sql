@@ -0,0 +1,21 @@
+-- +goose Up
+-- Phases of a project, e.g. for fixed-bid work billed in stages. A milestone invoiced directly
+-- keeps the invoice it was billed on.
+CREATE TABLE milestone (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INTEGER NOT NULL REFERENCES project(id),
+    name TEXT NOT NULL,
+    due_date DATE,
+    fee REAL NOT NULL DEFAULT 0.00,
+    status TEXT NOT NULL DEFAULT 'Planned' CHECK (status IN ('Planned', 'In Progress', 'Complete', 'Invoiced')),
+    invoice_id INTEGER REFERENCES invoice(id),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME NULL
+);
+
+CREATE INDEX idx_milestone_project_id ON milestone(project_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_milestone_project_id;
+DROP TABLE IF EXISTS milestone;
\ No newline at end of file
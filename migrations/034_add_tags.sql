@@ -0,0 +1,31 @@
+-- +goose Up
+-- Coloured labels for grouping clients and projects, e.g. by referral source or type of work
+CREATE TABLE tag (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    color TEXT NOT NULL DEFAULT '#6c757d',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE client_tag (
+    client_id INTEGER NOT NULL REFERENCES client(id),
+    tag_id INTEGER NOT NULL REFERENCES tag(id),
+    PRIMARY KEY (client_id, tag_id)
+);
+
+CREATE TABLE project_tag (
+    project_id INTEGER NOT NULL REFERENCES project(id),
+    tag_id INTEGER NOT NULL REFERENCES tag(id),
+    PRIMARY KEY (project_id, tag_id)
+);
+
+CREATE INDEX idx_client_tag_tag_id ON client_tag(tag_id);
+CREATE INDEX idx_project_tag_tag_id ON project_tag(tag_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_project_tag_tag_id;
+DROP INDEX IF EXISTS idx_client_tag_tag_id;
+DROP TABLE IF EXISTS project_tag;
+DROP TABLE IF EXISTS client_tag;
+DROP TABLE IF EXISTS tag;
\ No newline at end of file
@@ -0,0 +1,35 @@
+-- +goose Up
+-- The project's rates, discount, adjustment and currency as they stood when the invoice was
+-- created, so that editing the project later doesn't change invoices already issued. An invoice
+-- without financials_captured_at falls back to the project's current values.
+ALTER TABLE invoice ADD COLUMN hourly_rate REAL;
+ALTER TABLE invoice ADD COLUMN discount_percent REAL;
+ALTER TABLE invoice ADD COLUMN discount_reason TEXT;
+ALTER TABLE invoice ADD COLUMN adjustment_amount REAL;
+ALTER TABLE invoice ADD COLUMN adjustment_reason TEXT;
+ALTER TABLE invoice ADD COLUMN currency_display TEXT;
+ALTER TABLE invoice ADD COLUMN currency_conversion_rate REAL;
+ALTER TABLE invoice ADD COLUMN financials_captured_at DATETIME;
+
+-- Existing invoices can only take the project's values as they are now, which at least stops
+-- them drifting any further
+UPDATE invoice SET
+    hourly_rate = (SELECT p.hourly_rate FROM project p WHERE p.id = invoice.project_id),
+    discount_percent = (SELECT p.discount_percent FROM project p WHERE p.id = invoice.project_id),
+    discount_reason = (SELECT p.discount_reason FROM project p WHERE p.id = invoice.project_id),
+    adjustment_amount = (SELECT p.adjustment_amount FROM project p WHERE p.id = invoice.project_id),
+    adjustment_reason = (SELECT p.adjustment_reason FROM project p WHERE p.id = invoice.project_id),
+    currency_display = (SELECT p.currency_display FROM project p WHERE p.id = invoice.project_id),
+    currency_conversion_rate = (SELECT p.currency_conversion_rate FROM project p WHERE p.id = invoice.project_id),
+    financials_captured_at = CURRENT_TIMESTAMP
+WHERE EXISTS (SELECT 1 FROM project p WHERE p.id = invoice.project_id);
+
+-- +goose Down
+ALTER TABLE invoice DROP COLUMN financials_captured_at;
+ALTER TABLE invoice DROP COLUMN currency_conversion_rate;
+ALTER TABLE invoice DROP COLUMN currency_display;
+ALTER TABLE invoice DROP COLUMN adjustment_reason;
+ALTER TABLE invoice DROP COLUMN adjustment_amount;
+ALTER TABLE invoice DROP COLUMN discount_reason;
+ALTER TABLE invoice DROP COLUMN discount_percent;
+ALTER TABLE invoice DROP COLUMN hourly_rate;
\ No newline at end of file
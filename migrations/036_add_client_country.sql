@@ -0,0 +1,7 @@
+-- +goose Up
+-- ISO 3166 country code of the client's address, which decides how the address is laid out on
+-- invoices. Existing clients are left blank and keep the US layout they were printed with.
+ALTER TABLE client ADD COLUMN country TEXT;
+
+-- +goose Down
+ALTER TABLE client DROP COLUMN country;
\ No newline at end of file
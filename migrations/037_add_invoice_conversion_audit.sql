@@ -0,0 +1,13 @@
+-- +goose Up
+-- Where the conversion rate captured on an invoice came from and when it was applied, kept as
+-- an audit trail for invoices billed in a currency other than the domestic one
+ALTER TABLE invoice ADD COLUMN conversion_rate_source TEXT;
+ALTER TABLE invoice ADD COLUMN converted_at DATETIME;
+
+UPDATE invoice
+SET conversion_rate_source = 'project', converted_at = financials_captured_at
+WHERE financials_captured_at IS NOT NULL AND currency_conversion_rate IS NOT NULL AND currency_conversion_rate != 1;
+
+-- +goose Down
+ALTER TABLE invoice DROP COLUMN converted_at;
+ALTER TABLE invoice DROP COLUMN conversion_rate_source;
\ No newline at end of file
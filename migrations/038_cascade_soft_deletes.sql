@@ -0,0 +1,23 @@
+-- +goose Up
+-- Projects, timesheets, invoices and milestones used to stay active when the client or project
+-- they belong to was deleted. Delete them along with it, giving them its deleted_at timestamp
+-- the way the models now do, so that restoring the parent restores them too.
+UPDATE project
+SET deleted_at = (SELECT c.deleted_at FROM client c WHERE c.id = project.client_id)
+WHERE deleted_at IS NULL AND client_id IN (SELECT id FROM client WHERE deleted_at IS NOT NULL);
+
+UPDATE timesheet
+SET deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = timesheet.project_id)
+WHERE deleted_at IS NULL AND project_id IN (SELECT id FROM project WHERE deleted_at IS NOT NULL);
+
+UPDATE invoice
+SET deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = invoice.project_id)
+WHERE deleted_at IS NULL AND project_id IN (SELECT id FROM project WHERE deleted_at IS NOT NULL);
+
+UPDATE milestone
+SET deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = milestone.project_id)
+WHERE deleted_at IS NULL AND project_id IN (SELECT id FROM project WHERE deleted_at IS NOT NULL);
+
+-- +goose Down
+-- Rows deleted here can't be told apart from ones deleted with their parent since, so they
+-- are left deleted
\ No newline at end of file
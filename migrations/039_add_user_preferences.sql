@@ -0,0 +1,15 @@
+-- +goose Up
+-- Each user's own choices for how the application looks and behaves. Blank columns fall back
+-- to the global settings.
+CREATE TABLE user_preferences (
+    user_id INTEGER PRIMARY KEY REFERENCES user(id),
+    page_size INTEGER,
+    landing_page TEXT,
+    date_format TEXT,
+    theme TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_preferences;
\ No newline at end of file
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Optional digital signing of invoice PDFs with a PKCS#12 certificate, and a record of when
+-- each invoice's PDF was last signed and by whom
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('pdf_signing_certificate', '', 'string', 'Path on the server to a PKCS#12 (.p12 or .pfx) certificate used to digitally sign invoice PDFs. Leave blank to disable signing'),
+    ('pdf_signing_password', '', 'string', 'Password of the PDF signing certificate');
+ALTER TABLE invoice ADD COLUMN pdf_signed_at DATETIME;
+ALTER TABLE invoice ADD COLUMN pdf_signed_by TEXT;
+
+-- +goose Down
+ALTER TABLE invoice DROP COLUMN pdf_signed_by;
+ALTER TABLE invoice DROP COLUMN pdf_signed_at;
+DELETE FROM settings WHERE key IN (
+    'pdf_signing_certificate',
+    'pdf_signing_password'
+);
\ No newline at end of file
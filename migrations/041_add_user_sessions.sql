@@ -0,0 +1,21 @@
+-- +goose Up
+-- One row for each login, so that users can see where they are logged in and log out of their
+-- other sessions. The session data itself stays in the sessions table scs manages; a login whose
+-- row here is gone or has expired is logged out on its next request.
+CREATE TABLE user_session (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES user(id),
+    token TEXT NOT NULL UNIQUE,
+    remember_me BOOLEAN NOT NULL DEFAULT false,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    expires_at DATETIME NOT NULL,
+    last_seen_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX user_session_user_idx ON user_session (user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS user_session_user_idx;
+DROP TABLE IF EXISTS user_session;
\ No newline at end of file
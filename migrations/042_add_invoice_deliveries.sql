@@ -0,0 +1,36 @@
+-- +goose Up
+-- Emailing invoices through an SMTP server, and a log of every attempt to deliver one. When
+-- tracking is on, each delivery gets a token for the image that records when it is opened.
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('smtp_host', '', 'string', 'SMTP server invoices are emailed through, e.g. smtp.example.com. Leave blank to disable emailing'),
+    ('smtp_port', '587', 'int', 'Port of the SMTP server. 465 uses TLS from the start; other ports upgrade with STARTTLS when offered'),
+    ('smtp_username', '', 'string', 'Username for the SMTP server, if it requires logging in'),
+    ('smtp_password', '', 'string', 'Password for the SMTP server'),
+    ('email_open_tracking', 'false', 'bool', 'Embed an invisible image in invoice emails to record when they are opened');
+
+CREATE TABLE invoice_delivery (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    invoice_id INTEGER NOT NULL REFERENCES invoice(id),
+    recipients TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    status TEXT NOT NULL,
+    smtp_response TEXT NOT NULL DEFAULT '',
+    tracking_token TEXT UNIQUE,
+    open_count INTEGER NOT NULL DEFAULT 0,
+    first_opened_at DATETIME,
+    last_opened_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX invoice_delivery_invoice_idx ON invoice_delivery (invoice_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS invoice_delivery_invoice_idx;
+DROP TABLE IF EXISTS invoice_delivery;
+DELETE FROM settings WHERE key IN (
+    'smtp_host',
+    'smtp_port',
+    'smtp_username',
+    'smtp_password',
+    'email_open_tracking'
+);
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Clients billed once a month with a single invoice covering the unbilled time on all their
+-- projects, and the projects each such invoice covers with their share of its total
+ALTER TABLE client ADD COLUMN consolidated_invoicing BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE invoice_project (
+    invoice_id INTEGER NOT NULL REFERENCES invoice(id),
+    project_id INTEGER NOT NULL REFERENCES project(id),
+    hours REAL NOT NULL DEFAULT 0,
+    amount REAL NOT NULL DEFAULT 0,
+    PRIMARY KEY (invoice_id, project_id)
+);
+
+CREATE INDEX invoice_project_project_idx ON invoice_project (project_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS invoice_project_project_idx;
+DROP TABLE IF EXISTS invoice_project;
+ALTER TABLE client DROP COLUMN consolidated_invoicing;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Optional estimates of a project's hours and fee, tracked against the time actually logged
+ALTER TABLE project ADD COLUMN estimated_hours REAL;
+ALTER TABLE project ADD COLUMN estimated_amount REAL;
+
+-- +goose Down
+ALTER TABLE project DROP COLUMN estimated_amount;
+ALTER TABLE project DROP COLUMN estimated_hours;
\ No newline at end of file
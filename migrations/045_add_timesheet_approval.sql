@@ -0,0 +1,6 @@
+-- +goose Up
+-- Time logged by subcontractors waits for an owner's approval before it can be invoiced
+ALTER TABLE timesheet ADD COLUMN pending_approval BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE timesheet DROP COLUMN pending_approval;
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- +goose Up
+-- A client's notes become a list, with the important ones pinned to the top of the client
+-- page and the invoice form. Existing notes carry over as the first note of each client.
+CREATE TABLE client_note (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    client_id INTEGER NOT NULL REFERENCES client(id),
+    body TEXT NOT NULL,
+    pinned BOOLEAN NOT NULL DEFAULT false,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_client_note_client_id ON client_note(client_id);
+
+INSERT INTO client_note (client_id, body, created_at, updated_at)
+SELECT id, notes, updated_at, updated_at FROM client WHERE trim(COALESCE(notes, '')) != '' ORDER BY id;
+
+ALTER TABLE client DROP COLUMN notes;
+
+-- +goose Down
+ALTER TABLE client ADD COLUMN notes TEXT;
+
+UPDATE client SET notes = (
+    SELECT group_concat(body, char(10) || char(10))
+    FROM (SELECT n.body FROM client_note n WHERE n.client_id = client.id ORDER BY n.pinned DESC, n.id)
+);
+
+DROP INDEX IF EXISTS idx_client_note_client_id;
+DROP TABLE IF EXISTS client_note;
\ No newline at end of file
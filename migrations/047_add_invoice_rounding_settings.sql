@@ -0,0 +1,9 @@
+-- +goose Up
+-- How invoice totals are rounded: to the nearest cent, five cents or whole unit, applied
+-- either to the final total or to each line before they are added up.
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('invoice_rounding_increment', '0.01', 'string', 'Invoice amounts are rounded to the nearest 0.01, 0.05 or 1.00'),
+    ('invoice_rounding_mode', 'total', 'string', 'Round only the final total of an invoice (total) or each of its lines before adding them up (line)');
+
+-- +goose Down
+DELETE FROM settings WHERE key IN ('invoice_rounding_increment', 'invoice_rounding_mode');
\ No newline at end of file
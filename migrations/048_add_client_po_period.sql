@@ -0,0 +1,14 @@
+-- +goose Up
+-- A client's fiscal year end, as MM-DD, and the purchase order invoices are billed against,
+-- so that invoices dated outside the PO period or across a fiscal year end can be caught
+-- before the client rejects them.
+ALTER TABLE client ADD COLUMN fiscal_year_end TEXT;
+ALTER TABLE client ADD COLUMN po_number TEXT;
+ALTER TABLE client ADD COLUMN po_valid_from TEXT;
+ALTER TABLE client ADD COLUMN po_valid_to TEXT;
+
+-- +goose Down
+ALTER TABLE client DROP COLUMN po_valid_to;
+ALTER TABLE client DROP COLUMN po_valid_from;
+ALTER TABLE client DROP COLUMN po_number;
+ALTER TABLE client DROP COLUMN fiscal_year_end;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Filters saved under a name for the client and project lists. Views saved while nobody is
+-- logged in, before any accounts exist, have no user.
+CREATE TABLE saved_view (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER REFERENCES user(id),
+    list TEXT NOT NULL,
+    name TEXT NOT NULL,
+    filter TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_saved_view_user_list ON saved_view(user_id, list);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_saved_view_user_list;
+DROP TABLE IF EXISTS saved_view;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Payment terms offered on the invoice form, so common terms don't have to be retyped.
+-- Invoices keep their own copy of the terms, so removing a preset leaves them unchanged.
+CREATE TABLE payment_terms_preset (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    terms TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO payment_terms_preset (terms) VALUES
+    ('Due on receipt'),
+    ('Net 15'),
+    ('Net 30'),
+    ('Net 60'),
+    ('50% upfront, balance on completion');
+
+-- +goose Down
+DROP TABLE IF EXISTS payment_terms_preset;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Documents and hand-offs to keep track of on projects, such as a signed agreement or the
+-- final files. Items with a tag apply to projects of that type of work; items without one
+-- apply to every project.
+CREATE TABLE checklist_item (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tag_id INTEGER REFERENCES tag(id),
+    label TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- When each checklist item was ticked off on a project
+CREATE TABLE project_checklist (
+    project_id INTEGER NOT NULL REFERENCES project(id),
+    checklist_item_id INTEGER NOT NULL REFERENCES checklist_item(id),
+    checked_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, checklist_item_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS project_checklist;
+DROP TABLE IF EXISTS checklist_item;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Alerts for invoices falling overdue and project deadlines coming up, sent to Slack or
+-- Telegram, and a record of those sent so each is only sent once
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('slack_webhook_url', '', 'string', 'Slack incoming webhook URL that overdue invoice and deadline reminders are posted to. Leave blank to disable Slack alerts'),
+    ('telegram_bot_token', '', 'string', 'Token of the Telegram bot that sends overdue invoice and deadline reminders, as given by @BotFather. Leave blank to disable Telegram alerts'),
+    ('telegram_chat_id', '', 'string', 'Telegram chat the bot sends reminders to: a chat ID number or a channel @username'),
+    ('deadline_reminder_days', '3', 'int', 'How many days before a project deadline a reminder is sent. 0 disables deadline reminders');
+
+CREATE TABLE notification_sent (
+    kind TEXT NOT NULL,
+    subject_id INTEGER NOT NULL,
+    due_on TEXT NOT NULL,
+    sent_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (kind, subject_id, due_on)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_sent;
+DELETE FROM settings WHERE key IN ('slack_webhook_url', 'telegram_bot_token', 'telegram_chat_id', 'deadline_reminder_days');
@@ -0,0 +1,10 @@
+-- +goose Up
+-- A running header and footer on every page of invoice PDFs, so the later pages of a long
+-- invoice still show whose invoice they belong to
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('invoice_page_header_footer', 'true', 'bool', 'Print the header and footer below on every page of invoice PDFs'),
+    ('invoice_page_header', 'Invoice #{invoice} · {client}', 'string', 'Header on every invoice PDF page. {invoice}, {client}, {date}, {page} and {pages} are replaced with the invoice number, client name, invoice date, page number and page count'),
+    ('invoice_page_footer', 'Page {page} of {pages}', 'string', 'Footer on every invoice PDF page, with the same replacements as the header');
+
+-- +goose Down
+DELETE FROM settings WHERE key IN ('invoice_page_header_footer', 'invoice_page_header', 'invoice_page_footer');
@@ -0,0 +1,7 @@
+-- +goose Up
+-- When a timesheet entry billed on an invoice was last changed by overriding its lock, so the
+-- invoice can be flagged as needing to be regenerated until it is next saved
+ALTER TABLE invoice ADD COLUMN timesheets_changed_at DATETIME;
+
+-- +goose Down
+ALTER TABLE invoice DROP COLUMN timesheets_changed_at;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- A fixed discount given on an invoice, taking the place of the percentage discount it would
+-- otherwise carry over from its project
+ALTER TABLE invoice ADD COLUMN discount_amount REAL;
+
+-- +goose Down
+ALTER TABLE invoice DROP COLUMN discount_amount;
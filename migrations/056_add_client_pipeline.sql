@@ -0,0 +1,7 @@
+-- +goose Up
+-- A prospect's place in the sales pipeline: Lead, Proposal Sent, Won or Lost. Active clients
+-- have none; converting a prospect clears it, which allows projects to be created for it.
+ALTER TABLE client ADD COLUMN pipeline_stage TEXT;
+
+-- +goose Down
+ALTER TABLE client DROP COLUMN pipeline_stage;
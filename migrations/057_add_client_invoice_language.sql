@@ -0,0 +1,7 @@
+-- +goose Up
+-- Language code of the labels printed on the client's invoices, such as "de" or "fr". Clients
+-- without one keep the English labels they were invoiced with.
+ALTER TABLE client ADD COLUMN invoice_language TEXT;
+
+-- +goose Down
+ALTER TABLE client DROP COLUMN invoice_language;
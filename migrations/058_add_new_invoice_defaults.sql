@@ -0,0 +1,11 @@
+-- +goose Up
+-- How the form for a new invoice is filled in. The defaults keep the form as it was: dated
+-- today, without details, terms or an amount.
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('invoice_default_display_details', 'false', 'bool', 'Tick Display Details on new invoices'),
+    ('invoice_default_date_today', 'true', 'bool', 'Fill in today''s date as the invoice date of new invoices'),
+    ('invoice_default_payment_terms', '', 'string', 'Payment terms filled in on new invoices, such as one of the payment terms presets'),
+    ('invoice_default_amount_auto', 'false', 'bool', 'Fill in the amount due of new invoices from the project''s approved timesheets not yet billed');
+
+-- +goose Down
+DELETE FROM settings WHERE key IN ('invoice_default_display_details', 'invoice_default_date_today', 'invoice_default_payment_terms', 'invoice_default_amount_auto');
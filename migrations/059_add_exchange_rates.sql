@@ -0,0 +1,19 @@
+-- +goose Up
+-- Exchange rates fetched from a rates service, one per currency per day, so that invoices
+-- dated in the past are converted at the rate of their date. Rates are the units of the
+-- currency bought by one unit of the domestic currency.
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('exchange_rate_url', '', 'string', 'Address of a Frankfurter compatible exchange rate service, such as https://api.frankfurter.app, that the rates of converted invoices are fetched from. Leave blank to use each project''s conversion rate');
+
+CREATE TABLE exchange_rate (
+    currency TEXT NOT NULL,
+    rate_date TEXT NOT NULL,
+    rate REAL NOT NULL,
+    source TEXT NOT NULL DEFAULT '',
+    fetched_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (currency, rate_date)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS exchange_rate;
+DELETE FROM settings WHERE key = 'exchange_rate_url';
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Terms a client's new projects start from, kept apart from the client's own fields so a
+-- client can have its work billed differently from its contact and billing details
+CREATE TABLE client_project_defaults (
+    client_id INTEGER PRIMARY KEY REFERENCES client(id),
+    status TEXT NOT NULL DEFAULT 'Estimating',
+    currency_display TEXT NOT NULL DEFAULT 'USD',
+    currency_conversion_rate REAL NOT NULL DEFAULT 1.0,
+    discount_percent REAL,
+    discount_reason TEXT NOT NULL DEFAULT '',
+    flat_fee_invoice BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS client_project_defaults;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Recurring tasks run on cron schedules kept in settings. scheduled_task remembers when each
+-- last ran and the job it queued, so that a run missed while the server was down is made up
+-- when it starts again.
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('schedule_consolidated_invoices', '0 0 1 * *', 'string', 'Cron schedule (minute hour day month weekday, in UTC) on which the previous month is invoiced for every client billed monthly. Leave blank to stop invoicing them automatically'),
+    ('schedule_reminders', '0 0 * * *', 'string', 'Cron schedule (minute hour day month weekday, in UTC) on which overdue invoice and deadline reminders are sent. Leave blank to stop sending reminders');
+
+CREATE TABLE scheduled_task (
+    name TEXT PRIMARY KEY,
+    last_run_at DATETIME,
+    last_job_id INTEGER,
+    last_error TEXT NOT NULL DEFAULT '',
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS scheduled_task;
+DELETE FROM settings WHERE key IN ('schedule_consolidated_invoices', 'schedule_reminders');
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Supporting documents kept with a project, such as receipts, which can be sent along with
+-- its invoices. The files themselves are kept in the upload directory.
+CREATE TABLE project_attachment (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INTEGER NOT NULL REFERENCES project(id),
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    path TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_project_attachment_project_id ON project_attachment(project_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS project_attachment;
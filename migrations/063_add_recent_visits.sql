@@ -0,0 +1,14 @@
+-- +goose Up
+-- The clients and projects each user opens, and how often, so the command palette can offer
+-- the ones they use most
+CREATE TABLE recent_visit (
+    user_id INTEGER NOT NULL REFERENCES user(id),
+    kind TEXT NOT NULL,
+    item_id INTEGER NOT NULL,
+    visit_count INTEGER NOT NULL DEFAULT 1,
+    last_visited_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, kind, item_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS recent_visit;
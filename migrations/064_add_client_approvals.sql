@@ -0,0 +1,19 @@
+-- +goose Up
+-- Links a client can open without logging in to review a project's timesheets over a date
+-- range and approve them. Who approved, when and from which address are kept as a record.
+CREATE TABLE client_approval (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INTEGER NOT NULL REFERENCES project(id),
+    token TEXT NOT NULL UNIQUE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    approved_at DATETIME NULL,
+    approved_by TEXT NULL,
+    approved_ip TEXT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_client_approval_project_id ON client_approval(project_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS client_approval;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Who an invoice was billed to and from, and the logo printed on it, captured when its PDF
+-- is first generated so that reprinting it later doesn't pick up a changed address.
+CREATE TABLE invoice_snapshot (
+    invoice_id INTEGER PRIMARY KEY REFERENCES invoice(id),
+    bill_to TEXT NOT NULL,
+    freelancer_name TEXT NOT NULL,
+    freelancer_address TEXT NOT NULL,
+    freelancer_city_state_zip TEXT NOT NULL,
+    freelancer_phone TEXT NOT NULL,
+    freelancer_email TEXT NOT NULL,
+    logo_data_url TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS invoice_snapshot;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Timesheet descriptions are Markdown and may be longer than the 255 characters they were
+-- first limited to. SQLite doesn't enforce the column's declared length, so only the limit
+-- forms check against needs to change.
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('timesheet_description_max_length', '255', 'int', 'Longest timesheet description, in characters, that can be entered. Descriptions can use Markdown: **bold**, *italic*, `code`, and lists with lines starting "- " or "1. "');
+
+-- +goose Down
+DELETE FROM settings WHERE key = 'timesheet_description_max_length';
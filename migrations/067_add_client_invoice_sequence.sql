@@ -0,0 +1,9 @@
+-- +goose Up
+-- A client that needs its own sequential invoice numbers has a prefix and the next number
+-- in its sequence. Clients without a next number are numbered by their business profile.
+ALTER TABLE client ADD COLUMN invoice_prefix TEXT;
+ALTER TABLE client ADD COLUMN next_invoice_number INTEGER;
+
+-- +goose Down
+ALTER TABLE client DROP COLUMN next_invoice_number;
+ALTER TABLE client DROP COLUMN invoice_prefix;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Invoice and payment dates were written as Go timestamps, such as
+-- "2024-03-10 00:00:00 -0500 EST", which SQLite's date functions can't read and which could
+-- land on the neighbouring day once converted to another zone. Keep just the calendar date
+-- they were entered as.
+UPDATE invoice SET invoice_date = substr(invoice_date, 1, 10)
+WHERE typeof(invoice_date) = 'text' AND length(invoice_date) > 10;
+
+UPDATE invoice SET date_paid = substr(date_paid, 1, 10)
+WHERE typeof(date_paid) = 'text' AND length(date_paid) > 10;
+
+-- +goose Down
+-- Dates stored as YYYY-MM-DD read back the same as the timestamps they replaced, so there
+-- is nothing to undo.
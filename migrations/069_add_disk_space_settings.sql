@@ -0,0 +1,9 @@
+-- +goose Up
+-- SQLite can leave a corrupt file behind if the disk fills up in the middle of a write, so
+-- uploads and PDFs are checked against the space left for the database and upload folders.
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('disk_space_warn_mb', '1024', 'int', 'Free disk space, in MB, below which uploads and PDF generation warn that the disk is filling up'),
+    ('disk_space_min_mb', '100', 'int', 'Free disk space, in MB, that uploads and PDF generation must leave, so the database always has room to write');
+
+-- +goose Down
+DELETE FROM settings WHERE key IN ('disk_space_warn_mb', 'disk_space_min_mb');
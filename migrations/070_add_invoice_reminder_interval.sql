@@ -0,0 +1,8 @@
+-- +goose Up
+-- Overdue invoices can be emailed to their clients again in bulk. Those emailed more recently
+-- than this are skipped, so a client isn't sent the same reminder twice in a row.
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('invoice_reminder_interval_days', '7', 'int', 'Days after an invoice is emailed before a reminder can be sent for it from the overdue invoices page');
+
+-- +goose Down
+DELETE FROM settings WHERE key = 'invoice_reminder_interval_days';
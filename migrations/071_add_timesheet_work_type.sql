@@ -0,0 +1,13 @@
+-- +goose Up
+-- Each timesheet entry can say what kind of work it was, chosen from the list in settings, so
+-- that reports can total time by kind and invoices can list entries grouped by it. Entries
+-- logged before have no work type.
+ALTER TABLE timesheet ADD COLUMN work_type TEXT NOT NULL DEFAULT '';
+
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('timesheet_work_types', 'Editing, Formatting, Consultation, Meeting', 'string', 'Kinds of work a timesheet entry can be logged as, separated by commas. Leave blank to stop asking for a work type'),
+    ('invoice_group_by_work_type', 'false', 'bool', 'Group the timesheet entries listed on detailed invoices by work type, with a subtotal for each');
+
+-- +goose Down
+DELETE FROM settings WHERE key IN ('timesheet_work_types', 'invoice_group_by_work_type');
+ALTER TABLE timesheet DROP COLUMN work_type;
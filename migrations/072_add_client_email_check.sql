@@ -0,0 +1,8 @@
+-- +goose Up
+-- Saving a client can look up whether the domain of its email addresses receives mail, so a
+-- mistyped address is caught before an invoice sent to it bounces.
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('client_email_check', 'true', 'bool', 'Look up whether the domains of a client''s email addresses can receive mail when the client is saved, and warn about those that can''t');
+
+-- +goose Down
+DELETE FROM settings WHERE key = 'client_email_check';
@@ -0,0 +1,17 @@
+-- +goose Up
+-- The IDs clients, projects and invoices have in other systems, such as accounting software
+-- or the tool they were imported from, so that a sync can find what it created before instead
+-- of creating it again. Each record has at most one ID per source, and within a source an ID
+-- belongs to one record of each kind.
+CREATE TABLE external_id (
+    kind TEXT NOT NULL CHECK (kind IN ('client', 'project', 'invoice')),
+    item_id INTEGER NOT NULL,
+    source TEXT NOT NULL,
+    external_id TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (kind, item_id, source),
+    UNIQUE (kind, source, external_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS external_id;
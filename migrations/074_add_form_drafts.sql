@@ -0,0 +1,15 @@
+-- +goose Up
+-- Unsaved changes to the long client and project forms, kept as the user types so they can
+-- be restored after a browser crash or on another device. Item 0 is a form creating a new
+-- client or project.
+CREATE TABLE form_draft (
+    user_id INTEGER NOT NULL REFERENCES user(id),
+    form TEXT NOT NULL CHECK (form IN ('client', 'project')),
+    item_id INTEGER NOT NULL,
+    fields TEXT NOT NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, form, item_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS form_draft;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- A monthly invoice aging report, emailed to the freelancer with the detail attached as CSV
+INSERT INTO settings (key, value, data_type, description) VALUES 
+    ('schedule_aging_report', '0 6 1 * *', 'string', 'Cron schedule (minute hour day month weekday, in UTC) on which the invoice aging report is emailed to freelancer_email, with every unpaid invoice attached as CSV. Leave blank to stop sending it');
+
+-- +goose Down
+DELETE FROM settings WHERE key = 'schedule_aging_report';
@@ -0,0 +1,11 @@
+-- +goose Up
+-- The cost center or account code a client charges its invoices to, printed in the invoice
+-- header. A project's code is used in place of its client's, and an invoice's in place of both.
+ALTER TABLE client ADD COLUMN budget_code TEXT;
+ALTER TABLE project ADD COLUMN budget_code TEXT;
+ALTER TABLE invoice ADD COLUMN budget_code TEXT;
+
+-- +goose Down
+ALTER TABLE invoice DROP COLUMN budget_code;
+ALTER TABLE project DROP COLUMN budget_code;
+ALTER TABLE client DROP COLUMN budget_code;
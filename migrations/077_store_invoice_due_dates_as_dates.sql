@@ -0,0 +1,16 @@
+-- +goose Up
+-- Due dates and timesheet ranges were still written as Go timestamps, such as
+-- "2026-11-09 00:00:00 +0000 UTC", after invoice and payment dates moved to YYYY-MM-DD in
+-- 068. Keep just the calendar date they were entered as.
+UPDATE invoice SET due_date = substr(due_date, 1, 10)
+WHERE typeof(due_date) = 'text' AND length(due_date) > 10;
+
+UPDATE invoice SET timesheets_from = substr(timesheets_from, 1, 10)
+WHERE typeof(timesheets_from) = 'text' AND length(timesheets_from) > 10;
+
+UPDATE invoice SET timesheets_to = substr(timesheets_to, 1, 10)
+WHERE typeof(timesheets_to) = 'text' AND length(timesheets_to) > 10;
+
+-- +goose Down
+-- Dates stored as YYYY-MM-DD read back the same as the timestamps they replaced, so there
+-- is nothing to undo.
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Google's access tokens only last about an hour, so say so where the token is entered
+UPDATE settings
+SET description = 'Google People API OAuth access token for syncing client contact details. Google tokens expire after about an hour, so paste a fresh one before each sync'
+WHERE key = 'contacts_google_access_token';
+
+-- +goose Down
+UPDATE settings
+SET description = 'Google People API OAuth access token for syncing client contact details'
+WHERE key = 'contacts_google_access_token';
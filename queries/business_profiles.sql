@@ -0,0 +1,30 @@
+-- name: InsertBusinessProfile :execlastid
+INSERT INTO business_profile (name, freelancer_name, freelancer_address, freelancer_city_state_zip, freelancer_phone, freelancer_email, logo_path, bank_details, invoice_prefix, next_invoice_number) 
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetBusinessProfile :one
+SELECT id, name, freelancer_name, freelancer_address, freelancer_city_state_zip, freelancer_phone, freelancer_email, logo_path, bank_details, invoice_prefix, next_invoice_number, updated_at, created_at, deleted_at 
+FROM business_profile 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: GetAllBusinessProfiles :many
+SELECT id, name, freelancer_name, freelancer_address, freelancer_city_state_zip, freelancer_phone, freelancer_email, logo_path, bank_details, invoice_prefix, next_invoice_number, updated_at, created_at, deleted_at 
+FROM business_profile 
+WHERE deleted_at IS NULL
+ORDER BY name;
+
+-- name: UpdateBusinessProfile :exec
+UPDATE business_profile 
+SET name = ?, freelancer_name = ?, freelancer_address = ?, freelancer_city_state_zip = ?, freelancer_phone = ?, freelancer_email = ?, logo_path = ?, bank_details = ?, invoice_prefix = ?, next_invoice_number = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: ClaimBusinessProfileInvoiceNumber :one
+UPDATE business_profile 
+SET next_invoice_number = next_invoice_number + 1, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL
+RETURNING invoice_prefix, next_invoice_number;
+
+-- name: DeleteBusinessProfile :exec
+UPDATE business_profile 
+SET deleted_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
\ No newline at end of file
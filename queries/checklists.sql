@@ -0,0 +1,42 @@
+-- name: InsertChecklistItem :execlastid
+INSERT INTO checklist_item (tag_id, label)
+VALUES (?, ?);
+
+-- name: GetChecklistItems :many
+SELECT ci.id, ci.tag_id, CAST(COALESCE(t.name, '') AS TEXT) AS tag_name, ci.label, ci.created_at
+FROM checklist_item ci
+LEFT JOIN tag t ON t.id = ci.tag_id
+ORDER BY t.name, ci.id;
+
+-- name: DeleteChecklistItem :execrows
+DELETE FROM checklist_item
+WHERE id = ?;
+
+-- name: DeleteChecklistItemsByTag :exec
+DELETE FROM checklist_item
+WHERE tag_id = ?;
+
+-- name: DeleteProjectChecksByItem :exec
+DELETE FROM project_checklist
+WHERE checklist_item_id = ?;
+
+-- name: DeleteProjectChecksByTag :exec
+DELETE FROM project_checklist
+WHERE checklist_item_id IN (SELECT id FROM checklist_item WHERE tag_id = ?);
+
+-- name: GetProjectChecklist :many
+SELECT ci.id, ci.tag_id, CAST(COALESCE(t.name, '') AS TEXT) AS tag_name, ci.label, ci.created_at, pc.checked_at
+FROM project p
+JOIN checklist_item ci ON ci.tag_id IS NULL OR ci.tag_id IN (SELECT pt.tag_id FROM project_tag pt WHERE pt.project_id = p.id)
+LEFT JOIN tag t ON t.id = ci.tag_id
+LEFT JOIN project_checklist pc ON pc.checklist_item_id = ci.id AND pc.project_id = p.id
+WHERE p.id = ?
+ORDER BY ci.id;
+
+-- name: CheckProjectChecklistItem :exec
+INSERT OR IGNORE INTO project_checklist (project_id, checklist_item_id)
+VALUES (?, ?);
+
+-- name: UncheckProjectChecklistItem :exec
+DELETE FROM project_checklist
+WHERE project_id = ? AND checklist_item_id = ?;
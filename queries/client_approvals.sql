@@ -0,0 +1,28 @@
+-- name: InsertClientApproval :execlastid
+INSERT INTO client_approval (project_id, token, start_date, end_date)
+VALUES (?, ?, ?, ?);
+
+-- name: GetClientApproval :one
+SELECT id, project_id, token, start_date, end_date, approved_at, approved_by, approved_ip, created_at
+FROM client_approval
+WHERE id = ?;
+
+-- name: GetClientApprovalByToken :one
+SELECT id, project_id, token, start_date, end_date, approved_at, approved_by, approved_ip, created_at
+FROM client_approval
+WHERE token = ?;
+
+-- name: GetClientApprovalsByProject :many
+SELECT id, project_id, token, start_date, end_date, approved_at, approved_by, approved_ip, created_at
+FROM client_approval
+WHERE project_id = ?
+ORDER BY created_at DESC, id DESC;
+
+-- name: ApproveClientApproval :execrows
+UPDATE client_approval
+SET approved_at = ?, approved_by = ?, approved_ip = ?
+WHERE id = ? AND approved_at IS NULL;
+
+-- name: DeleteClientApproval :exec
+DELETE FROM client_approval
+WHERE id = ?;
@@ -0,0 +1,26 @@
+-- name: InsertClientCredit :execlastid
+INSERT INTO client_credit (client_id, invoice_id, amount, description) 
+VALUES (?, ?, ?, ?);
+
+-- name: GetClientCreditsByClient :many
+SELECT id, client_id, invoice_id, amount, description, created_at 
+FROM client_credit 
+WHERE client_id = ?
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetClientCreditBalance :one
+SELECT CAST(COALESCE(SUM(amount), 0) AS REAL) as balance
+FROM client_credit 
+WHERE client_id = ?;
+
+-- name: DeleteInvoiceOverpaymentCredit :exec
+DELETE FROM client_credit 
+WHERE invoice_id = ? AND amount > 0;
+-- name: DeleteInvoiceAppliedCredit :exec
+DELETE FROM client_credit 
+WHERE invoice_id = ? AND amount < 0;
+
+-- name: GetInvoiceOverpaymentCredit :many
+SELECT client_id, amount 
+FROM client_credit 
+WHERE invoice_id = ? AND amount > 0;
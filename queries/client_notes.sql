@@ -0,0 +1,34 @@
+-- name: InsertClientNote :execlastid
+INSERT INTO client_note (client_id, body, pinned) 
+VALUES (?, ?, ?);
+
+-- name: GetClientNote :one
+SELECT id, client_id, body, pinned, updated_at, created_at 
+FROM client_note 
+WHERE id = ?;
+
+-- name: GetClientNotesByClient :many
+SELECT id, client_id, body, pinned, updated_at, created_at 
+FROM client_note 
+WHERE client_id = ? 
+ORDER BY pinned DESC, created_at DESC, id DESC;
+
+-- name: GetPinnedClientNotes :many
+SELECT id, client_id, body, pinned, updated_at, created_at 
+FROM client_note 
+WHERE client_id = ? AND pinned = true 
+ORDER BY created_at DESC, id DESC;
+
+-- name: UpdateClientNote :exec
+UPDATE client_note 
+SET body = ?, pinned = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ?;
+
+-- name: SetClientNotePinned :exec
+UPDATE client_note 
+SET pinned = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ?;
+
+-- name: DeleteClientNote :exec
+DELETE FROM client_note 
+WHERE id = ?;
\ No newline at end of file
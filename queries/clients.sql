@@ -1,20 +1,20 @@
 -- name: InsertClient :execlastid
-INSERT INTO client (name, email, phone, address1, address2, address3, city, state, zip_code, hourly_rate, notes, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation) 
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+INSERT INTO client (name, email, phone, address1, address2, address3, city, state, zip_code, country, hourly_rate, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, business_profile_id, monthly_hour_allowance, prepay_only, consolidated_invoicing, fiscal_year_end, po_number, po_valid_from, po_valid_to, invoice_language, invoice_prefix, next_invoice_number, budget_code) 
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: GetClient :one
-SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, hourly_rate, notes, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, updated_at, created_at, deleted_at 
+SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, country, hourly_rate, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, business_profile_id, monthly_hour_allowance, prepay_only, consolidated_invoicing, fiscal_year_end, po_number, po_valid_from, po_valid_to, invoice_language, invoice_prefix, next_invoice_number, budget_code, pipeline_stage, updated_at, created_at, deleted_at 
 FROM client 
 WHERE id = ? AND deleted_at IS NULL;
 
 -- name: GetAllClients :many
-SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, hourly_rate, notes, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, updated_at, created_at, deleted_at 
+SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, country, hourly_rate, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, business_profile_id, monthly_hour_allowance, prepay_only, consolidated_invoicing, fiscal_year_end, po_number, po_valid_from, po_valid_to, invoice_language, invoice_prefix, next_invoice_number, budget_code, pipeline_stage, updated_at, created_at, deleted_at 
 FROM client 
 WHERE deleted_at IS NULL
 ORDER BY updated_at DESC;
 
 -- name: GetClientsWithPagination :many
-SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, hourly_rate, notes, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, updated_at, created_at, deleted_at 
+SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, country, hourly_rate, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, business_profile_id, monthly_hour_allowance, prepay_only, consolidated_invoicing, fiscal_year_end, po_number, po_valid_from, po_valid_to, invoice_language, invoice_prefix, next_invoice_number, budget_code, pipeline_stage, updated_at, created_at, deleted_at 
 FROM client 
 WHERE deleted_at IS NULL
 ORDER BY updated_at DESC
@@ -25,12 +25,53 @@ SELECT COUNT(*)
 FROM client 
 WHERE deleted_at IS NULL;
 
+-- name: GetClientsByTagWithPagination :many
+SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, country, hourly_rate, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, business_profile_id, monthly_hour_allowance, prepay_only, consolidated_invoicing, fiscal_year_end, po_number, po_valid_from, po_valid_to, invoice_language, invoice_prefix, next_invoice_number, budget_code, pipeline_stage, updated_at, created_at, deleted_at 
+FROM client 
+WHERE deleted_at IS NULL AND id IN (SELECT client_id FROM client_tag WHERE tag_id = ?)
+ORDER BY updated_at DESC
+LIMIT ? OFFSET ?;
+
+-- name: GetClientsByIDs :many
+SELECT id, name, email, phone, address1, address2, address3, city, state, zip_code, country, hourly_rate, additional_info, additional_info2, bill_to, include_address_on_invoice, invoice_cc_email, invoice_cc_description, university_affiliation, business_profile_id, monthly_hour_allowance, prepay_only, consolidated_invoicing, fiscal_year_end, po_number, po_valid_from, po_valid_to, invoice_language, invoice_prefix, next_invoice_number, budget_code, pipeline_stage, updated_at, created_at, deleted_at 
+FROM client 
+WHERE deleted_at IS NULL AND id IN (sqlc.slice(ids))
+ORDER BY updated_at DESC;
+
+-- name: GetClientsByTagCount :one
+SELECT COUNT(*) 
+FROM client 
+WHERE deleted_at IS NULL AND id IN (SELECT client_id FROM client_tag WHERE tag_id = ?);
+
 -- name: UpdateClient :exec
 UPDATE client 
-SET name = ?, email = ?, phone = ?, address1 = ?, address2 = ?, address3 = ?, city = ?, state = ?, zip_code = ?, hourly_rate = ?, notes = ?, additional_info = ?, additional_info2 = ?, bill_to = ?, include_address_on_invoice = ?, invoice_cc_email = ?, invoice_cc_description = ?, university_affiliation = ?, updated_at = CURRENT_TIMESTAMP 
+SET name = ?, email = ?, phone = ?, address1 = ?, address2 = ?, address3 = ?, city = ?, state = ?, zip_code = ?, country = ?, hourly_rate = ?, additional_info = ?, additional_info2 = ?, bill_to = ?, include_address_on_invoice = ?, invoice_cc_email = ?, invoice_cc_description = ?, university_affiliation = ?, business_profile_id = ?, monthly_hour_allowance = ?, prepay_only = ?, consolidated_invoicing = ?, fiscal_year_end = ?, po_number = ?, po_valid_from = ?, po_valid_to = ?, invoice_language = ?, invoice_prefix = ?, next_invoice_number = ?, budget_code = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: ClaimClientInvoiceNumber :one
+UPDATE client 
+SET next_invoice_number = next_invoice_number + 1 
+WHERE id = ? AND next_invoice_number IS NOT NULL AND deleted_at IS NULL
+RETURNING invoice_prefix, next_invoice_number;
+
+-- name: SetClientPipelineStage :exec
+UPDATE client 
+SET pipeline_stage = ?, updated_at = CURRENT_TIMESTAMP 
 WHERE id = ? AND deleted_at IS NULL;
 
 -- name: DeleteClient :exec
 UPDATE client 
 SET deleted_at = CURRENT_TIMESTAMP 
-WHERE id = ? AND deleted_at IS NULL;
\ No newline at end of file
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: RestoreClient :exec
+UPDATE client 
+SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NOT NULL;
+
+-- name: SearchClients :many
+SELECT id, name
+FROM client
+WHERE deleted_at IS NULL AND name LIKE sqlc.arg(pattern) ESCAPE '\'
+ORDER BY updated_at DESC, name
+LIMIT sqlc.arg(limit);
@@ -0,0 +1,34 @@
+-- name: GetUnbilledProjectTotals :many
+SELECT p.id AS project_id, p.name AS project_name,
+    CAST(COALESCE(SUM(CASE WHEN t.unit = 'hour' THEN t.hours_worked ELSE 0 END), 0) AS REAL) AS hours,
+    CAST(COALESCE(SUM(t.hours_worked * t.hourly_rate), 0) AS REAL) AS amount
+FROM timesheet t
+JOIN project p ON p.id = t.project_id
+WHERE p.client_id = sqlc.arg(client_id) AND p.deleted_at IS NULL AND t.deleted_at IS NULL AND t.pending_approval = false
+  AND substr(t.work_date, 1, 10) >= sqlc.arg(start_date) AND substr(t.work_date, 1, 10) < sqlc.arg(end_date)
+  AND NOT EXISTS (
+    SELECT 1 FROM invoice i
+    WHERE i.deleted_at IS NULL AND i.voided_at IS NULL
+      AND i.timesheets_from IS NOT NULL AND i.timesheets_to IS NOT NULL
+      AND substr(t.work_date, 1, 10) BETWEEN substr(i.timesheets_from, 1, 10) AND substr(i.timesheets_to, 1, 10)
+      AND (i.project_id = t.project_id OR i.id IN (SELECT ip.invoice_id FROM invoice_project ip WHERE ip.project_id = t.project_id))
+  )
+GROUP BY p.id, p.name
+ORDER BY p.name;
+
+-- name: InsertInvoiceProject :exec
+INSERT INTO invoice_project (invoice_id, project_id, hours, amount)
+VALUES (?, ?, ?, ?);
+
+-- name: GetInvoiceProjects :many
+SELECT ip.project_id, p.name AS project_name, ip.hours, ip.amount
+FROM invoice_project ip
+JOIN project p ON p.id = ip.project_id
+WHERE ip.invoice_id = ?
+ORDER BY p.name;
+
+-- name: GetConsolidatedInvoicingClientIDs :many
+SELECT id
+FROM client
+WHERE consolidated_invoicing = true AND deleted_at IS NULL
+ORDER BY name;
\ No newline at end of file
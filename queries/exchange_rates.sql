@@ -0,0 +1,11 @@
+-- name: SaveExchangeRate :exec
+INSERT INTO exchange_rate (currency, rate_date, rate, source)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (currency, rate_date) DO UPDATE SET rate = excluded.rate, source = excluded.source, fetched_at = CURRENT_TIMESTAMP;
+
+-- name: GetExchangeRate :one
+SELECT currency, rate_date, rate, source, fetched_at
+FROM exchange_rate
+WHERE currency = ? AND rate_date <= ?
+ORDER BY rate_date DESC
+LIMIT 1;
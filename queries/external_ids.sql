@@ -0,0 +1,19 @@
+-- name: SetExternalID :exec
+INSERT INTO external_id (kind, item_id, source, external_id)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (kind, item_id, source) DO UPDATE SET external_id = excluded.external_id, created_at = CURRENT_TIMESTAMP;
+
+-- name: GetExternalIDs :many
+SELECT kind, item_id, source, external_id, created_at
+FROM external_id
+WHERE kind = ? AND item_id = ?
+ORDER BY source;
+
+-- name: FindExternalID :one
+SELECT item_id
+FROM external_id
+WHERE kind = ? AND source = ? AND external_id = ?;
+
+-- name: DeleteExternalID :execrows
+DELETE FROM external_id
+WHERE kind = ? AND item_id = ? AND source = ?;
@@ -0,0 +1,13 @@
+-- name: SaveFormDraft :exec
+INSERT INTO form_draft (user_id, form, item_id, fields, updated_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (user_id, form, item_id) DO UPDATE SET fields = excluded.fields, updated_at = excluded.updated_at;
+
+-- name: GetFormDraft :one
+SELECT user_id, form, item_id, fields, updated_at
+FROM form_draft
+WHERE user_id = ? AND form = ? AND item_id = ?;
+
+-- name: DeleteFormDraft :exec
+DELETE FROM form_draft
+WHERE user_id = ? AND form = ? AND item_id = ?;
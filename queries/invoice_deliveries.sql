@@ -0,0 +1,16 @@
+-- name: InsertInvoiceDelivery :execlastid
+INSERT INTO invoice_delivery (invoice_id, recipients, subject, status, smtp_response, tracking_token)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetInvoiceDeliveries :many
+SELECT id, invoice_id, recipients, subject, status, smtp_response, tracking_token, open_count, first_opened_at, last_opened_at, created_at
+FROM invoice_delivery
+WHERE invoice_id = ?
+ORDER BY created_at DESC, id DESC;
+
+-- name: RecordInvoiceDeliveryOpen :execrows
+UPDATE invoice_delivery
+SET open_count = open_count + 1,
+    first_opened_at = COALESCE(first_opened_at, sqlc.arg(first_opened_at)),
+    last_opened_at = sqlc.arg(last_opened_at)
+WHERE tracking_token = sqlc.arg(tracking_token);
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- name: InsertInvoiceEvent :execlastid
+INSERT INTO invoice_event (invoice_id, event_type) 
+VALUES (?, ?);
+
+-- name: GetInvoiceEventsAfter :many
+SELECT e.id AS event_id, e.event_type, e.created_at AS occurred_at,
+       i.id, i.project_id, i.invoice_date, i.date_paid, i.payment_terms, i.amount_due, i.invoice_number,
+       i.voided_at, i.void_reason, i.amount_paid, i.credit_applied, i.due_date, i.updated_at, i.created_at, i.deleted_at,
+       p.name AS project_name, p.currency_display, p.client_id, c.name AS client_name,
+       i.hourly_rate, i.discount_percent, i.discount_amount, i.discount_reason, i.adjustment_amount, i.adjustment_reason,
+       i.currency_display AS invoice_currency_display, i.currency_conversion_rate, i.financials_captured_at, i.conversion_rate_source, i.converted_at
+FROM invoice_event e
+JOIN invoice i ON i.id = e.invoice_id
+JOIN project p ON p.id = i.project_id
+JOIN client c ON c.id = p.client_id
+WHERE e.id > ?
+ORDER BY e.id
+LIMIT ?;
\ No newline at end of file
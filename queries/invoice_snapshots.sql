@@ -0,0 +1,10 @@
+-- name: GetInvoiceSnapshot :one
+SELECT invoice_id, bill_to, freelancer_name, freelancer_address, freelancer_city_state_zip,
+    freelancer_phone, freelancer_email, logo_data_url, created_at
+FROM invoice_snapshot
+WHERE invoice_id = ?;
+
+-- name: InsertInvoiceSnapshot :exec
+INSERT OR IGNORE INTO invoice_snapshot (invoice_id, bill_to, freelancer_name, freelancer_address,
+    freelancer_city_state_zip, freelancer_phone, freelancer_email, logo_data_url)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
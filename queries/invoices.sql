@@ -3,21 +3,98 @@ INSERT INTO invoice (project_id, invoice_date, date_paid, payment_terms, amount_
 VALUES (?, ?, ?, ?, ?, ?);
 
 -- name: GetInvoice :one
-SELECT id, project_id, invoice_date, date_paid, payment_terms, amount_due, display_details, updated_at, created_at, deleted_at 
+SELECT id, project_id, invoice_date, date_paid, payment_terms, amount_due, display_details, invoice_number, voided_at, void_reason, amount_paid, credit_applied, due_date, cc_email, timesheets_from, timesheets_to, hourly_rate, discount_percent, discount_amount, discount_reason, adjustment_amount, adjustment_reason, currency_display, currency_conversion_rate, financials_captured_at, conversion_rate_source, converted_at, pdf_signed_at, pdf_signed_by, timesheets_changed_at, budget_code, updated_at, created_at, deleted_at 
 FROM invoice 
 WHERE id = ? AND deleted_at IS NULL;
 
 -- name: GetInvoicesByProject :many
-SELECT id, project_id, invoice_date, date_paid, payment_terms, amount_due, display_details, updated_at, created_at, deleted_at 
+SELECT id, project_id, invoice_date, date_paid, payment_terms, amount_due, display_details, invoice_number, voided_at, void_reason, amount_paid, credit_applied, due_date, cc_email, timesheets_from, timesheets_to, hourly_rate, discount_percent, discount_amount, discount_reason, adjustment_amount, adjustment_reason, currency_display, currency_conversion_rate, financials_captured_at, conversion_rate_source, converted_at, pdf_signed_at, pdf_signed_by, timesheets_changed_at, budget_code, updated_at, created_at, deleted_at 
 FROM invoice 
 WHERE project_id = ? AND deleted_at IS NULL
 ORDER BY invoice_date DESC, created_at DESC;
 
+-- name: GetInvoicesByProjectCount :one
+SELECT COUNT(*)
+FROM invoice
+WHERE project_id = ? AND deleted_at IS NULL;
+
+-- name: GetInvoicesByProjectWithPagination :many
+SELECT id, project_id, invoice_date, date_paid, payment_terms, amount_due, display_details, invoice_number, voided_at, void_reason, amount_paid, credit_applied, due_date, cc_email, timesheets_from, timesheets_to, hourly_rate, discount_percent, discount_amount, discount_reason, adjustment_amount, adjustment_reason, currency_display, currency_conversion_rate, financials_captured_at, conversion_rate_source, converted_at, pdf_signed_at, pdf_signed_by, timesheets_changed_at, budget_code, updated_at, created_at, deleted_at 
+FROM invoice 
+WHERE project_id = ? AND deleted_at IS NULL
+ORDER BY invoice_date DESC, created_at DESC, id DESC
+LIMIT ? OFFSET ?;
+
 -- name: UpdateInvoice :exec
 UPDATE invoice 
-SET invoice_date = ?, date_paid = ?, payment_terms = ?, amount_due = ?, display_details = ?, updated_at = CURRENT_TIMESTAMP 
+SET invoice_date = ?, date_paid = ?, payment_terms = ?, amount_due = ?, display_details = ?, timesheets_changed_at = NULL, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL AND voided_at IS NULL;
+
+-- name: SetInvoiceNumber :exec
+UPDATE invoice 
+SET invoice_number = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: SetInvoiceDueDate :exec
+UPDATE invoice 
+SET due_date = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: SetInvoiceCCEmail :exec
+UPDATE invoice 
+SET cc_email = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: SetInvoiceBudgetCode :exec
+UPDATE invoice 
+SET budget_code = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: SetInvoiceFinancials :exec
+UPDATE invoice 
+SET hourly_rate = ?, discount_percent = ?, discount_amount = ?, discount_reason = ?, adjustment_amount = ?, adjustment_reason = ?, currency_display = ?, currency_conversion_rate = ?, conversion_rate_source = ?, converted_at = ?, financials_captured_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: FlagInvoiceTimesheetsChanged :exec
+UPDATE invoice 
+SET timesheets_changed_at = CURRENT_TIMESTAMP 
 WHERE id = ? AND deleted_at IS NULL;
 
+-- name: SetInvoicePDFSignature :exec
+UPDATE invoice 
+SET pdf_signed_at = ?, pdf_signed_by = ? 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: SetInvoiceTimesheetRange :exec
+UPDATE invoice 
+SET timesheets_from = ?, timesheets_to = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: CountInvoicesWithNumber :one
+SELECT COUNT(*) FROM invoice
+WHERE invoice_number = ? AND deleted_at IS NULL;
+
+-- name: SetInvoicePayment :exec
+UPDATE invoice 
+SET amount_paid = ?, credit_applied = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: MarkInvoicePaid :execrows
+UPDATE invoice 
+SET date_paid = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND date_paid IS NULL AND deleted_at IS NULL AND voided_at IS NULL;
+
+-- name: VoidInvoice :execrows
+UPDATE invoice 
+SET voided_at = CURRENT_TIMESTAMP, void_reason = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL AND voided_at IS NULL;
+
+-- name: CountSimilarInvoices :one
+SELECT COUNT(*) FROM invoice
+WHERE project_id = sqlc.arg(project_id) AND abs(amount_due - sqlc.arg(amount_due)) < 0.005
+  AND abs(julianday(substr(invoice_date, 1, 10)) - julianday(sqlc.arg(invoice_date))) <= sqlc.arg(within_days)
+  AND deleted_at IS NULL AND voided_at IS NULL;
+
 -- name: DeleteInvoice :exec
 UPDATE invoice 
 SET deleted_at = CURRENT_TIMESTAMP 
@@ -25,8 +102,9 @@ WHERE id = ? AND deleted_at IS NULL;
 
 -- name: GetInvoiceForPDF :one
 SELECT 
-    i.id, i.project_id, i.invoice_date, i.date_paid, i.payment_terms, i.amount_due, i.display_details,
-    i.updated_at, i.created_at, i.deleted_at,
+    i.id, i.project_id, i.invoice_date, i.date_paid, i.payment_terms, i.amount_due, i.display_details, i.invoice_number, i.voided_at, i.void_reason, i.amount_paid, i.credit_applied, i.due_date,
+    i.timesheets_from, i.timesheets_to, i.hourly_rate, i.discount_percent, i.discount_amount, i.discount_reason, i.adjustment_amount, i.adjustment_reason,
+    i.currency_display, i.currency_conversion_rate, i.financials_captured_at, i.conversion_rate_source, i.converted_at, i.budget_code, i.updated_at, i.created_at, i.deleted_at,
     p.name as project_name,
     c.name as client_name
 FROM invoice i
@@ -36,7 +114,7 @@ WHERE i.id = ? AND i.deleted_at IS NULL;
 
 -- name: GetInvoiceComprehensiveForPDF :one
 SELECT 
-    i.id, i.project_id, i.invoice_date, i.date_paid, i.payment_terms, i.amount_due, i.display_details,
+    i.id, i.project_id, i.invoice_date, i.date_paid, i.payment_terms, i.amount_due, i.display_details, i.invoice_number, i.voided_at, i.void_reason, i.amount_paid, i.credit_applied, i.due_date,
     i.updated_at, i.created_at, i.deleted_at,
     p.name as project_name, p.status as project_status, p.hourly_rate as project_hourly_rate,
     p.discount_percent, p.discount_reason, p.adjustment_amount, p.adjustment_reason,
@@ -45,10 +123,74 @@ SELECT
     c.id as client_id, c.name as client_name, c.email as client_email,
     c.phone as client_phone, c.address1 as client_address1, c.address2 as client_address2, 
     c.address3 as client_address3, c.city as client_city, c.state as client_state, 
-    c.zip_code as client_zip_code, c.bill_to as client_bill_to,
+    c.zip_code as client_zip_code, c.country as client_country, c.bill_to as client_bill_to,
     c.include_address_on_invoice, c.university_affiliation,
     c.additional_info as client_additional_info, c.additional_info2 as client_additional_info2
 FROM invoice i
 JOIN project p ON i.project_id = p.id
 JOIN client c ON p.client_id = c.id
-WHERE i.id = ? AND i.deleted_at IS NULL;
\ No newline at end of file
+WHERE i.id = ? AND i.deleted_at IS NULL;
+
+-- name: GetInvoiceWithClient :one
+SELECT i.id, i.project_id, i.invoice_date, i.date_paid, i.payment_terms, i.amount_due, i.invoice_number,
+       i.voided_at, i.void_reason, i.amount_paid, i.credit_applied, i.due_date, i.updated_at, i.created_at, i.deleted_at,
+       p.name AS project_name, p.currency_display, p.client_id, c.name AS client_name,
+       i.hourly_rate, i.discount_percent, i.discount_amount, i.discount_reason, i.adjustment_amount, i.adjustment_reason,
+       i.currency_display AS invoice_currency_display, i.currency_conversion_rate, i.financials_captured_at, i.conversion_rate_source, i.converted_at
+FROM invoice i
+JOIN project p ON p.id = i.project_id
+JOIN client c ON c.id = p.client_id
+WHERE i.id = ? AND i.deleted_at IS NULL;
+
+-- name: GetInvoicesWithClientAfter :many
+SELECT i.id, i.project_id, i.invoice_date, i.date_paid, i.payment_terms, i.amount_due, i.invoice_number,
+       i.voided_at, i.void_reason, i.amount_paid, i.credit_applied, i.due_date, i.updated_at, i.created_at, i.deleted_at,
+       p.name AS project_name, p.currency_display, p.client_id, c.name AS client_name,
+       i.hourly_rate, i.discount_percent, i.discount_amount, i.discount_reason, i.adjustment_amount, i.adjustment_reason,
+       i.currency_display AS invoice_currency_display, i.currency_conversion_rate, i.financials_captured_at, i.conversion_rate_source, i.converted_at
+FROM invoice i
+JOIN project p ON p.id = i.project_id
+JOIN client c ON c.id = p.client_id
+WHERE i.id > ? AND i.deleted_at IS NULL
+ORDER BY i.id
+LIMIT ?;
+
+-- name: GetOpenInvoicesWithClient :many
+SELECT i.id, i.project_id, i.invoice_date, i.date_paid, i.payment_terms, i.amount_due, i.invoice_number,
+       i.voided_at, i.void_reason, i.amount_paid, i.credit_applied, i.due_date, i.updated_at, i.created_at, i.deleted_at,
+       p.name AS project_name, p.currency_display, p.client_id, c.name AS client_name,
+       i.hourly_rate, i.discount_percent, i.discount_amount, i.discount_reason, i.adjustment_amount, i.adjustment_reason,
+       i.currency_display AS invoice_currency_display, i.currency_conversion_rate, i.financials_captured_at, i.conversion_rate_source, i.converted_at
+FROM invoice i
+JOIN project p ON p.id = i.project_id
+JOIN client c ON c.id = p.client_id
+WHERE i.date_paid IS NULL AND i.voided_at IS NULL AND i.deleted_at IS NULL AND p.deleted_at IS NULL
+ORDER BY i.invoice_date, i.id;
+
+-- name: GetClientPaidInvoices :many
+SELECT i.invoice_date, i.due_date, i.date_paid, i.payment_terms
+FROM invoice i
+JOIN project p ON p.id = i.project_id
+WHERE p.client_id = ? AND i.date_paid IS NOT NULL AND i.deleted_at IS NULL AND i.voided_at IS NULL
+ORDER BY i.invoice_date;
+
+-- name: DeleteProjectInvoices :exec
+UPDATE invoice 
+SET deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = invoice.project_id) 
+WHERE project_id = ? AND deleted_at IS NULL;
+
+-- name: RestoreProjectInvoices :exec
+UPDATE invoice 
+SET deleted_at = NULL 
+WHERE project_id = ? AND deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = invoice.project_id);
+
+-- name: DeleteClientInvoices :exec
+UPDATE invoice 
+SET deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = invoice.project_id) 
+WHERE project_id IN (SELECT id FROM project WHERE client_id = ? AND deleted_at IS NOT NULL) AND deleted_at IS NULL;
+
+-- name: RestoreClientInvoices :exec
+UPDATE invoice 
+SET deleted_at = NULL 
+WHERE project_id IN (SELECT p.id FROM project p WHERE p.client_id = ? AND p.deleted_at = (SELECT c.deleted_at FROM client c WHERE c.id = p.client_id)) 
+  AND deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = invoice.project_id);
\ No newline at end of file
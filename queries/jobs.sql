@@ -0,0 +1,43 @@
+-- name: InsertJob :execlastid
+INSERT INTO job (kind, payload) 
+VALUES (?, ?);
+
+-- name: GetJob :one
+SELECT id, kind, payload, status, result, result_type, result_name, error, started_at, finished_at, created_at, updated_at
+FROM job 
+WHERE id = ?;
+
+-- name: GetNextQueuedJobID :one
+SELECT id FROM job 
+WHERE status = 'queued' 
+ORDER BY id 
+LIMIT 1;
+
+-- name: ClaimJob :execrows
+UPDATE job 
+SET status = 'running', started_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND status = 'queued';
+
+-- name: CompleteJob :exec
+UPDATE job 
+SET status = 'done', result = ?, result_type = ?, result_name = ?, finished_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ?;
+
+-- name: FailJob :exec
+UPDATE job 
+SET status = 'failed', error = ?, finished_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ?;
+
+-- name: RequeueRunningJobs :execrows
+UPDATE job 
+SET status = 'queued', started_at = NULL, updated_at = CURRENT_TIMESTAMP 
+WHERE status = 'running';
+
+-- name: DeleteFinishedJobsBefore :execrows
+DELETE FROM job 
+WHERE status IN ('done', 'failed') AND finished_at < ?;
+-- name: ListRecentJobs :many
+SELECT id, kind, status, error, started_at, finished_at, created_at
+FROM job 
+ORDER BY id DESC 
+LIMIT ?;
@@ -0,0 +1,55 @@
+-- name: InsertMilestone :execlastid
+INSERT INTO milestone (project_id, name, due_date, fee, status) 
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetMilestone :one
+SELECT id, project_id, name, due_date, fee, status, invoice_id, updated_at, created_at, deleted_at 
+FROM milestone 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: GetMilestonesByProject :many
+SELECT id, project_id, name, due_date, fee, status, invoice_id, updated_at, created_at, deleted_at 
+FROM milestone 
+WHERE project_id = ? AND deleted_at IS NULL
+ORDER BY due_date IS NULL, due_date, id;
+
+-- name: UpdateMilestone :exec
+UPDATE milestone 
+SET name = ?, due_date = ?, fee = ?, status = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: DeleteMilestone :exec
+UPDATE milestone 
+SET deleted_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: SetMilestoneInvoice :exec
+UPDATE milestone 
+SET invoice_id = ?, status = 'Invoiced', updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: ReleaseMilestonesForInvoice :exec
+UPDATE milestone 
+SET invoice_id = NULL, status = 'Complete', updated_at = CURRENT_TIMESTAMP 
+WHERE invoice_id = ?;
+
+-- name: DeleteProjectMilestones :exec
+UPDATE milestone 
+SET deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = milestone.project_id) 
+WHERE project_id = ? AND deleted_at IS NULL;
+
+-- name: RestoreProjectMilestones :exec
+UPDATE milestone 
+SET deleted_at = NULL 
+WHERE project_id = ? AND deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = milestone.project_id);
+
+-- name: DeleteClientMilestones :exec
+UPDATE milestone 
+SET deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = milestone.project_id) 
+WHERE project_id IN (SELECT id FROM project WHERE client_id = ? AND deleted_at IS NOT NULL) AND deleted_at IS NULL;
+
+-- name: RestoreClientMilestones :exec
+UPDATE milestone 
+SET deleted_at = NULL 
+WHERE project_id IN (SELECT p.id FROM project p WHERE p.client_id = ? AND p.deleted_at = (SELECT c.deleted_at FROM client c WHERE c.id = p.client_id)) 
+  AND deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = milestone.project_id);
\ No newline at end of file
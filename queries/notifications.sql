@@ -0,0 +1,7 @@
+-- name: InsertNotificationSent :execrows
+INSERT OR IGNORE INTO notification_sent (kind, subject_id, due_on)
+VALUES (?, ?, ?);
+
+-- name: DeleteNotificationSent :exec
+DELETE FROM notification_sent
+WHERE kind = ? AND subject_id = ? AND due_on = ?;
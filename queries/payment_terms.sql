@@ -0,0 +1,12 @@
+-- name: InsertPaymentTermsPreset :execlastid
+INSERT INTO payment_terms_preset (terms)
+VALUES (?);
+
+-- name: GetPaymentTermsPresets :many
+SELECT id, terms, created_at
+FROM payment_terms_preset
+ORDER BY id;
+
+-- name: DeletePaymentTermsPreset :execrows
+DELETE FROM payment_terms_preset
+WHERE id = ?;
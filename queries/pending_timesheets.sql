@@ -0,0 +1,22 @@
+-- name: InsertPendingTimesheet :execlastid
+INSERT INTO pending_timesheet (project_id, work_date, hours_worked, description, source) 
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetPendingTimesheet :one
+SELECT pt.id, pt.project_id, p.name as project_name, pt.work_date, pt.hours_worked,
+       pt.description, pt.source, pt.created_at
+FROM pending_timesheet pt
+JOIN project p ON pt.project_id = p.id
+WHERE pt.id = ?;
+
+-- name: GetAllPendingTimesheets :many
+SELECT pt.id, pt.project_id, p.name as project_name, pt.work_date, pt.hours_worked,
+       pt.description, pt.source, pt.created_at
+FROM pending_timesheet pt
+JOIN project p ON pt.project_id = p.id
+WHERE p.deleted_at IS NULL
+ORDER BY pt.work_date, pt.id;
+
+-- name: DeletePendingTimesheet :exec
+DELETE FROM pending_timesheet 
+WHERE id = ?;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- name: InsertProjectAttachment :execlastid
+INSERT INTO project_attachment (project_id, filename, content_type, size, path) 
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetProjectAttachment :one
+SELECT id, project_id, filename, content_type, size, path, created_at 
+FROM project_attachment 
+WHERE id = ?;
+
+-- name: GetProjectAttachmentsByProject :many
+SELECT id, project_id, filename, content_type, size, path, created_at 
+FROM project_attachment 
+WHERE project_id = ? 
+ORDER BY created_at DESC, id DESC;
+
+-- name: DeleteProjectAttachment :exec
+DELETE FROM project_attachment 
+WHERE id = ?;
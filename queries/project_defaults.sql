@@ -0,0 +1,20 @@
+-- name: GetClientProjectDefaults :one
+SELECT client_id, status, currency_display, currency_conversion_rate, discount_percent, discount_reason, flat_fee_invoice, updated_at
+FROM client_project_defaults
+WHERE client_id = ?;
+
+-- name: SaveClientProjectDefaults :exec
+INSERT INTO client_project_defaults (client_id, status, currency_display, currency_conversion_rate, discount_percent, discount_reason, flat_fee_invoice)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (client_id) DO UPDATE SET
+    status = excluded.status,
+    currency_display = excluded.currency_display,
+    currency_conversion_rate = excluded.currency_conversion_rate,
+    discount_percent = excluded.discount_percent,
+    discount_reason = excluded.discount_reason,
+    flat_fee_invoice = excluded.flat_fee_invoice,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteClientProjectDefaults :exec
+DELETE FROM client_project_defaults
+WHERE client_id = ?;
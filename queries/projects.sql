@@ -4,16 +4,18 @@ INSERT INTO project (
     invoice_cc_email, invoice_cc_description, schedule_comments,
     additional_info, additional_info2, discount_percent, discount_reason,
     adjustment_amount, adjustment_reason, currency_display, 
-    currency_conversion_rate, flat_fee_invoice, notes
+    currency_conversion_rate, flat_fee_invoice, notes, business_profile_id,
+    estimated_hours, estimated_amount, budget_code
 ) 
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: GetProject :one
 SELECT id, name, client_id, status, hourly_rate, deadline, scheduled_start,
        invoice_cc_email, invoice_cc_description, schedule_comments,
        additional_info, additional_info2, discount_percent, discount_reason,
        adjustment_amount, adjustment_reason, currency_display, 
-       currency_conversion_rate, flat_fee_invoice, notes,
+       currency_conversion_rate, flat_fee_invoice, notes, business_profile_id,
+       estimated_hours, estimated_amount, budget_code,
        updated_at, created_at, deleted_at 
 FROM project 
 WHERE id = ? AND deleted_at IS NULL;
@@ -23,7 +25,8 @@ SELECT id, name, client_id, status, hourly_rate, deadline, scheduled_start,
        invoice_cc_email, invoice_cc_description, schedule_comments,
        additional_info, additional_info2, discount_percent, discount_reason,
        adjustment_amount, adjustment_reason, currency_display, 
-       currency_conversion_rate, flat_fee_invoice, notes,
+       currency_conversion_rate, flat_fee_invoice, notes, business_profile_id,
+       estimated_hours, estimated_amount, budget_code,
        updated_at, created_at, deleted_at 
 FROM project 
 WHERE client_id = ? AND deleted_at IS NULL
@@ -35,10 +38,16 @@ SET name = ?, status = ?, hourly_rate = ?, deadline = ?, scheduled_start = ?,
     invoice_cc_email = ?, invoice_cc_description = ?, schedule_comments = ?,
     additional_info = ?, additional_info2 = ?, discount_percent = ?, discount_reason = ?,
     adjustment_amount = ?, adjustment_reason = ?, currency_display = ?, 
-    currency_conversion_rate = ?, flat_fee_invoice = ?, notes = ?,
+    currency_conversion_rate = ?, flat_fee_invoice = ?, notes = ?, business_profile_id = ?,
+    estimated_hours = ?, estimated_amount = ?, budget_code = ?,
     updated_at = CURRENT_TIMESTAMP 
 WHERE id = ? AND deleted_at IS NULL;
 
+-- name: SetProjectStatus :exec
+UPDATE project 
+SET status = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
+
 -- name: DeleteProject :exec
 UPDATE project 
 SET deleted_at = CURRENT_TIMESTAMP 
@@ -63,10 +72,20 @@ SELECT p.id, p.name, p.client_id, p.status, p.hourly_rate, p.deadline, p.schedul
        p.additional_info, p.additional_info2, p.discount_percent, p.discount_reason,
        p.adjustment_amount, p.adjustment_reason, p.currency_display, 
        p.currency_conversion_rate, p.flat_fee_invoice, p.notes,
+       p.estimated_hours, p.estimated_amount,
+       CAST(COALESCE(ts.hours, 0) AS REAL) AS actual_hours,
+       CAST(COALESCE(ts.amount, 0) AS REAL) AS actual_amount,
        p.updated_at, p.created_at, p.deleted_at,
        c.name as client_name
 FROM project p
 JOIN client c ON p.client_id = c.id
+LEFT JOIN (
+    SELECT project_id, SUM(CASE WHEN unit = 'hour' THEN hours_worked ELSE 0 END) AS hours,
+        SUM(hours_worked * hourly_rate) AS amount
+    FROM timesheet
+    WHERE deleted_at IS NULL
+    GROUP BY project_id
+) ts ON ts.project_id = p.id
 WHERE p.deleted_at IS NULL AND c.deleted_at IS NULL
 ORDER BY p.updated_at DESC
 LIMIT ? OFFSET ?;
@@ -75,4 +94,88 @@ LIMIT ? OFFSET ?;
 SELECT COUNT(*) 
 FROM project p
 JOIN client c ON p.client_id = c.id
-WHERE p.deleted_at IS NULL AND c.deleted_at IS NULL;
\ No newline at end of file
+WHERE p.deleted_at IS NULL AND c.deleted_at IS NULL;
+
+-- name: GetProjectsByTagWithClientPagination :many
+SELECT p.id, p.name, p.client_id, p.status, p.hourly_rate, p.deadline, p.scheduled_start,
+       p.invoice_cc_email, p.invoice_cc_description, p.schedule_comments,
+       p.additional_info, p.additional_info2, p.discount_percent, p.discount_reason,
+       p.adjustment_amount, p.adjustment_reason, p.currency_display, 
+       p.currency_conversion_rate, p.flat_fee_invoice, p.notes,
+       p.estimated_hours, p.estimated_amount,
+       CAST(COALESCE(ts.hours, 0) AS REAL) AS actual_hours,
+       CAST(COALESCE(ts.amount, 0) AS REAL) AS actual_amount,
+       p.updated_at, p.created_at, p.deleted_at,
+       c.name as client_name
+FROM project p
+JOIN client c ON p.client_id = c.id
+LEFT JOIN (
+    SELECT project_id, SUM(CASE WHEN unit = 'hour' THEN hours_worked ELSE 0 END) AS hours,
+        SUM(hours_worked * hourly_rate) AS amount
+    FROM timesheet
+    WHERE deleted_at IS NULL
+    GROUP BY project_id
+) ts ON ts.project_id = p.id
+WHERE p.deleted_at IS NULL AND c.deleted_at IS NULL
+  AND p.id IN (SELECT project_id FROM project_tag WHERE tag_id = ?)
+ORDER BY p.updated_at DESC
+LIMIT ? OFFSET ?;
+
+-- name: GetProjectsWithClientByIDs :many
+SELECT p.id, p.name, p.client_id, p.status, p.hourly_rate, p.deadline, p.scheduled_start,
+       p.invoice_cc_email, p.invoice_cc_description, p.schedule_comments,
+       p.additional_info, p.additional_info2, p.discount_percent, p.discount_reason,
+       p.adjustment_amount, p.adjustment_reason, p.currency_display, 
+       p.currency_conversion_rate, p.flat_fee_invoice, p.notes,
+       p.estimated_hours, p.estimated_amount,
+       CAST(COALESCE(ts.hours, 0) AS REAL) AS actual_hours,
+       CAST(COALESCE(ts.amount, 0) AS REAL) AS actual_amount,
+       p.updated_at, p.created_at, p.deleted_at,
+       c.name as client_name
+FROM project p
+JOIN client c ON p.client_id = c.id
+LEFT JOIN (
+    SELECT project_id, SUM(CASE WHEN unit = 'hour' THEN hours_worked ELSE 0 END) AS hours,
+        SUM(hours_worked * hourly_rate) AS amount
+    FROM timesheet
+    WHERE deleted_at IS NULL
+    GROUP BY project_id
+) ts ON ts.project_id = p.id
+WHERE p.deleted_at IS NULL AND c.deleted_at IS NULL
+  AND p.id IN (sqlc.slice(ids))
+ORDER BY p.updated_at DESC;
+
+-- name: GetProjectsByTagCount :one
+SELECT COUNT(*) 
+FROM project p
+JOIN client c ON p.client_id = c.id
+WHERE p.deleted_at IS NULL AND c.deleted_at IS NULL
+  AND p.id IN (SELECT project_id FROM project_tag WHERE tag_id = ?);
+
+-- name: SearchProjectsWithClient :many
+SELECT p.id, p.name, p.client_id, p.status, c.name as client_name
+FROM project p
+JOIN client c ON p.client_id = c.id
+WHERE p.deleted_at IS NULL AND c.deleted_at IS NULL AND p.status != 'Archived'
+  AND (p.name || ' ' || c.name) LIKE sqlc.arg(pattern) ESCAPE '\'
+ORDER BY MAX(
+    p.updated_at,
+    COALESCE((SELECT MAX(t.updated_at) FROM timesheet t WHERE t.project_id = p.id AND t.deleted_at IS NULL), p.updated_at),
+    COALESCE((SELECT MAX(i.updated_at) FROM invoice i WHERE i.project_id = p.id AND i.deleted_at IS NULL), p.updated_at)
+) DESC, p.name
+LIMIT sqlc.arg(limit);
+
+-- name: RestoreProject :exec
+UPDATE project 
+SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NOT NULL;
+
+-- name: DeleteClientProjects :exec
+UPDATE project 
+SET deleted_at = (SELECT c.deleted_at FROM client c WHERE c.id = project.client_id) 
+WHERE client_id = ? AND deleted_at IS NULL;
+
+-- name: RestoreClientProjects :exec
+UPDATE project 
+SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP 
+WHERE client_id = ? AND deleted_at = (SELECT c.deleted_at FROM client c WHERE c.id = project.client_id);
\ No newline at end of file
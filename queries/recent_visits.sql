@@ -0,0 +1,21 @@
+-- name: RecordVisit :exec
+INSERT INTO recent_visit (user_id, kind, item_id, last_visited_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (user_id, kind, item_id) DO UPDATE SET visit_count = visit_count + 1, last_visited_at = excluded.last_visited_at;
+
+-- name: GetRecentClientVisits :many
+SELECT v.item_id, v.visit_count, v.last_visited_at, c.name
+FROM recent_visit v
+JOIN client c ON c.id = v.item_id
+WHERE v.user_id = ? AND v.kind = 'client' AND c.deleted_at IS NULL
+ORDER BY v.last_visited_at DESC
+LIMIT ?;
+
+-- name: GetRecentProjectVisits :many
+SELECT v.item_id, v.visit_count, v.last_visited_at, p.name, c.name as client_name
+FROM recent_visit v
+JOIN project p ON p.id = v.item_id
+JOIN client c ON c.id = p.client_id
+WHERE v.user_id = ? AND v.kind = 'project' AND p.deleted_at IS NULL AND c.deleted_at IS NULL
+ORDER BY v.last_visited_at DESC
+LIMIT ?;
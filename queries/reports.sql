@@ -0,0 +1,103 @@
+-- name: GetClientLifetimeValue :one
+SELECT c.id, c.name,
+       CAST(COALESCE(date(MIN(p.created_at)), '') AS TEXT) AS first_project_date,
+       CAST(COUNT(i.id) AS INTEGER) AS invoices,
+       CAST(COALESCE(SUM(i.amount_due), 0) AS REAL) AS billed,
+       CAST(COALESCE(SUM(CASE WHEN i.date_paid IS NOT NULL THEN COALESCE(i.amount_paid, i.amount_due - i.credit_applied) ELSE COALESCE(i.amount_paid, 0) END), 0) AS REAL) AS paid
+FROM client c
+LEFT JOIN project p ON p.client_id = c.id AND p.deleted_at IS NULL
+LEFT JOIN invoice i ON i.project_id = p.id AND i.deleted_at IS NULL AND i.voided_at IS NULL
+WHERE c.id = ? AND c.deleted_at IS NULL
+GROUP BY c.id, c.name;
+
+-- name: GetClientLifetimeValues :many
+SELECT c.id, c.name,
+       CAST(COALESCE(date(MIN(p.created_at)), '') AS TEXT) AS first_project_date,
+       CAST(COUNT(i.id) AS INTEGER) AS invoices,
+       CAST(COALESCE(SUM(i.amount_due), 0) AS REAL) AS billed,
+       CAST(COALESCE(SUM(CASE WHEN i.date_paid IS NOT NULL THEN COALESCE(i.amount_paid, i.amount_due - i.credit_applied) ELSE COALESCE(i.amount_paid, 0) END), 0) AS REAL) AS paid
+FROM client c
+LEFT JOIN project p ON p.client_id = c.id AND p.deleted_at IS NULL
+LEFT JOIN invoice i ON i.project_id = p.id AND i.deleted_at IS NULL AND i.voided_at IS NULL
+WHERE c.deleted_at IS NULL
+GROUP BY c.id, c.name
+ORDER BY c.name;
+
+-- name: GetProjectMargins :many
+SELECT p.id, p.name, p.client_id, c.name AS client_name,
+       CAST(COALESCE(SUM(CASE WHEN t.unit = 'hour' THEN t.hours_worked ELSE 0 END), 0) AS REAL) AS hours,
+       CAST(COALESCE(SUM(t.hours_worked * t.hourly_rate), 0) AS REAL) AS billed,
+       CAST(COALESCE(SUM(t.hours_worked * COALESCE(t.cost_rate, 0)), 0) AS REAL) AS cost
+FROM project p
+JOIN client c ON c.id = p.client_id AND c.deleted_at IS NULL
+JOIN timesheet t ON t.project_id = p.id AND t.deleted_at IS NULL
+WHERE p.deleted_at IS NULL
+GROUP BY p.id, p.name, p.client_id, c.name
+ORDER BY c.name, p.name;
+
+-- name: GetUnbilledWork :many
+SELECT p.id, p.name, p.client_id, c.name AS client_name,
+       CAST(COUNT(t.id) AS INTEGER) AS entries,
+       CAST(COALESCE(SUM(CASE WHEN t.unit = 'hour' THEN t.hours_worked ELSE 0 END), 0) AS REAL) AS hours,
+       CAST(COALESCE(SUM(t.hours_worked * t.hourly_rate), 0) AS REAL) AS amount,
+       CAST(MIN(substr(t.work_date, 1, 10)) AS TEXT) AS oldest_work_date
+FROM project p
+JOIN client c ON c.id = p.client_id AND c.deleted_at IS NULL
+JOIN timesheet t ON t.project_id = p.id AND t.deleted_at IS NULL
+WHERE p.deleted_at IS NULL AND t.hours_worked > 0 AND t.pending_approval = false
+  AND NOT EXISTS (
+    SELECT 1 FROM invoice i
+    WHERE (i.project_id = t.project_id OR i.id IN (SELECT ip.invoice_id FROM invoice_project ip WHERE ip.project_id = t.project_id))
+      AND i.deleted_at IS NULL AND i.voided_at IS NULL AND i.created_at >= t.created_at
+      AND (i.timesheets_from IS NULL OR substr(i.timesheets_from, 1, 10) <= substr(t.work_date, 1, 10))
+      AND (i.timesheets_to IS NULL OR substr(i.timesheets_to, 1, 10) >= substr(t.work_date, 1, 10))
+  )
+GROUP BY p.id, p.name, p.client_id, c.name
+ORDER BY oldest_work_date, c.name, p.name;
+
+-- name: GetUnpaidInvoices :many
+SELECT i.id, i.invoice_number, i.invoice_date, i.due_date, i.payment_terms, i.amount_due, i.amount_paid, i.credit_applied,
+       p.id AS project_id, p.name AS project_name, c.id AS client_id, c.name AS client_name
+FROM invoice i
+JOIN project p ON p.id = i.project_id AND p.deleted_at IS NULL
+JOIN client c ON c.id = p.client_id AND c.deleted_at IS NULL
+WHERE i.date_paid IS NULL AND i.deleted_at IS NULL AND i.voided_at IS NULL
+ORDER BY i.invoice_date, i.id;
+
+-- name: GetPaidInvoiceHistory :many
+SELECT p.client_id, i.invoice_date, i.due_date, i.date_paid, i.payment_terms
+FROM invoice i
+JOIN project p ON p.id = i.project_id
+WHERE i.date_paid IS NOT NULL AND i.deleted_at IS NULL AND i.voided_at IS NULL
+ORDER BY i.invoice_date;
+
+-- name: GetDailyInvoiceTotals :many
+SELECT CAST(substr(i.invoice_date, 1, 10) AS TEXT) AS day,
+       CAST(COALESCE(SUM(i.amount_due), 0) AS REAL) AS invoiced
+FROM invoice i
+JOIN project p ON p.id = i.project_id AND p.deleted_at IS NULL
+JOIN client c ON c.id = p.client_id AND c.deleted_at IS NULL
+WHERE i.deleted_at IS NULL AND i.voided_at IS NULL AND substr(i.invoice_date, 1, 10) >= sqlc.arg(since)
+GROUP BY day
+ORDER BY day;
+
+-- name: GetDailyPaymentTotals :many
+SELECT CAST(substr(i.date_paid, 1, 10) AS TEXT) AS day,
+       CAST(COALESCE(SUM(COALESCE(i.amount_paid, i.amount_due - i.credit_applied)), 0) AS REAL) AS received
+FROM invoice i
+JOIN project p ON p.id = i.project_id AND p.deleted_at IS NULL
+JOIN client c ON c.id = p.client_id AND c.deleted_at IS NULL
+WHERE i.date_paid IS NOT NULL AND i.deleted_at IS NULL AND i.voided_at IS NULL AND substr(i.date_paid, 1, 10) >= sqlc.arg(since)
+GROUP BY day
+ORDER BY day;
+
+-- name: GetDailyTimesheetTotals :many
+SELECT CAST(substr(t.work_date, 1, 10) AS TEXT) AS day,
+       CAST(COALESCE(SUM(CASE WHEN t.unit = 'hour' THEN t.hours_worked ELSE 0 END), 0) AS REAL) AS hours,
+       CAST(COALESCE(SUM(t.hours_worked * t.hourly_rate), 0) AS REAL) AS billed
+FROM timesheet t
+JOIN project p ON p.id = t.project_id AND p.deleted_at IS NULL
+JOIN client c ON c.id = p.client_id AND c.deleted_at IS NULL
+WHERE t.deleted_at IS NULL AND t.pending_approval = false AND substr(t.work_date, 1, 10) >= sqlc.arg(since)
+GROUP BY day
+ORDER BY day;
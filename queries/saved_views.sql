@@ -0,0 +1,18 @@
+-- name: InsertSavedView :execlastid
+INSERT INTO saved_view (user_id, list, name, filter) 
+VALUES (?, ?, ?, ?);
+
+-- name: GetSavedView :one
+SELECT id, user_id, list, name, filter, updated_at, created_at 
+FROM saved_view 
+WHERE id = ?;
+
+-- name: GetSavedViewsForList :many
+SELECT id, user_id, list, name, filter, updated_at, created_at 
+FROM saved_view 
+WHERE list = ? AND user_id IS ? 
+ORDER BY name, id;
+
+-- name: DeleteSavedView :exec
+DELETE FROM saved_view 
+WHERE id = ?;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- name: GetScheduledTask :one
+SELECT name, last_run_at, last_job_id, last_error, updated_at
+FROM scheduled_task
+WHERE name = ?;
+
+-- name: RecordScheduledTaskRun :exec
+INSERT INTO scheduled_task (name, last_run_at, last_job_id, last_error)
+VALUES (?, ?, ?, '')
+ON CONFLICT (name) DO UPDATE SET last_run_at = excluded.last_run_at, last_job_id = excluded.last_job_id, last_error = '', updated_at = CURRENT_TIMESTAMP;
+
+-- name: RecordScheduledTaskError :exec
+INSERT INTO scheduled_task (name, last_error)
+VALUES (?, ?)
+ON CONFLICT (name) DO UPDATE SET last_error = excluded.last_error, updated_at = CURRENT_TIMESTAMP;
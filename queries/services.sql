@@ -0,0 +1,24 @@
+-- name: InsertService :execlastid
+INSERT INTO service (name, default_rate, unit) 
+VALUES (?, ?, ?);
+
+-- name: GetService :one
+SELECT id, name, default_rate, unit, updated_at, created_at, deleted_at 
+FROM service 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: GetAllServices :many
+SELECT id, name, default_rate, unit, updated_at, created_at, deleted_at 
+FROM service 
+WHERE deleted_at IS NULL
+ORDER BY name;
+
+-- name: UpdateService :exec
+UPDATE service 
+SET name = ?, default_rate = ?, unit = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: DeleteService :exec
+UPDATE service 
+SET deleted_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
\ No newline at end of file
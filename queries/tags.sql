@@ -0,0 +1,72 @@
+-- name: InsertTag :execlastid
+INSERT INTO tag (name, color) 
+VALUES (?, ?);
+
+-- name: GetTag :one
+SELECT id, name, color, updated_at, created_at 
+FROM tag 
+WHERE id = ?;
+
+-- name: GetAllTags :many
+SELECT id, name, color, updated_at, created_at 
+FROM tag 
+ORDER BY name;
+
+-- name: UpdateTag :exec
+UPDATE tag 
+SET name = ?, color = ?, updated_at = CURRENT_TIMESTAMP 
+WHERE id = ?;
+
+-- name: DeleteTag :exec
+DELETE FROM tag 
+WHERE id = ?;
+
+-- name: DeleteClientTagsByTag :exec
+DELETE FROM client_tag 
+WHERE tag_id = ?;
+
+-- name: DeleteProjectTagsByTag :exec
+DELETE FROM project_tag 
+WHERE tag_id = ?;
+
+-- name: DeleteClientTags :exec
+DELETE FROM client_tag 
+WHERE client_id = ?;
+
+-- name: InsertClientTag :exec
+INSERT INTO client_tag (client_id, tag_id) 
+VALUES (?, ?);
+
+-- name: DeleteProjectTags :exec
+DELETE FROM project_tag 
+WHERE project_id = ?;
+
+-- name: InsertProjectTag :exec
+INSERT INTO project_tag (project_id, tag_id) 
+VALUES (?, ?);
+
+-- name: GetTagsForClient :many
+SELECT t.id, t.name, t.color, t.updated_at, t.created_at 
+FROM tag t
+JOIN client_tag ct ON ct.tag_id = t.id
+WHERE ct.client_id = ?
+ORDER BY t.name;
+
+-- name: GetTagsForProject :many
+SELECT t.id, t.name, t.color, t.updated_at, t.created_at 
+FROM tag t
+JOIN project_tag pt ON pt.tag_id = t.id
+WHERE pt.project_id = ?
+ORDER BY t.name;
+
+-- name: GetAllClientTags :many
+SELECT ct.client_id, t.id, t.name, t.color, t.updated_at, t.created_at 
+FROM client_tag ct
+JOIN tag t ON ct.tag_id = t.id
+ORDER BY t.name;
+
+-- name: GetAllProjectTags :many
+SELECT pt.project_id, t.id, t.name, t.color, t.updated_at, t.created_at 
+FROM project_tag pt
+JOIN tag t ON pt.tag_id = t.id
+ORDER BY t.name;
\ No newline at end of file
@@ -3,22 +3,135 @@ INSERT INTO timesheet (project_id, work_date, hours_worked, hourly_rate, descrip
 VALUES (?, ?, ?, ?, ?);
 
 -- name: GetTimesheet :one
-SELECT id, project_id, work_date, hours_worked, hourly_rate, description, updated_at, created_at, deleted_at 
-FROM timesheet 
-WHERE id = ? AND deleted_at IS NULL;
+SELECT t.id, t.project_id, t.work_date, t.hours_worked, t.hourly_rate, t.cost_rate, t.description, t.user_id, t.service_id, s.name AS service_name, t.unit, t.work_type, t.pending_approval, t.updated_at, t.created_at, t.deleted_at 
+FROM timesheet t
+LEFT JOIN service s ON s.id = t.service_id
+WHERE t.id = ? AND t.deleted_at IS NULL;
+
+-- name: GetTimesheetTotalsByProject :one
+SELECT COUNT(*) AS entries,
+    CAST(COALESCE(SUM(CASE WHEN unit = 'hour' THEN hours_worked ELSE 0 END), 0) AS REAL) AS hours,
+    CAST(COALESCE(SUM(hours_worked * hourly_rate), 0) AS REAL) AS billed,
+    CAST(COALESCE(SUM(hours_worked * COALESCE(cost_rate, 0)), 0) AS REAL) AS cost
+FROM timesheet
+WHERE project_id = ? AND deleted_at IS NULL;
 
 -- name: GetTimesheetsByProject :many
-SELECT id, project_id, work_date, hours_worked, hourly_rate, description, updated_at, created_at, deleted_at 
-FROM timesheet 
-WHERE project_id = ? AND deleted_at IS NULL
-ORDER BY work_date DESC, created_at DESC;
+SELECT t.id, t.project_id, t.work_date, t.hours_worked, t.hourly_rate, t.cost_rate, t.description, t.user_id, t.service_id, s.name AS service_name, t.unit, t.work_type, t.pending_approval, t.updated_at, t.created_at, t.deleted_at 
+FROM timesheet t
+LEFT JOIN service s ON s.id = t.service_id
+WHERE t.project_id = ? AND t.deleted_at IS NULL
+ORDER BY t.work_date DESC, t.created_at DESC;
+
+-- name: GetTimesheetsByProjectWithPagination :many
+SELECT t.id, t.project_id, t.work_date, t.hours_worked, t.hourly_rate, t.cost_rate, t.description, t.user_id, t.service_id, s.name AS service_name, t.unit, t.work_type, t.pending_approval, t.updated_at, t.created_at, t.deleted_at 
+FROM timesheet t
+LEFT JOIN service s ON s.id = t.service_id
+WHERE t.project_id = ? AND t.deleted_at IS NULL
+ORDER BY t.work_date DESC, t.created_at DESC, t.id DESC
+LIMIT ? OFFSET ?;
 
 -- name: UpdateTimesheet :exec
 UPDATE timesheet 
 SET work_date = ?, hours_worked = ?, hourly_rate = ?, description = ?, updated_at = CURRENT_TIMESTAMP 
 WHERE id = ? AND deleted_at IS NULL;
 
+-- name: SetTimesheetUser :exec
+UPDATE timesheet 
+SET user_id = ? 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: SetTimesheetCostRate :exec
+UPDATE timesheet 
+SET cost_rate = ? 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: SetTimesheetService :exec
+UPDATE timesheet 
+SET service_id = ?, unit = ? 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: SetTimesheetWorkType :exec
+UPDATE timesheet 
+SET work_type = ? 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: SetTimesheetPendingApproval :exec
+UPDATE timesheet 
+SET pending_approval = ? 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: GetTimesheetsPendingApproval :many
+SELECT t.id, t.project_id, p.name AS project_name, t.work_date, t.hours_worked, t.description, t.unit, t.user_id, u.name AS user_name, t.created_at
+FROM timesheet t
+JOIN project p ON p.id = t.project_id
+LEFT JOIN user u ON u.id = t.user_id
+WHERE t.pending_approval = true AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+ORDER BY t.work_date, t.created_at;
+
+-- name: GetTimesheetLockingInvoices :many
+-- Invoices that bill a timesheet entry: those not voided or deleted, made after the entry was
+-- logged, whose timesheet range takes in its work date, including consolidated invoices.
+-- Milestone invoices bill a fixed fee rather than the hours worked, so they never lock.
+SELECT DISTINCT i.id
+FROM timesheet t
+JOIN invoice i ON i.project_id = t.project_id
+    OR i.id IN (SELECT ip.invoice_id FROM invoice_project ip WHERE ip.project_id = t.project_id)
+WHERE t.id = ? AND t.deleted_at IS NULL AND i.deleted_at IS NULL AND i.voided_at IS NULL AND i.created_at >= t.created_at
+  AND (i.timesheets_from IS NULL OR substr(i.timesheets_from, 1, 10) <= substr(t.work_date, 1, 10))
+  AND (i.timesheets_to IS NULL OR substr(i.timesheets_to, 1, 10) >= substr(t.work_date, 1, 10))
+  AND NOT EXISTS (SELECT 1 FROM milestone m WHERE m.invoice_id = i.id)
+ORDER BY i.id;
+
+-- name: GetLockedTimesheetIDs :many
+SELECT DISTINCT t.id
+FROM timesheet t
+JOIN invoice i ON i.project_id = t.project_id
+    OR i.id IN (SELECT ip.invoice_id FROM invoice_project ip WHERE ip.project_id = t.project_id)
+WHERE t.project_id = ? AND t.deleted_at IS NULL AND i.deleted_at IS NULL AND i.voided_at IS NULL AND i.created_at >= t.created_at
+  AND (i.timesheets_from IS NULL OR substr(i.timesheets_from, 1, 10) <= substr(t.work_date, 1, 10))
+  AND (i.timesheets_to IS NULL OR substr(i.timesheets_to, 1, 10) >= substr(t.work_date, 1, 10))
+  AND NOT EXISTS (SELECT 1 FROM milestone m WHERE m.invoice_id = i.id)
+ORDER BY t.id;
+
 -- name: DeleteTimesheet :exec
 UPDATE timesheet 
 SET deleted_at = CURRENT_TIMESTAMP 
-WHERE id = ? AND deleted_at IS NULL;
\ No newline at end of file
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: GetClientHoursBetween :one
+SELECT CAST(COALESCE(SUM(t.hours_worked), 0) AS REAL) AS hours
+FROM timesheet t
+JOIN project p ON p.id = t.project_id
+WHERE p.client_id = sqlc.arg(client_id) AND substr(t.work_date, 1, 10) >= sqlc.arg(start_date) AND substr(t.work_date, 1, 10) < sqlc.arg(end_date)
+  AND t.unit = 'hour' AND t.deleted_at IS NULL AND p.deleted_at IS NULL;
+
+-- name: DeleteProjectTimesheets :exec
+UPDATE timesheet 
+SET deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = timesheet.project_id) 
+WHERE project_id = ? AND deleted_at IS NULL;
+
+-- name: RestoreProjectTimesheets :exec
+UPDATE timesheet 
+SET deleted_at = NULL 
+WHERE project_id = ? AND deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = timesheet.project_id);
+
+-- name: DeleteClientTimesheets :exec
+UPDATE timesheet 
+SET deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = timesheet.project_id) 
+WHERE project_id IN (SELECT id FROM project WHERE client_id = ? AND deleted_at IS NOT NULL) AND deleted_at IS NULL;
+
+-- name: RestoreClientTimesheets :exec
+UPDATE timesheet 
+SET deleted_at = NULL 
+WHERE project_id IN (SELECT p.id FROM project p WHERE p.client_id = ? AND p.deleted_at = (SELECT c.deleted_at FROM client c WHERE c.id = p.client_id)) 
+  AND deleted_at = (SELECT p.deleted_at FROM project p WHERE p.id = timesheet.project_id);
+
+-- name: GetTimesheetsForReport :many
+SELECT t.id, t.project_id, p.name AS project_name, t.work_date, t.hours_worked, t.description, t.unit, t.work_type
+FROM timesheet t
+JOIN project p ON p.id = t.project_id
+WHERE (p.id = sqlc.arg(project_id) OR p.client_id = sqlc.arg(client_id))
+  AND substr(t.work_date, 1, 10) >= sqlc.arg(start_date) AND substr(t.work_date, 1, 10) <= sqlc.arg(end_date)
+  AND t.pending_approval = false AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+ORDER BY t.work_date, t.created_at;
\ No newline at end of file
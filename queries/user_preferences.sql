@@ -0,0 +1,10 @@
+-- name: GetUserPreferences :one
+SELECT user_id, page_size, landing_page, date_format, theme, updated_at, created_at 
+FROM user_preferences 
+WHERE user_id = ?;
+
+-- name: SaveUserPreferences :exec
+INSERT INTO user_preferences (user_id, page_size, landing_page, date_format, theme) 
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (user_id) DO UPDATE 
+SET page_size = excluded.page_size, landing_page = excluded.landing_page, date_format = excluded.date_format, theme = excluded.theme, updated_at = CURRENT_TIMESTAMP;
\ No newline at end of file
@@ -0,0 +1,31 @@
+-- name: InsertUserSession :execlastid
+INSERT INTO user_session (user_id, token, remember_me, user_agent, ip_address, expires_at, last_seen_at)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetUserSessionByToken :one
+SELECT id, user_id, token, remember_me, user_agent, ip_address, expires_at, last_seen_at, created_at
+FROM user_session
+WHERE token = ?;
+
+-- name: GetUserSessionsByUser :many
+SELECT id, user_id, token, remember_me, user_agent, ip_address, expires_at, last_seen_at, created_at
+FROM user_session
+WHERE user_id = ? AND expires_at > ?
+ORDER BY last_seen_at DESC;
+
+-- name: TouchUserSession :exec
+UPDATE user_session
+SET last_seen_at = ?, expires_at = ?
+WHERE id = ?;
+
+-- name: DeleteUserSession :exec
+DELETE FROM user_session
+WHERE id = ?;
+
+-- name: DeleteOtherUserSessions :execrows
+DELETE FROM user_session
+WHERE user_id = ? AND id != ?;
+
+-- name: DeleteExpiredUserSessions :exec
+DELETE FROM user_session
+WHERE expires_at <= ?;
\ No newline at end of file
@@ -0,0 +1,55 @@
+-- name: InsertUser :execlastid
+INSERT INTO user (name, email, hashed_password, role) 
+VALUES (?, ?, ?, ?);
+
+-- name: GetUser :one
+SELECT id, name, email, hashed_password, role, updated_at, created_at, deleted_at 
+FROM user 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: GetUserByEmail :one
+SELECT id, name, email, hashed_password, role, updated_at, created_at, deleted_at 
+FROM user 
+WHERE email = ? AND deleted_at IS NULL;
+
+-- name: GetAllUsers :many
+SELECT id, name, email, hashed_password, role, updated_at, created_at, deleted_at 
+FROM user 
+WHERE deleted_at IS NULL
+ORDER BY name;
+
+-- name: GetUsersCount :one
+SELECT COUNT(*) FROM user WHERE deleted_at IS NULL;
+
+-- name: DeleteUser :exec
+UPDATE user 
+SET deleted_at = CURRENT_TIMESTAMP 
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: ShareProject :exec
+INSERT INTO project_share (project_id, user_id) 
+VALUES (?, ?)
+ON CONFLICT (project_id, user_id) DO NOTHING;
+
+-- name: UnshareProject :exec
+DELETE FROM project_share 
+WHERE project_id = ? AND user_id = ?;
+
+-- name: GetProjectSharedUsers :many
+SELECT u.id, u.name, u.email, u.hashed_password, u.role, u.updated_at, u.created_at, u.deleted_at 
+FROM user u
+JOIN project_share s ON s.user_id = u.id
+WHERE s.project_id = ? AND u.deleted_at IS NULL
+ORDER BY u.name;
+
+-- name: IsProjectShared :one
+SELECT COUNT(*) FROM project_share s
+JOIN project p ON s.project_id = p.id
+WHERE s.project_id = ? AND s.user_id = ? AND p.deleted_at IS NULL;
+
+-- name: GetSharedProjectIDs :many
+SELECT s.project_id 
+FROM project_share s
+JOIN project p ON s.project_id = p.id
+WHERE s.user_id = ? AND p.deleted_at IS NULL
+ORDER BY p.name;
\ No newline at end of file
@@ -0,0 +1,10 @@
+// Package ui holds the HTML templates and static assets, embedded so that the server
+// binary is self-contained and can run from any working directory.
+package ui
+
+import "embed"
+
+// Files contains the html and static directories
+//
+//go:embed "html" "static"
+var Files embed.FS
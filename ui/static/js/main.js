@@ -93,40 +93,18 @@ function showConfirmation(message, onConfirm) {
     document.addEventListener('keydown', handleKeyDown);
 }
 
-// Delete confirmation using event listeners
+// Delete confirmation: forms marked with data-confirm ask before they are submitted
 function setupDeleteConfirmations() {
-    var deleteButtons = document.querySelectorAll('button[title*="Delete"], button.btn-delete');
+    var forms = document.querySelectorAll('form[data-confirm]');
     
-    for (var i = 0; i < deleteButtons.length; i++) {
-        var button = deleteButtons[i];
-        
-        button.addEventListener('click', function(e) {
+    for (var i = 0; i < forms.length; i++) {
+        forms[i].addEventListener('submit', function(e) {
             e.preventDefault();
-            e.stopPropagation();
             
-            var form = this.closest('form');
-            if (form) {
-                var action = form.getAttribute('action');
-                
-                var itemType = 'item';
-                if (action.indexOf('/client/delete/') !== -1) {
-                    itemType = 'client';
-                } else if (action.indexOf('/project/delete/') !== -1) {
-                    itemType = 'project';
-                } else if (action.indexOf('/timesheet/delete/') !== -1) {
-                    itemType = 'timesheet';
-                } else if (action.indexOf('/invoice/delete/') !== -1) {
-                    itemType = 'invoice';
-                }
-                
-                var message = 'Are you sure you want to delete this ' + itemType + '? This action cannot be undone.';
-                
-                showConfirmation(message, function() {
-                    form.submit();
-                });
-            }
-            
-            return false;
+            var form = this;
+            showConfirmation(form.getAttribute('data-confirm'), function() {
+                form.submit();
+            });
         });
     }
 }
@@ -155,10 +133,522 @@ function setupClientDetailsToggle() {
     }
 }
 
+// Upload a logo as soon as it is dropped on or chosen in the drop zone
+function setupLogoDropZone() {
+    var zone = document.getElementById('logo-drop-zone');
+    if (!zone) return;
+
+    var input = zone.querySelector('input[type=file]');
+    var form = zone.closest('form');
+
+    zone.addEventListener('dragover', function(e) {
+        e.preventDefault();
+        zone.classList.add('dragging');
+    });
+    zone.addEventListener('dragleave', function() {
+        zone.classList.remove('dragging');
+    });
+    zone.addEventListener('drop', function(e) {
+        e.preventDefault();
+        zone.classList.remove('dragging');
+        if (e.dataTransfer.files.length > 0) {
+            input.files = e.dataTransfer.files;
+            form.submit();
+        }
+    });
+    input.addEventListener('change', function() {
+        if (input.files.length > 0) {
+            form.submit();
+        }
+    });
+}
+
+// Fill in a service's default rate when it is chosen on a timesheet entry
+function setupServiceRate() {
+    var select = document.getElementById('service_id');
+    var rate = document.querySelector('input[name=hourly_rate]');
+    if (!select || !rate) return;
+
+    var initialRate = rate.value;
+    select.addEventListener('change', function() {
+        var option = select.options[select.selectedIndex];
+        rate.value = option.getAttribute('data-rate') || initialRate;
+    });
+}
+
+// Poll a queued job until it finishes, then download its result
+function setupJobPolling() {
+    var status = document.getElementById('job-status');
+    if (!status) return;
+
+    var url = status.getAttribute('data-status-url');
+    var show = function(state) {
+        ['pending', 'done', 'failed'].forEach(function(name) {
+            status.querySelector('.job-' + name).hidden = name !== state;
+        });
+    };
+
+    var poll = function() {
+        fetch(url, { headers: { 'Accept': 'application/json' }, credentials: 'same-origin' })
+            .then(function(response) { return response.json(); })
+            .then(function(job) {
+                if (job.status === 'done') {
+                    show('done');
+                    window.location = job.download_url;
+                } else if (job.status === 'failed') {
+                    status.querySelector('.job-error').textContent = job.error;
+                    show('failed');
+                } else {
+                    setTimeout(poll, 1000);
+                }
+            })
+            .catch(function() {
+                setTimeout(poll, 3000);
+            });
+    };
+
+    var initial = status.getAttribute('data-status');
+    if (initial === 'queued' || initial === 'running') {
+        poll();
+    }
+}
+
+// Show a list view as soon as it is chosen, and offer only the comparisons that suit the
+// field a condition is being added on
+function setupListFilter() {
+    var view = document.getElementById('list-filter-view');
+    if (view) {
+        view.addEventListener('change', function() {
+            view.form.submit();
+        });
+    }
+
+    document.querySelectorAll('.list-filter-add').forEach(function(form) {
+        var field = form.querySelector('select[name=field]');
+        var op = form.querySelector('select[name=op]');
+        var update = function() {
+            var first = null;
+            op.querySelectorAll('option').forEach(function(option) {
+                var matches = option.getAttribute('data-field') === field.value;
+                option.hidden = !matches;
+                option.disabled = !matches;
+                if (matches && !first) first = option;
+            });
+            op.querySelectorAll('optgroup').forEach(function(group) {
+                group.hidden = group.querySelector('option:not([hidden])') === null;
+            });
+            if (first && (op.selectedOptions.length === 0 || op.selectedOptions[0].disabled)) {
+                first.selected = true;
+            }
+        };
+        field.addEventListener('change', update);
+        update();
+    });
+}
+
+// Search projects as the user types in a project picker. Choosing one fills in its hidden
+// project_id and submits the picker's form, which takes the user to that project.
+function setupProjectPickers() {
+    document.querySelectorAll('.project-picker').forEach(function(picker) {
+        var input = picker.querySelector('.project-picker-input');
+        var projectID = picker.querySelector('input[name=project_id]');
+        var results = picker.querySelector('.project-picker-results');
+        var url = picker.getAttribute('data-search-url');
+        var matches = [];
+        var active = -1;
+        var timer;
+
+        var close = function() {
+            results.hidden = true;
+            input.setAttribute('aria-expanded', 'false');
+        };
+
+        var choose = function(project) {
+            input.value = project.name;
+            projectID.value = project.id;
+            close();
+            if (input.form) {
+                input.form.submit();
+            }
+        };
+
+        var render = function() {
+            results.innerHTML = '';
+            matches.forEach(function(project, i) {
+                var option = document.createElement('li');
+                option.className = 'project-picker-option' + (i === active ? ' active' : '');
+                option.setAttribute('role', 'option');
+                option.setAttribute('aria-selected', i === active ? 'true' : 'false');
+                option.textContent = project.name;
+
+                var client = document.createElement('span');
+                client.className = 'project-picker-client';
+                client.textContent = project.client_name;
+                option.appendChild(client);
+
+                // mousedown rather than click so that it fires before the input loses focus
+                option.addEventListener('mousedown', function(e) {
+                    e.preventDefault();
+                    choose(project);
+                });
+                results.appendChild(option);
+            });
+            results.hidden = matches.length === 0;
+            input.setAttribute('aria-expanded', matches.length === 0 ? 'false' : 'true');
+        };
+
+        var search = function() {
+            fetch(url + '?q=' + encodeURIComponent(input.value), { headers: { 'Accept': 'application/json' }, credentials: 'same-origin' })
+                .then(function(response) { return response.json(); })
+                .then(function(found) {
+                    matches = found || [];
+                    active = -1;
+                    render();
+                })
+                .catch(function() {
+                    matches = [];
+                    render();
+                });
+        };
+
+        input.addEventListener('input', function() {
+            projectID.value = '';
+            clearTimeout(timer);
+            timer = setTimeout(search, 200);
+        });
+        input.addEventListener('focus', search);
+        input.addEventListener('blur', close);
+        input.addEventListener('keydown', function(e) {
+            if (results.hidden) return;
+            if (e.key === 'ArrowDown') {
+                active = Math.min(active + 1, matches.length - 1);
+                render();
+                e.preventDefault();
+            } else if (e.key === 'ArrowUp') {
+                active = Math.max(active - 1, 0);
+                render();
+                e.preventDefault();
+            } else if (e.key === 'Enter' && active >= 0) {
+                e.preventDefault();
+                choose(matches[active]);
+            } else if (e.key === 'Escape') {
+                close();
+            }
+        });
+    });
+}
+
+// Open the command palette with Ctrl+K (Cmd+K on a Mac) or / from any page, and go to the
+// client, project or page chosen in it. The clients and projects visited most are offered
+// before anything is typed.
+function setupCommandPalette() {
+    var overlay = document.getElementById('command-palette');
+    if (!overlay) return;
+
+    var input = overlay.querySelector('.palette-input');
+    var results = overlay.querySelector('.palette-results');
+    var url = overlay.getAttribute('data-palette-url');
+    var kinds = { client: 'Client', project: 'Project', action: 'Page' };
+    var items = [];
+    var active = 0;
+    var timer;
+    var returnFocus = null;
+
+    var render = function() {
+        results.innerHTML = '';
+        items.forEach(function(item, i) {
+            var option = document.createElement('li');
+            option.className = 'palette-option' + (i === active ? ' active' : '');
+            option.id = 'palette-option-' + i;
+            option.setAttribute('role', 'option');
+            option.setAttribute('aria-selected', i === active ? 'true' : 'false');
+
+            var kind = document.createElement('span');
+            kind.className = 'palette-kind';
+            kind.textContent = kinds[item.kind] || item.kind;
+            option.appendChild(kind);
+            option.appendChild(document.createTextNode(item.label));
+            if (item.detail) {
+                var detail = document.createElement('span');
+                detail.className = 'palette-detail';
+                detail.textContent = item.detail;
+                option.appendChild(detail);
+            }
+
+            option.addEventListener('mousedown', function(e) {
+                e.preventDefault();
+                window.location = item.url;
+            });
+            results.appendChild(option);
+        });
+        if (items.length > 0) {
+            input.setAttribute('aria-activedescendant', 'palette-option-' + active);
+            results.children[active].scrollIntoView({ block: 'nearest' });
+        } else {
+            input.removeAttribute('aria-activedescendant');
+        }
+    };
+
+    var search = function() {
+        fetch(url + '?q=' + encodeURIComponent(input.value), { headers: { 'Accept': 'application/json' }, credentials: 'same-origin' })
+            .then(function(response) { return response.json(); })
+            .then(function(found) {
+                items = (found && found.items) || [];
+                active = 0;
+                render();
+            })
+            .catch(function() {
+                items = [];
+                render();
+            });
+    };
+
+    var open = function() {
+        if (!overlay.hidden) return;
+        returnFocus = document.activeElement;
+        overlay.hidden = false;
+        input.value = '';
+        input.focus();
+        search();
+    };
+
+    var close = function() {
+        overlay.hidden = true;
+        if (returnFocus) returnFocus.focus();
+    };
+
+    var typing = function(element) {
+        return element && (element.isContentEditable || /^(INPUT|TEXTAREA|SELECT)$/.test(element.tagName));
+    };
+
+    document.addEventListener('keydown', function(e) {
+        if ((e.ctrlKey || e.metaKey) && e.key.toLowerCase() === 'k') {
+            e.preventDefault();
+            if (overlay.hidden) {
+                open();
+            } else {
+                close();
+            }
+        } else if (e.key === '/' && overlay.hidden && !typing(document.activeElement)) {
+            e.preventDefault();
+            open();
+        }
+    });
+
+    input.addEventListener('input', function() {
+        clearTimeout(timer);
+        timer = setTimeout(search, 150);
+    });
+    input.addEventListener('keydown', function(e) {
+        if (e.key === 'ArrowDown' && items.length > 0) {
+            active = (active + 1) % items.length;
+            render();
+            e.preventDefault();
+        } else if (e.key === 'ArrowUp' && items.length > 0) {
+            active = (active - 1 + items.length) % items.length;
+            render();
+            e.preventDefault();
+        } else if (e.key === 'Enter') {
+            e.preventDefault();
+            if (items[active]) window.location = items[active].url;
+        } else if (e.key === 'Escape') {
+            e.preventDefault();
+            close();
+        }
+    });
+    overlay.addEventListener('mousedown', function(e) {
+        if (e.target === overlay) close();
+    });
+
+    var button = document.getElementById('palette-open');
+    if (button) {
+        button.hidden = false;
+        button.addEventListener('click', open);
+    }
+}
+
+// Keep a draft of long forms marked with data-draft as they are typed in: in the browser at
+// once, and on the server a little later so it can be restored on another device. When a form
+// is opened with a draft that differs from what it shows, offer to restore or discard it.
+function setupFormDrafts() {
+    var form = document.querySelector('form[data-draft]');
+    if (!form || !window.fetch) return;
+
+    var url = form.getAttribute('data-draft');
+    var storageKey = 'draft:' + url;
+    var serverTimer = null;
+
+    var values = function() {
+        var params = new URLSearchParams();
+        new FormData(form).forEach(function(value, name) {
+            if (name === 'csrf_token' || typeof value !== 'string') return;
+            params.append(name, value);
+        });
+        return params.toString();
+    };
+    var readLocal = function() {
+        try {
+            return JSON.parse(localStorage.getItem(storageKey));
+        } catch (e) {
+            return null;
+        }
+    };
+    var writeLocal = function(draft) {
+        try {
+            if (draft) {
+                localStorage.setItem(storageKey, JSON.stringify(draft));
+            } else {
+                localStorage.removeItem(storageKey);
+            }
+        } catch (e) {
+            // Storage can be full or turned off; the server still keeps the draft
+        }
+    };
+    var post = function(path, body) {
+        return fetch(path, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
+            body: body,
+            credentials: 'same-origin'
+        });
+    };
+
+    var sorted = function(fields) {
+        var params = new URLSearchParams(fields);
+        params.sort();
+        return params.toString();
+    };
+
+    var restore = function(fields) {
+        var params = new URLSearchParams(fields);
+        var seen = {};
+        Array.prototype.forEach.call(form.elements, function(field) {
+            var name = field.name;
+            if (!name || name === 'csrf_token' || field.type === 'file' || field.type === 'password') return;
+            var all = params.getAll(name);
+            if (field.type === 'checkbox' || field.type === 'radio') {
+                field.checked = all.indexOf(field.value) !== -1;
+            } else if (field.multiple) {
+                Array.prototype.forEach.call(field.options, function(option) {
+                    option.selected = all.indexOf(option.value) !== -1;
+                });
+            } else {
+                var index = seen[name] || 0;
+                seen[name] = index + 1;
+                if (index < all.length) field.value = all[index];
+            }
+        });
+    };
+
+    var offer = function(draft) {
+        var notice = document.createElement('div');
+        notice.className = 'draft-notice';
+        notice.setAttribute('role', 'status');
+        var text = document.createElement('span');
+        text.textContent = 'You have unsaved changes to this form from ' + new Date(draft.updated).toLocaleString() + '.';
+        var buttons = document.createElement('span');
+        var restoreButton = document.createElement('button');
+        restoreButton.type = 'button';
+        restoreButton.textContent = 'Restore';
+        var discardButton = document.createElement('button');
+        discardButton.type = 'button';
+        discardButton.textContent = 'Discard';
+        buttons.appendChild(restoreButton);
+        buttons.appendChild(document.createTextNode(' '));
+        buttons.appendChild(discardButton);
+        notice.appendChild(text);
+        notice.appendChild(buttons);
+        form.parentNode.insertBefore(notice, form);
+
+        restoreButton.addEventListener('click', function() {
+            restore(draft.fields);
+            notice.remove();
+        });
+        discardButton.addEventListener('click', function() {
+            writeLocal(null);
+            post(url + '/discard', '');
+            notice.remove();
+        });
+    };
+
+    var save = function() {
+        var fields = values();
+        writeLocal({ fields: fields, updated: Date.now() });
+        clearTimeout(serverTimer);
+        serverTimer = setTimeout(function() {
+            post(url, fields);
+        }, 3000);
+    };
+    var localTimer = null;
+    var changed = function() {
+        clearTimeout(localTimer);
+        localTimer = setTimeout(save, 500);
+    };
+    form.addEventListener('input', changed);
+    form.addEventListener('change', changed);
+
+    // The page that answers the form either saves it, which discards the server's draft, or
+    // shows it again with what was typed still filled in
+    form.addEventListener('submit', function() {
+        clearTimeout(localTimer);
+        clearTimeout(serverTimer);
+        writeLocal(null);
+    });
+
+    var shown = values();
+    var local = readLocal();
+    fetch(url, { headers: { 'Accept': 'application/json' }, credentials: 'same-origin' })
+        .then(function(response) { return response.ok ? response.json() : null; })
+        .catch(function() { return null; })
+        .then(function(server) {
+            var draft = null;
+            if (server) {
+                // The server gives each field as a list of its values
+                var params = new URLSearchParams();
+                Object.keys(server.fields).forEach(function(name) {
+                    server.fields[name].forEach(function(value) { params.append(name, value); });
+                });
+                draft = { fields: params.toString(), updated: Date.parse(server.updated) };
+            }
+            if (local && (!draft || local.updated > draft.updated)) {
+                draft = local;
+            }
+            if (draft && sorted(draft.fields) !== sorted(shown)) {
+                offer(draft);
+            }
+        });
+}
+
+// Move focus to the summary of a form's errors so screen readers announce them, and focus
+// the field itself when one of its links is followed
+function setupErrorSummary() {
+    var summary = document.querySelector('.error-summary');
+    if (!summary) return;
+
+    summary.focus();
+    summary.querySelectorAll('a[href^="#"]').forEach(function(link) {
+        link.addEventListener('click', function(e) {
+            var field = document.getElementById(link.getAttribute('href').slice(1));
+            if (!field) return;
+            e.preventDefault();
+            field.scrollIntoView({ block: 'center' });
+            field.focus();
+        });
+    });
+}
+
 // Set up all functionality when page loads
 function setupPageFunctions() {
     setupDeleteConfirmations();
     setupClientDetailsToggle();
+    setupLogoDropZone();
+    setupServiceRate();
+    setupJobPolling();
+    setupProjectPickers();
+    setupListFilter();
+    setupErrorSummary();
+    setupCommandPalette();
+    setupFormDrafts();
 }
 
 if (document.readyState === 'loading') {